@@ -0,0 +1,158 @@
+// apierror/apierror.go
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// CodeHeader carries the code of every error response, so clients of any API version can branch
+// on it
+const CodeHeader = "Error-Code"
+
+// Code is a stable, machine-readable reason a request failed. Messages may be reworded; codes
+// are not.
+type Code string
+
+// Codes for failures any endpoint can report
+const (
+	CodeBadRequest           Code = "BAD_REQUEST"
+	CodeValidationFailed     Code = "VALIDATION_FAILED"
+	CodeUnauthenticated      Code = "UNAUTHENTICATED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeMethodNotAllowed     Code = "METHOD_NOT_ALLOWED"
+	CodeConflict             Code = "CONFLICT"
+	CodeGone                 Code = "GONE"
+	CodePreconditionFailed   Code = "PRECONDITION_FAILED"
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeInternal             Code = "INTERNAL_ERROR"
+	CodeNotImplemented       Code = "NOT_IMPLEMENTED"
+	CodeBadGateway           Code = "BAD_GATEWAY"
+	CodeUnavailable          Code = "SERVICE_UNAVAILABLE"
+)
+
+// Codes for failures particular to a resource
+const (
+	// CodeDuplicateItem is returned when a name or other key that must be unique is already taken
+	CodeDuplicateItem Code = "DUPLICATE_ITEM"
+	// CodeChoreLocked is returned when a completed chore is changed
+	CodeChoreLocked Code = "CHORE_LOCKED"
+	// CodeLimitReached is returned when a group or member already has as many of something as allowed
+	CodeLimitReached Code = "LIMIT_REACHED"
+	// CodeVotingClosed is returned when a poll or decision that has closed is voted on
+	CodeVotingClosed Code = "VOTING_CLOSED"
+	// CodeEditConflict is returned when a document was changed since the client read it
+	CodeEditConflict Code = "EDIT_CONFLICT"
+	// CodeCategoryInUse is returned when a pantry category that items still use is deleted
+	CodeCategoryInUse Code = "CATEGORY_IN_USE"
+	// CodeInProgress is returned when a job the request would start is already running
+	CodeInProgress Code = "IN_PROGRESS"
+	// CodeUnitMismatch is returned when quantities in units that cannot be converted are combined
+	CodeUnitMismatch Code = "UNIT_MISMATCH"
+	// CodeCurrencyLocked is returned when a group with expenses changes its currency
+	CodeCurrencyLocked Code = "CURRENCY_LOCKED"
+	// CodeInsufficientPoints is returned when a member redeems a reward they have too few points for
+	CodeInsufficientPoints Code = "INSUFFICIENT_POINTS"
+)
+
+// statuses maps every code to the one status it is sent with
+var statuses = map[Code]int{
+	CodeBadRequest:           http.StatusBadRequest,
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeUnauthenticated:      http.StatusUnauthorized,
+	CodeForbidden:            http.StatusForbidden,
+	CodeNotFound:             http.StatusNotFound,
+	CodeMethodNotAllowed:     http.StatusMethodNotAllowed,
+	CodeConflict:             http.StatusConflict,
+	CodeGone:                 http.StatusGone,
+	CodePreconditionFailed:   http.StatusPreconditionFailed,
+	CodePayloadTooLarge:      http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	CodeRateLimited:          http.StatusTooManyRequests,
+	CodeInternal:             http.StatusInternalServerError,
+	CodeNotImplemented:       http.StatusNotImplemented,
+	CodeBadGateway:           http.StatusBadGateway,
+	CodeUnavailable:          http.StatusServiceUnavailable,
+	CodeDuplicateItem:        http.StatusConflict,
+	CodeChoreLocked:          http.StatusConflict,
+	CodeLimitReached:         http.StatusConflict,
+	CodeVotingClosed:         http.StatusConflict,
+	CodeEditConflict:         http.StatusConflict,
+	CodeCategoryInUse:        http.StatusConflict,
+	CodeInProgress:           http.StatusConflict,
+	CodeUnitMismatch:         http.StatusConflict,
+	CodeCurrencyLocked:       http.StatusConflict,
+	CodeInsufficientPoints:   http.StatusConflict,
+}
+
+// Status returns the HTTP status the code is sent with
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusBadRequest
+}
+
+// CodeForStatus returns the general code of a status, for errors written without a code
+func CodeForStatus(status int) Code {
+	for _, code := range []Code{
+		CodeBadRequest, CodeUnauthenticated, CodeForbidden, CodeNotFound, CodeMethodNotAllowed,
+		CodeConflict, CodeGone, CodePreconditionFailed, CodePayloadTooLarge, CodeUnsupportedMediaType,
+		CodeRateLimited, CodeInternal, CodeNotImplemented, CodeBadGateway, CodeUnavailable,
+	} {
+		if statuses[code] == status {
+			return code
+		}
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// FieldError is a problem with one field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is the body of every error response from API version 2 on
+type Error struct {
+	Status  int          `json:"status"`
+	Code    Code         `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// New returns an error with the code's status
+func New(code Code, message string) *Error {
+	return &Error{Status: code.Status(), Code: code, Message: message}
+}
+
+// FromStatus returns an error with the general code of the status, for errors written without one
+func FromStatus(status int, message string) *Error {
+	return &Error{Status: status, Code: CodeForStatus(status), Message: message}
+}
+
+// WithFields adds the problems with individual fields of the request
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+// Error returns the message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Write responds with the error as JSON
+func Write(w http.ResponseWriter, err *Error) {
+	w.Header().Set(CodeHeader, string(err.Code))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(err.Status)
+	json.NewEncoder(w).Encode(err)
+}
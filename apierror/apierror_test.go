@@ -0,0 +1,63 @@
+package apierror
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCodeStatus(t *testing.T) {
+	tests := []struct {
+		code   Code
+		status int
+	}{
+		{CodeValidationFailed, http.StatusBadRequest},
+		{CodeUnauthenticated, http.StatusUnauthorized},
+		{CodeDuplicateItem, http.StatusConflict},
+		{CodeChoreLocked, http.StatusConflict},
+		{"SOMETHING_NEW", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if got := tt.code.Status(); got != tt.status {
+			t.Errorf("%s: got status %d, want %d", tt.code, got, tt.status)
+		}
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		code   Code
+	}{
+		{http.StatusBadRequest, CodeBadRequest}, // Not VALIDATION_FAILED, which shares the status
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusConflict, CodeConflict},
+		{http.StatusTooManyRequests, CodeRateLimited},
+		{http.StatusTeapot, CodeBadRequest},
+		{http.StatusInternalServerError, CodeInternal},
+		{http.StatusGatewayTimeout, CodeInternal},
+	}
+	for _, tt := range tests {
+		if got := CodeForStatus(tt.status); got != tt.code {
+			t.Errorf("%d: got %s, want %s", tt.status, got, tt.code)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	rr := httptest.NewRecorder()
+	Write(rr, New(CodeValidationFailed, "Validation failed").WithFields(FieldError{Field: "name", Message: "This field is required"}))
+
+	if rr.Code != http.StatusBadRequest || rr.Header().Get(CodeHeader) != "VALIDATION_FAILED" || rr.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %v", rr.Code, rr.Header())
+	}
+	var body Error
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != http.StatusBadRequest || body.Code != CodeValidationFailed || body.Message != "Validation failed" ||
+		len(body.Fields) != 1 || body.Fields[0].Field != "name" {
+		t.Errorf("unexpected body %+v", body)
+	}
+}
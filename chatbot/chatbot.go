@@ -0,0 +1,193 @@
+// chatbot/chatbot.go
+package chatbot
+
+import (
+	"bytes"
+	"context"
+	"cribb-backend/models"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxDiscordMessage is the longest message Discord accepts, in characters
+const maxDiscordMessage = 2000
+
+// ErrInvalidSignature is returned for slash commands that were not signed by the chat app
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+var (
+	// slackSigningSecret signs the slash commands of the Cribb Slack app (SLACK_SIGNING_SECRET)
+	slackSigningSecret string
+
+	// discordPublicKey verifies the interactions of the Cribb Discord app (DISCORD_PUBLIC_KEY)
+	discordPublicKey ed25519.PublicKey
+
+	// httpClient posts summaries to incoming webhooks
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// Configure reads the credentials slash commands are verified with from the environment. Slash
+// commands from a platform are refused until its credentials are set.
+func Configure() {
+	slackSigningSecret = strings.TrimSpace(os.Getenv("SLACK_SIGNING_SECRET"))
+	if slackSigningSecret == "" {
+		log.Println("Slack commands disabled: SLACK_SIGNING_SECRET is not set")
+	}
+
+	discordPublicKey = nil
+	if key := strings.TrimSpace(os.Getenv("DISCORD_PUBLIC_KEY")); key != "" {
+		decoded, err := hex.DecodeString(key)
+		if err != nil || len(decoded) != ed25519.PublicKeySize {
+			log.Println("Discord commands disabled: DISCORD_PUBLIC_KEY is not a hex Ed25519 public key")
+		} else {
+			discordPublicKey = decoded
+		}
+	} else {
+		log.Println("Discord commands disabled: DISCORD_PUBLIC_KEY is not set")
+	}
+}
+
+// Enabled reports whether slash commands from the platform are accepted
+func Enabled(platform models.ChatPlatform) bool {
+	switch platform {
+	case models.ChatPlatformSlack:
+		return slackSigningSecret != ""
+	case models.ChatPlatformDiscord:
+		return discordPublicKey != nil
+	}
+	return false
+}
+
+// Action is what a slash command does
+type Action string
+
+const (
+	ActionHelp            Action = "help"
+	ActionLink            Action = "link"
+	ActionCompleteChore   Action = "complete_chore"
+	ActionAddShoppingItem Action = "add_shopping_item"
+	ActionListChores      Action = "list_chores"
+	ActionListShopping    Action = "list_shopping"
+)
+
+// Command is a slash command read from chat
+type Command struct {
+	Action  Action
+	Code    string                // Link code, for link
+	Inbound models.InboundCommand // What to complete or add, run as inbound hooks run it
+}
+
+// ParseCommand reads a slash command and its text: "/chore done dishes", "/chore list",
+// "/shopping add 2 milk", "/shopping list", or "link CODE" with either command
+func ParseCommand(name, text string) Command {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "/"))
+	fields := strings.Fields(text)
+	verb, rest := "", ""
+	if len(fields) > 0 {
+		verb, rest = strings.ToLower(fields[0]), strings.Join(fields[1:], " ")
+	}
+
+	if verb == "link" && rest != "" {
+		return Command{Action: ActionLink, Code: rest}
+	}
+	switch name {
+	case "chore", "chores":
+		switch verb {
+		case "", "list", "today", "mine":
+			return Command{Action: ActionListChores}
+		case "done", "complete", "finished", "did":
+			command := models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: rest}
+			if command.Validate() == nil {
+				return Command{Action: ActionCompleteChore, Inbound: command}
+			}
+		}
+	case "shopping", "shop", "groceries":
+		switch verb {
+		case "", "list", "show":
+			return Command{Action: ActionListShopping}
+		case "add", "buy":
+			if command, err := models.ParseInboundCommand("add " + rest); err == nil && command.Action == models.InboundActionAddShoppingItem {
+				return Command{Action: ActionAddShoppingItem, Inbound: command}
+			}
+		}
+	}
+	return Command{Action: ActionHelp}
+}
+
+// HelpText lists the slash commands
+const HelpText = "Try `/chore done dishes`, `/chore list`, `/shopping add milk` or `/shopping list`. " +
+	"Link your Cribb account first with `/chore link CODE`, using a code from the Cribb app."
+
+// UnlinkedText tells members to link their chat account before sending commands
+const UnlinkedText = "Your chat account is not linked to Cribb yet. Get a code in the Cribb app and send `/chore link CODE`."
+
+// SummaryChore is a chore listed in the daily summary
+type SummaryChore struct {
+	Title    string
+	Assignee string
+	Overdue  bool
+}
+
+// DailySummary writes the summary of the day's chores posted to the house channel
+func DailySummary(day time.Time, chores []SummaryChore) string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Chores for %s", day.Format("Monday, January 2"))
+	if len(chores) == 0 {
+		summary.WriteString(": nothing due today. Enjoy the day off!")
+		return summary.String()
+	}
+	summary.WriteString(":")
+	for _, chore := range chores {
+		summary.WriteString("\n• " + chore.Title)
+		if chore.Assignee != "" {
+			summary.WriteString(" (" + chore.Assignee + ")")
+		}
+		if chore.Overdue {
+			summary.WriteString(", overdue")
+		}
+	}
+	return summary.String()
+}
+
+// Post sends a message to an incoming webhook of the platform
+func Post(ctx context.Context, platform models.ChatPlatform, webhookURL, text string) error {
+	var payload interface{}
+	switch platform {
+	case models.ChatPlatformSlack:
+		payload = map[string]string{"text": text}
+	case models.ChatPlatformDiscord:
+		if runes := []rune(text); len(runes) > maxDiscordMessage {
+			text = string(runes[:maxDiscordMessage-1]) + "…"
+		}
+		payload = map[string]string{"content": text}
+	default:
+		return fmt.Errorf("unknown chat platform %q", platform)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s webhook returned status %d", platform, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package chatbot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"cribb-backend/models"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		name, text string
+		want       Command
+	}{
+		{"/chore", "done Dishes", Command{Action: ActionCompleteChore, Inbound: models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: "Dishes"}}},
+		{"/chore", "", Command{Action: ActionListChores}},
+		{"/chores", "list", Command{Action: ActionListChores}},
+		{"/shopping", "add milk", Command{Action: ActionAddShoppingItem, Inbound: models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "milk", Quantity: 1}}},
+		{"/shopping", "add 2 oat milk", Command{Action: ActionAddShoppingItem, Inbound: models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "oat milk", Quantity: 2}}},
+		{"/shopping", "list", Command{Action: ActionListShopping}},
+		{"/shopping", "link 3f9a 1c2b7d", Command{Action: ActionLink, Code: "3f9a 1c2b7d"}},
+		{"/chore", "done", Command{Action: ActionHelp}},
+		{"/shopping", "add", Command{Action: ActionHelp}},
+		{"/chore", "dance", Command{Action: ActionHelp}},
+		{"/weather", "today", Command{Action: ActionHelp}},
+	}
+	for _, tt := range tests {
+		if got := ParseCommand(tt.name, tt.text); got != tt.want {
+			t.Errorf("ParseCommand(%q, %q) = %+v, want %+v", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordCommand(t *testing.T) {
+	var interaction DiscordInteraction
+	err := json.Unmarshal([]byte(`{
+		"type": 2,
+		"guild_id": "G1",
+		"member": {"user": {"id": "D1", "username": "alice"}},
+		"data": {"name": "shopping", "options": [{"name": "add", "type": 1, "options": [
+			{"name": "item", "type": 3, "value": "eggs"},
+			{"name": "quantity", "type": 10, "value": 12}
+		]}]}
+	}`), &interaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interaction.UserID() != "D1" {
+		t.Errorf("expected the member's user ID, got %q", interaction.UserID())
+	}
+	want := Command{Action: ActionAddShoppingItem, Inbound: models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "eggs", Quantity: 12}}
+	if got := interaction.Command(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	interaction.Data.Name = "chore"
+	interaction.Data.Options = []DiscordOption{{Name: "done", Type: 1, Options: []DiscordOption{{Name: "chore", Type: 3, Value: "trash"}}}}
+	if got := interaction.Command(); got.Action != ActionCompleteChore || got.Inbound.Chore != "trash" {
+		t.Errorf("unexpected command %+v", got)
+	}
+}
+
+func TestDailySummary(t *testing.T) {
+	day := time.Date(2025, 3, 4, 8, 0, 0, 0, time.UTC)
+	if got := DailySummary(day, nil); got != "Chores for Tuesday, March 4: nothing due today. Enjoy the day off!" {
+		t.Errorf("got %q", got)
+	}
+	got := DailySummary(day, []SummaryChore{
+		{Title: "Laundry", Assignee: "Bob", Overdue: true},
+		{Title: "Dishes", Assignee: "Alice"},
+		{Title: "Plants"},
+	})
+	want := "Chores for Tuesday, March 4:\n• Laundry (Bob), overdue\n• Dishes (Alice)\n• Plants"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte("command=%2Fchore&text=done+dishes&team_id=T1&user_id=U1")
+	sign := func(secret string, at time.Time) http.Header {
+		timestamp := strconv.FormatInt(at.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+		header := http.Header{}
+		header.Set(SlackTimestampHeader, timestamp)
+		header.Set(SlackSignatureHeader, "v0="+hex.EncodeToString(mac.Sum(nil)))
+		return header
+	}
+
+	if err := VerifySlackSignature("secret", sign("secret", now), body, now); err != nil {
+		t.Errorf("expected the signed command to pass, got %v", err)
+	}
+	if err := VerifySlackSignature("secret", sign("other", now), body, now); err != ErrInvalidSignature {
+		t.Errorf("expected another secret to be rejected, got %v", err)
+	}
+	if err := VerifySlackSignature("secret", sign("secret", now.Add(-10*time.Minute)), body, now); err != ErrInvalidSignature {
+		t.Errorf("expected an old command to be rejected, got %v", err)
+	}
+	if err := VerifySlackSignature("secret", sign("secret", now), append(body, '1'), now); err != ErrInvalidSignature {
+		t.Errorf("expected a changed body to be rejected, got %v", err)
+	}
+
+	form, _ := url.ParseQuery(string(body))
+	if command := ParseSlackCommand(form); command.Command != "/chore" || command.Text != "done dishes" || command.TeamID != "T1" || command.UserID != "U1" {
+		t.Errorf("unexpected command %+v", command)
+	}
+}
+
+func TestVerifyDiscordSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := []byte(`{"type":1}`)
+	header := http.Header{}
+	header.Set(DiscordTimestampHeader, "1700000000")
+	header.Set(DiscordSignatureHeader, hex.EncodeToString(ed25519.Sign(privateKey, append([]byte("1700000000"), body...))))
+
+	if err := VerifyDiscordSignature(publicKey, header, body); err != nil {
+		t.Errorf("expected the signed interaction to pass, got %v", err)
+	}
+	if err := VerifyDiscordSignature(publicKey, header, []byte(`{"type":2}`)); err != ErrInvalidSignature {
+		t.Errorf("expected a changed body to be rejected, got %v", err)
+	}
+	header.Set(DiscordTimestampHeader, "1700000001")
+	if err := VerifyDiscordSignature(publicKey, header, body); err != ErrInvalidSignature {
+		t.Errorf("expected a changed timestamp to be rejected, got %v", err)
+	}
+}
+
+func TestResponses(t *testing.T) {
+	if response := NewSlackResponse("hi", true); response.ResponseType != "in_channel" {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if response := NewSlackResponse("hi", false); response.ResponseType != "ephemeral" {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if response := NewDiscordResponse("hi", false); response.Type != DiscordResponseChannelMessage || response.Data.Flags != discordEphemeral {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestPost(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &got)
+		if strings.Contains(r.URL.Path, "fail") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := Post(context.Background(), models.ChatPlatformSlack, server.URL+"/slack", "hello"); err != nil || got["text"] != "hello" {
+		t.Errorf("expected Slack's text field, got %v %v", got, err)
+	}
+	long := strings.Repeat("a", 2100)
+	if err := Post(context.Background(), models.ChatPlatformDiscord, server.URL+"/discord", long); err != nil || len([]rune(got["content"])) != maxDiscordMessage {
+		t.Errorf("expected Discord's content field cut to its limit, got %d characters %v", len([]rune(got["content"])), err)
+	}
+	if err := Post(context.Background(), models.ChatPlatformSlack, server.URL+"/fail", "hello"); err == nil {
+		t.Error("expected a removed webhook to fail")
+	}
+}
@@ -0,0 +1,144 @@
+// chatbot/discord.go
+package chatbot
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DiscordSignatureHeader and DiscordTimestampHeader carry the signature of every interaction
+	DiscordSignatureHeader = "X-Signature-Ed25519"
+	DiscordTimestampHeader = "X-Signature-Timestamp"
+)
+
+// Interaction and response types Discord uses
+const (
+	DiscordInteractionPing               = 1
+	DiscordInteractionApplicationCommand = 2
+
+	DiscordResponsePong           = 1
+	DiscordResponseChannelMessage = 4
+
+	// discordEphemeral shows a message only to the member who sent the command
+	discordEphemeral = 1 << 6
+)
+
+// DiscordInteraction is a slash command, or a ping checking the endpoint, Discord posts as JSON
+type DiscordInteraction struct {
+	Type    int    `json:"type"`
+	GuildID string `json:"guild_id,omitempty"` // Server the command was sent from
+	Member  *struct {
+		User DiscordUser `json:"user"`
+	} `json:"member,omitempty"`
+	User *DiscordUser `json:"user,omitempty"` // Set instead of Member in direct messages
+	Data struct {
+		Name    string          `json:"name"`
+		Options []DiscordOption `json:"options,omitempty"`
+	} `json:"data"`
+}
+
+// DiscordUser is the Discord account that sent a command
+type DiscordUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+// DiscordOption is a subcommand with its options, or an option's value
+type DiscordOption struct {
+	Name    string          `json:"name"`
+	Type    int             `json:"type"`
+	Value   interface{}     `json:"value,omitempty"`
+	Options []DiscordOption `json:"options,omitempty"`
+}
+
+// UserID returns the ID of the Discord account that sent the command
+func (i DiscordInteraction) UserID() string {
+	if i.Member != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// Command reads the slash command as Slack sends it, a name and its text, so both are parsed
+// alike. The Discord app registers /chore with the done (chore), list and link (code) subcommands
+// and /shopping with the add (item, quantity), list and link (code) subcommands.
+func (i DiscordInteraction) Command() Command {
+	words := make([]string, 0)
+	for _, subcommand := range i.Data.Options {
+		words = append(words, subcommand.Name)
+		// A quantity is said before the item: "add 2 milk"
+		for _, option := range subcommand.Options {
+			if option.Name == "quantity" {
+				words = append(words, optionText(option.Value))
+			}
+		}
+		for _, option := range subcommand.Options {
+			if option.Name != "quantity" {
+				words = append(words, optionText(option.Value))
+			}
+		}
+	}
+	return ParseCommand(i.Data.Name, strings.Join(words, " "))
+}
+
+func optionText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// DiscordResponse answers an interaction
+type DiscordResponse struct {
+	Type int                  `json:"type"`
+	Data *DiscordResponseData `json:"data,omitempty"`
+}
+
+// DiscordResponseData is the message a command is answered with
+type DiscordResponseData struct {
+	Content string `json:"content"`
+	Flags   int    `json:"flags,omitempty"`
+}
+
+// NewDiscordResponse answers a command, in the channel or only to its sender
+func NewDiscordResponse(text string, inChannel bool) DiscordResponse {
+	data := &DiscordResponseData{Content: text}
+	if !inChannel {
+		data.Flags = discordEphemeral
+	}
+	return DiscordResponse{Type: DiscordResponseChannelMessage, Data: data}
+}
+
+// VerifyDiscord checks the signature Discord sends with an interaction using the app's public
+// key, see VerifyDiscordSignature
+func VerifyDiscord(header http.Header, body []byte) error {
+	if discordPublicKey == nil {
+		return ErrInvalidSignature
+	}
+	return VerifyDiscordSignature(discordPublicKey, header, body)
+}
+
+// VerifyDiscordSignature checks the hex Ed25519 signature of the timestamp header followed by
+// the body
+func VerifyDiscordSignature(publicKey ed25519.PublicKey, header http.Header, body []byte) error {
+	signature, err := hex.DecodeString(header.Get(DiscordSignatureHeader))
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+	message := append([]byte(header.Get(DiscordTimestampHeader)), body...)
+	if !ed25519.Verify(publicKey, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// chatbot/slack.go
+package chatbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// SlackSignatureHeader and SlackTimestampHeader carry the signature of every slash command
+	SlackSignatureHeader = "X-Slack-Signature"
+	SlackTimestampHeader = "X-Slack-Request-Timestamp"
+
+	// slackTimestampTolerance is how old, or how far ahead, a command's timestamp may be
+	slackTimestampTolerance = 5 * time.Minute
+)
+
+// SlackCommand is a slash command Slack posts as a form
+type SlackCommand struct {
+	Command  string // e.g. /chore
+	Text     string // What follows the command
+	TeamID   string // Workspace the command was sent from
+	UserID   string
+	UserName string
+}
+
+// ParseSlackCommand reads the slash command from the form Slack posts
+func ParseSlackCommand(form url.Values) SlackCommand {
+	return SlackCommand{
+		Command:  form.Get("command"),
+		Text:     form.Get("text"),
+		TeamID:   form.Get("team_id"),
+		UserID:   form.Get("user_id"),
+		UserName: form.Get("user_name"),
+	}
+}
+
+// SlackResponse answers a slash command. In-channel answers are shown to everyone in the channel,
+// ephemeral ones only to the member who sent the command.
+type SlackResponse struct {
+	ResponseType string `json:"response_type"` // in_channel or ephemeral
+	Text         string `json:"text"`
+}
+
+// NewSlackResponse answers a slash command, in the channel or only to its sender
+func NewSlackResponse(text string, inChannel bool) SlackResponse {
+	if inChannel {
+		return SlackResponse{ResponseType: "in_channel", Text: text}
+	}
+	return SlackResponse{ResponseType: "ephemeral", Text: text}
+}
+
+// VerifySlack checks the signature Slack sends with a slash command using the app's signing
+// secret, see VerifySlackSignature
+func VerifySlack(header http.Header, body []byte, now time.Time) error {
+	if slackSigningSecret == "" {
+		return ErrInvalidSignature
+	}
+	return VerifySlackSignature(slackSigningSecret, header, body, now)
+}
+
+// VerifySlackSignature checks that the body was signed with the secret moments ago: the signature
+// header is "v0=" and the hex HMAC-SHA256 of "v0:{timestamp}:{body}"
+func VerifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get(SlackTimestampHeader)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > slackTimestampTolerance || age < -slackTimestampTolerance {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get(SlackSignatureHeader))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
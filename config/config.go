@@ -19,6 +19,17 @@ import (
 var (
 	DB        *mongo.Database
 	JWTSecret []byte
+
+	// PantrySearchIndex names the Atlas Search index on pantry_items; pantry search falls back
+	// to matching in the application when it is empty (ATLAS_PANTRY_SEARCH_INDEX)
+	PantrySearchIndex string
+
+	// FrontendURL is where the web app is served, used for links in emails (FRONTEND_URL)
+	FrontendURL string
+
+	// PublicAPIURL is where this API is reached from outside, used for links to its public
+	// endpoints such as shared shopping lists (PUBLIC_API_URL)
+	PublicAPIURL string
 )
 
 func init() {
@@ -54,6 +65,15 @@ func ConnectDB() {
 
 	// Set JWT secret
 	JWTSecret = []byte(jwtSecret)
+	PantrySearchIndex = strings.TrimSpace(os.Getenv("ATLAS_PANTRY_SEARCH_INDEX"))
+	FrontendURL = strings.TrimRight(strings.TrimSpace(os.Getenv("FRONTEND_URL")), "/")
+	if FrontendURL == "" {
+		FrontendURL = "http://localhost:4200" // Same default as the CORS middleware
+	}
+	PublicAPIURL = strings.TrimRight(strings.TrimSpace(os.Getenv("PUBLIC_API_URL")), "/")
+	if PublicAPIURL == "" {
+		PublicAPIURL = "http://localhost:8080" // The port the server listens on
+	}
 
 	log.Printf("Attempting to connect to MongoDB...")
 
@@ -105,6 +125,11 @@ func initializeDatabase() error {
 		// Continue anyway, as this might be a fresh installation
 	}
 
+	// Groups created before admins were tracked get their earliest member as admin
+	if err := models.MigrateGroupAdmins(DB); err != nil {
+		log.Printf("Warning: Could not migrate group admins: %v", err)
+	}
+
 	// Create users collection with indexes
 	usersCollection := DB.Collection("users")
 	usersIndexes := []mongo.IndexModel{
@@ -225,30 +250,800 @@ func initializeDatabase() error {
 		return fmt.Errorf("failed to create chore completion indexes: %v", err)
 	}
 
-	shoppingCartCollection := DB.Collection("shopping_cart")
-	shoppingCartIndexes := []mongo.IndexModel{
+	// Create blackout_dates collection with indexes
+	blackoutsCollection := DB.Collection("blackout_dates")
+	blackoutsIndexes := []mongo.IndexModel{
 		{
-			Keys: bson.D{{Key: "group_id", Value: 1}},
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "end_date", Value: 1}},
+		},
+	}
+	_, err = blackoutsCollection.Indexes().CreateMany(ctx, blackoutsIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create blackout date indexes: %v", err)
+	}
+
+	// Expired scheduler leases are cleaned up automatically
+	_, err = DB.Collection("scheduler_leases").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scheduler lease indexes: %v", err)
+	}
+
+	// Create score_events collection with indexes
+	scoreEventsCollection := DB.Collection("score_events")
+	scoreEventsIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "type", Value: 1}},
+		},
+	}
+	_, err = scoreEventsCollection.Indexes().CreateMany(ctx, scoreEventsIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create score event indexes: %v", err)
+	}
+
+	// Create rewards and redemptions collections with indexes
+	_, err = DB.Collection("rewards").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "is_active", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reward indexes: %v", err)
+	}
+	_, err = DB.Collection("redemptions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
 		},
 		{
 			Keys: bson.D{{Key: "user_id", Value: 1}},
 		},
 		{
-			Keys: bson.D{{Key: "item_name", Value: 1}},
+			Keys: bson.D{{Key: "status", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create redemption indexes: %v", err)
+	}
+
+	// Create challenges collection with indexes
+	_, err = DB.Collection("challenges").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "status", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "end_date", Value: 1}},
 		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create challenge indexes: %v", err)
+	}
+
+	// Create leaderboard_snapshots collection with indexes
+	snapshotsCollection := DB.Collection("leaderboard_snapshots")
+	snapshotsIndexes := []mongo.IndexModel{
 		{
 			Keys: bson.D{
-				{Key: "user_id", Value: 1},
 				{Key: "group_id", Value: 1},
-				{Key: "item_name", Value: 1},
+				{Key: "period", Value: 1},
+				{Key: "period_start", Value: -1},
 			},
 			Options: options.Index().SetUnique(true),
 		},
 	}
+	_, err = snapshotsCollection.Indexes().CreateMany(ctx, snapshotsIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create leaderboard snapshot indexes: %v", err)
+	}
+
+	// Create user_badges collection with indexes
+	badgesCollection := DB.Collection("user_badges")
+	badgesIndexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "badge_key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}},
+		},
+	}
+	_, err = badgesCollection.Indexes().CreateMany(ctx, badgesIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create user badge indexes: %v", err)
+	}
+
+	// Create notifications collection with indexes
+	notificationsCollection := DB.Collection("notifications")
+	notificationsIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "read", Value: 1}},
+		},
+		{
+			// Counts a member's unread notifications for the bell icon
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "read", Value: 1}},
+		},
+		{
+			// Finds notifications that still need to be pushed to members' devices
+			Keys: bson.D{{Key: "pushed_at", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	}
+	_, err = notificationsCollection.Indexes().CreateMany(ctx, notificationsIndexes)
+	if err != nil {
+		return fmt.Errorf("failed to create notification indexes: %v", err)
+	}
+
+	shoppingCartCollection := DB.Collection("shopping_cart")
+	shoppingCartIndexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "item_name", Value: 1}},
+		},
+	}
+	// Items from before personal items existed were all bought for the group
+	_, err = shoppingCartCollection.UpdateMany(
+		ctx,
+		bson.M{"is_shared": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"is_shared": true}},
+	)
+	if err != nil {
+		log.Printf("Warning: Unable to set default is_shared on existing shopping cart items: %v", err)
+	}
+	// The unique index used to span all lists; drop it so the same item can go on several lists
+	_, _ = shoppingCartCollection.Indexes().DropOne(ctx, "user_id_1_group_id_1_item_name_1")
+	// Items used to be unique per member; they are now shared by everyone who asks for them
+	_, _ = shoppingCartCollection.Indexes().DropOne(ctx, "user_id_1_group_id_1_list_id_1_item_name_1")
 	_, err = shoppingCartCollection.Indexes().CreateMany(ctx, shoppingCartIndexes)
 	if err != nil {
 		return fmt.Errorf("failed to create shopping cart indexes: %v", err)
 	}
+	if err := mergeDuplicateShoppingItems(ctx); err != nil {
+		log.Printf("Warning: Unable to merge duplicate shopping cart items: %v", err)
+	}
+	// Each list has one shared and one personal entry per item, whichever members asked for it
+	_, _ = shoppingCartCollection.Indexes().DropOne(ctx, "group_id_1_list_id_1_normalized_name_1")
+	_, err = shoppingCartCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "group_id", Value: 1},
+			{Key: "list_id", Value: 1},
+			{Key: "normalized_name", Value: 1},
+			{Key: "is_shared", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		// Duplicates in units that cannot be merged block the index; adds still merge into the first match
+		log.Printf("Warning: Unable to create unique shopping cart item index: %v", err)
+	}
+
+	// Create shopping_lists collection with indexes
+	_, err = DB.Collection("shopping_lists").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shopping list indexes: %v", err)
+	}
+
+	// Create shopping_cart_archive collection with indexes; archived items are removed after 30 days
+	_, err = DB.Collection("shopping_cart_archive").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "archived_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "archived_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(models.ShoppingArchiveRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shopping cart archive indexes: %v", err)
+	}
+
+	// Create shopping_list_shares collection with indexes; expired links are removed automatically
+	_, err = DB.Collection("shopping_list_shares").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shopping list share indexes: %v", err)
+	}
+
+	// Create purchase_history collection with indexes
+	_, err = DB.Collection("purchase_history").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "purchased_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "purchased_by", Value: 1}},
+		},
+		{
+			// Purchases billed through an expense, recalculated together when one is corrected
+			Keys:    bson.D{{Key: "expense_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create purchase history indexes: %v", err)
+	}
+
+	// Create expenses collection with indexes
+	_, err = DB.Collection("expenses").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "paid_by", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "splits.user_id", Value: 1}},
+		},
+		{
+			// Expenses imported from another app are imported once
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "external_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create expense indexes: %v", err)
+	}
+
+	// Create settlements collection with indexes
+	_, err = DB.Collection("settlements").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			// Settlements are imported from and sent to other apps once
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "external_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create settlement indexes: %v", err)
+	}
+
+	// Create recurring bills collection with indexes
+	_, err = DB.Collection("recurring_bills").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "next_due_date", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "is_active", Value: 1}, {Key: "next_due_date", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create recurring bill indexes: %v", err)
+	}
+
+	// Create exchange rates collection (conversion rate cache) with indexes
+	_, err = DB.Collection("exchange_rates").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "base", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create exchange rate indexes: %v", err)
+	}
+
+	// Create payment reminders collection with indexes
+	_, err = DB.Collection("payment_reminders").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payment reminder indexes: %v", err)
+	}
+
+	// Create rent configuration collection with indexes
+	_, err = DB.Collection("rent_configs").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create rent configuration indexes: %v", err)
+	}
+
+	// Create meter readings collection with indexes
+	_, err = DB.Collection("meter_readings").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			// One reading per meter at a time; readings of the main meter have no user_id
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "meter", Value: 1}, {Key: "user_id", Value: 1}, {Key: "read_at", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create meter reading indexes: %v", err)
+	}
+
+	// Create device tokens collection with indexes
+	_, err = DB.Collection("device_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create device token indexes: %v", err)
+	}
+
+	// Shopping updates still to be pushed are found by these fields
+	_, err = DB.Collection("shopping_cart_activity").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "pushed_at", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create shopping activity indexes: %v", err)
+	}
+
+	// Password resets are found by the hash of their token; expired ones are removed automatically
+	_, err = DB.Collection("password_resets").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create password reset indexes: %v", err)
+	}
+
+	// Webhooks are found by group when an event happens
+	_, err = DB.Collection("webhooks").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "active", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook indexes: %v", err)
+	}
+
+	// Inbound hooks are found by the hash of the token in their URL and listed per group
+	_, err = DB.Collection("inbound_hooks").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create inbound hook indexes: %v", err)
+	}
+
+	// Voice assistant links are found by the hashes of their code and tokens and listed per member;
+	// links whose code was never exchanged are removed once it expires
+	_, err = DB.Collection("oauth_grants").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "access_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "refresh_token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true).SetSparse(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "client_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "code_expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create OAuth grant indexes: %v", err)
+	}
+
+	// A workspace is connected to one group and a group has one integration per platform; due
+	// summaries are found by time
+	_, err = DB.Collection("chat_integrations").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "platform", Value: 1}, {Key: "workspace_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "platform", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "next_summary_at", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat integration indexes: %v", err)
+	}
+
+	// Chat link codes are found by hash and removed once they expire
+	_, err = DB.Collection("chat_link_codes").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "code_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat link code indexes: %v", err)
+	}
+
+	// Webhook deliveries are claimed when due and listed per webhook; old ones are removed after 30 days
+	_, err = DB.Collection("webhook_deliveries").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(models.WebhookDeliveryRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery indexes: %v", err)
+	}
+
+	// Queued pushes are claimed when due and listed per group; old ones are removed after 14 days
+	_, err = DB.Collection("push_queue").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(int32(models.PushQueueRetention.Seconds())),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create push queue indexes: %v", err)
+	}
+
+	// House events are listed per group by start time and claimed when their reminder is due
+	_, err = DB.Collection("house_events").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "starts_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "remind_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create house event indexes: %v", err)
+	}
+
+	// Guest stays are listed per group by departure and claimed by arrival to announce them
+	_, err = DB.Collection("guest_stays").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "depart_on", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "arrive_on", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create guest stay indexes: %v", err)
+	}
+
+	// Resources are listed per group
+	_, err = DB.Collection("resources").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "group_id", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create resource indexes: %v", err)
+	}
+
+	// Each slot of a resource can be booked once; the multikey index over the booked slots is
+	// what keeps concurrent bookings from overlapping
+	_, err = DB.Collection("resource_bookings").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "resource_id", Value: 1}, {Key: "slots", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "resource_id", Value: 1}, {Key: "starts_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create resource booking indexes: %v", err)
+	}
+
+	// Quiet hours violations are listed and summarized per group by month
+	_, err = DB.Collection("quiet_hours_violations").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "occurred_at", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create quiet hours violation indexes: %v", err)
+	}
+
+	// Scheduling polls are listed per group and decided once voting closes
+	_, err = DB.Collection("scheduling_polls").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "closes_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scheduling poll indexes: %v", err)
+	}
+
+	// Decision polls are listed per group and closed by deadline
+	_, err = DB.Collection("decision_polls").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "deadline", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create decision poll indexes: %v", err)
+	}
+
+	// Pickup schedules are listed per group and claimed by their next reminder
+	_, err = DB.Collection("pickup_schedules").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "next_pickup", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "next_reminder_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pickup schedule indexes: %v", err)
+	}
+
+	// Create posts indexes for paging through a group's bulletin board, newest first
+	_, err = DB.Collection("posts").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "category", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create post indexes: %v", err)
+	}
+
+	// Reactions are unique per member and emoji on each completion or post
+	_, err = DB.Collection("reactions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "emoji", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reaction indexes: %v", err)
+	}
+
+	// Conversations are unique per pair of members in a group and listed by latest message
+	_, err = DB.Collection("conversations").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "participants.user_id", Value: 1}, {Key: "last_message_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create conversation indexes: %v", err)
+	}
+
+	// Direct messages are paged newest first within a conversation
+	_, err = DB.Collection("direct_messages").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "conversation_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create direct message indexes: %v", err)
+	}
+
+	// Chat messages are paged newest first within a group
+	_, err = DB.Collection("chat_messages").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "_id", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat message indexes: %v", err)
+	}
+
+	// Typing indicators are kept per member and expire shortly after they stop typing
+	_, err = DB.Collection("chat_typing").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat typing indexes: %v", err)
+	}
+
+	// House rules are versioned per group; a unique version makes concurrent edits conflict
+	_, err = DB.Collection("house_rules").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create house rules indexes: %v", err)
+	}
+
+	// Each member accepts each version of the house rules at most once
+	_, err = DB.Collection("house_rule_acknowledgments").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create house rules acknowledgment indexes: %v", err)
+	}
+
+	// Issues are listed per group, newest first, by status and category
+	_, err = DB.Collection("issues").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "category", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create issue indexes: %v", err)
+	}
+
+	// Kudos are listed per group and month; budgets are tracked once per group and month
+	_, err = DB.Collection("kudos").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "month", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create kudos indexes: %v", err)
+	}
+	_, err = DB.Collection("kudos_budgets").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "group_id", Value: 1}, {Key: "month", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create kudos budget indexes: %v", err)
+	}
+
+	// Group exports are claimed oldest first, listed per group and removed once expired
+	_, err = DB.Collection("group_exports").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "expires_at", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "file_id", Value: 1}},
+			Options: options.Index().SetSparse(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create group export indexes: %v", err)
+	}
+
+	// Create products collection (barcode lookup cache) with indexes
+	_, err = DB.Collection("products").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "barcode", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create product indexes: %v", err)
+	}
+
+	// Create pantry_items indexes for listing items by expiration date and by owner
+	_, err = DB.Collection("pantry_items").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "expiration_date", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "owner_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pantry item indexes: %v", err)
+	}
+
+	// Create pantry_usage indexes for usage reports by item and member
+	_, err = DB.Collection("pantry_usage").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "item_id", Value: 1}, {Key: "used_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "used_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pantry usage indexes: %v", err)
+	}
+
+	// Create pantry_item_versions indexes so each item's versions are numbered once
+	_, err = DB.Collection("pantry_item_versions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "item_id", Value: 1}, {Key: "version", Value: -1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pantry item version indexes: %v", err)
+	}
+
+	// Create pantry_waste indexes for monthly waste reports
+	_, err = DB.Collection("pantry_waste").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "group_id", Value: 1}, {Key: "discarded_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create pantry waste indexes: %v", err)
+	}
 
 	// Create pantry_categories collection with indexes
 	categoriesCollection := DB.Collection("pantry_categories")
@@ -284,6 +1079,85 @@ func initializeDatabase() error {
 	return nil
 }
 
+// mergeDuplicateShoppingItems backfills the normalized name and requesters of shopping cart items
+// added before duplicates were merged, then folds duplicates on each list into the earliest one
+// with the same sharing
+func mergeDuplicateShoppingItems(ctx context.Context) error {
+	collection := DB.Collection("shopping_cart")
+
+	cursor, err := collection.Find(ctx, bson.M{"$or": []bson.M{
+		{"normalized_name": bson.M{"$exists": false}},
+		{"requested_by": bson.M{"$exists": false}},
+	}})
+	if err != nil {
+		return err
+	}
+	var legacy []models.ShoppingCartItem
+	if err := cursor.All(ctx, &legacy); err != nil {
+		return err
+	}
+	for _, item := range legacy {
+		_, err := collection.UpdateOne(ctx, bson.M{"_id": item.ID}, bson.M{"$set": bson.M{
+			"normalized_name": models.NormalizeItemName(item.ItemName),
+			"requested_by":    item.Requesters(),
+		}})
+		if err != nil {
+			return err
+		}
+	}
+
+	duplicates, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"group_id": "$group_id", "list_id": "$list_id", "name": "$normalized_name", "shared": "$is_shared"},
+			"count": bson.M{"$sum": 1},
+			"ids":   bson.M{"$push": "$_id"},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	var sets []struct {
+		IDs []interface{} `bson:"ids"`
+	}
+	if err := duplicates.All(ctx, &sets); err != nil {
+		return err
+	}
+
+	for _, set := range sets {
+		cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": set.IDs}},
+			options.Find().SetSort(bson.D{{Key: "added_at", Value: 1}}))
+		if err != nil {
+			return err
+		}
+		var items []models.ShoppingCartItem
+		if err := cursor.All(ctx, &items); err != nil {
+			return err
+		}
+		if len(items) < 2 {
+			continue
+		}
+
+		keeper := items[0]
+		merged := models.MergeDuplicates(&keeper, items[1:])
+		if len(merged) == 0 {
+			continue
+		}
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": keeper.ID}, bson.M{"$set": bson.M{
+			"quantity":     keeper.Quantity,
+			"requested_by": keeper.RequestedBy,
+		}})
+		if err != nil {
+			return err
+		}
+		if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": merged}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // seedPredefinedCategories seeds the database with predefined pantry categories
 func seedPredefinedCategories() error {
 	if DB == nil {
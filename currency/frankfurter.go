@@ -0,0 +1,78 @@
+// currency/frankfurter.go
+package currency
+
+import (
+	"context"
+	"cribb-backend/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultFrankfurterURL is used unless EXCHANGE_RATES_BASE_URL is set
+const defaultFrankfurterURL = "https://api.frankfurter.app"
+
+// FrankfurterClient fetches the European Central Bank reference rates published by Frankfurter
+type FrankfurterClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// NewFrankfurterClient creates a client for the Frankfurter API
+func NewFrankfurterClient() *FrankfurterClient {
+	baseURL := os.Getenv("EXCHANGE_RATES_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultFrankfurterURL
+	}
+	return &FrankfurterClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// frankfurterResponse is the latest rates response
+type frankfurterResponse struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rates fetches the latest rates from the base currency
+func (c *FrankfurterClient) Rates(ctx context.Context, base string) (*models.ExchangeRates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/latest?from="+url.QueryEscape(base), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Frankfurter answers unknown currencies with not found or unprocessable entity
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return nil, ErrUnsupportedCurrency
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("frankfurter returned status %d", resp.StatusCode)
+	}
+
+	var body frankfurterResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if len(body.Rates) == 0 {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	return &models.ExchangeRates{
+		Base:      base,
+		Rates:     body.Rates,
+		Source:    "frankfurter",
+		FetchedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,94 @@
+// currency/rates.go
+package currency
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cacheTTL is how long cached exchange rates are trusted before they are fetched again
+const cacheTTL = 12 * time.Hour
+
+// ErrUnsupportedCurrency is returned when no rate is known between two currencies
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// Provider fetches the current exchange rates from a base currency
+type Provider interface {
+	Rates(ctx context.Context, base string) (*models.ExchangeRates, error)
+}
+
+// provider is the source of exchange rates used on a cache miss
+var provider Provider = NewFrankfurterClient()
+
+// SetProvider replaces the source of exchange rates
+func SetProvider(p Provider) {
+	provider = p
+}
+
+// Rate returns how many units of to one unit of from buys, from the exchange_rates collection
+// when cached recently and from the provider otherwise. Fresh rates are written back to the cache.
+func Rate(ctx context.Context, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	rates, err := ratesFrom(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	rate, ok := rates.Rates[to]
+	if !ok || rate <= 0 {
+		return 0, ErrUnsupportedCurrency
+	}
+	return rate, nil
+}
+
+// Convert converts an amount between currencies, rounded to the cent, and returns the rate used
+func Convert(ctx context.Context, amount float64, from, to string) (float64, float64, error) {
+	rate, err := Rate(ctx, from, to)
+	if err != nil {
+		return 0, 0, err
+	}
+	return models.RoundMoney(amount * rate), rate, nil
+}
+
+// ratesFrom returns the exchange rates from a base currency, cached or fetched
+func ratesFrom(ctx context.Context, base string) (*models.ExchangeRates, error) {
+	collection := config.DB.Collection("exchange_rates")
+
+	var cached models.ExchangeRates
+	err := collection.FindOne(ctx, bson.M{"base": base}).Decode(&cached)
+	if err == nil && time.Since(cached.FetchedAt) < cacheTTL {
+		return &cached, nil
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	rates, fetchErr := provider.Rates(ctx, base)
+	if fetchErr != nil {
+		// Use stale rates rather than none when the provider is unavailable
+		if err == nil && !errors.Is(fetchErr, ErrUnsupportedCurrency) {
+			return &cached, nil
+		}
+		return nil, fetchErr
+	}
+
+	_, err = collection.UpdateOne(
+		ctx,
+		bson.M{"base": base},
+		bson.M{"$set": rates},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
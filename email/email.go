@@ -0,0 +1,79 @@
+// email/email.go
+package email
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/mail"
+	"os"
+	"strings"
+)
+
+// ErrDisabled is returned when an email is sent while no provider is configured
+var ErrDisabled = errors.New("email is not configured")
+
+// Message is an email to one recipient. Text is the plain version shown by clients that do not
+// render HTML.
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// Sender delivers emails through a provider
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// sender delivers emails; nil turns email off
+var sender Sender
+
+// SetSender replaces how emails are delivered; nil turns email off
+func SetSender(s Sender) {
+	sender = s
+}
+
+// Enabled reports whether emails are delivered
+func Enabled() bool {
+	return sender != nil
+}
+
+// Send delivers the message, or returns ErrDisabled when email is off
+func Send(ctx context.Context, message Message) error {
+	if sender == nil {
+		return ErrDisabled
+	}
+	return sender.Send(ctx, message)
+}
+
+// Configure sets up delivery from the environment. EMAIL_FROM is the sender address. SendGrid is
+// used when SENDGRID_API_KEY is set, otherwise SMTP when SMTP_HOST is set, see NewSMTPSenderFromEnv.
+// Email stays off when neither is configured.
+func Configure() {
+	from := strings.TrimSpace(os.Getenv("EMAIL_FROM"))
+	apiKey := strings.TrimSpace(os.Getenv("SENDGRID_API_KEY"))
+	host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+	if apiKey == "" && host == "" {
+		log.Println("Email disabled: SENDGRID_API_KEY or SMTP_HOST is not set")
+		return
+	}
+	if _, err := mail.ParseAddress(from); err != nil {
+		log.Printf("Email disabled: EMAIL_FROM is not a valid address: %v", err)
+		return
+	}
+
+	if apiKey != "" {
+		SetSender(NewSendGridClient(apiKey, from))
+		log.Println("Email enabled through SendGrid")
+		return
+	}
+	smtpSender, err := NewSMTPSenderFromEnv(from)
+	if err != nil {
+		log.Printf("Email disabled: %v", err)
+		return
+	}
+	SetSender(smtpSender)
+	log.Println("Email enabled through SMTP")
+}
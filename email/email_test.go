@@ -0,0 +1,180 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWeeklyDigestEmail(t *testing.T) {
+	message, err := WeeklyDigestEmail("sam@example.com", WeeklyDigestData{
+		Name:                 "Sam",
+		GroupName:            "Flat <3>",
+		ChoresCompleted:      2,
+		GroupChoresCompleted: 7,
+		OverdueChores:        []DigestChore{{Title: "Bins", Due: "Mon Mar 3"}},
+		Balance:              "You owe 12.50 USD",
+		AppURL:               "https://cribb.example.com",
+	})
+	if err != nil {
+		t.Fatalf("expected the digest to render, got %v", err)
+	}
+
+	if message.To != "sam@example.com" || message.Subject != "Your week in Flat <3>" {
+		t.Errorf("unexpected recipient or subject %q %q", message.To, message.Subject)
+	}
+	if !strings.Contains(message.HTML, "Flat &lt;3&gt;") || strings.Contains(message.HTML, "Flat <3>") {
+		t.Error("expected the group name to be escaped in the HTML")
+	}
+	for _, want := range []string{"Bins, due Mon Mar 3", "You owe 12.50 USD", "no chores due in the coming week", "Turn off the weekly digest"} {
+		if !strings.Contains(message.HTML, want) {
+			t.Errorf("expected the HTML to contain %q", want)
+		}
+	}
+	if !strings.Contains(message.Text, "Overdue:\n- Bins, due Mon Mar 3") || strings.Contains(message.Text, "Coming up") {
+		t.Errorf("unexpected text version %q", message.Text)
+	}
+}
+
+func TestWeeklyDigestEmailSections(t *testing.T) {
+	message, err := WeeklyDigestEmail("sam@example.com", WeeklyDigestData{
+		Name:        "Sam",
+		GroupName:   "Flat",
+		HideChores:  true,
+		ItemsBought: []string{"Milk", "Eggs"},
+	})
+	if err != nil {
+		t.Fatalf("expected the digest to render, got %v", err)
+	}
+	for _, unwanted := range []string{"chores you did", "no chores due", "Balance"} {
+		if strings.Contains(message.HTML, unwanted) || strings.Contains(message.Text, unwanted) {
+			t.Errorf("expected %q to be left out", unwanted)
+		}
+	}
+	if !strings.Contains(message.HTML, "Milk, Eggs") || !strings.Contains(message.Text, "Bought this week: Milk, Eggs") {
+		t.Error("expected the items bought to be listed")
+	}
+}
+
+func TestDailyDigestEmail(t *testing.T) {
+	message, err := WeeklyDigestEmail("sam@example.com", WeeklyDigestData{
+		Name:          "Sam",
+		GroupName:     "Flat",
+		Period:        "day",
+		ExpiringItems: []DigestPantryItem{{Name: "Yoghurt", Expires: "Tue Mar 4"}},
+		Balance:       "You owe 5.00 USD",
+		BalanceChange: "Down 5.00 USD since your last digest",
+	})
+	if err != nil {
+		t.Fatalf("expected the digest to render, got %v", err)
+	}
+	if message.Subject != "Your day in Flat" {
+		t.Errorf("unexpected subject %q", message.Subject)
+	}
+	for _, want := range []string{"no chores due in the coming day", "Yoghurt, Tue Mar 4", "Down 5.00 USD since your last digest"} {
+		if !strings.Contains(message.HTML, want) {
+			t.Errorf("expected the HTML to contain %q", want)
+		}
+	}
+	if !strings.Contains(message.Text, "Expiring soon:\n- Yoghurt, Tue Mar 4") {
+		t.Errorf("unexpected text version %q", message.Text)
+	}
+}
+
+func TestWeeklyDigestSummary(t *testing.T) {
+	data := WeeklyDigestData{
+		OverdueChores:  []DigestChore{{Title: "Bins"}},
+		UpcomingChores: []DigestChore{{Title: "Dishes"}, {Title: "Hoover"}},
+		ExpiringItems:  []DigestPantryItem{{Name: "Milk"}},
+		Balance:        "You owe 12.50 USD",
+	}
+	if got, want := data.Summary(), "1 overdue chore, 2 chores coming up, 1 pantry item expiring. You owe 12.50 USD"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+	if got := (WeeklyDigestData{Period: "day"}).Summary(); got != "Nothing needs you right now" {
+		t.Errorf("unexpected empty summary %q", got)
+	}
+}
+
+func TestChoreEscalationEmail(t *testing.T) {
+	data := ChoreEscalationData{Name: "Alex", AssigneeName: "Sam", GroupName: "Flat", ChoreTitle: "Dishes", DueDate: "Mon Mar 3", DaysOverdue: 3}
+	message, err := ChoreEscalationEmail("alex@example.com", data)
+	if err != nil {
+		t.Fatalf("expected the email to render, got %v", err)
+	}
+	if !strings.Contains(message.HTML, "assigned to Sam") || !strings.Contains(message.HTML, "As an admin of Flat") {
+		t.Error("expected admins to be asked to step in")
+	}
+
+	data.ToAssignee = true
+	message, err = ChoreEscalationEmail("sam@example.com", data)
+	if err != nil {
+		t.Fatalf("expected the email to render, got %v", err)
+	}
+	if strings.Contains(message.HTML, "As an admin") || !strings.Contains(message.HTML, "Your housemates have been told too") {
+		t.Error("expected the assignee's copy")
+	}
+	if message.Subject != "Dishes is overdue" {
+		t.Errorf("unexpected subject %q", message.Subject)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	from := &mail.Address{Name: "Cribb", Address: "noreply@cribb.example.com"}
+	to := &mail.Address{Address: "sam@example.com"}
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	body, err := buildMIME(from, to, Message{Subject: "Café night", HTML: "<p>Hi</p>", Text: "Hi"}, now)
+	if err != nil {
+		t.Fatalf("expected a message, got %v", err)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("expected a parseable message, got %v", err)
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || decoded != "Café night" {
+		t.Errorf("expected the subject to round trip, got %q (%v)", decoded, err)
+	}
+	if !strings.HasPrefix(parsed.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("unexpected content type %q", parsed.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "text/plain") || !strings.Contains(string(body), "text/html") {
+		t.Error("expected both versions of the body")
+	}
+}
+
+func TestSendGridSend(t *testing.T) {
+	var request sendGridRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&request)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewSendGridClient("key", "Cribb <noreply@cribb.example.com>")
+	client.BaseURL = server.URL
+	if err := client.Send(context.Background(), Message{To: "sam@example.com", Subject: "Hi", HTML: "<p>Hi</p>", Text: "Hi"}); err != nil {
+		t.Fatalf("expected the email to be sent, got %v", err)
+	}
+
+	if authorization != "Bearer key" {
+		t.Errorf("unexpected authorization %q", authorization)
+	}
+	if request.From.Email != "noreply@cribb.example.com" || request.From.Name != "Cribb" {
+		t.Errorf("unexpected sender %+v", request.From)
+	}
+	if len(request.Personalizations) != 1 || request.Personalizations[0].To[0].Email != "sam@example.com" {
+		t.Errorf("unexpected recipients %+v", request.Personalizations)
+	}
+	if len(request.Content) != 2 || request.Content[0].Type != "text/plain" || request.Content[1].Type != "text/html" {
+		t.Errorf("expected the plain version before the HTML one, got %+v", request.Content)
+	}
+}
@@ -0,0 +1,107 @@
+// email/sendgrid.go
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultSendGridURL is used unless SENDGRID_BASE_URL is set
+const defaultSendGridURL = "https://api.sendgrid.com"
+
+// SendGridClient delivers emails through the SendGrid v3 mail send API
+type SendGridClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	APIKey     string
+	From       string
+}
+
+// NewSendGridClient creates a client that sends from the given address
+func NewSendGridClient(apiKey, from string) *SendGridClient {
+	baseURL := os.Getenv("SENDGRID_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultSendGridURL
+	}
+	return &SendGridClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		APIKey:     apiKey,
+		From:       from,
+	}
+}
+
+// sendGridAddress is an email address in a send request
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridPersonalization lists the recipients of a send request
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridContent is one version of the message body
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridRequest is the body of a mail send request
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Send delivers the message
+func (c *SendGridClient) Send(ctx context.Context, message Message) error {
+	from, err := mail.ParseAddress(c.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %v", err)
+	}
+	request := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: message.To}}}},
+		From:             sendGridAddress{Email: from.Address, Name: from.Name},
+		Subject:          message.Subject,
+	}
+	// SendGrid wants the plain version before the HTML one
+	if message.Text != "" {
+		request.Content = append(request.Content, sendGridContent{Type: "text/plain", Value: message.Text})
+	}
+	if message.HTML != "" {
+		request.Content = append(request.Content, sendGridContent{Type: "text/html", Value: message.HTML})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("sendgrid returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+}
@@ -0,0 +1,199 @@
+// email/templates.go
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// pages are the HTML emails, each rendered inside the shared layout
+var pages = map[string]*template.Template{}
+
+func init() {
+	for _, name := range []string{"password_reset", "weekly_digest", "chore_escalation"} {
+		pages[name] = template.Must(template.ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html"))
+	}
+}
+
+// render executes the named page with data
+func render(name string, data interface{}) (string, error) {
+	page, ok := pages[name]
+	if !ok {
+		return "", fmt.Errorf("unknown email template %q", name)
+	}
+	var html bytes.Buffer
+	if err := page.ExecuteTemplate(&html, "layout.html", data); err != nil {
+		return "", err
+	}
+	return html.String(), nil
+}
+
+// PasswordResetData fills the password reset email
+type PasswordResetData struct {
+	Name      string
+	ResetURL  string
+	ExpiresIn string // e.g. "1 hour"
+}
+
+// PasswordResetEmail builds the email with a link to choose a new password
+func PasswordResetEmail(to string, data PasswordResetData) (Message, error) {
+	html, err := render("password_reset", data)
+	if err != nil {
+		return Message{}, err
+	}
+	text := fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password of your Cribb account. If it was you, choose a new password at the link below. The link works for %s.\n\n%s\n\nIf you did not ask for this, you can ignore this email; your password stays the same.\n",
+		data.Name, data.ExpiresIn, data.ResetURL)
+	return Message{To: to, Subject: "Reset your Cribb password", HTML: html, Text: text}, nil
+}
+
+// DigestChore is a chore listed in the digest
+type DigestChore struct {
+	Title string
+	Due   string
+}
+
+// DigestPantryItem is a pantry item listed in the digest because it expires soon
+type DigestPantryItem struct {
+	Name    string
+	Expires string
+}
+
+// WeeklyDigestData fills the digest email, weekly or daily
+type WeeklyDigestData struct {
+	Name                 string
+	GroupName            string
+	Period               string // What the digest covers, "week" or "day"; empty means week
+	ChoresCompleted      int    // By the member in the past period
+	GroupChoresCompleted int    // By the whole group in the past period
+	WeeklyScore          int
+	OverdueChores        []DigestChore
+	UpcomingChores       []DigestChore // Due in the coming period
+	HideChores           bool          // Leaves out the chore counts and lists
+	ExpiringItems        []DigestPantryItem
+	ItemsBought          []string // Shopping list items the group bought in the past period
+	Balance              string   // e.g. "You owe 12.50 USD"; empty leaves the balance out
+	BalanceChange        string   // e.g. "Down 5.00 USD since your last digest"; empty when it did not move
+	AppURL               string
+}
+
+// Summary is a one-line version of the digest for a push notification
+func (d WeeklyDigestData) Summary() string {
+	parts := make([]string, 0, 4)
+	if !d.HideChores {
+		if count := len(d.OverdueChores); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d overdue %s", count, plural(count, "chore")))
+		}
+		if count := len(d.UpcomingChores); count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s coming up", count, plural(count, "chore")))
+		}
+	}
+	if count := len(d.ExpiringItems); count > 0 {
+		parts = append(parts, fmt.Sprintf("%d pantry %s expiring", count, plural(count, "item")))
+	}
+	summary := strings.Join(parts, ", ")
+	if d.Balance != "" {
+		if summary != "" {
+			summary += ". "
+		}
+		summary += d.Balance
+	}
+	if summary == "" {
+		return "Nothing needs you right now"
+	}
+	return summary
+}
+
+// period returns what the digest covers, defaulting to a week
+func (d WeeklyDigestData) period() string {
+	if d.Period == "" {
+		return "week"
+	}
+	return d.Period
+}
+
+// plural adds an s to the noun unless there is one
+func plural(count int, noun string) string {
+	if count == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// WeeklyDigestEmail builds the summary of a member's chores, expiring pantry items, the group's
+// shopping and the member's balance over the past week, or day for a daily digest
+func WeeklyDigestEmail(to string, data WeeklyDigestData) (Message, error) {
+	data.Period = data.period()
+	html, err := render("weekly_digest", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hi %s, here is your %s in %s.\n", data.Name, data.Period, data.GroupName)
+	if !data.HideChores {
+		fmt.Fprintf(&text, "\nChores you did: %d\nChores the group did: %d\nPoints this week: %d\n", data.ChoresCompleted, data.GroupChoresCompleted, data.WeeklyScore)
+		for _, section := range []struct {
+			heading string
+			chores  []DigestChore
+		}{{"Overdue", data.OverdueChores}, {"Coming up", data.UpcomingChores}} {
+			if len(section.chores) == 0 {
+				continue
+			}
+			fmt.Fprintf(&text, "\n%s:\n", section.heading)
+			for _, chore := range section.chores {
+				fmt.Fprintf(&text, "- %s, due %s\n", chore.Title, chore.Due)
+			}
+		}
+	}
+	if len(data.ExpiringItems) > 0 {
+		fmt.Fprint(&text, "\nExpiring soon:\n")
+		for _, item := range data.ExpiringItems {
+			fmt.Fprintf(&text, "- %s, %s\n", item.Name, item.Expires)
+		}
+	}
+	if len(data.ItemsBought) > 0 {
+		fmt.Fprintf(&text, "\nBought this %s: %s\n", data.Period, strings.Join(data.ItemsBought, ", "))
+	}
+	if data.Balance != "" {
+		fmt.Fprintf(&text, "\nBalance: %s\n", data.Balance)
+		if data.BalanceChange != "" {
+			fmt.Fprintf(&text, "%s\n", data.BalanceChange)
+		}
+	}
+	fmt.Fprintf(&text, "\n%s\n", data.AppURL)
+	return Message{To: to, Subject: "Your " + data.Period + " in " + data.GroupName, HTML: html, Text: text.String()}, nil
+}
+
+// ChoreEscalationData fills the email about a chore that stayed overdue
+type ChoreEscalationData struct {
+	Name         string // The recipient
+	AssigneeName string
+	GroupName    string
+	ChoreTitle   string
+	DueDate      string
+	DaysOverdue  int
+	ToAssignee   bool // The assignee is told their housemates know; admins are asked to step in
+	AppURL       string
+}
+
+// ChoreEscalationEmail builds the email about a chore that stayed overdue, for its assignee or a
+// group admin
+func ChoreEscalationEmail(to string, data ChoreEscalationData) (Message, error) {
+	html, err := render("chore_escalation", data)
+	if err != nil {
+		return Message{}, err
+	}
+	text := fmt.Sprintf("Hi %s,\n\n%s, assigned to %s, was due %s and is still not done, %d days on.\n\n%s\n",
+		data.Name, data.ChoreTitle, data.AssigneeName, data.DueDate, data.DaysOverdue, data.AppURL)
+	if data.ToAssignee {
+		text = fmt.Sprintf("Hi %s,\n\n%s was due %s and is still not done, %d days on. Your housemates have been told too.\n\n%s\n",
+			data.Name, data.ChoreTitle, data.DueDate, data.DaysOverdue, data.AppURL)
+	}
+	return Message{To: to, Subject: data.ChoreTitle + " is overdue", HTML: html, Text: text}, nil
+}
@@ -0,0 +1,119 @@
+// graph/resolver.go
+//
+// Package graph serves the GraphQL API described in schema.graphqls. The
+// executable schema in generated.go and the resolver signatures in
+// schema.resolvers.go are generated by gqlgen from the configuration in
+// gqlgen.yml at the repository root.
+package graph
+
+//go:generate go run github.com/99designs/gqlgen generate
+
+import (
+	"context"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"errors"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Resolver is the root resolver. Every query starts from the authenticated member, so like the
+// REST handlers it only reaches documents of the caller's group.
+type Resolver struct {
+	Store Store
+}
+
+// NewHandler serves GraphQL queries over GET and POST. It expects AuthMiddleware in front of it,
+// which puts the caller's claims in the request context.
+func NewHandler(store Store) http.Handler {
+	server := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{Store: store}}))
+	server.AddTransport(transport.GET{})
+	server.AddTransport(transport.POST{})
+	server.Use(extension.Introspection{})
+	return server
+}
+
+// requestUser loads the authenticated member, like getRequestUser does for the REST handlers
+func (r *Resolver) requestUser(ctx context.Context) (*models.User, error) {
+	userClaims, ok := middleware.GetUserFromContext(ctx)
+	if !ok {
+		return nil, errors.New("user not authenticated")
+	}
+	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
+	if err != nil {
+		return nil, errors.New("invalid user ID")
+	}
+
+	user, err := r.Store.FindUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("user not found")
+		}
+		return nil, errors.New("failed to fetch user")
+	}
+	return user, nil
+}
+
+// requestGroup loads the authenticated member and their group
+func (r *Resolver) requestGroup(ctx context.Context) (*models.User, *models.Group, error) {
+	user, err := r.requestUser(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user.GroupID.IsZero() {
+		return nil, nil, errors.New("user is not a member of any group")
+	}
+
+	group, err := r.Store.FindGroup(ctx, user.GroupID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil, errors.New("group not found")
+		}
+		return nil, nil, errors.New("failed to fetch group")
+	}
+	return user, group, nil
+}
+
+// member resolves a reference to a member. It returns nil for an empty reference and, unless
+// required, for a member who no longer exists.
+func (r *Resolver) member(ctx context.Context, id primitive.ObjectID, required bool) (*models.User, error) {
+	if id.IsZero() && !required {
+		return nil, nil
+	}
+	user, err := r.Store.FindUser(ctx, id)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.New("failed to fetch user")
+		}
+		if required {
+			return nil, errors.New("user not found")
+		}
+		return nil, nil
+	}
+	return user, nil
+}
+
+// members resolves references to members in the order given, leaving out those who no longer exist
+func (r *Resolver) members(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	found, err := r.Store.FindUsers(ctx, ids)
+	if err != nil {
+		return nil, errors.New("failed to fetch users")
+	}
+	byID := make(map[primitive.ObjectID]*models.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+
+	users := make([]*models.User, 0, len(ids))
+	for _, id := range ids {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
@@ -0,0 +1,391 @@
+// graph/resolver_test.go
+package graph_test
+
+import (
+	"context"
+	"cribb-backend/graph"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/client"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// memoryStore is an in-memory graph.Store
+type memoryStore struct {
+	users         []*models.User
+	groups        []*models.Group
+	chores        []*models.Chore
+	pantryItems   []*models.PantryItem
+	shoppingLists []*models.ShoppingList
+	cartItems     []*models.ShoppingCartItem
+}
+
+func (s *memoryStore) FindUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	for _, user := range s.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *memoryStore) FindUsers(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	users := []*models.User{}
+	for _, id := range ids {
+		if user, err := s.FindUser(ctx, id); err == nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *memoryStore) FindGroup(ctx context.Context, id primitive.ObjectID) (*models.Group, error) {
+	for _, group := range s.groups {
+		if group.ID == id {
+			return group, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (s *memoryStore) FindChores(ctx context.Context, filter graph.ChoreFilter) ([]*models.Chore, error) {
+	chores := []*models.Chore{}
+	for _, chore := range s.chores {
+		if chore.GroupID != filter.GroupID {
+			continue
+		}
+		if !filter.AssignedTo.IsZero() && chore.AssignedTo != filter.AssignedTo {
+			continue
+		}
+		if filter.Status != nil && chore.Status != *filter.Status {
+			continue
+		}
+		chores = append(chores, chore)
+	}
+	sort.Slice(chores, func(i, j int) bool { return chores[i].DueDate.Before(chores[j].DueDate) })
+	return chores, nil
+}
+
+func (s *memoryStore) FindPantryItems(ctx context.Context, groupID primitive.ObjectID) ([]*models.PantryItem, error) {
+	items := []*models.PantryItem{}
+	for _, item := range s.pantryItems {
+		if item.GroupID == groupID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (s *memoryStore) FindShoppingLists(ctx context.Context, groupID primitive.ObjectID) ([]*models.ShoppingList, error) {
+	lists := []*models.ShoppingList{}
+	for _, list := range s.shoppingLists {
+		if list.GroupID == groupID {
+			lists = append(lists, list)
+		}
+	}
+	return lists, nil
+}
+
+func (s *memoryStore) FindShoppingCartItems(ctx context.Context, groupID, listID primitive.ObjectID) ([]*models.ShoppingCartItem, error) {
+	items := []*models.ShoppingCartItem{}
+	for _, item := range s.cartItems {
+		if item.GroupID == groupID && item.ListID == listID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// household is a group of two members, Alice the admin and Bob
+type household struct {
+	store *memoryStore
+	group *models.Group
+	alice *models.User
+	bob   *models.User
+}
+
+func newHousehold() household {
+	groupID := primitive.NewObjectID()
+	alice := &models.User{ID: primitive.NewObjectID(), Username: "alice", Name: "Alice", RoomNumber: "101", Score: 30, GroupID: groupID}
+	bob := &models.User{ID: primitive.NewObjectID(), Username: "bob", Name: "Bob", RoomNumber: "102", Score: 20, GroupID: groupID}
+	group := &models.Group{
+		ID:        groupID,
+		Name:      "Test Apartment",
+		GroupCode: "ABCDEF",
+		Members:   []primitive.ObjectID{alice.ID, bob.ID},
+		Admins:    []primitive.ObjectID{alice.ID},
+	}
+	return household{
+		store: &memoryStore{users: []*models.User{alice, bob}, groups: []*models.Group{group}},
+		group: group,
+		alice: alice,
+		bob:   bob,
+	}
+}
+
+// asUser runs a query with the claims AuthMiddleware puts in the request context
+func asUser(user *models.User) client.Option {
+	return func(bd *client.Request) {
+		claims := middleware.UserClaims{ID: user.ID.Hex(), Username: user.Username}
+		bd.HTTP = bd.HTTP.WithContext(context.WithValue(bd.HTTP.Context(), middleware.UserContextKey, claims))
+	}
+}
+
+func TestQueryRequiresAuthentication(t *testing.T) {
+	h := newHousehold()
+	c := client.New(graph.NewHandler(h.store))
+
+	var resp struct{ Me struct{ ID string } }
+	err := c.Post(`{ me { id } }`, &resp)
+	if err == nil || !strings.Contains(err.Error(), "user not authenticated") {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+}
+
+func TestQueryMe(t *testing.T) {
+	h := newHousehold()
+	c := client.New(graph.NewHandler(h.store))
+
+	var resp struct {
+		Me struct {
+			ID       string
+			Username string
+			Score    int
+		}
+	}
+	if err := c.Post(`{ me { id username score } }`, &resp, asUser(h.alice)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if resp.Me.ID != h.alice.ID.Hex() || resp.Me.Username != "alice" || resp.Me.Score != 30 {
+		t.Errorf("Unexpected me: %+v", resp.Me)
+	}
+}
+
+func TestQueryGroupMembersAndChores(t *testing.T) {
+	h := newHousehold()
+	now := time.Now()
+	h.store.chores = []*models.Chore{
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Title: "Dishes", Type: models.ChoreTypeIndividual, Status: models.ChoreStatusPending, AssignedTo: h.bob.ID, DueDate: now.Add(48 * time.Hour)},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Title: "Trash", Type: models.ChoreTypeRecurring, Status: models.ChoreStatusPending, AssignedTo: h.alice.ID, DueDate: now.Add(24 * time.Hour)},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Title: "Vacuum", Type: models.ChoreTypeIndividual, Status: models.ChoreStatusCompleted, AssignedTo: h.alice.ID},
+		// Another group's chore is never returned
+		{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID(), Title: "Elsewhere", Status: models.ChoreStatusPending},
+	}
+	c := client.New(graph.NewHandler(h.store))
+
+	var resp struct {
+		Group struct {
+			Name    string
+			Members []struct{ Username string }
+			Admins  []struct{ Username string }
+			Chores  []struct {
+				Title      string
+				AssignedTo *struct{ Username string }
+				DueDate    *string
+			}
+		}
+		Me struct {
+			Chores []struct{ Title string }
+		}
+	}
+	query := `{
+		group {
+			name
+			members { username }
+			admins { username }
+			chores(status: pending) { title assignedTo { username } dueDate }
+		}
+		me { chores { title } }
+	}`
+	if err := c.Post(query, &resp, asUser(h.alice)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if resp.Group.Name != "Test Apartment" {
+		t.Errorf("Expected group name %q, got %q", "Test Apartment", resp.Group.Name)
+	}
+	if len(resp.Group.Members) != 2 || resp.Group.Members[0].Username != "alice" || resp.Group.Members[1].Username != "bob" {
+		t.Errorf("Expected members alice and bob in order, got %+v", resp.Group.Members)
+	}
+	if len(resp.Group.Admins) != 1 || resp.Group.Admins[0].Username != "alice" {
+		t.Errorf("Expected alice as the only admin, got %+v", resp.Group.Admins)
+	}
+
+	if len(resp.Group.Chores) != 2 {
+		t.Fatalf("Expected 2 pending chores, got %+v", resp.Group.Chores)
+	}
+	if resp.Group.Chores[0].Title != "Trash" || resp.Group.Chores[1].Title != "Dishes" {
+		t.Errorf("Expected chores soonest due first, got %+v", resp.Group.Chores)
+	}
+	if resp.Group.Chores[1].AssignedTo == nil || resp.Group.Chores[1].AssignedTo.Username != "bob" {
+		t.Errorf("Expected Dishes assigned to bob, got %+v", resp.Group.Chores[1].AssignedTo)
+	}
+	if resp.Group.Chores[0].DueDate == nil {
+		t.Error("Expected a due date")
+	}
+
+	if len(resp.Me.Chores) != 2 {
+		t.Errorf("Expected alice's 2 chores, got %+v", resp.Me.Chores)
+	}
+	for _, chore := range resp.Me.Chores {
+		if chore.Title == "Dishes" {
+			t.Error("Expected only chores assigned to alice")
+		}
+	}
+}
+
+func TestQueryGroupWithoutAdmins(t *testing.T) {
+	h := newHousehold()
+	h.group.Admins = nil
+	c := client.New(graph.NewHandler(h.store))
+
+	var resp struct {
+		Group struct {
+			Admins []struct{ Username string }
+		}
+	}
+	if err := c.Post(`{ group { admins { username } } }`, &resp, asUser(h.bob)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(resp.Group.Admins) != 0 {
+		t.Errorf("Expected no admins for a group without admins, got %+v", resp.Group.Admins)
+	}
+}
+
+func TestQueryGroupWithoutGroup(t *testing.T) {
+	h := newHousehold()
+	loner := &models.User{ID: primitive.NewObjectID(), Username: "loner"}
+	h.store.users = append(h.store.users, loner)
+	c := client.New(graph.NewHandler(h.store))
+
+	var resp struct{ Group struct{ Name string } }
+	err := c.Post(`{ group { name } }`, &resp, asUser(loner))
+	if err == nil || !strings.Contains(err.Error(), "user is not a member of any group") {
+		t.Errorf("Expected a missing group error, got %v", err)
+	}
+}
+
+func TestQueryPantry(t *testing.T) {
+	h := newHousehold()
+	now := time.Now()
+	aliceID, bobID := h.alice.ID, h.bob.ID
+	h.store.pantryItems = []*models.PantryItem{
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Name: "Milk", Quantity: 1, Unit: "l", AddedBy: aliceID, ExpirationDate: now.Add(24 * time.Hour)},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Name: "Rice", Quantity: 2, Unit: "kg", AddedBy: aliceID},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Name: "Alice's yogurt", Quantity: 1, Unit: "cup", AddedBy: aliceID, Visibility: models.VisibilityPersonal, OwnerID: &aliceID},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, Name: "Bob's cheese", Quantity: 1, Unit: "block", AddedBy: bobID, Visibility: models.VisibilityPersonal, OwnerID: &bobID},
+	}
+	c := client.New(graph.NewHandler(h.store))
+
+	type pantryItem struct {
+		Name           string
+		Visibility     string
+		Owner          *struct{ Username string }
+		ExpirationDate *string
+		AddedBy        struct{ Username string }
+	}
+	var resp struct {
+		Group struct{ Pantry []pantryItem }
+	}
+	query := `{ group { pantry { name visibility owner { username } expirationDate addedBy { username } } } }`
+	if err := c.Post(query, &resp, asUser(h.alice)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	items := make(map[string]pantryItem)
+	for _, item := range resp.Group.Pantry {
+		items[item.Name] = item
+	}
+	if len(items) != 3 {
+		t.Errorf("Expected 3 visible items, got %+v", resp.Group.Pantry)
+	}
+	if _, ok := items["Bob's cheese"]; ok {
+		t.Error("Expected bob's personal item to be hidden from alice")
+	}
+	if yogurt := items["Alice's yogurt"]; yogurt.Visibility != "personal" || yogurt.Owner == nil || yogurt.Owner.Username != "alice" {
+		t.Errorf("Expected alice's yogurt to be personal to her, got %+v", yogurt)
+	}
+	if rice := items["Rice"]; rice.Visibility != "shared" || rice.Owner != nil || rice.ExpirationDate != nil {
+		t.Errorf("Expected rice to be shared without an expiration date, got %+v", rice)
+	}
+	if milk := items["Milk"]; milk.ExpirationDate == nil || milk.AddedBy.Username != "alice" {
+		t.Errorf("Expected milk with an expiration date added by alice, got %+v", milk)
+	}
+
+	resp.Group.Pantry = nil
+	if err := c.Post(`{ group { pantry(expiringWithinDays: 3) { name } } }`, &resp, asUser(h.alice)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(resp.Group.Pantry) != 1 || resp.Group.Pantry[0].Name != "Milk" {
+		t.Errorf("Expected only milk to expire soon, got %+v", resp.Group.Pantry)
+	}
+
+	err := c.Post(`{ group { pantry(expiringWithinDays: -1) { name } } }`, &resp, asUser(h.alice))
+	if err == nil || !strings.Contains(err.Error(), "expiringWithinDays cannot be negative") {
+		t.Errorf("Expected a negative days error, got %v", err)
+	}
+}
+
+func TestQueryShopping(t *testing.T) {
+	h := newHousehold()
+	list := &models.ShoppingList{ID: primitive.NewObjectID(), GroupID: h.group.ID, Name: "Party", CreatedBy: h.bob.ID}
+	h.store.shoppingLists = []*models.ShoppingList{list}
+	h.store.cartItems = []*models.ShoppingCartItem{
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, UserID: h.alice.ID, ItemName: "Eggs", Quantity: 12, IsShared: true, RequestedBy: []primitive.ObjectID{h.alice.ID, h.bob.ID}},
+		{ID: primitive.NewObjectID(), GroupID: h.group.ID, UserID: h.bob.ID, ListID: list.ID, ItemName: "Chips", Quantity: 3},
+	}
+	c := client.New(graph.NewHandler(h.store))
+
+	type cartItem struct {
+		ItemName    string
+		AddedBy     struct{ Username string }
+		RequestedBy []struct{ Username string }
+	}
+	var resp struct {
+		Group struct {
+			ShoppingCart  []cartItem
+			ShoppingLists []struct {
+				Name      string
+				CreatedBy struct{ Username string }
+				Items     []cartItem
+			}
+		}
+	}
+	query := `{
+		group {
+			shoppingCart { itemName addedBy { username } requestedBy { username } }
+			shoppingLists { name createdBy { username } items { itemName addedBy { username } requestedBy { username } } }
+		}
+	}`
+	if err := c.Post(query, &resp, asUser(h.alice)); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+
+	if len(resp.Group.ShoppingCart) != 1 || resp.Group.ShoppingCart[0].ItemName != "Eggs" {
+		t.Fatalf("Expected only eggs on the default list, got %+v", resp.Group.ShoppingCart)
+	}
+	if len(resp.Group.ShoppingCart[0].RequestedBy) != 2 {
+		t.Errorf("Expected eggs requested by both members, got %+v", resp.Group.ShoppingCart[0].RequestedBy)
+	}
+
+	if len(resp.Group.ShoppingLists) != 1 {
+		t.Fatalf("Expected 1 shopping list, got %+v", resp.Group.ShoppingLists)
+	}
+	party := resp.Group.ShoppingLists[0]
+	if party.Name != "Party" || party.CreatedBy.Username != "bob" {
+		t.Errorf("Expected the party list created by bob, got %+v", party)
+	}
+	if len(party.Items) != 1 || party.Items[0].ItemName != "Chips" {
+		t.Fatalf("Expected chips on the party list, got %+v", party.Items)
+	}
+	// Items from before requesters were tracked are requested by whoever added them
+	if len(party.Items[0].RequestedBy) != 1 || party.Items[0].RequestedBy[0].Username != "bob" {
+		t.Errorf("Expected chips requested by bob, got %+v", party.Items[0].RequestedBy)
+	}
+}
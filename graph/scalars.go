@@ -0,0 +1,23 @@
+// graph/scalars.go
+package graph
+
+import (
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MarshalObjectID writes document IDs as the hex strings the REST API uses
+func MarshalObjectID(id primitive.ObjectID) graphql.Marshaler {
+	return graphql.MarshalString(id.Hex())
+}
+
+// UnmarshalObjectID reads an ID argument given as a hex string
+func UnmarshalObjectID(v interface{}) (primitive.ObjectID, error) {
+	hex, ok := v.(string)
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("ID must be a string, got %T", v)
+	}
+	return primitive.ObjectIDFromHex(hex)
+}
@@ -0,0 +1,195 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.55
+
+import (
+	"context"
+	"cribb-backend/models"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AssignedTo is the resolver for the assignedTo field.
+func (r *choreResolver) AssignedTo(ctx context.Context, obj *models.Chore) (*models.User, error) {
+	return r.member(ctx, obj.AssignedTo, false)
+}
+
+// DueDate is the resolver for the dueDate field.
+func (r *choreResolver) DueDate(ctx context.Context, obj *models.Chore) (*time.Time, error) {
+	if obj.DueDate.IsZero() {
+		return nil, nil
+	}
+	return &obj.DueDate, nil
+}
+
+// Members is the resolver for the members field.
+func (r *groupResolver) Members(ctx context.Context, obj *models.Group) ([]*models.User, error) {
+	return r.members(ctx, obj.Members)
+}
+
+// Admins is the resolver for the admins field.
+func (r *groupResolver) Admins(ctx context.Context, obj *models.Group) ([]*models.User, error) {
+	return r.members(ctx, obj.Admins)
+}
+
+// Chores is the resolver for the chores field.
+func (r *groupResolver) Chores(ctx context.Context, obj *models.Group, status *models.ChoreStatus) ([]*models.Chore, error) {
+	chores, err := r.Store.FindChores(ctx, ChoreFilter{GroupID: obj.ID, Status: status})
+	if err != nil {
+		return nil, errors.New("failed to fetch chores")
+	}
+	return chores, nil
+}
+
+// Pantry is the resolver for the pantry field.
+func (r *groupResolver) Pantry(ctx context.Context, obj *models.Group, expiringWithinDays *int) ([]*models.PantryItem, error) {
+	if expiringWithinDays != nil && *expiringWithinDays < 0 {
+		return nil, errors.New("expiringWithinDays cannot be negative")
+	}
+	user, err := r.requestUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.Store.FindPantryItems(ctx, obj.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch pantry items")
+	}
+	visible := make([]*models.PantryItem, 0, len(items))
+	for _, item := range items {
+		// Other members' personal food stays private
+		if item.IsPersonal() && !item.IsOwnedBy(user.ID) {
+			continue
+		}
+		if expiringWithinDays != nil && !item.IsExpiringSoon(*expiringWithinDays) {
+			continue
+		}
+		visible = append(visible, item)
+	}
+	return visible, nil
+}
+
+// ShoppingLists is the resolver for the shoppingLists field.
+func (r *groupResolver) ShoppingLists(ctx context.Context, obj *models.Group) ([]*models.ShoppingList, error) {
+	lists, err := r.Store.FindShoppingLists(ctx, obj.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch shopping lists")
+	}
+	return lists, nil
+}
+
+// ShoppingCart is the resolver for the shoppingCart field.
+func (r *groupResolver) ShoppingCart(ctx context.Context, obj *models.Group) ([]*models.ShoppingCartItem, error) {
+	items, err := r.Store.FindShoppingCartItems(ctx, obj.ID, primitive.NilObjectID)
+	if err != nil {
+		return nil, errors.New("failed to fetch shopping cart items")
+	}
+	return items, nil
+}
+
+// Visibility is the resolver for the visibility field.
+func (r *pantryItemResolver) Visibility(ctx context.Context, obj *models.PantryItem) (string, error) {
+	if obj.IsPersonal() {
+		return string(models.VisibilityPersonal), nil
+	}
+	// Items from before visibility was tracked are shared
+	return string(models.VisibilityShared), nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *pantryItemResolver) Owner(ctx context.Context, obj *models.PantryItem) (*models.User, error) {
+	if !obj.IsPersonal() {
+		return nil, nil
+	}
+	return r.member(ctx, *obj.OwnerID, false)
+}
+
+// ExpirationDate is the resolver for the expirationDate field.
+func (r *pantryItemResolver) ExpirationDate(ctx context.Context, obj *models.PantryItem) (*time.Time, error) {
+	if obj.ExpirationDate.IsZero() {
+		return nil, nil
+	}
+	return &obj.ExpirationDate, nil
+}
+
+// AddedBy is the resolver for the addedBy field.
+func (r *pantryItemResolver) AddedBy(ctx context.Context, obj *models.PantryItem) (*models.User, error) {
+	return r.member(ctx, obj.AddedBy, true)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	return r.requestUser(ctx)
+}
+
+// Group is the resolver for the group field.
+func (r *queryResolver) Group(ctx context.Context) (*models.Group, error) {
+	_, group, err := r.requestGroup(ctx)
+	return group, err
+}
+
+// AddedBy is the resolver for the addedBy field.
+func (r *shoppingCartItemResolver) AddedBy(ctx context.Context, obj *models.ShoppingCartItem) (*models.User, error) {
+	return r.member(ctx, obj.UserID, true)
+}
+
+// RequestedBy is the resolver for the requestedBy field.
+func (r *shoppingCartItemResolver) RequestedBy(ctx context.Context, obj *models.ShoppingCartItem) ([]*models.User, error) {
+	return r.members(ctx, obj.Requesters())
+}
+
+// CreatedBy is the resolver for the createdBy field.
+func (r *shoppingListResolver) CreatedBy(ctx context.Context, obj *models.ShoppingList) (*models.User, error) {
+	return r.member(ctx, obj.CreatedBy, true)
+}
+
+// Items is the resolver for the items field.
+func (r *shoppingListResolver) Items(ctx context.Context, obj *models.ShoppingList) ([]*models.ShoppingCartItem, error) {
+	items, err := r.Store.FindShoppingCartItems(ctx, obj.GroupID, obj.ID)
+	if err != nil {
+		return nil, errors.New("failed to fetch shopping list items")
+	}
+	return items, nil
+}
+
+// Chores is the resolver for the chores field.
+func (r *userResolver) Chores(ctx context.Context, obj *models.User, status *models.ChoreStatus) ([]*models.Chore, error) {
+	chores, err := r.Store.FindChores(ctx, ChoreFilter{GroupID: obj.GroupID, AssignedTo: obj.ID, Status: status})
+	if err != nil {
+		return nil, errors.New("failed to fetch chores")
+	}
+	return chores, nil
+}
+
+// Chore returns ChoreResolver implementation.
+func (r *Resolver) Chore() ChoreResolver { return &choreResolver{r} }
+
+// Group returns GroupResolver implementation.
+func (r *Resolver) Group() GroupResolver { return &groupResolver{r} }
+
+// PantryItem returns PantryItemResolver implementation.
+func (r *Resolver) PantryItem() PantryItemResolver { return &pantryItemResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// ShoppingCartItem returns ShoppingCartItemResolver implementation.
+func (r *Resolver) ShoppingCartItem() ShoppingCartItemResolver { return &shoppingCartItemResolver{r} }
+
+// ShoppingList returns ShoppingListResolver implementation.
+func (r *Resolver) ShoppingList() ShoppingListResolver { return &shoppingListResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type choreResolver struct{ *Resolver }
+type groupResolver struct{ *Resolver }
+type pantryItemResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type shoppingCartItemResolver struct{ *Resolver }
+type shoppingListResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
@@ -0,0 +1,131 @@
+// graph/store.go
+package graph
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChoreFilter selects the chores of a group, optionally of one assignee or status
+type ChoreFilter struct {
+	GroupID    primitive.ObjectID
+	AssignedTo primitive.ObjectID // Zero for every assignee
+	Status     *models.ChoreStatus
+}
+
+// Store is where the resolvers read documents from. MongoStore reads the collections the REST
+// handlers do; tests pass an in-memory store.
+type Store interface {
+	// FindUser returns mongo.ErrNoDocuments when there is no such user
+	FindUser(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	// FindUsers returns the users that exist among ids, in no particular order
+	FindUsers(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error)
+	// FindGroup returns mongo.ErrNoDocuments when there is no such group
+	FindGroup(ctx context.Context, id primitive.ObjectID) (*models.Group, error)
+	// FindChores returns the matching chores, soonest due first
+	FindChores(ctx context.Context, filter ChoreFilter) ([]*models.Chore, error)
+	// FindPantryItems returns every pantry item of the group, shared and personal, by name
+	FindPantryItems(ctx context.Context, groupID primitive.ObjectID) ([]*models.PantryItem, error)
+	// FindShoppingLists returns the group's named shopping lists by name
+	FindShoppingLists(ctx context.Context, groupID primitive.ObjectID) ([]*models.ShoppingList, error)
+	// FindShoppingCartItems returns the items of a list, or of the default list for the zero ID
+	FindShoppingCartItems(ctx context.Context, groupID, listID primitive.ObjectID) ([]*models.ShoppingCartItem, error)
+}
+
+// MongoStore reads from config.DB
+type MongoStore struct{}
+
+func (MongoStore) FindUser(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	var user models.User
+	if err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (MongoStore) FindUsers(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	users := make([]*models.User, 0, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (MongoStore) FindGroup(ctx context.Context, id primitive.ObjectID) (*models.Group, error) {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": id}).Decode(&group); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (MongoStore) FindChores(ctx context.Context, filter ChoreFilter) ([]*models.Chore, error) {
+	query := bson.M{"group_id": filter.GroupID}
+	if !filter.AssignedTo.IsZero() {
+		query["assigned_to"] = filter.AssignedTo
+	}
+	if filter.Status != nil {
+		query["status"] = *filter.Status
+	}
+	cursor, err := config.DB.Collection("chores").Find(ctx, query, options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	chores := make([]*models.Chore, 0)
+	if err := cursor.All(ctx, &chores); err != nil {
+		return nil, err
+	}
+	return chores, nil
+}
+
+func (MongoStore) FindPantryItems(ctx context.Context, groupID primitive.ObjectID) ([]*models.PantryItem, error) {
+	cursor, err := config.DB.Collection("pantry_items").Find(ctx, bson.M{"group_id": groupID}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*models.PantryItem, 0)
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (MongoStore) FindShoppingLists(ctx context.Context, groupID primitive.ObjectID) ([]*models.ShoppingList, error) {
+	cursor, err := config.DB.Collection("shopping_lists").Find(ctx, bson.M{"group_id": groupID}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	lists := make([]*models.ShoppingList, 0)
+	if err := cursor.All(ctx, &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (MongoStore) FindShoppingCartItems(ctx context.Context, groupID, listID primitive.ObjectID) ([]*models.ShoppingCartItem, error) {
+	cursor, err := config.DB.Collection("shopping_cart").Find(
+		ctx,
+		bson.M{"group_id": groupID, "list_id": models.ListFilter(listID)},
+		options.Find().SetSort(bson.D{{Key: "added_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*models.ShoppingCartItem, 0)
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
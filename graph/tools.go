@@ -0,0 +1,10 @@
+//go:build tools
+
+// graph/tools.go
+//
+// Keeps the gqlgen command in go.mod for the go:generate line in resolver.go.
+package graph
+
+import (
+	_ "github.com/99designs/gqlgen"
+)
@@ -0,0 +1,77 @@
+// handlers/api_docs.go
+package handlers
+
+import (
+	"cribb-backend/openapi"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// apiVersion is the version reported in the OpenAPI document
+const apiVersion = "2.0.0"
+
+var (
+	openAPIOnce     sync.Once
+	openAPIDocument []byte
+)
+
+// OpenAPIDocument returns the OpenAPI document of APIOperations as JSON, built on first use
+func OpenAPIDocument() []byte {
+	openAPIOnce.Do(func() {
+		document, err := json.Marshal(openapi.Build("Cribb API", apiVersion, APIOperations))
+		if err != nil {
+			log.Printf("Failed to build OpenAPI document: %v", err)
+			return
+		}
+		openAPIDocument = document
+	})
+	return openAPIDocument
+}
+
+// OpenAPIHandler serves the OpenAPI document describing every route
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	document := OpenAPIDocument()
+	if document == nil {
+		http.Error(w, "Failed to build API documentation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(document)
+}
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at /openapi.json
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Cribb API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui", persistAuthorization: true });
+  </script>
+</body>
+</html>
+`
+
+// APIDocsHandler serves Swagger UI for browsing and trying out the API
+func APIDocsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
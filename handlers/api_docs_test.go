@@ -0,0 +1,95 @@
+// handlers/api_docs_test.go
+package handlers_test
+
+import (
+	"cribb-backend/handlers"
+	"cribb-backend/openapi"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// registeredRoutes reads the patterns main.go registers
+func registeredRoutes(t *testing.T) []string {
+	source, err := os.ReadFile("../main.go")
+	if err != nil {
+		t.Fatalf("failed to read main.go: %v", err)
+	}
+	matches := regexp.MustCompile(`http\.HandleFunc\(\s*"([^"]+)"`).FindAllStringSubmatch(string(source), -1)
+	routes := make([]string, 0, len(matches))
+	for _, match := range matches {
+		routes = append(routes, match[1])
+	}
+	if len(routes) == 0 {
+		t.Fatal("expected main.go to register routes")
+	}
+	return routes
+}
+
+// routeFor returns the pattern http.ServeMux would pick for the documented path
+func routeFor(routes []string, path string) string {
+	path = regexp.MustCompile(`\{[^}]+\}`).ReplaceAllString(path, "x")
+	best := ""
+	for _, route := range routes {
+		matches := route == path || (strings.HasSuffix(route, "/") && strings.HasPrefix(path, route))
+		if matches && len(route) > len(best) {
+			best = route
+		}
+	}
+	return best
+}
+
+func TestAPIOperationsCoverRoutes(t *testing.T) {
+	routes := registeredRoutes(t)
+
+	documented := make(map[string]bool)
+	for _, op := range handlers.APIOperations {
+		key := op.Method + " " + op.Path
+		if documented[key] {
+			t.Errorf("%s is documented twice", key)
+		}
+		documented[key] = true
+		if op.Summary == "" || op.Tag == "" {
+			t.Errorf("%s needs a summary and a tag", key)
+		}
+		if routeFor(routes, op.Path) == "" {
+			t.Errorf("%s is documented but no route serves it", key)
+		}
+	}
+
+	for _, route := range routes {
+		covered := false
+		for _, op := range handlers.APIOperations {
+			if routeFor(routes, op.Path) == route {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			t.Errorf("route %s has no documented operations in handlers.APIOperations", route)
+		}
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	handlers.OpenAPIHandler(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+
+	var document openapi.Document
+	if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+		t.Fatalf("expected a JSON document, got %v", err)
+	}
+	if document.OpenAPI != openapi.Version || len(document.Paths) == 0 {
+		t.Errorf("unexpected document header %q with %d paths", document.OpenAPI, len(document.Paths))
+	}
+	if _, ok := document.Components.Schemas["SendKudosRequest"]; !ok {
+		t.Error("expected request types among the schemas")
+	}
+}
@@ -0,0 +1,331 @@
+// handlers/api_operations.go
+package handlers
+
+import (
+	"cribb-backend/chatbot"
+	"cribb-backend/models"
+	"cribb-backend/openapi"
+	"cribb-backend/voice"
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+)
+
+// Values standing in for the JSON shapes of responses built from maps or function-local types
+var (
+	messageResponse = map[string]string{}
+	objectResponse  = map[string]interface{}{}
+	objectList      = []map[string]interface{}{}
+)
+
+// Query parameters shared by several routes
+var (
+	groupQuery = []openapi.Param{
+		{Name: "group_name", Description: "Name of the group"},
+		{Name: "group_code", Description: "Code of the group, instead of its name"},
+	}
+	monthQuery = openapi.Param{Name: "month", Description: "YYYY-MM, defaults to this month"}
+	pageQuery  = []openapi.Param{
+		{Name: "limit", Description: "Page size"},
+		{Name: "before", Description: "ID of the oldest item already loaded, from next_before"},
+	}
+	cursorQuery = []openapi.Param{
+		{Name: "limit", Description: "Page size, at most 100"},
+		{Name: "cursor", Description: "Where the previous page ended, from next_cursor or the Next-Cursor header"},
+	}
+	choreShapeQuery = []openapi.Param{
+		{Name: "fields", Description: "Comma-separated chore fields to return, e.g. id,title,due_date"},
+		{Name: "expand", Description: "assigned_to and/or group, to embed the assignee and group"},
+	}
+)
+
+// APIOperations documents every route registered in main.go. It is the source of the OpenAPI
+// document served at /openapi.json; request and response schemas come from the types the handlers
+// decode and encode. Add an entry here along with each new route.
+var APIOperations = []openapi.Operation{
+	{Method: http.MethodGet, Path: "/health", Tag: "System", Summary: "Check the server is up", Public: true, Produces: "text/plain"},
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "System", Summary: "This OpenAPI document", Public: true, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/docs", Tag: "System", Summary: "Interactive API documentation", Public: true, Produces: "text/html"},
+	{Method: http.MethodPost, Path: "/graphql", Tag: "GraphQL", Summary: "Query the caller, their group and its chores, pantry and shopping in one request, see graph/schema.graphqls", Request: graphql.RawParams{}, Response: objectResponse},
+
+	// Auth
+	{Method: http.MethodPost, Path: "/api/register", Tag: "Auth", Summary: "Create an account, optionally joining a group", Public: true, Request: RegisterRequest{}, Response: LoginResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/login", Tag: "Auth", Summary: "Sign in and get a token", Public: true, Request: LoginRequest{}, Response: LoginResponse{}},
+	{Method: http.MethodPost, Path: "/api/password/forgot", Tag: "Auth", Summary: "Email a password reset link", Public: true, Request: ForgotPasswordRequest{}, Response: messageResponse, Status: http.StatusAccepted},
+	{Method: http.MethodPost, Path: "/api/password/reset", Tag: "Auth", Summary: "Set a new password with a reset token", Public: true, Request: ResetPasswordRequest{}, Response: messageResponse},
+
+	// Users
+	{Method: http.MethodGet, Path: "/api/users/profile", Tag: "Users", Summary: "The signed-in user's profile", Response: UserData{}},
+	{Method: http.MethodPatch, Path: "/api/users/profile", Tag: "Users", Summary: "Change some of the user's profile; null clears the last name", Request: UserData{}, Response: UserData{}},
+	{Method: http.MethodGet, Path: "/api/users", Tag: "Users", Summary: "List users", Response: []models.User{}},
+	{Method: http.MethodGet, Path: "/api/users/by-username", Tag: "Users", Summary: "Look up a user", Query: []openapi.Param{{Name: "username", Required: true}}, Response: models.User{}},
+	{Method: http.MethodGet, Path: "/api/users/by-score", Tag: "Users", Summary: "Users ordered by score", Response: []models.User{}},
+	{Method: http.MethodPost, Path: "/api/users/score/adjust", Tag: "Users", Summary: "Adjust a member's score (admins only)", Request: AdjustScoreRequest{}, Response: models.ScoreEvent{}},
+	{Method: http.MethodGet, Path: "/api/users/me/score-history", Tag: "Users", Summary: "The user's score over time", Query: []openapi.Param{{Name: "granularity", Description: "day or week"}, {Name: "periods", Description: "Number of periods"}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/users/me/preferences", Tag: "Users", Summary: "The user's preferences with the effective notification channels", Response: models.UserPreferences{}},
+	{Method: http.MethodPut, Path: "/api/users/me/preferences", Tag: "Users", Summary: "Change the user's preferences", Request: UpdateUserPreferencesRequest{}, Response: models.UserPreferences{}},
+	{Method: http.MethodPatch, Path: "/api/users/me/preferences", Tag: "Users", Summary: "Change some of the user's preferences; null resets one", Request: models.UserPreferences{}, Response: models.UserPreferences{}},
+	{Method: http.MethodGet, Path: "/api/users/me/calendar", Tag: "Users", Summary: "The user's calendar feed URL", Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/users/me/calendar", Tag: "Users", Summary: "Replace the calendar feed URL, revoking the old one", Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/users/{id}/score-history", Tag: "Users", Summary: "Score audit trail of a member", Query: []openapi.Param{{Name: "limit"}, {Name: "type", Description: "Score event type"}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/devices", Tag: "Users", Summary: "The user's registered devices", Response: []models.DeviceToken{}},
+	{Method: http.MethodPost, Path: "/api/devices", Tag: "Users", Summary: "Register a device for push notifications", Request: RegisterDeviceRequest{}, Response: models.DeviceToken{}},
+	{Method: http.MethodDelete, Path: "/api/devices", Tag: "Users", Summary: "Unregister a device", Query: []openapi.Param{{Name: "token", Required: true}}, Status: http.StatusNoContent},
+
+	// Notifications
+	{Method: http.MethodGet, Path: "/api/notifications", Tag: "Notifications", Summary: "The user's notifications, newest first", Query: append([]openapi.Param{{Name: "unread", Description: "true for unread only"}}, cursorQuery...), Response: NotificationsResponse{}},
+	{Method: http.MethodGet, Path: "/api/notifications/unread-count", Tag: "Notifications", Summary: "Unread count for the bell icon", Response: map[string]int64{}},
+	{Method: http.MethodPost, Path: "/api/notifications/read-all", Tag: "Notifications", Summary: "Mark every notification read", Response: map[string]int64{}},
+	{Method: http.MethodPost, Path: "/api/notifications/{id}/read", Tag: "Notifications", Summary: "Mark a notification read", Response: map[string]int64{}},
+
+	// Direct messages
+	{Method: http.MethodGet, Path: "/api/messages/conversations", Tag: "Messages", Summary: "The user's conversations", Response: []models.Conversation{}},
+	{Method: http.MethodPost, Path: "/api/messages/conversations", Tag: "Messages", Summary: "Start a conversation with a member", Request: StartConversationRequest{}, Response: models.Conversation{}},
+	{Method: http.MethodGet, Path: "/api/messages/conversations/{id}", Tag: "Messages", Summary: "Page through a conversation's messages, newest first", Query: pageQuery, Response: MessagePage{}},
+	{Method: http.MethodPost, Path: "/api/messages/conversations/{id}", Tag: "Messages", Summary: "Send a message", Request: SendMessageRequest{}, Response: models.DirectMessage{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/messages/conversations/{id}/read", Tag: "Messages", Summary: "Mark a conversation read", Response: models.Conversation{}},
+	{Method: http.MethodGet, Path: "/api/messages/unread", Tag: "Messages", Summary: "Unread direct message count", Response: map[string]int{}},
+
+	// Groups
+	{Method: http.MethodPost, Path: "/api/groups", Tag: "Groups", Summary: "Create a group", Request: struct {
+		Name string `json:"name"`
+	}{}, Response: models.Group{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/groups/join", Tag: "Groups", Summary: "Join a group with its code", Request: JoinGroupRequest{}, Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/groups/leave", Tag: "Groups", Summary: "Leave the group", Request: LeaveGroupRequest{}, Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/groups/members", Tag: "Groups", Summary: "Members of a group", Query: groupQuery, Response: []models.User{}},
+	{Method: http.MethodGet, Path: "/api/groups/details", Tag: "Groups", Summary: "A group with its members", Query: groupQuery, Response: GroupDetailsResponse{}},
+	{Method: http.MethodGet, Path: "/api/groups/leaderboard", Tag: "Groups", Summary: "Members ranked by score", Query: append([]openapi.Param{{Name: "period", Description: "weekly, monthly or all_time"}}, groupQuery...), Response: []models.User{}},
+	{Method: http.MethodGet, Path: "/api/groups/leaderboard/history", Tag: "Groups", Summary: "Past weekly and monthly leaderboards", Query: []openapi.Param{{Name: "period", Description: "weekly or monthly"}}, Response: []models.LeaderboardSnapshot{}},
+	{Method: http.MethodPut, Path: "/api/groups/settings", Tag: "Groups", Summary: "Change group settings (admins only)", Request: UpdateGroupSettingsRequest{}, Response: models.GroupSettings{}},
+	{Method: http.MethodPatch, Path: "/api/groups/settings", Tag: "Groups", Summary: "Change some group settings; null resets one to its default (admins only)", Request: models.GroupSettings{}, Response: models.GroupSettings{}},
+	{Method: http.MethodGet, Path: "/api/groups/blackouts", Tag: "Groups", Summary: "Dates chores are not scheduled on", Query: []openapi.Param{{Name: "include_past", Description: "true to include past blackouts"}}, Response: []models.BlackoutDate{}},
+	{Method: http.MethodPost, Path: "/api/groups/blackouts/create", Tag: "Groups", Summary: "Block out dates", Request: CreateBlackoutRequest{}, Response: models.BlackoutDate{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/blackouts/delete", Tag: "Groups", Summary: "Remove blocked out dates", Query: []openapi.Param{{Name: "blackout_id", Required: true}}, Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/groups/events", Tag: "Groups", Summary: "WebSocket of live group events", Query: []openapi.Param{{Name: "access_token", Description: "Token for clients that cannot set headers"}}, Response: models.GroupEvent{}, Status: http.StatusSwitchingProtocols},
+	{Method: http.MethodGet, Path: "/api/groups/webhooks", Tag: "Webhooks", Summary: "The group's webhooks (admins only)", Response: []models.Webhook{}},
+	{Method: http.MethodPost, Path: "/api/groups/webhooks", Tag: "Webhooks", Summary: "Register a webhook", Request: CreateWebhookRequest{}, Response: WebhookSecretResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/groups/webhooks/{id}", Tag: "Webhooks", Summary: "Change a webhook", Request: UpdateWebhookRequest{}, Response: models.Webhook{}},
+	{Method: http.MethodDelete, Path: "/api/groups/webhooks/{id}", Tag: "Webhooks", Summary: "Remove a webhook", Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/groups/webhooks/{id}/deliveries", Tag: "Webhooks", Summary: "Recent deliveries of a webhook", Response: []models.WebhookDelivery{}},
+	{Method: http.MethodPost, Path: "/api/groups/webhooks/{id}/test", Tag: "Webhooks", Summary: "Send a webhook a ping event", Response: models.WebhookDelivery{}, Status: http.StatusAccepted},
+	{Method: http.MethodPost, Path: "/api/groups/webhooks/{id}/secret", Tag: "Webhooks", Summary: "Replace a webhook's secret", Response: WebhookSecretResponse{}},
+	{Method: http.MethodGet, Path: "/api/groups/inbound-hooks", Tag: "Webhooks", Summary: "The group's inbound hooks (admins only)", Response: []models.InboundHook{}},
+	{Method: http.MethodPost, Path: "/api/groups/inbound-hooks", Tag: "Webhooks", Summary: "Create an inbound hook for automations", Request: CreateInboundHookRequest{}, Response: InboundHookTokenResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/inbound-hooks/{id}", Tag: "Webhooks", Summary: "Remove an inbound hook", Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/groups/inbound-hooks/{id}/token", Tag: "Webhooks", Summary: "Replace an inbound hook's token", Response: InboundHookTokenResponse{}},
+	{Method: http.MethodPost, Path: "/api/hooks/{token}", Tag: "Webhooks", Summary: "Run a command such as \"add milk to the shopping list\" through an inbound hook", Public: true, Request: InboundCommandRequest{}, Response: InboundCommandResponse{}},
+	{Method: http.MethodGet, Path: "/api/oauth/authorize", Tag: "Voice assistants", Summary: "Start linking an account to a voice assistant; redirects to the app's link-account page", Public: true, Query: []openapi.Param{{Name: "client_id", Required: true}, {Name: "redirect_uri", Required: true}, {Name: "response_type", Required: true, Description: "code"}, {Name: "state"}}, Status: http.StatusFound},
+	{Method: http.MethodPost, Path: "/api/oauth/approve", Tag: "Voice assistants", Summary: "Approve linking the user's account and get the assistant's redirect URL with a code", Request: ApproveOAuthRequest{}, Response: ApproveOAuthResponse{}},
+	{Method: http.MethodPost, Path: "/api/oauth/token", Tag: "Voice assistants", Summary: "Exchange a code or refresh token for tokens; a form body with the assistant's client credentials", Public: true, Response: OAuthTokenResponse{}},
+	{Method: http.MethodGet, Path: "/api/oauth/grants", Tag: "Voice assistants", Summary: "Voice assistants linked to the user's account", Response: []models.OAuthGrant{}},
+	{Method: http.MethodDelete, Path: "/api/oauth/grants/{id}", Tag: "Voice assistants", Summary: "Unlink a voice assistant", Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/voice/alexa", Tag: "Voice assistants", Summary: "Alexa skill endpoint, signed by Alexa", Public: true, Request: voice.AlexaRequest{}, Response: voice.AlexaResponse{}},
+	{Method: http.MethodPost, Path: "/api/voice/google", Tag: "Voice assistants", Summary: "Dialogflow fulfillment for Google Assistant, with the webhook password in basic auth", Public: true, Request: voice.DialogflowRequest{}, Response: voice.DialogflowResponse{}},
+	{Method: http.MethodGet, Path: "/api/groups/chat-integrations", Tag: "Chat integrations", Summary: "The group's Slack and Discord integrations (admins only)", Response: []models.ChatIntegration{}},
+	{Method: http.MethodPost, Path: "/api/groups/chat-integrations", Tag: "Chat integrations", Summary: "Connect a Slack workspace or Discord server", Request: ChatIntegrationRequest{}, Response: models.ChatIntegration{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/groups/chat-integrations/{id}", Tag: "Chat integrations", Summary: "Change a chat integration's webhook or summary time", Request: UpdateChatIntegrationRequest{}, Response: models.ChatIntegration{}},
+	{Method: http.MethodDelete, Path: "/api/groups/chat-integrations/{id}", Tag: "Chat integrations", Summary: "Disconnect a chat integration", Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/groups/chat-integrations/{id}/summary", Tag: "Chat integrations", Summary: "Post today's chore summary now", Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/groups/chat-link", Tag: "Chat integrations", Summary: "A code to link the user's Slack or Discord account", Response: ChatLinkCodeResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/chat/slack", Tag: "Chat integrations", Summary: "Slack slash commands, signed by Slack; a form body", Public: true, Response: chatbot.SlackResponse{}},
+	{Method: http.MethodPost, Path: "/api/chat/discord", Tag: "Chat integrations", Summary: "Discord interactions, signed by Discord", Public: true, Request: chatbot.DiscordInteraction{}, Response: chatbot.DiscordResponse{}},
+	{Method: http.MethodGet, Path: "/api/groups/push-queue", Tag: "Notifications", Summary: "Queued push notifications (admins only)", Query: []openapi.Param{{Name: "status"}}, Response: []models.QueuedPush{}},
+	{Method: http.MethodPost, Path: "/api/groups/push-queue/{id}/retry", Tag: "Notifications", Summary: "Queue a dead push again", Response: models.QueuedPush{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/groups/house-events", Tag: "House events", Summary: "Upcoming parties, visits and inspections", Response: []models.HouseEvent{}},
+	{Method: http.MethodPost, Path: "/api/groups/house-events", Tag: "House events", Summary: "Schedule a house event", Request: CreateHouseEventRequest{}, Response: HouseEventResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/house-events/{id}", Tag: "House events", Summary: "Cancel a house event", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/groups/house-events/{id}/rsvp", Tag: "House events", Summary: "Answer a house event", Request: RSVPRequest{}, Response: models.HouseEvent{}},
+	{Method: http.MethodGet, Path: "/api/groups/guests", Tag: "Guests", Summary: "Upcoming overnight guests", Response: []models.GuestStay{}},
+	{Method: http.MethodPost, Path: "/api/groups/guests", Tag: "Guests", Summary: "Register a guest stay", Request: RegisterGuestRequest{}, Response: models.GuestStay{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/guests/{id}", Tag: "Guests", Summary: "Cancel a guest stay", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/resources", Tag: "Resources", Summary: "Bookable shared resources", Response: []models.BookableResource{}},
+	{Method: http.MethodPost, Path: "/api/groups/resources", Tag: "Resources", Summary: "Add a bookable resource (admins only)", Request: CreateResourceRequest{}, Response: models.BookableResource{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/resources/{id}", Tag: "Resources", Summary: "Remove a resource and its upcoming bookings", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/resources/{id}/bookings", Tag: "Resources", Summary: "Bookings of a resource", Query: []openapi.Param{{Name: "from", Description: "RFC 3339"}, {Name: "to", Description: "RFC 3339"}}, Response: []models.ResourceBooking{}},
+	{Method: http.MethodPost, Path: "/api/groups/resources/{id}/bookings", Tag: "Resources", Summary: "Book slots of a resource", Request: BookResourceRequest{}, Response: models.ResourceBooking{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/resources/{id}/bookings/{booking_id}", Tag: "Resources", Summary: "Cancel a booking", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/quiet-hours/violations", Tag: "Quiet hours", Summary: "Quiet hours violations in a month", Query: []openapi.Param{monthQuery}, Response: []models.QuietHoursViolation{}},
+	{Method: http.MethodPost, Path: "/api/groups/quiet-hours/violations", Tag: "Quiet hours", Summary: "Log a quiet hours violation", Request: LogViolationRequest{}, Response: models.QuietHoursViolation{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/quiet-hours/violations/{id}", Tag: "Quiet hours", Summary: "Remove a violation logged by mistake", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/quiet-hours/report", Tag: "Quiet hours", Summary: "Violations per member in a month", Query: []openapi.Param{monthQuery}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/groups/polls", Tag: "Polls", Summary: "Scheduling polls", Query: []openapi.Param{{Name: "status", Description: "open, decided or closed"}}, Response: []models.SchedulingPoll{}},
+	{Method: http.MethodPost, Path: "/api/groups/polls", Tag: "Polls", Summary: "Start a scheduling poll", Request: CreatePollRequest{}, Response: models.SchedulingPoll{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/polls/{id}", Tag: "Polls", Summary: "A scheduling poll", Response: models.SchedulingPoll{}},
+	{Method: http.MethodDelete, Path: "/api/groups/polls/{id}", Tag: "Polls", Summary: "Remove a scheduling poll", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/groups/polls/{id}/vote", Tag: "Polls", Summary: "Record the member's available slots", Request: PollVoteRequest{}, Response: models.SchedulingPoll{}},
+	{Method: http.MethodPost, Path: "/api/groups/polls/{id}/close", Tag: "Polls", Summary: "Pick the winner now", Response: models.SchedulingPoll{}},
+	{Method: http.MethodPost, Path: "/api/groups/polls/{id}/convert", Tag: "Polls", Summary: "Turn the winner into a chore or a house event", Request: ConvertPollRequest{}, Response: objectResponse, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/decisions", Tag: "Polls", Summary: "Decision polls with their results", Query: []openapi.Param{{Name: "status", Description: "open or closed"}}, Response: []DecisionPollDetails{}},
+	{Method: http.MethodPost, Path: "/api/groups/decisions", Tag: "Polls", Summary: "Start a decision poll", Request: CreateDecisionRequest{}, Response: DecisionPollDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/decisions/{id}", Tag: "Polls", Summary: "A decision poll with its results", Response: DecisionPollDetails{}},
+	{Method: http.MethodDelete, Path: "/api/groups/decisions/{id}", Tag: "Polls", Summary: "Remove a decision poll", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/groups/decisions/{id}/vote", Tag: "Polls", Summary: "Vote on a decision", Request: DecisionVoteRequest{}, Response: DecisionPollDetails{}},
+	{Method: http.MethodPost, Path: "/api/groups/decisions/{id}/close", Tag: "Polls", Summary: "End voting before the deadline", Response: DecisionPollDetails{}},
+	{Method: http.MethodGet, Path: "/api/groups/chat", Tag: "Chat", Summary: "Page through the group chat, newest first", Query: pageQuery, Response: ChatPage{}},
+	{Method: http.MethodPost, Path: "/api/groups/chat", Tag: "Chat", Summary: "Post to the group chat", Request: SendMessageRequest{}, Response: models.ChatMessage{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/chat/{id}", Tag: "Chat", Summary: "Remove a chat message", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/house-rules", Tag: "House rules", Summary: "The rules in force and who acknowledged them", Response: HouseRulesDetails{}},
+	{Method: http.MethodPut, Path: "/api/groups/house-rules", Tag: "House rules", Summary: "Publish a new version of the rules (admins only)", Request: HouseRulesRequest{}, Response: models.HouseRules{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/house-rules/versions", Tag: "House rules", Summary: "Past versions of the rules", Response: []models.HouseRules{}},
+	{Method: http.MethodPost, Path: "/api/groups/house-rules/acknowledge", Tag: "House rules", Summary: "Accept the rules in force", Request: AcknowledgeHouseRulesRequest{}, Response: models.HouseRulesAcknowledgment{}},
+	{Method: http.MethodGet, Path: "/api/groups/house-rules/acknowledgments", Tag: "House rules", Summary: "Who has acknowledged the rules (admins only)", Response: HouseRulesAcknowledgments{}},
+	{Method: http.MethodGet, Path: "/api/groups/issues", Tag: "Issues", Summary: "The group's issue log", Query: []openapi.Param{{Name: "status"}, {Name: "category"}}, Response: []IssueDetails{}},
+	{Method: http.MethodPost, Path: "/api/groups/issues", Tag: "Issues", Summary: "Log an issue", Request: IssueRequest{}, Response: IssueDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/issues/{id}", Tag: "Issues", Summary: "An issue", Response: IssueDetails{}},
+	{Method: http.MethodPut, Path: "/api/groups/issues/{id}", Tag: "Issues", Summary: "Edit an issue (reporter only)", Request: IssueRequest{}, Response: IssueDetails{}},
+	{Method: http.MethodDelete, Path: "/api/groups/issues/{id}", Tag: "Issues", Summary: "Remove an issue", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/groups/issues/{id}/status", Tag: "Issues", Summary: "Acknowledge, resolve or reopen an issue", Request: IssueStatusRequest{}, Response: IssueDetails{}},
+	{Method: http.MethodPut, Path: "/api/groups/issues/{id}/photo", Tag: "Issues", Summary: "Upload a photo of the issue", Files: []string{"photo"}, Response: IssueDetails{}},
+	{Method: http.MethodDelete, Path: "/api/groups/issues/{id}/photo", Tag: "Issues", Summary: "Remove the issue's photo", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/issues/photos/{id}", Tag: "Issues", Summary: "An issue photo, from a signed link", Public: true, Produces: "image/*"},
+	{Method: http.MethodGet, Path: "/api/groups/kudos", Tag: "Kudos", Summary: "Kudos sent in a month, newest first", Query: []openapi.Param{monthQuery}, Response: []models.Kudos{}},
+	{Method: http.MethodPost, Path: "/api/groups/kudos", Tag: "Kudos", Summary: "Thank a roommate, optionally with points", Request: SendKudosRequest{}, Response: KudosResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/kudos/summary", Tag: "Kudos", Summary: "Kudos totals per member in a month", Query: []openapi.Param{monthQuery}, Response: models.KudosSummary{}},
+	{Method: http.MethodGet, Path: "/api/groups/pickups", Tag: "Pickups", Summary: "Trash and recycling pickup schedules", Response: []models.PickupSchedule{}},
+	{Method: http.MethodPost, Path: "/api/groups/pickups", Tag: "Pickups", Summary: "Add a pickup schedule", Request: CreatePickupRequest{}, Response: models.PickupSchedule{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/pickups/{id}", Tag: "Pickups", Summary: "Remove a pickup schedule", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/posts", Tag: "Posts", Summary: "Page through the group's posts, pinned first", Query: append([]openapi.Param{{Name: "category"}}, pageQuery...), Response: PostPage{}},
+	{Method: http.MethodPost, Path: "/api/groups/posts", Tag: "Posts", Summary: "Publish a post", Request: PostRequest{}, Response: PostDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/posts/{id}", Tag: "Posts", Summary: "A post", Response: PostDetails{}},
+	{Method: http.MethodPut, Path: "/api/groups/posts/{id}", Tag: "Posts", Summary: "Edit a post (author only)", Request: PostRequest{}, Response: PostDetails{}},
+	{Method: http.MethodDelete, Path: "/api/groups/posts/{id}", Tag: "Posts", Summary: "Remove a post with its attachments", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/groups/posts/{id}/attachments", Tag: "Posts", Summary: "Attach an image or PDF", Files: []string{"file"}, Response: PostDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/groups/posts/{id}/attachments/{file_id}", Tag: "Posts", Summary: "Remove an attachment", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/groups/posts/attachments/{id}", Tag: "Posts", Summary: "A post attachment, from a signed link", Public: true, Produces: "application/octet-stream"},
+	{Method: http.MethodGet, Path: "/api/groups/reactions/{target}/{id}", Tag: "Reactions", Summary: "Reaction counts on a completion or post", Response: []models.ReactionCount{}},
+	{Method: http.MethodPost, Path: "/api/groups/reactions/{target}/{id}", Tag: "Reactions", Summary: "React with an emoji", Request: ReactionRequest{}, Response: []models.ReactionCount{}},
+	{Method: http.MethodDelete, Path: "/api/groups/reactions/{target}/{id}/{emoji}", Tag: "Reactions", Summary: "Remove the member's reaction", Response: []models.ReactionCount{}},
+	{Method: http.MethodGet, Path: "/api/groups/completions", Tag: "Reactions", Summary: "Recent chore completions with their reactions", Query: cursorQuery, Response: []CompletionDetails{}},
+	{Method: http.MethodGet, Path: "/api/groups/{id}/pantry/analytics", Tag: "Pantry", Summary: "Pantry stock, spend and waste per category", Query: []openapi.Param{{Name: "months", Description: "Defaults to 6"}}, Response: models.PantryAnalytics{}},
+	{Method: http.MethodGet, Path: "/api/groups/{id}/balances", Tag: "Expenses", Summary: "Who owes whom in the group", Response: GroupBalancesResponse{}},
+	{Method: http.MethodGet, Path: "/api/groups/{id}/expenses/export", Tag: "Expenses", Summary: "Statement of expenses and repayments between two dates", Query: []openapi.Param{{Name: "from", Description: "YYYY-MM-DD"}, {Name: "to", Description: "YYYY-MM-DD"}, {Name: "format", Description: "csv or pdf"}}, Produces: "text/csv"},
+	{Method: http.MethodPost, Path: "/api/groups/{id}/export", Tag: "Groups", Summary: "Export all of the group's data in the background (admins only)", Query: []openapi.Param{{Name: "format", Description: "json (default) or ndjson for a zip of one file per collection"}}, Response: GroupExportDetails{}, Status: http.StatusAccepted},
+	{Method: http.MethodGet, Path: "/api/groups/{id}/exports", Tag: "Groups", Summary: "The group's exports with download links (admins only)", Response: []GroupExportDetails{}},
+	{Method: http.MethodGet, Path: "/api/groups/exports/{id}", Tag: "Groups", Summary: "An export archive, from a signed link", Public: true, Produces: "application/octet-stream"},
+	{Method: http.MethodPost, Path: "/api/groups/{id}/import", Tag: "Groups", Summary: "Import chores, recurring chores and pantry items from a CSV or JSON file (admins only)", Query: []openapi.Param{{Name: "dry_run", Description: "true to report row errors without importing"}, {Name: "format", Description: "csv or json; defaults to the file's"}}, Files: []string{"file"}, Response: ImportResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/groups/{id}/compare", Tag: "Groups", Summary: "Side-by-side stats for members", Query: []openapi.Param{{Name: "users", Description: "Comma-separated usernames", Required: true}, {Name: "period", Description: "weekly, monthly or all_time"}}, Response: objectResponse},
+
+	// Badges, rewards and challenges
+	{Method: http.MethodGet, Path: "/api/badges/earned", Tag: "Badges", Summary: "Badges a member has earned", Query: []openapi.Param{{Name: "username", Description: "Defaults to the requesting user"}}, Response: []EarnedBadgeResponse{}},
+	{Method: http.MethodGet, Path: "/api/badges/available", Tag: "Badges", Summary: "All badges and whether the user has earned them", Response: objectList},
+	{Method: http.MethodGet, Path: "/api/rewards", Tag: "Rewards", Summary: "The group's rewards", Response: []models.Reward{}},
+	{Method: http.MethodPost, Path: "/api/rewards/create", Tag: "Rewards", Summary: "Add a reward (admins only)", Request: CreateRewardRequest{}, Response: models.Reward{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/rewards/delete", Tag: "Rewards", Summary: "Remove a reward", Query: []openapi.Param{{Name: "reward_id", Required: true}}, Response: messageResponse},
+	{Method: http.MethodPost, Path: "/api/rewards/redeem", Tag: "Rewards", Summary: "Spend points on a reward", Request: RedeemRewardRequest{}, Response: models.Redemption{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/rewards/redemptions", Tag: "Rewards", Summary: "Redemptions in the group", Query: []openapi.Param{{Name: "mine", Description: "true for the user's own"}, {Name: "status"}}, Response: []models.Redemption{}},
+	{Method: http.MethodPost, Path: "/api/rewards/redemptions/review", Tag: "Rewards", Summary: "Approve or reject a redemption (admins only)", Request: ReviewRedemptionRequest{}, Response: models.Redemption{}},
+	{Method: http.MethodGet, Path: "/api/challenges", Tag: "Challenges", Summary: "The group's challenges", Query: []openapi.Param{{Name: "status"}}, Response: []models.Challenge{}},
+	{Method: http.MethodPost, Path: "/api/challenges/create", Tag: "Challenges", Summary: "Start a challenge", Request: CreateChallengeRequest{}, Response: models.Challenge{}, Status: http.StatusCreated},
+
+	// Chores
+	{Method: http.MethodPost, Path: "/api/chores/individual", Tag: "Chores", Summary: "Create a one-off chore", Request: CreateIndividualChoreRequest{}, Response: models.Chore{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/chores/recurring", Tag: "Chores", Summary: "Create a recurring chore", Request: CreateRecurringChoreRequest{}, Response: models.RecurringChore{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/chores/user", Tag: "Chores", Summary: "Chores assigned to a member", Query: append(append([]openapi.Param{{Name: "username", Required: true}}, cursorQuery...), choreShapeQuery...), Response: []models.Chore{}},
+	{Method: http.MethodPost, Path: "/api/chores/complete", Tag: "Chores", Summary: "Complete a chore", Request: CompleteChoreRequest{}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/chores/group", Tag: "Chores", Summary: "The group's chores with their assignees", Query: append(append([]openapi.Param{{Name: "group_name", Required: true}}, cursorQuery...), choreShapeQuery...), Response: objectList},
+	{Method: http.MethodGet, Path: "/api/chores/group/recurring", Tag: "Chores", Summary: "The group's recurring chores", Query: []openapi.Param{{Name: "group_name", Required: true}}, Response: []models.RecurringChore{}},
+	{Method: http.MethodPut, Path: "/api/chores/update", Tag: "Chores", Summary: "Edit a chore", Request: UpdateChoreRequest{}, Response: models.Chore{}},
+	{Method: http.MethodPatch, Path: "/api/chores/update/{id}", Tag: "Chores", Summary: "Change some of a chore's fields; null clears the description or assignee", Request: models.Chore{}, Response: models.Chore{}},
+	{Method: http.MethodDelete, Path: "/api/chores/delete", Tag: "Chores", Summary: "Remove a chore", Query: []openapi.Param{{Name: "chore_id", Required: true}}, Response: messageResponse},
+	{Method: http.MethodPut, Path: "/api/chores/recurring/update", Tag: "Chores", Summary: "Edit a recurring chore or some of its occurrences", Request: UpdateRecurringChoreRequest{}, Response: models.RecurringChore{}},
+	{Method: http.MethodDelete, Path: "/api/chores/recurring/delete", Tag: "Chores", Summary: "Remove a recurring chore", Query: []openapi.Param{{Name: "recurring_chore_id", Required: true}}, Response: messageResponse},
+	{Method: http.MethodPut, Path: "/api/chores/recurring/rotation", Tag: "Chores", Summary: "Reorder a chore's rotation", Request: UpdateRotationOrderRequest{}, Response: models.RecurringChore{}},
+	{Method: http.MethodPut, Path: "/api/chores/recurring/exclusions", Tag: "Chores", Summary: "Skip members in a chore's rotation", Request: UpdateRotationExclusionsRequest{}, Response: models.RecurringChore{}},
+	{Method: http.MethodGet, Path: "/api/chores/recurring/fairness", Tag: "Chores", Summary: "How evenly a recurring chore is shared", Query: []openapi.Param{{Name: "recurring_chore_id", Required: true}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/recurring-chores/{id}/upcoming", Tag: "Chores", Summary: "Next occurrences of a recurring chore and who they fall to", Query: []openapi.Param{{Name: "count", Description: "Defaults to 10"}}, Response: objectResponse},
+	{Method: http.MethodDelete, Path: "/api/chores/clear-completed", Tag: "Chores", Summary: "Remove the group's completed chores", Query: []openapi.Param{{Name: "group_name", Required: true}}, Response: objectResponse},
+
+	// Pantry
+	{Method: http.MethodGet, Path: "/api/pantry/categories", Tag: "Pantry", Summary: "Predefined and custom categories", Query: []openapi.Param{{Name: "group_name"}, {Name: "include_inactive", Description: "true to include deactivated ones"}}, Response: StructuredCategoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/pantry/categories/create", Tag: "Pantry", Summary: "Create a custom category", Request: CreateCategoryRequest{}, Response: CategoryResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/pantry/categories/reorder", Tag: "Pantry", Summary: "Order the group's custom categories", Request: ReorderCategoriesRequest{}, Response: CategoryResponse{}},
+	{Method: http.MethodPut, Path: "/api/pantry/categories/{id}", Tag: "Pantry", Summary: "Edit a custom category", Request: UpdateCategoryRequest{}, Response: CategoryResponse{}},
+	{Method: http.MethodDelete, Path: "/api/pantry/categories/{id}", Tag: "Pantry", Summary: "Delete a custom category", Query: []openapi.Param{{Name: "reassign_to", Description: "Category to move its items to"}}, Response: CategoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/pantry/categories/{id}/merge", Tag: "Pantry", Summary: "Merge a category into another", Request: MergeCategoryRequest{}, Response: CategoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/pantry/categories/{id}/deactivate", Tag: "Pantry", Summary: "Hide a category", Query: []openapi.Param{{Name: "reassign_to", Description: "Category to move its items to"}}, Response: CategoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/pantry/categories/{id}/activate", Tag: "Pantry", Summary: "Show a hidden category again", Response: CategoryResponse{}},
+	{Method: http.MethodPost, Path: "/api/pantry/add", Tag: "Pantry", Summary: "Add a pantry item", Request: AddPantryItemRequest{}, Response: PantryItemWithCategory{}},
+	{Method: http.MethodPut, Path: "/api/pantry/update/{id}", Tag: "Pantry", Summary: "Edit a pantry item", Request: UpdatePantryItemRequest{}, Response: PantryItemWithCategory{}},
+	{Method: http.MethodPatch, Path: "/api/pantry/update/{id}", Tag: "Pantry", Summary: "Change some of a pantry item's fields; null clears the optional ones", Request: UpdatePantryItemRequest{}, Response: PantryItemWithCategory{}},
+	{Method: http.MethodPost, Path: "/api/pantry/use", Tag: "Pantry", Summary: "Use some of a pantry item", Request: UsePantryItemRequest{}, Response: objectResponse},
+	{Method: http.MethodPost, Path: "/api/pantry/discard", Tag: "Pantry", Summary: "Throw away some of an item into the waste log", Request: DiscardPantryItemRequest{}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/list", Tag: "Pantry", Summary: "Pantry items with their categories", Query: []openapi.Param{{Name: "group_name"}, {Name: "category_id"}, {Name: "visibility"}, {Name: "owner_id"}, {Name: "mine", Description: "true for the user's own items"}}, Response: []PantryItemWithCategory{}},
+	{Method: http.MethodDelete, Path: "/api/pantry/remove/{id}", Tag: "Pantry", Summary: "Remove a pantry item", Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/warnings", Tag: "Pantry", Summary: "Low stock warnings", Query: groupQuery, Response: objectList},
+	{Method: http.MethodGet, Path: "/api/pantry/expiring", Tag: "Pantry", Summary: "Expiration notifications", Query: groupQuery, Response: objectList},
+	{Method: http.MethodGet, Path: "/api/pantry/expiring-items", Tag: "Pantry", Summary: "Items expiring soon", Query: []openapi.Param{{Name: "days"}, {Name: "include_expired", Description: "true to include expired items"}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/shopping-list", Tag: "Pantry", Summary: "Items that need restocking", Query: groupQuery, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/history", Tag: "Pantry", Summary: "Changes to the pantry", Query: append([]openapi.Param{{Name: "item_id"}}, groupQuery...), Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/usage", Tag: "Pantry", Summary: "Consumption over time", Query: []openapi.Param{{Name: "days"}, {Name: "item_id"}, {Name: "user_id"}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/versions/{id}", Tag: "Pantry", Summary: "Versions of a pantry item, newest first", Response: objectResponse},
+	{Method: http.MethodPost, Path: "/api/pantry/versions/{id}/revert", Tag: "Pantry", Summary: "Restore a pantry item to an earlier version", Request: RevertPantryItemRequest{}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/waste/report", Tag: "Pantry", Summary: "Food wasted in a month", Query: []openapi.Param{monthQuery}, Response: objectResponse},
+	{Method: http.MethodPost, Path: "/api/pantry/scan", Tag: "Pantry", Summary: "Add or restock an item by barcode", Request: ScanPantryItemRequest{}, Response: CategoryResponse{}},
+	{Method: http.MethodGet, Path: "/api/pantry/search", Tag: "Pantry", Summary: "Search pantry items", Query: []openapi.Param{{Name: "q", Required: true}, {Name: "limit"}}, Response: objectResponse},
+	{Method: http.MethodGet, Path: "/api/pantry/photo/{id}", Tag: "Pantry", Summary: "A pantry item's photo", Query: []openapi.Param{{Name: "access_token", Description: "Token for image tags that cannot set headers"}}, Produces: "image/*"},
+	{Method: http.MethodPut, Path: "/api/pantry/photo/{id}", Tag: "Pantry", Summary: "Upload a pantry item's photo", Files: []string{"photo"}, Response: objectResponse},
+	{Method: http.MethodDelete, Path: "/api/pantry/photo/{id}", Tag: "Pantry", Summary: "Remove a pantry item's photo", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/pantry/notify/read", Tag: "Pantry", Summary: "Mark a pantry notification read", Request: MarkNotificationReadRequest{}, Response: messageResponse},
+	{Method: http.MethodDelete, Path: "/api/pantry/notify/delete", Tag: "Pantry", Summary: "Remove a pantry notification", Query: []openapi.Param{{Name: "notification_id", Required: true}}, Response: messageResponse},
+
+	// Shopping cart
+	{Method: http.MethodPost, Path: "/api/shopping-cart/add", Tag: "Shopping", Summary: "Add an item to the shopping list", Request: AddShoppingCartItemRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodPut, Path: "/api/shopping-cart/update", Tag: "Shopping", Summary: "Edit a shopping list item", Request: UpdateShoppingCartItemRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/shopping-cart/delete/{id}", Tag: "Shopping", Summary: "Remove a shopping list item", Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/list", Tag: "Shopping", Summary: "The group's shopping list", Query: []openapi.Param{{Name: "user_id"}, {Name: "shared"}, {Name: "list_id"}, {Name: "group_by", Description: "category to group items in aisle order"}}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-cart/{id}/purchase", Tag: "Shopping", Summary: "Mark an item purchased", Request: PurchaseShoppingCartItemRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-cart/checkout", Tag: "Shopping", Summary: "Purchase several items at once", Request: CheckoutShoppingCartRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/history", Tag: "Shopping", Summary: "Purchase history", Query: append([]openapi.Param{monthQuery}, cursorQuery...), Response: ShoppingCartResponse{}},
+	{Method: http.MethodPut, Path: "/api/shopping-cart/history/{id}", Tag: "Shopping", Summary: "Correct a purchase's price and its linked expense", Request: UpdatePurchaseRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-cart/scan", Tag: "Shopping", Summary: "Add an item by barcode", Request: ScanShoppingItemRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/suggestions", Tag: "Shopping", Summary: "Item name suggestions", Query: []openapi.Param{{Name: "q"}, {Name: "limit"}}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/archive", Tag: "Shopping", Summary: "Purchased and removed items", Query: []openapi.Param{{Name: "purchased", Description: "true or false"}, {Name: "list_id"}}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-cart/readd", Tag: "Shopping", Summary: "Put an archived item back on the list", Request: ReaddArchivedItemRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/events", Tag: "Shopping", Summary: "Server-sent events of shopping list changes", Query: []openapi.Param{{Name: "access_token", Description: "Token for clients that cannot set headers"}}, Produces: "text/event-stream"},
+	{Method: http.MethodGet, Path: "/api/shopping/items/{name}/price-history", Tag: "Shopping", Summary: "Prices the group paid for an item", Query: []openapi.Param{{Name: "months", Description: "Defaults to 12"}}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-cart/activity", Tag: "Shopping", Summary: "Recent shopping list activity", Query: append(append([]openapi.Param{}, groupQuery...), cursorQuery...), Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-cart/activity/read", Tag: "Shopping", Summary: "Mark shopping list activity read", Request: MarkActivityReadRequest{}, Response: messageResponse},
+
+	// Shopping lists
+	{Method: http.MethodGet, Path: "/api/shopping-lists", Tag: "Shopping lists", Summary: "The group's shopping lists with item counts", Response: ShoppingCartResponse{}},
+	{Method: http.MethodPost, Path: "/api/shopping-lists/create", Tag: "Shopping lists", Summary: "Create a shopping list", Request: CreateShoppingListRequest{}, Response: ShoppingCartResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPut, Path: "/api/shopping-lists/update", Tag: "Shopping lists", Summary: "Rename a shopping list", Request: UpdateShoppingListRequest{}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodDelete, Path: "/api/shopping-lists/delete", Tag: "Shopping lists", Summary: "Remove a shopping list", Query: []openapi.Param{{Name: "list_id", Required: true}}, Response: ShoppingCartResponse{}},
+	{Method: http.MethodGet, Path: "/api/shopping-lists/export", Tag: "Shopping lists", Summary: "A shopping list as text", Query: []openapi.Param{{Name: "list_id"}, {Name: "format", Description: "text or markdown"}}, Produces: "text/plain"},
+	{Method: http.MethodPost, Path: "/api/shopping-lists/share", Tag: "Shopping lists", Summary: "Create a public read-only link to a list", Request: ShareShoppingListRequest{}, Response: ShoppingCartResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/public/shopping-lists/{token}", Tag: "Public", Summary: "The shared items of a shared shopping list", Public: true, Query: []openapi.Param{{Name: "format", Description: "text or markdown"}}, Produces: "text/plain"},
+	{Method: http.MethodGet, Path: "/api/public/calendar/{user_id}.ics", Tag: "Public", Summary: "A member's iCalendar feed", Public: true, Query: []openapi.Param{{Name: "signature", Required: true}}, Produces: "text/calendar"},
+
+	// Expenses
+	{Method: http.MethodGet, Path: "/api/expenses", Tag: "Expenses", Summary: "The group's expenses", Query: []openapi.Param{{Name: "paid_by"}, {Name: "user_id"}, {Name: "source"}, {Name: "disputed", Description: "true or false"}, monthQuery, {Name: "limit"}}, Response: []ExpenseDetails{}},
+	{Method: http.MethodPost, Path: "/api/expenses", Tag: "Expenses", Summary: "Record an expense", Request: CreateExpenseRequest{}, Response: models.Expense{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/expenses/balances", Tag: "Expenses", Summary: "Each member's balance", Response: []models.MemberBalance{}},
+	{Method: http.MethodGet, Path: "/api/expenses/report", Tag: "Expenses", Summary: "Monthly spending report", Query: []openapi.Param{monthQuery, {Name: "format", Description: "json or csv"}}, Response: models.ExpenseReport{}},
+	{Method: http.MethodPost, Path: "/api/expenses/splitwise/import", Tag: "Expenses", Summary: "Import a Splitwise group's expenses and payments (admins only)", Query: []openapi.Param{{Name: "dry_run", Description: "true to only report what would be imported"}}, Request: SplitwiseRequest{}, Files: []string{"file"}, Response: SplitwiseImportResponse{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/expenses/splitwise/settlements", Tag: "Expenses", Summary: "Record the group's settlements as Splitwise payments (admins only)", Query: []openapi.Param{{Name: "dry_run", Description: "true to only report what would be sent"}}, Request: SplitwiseRequest{}, Response: SplitwiseExportResponse{}},
+	{Method: http.MethodGet, Path: "/api/expenses/{id}", Tag: "Expenses", Summary: "An expense with its receipt link", Response: ExpenseDetails{}},
+	{Method: http.MethodPut, Path: "/api/expenses/{id}", Tag: "Expenses", Summary: "Correct an expense", Request: UpdateExpenseRequest{}, Response: ExpenseDetails{}},
+	{Method: http.MethodPut, Path: "/api/expenses/{id}/receipt", Tag: "Expenses", Summary: "Upload a receipt image", Files: []string{"receipt"}, Response: ExpenseDetails{}},
+	{Method: http.MethodDelete, Path: "/api/expenses/{id}/receipt", Tag: "Expenses", Summary: "Remove the receipt", Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/api/expenses/{id}/dispute", Tag: "Expenses", Summary: "Dispute an expense", Request: ExpenseDisputeRequest{}, Response: ExpenseDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodPost, Path: "/api/expenses/{id}/dispute/comments", Tag: "Expenses", Summary: "Comment on a dispute", Request: ExpenseDisputeRequest{}, Response: ExpenseDetails{}},
+	{Method: http.MethodPost, Path: "/api/expenses/{id}/dispute/resolve", Tag: "Expenses", Summary: "Resolve a dispute", Request: ExpenseDisputeRequest{}, Response: ExpenseDetails{}},
+	{Method: http.MethodGet, Path: "/api/expenses/receipts/{id}", Tag: "Expenses", Summary: "A receipt image, from a signed link", Public: true, Produces: "image/*"},
+	{Method: http.MethodGet, Path: "/api/bills/recurring", Tag: "Expenses", Summary: "The group's recurring bills", Response: []models.RecurringBill{}},
+	{Method: http.MethodPost, Path: "/api/bills/recurring", Tag: "Expenses", Summary: "Set up a recurring bill", Request: CreateRecurringBillRequest{}, Response: models.RecurringBill{}, Status: http.StatusCreated},
+	{Method: http.MethodDelete, Path: "/api/bills/recurring/delete", Tag: "Expenses", Summary: "Stop a recurring bill", Query: []openapi.Param{{Name: "bill_id", Required: true}}, Response: messageResponse},
+	{Method: http.MethodGet, Path: "/api/rent", Tag: "Rent", Summary: "The rent in effect today", Response: models.RentConfig{}},
+	{Method: http.MethodPut, Path: "/api/rent", Tag: "Rent", Summary: "Record a new rent version (admins only)", Request: UpdateRentConfigRequest{}, Response: models.RentConfig{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/rent/history", Tag: "Rent", Summary: "Every rent version", Response: []models.RentConfig{}},
+	{Method: http.MethodGet, Path: "/api/meters/readings", Tag: "Meters", Summary: "The group's meter readings", Query: []openapi.Param{{Name: "meter"}, {Name: "username"}, {Name: "from", Description: "YYYY-MM-DD"}, {Name: "to", Description: "YYYY-MM-DD"}}, Response: []MeterReadingDetails{}},
+	{Method: http.MethodPost, Path: "/api/meters/readings", Tag: "Meters", Summary: "Record a meter reading", Request: CreateMeterReadingRequest{}, Response: MeterReadingDetails{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/meters/readings/{id}", Tag: "Meters", Summary: "A meter reading", Response: MeterReadingDetails{}},
+	{Method: http.MethodDelete, Path: "/api/meters/readings/{id}", Tag: "Meters", Summary: "Remove a meter reading", Status: http.StatusNoContent},
+	{Method: http.MethodPut, Path: "/api/meters/readings/{id}/photo", Tag: "Meters", Summary: "Upload a photo of the meter", Files: []string{"photo"}, Response: MeterReadingDetails{}},
+	{Method: http.MethodDelete, Path: "/api/meters/readings/{id}/photo", Tag: "Meters", Summary: "Remove the meter photo", Status: http.StatusNoContent},
+	{Method: http.MethodGet, Path: "/api/meters/photos/{id}", Tag: "Meters", Summary: "A meter photo, from a signed link", Public: true, Produces: "image/*"},
+	{Method: http.MethodGet, Path: "/api/meters/consumption", Tag: "Meters", Summary: "Consumption between readings", Query: []openapi.Param{{Name: "meter", Required: true}, {Name: "from", Description: "YYYY-MM-DD"}, {Name: "to", Description: "YYYY-MM-DD"}}, Response: MeterConsumptionResponse{}},
+	{Method: http.MethodGet, Path: "/api/settlements", Tag: "Expenses", Summary: "Recorded repayments", Response: []models.Settlement{}},
+	{Method: http.MethodPost, Path: "/api/settlements", Tag: "Expenses", Summary: "Record a repayment", Request: CreateSettlementRequest{}, Response: models.Settlement{}, Status: http.StatusCreated},
+	{Method: http.MethodGet, Path: "/api/settlements/history", Tag: "Expenses", Summary: "Repayments between two members", Query: []openapi.Param{{Name: "from", Description: "Username, defaults to the requesting user"}, {Name: "to", Description: "Username", Required: true}}, Response: models.PaymentHistory{}},
+}
@@ -5,11 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"cribb-backend/apierror"
 	"cribb-backend/config"
+	"cribb-backend/jobs"
 	"cribb-backend/middleware"
 	"cribb-backend/models"
 
@@ -140,12 +143,21 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 			return fmt.Errorf("failed to create user: %v", err)
 		}
 
-		// Update group with the actual user ID
+		event := models.CreateScoreEvent(newUser.ID, groupID, models.ScoreEventInitial, newUser.Score, newUser.Score, "Starting points")
+		if err := jobs.RecordScoreEvent(sc, event); err != nil {
+			return fmt.Errorf("failed to record score event: %v", err)
+		}
+
+		// Update group with the actual user ID; the creator of a new group becomes its admin
+		groupPush := bson.M{"members": newUser.ID}
+		if req.Group != "" {
+			groupPush["admins"] = newUser.ID
+		}
 		_, err = config.DB.Collection("groups").UpdateOne(
 			sc,
 			bson.M{"_id": groupID},
 			bson.M{
-				"$push": bson.M{"members": newUser.ID},
+				"$push": groupPush,
 			},
 		)
 		if err != nil {
@@ -189,7 +201,7 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		session.AbortTransaction(context.Background())
 		if mongo.IsDuplicateKeyError(err) {
-			http.Error(w, "Username, phone number, or group name already exists", http.StatusConflict)
+			writeError(w, r, apierror.CodeDuplicateItem, "Username, phone number, or group name already exists")
 			return
 		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -232,6 +244,8 @@ type UserData struct {
 	Score      int    `json:"score"`
 	GroupCode  string `json:"groupCode,omitempty"`
 	GroupName  string `json:"groupName,omitempty"`
+
+	Streak *models.StreakStats `json:"streak,omitempty"`
 }
 
 type LoginResponse struct {
@@ -353,6 +367,78 @@ func GetUserProfileHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Return response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(userProfile(user))
+}
+
+// PatchUserProfileHandler changes only the profile fields a JSON Merge Patch names. Null clears the
+// last name; the first name, phone and room number cannot be cleared.
+func PatchUserProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r, "firstName", "lastName", "phone", "roomNo")
+	if !ok {
+		return
+	}
+	if err := patch.Require("firstName", "phone", "roomNo"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profile := userProfile(user)
+	if err := patch.Apply(&profile); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	profile.FirstName = strings.TrimSpace(profile.FirstName)
+	profile.LastName = strings.TrimSpace(profile.LastName)
+	profile.Phone = strings.TrimSpace(profile.Phone)
+	profile.RoomNumber = strings.TrimSpace(profile.RoomNumber)
+	if profile.FirstName == "" || profile.Phone == "" || profile.RoomNumber == "" {
+		http.Error(w, "First name, phone and room number cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	updateFields := bson.M{"updated_at": time.Now()}
+	if patch.Has("firstName") || patch.Has("lastName") {
+		user.Name = strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+		updateFields["name"] = user.Name
+	}
+	if patch.Has("phone") {
+		user.PhoneNumber = profile.Phone
+		updateFields["phone_number"] = user.PhoneNumber
+	}
+	if patch.Has("roomNo") {
+		user.RoomNumber = profile.RoomNumber
+		updateFields["room_number"] = user.RoomNumber
+	}
+
+	_, err := config.DB.Collection("users").UpdateOne(
+		context.Background(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": updateFields},
+	)
+	if err != nil {
+		log.Printf("Failed to update profile of user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to update profile", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(userProfile(user))
+}
+
+// userProfile returns the profile of the user as the app shows it
+func userProfile(user models.User) UserData {
 	// Split name into first and last name
 	nameParts := strings.Split(user.Name, " ")
 	firstName := nameParts[0]
@@ -361,8 +447,7 @@ func GetUserProfileHandler(w http.ResponseWriter, r *http.Request) {
 		lastName = strings.Join(nameParts[1:], " ")
 	}
 
-	// Prepare response with group name included
-	response := UserData{
+	return UserData{
 		ID:         user.ID.Hex(),
 		Email:      user.Username,
 		FirstName:  firstName,
@@ -372,9 +457,6 @@ func GetUserProfileHandler(w http.ResponseWriter, r *http.Request) {
 		Score:      user.Score,
 		GroupCode:  user.GroupCode,
 		GroupName:  user.Group, // Add the existing group name field
+		Streak:     &user.Streak,
 	}
-
-	// Return response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
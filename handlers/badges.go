@@ -0,0 +1,137 @@
+// handlers/badges.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EarnedBadgeResponse is a badge a user has earned along with when it was awarded
+type EarnedBadgeResponse struct {
+	models.BadgeDefinition
+	AwardedAt time.Time `json:"awarded_at"`
+}
+
+// GetEarnedBadgesHandler lists the badges earned by the requesting user or by a member of their group
+func GetEarnedBadgesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	target := requester
+	if username := r.URL.Query().Get("username"); username != "" && username != requester.Username {
+		err := config.DB.Collection("users").FindOne(
+			context.Background(),
+			bson.M{"username": username},
+		).Decode(&target)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				http.Error(w, "User not found", http.StatusNotFound)
+			} else {
+				http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+			}
+			return
+		}
+		if target.GroupID != requester.GroupID {
+			http.Error(w, "User is not a member of this group", http.StatusForbidden)
+			return
+		}
+	}
+
+	cursor, err := config.DB.Collection("user_badges").Find(
+		context.Background(),
+		bson.M{"user_id": target.ID},
+		options.Find().SetSort(bson.D{{Key: "awarded_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch badges", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var awarded []models.UserBadge
+	if err := cursor.All(context.Background(), &awarded); err != nil {
+		http.Error(w, "Failed to decode badges", http.StatusInternalServerError)
+		return
+	}
+
+	badges := make([]EarnedBadgeResponse, 0, len(awarded))
+	for _, userBadge := range awarded {
+		definition, found := models.FindBadgeDefinition(userBadge.BadgeKey)
+		if !found {
+			continue // Badge has been retired
+		}
+		badges = append(badges, EarnedBadgeResponse{
+			BadgeDefinition: definition,
+			AwardedAt:       userBadge.AwardedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(badges)
+}
+
+// GetAvailableBadgesHandler lists every badge that can be earned and whether the requesting user has it
+func GetAvailableBadgesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("user_badges").Find(
+		context.Background(),
+		bson.M{"user_id": user.ID},
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch badges", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var awarded []models.UserBadge
+	if err := cursor.All(context.Background(), &awarded); err != nil {
+		http.Error(w, "Failed to decode badges", http.StatusInternalServerError)
+		return
+	}
+
+	earned := make(map[string]bool, len(awarded))
+	for _, userBadge := range awarded {
+		earned[userBadge.BadgeKey] = true
+	}
+
+	type availableBadge struct {
+		models.BadgeDefinition
+		Earned bool `json:"earned"`
+	}
+
+	badges := make([]availableBadge, 0, len(models.BadgeDefinitions))
+	for _, definition := range models.BadgeDefinitions {
+		badges = append(badges, availableBadge{
+			BadgeDefinition: definition,
+			Earned:          earned[definition.Key],
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(badges)
+}
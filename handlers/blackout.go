@@ -0,0 +1,181 @@
+// handlers/blackout.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// isValidBlackoutPolicy checks a blackout policy supplied by the client
+func isValidBlackoutPolicy(policy string) bool {
+	switch models.BlackoutPolicy(policy) {
+	case models.BlackoutPolicyPush, models.BlackoutPolicySkip:
+		return true
+	}
+	return false
+}
+
+// GetBlackoutDatesHandler lists the blackout dates of the requesting user's group
+func GetBlackoutDatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+
+	// Past blackouts are hidden unless explicitly requested
+	if r.URL.Query().Get("include_past") != "true" {
+		filter["end_date"] = bson.M{"$gte": time.Now().AddDate(0, 0, -1)}
+	}
+
+	cursor, err := config.DB.Collection("blackout_dates").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "start_date", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch blackout dates", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	blackouts := make([]models.BlackoutDate, 0)
+	if err = cursor.All(context.Background(), &blackouts); err != nil {
+		http.Error(w, "Failed to decode blackout dates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(blackouts)
+}
+
+// CreateBlackoutRequest defines the request structure for blocking out dates
+type CreateBlackoutRequest struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Reason    string    `json:"reason"`
+}
+
+// CreateBlackoutDateHandler lets a group admin add a blackout range for the group's recurring chores
+func CreateBlackoutDateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateBlackoutRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.StartDate.IsZero() || request.EndDate.IsZero() {
+		http.Error(w, "Start date and end date are required", http.StatusBadRequest)
+		return
+	}
+
+	if request.EndDate.Before(request.StartDate) {
+		http.Error(w, "End date cannot be before start date", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can manage blackout dates", http.StatusForbidden)
+		return
+	}
+
+	blackout := models.CreateBlackoutDate(group.ID, user.ID, request.StartDate, request.EndDate, request.Reason)
+
+	result, err := config.DB.Collection("blackout_dates").InsertOne(context.Background(), blackout)
+	if err != nil {
+		log.Printf("Blackout date creation error: %v", err)
+		http.Error(w, "Failed to create blackout date", http.StatusInternalServerError)
+		return
+	}
+	blackout.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(blackout)
+}
+
+// DeleteBlackoutDateHandler lets a group admin remove a blackout range
+func DeleteBlackoutDateHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	blackoutIDStr := r.URL.Query().Get("blackout_id")
+	if blackoutIDStr == "" {
+		http.Error(w, "Blackout ID is required", http.StatusBadRequest)
+		return
+	}
+
+	blackoutID, err := primitive.ObjectIDFromHex(blackoutIDStr)
+	if err != nil {
+		http.Error(w, "Invalid blackout ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can manage blackout dates", http.StatusForbidden)
+		return
+	}
+
+	result, err := config.DB.Collection("blackout_dates").DeleteOne(
+		context.Background(),
+		bson.M{"_id": blackoutID, "group_id": group.ID},
+	)
+	if err != nil {
+		log.Printf("Blackout date deletion error: %v", err)
+		http.Error(w, "Failed to delete blackout date", http.StatusInternalServerError)
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		http.Error(w, "Blackout date not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Blackout date deleted successfully",
+	})
+}
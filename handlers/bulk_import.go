@@ -0,0 +1,435 @@
+// handlers/bulk_import.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportResponse reports the outcome of a bulk import, or what it would do on a dry run
+type ImportResponse struct {
+	DryRun          bool                 `json:"dry_run"`
+	Valid           bool                 `json:"valid"` // No row has errors, so the import can be applied
+	Rows            int                  `json:"rows"`
+	Chores          int                  `json:"chores"`
+	RecurringChores int                  `json:"recurring_chores"`
+	PantryItems     int                  `json:"pantry_items"`
+	Errors          []models.ImportError `json:"errors"`
+}
+
+// groupImport is what an import adds to a group once every row is resolved
+type groupImport struct {
+	chores          []*models.Chore
+	recurringChores []*models.RecurringChore
+	firstInstances  []*models.Chore
+	pantryItems     []*models.PantryItem
+}
+
+// ImportGroupDataHandler creates chores, recurring chores and pantry items in bulk from a CSV or
+// JSON file, sent as the "file" field of a multipart form or as the request body. Either every
+// row is applied in one transaction or, when any row has errors, none are. Only group admins can
+// import.
+// Path format: /api/groups/{id}/import
+// Query: ?dry_run=true validates the file and reports row errors without writing anything;
+// ?format=csv|json overrides the format taken from the file name or Content-Type
+func ImportGroupDataHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/import"))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	if requester.GroupID != groupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	group, ok := getGroupByID(w, groupID)
+	if !ok {
+		return
+	}
+	if !group.IsAdmin(requester.ID) {
+		http.Error(w, "Only group admins can import chores and pantry items", http.StatusForbidden)
+		return
+	}
+
+	data, format, ok := readImportFile(w, r)
+	if !ok {
+		return
+	}
+	rows, rowErrors, err := models.ParseImport(data, format)
+	if err != nil {
+		http.Error(w, "Invalid import file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	for i, row := range rows {
+		rowErrors = append(rowErrors, row.Validate(i+1)...)
+	}
+	invalid := make(map[int]bool, len(rowErrors))
+	for _, rowError := range rowErrors {
+		invalid[rowError.Row] = true
+	}
+
+	ctx := context.Background()
+	plan, resolveErrors, err := resolveGroupImport(ctx, group, requester, rows, invalid)
+	if err != nil {
+		log.Printf("Failed to resolve import for group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to check import", http.StatusInternalServerError)
+		return
+	}
+	rowErrors = append(rowErrors, resolveErrors...)
+
+	response := ImportResponse{
+		DryRun:          dryRun,
+		Valid:           len(rowErrors) == 0,
+		Rows:            len(rows),
+		Chores:          len(plan.chores),
+		RecurringChores: len(plan.recurringChores),
+		PantryItems:     len(plan.pantryItems),
+		Errors:          rowErrors,
+	}
+	if response.Errors == nil {
+		response.Errors = []models.ImportError{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if dryRun {
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if !response.Valid {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	if err := applyGroupImport(ctx, plan, requester); err != nil {
+		log.Printf("Failed to import into group %s: %v", group.ID.Hex(), err)
+		w.Header().Del("Content-Type")
+		http.Error(w, "Failed to apply import", http.StatusInternalServerError)
+		return
+	}
+
+	// Members hear about their new chores only once the import is committed
+	for _, chore := range append(plan.chores, plan.firstInstances...) {
+		if _, err := config.DB.Collection("notifications").InsertOne(ctx, models.ChoreAssignedNotification(chore)); err != nil {
+			log.Printf("Failed to notify member of imported chore %s: %v", chore.ID.Hex(), err)
+		}
+		webhooks.ChoreAssigned(ctx, *chore)
+	}
+	for _, item := range plan.pantryItems {
+		UpdatePantryHistoryForAdd(group.ID, item.ID, item.Name, requester.ID, requester.Name, item.Quantity)
+		if item.IsExpiringSoon(group.Settings.ExpirationAlertWindow()) {
+			notification := models.CreatePantryNotification(
+				group.ID,
+				item.ID,
+				item.Name,
+				models.NotificationTypeExpiringSoon,
+				fmt.Sprintf("Item will expire in %d days or less", group.Settings.ExpirationAlertWindow()),
+			)
+			if _, err := config.DB.Collection("pantry_notifications").InsertOne(ctx, notification); err != nil {
+				log.Printf("Failed to create expiration notification: %v", err)
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// readImportFile reads the uploaded file and works out its format.
+// On failure it writes the error response and returns false.
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, models.ImportFormat, bool) {
+	var data []byte
+	var name, contentType string
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		// Leave room for the multipart headers around the file
+		r.Body = http.MaxBytesReader(w, r.Body, models.MaxImportSize+64<<10)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "A CSV or JSON file under 1 MB is required in the \"file\" field", http.StatusBadRequest)
+			return nil, "", false
+		}
+		defer file.Close()
+		if data, err = io.ReadAll(file); err != nil {
+			http.Error(w, "Failed to read import file", http.StatusBadRequest)
+			return nil, "", false
+		}
+		name, contentType = header.Filename, header.Header.Get("Content-Type")
+	} else {
+		var err error
+		if data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, models.MaxImportSize)); err != nil {
+			http.Error(w, "Import files must be 1 MB or smaller", http.StatusRequestEntityTooLarge)
+			return nil, "", false
+		}
+		contentType = r.Header.Get("Content-Type")
+	}
+	if len(data) > models.MaxImportSize {
+		http.Error(w, "Import files must be 1 MB or smaller", http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+
+	format := models.ImportFormat(strings.ToLower(r.URL.Query().Get("format")))
+	if format == "" {
+		format = importFormatFor(name, contentType)
+	}
+	if format != models.ImportFormatCSV && format != models.ImportFormatJSON {
+		http.Error(w, "Import files must be CSV or JSON", http.StatusUnsupportedMediaType)
+		return nil, "", false
+	}
+	return data, format, true
+}
+
+// importFormatFor picks the format of an import from its file name, then its Content-Type
+func importFormatFor(name, contentType string) models.ImportFormat {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return models.ImportFormatCSV
+	case ".json":
+		return models.ImportFormatJSON
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return models.ImportFormatCSV
+	case "application/json":
+		return models.ImportFormatJSON
+	}
+	return ""
+}
+
+// resolveGroupImport looks up the members and categories the rows name and builds what the
+// import adds. Rows marked invalid by earlier checks are still looked up, so every error is
+// reported at once, but are left out of the plan along with any row failing here.
+func resolveGroupImport(ctx context.Context, group models.Group, requester models.User, rows []models.ImportRow, invalid map[int]bool) (groupImport, []models.ImportError, error) {
+	var plan groupImport
+	var rowErrors []models.ImportError
+
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		return plan, nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return plan, nil, err
+	}
+	members := make(map[string]primitive.ObjectID, len(users))
+	everyone := make([]primitive.ObjectID, 0, len(users))
+	for _, user := range users {
+		members[user.Username] = user.ID
+		everyone = append(everyone, user.ID)
+	}
+
+	// Predefined categories come first, so they win over custom categories of the same name
+	cursor, err = config.DB.Collection("pantry_categories").Find(
+		ctx,
+		bson.M{
+			"is_active": true,
+			"$or": []bson.M{
+				{"type": models.CategoryTypePredefined},
+				{"type": models.CategoryTypeCustom, "group_id": group.ID},
+			},
+		},
+		options.Find().SetSort(bson.D{{Key: "type", Value: 1}, {Key: "sort_order", Value: 1}}),
+	)
+	if err != nil {
+		return plan, nil, err
+	}
+	var categories []models.PantryCategory
+	if err := cursor.All(ctx, &categories); err != nil {
+		return plan, nil, err
+	}
+	categoryIDs := make(map[string]primitive.ObjectID, 2*len(categories))
+	for _, category := range categories {
+		categoryIDs[category.ID.Hex()] = category.ID
+		if _, ok := categoryIDs[strings.ToLower(category.Name)]; !ok {
+			categoryIDs[strings.ToLower(category.Name)] = category.ID
+		}
+	}
+
+	// Imported items are shared, so they cannot repeat a shared item already in the pantry
+	cursor, err = config.DB.Collection("pantry_items").Find(
+		ctx,
+		bson.M{"group_id": group.ID, "owner_id": nil},
+		options.Find().SetProjection(bson.M{"name": 1, "category_id": 1}),
+	)
+	if err != nil {
+		return plan, nil, err
+	}
+	var existing []models.PantryItem
+	if err := cursor.All(ctx, &existing); err != nil {
+		return plan, nil, err
+	}
+	stocked := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		stocked[pantryItemKey(item.Name, item.CategoryID)] = true
+	}
+
+	now := time.Now()
+	for i, row := range rows {
+		n := i + 1
+		fail := func(field, message string) {
+			rowErrors = append(rowErrors, models.ImportError{Row: n, Field: field, Message: message})
+			invalid[n] = true
+		}
+		points := group.Settings.Scoring.ChorePoints(row.Points)
+
+		switch row.Type {
+		case models.ImportRowChore:
+			assignee, ok := members[row.AssignedTo]
+			if row.AssignedTo != "" && !ok {
+				fail("assigned_to", "no member of the group is called "+row.AssignedTo)
+			}
+			dueDate, err := models.ParseImportDate(row.DueDate, true)
+			if invalid[n] || err != nil {
+				continue
+			}
+			plan.chores = append(plan.chores, models.CreateChore(
+				strings.TrimSpace(row.Title), row.Description, group.ID, assignee, dueDate, points,
+			))
+
+		case models.ImportRowRecurringChore:
+			rotation := everyone
+			if len(row.Members) > 0 {
+				rotation = make([]primitive.ObjectID, 0, len(row.Members))
+				for _, username := range row.Members {
+					id, ok := members[username]
+					if !ok {
+						fail("members", "no member of the group is called "+username)
+						continue
+					}
+					rotation = append(rotation, id)
+				}
+			}
+			if len(rotation) == 0 && len(row.Members) == 0 {
+				fail("members", "the group has no members to assign the chore to")
+			}
+			if invalid[n] {
+				continue
+			}
+
+			recurringChore := models.CreateRecurringChore(
+				strings.TrimSpace(row.Title), row.Description, group.ID, rotation, row.Frequency, points,
+			)
+			recurringChore.ID = primitive.NewObjectID()
+			recurringChore.NextAssignment = models.NextOccurrence(row.Frequency, now)
+			recurringChore.CreatedBy = requester.ID
+
+			var firstChore *models.Chore
+			if row.FirstDueDate != "" {
+				if dueDate, err := models.ParseImportDate(row.FirstDueDate, true); err == nil {
+					firstChore = models.CreateChoreFromRecurringWithBaseDate(recurringChore, dueDate)
+				}
+			}
+			if firstChore == nil {
+				firstChore = models.CreateChoreFromRecurring(recurringChore)
+			}
+			recurringChore.OccurrenceCount = 1
+			recurringChore.IsActive = !recurringChore.HasEnded(recurringChore.NextAssignment)
+
+			plan.recurringChores = append(plan.recurringChores, recurringChore)
+			plan.firstInstances = append(plan.firstInstances, firstChore)
+
+		case models.ImportRowPantryItem:
+			categoryID, ok := categoryIDs[strings.ToLower(row.Category)]
+			if row.Category != "" && !ok {
+				fail("category", "no category of the group is called "+row.Category)
+			}
+			name := strings.TrimSpace(row.Name)
+			if !ok || name == "" {
+				continue
+			}
+			key := pantryItemKey(name, categoryID)
+			if stocked[key] {
+				fail("name", name+" is already in the pantry under this category")
+			}
+			stocked[key] = true
+			if invalid[n] {
+				continue
+			}
+
+			var expirationDate time.Time
+			if row.ExpirationDate != "" {
+				if expirationDate, err = models.ParseImportDate(row.ExpirationDate, false); err != nil {
+					continue
+				}
+			}
+			item := models.CreatePantryItem(group.ID, name, row.Quantity, row.Unit, categoryID, expirationDate, requester.ID)
+			item.MinQuantity = row.MinQuantity
+			item.Notes = strings.TrimSpace(row.Notes)
+			plan.pantryItems = append(plan.pantryItems, item)
+		}
+	}
+
+	return plan, rowErrors, nil
+}
+
+// pantryItemKey identifies a pantry item by its name, ignoring case, within a category
+func pantryItemKey(name string, categoryID primitive.ObjectID) string {
+	return strings.ToLower(strings.TrimSpace(name)) + "|" + categoryID.Hex()
+}
+
+// applyGroupImport inserts everything the import adds in a single transaction
+func applyGroupImport(ctx context.Context, plan groupImport, requester models.User) error {
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		for _, recurringChore := range plan.recurringChores {
+			if _, err := config.DB.Collection("recurring_chores").InsertOne(sc, recurringChore); err != nil {
+				return nil, err
+			}
+		}
+		for _, chore := range append(plan.chores, plan.firstInstances...) {
+			result, err := config.DB.Collection("chores").InsertOne(sc, chore)
+			if err != nil {
+				return nil, err
+			}
+			chore.ID = result.InsertedID.(primitive.ObjectID)
+		}
+		for _, item := range plan.pantryItems {
+			result, err := config.DB.Collection("pantry_items").InsertOne(sc, item)
+			if err != nil {
+				return nil, err
+			}
+			item.ID = result.InsertedID.(primitive.ObjectID)
+			if err := recordPantryItemVersion(sc, nil, item, models.ActionTypeAdd, requester); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
@@ -0,0 +1,219 @@
+// handlers/calendar.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// publicCalendarPath is where members' calendar feeds are served without logging in
+const publicCalendarPath = "/api/public/calendar/"
+
+// calendarSignature signs a member's feed URL with the server secret and the member's calendar
+// key, so a new key revokes the old URL
+func calendarSignature(userID primitive.ObjectID, key string) string {
+	mac := hmac.New(sha256.New, config.JWTSecret)
+	mac.Write([]byte("calendar:" + userID.Hex() + ":" + key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// calendarURL returns the feed URL of the member with the calendar key
+func calendarURL(r *http.Request, userID primitive.ObjectID, key string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + publicCalendarPath + userID.Hex() + ".ics?signature=" + calendarSignature(userID, key)
+}
+
+// CalendarHandler returns the URL of the member's iCalendar feed of their chores, bills and house
+// events, which calendar apps can subscribe to, on GET. POST replaces the URL, so the old one stops
+// working.
+func CalendarHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	key := user.CalendarKey
+	if key == "" || r.Method == http.MethodPost {
+		newKey, err := models.NewCalendarKey()
+		if err != nil {
+			log.Printf("Failed to generate calendar key: %v", err)
+			http.Error(w, "Failed to create calendar link", http.StatusInternalServerError)
+			return
+		}
+		// Requests at the same time must not hand out URLs that stop working right away, so the
+		// key is only replaced if it is still the one loaded; otherwise the winner's key is used
+		filter := bson.M{"_id": user.ID, "calendar_key": bson.M{"$exists": false}}
+		if user.CalendarKey != "" {
+			filter["calendar_key"] = user.CalendarKey
+		}
+		users := config.DB.Collection("users")
+		result, err := users.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"calendar_key": newKey}})
+		if err == nil && result.ModifiedCount == 0 {
+			err = users.FindOne(context.Background(), bson.M{"_id": user.ID}).Decode(&user)
+			newKey = user.CalendarKey
+		}
+		if err != nil {
+			http.Error(w, "Failed to create calendar link", http.StatusInternalServerError)
+			return
+		}
+		key = newKey
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"url": calendarURL(r, user.ID, key)})
+}
+
+// PublicCalendarHandler serves a member's iCalendar feed to anyone holding its signed URL. It
+// requires no authentication. Path format: /api/public/calendar/{user_id}.ics?signature=
+func PublicCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, publicCalendarPath), ".ics"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := context.Background()
+	var user models.User
+	err = config.DB.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Failed to fetch calendar", http.StatusInternalServerError)
+		return
+	}
+	// Unknown members and bad signatures look the same, so feed URLs cannot be probed
+	signature := r.URL.Query().Get("signature")
+	if err != nil || user.CalendarKey == "" || !hmac.Equal([]byte(signature), []byte(calendarSignature(user.ID, user.CalendarKey))) {
+		http.Error(w, "Calendar not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	name, events, err := calendarEvents(ctx, user, now)
+	if err != nil {
+		log.Printf("Failed to build calendar for user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to build calendar", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="cribb.ics"`)
+	w.Write([]byte(models.RenderCalendar(name, events, now)))
+}
+
+// calendarEvents gathers the member's feed: chores assigned to them from CalendarFeedHistory ago,
+// the occurrences of recurring chores that will rotate to them, the due dates of bills they share
+// or pay and the house events they have not declined, up to CalendarFeedHorizon ahead. It returns
+// the feed's name with the events.
+func calendarEvents(ctx context.Context, user models.User, now time.Time) (string, []models.CalendarEvent, error) {
+	var events []models.CalendarEvent
+	until := now.Add(models.CalendarFeedHorizon)
+
+	cursor, err := config.DB.Collection("chores").Find(ctx, bson.M{
+		"assigned_to": user.ID,
+		"due_date":    bson.M{"$gte": now.Add(-models.CalendarFeedHistory), "$lte": until},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	var chores []models.Chore
+	if err := cursor.All(ctx, &chores); err != nil {
+		return "", nil, err
+	}
+	for _, chore := range chores {
+		events = append(events, models.ChoreCalendarEvent(chore))
+	}
+
+	var group models.Group
+	err = config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": user.GroupID}).Decode(&group)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "Cribb", events, nil // Members without a group only have their own chores
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	cursor, err = config.DB.Collection("recurring_chores").Find(ctx, bson.M{
+		"group_id":        group.ID,
+		"is_active":       true,
+		"member_rotation": user.ID,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	var recurringChores []models.RecurringChore
+	if err := cursor.All(ctx, &recurringChores); err != nil {
+		return "", nil, err
+	}
+	if len(recurringChores) > 0 {
+		blackouts, err := jobs.GetGroupBlackouts(ctx, group.ID, now)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, recurringChore := range recurringChores {
+			// A daily chore rotating through few members can take this many to cover the horizon
+			occurrences := recurringChore.PreviewOccurrences(100, now, blackouts)
+			events = append(events, models.RecurringChoreCalendarEvents(recurringChore, occurrences, user.ID, until)...)
+		}
+	}
+
+	cursor, err = config.DB.Collection("recurring_bills").Find(ctx, bson.M{"group_id": group.ID, "is_active": true})
+	if err != nil {
+		return "", nil, err
+	}
+	var bills []models.RecurringBill
+	if err := cursor.All(ctx, &bills); err != nil {
+		return "", nil, err
+	}
+	for _, bill := range bills {
+		if bill.Involves(user.ID, group.Members) {
+			events = append(events, bill.CalendarEvents(until)...)
+		}
+	}
+
+	cursor, err = config.DB.Collection("house_events").Find(ctx, bson.M{
+		"group_id":  group.ID,
+		"ends_at":   bson.M{"$gte": now.Add(-models.CalendarFeedHistory)},
+		"starts_at": bson.M{"$lte": until},
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	var houseEvents []models.HouseEvent
+	if err := cursor.All(ctx, &houseEvents); err != nil {
+		return "", nil, err
+	}
+	for _, houseEvent := range houseEvents {
+		if houseEvent.RSVP(user.ID) != models.RSVPNotGoing {
+			events = append(events, houseEvent.CalendarEvent())
+		}
+	}
+
+	return "Cribb: " + group.Name, events, nil
+}
@@ -0,0 +1,147 @@
+// handlers/challenges.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateChallengeRequest defines the request structure for starting a group challenge
+type CreateChallengeRequest struct {
+	Title       string               `json:"title"`
+	Description string               `json:"description"`
+	Reward      string               `json:"reward"`
+	Goal        models.ChallengeGoal `json:"goal"`
+	Target      int                  `json:"target"`     // Required for completion_count challenges
+	StartDate   time.Time            `json:"start_date"` // Defaults to now
+	EndDate     time.Time            `json:"end_date"`
+}
+
+// CreateChallengeHandler lets a group admin start a time-boxed challenge for the whole group
+func CreateChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateChallengeRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Title == "" || request.EndDate.IsZero() {
+		http.Error(w, "Title and end date are required", http.StatusBadRequest)
+		return
+	}
+
+	if !models.IsValidChallengeGoal(request.Goal) {
+		http.Error(w, "Invalid goal. Must be all_chores or completion_count", http.StatusBadRequest)
+		return
+	}
+
+	if request.Goal == models.ChallengeGoalCompletionCount && request.Target < 1 {
+		http.Error(w, "Completion count challenges need a target of at least 1", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if request.StartDate.IsZero() {
+		request.StartDate = now
+	}
+	if !request.EndDate.After(request.StartDate) || !request.EndDate.After(now) {
+		http.Error(w, "End date must be in the future and after the start date", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can create challenges", http.StatusForbidden)
+		return
+	}
+
+	challenge := models.CreateChallenge(group.ID, user.ID, request.Title, request.Description, request.Reward,
+		request.Goal, request.Target, request.StartDate, request.EndDate)
+	result, err := config.DB.Collection("challenges").InsertOne(context.Background(), challenge)
+	if err != nil {
+		log.Printf("Challenge creation error: %v", err)
+		http.Error(w, "Failed to create challenge", http.StatusInternalServerError)
+		return
+	}
+	challenge.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(challenge)
+}
+
+// GetChallengesHandler lists the challenges of the requesting user's group with up-to-date progress
+func GetChallengesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		switch models.ChallengeStatus(status) {
+		case models.ChallengeStatusActive, models.ChallengeStatusCompleted, models.ChallengeStatusFailed:
+			filter["status"] = status
+		default:
+			http.Error(w, "Invalid status. Must be active, completed or failed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx := context.Background()
+
+	// Refresh progress so members see the effect of their latest completions
+	if err := jobs.UpdateGroupChallenges(ctx, user.GroupID); err != nil {
+		log.Printf("Failed to update challenges for group %s: %v", user.GroupID.Hex(), err)
+	}
+
+	cursor, err := config.DB.Collection("challenges").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "end_date", Value: -1}}).SetLimit(100),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch challenges", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	challenges := make([]models.Challenge, 0)
+	if err := cursor.All(ctx, &challenges); err != nil {
+		http.Error(w, "Failed to decode challenges", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(challenges)
+}
@@ -0,0 +1,209 @@
+// handlers/chat.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// chatTypingLifetime is how long a typing record is kept after the member's last keystroke event
+const chatTypingLifetime = 30 * time.Second
+
+// ChatPage is a page of the group chat, newest first. NextBefore is passed as ?before= to fetch
+// older messages and is empty on the last page.
+type ChatPage struct {
+	Messages   []models.ChatMessage `json:"messages"`
+	NextBefore string               `json:"next_before,omitempty"`
+}
+
+// ChatHandler pages through the group chat, newest first, on GET (?limit=, ?before= a message
+// ID from next_before) and sends a message on POST, notifying the members it @mentions. New
+// messages and typing indicators are streamed over /api/groups/events.
+func ChatHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listChatMessages(w, r, user)
+	case http.MethodPost:
+		sendChatMessage(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listChatMessages returns a page of the group chat
+func listChatMessages(w http.ResponseWriter, r *http.Request, user models.User) {
+	query := r.URL.Query()
+	limit := models.DefaultMessagePageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > models.MaxMessagePageSize {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", models.MaxMessagePageSize), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if before := query.Get("before"); before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			http.Error(w, "Invalid before message ID", http.StatusBadRequest)
+			return
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	cursor, err := config.DB.Collection("chat_messages").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit+1)),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch messages", http.StatusInternalServerError)
+		return
+	}
+	messages := make([]models.ChatMessage, 0, limit+1)
+	if err := cursor.All(context.Background(), &messages); err != nil {
+		http.Error(w, "Failed to decode messages", http.StatusInternalServerError)
+		return
+	}
+
+	page := ChatPage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextBefore = messages[limit-1].ID.Hex()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// sendChatMessage posts a message to the group chat and notifies the members it mentions
+func sendChatMessage(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateMessageBody(request.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	mentionIDs := make([]primitive.ObjectID, 0)
+	if len(models.ParseMentions(request.Body)) > 0 {
+		cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": group.Members}})
+		if err != nil {
+			http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+			return
+		}
+		members := make([]models.User, 0, len(group.Members))
+		if err := cursor.All(ctx, &members); err != nil {
+			http.Error(w, "Failed to decode group members", http.StatusInternalServerError)
+			return
+		}
+		mentionIDs = models.MentionedMembers(request.Body, members, user.ID)
+	}
+
+	message := models.CreateChatMessage(group.ID, user.ID, request.Body, mentionIDs)
+	result, err := config.DB.Collection("chat_messages").InsertOne(ctx, message)
+	if err != nil {
+		log.Printf("Failed to send chat message: %v", err)
+		http.Error(w, "Failed to send message", http.StatusInternalServerError)
+		return
+	}
+	message.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(mentionIDs))
+	for _, memberID := range mentionIDs {
+		notifications = append(notifications, models.ChatMentionNotification(message, memberID, user.Name))
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// ChatMessageHandler handles DELETE /api/groups/chat/{id}, which removes a message from the group
+// chat. Only its sender and group admins can remove it.
+func ChatMessageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	messageID, err := primitive.ObjectIDFromHex(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/chat/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+	ctx := context.Background()
+	var message models.ChatMessage
+	err = config.DB.Collection("chat_messages").FindOne(ctx, bson.M{"_id": messageID, "group_id": group.ID}).Decode(&message)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Message not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch message", http.StatusInternalServerError)
+		}
+		return
+	}
+	if message.SenderID != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the sender and group admins can remove a message", http.StatusForbidden)
+		return
+	}
+
+	if _, err := config.DB.Collection("chat_messages").DeleteOne(ctx, bson.M{"_id": message.ID}); err != nil {
+		http.Error(w, "Failed to remove message", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recordChatTyping notes that the member is typing in the group chat. The change is streamed to
+// the group from every instance, so members connected elsewhere see the indicator too.
+func recordChatTyping(user models.User) {
+	now := time.Now()
+	_, err := config.DB.Collection("chat_typing").UpdateOne(
+		context.Background(),
+		bson.M{"group_id": user.GroupID, "user_id": user.ID},
+		bson.M{"$set": bson.M{"name": user.Name, "typing_at": now, "expires_at": now.Add(chatTypingLifetime)}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		log.Printf("Failed to record user %s typing: %v", user.ID.Hex(), err)
+	}
+}
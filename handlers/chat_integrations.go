@@ -0,0 +1,453 @@
+// handlers/chat_integrations.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/chatbot"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxSlashCommandSize is the largest slash command body read, in bytes
+const maxSlashCommandSize = 16 << 10
+
+// ChatIntegrationRequest defines the request structure for connecting a Slack workspace or
+// Discord server
+type ChatIntegrationRequest struct {
+	Platform    models.ChatPlatform `json:"platform"`
+	WorkspaceID string              `json:"workspace_id"`
+	WebhookURL  string              `json:"webhook_url"`  // Optional; the daily summary is posted here
+	SummaryTime string              `json:"summary_time"` // HH:MM, defaults to 08:00
+	TimeZone    string              `json:"time_zone"`    // Defaults to UTC
+}
+
+// UpdateChatIntegrationRequest defines the request structure for changing an integration; fields
+// left out are unchanged and an empty webhook URL stops the daily summary
+type UpdateChatIntegrationRequest struct {
+	WorkspaceID *string `json:"workspace_id"`
+	WebhookURL  *string `json:"webhook_url"`
+	SummaryTime *string `json:"summary_time"`
+	TimeZone    *string `json:"time_zone"`
+}
+
+// ChatLinkCodeResponse is a code the member sends from chat to link their chat account
+type ChatLinkCodeResponse struct {
+	Code      string    `json:"code"`
+	Command   string    `json:"command"` // What to send, e.g. /chore link 3F9A1C2B7D
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ChatIntegrationsHandler lists the group's chat integrations on GET and connects a Slack
+// workspace or Discord server on POST. Only group admins manage chat integrations.
+func ChatIntegrationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, group, ok := getAdminGroup(w, r, "manage chat integrations")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listChatIntegrations(w, group)
+	case http.MethodPost:
+		createChatIntegration(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listChatIntegrations returns the group's chat integrations, oldest first
+func listChatIntegrations(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("chat_integrations").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch chat integrations", http.StatusInternalServerError)
+		return
+	}
+	integrations := make([]models.ChatIntegration, 0)
+	if err := cursor.All(context.Background(), &integrations); err != nil {
+		http.Error(w, "Failed to decode chat integrations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(integrations)
+}
+
+// createChatIntegration connects the workspace to the group. A group has one integration per
+// platform, and a workspace is connected to one group.
+func createChatIntegration(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request ChatIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.SummaryTime == "" {
+		request.SummaryTime = models.DefaultChatSummaryTime
+	}
+	request.WebhookURL = strings.TrimSpace(request.WebhookURL)
+	if err := models.ValidateChatIntegration(request.Platform, request.WorkspaceID, request.WebhookURL, request.SummaryTime, request.TimeZone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	integration := models.CreateChatIntegration(group.ID, user.ID, request.Platform, request.WorkspaceID, request.WebhookURL, request.SummaryTime, request.TimeZone, time.Now())
+	result, err := config.DB.Collection("chat_integrations").InsertOne(context.Background(), integration)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeDuplicateItem, "The group already has a "+string(request.Platform)+" integration, or the workspace is connected to another group")
+			return
+		}
+		log.Printf("Failed to create chat integration: %v", err)
+		http.Error(w, "Failed to create chat integration", http.StatusInternalServerError)
+		return
+	}
+	integration.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(integration)
+}
+
+// ChatIntegrationHandler manages one of the group's chat integrations: PUT
+// /api/groups/chat-integrations/{id} changes it, DELETE removes it and POST
+// /api/groups/chat-integrations/{id}/summary posts today's summary now. Only group admins manage
+// chat integrations.
+func ChatIntegrationHandler(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := getAdminGroup(w, r, "manage chat integrations")
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/chat-integrations/"), "/"), "/")
+	integrationID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid chat integration ID", http.StatusBadRequest)
+		return
+	}
+	var integration models.ChatIntegration
+	err = config.DB.Collection("chat_integrations").FindOne(context.Background(), bson.M{"_id": integrationID, "group_id": group.ID}).Decode(&integration)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Chat integration not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch chat integration", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodPut:
+		updateChatIntegration(w, r, integration)
+	case action == "" && r.Method == http.MethodDelete:
+		if _, err := config.DB.Collection("chat_integrations").DeleteOne(context.Background(), bson.M{"_id": integration.ID}); err != nil {
+			log.Printf("Failed to delete chat integration %s: %v", integration.ID.Hex(), err)
+			http.Error(w, "Failed to delete chat integration", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "Chat integration deleted successfully"})
+	case action == "summary" && r.Method == http.MethodPost:
+		if integration.WebhookURL == "" {
+			http.Error(w, "Set a webhook URL to post summaries", http.StatusBadRequest)
+			return
+		}
+		if err := jobs.PostChatSummary(r.Context(), integration, time.Now()); err != nil {
+			log.Printf("Failed to post chat summary %s: %v", integration.ID.Hex(), err)
+			http.Error(w, "Failed to post the summary: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "Summary posted"})
+	case action == "" || action == "summary":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// updateChatIntegration changes the integration's workspace, webhook URL or summary time and
+// schedules its next summary again
+func updateChatIntegration(w http.ResponseWriter, r *http.Request, integration models.ChatIntegration) {
+	var request UpdateChatIntegrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.WorkspaceID != nil {
+		integration.WorkspaceID = strings.TrimSpace(*request.WorkspaceID)
+	}
+	if request.WebhookURL != nil {
+		integration.WebhookURL = strings.TrimSpace(*request.WebhookURL)
+	}
+	if request.SummaryTime != nil {
+		integration.SummaryTime = *request.SummaryTime
+	}
+	if request.TimeZone != nil {
+		integration.TimeZone = *request.TimeZone
+	}
+	if err := models.ValidateChatIntegration(integration.Platform, integration.WorkspaceID, integration.WebhookURL, integration.SummaryTime, integration.TimeZone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	integration.ScheduleSummary(now)
+	integration.UpdatedAt = now
+	update := bson.M{
+		"$set": bson.M{
+			"workspace_id": integration.WorkspaceID,
+			"webhook_url":  integration.WebhookURL,
+			"summary_time": integration.SummaryTime,
+			"time_zone":    integration.TimeZone,
+			"updated_at":   integration.UpdatedAt,
+		},
+	}
+	if integration.NextSummaryAt != nil {
+		update["$set"].(bson.M)["next_summary_at"] = integration.NextSummaryAt
+	} else {
+		update["$unset"] = bson.M{"next_summary_at": ""}
+	}
+	_, err := config.DB.Collection("chat_integrations").UpdateOne(context.Background(), bson.M{"_id": integration.ID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeDuplicateItem, "The workspace is connected to another group")
+			return
+		}
+		log.Printf("Failed to update chat integration %s: %v", integration.ID.Hex(), err)
+		http.Error(w, "Failed to update chat integration", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(integration)
+}
+
+// ChatLinkCodeHandler creates a code the requesting member sends from Slack or Discord, with
+// "/chore link CODE", to link their chat account so their slash commands act as them
+func ChatLinkCodeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	if user.GroupID.IsZero() {
+		http.Error(w, "Join a group to link a chat account", http.StatusBadRequest)
+		return
+	}
+
+	linkCode, code, err := models.CreateChatLinkCode(user.ID, user.GroupID, time.Now())
+	if err != nil {
+		log.Printf("Failed to create chat link code: %v", err)
+		http.Error(w, "Failed to create link code", http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Collection("chat_link_codes").InsertOne(context.Background(), linkCode); err != nil {
+		log.Printf("Failed to store chat link code for user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to create link code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ChatLinkCodeResponse{Code: code, Command: "/chore link " + code, ExpiresAt: linkCode.ExpiresAt})
+}
+
+// SlackCommandHandler answers the /chore and /shopping slash commands of the Cribb Slack app.
+// Commands must be signed with SLACK_SIGNING_SECRET and come from a workspace a group connected.
+func SlackCommandHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !chatbot.Enabled(models.ChatPlatformSlack) {
+		http.Error(w, "Slack is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSlashCommandSize))
+	if err != nil {
+		http.Error(w, "Request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	now := time.Now()
+	if err := chatbot.VerifySlack(r.Header, body, now); err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "Invalid form body", http.StatusBadRequest)
+		return
+	}
+	// Slack checks the endpoint's certificate with an empty command
+	if form.Get("ssl_check") == "1" {
+		return
+	}
+
+	slash := chatbot.ParseSlackCommand(form)
+	command := chatbot.ParseCommand(slash.Command, slash.Text)
+	text, inChannel := runChatCommand(r.Context(), models.ChatPlatformSlack, slash.TeamID, slash.UserID, command, now)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chatbot.NewSlackResponse(text, inChannel))
+}
+
+// DiscordInteractionHandler answers the /chore and /shopping slash commands of the Cribb Discord
+// app. Interactions must be signed with the key in DISCORD_PUBLIC_KEY and come from a server a
+// group connected.
+func DiscordInteractionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !chatbot.Enabled(models.ChatPlatformDiscord) {
+		http.Error(w, "Discord is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSlashCommandSize))
+	if err != nil {
+		http.Error(w, "Request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err := chatbot.VerifyDiscord(r.Header, body); err != nil {
+		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		return
+	}
+	var interaction chatbot.DiscordInteraction
+	if err := json.Unmarshal(body, &interaction); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	switch interaction.Type {
+	case chatbot.DiscordInteractionPing:
+		json.NewEncoder(w).Encode(chatbot.DiscordResponse{Type: chatbot.DiscordResponsePong})
+	case chatbot.DiscordInteractionApplicationCommand:
+		if interaction.GuildID == "" {
+			json.NewEncoder(w).Encode(chatbot.NewDiscordResponse("Send Cribb commands in your house server.", false))
+			return
+		}
+		text, inChannel := runChatCommand(r.Context(), models.ChatPlatformDiscord, interaction.GuildID, interaction.UserID(), interaction.Command(), time.Now())
+		json.NewEncoder(w).Encode(chatbot.NewDiscordResponse(text, inChannel))
+	default:
+		http.Error(w, "Unsupported interaction type", http.StatusBadRequest)
+	}
+}
+
+// runChatCommand carries out a slash command from the workspace, as the member the chat account
+// is linked to, and returns the answer and whether the whole channel sees it
+func runChatCommand(ctx context.Context, platform models.ChatPlatform, workspaceID, externalUserID string, command chatbot.Command, now time.Time) (string, bool) {
+	var integration models.ChatIntegration
+	err := config.DB.Collection("chat_integrations").FindOne(ctx, bson.M{"platform": platform, "workspace_id": workspaceID}).Decode(&integration)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Failed to fetch %s integration for %s: %v", platform, workspaceID, err)
+			return "Something went wrong. Please try again later.", false
+		}
+		return "This workspace is not connected to a Cribb group. A group admin can connect it in the Cribb app.", false
+	}
+
+	switch command.Action {
+	case chatbot.ActionHelp:
+		return chatbot.HelpText, false
+	case chatbot.ActionLink:
+		return linkChatAccount(ctx, integration, externalUserID, command.Code, now), false
+	}
+
+	userID, ok := integration.LinkedMember(externalUserID)
+	if !ok {
+		return chatbot.UnlinkedText, false
+	}
+	var member models.User
+	err = config.DB.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&member)
+	if err != nil || member.GroupID != integration.GroupID {
+		return "Your linked Cribb account is no longer in this group.", false
+	}
+
+	switch command.Action {
+	case chatbot.ActionListChores:
+		return choresTodaySpeech(ctx, member, now), false
+	case chatbot.ActionListShopping:
+		return shoppingListSpeech(ctx, member), false
+	}
+
+	var response InboundCommandResponse
+	var status int
+	if command.Action == chatbot.ActionCompleteChore {
+		response, status = completeInboundChore(ctx, member, command.Inbound)
+	} else {
+		response, status = addInboundShoppingItem(member, command.Inbound)
+	}
+	if status != http.StatusOK {
+		return response.Message, false
+	}
+	name := member.Name
+	if name == "" {
+		name = member.Username
+	}
+	return response.Message + " (" + name + ")", true
+}
+
+// linkChatAccount links the chat account to the member who created the code, replacing any
+// earlier link of the account or the member. Codes work once and only in their group's workspace.
+func linkChatAccount(ctx context.Context, integration models.ChatIntegration, externalUserID, code string, now time.Time) string {
+	if externalUserID == "" {
+		return "Your chat account could not be identified."
+	}
+	var linkCode models.ChatLinkCode
+	err := config.DB.Collection("chat_link_codes").FindOneAndDelete(ctx, bson.M{
+		"code_hash":  models.HashChatLinkCode(code),
+		"group_id":   integration.GroupID,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(&linkCode)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Failed to fetch chat link code: %v", err)
+			return "Something went wrong. Please try again later."
+		}
+		return "That code is invalid or expired. Get a new one in the Cribb app."
+	}
+
+	collection := config.DB.Collection("chat_integrations")
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": integration.ID}, bson.M{
+		"$pull": bson.M{"links": bson.M{"$or": bson.A{
+			bson.M{"external_user_id": externalUserID},
+			bson.M{"user_id": linkCode.UserID},
+		}}},
+	})
+	if err == nil {
+		_, err = collection.UpdateOne(ctx, bson.M{"_id": integration.ID}, bson.M{
+			"$push": bson.M{"links": models.ChatLink{ExternalUserID: externalUserID, UserID: linkCode.UserID, LinkedAt: now}},
+			"$set":  bson.M{"updated_at": now},
+		})
+	}
+	if err != nil {
+		log.Printf("Failed to link chat account to user %s: %v", linkCode.UserID.Hex(), err)
+		return "Something went wrong. Please try again later."
+	}
+	return "Your chat account is linked to Cribb. Try `/chore list`."
+}
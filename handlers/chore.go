@@ -4,7 +4,10 @@ package handlers
 import (
 	"context"
 	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/middleware"
 	"cribb-backend/models"
+	"cribb-backend/webhooks"
 	"encoding/json"
 	"errors"
 	"log"
@@ -16,6 +19,25 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// isValidCatchUpPolicy checks a catch-up policy supplied by the client
+func isValidCatchUpPolicy(policy string) bool {
+	switch models.CatchUpPolicy(policy) {
+	case models.CatchUpPolicyBackfill, models.CatchUpPolicySkipForward:
+		return true
+	}
+	return false
+}
+
+// CreateIndividualChoreRequest defines the request structure for creating a one-off chore
+type CreateIndividualChoreRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	GroupName   string    `json:"group_name"`
+	AssignedTo  string    `json:"assigned_to"` // Username of user to assign
+	DueDate     time.Time `json:"due_date"`
+	Points      int       `json:"points"`
+}
+
 // CreateIndividualChoreHandler creates a new individual chore
 func CreateIndividualChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -23,14 +45,7 @@ func CreateIndividualChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		GroupName   string    `json:"group_name"`
-		AssignedTo  string    `json:"assigned_to"` // Username of user to assign
-		DueDate     time.Time `json:"due_date"`
-		Points      int       `json:"points"`
-	}
+	var request CreateIndividualChoreRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -43,10 +58,6 @@ func CreateIndividualChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if request.Points < 1 {
-		request.Points = 1 // Default points if not provided or invalid
-	}
-
 	// Find the group
 	var group models.Group
 	err := config.DB.Collection("groups").FindOne(
@@ -63,6 +74,9 @@ func CreateIndividualChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Chores without valid points use the group's configured default
+	request.Points = group.Settings.Scoring.ChorePoints(request.Points)
+
 	// Find the user
 	var user models.User
 	err = config.DB.Collection("users").FindOne(
@@ -106,12 +120,31 @@ func CreateIndividualChoreHandler(w http.ResponseWriter, r *http.Request) {
 	// Set the inserted ID
 	chore.ID = result.InsertedID.(primitive.ObjectID)
 
+	if _, err := config.DB.Collection("notifications").InsertOne(context.Background(), models.ChoreAssignedNotification(chore)); err != nil {
+		log.Printf("Failed to notify member of chore %s: %v", chore.ID.Hex(), err)
+	}
+	webhooks.ChoreAssigned(context.Background(), *chore)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(chore)
 }
 
-// GetUserChoresHandler retrieves all chores assigned to a user
+// CreateRecurringChoreRequest defines the request structure for creating a recurring chore
+type CreateRecurringChoreRequest struct {
+	Title           string   `json:"title"`
+	Description     string   `json:"description"`
+	GroupName       string   `json:"group_name"`
+	Frequency       string   `json:"frequency"` // daily, weekly, biweekly, monthly
+	Points          int      `json:"points"`
+	MemberUsernames []string `json:"member_usernames"`
+	FirstDueDate    string   `json:"first_due_date"`
+	UntilDate       string   `json:"until_date"`      // Optional RFC3339 end of the run
+	MaxOccurrences  int      `json:"max_occurrences"` // Optional limit on instances, 0 for unlimited
+	BlackoutPolicy  string   `json:"blackout_policy"` // push (default) or skip
+	CatchUpPolicy   string   `json:"catch_up_policy"` // backfill or skip_forward; empty uses the server default
+}
+
 // CreateRecurringChoreHandler creates a new recurring chore
 func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -119,15 +152,7 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		Title           string   `json:"title"`
-		Description     string   `json:"description"`
-		GroupName       string   `json:"group_name"`
-		Frequency       string   `json:"frequency"` // daily, weekly, biweekly, monthly
-		Points          int      `json:"points"`
-		MemberUsernames []string `json:"member_usernames"`
-		FirstDueDate    string   `json:"first_due_date"`
-	}
+	var request CreateRecurringChoreRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -153,8 +178,33 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if request.Points < 1 {
-		request.Points = 1 // Default points if not provided or invalid
+	if request.BlackoutPolicy != "" && !isValidBlackoutPolicy(request.BlackoutPolicy) {
+		http.Error(w, "Invalid blackout policy. Must be push or skip", http.StatusBadRequest)
+		return
+	}
+
+	if request.CatchUpPolicy != "" && !isValidCatchUpPolicy(request.CatchUpPolicy) {
+		http.Error(w, "Invalid catch-up policy. Must be backfill or skip_forward", http.StatusBadRequest)
+		return
+	}
+
+	if request.MaxOccurrences < 0 {
+		http.Error(w, "Max occurrences cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	var untilDate *time.Time
+	if request.UntilDate != "" {
+		ts, err := time.Parse(time.RFC3339, request.UntilDate)
+		if err != nil {
+			http.Error(w, "Invalid until date format. Use RFC3339", http.StatusBadRequest)
+			return
+		}
+		if ts.Before(time.Now()) {
+			http.Error(w, "Until date must be in the future", http.StatusBadRequest)
+			return
+		}
+		untilDate = &ts
 	}
 
 	// Find the group
@@ -173,6 +223,9 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Chores without valid points use the group's configured default
+	request.Points = group.Settings.Scoring.ChorePoints(request.Points)
+
 	// Create member rotation array
 	var memberRotation []primitive.ObjectID
 
@@ -241,18 +294,17 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 	)
 
 	// Calculate next assignment time based on frequency
-	var nextAssignment time.Time
-	switch request.Frequency {
-	case "daily":
-		nextAssignment = time.Now().Add(24 * time.Hour)
-	case "weekly":
-		nextAssignment = time.Now().Add(7 * 24 * time.Hour)
-	case "biweekly":
-		nextAssignment = time.Now().Add(14 * 24 * time.Hour)
-	case "monthly":
-		nextAssignment = time.Now().AddDate(0, 1, 0)
-	}
-	recurringChore.NextAssignment = nextAssignment
+	recurringChore.NextAssignment = models.NextOccurrence(request.Frequency, time.Now())
+	recurringChore.UntilDate = untilDate
+	recurringChore.MaxOccurrences = request.MaxOccurrences
+	recurringChore.BlackoutPolicy = models.BlackoutPolicy(request.BlackoutPolicy)
+	recurringChore.CatchUpPolicy = models.CatchUpPolicy(request.CatchUpPolicy)
+
+	if userClaims, ok := middleware.GetUserFromContext(r.Context()); ok {
+		if creatorID, err := primitive.ObjectIDFromHex(userClaims.ID); err == nil {
+			recurringChore.CreatedBy = creatorID
+		}
+	}
 
 	// Insert the recurring chore
 	result, err := config.DB.Collection("recurring_chores").InsertOne(context.Background(), recurringChore)
@@ -278,21 +330,34 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		firstChore = models.CreateChoreFromRecurring(recurringChore)
 	}
 
-	// Persist the updated current_index after the first assignment
+	// Persist the rotation position and occurrence count after the first assignment.
+	// A single-occurrence chore is finished as soon as its first instance exists.
+	recurringChore.OccurrenceCount = 1
+	recurringChore.IsActive = !recurringChore.HasEnded(recurringChore.NextAssignment)
 	_, err = config.DB.Collection("recurring_chores").UpdateOne(
 		context.Background(),
 		bson.M{"_id": recurringChore.ID},
-		bson.M{"$set": bson.M{"current_index": recurringChore.CurrentIndex}},
+		bson.M{"$set": bson.M{
+			"current_index":    recurringChore.CurrentIndex,
+			"occurrence_count": recurringChore.OccurrenceCount,
+			"is_active":        recurringChore.IsActive,
+		}},
 	)
 	if err != nil {
 		log.Printf("Failed to update recurring chore current index: %v", err)
 	}
 
 	// Insert the first chore instance
-	_, err = config.DB.Collection("chores").InsertOne(context.Background(), firstChore)
+	firstResult, err := config.DB.Collection("chores").InsertOne(context.Background(), firstChore)
 	if err != nil {
 		log.Printf("Failed to create first chore instance: %v", err)
 		// Continue anyway since the recurring definition was created successfully
+	} else {
+		firstChore.ID = firstResult.InsertedID.(primitive.ObjectID)
+		if _, err := config.DB.Collection("notifications").InsertOne(context.Background(), models.ChoreAssignedNotification(firstChore)); err != nil {
+			log.Printf("Failed to notify member of recurring chore %s: %v", recurringChore.ID.Hex(), err)
+		}
+		webhooks.ChoreAssigned(context.Background(), *firstChore)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -300,6 +365,10 @@ func CreateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(recurringChore)
 }
 
+// GetUserChoresHandler retrieves the chores assigned to a user that are not completed yet, soonest
+// due first. Without ?limit= or ?cursor= every one is returned; with them the chores are paged and
+// the cursor of the next page is sent in the Next-Cursor header. ?fields= limits the fields returned and ?expand=assigned_to,group embeds the
+// assignee and group.
 func GetUserChoresHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -312,6 +381,15 @@ func GetUserChoresHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "due_date"}, models.Unpaged)
+	if !ok {
+		return
+	}
+	shape, ok := parseFieldsRequest(w, r, models.Chore{}, "assigned_to", "group")
+	if !ok {
+		return
+	}
+
 	// Find the user
 	var user models.User
 	err := config.DB.Collection("users").FindOne(
@@ -328,13 +406,14 @@ func GetUserChoresHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get all active chores for the user
+	// Get a page of the user's active chores
 	cursor, err := config.DB.Collection("chores").Find(
 		context.Background(),
-		bson.M{
+		page.filter(bson.M{
 			"assigned_to": user.ID,
 			"status":      bson.M{"$ne": models.ChoreStatusCompleted},
-		},
+		}),
+		shape.project(page.findOptions(), models.Chore{}, "due_date", "status", "group_id", "assigned_to"),
 	)
 	if err != nil {
 		http.Error(w, "Failed to fetch chores", http.StatusInternalServerError)
@@ -347,22 +426,45 @@ func GetUserChoresHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to decode chores", http.StatusInternalServerError)
 		return
 	}
+	if page.hasMore(len(chores)) {
+		chores = chores[:page.Limit]
+		last := chores[page.Limit-1]
+		nextCursor(w, models.PageCursor{Key: last.DueDate, ID: last.ID})
+	}
 
 	// Check for overdue chores and update their status
 	now := time.Now()
+	overdue := false
 	for i, chore := range chores {
 		if chore.Status != models.ChoreStatusOverdue && !chore.DueDate.IsZero() && chore.DueDate.Before(now) {
 			chores[i].Status = models.ChoreStatusOverdue
+			overdue = true
+		}
+	}
 
-			// Update in database
-			_, _ = config.DB.Collection("chores").UpdateOne(
-				context.Background(),
-				bson.M{"_id": chore.ID},
-				bson.M{"$set": bson.M{"status": models.ChoreStatusOverdue}},
-			)
+	// Update in database, applying the group's overdue penalty
+	if overdue {
+		_, err := jobs.MarkChoresOverdue(context.Background(), bson.M{
+			"assigned_to": user.ID,
+			"due_date":    bson.M{"$lt": now, "$gt": time.Time{}},
+		})
+		if err != nil {
+			log.Printf("Failed to mark overdue chores for user %s: %v", user.ID.Hex(), err)
 		}
 	}
 
+	if shape.shaped() {
+		shaped, err := shapeChores(context.Background(), chores, shape)
+		if err != nil {
+			log.Printf("Failed to shape chores of user %s: %v", user.ID.Hex(), err)
+			http.Error(w, "Failed to fetch chores", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shaped)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(chores)
 }
@@ -4,9 +4,12 @@ package handlers
 import (
 	"context"
 	"cribb-backend/config"
+	"cribb-backend/jobs"
 	"cribb-backend/models"
+	"cribb-backend/webhooks"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
@@ -14,9 +17,14 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// CompleteChoreRequest defines the request structure for completing a chore
+type CompleteChoreRequest struct {
+	ChoreID string `json:"chore_id"`
+	UserID  string `json:"user_id"` // Changed from Username to UserID
+}
+
 // CompleteChoreHandler handles the completion of a chore by a user
 func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -24,10 +32,7 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		ChoreID string `json:"chore_id"`
-		UserID  string `json:"user_id"` // Changed from Username to UserID
-	}
+	var request CompleteChoreRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -63,8 +68,19 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer session.EndSession(context.Background())
 
+	// Set when completing this chore ends its recurring chore's run
+	var endedRecurringChore *models.RecurringChore
+	// The completed chore and who completed it, to tell the rest of the group
+	var completedChore models.Chore
+	var completer models.User
+	// Set when completing this chore assigns the next one of its recurring chore
+	var assignedChore *models.Chore
+
 	// Define the transaction
 	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		endedRecurringChore = nil
+		assignedChore = nil
+
 		// 1. Get the user by ID
 		var user models.User
 		err := config.DB.Collection("users").FindOne(
@@ -104,6 +120,7 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		now := time.Now()
+		completedChore, completer = chore, user
 
 		// 5. Create chore completion record
 		choreCompletion := models.ChoreCompletion{
@@ -141,7 +158,11 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 			sessionContext,
 			bson.M{"_id": user.ID},
 			bson.M{
-				"$inc": bson.M{"score": chore.Points},
+				"$inc": bson.M{
+					"score":         chore.Points,
+					"weekly_score":  chore.Points,
+					"monthly_score": chore.Points,
+				},
 				"$set": bson.M{"updated_at": now},
 			},
 		)
@@ -149,6 +170,13 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 			return nil, err
 		}
 
+		event := models.CreateScoreEvent(user.ID, user.GroupID, models.ScoreEventCompletion, chore.Points, user.Score+chore.Points,
+			fmt.Sprintf("Completed %s", chore.Title))
+		event.ReferenceID = &chore.ID
+		if err := jobs.RecordScoreEvent(sessionContext, event); err != nil {
+			return nil, err
+		}
+
 		// 8. If this is a recurring chore, create the next instance
 		if chore.Type == models.ChoreTypeRecurring && !chore.RecurringID.IsZero() {
 			var recurringChore models.RecurringChore
@@ -158,57 +186,70 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 			).Decode(&recurringChore)
 
 			if err == nil && recurringChore.IsActive {
-				// Calculate the next assignment date
-				var nextAssignment time.Time
-				switch recurringChore.Frequency {
-				case "daily":
-					nextAssignment = now.Add(24 * time.Hour)
-				case "weekly":
-					nextAssignment = now.Add(7 * 24 * time.Hour)
-				case "biweekly":
-					nextAssignment = now.Add(14 * 24 * time.Hour)
-				case "monthly":
-					nextAssignment = now.AddDate(0, 1, 0)
-				default:
-					nextAssignment = now.Add(7 * 24 * time.Hour) // Default to weekly
-				}
-
-				// Update recurring chore with next assignment date
-				_, err = config.DB.Collection("recurring_chores").UpdateOne(
-					sessionContext,
-					bson.M{"_id": recurringChore.ID},
-					bson.M{
-						"$set": bson.M{
-							"next_assignment": nextAssignment,
-							"updated_at":      now,
-						},
-					},
-				)
+				blackouts, err := jobs.GetGroupBlackouts(sessionContext, recurringChore.GroupID, chore.DueDate)
 				if err != nil {
 					return nil, err
 				}
 
-				// Create next chore instance using the completed chore's due date as base
-				nextChore := models.CreateChoreFromRecurringWithBaseDate(&recurringChore, chore.DueDate)
-
-				// Update the recurring chore's current index in the database to persist the rotation
-				_, err = config.DB.Collection("recurring_chores").UpdateOne(
-					sessionContext,
-					bson.M{"_id": recurringChore.ID},
-					bson.M{
-						"$set": bson.M{
-							"current_index": recurringChore.CurrentIndex,
-							"updated_at":    now,
-						},
-					},
-				)
-				if err != nil {
-					return nil, err
+				// The next instance uses the completed chore's due date as its base.
+				// Occurrences dropped by a blackout move on to the following interval.
+				nextDueDate := chore.DueDate
+				for !recurringChore.HasEnded(nextDueDate) {
+					dueDate, ok := models.ApplyBlackouts(nextDueDate, recurringChore.BlackoutPolicy, blackouts)
+					if ok {
+						nextDueDate = dueDate
+						break
+					}
+					nextDueDate = models.NextOccurrence(recurringChore.Frequency, nextDueDate)
 				}
 
-				_, err = config.DB.Collection("chores").InsertOne(sessionContext, nextChore)
-				if err != nil {
-					return nil, err
+				// Stop here if the recurring chore has finished its run
+				if recurringChore.HasEnded(nextDueDate) {
+					_, err = config.DB.Collection("recurring_chores").UpdateOne(
+						sessionContext,
+						bson.M{"_id": recurringChore.ID},
+						bson.M{
+							"$set": bson.M{
+								"is_active":  false,
+								"updated_at": now,
+							},
+						},
+					)
+					if err != nil {
+						return nil, err
+					}
+					endedRecurringChore = &recurringChore
+				} else if len(recurringChore.EligibleMembers()) > 0 {
+					// Create next chore instance using the completed chore's due date as base
+					nextChore := models.CreateChoreFromRecurringWithBaseDate(&recurringChore, nextDueDate)
+					recurringChore.OccurrenceCount++
+
+					// Persist the next assignment date, rotation position and occurrence count
+					_, err = config.DB.Collection("recurring_chores").UpdateOne(
+						sessionContext,
+						bson.M{"_id": recurringChore.ID},
+						bson.M{
+							"$set": bson.M{
+								"next_assignment":  models.NextOccurrence(recurringChore.Frequency, now),
+								"current_index":    recurringChore.CurrentIndex,
+								"occurrence_count": recurringChore.OccurrenceCount,
+								"updated_at":       now,
+							},
+						},
+					)
+					if err != nil {
+						return nil, err
+					}
+
+					insertResult, err := config.DB.Collection("chores").InsertOne(sessionContext, nextChore)
+					if err != nil {
+						return nil, err
+					}
+					nextChore.ID = insertResult.InsertedID.(primitive.ObjectID)
+					if _, err := config.DB.Collection("notifications").InsertOne(sessionContext, models.ChoreAssignedNotification(nextChore)); err != nil {
+						return nil, err
+					}
+					assignedChore = nextChore
 				}
 			}
 		}
@@ -225,13 +266,46 @@ func CompleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if endedRecurringChore != nil {
+		go jobs.NotifyRecurringChoreEnded(*endedRecurringChore)
+	}
+
+	// Tell the rest of the group the chore is done
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(context.Background(), bson.M{"_id": completedChore.GroupID}).Decode(&group); err != nil {
+		log.Printf("Failed to load group %s for completion notifications: %v", completedChore.GroupID.Hex(), err)
+	} else {
+		insertNotifications(context.Background(), models.ChoreCompletedNotifications(completedChore, group.Members, completer))
+	}
+	webhooks.ChoreCompleted(context.Background(), completedChore, completer)
+	if assignedChore != nil {
+		webhooks.ChoreAssigned(context.Background(), *assignedChore)
+	}
+
+	// Award any badges this completion has earned
+	go jobs.EvaluateAchievements(userID)
+
+	// Count the completion towards the group's challenges
+	go func() {
+		var user models.User
+		if err := config.DB.Collection("users").FindOne(context.Background(), bson.M{"_id": userID}).Decode(&user); err != nil {
+			log.Printf("Failed to load user %s for challenge progress: %v", userID.Hex(), err)
+			return
+		}
+		if err := jobs.UpdateGroupChallenges(context.Background(), user.GroupID); err != nil {
+			log.Printf("Failed to update challenge progress: %v", err)
+		}
+	}()
+
 	// Return success response
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
-// GetGroupChoresHandler retrieves all active chores for a group
-// GetGroupChoresHandler retrieves all active chores for a group
+// GetGroupChoresHandler retrieves the chores of a group, soonest due first. Without ?limit= or
+// ?cursor= every chore is returned; with them the chores are paged and the cursor of the next page
+// is sent in the Next-Cursor header. ?fields= limits the fields returned and
+// ?expand=assigned_to,group embeds the assignee and group, in place of assignee_name.
 func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -244,6 +318,15 @@ func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "due_date"}, models.Unpaged)
+	if !ok {
+		return
+	}
+	shape, ok := parseFieldsRequest(w, r, models.Chore{}, "assigned_to", "group")
+	if !ok {
+		return
+	}
+
 	// Find the group by name
 	var group models.Group
 	err := config.DB.Collection("groups").FindOne(
@@ -260,12 +343,11 @@ func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get all chores for the group, sorted by due date
-	opts := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}})
+	// Get a page of the group's chores, sorted by due date
 	cursor, err := config.DB.Collection("chores").Find(
 		context.Background(),
-		bson.M{"group_id": group.ID},
-		opts,
+		page.filter(bson.M{"group_id": group.ID}),
+		shape.project(page.findOptions(), models.Chore{}, "due_date", "status", "group_id", "assigned_to"),
 	)
 
 	if err != nil {
@@ -279,6 +361,11 @@ func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to decode chores", http.StatusInternalServerError)
 		return
 	}
+	if page.hasMore(len(chores)) {
+		chores = chores[:page.Limit]
+		last := chores[page.Limit-1]
+		nextCursor(w, models.PageCursor{Key: last.DueDate, ID: last.ID})
+	}
 
 	// Check for overdue chores and update their status
 	// now := time.Now()
@@ -286,6 +373,7 @@ func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 	year, month, day := time.Now().UTC().Date()
 	startOfTodayUTC := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 
+	overdue := false
 	for i, chore := range chores {
 		// Remove previous logging
 		// log.Printf(...)
@@ -303,20 +391,34 @@ func GetGroupChoresHandler(w http.ResponseWriter, r *http.Request) {
 			if !startOfTodayUTC.Before(endOfDueDateUTC) { // Equivalent to startOfTodayUTC >= endOfDueDateUTC
 				// log.Printf("Marking chore ID %s as OVERDUE", chore.ID.Hex()) // Optional: keep logging if needed
 				chores[i].Status = models.ChoreStatusOverdue
+				overdue = true
+			}
+		}
+	}
 
-				// Update in database (don't wait for the result)
-				go func(choreID primitive.ObjectID) {
-					_, err := config.DB.Collection("chores").UpdateOne(
-						context.Background(),
-						bson.M{"_id": choreID},
-						bson.M{"$set": bson.M{"status": models.ChoreStatusOverdue}},
-					)
-					if err != nil {
-						log.Printf("Failed to update chore status to overdue: %v", err)
-					}
-				}(chore.ID)
+	// Update in database, applying the group's overdue penalty (don't wait for the result)
+	if overdue {
+		go func(groupID primitive.ObjectID) {
+			_, err := jobs.MarkChoresOverdue(context.Background(), bson.M{
+				"group_id": groupID,
+				"due_date": bson.M{"$lt": startOfTodayUTC, "$gt": time.Time{}},
+			})
+			if err != nil {
+				log.Printf("Failed to update chore status to overdue: %v", err)
 			}
+		}(group.ID)
+	}
+
+	if shape.shaped() {
+		shaped, err := shapeChores(context.Background(), chores, shape)
+		if err != nil {
+			log.Printf("Failed to shape chores of group %s: %v", group.ID.Hex(), err)
+			http.Error(w, "Failed to fetch chores", http.StatusInternalServerError)
+			return
 		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(shaped)
+		return
 	}
 
 	// For each chore, include assignee information
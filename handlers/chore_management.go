@@ -3,19 +3,34 @@ package handlers
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
+	"cribb-backend/jobs"
 	"cribb-backend/models"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// UpdateChoreRequest defines the request structure for editing a chore
+type UpdateChoreRequest struct {
+	ChoreID     string    `json:"chore_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	AssignedTo  string    `json:"assigned_to"` // Username of user to assign
+	DueDate     time.Time `json:"due_date"`
+	Points      int       `json:"points"`
+}
+
 // UpdateChoreHandler handles updating an existing chore
 func UpdateChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -23,14 +38,7 @@ func UpdateChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		ChoreID     string    `json:"chore_id"`
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		AssignedTo  string    `json:"assigned_to"` // Username of user to assign
-		DueDate     time.Time `json:"due_date"`
-		Points      int       `json:"points"`
-	}
+	var request UpdateChoreRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -68,7 +76,7 @@ func UpdateChoreHandler(w http.ResponseWriter, r *http.Request) {
 
 	// If a chore is already completed, don't allow updates
 	if chore.Status == models.ChoreStatusCompleted {
-		http.Error(w, "Cannot update a completed chore", http.StatusBadRequest)
+		writeError(w, r, apierror.CodeChoreLocked, "Cannot update a completed chore")
 		return
 	}
 
@@ -170,6 +178,134 @@ func UpdateChoreHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(updatedChore)
 }
 
+// PatchChoreHandler changes only the fields of a chore a JSON Merge Patch names. Null clears the
+// description or unassigns the chore; the title, due date and points cannot be cleared.
+func PatchChoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	choreID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, "/api/chores/update/"))
+	if err != nil {
+		http.Error(w, "Invalid chore ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r, "title", "description", "assigned_to", "due_date", "points")
+	if !ok {
+		return
+	}
+	if err := patch.Require("title", "due_date", "points"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var chore models.Chore
+	err = config.DB.Collection("chores").FindOne(context.Background(), bson.M{"_id": choreID}).Decode(&chore)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Chore not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch chore", http.StatusInternalServerError)
+		}
+		return
+	}
+	if chore.GroupID != user.GroupID {
+		http.Error(w, "Chore does not belong to your group", http.StatusForbidden)
+		return
+	}
+	if chore.Status == models.ChoreStatusCompleted {
+		writeError(w, r, apierror.CodeChoreLocked, "Cannot update a completed chore")
+		return
+	}
+
+	patched := chore
+	if err := patch.Apply(&patched); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	set := bson.M{"updated_at": time.Now()}
+	unset := bson.M{}
+	if patch.Has("title") {
+		patched.Title = strings.TrimSpace(patched.Title)
+		if patched.Title == "" {
+			http.Error(w, "Title cannot be empty", http.StatusBadRequest)
+			return
+		}
+		set["title"] = patched.Title
+	}
+	if patch.Has("description") {
+		set["description"] = patched.Description
+	}
+	if patch.Has("points") {
+		if patched.Points < 1 {
+			http.Error(w, "Points must be at least 1", http.StatusBadRequest)
+			return
+		}
+		set["points"] = patched.Points
+	}
+	if patch.Has("due_date") {
+		if patched.DueDate.IsZero() {
+			http.Error(w, "Due date cannot be empty", http.StatusBadRequest)
+			return
+		}
+		set["due_date"] = patched.DueDate
+
+		// Re-evaluate status based on the new due date
+		if patched.DueDate.Before(time.Now()) {
+			set["status"] = models.ChoreStatusOverdue
+		} else {
+			set["status"] = models.ChoreStatusPending
+		}
+	}
+	if patch.Has("assigned_to") {
+		if patched.AssignedTo.IsZero() {
+			unset["assigned_to"] = ""
+		} else {
+			count, err := config.DB.Collection("users").CountDocuments(
+				context.Background(),
+				bson.M{"_id": patched.AssignedTo, "group_id": chore.GroupID},
+			)
+			if err != nil {
+				http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+				return
+			}
+			if count == 0 {
+				http.Error(w, "User does not belong to this chore's group", http.StatusBadRequest)
+				return
+			}
+			set["assigned_to"] = patched.AssignedTo
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	var updatedChore models.Chore
+	err = config.DB.Collection("chores").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": choreID},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedChore)
+	if err != nil {
+		log.Printf("Failed to patch chore %s: %v", choreID.Hex(), err)
+		http.Error(w, "Failed to update chore", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedChore)
+}
+
 // DeleteChoreHandler handles deleting a chore
 func DeleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
@@ -230,6 +366,49 @@ func DeleteChoreHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// UpdateRecurringChoreRequest defines the request structure for editing a recurring chore
+type UpdateRecurringChoreRequest struct {
+	RecurringChoreID string   `json:"recurring_chore_id"`
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	Frequency        string   `json:"frequency"` // daily, weekly, biweekly, monthly
+	Points           int      `json:"points"`
+	IsActive         *bool    `json:"is_active"`
+	MemberUsernames  []string `json:"member_usernames"`
+	UntilDate        *string  `json:"until_date"`      // RFC3339; empty string removes the end date
+	MaxOccurrences   *int     `json:"max_occurrences"` // 0 removes the limit
+	BlackoutPolicy   string   `json:"blackout_policy"` // push or skip
+	CatchUpPolicy    *string  `json:"catch_up_policy"` // backfill or skip_forward; empty string restores the server default
+	Scope            string   `json:"scope"`           // this, future or all; empty updates the template only
+	ChoreID          string   `json:"chore_id"`        // Instance the edit starts from, required for "this"
+	Regenerate       bool     `json:"regenerate"`      // Replace pending instances instead of editing them in place
+}
+
+// changedFields lists the fields the request sets, by their JSON names
+func (req UpdateRecurringChoreRequest) changedFields() []string {
+	var fields []string
+	for _, field := range []struct {
+		name string
+		set  bool
+	}{
+		{"title", req.Title != ""},
+		{"description", req.Description != ""},
+		{"frequency", req.Frequency != ""},
+		{"points", req.Points > 0},
+		{"is_active", req.IsActive != nil},
+		{"member_usernames", len(req.MemberUsernames) > 0},
+		{"until_date", req.UntilDate != nil},
+		{"max_occurrences", req.MaxOccurrences != nil},
+		{"blackout_policy", req.BlackoutPolicy != ""},
+		{"catch_up_policy", req.CatchUpPolicy != nil},
+	} {
+		if field.set {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
 // UpdateRecurringChoreHandler handles updating a recurring chore
 func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPut {
@@ -237,15 +416,7 @@ func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		RecurringChoreID string   `json:"recurring_chore_id"`
-		Title            string   `json:"title"`
-		Description      string   `json:"description"`
-		Frequency        string   `json:"frequency"` // daily, weekly, biweekly, monthly
-		Points           int      `json:"points"`
-		IsActive         *bool    `json:"is_active"`
-		MemberUsernames  []string `json:"member_usernames"`
-	}
+	var request UpdateRecurringChoreRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -281,6 +452,46 @@ func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Validate propagation options; a single occurrence only takes the fields it copies from the template
+	scope := models.EditScope(request.Scope)
+	if err := models.ValidateEditScope(scope, request.Regenerate, request.changedFields()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var selectedChore *models.Chore
+	if request.ChoreID != "" {
+		choreID, err := primitive.ObjectIDFromHex(request.ChoreID)
+		if err != nil {
+			http.Error(w, "Invalid chore ID format", http.StatusBadRequest)
+			return
+		}
+
+		var chore models.Chore
+		err = config.DB.Collection("chores").FindOne(
+			context.Background(),
+			bson.M{"_id": choreID, "recurring_id": recurringChoreID},
+		).Decode(&chore)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				http.Error(w, "Chore not found for this recurring chore", http.StatusNotFound)
+			} else {
+				http.Error(w, "Failed to fetch chore", http.StatusInternalServerError)
+			}
+			return
+		}
+		selectedChore = &chore
+	}
+
+	if scope == models.EditScopeThis {
+		if selectedChore == nil {
+			http.Error(w, "Chore ID is required when editing a single occurrence", http.StatusBadRequest)
+			return
+		}
+		updateSingleOccurrence(w, r, *selectedChore, request.Title, request.Description, request.Points)
+		return
+	}
+
 	// Validate frequency if provided
 	if request.Frequency != "" {
 		validFrequencies := map[string]bool{
@@ -322,6 +533,52 @@ func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		updateFields["is_active"] = *request.IsActive
 	}
 
+	if request.BlackoutPolicy != "" {
+		if !isValidBlackoutPolicy(request.BlackoutPolicy) {
+			http.Error(w, "Invalid blackout policy. Must be push or skip", http.StatusBadRequest)
+			return
+		}
+		updateFields["blackout_policy"] = request.BlackoutPolicy
+	}
+
+	// End date, occurrence limit and catch-up policy can also be cleared
+	unsetFields := bson.M{}
+	if request.CatchUpPolicy != nil {
+		if *request.CatchUpPolicy == "" {
+			unsetFields["catch_up_policy"] = ""
+		} else if isValidCatchUpPolicy(*request.CatchUpPolicy) {
+			updateFields["catch_up_policy"] = *request.CatchUpPolicy
+		} else {
+			http.Error(w, "Invalid catch-up policy. Must be backfill or skip_forward", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if request.UntilDate != nil {
+		if *request.UntilDate == "" {
+			unsetFields["until_date"] = ""
+		} else {
+			ts, err := time.Parse(time.RFC3339, *request.UntilDate)
+			if err != nil {
+				http.Error(w, "Invalid until date format. Use RFC3339", http.StatusBadRequest)
+				return
+			}
+			updateFields["until_date"] = ts
+		}
+	}
+
+	if request.MaxOccurrences != nil {
+		if *request.MaxOccurrences < 0 {
+			http.Error(w, "Max occurrences cannot be negative", http.StatusBadRequest)
+			return
+		}
+		if *request.MaxOccurrences == 0 {
+			unsetFields["max_occurrences"] = ""
+		} else {
+			updateFields["max_occurrences"] = *request.MaxOccurrences
+		}
+	}
+
 	if len(request.MemberUsernames) > 0 {
 		// Build new rotation list
 		newRotation := make([]primitive.ObjectID, 0, len(request.MemberUsernames))
@@ -349,12 +606,69 @@ func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		updateFields["current_index"] = 0
 	}
 
-	// Update recurring chore in the database
-	result, err := config.DB.Collection("recurring_chores").UpdateOne(
-		context.Background(),
-		bson.M{"_id": recurringChoreID},
-		bson.M{"$set": updateFields},
-	)
+	update := bson.M{"$set": updateFields}
+	if len(unsetFields) > 0 {
+		update["$unset"] = unsetFields
+	}
+
+	// Fields that are copied onto already-generated instances
+	instanceFields := bson.M{"updated_at": updateFields["updated_at"]}
+	for _, field := range []string{"title", "description", "points"} {
+		if value, ok := updateFields[field]; ok {
+			instanceFields[field] = value
+		}
+	}
+
+	// Instances in scope: pending or overdue ones, from the selected occurrence onwards for "future"
+	instanceFilter := bson.M{
+		"recurring_id": recurringChoreID,
+		"status":       bson.M{"$ne": models.ChoreStatusCompleted},
+	}
+	if scope == models.EditScopeFuture {
+		from := time.Now()
+		if selectedChore != nil {
+			from = selectedChore.DueDate
+		}
+		instanceFilter["due_date"] = bson.M{"$gte": from}
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	// Update the template and propagate to its instances atomically
+	changed, err := session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		result, err := config.DB.Collection("recurring_chores").UpdateOne(
+			sc,
+			bson.M{"_id": recurringChoreID},
+			update,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if scope == "" {
+			return result.ModifiedCount > 0, nil
+		}
+
+		if !request.Regenerate {
+			instanceResult, err := config.DB.Collection("chores").UpdateMany(
+				sc,
+				instanceFilter,
+				bson.M{"$set": instanceFields},
+			)
+			if err != nil {
+				return nil, err
+			}
+			return result.ModifiedCount > 0 || instanceResult.ModifiedCount > 0, nil
+		}
+
+		return true, regenerateInstances(sc, recurringChoreID, instanceFilter, len(request.MemberUsernames) > 0)
+	})
 
 	if err != nil {
 		log.Printf("Failed to update recurring chore: %v", err)
@@ -362,7 +676,7 @@ func UpdateRecurringChoreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if result.ModifiedCount == 0 {
+	if !changed.(bool) {
 		http.Error(w, "No changes were made", http.StatusOK)
 		return
 	}
@@ -516,3 +830,476 @@ func ClearCompletedChoresHandler(w http.ResponseWriter, r *http.Request) {
 		"message":       "Completed chores cleared successfully",
 	})
 }
+
+// updateSingleOccurrence edits one generated instance of a recurring chore without touching its template
+func updateSingleOccurrence(w http.ResponseWriter, r *http.Request, chore models.Chore, title, description string, points int) {
+	if chore.Status == models.ChoreStatusCompleted {
+		writeError(w, r, apierror.CodeChoreLocked, "Cannot update a completed chore")
+		return
+	}
+
+	updateFields := bson.M{"updated_at": time.Now()}
+	if title != "" {
+		updateFields["title"] = title
+	}
+	if description != "" {
+		updateFields["description"] = description
+	}
+	if points > 0 {
+		updateFields["points"] = points
+	}
+
+	var updatedChore models.Chore
+	err := config.DB.Collection("chores").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": chore.ID},
+		bson.M{"$set": updateFields},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedChore)
+	if err != nil {
+		log.Printf("Failed to update chore occurrence: %v", err)
+		http.Error(w, "Failed to update chore", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedChore)
+}
+
+// regenerateInstances replaces the pending instances matched by filter with instances built from the
+// updated template, one per replaced instance and due when it was
+func regenerateInstances(sc mongo.SessionContext, recurringChoreID primitive.ObjectID, filter bson.M, rotationReset bool) error {
+	var recurringChore models.RecurringChore
+	err := config.DB.Collection("recurring_chores").FindOne(
+		sc,
+		bson.M{"_id": recurringChoreID},
+	).Decode(&recurringChore)
+	if err != nil {
+		return err
+	}
+
+	cursor, err := config.DB.Collection("chores").Find(
+		sc,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+	var replaced []models.Chore
+	if err = cursor.All(sc, &replaced); err != nil {
+		return err
+	}
+	if len(replaced) == 0 {
+		// Nothing generated yet, the scheduler will create instances from the new template
+		return nil
+	}
+
+	if _, err = config.DB.Collection("chores").DeleteMany(sc, filter); err != nil {
+		return err
+	}
+
+	instances := recurringChore.RegenerateInstances(replaced, rotationReset)
+	if len(instances) > 0 {
+		documents := make([]interface{}, len(instances))
+		for i, instance := range instances {
+			documents[i] = instance
+		}
+		if _, err = config.DB.Collection("chores").InsertMany(sc, documents); err != nil {
+			return err
+		}
+	}
+
+	_, err = config.DB.Collection("recurring_chores").UpdateOne(
+		sc,
+		bson.M{"_id": recurringChoreID},
+		bson.M{"$set": bson.M{
+			"current_index":    recurringChore.CurrentIndex,
+			"occurrence_count": recurringChore.OccurrenceCount,
+			"updated_at":       time.Now(),
+		}},
+	)
+	return err
+}
+
+// UpdateRotationOrderRequest defines the request structure for reordering a chore rotation
+type UpdateRotationOrderRequest struct {
+	RecurringChoreID string   `json:"recurring_chore_id"`
+	MemberUsernames  []string `json:"member_usernames"` // Full rotation in the desired order
+}
+
+// UpdateRotationOrderHandler lets a group admin set the explicit rotation sequence of a recurring chore
+func UpdateRotationOrderHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UpdateRotationOrderRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.RecurringChoreID == "" || len(request.MemberUsernames) == 0 {
+		http.Error(w, "Recurring chore ID and member usernames are required", http.StatusBadRequest)
+		return
+	}
+
+	recurringChoreID, err := primitive.ObjectIDFromHex(request.RecurringChoreID)
+	if err != nil {
+		http.Error(w, "Invalid recurring chore ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var recurringChore models.RecurringChore
+	err = config.DB.Collection("recurring_chores").FindOne(
+		context.Background(),
+		bson.M{"_id": recurringChoreID},
+	).Decode(&recurringChore)
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring chore not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch recurring chore", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	group, ok := getGroupByID(w, recurringChore.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can change the rotation order", http.StatusForbidden)
+		return
+	}
+
+	// Resolve usernames against current group members
+	cursor, err := config.DB.Collection("users").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID},
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var members []models.User
+	if err := cursor.All(context.Background(), &members); err != nil {
+		http.Error(w, "Failed to decode group members", http.StatusInternalServerError)
+		return
+	}
+
+	memberIDs := make([]primitive.ObjectID, 0, len(members))
+	idsByUsername := make(map[string]primitive.ObjectID, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.ID)
+		idsByUsername[member.Username] = member.ID
+	}
+
+	newRotation := make([]primitive.ObjectID, 0, len(request.MemberUsernames))
+	for _, username := range request.MemberUsernames {
+		id, found := idsByUsername[username]
+		if !found {
+			http.Error(w, "User "+username+" is not a member of this group", http.StatusBadRequest)
+			return
+		}
+		newRotation = append(newRotation, id)
+	}
+
+	if err := models.ValidateRotationOrder(newRotation, memberIDs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	recurringChore.SetRotationOrder(newRotation)
+	recurringChore.UpdatedAt = time.Now()
+
+	_, err = config.DB.Collection("recurring_chores").UpdateOne(
+		context.Background(),
+		bson.M{"_id": recurringChoreID},
+		bson.M{"$set": bson.M{
+			"member_rotation": recurringChore.MemberRotation,
+			"current_index":   recurringChore.CurrentIndex,
+			"updated_at":      recurringChore.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		log.Printf("Failed to update rotation order: %v", err)
+		http.Error(w, "Failed to update rotation order", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(recurringChore)
+}
+
+// GetUpcomingAssignmentsHandler previews the next occurrences of a recurring chore and who they fall to
+func GetUpcomingAssignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Path format: /api/recurring-chores/{id}/upcoming
+	path := strings.TrimPrefix(r.URL.Path, "/api/recurring-chores/")
+	if !strings.HasSuffix(path, "/upcoming") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	recurringChoreIDStr := strings.TrimSuffix(path, "/upcoming")
+
+	recurringChoreID, err := primitive.ObjectIDFromHex(recurringChoreIDStr)
+	if err != nil {
+		http.Error(w, "Invalid recurring chore ID format", http.StatusBadRequest)
+		return
+	}
+
+	count := 10
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		count, err = strconv.Atoi(countStr)
+		if err != nil || count < 1 || count > 100 {
+			http.Error(w, "Count must be between 1 and 100", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var recurringChore models.RecurringChore
+	err = config.DB.Collection("recurring_chores").FindOne(
+		context.Background(),
+		bson.M{"_id": recurringChoreID},
+	).Decode(&recurringChore)
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring chore not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch recurring chore", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if recurringChore.GroupID != user.GroupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	now := time.Now()
+	blackouts, err := jobs.GetGroupBlackouts(context.Background(), recurringChore.GroupID, now)
+	if err != nil {
+		http.Error(w, "Failed to fetch blackout dates", http.StatusInternalServerError)
+		return
+	}
+
+	occurrences := recurringChore.PreviewOccurrences(count, now, blackouts)
+
+	// Resolve assignee names for display
+	users := make(map[primitive.ObjectID]models.User)
+	cursor, err := config.DB.Collection("users").Find(
+		context.Background(),
+		bson.M{"_id": bson.M{"$in": recurringChore.MemberRotation}},
+	)
+	if err == nil {
+		var members []models.User
+		if err := cursor.All(context.Background(), &members); err == nil {
+			for _, member := range members {
+				users[member.ID] = member
+			}
+		}
+		cursor.Close(context.Background())
+	}
+
+	type upcomingAssignment struct {
+		models.UpcomingOccurrence
+		AssigneeUsername string `json:"assignee_username"`
+		AssigneeName     string `json:"assignee_name"`
+	}
+
+	response := make([]upcomingAssignment, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		assignee := users[occurrence.AssignedTo]
+		response = append(response, upcomingAssignment{
+			UpcomingOccurrence: occurrence,
+			AssigneeUsername:   assignee.Username,
+			AssigneeName:       assignee.Name,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recurring_chore_id": recurringChore.ID,
+		"title":              recurringChore.Title,
+		"upcoming":           response,
+	})
+}
+
+// UpdateRotationExclusionsRequest defines the request structure for excluding members from a chore rotation
+type UpdateRotationExclusionsRequest struct {
+	RecurringChoreID  string   `json:"recurring_chore_id"`
+	ExcludedUsernames []string `json:"excluded_usernames"` // Replaces the current exclusions; empty clears them
+}
+
+// UpdateRotationExclusionsHandler lets a group admin exclude members from a recurring chore's rotation
+func UpdateRotationExclusionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UpdateRotationExclusionsRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	recurringChore, ok := getRecurringChoreByID(w, request.RecurringChoreID)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, recurringChore.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can change rotation exclusions", http.StatusForbidden)
+		return
+	}
+
+	excluded := make([]primitive.ObjectID, 0, len(request.ExcludedUsernames))
+	seen := make(map[primitive.ObjectID]bool, len(request.ExcludedUsernames))
+	for _, username := range request.ExcludedUsernames {
+		var member models.User
+		err := config.DB.Collection("users").FindOne(
+			context.Background(),
+			bson.M{"username": username, "group_id": group.ID},
+		).Decode(&member)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				http.Error(w, "User "+username+" not found in group", http.StatusBadRequest)
+			} else {
+				http.Error(w, "Failed to fetch user "+username, http.StatusInternalServerError)
+			}
+			return
+		}
+		if !seen[member.ID] {
+			seen[member.ID] = true
+			excluded = append(excluded, member.ID)
+		}
+	}
+	recurringChore.ExcludedMembers = excluded
+
+	if len(recurringChore.EligibleMembers()) == 0 {
+		http.Error(w, "At least one member of the rotation must remain eligible", http.StatusBadRequest)
+		return
+	}
+
+	recurringChore.UpdatedAt = time.Now()
+	_, err := config.DB.Collection("recurring_chores").UpdateOne(
+		context.Background(),
+		bson.M{"_id": recurringChore.ID},
+		bson.M{"$set": bson.M{
+			"excluded_members": recurringChore.ExcludedMembers,
+			"updated_at":       recurringChore.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		log.Printf("Failed to update rotation exclusions: %v", err)
+		http.Error(w, "Failed to update rotation exclusions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(recurringChore)
+}
+
+// GetRotationFairnessHandler reports how evenly a recurring chore has been spread across its eligible members
+func GetRotationFairnessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	recurringChore, ok := getRecurringChoreByID(w, r.URL.Query().Get("recurring_chore_id"))
+	if !ok {
+		return
+	}
+
+	if recurringChore.GroupID != user.GroupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	// Count generated instances per assignee
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"recurring_id": recurringChore.ID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      "$assigned_to",
+			"assigned": bson.M{"$sum": 1},
+			"completed": bson.M{"$sum": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$status", models.ChoreStatusCompleted}}, 1, 0},
+			}},
+		}}},
+	}
+
+	cursor, err := config.DB.Collection("chores").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		http.Error(w, "Failed to compute fairness stats", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var counts []struct {
+		UserID    primitive.ObjectID `bson:"_id"`
+		Assigned  int                `bson:"assigned"`
+		Completed int                `bson:"completed"`
+	}
+	if err := cursor.All(context.Background(), &counts); err != nil {
+		http.Error(w, "Failed to decode fairness stats", http.StatusInternalServerError)
+		return
+	}
+
+	assigned := make(map[primitive.ObjectID]int, len(counts))
+	completed := make(map[primitive.ObjectID]int, len(counts))
+	for _, count := range counts {
+		assigned[count.UserID] = count.Assigned
+		completed[count.UserID] = count.Completed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"recurring_chore_id": recurringChore.ID,
+		"title":              recurringChore.Title,
+		"members":            recurringChore.RotationFairness(assigned, completed),
+	})
+}
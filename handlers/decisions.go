@@ -0,0 +1,292 @@
+// handlers/decisions.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateDecisionRequest defines the request structure for asking the group a question
+type CreateDecisionRequest struct {
+	Question       string     `json:"question"`
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multiple_choice,omitempty"` // Members can pick several options
+	Anonymous      bool       `json:"anonymous,omitempty"`       // Results leave out who voted for what
+	Deadline       *time.Time `json:"deadline,omitempty"`        // Defaults to 48 hours from now
+}
+
+// DecisionVoteRequest defines the request structure for voting in a decision poll
+type DecisionVoteRequest struct {
+	OptionIDs []string `json:"option_ids"` // One option, or several in multiple choice polls
+}
+
+// DecisionPollDetails is a decision poll with its results so far and the requester's vote
+type DecisionPollDetails struct {
+	models.DecisionPoll
+	Results    []models.DecisionResult `json:"results"`
+	VoterCount int                     `json:"voter_count"`
+	YourVote   []primitive.ObjectID    `json:"your_vote,omitempty"`
+}
+
+// decisionDetails tallies the poll as the member sees it
+func decisionDetails(poll models.DecisionPoll, userID primitive.ObjectID) DecisionPollDetails {
+	details := DecisionPollDetails{DecisionPoll: poll, Results: poll.Tally(), VoterCount: len(poll.Ballots)}
+	if ballot, ok := poll.Ballot(userID); ok {
+		details.YourVote = ballot.OptionIDs
+	}
+	return details
+}
+
+// DecisionsHandler lists the group's decision polls, newest first, with their results on GET
+// (?status=open or closed to filter) and asks the group a question on POST
+func DecisionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listDecisions(w, r, user, group)
+	case http.MethodPost:
+		createDecision(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listDecisions returns the group's most recent decision polls
+func listDecisions(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	filter := bson.M{"group_id": group.ID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		switch models.PollStatus(status) {
+		case models.PollOpen, models.PollClosed:
+			filter["status"] = status
+		default:
+			http.Error(w, "Status must be open or closed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cursor, err := config.DB.Collection("decision_polls").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(50),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch polls", http.StatusInternalServerError)
+		return
+	}
+	polls := make([]models.DecisionPoll, 0)
+	if err := cursor.All(context.Background(), &polls); err != nil {
+		http.Error(w, "Failed to decode polls", http.StatusInternalServerError)
+		return
+	}
+
+	details := make([]DecisionPollDetails, 0, len(polls))
+	for _, poll := range polls {
+		details = append(details, decisionDetails(poll, user.ID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// createDecision asks the question and lets the other members know
+func createDecision(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreateDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	deadline := now.Add(models.DefaultPollVotingPeriod)
+	if request.Deadline != nil {
+		deadline = *request.Deadline
+	}
+	if err := models.ValidateDecisionPoll(request.Question, request.Options, deadline, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	poll := models.CreateDecisionPoll(group.ID, user.ID, request.Question, request.Options, request.MultipleChoice, request.Anonymous, deadline)
+	ctx := context.Background()
+	result, err := config.DB.Collection("decision_polls").InsertOne(ctx, poll)
+	if err != nil {
+		log.Printf("Failed to create decision poll: %v", err)
+		http.Error(w, "Failed to create poll", http.StatusInternalServerError)
+		return
+	}
+	poll.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.DecisionCreatedNotification(poll, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(decisionDetails(*poll, user.ID))
+}
+
+// DecisionHandler handles one of the group's decision polls: GET /api/groups/decisions/{id}
+// returns it with its results, DELETE removes it, POST /api/groups/decisions/{id}/vote records
+// the member's vote and POST /api/groups/decisions/{id}/close ends voting before the deadline.
+// Removing and closing are for the poll's creator and group admins.
+func DecisionHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/decisions/"), "/"), "/")
+	pollID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid poll ID", http.StatusBadRequest)
+		return
+	}
+	var poll models.DecisionPoll
+	err = config.DB.Collection("decision_polls").FindOne(context.Background(), bson.M{"_id": pollID, "group_id": group.ID}).Decode(&poll)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Poll not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch poll", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	manages := poll.CreatedBy == user.ID || group.IsAdmin(user.ID)
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(decisionDetails(poll, user.ID))
+	case action == "" && r.Method == http.MethodDelete:
+		if !manages {
+			http.Error(w, "Only the poll's creator and group admins can remove it", http.StatusForbidden)
+			return
+		}
+		if _, err := config.DB.Collection("decision_polls").DeleteOne(context.Background(), bson.M{"_id": poll.ID}); err != nil {
+			http.Error(w, "Failed to remove poll", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "vote" && r.Method == http.MethodPost:
+		voteDecision(w, r, user, group, poll)
+	case action == "close" && r.Method == http.MethodPost:
+		if !manages {
+			http.Error(w, "Only the poll's creator and group admins can close it", http.StatusForbidden)
+			return
+		}
+		poll, closed, err := models.CloseDecisionPoll(context.Background(), config.DB, poll)
+		if err != nil {
+			log.Printf("Failed to close decision poll %s: %v", poll.ID.Hex(), err)
+			http.Error(w, "Failed to close poll", http.StatusInternalServerError)
+			return
+		}
+		if !closed {
+			writeError(w, r, apierror.CodeVotingClosed, "Voting has already closed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(decisionDetails(poll, user.ID))
+	case action == "" || action == "vote" || action == "close":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// voteDecision records the member's vote, replacing an earlier one. Once every member has voted
+// the poll closes straight away.
+func voteDecision(w http.ResponseWriter, r *http.Request, user models.User, group models.Group, poll models.DecisionPoll) {
+	var request DecisionVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if poll.Status != models.PollOpen {
+		writeError(w, r, apierror.CodeVotingClosed, "Voting has closed")
+		return
+	}
+	optionIDs := make([]primitive.ObjectID, 0, len(request.OptionIDs))
+	for _, id := range request.OptionIDs {
+		optionID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			http.Error(w, "Invalid option ID", http.StatusBadRequest)
+			return
+		}
+		optionIDs = append(optionIDs, optionID)
+	}
+	now := time.Now()
+	if err := poll.SetBallot(user.ID, optionIDs, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ballot, _ := poll.Ballot(user.ID)
+
+	// Replace the member's ballot in place so ballots from other members are not overwritten
+	ctx := context.Background()
+	collection := config.DB.Collection("decision_polls")
+	open := bson.M{"_id": poll.ID, "status": models.PollOpen}
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": poll.ID, "status": models.PollOpen, "ballots.user_id": user.ID},
+		bson.M{"$set": bson.M{"ballots.$": ballot, "updated_at": now}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		open["ballots.user_id"] = bson.M{"$ne": user.ID}
+		result, err = collection.UpdateOne(ctx, open, bson.M{
+			"$push": bson.M{"ballots": ballot},
+			"$set":  bson.M{"updated_at": now},
+		})
+	}
+	if err != nil {
+		http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeError(w, r, apierror.CodeVotingClosed, "Voting has closed")
+		return
+	}
+	poll.UpdatedAt = now
+
+	if poll.AllVoted(group.Members) {
+		// Close the poll as stored, which includes votes that came in since it was read
+		if err := collection.FindOne(ctx, bson.M{"_id": poll.ID}).Decode(&poll); err == nil && poll.AllVoted(group.Members) {
+			if poll, _, err = models.CloseDecisionPoll(ctx, config.DB, poll); err != nil {
+				log.Printf("Failed to close decision poll %s: %v", poll.ID.Hex(), err)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(decisionDetails(poll, user.ID))
+}
@@ -0,0 +1,137 @@
+// handlers/device_tokens.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxDeviceTokenLength caps push tokens; FCM tokens are a few hundred characters
+const maxDeviceTokenLength = 4096
+
+// RegisterDeviceRequest defines the request structure for registering a device for push notifications
+type RegisterDeviceRequest struct {
+	Token      string `json:"token"`                 // FCM registration token, or the APNs device token on iOS when APNs is configured
+	Platform   string `json:"platform"`              // android, ios or web
+	DeviceName string `json:"device_name,omitempty"` // Shown in the member's list of devices
+}
+
+// DeviceHandler manages the requesting user's devices: GET lists them, POST registers a token for
+// push notifications and DELETE with ?token= unregisters one, e.g. on sign out
+func DeviceHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listDevices(w, user)
+	case http.MethodPost:
+		registerDevice(w, r, user)
+	case http.MethodDelete:
+		unregisterDevice(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listDevices returns the user's registered devices, most recently seen first
+func listDevices(w http.ResponseWriter, user models.User) {
+	cursor, err := config.DB.Collection("device_tokens").Find(
+		context.Background(),
+		bson.M{"user_id": user.ID},
+		options.Find().SetSort(bson.D{{Key: "last_seen_at", Value: -1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch devices", http.StatusInternalServerError)
+		return
+	}
+	devices := make([]models.DeviceToken, 0)
+	if err := cursor.All(context.Background(), &devices); err != nil {
+		http.Error(w, "Failed to decode devices", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
+
+// registerDevice saves a registration token for the user. Apps register on every start, so a
+// known token is refreshed, and moved to this user if someone else signed in on the device before.
+func registerDevice(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request RegisterDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Token = strings.TrimSpace(request.Token)
+	if request.Token == "" || len(request.Token) > maxDeviceTokenLength {
+		http.Error(w, "A valid device token is required", http.StatusBadRequest)
+		return
+	}
+	platform := models.DevicePlatform(request.Platform)
+	if !models.IsValidDevicePlatform(platform) {
+		http.Error(w, "Platform must be android, ios or web", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	var device models.DeviceToken
+	err := config.DB.Collection("device_tokens").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"token": request.Token},
+		bson.M{
+			"$set": bson.M{
+				"user_id":      user.ID,
+				"platform":     platform,
+				"device_name":  strings.TrimSpace(request.DeviceName),
+				"last_seen_at": now,
+			},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&device)
+	if err != nil {
+		log.Printf("Failed to register device of user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to register device", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+// unregisterDevice removes one of the user's registration tokens
+func unregisterDevice(w http.ResponseWriter, r *http.Request, user models.User) {
+	token := strings.TrimSpace(r.URL.Query().Get("token"))
+	if token == "" {
+		http.Error(w, "token is required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := config.DB.Collection("device_tokens").DeleteOne(
+		context.Background(),
+		bson.M{"token": token, "user_id": user.ID},
+	)
+	if err != nil {
+		http.Error(w, "Failed to unregister device", http.StatusInternalServerError)
+		return
+	}
+	if result.DeletedCount == 0 {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
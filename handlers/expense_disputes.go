@@ -0,0 +1,290 @@
+// handlers/expense_disputes.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errInvalidExpenseChange marks an edit or dispute rejected because of the expense's current state
+var errInvalidExpenseChange = errors.New("invalid expense change")
+
+// UpdateExpenseRequest defines the request structure for correcting an expense. Amounts are in the
+// group's currency.
+type UpdateExpenseRequest struct {
+	Description  string                      `json:"description"`
+	Amount       float64                     `json:"amount"`
+	SplitMethod  string                      `json:"split_method,omitempty"` // equal (default), percentage or exact
+	Participants []ExpenseParticipantRequest `json:"participants,omitempty"` // Defaults to the whole group for equal splits
+	Category     string                      `json:"category,omitempty"`     // Defaults to the expense's current category
+	Note         string                      `json:"note,omitempty"`         // Added to the dispute thread when the edit resolves a dispute
+}
+
+// ExpenseDisputeRequest defines the request structure for disputing an expense, commenting on the
+// dispute or resolving it
+type ExpenseDisputeRequest struct {
+	Message string `json:"message"` // The reason when opening a dispute; optional when resolving
+}
+
+// changeExpense applies change to a fresh copy of the expense and saves it with the update change
+// returns. The group's ledger version is bumped in the same transaction, so settlements being
+// checked against the balances conflict with the change. Errors wrapping errInvalidExpenseChange
+// are the caller's to report.
+func changeExpense(groupID, expenseID primitive.ObjectID, change func(expense *models.Expense) (bson.M, error)) (models.Expense, error) {
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		return models.Expense{}, err
+	}
+	defer session.EndSession(context.Background())
+
+	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		if _, err := config.DB.Collection("groups").UpdateOne(
+			sessionContext,
+			bson.M{"_id": groupID},
+			bson.M{"$inc": bson.M{"ledger_version": 1}},
+		); err != nil {
+			return nil, err
+		}
+
+		var expense models.Expense
+		if err := config.DB.Collection("expenses").FindOne(sessionContext, bson.M{"_id": expenseID, "group_id": groupID}).Decode(&expense); err != nil {
+			return nil, err
+		}
+		update, err := change(&expense)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errInvalidExpenseChange, err)
+		}
+		if _, err := config.DB.Collection("expenses").UpdateOne(sessionContext, bson.M{"_id": expense.ID}, update); err != nil {
+			return nil, err
+		}
+		return expense, nil
+	})
+	if err != nil {
+		return models.Expense{}, err
+	}
+	return result.(models.Expense), nil
+}
+
+// writeExpenseChangeError reports why changing an expense failed
+func writeExpenseChangeError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, errInvalidExpenseChange):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, mongo.ErrNoDocuments):
+		http.Error(w, "Expense not found", http.StatusNotFound)
+	default:
+		log.Printf("Failed to %s: %v", action, err)
+		http.Error(w, "Failed to "+action, http.StatusInternalServerError)
+	}
+}
+
+// notifyExpenseDispute tells a member about a dispute of an expense, unless they caused the change
+func notifyExpenseDispute(expense models.Expense, userID primitive.ObjectID, actor models.User, notificationType models.NotificationType, templateID models.NotificationTemplateID) {
+	if userID == actor.ID {
+		return
+	}
+	params := models.NotificationParams{"name": actor.Name, "expense": expense.Description}
+	if expense.Dispute != nil {
+		params["reason"] = expense.Dispute.Reason
+	}
+	notification := models.CreateTemplatedNotification(userID, expense.GroupID, notificationType, templateID, params)
+	if _, err := config.DB.Collection("notifications").InsertOne(context.Background(), notification); err != nil {
+		log.Printf("Failed to notify member of dispute of expense %s: %v", expense.ID.Hex(), err)
+	}
+}
+
+// updateExpense corrects an expense's description, amount, split and category. Only the member who
+// paid or recorded it can edit it, and an edit resolves an open dispute. Shopping expenses follow
+// their purchases, so their prices are corrected through the purchase history instead.
+func updateExpense(w http.ResponseWriter, r *http.Request, user models.User, expense models.Expense) {
+	if expense.PaidBy != user.ID && expense.CreatedBy != user.ID {
+		http.Error(w, "Only the member who paid or recorded the expense can edit it", http.StatusForbidden)
+		return
+	}
+	if expense.Source == models.ExpenseSourceShopping {
+		http.Error(w, "Shopping expenses follow their purchases, correct the purchase price instead", http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Description = strings.TrimSpace(request.Description)
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+	request.Amount = models.RoundMoney(request.Amount)
+	if request.Amount <= 0 {
+		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		return
+	}
+
+	method := models.SplitMethod(request.SplitMethod)
+	if method == "" {
+		method = models.SplitMethodEqual
+	}
+	if !models.IsValidSplitMethod(method) {
+		http.Error(w, "Split method must be equal, percentage or exact", http.StatusBadRequest)
+		return
+	}
+	if method != models.SplitMethodEqual && len(request.Participants) == 0 {
+		http.Error(w, "Participants are required for percentage and exact splits", http.StatusBadRequest)
+		return
+	}
+
+	category := models.ExpenseCategory(request.Category)
+	if category == "" {
+		category = expense.CategoryOrDefault()
+	}
+	if !models.IsValidExpenseCategory(category) {
+		http.Error(w, "Category must be rent, groceries, utilities, fun or other", http.StatusBadRequest)
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+	usernames, ok := participantUsernames(w, request.Participants)
+	if !ok {
+		return
+	}
+	members, ok := findGroupMembersByUsername(w, group.ID, usernames)
+	if !ok {
+		return
+	}
+	splits, err := splitExpense(request.Amount, method, request.Participants, members, group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var disputedBy primitive.ObjectID
+	updated, err := changeExpense(group.ID, expense.ID, func(current *models.Expense) (bson.M, error) {
+		if current.Source == models.ExpenseSourceShopping {
+			return nil, errors.New("shopping expenses follow their purchases")
+		}
+		now := time.Now()
+		set := bson.M{
+			"description":  request.Description,
+			"amount":       request.Amount,
+			"splits":       splits,
+			"split_method": method,
+			"category":     category,
+			"updated_at":   now,
+		}
+		unset := bson.M{}
+		// An amount entered in the group's currency replaces the converted one
+		if current.OriginalCurrency != "" && current.Amount != request.Amount {
+			unset = bson.M{"original_amount": "", "original_currency": "", "exchange_rate": ""}
+			current.OriginalAmount, current.OriginalCurrency, current.ExchangeRate = 0, "", 0
+		}
+		if current.IsDisputed() {
+			disputedBy = current.Dispute.OpenedBy
+			if err := current.ResolveDispute(user.ID, user.Name, models.DisputeMessageEdited, request.Note, now); err != nil {
+				return nil, err
+			}
+			set["dispute"] = current.Dispute
+		}
+		current.Description, current.Amount, current.Splits = request.Description, request.Amount, splits
+		current.SplitMethod, current.Category, current.UpdatedAt = method, category, now
+
+		update := bson.M{"$set": set}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+		return update, nil
+	})
+	if err != nil {
+		writeExpenseChangeError(w, err, "update expense")
+		return
+	}
+
+	if !disputedBy.IsZero() {
+		notifyExpenseDispute(updated, disputedBy, user, models.NotificationTypeExpenseDisputeResolved, models.TemplateDisputedExpenseEdited)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expenseDetails(updated, time.Now()))
+}
+
+// expenseDispute handles the dispute of an expense. POST /api/expenses/{id}/dispute disputes it
+// with the reason as the message, POST /api/expenses/{id}/dispute/comments adds a message to the
+// thread and POST /api/expenses/{id}/dispute/resolve lets a group admin count it again as is.
+func expenseDispute(w http.ResponseWriter, r *http.Request, user models.User, expense models.Expense, sub string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ExpenseDisputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if sub == "dispute/resolve" {
+		group, ok := getGroupByID(w, user.GroupID)
+		if !ok {
+			return
+		}
+		if !group.IsAdmin(user.ID) {
+			http.Error(w, "Only group admins can resolve a dispute; the payer resolves it by editing the expense", http.StatusForbidden)
+			return
+		}
+	}
+
+	var disputedBy primitive.ObjectID
+	updated, err := changeExpense(user.GroupID, expense.ID, func(current *models.Expense) (bson.M, error) {
+		now := time.Now()
+		var err error
+		switch sub {
+		case "dispute":
+			err = current.OpenDispute(user.ID, user.Name, request.Message, now)
+		case "dispute/comments":
+			err = current.CommentOnDispute(user.ID, user.Name, request.Message, now)
+		case "dispute/resolve":
+			if current.Dispute != nil {
+				disputedBy = current.Dispute.OpenedBy
+			}
+			err = current.ResolveDispute(user.ID, user.Name, models.DisputeMessageResolved, request.Message, now)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{"$set": bson.M{"dispute": current.Dispute}}, nil
+	})
+	if err != nil {
+		writeExpenseChangeError(w, err, "update dispute")
+		return
+	}
+
+	switch sub {
+	case "dispute":
+		notifyExpenseDispute(updated, updated.PaidBy, user, models.NotificationTypeExpenseDisputed, models.TemplateExpenseDisputed)
+	case "dispute/resolve":
+		notifyExpenseDispute(updated, disputedBy, user, models.NotificationTypeExpenseDisputeResolved, models.TemplateExpenseDisputeResolved)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if sub == "dispute" {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(expenseDetails(updated, time.Now()))
+}
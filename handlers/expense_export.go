@@ -0,0 +1,149 @@
+// handlers/expense_export.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/pdf"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportGroupExpensesHandler downloads a statement of the group's expenses and repayments between
+// two dates with the balances they leave, as documentation for the final settlement at lease end.
+// Path format: /api/groups/{id}/expenses/export
+// Query: ?from=YYYY-MM-DD (defaults to when the group was created)&to=YYYY-MM-DD (defaults to
+// today)&format=csv|pdf (defaults to csv)
+func ExportGroupExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/expenses/export"))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "pdf" {
+		http.Error(w, "Format must be csv or pdf", http.StatusBadRequest)
+		return
+	}
+
+	var from, to time.Time
+	if value := query.Get("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid from date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if value := query.Get("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid to date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	if requester.GroupID != groupID {
+		http.Error(w, "You can only export your own group's expenses", http.StatusForbidden)
+		return
+	}
+
+	group, ok := getGroupByID(w, groupID)
+	if !ok {
+		return
+	}
+	if from.IsZero() {
+		from = group.CreatedAt.UTC().Truncate(24 * time.Hour)
+	}
+	if to.Before(from) {
+		http.Error(w, "The to date cannot be before the from date", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	// The to date is inclusive, so include everything before the following midnight
+	filter := bson.M{
+		"group_id":   group.ID,
+		"created_at": bson.M{"$gte": from, "$lt": to.AddDate(0, 0, 1)},
+	}
+	oldestFirst := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	var expenses []models.Expense
+	cursor, err := config.DB.Collection("expenses").Find(ctx, filter, oldestFirst)
+	if err != nil {
+		log.Printf("Failed to fetch expenses for export: %v", err)
+		http.Error(w, "Failed to fetch expenses", http.StatusInternalServerError)
+		return
+	}
+	if err := cursor.All(ctx, &expenses); err != nil {
+		http.Error(w, "Failed to decode expenses", http.StatusInternalServerError)
+		return
+	}
+
+	var settlements []models.Settlement
+	cursor, err = config.DB.Collection("settlements").Find(ctx, filter, oldestFirst)
+	if err != nil {
+		log.Printf("Failed to fetch settlements for export: %v", err)
+		http.Error(w, "Failed to fetch settlements", http.StatusInternalServerError)
+		return
+	}
+	if err := cursor.All(ctx, &settlements); err != nil {
+		http.Error(w, "Failed to decode settlements", http.StatusInternalServerError)
+		return
+	}
+
+	// Name everyone who appears, including former members
+	ids := append([]primitive.ObjectID{}, group.Members...)
+	for _, expense := range expenses {
+		ids = append(ids, expense.PaidBy)
+		for _, share := range expense.Splits {
+			ids = append(ids, share.UserID)
+		}
+	}
+	for _, settlement := range settlements {
+		ids = append(ids, settlement.FromUserID, settlement.ToUserID)
+	}
+	names, err := userNames(ctx, ids)
+	if err != nil {
+		log.Printf("Failed to fetch member names for expense export: %v", err)
+	}
+
+	statement := models.BuildExpenseStatement(from, to, expenses, settlements, group.Members, names)
+	statement.Group = group.Name
+	statement.Currency = group.Settings.CurrencyCode()
+
+	filename := "expenses-" + from.Format("2006-01-02") + "-to-" + to.Format("2006-01-02")
+	if format == "pdf" {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.pdf"`)
+		w.Write(pdf.TextDocument("Expense statement", statement.Lines()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+	if err := csv.NewWriter(w).WriteAll(statement.CSVRecords()); err != nil {
+		log.Printf("Failed to write expense export: %v", err)
+	}
+}
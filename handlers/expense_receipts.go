@@ -0,0 +1,231 @@
+// handlers/expense_receipts.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// expensePath serves single expenses: /api/expenses/{id} and /api/expenses/{id}/receipt
+const expensePath = "/api/expenses/"
+
+// expenseReceiptPath serves receipt images through signed URLs: /api/expenses/receipts/{file_id}
+const expenseReceiptPath = "/api/expenses/receipts/"
+
+// ExpenseDetails is an expense with a signed link to its receipt image
+type ExpenseDetails struct {
+	models.Expense
+	ReceiptURL string `json:"receipt_url,omitempty"`
+}
+
+// expenseDetails signs the receipt URL of an expense, leaving it empty when it has no receipt
+func expenseDetails(expense models.Expense, now time.Time) ExpenseDetails {
+	details := ExpenseDetails{Expense: expense}
+	if expense.ReceiptID != nil {
+		details.ReceiptURL = storage.SignedURL(expenseReceiptPath, *expense.ReceiptID, now)
+	}
+	return details
+}
+
+// ExpenseHandler serves a single expense of the user's group. GET /api/expenses/{id} returns its
+// details and PUT edits it; PUT /api/expenses/{id}/receipt uploads a receipt image as the multipart
+// "receipt" field and DELETE removes it. Disputes are under /api/expenses/{id}/dispute, see
+// expenseDispute.
+func ExpenseHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, expensePath)
+	idStr, sub, _ := strings.Cut(path, "/")
+	expenseID, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		http.Error(w, "Invalid expense ID format", http.StatusBadRequest)
+		return
+	}
+	switch sub {
+	case "", "receipt", "dispute", "dispute/comments", "dispute/resolve":
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var expense models.Expense
+	err = config.DB.Collection("expenses").FindOne(
+		context.Background(),
+		bson.M{"_id": expenseID, "group_id": user.GroupID},
+	).Decode(&expense)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Expense not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch expense", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if sub == "" {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(expenseDetails(expense, time.Now()))
+		case http.MethodPut:
+			updateExpense(w, r, user, expense)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if strings.HasPrefix(sub, "dispute") {
+		expenseDispute(w, r, user, expense, sub)
+		return
+	}
+
+	if expense.PaidBy != user.ID && expense.CreatedBy != user.ID {
+		http.Error(w, "Only the member who paid or recorded the expense can change its receipt", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPost:
+		uploadExpenseReceipt(w, r, expense)
+	case http.MethodDelete:
+		deleteExpenseReceipt(w, expense)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadExpenseReceipt stores a receipt image for an expense, replacing the previous one
+func uploadExpenseReceipt(w http.ResponseWriter, r *http.Request, expense models.Expense) {
+	// Leave room for the multipart headers around the image
+	r.Body = http.MaxBytesReader(w, r.Body, storage.MaxImageSize+64<<10)
+	file, header, err := r.FormFile("receipt")
+	if err != nil {
+		http.Error(w, "A receipt image under 5 MB is required in the \"receipt\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read receipt", http.StatusBadRequest)
+		return
+	}
+
+	receiptID, err := storage.SaveImage(header.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrImageTooLarge):
+			http.Error(w, "Receipt must be 5 MB or smaller", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrUnsupportedImage):
+			http.Error(w, "Receipt must be a JPEG, PNG, GIF or WebP image", http.StatusUnsupportedMediaType)
+		default:
+			log.Printf("Failed to store receipt of expense %s: %v", expense.ID.Hex(), err)
+			http.Error(w, "Failed to store receipt", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_, err = config.DB.Collection("expenses").UpdateOne(
+		context.Background(),
+		bson.M{"_id": expense.ID},
+		bson.M{"$set": bson.M{"receipt_id": receiptID, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to attach receipt to expense %s: %v", expense.ID.Hex(), err)
+		storage.Delete(context.Background(), receiptID)
+		http.Error(w, "Failed to attach receipt", http.StatusInternalServerError)
+		return
+	}
+
+	if expense.ReceiptID != nil {
+		removeExpenseReceipt(*expense.ReceiptID)
+	}
+	expense.ReceiptID = &receiptID
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expenseDetails(expense, time.Now()))
+}
+
+// deleteExpenseReceipt detaches and removes an expense's receipt
+func deleteExpenseReceipt(w http.ResponseWriter, expense models.Expense) {
+	if expense.ReceiptID == nil {
+		http.Error(w, "Expense has no receipt", http.StatusNotFound)
+		return
+	}
+
+	_, err := config.DB.Collection("expenses").UpdateOne(
+		context.Background(),
+		bson.M{"_id": expense.ID},
+		bson.M{"$unset": bson.M{"receipt_id": ""}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to detach receipt from expense %s: %v", expense.ID.Hex(), err)
+		http.Error(w, "Failed to delete receipt", http.StatusInternalServerError)
+		return
+	}
+	removeExpenseReceipt(*expense.ReceiptID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeExpenseReceipt deletes a stored receipt in the background once nothing refers to it
+func removeExpenseReceipt(receiptID primitive.ObjectID) {
+	go func() {
+		if err := storage.Delete(context.Background(), receiptID); err != nil {
+			log.Printf("Failed to delete expense receipt %s: %v", receiptID.Hex(), err)
+		}
+	}()
+}
+
+// ServeExpenseReceiptHandler streams a receipt image from a signed URL handed out in expense
+// details, so image tags can load it without the auth header
+func ServeExpenseReceiptHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	receiptID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, expenseReceiptPath))
+	if err != nil {
+		http.Error(w, "Invalid receipt ID format", http.StatusBadRequest)
+		return
+	}
+	if err := storage.VerifySignature(receiptID, r.URL.Query(), time.Now()); err != nil {
+		http.Error(w, "Receipt link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	receipt, contentType, err := storage.Open(context.Background(), receiptID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Receipt not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open expense receipt %s: %v", receiptID.Hex(), err)
+			http.Error(w, "Failed to fetch receipt", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer receipt.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	if _, err := io.Copy(w, receipt); err != nil {
+		log.Printf("Failed to send expense receipt %s: %v", receiptID.Hex(), err)
+	}
+}
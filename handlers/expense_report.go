@@ -0,0 +1,95 @@
+// handlers/expense_report.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetExpenseReportHandler totals the group's spending in a month per category and member.
+// Query: ?month=YYYY-MM (defaults to the current month)&format=json|csv, where csv downloads the
+// report as a spreadsheet for budgeting.
+func GetExpenseReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	month := query.Get("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+	start, end, err := models.MonthBounds(month)
+	if err != nil {
+		http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "Format must be json or csv", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("expenses").Find(ctx, bson.M{
+		"group_id":   group.ID,
+		"created_at": bson.M{"$gte": start, "$lt": end},
+	})
+	if err != nil {
+		log.Printf("Failed to fetch expenses for report: %v", err)
+		http.Error(w, "Failed to fetch expenses", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var expenses []models.Expense
+	if err := cursor.All(ctx, &expenses); err != nil {
+		http.Error(w, "Failed to decode expenses", http.StatusInternalServerError)
+		return
+	}
+
+	report := models.BuildExpenseReport(month, expenses, group.Members)
+	report.Currency = group.Settings.CurrencyCode()
+	ids := make([]primitive.ObjectID, 0, len(report.Members))
+	for _, member := range report.Members {
+		ids = append(ids, member.UserID)
+	}
+	names, err := userNames(ctx, ids)
+	if err != nil {
+		log.Printf("Failed to fetch member names for expense report: %v", err)
+	}
+	report.SetNames(names)
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="expenses-`+month+`.csv"`)
+		if err := csv.NewWriter(w).WriteAll(report.CSVRecords()); err != nil {
+			log.Printf("Failed to write expense report: %v", err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,439 @@
+// handlers/expenses.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/currency"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxExpenseListLimit caps how many expenses a single list request returns
+const maxExpenseListLimit = 500
+
+// ExpenseParticipantRequest is one member sharing an expense
+type ExpenseParticipantRequest struct {
+	Username   string  `json:"username"`
+	Percentage float64 `json:"percentage,omitempty"` // For the percentage split method
+	Amount     float64 `json:"amount,omitempty"`     // For the exact split method
+}
+
+// CreateExpenseRequest defines the request structure for recording an expense
+type CreateExpenseRequest struct {
+	Description  string                      `json:"description" validate:"required"`
+	Amount       float64                     `json:"amount" validate:"required,min=0"`
+	PaidBy       string                      `json:"paid_by,omitempty"`      // Username; defaults to the requesting user
+	SplitMethod  string                      `json:"split_method,omitempty"` // equal (default), percentage or exact
+	Participants []ExpenseParticipantRequest `json:"participants,omitempty"` // Defaults to the whole group for equal splits
+	Category     string                      `json:"category,omitempty"`     // rent, groceries, utilities, fun or other (default)
+	Currency     string                      `json:"currency,omitempty"`     // Currency the amounts are in; defaults to the group's currency
+}
+
+// findGroupMembersByUsername resolves usernames to members of the group, writing the error response
+// when one is not in the group
+func findGroupMembersByUsername(w http.ResponseWriter, groupID primitive.ObjectID, usernames []string) (map[string]models.User, bool) {
+	cursor, err := config.DB.Collection("users").Find(
+		context.Background(),
+		bson.M{"group_id": groupID, "username": bson.M{"$in": usernames}},
+	)
+	if err != nil {
+		log.Printf("Failed to fetch group members: %v", err)
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return nil, false
+	}
+	defer cursor.Close(context.Background())
+
+	var users []models.User
+	if err := cursor.All(context.Background(), &users); err != nil {
+		log.Printf("Failed to decode group members: %v", err)
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	members := make(map[string]models.User, len(users))
+	for _, user := range users {
+		members[user.Username] = user
+	}
+	for _, username := range usernames {
+		if _, ok := members[username]; !ok {
+			http.Error(w, "User "+username+" not found in group", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return members, true
+}
+
+// participantUsernames returns the participants' usernames, writing the error response when one
+// has no username or is listed more than once
+func participantUsernames(w http.ResponseWriter, participants []ExpenseParticipantRequest) ([]string, bool) {
+	usernames := make([]string, 0, len(participants)+1)
+	seen := make(map[string]bool)
+	for _, participant := range participants {
+		if participant.Username == "" {
+			http.Error(w, "Every participant needs a username", http.StatusBadRequest)
+			return nil, false
+		}
+		if seen[participant.Username] {
+			http.Error(w, "Participant "+participant.Username+" is listed more than once", http.StatusBadRequest)
+			return nil, false
+		}
+		seen[participant.Username] = true
+		usernames = append(usernames, participant.Username)
+	}
+	return usernames, true
+}
+
+// splitExpense divides the amount between the participants by the split method, or evenly across
+// the group for an equal split without participants. members must hold every participant.
+func splitExpense(amount float64, method models.SplitMethod, participants []ExpenseParticipantRequest, members map[string]models.User, group models.Group) ([]models.CostShare, error) {
+	switch method {
+	case models.SplitMethodPercentage:
+		ids := make([]primitive.ObjectID, 0, len(participants))
+		percentages := make([]float64, 0, len(participants))
+		for _, participant := range participants {
+			ids = append(ids, members[participant.Username].ID)
+			percentages = append(percentages, participant.Percentage)
+		}
+		return models.SplitByPercentage(amount, ids, percentages)
+	case models.SplitMethodExact:
+		splits := make([]models.CostShare, 0, len(participants))
+		for _, participant := range participants {
+			splits = append(splits, models.CostShare{
+				UserID: members[participant.Username].ID,
+				Amount: models.RoundMoney(participant.Amount),
+			})
+		}
+		if err := models.CheckExactSplit(amount, splits); err != nil {
+			return nil, err
+		}
+		return splits, nil
+	default:
+		ids := group.Members
+		if len(participants) > 0 {
+			ids = make([]primitive.ObjectID, 0, len(participants))
+			for _, participant := range participants {
+				ids = append(ids, members[participant.Username].ID)
+			}
+		}
+		return models.SplitEvenly(amount, ids), nil
+	}
+}
+
+// CreateExpenseHandler records money a member paid for the group, split between the participants
+// equally, by percentage or by exact amounts
+func CreateExpenseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateExpenseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Description = strings.TrimSpace(request.Description)
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+	request.Amount = models.RoundMoney(request.Amount)
+	if request.Amount <= 0 {
+		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		return
+	}
+
+	method := models.SplitMethod(request.SplitMethod)
+	if method == "" {
+		method = models.SplitMethodEqual
+	}
+	if !models.IsValidSplitMethod(method) {
+		http.Error(w, "Split method must be equal, percentage or exact", http.StatusBadRequest)
+		return
+	}
+	if method != models.SplitMethodEqual && len(request.Participants) == 0 {
+		http.Error(w, "Participants are required for percentage and exact splits", http.StatusBadRequest)
+		return
+	}
+
+	category := models.ExpenseCategory(request.Category)
+	if category == "" {
+		category = models.DefaultExpenseCategory(models.ExpenseSourceManual)
+	}
+	if !models.IsValidExpenseCategory(category) {
+		http.Error(w, "Category must be rent, groceries, utilities, fun or other", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	groupCurrency := group.Settings.CurrencyCode()
+	expenseCurrency := models.NormalizeCurrency(request.Currency)
+	if expenseCurrency == "" {
+		expenseCurrency = groupCurrency
+	}
+	if !models.IsValidCurrency(expenseCurrency) {
+		http.Error(w, "Currency must be a three-letter ISO 4217 code", http.StatusBadRequest)
+		return
+	}
+
+	usernames, ok := participantUsernames(w, request.Participants)
+	if !ok {
+		return
+	}
+	if request.PaidBy != "" && !slices.Contains(usernames, request.PaidBy) {
+		usernames = append(usernames, request.PaidBy)
+	}
+
+	members, ok := findGroupMembersByUsername(w, group.ID, usernames)
+	if !ok {
+		return
+	}
+
+	paidBy := user.ID
+	if request.PaidBy != "" {
+		paidBy = members[request.PaidBy].ID
+	}
+
+	splits, err := splitExpense(request.Amount, method, request.Participants, members, group)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Balances are kept in the group's currency, so other currencies are converted when recorded
+	amount := request.Amount
+	rate := 0.0
+	if expenseCurrency != groupCurrency {
+		amount, rate, err = currency.Convert(r.Context(), request.Amount, expenseCurrency, groupCurrency)
+		if err != nil {
+			if errors.Is(err, currency.ErrUnsupportedCurrency) {
+				http.Error(w, "No exchange rate from "+expenseCurrency+" to "+groupCurrency, http.StatusBadRequest)
+			} else {
+				log.Printf("Failed to fetch exchange rates: %v", err)
+				http.Error(w, "Exchange rates are unavailable, try again later", http.StatusServiceUnavailable)
+			}
+			return
+		}
+		if splits, err = models.ConvertedShares(amount, splits); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	expense := models.CreateExpense(group.ID, paidBy, request.Description, amount, splits, models.ExpenseSourceManual)
+	expense.Currency = groupCurrency
+	if rate != 0 {
+		expense.OriginalAmount = request.Amount
+		expense.OriginalCurrency = expenseCurrency
+		expense.ExchangeRate = rate
+	}
+	expense.SplitMethod = method
+	expense.Category = category
+	expense.CreatedBy = user.ID
+
+	result, err := config.DB.Collection("expenses").InsertOne(context.Background(), expense)
+	if err != nil {
+		log.Printf("Failed to create expense: %v", err)
+		http.Error(w, "Failed to create expense", http.StatusInternalServerError)
+		return
+	}
+	expense.ID = result.InsertedID.(primitive.ObjectID)
+	insertNotifications(context.Background(), models.ExpenseAddedNotifications(*expense, user.Name))
+	webhooks.ExpenseAdded(context.Background(), *expense, user)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(expense)
+}
+
+// GetExpensesHandler lists the expenses of the requesting user's group, newest first.
+// Query: ?paid_by={user_id}&user_id={user_id}&source=manual|shopping|recurring|splitwise&disputed=true|false&month=YYYY-MM&limit=100,
+// where user_id matches expenses the member paid or has a share in and disputed=true matches
+// expenses with an open dispute.
+func GetExpensesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := bson.M{"group_id": user.GroupID}
+	if paidByStr := query.Get("paid_by"); paidByStr != "" {
+		paidBy, err := primitive.ObjectIDFromHex(paidByStr)
+		if err != nil {
+			http.Error(w, "Invalid paid_by user ID format", http.StatusBadRequest)
+			return
+		}
+		filter["paid_by"] = paidBy
+	}
+	if userIDStr := query.Get("user_id"); userIDStr != "" {
+		userID, err := primitive.ObjectIDFromHex(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+			return
+		}
+		filter["$or"] = bson.A{bson.M{"paid_by": userID}, bson.M{"splits.user_id": userID}}
+	}
+	if source := models.ExpenseSource(query.Get("source")); source != "" {
+		if source != models.ExpenseSourceManual && source != models.ExpenseSourceShopping && source != models.ExpenseSourceRecurring && source != models.ExpenseSourceSplitwise {
+			http.Error(w, "Source must be manual, shopping, recurring or splitwise", http.StatusBadRequest)
+			return
+		}
+		filter["source"] = source
+	}
+	if disputed := query.Get("disputed"); disputed != "" {
+		switch disputed {
+		case "true":
+			filter["dispute.status"] = models.DisputeStatusOpen
+		case "false":
+			filter["dispute.status"] = bson.M{"$ne": models.DisputeStatusOpen}
+		default:
+			http.Error(w, "Disputed must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+	if month := query.Get("month"); month != "" {
+		start, end, err := models.MonthBounds(month)
+		if err != nil {
+			http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		filter["created_at"] = bson.M{"$gte": start, "$lt": end}
+	}
+
+	limit := int64(100)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || parsed < 1 || parsed > maxExpenseListLimit {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", maxExpenseListLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("expenses").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(limit),
+	)
+	if err != nil {
+		log.Printf("GetExpensesHandler find error: %v", err)
+		http.Error(w, "Failed to fetch expenses", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var expenses []models.Expense
+	if err := cursor.All(ctx, &expenses); err != nil {
+		http.Error(w, "Failed to decode expenses", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	details := make([]ExpenseDetails, 0, len(expenses))
+	for _, expense := range expenses {
+		details = append(details, expenseDetails(expense, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// GetExpenseBalancesHandler returns each member's running balance across all of the group's expenses
+// and settlements
+func GetExpenseBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	balances, err := loadGroupBalances(ctx, group)
+	if err != nil {
+		log.Printf("Failed to compute balances: %v", err)
+		http.Error(w, "Failed to compute balances", http.StatusInternalServerError)
+		return
+	}
+	if err := fillBalanceNames(ctx, balances); err != nil {
+		log.Printf("Failed to fetch member names for balances: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// fillBalanceNames sets the name of each member in the balances
+func fillBalanceNames(ctx context.Context, balances []models.MemberBalance) error {
+	ids := make([]primitive.ObjectID, 0, len(balances))
+	for _, balance := range balances {
+		ids = append(ids, balance.UserID)
+	}
+
+	names, err := userNames(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range balances {
+		balances[i].Name = names[balances[i].UserID]
+	}
+	return nil
+}
+
+// userNames looks up the names of the users with the given IDs
+func userNames(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+	}
+	return names, nil
+}
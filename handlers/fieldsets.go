@@ -0,0 +1,162 @@
+// handlers/fieldsets.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fieldsRequest is the shape a list request asks for: only the fields in Fields, with the related
+// documents in Expand embedded
+type fieldsRequest struct {
+	Fields models.Fieldset
+	Expand []string
+}
+
+// MemberInfo is an expanded reference to a member
+type MemberInfo struct {
+	ID       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	Username string             `json:"username"`
+}
+
+// GroupInfo is an expanded reference to a group
+type GroupInfo struct {
+	ID   primitive.ObjectID `json:"id"`
+	Name string             `json:"name"`
+}
+
+// parseFieldsRequest reads ?fields=, the JSON fields of document to return, and ?expand=, the
+// related documents to embed. It writes a bad request response and returns false when either
+// names something the endpoint does not have.
+func parseFieldsRequest(w http.ResponseWriter, r *http.Request, document interface{}, expandable ...string) (fieldsRequest, bool) {
+	var request fieldsRequest
+	query := r.URL.Query()
+
+	fields, err := models.ParseFieldset(query.Get("fields"), document)
+	if err != nil {
+		http.Error(w, "Invalid fields: "+err.Error(), http.StatusBadRequest)
+		return request, false
+	}
+	expand, err := models.ParseExpand(query.Get("expand"), expandable...)
+	if err != nil {
+		http.Error(w, "Invalid expand: "+err.Error(), http.StatusBadRequest)
+		return request, false
+	}
+	// An expanded reference is returned even when it was left out of the fields
+	for _, name := range expand {
+		if len(fields) > 0 && !fields.Has(name) {
+			fields = append(fields, name)
+		}
+	}
+
+	request.Fields = fields
+	request.Expand = expand
+	return request, true
+}
+
+// shaped reports whether the response differs from the full documents
+func (f fieldsRequest) shaped() bool {
+	return len(f.Fields) > 0 || len(f.Expand) > 0
+}
+
+// expands reports whether the related document is embedded
+func (f fieldsRequest) expands(name string) bool {
+	for _, expand := range f.Expand {
+		if expand == name {
+			return true
+		}
+	}
+	return false
+}
+
+// project limits what find loads to the fields asked for, along with the stored fields the
+// endpoint needs whatever was asked for
+func (f fieldsRequest) project(find *options.FindOptions, document interface{}, required ...string) *options.FindOptions {
+	if projection := f.Fields.Projection(document, required...); projection != nil {
+		find.SetProjection(projection)
+	}
+	return find
+}
+
+// memberInfos loads the members with the given IDs for expansion
+func memberInfos(ctx context.Context, ids []primitive.ObjectID) (map[primitive.ObjectID]MemberInfo, error) {
+	members := make(map[primitive.ObjectID]MemberInfo, len(ids))
+	if len(ids) == 0 {
+		return members, nil
+	}
+	cursor, err := config.DB.Collection("users").Find(
+		ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"name": 1, "username": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		members[user.ID] = MemberInfo{ID: user.ID, Name: user.Name, Username: user.Username}
+	}
+	return members, nil
+}
+
+// shapeChores returns the chores with only the fields asked for, embedding each assignee under
+// assigned_to and the chore's group under group when they are expanded
+func shapeChores(ctx context.Context, chores []models.Chore, shape fieldsRequest) ([]map[string]interface{}, error) {
+	var members map[primitive.ObjectID]MemberInfo
+	if shape.expands("assigned_to") {
+		ids := make([]primitive.ObjectID, 0, len(chores))
+		for _, chore := range chores {
+			if !chore.AssignedTo.IsZero() {
+				ids = append(ids, chore.AssignedTo)
+			}
+		}
+		var err error
+		if members, err = memberInfos(ctx, ids); err != nil {
+			return nil, err
+		}
+	}
+	groups := make(map[primitive.ObjectID]*GroupInfo)
+	if shape.expands("group") {
+		for _, chore := range chores {
+			groups[chore.GroupID] = nil
+		}
+		for id := range groups {
+			var group models.Group
+			err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": id}, options.FindOne().SetProjection(bson.M{"name": 1})).Decode(&group)
+			if err == nil {
+				groups[id] = &GroupInfo{ID: group.ID, Name: group.Name}
+			}
+		}
+	}
+
+	shaped := make([]map[string]interface{}, 0, len(chores))
+	for _, chore := range chores {
+		item, err := shape.Fields.Select(chore)
+		if err != nil {
+			return nil, err
+		}
+		if shape.expands("assigned_to") {
+			// Unassigned chores and members who left expand to null
+			if member, ok := members[chore.AssignedTo]; ok {
+				item["assigned_to"] = member
+			} else {
+				item["assigned_to"] = nil
+			}
+		}
+		if shape.expands("group") {
+			item["group"] = groups[chore.GroupID]
+		}
+		shaped = append(shaped, item)
+	}
+	return shaped, nil
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/middleware"
 	"cribb-backend/models"
 	"encoding/json"
 	"errors"
@@ -35,11 +38,18 @@ func CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 	// Initialize with proper defaults (including group_code generation)
 	group = *models.NewGroup(group.Name)
 
+	// The authenticated creator becomes the group's first admin
+	if userClaims, ok := middleware.GetUserFromContext(r.Context()); ok {
+		if creatorID, err := primitive.ObjectIDFromHex(userClaims.ID); err == nil {
+			group.Admins = append(group.Admins, creatorID)
+		}
+	}
+
 	// Insert and get generated ID
 	result, err := config.DB.Collection("groups").InsertOne(context.Background(), group)
 	if err != nil {
 		if mongo.IsDuplicateKeyError(err) {
-			http.Error(w, "Group name already exists", http.StatusConflict)
+			writeError(w, r, apierror.CodeDuplicateItem, "Group name already exists")
 		} else {
 			log.Printf("Group creation error: %v", err)
 			http.Error(w, "Failed to create group", http.StatusInternalServerError)
@@ -380,6 +390,31 @@ func GetGroupLeaderboardHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Weekly and monthly boards are computed from completions within the current period
+	period := models.LeaderboardPeriod(r.URL.Query().Get("period"))
+	switch period {
+	case "", models.LeaderboardPeriodAllTime:
+	case models.LeaderboardPeriodWeekly, models.LeaderboardPeriodMonthly:
+		start, end := models.PeriodBounds(period, time.Now())
+		entries, err := jobs.ComputeLeaderboard(ctx, group.ID, start, end)
+		if err != nil {
+			log.Printf("GetGroupLeaderboardHandler compute error: %v", err)
+			http.Error(w, "Failed to compute leaderboard", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"period":       period,
+			"period_start": start,
+			"period_end":   end,
+			"entries":      entries,
+		})
+		return
+	default:
+		http.Error(w, "Invalid period. Must be weekly, monthly, or all_time", http.StatusBadRequest)
+		return
+	}
+
 	// Retrieve users for the group sorted by score DESC
 	opts := options.Find().SetSort(bson.D{{Key: "score", Value: -1}})
 	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"group_id": group.ID}, opts)
@@ -466,13 +501,23 @@ func LeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
 
 		// 2. Update group document: pull member
 		_, err := config.DB.Collection("groups").UpdateByID(sc, user.GroupID, bson.M{
-			"$pull": bson.M{"members": user.ID},
+			"$pull": bson.M{"members": user.ID, "admins": user.ID},
 			"$set":  bson.M{"updated_at": time.Now()},
 		})
 		if err != nil {
 			return fmt.Errorf("failed to update group: %v", err)
 		}
 
+		// The last admin leaving hands the group to its earliest remaining member
+		adminFilter := models.NoAdminsFilter()
+		adminFilter["_id"] = user.GroupID
+		_, err = config.DB.Collection("groups").UpdateOne(sc, adminFilter, mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"admins": bson.A{bson.M{"$arrayElemAt": bson.A{"$members", 0}}}}}},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update group admins: %v", err)
+		}
+
 		// 3. Update user document: unset group fields, optionally reset score
 		update := bson.M{
 			"$unset": bson.M{
@@ -488,12 +533,21 @@ func LeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
 
 		if !req.CarryForward {
 			update["$set"].(bson.M)["score"] = 0
+			update["$set"].(bson.M)["weekly_score"] = 0
+			update["$set"].(bson.M)["monthly_score"] = 0
 		}
 
 		if _, err := config.DB.Collection("users").UpdateByID(sc, user.ID, update); err != nil {
 			return fmt.Errorf("failed to update user: %v", err)
 		}
 
+		if !req.CarryForward && user.Score != 0 {
+			event := models.CreateScoreEvent(user.ID, user.GroupID, models.ScoreEventReset, -user.Score, 0, "Score reset on leaving the group")
+			if err := jobs.RecordScoreEvent(sc, event); err != nil {
+				return fmt.Errorf("failed to record score event: %v", err)
+			}
+		}
+
 		return nil
 	})
 
@@ -509,3 +563,488 @@ func LeaveGroupHandler(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(bson.M{"message": "left group successfully"})
 }
+
+// GetLeaderboardHistoryHandler lists archived leaderboard periods and their winners for a group
+func GetLeaderboardHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if period := r.URL.Query().Get("period"); period != "" {
+		if period != string(models.LeaderboardPeriodWeekly) && period != string(models.LeaderboardPeriodMonthly) {
+			http.Error(w, "Invalid period. Must be weekly or monthly", http.StatusBadRequest)
+			return
+		}
+		filter["period"] = period
+	}
+
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "period_start", Value: -1}}).SetLimit(52)
+	cursor, err := config.DB.Collection("leaderboard_snapshots").Find(ctx, filter, opts)
+	if err != nil {
+		log.Printf("GetLeaderboardHistoryHandler find error: %v", err)
+		http.Error(w, "Failed to fetch leaderboard history", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := make([]models.LeaderboardSnapshot, 0)
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		log.Printf("GetLeaderboardHistoryHandler cursor decode error: %v", err)
+		http.Error(w, "Failed to decode leaderboard history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+// CompareMembersHandler returns side-by-side stats for members of a group over a period.
+// Path format: /api/groups/{id}/compare?users=alice,bob&period=weekly|monthly|all_time
+func CompareMembersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	if !strings.HasSuffix(path, "/compare") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/compare"))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	usernames := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, username := range strings.Split(r.URL.Query().Get("users"), ",") {
+		if username = strings.TrimSpace(username); username != "" && !seen[username] {
+			seen[username] = true
+			usernames = append(usernames, username)
+		}
+	}
+	if len(usernames) < 2 || len(usernames) > 10 {
+		http.Error(w, "Between 2 and 10 usernames are required", http.StatusBadRequest)
+		return
+	}
+
+	period := models.LeaderboardPeriod(r.URL.Query().Get("period"))
+	if period == "" {
+		period = models.LeaderboardPeriodAllTime
+	}
+	if period != models.LeaderboardPeriodWeekly && period != models.LeaderboardPeriodMonthly && period != models.LeaderboardPeriodAllTime {
+		http.Error(w, "Invalid period. Must be weekly, monthly or all_time", http.StatusBadRequest)
+		return
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	if requester.GroupID != groupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	start, end := models.PeriodBounds(period, time.Now())
+
+	// Stats for every requested member in a single pipeline: users joined to their
+	// completions in the period, each joined to its chore to judge punctuality
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": groupID, "username": bson.M{"$in": usernames}}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": "chore_completions",
+			"let":  bson.M{"uid": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+					bson.M{"$eq": bson.A{"$user_id", "$$uid"}},
+					bson.M{"$gte": bson.A{"$completed_at", start}},
+					bson.M{"$lt": bson.A{"$completed_at", end}},
+				}}}},
+				bson.M{"$lookup": bson.M{
+					"from":         "chores",
+					"localField":   "chore_id",
+					"foreignField": "_id",
+					"as":           "chore",
+				}},
+				bson.M{"$unwind": bson.M{"path": "$chore", "preserveNullAndEmptyArrays": true}},
+				bson.M{"$group": bson.M{
+					"_id":         nil,
+					"completions": bson.M{"$sum": 1},
+					"points":      bson.M{"$sum": "$points"},
+					"rated": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$eq": bson.A{bson.M{"$type": "$chore.due_date"}, "date"}}, 1, 0,
+					}}},
+					"on_time": bson.M{"$sum": bson.M{"$cond": bson.A{
+						bson.M{"$and": bson.A{
+							bson.M{"$eq": bson.A{bson.M{"$type": "$chore.due_date"}, "date"}},
+							bson.M{"$lte": bson.A{"$completed_at", "$chore.due_date"}},
+						}}, 1, 0,
+					}}},
+				}},
+			},
+			"as": "stats",
+		}}},
+		{{Key: "$unwind", Value: bson.M{"path": "$stats", "preserveNullAndEmptyArrays": true}}},
+		{{Key: "$project", Value: bson.M{
+			"username":    1,
+			"name":        1,
+			"streak":      1,
+			"completions": bson.M{"$ifNull": bson.A{"$stats.completions", 0}},
+			"points":      bson.M{"$ifNull": bson.A{"$stats.points", 0}},
+			"rated":       bson.M{"$ifNull": bson.A{"$stats.rated", 0}},
+			"on_time":     bson.M{"$ifNull": bson.A{"$stats.on_time", 0}},
+		}}},
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("users").Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Printf("CompareMembersHandler aggregate error: %v", err)
+		http.Error(w, "Failed to compare members", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var members []models.MemberComparison
+	if err := cursor.All(ctx, &members); err != nil {
+		log.Printf("CompareMembersHandler cursor decode error: %v", err)
+		http.Error(w, "Failed to decode comparison", http.StatusInternalServerError)
+		return
+	}
+
+	if len(members) != len(usernames) {
+		http.Error(w, "One or more users not found in this group", http.StatusNotFound)
+		return
+	}
+
+	// Keep the order the members were requested in
+	byUsername := make(map[string]models.MemberComparison, len(members))
+	for _, member := range members {
+		member.ComputeOnTimeRate()
+		byUsername[member.Username] = member
+	}
+	ordered := make([]models.MemberComparison, 0, len(usernames))
+	for _, username := range usernames {
+		ordered = append(ordered, byUsername[username])
+	}
+
+	response := map[string]interface{}{
+		"period":  period,
+		"members": ordered,
+	}
+	if period != models.LeaderboardPeriodAllTime {
+		response["period_start"] = start
+		response["period_end"] = end
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UpdateGroupSettingsRequest defines the request structure for changing group settings
+type UpdateGroupSettingsRequest struct {
+	ResetPeriodScores   *bool                          `json:"reset_period_scores"`
+	DecayPercent        *int                           `json:"decay_percent"`
+	DecayInactiveWeeks  *int                           `json:"decay_inactive_weeks"`
+	Scoring             *UpdateScoringRequest          `json:"scoring"`
+	AutoAddToPantry     *bool                          `json:"auto_add_to_pantry"`
+	AutoCreateExpenses  *bool                          `json:"auto_create_expenses"`
+	AisleOrder          *[]string                      `json:"aisle_order"` // Pantry category IDs in store order
+	ExpirationAlertDays *int                           `json:"expiration_alert_days"`
+	Currency            *string                        `json:"currency"`
+	PaymentReminders    *UpdatePaymentRemindersRequest `json:"payment_reminders"`
+	MaxGuestNights      *int                           `json:"max_guest_nights"` // 0 removes the limit
+	QuietHours          *models.QuietHours             `json:"quiet_hours"`      // Empty start and end remove them
+	KudosBudget         *int                           `json:"kudos_budget"`     // Monthly points; 0 means kudos carry no points
+}
+
+// UpdateScoringRequest changes some of the group's scoring rules
+type UpdateScoringRequest struct {
+	PointsPerCompletion *int `json:"points_per_completion"`
+	OverduePenalty      *int `json:"overdue_penalty"`
+}
+
+// UpdatePaymentRemindersRequest changes some of the group's payment reminder rules
+type UpdatePaymentRemindersRequest struct {
+	Disabled     *bool    `json:"disabled"`
+	Threshold    *float64 `json:"threshold"`
+	AfterDays    *int     `json:"after_days"`
+	IntervalDays *int     `json:"interval_days"`
+}
+
+// UpdateGroupSettingsHandler lets a group admin change the group's settings.
+// Only the fields present in the request are changed. On PATCH the request is a JSON Merge Patch
+// of the settings, where null resets a setting to its default.
+func UpdateGroupSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UpdateGroupSettingsRequest
+
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can change group settings", http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		patch, ok := readMergePatch(w, r, groupSettingsFields...)
+		if !ok {
+			return
+		}
+		if request, ok = groupSettingsPatchRequest(w, group.Settings, patch); !ok {
+			return
+		}
+	}
+
+	updateFields := bson.M{"updated_at": time.Now()}
+	if request.ResetPeriodScores != nil {
+		updateFields["settings.reset_period_scores"] = *request.ResetPeriodScores
+	}
+	if request.DecayPercent != nil {
+		if *request.DecayPercent < 0 || *request.DecayPercent > 100 {
+			http.Error(w, "Decay percent must be between 0 and 100", http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.decay_percent"] = *request.DecayPercent
+	}
+	if request.DecayInactiveWeeks != nil {
+		if *request.DecayInactiveWeeks < 1 {
+			http.Error(w, "Decay inactive weeks must be at least 1", http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.decay_inactive_weeks"] = *request.DecayInactiveWeeks
+	}
+	if request.Scoring != nil {
+		// Merge the provided rules into the current ones so partial updates are validated as a whole
+		scoring := group.Settings.Scoring
+		if scoring.PointsPerCompletion < 1 {
+			scoring.PointsPerCompletion = models.DefaultPointsPerCompletion
+		}
+		if request.Scoring.PointsPerCompletion != nil {
+			scoring.PointsPerCompletion = *request.Scoring.PointsPerCompletion
+		}
+		if request.Scoring.OverduePenalty != nil {
+			scoring.OverduePenalty = *request.Scoring.OverduePenalty
+		}
+		if err := scoring.Validate(); err != nil {
+			http.Error(w, "Invalid scoring rules: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.scoring"] = scoring
+	}
+	if request.AutoAddToPantry != nil {
+		updateFields["settings.auto_add_to_pantry"] = *request.AutoAddToPantry
+	}
+	if request.AutoCreateExpenses != nil {
+		updateFields["settings.auto_create_expenses"] = *request.AutoCreateExpenses
+	}
+	if request.AisleOrder != nil {
+		aisleOrder := make([]primitive.ObjectID, 0, len(*request.AisleOrder))
+		for _, categoryID := range *request.AisleOrder {
+			category, err := validateCategoryID(categoryID, group.ID)
+			if err != nil {
+				http.Error(w, "Invalid aisle order: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			aisleOrder = append(aisleOrder, category.ID)
+		}
+		updateFields["settings.aisle_order"] = aisleOrder
+	}
+	if request.ExpirationAlertDays != nil {
+		if *request.ExpirationAlertDays < 1 || *request.ExpirationAlertDays > models.MaxExpirationAlertDays {
+			http.Error(w, fmt.Sprintf("Expiration alert days must be between 1 and %d", models.MaxExpirationAlertDays), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.expiration_alert_days"] = *request.ExpirationAlertDays
+	}
+	if request.Currency != nil {
+		code := models.NormalizeCurrency(*request.Currency)
+		if !models.IsValidCurrency(code) {
+			http.Error(w, "Currency must be a three-letter ISO 4217 code", http.StatusBadRequest)
+			return
+		}
+		if code != group.Settings.CurrencyCode() {
+			// Existing expenses and settlements are in the old currency, so switching would break the balances
+			count, err := config.DB.Collection("expenses").CountDocuments(context.Background(), bson.M{"group_id": group.ID}, options.Count().SetLimit(1))
+			if err != nil {
+				log.Printf("UpdateGroupSettingsHandler expense count error: %v", err)
+				http.Error(w, "Failed to update group settings", http.StatusInternalServerError)
+				return
+			}
+			if count > 0 {
+				writeError(w, r, apierror.CodeCurrencyLocked, "The currency cannot be changed once the group has expenses")
+				return
+			}
+		}
+		updateFields["settings.currency"] = code
+	}
+	if request.PaymentReminders != nil {
+		rules := group.Settings.PaymentReminders
+		if request.PaymentReminders.Disabled != nil {
+			rules.Disabled = *request.PaymentReminders.Disabled
+		}
+		if request.PaymentReminders.Threshold != nil {
+			rules.Threshold = *request.PaymentReminders.Threshold
+		}
+		if request.PaymentReminders.AfterDays != nil {
+			rules.AfterDays = *request.PaymentReminders.AfterDays
+		}
+		if request.PaymentReminders.IntervalDays != nil {
+			rules.IntervalDays = *request.PaymentReminders.IntervalDays
+		}
+		if err := rules.Validate(); err != nil {
+			http.Error(w, "Invalid payment reminders: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.payment_reminders"] = rules
+	}
+	if request.MaxGuestNights != nil {
+		if *request.MaxGuestNights < 0 || *request.MaxGuestNights > models.MaxGuestNightsLimit {
+			http.Error(w, fmt.Sprintf("Max guest nights must be between 0 and %d", models.MaxGuestNightsLimit), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.max_guest_nights"] = *request.MaxGuestNights
+	}
+	if request.QuietHours != nil {
+		quietHours := *request.QuietHours
+		quietHours.TimeZone = strings.TrimSpace(quietHours.TimeZone)
+		if err := quietHours.Validate(); err != nil {
+			http.Error(w, "Invalid quiet hours: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.quiet_hours"] = quietHours
+	}
+	if request.KudosBudget != nil {
+		if *request.KudosBudget < 0 || *request.KudosBudget > models.MaxKudosBudget {
+			http.Error(w, fmt.Sprintf("Kudos budget must be between 0 and %d", models.MaxKudosBudget), http.StatusBadRequest)
+			return
+		}
+		updateFields["settings.kudos_budget"] = *request.KudosBudget
+	}
+
+	var updatedGroup models.Group
+	err := config.DB.Collection("groups").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": group.ID},
+		bson.M{"$set": updateFields},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updatedGroup)
+	if err != nil {
+		log.Printf("UpdateGroupSettingsHandler update error: %v", err)
+		http.Error(w, "Failed to update group settings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updatedGroup.Settings)
+}
+
+// groupSettingsFields are the settings a merge patch can change
+var groupSettingsFields = []string{
+	"reset_period_scores", "decay_percent", "decay_inactive_weeks", "scoring", "auto_add_to_pantry",
+	"auto_create_expenses", "aisle_order", "expiration_alert_days", "currency", "payment_reminders",
+	"max_guest_nights", "quiet_hours", "kudos_budget",
+}
+
+// groupSettingsPatchRequest merges the patch into the group's settings and returns the settings it
+// names as an update, so they are checked like any other. Cleared settings go back to their zero
+// value, which is their default.
+// On failure it writes the error response and returns false.
+func groupSettingsPatchRequest(w http.ResponseWriter, settings models.GroupSettings, patch models.MergePatch) (UpdateGroupSettingsRequest, bool) {
+	var request UpdateGroupSettingsRequest
+	if err := patch.Apply(&settings); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return request, false
+	}
+
+	if patch.Has("reset_period_scores") {
+		request.ResetPeriodScores = &settings.ResetPeriodScores
+	}
+	if patch.Has("decay_percent") {
+		request.DecayPercent = &settings.DecayPercent
+	}
+	if patch.Has("decay_inactive_weeks") {
+		request.DecayInactiveWeeks = &settings.DecayInactiveWeeks
+	}
+	if patch.Has("scoring") {
+		if settings.Scoring.PointsPerCompletion < 1 {
+			settings.Scoring.PointsPerCompletion = models.DefaultPointsPerCompletion
+		}
+		request.Scoring = &UpdateScoringRequest{
+			PointsPerCompletion: &settings.Scoring.PointsPerCompletion,
+			OverduePenalty:      &settings.Scoring.OverduePenalty,
+		}
+	}
+	if patch.Has("auto_add_to_pantry") {
+		request.AutoAddToPantry = &settings.AutoAddToPantry
+	}
+	if patch.Has("auto_create_expenses") {
+		request.AutoCreateExpenses = &settings.AutoCreateExpenses
+	}
+	if patch.Has("aisle_order") {
+		aisleOrder := make([]string, 0, len(settings.AisleOrder))
+		for _, categoryID := range settings.AisleOrder {
+			aisleOrder = append(aisleOrder, categoryID.Hex())
+		}
+		request.AisleOrder = &aisleOrder
+	}
+	if patch.Has("expiration_alert_days") {
+		alertDays := settings.ExpirationAlertWindow()
+		request.ExpirationAlertDays = &alertDays
+	}
+	if patch.Has("currency") {
+		currency := settings.CurrencyCode()
+		request.Currency = &currency
+	}
+	if patch.Has("payment_reminders") {
+		rules := settings.PaymentReminders
+		request.PaymentReminders = &UpdatePaymentRemindersRequest{
+			Disabled:     &rules.Disabled,
+			Threshold:    &rules.Threshold,
+			AfterDays:    &rules.AfterDays,
+			IntervalDays: &rules.IntervalDays,
+		}
+	}
+	if patch.Has("max_guest_nights") {
+		request.MaxGuestNights = &settings.MaxGuestNights
+	}
+	if patch.Has("quiet_hours") {
+		request.QuietHours = &settings.QuietHours
+	}
+	if patch.Has("kudos_budget") {
+		request.KudosBudget = &settings.KudosBudget
+	}
+	return request, true
+}
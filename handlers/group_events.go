@@ -0,0 +1,76 @@
+// handlers/group_events.go
+package handlers
+
+import (
+	"cribb-backend/models"
+	"cribb-backend/realtime"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// GroupEventsHandler upgrades to a WebSocket that streams live events in the user's group: chores
+// completed, items added to the shopping list, expenses added, chat messages, members typing in
+// the chat, kudos and direct messages to or from the user. Each message is a JSON object with the
+// event type, the group ID, the data of the chore, shopping event, expense, message, typing member
+// or kudos and when it happened. Clients send {"type": "chat.typing"} while the user types in the chat.
+// Browsers cannot set headers on WebSockets, so the token may be sent as access_token.
+func GroupEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := realtime.Upgrade(w, r)
+	if err != nil {
+		return // Upgrade already answered the request
+	}
+	defer conn.Close()
+
+	events, unsubscribe := realtime.Groups.Subscribe(user.GroupID)
+	defer unsubscribe()
+
+	// The read loop answers the client's pings, notices when it goes away and records typing,
+	// at most once per throttle period
+	closed := make(chan struct{})
+	go func() {
+		var lastTyping time.Time
+		conn.ReadMessages(func(payload []byte) {
+			var message struct {
+				Type models.GroupEventType `json:"type"`
+			}
+			if json.Unmarshal(payload, &message) != nil || message.Type != models.GroupEventChatTyping {
+				return
+			}
+			if time.Since(lastTyping) < models.TypingThrottle {
+				return
+			}
+			lastTyping = time.Now()
+			recordChatTyping(user)
+		})
+		close(closed)
+	}()
+
+	heartbeat := time.NewTicker(realtime.PingInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-heartbeat.C:
+			if err := conn.Ping(); err != nil {
+				return
+			}
+		case event := <-events:
+			if !event.VisibleTo(user.ID) {
+				continue
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				log.Printf("Failed to send group event to user %s: %v", user.ID.Hex(), err)
+				return
+			}
+		}
+	}
+}
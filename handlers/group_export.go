@@ -0,0 +1,209 @@
+// handlers/group_export.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// groupExportPath serves export archives through signed URLs: /api/groups/exports/{file_id}
+const groupExportPath = "/api/groups/exports/"
+
+// GroupExportDetails is a group export with a download link once it is ready
+type GroupExportDetails struct {
+	models.GroupExport
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// RequestGroupExportHandler queues an export of all of the group's data for backup or moving to
+// another deployment. The export is written in the background; its download link shows up in the
+// group's exports once it is ready. Only group admins can export.
+// Path format: /api/groups/{id}/export
+// Query: ?format=json (default, one JSON document) or ndjson (a zip of one file per collection)
+func RequestGroupExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	group, requester, ok := getExportGroup(w, r, "/export")
+	if !ok {
+		return
+	}
+
+	format := models.GroupExportFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = models.GroupExportJSON
+	}
+	if !models.IsValidGroupExportFormat(format) {
+		http.Error(w, "Format must be json or ndjson", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	inProgress, err := config.DB.Collection("group_exports").CountDocuments(ctx, bson.M{
+		"group_id": group.ID,
+		"status":   bson.M{"$in": []models.GroupExportStatus{models.GroupExportPending, models.GroupExportRunning}},
+	})
+	if err != nil {
+		log.Printf("Failed to check exports of group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to request export", http.StatusInternalServerError)
+		return
+	}
+	if inProgress > 0 {
+		writeError(w, r, apierror.CodeInProgress, "An export of this group is already in progress")
+		return
+	}
+
+	export := models.CreateGroupExport(group.ID, requester.ID, format)
+	result, err := config.DB.Collection("group_exports").InsertOne(ctx, export)
+	if err != nil {
+		log.Printf("Failed to request export of group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to request export", http.StatusInternalServerError)
+		return
+	}
+	export.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(GroupExportDetails{GroupExport: *export})
+}
+
+// GetGroupExportsHandler lists the group's exports, newest first, with download links for the
+// finished ones. Only group admins can see them.
+// Path format: /api/groups/{id}/exports
+func GetGroupExportsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	group, _, ok := getExportGroup(w, r, "/exports")
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("group_exports").Find(
+		ctx,
+		bson.M{"group_id": group.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch exports of group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to fetch exports", http.StatusInternalServerError)
+		return
+	}
+	var exports []models.GroupExport
+	if err := cursor.All(ctx, &exports); err != nil {
+		http.Error(w, "Failed to decode exports", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	details := make([]GroupExportDetails, 0, len(exports))
+	for _, export := range exports {
+		detail := GroupExportDetails{GroupExport: export}
+		if export.Status == models.GroupExportCompleted && export.FileID != nil {
+			detail.DownloadURL = storage.SignedURL(groupExportPath, *export.FileID, now)
+		}
+		details = append(details, detail)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// ServeGroupExportHandler downloads an export archive from a signed URL handed out in the group's
+// exports
+func ServeGroupExportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, groupExportPath))
+	if err != nil {
+		http.Error(w, "Invalid export ID format", http.StatusBadRequest)
+		return
+	}
+	if err := storage.VerifySignature(fileID, r.URL.Query(), time.Now()); err != nil {
+		http.Error(w, "Export link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	ctx := context.Background()
+	var export models.GroupExport
+	if err := config.DB.Collection("group_exports").FindOne(ctx, bson.M{"file_id": fileID}).Decode(&export); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Export not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch export", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	archive, contentType, err := storage.Open(ctx, fileID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Export not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open group export %s: %v", export.ID.Hex(), err)
+			http.Error(w, "Failed to fetch export", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+export.FileName()+`"`)
+	w.Header().Set("Cache-Control", "private, no-store")
+	if _, err := io.Copy(w, archive); err != nil {
+		log.Printf("Failed to send group export %s: %v", export.ID.Hex(), err)
+	}
+}
+
+// getExportGroup loads the group in the path, which ends in suffix, for one of its admins.
+// On failure it writes the error response and returns false.
+func getExportGroup(w http.ResponseWriter, r *http.Request, suffix string) (models.Group, models.User, bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, suffix))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return models.Group{}, models.User{}, false
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return models.Group{}, requester, false
+	}
+	if requester.GroupID != groupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return models.Group{}, requester, false
+	}
+
+	group, ok := getGroupByID(w, groupID)
+	if !ok {
+		return group, requester, false
+	}
+	if !group.IsAdmin(requester.ID) {
+		http.Error(w, "Only group admins can export the group's data", http.StatusForbidden)
+		return group, requester, false
+	}
+	return group, requester, true
+}
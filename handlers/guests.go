@@ -0,0 +1,184 @@
+// handlers/guests.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RegisterGuestRequest defines the request structure for registering an overnight guest
+type RegisterGuestRequest struct {
+	GuestName string    `json:"guest_name"`
+	ArriveOn  time.Time `json:"arrive_on"`
+	DepartOn  time.Time `json:"depart_on"`
+	Notes     string    `json:"notes,omitempty"`
+}
+
+// GuestsHandler lists the group's current and upcoming guests, earliest arrival first, on GET and
+// registers a guest of the requesting member on POST. Stays that would keep a guest longer than
+// the group's max_guest_nights in a row, counting the guest's adjoining stays, are rejected.
+func GuestsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listGuests(w, group)
+	case http.MethodPost:
+		registerGuest(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listGuests returns the group's stays that have not ended yet
+func listGuests(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("guest_stays").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "depart_on": bson.M{"$gte": time.Now().UTC().Truncate(24 * time.Hour)}},
+		options.Find().SetSort(bson.D{{Key: "arrive_on", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch guests", http.StatusInternalServerError)
+		return
+	}
+	stays := make([]models.GuestStay, 0)
+	if err := cursor.All(context.Background(), &stays); err != nil {
+		http.Error(w, "Failed to decode guests", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stays)
+}
+
+// registerGuest records the stay, checks it against the group's limit and tells the other members
+func registerGuest(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request RegisterGuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(request.GuestName) == "" {
+		http.Error(w, "Guest name is required", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if err := models.ValidateGuestStayDates(request.ArriveOn, request.DepartOn, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stay := models.CreateGuestStay(group.ID, user.ID, request.GuestName, request.ArriveOn, request.DepartOn, request.Notes)
+
+	ctx := context.Background()
+	if group.Settings.MaxGuestNights > 0 {
+		// Stays further apart than the longest possible stay cannot join up with this one
+		reach := time.Duration(models.MaxGuestNightsLimit) * 24 * time.Hour
+		cursor, err := config.DB.Collection("guest_stays").Find(ctx, bson.M{
+			"group_id":  group.ID,
+			"arrive_on": bson.M{"$lte": stay.DepartOn.Add(reach)},
+			"depart_on": bson.M{"$gte": stay.ArriveOn.Add(-reach)},
+		})
+		if err != nil {
+			http.Error(w, "Failed to fetch guests", http.StatusInternalServerError)
+			return
+		}
+		var others []models.GuestStay
+		if err := cursor.All(ctx, &others); err != nil {
+			http.Error(w, "Failed to decode guests", http.StatusInternalServerError)
+			return
+		}
+		if nights := models.ConsecutiveGuestNights(*stay, others); group.Settings.GuestNightsExceeded(nights) {
+			http.Error(w, fmt.Sprintf("Guests can stay at most %d nights in a row; %s would stay %d", group.Settings.MaxGuestNights, stay.GuestName, nights), http.StatusConflict)
+			return
+		}
+	}
+
+	// The registration already tells members about guests arriving within the notice period
+	if stay.ArriveOn.Before(now.Add(models.GuestArrivalNotice)) {
+		stay.ArrivalNotifiedAt = &now
+	}
+
+	result, err := config.DB.Collection("guest_stays").InsertOne(ctx, stay)
+	if err != nil {
+		log.Printf("Failed to register guest: %v", err)
+		http.Error(w, "Failed to register guest", http.StatusInternalServerError)
+		return
+	}
+	stay.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.GuestRegisteredNotification(*stay, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(stay)
+}
+
+// GuestHandler handles DELETE /api/groups/guests/{id}, which cancels a stay. Only the host and
+// group admins can cancel it.
+func GuestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	stayID, err := primitive.ObjectIDFromHex(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/guests/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid guest ID", http.StatusBadRequest)
+		return
+	}
+	var stay models.GuestStay
+	err = config.DB.Collection("guest_stays").FindOne(context.Background(), bson.M{"_id": stayID, "group_id": group.ID}).Decode(&stay)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Guest not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch guest", http.StatusInternalServerError)
+		}
+		return
+	}
+	if stay.HostID != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the host and group admins can cancel a guest's stay", http.StatusForbidden)
+		return
+	}
+
+	if _, err := config.DB.Collection("guest_stays").DeleteOne(context.Background(), bson.M{"_id": stay.ID}); err != nil {
+		http.Error(w, "Failed to cancel guest's stay", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+// handlers/helpers.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// getRequestUser loads the authenticated user for the request.
+// On failure it writes the error response and returns false.
+func getRequestUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	var user models.User
+
+	userClaims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not authenticated", http.StatusUnauthorized)
+		return user, false
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return user, false
+	}
+
+	err = config.DB.Collection("users").FindOne(
+		context.Background(),
+		bson.M{"_id": userID},
+	).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "User not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+		}
+		return user, false
+	}
+
+	return user, true
+}
+
+// getGroupByID loads a group by ID.
+// On failure it writes the error response and returns false.
+func getGroupByID(w http.ResponseWriter, groupID primitive.ObjectID) (models.Group, bool) {
+	var group models.Group
+	err := config.DB.Collection("groups").FindOne(
+		context.Background(),
+		bson.M{"_id": groupID},
+	).Decode(&group)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Group not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch group", http.StatusInternalServerError)
+		}
+		return group, false
+	}
+	return group, true
+}
+
+// getRecurringChoreByID loads a recurring chore from its hex ID.
+// On failure it writes the error response and returns false.
+func getRecurringChoreByID(w http.ResponseWriter, recurringChoreIDStr string) (models.RecurringChore, bool) {
+	var recurringChore models.RecurringChore
+
+	if recurringChoreIDStr == "" {
+		http.Error(w, "Recurring chore ID is required", http.StatusBadRequest)
+		return recurringChore, false
+	}
+
+	recurringChoreID, err := primitive.ObjectIDFromHex(recurringChoreIDStr)
+	if err != nil {
+		http.Error(w, "Invalid recurring chore ID format", http.StatusBadRequest)
+		return recurringChore, false
+	}
+
+	err = config.DB.Collection("recurring_chores").FindOne(
+		context.Background(),
+		bson.M{"_id": recurringChoreID},
+	).Decode(&recurringChore)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring chore not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch recurring chore", http.StatusInternalServerError)
+		}
+		return recurringChore, false
+	}
+
+	return recurringChore, true
+}
+
+// readMergePatch reads a JSON Merge Patch request body, sent as application/merge-patch+json or
+// plain JSON, and checks it only changes the allowed members.
+// On failure it writes the error response and returns false.
+func readMergePatch(w http.ResponseWriter, r *http.Request, allowed ...string) (models.MergePatch, bool) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != models.MergePatchContentType && mediaType != "application/json") {
+			http.Error(w, "PATCH requests must be sent as "+models.MergePatchContentType, http.StatusUnsupportedMediaType)
+			return nil, false
+		}
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return nil, false
+	}
+	patch, err := models.ParseMergePatch(body)
+	if err == nil {
+		err = patch.Allow(allowed...)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return patch, true
+}
+
+// writeError responds with a coded error, see middleware.WriteError
+func writeError(w http.ResponseWriter, r *http.Request, code apierror.Code, message string) {
+	middleware.WriteError(w, r, apierror.New(code, message))
+}
@@ -0,0 +1,279 @@
+// handlers/house_events.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateHouseEventRequest defines the request structure for adding a house event
+type CreateHouseEventRequest struct {
+	Type             string    `json:"type"` // party, maintenance, inspection or other (default)
+	Title            string    `json:"title"`
+	Description      string    `json:"description,omitempty"`
+	Location         string    `json:"location,omitempty"`
+	StartsAt         time.Time `json:"starts_at"`
+	EndsAt           time.Time `json:"ends_at"`
+	TimeZone         string    `json:"time_zone,omitempty"`          // IANA name such as America/New_York; defaults to UTC
+	ReminderMinutes  *int      `json:"reminder_minutes,omitempty"`   // Before the start; 0 turns reminders off, defaults to 60
+	IgnoreQuietHours bool      `json:"ignore_quiet_hours,omitempty"` // Create the event even if it runs into members' quiet hours
+}
+
+// RSVPRequest defines the request structure for answering a house event
+type RSVPRequest struct {
+	Status string `json:"status"` // going, maybe or not_going
+}
+
+// HouseEventResponse is a house event with the members whose quiet hours it runs into
+type HouseEventResponse struct {
+	models.HouseEvent
+	QuietHourConflicts []models.QuietHourConflict `json:"quiet_hour_conflicts"`
+}
+
+// HouseEventsHandler lists the group's upcoming and ongoing house events, earliest first, on GET
+// and adds one on POST. Events that run into other members' quiet hours are rejected with 409 and
+// the conflicts unless ignore_quiet_hours is set.
+func HouseEventsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listHouseEvents(w, group)
+	case http.MethodPost:
+		createHouseEvent(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listHouseEvents returns the group's events that have not ended yet
+func listHouseEvents(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("house_events").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "ends_at": bson.M{"$gt": time.Now()}},
+		options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch house events", http.StatusInternalServerError)
+		return
+	}
+	events := make([]models.HouseEvent, 0)
+	if err := cursor.All(context.Background(), &events); err != nil {
+		http.Error(w, "Failed to decode house events", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// createHouseEvent adds an event, checking it against the members' quiet hours, and tells the
+// other members about it
+func createHouseEvent(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreateHouseEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Title = strings.TrimSpace(request.Title)
+	if request.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+	eventType := models.HouseEventOther
+	if request.Type != "" {
+		eventType = models.HouseEventType(request.Type)
+	}
+	if !models.IsValidHouseEventType(eventType) {
+		http.Error(w, "Type must be party, maintenance, inspection or other", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateHouseEventTimes(request.StartsAt, request.EndsAt, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := time.LoadLocation(request.TimeZone); err != nil {
+		http.Error(w, fmt.Sprintf("Unknown time zone %q", request.TimeZone), http.StatusBadRequest)
+		return
+	}
+	reminder := models.DefaultHouseEventReminder
+	if request.ReminderMinutes != nil {
+		reminder = time.Duration(*request.ReminderMinutes) * time.Minute
+		if reminder < 0 || reminder > models.MaxHouseEventReminder {
+			http.Error(w, fmt.Sprintf("Reminder must be between 0 and %d minutes", int(models.MaxHouseEventReminder.Minutes())), http.StatusBadRequest)
+			return
+		}
+	}
+
+	event := models.CreateHouseEvent(group.ID, user.ID, eventType, request.Title, request.StartsAt, request.EndsAt, request.TimeZone, reminder)
+	event.Description = strings.TrimSpace(request.Description)
+	event.Location = strings.TrimSpace(request.Location)
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": group.Members}})
+	if err != nil {
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return
+	}
+	var members []models.User
+	if err := cursor.All(ctx, &members); err != nil {
+		http.Error(w, "Failed to decode group members", http.StatusInternalServerError)
+		return
+	}
+
+	conflicts := event.QuietHourConflicts(members)
+	if len(conflicts) > 0 && !request.IgnoreQuietHours {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":                "The event runs into members' quiet hours; set ignore_quiet_hours to add it anyway",
+			"quiet_hour_conflicts": conflicts,
+		})
+		return
+	}
+
+	result, err := config.DB.Collection("house_events").InsertOne(ctx, event)
+	if err != nil {
+		log.Printf("Failed to create house event: %v", err)
+		http.Error(w, "Failed to create house event", http.StatusInternalServerError)
+		return
+	}
+	event.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(members))
+	for _, member := range members {
+		if member.ID != user.ID {
+			notifications = append(notifications, models.HouseEventCreatedNotification(event, member.ID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(HouseEventResponse{HouseEvent: *event, QuietHourConflicts: conflicts})
+}
+
+// HouseEventHandler handles one of the group's house events: DELETE
+// /api/groups/house-events/{id} cancels it, which its creator and group admins can do, and
+// POST /api/groups/house-events/{id}/rsvp records the member's answer.
+func HouseEventHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/house-events/"), "/"), "/")
+	eventID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid house event ID", http.StatusBadRequest)
+		return
+	}
+	var event models.HouseEvent
+	err = config.DB.Collection("house_events").FindOne(context.Background(), bson.M{"_id": eventID, "group_id": group.ID}).Decode(&event)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "House event not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch house event", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		deleteHouseEvent(w, user, group, event)
+	case action == "rsvp" && r.Method == http.MethodPost:
+		rsvpHouseEvent(w, r, user, event)
+	case action == "" || action == "rsvp":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// deleteHouseEvent cancels the event
+func deleteHouseEvent(w http.ResponseWriter, user models.User, group models.Group, event models.HouseEvent) {
+	if event.CreatedBy != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the event's creator and group admins can cancel it", http.StatusForbidden)
+		return
+	}
+	if _, err := config.DB.Collection("house_events").DeleteOne(context.Background(), bson.M{"_id": event.ID}); err != nil {
+		http.Error(w, "Failed to cancel house event", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// rsvpHouseEvent records the member's answer to the event
+func rsvpHouseEvent(w http.ResponseWriter, r *http.Request, user models.User, event models.HouseEvent) {
+	var request RSVPRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	status := models.RSVPStatus(request.Status)
+	if !models.IsValidRSVPStatus(status) {
+		http.Error(w, "Status must be going, maybe or not_going", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if !event.EndsAt.After(now) {
+		http.Error(w, "The event is over", http.StatusConflict)
+		return
+	}
+
+	// Replace the member's answer in place so answers from other members are not overwritten
+	ctx := context.Background()
+	collection := config.DB.Collection("house_events")
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": event.ID, "rsvps.user_id": user.ID},
+		bson.M{"$set": bson.M{"rsvps.$.status": status, "rsvps.$.responded_at": now, "updated_at": now}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		_, err = collection.UpdateOne(ctx,
+			bson.M{"_id": event.ID, "rsvps.user_id": bson.M{"$ne": user.ID}},
+			bson.M{
+				"$push": bson.M{"rsvps": models.HouseEventRSVP{UserID: user.ID, Status: status, RespondedAt: now}},
+				"$set":  bson.M{"updated_at": now},
+			},
+		)
+	}
+	if err != nil {
+		http.Error(w, "Failed to record RSVP", http.StatusInternalServerError)
+		return
+	}
+	event.SetRSVP(user.ID, status, now)
+	event.UpdatedAt = now
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(event)
+}
@@ -0,0 +1,314 @@
+// handlers/house_rules.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// HouseRulesRequest defines the request structure for writing a new version of the house rules
+type HouseRulesRequest struct {
+	Body string `json:"body"`
+	Note string `json:"note"`
+}
+
+// AcknowledgeHouseRulesRequest defines the request structure for accepting the house rules. The
+// version is the one the member read, so an edit made meanwhile is not accepted unseen.
+type AcknowledgeHouseRulesRequest struct {
+	Version int `json:"version"`
+}
+
+// HouseRulesDetails is the version of the house rules in force and whether the requester has
+// accepted it
+type HouseRulesDetails struct {
+	models.HouseRules
+	Acknowledged   bool       `json:"acknowledged"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// HouseRulesAcknowledgments is who in the group has accepted the house rules in force
+type HouseRulesAcknowledgments struct {
+	Version int                           `json:"version"`
+	Pending int                           `json:"pending"`
+	Members []models.MemberAcknowledgment `json:"members"`
+}
+
+// currentHouseRules returns the group's latest version of the house rules, or nil when none have
+// been written
+func currentHouseRules(ctx context.Context, groupID primitive.ObjectID) (*models.HouseRules, error) {
+	var rules models.HouseRules
+	err := config.DB.Collection("house_rules").FindOne(ctx,
+		bson.M{"group_id": groupID},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+	).Decode(&rules)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// HouseRulesHandler returns the house rules in force on GET, with whether the requester has
+// accepted them, and writes a new version on PUT. Only group admins can change the rules, and
+// every member is asked to accept each new version.
+func HouseRulesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		getHouseRules(w, r)
+	case http.MethodPut:
+		updateHouseRules(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// getHouseRules returns the house rules in force and the requester's acknowledgment of them
+func getHouseRules(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	rules, err := currentHouseRules(ctx, user.GroupID)
+	if err != nil {
+		http.Error(w, "Failed to fetch house rules", http.StatusInternalServerError)
+		return
+	}
+	if rules == nil {
+		http.Error(w, "The group has no house rules yet", http.StatusNotFound)
+		return
+	}
+
+	details := HouseRulesDetails{HouseRules: *rules}
+	var acknowledgment models.HouseRulesAcknowledgment
+	err = config.DB.Collection("house_rule_acknowledgments").FindOne(ctx, bson.M{
+		"group_id": user.GroupID,
+		"user_id":  user.ID,
+		"version":  rules.Version,
+	}).Decode(&acknowledgment)
+	switch {
+	case err == nil:
+		details.Acknowledged = true
+		details.AcknowledgedAt = &acknowledgment.AcknowledgedAt
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		http.Error(w, "Failed to fetch acknowledgment", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// updateHouseRules writes the next version of the house rules and asks the other members to
+// accept it. The editor accepts their own version.
+func updateHouseRules(w http.ResponseWriter, r *http.Request) {
+	user, group, ok := getAdminGroup(w, r, "change the house rules")
+	if !ok {
+		return
+	}
+	var request HouseRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateHouseRules(request.Body, request.Note); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	current, err := currentHouseRules(ctx, group.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch house rules", http.StatusInternalServerError)
+		return
+	}
+	rules := models.CreateHouseRules(group.ID, user.ID, current, request.Body, request.Note)
+	result, err := config.DB.Collection("house_rules").InsertOne(ctx, rules)
+	if err != nil {
+		// Versions are unique per group, so a concurrent edit loses rather than overwriting
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeEditConflict, "The house rules were changed meanwhile; reload them and try again")
+			return
+		}
+		log.Printf("Failed to save house rules: %v", err)
+		http.Error(w, "Failed to save house rules", http.StatusInternalServerError)
+		return
+	}
+	rules.ID = result.InsertedID.(primitive.ObjectID)
+
+	if _, err := config.DB.Collection("house_rule_acknowledgments").InsertOne(ctx, models.CreateHouseRulesAcknowledgment(rules, user.ID)); err != nil {
+		log.Printf("Failed to record house rules acknowledgment for user %s: %v", user.ID.Hex(), err)
+	}
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.HouseRulesUpdatedNotification(rules, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rules)
+}
+
+// HouseRulesActionHandler handles GET /api/groups/house-rules/versions, which lists every version
+// of the rules newest first, POST /api/groups/house-rules/acknowledge, which accepts the version in
+// force, and GET /api/groups/house-rules/acknowledgments, which shows admins who has not accepted
+// it yet.
+func HouseRulesActionHandler(w http.ResponseWriter, r *http.Request) {
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/house-rules/"), "/")
+	switch {
+	case action == "versions" && r.Method == http.MethodGet:
+		listHouseRulesVersions(w, r)
+	case action == "acknowledge" && r.Method == http.MethodPost:
+		acknowledgeHouseRules(w, r)
+	case action == "acknowledgments" && r.Method == http.MethodGet:
+		getHouseRulesAcknowledgments(w, r)
+	case action == "versions" || action == "acknowledge" || action == "acknowledgments":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// listHouseRulesVersions returns every version of the group's house rules, newest first
+func listHouseRulesVersions(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("house_rules").Find(ctx,
+		bson.M{"group_id": user.GroupID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch house rules", http.StatusInternalServerError)
+		return
+	}
+	versions := make([]models.HouseRules, 0)
+	if err := cursor.All(ctx, &versions); err != nil {
+		http.Error(w, "Failed to decode house rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(versions)
+}
+
+// acknowledgeHouseRules records the member accepting the version of the rules in force. Accepting
+// it again changes nothing.
+func acknowledgeHouseRules(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	var request AcknowledgeHouseRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	rules, err := currentHouseRules(ctx, user.GroupID)
+	if err != nil {
+		http.Error(w, "Failed to fetch house rules", http.StatusInternalServerError)
+		return
+	}
+	if rules == nil {
+		http.Error(w, "The group has no house rules yet", http.StatusNotFound)
+		return
+	}
+	if request.Version != rules.Version {
+		http.Error(w, fmt.Sprintf("Version %d of the house rules is in force; read it before accepting", rules.Version), http.StatusConflict)
+		return
+	}
+
+	acknowledgment := models.CreateHouseRulesAcknowledgment(rules, user.ID)
+	err = config.DB.Collection("house_rule_acknowledgments").FindOneAndUpdate(ctx,
+		bson.M{"group_id": user.GroupID, "user_id": user.ID, "version": rules.Version},
+		bson.M{"$setOnInsert": acknowledgment},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(acknowledgment)
+	if err != nil {
+		log.Printf("Failed to record house rules acknowledgment for user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to accept house rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acknowledgment)
+}
+
+// getHouseRulesAcknowledgments shows admins which members have accepted the rules in force,
+// members who have not listed first
+func getHouseRulesAcknowledgments(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := getAdminGroup(w, r, "see who accepted the house rules")
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	rules, err := currentHouseRules(ctx, group.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch house rules", http.StatusInternalServerError)
+		return
+	}
+	if rules == nil {
+		http.Error(w, "The group has no house rules yet", http.StatusNotFound)
+		return
+	}
+
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": group.Members}})
+	if err != nil {
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return
+	}
+	members := make([]models.User, 0, len(group.Members))
+	if err := cursor.All(ctx, &members); err != nil {
+		http.Error(w, "Failed to decode group members", http.StatusInternalServerError)
+		return
+	}
+
+	cursor, err = config.DB.Collection("house_rule_acknowledgments").Find(ctx, bson.M{
+		"group_id": group.ID,
+		"user_id":  bson.M{"$in": group.Members},
+	})
+	if err != nil {
+		http.Error(w, "Failed to fetch acknowledgments", http.StatusInternalServerError)
+		return
+	}
+	acknowledgments := make([]models.HouseRulesAcknowledgment, 0)
+	if err := cursor.All(ctx, &acknowledgments); err != nil {
+		http.Error(w, "Failed to decode acknowledgments", http.StatusInternalServerError)
+		return
+	}
+
+	status := models.AcknowledgmentStatus(members, acknowledgments, rules.Version)
+	response := HouseRulesAcknowledgments{Version: rules.Version, Members: status}
+	for _, member := range status {
+		if !member.Acknowledged {
+			response.Pending++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,417 @@
+// handlers/inbound_hooks.go
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// inboundHookPath is where automations post commands: /api/hooks/{token}
+const inboundHookPath = "/api/hooks/"
+
+// maxInboundCommandSize is the largest command body read, in bytes
+const maxInboundCommandSize = 4 << 10
+
+// CreateInboundHookRequest defines the request structure for creating an inbound hook
+type CreateInboundHookRequest struct {
+	Name string `json:"name"` // e.g. "Kitchen button" or "Siri shortcut"
+}
+
+// InboundHookTokenResponse is an inbound hook with its URL, which holds the token and is only
+// shown when the hook is created or its token is replaced
+type InboundHookTokenResponse struct {
+	models.InboundHook
+	Token string `json:"token"`
+	URL   string `json:"url"` // Path to post commands to
+}
+
+// InboundCommandRequest is the JSON body of a command: either a sentence in Command or the
+// structured fields of models.InboundCommand
+type InboundCommandRequest struct {
+	Command string `json:"command,omitempty"`
+	models.InboundCommand
+}
+
+// InboundCommandResponse tells the automation what its command did
+type InboundCommandResponse struct {
+	Action  models.InboundAction `json:"action"`
+	Message string               `json:"message"`
+	ChoreID *primitive.ObjectID  `json:"chore_id,omitempty"` // The completed chore
+}
+
+// InboundHooksHandler lists the group's inbound hooks on GET and creates one on POST. Only group
+// admins manage inbound hooks.
+func InboundHooksHandler(w http.ResponseWriter, r *http.Request) {
+	user, group, ok := getAdminGroup(w, r, "manage inbound hooks")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listInboundHooks(w, group)
+	case http.MethodPost:
+		createInboundHook(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listInboundHooks returns the group's inbound hooks, oldest first, without their tokens
+func listInboundHooks(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("inbound_hooks").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch inbound hooks", http.StatusInternalServerError)
+		return
+	}
+	hooks := make([]models.InboundHook, 0)
+	if err := cursor.All(context.Background(), &hooks); err != nil {
+		http.Error(w, "Failed to decode inbound hooks", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// createInboundHook creates an inbound hook acting as the requesting admin and returns its token
+func createInboundHook(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreateInboundHookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+
+	count, err := config.DB.Collection("inbound_hooks").CountDocuments(context.Background(), bson.M{"group_id": group.ID})
+	if err != nil {
+		http.Error(w, "Failed to count inbound hooks", http.StatusInternalServerError)
+		return
+	}
+	if count >= models.MaxInboundHooksPerGroup {
+		writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("A group can have at most %d inbound hooks", models.MaxInboundHooksPerGroup))
+		return
+	}
+
+	hook, token, err := models.CreateInboundHook(group.ID, user.ID, request.Name)
+	if err != nil {
+		log.Printf("Failed to create inbound hook token: %v", err)
+		http.Error(w, "Failed to create inbound hook", http.StatusInternalServerError)
+		return
+	}
+	result, err := config.DB.Collection("inbound_hooks").InsertOne(context.Background(), hook)
+	if err != nil {
+		log.Printf("Failed to create inbound hook: %v", err)
+		http.Error(w, "Failed to create inbound hook", http.StatusInternalServerError)
+		return
+	}
+	hook.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(InboundHookTokenResponse{InboundHook: *hook, Token: token, URL: inboundHookPath + token})
+}
+
+// InboundHookHandler manages one of the group's inbound hooks: DELETE
+// /api/groups/inbound-hooks/{id} removes it and POST /api/groups/inbound-hooks/{id}/token
+// replaces its token, so the old URL stops working. Only group admins manage inbound hooks.
+func InboundHookHandler(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := getAdminGroup(w, r, "manage inbound hooks")
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/inbound-hooks/"), "/"), "/")
+	hookID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid inbound hook ID", http.StatusBadRequest)
+		return
+	}
+	var hook models.InboundHook
+	err = config.DB.Collection("inbound_hooks").FindOne(context.Background(), bson.M{"_id": hookID, "group_id": group.ID}).Decode(&hook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Inbound hook not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch inbound hook", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		if _, err := config.DB.Collection("inbound_hooks").DeleteOne(context.Background(), bson.M{"_id": hook.ID}); err != nil {
+			log.Printf("Failed to delete inbound hook %s: %v", hook.ID.Hex(), err)
+			http.Error(w, "Failed to delete inbound hook", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"message": "Inbound hook deleted successfully"})
+	case action == "token" && r.Method == http.MethodPost:
+		rotateInboundHookToken(w, hook)
+	case action == "" || action == "token":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// rotateInboundHookToken replaces the hook's token and returns the new one
+func rotateInboundHookToken(w http.ResponseWriter, hook models.InboundHook) {
+	replacement, token, err := models.CreateInboundHook(hook.GroupID, hook.CreatedBy, hook.Name)
+	if err != nil {
+		log.Printf("Failed to create inbound hook token: %v", err)
+		http.Error(w, "Failed to replace token", http.StatusInternalServerError)
+		return
+	}
+	hook.TokenHash = replacement.TokenHash
+	hook.UpdatedAt = replacement.UpdatedAt
+
+	_, err = config.DB.Collection("inbound_hooks").UpdateOne(
+		context.Background(),
+		bson.M{"_id": hook.ID},
+		bson.M{"$set": bson.M{"token_hash": hook.TokenHash, "updated_at": hook.UpdatedAt}},
+	)
+	if err != nil {
+		log.Printf("Failed to replace token of inbound hook %s: %v", hook.ID.Hex(), err)
+		http.Error(w, "Failed to replace token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(InboundHookTokenResponse{InboundHook: hook, Token: token, URL: inboundHookPath + token})
+}
+
+// InboundCommandHandler runs a command posted to an inbound hook by an automation such as an
+// IFTTT applet, an Apple Shortcut or a smart-home button. The token in the path authenticates the
+// hook, and commands run as the member who created it. The body is a sentence as plain text, a
+// "command" form field, or JSON with either a "command" sentence or an action and its fields.
+// Path format: /api/hooks/{token}
+func InboundCommandHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := context.Background()
+	token := strings.TrimPrefix(r.URL.Path, inboundHookPath)
+	var hook models.InboundHook
+	err := config.DB.Collection("inbound_hooks").FindOne(
+		ctx,
+		bson.M{"token_hash": models.HashInboundHookToken(token), "active": true},
+	).Decode(&hook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Invalid or revoked hook token", http.StatusUnauthorized)
+		} else {
+			http.Error(w, "Failed to fetch inbound hook", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var member models.User
+	if err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": hook.CreatedBy}).Decode(&member); err != nil || member.GroupID != hook.GroupID {
+		http.Error(w, "The member who created this hook is no longer in the group", http.StatusForbidden)
+		return
+	}
+
+	command, err := readInboundCommand(w, r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var response InboundCommandResponse
+	var status int
+	switch command.Action {
+	case models.InboundActionAddShoppingItem:
+		response, status = addInboundShoppingItem(member, command)
+	case models.InboundActionCompleteChore:
+		response, status = completeInboundChore(ctx, member, command)
+	}
+
+	if _, err := config.DB.Collection("inbound_hooks").UpdateOne(ctx, bson.M{"_id": hook.ID}, bson.M{"$set": bson.M{"last_used_at": time.Now()}}); err != nil {
+		log.Printf("Failed to record use of inbound hook %s: %v", hook.ID.Hex(), err)
+	}
+	if status != http.StatusOK {
+		http.Error(w, response.Message, status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// readInboundCommand reads and checks the command in the request body
+func readInboundCommand(w http.ResponseWriter, r *http.Request) (models.InboundCommand, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxInboundCommandSize))
+	if err != nil {
+		return models.InboundCommand{}, errors.New("command is too long")
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		var request InboundCommandRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			return models.InboundCommand{}, errors.New("invalid request body")
+		}
+		if request.Command != "" {
+			return models.ParseInboundCommand(request.Command)
+		}
+		command := request.InboundCommand
+		return command, command.Validate()
+	case "application/x-www-form-urlencoded":
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		return models.ParseInboundCommand(r.PostFormValue("command"))
+	default:
+		return models.ParseInboundCommand(string(data))
+	}
+}
+
+// addInboundShoppingItem puts the item on the group's default shopping list as the member
+func addInboundShoppingItem(member models.User, command models.InboundCommand) (InboundCommandResponse, int) {
+	response := InboundCommandResponse{Action: command.Action}
+	status, body := runAsMember(member, AddShoppingCartItemHandler, http.MethodPost, "/api/shopping-cart/add", AddShoppingCartItemRequest{
+		ItemName: command.Item,
+		Quantity: command.Quantity,
+		Unit:     command.Unit,
+	})
+	if status >= http.StatusBadRequest {
+		response.Message = strings.TrimSpace(string(body))
+		return response, status
+	}
+	response.Message = "Added " + command.Item + " to the shopping list"
+	return response, http.StatusOK
+}
+
+// completeInboundChore completes the group's open chore with the title, credited to the member it
+// is assigned to. A chore of the hook's member is preferred, then the one due soonest; titles are
+// matched exactly before partly, ignoring case.
+func completeInboundChore(ctx context.Context, member models.User, command models.InboundCommand) (InboundCommandResponse, int) {
+	response := InboundCommandResponse{Action: command.Action}
+	pattern := regexp.QuoteMeta(strings.TrimSpace(command.Chore))
+
+	var chore *models.Chore
+	for _, titlePattern := range []string{"^" + pattern + "$", pattern} {
+		cursor, err := config.DB.Collection("chores").Find(
+			ctx,
+			bson.M{
+				"group_id": member.GroupID,
+				"status":   bson.M{"$ne": models.ChoreStatusCompleted},
+				"title":    bson.M{"$regex": primitive.Regex{Pattern: titlePattern, Options: "i"}},
+			},
+			options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}).SetLimit(20),
+		)
+		if err != nil {
+			response.Message = "Failed to fetch chores"
+			return response, http.StatusInternalServerError
+		}
+		var chores []models.Chore
+		if err := cursor.All(ctx, &chores); err != nil {
+			response.Message = "Failed to decode chores"
+			return response, http.StatusInternalServerError
+		}
+		for i := range chores {
+			if chore == nil || (chores[i].AssignedTo == member.ID && chore.AssignedTo != member.ID) {
+				chore = &chores[i]
+			}
+		}
+		if chore != nil {
+			break
+		}
+	}
+	if chore == nil {
+		response.Message = "No open chore called " + command.Chore
+		return response, http.StatusNotFound
+	}
+
+	status, body := runAsMember(member, CompleteChoreHandler, http.MethodPost, "/api/chores/complete", CompleteChoreRequest{
+		ChoreID: chore.ID.Hex(),
+		UserID:  chore.AssignedTo.Hex(),
+	})
+	if status >= http.StatusBadRequest {
+		response.Message = strings.TrimSpace(string(body))
+		return response, status
+	}
+	response.Message = "Completed " + chore.Title
+	response.ChoreID = &chore.ID
+	return response, http.StatusOK
+}
+
+// memberResponse collects what a handler run for an inbound hook writes
+type memberResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (m *memberResponse) Header() http.Header {
+	return m.header
+}
+
+func (m *memberResponse) Write(data []byte) (int, error) {
+	if m.status == 0 {
+		m.status = http.StatusOK
+	}
+	return m.body.Write(data)
+}
+
+func (m *memberResponse) WriteHeader(status int) {
+	if m.status == 0 {
+		m.status = status
+	}
+}
+
+// runAsMember runs a handler in process as if the member had called it, sending body as JSON, and
+// returns the status and body it responded with
+func runAsMember(member models.User, handler http.HandlerFunc, method, target string, body interface{}) (int, []byte) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return http.StatusInternalServerError, []byte("Failed to encode request")
+	}
+	claims := middleware.UserClaims{ID: member.ID.Hex(), Username: member.Username}
+	ctx := context.WithValue(context.Background(), middleware.UserContextKey, claims)
+	r, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(data))
+	if err != nil {
+		return http.StatusInternalServerError, []byte("Failed to build request")
+	}
+	r.Header.Set("Content-Type", "application/json")
+
+	w := &memberResponse{header: make(http.Header)}
+	handler(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.status, w.body.Bytes()
+}
@@ -0,0 +1,433 @@
+// handlers/issues.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// issuePath serves single issues: /api/groups/issues/{id}, /api/groups/issues/{id}/status and
+// /api/groups/issues/{id}/photo
+const issuePath = "/api/groups/issues/"
+
+// issuePhotoPath serves issue photos through signed URLs: /api/groups/issues/photos/{file_id}
+const issuePhotoPath = "/api/groups/issues/photos/"
+
+// IssueRequest defines the request structure for logging or editing an issue
+type IssueRequest struct {
+	Category    models.IssueCategory `json:"category"`
+	Description string               `json:"description"`
+	Anonymous   bool                 `json:"anonymous"` // Only read when logging the issue
+}
+
+// IssueStatusRequest defines the request structure for moving an issue to another status
+type IssueStatusRequest struct {
+	Status models.IssueStatus `json:"status"`
+	Note   string             `json:"note,omitempty"` // How it was resolved
+}
+
+// IssueDetails is an issue as a member sees it: the reporter is left out of anonymous issues
+// except for the reporter themselves, and the photo comes as a signed link
+type IssueDetails struct {
+	models.Issue
+	ReportedBy *primitive.ObjectID `json:"reported_by,omitempty"`
+	Mine       bool                `json:"mine"`
+	PhotoURL   string              `json:"photo_url,omitempty"`
+}
+
+// issueDetails prepares the issue for the member to see
+func issueDetails(issue models.Issue, viewerID primitive.ObjectID, now time.Time) IssueDetails {
+	details := IssueDetails{
+		Issue:      issue,
+		ReportedBy: issue.Reporter(viewerID),
+		Mine:       issue.ReportedBy == viewerID,
+	}
+	if issue.PhotoID != nil {
+		details.PhotoURL = storage.SignedURL(issuePhotoPath, *issue.PhotoID, now)
+	}
+	return details
+}
+
+// IssuesHandler lists the group's issues, newest first, on GET (?status= and ?category= narrow
+// the list) and logs one on POST, telling the other members about it. A photo can be added
+// afterwards through /api/groups/issues/{id}/photo.
+func IssuesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listIssues(w, r, user)
+	case http.MethodPost:
+		createIssue(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listIssues returns the group's issues with the requested status and category
+func listIssues(w http.ResponseWriter, r *http.Request, user models.User) {
+	query := r.URL.Query()
+	filter := bson.M{"group_id": user.GroupID}
+	if status := models.IssueStatus(query.Get("status")); status != "" {
+		if status != models.IssueStatusOpen && status != models.IssueStatusAcknowledged && status != models.IssueStatusResolved {
+			http.Error(w, "Status must be open, acknowledged or resolved", http.StatusBadRequest)
+			return
+		}
+		filter["status"] = status
+	}
+	if category := models.IssueCategory(query.Get("category")); category != "" {
+		if !models.IsValidIssueCategory(category) {
+			http.Error(w, "Category must be noise, cleanliness, damage or other", http.StatusBadRequest)
+			return
+		}
+		filter["category"] = category
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("issues").Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		http.Error(w, "Failed to fetch issues", http.StatusInternalServerError)
+		return
+	}
+	issues := make([]models.Issue, 0)
+	if err := cursor.All(ctx, &issues); err != nil {
+		http.Error(w, "Failed to decode issues", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	details := make([]IssueDetails, 0, len(issues))
+	for _, issue := range issues {
+		details = append(details, issueDetails(issue, user.ID, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// createIssue logs an open issue and notifies the rest of the group
+func createIssue(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request IssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateIssue(request.Category, request.Description); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	issue := models.CreateIssue(group.ID, user.ID, request.Category, request.Description, request.Anonymous)
+	result, err := config.DB.Collection("issues").InsertOne(ctx, issue)
+	if err != nil {
+		log.Printf("Failed to log issue: %v", err)
+		http.Error(w, "Failed to log issue", http.StatusInternalServerError)
+		return
+	}
+	issue.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.IssueReportedNotification(issue, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(issueDetails(*issue, user.ID, time.Now()))
+}
+
+// IssueHandler serves a single issue of the user's group. GET /api/groups/issues/{id} returns it,
+// PUT edits its category and description and DELETE removes it; POST /api/groups/issues/{id}/status
+// moves it to open, acknowledged or resolved; PUT /api/groups/issues/{id}/photo uploads a photo as
+// the multipart "photo" field and DELETE removes it. Any member can change the status, only the
+// reporter can edit the issue or its photo, and the reporter or a group admin can remove it.
+func IssueHandler(w http.ResponseWriter, r *http.Request) {
+	idStr, sub, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, issuePath), "/"), "/")
+	issueID, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		http.Error(w, "Invalid issue ID format", http.StatusBadRequest)
+		return
+	}
+	if sub != "" && sub != "status" && sub != "photo" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var issue models.Issue
+	err = config.DB.Collection("issues").FindOne(
+		context.Background(),
+		bson.M{"_id": issueID, "group_id": user.GroupID},
+	).Decode(&issue)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Issue not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch issue", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(issueDetails(issue, user.ID, time.Now()))
+	case sub == "status" && r.Method == http.MethodPost:
+		setIssueStatus(w, r, user, issue)
+	case sub == "" && r.Method == http.MethodDelete:
+		if issue.ReportedBy != user.ID {
+			group, ok := getGroupByID(w, user.GroupID)
+			if !ok {
+				return
+			}
+			if !group.IsAdmin(user.ID) {
+				http.Error(w, "Only the reporter or a group admin can remove an issue", http.StatusForbidden)
+				return
+			}
+		}
+		deleteIssue(w, issue)
+	case issue.ReportedBy != user.ID && (r.Method == http.MethodPut || r.Method == http.MethodPost || r.Method == http.MethodDelete):
+		http.Error(w, "Only the reporter can change an issue", http.StatusForbidden)
+	case sub == "" && r.Method == http.MethodPut:
+		updateIssue(w, r, user, issue)
+	case sub == "photo" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		uploadIssuePhoto(w, r, user, issue)
+	case sub == "photo" && r.Method == http.MethodDelete:
+		deleteIssuePhoto(w, issue)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// updateIssue changes the category and description of an issue that has not been resolved
+func updateIssue(w http.ResponseWriter, r *http.Request, user models.User, issue models.Issue) {
+	if issue.Status == models.IssueStatusResolved {
+		http.Error(w, "Resolved issues cannot be edited", http.StatusConflict)
+		return
+	}
+	var request IssueRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateIssue(request.Category, request.Description); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	issue.Category = request.Category
+	issue.Description = strings.TrimSpace(request.Description)
+	issue.UpdatedAt = time.Now()
+	_, err := config.DB.Collection("issues").UpdateOne(
+		context.Background(),
+		bson.M{"_id": issue.ID},
+		bson.M{"$set": bson.M{"category": issue.Category, "description": issue.Description, "updated_at": issue.UpdatedAt}},
+	)
+	if err != nil {
+		log.Printf("Failed to update issue %s: %v", issue.ID.Hex(), err)
+		http.Error(w, "Failed to update issue", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issueDetails(issue, user.ID, time.Now()))
+}
+
+// setIssueStatus moves the issue to the requested status and tells the reporter when someone else
+// resolves it
+func setIssueStatus(w http.ResponseWriter, r *http.Request, user models.User, issue models.Issue) {
+	var request IssueStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	previous := issue.UpdatedAt
+	if err := issue.SetStatus(request.Status, user.ID, request.Note, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Only apply the change if nobody else changed the issue since it was read
+	ctx := context.Background()
+	result, err := config.DB.Collection("issues").ReplaceOne(ctx, bson.M{"_id": issue.ID, "updated_at": previous}, issue)
+	if err != nil {
+		log.Printf("Failed to update status of issue %s: %v", issue.ID.Hex(), err)
+		http.Error(w, "Failed to update issue", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeError(w, r, apierror.CodeEditConflict, "The issue was changed meanwhile; reload it and try again")
+		return
+	}
+
+	if issue.Status == models.IssueStatusResolved && issue.ReportedBy != user.ID {
+		insertNotifications(ctx, []*models.Notification{models.IssueResolvedNotification(&issue, user.Name)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issueDetails(issue, user.ID, time.Now()))
+}
+
+// deleteIssue removes an issue along with its photo
+func deleteIssue(w http.ResponseWriter, issue models.Issue) {
+	if _, err := config.DB.Collection("issues").DeleteOne(context.Background(), bson.M{"_id": issue.ID}); err != nil {
+		log.Printf("Failed to delete issue %s: %v", issue.ID.Hex(), err)
+		http.Error(w, "Failed to delete issue", http.StatusInternalServerError)
+		return
+	}
+	if issue.PhotoID != nil {
+		removeIssuePhoto(*issue.PhotoID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadIssuePhoto stores a photo of the issue, replacing the previous one
+func uploadIssuePhoto(w http.ResponseWriter, r *http.Request, user models.User, issue models.Issue) {
+	// Leave room for the multipart headers around the image
+	r.Body = http.MaxBytesReader(w, r.Body, storage.MaxImageSize+64<<10)
+	file, header, err := r.FormFile("photo")
+	if err != nil {
+		http.Error(w, "A photo under 5 MB is required in the \"photo\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read photo", http.StatusBadRequest)
+		return
+	}
+
+	photoID, err := storage.SaveImage(header.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrImageTooLarge):
+			http.Error(w, "Photo must be 5 MB or smaller", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrUnsupportedImage):
+			http.Error(w, "Photo must be a JPEG, PNG, GIF or WebP image", http.StatusUnsupportedMediaType)
+		default:
+			log.Printf("Failed to store photo of issue %s: %v", issue.ID.Hex(), err)
+			http.Error(w, "Failed to store photo", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_, err = config.DB.Collection("issues").UpdateOne(
+		context.Background(),
+		bson.M{"_id": issue.ID},
+		bson.M{"$set": bson.M{"photo_id": photoID}},
+	)
+	if err != nil {
+		log.Printf("Failed to attach photo to issue %s: %v", issue.ID.Hex(), err)
+		storage.Delete(context.Background(), photoID)
+		http.Error(w, "Failed to attach photo", http.StatusInternalServerError)
+		return
+	}
+
+	if issue.PhotoID != nil {
+		removeIssuePhoto(*issue.PhotoID)
+	}
+	issue.PhotoID = &photoID
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issueDetails(issue, user.ID, time.Now()))
+}
+
+// deleteIssuePhoto detaches and removes an issue's photo
+func deleteIssuePhoto(w http.ResponseWriter, issue models.Issue) {
+	if issue.PhotoID == nil {
+		http.Error(w, "Issue has no photo", http.StatusNotFound)
+		return
+	}
+
+	_, err := config.DB.Collection("issues").UpdateOne(
+		context.Background(),
+		bson.M{"_id": issue.ID},
+		bson.M{"$unset": bson.M{"photo_id": ""}},
+	)
+	if err != nil {
+		log.Printf("Failed to detach photo from issue %s: %v", issue.ID.Hex(), err)
+		http.Error(w, "Failed to delete photo", http.StatusInternalServerError)
+		return
+	}
+	removeIssuePhoto(*issue.PhotoID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeIssuePhoto deletes a stored issue photo in the background once nothing refers to it
+func removeIssuePhoto(photoID primitive.ObjectID) {
+	go func() {
+		if err := storage.Delete(context.Background(), photoID); err != nil {
+			log.Printf("Failed to delete issue photo %s: %v", photoID.Hex(), err)
+		}
+	}()
+}
+
+// ServeIssuePhotoHandler streams an issue photo from a signed URL handed out with the issue, so
+// image tags can load it without the auth header
+func ServeIssuePhotoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	photoID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, issuePhotoPath))
+	if err != nil {
+		http.Error(w, "Invalid photo ID format", http.StatusBadRequest)
+		return
+	}
+	if err := storage.VerifySignature(photoID, r.URL.Query(), time.Now()); err != nil {
+		http.Error(w, "Photo link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	photo, contentType, err := storage.Open(context.Background(), photoID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open issue photo %s: %v", photoID.Hex(), err)
+			http.Error(w, "Failed to fetch photo", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer photo.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	if _, err := io.Copy(w, photo); err != nil {
+		log.Printf("Failed to send issue photo %s: %v", photoID.Hex(), err)
+	}
+}
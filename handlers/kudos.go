@@ -0,0 +1,261 @@
+// handlers/kudos.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SendKudosRequest defines the request structure for thanking a roommate
+type SendKudosRequest struct {
+	UserID  string `json:"user_id"`
+	Message string `json:"message"`
+	Points  int    `json:"points,omitempty"` // Bonus points from the group's monthly kudos budget
+}
+
+// KudosResponse is sent kudos with what is left of the group's kudos budget for the month
+type KudosResponse struct {
+	models.Kudos
+	BudgetRemaining int `json:"budget_remaining"`
+}
+
+// KudosHandler lists the group's kudos in a month, newest first, on GET (?month=YYYY-MM, defaults
+// to this month) and sends kudos to another member on POST. Points sent with kudos come out of the
+// group's monthly kudos budget and are added to the recipient's score. Sent kudos are streamed to
+// the group over /api/groups/events.
+func KudosHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listKudos(w, r, user)
+	case http.MethodPost:
+		sendKudos(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// kudosInMonth loads the group's kudos sent in the month, newest first
+func kudosInMonth(ctx context.Context, groupID primitive.ObjectID, month string) ([]models.Kudos, error) {
+	cursor, err := config.DB.Collection("kudos").Find(ctx,
+		bson.M{"group_id": groupID, "month": month},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	kudos := make([]models.Kudos, 0)
+	if err := cursor.All(ctx, &kudos); err != nil {
+		return nil, err
+	}
+	return kudos, nil
+}
+
+// requestedKudosMonth reads ?month=, defaulting to this month. It writes the error response itself
+// and returns false when the month is malformed.
+func requestedKudosMonth(w http.ResponseWriter, r *http.Request) (string, bool) {
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		return models.KudosMonth(time.Now()), true
+	}
+	if _, _, err := models.MonthBounds(month); err != nil {
+		http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+		return "", false
+	}
+	return month, true
+}
+
+// listKudos returns the kudos sent in the group in the month
+func listKudos(w http.ResponseWriter, r *http.Request, user models.User) {
+	month, ok := requestedKudosMonth(w, r)
+	if !ok {
+		return
+	}
+	kudos, err := kudosInMonth(context.Background(), user.GroupID, month)
+	if err != nil {
+		http.Error(w, "Failed to fetch kudos", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(kudos)
+}
+
+// sendKudos thanks another member, drawing any points from the budget and adding them to the
+// recipient's score in one transaction
+func sendKudos(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request SendKudosRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	recipientID, err := primitive.ObjectIDFromHex(request.UserID)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateKudos(user.ID, recipientID, request.Message, request.Points); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+	if !group.IsMember(recipientID) {
+		http.Error(w, "User is not a member of your group", http.StatusBadRequest)
+		return
+	}
+	budget := group.Settings.KudosBudget
+	if request.Points > 0 && budget == 0 {
+		http.Error(w, "The group has no kudos budget, so kudos cannot carry points", http.StatusBadRequest)
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	kudos := models.CreateKudos(group.ID, user.ID, recipientID, request.Message, request.Points)
+	result, err := session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		used, err := spendKudosBudget(sc, group.ID, kudos.Month, budget, kudos.Points)
+		if err != nil {
+			return nil, err
+		}
+
+		inserted, err := config.DB.Collection("kudos").InsertOne(sc, kudos)
+		if err != nil {
+			return nil, err
+		}
+		kudos.ID = inserted.InsertedID.(primitive.ObjectID)
+
+		if kudos.Points > 0 {
+			var recipient models.User
+			err = config.DB.Collection("users").FindOneAndUpdate(
+				sc,
+				bson.M{"_id": recipientID},
+				bson.M{
+					"$inc": bson.M{
+						"score":         kudos.Points,
+						"weekly_score":  kudos.Points,
+						"monthly_score": kudos.Points,
+					},
+					"$set": bson.M{"updated_at": kudos.CreatedAt},
+				},
+				options.FindOneAndUpdate().SetReturnDocument(options.After),
+			).Decode(&recipient)
+			if err != nil {
+				return nil, err
+			}
+
+			event := models.CreateScoreEvent(recipient.ID, group.ID, models.ScoreEventKudos, kudos.Points, recipient.Score,
+				"Kudos from "+user.Username)
+			event.ReferenceID = &kudos.ID
+			if err := jobs.RecordScoreEvent(sc, event); err != nil {
+				return nil, err
+			}
+		}
+
+		return KudosResponse{Kudos: *kudos, BudgetRemaining: max(budget-used, 0)}, nil
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrKudosBudgetSpent) {
+			http.Error(w, fmt.Sprintf("The group's kudos budget for this month cannot cover %d points", kudos.Points), http.StatusConflict)
+			return
+		}
+		log.Printf("Failed to send kudos: %v", err)
+		http.Error(w, "Failed to send kudos", http.StatusInternalServerError)
+		return
+	}
+
+	insertNotifications(context.Background(), []*models.Notification{models.KudosReceivedNotification(kudos, user.Name)})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// spendKudosBudget takes the points from the group's budget for the month and returns how much of
+// it is used afterwards. It fails with models.ErrKudosBudgetSpent when too little is left.
+func spendKudosBudget(ctx context.Context, groupID primitive.ObjectID, month string, budget, points int) (int, error) {
+	budgets := config.DB.Collection("kudos_budgets")
+	_, err := budgets.UpdateOne(ctx,
+		bson.M{"group_id": groupID, "month": month},
+		bson.M{"$setOnInsert": bson.M{"used": 0}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Kudos without points go through even after the budget was lowered below what is used
+	filter := bson.M{"group_id": groupID, "month": month}
+	if points > 0 {
+		filter["used"] = bson.M{"$lte": budget - points}
+	}
+	var spent models.KudosBudget
+	err = budgets.FindOneAndUpdate(ctx,
+		filter,
+		bson.M{"$inc": bson.M{"used": points}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&spent)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, models.ErrKudosBudgetSpent
+	}
+	if err != nil {
+		return 0, err
+	}
+	return spent.Used, nil
+}
+
+// GetKudosSummaryHandler sums up the group's kudos over a month: how many were sent, how much of
+// the budget went out as points and how many each member sent and received, most thanked first.
+// Query: ?month=YYYY-MM (defaults to this month)
+func GetKudosSummaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+	month, ok := requestedKudosMonth(w, r)
+	if !ok {
+		return
+	}
+
+	kudos, err := kudosInMonth(context.Background(), group.ID, month)
+	if err != nil {
+		http.Error(w, "Failed to fetch kudos", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.SummarizeKudos(month, group.Settings.KudosBudget, group.Members, kudos))
+}
@@ -0,0 +1,333 @@
+// handlers/messages.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartConversationRequest defines the request structure for opening a conversation with a member
+type StartConversationRequest struct {
+	UserID string `json:"user_id"`
+}
+
+// SendMessageRequest defines the request structure for sending a direct message
+type SendMessageRequest struct {
+	Body string `json:"body"`
+}
+
+// MessagePage is a page of messages, newest first. NextBefore is passed as ?before= to fetch
+// older messages and is empty on the last page.
+type MessagePage struct {
+	Messages   []models.DirectMessage `json:"messages"`
+	NextBefore string                 `json:"next_before,omitempty"`
+}
+
+// ConversationsHandler lists the user's conversations in their group, most recently active first,
+// on GET and opens a conversation with another member on POST, returning the existing one if
+// they already have one. New messages are also streamed over /api/groups/events.
+func ConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listConversations(w, user)
+	case http.MethodPost:
+		startConversation(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listConversations returns the user's conversations
+func listConversations(w http.ResponseWriter, user models.User) {
+	cursor, err := config.DB.Collection("conversations").Find(
+		context.Background(),
+		bson.M{"group_id": user.GroupID, "participants.user_id": user.ID},
+		options.Find().SetSort(bson.D{{Key: "last_message_at", Value: -1}, {Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch conversations", http.StatusInternalServerError)
+		return
+	}
+	conversations := make([]models.Conversation, 0)
+	if err := cursor.All(context.Background(), &conversations); err != nil {
+		http.Error(w, "Failed to decode conversations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversations)
+}
+
+// startConversation finds or creates the conversation between the user and another member
+func startConversation(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request StartConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	otherID, err := primitive.ObjectIDFromHex(request.UserID)
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	if otherID == user.ID {
+		http.Error(w, "Cannot message yourself", http.StatusBadRequest)
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+	if !group.IsMember(otherID) {
+		http.Error(w, "User is not a member of your group", http.StatusBadRequest)
+		return
+	}
+
+	// Upsert on the key so two members opening a conversation at once end up in the same one
+	ctx := context.Background()
+	conversation := models.CreateConversation(group.ID, user.ID, otherID)
+	filter := bson.M{"group_id": group.ID, "key": conversation.Key}
+	collection := config.DB.Collection("conversations")
+	err = collection.FindOneAndUpdate(ctx, filter,
+		bson.M{"$setOnInsert": conversation},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(conversation)
+	if mongo.IsDuplicateKeyError(err) {
+		err = collection.FindOne(ctx, filter).Decode(conversation)
+	}
+	if err != nil {
+		log.Printf("Failed to start conversation: %v", err)
+		http.Error(w, "Failed to start conversation", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversation)
+}
+
+// ConversationHandler handles one of the user's conversations: GET
+// /api/messages/conversations/{id} pages through its messages, newest first (?limit=, ?before=
+// a message ID from next_before), POST sends a message and POST
+// /api/messages/conversations/{id}/read marks it read.
+func ConversationHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/messages/conversations/"), "/"), "/")
+	conversationID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid conversation ID", http.StatusBadRequest)
+		return
+	}
+	var conversation models.Conversation
+	err = config.DB.Collection("conversations").FindOne(context.Background(), bson.M{
+		"_id":                  conversationID,
+		"group_id":             user.GroupID,
+		"participants.user_id": user.ID,
+	}).Decode(&conversation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Conversation not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch conversation", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		listMessages(w, r, conversation)
+	case action == "" && r.Method == http.MethodPost:
+		sendMessage(w, r, user, conversation)
+	case action == "read" && r.Method == http.MethodPost:
+		markConversationRead(w, user, conversation)
+	case action == "" || action == "read":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// listMessages returns a page of the conversation's messages
+func listMessages(w http.ResponseWriter, r *http.Request, conversation models.Conversation) {
+	query := r.URL.Query()
+	limit := models.DefaultMessagePageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > models.MaxMessagePageSize {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", models.MaxMessagePageSize), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := bson.M{"conversation_id": conversation.ID}
+	if before := query.Get("before"); before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			http.Error(w, "Invalid before message ID", http.StatusBadRequest)
+			return
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+
+	cursor, err := config.DB.Collection("direct_messages").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit+1)),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch messages", http.StatusInternalServerError)
+		return
+	}
+	messages := make([]models.DirectMessage, 0, limit+1)
+	if err := cursor.All(context.Background(), &messages); err != nil {
+		http.Error(w, "Failed to decode messages", http.StatusInternalServerError)
+		return
+	}
+
+	page := MessagePage{Messages: messages}
+	if len(messages) > limit {
+		page.Messages = messages[:limit]
+		page.NextBefore = messages[limit-1].ID.Hex()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// sendMessage stores a message, counts it as unread for the other member and marks the
+// conversation read for the sender. The group event stream delivers it live.
+func sendMessage(w http.ResponseWriter, r *http.Request, user models.User, conversation models.Conversation) {
+	var request SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := models.ValidateMessageBody(request.Body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	message := models.CreateDirectMessage(&conversation, user.ID, request.Body)
+	result, err := config.DB.Collection("direct_messages").InsertOne(ctx, message)
+	if err != nil {
+		log.Printf("Failed to send message: %v", err)
+		http.Error(w, "Failed to send message", http.StatusInternalServerError)
+		return
+	}
+	message.ID = result.InsertedID.(primitive.ObjectID)
+
+	sender, _ := conversation.Participant(user.ID)
+	recipient := conversation.Other(user.ID)
+	_, err = config.DB.Collection("conversations").UpdateOne(ctx,
+		bson.M{"_id": conversation.ID},
+		bson.M{
+			"$set": bson.M{
+				"last_message":    models.MessagePreview(message.Body),
+				"last_sender_id":  user.ID,
+				"last_message_at": message.CreatedAt,
+				"updated_at":      message.CreatedAt,
+				fmt.Sprintf("participants.%d.unread", sender):       0,
+				fmt.Sprintf("participants.%d.last_read_at", sender): message.CreatedAt,
+			},
+			"$inc": bson.M{fmt.Sprintf("participants.%d.unread", recipient): 1},
+		},
+	)
+	if err != nil {
+		// The message is stored and streamed; only the conversation list is behind
+		log.Printf("Failed to update conversation %s: %v", conversation.ID.Hex(), err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// markConversationRead clears the user's unread count
+func markConversationRead(w http.ResponseWriter, user models.User, conversation models.Conversation) {
+	index, _ := conversation.Participant(user.ID)
+	now := time.Now()
+	_, err := config.DB.Collection("conversations").UpdateOne(context.Background(),
+		bson.M{"_id": conversation.ID},
+		bson.M{"$set": bson.M{
+			fmt.Sprintf("participants.%d.unread", index):       0,
+			fmt.Sprintf("participants.%d.last_read_at", index): now,
+		}},
+	)
+	if err != nil {
+		http.Error(w, "Failed to mark conversation read", http.StatusInternalServerError)
+		return
+	}
+	conversation.Participants[index].Unread = 0
+	conversation.Participants[index].LastReadAt = &now
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(conversation)
+}
+
+// GetUnreadMessagesHandler returns how many unread direct messages the user has across their
+// conversations, for the badge on the messages tab
+func GetUnreadMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("conversations").Find(context.Background(), bson.M{
+		"group_id":     user.GroupID,
+		"participants": bson.M{"$elemMatch": bson.M{"user_id": user.ID, "unread": bson.M{"$gt": 0}}},
+	})
+	if err != nil {
+		http.Error(w, "Failed to fetch conversations", http.StatusInternalServerError)
+		return
+	}
+	conversations := make([]models.Conversation, 0)
+	if err := cursor.All(context.Background(), &conversations); err != nil {
+		http.Error(w, "Failed to decode conversations", http.StatusInternalServerError)
+		return
+	}
+
+	unread := 0
+	for _, conversation := range conversations {
+		if index, ok := conversation.Participant(user.ID); ok {
+			unread += conversation.Participants[index].Unread
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{
+		"unread":        unread,
+		"conversations": len(conversations),
+	})
+}
@@ -0,0 +1,521 @@
+// handlers/meter_readings.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// meterReadingPath serves single readings: /api/meters/readings/{id} and /api/meters/readings/{id}/photo
+const meterReadingPath = "/api/meters/readings/"
+
+// meterPhotoPath serves meter photos through signed URLs: /api/meters/photos/{file_id}
+const meterPhotoPath = "/api/meters/photos/"
+
+// CreateMeterReadingRequest defines the request structure for recording a meter reading
+type CreateMeterReadingRequest struct {
+	Meter    string  `json:"meter" validate:"required"` // electricity, gas or water
+	Value    float64 `json:"value" validate:"min=0"`
+	Username string  `json:"username,omitempty"` // Member whose room the sub-meter is in; empty for the main meter
+	ReadAt   string  `json:"read_at,omitempty"`  // RFC3339; defaults to now
+	Note     string  `json:"note,omitempty"`
+}
+
+// MeterReadingDetails is a meter reading with the name of the member whose meter it is and a
+// signed link to its photo
+type MeterReadingDetails struct {
+	models.MeterReading
+	Name     string `json:"name,omitempty"`
+	PhotoURL string `json:"photo_url,omitempty"`
+}
+
+// meterReadingDetails signs the photo URL of a reading, leaving it empty when it has no photo
+func meterReadingDetails(reading models.MeterReading, names map[primitive.ObjectID]string, now time.Time) MeterReadingDetails {
+	details := MeterReadingDetails{MeterReading: reading, Name: names[reading.UserID]}
+	if reading.PhotoID != nil {
+		details.PhotoURL = storage.SignedURL(meterPhotoPath, *reading.PhotoID, now)
+	}
+	return details
+}
+
+// CreateMeterReadingHandler records what a utility meter shows. A photo of the meter can be added
+// afterwards through /api/meters/readings/{id}/photo.
+func CreateMeterReadingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateMeterReadingRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	meter := models.MeterType(request.Meter)
+	if !models.IsValidMeterType(meter) {
+		http.Error(w, "Meter must be electricity, gas or water", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	readAt := now
+	if request.ReadAt != "" {
+		var err error
+		readAt, err = time.Parse(time.RFC3339, request.ReadAt)
+		if err != nil {
+			http.Error(w, "Invalid read at format. Use ISO 8601/RFC3339 format (YYYY-MM-DDTHH:MM:SSZ)", http.StatusBadRequest)
+			return
+		}
+		if readAt.After(now) {
+			http.Error(w, "A reading cannot be in the future", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	reading := models.MeterReading{
+		GroupID:        user.GroupID,
+		Meter:          meter,
+		Value:          request.Value,
+		ReadAt:         readAt.UTC(),
+		Note:           strings.TrimSpace(request.Note),
+		RecordedBy:     user.ID,
+		RecordedByName: user.Name,
+		CreatedAt:      now,
+	}
+	names := make(map[primitive.ObjectID]string)
+	if request.Username != "" {
+		members, ok := findGroupMembersByUsername(w, user.GroupID, []string{request.Username})
+		if !ok {
+			return
+		}
+		reading.UserID = members[request.Username].ID
+		names[reading.UserID] = members[request.Username].Name
+	}
+
+	ctx := context.Background()
+	readings, err := models.LoadMeterReadings(ctx, config.DB, bson.M{"group_id": user.GroupID, "meter": meter, "user_id": meterOwnerFilter(reading.UserID)})
+	if err != nil {
+		log.Printf("Failed to fetch meter readings: %v", err)
+		http.Error(w, "Failed to record meter reading", http.StatusInternalServerError)
+		return
+	}
+	if err := models.CheckMeterReading(readings, reading); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := config.DB.Collection("meter_readings").InsertOne(ctx, reading)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeDuplicateItem, "The meter already has a reading at that time")
+			return
+		}
+		log.Printf("Failed to record meter reading: %v", err)
+		http.Error(w, "Failed to record meter reading", http.StatusInternalServerError)
+		return
+	}
+	reading.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(meterReadingDetails(reading, names, now))
+}
+
+// meterOwnerFilter matches the readings of a member's sub-meter, or of the main meter for an empty ID
+func meterOwnerFilter(userID primitive.ObjectID) interface{} {
+	if userID.IsZero() {
+		return bson.M{"$exists": false}
+	}
+	return userID
+}
+
+// readingMemberNames looks up the names of the members whose sub-meters the readings are from
+func readingMemberNames(ctx context.Context, readings []models.MeterReading) (map[primitive.ObjectID]string, error) {
+	ids := make([]primitive.ObjectID, 0, len(readings))
+	for _, reading := range readings {
+		if !reading.UserID.IsZero() {
+			ids = append(ids, reading.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return map[primitive.ObjectID]string{}, nil
+	}
+	return userNames(ctx, ids)
+}
+
+// GetMeterReadingsHandler lists the group's meter readings, oldest first
+// Query: ?meter=electricity|gas|water&username={member}&from=YYYY-MM-DD&to=YYYY-MM-DD, all optional
+func GetMeterReadingsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := bson.M{"group_id": user.GroupID}
+	if meter := query.Get("meter"); meter != "" {
+		if !models.IsValidMeterType(models.MeterType(meter)) {
+			http.Error(w, "Meter must be electricity, gas or water", http.StatusBadRequest)
+			return
+		}
+		filter["meter"] = meter
+	}
+	if username := query.Get("username"); username != "" {
+		members, ok := findGroupMembersByUsername(w, user.GroupID, []string{username})
+		if !ok {
+			return
+		}
+		filter["user_id"] = members[username].ID
+	}
+	readAt := bson.M{}
+	if value := query.Get("from"); value != "" {
+		from, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid from date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		readAt["$gte"] = from
+	}
+	if value := query.Get("to"); value != "" {
+		to, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			http.Error(w, "Invalid to date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		readAt["$lt"] = to.AddDate(0, 0, 1)
+	}
+	if len(readAt) > 0 {
+		filter["read_at"] = readAt
+	}
+
+	ctx := context.Background()
+	readings, err := models.LoadMeterReadings(ctx, config.DB, filter)
+	if err != nil {
+		log.Printf("Failed to fetch meter readings: %v", err)
+		http.Error(w, "Failed to fetch meter readings", http.StatusInternalServerError)
+		return
+	}
+	names, err := readingMemberNames(ctx, readings)
+	if err != nil {
+		log.Printf("Failed to fetch member names: %v", err)
+		http.Error(w, "Failed to fetch meter readings", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	response := make([]MeterReadingDetails, 0, len(readings))
+	for _, reading := range readings {
+		response = append(response, meterReadingDetails(reading, names, now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// MeterConsumptionResponse is the usage of each of a group's meters of one type over a period
+type MeterConsumptionResponse struct {
+	Meter  models.MeterType    `json:"meter"`
+	From   time.Time           `json:"from"`
+	To     time.Time           `json:"to"`
+	Usages []models.MeterUsage `json:"usages"`
+}
+
+// GetMeterConsumptionHandler works out how much each meter counted over a period, which is what
+// usage-based utility bills are split by
+// Query: ?meter=electricity|gas|water&from=YYYY-MM-DD (defaults to a month before to)&to=YYYY-MM-DD
+// (defaults to today)
+func GetMeterConsumptionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	meter := models.MeterType(query.Get("meter"))
+	if !models.IsValidMeterType(meter) {
+		http.Error(w, "Meter must be electricity, gas or water", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if value := query.Get("to"); value != "" {
+		if to, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid to date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	from := to.AddDate(0, -1, 0)
+	if value := query.Get("from"); value != "" {
+		if from, err = time.Parse("2006-01-02", value); err != nil {
+			http.Error(w, "Invalid from date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if !from.Before(to) {
+		http.Error(w, "The from date must be before the to date", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	readings, err := models.LoadMeterReadings(ctx, config.DB, bson.M{"group_id": user.GroupID, "meter": meter})
+	if err != nil {
+		log.Printf("Failed to fetch meter readings: %v", err)
+		http.Error(w, "Failed to fetch meter consumption", http.StatusInternalServerError)
+		return
+	}
+	names, err := readingMemberNames(ctx, readings)
+	if err != nil {
+		log.Printf("Failed to fetch member names: %v", err)
+		http.Error(w, "Failed to fetch meter consumption", http.StatusInternalServerError)
+		return
+	}
+
+	usages := models.MeterConsumption(readings, from, to)
+	for i := range usages {
+		usages[i].Name = names[usages[i].UserID]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(MeterConsumptionResponse{Meter: meter, From: from, To: to, Usages: usages})
+}
+
+// MeterReadingHandler serves a single meter reading of the user's group. GET /api/meters/readings/{id}
+// returns its details and DELETE removes it; PUT /api/meters/readings/{id}/photo uploads a photo of
+// the meter as the multipart "photo" field and DELETE removes it. Only the member who recorded the
+// reading or a group admin can change it.
+func MeterReadingHandler(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, meterReadingPath)
+	idStr, sub, _ := strings.Cut(path, "/")
+	readingID, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		http.Error(w, "Invalid reading ID format", http.StatusBadRequest)
+		return
+	}
+	if sub != "" && sub != "photo" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var reading models.MeterReading
+	err = config.DB.Collection("meter_readings").FindOne(
+		context.Background(),
+		bson.M{"_id": readingID, "group_id": user.GroupID},
+	).Decode(&reading)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Meter reading not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch meter reading", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if sub == "" && r.Method == http.MethodGet {
+		names, err := readingMemberNames(context.Background(), []models.MeterReading{reading})
+		if err != nil {
+			log.Printf("Failed to fetch member names: %v", err)
+			http.Error(w, "Failed to fetch meter reading", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(meterReadingDetails(reading, names, time.Now()))
+		return
+	}
+
+	if reading.RecordedBy != user.ID {
+		group, ok := getGroupByID(w, user.GroupID)
+		if !ok {
+			return
+		}
+		if !group.IsAdmin(user.ID) {
+			http.Error(w, "Only the member who recorded the reading or a group admin can change it", http.StatusForbidden)
+			return
+		}
+	}
+
+	switch {
+	case sub == "" && r.Method == http.MethodDelete:
+		deleteMeterReading(w, reading)
+	case sub == "photo" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		uploadMeterPhoto(w, r, reading)
+	case sub == "photo" && r.Method == http.MethodDelete:
+		deleteMeterPhoto(w, reading)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// deleteMeterReading removes a reading recorded by mistake, along with its photo. Bills already
+// split by the reading keep their shares.
+func deleteMeterReading(w http.ResponseWriter, reading models.MeterReading) {
+	if _, err := config.DB.Collection("meter_readings").DeleteOne(context.Background(), bson.M{"_id": reading.ID}); err != nil {
+		log.Printf("Failed to delete meter reading %s: %v", reading.ID.Hex(), err)
+		http.Error(w, "Failed to delete meter reading", http.StatusInternalServerError)
+		return
+	}
+	if reading.PhotoID != nil {
+		removeMeterPhoto(*reading.PhotoID)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadMeterPhoto stores a photo of the meter for a reading, replacing the previous one
+func uploadMeterPhoto(w http.ResponseWriter, r *http.Request, reading models.MeterReading) {
+	// Leave room for the multipart headers around the image
+	r.Body = http.MaxBytesReader(w, r.Body, storage.MaxImageSize+64<<10)
+	file, header, err := r.FormFile("photo")
+	if err != nil {
+		http.Error(w, "A photo under 5 MB is required in the \"photo\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read photo", http.StatusBadRequest)
+		return
+	}
+
+	photoID, err := storage.SaveImage(header.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrImageTooLarge):
+			http.Error(w, "Photo must be 5 MB or smaller", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrUnsupportedImage):
+			http.Error(w, "Photo must be a JPEG, PNG, GIF or WebP image", http.StatusUnsupportedMediaType)
+		default:
+			log.Printf("Failed to store photo of meter reading %s: %v", reading.ID.Hex(), err)
+			http.Error(w, "Failed to store photo", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_, err = config.DB.Collection("meter_readings").UpdateOne(
+		context.Background(),
+		bson.M{"_id": reading.ID},
+		bson.M{"$set": bson.M{"photo_id": photoID}},
+	)
+	if err != nil {
+		log.Printf("Failed to attach photo to meter reading %s: %v", reading.ID.Hex(), err)
+		storage.Delete(context.Background(), photoID)
+		http.Error(w, "Failed to attach photo", http.StatusInternalServerError)
+		return
+	}
+
+	if reading.PhotoID != nil {
+		removeMeterPhoto(*reading.PhotoID)
+	}
+	reading.PhotoID = &photoID
+
+	names, err := readingMemberNames(context.Background(), []models.MeterReading{reading})
+	if err != nil {
+		log.Printf("Failed to fetch member names: %v", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(meterReadingDetails(reading, names, time.Now()))
+}
+
+// deleteMeterPhoto detaches and removes a reading's photo
+func deleteMeterPhoto(w http.ResponseWriter, reading models.MeterReading) {
+	if reading.PhotoID == nil {
+		http.Error(w, "Meter reading has no photo", http.StatusNotFound)
+		return
+	}
+
+	_, err := config.DB.Collection("meter_readings").UpdateOne(
+		context.Background(),
+		bson.M{"_id": reading.ID},
+		bson.M{"$unset": bson.M{"photo_id": ""}},
+	)
+	if err != nil {
+		log.Printf("Failed to detach photo from meter reading %s: %v", reading.ID.Hex(), err)
+		http.Error(w, "Failed to delete photo", http.StatusInternalServerError)
+		return
+	}
+	removeMeterPhoto(*reading.PhotoID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeMeterPhoto deletes a stored meter photo in the background once nothing refers to it
+func removeMeterPhoto(photoID primitive.ObjectID) {
+	go func() {
+		if err := storage.Delete(context.Background(), photoID); err != nil {
+			log.Printf("Failed to delete meter photo %s: %v", photoID.Hex(), err)
+		}
+	}()
+}
+
+// ServeMeterPhotoHandler streams a meter photo from a signed URL handed out in reading details, so
+// image tags can load it without the auth header
+func ServeMeterPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	photoID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, meterPhotoPath))
+	if err != nil {
+		http.Error(w, "Invalid photo ID format", http.StatusBadRequest)
+		return
+	}
+	if err := storage.VerifySignature(photoID, r.URL.Query(), time.Now()); err != nil {
+		http.Error(w, "Photo link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	photo, contentType, err := storage.Open(context.Background(), photoID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Photo not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open meter photo %s: %v", photoID.Hex(), err)
+			http.Error(w, "Failed to fetch photo", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer photo.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	if _, err := io.Copy(w, photo); err != nil {
+		log.Printf("Failed to send meter photo %s: %v", photoID.Hex(), err)
+	}
+}
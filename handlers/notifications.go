@@ -0,0 +1,178 @@
+// handlers/notifications.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationsResponse is a page of the user's notifications with how many are unread in total
+type NotificationsResponse struct {
+	Notifications []models.Notification `json:"notifications"`
+	UnreadCount   int64                 `json:"unread_count"`
+	NextCursor    string                `json:"next_cursor,omitempty"`
+}
+
+// insertNotifications stores notifications created by a domain event. Failing to notify does not
+// undo the event, so errors are only logged.
+func insertNotifications(ctx context.Context, notifications []*models.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	documents := make([]interface{}, 0, len(notifications))
+	for _, notification := range notifications {
+		documents = append(documents, notification)
+	}
+	if _, err := config.DB.Collection("notifications").InsertMany(ctx, documents); err != nil {
+		log.Printf("Failed to create %d %s notifications: %v", len(notifications), notifications[0].Type, err)
+	}
+}
+
+// unreadNotificationCount counts the user's unread notifications
+func unreadNotificationCount(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return config.DB.Collection("notifications").CountDocuments(ctx, bson.M{"user_id": userID, "read": false})
+}
+
+// GetNotificationsHandler lists the requesting user's notifications, newest first and in their
+// locale, with their unread count. ?unread=true lists only unread ones; ?limit= and ?cursor= page
+// through them. ?before=<notification id> is still read in place of the cursor for older clients.
+func GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	filter := bson.M{"user_id": user.ID}
+	if query.Get("unread") == "true" {
+		filter["read"] = false
+	}
+
+	// IDs grow with creation time, so they order the pages without ties
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "_id", Descending: true}, models.DefaultPageSize)
+	if !ok {
+		return
+	}
+	if before := query.Get("before"); before != "" && page.Cursor == nil {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			http.Error(w, "Invalid before notification ID", http.StatusBadRequest)
+			return
+		}
+		page.Cursor = &models.PageCursor{ID: beforeID}
+	}
+
+	cursor, err := config.DB.Collection("notifications").Find(context.Background(), page.filter(filter), page.findOptions())
+	if err != nil {
+		http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
+	}
+	notifications := make([]models.Notification, 0)
+	if err := cursor.All(context.Background(), &notifications); err != nil {
+		http.Error(w, "Failed to decode notifications", http.StatusInternalServerError)
+		return
+	}
+	response := NotificationsResponse{Notifications: notifications}
+	if page.hasMore(len(notifications)) {
+		response.Notifications = notifications[:page.Limit]
+		response.NextCursor = nextCursor(w, models.PageCursor{ID: response.Notifications[page.Limit-1].ID})
+	}
+	locale := user.Locale()
+	for i := range response.Notifications {
+		response.Notifications[i].Localize(locale)
+	}
+	response.UnreadCount, err = unreadNotificationCount(context.Background(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to count unread notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// NotificationHandler handles the requesting user's notifications: GET
+// /api/notifications/unread-count returns the unread count for the bell icon, POST
+// /api/notifications/read-all marks every notification read and POST /api/notifications/{id}/read
+// marks one read.
+func NotificationHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/notifications/"), "/")
+	switch {
+	case path == "unread-count":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		unread, err := unreadNotificationCount(context.Background(), user.ID)
+		if err != nil {
+			http.Error(w, "Failed to count unread notifications", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]int64{"unread_count": unread})
+	case path == "read-all":
+		markNotificationsRead(w, r, user.ID, primitive.NilObjectID)
+	case strings.HasSuffix(path, "/read"):
+		notificationID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/read"))
+		if err != nil {
+			http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+			return
+		}
+		markNotificationsRead(w, r, user.ID, notificationID)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// markNotificationsRead marks one of the user's notifications read, or all of them when
+// notificationID is zero, and returns how many are left unread
+func markNotificationsRead(w http.ResponseWriter, r *http.Request, userID, notificationID primitive.ObjectID) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter := bson.M{"user_id": userID, "read": false}
+	if !notificationID.IsZero() {
+		filter = bson.M{"user_id": userID, "_id": notificationID}
+	}
+	result, err := config.DB.Collection("notifications").UpdateMany(
+		context.Background(),
+		filter,
+		bson.M{"$set": bson.M{"read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		http.Error(w, "Failed to mark notifications read", http.StatusInternalServerError)
+		return
+	}
+	if !notificationID.IsZero() && result.MatchedCount == 0 {
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+
+	unread, err := unreadNotificationCount(context.Background(), userID)
+	if err != nil {
+		http.Error(w, "Failed to count unread notifications", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int64{"marked_read": result.ModifiedCount, "unread_count": unread})
+}
@@ -0,0 +1,379 @@
+// handlers/oauth.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/voice"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ApproveOAuthRequest defines the request structure for approving a voice assistant's request to
+// link the member's account, with the parameters the assistant sent to the authorize endpoint
+type ApproveOAuthRequest struct {
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	State       string `json:"state"`
+}
+
+// ApproveOAuthResponse is where the app sends the member back to finish linking
+type ApproveOAuthResponse struct {
+	RedirectURL string `json:"redirect_url"`
+}
+
+// OAuthTokenResponse is the token endpoint's answer, as RFC 6749 defines it
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"` // Seconds
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// oauthError is an error from the token endpoint, as RFC 6749 defines it
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthAuthorizeHandler starts linking a member's account to a voice assistant. The assistant
+// sends the member here with its client_id, redirect_uri and state; the request is checked and the
+// member is sent on to the app's link-account page, which asks them to approve it.
+func OAuthAuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	assistant, ok := voice.FindAssistant(query.Get("client_id"))
+	if !ok {
+		http.Error(w, "Unknown client_id", http.StatusBadRequest)
+		return
+	}
+	// Errors are only sent back to a redirect URI the assistant registered
+	redirectURI := query.Get("redirect_uri")
+	if !assistant.AllowsRedirect(redirectURI) {
+		http.Error(w, "redirect_uri is not registered for this client", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" {
+		http.Redirect(w, r, oauthRedirect(redirectURI, url.Values{
+			"error": {"unsupported_response_type"},
+			"state": {query.Get("state")},
+		}), http.StatusFound)
+		return
+	}
+
+	link := url.Values{
+		"client_id":    {assistant.ClientID},
+		"redirect_uri": {redirectURI},
+		"state":        {query.Get("state")},
+		"assistant":    {assistant.Name},
+	}
+	http.Redirect(w, r, config.FrontendURL+"/link-account?"+link.Encode(), http.StatusFound)
+}
+
+// ApproveOAuthHandler links the requesting member's account to the voice assistant and returns
+// the redirect URI with an authorization code, which the app sends the member back to
+func ApproveOAuthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var request ApproveOAuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	assistant, ok := voice.FindAssistant(request.ClientID)
+	if !ok {
+		http.Error(w, "Unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if !assistant.AllowsRedirect(request.RedirectURI) {
+		http.Error(w, "redirect_uri is not registered for this client", http.StatusBadRequest)
+		return
+	}
+
+	grant, code, err := models.CreateOAuthGrant(user.ID, assistant.ClientID, assistant.Name, request.RedirectURI, time.Now())
+	if err != nil {
+		log.Printf("Failed to create authorization code: %v", err)
+		http.Error(w, "Failed to link account", http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Collection("oauth_grants").InsertOne(context.Background(), grant); err != nil {
+		log.Printf("Failed to store OAuth grant for user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to link account", http.StatusInternalServerError)
+		return
+	}
+
+	params := url.Values{"code": {code}}
+	if request.State != "" {
+		params.Set("state", request.State)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ApproveOAuthResponse{RedirectURL: oauthRedirect(request.RedirectURI, params)})
+}
+
+// oauthRedirect adds the parameters to the redirect URI's query
+func oauthRedirect(redirectURI string, params url.Values) string {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// OAuthTokenHandler exchanges an authorization code for tokens with the authorization_code grant
+// and issues a new access token with the refresh_token grant. Assistants authenticate with their
+// client credentials in basic auth or the form.
+func OAuthTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid form body")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	assistant, ok := voice.FindAssistant(clientID)
+	if !ok || !assistant.CheckSecret(clientSecret) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="cribb"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Unknown client or wrong secret")
+		return
+	}
+
+	switch r.PostFormValue("grant_type") {
+	case "authorization_code":
+		exchangeOAuthCode(w, r, assistant)
+	case "refresh_token":
+		refreshOAuthToken(w, r, assistant)
+	case "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "grant_type is required")
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "")
+	}
+}
+
+// exchangeOAuthCode issues the first tokens of a grant for its authorization code, which then
+// stops working. Other grants between the member and the assistant are removed, so relinking
+// replaces the old link.
+func exchangeOAuthCode(w http.ResponseWriter, r *http.Request, assistant voice.Assistant) {
+	ctx := context.Background()
+	now := time.Now()
+	code := r.PostFormValue("code")
+	if code == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "code is required")
+		return
+	}
+
+	accessToken, refreshToken, err := newOAuthTokens()
+	if err != nil {
+		log.Printf("Failed to create OAuth tokens: %v", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	accessExpiresAt := now.Add(models.OAuthAccessTokenTTL)
+
+	// Claiming the code and issuing the tokens in one update means a code is only used once
+	var grant models.OAuthGrant
+	err = config.DB.Collection("oauth_grants").FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"code_hash":       models.HashOAuthToken(code),
+			"client_id":       assistant.ClientID,
+			"redirect_uri":    r.PostFormValue("redirect_uri"),
+			"code_expires_at": bson.M{"$gt": now},
+		},
+		bson.M{
+			"$set": bson.M{
+				"access_token_hash":       models.HashOAuthToken(accessToken),
+				"access_token_expires_at": accessExpiresAt,
+				"refresh_token_hash":      models.HashOAuthToken(refreshToken),
+				"updated_at":              now,
+			},
+			"$unset": bson.M{"code_hash": "", "code_expires_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&grant)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The code is invalid, expired or already used")
+		} else {
+			log.Printf("Failed to exchange authorization code: %v", err)
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		}
+		return
+	}
+
+	_, err = config.DB.Collection("oauth_grants").DeleteMany(ctx, bson.M{
+		"_id":       bson.M{"$ne": grant.ID},
+		"user_id":   grant.UserID,
+		"client_id": grant.ClientID,
+	})
+	if err != nil {
+		log.Printf("Failed to remove earlier %s links of user %s: %v", assistant.Name, grant.UserID.Hex(), err)
+	}
+
+	writeOAuthTokens(w, accessToken, refreshToken)
+}
+
+// refreshOAuthToken issues a new access token for the grant with the refresh token, replacing the
+// refresh token too
+func refreshOAuthToken(w http.ResponseWriter, r *http.Request, assistant voice.Assistant) {
+	refreshToken := r.PostFormValue("refresh_token")
+	if refreshToken == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+		return
+	}
+
+	accessToken, newRefreshToken, err := newOAuthTokens()
+	if err != nil {
+		log.Printf("Failed to create OAuth tokens: %v", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	now := time.Now()
+	result, err := config.DB.Collection("oauth_grants").UpdateOne(
+		context.Background(),
+		bson.M{"refresh_token_hash": models.HashOAuthToken(refreshToken), "client_id": assistant.ClientID},
+		bson.M{"$set": bson.M{
+			"access_token_hash":       models.HashOAuthToken(accessToken),
+			"access_token_expires_at": now.Add(models.OAuthAccessTokenTTL),
+			"refresh_token_hash":      models.HashOAuthToken(newRefreshToken),
+			"updated_at":              now,
+		}},
+	)
+	if err != nil {
+		log.Printf("Failed to refresh OAuth token: %v", err)
+		writeOAuthError(w, http.StatusInternalServerError, "server_error", "")
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant", "The refresh token is invalid or the account was unlinked")
+		return
+	}
+
+	writeOAuthTokens(w, accessToken, newRefreshToken)
+}
+
+// newOAuthTokens returns a new access token and refresh token
+func newOAuthTokens() (string, string, error) {
+	accessToken, err := models.NewOAuthToken("cat_")
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := models.NewOAuthToken("crt_")
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+func writeOAuthTokens(w http.ResponseWriter, accessToken, refreshToken string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OAuthTokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(models.OAuthAccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(oauthError{Error: code, ErrorDescription: description})
+}
+
+// LinkedAssistantsHandler lists the voice assistants linked to the requesting member's account
+func LinkedAssistantsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("oauth_grants").Find(
+		context.Background(),
+		bson.M{"user_id": user.ID, "access_token_hash": bson.M{"$exists": true}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch linked assistants", http.StatusInternalServerError)
+		return
+	}
+	grants := make([]models.OAuthGrant, 0)
+	if err := cursor.All(context.Background(), &grants); err != nil {
+		http.Error(w, "Failed to decode linked assistants", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(grants)
+}
+
+// LinkedAssistantHandler unlinks a voice assistant from the requesting member's account, so its
+// tokens stop working.
+// Path format: /api/oauth/grants/{id}
+func LinkedAssistantHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	grantID, err := primitive.ObjectIDFromHex(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/oauth/grants/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+	result, err := config.DB.Collection("oauth_grants").DeleteOne(context.Background(), bson.M{"_id": grantID, "user_id": user.ID})
+	if err != nil {
+		log.Printf("Failed to delete OAuth grant %s: %v", grantID.Hex(), err)
+		http.Error(w, "Failed to unlink assistant", http.StatusInternalServerError)
+		return
+	}
+	if result.DeletedCount == 0 {
+		http.Error(w, "Linked assistant not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Assistant unlinked successfully"})
+}
@@ -0,0 +1,77 @@
+// handlers/pagination.go
+package handlers
+
+import (
+	"cribb-backend/models"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pageRequest is the page a list request asks for: up to Limit items in Order, following Cursor
+// when it is set. A Limit of models.Unpaged asks for every item.
+type pageRequest struct {
+	Order  models.PageOrder
+	Limit  int
+	Cursor *models.PageCursor
+}
+
+// parsePageRequest reads ?limit= and ?cursor= for a list endpoint in the given order, see
+// models.PageLimit for the default. It writes a bad request response and returns false when
+// either is invalid.
+func parsePageRequest(w http.ResponseWriter, r *http.Request, order models.PageOrder, defaultLimit int) (pageRequest, bool) {
+	page := pageRequest{Order: order}
+	query := r.URL.Query()
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := models.DecodePageCursor(value)
+		if err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return page, false
+		}
+		page.Cursor = &cursor
+	}
+
+	limit, err := models.PageLimit(query.Get("limit"), page.Cursor != nil, defaultLimit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", models.MaxPageSize), http.StatusBadRequest)
+		return page, false
+	}
+	page.Limit = limit
+
+	return page, true
+}
+
+// filter narrows filter to the items of the page
+func (p pageRequest) filter(filter bson.M) bson.M {
+	if p.Cursor == nil {
+		return filter
+	}
+	return bson.M{"$and": bson.A{filter, p.Order.After(*p.Cursor)}}
+}
+
+// findOptions sorts in the page order and fetches one item past the page, which tells whether
+// another page follows
+func (p pageRequest) findOptions() *options.FindOptions {
+	opts := options.Find().SetSort(p.Order.Sort())
+	if p.Limit == models.Unpaged {
+		return opts
+	}
+	return opts.SetLimit(int64(p.Limit + 1))
+}
+
+// hasMore reports whether fetched items run past the page; callers drop the items past Limit and
+// pass the last item they keep to nextCursor
+func (p pageRequest) hasMore(fetched int) bool {
+	return p.Limit != models.Unpaged && fetched > p.Limit
+}
+
+// nextCursor returns the encoded cursor of the page ending at last and sends it in the Next-Cursor
+// header, so endpoints that respond with a plain array can page too
+func nextCursor(w http.ResponseWriter, last models.PageCursor) string {
+	cursor := models.EncodePageCursor(last)
+	w.Header().Set("Next-Cursor", cursor)
+	return cursor
+}
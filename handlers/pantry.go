@@ -2,11 +2,13 @@ package handlers
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
 	"cribb-backend/middleware"
 	"cribb-backend/models"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -20,28 +22,35 @@ import (
 
 // AddPantryItemRequest defines the request structure for adding a pantry item
 type AddPantryItemRequest struct {
-	Name           string  `json:"name" validate:"required"`
-	Quantity       float64 `json:"quantity" validate:"required,min=0"`
-	Unit           string  `json:"unit" validate:"required"`
-	CategoryID     string  `json:"category_id" validate:"required"` // Now required, no fallbacks
-	ExpirationDate *string `json:"expiration_date,omitempty"`
-	GroupName      string  `json:"group_name" validate:"required"`
+	Name           string   `json:"name" validate:"required"`
+	Quantity       float64  `json:"quantity" validate:"required,min=0"`
+	Unit           string   `json:"unit"`                            // Empty for the existing item's unit, or individual items
+	CategoryID     string   `json:"category_id" validate:"required"` // Now required, no fallbacks
+	ExpirationDate *string  `json:"expiration_date,omitempty"`
+	MinQuantity    *float64 `json:"min_quantity,omitempty"` // Zero turns off automatic shopping list adds
+	Notes          *string  `json:"notes,omitempty"`
+	Visibility     string   `json:"visibility,omitempty"` // "shared" (default) or "personal" to the adding member
+	GroupName      string   `json:"group_name" validate:"required"`
 }
 
 // UpdatePantryItemRequest defines the request structure for updating a pantry item
 type UpdatePantryItemRequest struct {
-	Name           string  `json:"name" validate:"required"`
-	Quantity       float64 `json:"quantity" validate:"required,min=0"`
-	Unit           string  `json:"unit" validate:"required"`
-	CategoryID     string  `json:"category_id" validate:"required"` // Now required, no fallbacks
-	ExpirationDate *string `json:"expiration_date,omitempty"`
-	GroupName      string  `json:"group_name" validate:"required"`
+	Name           string   `json:"name" validate:"required"`
+	Quantity       float64  `json:"quantity" validate:"required,min=0"`
+	Unit           string   `json:"unit" validate:"required"`
+	CategoryID     string   `json:"category_id" validate:"required"` // Now required, no fallbacks
+	ExpirationDate *string  `json:"expiration_date,omitempty"`
+	MinQuantity    *float64 `json:"min_quantity,omitempty"` // Zero turns off automatic shopping list adds
+	Notes          *string  `json:"notes,omitempty"`
+	Visibility     string   `json:"visibility,omitempty"` // "shared" or "personal" to the editing member; unchanged when empty
+	GroupName      string   `json:"group_name" validate:"required"`
 }
 
 // UsePantryItemRequest defines the request structure for using a pantry item
 type UsePantryItemRequest struct {
 	ItemID   string  `json:"item_id" validate:"required"`
 	Quantity float64 `json:"quantity" validate:"required,min=0.1"`
+	Unit     string  `json:"unit,omitempty"` // Defaults to the item's unit, e.g. "500 g" from an item tracked in kg
 }
 
 // PantryItemWithCategory represents a pantry item with resolved category information
@@ -51,6 +60,9 @@ type PantryItemWithCategory struct {
 	IsExpiringSoon bool         `json:"is_expiring_soon"`
 	IsExpired      bool         `json:"is_expired"`
 	AddedByName    string       `json:"added_by_name"`
+	OwnerName      string       `json:"owner_name,omitempty"`
+	IsMine         bool         `json:"is_mine"` // Personal item owned by the requesting user
+	PhotoURL       string       `json:"photo_url,omitempty"`
 }
 
 // CategoryInfo represents resolved category information
@@ -95,7 +107,10 @@ func validateCategoryID(categoryIDStr string, groupID primitive.ObjectID) (*mode
 	return &category, nil
 }
 
-// AddPantryItemHandler creates or updates a pantry item
+// errPantryUnitMismatch is returned when a quantity cannot be converted into an item's unit
+var errPantryUnitMismatch = errors.New("unit cannot be converted into the item's unit")
+
+// AddPantryItemHandler creates an item or adds the quantity to the matching one, in its unit
 func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -116,8 +131,16 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if request.Name == "" || request.Quantity < 0 || request.Unit == "" || request.GroupName == "" || request.CategoryID == "" {
-		http.Error(w, "Name, quantity, unit, category_id, and group name are required", http.StatusBadRequest)
+	if request.Name == "" || request.Quantity < 0 || request.GroupName == "" || request.CategoryID == "" {
+		http.Error(w, "Name, quantity, category_id, and group name are required", http.StatusBadRequest)
+		return
+	}
+	if request.MinQuantity != nil && *request.MinQuantity < 0 {
+		http.Error(w, "Minimum quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if request.Visibility != "" && !models.IsValidPantryVisibility(models.PantryVisibility(request.Visibility)) {
+		http.Error(w, "Visibility must be \"shared\" or \"personal\"", http.StatusBadRequest)
 		return
 	}
 
@@ -195,19 +218,26 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer session.EndSession(context.Background())
 
+	// Personal items belong to the member adding them
+	var owner *primitive.ObjectID
+	if models.PantryVisibility(request.Visibility) == models.VisibilityPersonal {
+		owner = &userID
+	}
+
 	// Start transaction
 	var pantryItem models.PantryItem
 	var isNewItem bool = true
 	var oldQuantity float64 = 0
 
 	err = mongo.WithSession(context.Background(), session, func(sc mongo.SessionContext) error {
-		// Check if item already exists in this group with same name and category
+		// Check if item already exists in this group with same name, category and owner
 		existingItem := config.DB.Collection("pantry_items").FindOne(
 			sc,
 			bson.M{
 				"group_id":    group.ID,
 				"name":        bson.M{"$regex": primitive.Regex{Pattern: "^" + strings.TrimSpace(request.Name) + "$", Options: "i"}},
 				"category_id": categoryID,
+				"owner_id":    owner,
 			},
 		)
 
@@ -219,10 +249,18 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 
 			isNewItem = false
 			oldQuantity = pantryItem.Quantity
+			before := pantryItem
 
-			// Update the item
-			pantryItem.Quantity = request.Quantity
-			pantryItem.Unit = request.Unit
+			// Add to the stock in the item's own unit, which never changes here
+			if !pantryItem.AddStock(request.Quantity, request.Unit) {
+				return errPantryUnitMismatch
+			}
+			if request.MinQuantity != nil {
+				pantryItem.MinQuantity, _ = pantryItem.InItemUnit(*request.MinQuantity, request.Unit)
+			}
+			if request.Notes != nil {
+				pantryItem.Notes = strings.TrimSpace(*request.Notes)
+			}
 			if !expirationDate.IsZero() {
 				pantryItem.ExpirationDate = expirationDate
 			}
@@ -236,6 +274,9 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 			if err != nil {
 				return err
 			}
+			if err := recordPantryItemVersion(sc, &before, &pantryItem, models.ActionTypeAdd, user); err != nil {
+				return err
+			}
 		} else if errors.Is(existingItem.Err(), mongo.ErrNoDocuments) {
 			// Item doesn't exist, create new one
 			pantryItem = *models.CreatePantryItem(
@@ -247,25 +288,35 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 				expirationDate,
 				userID,
 			)
+			pantryItem.SetOwner(owner)
+			if request.MinQuantity != nil {
+				pantryItem.MinQuantity = *request.MinQuantity
+			}
+			if request.Notes != nil {
+				pantryItem.Notes = strings.TrimSpace(*request.Notes)
+			}
 
 			result, err := config.DB.Collection("pantry_items").InsertOne(sc, pantryItem)
 			if err != nil {
 				return err
 			}
 			pantryItem.ID = result.InsertedID.(primitive.ObjectID)
+			if err := recordPantryItemVersion(sc, nil, &pantryItem, models.ActionTypeAdd, user); err != nil {
+				return err
+			}
 		} else {
 			// Some other error occurred
 			return existingItem.Err()
 		}
 
 		// Check if we need to create expiration notification
-		if !expirationDate.IsZero() && pantryItem.IsExpiringSoon(3) {
+		if !expirationDate.IsZero() && pantryItem.IsExpiringSoon(group.Settings.ExpirationAlertWindow()) {
 			notification := models.CreatePantryNotification(
 				group.ID,
 				pantryItem.ID,
 				pantryItem.Name,
 				models.NotificationTypeExpiringSoon,
-				"Item will expire in 3 days or less",
+				fmt.Sprintf("Item will expire in %d days or less", group.Settings.ExpirationAlertWindow()),
 			)
 			_, err = config.DB.Collection("pantry_notifications").InsertOne(sc, notification)
 			if err != nil {
@@ -278,6 +329,14 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
+		if errors.Is(err, errPantryUnitMismatch) {
+			unit := pantryItem.Unit
+			if unit == "" {
+				unit = "individual items"
+			}
+			writeError(w, r, apierror.CodeUnitMismatch, fmt.Sprintf("%s is tracked in %s; add it in a compatible unit", pantryItem.Name, unit))
+			return
+		}
 		log.Printf("Transaction failed: %v", err)
 		http.Error(w, "Failed to add/update pantry item", http.StatusInternalServerError)
 		return
@@ -295,14 +354,14 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		)
 	} else {
 		// If quantity changed, record it as an update
-		if oldQuantity != request.Quantity {
+		if oldQuantity != pantryItem.Quantity {
 			UpdatePantryHistoryForAdd(
 				group.ID,
 				pantryItem.ID,
 				pantryItem.Name,
 				userID,
 				user.Name,
-				request.Quantity-oldQuantity,
+				pantryItem.Quantity-oldQuantity,
 			)
 		}
 	}
@@ -315,9 +374,14 @@ func AddPantryItemHandler(w http.ResponseWriter, r *http.Request) {
 			Name: category.Name,
 			Type: string(category.Type),
 		},
-		IsExpiringSoon: pantryItem.IsExpiringSoon(3),
+		IsExpiringSoon: pantryItem.IsExpiringSoon(group.Settings.ExpirationAlertWindow()),
 		IsExpired:      pantryItem.IsExpired(),
 		AddedByName:    user.Name,
+		IsMine:         pantryItem.IsOwnedBy(userID),
+		PhotoURL:       pantryPhotoURL(pantryItem),
+	}
+	if responseItem.IsMine {
+		responseItem.OwnerName = user.Name
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -359,15 +423,100 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	updatePantryItem(w, userClaims.ID, itemID, request, false)
+}
+
+// PatchPantryItemHandler changes only the fields of a pantry item a JSON Merge Patch names. Null
+// clears the expiration date, notes and minimum quantity, or shares a personal item.
+func PatchPantryItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, "/api/pantry/update/"))
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	patch, ok := readMergePatch(w, r, "name", "quantity", "unit", "category_id", "expiration_date", "min_quantity", "notes", "visibility")
+	if !ok {
+		return
+	}
+	if err := patch.Require("name", "quantity", "unit", "category_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var item models.PantryItem
+	err = config.DB.Collection("pantry_items").FindOne(
+		context.Background(),
+		bson.M{"_id": itemID, "group_id": user.GroupID},
+	).Decode(&item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Pantry item not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch pantry item", http.StatusInternalServerError)
+		}
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	// Start from the item as a full update, leaving out the fields an update keeps when omitted
+	request := UpdatePantryItemRequest{
+		Name:       item.Name,
+		Quantity:   item.Quantity,
+		Unit:       item.Unit,
+		CategoryID: item.CategoryID.Hex(),
+		GroupName:  group.Name,
+	}
+	if err := patch.Apply(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if patch.IsNull("min_quantity") {
+		request.MinQuantity = new(float64)
+	}
+	if patch.IsNull("notes") {
+		request.Notes = new(string)
+	}
+	if patch.IsNull("visibility") {
+		request.Visibility = string(models.VisibilityShared)
+	}
+
+	updatePantryItem(w, user.ID.Hex(), itemID, request, patch.IsNull("expiration_date"))
+}
+
+// updatePantryItem saves every field of the request to the pantry item, keeping its expiration
+// date when none is given unless clearExpiration is set
+func updatePantryItem(w http.ResponseWriter, userIDHex string, itemID primitive.ObjectID, request UpdatePantryItemRequest, clearExpiration bool) {
 	// Validate required fields
 	if request.Name == "" || request.Quantity < 0 || request.Unit == "" || request.GroupName == "" || request.CategoryID == "" {
 		http.Error(w, "Name, quantity, unit, category_id, and group name are required", http.StatusBadRequest)
 		return
 	}
+	if request.MinQuantity != nil && *request.MinQuantity < 0 {
+		http.Error(w, "Minimum quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if request.Visibility != "" && !models.IsValidPantryVisibility(models.PantryVisibility(request.Visibility)) {
+		http.Error(w, "Visibility must be \"shared\" or \"personal\"", http.StatusBadRequest)
+		return
+	}
 
 	// Find the group
 	var group models.Group
-	err = config.DB.Collection("groups").FindOne(
+	err := config.DB.Collection("groups").FindOne(
 		context.Background(),
 		bson.M{"name": request.GroupName},
 	).Decode(&group)
@@ -382,7 +531,7 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user ID
-	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
+	userID, err := primitive.ObjectIDFromHex(userIDHex)
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
@@ -461,16 +610,43 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		if pantryItem.GroupID != user.GroupID {
 			return errors.New("pantry item does not belong to user's group")
 		}
+		if !pantryItem.CanBeModifiedBy(user.ID) {
+			return errors.New("only the owner can edit a personal pantry item")
+		}
+		before := pantryItem
 
+		// Compare quantities in the new unit so switching from kg to g is not a change
 		oldQuantity = pantryItem.Quantity
+		if converted, ok := models.ConvertQuantity(pantryItem.Quantity, pantryItem.Unit, request.Unit); ok {
+			oldQuantity = converted
+		}
 
 		// Update the item fields
+		if request.MinQuantity != nil {
+			pantryItem.MinQuantity = *request.MinQuantity
+		} else if converted, ok := models.ConvertQuantity(pantryItem.MinQuantity, pantryItem.Unit, request.Unit); ok {
+			pantryItem.MinQuantity = converted
+		}
+		if request.Notes != nil {
+			pantryItem.Notes = strings.TrimSpace(*request.Notes)
+		}
+		switch models.PantryVisibility(request.Visibility) {
+		case models.VisibilityPersonal:
+			pantryItem.SetOwner(&user.ID)
+		case models.VisibilityShared:
+			pantryItem.SetOwner(nil)
+		}
 		pantryItem.Name = request.Name
 		pantryItem.Quantity = request.Quantity
 		pantryItem.Unit = request.Unit
 		pantryItem.CategoryID = categoryID
+		update := bson.M{"$set": &pantryItem}
 		if !expirationDate.IsZero() {
 			pantryItem.ExpirationDate = expirationDate
+		} else if clearExpiration {
+			// A zero expiration date is left out of $set, so it has to be removed
+			pantryItem.ExpirationDate = time.Time{}
+			update["$unset"] = bson.M{"expiration_date": ""}
 		}
 		pantryItem.UpdatedAt = time.Now()
 
@@ -478,20 +654,31 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		_, err = config.DB.Collection("pantry_items").UpdateOne(
 			sc,
 			bson.M{"_id": pantryItem.ID},
-			bson.M{"$set": pantryItem},
+			update,
 		)
 		if err != nil {
 			return err
 		}
+		if err := recordPantryItemVersion(sc, &before, &pantryItem, models.ActionTypeUpdate, user); err != nil {
+			return err
+		}
+
+		// Lowering the quantity by hand counts as using the difference
+		if pantryItem.Quantity < oldQuantity {
+			usage := models.CreatePantryUsage(&pantryItem, user.ID, user.Name, oldQuantity-pantryItem.Quantity, models.UsageSourceUpdate)
+			if _, err := config.DB.Collection("pantry_usage").InsertOne(sc, usage); err != nil {
+				return err
+			}
+		}
 
 		// Check if we need to create expiration notification
-		if !expirationDate.IsZero() && pantryItem.IsExpiringSoon(3) {
+		if !expirationDate.IsZero() && pantryItem.IsExpiringSoon(group.Settings.ExpirationAlertWindow()) {
 			notification := models.CreatePantryNotification(
 				group.ID,
 				pantryItem.ID,
 				pantryItem.Name,
 				models.NotificationTypeExpiringSoon,
-				"Item will expire in 3 days or less",
+				fmt.Sprintf("Item will expire in %d days or less", group.Settings.ExpirationAlertWindow()),
 			)
 			_, err = config.DB.Collection("pantry_notifications").InsertOne(sc, notification)
 			if err != nil {
@@ -529,9 +716,14 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 			Name: category.Name,
 			Type: string(category.Type),
 		},
-		IsExpiringSoon: pantryItem.IsExpiringSoon(3),
+		IsExpiringSoon: pantryItem.IsExpiringSoon(group.Settings.ExpirationAlertWindow()),
 		IsExpired:      pantryItem.IsExpired(),
 		AddedByName:    user.Name,
+		IsMine:         pantryItem.IsOwnedBy(userID),
+		PhotoURL:       pantryPhotoURL(pantryItem),
+	}
+	if responseItem.IsMine {
+		responseItem.OwnerName = user.Name
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -539,7 +731,9 @@ func UpdatePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(responseItem)
 }
 
-// GetPantryItemsHandler retrieves all pantry items for a group with resolved category information
+// GetPantryItemsHandler retrieves all pantry items for a group with resolved category information.
+// Items can be filtered by category_id, visibility (shared or personal), owner_id, or mine=true for
+// the requesting user's personal items.
 func GetPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -556,6 +750,8 @@ func GetPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
 	groupName := r.URL.Query().Get("group_name")
 	categoryFilter := r.URL.Query().Get("category_id")
+	visibilityFilter := r.URL.Query().Get("visibility")
+	ownerFilter := r.URL.Query().Get("owner_id")
 
 	// Verify group name is provided
 	if groupName == "" {
@@ -618,6 +814,28 @@ func GetPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
 		}
 		filter["category_id"] = categoryID
 	}
+	switch models.PantryVisibility(visibilityFilter) {
+	case "":
+	case models.VisibilityShared:
+		// Items from before visibility was tracked are shared
+		filter["visibility"] = bson.M{"$ne": models.VisibilityPersonal}
+	case models.VisibilityPersonal:
+		filter["visibility"] = models.VisibilityPersonal
+	default:
+		http.Error(w, "Visibility must be \"shared\" or \"personal\"", http.StatusBadRequest)
+		return
+	}
+	if r.URL.Query().Get("mine") == "true" {
+		ownerFilter = userID.Hex()
+	}
+	if ownerFilter != "" {
+		ownerID, err := primitive.ObjectIDFromHex(ownerFilter)
+		if err != nil {
+			http.Error(w, "Invalid owner ID format", http.StatusBadRequest)
+			return
+		}
+		filter["owner_id"] = ownerID
+	}
 
 	// Find pantry items
 	opts := options.Find().SetSort(bson.D{
@@ -646,13 +864,30 @@ func GetPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
 	response := make([]PantryItemWithCategory, 0, len(pantryItems))
 	categoryCache := make(map[string]*models.PantryCategory)
 	userCache := make(map[string]string)
+	userName := func(id primitive.ObjectID) string {
+		name, found := userCache[id.Hex()]
+		if !found {
+			var member models.User
+			err := config.DB.Collection("users").FindOne(
+				context.Background(),
+				bson.M{"_id": id},
+			).Decode(&member)
+			if err == nil {
+				name = member.Name
+				userCache[id.Hex()] = name
+			}
+		}
+		return name
+	}
 
 	for _, item := range pantryItems {
 		extendedItem := PantryItemWithCategory{
 			PantryItem:     item,
-			IsExpiringSoon: item.IsExpiringSoon(3),
+			IsExpiringSoon: item.IsExpiringSoon(group.Settings.ExpirationAlertWindow()),
 			IsExpired:      item.IsExpired(),
 			AddedByName:    "",
+			IsMine:         item.IsOwnedBy(userID),
+			PhotoURL:       pantryPhotoURL(item),
 		}
 
 		// Get category information
@@ -678,21 +913,11 @@ func GetPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
-		// Get the name of the user who added the item
-		userIDStr := item.AddedBy.Hex()
-		userName, found := userCache[userIDStr]
-		if !found {
-			var addedByUser models.User
-			err = config.DB.Collection("users").FindOne(
-				context.Background(),
-				bson.M{"_id": item.AddedBy},
-			).Decode(&addedByUser)
-			if err == nil {
-				userName = addedByUser.Name
-				userCache[userIDStr] = userName
-			}
+		// Get the names of the user who added the item and of its owner
+		extendedItem.AddedByName = userName(item.AddedBy)
+		if item.IsPersonal() {
+			extendedItem.OwnerName = userName(*item.OwnerID)
 		}
-		extendedItem.AddedByName = userName
 
 		response = append(response, extendedItem)
 	}
@@ -774,9 +999,13 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		Unit         string  `json:"unit"`
 	}
 	var response UsePantryItemResponse
+	var restockItem *models.PantryItem
+	var usedQuantity float64
 
 	// Start transaction
 	err = mongo.WithSession(context.Background(), session, func(sc mongo.SessionContext) error {
+		restockItem = nil
+
 		// Find the pantry item
 		var pantryItem models.PantryItem
 		err := config.DB.Collection("pantry_items").FindOne(
@@ -795,15 +1024,34 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		if pantryItem.GroupID != user.GroupID {
 			return errors.New("pantry item does not belong to user's group")
 		}
+		if !pantryItem.CanBeModifiedBy(user.ID) {
+			return errors.New("only the owner can use a personal pantry item")
+		}
+		before := pantryItem
+
+		used, ok := pantryItem.InItemUnit(request.Quantity, request.Unit)
+		if !ok {
+			return fmt.Errorf("cannot use %s of an item tracked in %s", request.Unit, pantryItem.Unit)
+		}
 
 		// Check if there's enough quantity
-		if pantryItem.Quantity < request.Quantity {
+		if pantryItem.Quantity < used {
 			return errors.New("not enough quantity available")
 		}
 
 		// Update the quantity
-		newQuantity := pantryItem.Quantity - request.Quantity
+		wasBelowMinimum := pantryItem.IsBelowMinimum()
+		newQuantity := pantryItem.Quantity - used
 		pantryItem.UpdateQuantity(newQuantity)
+		if pantryItem.IsBelowMinimum() && !wasBelowMinimum {
+			restockItem = &pantryItem
+		}
+
+		usedQuantity = used
+		usage := models.CreatePantryUsage(&pantryItem, user.ID, user.Name, used, models.UsageSourceUse)
+		if _, err := config.DB.Collection("pantry_usage").InsertOne(sc, usage); err != nil {
+			return err
+		}
 
 		_, err = config.DB.Collection("pantry_items").UpdateOne(
 			sc,
@@ -816,6 +1064,9 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		if err != nil {
 			return err
 		}
+		if err := recordPantryItemVersion(sc, &before, &pantryItem, models.ActionTypeUse, user); err != nil {
+			return err
+		}
 
 		// Set response values
 		response.Success = true
@@ -824,7 +1075,7 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		response.Unit = pantryItem.Unit
 
 		// Check if low-stock notification is needed (if quantity is below threshold)
-		if newQuantity > 0 && newQuantity <= 1 {
+		if newQuantity > 0 && newQuantity <= pantryItem.LowStockThreshold() {
 			notification := models.CreatePantryNotification(
 				pantryItem.GroupID,
 				pantryItem.ID,
@@ -878,6 +1129,11 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Put the item on the shopping list once it drops below its minimum
+	if restockItem != nil {
+		go addLowStockItemToShoppingList(user, *restockItem)
+	}
+
 	// Create history record for using an item
 	itemID, _ = primitive.ObjectIDFromHex(request.ItemID)
 	var pantryItem models.PantryItem
@@ -893,7 +1149,7 @@ func UsePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 			pantryItem.Name,
 			userID,
 			user.Name,
-			request.Quantity,
+			usedQuantity,
 		)
 	}
 
@@ -1000,6 +1256,17 @@ func DeletePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 			return errors.New("pantry item does not belong to user's group")
 		}
 
+		// Personal items can be cleared out by their owner or a group admin, e.g. after a move-out
+		if !pantryItem.CanBeModifiedBy(user.ID) {
+			var group models.Group
+			if err := config.DB.Collection("groups").FindOne(sc, bson.M{"_id": pantryItem.GroupID}).Decode(&group); err != nil {
+				return err
+			}
+			if !group.IsAdmin(user.ID) {
+				return errors.New("only the owner or a group admin can delete a personal pantry item")
+			}
+		}
+
 		// Delete the pantry item
 		_, err = config.DB.Collection("pantry_items").DeleteOne(
 			sc,
@@ -1028,6 +1295,10 @@ func DeletePantryItemHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if pantryItem.PhotoID != nil {
+		removePantryPhoto(*pantryItem.PhotoID)
+	}
+
 	// Create history record for removing an item
 	if itemName != "" {
 		UpdatePantryHistoryForRemove(
@@ -0,0 +1,189 @@
+// handlers/pantry_analytics.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetPantryAnalyticsHandler breaks down the group's pantry by category: items stocked now, and
+// money spent and food wasted per month. Path format: /api/groups/{id}/pantry/analytics?months=6
+func GetPantryAnalyticsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/pantry/analytics"))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	months := models.DefaultAnalyticsMonths
+	if monthsStr := r.URL.Query().Get("months"); monthsStr != "" {
+		parsed, err := strconv.Atoi(monthsStr)
+		if err != nil || parsed < 1 || parsed > models.MaxAnalyticsMonths {
+			http.Error(w, fmt.Sprintf("Months must be between 1 and %d", models.MaxAnalyticsMonths), http.StatusBadRequest)
+			return
+		}
+		months = parsed
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	if requester.GroupID != groupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	monthList := models.AnalyticsMonths(time.Now(), months)
+	start, _, _ := models.MonthBounds(monthList[0])
+	ctx := context.Background()
+
+	itemCounts, err := countPantryItemsByCategory(ctx, groupID)
+	if err != nil {
+		log.Printf("Failed to count pantry items by category: %v", err)
+		http.Error(w, "Failed to build pantry analytics", http.StatusInternalServerError)
+		return
+	}
+
+	spend, err := categoryMonthTotals(ctx, "purchase_history", groupID, "purchased_at", start, bson.M{
+		"purchases": bson.M{"$sum": 1},
+		"spend":     bson.M{"$sum": "$price"},
+	})
+	if err != nil {
+		log.Printf("Failed to total purchases by category: %v", err)
+		http.Error(w, "Failed to build pantry analytics", http.StatusInternalServerError)
+		return
+	}
+
+	waste, err := categoryMonthTotals(ctx, "pantry_waste", groupID, "discarded_at", start, bson.M{
+		"discards":    bson.M{"$sum": 1},
+		"waste_value": bson.M{"$sum": "$estimated_value"},
+	})
+	if err != nil {
+		log.Printf("Failed to total waste by category: %v", err)
+		http.Error(w, "Failed to build pantry analytics", http.StatusInternalServerError)
+		return
+	}
+
+	names, err := pantryCategoryNames(ctx, itemCounts, spend, waste)
+	if err != nil {
+		log.Printf("Failed to fetch category names: %v", err)
+		http.Error(w, "Failed to build pantry analytics", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.BuildPantryAnalytics(monthList, names, itemCounts, spend, waste))
+}
+
+// countPantryItemsByCategory counts the group's pantry items in each category
+func countPantryItemsByCategory(ctx context.Context, groupID primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	cursor, err := config.DB.Collection("pantry_items").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": groupID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$ifNull": bson.A{"$category_id", primitive.NilObjectID}},
+			"count": bson.M{"$sum": 1},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		CategoryID primitive.ObjectID `bson:"_id"`
+		Count      int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// categoryMonthTotals groups a collection's documents since start by category and month of the
+// date field, computing the given accumulators
+func categoryMonthTotals(ctx context.Context, collection string, groupID primitive.ObjectID, dateField string, start time.Time, accumulators bson.M) ([]models.CategoryMonthTotals, error) {
+	group := bson.M{"_id": bson.M{
+		"category_id": bson.M{"$ifNull": bson.A{"$category_id", primitive.NilObjectID}},
+		"month":       bson.M{"$dateToString": bson.M{"format": "%Y-%m", "date": "$" + dateField}},
+	}}
+	project := bson.M{"_id": 0, "category_id": "$_id.category_id", "month": "$_id.month"}
+	for field, accumulator := range accumulators {
+		group[field] = accumulator
+		project[field] = 1
+	}
+
+	cursor, err := config.DB.Collection(collection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": groupID, dateField: bson.M{"$gte": start}}}},
+		{{Key: "$group", Value: group}},
+		{{Key: "$project", Value: project}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	totals := make([]models.CategoryMonthTotals, 0)
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// pantryCategoryNames looks up the names of every category appearing in the analytics
+func pantryCategoryNames(ctx context.Context, itemCounts map[primitive.ObjectID]int, spend, waste []models.CategoryMonthTotals) (map[primitive.ObjectID]string, error) {
+	ids := make([]primitive.ObjectID, 0, len(itemCounts))
+	for id := range itemCounts {
+		ids = append(ids, id)
+	}
+	for _, rows := range [][]models.CategoryMonthTotals{spend, waste} {
+		for _, row := range rows {
+			if !row.CategoryID.IsZero() {
+				ids = append(ids, row.CategoryID)
+			}
+		}
+	}
+
+	names := make(map[primitive.ObjectID]string)
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	cursor, err := config.DB.Collection("pantry_categories").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []models.PantryCategory
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
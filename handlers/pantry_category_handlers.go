@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
 	"cribb-backend/middleware"
 	"cribb-backend/models"
@@ -37,6 +38,8 @@ type CategoryWithCreator struct {
 	GroupName   *string            `json:"group_name,omitempty"`
 	CreatedBy   *string            `json:"created_by,omitempty"`
 	CreatedByID *string            `json:"created_by_id,omitempty"`
+	SortOrder   int                `json:"sort_order"`
+	IsActive    bool               `json:"is_active"`
 }
 
 // StructuredCategoryResponse represents the new structured response format
@@ -135,10 +138,15 @@ func GetPantryCategoriesHandler(w http.ResponseWriter, r *http.Request) {
 			},
 		},
 	}
+	// Deactivated custom categories are only listed on request so they can be reactivated
+	if r.URL.Query().Get("include_inactive") == "true" {
+		delete(filter, "is_active")
+	}
 
-	// Sort by type (predefined first) then by name
+	// Sort by type (predefined first), then by the group's order, then by name
 	opts := options.Find().SetSort(bson.D{
 		{Key: "type", Value: 1}, // predefined comes before custom alphabetically
+		{Key: "sort_order", Value: 1},
 		{Key: "name", Value: 1},
 	})
 
@@ -168,9 +176,11 @@ func GetPantryCategoriesHandler(w http.ResponseWriter, r *http.Request) {
 
 	for _, category := range categories {
 		categoryWithCreator := CategoryWithCreator{
-			ID:   category.ID,
-			Name: category.Name,
-			Type: string(category.Type),
+			ID:        category.ID,
+			Name:      category.Name,
+			Type:      string(category.Type),
+			SortOrder: category.SortOrder,
+			IsActive:  category.IsActive,
 		}
 
 		if category.IsPredefined() {
@@ -292,7 +302,7 @@ func CreatePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		if existingCategory.IsCustom() {
 			categoryType = "custom"
 		}
-		http.Error(w, "A "+categoryType+" category with this name already exists", http.StatusConflict)
+		writeError(w, r, apierror.CodeDuplicateItem, "A "+categoryType+" category with this name already exists")
 		return
 	} else if !errors.Is(err, mongo.ErrNoDocuments) {
 		// Some other error occurred
@@ -301,8 +311,18 @@ func CreatePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create new custom category
+	// Create new custom category, placed after the group's existing ones
 	newCategory := models.CreateCustomCategory(categoryName, user.GroupID, userID)
+	customCount, err := config.DB.Collection("pantry_categories").CountDocuments(
+		context.Background(),
+		bson.M{"type": models.CategoryTypeCustom, "group_id": user.GroupID},
+	)
+	if err != nil {
+		log.Printf("Failed to count custom categories: %v", err)
+		http.Error(w, "Failed to create category", http.StatusInternalServerError)
+		return
+	}
+	newCategory.SortOrder = int(customCount) + 1
 
 	// Insert the category
 	result, err := config.DB.Collection("pantry_categories").InsertOne(
@@ -313,7 +333,7 @@ func CreatePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Failed to create custom category: %v", err)
 		if mongo.IsDuplicateKeyError(err) {
-			http.Error(w, "Category with this name already exists for your group", http.StatusConflict)
+			writeError(w, r, apierror.CodeDuplicateItem, "Category with this name already exists for your group")
 		} else {
 			http.Error(w, "Failed to create category", http.StatusInternalServerError)
 		}
@@ -451,7 +471,7 @@ func UpdatePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		if existingCategory.IsCustom() {
 			categoryType = "custom"
 		}
-		http.Error(w, "A "+categoryType+" category with this name already exists", http.StatusConflict)
+		writeError(w, r, apierror.CodeDuplicateItem, "A "+categoryType+" category with this name already exists")
 		return
 	} else if !errors.Is(err, mongo.ErrNoDocuments) {
 		// Some other error occurred
@@ -494,7 +514,8 @@ func UpdatePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// DeletePantryCategoryHandler deletes a custom category (only if no items use it)
+// DeletePantryCategoryHandler deletes a custom category. Categories still used by pantry items
+// can only be deleted with ?reassign_to={category_id}, which moves the items there first.
 func DeletePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -583,8 +604,19 @@ func DeletePantryCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if itemCount > 0 {
-		http.Error(w, "Cannot delete category: it is being used by pantry items", http.StatusConflict)
+	if reassignTo := r.URL.Query().Get("reassign_to"); reassignTo != "" {
+		target, ok := getReassignTarget(w, reassignTo, &category)
+		if !ok {
+			return
+		}
+
+		if _, err := reassignCategoryItems(user.GroupID, categoryID, target); err != nil {
+			log.Printf("Failed to reassign items of category %s: %v", categoryID.Hex(), err)
+			http.Error(w, "Failed to move category items", http.StatusInternalServerError)
+			return
+		}
+	} else if itemCount > 0 {
+		writeError(w, r, apierror.CodeCategoryInUse, "Cannot delete category: it is being used by pantry items. Pass reassign_to to move them to another category")
 		return
 	}
 
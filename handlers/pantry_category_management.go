@@ -0,0 +1,304 @@
+// handlers/pantry_category_management.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReorderCategoriesRequest defines the request structure for ordering a group's custom categories
+type ReorderCategoriesRequest struct {
+	CategoryIDs []string `json:"category_ids"` // Categories left out are placed after these by name
+}
+
+// MergeCategoryRequest defines the request structure for merging a custom category into another
+type MergeCategoryRequest struct {
+	TargetID string `json:"target_id"`
+}
+
+// getEditableCategory fetches a category the user may change, writing the error response otherwise
+func getEditableCategory(w http.ResponseWriter, idStr string, user models.User) (*models.PantryCategory, bool) {
+	categoryID, err := primitive.ObjectIDFromHex(idStr)
+	if err != nil {
+		http.Error(w, "Invalid category ID format", http.StatusBadRequest)
+		return nil, false
+	}
+
+	var category models.PantryCategory
+	err = config.DB.Collection("pantry_categories").FindOne(
+		context.Background(),
+		bson.M{"_id": categoryID},
+	).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Category not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch category", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+
+	if !category.CanBeEditedBy(user.ID, user.GroupID) {
+		if category.IsPredefined() {
+			http.Error(w, "Predefined categories cannot be edited", http.StatusForbidden)
+		} else {
+			http.Error(w, "You can only edit custom categories from your group", http.StatusForbidden)
+		}
+		return nil, false
+	}
+
+	return &category, true
+}
+
+// getReassignTarget resolves the category a category's items are moved to, writing the error response otherwise
+func getReassignTarget(w http.ResponseWriter, idStr string, source *models.PantryCategory) (*models.PantryCategory, bool) {
+	target, err := validateCategoryID(idStr, *source.GroupID)
+	if err != nil {
+		http.Error(w, "Target "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if target.ID == source.ID {
+		http.Error(w, "Items cannot be moved to the same category", http.StatusBadRequest)
+		return nil, false
+	}
+	return target, true
+}
+
+// reassignCategoryItems moves the group's pantry and shopping items from one category to another and
+// drops the old category from the group's aisle order. It returns the number of pantry items moved.
+func reassignCategoryItems(groupID, fromID primitive.ObjectID, to *models.PantryCategory) (int64, error) {
+	ctx := context.Background()
+
+	result, err := config.DB.Collection("pantry_items").UpdateMany(
+		ctx,
+		bson.M{"group_id": groupID, "category_id": fromID},
+		bson.M{"$set": bson.M{"category_id": to.ID, "category": to.Name}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, collection := range []string{"shopping_cart", "shopping_cart_archive"} {
+		_, err := config.DB.Collection(collection).UpdateMany(
+			ctx,
+			bson.M{"group_id": groupID, "category_id": fromID},
+			bson.M{"$set": bson.M{"category_id": to.ID, "category": to.Name}},
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = config.DB.Collection("groups").UpdateOne(
+		ctx,
+		bson.M{"_id": groupID},
+		bson.M{"$pull": bson.M{"settings.aisle_order": fromID}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// PantryCategoryActionHandler handles POST /api/pantry/categories/{id}/{merge|deactivate|activate}
+func PantryCategoryActionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/pantry/categories/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	category, ok := getEditableCategory(w, parts[0], user)
+	if !ok {
+		return
+	}
+
+	switch parts[1] {
+	case "merge":
+		mergePantryCategory(w, r, category)
+	case "deactivate":
+		setPantryCategoryActive(w, r, category, false)
+	case "activate":
+		setPantryCategoryActive(w, r, category, true)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// mergePantryCategory moves a custom category's items into the target category and deletes it
+func mergePantryCategory(w http.ResponseWriter, r *http.Request, category *models.PantryCategory) {
+	var request MergeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	target, ok := getReassignTarget(w, request.TargetID, category)
+	if !ok {
+		return
+	}
+
+	moved, err := reassignCategoryItems(*category.GroupID, category.ID, target)
+	if err != nil {
+		log.Printf("Failed to reassign items of category %s: %v", category.ID.Hex(), err)
+		http.Error(w, "Failed to move category items", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := config.DB.Collection("pantry_categories").DeleteOne(context.Background(), bson.M{"_id": category.ID}); err != nil {
+		log.Printf("Failed to delete merged category: %v", err)
+		http.Error(w, "Failed to delete merged category", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryResponse{
+		Status:  "success",
+		Message: "Category merged into " + target.Name,
+		Data: map[string]interface{}{
+			"category":    target,
+			"items_moved": moved,
+		},
+	})
+}
+
+// setPantryCategoryActive hides a custom category from the category list or brings it back.
+// Deactivating with ?reassign_to={category_id} also moves its items to that category.
+func setPantryCategoryActive(w http.ResponseWriter, r *http.Request, category *models.PantryCategory, active bool) {
+	var moved int64
+	if reassignTo := r.URL.Query().Get("reassign_to"); reassignTo != "" && !active {
+		target, ok := getReassignTarget(w, reassignTo, category)
+		if !ok {
+			return
+		}
+
+		var err error
+		moved, err = reassignCategoryItems(*category.GroupID, category.ID, target)
+		if err != nil {
+			log.Printf("Failed to reassign items of category %s: %v", category.ID.Hex(), err)
+			http.Error(w, "Failed to move category items", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	_, err := config.DB.Collection("pantry_categories").UpdateOne(
+		context.Background(),
+		bson.M{"_id": category.ID},
+		bson.M{"$set": bson.M{"is_active": active}},
+	)
+	if err != nil {
+		log.Printf("Failed to update category status: %v", err)
+		http.Error(w, "Failed to update category", http.StatusInternalServerError)
+		return
+	}
+	category.IsActive = active
+
+	message := "Category deactivated successfully"
+	if active {
+		message = "Category activated successfully"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryResponse{
+		Status:  "success",
+		Message: message,
+		Data: map[string]interface{}{
+			"category":    category,
+			"items_moved": moved,
+		},
+	})
+}
+
+// ReorderPantryCategoriesHandler sets the display order of the group's custom categories
+func ReorderPantryCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ReorderCategoriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	order := make([]primitive.ObjectID, 0, len(request.CategoryIDs))
+	for _, idStr := range request.CategoryIDs {
+		id, err := primitive.ObjectIDFromHex(idStr)
+		if err != nil {
+			http.Error(w, "Invalid category ID format: "+idStr, http.StatusBadRequest)
+			return
+		}
+		order = append(order, id)
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("pantry_categories").Find(
+		context.Background(),
+		bson.M{"type": models.CategoryTypeCustom, "group_id": user.GroupID},
+	)
+	if err != nil {
+		log.Printf("Failed to fetch custom categories: %v", err)
+		http.Error(w, "Failed to fetch categories", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var categories []models.PantryCategory
+	if err := cursor.All(context.Background(), &categories); err != nil {
+		log.Printf("Failed to decode custom categories: %v", err)
+		http.Error(w, "Failed to decode categories", http.StatusInternalServerError)
+		return
+	}
+
+	positions := models.OrderCategories(categories, order)
+	writes := make([]mongo.WriteModel, 0, len(positions))
+	for i := range categories {
+		categories[i].SortOrder = positions[categories[i].ID]
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": categories[i].ID}).
+			SetUpdate(bson.M{"$set": bson.M{"sort_order": categories[i].SortOrder}}))
+	}
+
+	if len(writes) > 0 {
+		if _, err := config.DB.Collection("pantry_categories").BulkWrite(context.Background(), writes); err != nil {
+			log.Printf("Failed to reorder categories: %v", err)
+			http.Error(w, "Failed to reorder categories", http.StatusInternalServerError)
+			return
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].SortOrder < categories[j].SortOrder })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryResponse{
+		Status:  "success",
+		Message: "Categories reordered successfully",
+		Data:    categories,
+	})
+}
@@ -0,0 +1,92 @@
+// handlers/pantry_expiration.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetExpiringPantryItemsHandler lists the group's pantry items expiring within the next days, soonest first.
+// Query: ?days= (defaults to the group's expiration alert window)&include_expired=true
+func GetExpiringPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	days := group.Settings.ExpirationAlertWindow()
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > models.MaxExpirationAlertDays {
+			http.Error(w, fmt.Sprintf("Days must be between 1 and %d", models.MaxExpirationAlertDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	now := time.Now()
+	window := bson.M{"$lte": now.AddDate(0, 0, days)}
+	if r.URL.Query().Get("include_expired") != "true" {
+		window["$gte"] = now
+	} else {
+		// Items without an expiration date are stored with the zero time
+		window["$gt"] = time.Time{}
+	}
+
+	cursor, err := config.DB.Collection("pantry_items").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "expiration_date": window},
+		options.Find().SetSort(bson.D{{Key: "expiration_date", Value: 1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch expiring pantry items: %v", err)
+		http.Error(w, "Failed to fetch expiring items", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var items []models.PantryItem
+	if err := cursor.All(context.Background(), &items); err != nil {
+		log.Printf("Failed to decode expiring pantry items: %v", err)
+		http.Error(w, "Failed to decode expiring items", http.StatusInternalServerError)
+		return
+	}
+
+	type ExpiringPantryItem struct {
+		models.PantryItem
+		DaysLeft  int  `json:"days_left"`
+		IsExpired bool `json:"is_expired"`
+	}
+
+	response := make([]ExpiringPantryItem, 0, len(items))
+	for _, item := range items {
+		daysLeft, _ := item.DaysUntilExpiration(now)
+		response = append(response, ExpiringPantryItem{PantryItem: item, DaysLeft: daysLeft, IsExpired: item.IsExpired()})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":  days,
+		"items": response,
+	})
+}
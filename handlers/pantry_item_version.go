@@ -0,0 +1,205 @@
+// handlers/pantry_item_version.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RevertPantryItemRequest defines the request structure for restoring a pantry item to an earlier version
+type RevertPantryItemRequest struct {
+	Version int `json:"version"`
+}
+
+// recordPantryItemVersion saves an item's state after a change as its next version. before is nil
+// for new items; edits that leave every tracked field alone are not recorded.
+func recordPantryItemVersion(ctx context.Context, before, after *models.PantryItem, action models.ActionType, user models.User) error {
+	var changes []models.PantryItemChange
+	if before != nil {
+		changes = models.DiffPantryItems(before, after)
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+
+	collection := config.DB.Collection("pantry_item_versions")
+	var latest models.PantryItemVersion
+	err := collection.FindOne(
+		ctx,
+		bson.M{"item_id": after.ID},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+	).Decode(&latest)
+	if errors.Is(err, mongo.ErrNoDocuments) && before != nil {
+		// Keep the state from before versions were recorded so this edit can be undone
+		baseline := models.CreatePantryItemVersion(before, 1, nil, models.ActionTypeAdd, before.AddedBy, "")
+		baseline.CreatedAt = before.UpdatedAt
+		if _, err := collection.InsertOne(ctx, baseline); err != nil {
+			return err
+		}
+		latest.Version = baseline.Version
+	} else if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	version := models.CreatePantryItemVersion(after, latest.Version+1, changes, action, user.ID, user.Name)
+	_, err = collection.InsertOne(ctx, version)
+	return err
+}
+
+// PantryItemVersionsHandler handles the change history of a pantry item:
+// GET /api/pantry/versions/{item_id} lists its versions, newest first, and
+// POST /api/pantry/versions/{item_id}/revert restores it to the version in the body
+func PantryItemVersionsHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/pantry/versions/"), "/")
+	itemID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		getPantryItemVersions(w, r, itemID)
+	case len(parts) == 2 && parts[1] == "revert" && r.Method == http.MethodPost:
+		revertPantryItem(w, r, itemID)
+	case len(parts) <= 2:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// getPantryItemVersions lists an item's versions, newest first
+func getPantryItemVersions(w http.ResponseWriter, r *http.Request, itemID primitive.ObjectID) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("pantry_item_versions").Find(
+		context.Background(),
+		bson.M{"item_id": itemID, "group_id": user.GroupID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch pantry item versions: %v", err)
+		http.Error(w, "Failed to fetch pantry item history", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	versions := make([]models.PantryItemVersion, 0)
+	if err := cursor.All(context.Background(), &versions); err != nil {
+		log.Printf("Failed to decode pantry item versions: %v", err)
+		http.Error(w, "Failed to decode pantry item history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item_id":  itemID,
+		"versions": versions,
+	})
+}
+
+// revertPantryItem restores an item's edited fields to an earlier version, recording the revert
+// as a new version so it can be undone in turn
+func revertPantryItem(w http.ResponseWriter, r *http.Request, itemID primitive.ObjectID) {
+	var request RevertPantryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Version < 1 {
+		http.Error(w, "Version must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	var pantryItem models.PantryItem
+	err = mongo.WithSession(context.Background(), session, func(sc mongo.SessionContext) error {
+		err := config.DB.Collection("pantry_items").FindOne(
+			sc,
+			bson.M{"_id": itemID, "group_id": user.GroupID},
+		).Decode(&pantryItem)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return errors.New("pantry item not found")
+			}
+			return err
+		}
+		if !pantryItem.CanBeModifiedBy(user.ID) {
+			return errors.New("only the owner can revert a personal pantry item")
+		}
+
+		var version models.PantryItemVersion
+		err = config.DB.Collection("pantry_item_versions").FindOne(
+			sc,
+			bson.M{"item_id": itemID, "version": request.Version},
+		).Decode(&version)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return fmt.Errorf("version %d not found", request.Version)
+			}
+			return err
+		}
+
+		if version.Item.IsPersonal() && !version.Item.IsOwnedBy(user.ID) {
+			return errors.New("only the owner can make this item personal again")
+		}
+		if _, err := validateCategoryID(version.Item.CategoryID.Hex(), user.GroupID); err != nil {
+			return fmt.Errorf("cannot revert: %v", err)
+		}
+
+		before := pantryItem
+		version.RestoreTo(&pantryItem)
+
+		_, err = config.DB.Collection("pantry_items").UpdateOne(
+			sc,
+			bson.M{"_id": pantryItem.ID},
+			bson.M{"$set": pantryItem},
+		)
+		if err != nil {
+			return err
+		}
+
+		return recordPantryItemVersion(sc, &before, &pantryItem, models.ActionTypeRevert, user)
+	})
+
+	if err != nil {
+		log.Printf("Transaction failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Item reverted to version %d", request.Version),
+		"item":    pantryItem,
+	})
+}
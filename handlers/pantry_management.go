@@ -303,6 +303,11 @@ func GetPantryExpiringHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// MarkNotificationReadRequest defines the request structure for marking a pantry notification read
+type MarkNotificationReadRequest struct {
+	NotificationID string `json:"notification_id"`
+}
+
 // MarkNotificationReadHandler marks a notification as read by the current user
 func MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -317,9 +322,7 @@ func MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		NotificationID string `json:"notification_id"`
-	}
+	var request MarkNotificationReadRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -778,3 +781,26 @@ func UpdatePantryHistoryForRemove(groupID, itemID primitive.ObjectID, itemName s
 		log.Printf("Failed to create pantry history record: %v", err)
 	}
 }
+
+// UpdatePantryHistoryForDiscard creates a history record for throwing an item away
+func UpdatePantryHistoryForDiscard(groupID, itemID primitive.ObjectID, itemName string, userID primitive.ObjectID, userName string, quantity float64, reason models.WasteReason) {
+	history := models.CreatePantryHistory(
+		groupID,
+		itemID,
+		itemName,
+		userID,
+		userName,
+		models.ActionTypeDiscard,
+		quantity,
+		"Item discarded from pantry ("+string(reason)+")",
+	)
+
+	_, err := config.DB.Collection("pantry_history").InsertOne(
+		context.Background(),
+		history,
+	)
+
+	if err != nil {
+		log.Printf("Failed to create pantry history record: %v", err)
+	}
+}
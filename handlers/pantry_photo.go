@@ -0,0 +1,187 @@
+// handlers/pantry_photo.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// pantryPhotoPath serves pantry item photos: /api/pantry/photo/{item_id}
+const pantryPhotoPath = "/api/pantry/photo/"
+
+// pantryPhotoURL returns where an item's photo can be fetched, or "" when it has none
+func pantryPhotoURL(item models.PantryItem) string {
+	if item.PhotoID == nil {
+		return ""
+	}
+	return pantryPhotoPath + item.ID.Hex()
+}
+
+// PantryItemPhotoHandler manages the photo of a pantry item in the user's group.
+// GET returns the image, PUT uploads a replacement as the multipart "photo" field, DELETE removes it.
+func PantryItemPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	itemID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, pantryPhotoPath))
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var item models.PantryItem
+	err = config.DB.Collection("pantry_items").FindOne(
+		context.Background(),
+		bson.M{"_id": itemID, "group_id": user.GroupID},
+	).Decode(&item)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Pantry item not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch pantry item", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if r.Method != http.MethodGet && !item.CanBeModifiedBy(user.ID) {
+		http.Error(w, "Only the owner can change the photo of a personal pantry item", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		servePantryPhoto(w, item)
+	case http.MethodPut, http.MethodPost:
+		uploadPantryPhoto(w, r, item)
+	case http.MethodDelete:
+		deletePantryPhoto(w, item)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// servePantryPhoto streams an item's photo
+func servePantryPhoto(w http.ResponseWriter, item models.PantryItem) {
+	if item.PhotoID == nil {
+		http.Error(w, "Pantry item has no photo", http.StatusNotFound)
+		return
+	}
+
+	photo, contentType, err := storage.Open(context.Background(), *item.PhotoID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Pantry item has no photo", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open photo of pantry item %s: %v", item.ID.Hex(), err)
+			http.Error(w, "Failed to fetch photo", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer photo.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if _, err := io.Copy(w, photo); err != nil {
+		log.Printf("Failed to send photo of pantry item %s: %v", item.ID.Hex(), err)
+	}
+}
+
+// uploadPantryPhoto stores a new photo for an item, replacing the previous one
+func uploadPantryPhoto(w http.ResponseWriter, r *http.Request, item models.PantryItem) {
+	// Leave room for the multipart headers around the image
+	r.Body = http.MaxBytesReader(w, r.Body, storage.MaxImageSize+64<<10)
+	file, header, err := r.FormFile("photo")
+	if err != nil {
+		http.Error(w, "A photo file under 5 MB is required in the \"photo\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read photo", http.StatusBadRequest)
+		return
+	}
+
+	photoID, err := storage.SaveImage(header.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrImageTooLarge):
+			http.Error(w, "Photo must be 5 MB or smaller", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrUnsupportedImage):
+			http.Error(w, "Photo must be a JPEG, PNG, GIF or WebP image", http.StatusUnsupportedMediaType)
+		default:
+			log.Printf("Failed to store photo of pantry item %s: %v", item.ID.Hex(), err)
+			http.Error(w, "Failed to store photo", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	_, err = config.DB.Collection("pantry_items").UpdateOne(
+		context.Background(),
+		bson.M{"_id": item.ID},
+		bson.M{"$set": bson.M{"photo_id": photoID}},
+	)
+	if err != nil {
+		log.Printf("Failed to attach photo to pantry item %s: %v", item.ID.Hex(), err)
+		storage.Delete(context.Background(), photoID)
+		http.Error(w, "Failed to attach photo", http.StatusInternalServerError)
+		return
+	}
+
+	if item.PhotoID != nil {
+		removePantryPhoto(*item.PhotoID)
+	}
+	item.PhotoID = &photoID
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"item":      item,
+		"photo_url": pantryPhotoURL(item),
+	})
+}
+
+// deletePantryPhoto detaches and removes an item's photo
+func deletePantryPhoto(w http.ResponseWriter, item models.PantryItem) {
+	if item.PhotoID == nil {
+		http.Error(w, "Pantry item has no photo", http.StatusNotFound)
+		return
+	}
+
+	_, err := config.DB.Collection("pantry_items").UpdateOne(
+		context.Background(),
+		bson.M{"_id": item.ID},
+		bson.M{"$unset": bson.M{"photo_id": ""}},
+	)
+	if err != nil {
+		log.Printf("Failed to detach photo from pantry item %s: %v", item.ID.Hex(), err)
+		http.Error(w, "Failed to delete photo", http.StatusInternalServerError)
+		return
+	}
+	removePantryPhoto(*item.PhotoID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removePantryPhoto deletes a stored photo in the background once nothing refers to it
+func removePantryPhoto(photoID primitive.ObjectID) {
+	go func() {
+		if err := storage.Delete(context.Background(), photoID); err != nil {
+			log.Printf("Failed to delete pantry photo %s: %v", photoID.Hex(), err)
+		}
+	}()
+}
@@ -0,0 +1,228 @@
+// handlers/pantry_restock.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultPantryUnit is used for pantry items created from cart items bought as individual items
+const defaultPantryUnit = "pcs"
+
+// findPantryCategoryByName resolves a shopping category name to one of the group's pantry
+// categories, falling back to the predefined "Other" category
+func findPantryCategoryByName(name string, groupID primitive.ObjectID) (*models.PantryCategory, error) {
+	accessible := []bson.M{
+		{"type": models.CategoryTypePredefined},
+		{"type": models.CategoryTypeCustom, "group_id": groupID},
+	}
+
+	for _, candidate := range []string{strings.TrimSpace(name), "Other"} {
+		if candidate == "" {
+			continue
+		}
+
+		var category models.PantryCategory
+		err := config.DB.Collection("pantry_categories").FindOne(
+			context.Background(),
+			bson.M{
+				"name":      bson.M{"$regex": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(candidate) + "$", Options: "i"}},
+				"is_active": true,
+				"$or":       accessible,
+			},
+		).Decode(&category)
+		if err == nil {
+			return &category, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, err
+		}
+	}
+
+	return nil, errors.New("no pantry category found for " + name)
+}
+
+// restockPantry adds purchased items to the group's pantry. Failures are logged and skipped so
+// they never undo the purchase itself.
+func restockPantry(user models.User, purchases []*models.PurchaseRecord) []models.PantryItem {
+	restocked := make([]models.PantryItem, 0, len(purchases))
+
+	for _, purchase := range purchases {
+		// Personal purchases stock the requester's own shelf
+		var owner *primitive.ObjectID
+		if !purchase.IsShared {
+			owner = &purchase.RequestedBy
+		}
+
+		pantryItem, err := restockPantryItem(user, purchase.ItemName, purchase.Quantity, purchase.Unit, purchase.Category, purchase.CategoryID, owner)
+		if err != nil {
+			log.Printf("Failed to add %s to the pantry: %v", purchase.ItemName, err)
+			continue
+		}
+		restocked = append(restocked, *pantryItem)
+	}
+
+	return restocked
+}
+
+// restockPantryItem adds quantity of an item to the group's pantry. It increments the pantry item
+// with the same name, category and owner when their units are compatible; otherwise a new pantry
+// item is created. The category is resolved from categoryID when set and from the category name
+// otherwise. A nil owner stocks the shared pantry.
+func restockPantryItem(user models.User, name string, quantity float64, unit, categoryName string, categoryID *primitive.ObjectID, owner *primitive.ObjectID) (*models.PantryItem, error) {
+	var category *models.PantryCategory
+	var err error
+	if categoryID != nil {
+		category, err = validateCategoryID(categoryID.Hex(), user.GroupID)
+	}
+	if category == nil {
+		category, err = findPantryCategoryByName(categoryName, user.GroupID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	cursor, err := config.DB.Collection("pantry_items").Find(
+		context.Background(),
+		bson.M{
+			"group_id":    user.GroupID,
+			"name":        bson.M{"$regex": primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name) + "$", Options: "i"}},
+			"category_id": category.ID,
+			"owner_id":    owner,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.PantryItem
+	err = cursor.All(context.Background(), &matches)
+	cursor.Close(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var pantryItem, before *models.PantryItem
+	for i := range matches {
+		previous := matches[i]
+		if matches[i].Restock(quantity, unit) {
+			pantryItem, before = &matches[i], &previous
+			break
+		}
+	}
+
+	if pantryItem != nil {
+		_, err = config.DB.Collection("pantry_items").UpdateOne(
+			context.Background(),
+			bson.M{"_id": pantryItem.ID},
+			bson.M{"$set": bson.M{"quantity": pantryItem.Quantity, "updated_at": pantryItem.UpdatedAt}},
+		)
+	} else {
+		if unit == "" {
+			unit = defaultPantryUnit
+		}
+		pantryItem = models.CreatePantryItem(user.GroupID, name, quantity, unit, category.ID, time.Time{}, user.ID)
+		pantryItem.SetOwner(owner)
+
+		var result *mongo.InsertOneResult
+		result, err = config.DB.Collection("pantry_items").InsertOne(context.Background(), pantryItem)
+		if err == nil {
+			pantryItem.ID = result.InsertedID.(primitive.ObjectID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordPantryItemVersion(context.Background(), before, pantryItem, models.ActionTypeAdd, user); err != nil {
+		log.Printf("Failed to record version of pantry item %s: %v", pantryItem.ID.Hex(), err)
+	}
+	UpdatePantryHistoryForAdd(user.GroupID, pantryItem.ID, pantryItem.Name, user.ID, user.Name, quantity)
+	return pantryItem, nil
+}
+
+// addLowStockItemToShoppingList puts a pantry item that dropped below its minimum quantity on the
+// group's default shopping list and tells the group, or just the owner of a personal item. Nothing
+// happens when the item is already on the list, so repeated consumption never piles up quantities.
+func addLowStockItemToShoppingList(user models.User, pantryItem models.PantryItem) {
+	ctx := context.Background()
+	item := models.CreateShoppingCartItem(user.ID, pantryItem.GroupID, pantryItem.Name, pantryItem.RestockQuantity(), pantryItem.Unit, "")
+	item.IsShared = !pantryItem.IsPersonal()
+
+	count, err := config.DB.Collection("shopping_cart").CountDocuments(ctx, item.DuplicateFilter())
+	if err != nil {
+		log.Printf("Failed to check shopping list for %s: %v", pantryItem.Name, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+
+	if category, err := validateCategoryID(pantryItem.CategoryID.Hex(), pantryItem.GroupID); err == nil {
+		item.CategoryID = &category.ID
+		item.Category = category.Name
+	}
+
+	result, err := config.DB.Collection("shopping_cart").InsertOne(ctx, item)
+	if err != nil {
+		// A member added the item at the same moment
+		if !mongo.IsDuplicateKeyError(err) {
+			log.Printf("Failed to add low-stock item %s to the shopping list: %v", pantryItem.Name, err)
+		}
+		return
+	}
+	item.ID = result.InsertedID.(primitive.ObjectID)
+
+	activity := models.CreateShoppingCartActivity(
+		item.GroupID,
+		item.ID,
+		item.ItemName,
+		user.ID,
+		user.Name,
+		models.CartActivityTypeAdd,
+		item.Quantity,
+		"Added automatically because pantry stock is low",
+	)
+	if _, err := config.DB.Collection("shopping_cart_activity").InsertOne(ctx, activity); err != nil {
+		log.Printf("Failed to create shopping cart activity record: %v", err)
+	}
+	webhooks.ItemAdded(ctx, *item, item.Quantity, user)
+
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": pantryItem.GroupID}).Decode(&group); err != nil {
+		log.Printf("Failed to load group for low-stock notification: %v", err)
+		return
+	}
+
+	minimum := strings.TrimSpace(strconv.FormatFloat(pantryItem.MinQuantity, 'f', -1, 64) + " " + pantryItem.Unit)
+	params := models.NotificationParams{"item": pantryItem.Name, "minimum": minimum}
+	recipients := group.Members
+	if pantryItem.IsPersonal() {
+		recipients = []primitive.ObjectID{*pantryItem.OwnerID}
+	}
+	for _, memberID := range recipients {
+		notification := models.CreateTemplatedNotification(
+			memberID,
+			group.ID,
+			models.NotificationTypeLowStock,
+			models.TemplateLowStock,
+			params,
+		)
+		if _, err := config.DB.Collection("notifications").InsertOne(ctx, notification); err != nil {
+			log.Printf("Failed to create low-stock notification for user %s: %v", memberID.Hex(), err)
+		}
+	}
+}
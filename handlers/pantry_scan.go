@@ -0,0 +1,144 @@
+// handlers/pantry_scan.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/products"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// maxScanPackages caps how many packages a single scan can add
+const maxScanPackages = 100
+
+// ScanPantryItemRequest defines the request structure for restocking the pantry from a barcode
+type ScanPantryItemRequest struct {
+	Barcode        string  `json:"barcode"`
+	Packages       int     `json:"packages,omitempty"`        // Defaults to one package
+	ExpirationDate *string `json:"expiration_date,omitempty"` // RFC3339
+	Preview        bool    `json:"preview,omitempty"`         // Resolve the product without changing the pantry
+}
+
+// ScanPantryItemHandler resolves a scanned barcode to a product and adds its packages to the
+// group's pantry, merging them into a matching item when one exists
+func ScanPantryItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ScanPantryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Barcode = strings.TrimSpace(request.Barcode)
+	if !models.ValidBarcode(request.Barcode) {
+		http.Error(w, "Invalid barcode", http.StatusBadRequest)
+		return
+	}
+
+	if request.Packages == 0 {
+		request.Packages = 1
+	}
+	if request.Packages < 1 || request.Packages > maxScanPackages {
+		http.Error(w, fmt.Sprintf("Packages must be between 1 and %d", maxScanPackages), http.StatusBadRequest)
+		return
+	}
+
+	var expirationDate time.Time
+	if request.ExpirationDate != nil && *request.ExpirationDate != "" {
+		var err error
+		expirationDate, err = time.Parse(time.RFC3339, *request.ExpirationDate)
+		if err != nil {
+			http.Error(w, "Invalid expiration date format. Use ISO 8601/RFC3339 format (YYYY-MM-DDTHH:MM:SSZ)", http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := products.Resolve(context.Background(), request.Barcode)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			http.Error(w, "No product found for this barcode", http.StatusNotFound)
+		} else {
+			log.Printf("Barcode lookup failed for %s: %v", request.Barcode, err)
+			http.Error(w, "Failed to look up barcode", http.StatusBadGateway)
+		}
+		return
+	}
+	if strings.TrimSpace(product.Name) == "" {
+		http.Error(w, "The product database has no name for this barcode", http.StatusUnprocessableEntity)
+		return
+	}
+
+	quantity, unit := product.PackageAmount(request.Packages)
+
+	if request.Preview {
+		item := AddPantryItemRequest{
+			Name:     product.Name,
+			Quantity: quantity,
+			Unit:     unit,
+		}
+		if item.Unit == "" {
+			item.Unit = defaultPantryUnit
+		}
+		if category, err := findPantryCategoryByName(product.Category, user.GroupID); err == nil {
+			item.CategoryID = category.ID.Hex()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(CategoryResponse{
+			Status:  "success",
+			Message: "Product found",
+			Data: map[string]interface{}{
+				"product": product,
+				"item":    item,
+			},
+		})
+		return
+	}
+
+	pantryItem, err := restockPantryItem(user, product.Name, quantity, unit, product.Category, nil, nil)
+	if err != nil {
+		log.Printf("Failed to add scanned product %s to the pantry: %v", request.Barcode, err)
+		http.Error(w, "Failed to add item to the pantry", http.StatusInternalServerError)
+		return
+	}
+
+	if !expirationDate.IsZero() {
+		pantryItem.ExpirationDate = expirationDate
+		_, err = config.DB.Collection("pantry_items").UpdateOne(
+			context.Background(),
+			bson.M{"_id": pantryItem.ID},
+			bson.M{"$set": bson.M{"expiration_date": expirationDate}},
+		)
+		if err != nil {
+			log.Printf("Failed to set expiration date for scanned item: %v", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CategoryResponse{
+		Status:  "success",
+		Message: "Item added to the pantry",
+		Data: map[string]interface{}{
+			"product": product,
+			"item":    pantryItem,
+		},
+	})
+}
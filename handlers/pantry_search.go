@@ -0,0 +1,130 @@
+// handlers/pantry_search.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultPantrySearchLimit and maxPantrySearchLimit bound how many results a search returns
+const (
+	defaultPantrySearchLimit = 20
+	maxPantrySearchLimit     = 100
+)
+
+// SearchPantryItemsHandler finds the group's pantry items by name or notes, tolerating typos.
+// Query: ?q=&limit= (defaults to 20)
+func SearchPantryItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Search query is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultPantrySearchLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > maxPantrySearchLimit {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", maxPantrySearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var results []models.PantrySearchResult
+	var err error
+	if config.PantrySearchIndex != "" {
+		results, err = atlasSearchPantry(user.GroupID, query, limit)
+	} else {
+		results, err = matchPantryItems(user.GroupID, query, limit)
+	}
+	if err != nil {
+		log.Printf("Pantry search failed: %v", err)
+		http.Error(w, "Failed to search pantry", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":   query,
+		"results": results,
+	})
+}
+
+// matchPantryItems ranks the group's pantry items in the application; a group's pantry is small
+// enough to score in full
+func matchPantryItems(groupID primitive.ObjectID, query string, limit int) ([]models.PantrySearchResult, error) {
+	cursor, err := config.DB.Collection("pantry_items").Find(context.Background(), bson.M{"group_id": groupID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var items []models.PantryItem
+	if err := cursor.All(context.Background(), &items); err != nil {
+		return nil, err
+	}
+	return models.SearchPantryItems(items, query, limit), nil
+}
+
+// atlasSearchPantry runs a fuzzy Atlas Search query over item names and notes. The index must
+// map group_id as an objectId so results can be limited to the group.
+func atlasSearchPantry(groupID primitive.ObjectID, query string, limit int) ([]models.PantrySearchResult, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$search", Value: bson.M{
+			"index": config.PantrySearchIndex,
+			"compound": bson.M{
+				"filter": bson.A{bson.M{"equals": bson.M{"path": "group_id", "value": groupID}}},
+				"should": bson.A{
+					bson.M{"text": bson.M{
+						"query": query,
+						"path":  "name",
+						"fuzzy": bson.M{"maxEdits": 2, "prefixLength": 1},
+					}},
+					bson.M{"text": bson.M{
+						"query": query,
+						"path":  "notes",
+						"fuzzy": bson.M{"maxEdits": 2, "prefixLength": 1},
+						"score": bson.M{"boost": bson.M{"value": 0.6}},
+					}},
+				},
+				"minimumShouldMatch": 1,
+			},
+		}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "searchScore"}}}},
+	}
+
+	cursor, err := config.DB.Collection("pantry_items").Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	results := make([]models.PantrySearchResult, 0)
+	if err := cursor.All(context.Background(), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,91 @@
+// handlers/pantry_usage.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetPantryUsageHandler reports how the group's pantry items were used, newest first, with totals
+// per item and member and the last member to finish an item.
+// Query: ?item_id=&user_id=&days= (defaults to 30)
+func GetPantryUsageHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	days := models.DefaultUsageReportDays
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed < 1 || parsed > models.MaxUsageReportDays {
+			http.Error(w, fmt.Sprintf("Days must be between 1 and %d", models.MaxUsageReportDays), http.StatusBadRequest)
+			return
+		}
+		days = parsed
+	}
+
+	filter := bson.M{
+		"group_id": user.GroupID,
+		"used_at":  bson.M{"$gte": time.Now().AddDate(0, 0, -days)},
+	}
+	if itemIDStr := r.URL.Query().Get("item_id"); itemIDStr != "" {
+		itemID, err := primitive.ObjectIDFromHex(itemIDStr)
+		if err != nil {
+			http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+			return
+		}
+		filter["item_id"] = itemID
+	}
+	if userIDStr := r.URL.Query().Get("user_id"); userIDStr != "" {
+		userID, err := primitive.ObjectIDFromHex(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+			return
+		}
+		filter["user_id"] = userID
+	}
+
+	cursor, err := config.DB.Collection("pantry_usage").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "used_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch pantry usage: %v", err)
+		http.Error(w, "Failed to fetch pantry usage", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	usage := make([]models.PantryUsage, 0)
+	if err := cursor.All(context.Background(), &usage); err != nil {
+		log.Printf("Failed to decode pantry usage: %v", err)
+		http.Error(w, "Failed to decode pantry usage", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"days":          days,
+		"usage":         usage,
+		"totals":        models.SummarizePantryUsage(usage),
+		"last_finished": models.LastFinished(usage),
+	})
+}
@@ -0,0 +1,274 @@
+// handlers/pantry_waste.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// wastePriceMonths is how far back purchases are looked up to price discarded food
+const wastePriceMonths = 12
+
+// DiscardPantryItemRequest defines the request structure for throwing away a pantry item
+type DiscardPantryItemRequest struct {
+	ItemID         string   `json:"item_id" validate:"required"`
+	Quantity       float64  `json:"quantity,omitempty"` // Defaults to everything that is left
+	Unit           string   `json:"unit,omitempty"`     // Defaults to the item's unit
+	Reason         string   `json:"reason" validate:"required"`
+	Notes          string   `json:"notes,omitempty"`
+	EstimatedValue *float64 `json:"estimated_value,omitempty"` // Estimated from the group's purchases when omitted
+}
+
+// DiscardPantryItemHandler throws away some or all of a pantry item and records it in the
+// group's waste log with its reason and estimated value
+func DiscardPantryItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request DiscardPantryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(request.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+	reason := models.WasteReason(request.Reason)
+	if !models.IsValidWasteReason(reason) {
+		http.Error(w, "Reason must be \"expired\", \"spoiled\" or \"other\"", http.StatusBadRequest)
+		return
+	}
+	if request.Quantity < 0 {
+		http.Error(w, "Quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if request.EstimatedValue != nil && *request.EstimatedValue < 0 {
+		http.Error(w, "Estimated value cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	var waste *models.PantryWaste
+	var pantryItem models.PantryItem
+	var restockItem *models.PantryItem
+
+	err = mongo.WithSession(context.Background(), session, func(sc mongo.SessionContext) error {
+		restockItem = nil
+
+		err := config.DB.Collection("pantry_items").FindOne(
+			sc,
+			bson.M{"_id": itemID},
+		).Decode(&pantryItem)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return errors.New("pantry item not found")
+			}
+			return err
+		}
+
+		if pantryItem.GroupID != user.GroupID {
+			return errors.New("pantry item does not belong to user's group")
+		}
+		if !pantryItem.CanBeModifiedBy(user.ID) {
+			return errors.New("only the owner can discard a personal pantry item")
+		}
+		before := pantryItem
+
+		discarded := pantryItem.Quantity
+		if request.Quantity > 0 {
+			var ok bool
+			discarded, ok = pantryItem.InItemUnit(request.Quantity, request.Unit)
+			if !ok {
+				return fmt.Errorf("cannot discard %s of an item tracked in %s", request.Unit, pantryItem.Unit)
+			}
+		}
+		if discarded <= 0 {
+			return errors.New("there is nothing left to discard")
+		}
+		if discarded > pantryItem.Quantity {
+			return errors.New("not enough quantity available")
+		}
+
+		value := 0.0
+		if request.EstimatedValue != nil {
+			value = *request.EstimatedValue
+		} else {
+			purchases, err := findItemPurchases(sc, user.GroupID, models.NormalizeItemName(pantryItem.Name), wastePriceMonths)
+			if err != nil {
+				return err
+			}
+			value, _ = models.SummarizePrices(purchases).EstimateValue(discarded, pantryItem.Unit)
+		}
+
+		wasBelowMinimum := pantryItem.IsBelowMinimum()
+		pantryItem.UpdateQuantity(pantryItem.Quantity - discarded)
+		if pantryItem.IsBelowMinimum() && !wasBelowMinimum {
+			restockItem = &pantryItem
+		}
+
+		waste = models.CreatePantryWaste(&pantryItem, user.ID, user.Name, discarded, reason, value)
+		waste.Notes = strings.TrimSpace(request.Notes)
+		if _, err := config.DB.Collection("pantry_waste").InsertOne(sc, waste); err != nil {
+			return err
+		}
+
+		_, err = config.DB.Collection("pantry_items").UpdateOne(
+			sc,
+			bson.M{"_id": pantryItem.ID},
+			bson.M{"$set": bson.M{
+				"quantity":   pantryItem.Quantity,
+				"updated_at": pantryItem.UpdatedAt,
+			}},
+		)
+		if err != nil {
+			return err
+		}
+		if err := recordPantryItemVersion(sc, &before, &pantryItem, models.ActionTypeDiscard, user); err != nil {
+			return err
+		}
+
+		// Expiration warnings no longer apply once the food is gone
+		if pantryItem.Quantity == 0 {
+			_, err = config.DB.Collection("pantry_notifications").DeleteMany(
+				sc,
+				bson.M{
+					"item_id": pantryItem.ID,
+					"type": bson.M{"$in": []models.NotificationType{
+						models.NotificationTypeExpiringSoon,
+						models.NotificationTypeExpired,
+					}},
+				},
+			)
+			if err != nil {
+				log.Printf("Failed to delete expiration notifications: %v", err)
+				// Continue anyway, as this is not critical
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Transaction failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	UpdatePantryHistoryForDiscard(
+		pantryItem.GroupID,
+		pantryItem.ID,
+		pantryItem.Name,
+		user.ID,
+		user.Name,
+		waste.Quantity,
+		reason,
+	)
+	if restockItem != nil {
+		go addLowStockItemToShoppingList(user, *restockItem)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":            true,
+		"message":            "Item discarded successfully",
+		"waste":              waste,
+		"remaining_quantity": pantryItem.Quantity,
+		"unit":               pantryItem.Unit,
+	})
+}
+
+// GetPantryWasteReportHandler summarizes the food the group threw away in a month, with the
+// value wasted the month before for comparison. Query: ?month=YYYY-MM (defaults to this month)
+func GetPantryWasteReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+	start, end, err := models.MonthBounds(month)
+	if err != nil {
+		http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+		return
+	}
+	previousStart := start.AddDate(0, -1, 0)
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("pantry_waste").Find(
+		context.Background(),
+		bson.M{
+			"group_id":     user.GroupID,
+			"discarded_at": bson.M{"$gte": previousStart, "$lt": end},
+		},
+		options.Find().SetSort(bson.D{{Key: "discarded_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch pantry waste: %v", err)
+		http.Error(w, "Failed to fetch pantry waste", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	waste := make([]models.PantryWaste, 0)
+	if err := cursor.All(context.Background(), &waste); err != nil {
+		log.Printf("Failed to decode pantry waste: %v", err)
+		http.Error(w, "Failed to decode pantry waste", http.StatusInternalServerError)
+		return
+	}
+
+	// Entries are newest first, so the month's entries come before the previous month's
+	split := len(waste)
+	for i, entry := range waste {
+		if entry.DiscardedAt.Before(start) {
+			split = i
+			break
+		}
+	}
+	entries := waste[:split]
+
+	report := models.SummarizeWaste(month, entries)
+	report.PreviousTotalValue = models.SummarizeWaste("", waste[split:]).TotalValue
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"report":  report,
+		"entries": entries,
+	})
+}
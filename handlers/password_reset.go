@@ -0,0 +1,187 @@
+// handlers/password_reset.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/email"
+	"cribb-backend/models"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// passwordResetInterval is how long a member waits before another reset email is sent, so the
+// endpoint cannot be used to flood their inbox
+const passwordResetInterval = time.Minute
+
+// ForgotPasswordRequest defines the request structure for asking for a password reset email
+type ForgotPasswordRequest struct {
+	Username string `json:"username"`
+}
+
+// ResetPasswordRequest defines the request structure for choosing a new password
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// ForgotPasswordHandler emails a link to choose a new password to the member with the username.
+// The response is the same whether or not the account exists, so it cannot be used to find out
+// who has one.
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !email.Enabled() {
+		http.Error(w, "Password reset by email is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Username = strings.TrimSpace(request.Username)
+	if request.Username == "" {
+		http.Error(w, "Username is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := sendPasswordReset(r.Context(), request.Username, time.Now()); err != nil {
+		log.Printf("Failed to send password reset: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "If an account with an email address exists for that username, a reset link is on its way",
+	})
+}
+
+// sendPasswordReset stores a new reset for the member and emails them its link. Members without
+// an email address, or who were sent a link moments ago, are skipped.
+func sendPasswordReset(ctx context.Context, username string, now time.Time) error {
+	var user models.User
+	err := config.DB.Collection("users").FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	address, ok := user.EmailAddress()
+	if !ok {
+		return nil
+	}
+
+	recent, err := config.DB.Collection("password_resets").CountDocuments(ctx, bson.M{
+		"user_id":    user.ID,
+		"created_at": bson.M{"$gt": now.Add(-passwordResetInterval)},
+	})
+	if err != nil {
+		return err
+	}
+	if recent > 0 {
+		return nil
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(secret)
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: models.HashResetToken(token),
+		ExpiresAt: now.Add(models.PasswordResetTTL),
+		CreatedAt: now,
+	}
+	if _, err := config.DB.Collection("password_resets").InsertOne(ctx, reset); err != nil {
+		return err
+	}
+
+	message, err := email.PasswordResetEmail(address, email.PasswordResetData{
+		Name:      user.Name,
+		ResetURL:  config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token),
+		ExpiresIn: "1 hour",
+	})
+	if err != nil {
+		return err
+	}
+	return email.Send(ctx, message)
+}
+
+// ResetPasswordHandler sets a new password with the token from a reset email. Every other reset
+// link of the member stops working.
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Token = strings.TrimSpace(request.Token)
+	if request.Token == "" || request.Password == "" {
+		http.Error(w, "Token and password are required", http.StatusBadRequest)
+		return
+	}
+
+	// Claim the reset so the link works once
+	var reset models.PasswordReset
+	err := config.DB.Collection("password_resets").FindOneAndDelete(
+		context.Background(),
+		bson.M{"token_hash": models.HashResetToken(request.Token), "expires_at": bson.M{"$gt": time.Now()}},
+	).Decode(&reset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "The reset link is invalid or has expired", http.StatusBadRequest)
+		} else {
+			http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	result, err := config.DB.Collection("users").UpdateOne(
+		context.Background(),
+		bson.M{"_id": reset.UserID},
+		bson.M{"$set": bson.M{"password": string(hashedPassword), "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to reset password of user %s: %v", reset.UserID.Hex(), err)
+		http.Error(w, "Failed to reset password", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if _, err := config.DB.Collection("password_resets").DeleteMany(context.Background(), bson.M{"user_id": reset.UserID}); err != nil {
+		log.Printf("Failed to remove other password resets of user %s: %v", reset.UserID.Hex(), err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password updated, sign in with the new password"})
+}
@@ -0,0 +1,191 @@
+// handlers/pickups.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreatePickupRequest defines the request structure for adding a trash or recycling pickup
+type CreatePickupRequest struct {
+	Kind         string    `json:"kind"`                    // trash, recycling, compost or yard_waste
+	EveryWeeks   int       `json:"every_weeks,omitempty"`   // 1 (default) or 2
+	FirstPickup  time.Time `json:"first_pickup"`            // The date of the next collection
+	ReminderTime string    `json:"reminder_time,omitempty"` // HH:MM the evening before; defaults to 19:00
+	TimeZone     string    `json:"time_zone,omitempty"`     // IANA name such as America/New_York; defaults to UTC
+	MemberIDs    []string  `json:"member_ids,omitempty"`    // Rotation order; defaults to every member
+	Points       int       `json:"points,omitempty"`        // Defaults to the group's points per completion
+}
+
+// PickupsHandler lists the group's pickup schedules, next pickup first, on GET and adds one on
+// POST, creating the recurring chore that rotates putting the bins out
+func PickupsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listPickups(w, group)
+	case http.MethodPost:
+		createPickup(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listPickups returns the group's pickup schedules
+func listPickups(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("pickup_schedules").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID},
+		options.Find().SetSort(bson.D{{Key: "next_pickup", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch pickups", http.StatusInternalServerError)
+		return
+	}
+	schedules := make([]models.PickupSchedule, 0)
+	if err := cursor.All(context.Background(), &schedules); err != nil {
+		http.Error(w, "Failed to decode pickups", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedules)
+}
+
+// createPickup adds a pickup schedule and its recurring chore
+func createPickup(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreatePickupRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	kind := models.PickupKind(request.Kind)
+	if !models.IsValidPickupKind(kind) {
+		http.Error(w, "Kind must be trash, recycling, compost or yard_waste", http.StatusBadRequest)
+		return
+	}
+	if request.EveryWeeks == 0 {
+		request.EveryWeeks = 1
+	}
+	if request.ReminderTime == "" {
+		request.ReminderTime = models.DefaultPickupReminderTime
+	}
+	if err := models.ValidatePickupSchedule(request.EveryWeeks, request.FirstPickup, request.ReminderTime, request.TimeZone, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rotation := group.Members
+	if len(request.MemberIDs) > 0 {
+		rotation = make([]primitive.ObjectID, 0, len(request.MemberIDs))
+		for _, id := range request.MemberIDs {
+			memberID, err := primitive.ObjectIDFromHex(id)
+			if err != nil || !group.IsMember(memberID) {
+				http.Error(w, "Every member in the rotation must belong to the group", http.StatusBadRequest)
+				return
+			}
+			rotation = append(rotation, memberID)
+		}
+	}
+	if len(rotation) == 0 {
+		http.Error(w, "Group has no members to assign pickups to", http.StatusBadRequest)
+		return
+	}
+
+	schedule := models.CreatePickupSchedule(group.ID, user.ID, kind, request.EveryWeeks, request.FirstPickup, request.ReminderTime, request.TimeZone)
+	recurringChore := schedule.RecurringChore(rotation, group.Settings.Scoring.ChorePoints(request.Points))
+
+	ctx := context.Background()
+	result, err := config.DB.Collection("recurring_chores").InsertOne(ctx, recurringChore)
+	if err != nil {
+		log.Printf("Failed to create pickup chore: %v", err)
+		http.Error(w, "Failed to create pickup", http.StatusInternalServerError)
+		return
+	}
+	schedule.RecurringChoreID = result.InsertedID.(primitive.ObjectID)
+
+	result, err = config.DB.Collection("pickup_schedules").InsertOne(ctx, schedule)
+	if err != nil {
+		log.Printf("Failed to create pickup: %v", err)
+		config.DB.Collection("recurring_chores").DeleteOne(ctx, bson.M{"_id": schedule.RecurringChoreID})
+		http.Error(w, "Failed to create pickup", http.StatusInternalServerError)
+		return
+	}
+	schedule.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(schedule)
+}
+
+// PickupHandler handles DELETE /api/groups/pickups/{id}, which removes the schedule and stops its
+// recurring chore. Only the member who added it and group admins can remove it.
+func PickupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	scheduleID, err := primitive.ObjectIDFromHex(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/pickups/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid pickup ID", http.StatusBadRequest)
+		return
+	}
+	ctx := context.Background()
+	var schedule models.PickupSchedule
+	err = config.DB.Collection("pickup_schedules").FindOne(ctx, bson.M{"_id": scheduleID, "group_id": group.ID}).Decode(&schedule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Pickup not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch pickup", http.StatusInternalServerError)
+		}
+		return
+	}
+	if schedule.CreatedBy != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the member who added the pickup and group admins can remove it", http.StatusForbidden)
+		return
+	}
+
+	if _, err := config.DB.Collection("pickup_schedules").DeleteOne(ctx, bson.M{"_id": schedule.ID}); err != nil {
+		http.Error(w, "Failed to remove pickup", http.StatusInternalServerError)
+		return
+	}
+	// Turns already assigned stay with their members; no new ones are scheduled
+	_, err = config.DB.Collection("recurring_chores").UpdateOne(ctx,
+		bson.M{"_id": schedule.RecurringChoreID},
+		bson.M{"$set": bson.M{"is_active": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to stop pickup chore %s: %v", schedule.RecurringChoreID.Hex(), err)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,469 @@
+// handlers/polls.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreatePollRequest defines the request structure for starting a scheduling poll
+type CreatePollRequest struct {
+	Title       string            `json:"title"`
+	Description string            `json:"description,omitempty"`
+	TimeZone    string            `json:"time_zone,omitempty"` // IANA name such as America/New_York; defaults to UTC
+	Slots       []models.PollSlot `json:"slots"`               // Proposed times, each with starts_at and ends_at
+	ClosesAt    *time.Time        `json:"closes_at,omitempty"` // Defaults to 48 hours from now, or the earliest slot if sooner
+}
+
+// PollVoteRequest defines the request structure for voting in a poll
+type PollVoteRequest struct {
+	SlotIDs []string `json:"slot_ids"` // The slots the member can make; empty for none
+}
+
+// ConvertPollRequest defines the request structure for turning a poll's winner into a chore or
+// a house event
+type ConvertPollRequest struct {
+	To         string `json:"to"`                    // chore or event
+	AssignedTo string `json:"assigned_to,omitempty"` // Chores: member ID, defaults to the requester
+	Points     int    `json:"points,omitempty"`      // Chores: defaults to the group's points per completion
+	EventType  string `json:"event_type,omitempty"`  // Events: party, maintenance, inspection or other (default)
+}
+
+// PollsHandler lists the group's scheduling polls, newest first, on GET (?status=open, decided
+// or closed to filter) and starts one on POST
+func PollsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listPolls(w, r, group)
+	case http.MethodPost:
+		createPoll(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listPolls returns the group's most recent polls
+func listPolls(w http.ResponseWriter, r *http.Request, group models.Group) {
+	filter := bson.M{"group_id": group.ID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		switch models.PollStatus(status) {
+		case models.PollOpen, models.PollDecided, models.PollClosed:
+			filter["status"] = status
+		default:
+			http.Error(w, "Status must be open, decided or closed", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cursor, err := config.DB.Collection("scheduling_polls").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(50),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch polls", http.StatusInternalServerError)
+		return
+	}
+	polls := make([]models.SchedulingPoll, 0)
+	if err := cursor.All(context.Background(), &polls); err != nil {
+		http.Error(w, "Failed to decode polls", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(polls)
+}
+
+// createPoll starts a poll and asks the other members to vote
+func createPoll(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreatePollRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Title = strings.TrimSpace(request.Title)
+	if request.Title == "" {
+		http.Error(w, "Title is required", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.LoadLocation(request.TimeZone); err != nil {
+		http.Error(w, fmt.Sprintf("Unknown time zone %q", request.TimeZone), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	closesAt := now.Add(models.DefaultPollVotingPeriod)
+	if request.ClosesAt != nil {
+		closesAt = *request.ClosesAt
+	} else {
+		for _, slot := range request.Slots {
+			if slot.StartsAt.After(now) && slot.StartsAt.Before(closesAt) {
+				closesAt = slot.StartsAt
+			}
+		}
+	}
+	if err := models.ValidatePollSlots(request.Slots, closesAt, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	poll := models.CreateSchedulingPoll(group.ID, user.ID, request.Title, strings.TrimSpace(request.Description), request.TimeZone, request.Slots, closesAt)
+	ctx := context.Background()
+	result, err := config.DB.Collection("scheduling_polls").InsertOne(ctx, poll)
+	if err != nil {
+		log.Printf("Failed to create poll: %v", err)
+		http.Error(w, "Failed to create poll", http.StatusInternalServerError)
+		return
+	}
+	poll.ID = result.InsertedID.(primitive.ObjectID)
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.PollCreatedNotification(poll, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(poll)
+}
+
+// PollHandler handles one of the group's polls: GET /api/groups/polls/{id} returns it, DELETE
+// removes it, POST /api/groups/polls/{id}/vote records the member's available slots, POST
+// /api/groups/polls/{id}/close picks the winner without waiting for voting to close, and POST
+// /api/groups/polls/{id}/convert turns the winner into a chore or a house event. Removing,
+// closing and converting are for the poll's creator and group admins.
+func PollHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/polls/"), "/"), "/")
+	pollID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid poll ID", http.StatusBadRequest)
+		return
+	}
+	var poll models.SchedulingPoll
+	err = config.DB.Collection("scheduling_polls").FindOne(context.Background(), bson.M{"_id": pollID, "group_id": group.ID}).Decode(&poll)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Poll not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch poll", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	manages := poll.CreatedBy == user.ID || group.IsAdmin(user.ID)
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(poll)
+	case action == "" && r.Method == http.MethodDelete:
+		if !manages {
+			http.Error(w, "Only the poll's creator and group admins can remove it", http.StatusForbidden)
+			return
+		}
+		deletePoll(w, poll)
+	case action == "vote" && r.Method == http.MethodPost:
+		votePoll(w, r, user, group, poll)
+	case action == "close" && r.Method == http.MethodPost:
+		if !manages {
+			http.Error(w, "Only the poll's creator and group admins can close it", http.StatusForbidden)
+			return
+		}
+		closePoll(w, r, poll)
+	case action == "convert" && r.Method == http.MethodPost:
+		if !manages {
+			http.Error(w, "Only the poll's creator and group admins can convert it", http.StatusForbidden)
+			return
+		}
+		convertPoll(w, r, user, group, poll)
+	case action == "" || action == "vote" || action == "close" || action == "convert":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// deletePoll removes the poll; chores and events made from it stay
+func deletePoll(w http.ResponseWriter, poll models.SchedulingPoll) {
+	if _, err := config.DB.Collection("scheduling_polls").DeleteOne(context.Background(), bson.M{"_id": poll.ID}); err != nil {
+		http.Error(w, "Failed to remove poll", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// votePoll records the slots the member can make. Once every member has voted the poll is
+// decided straight away.
+func votePoll(w http.ResponseWriter, r *http.Request, user models.User, group models.Group, poll models.SchedulingPoll) {
+	var request PollVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if poll.Status != models.PollOpen {
+		writeError(w, r, apierror.CodeVotingClosed, "Voting has closed")
+		return
+	}
+	slotIDs := make([]primitive.ObjectID, 0, len(request.SlotIDs))
+	for _, id := range request.SlotIDs {
+		slotID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			http.Error(w, "Invalid slot ID", http.StatusBadRequest)
+			return
+		}
+		slotIDs = append(slotIDs, slotID)
+	}
+	now := time.Now()
+	if err := poll.SetVote(user.ID, slotIDs, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var vote models.PollVote
+	for _, v := range poll.Votes {
+		if v.UserID == user.ID {
+			vote = v
+		}
+	}
+
+	// Replace the member's vote in place so votes from other members are not overwritten
+	ctx := context.Background()
+	collection := config.DB.Collection("scheduling_polls")
+	open := bson.M{"_id": poll.ID, "status": models.PollOpen}
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": poll.ID, "status": models.PollOpen, "votes.user_id": user.ID},
+		bson.M{"$set": bson.M{"votes.$": vote, "updated_at": now}},
+	)
+	if err == nil && result.MatchedCount == 0 {
+		open["votes.user_id"] = bson.M{"$ne": user.ID}
+		result, err = collection.UpdateOne(ctx, open, bson.M{
+			"$push": bson.M{"votes": vote},
+			"$set":  bson.M{"updated_at": now},
+		})
+	}
+	if err != nil {
+		http.Error(w, "Failed to record vote", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		writeError(w, r, apierror.CodeVotingClosed, "Voting has closed")
+		return
+	}
+	poll.UpdatedAt = now
+
+	if poll.AllVoted(group.Members) {
+		// Decide the poll as stored, which includes votes that came in since it was read
+		if err := collection.FindOne(ctx, bson.M{"_id": poll.ID}).Decode(&poll); err == nil && poll.AllVoted(group.Members) {
+			if poll, _, err = jobs.ClosePoll(ctx, poll); err != nil {
+				log.Printf("Failed to decide poll %s: %v", poll.ID.Hex(), err)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poll)
+}
+
+// closePoll picks the poll's winner now
+func closePoll(w http.ResponseWriter, r *http.Request, poll models.SchedulingPoll) {
+	poll, decided, err := jobs.ClosePoll(context.Background(), poll)
+	if err != nil {
+		log.Printf("Failed to decide poll %s: %v", poll.ID.Hex(), err)
+		http.Error(w, "Failed to close poll", http.StatusInternalServerError)
+		return
+	}
+	if !decided {
+		writeError(w, r, apierror.CodeVotingClosed, "Voting has already closed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(poll)
+}
+
+// convertPoll makes the poll's winning slot into a chore due when the slot ends, or a house event
+// the members available for it are going to. A poll's winner can only be converted once.
+func convertPoll(w http.ResponseWriter, r *http.Request, user models.User, group models.Group, poll models.SchedulingPoll) {
+	var request ConvertPollRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	slot, ok := poll.WinningSlot()
+	if !ok {
+		http.Error(w, "The poll has no winning slot", http.StatusConflict)
+		return
+	}
+	if poll.ChoreID != nil || poll.HouseEventID != nil {
+		http.Error(w, "The poll has already been converted", http.StatusConflict)
+		return
+	}
+
+	switch request.To {
+	case "chore":
+		convertPollToChore(w, request, user, group, poll, slot)
+	case "event":
+		convertPollToEvent(w, request, user, group, poll, slot)
+	default:
+		http.Error(w, "To must be chore or event", http.StatusBadRequest)
+	}
+}
+
+// claimPollConversion links the poll to what it was converted into, failing if another request
+// converted it first
+func claimPollConversion(ctx context.Context, pollID primitive.ObjectID, field string, id primitive.ObjectID) (bool, error) {
+	result, err := config.DB.Collection("scheduling_polls").UpdateOne(ctx,
+		bson.M{"_id": pollID, "chore_id": bson.M{"$exists": false}, "house_event_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{field: id, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// convertPollToChore assigns a chore due at the end of the winning slot
+func convertPollToChore(w http.ResponseWriter, request ConvertPollRequest, user models.User, group models.Group, poll models.SchedulingPoll, slot models.PollSlot) {
+	assignee := user.ID
+	if request.AssignedTo != "" {
+		id, err := primitive.ObjectIDFromHex(request.AssignedTo)
+		if err != nil || !group.IsMember(id) {
+			http.Error(w, "Assigned user must be a member of the group", http.StatusBadRequest)
+			return
+		}
+		assignee = id
+	}
+
+	ctx := context.Background()
+	chore := models.CreateChore(poll.Title, poll.Description, group.ID, assignee, slot.EndsAt, group.Settings.Scoring.ChorePoints(request.Points))
+	chore.StartDate = slot.StartsAt
+	result, err := config.DB.Collection("chores").InsertOne(ctx, chore)
+	if err != nil {
+		log.Printf("Chore creation error: %v", err)
+		http.Error(w, "Failed to create chore", http.StatusInternalServerError)
+		return
+	}
+	chore.ID = result.InsertedID.(primitive.ObjectID)
+
+	claimed, err := claimPollConversion(ctx, poll.ID, "chore_id", chore.ID)
+	if err != nil || !claimed {
+		config.DB.Collection("chores").DeleteOne(ctx, bson.M{"_id": chore.ID})
+		if err != nil {
+			http.Error(w, "Failed to convert poll", http.StatusInternalServerError)
+		} else {
+			http.Error(w, "The poll has already been converted", http.StatusConflict)
+		}
+		return
+	}
+	poll.ChoreID = &chore.ID
+
+	if _, err := config.DB.Collection("notifications").InsertOne(ctx, models.ChoreAssignedNotification(chore)); err != nil {
+		log.Printf("Failed to notify member of chore %s: %v", chore.ID.Hex(), err)
+	}
+	webhooks.ChoreAssigned(ctx, *chore)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"poll":  poll,
+		"chore": chore,
+	})
+}
+
+// convertPollToEvent adds a house event over the winning slot with the members who can make it
+// going
+func convertPollToEvent(w http.ResponseWriter, request ConvertPollRequest, user models.User, group models.Group, poll models.SchedulingPoll, slot models.PollSlot) {
+	eventType := models.HouseEventOther
+	if request.EventType != "" {
+		eventType = models.HouseEventType(request.EventType)
+	}
+	if !models.IsValidHouseEventType(eventType) {
+		http.Error(w, "Event type must be party, maintenance, inspection or other", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if !slot.StartsAt.After(now) {
+		http.Error(w, "The winning slot has already started", http.StatusConflict)
+		return
+	}
+
+	ctx := context.Background()
+	event := models.CreateHouseEvent(group.ID, user.ID, eventType, poll.Title, slot.StartsAt, slot.EndsAt, poll.TimeZone, models.DefaultHouseEventReminder)
+	event.Description = poll.Description
+	for _, memberID := range poll.Available(slot.ID) {
+		event.SetRSVP(memberID, models.RSVPGoing, now)
+	}
+	result, err := config.DB.Collection("house_events").InsertOne(ctx, event)
+	if err != nil {
+		log.Printf("Failed to create house event: %v", err)
+		http.Error(w, "Failed to create house event", http.StatusInternalServerError)
+		return
+	}
+	event.ID = result.InsertedID.(primitive.ObjectID)
+
+	claimed, err := claimPollConversion(ctx, poll.ID, "house_event_id", event.ID)
+	if err != nil || !claimed {
+		config.DB.Collection("house_events").DeleteOne(ctx, bson.M{"_id": event.ID})
+		if err != nil {
+			http.Error(w, "Failed to convert poll", http.StatusInternalServerError)
+		} else {
+			http.Error(w, "The poll has already been converted", http.StatusConflict)
+		}
+		return
+	}
+	poll.HouseEventID = &event.ID
+
+	notifications := make([]*models.Notification, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != user.ID {
+			notifications = append(notifications, models.HouseEventCreatedNotification(event, memberID, user.Name))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"poll":  poll,
+		"event": event,
+	})
+}
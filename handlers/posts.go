@@ -0,0 +1,455 @@
+// handlers/posts.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// postAttachmentPath serves post attachments through signed URLs: /api/groups/posts/attachments/{file_id}
+const postAttachmentPath = "/api/groups/posts/attachments/"
+
+// PostRequest defines the request structure for writing or editing a post; fields left out of an
+// edit stay as they are
+type PostRequest struct {
+	Category *string `json:"category,omitempty"` // general (default), house_rules or sublet
+	Title    *string `json:"title,omitempty"`
+	Body     *string `json:"body,omitempty"`
+}
+
+// PostAttachmentDetails is an attachment with a signed link to the file
+type PostAttachmentDetails struct {
+	models.PostAttachment
+	URL string `json:"url"`
+}
+
+// PostDetails is a post with signed links to its attachments and the reactions on it
+type PostDetails struct {
+	models.Post
+	Attachments []PostAttachmentDetails `json:"attachments"`
+	Reactions   []models.ReactionCount  `json:"reactions"`
+}
+
+// PostPage is a page of posts, newest first. NextBefore is passed as ?before= to fetch the next
+// page and is empty on the last one.
+type PostPage struct {
+	Posts      []PostDetails `json:"posts"`
+	NextBefore string        `json:"next_before,omitempty"`
+}
+
+// postDetails signs the URLs of a post's attachments and adds its reactions
+func postDetails(post models.Post, reactions []models.ReactionCount, now time.Time) PostDetails {
+	if reactions == nil {
+		reactions = []models.ReactionCount{}
+	}
+	details := PostDetails{Post: post, Attachments: make([]PostAttachmentDetails, 0, len(post.Attachments)), Reactions: reactions}
+	for _, attachment := range post.Attachments {
+		details.Attachments = append(details.Attachments, PostAttachmentDetails{
+			PostAttachment: attachment,
+			URL:            storage.SignedURL(postAttachmentPath, attachment.FileID, now),
+		})
+	}
+	return details
+}
+
+// PostsHandler pages through the group's bulletin board, newest first, on GET (?limit=, ?before=
+// a post ID from next_before, ?category=) and writes a post on POST. Attachments are uploaded to
+// the post once it exists, see PostHandler.
+func PostsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listPosts(w, r, user)
+	case http.MethodPost:
+		createPost(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listPosts returns a page of the group's posts
+func listPosts(w http.ResponseWriter, r *http.Request, user models.User) {
+	query := r.URL.Query()
+	limit := models.DefaultPostPageSize
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > models.MaxPostPageSize {
+			http.Error(w, fmt.Sprintf("Limit must be between 1 and %d", models.MaxPostPageSize), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if before := query.Get("before"); before != "" {
+		beforeID, err := primitive.ObjectIDFromHex(before)
+		if err != nil {
+			http.Error(w, "Invalid before post ID", http.StatusBadRequest)
+			return
+		}
+		filter["_id"] = bson.M{"$lt": beforeID}
+	}
+	if category := query.Get("category"); category != "" {
+		if !models.IsValidPostCategory(models.PostCategory(category)) {
+			http.Error(w, "Category must be general, house_rules or sublet", http.StatusBadRequest)
+			return
+		}
+		filter["category"] = category
+	}
+
+	// IDs grow over time, so sorting by them is newest first and pages never shift as posts are added
+	cursor, err := config.DB.Collection("posts").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(int64(limit+1)),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch posts", http.StatusInternalServerError)
+		return
+	}
+	posts := make([]models.Post, 0, limit+1)
+	if err := cursor.All(context.Background(), &posts); err != nil {
+		http.Error(w, "Failed to decode posts", http.StatusInternalServerError)
+		return
+	}
+
+	page := PostPage{Posts: make([]PostDetails, 0, len(posts))}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		page.NextBefore = posts[limit-1].ID.Hex()
+	}
+	postIDs := make([]primitive.ObjectID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	reactions, err := reactionSummaries(context.Background(), user.GroupID, models.ReactionTargetPost, postIDs, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch reactions", http.StatusInternalServerError)
+		return
+	}
+	now := time.Now()
+	for _, post := range posts {
+		page.Posts = append(page.Posts, postDetails(post, reactions[post.ID], now))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// createPost writes a post to the group's board
+func createPost(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request PostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	category := models.PostGeneral
+	if request.Category != nil && *request.Category != "" {
+		category = models.PostCategory(*request.Category)
+	}
+	if !models.IsValidPostCategory(category) {
+		http.Error(w, "Category must be general, house_rules or sublet", http.StatusBadRequest)
+		return
+	}
+	var title, body string
+	if request.Title != nil {
+		title = *request.Title
+	}
+	if request.Body != nil {
+		body = *request.Body
+	}
+	if err := models.ValidatePost(title, body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	post := models.CreatePost(user.GroupID, user.ID, category, title, body)
+	result, err := config.DB.Collection("posts").InsertOne(context.Background(), post)
+	if err != nil {
+		log.Printf("Failed to create post: %v", err)
+		http.Error(w, "Failed to create post", http.StatusInternalServerError)
+		return
+	}
+	post.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(postDetails(*post, nil, time.Now()))
+}
+
+// PostHandler handles one of the group's posts: GET /api/groups/posts/{id} returns it, PUT edits
+// it and DELETE removes it with its attachments; POST /api/groups/posts/{id}/attachments uploads
+// an image or PDF as the multipart "file" field and DELETE
+// /api/groups/posts/{id}/attachments/{file_id} removes one. Only the author edits a post and
+// manages its attachments; group admins can also remove it.
+func PostHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/posts/"), "/"), "/")
+	postID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 3 || len(parts) > 1 && parts[1] != "attachments" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	var post models.Post
+	err = config.DB.Collection("posts").FindOne(context.Background(), bson.M{"_id": postID, "group_id": group.ID}).Decode(&post)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Post not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch post", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	isAuthor := post.AuthorID == user.ID
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		reactions := reactionsOn(context.Background(), group.ID, models.ReactionTargetPost, post.ID, user.ID)
+		json.NewEncoder(w).Encode(postDetails(post, reactions, time.Now()))
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if !isAuthor && !group.IsAdmin(user.ID) {
+			http.Error(w, "Only the author and group admins can remove a post", http.StatusForbidden)
+			return
+		}
+		deletePost(w, post)
+	case !isAuthor && (r.Method == http.MethodPut || r.Method == http.MethodPost || r.Method == http.MethodDelete):
+		http.Error(w, "Only the author can change a post", http.StatusForbidden)
+	case len(parts) == 1 && r.Method == http.MethodPut:
+		updatePost(w, r, user, post)
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		uploadPostAttachment(w, r, user, post)
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		deletePostAttachment(w, post, parts[2])
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// updatePost edits the post's category, title or body
+func updatePost(w http.ResponseWriter, r *http.Request, user models.User, post models.Post) {
+	var request PostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	updateFields := bson.M{"updated_at": now}
+	if request.Category != nil {
+		category := models.PostCategory(*request.Category)
+		if !models.IsValidPostCategory(category) {
+			http.Error(w, "Category must be general, house_rules or sublet", http.StatusBadRequest)
+			return
+		}
+		post.Category = category
+		updateFields["category"] = category
+	}
+	if request.Title != nil || request.Body != nil {
+		if request.Title != nil {
+			post.Title = strings.TrimSpace(*request.Title)
+		}
+		if request.Body != nil {
+			post.Body = strings.TrimSpace(*request.Body)
+		}
+		if err := models.ValidatePost(post.Title, post.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		post.EditedAt = &now
+		updateFields["title"] = post.Title
+		updateFields["body"] = post.Body
+		updateFields["edited_at"] = now
+	}
+	post.UpdatedAt = now
+
+	if _, err := config.DB.Collection("posts").UpdateOne(context.Background(), bson.M{"_id": post.ID}, bson.M{"$set": updateFields}); err != nil {
+		http.Error(w, "Failed to update post", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	reactions := reactionsOn(context.Background(), post.GroupID, models.ReactionTargetPost, post.ID, user.ID)
+	json.NewEncoder(w).Encode(postDetails(post, reactions, now))
+}
+
+// deletePost removes the post with its attachments and reactions
+func deletePost(w http.ResponseWriter, post models.Post) {
+	ctx := context.Background()
+	if _, err := config.DB.Collection("posts").DeleteOne(ctx, bson.M{"_id": post.ID}); err != nil {
+		http.Error(w, "Failed to remove post", http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Collection("reactions").DeleteMany(ctx, bson.M{"target_type": models.ReactionTargetPost, "target_id": post.ID}); err != nil {
+		log.Printf("Failed to remove reactions on post %s: %v", post.ID.Hex(), err)
+	}
+	for _, attachment := range post.Attachments {
+		removePostAttachment(attachment.FileID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// uploadPostAttachment stores a file and attaches it to the post
+func uploadPostAttachment(w http.ResponseWriter, r *http.Request, user models.User, post models.Post) {
+	if len(post.Attachments) >= models.MaxPostAttachments {
+		writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("Posts can have at most %d attachments", models.MaxPostAttachments))
+		return
+	}
+
+	// Leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, storage.MaxAttachmentSize+64<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "An image or PDF under 10 MB is required in the \"file\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read attachment", http.StatusBadRequest)
+		return
+	}
+
+	fileID, contentType, err := storage.SaveAttachment(header.Filename, data)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrFileTooLarge):
+			http.Error(w, "Attachments must be 10 MB or smaller", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, storage.ErrUnsupportedFile):
+			http.Error(w, "Attachments must be a JPEG, PNG, GIF or WebP image or a PDF", http.StatusUnsupportedMediaType)
+		default:
+			log.Printf("Failed to store attachment of post %s: %v", post.ID.Hex(), err)
+			http.Error(w, "Failed to store attachment", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	attachment := models.PostAttachment{FileID: fileID, Filename: header.Filename, ContentType: contentType, Size: len(data)}
+	now := time.Now()
+	// The size check in the filter keeps concurrent uploads from going over the limit
+	result, err := config.DB.Collection("posts").UpdateOne(
+		context.Background(),
+		bson.M{"_id": post.ID, fmt.Sprintf("attachments.%d", models.MaxPostAttachments-1): bson.M{"$exists": false}},
+		bson.M{"$push": bson.M{"attachments": attachment}, "$set": bson.M{"updated_at": now}},
+	)
+	if err != nil || result.MatchedCount == 0 {
+		removePostAttachment(fileID)
+		if err != nil {
+			log.Printf("Failed to attach file to post %s: %v", post.ID.Hex(), err)
+			http.Error(w, "Failed to attach file", http.StatusInternalServerError)
+		} else {
+			writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("Posts can have at most %d attachments", models.MaxPostAttachments))
+		}
+		return
+	}
+	post.Attachments = append(post.Attachments, attachment)
+	post.UpdatedAt = now
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	reactions := reactionsOn(context.Background(), post.GroupID, models.ReactionTargetPost, post.ID, user.ID)
+	json.NewEncoder(w).Encode(postDetails(post, reactions, now))
+}
+
+// deletePostAttachment detaches a file from the post and removes it
+func deletePostAttachment(w http.ResponseWriter, post models.Post, id string) {
+	fileID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := post.Attachment(fileID); !ok {
+		http.Error(w, "Attachment not found", http.StatusNotFound)
+		return
+	}
+
+	_, err = config.DB.Collection("posts").UpdateOne(
+		context.Background(),
+		bson.M{"_id": post.ID},
+		bson.M{"$pull": bson.M{"attachments": bson.M{"file_id": fileID}}, "$set": bson.M{"updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to detach file from post %s: %v", post.ID.Hex(), err)
+		http.Error(w, "Failed to remove attachment", http.StatusInternalServerError)
+		return
+	}
+	removePostAttachment(fileID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removePostAttachment deletes a stored attachment in the background once nothing refers to it
+func removePostAttachment(fileID primitive.ObjectID) {
+	go func() {
+		if err := storage.Delete(context.Background(), fileID); err != nil {
+			log.Printf("Failed to delete post attachment %s: %v", fileID.Hex(), err)
+		}
+	}()
+}
+
+// ServePostAttachmentHandler streams a post attachment from a signed URL handed out with the
+// post, so image tags and links can load it without the auth header
+func ServePostAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	fileID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, postAttachmentPath))
+	if err != nil {
+		http.Error(w, "Invalid attachment ID format", http.StatusBadRequest)
+		return
+	}
+	if err := storage.VerifySignature(fileID, r.URL.Query(), time.Now()); err != nil {
+		http.Error(w, "Attachment link is invalid or has expired", http.StatusForbidden)
+		return
+	}
+
+	file, contentType, err := storage.Open(context.Background(), fileID)
+	if err != nil {
+		if errors.Is(err, storage.ErrFileNotFound) {
+			http.Error(w, "Attachment not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to open post attachment %s: %v", fileID.Hex(), err)
+			http.Error(w, "Failed to fetch attachment", http.StatusInternalServerError)
+		}
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "private, max-age=900")
+	if _, err := io.Copy(w, file); err != nil {
+		log.Printf("Failed to send post attachment %s: %v", fileID.Hex(), err)
+	}
+}
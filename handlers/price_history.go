@@ -0,0 +1,102 @@
+// handlers/price_history.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetItemPriceHistoryHandler returns the prices the group paid for an item over time with the
+// average price and trend. Path format: /api/shopping/items/{name}/price-history?months=12
+func GetItemPriceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/shopping/items/")
+	if !strings.HasSuffix(path, "/price-history") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	name := models.NormalizeItemName(strings.TrimSuffix(path, "/price-history"))
+	if name == "" {
+		http.Error(w, "Item name is required", http.StatusBadRequest)
+		return
+	}
+
+	months := 12
+	if monthsStr := r.URL.Query().Get("months"); monthsStr != "" {
+		parsed, err := strconv.Atoi(monthsStr)
+		if err != nil || parsed < 1 || parsed > 60 {
+			http.Error(w, "Months must be between 1 and 60", http.StatusBadRequest)
+			return
+		}
+		months = parsed
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	purchases, err := findItemPurchases(context.Background(), user.GroupID, name, months)
+	if err != nil {
+		log.Printf("Failed to fetch price history: %v", err)
+		http.Error(w, "Failed to fetch price history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Price history retrieved successfully",
+		Data: map[string]interface{}{
+			"item_name": name,
+			"summary":   models.SummarizePrices(purchases),
+		},
+	})
+}
+
+// findItemPurchases returns the group's purchases of an item over the last months, oldest first.
+// The name is matched regardless of case and spacing, like duplicate items in the cart.
+func findItemPurchases(ctx context.Context, groupID primitive.ObjectID, name string, months int) ([]models.PurchaseRecord, error) {
+	words := strings.Fields(name)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	pattern := `^\s*` + strings.Join(words, `\s+`) + `\s*$`
+
+	cursor, err := config.DB.Collection("purchase_history").Find(
+		ctx,
+		bson.M{
+			"group_id":     groupID,
+			"item_name":    bson.M{"$regex": primitive.Regex{Pattern: pattern, Options: "i"}},
+			"purchased_at": bson.M{"$gte": time.Now().AddDate(0, -months, 0)},
+		},
+		options.Find().SetSort(bson.D{{Key: "purchased_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	purchases := make([]models.PurchaseRecord, 0)
+	if err := cursor.All(ctx, &purchases); err != nil {
+		return nil, err
+	}
+	return purchases, nil
+}
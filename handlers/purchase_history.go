@@ -0,0 +1,571 @@
+// handlers/purchase_history.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PurchaseShoppingCartItemRequest defines the request structure for marking an item as purchased
+type PurchaseShoppingCartItemRequest struct {
+	Price         float64  `json:"price" validate:"min=0"`
+	CreateExpense *bool    `json:"create_expense"` // Overrides the group's auto_create_expenses setting
+	SplitWith     []string `json:"split_with"`     // Usernames sharing shared items; defaults to the whole group
+	AddToPantry   *bool    `json:"add_to_pantry"`  // Overrides the group's auto_add_to_pantry setting
+}
+
+// CheckoutShoppingCartRequest defines the request structure for purchasing several items in one trip
+type CheckoutShoppingCartRequest struct {
+	Items []struct {
+		ItemID string  `json:"item_id"`
+		Price  float64 `json:"price"`
+	} `json:"items"`
+	Description   string   `json:"description"`
+	CreateExpense *bool    `json:"create_expense"`
+	SplitWith     []string `json:"split_with"`
+	AddToPantry   *bool    `json:"add_to_pantry"`
+}
+
+// purchaseLine is one cart item being bought at a price
+type purchaseLine struct {
+	ItemID primitive.ObjectID
+	Price  float64
+}
+
+// checkoutResult is returned by purchase and checkout requests
+type checkoutResult struct {
+	Purchases   []*models.PurchaseRecord `json:"purchases"`
+	Expense     *models.Expense          `json:"expense,omitempty"`
+	PantryItems []models.PantryItem      `json:"pantry_items,omitempty"` // Pantry items created or restocked by the purchase
+}
+
+// PurchaseShoppingCartItemHandler moves a shopping cart item into the group's purchase history.
+// Any group member can mark an item purchased, not only the member who added it.
+// Path format: /api/shopping-cart/{id}/purchase
+func PurchaseShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/shopping-cart/")
+	if !strings.HasSuffix(path, "/purchase") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/purchase"))
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	var request PurchaseShoppingCartItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Price < 0 {
+		http.Error(w, "Price cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	result, ok := checkoutItems(w, r, []purchaseLine{{ItemID: itemID, Price: request.Price}}, "", request.CreateExpense, request.SplitWith, request.AddToPantry)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Item marked as purchased",
+		Data:    result,
+	})
+}
+
+// CheckoutShoppingCartHandler marks several cart items purchased in one trip, optionally billing
+// the whole trip to the group as a single expense
+func CheckoutShoppingCartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CheckoutShoppingCartRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Items) == 0 {
+		http.Error(w, "At least one item is required", http.StatusBadRequest)
+		return
+	}
+
+	lines := make([]purchaseLine, 0, len(request.Items))
+	seen := make(map[primitive.ObjectID]bool)
+	for _, item := range request.Items {
+		itemID, err := primitive.ObjectIDFromHex(item.ItemID)
+		if err != nil {
+			http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+			return
+		}
+		if item.Price < 0 {
+			http.Error(w, "Price cannot be negative", http.StatusBadRequest)
+			return
+		}
+		if seen[itemID] {
+			http.Error(w, "Each item can only be purchased once", http.StatusBadRequest)
+			return
+		}
+		seen[itemID] = true
+		lines = append(lines, purchaseLine{ItemID: itemID, Price: item.Price})
+	}
+
+	result, ok := checkoutItems(w, r, lines, request.Description, request.CreateExpense, request.SplitWith, request.AddToPantry)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Items marked as purchased",
+		Data:    result,
+	})
+}
+
+// checkoutItems moves the items from the cart to the archive and records their purchases, plus an expense
+// covering them when createExpense, or the group's setting if nil, asks for it, all in one
+// transaction. Shared items are split between the
+// splitWith members (the whole group when empty). The items are then added to the pantry when
+// addToPantry, or the group's setting if nil, asks for it. It writes the error response itself
+// and returns false on failure.
+func checkoutItems(w http.ResponseWriter, r *http.Request, lines []purchaseLine, description string, createExpense *bool, splitWith []string, addToPantry *bool) (*checkoutResult, bool) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return nil, false
+	}
+
+	billed := group.Settings.AutoCreateExpenses
+	if createExpense != nil {
+		billed = *createExpense
+	}
+
+	members := group.Members
+	if len(splitWith) > 0 {
+		members = make([]primitive.ObjectID, 0, len(splitWith))
+		for _, username := range splitWith {
+			var member models.User
+			err := config.DB.Collection("users").FindOne(
+				context.Background(),
+				bson.M{"username": username, "group_id": group.ID},
+			).Decode(&member)
+			if err != nil {
+				if errors.Is(err, mongo.ErrNoDocuments) {
+					http.Error(w, "User "+username+" not found in group", http.StatusBadRequest)
+				} else {
+					http.Error(w, "Failed to fetch user "+username, http.StatusInternalServerError)
+				}
+				return nil, false
+			}
+			members = append(members, member.ID)
+		}
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil, false
+	}
+	defer session.EndSession(context.Background())
+
+	// Remove the items from the cart and record the purchases together
+	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		checkout := &checkoutResult{Purchases: make([]*models.PurchaseRecord, 0, len(lines))}
+
+		total := 0.0
+		shares := make([][]models.CostShare, 0, len(lines))
+		names := make([]string, 0, len(lines))
+		archived := make([]interface{}, 0, len(lines))
+		for _, line := range lines {
+			var item models.ShoppingCartItem
+			err := config.DB.Collection("shopping_cart").FindOneAndDelete(
+				sessionContext,
+				bson.M{"_id": line.ItemID, "group_id": user.GroupID},
+			).Decode(&item)
+			if err != nil {
+				return nil, err
+			}
+
+			record := models.CreatePurchaseRecord(item, user.ID, user.Name, line.Price, members)
+			record.ID = primitive.NewObjectID()
+			checkout.Purchases = append(checkout.Purchases, record)
+			archived = append(archived, models.ArchivePurchasedItem(item, user.ID, record.PurchasedAt))
+
+			total += line.Price
+			shares = append(shares, record.Shares)
+			names = append(names, item.ItemName)
+		}
+
+		if billed && total > 0 {
+			if description == "" {
+				description = "Shopping: " + strings.Join(names, ", ")
+			}
+			expense := models.CreateExpense(user.GroupID, user.ID, description, total, models.MergeShares(shares...), models.ExpenseSourceShopping)
+			expense.Currency = group.Settings.CurrencyCode()
+			expense.ID = primitive.NewObjectID()
+			for _, record := range checkout.Purchases {
+				expense.PurchaseIDs = append(expense.PurchaseIDs, record.ID)
+				record.ExpenseID = &expense.ID
+			}
+			if _, err := config.DB.Collection("expenses").InsertOne(sessionContext, expense); err != nil {
+				return nil, err
+			}
+			checkout.Expense = expense
+		}
+
+		records := make([]interface{}, 0, len(checkout.Purchases))
+		for _, record := range checkout.Purchases {
+			records = append(records, record)
+		}
+		if _, err := config.DB.Collection("purchase_history").InsertMany(sessionContext, records); err != nil {
+			return nil, err
+		}
+
+		// Archive the items so they can be re-added on the next trip
+		if _, err := config.DB.Collection("shopping_cart_archive").InsertMany(sessionContext, archived); err != nil {
+			return nil, err
+		}
+		return checkout, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Shopping cart item not found in your group", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to purchase shopping cart items: %v", err)
+			http.Error(w, "Failed to mark items as purchased", http.StatusInternalServerError)
+		}
+		return nil, false
+	}
+	checkout := result.(*checkoutResult)
+
+	restock := group.Settings.AutoAddToPantry
+	if addToPantry != nil {
+		restock = *addToPantry
+	}
+	if restock {
+		checkout.PantryItems = restockPantry(user, checkout.Purchases)
+	}
+
+	insertNotifications(context.Background(), models.ItemPurchasedNotifications(checkout.Purchases, user))
+	if checkout.Expense != nil {
+		insertNotifications(context.Background(), models.ExpenseAddedNotifications(*checkout.Expense, user.Name))
+	}
+	webhooks.ItemsPurchased(context.Background(), checkout.Purchases)
+	if checkout.Expense != nil {
+		webhooks.ExpenseAdded(context.Background(), *checkout.Expense, user)
+	}
+
+	// Log the activity
+	go func() {
+		for _, record := range checkout.Purchases {
+			activity := models.CreateShoppingCartActivity(
+				user.GroupID,
+				record.ItemID,
+				record.ItemName,
+				user.ID,
+				user.Name,
+				models.CartActivityTypePurchase,
+				record.Quantity,
+				fmt.Sprintf("Purchased %s for %.2f", record.ItemName, record.Price),
+			)
+
+			_, err := config.DB.Collection("shopping_cart_activity").InsertOne(
+				context.Background(),
+				activity,
+			)
+
+			if err != nil {
+				log.Printf("Failed to create shopping cart activity record: %v", err)
+			}
+		}
+	}()
+
+	return checkout, true
+}
+
+// purchaseTotals sums the price of the purchases matching filter and what each member owes for them
+func purchaseTotals(ctx context.Context, filter bson.M) (float64, map[string]float64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: bson.M{
+			"spent": bson.A{
+				bson.M{"$group": bson.M{"_id": nil, "amount": bson.M{"$sum": "$price"}}},
+			},
+			"shares": bson.A{
+				bson.M{"$unwind": "$shares"},
+				bson.M{"$group": bson.M{"_id": "$shares.user_id", "amount": bson.M{"$sum": "$shares.amount"}}},
+			},
+		}}},
+	}
+	cursor, err := config.DB.Collection("purchase_history").Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, nil, err
+	}
+	var results []struct {
+		Spent []struct {
+			Amount float64 `bson:"amount"`
+		} `bson:"spent"`
+		Shares []struct {
+			UserID primitive.ObjectID `bson:"_id"`
+			Amount float64            `bson:"amount"`
+		} `bson:"shares"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, nil, err
+	}
+
+	total := 0.0
+	memberTotals := make(map[string]float64)
+	for _, result := range results {
+		for _, spent := range result.Spent {
+			total += spent.Amount
+		}
+		for _, share := range result.Shares {
+			memberTotals[share.UserID.Hex()] += share.Amount
+		}
+	}
+	return total, memberTotals, nil
+}
+
+// GetPurchaseHistoryHandler lists the group's purchases, newest first and optionally for a single
+// month (?month=YYYY-MM). Without ?limit= or ?cursor= every purchase is returned; with them the
+// purchases are paged. The totals cover every purchase in the month, or ever, not just the page.
+func GetPurchaseHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "purchased_at", Descending: true}, models.Unpaged)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if month := r.URL.Query().Get("month"); month != "" {
+		start, end, err := models.MonthBounds(month)
+		if err != nil {
+			http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+			return
+		}
+		filter["purchased_at"] = bson.M{"$gte": start, "$lt": end}
+	}
+
+	cursor, err := config.DB.Collection("purchase_history").Find(context.Background(), page.filter(filter), page.findOptions())
+	if err != nil {
+		log.Printf("Failed to fetch purchase history: %v", err)
+		http.Error(w, "Failed to fetch purchase history", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	purchases := make([]models.PurchaseRecord, 0)
+	if err := cursor.All(context.Background(), &purchases); err != nil {
+		log.Printf("Failed to decode purchase history: %v", err)
+		http.Error(w, "Failed to decode purchase history", http.StatusInternalServerError)
+		return
+	}
+	var next string
+	if page.hasMore(len(purchases)) {
+		purchases = purchases[:page.Limit]
+		last := purchases[page.Limit-1]
+		next = nextCursor(w, models.PageCursor{Key: last.PurchasedAt, ID: last.ID})
+	}
+
+	// Totals per member only count what each member owes for the items, so personal purchases stay personal
+	total, memberTotals, err := purchaseTotals(context.Background(), filter)
+	if err != nil {
+		log.Printf("Failed to total purchase history: %v", err)
+		http.Error(w, "Failed to total purchase history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Purchase history retrieved successfully",
+		Data: map[string]interface{}{
+			"purchases":     purchases,
+			"total_spent":   total,
+			"member_shares": memberTotals,
+		},
+		NextCursor: next,
+	})
+}
+
+// UpdatePurchaseRequest defines the request structure for correcting the price of a purchase
+type UpdatePurchaseRequest struct {
+	Price float64 `json:"price" validate:"min=0"`
+}
+
+// UpdatePurchaseHandler corrects the price a purchase was recorded at. When the purchase was billed
+// through an expense, the expense's amount and splits are recalculated from all of its purchases in
+// the same transaction, so balances follow the correction. Only the member who bought the item or a
+// group admin can change it.
+// Path format: /api/shopping-cart/history/{id}
+func UpdatePurchaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	purchaseID, err := primitive.ObjectIDFromHex(strings.TrimPrefix(r.URL.Path, "/api/shopping-cart/history/"))
+	if err != nil {
+		http.Error(w, "Invalid purchase ID format", http.StatusBadRequest)
+		return
+	}
+
+	var request UpdatePurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Price < 0 {
+		http.Error(w, "Price cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var purchase models.PurchaseRecord
+	err = config.DB.Collection("purchase_history").FindOne(
+		context.Background(),
+		bson.M{"_id": purchaseID, "group_id": user.GroupID},
+	).Decode(&purchase)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Purchase not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch purchase", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if purchase.PurchasedBy != user.ID {
+		group, ok := getGroupByID(w, user.GroupID)
+		if !ok {
+			return
+		}
+		if !group.IsAdmin(user.ID) {
+			http.Error(w, "Only the member who bought the item or a group admin can change its price", http.StatusForbidden)
+			return
+		}
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	type updateResult struct {
+		Purchase models.PurchaseRecord `json:"purchase"`
+		Expense  *models.Expense       `json:"expense,omitempty"`
+	}
+	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		updated := updateResult{Purchase: purchase}
+		updated.Purchase.Reprice(request.Price)
+		_, err := config.DB.Collection("purchase_history").UpdateOne(
+			sessionContext,
+			bson.M{"_id": purchase.ID},
+			bson.M{"$set": bson.M{"price": updated.Purchase.Price, "shares": updated.Purchase.Shares}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if purchase.ExpenseID == nil {
+			return updated, nil
+		}
+
+		// The expense changes the balances, so conflict with settlements being checked against them
+		if _, err := config.DB.Collection("groups").UpdateOne(
+			sessionContext,
+			bson.M{"_id": user.GroupID},
+			bson.M{"$inc": bson.M{"ledger_version": 1}},
+		); err != nil {
+			return nil, err
+		}
+
+		var purchases []models.PurchaseRecord
+		cursor, err := config.DB.Collection("purchase_history").Find(sessionContext, bson.M{"expense_id": purchase.ExpenseID})
+		if err != nil {
+			return nil, err
+		}
+		if err := cursor.All(sessionContext, &purchases); err != nil {
+			return nil, err
+		}
+		amount, splits := models.PurchasesTotal(purchases)
+
+		var expense models.Expense
+		err = config.DB.Collection("expenses").FindOneAndUpdate(
+			sessionContext,
+			bson.M{"_id": purchase.ExpenseID},
+			bson.M{"$set": bson.M{"amount": amount, "splits": splits, "updated_at": time.Now()}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&expense)
+		if err != nil {
+			return nil, err
+		}
+		updated.Expense = &expense
+		return updated, nil
+	})
+
+	if err != nil {
+		log.Printf("Failed to update purchase %s: %v", purchase.ID.Hex(), err)
+		http.Error(w, "Failed to update purchase", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Purchase updated",
+		Data:    result,
+	})
+}
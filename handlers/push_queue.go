@@ -0,0 +1,102 @@
+// handlers/push_queue.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PushQueueHandler lists the 100 most recent queued pushes to the group's members, newest first.
+// The status query parameter picks pending, sent or dead pushes and defaults to dead, the pushes
+// that were given up on. Only group admins inspect the queue.
+func PushQueueHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	_, group, ok := getAdminGroup(w, r, "inspect the push queue")
+	if !ok {
+		return
+	}
+
+	status := models.QueuedPushDead
+	if value := r.URL.Query().Get("status"); value != "" {
+		status = models.QueuedPushStatus(value)
+		if !models.IsValidQueuedPushStatus(status) {
+			http.Error(w, "Invalid status", http.StatusBadRequest)
+			return
+		}
+	}
+
+	cursor, err := config.DB.Collection("push_queue").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "status": status},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(100),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch queued pushes", http.StatusInternalServerError)
+		return
+	}
+	pushes := make([]models.QueuedPush, 0)
+	if err := cursor.All(context.Background(), &pushes); err != nil {
+		http.Error(w, "Failed to decode queued pushes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pushes)
+}
+
+// QueuedPushHandler handles POST /api/groups/push-queue/{id}/retry, which queues a dead push
+// again with a fresh set of attempts. Only group admins manage the queue.
+func QueuedPushHandler(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := getAdminGroup(w, r, "inspect the push queue")
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/push-queue/"), "/"), "/")
+	pushID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) != 2 || parts[1] != "retry" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var push models.QueuedPush
+	err = config.DB.Collection("push_queue").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": pushID, "group_id": group.ID, "status": models.QueuedPushDead},
+		bson.M{
+			"$set":   bson.M{"status": models.QueuedPushPending, "attempts": 0, "next_attempt_at": time.Now()},
+			"$unset": bson.M{"dead_at": ""},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&push)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Dead push not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to retry push", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(push)
+}
@@ -0,0 +1,217 @@
+// handlers/quiet_hours.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LogViolationRequest defines the request structure for logging a quiet hours violation
+type LogViolationRequest struct {
+	OccurredAt *time.Time `json:"occurred_at,omitempty"` // Defaults to now
+	Note       string     `json:"note,omitempty"`
+}
+
+// QuietHoursViolationsHandler lists the group's quiet hours violations in a month, newest first,
+// on GET (?month=YYYY-MM, defaults to this month) and logs one on POST. Violations must fall
+// within the quiet hours in the group's settings.
+func QuietHoursViolationsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listViolations(w, r, group)
+	case http.MethodPost:
+		logViolation(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listViolations returns the violations logged for the month
+func listViolations(w http.ResponseWriter, r *http.Request, group models.Group) {
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+	start, end, err := models.MonthBounds(month)
+	if err != nil {
+		http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	cursor, err := config.DB.Collection("quiet_hours_violations").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "occurred_at": bson.M{"$gte": start, "$lt": end}},
+		options.Find().SetSort(bson.D{{Key: "occurred_at", Value: -1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch violations", http.StatusInternalServerError)
+		return
+	}
+	violations := make([]models.QuietHoursViolation, 0)
+	if err := cursor.All(context.Background(), &violations); err != nil {
+		http.Error(w, "Failed to decode violations", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(violations)
+}
+
+// logViolation records a violation the member noticed
+func logViolation(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request LogViolationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	occurredAt := now
+	if request.OccurredAt != nil {
+		occurredAt = *request.OccurredAt
+	}
+	if err := models.ValidateViolationTime(group.Settings.QuietHours, occurredAt, now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	violation := models.CreateQuietHoursViolation(group.ID, user.ID, occurredAt, request.Note)
+	result, err := config.DB.Collection("quiet_hours_violations").InsertOne(context.Background(), violation)
+	if err != nil {
+		log.Printf("Failed to log quiet hours violation: %v", err)
+		http.Error(w, "Failed to log violation", http.StatusInternalServerError)
+		return
+	}
+	violation.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(violation)
+}
+
+// QuietHoursViolationHandler handles DELETE /api/groups/quiet-hours/violations/{id}, which
+// removes a violation logged by mistake. Only the member who logged it and group admins can.
+func QuietHoursViolationHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	violationID, err := primitive.ObjectIDFromHex(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/quiet-hours/violations/"), "/"))
+	if err != nil {
+		http.Error(w, "Invalid violation ID", http.StatusBadRequest)
+		return
+	}
+	var violation models.QuietHoursViolation
+	err = config.DB.Collection("quiet_hours_violations").FindOne(context.Background(), bson.M{"_id": violationID, "group_id": group.ID}).Decode(&violation)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Violation not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch violation", http.StatusInternalServerError)
+		}
+		return
+	}
+	if violation.ReportedBy != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the member who logged the violation and group admins can remove it", http.StatusForbidden)
+		return
+	}
+
+	if _, err := config.DB.Collection("quiet_hours_violations").DeleteOne(context.Background(), bson.M{"_id": violation.ID}); err != nil {
+		http.Error(w, "Failed to remove violation", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetQuietHoursReportHandler summarizes the group's quiet hours violations in a month, with the
+// count from the month before for comparison. Query: ?month=YYYY-MM (defaults to this month)
+func GetQuietHoursReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	month := r.URL.Query().Get("month")
+	if month == "" {
+		month = time.Now().UTC().Format("2006-01")
+	}
+	start, end, err := models.MonthBounds(month)
+	if err != nil {
+		http.Error(w, "Invalid month format. Use YYYY-MM", http.StatusBadRequest)
+		return
+	}
+	previousStart := start.AddDate(0, -1, 0)
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("quiet_hours_violations").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID, "occurred_at": bson.M{"$gte": previousStart, "$lt": end}},
+		options.Find().SetSort(bson.D{{Key: "occurred_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch quiet hours violations: %v", err)
+		http.Error(w, "Failed to fetch violations", http.StatusInternalServerError)
+		return
+	}
+	violations := make([]models.QuietHoursViolation, 0)
+	if err := cursor.All(context.Background(), &violations); err != nil {
+		http.Error(w, "Failed to decode violations", http.StatusInternalServerError)
+		return
+	}
+
+	// Violations are newest first, so the month's come before the previous month's
+	split := len(violations)
+	for i, violation := range violations {
+		if violation.OccurredAt.Before(start) {
+			split = i
+			break
+		}
+	}
+	entries := violations[:split]
+
+	report := models.SummarizeQuietHoursViolations(month, group.Settings.QuietHours, entries)
+	report.PreviousViolations = len(violations) - split
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"report":     report,
+		"violations": entries,
+	})
+}
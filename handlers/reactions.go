@@ -0,0 +1,267 @@
+// handlers/reactions.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReactionRequest defines the request structure for reacting with an emoji
+type ReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+// CompletionDetails is a completed chore in the group's completions feed with its reactions
+type CompletionDetails struct {
+	ChoreID     primitive.ObjectID     `json:"chore_id"`
+	Title       string                 `json:"title"`
+	CompletedBy primitive.ObjectID     `json:"completed_by"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Points      int                    `json:"points"`
+	Reactions   []models.ReactionCount `json:"reactions"`
+}
+
+// reactionSummaries counts the reactions on each of the targets as the member sees them
+func reactionSummaries(ctx context.Context, groupID primitive.ObjectID, target models.ReactionTarget, targetIDs []primitive.ObjectID, userID primitive.ObjectID) (map[primitive.ObjectID][]models.ReactionCount, error) {
+	if len(targetIDs) == 0 {
+		return map[primitive.ObjectID][]models.ReactionCount{}, nil
+	}
+	cursor, err := config.DB.Collection("reactions").Find(ctx, bson.M{
+		"group_id":    groupID,
+		"target_type": target,
+		"target_id":   bson.M{"$in": targetIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+	reactions := make([]models.Reaction, 0)
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, err
+	}
+	return models.SummarizeReactions(reactions, userID), nil
+}
+
+// reactionsOn returns the reaction counts on one target, never nil so responses show an empty list
+func reactionsOn(ctx context.Context, groupID primitive.ObjectID, target models.ReactionTarget, targetID, userID primitive.ObjectID) []models.ReactionCount {
+	summaries, err := reactionSummaries(ctx, groupID, target, []primitive.ObjectID{targetID}, userID)
+	if err != nil {
+		log.Printf("Failed to count reactions on %s %s: %v", target, targetID.Hex(), err)
+	}
+	if counts := summaries[targetID]; counts != nil {
+		return counts
+	}
+	return []models.ReactionCount{}
+}
+
+// findReactionTarget checks that the completion or post exists in the group. Only completed chores
+// can be reacted to.
+func findReactionTarget(ctx context.Context, groupID primitive.ObjectID, target models.ReactionTarget, targetID primitive.ObjectID) error {
+	filter := bson.M{"_id": targetID, "group_id": groupID}
+	collection := "posts"
+	if target == models.ReactionTargetCompletion {
+		collection = "chores"
+		filter["status"] = models.ChoreStatusCompleted
+	}
+	return config.DB.Collection(collection).FindOne(ctx, filter, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+}
+
+// ReactionsHandler handles the reactions on a completion or post: GET
+// /api/groups/reactions/{completion|post}/{id} returns the counts, POST adds the member's emoji
+// and DELETE /api/groups/reactions/{completion|post}/{id}/{emoji} removes it. Completions are
+// identified by the completed chore's ID.
+func ReactionsHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/reactions/"), "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	target := models.ReactionTarget(parts[0])
+	if !models.IsValidReactionTarget(target) {
+		http.Error(w, "Reactions can be left on a completion or a post", http.StatusBadRequest)
+		return
+	}
+	targetID, err := primitive.ObjectIDFromHex(parts[1])
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid %s ID", target), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	if err := findReactionTarget(ctx, user.GroupID, target, targetID); err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments) && target == models.ReactionTargetCompletion:
+			http.Error(w, "Completed chore not found", http.StatusNotFound)
+		case errors.Is(err, mongo.ErrNoDocuments):
+			http.Error(w, "Post not found", http.StatusNotFound)
+		default:
+			http.Error(w, fmt.Sprintf("Failed to fetch %s", target), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && r.Method == http.MethodGet:
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		if !addReaction(w, r, user, target, targetID) {
+			return
+		}
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		_, err := config.DB.Collection("reactions").DeleteOne(ctx, bson.M{
+			"target_type": target,
+			"target_id":   targetID,
+			"user_id":     user.ID,
+			"emoji":       parts[2],
+		})
+		if err != nil {
+			http.Error(w, "Failed to remove reaction", http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reactionsOn(ctx, user.GroupID, target, targetID, user.ID))
+}
+
+// addReaction stores the member's emoji. Reacting twice with the same emoji changes nothing.
+func addReaction(w http.ResponseWriter, r *http.Request, user models.User, target models.ReactionTarget, targetID primitive.ObjectID) bool {
+	var request ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return false
+	}
+	request.Emoji = strings.TrimSpace(request.Emoji)
+	if err := models.ValidateReactionEmoji(request.Emoji); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+
+	ctx := context.Background()
+	collection := config.DB.Collection("reactions")
+	count, err := collection.CountDocuments(ctx, bson.M{"target_type": target, "target_id": targetID, "user_id": user.ID})
+	if err != nil {
+		http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+		return false
+	}
+	if count >= models.MaxReactionsPerMember {
+		writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("You can leave at most %d reactions on a %s", models.MaxReactionsPerMember, target))
+		return false
+	}
+
+	reaction := models.CreateReaction(user.GroupID, target, targetID, user.ID, request.Emoji)
+	if _, err := collection.InsertOne(ctx, reaction); err != nil && !mongo.IsDuplicateKeyError(err) {
+		log.Printf("Failed to add reaction: %v", err)
+		http.Error(w, "Failed to add reaction", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// GetCompletionsHandler lists the group's most recently completed chores with who completed them
+// and their reactions, so members can acknowledge each other's work. ?limit= and ?cursor= page
+// through them; the cursor of the next page is sent in the Next-Cursor header.
+func GetCompletionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "updated_at", Descending: true}, models.DefaultCompletionsPerFeed)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("chores").Find(ctx,
+		page.filter(bson.M{"group_id": user.GroupID, "status": models.ChoreStatusCompleted}),
+		page.findOptions(),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch completions", http.StatusInternalServerError)
+		return
+	}
+	chores := make([]models.Chore, 0, page.Limit+1)
+	if err := cursor.All(ctx, &chores); err != nil {
+		http.Error(w, "Failed to decode completions", http.StatusInternalServerError)
+		return
+	}
+	if page.hasMore(len(chores)) {
+		chores = chores[:page.Limit]
+		last := chores[page.Limit-1]
+		nextCursor(w, models.PageCursor{Key: last.UpdatedAt, ID: last.ID})
+	}
+	choreIDs := make([]primitive.ObjectID, 0, len(chores))
+	for _, chore := range chores {
+		choreIDs = append(choreIDs, chore.ID)
+	}
+
+	// Completion records carry who completed the chore and when
+	cursor, err = config.DB.Collection("chore_completions").Find(ctx, bson.M{"chore_id": bson.M{"$in": choreIDs}})
+	if err != nil {
+		http.Error(w, "Failed to fetch completions", http.StatusInternalServerError)
+		return
+	}
+	records := make([]models.ChoreCompletion, 0, len(chores))
+	if err := cursor.All(ctx, &records); err != nil {
+		http.Error(w, "Failed to decode completions", http.StatusInternalServerError)
+		return
+	}
+	completions := make(map[primitive.ObjectID]models.ChoreCompletion, len(records))
+	for _, record := range records {
+		if latest, ok := completions[record.ChoreID]; !ok || record.CompletedAt.After(latest.CompletedAt) {
+			completions[record.ChoreID] = record
+		}
+	}
+
+	reactions, err := reactionSummaries(ctx, user.GroupID, models.ReactionTargetCompletion, choreIDs, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch reactions", http.StatusInternalServerError)
+		return
+	}
+
+	feed := make([]CompletionDetails, 0, len(chores))
+	for _, chore := range chores {
+		details := CompletionDetails{
+			ChoreID:     chore.ID,
+			Title:       chore.Title,
+			CompletedBy: chore.AssignedTo,
+			CompletedAt: chore.UpdatedAt,
+			Points:      chore.Points,
+			Reactions:   reactions[chore.ID],
+		}
+		if record, ok := completions[chore.ID]; ok {
+			details.CompletedBy, details.CompletedAt, details.Points = record.UserID, record.CompletedAt, record.Points
+		}
+		if details.Reactions == nil {
+			details.Reactions = []models.ReactionCount{}
+		}
+		feed = append(feed, details)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
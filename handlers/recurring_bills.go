@@ -0,0 +1,335 @@
+// handlers/recurring_bills.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateRecurringBillRequest defines the request structure for setting up a recurring bill
+type CreateRecurringBillRequest struct {
+	Description  string                      `json:"description" validate:"required"`
+	Amount       float64                     `json:"amount" validate:"min=0"`
+	PaidBy       string                      `json:"paid_by,omitempty"`      // Username; defaults to the requesting user
+	Frequency    string                      `json:"frequency,omitempty"`    // weekly, biweekly or monthly (default)
+	DayOfMonth   int                         `json:"day_of_month,omitempty"` // 1-28 for monthly bills; defaults to today
+	StartDate    string                      `json:"start_date,omitempty"`   // First due date (YYYY-MM-DD) for weekly and biweekly bills; defaults to today
+	SplitMethod  string                      `json:"split_method,omitempty"` // equal (default), percentage or exact
+	Participants []ExpenseParticipantRequest `json:"participants,omitempty"` // Defaults to the whole group for equal splits
+	Category     string                      `json:"category,omitempty"`     // rent, groceries, utilities, fun or other (default)
+	ReminderDays *int                        `json:"reminder_days,omitempty"`
+
+	// UseRentConfig bills the group's rent, taking the amount and split from the rent configuration
+	// in effect on each due date instead of from the request
+	UseRentConfig bool `json:"use_rent_config,omitempty"`
+
+	// SplitByUsage splits each occurrence by the usage the members' electricity, gas or water
+	// meters counted over its billing period, falling back to the split above when readings are
+	// missing
+	SplitByUsage string `json:"split_by_usage,omitempty"`
+}
+
+// CreateRecurringBillHandler sets up an expense that the scheduler creates on every due date
+func CreateRecurringBillHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateRecurringBillRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Description = strings.TrimSpace(request.Description)
+	if request.Description == "" {
+		http.Error(w, "Description is required", http.StatusBadRequest)
+		return
+	}
+	request.Amount = models.RoundMoney(request.Amount)
+	if request.UseRentConfig {
+		if request.Amount != 0 || request.SplitMethod != "" || len(request.Participants) > 0 {
+			http.Error(w, "Rent bills take their amount and split from the rent configuration", http.StatusBadRequest)
+			return
+		}
+		if request.Category == "" {
+			request.Category = string(models.ExpenseCategoryRent)
+		}
+	} else if request.Amount <= 0 {
+		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		return
+	}
+
+	if request.SplitByUsage != "" {
+		if request.UseRentConfig {
+			http.Error(w, "Rent bills cannot be split by usage", http.StatusBadRequest)
+			return
+		}
+		if !models.IsValidMeterType(models.MeterType(request.SplitByUsage)) {
+			http.Error(w, "Split by usage must be electricity, gas or water", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if request.Frequency == "" {
+		request.Frequency = "monthly"
+	}
+	if !models.IsValidBillFrequency(request.Frequency) {
+		http.Error(w, "Invalid frequency. Must be weekly, biweekly, or monthly", http.StatusBadRequest)
+		return
+	}
+
+	method := models.SplitMethod(request.SplitMethod)
+	if method == "" {
+		method = models.SplitMethodEqual
+	}
+	if !models.IsValidSplitMethod(method) {
+		http.Error(w, "Split method must be equal, percentage or exact", http.StatusBadRequest)
+		return
+	}
+
+	category := models.ExpenseCategory(request.Category)
+	if category == "" {
+		category = models.DefaultExpenseCategory(models.ExpenseSourceRecurring)
+	}
+	if !models.IsValidExpenseCategory(category) {
+		http.Error(w, "Category must be rent, groceries, utilities, fun or other", http.StatusBadRequest)
+		return
+	}
+
+	reminderDays := models.DefaultBillReminderDays
+	if request.ReminderDays != nil {
+		reminderDays = *request.ReminderDays
+		if reminderDays < 0 || reminderDays > models.MaxBillReminderDays {
+			http.Error(w, fmt.Sprintf("Reminder days must be between 0 and %d", models.MaxBillReminderDays), http.StatusBadRequest)
+			return
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	firstDueDate := today
+	if request.Frequency == "monthly" {
+		if request.StartDate != "" {
+			http.Error(w, "Monthly bills are scheduled with day_of_month", http.StatusBadRequest)
+			return
+		}
+		day := request.DayOfMonth
+		if day == 0 {
+			day = min(today.Day(), 28)
+		}
+		if day < 1 || day > 28 {
+			http.Error(w, "Day of month must be between 1 and 28", http.StatusBadRequest)
+			return
+		}
+		firstDueDate = models.FirstBillDueDate(today, day)
+	} else {
+		if request.DayOfMonth != 0 {
+			http.Error(w, "Weekly bills are scheduled with start_date", http.StatusBadRequest)
+			return
+		}
+		if request.StartDate != "" {
+			startDate, err := time.Parse("2006-01-02", request.StartDate)
+			if err != nil {
+				http.Error(w, "Invalid start date format. Use YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			if startDate.Before(today) {
+				http.Error(w, "Start date cannot be in the past", http.StatusBadRequest)
+				return
+			}
+			firstDueDate = startDate
+		}
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	usernames := make([]string, 0, len(request.Participants)+1)
+	seen := make(map[string]bool)
+	for _, participant := range request.Participants {
+		if participant.Username == "" {
+			http.Error(w, "Every participant needs a username", http.StatusBadRequest)
+			return
+		}
+		if seen[participant.Username] {
+			http.Error(w, "Participant "+participant.Username+" is listed more than once", http.StatusBadRequest)
+			return
+		}
+		seen[participant.Username] = true
+		usernames = append(usernames, participant.Username)
+	}
+	if request.PaidBy != "" && !seen[request.PaidBy] {
+		usernames = append(usernames, request.PaidBy)
+	}
+
+	members, ok := findGroupMembersByUsername(w, group.ID, usernames)
+	if !ok {
+		return
+	}
+
+	paidBy := user.ID
+	if request.PaidBy != "" {
+		paidBy = members[request.PaidBy].ID
+	}
+
+	bill := models.CreateRecurringBill(group.ID, paidBy, request.Description, request.Amount, request.Frequency, firstDueDate)
+	bill.SplitMethod = method
+	bill.Category = category
+	bill.ReminderDays = reminderDays
+	bill.CreatedBy = user.ID
+	bill.UsageMeter = models.MeterType(request.SplitByUsage)
+	for _, participant := range request.Participants {
+		bill.Participants = append(bill.Participants, models.BillParticipant{
+			UserID:     members[participant.Username].ID,
+			Percentage: participant.Percentage,
+			Amount:     models.RoundMoney(participant.Amount),
+		})
+	}
+
+	if request.UseRentConfig {
+		configs, err := models.LoadRentConfigs(context.Background(), config.DB, group.ID)
+		if err != nil {
+			log.Printf("Failed to fetch rent configuration: %v", err)
+			http.Error(w, "Failed to fetch rent configuration", http.StatusInternalServerError)
+			return
+		}
+		// Show the rent of the first bill; the scheduler applies later changes on each due date
+		rentConfig := models.RentConfigAt(configs, firstDueDate)
+		if rentConfig == nil {
+			http.Error(w, "Set up the group's rent, effective by the first due date, before adding a rent bill", http.StatusBadRequest)
+			return
+		}
+		bill.UsesRentConfig = true
+		bill.ApplyRentConfig(*rentConfig)
+	}
+
+	// Check the split now rather than when the scheduler first creates the expense
+	if _, err := bill.Splits(group.Members); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := config.DB.Collection("recurring_bills").InsertOne(context.Background(), bill)
+	if err != nil {
+		log.Printf("Failed to create recurring bill: %v", err)
+		http.Error(w, "Failed to create recurring bill", http.StatusInternalServerError)
+		return
+	}
+	bill.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bill)
+}
+
+// GetRecurringBillsHandler lists the recurring bills of the requesting user's group, soonest due first
+func GetRecurringBillsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("recurring_bills").Find(
+		ctx,
+		bson.M{"group_id": user.GroupID},
+		options.Find().SetSort(bson.D{{Key: "next_due_date", Value: 1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch recurring bills: %v", err)
+		http.Error(w, "Failed to fetch recurring bills", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	bills := make([]models.RecurringBill, 0)
+	if err := cursor.All(ctx, &bills); err != nil {
+		http.Error(w, "Failed to decode recurring bills", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bills)
+}
+
+// DeleteRecurringBillHandler stops a recurring bill. Expenses already created from it are kept.
+// Only the member who set it up or a group admin can delete it. Query: ?bill_id={id}
+func DeleteRecurringBillHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	billID, err := primitive.ObjectIDFromHex(r.URL.Query().Get("bill_id"))
+	if err != nil {
+		http.Error(w, "Invalid bill ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var bill models.RecurringBill
+	err = config.DB.Collection("recurring_bills").FindOne(
+		context.Background(),
+		bson.M{"_id": billID, "group_id": user.GroupID},
+	).Decode(&bill)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Recurring bill not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch recurring bill", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if bill.CreatedBy != user.ID {
+		group, ok := getGroupByID(w, user.GroupID)
+		if !ok {
+			return
+		}
+		if !group.IsAdmin(user.ID) {
+			http.Error(w, "Only the member who set up the bill or a group admin can delete it", http.StatusForbidden)
+			return
+		}
+	}
+
+	if _, err := config.DB.Collection("recurring_bills").DeleteOne(context.Background(), bson.M{"_id": bill.ID}); err != nil {
+		log.Printf("Failed to delete recurring bill: %v", err)
+		http.Error(w, "Failed to delete recurring bill", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Recurring bill deleted successfully",
+	})
+}
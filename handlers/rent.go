@@ -0,0 +1,207 @@
+// handlers/rent.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateRentConfigRequest defines the request structure for changing how a group splits its rent
+type UpdateRentConfigRequest struct {
+	Amount float64 `json:"amount" validate:"required,min=0"` // Total rent per bill
+	Method string  `json:"method" validate:"required"`       // percentage or room_size
+	Shares []struct {
+		Username   string  `json:"username"`
+		Room       string  `json:"room,omitempty"`
+		Percentage float64 `json:"percentage,omitempty"`
+		RoomSize   float64 `json:"room_size,omitempty"`
+	} `json:"shares" validate:"required"`
+	EffectiveFrom string `json:"effective_from,omitempty"` // YYYY-MM-DD; defaults to today
+	Note          string `json:"note,omitempty"`           // Why the rent changed, for later proration questions
+}
+
+// GetRentConfigHandler returns the group's rent configuration in effect today
+func GetRentConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	configs, err := models.LoadRentConfigs(context.Background(), config.DB, group.ID)
+	if err != nil {
+		log.Printf("Failed to fetch rent configuration: %v", err)
+		http.Error(w, "Failed to fetch rent configuration", http.StatusInternalServerError)
+		return
+	}
+	current := models.RentConfigAt(configs, time.Now())
+	if current == nil {
+		http.Error(w, "The group has not set up its rent", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(current)
+}
+
+// UpdateRentConfigHandler records a new version of the group's rent configuration. Earlier
+// versions are kept, so expenses already created and the history behind them are unaffected.
+// Only group admins can change the rent.
+func UpdateRentConfigHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can change the rent", http.StatusForbidden)
+		return
+	}
+
+	var request UpdateRentConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	effectiveFrom := time.Now().UTC().Truncate(24 * time.Hour)
+	if request.EffectiveFrom != "" {
+		date, err := time.Parse("2006-01-02", request.EffectiveFrom)
+		if err != nil {
+			http.Error(w, "Invalid effective date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		effectiveFrom = date
+	}
+
+	usernames := make([]string, 0, len(request.Shares))
+	for _, share := range request.Shares {
+		if share.Username == "" {
+			http.Error(w, "Every share needs a username", http.StatusBadRequest)
+			return
+		}
+		usernames = append(usernames, share.Username)
+	}
+	members, ok := findGroupMembersByUsername(w, group.ID, usernames)
+	if !ok {
+		return
+	}
+
+	rentConfig := models.RentConfig{
+		GroupID:       group.ID,
+		Amount:        request.Amount,
+		Method:        models.RentSplitMethod(request.Method),
+		Shares:        make([]models.RentShare, 0, len(request.Shares)),
+		EffectiveFrom: effectiveFrom,
+		Note:          strings.TrimSpace(request.Note),
+		UpdatedBy:     user.ID,
+		UpdatedByName: user.Name,
+		CreatedAt:     time.Now(),
+	}
+	for _, share := range request.Shares {
+		member := members[share.Username]
+		rentConfig.Shares = append(rentConfig.Shares, models.RentShare{
+			UserID:     member.ID,
+			Name:       member.Name,
+			Room:       strings.TrimSpace(share.Room),
+			Percentage: share.Percentage,
+			RoomSize:   share.RoomSize,
+		})
+	}
+	if err := rentConfig.ComputeShares(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Number the version after the latest; two admins saving at once collide on the unique index
+	ctx := context.Background()
+	var latest models.RentConfig
+	err := config.DB.Collection("rent_configs").FindOne(
+		ctx,
+		bson.M{"group_id": group.ID},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+	).Decode(&latest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Failed to fetch rent configuration: %v", err)
+		http.Error(w, "Failed to update rent", http.StatusInternalServerError)
+		return
+	}
+	rentConfig.Version = latest.Version + 1
+
+	result, err := config.DB.Collection("rent_configs").InsertOne(ctx, rentConfig)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeEditConflict, "The rent was changed at the same time, please try again")
+			return
+		}
+		log.Printf("Failed to save rent configuration: %v", err)
+		http.Error(w, "Failed to update rent", http.StatusInternalServerError)
+		return
+	}
+	rentConfig.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rentConfig)
+}
+
+// GetRentHistoryHandler lists every version of the group's rent configuration, newest first, with
+// what each member paid under it. Path format: /api/rent/history
+func GetRentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	configs, err := models.LoadRentConfigs(context.Background(), config.DB, group.ID)
+	if err != nil {
+		log.Printf("Failed to fetch rent history: %v", err)
+		http.Error(w, "Failed to fetch rent history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(configs)
+}
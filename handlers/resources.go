@@ -0,0 +1,350 @@
+// handlers/resources.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateResourceRequest defines the request structure for adding a bookable resource
+type CreateResourceRequest struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"` // laundry, parking, bathroom or other (default)
+	SlotMinutes int    `json:"slot_minutes"`
+	OpenFrom    string `json:"open_from,omitempty"`     // HH:MM; leave both open_from and open_until empty for always open
+	OpenUntil   string `json:"open_until,omitempty"`    // HH:MM
+	TimeZone    string `json:"time_zone,omitempty"`     // IANA name such as America/New_York; defaults to UTC
+	SlotsAtOnce int    `json:"slots_at_once,omitempty"` // Defaults to 1
+}
+
+// BookResourceRequest defines the request structure for booking a resource
+type BookResourceRequest struct {
+	StartsAt time.Time `json:"starts_at"`
+	Slots    int       `json:"slots,omitempty"` // Consecutive slots to book; defaults to 1
+	Note     string    `json:"note,omitempty"`
+}
+
+// ResourcesHandler lists the group's bookable resources on GET and adds one on POST, which only
+// group admins can do
+func ResourcesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		user, ok := getRequestUser(w, r)
+		if !ok {
+			return
+		}
+		listResources(w, user.GroupID)
+	case http.MethodPost:
+		user, group, ok := getAdminGroup(w, r, "add bookable resources")
+		if !ok {
+			return
+		}
+		createResource(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listResources returns the group's resources by name
+func listResources(w http.ResponseWriter, groupID primitive.ObjectID) {
+	cursor, err := config.DB.Collection("resources").Find(
+		context.Background(),
+		bson.M{"group_id": groupID},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch resources", http.StatusInternalServerError)
+		return
+	}
+	resources := make([]models.BookableResource, 0)
+	if err := cursor.All(context.Background(), &resources); err != nil {
+		http.Error(w, "Failed to decode resources", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resources)
+}
+
+// createResource adds a resource to the group
+func createResource(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreateResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	resource := models.BookableResource{
+		GroupID:     group.ID,
+		Name:        strings.TrimSpace(request.Name),
+		Kind:        models.ResourceOther,
+		SlotMinutes: request.SlotMinutes,
+		OpenFrom:    request.OpenFrom,
+		OpenUntil:   request.OpenUntil,
+		TimeZone:    request.TimeZone,
+		SlotsAtOnce: models.DefaultResourceSlotsAtOnce,
+		CreatedBy:   user.ID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if request.Kind != "" {
+		resource.Kind = models.ResourceKind(request.Kind)
+	}
+	if request.SlotsAtOnce != 0 {
+		resource.SlotsAtOnce = request.SlotsAtOnce
+	}
+	if err := resource.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := context.Background()
+	count, err := config.DB.Collection("resources").CountDocuments(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		http.Error(w, "Failed to count resources", http.StatusInternalServerError)
+		return
+	}
+	if count >= models.MaxResourcesPerGroup {
+		writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("A group can have at most %d bookable resources", models.MaxResourcesPerGroup))
+		return
+	}
+
+	result, err := config.DB.Collection("resources").InsertOne(ctx, resource)
+	if err != nil {
+		log.Printf("Failed to create resource: %v", err)
+		http.Error(w, "Failed to create resource", http.StatusInternalServerError)
+		return
+	}
+	resource.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resource)
+}
+
+// ResourceHandler handles one of the group's resources: DELETE /api/groups/resources/{id} removes
+// it along with its upcoming bookings, which only group admins can do,
+// /api/groups/resources/{id}/bookings lists bookings on GET and books slots on POST, and DELETE
+// /api/groups/resources/{id}/bookings/{bookingID} cancels a booking.
+func ResourceHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/resources/"), "/"), "/")
+	resourceID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 3 {
+		http.Error(w, "Invalid resource ID", http.StatusBadRequest)
+		return
+	}
+	var resource models.BookableResource
+	err = config.DB.Collection("resources").FindOne(context.Background(), bson.M{"_id": resourceID, "group_id": group.ID}).Decode(&resource)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Resource not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch resource", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) >= 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodDelete:
+		deleteResource(w, user, group, resource)
+	case action == "bookings" && len(parts) == 2 && r.Method == http.MethodGet:
+		listBookings(w, r, resource)
+	case action == "bookings" && len(parts) == 2 && r.Method == http.MethodPost:
+		bookResource(w, r, user, resource)
+	case action == "bookings" && len(parts) == 3 && r.Method == http.MethodDelete:
+		cancelBooking(w, user, group, resource, parts[2])
+	case action == "" || action == "bookings":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// deleteResource removes the resource and its bookings, telling members whose upcoming bookings
+// were dropped
+func deleteResource(w http.ResponseWriter, user models.User, group models.Group, resource models.BookableResource) {
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can remove bookable resources", http.StatusForbidden)
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("resource_bookings").Find(ctx, bson.M{"resource_id": resource.ID, "ends_at": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		http.Error(w, "Failed to fetch bookings", http.StatusInternalServerError)
+		return
+	}
+	var upcoming []models.ResourceBooking
+	if err := cursor.All(ctx, &upcoming); err != nil {
+		http.Error(w, "Failed to decode bookings", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := config.DB.Collection("resources").DeleteOne(ctx, bson.M{"_id": resource.ID}); err != nil {
+		http.Error(w, "Failed to remove resource", http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Collection("resource_bookings").DeleteMany(ctx, bson.M{"resource_id": resource.ID}); err != nil {
+		log.Printf("Failed to remove bookings of resource %s: %v", resource.ID.Hex(), err)
+	}
+
+	notifications := make([]*models.Notification, 0, len(upcoming))
+	for _, booking := range upcoming {
+		if booking.UserID != user.ID {
+			notifications = append(notifications, models.BookingCancelledNotification(booking, resource, ""))
+		}
+	}
+	insertNotifications(ctx, notifications)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listBookings returns the resource's bookings overlapping the from and to query parameters
+// (RFC 3339), which default to now and a week from now
+func listBookings(w http.ResponseWriter, r *http.Request, resource models.BookableResource) {
+	from := time.Now()
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			http.Error(w, "Invalid from time", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+	to := from.Add(7 * 24 * time.Hour)
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil || !parsed.After(from) {
+			http.Error(w, "Invalid to time", http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+
+	cursor, err := config.DB.Collection("resource_bookings").Find(
+		context.Background(),
+		bson.M{"resource_id": resource.ID, "starts_at": bson.M{"$lt": to}, "ends_at": bson.M{"$gt": from}},
+		options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch bookings", http.StatusInternalServerError)
+		return
+	}
+	bookings := make([]models.ResourceBooking, 0)
+	if err := cursor.All(context.Background(), &bookings); err != nil {
+		http.Error(w, "Failed to decode bookings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bookings)
+}
+
+// bookResource reserves consecutive slots of the resource for the member. The unique index on
+// the resource and its slots turns away bookings that overlap one already made.
+func bookResource(w http.ResponseWriter, r *http.Request, user models.User, resource models.BookableResource) {
+	var request BookResourceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if request.Slots == 0 {
+		request.Slots = 1
+	}
+	now := time.Now()
+	switch {
+	case request.StartsAt.IsZero():
+		http.Error(w, "Start time is required", http.StatusBadRequest)
+		return
+	case request.StartsAt.Before(now):
+		http.Error(w, "Bookings cannot start in the past", http.StatusBadRequest)
+		return
+	case request.StartsAt.After(now.Add(models.MaxResourceBookingAhead)):
+		http.Error(w, fmt.Sprintf("Bookings can be made at most %d days ahead", int(models.MaxResourceBookingAhead.Hours()/24)), http.StatusBadRequest)
+		return
+	}
+	slots, err := resource.BookingSlots(request.StartsAt, request.Slots)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking := models.CreateResourceBooking(resource, user.ID, slots, request.Note)
+	result, err := config.DB.Collection("resource_bookings").InsertOne(context.Background(), booking)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			http.Error(w, resource.Name+" is already booked for some of that time", http.StatusConflict)
+			return
+		}
+		log.Printf("Failed to book resource: %v", err)
+		http.Error(w, "Failed to book resource", http.StatusInternalServerError)
+		return
+	}
+	booking.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(booking)
+}
+
+// cancelBooking removes a booking, which the member who made it and group admins can do. The
+// member is told when someone else cancels their booking.
+func cancelBooking(w http.ResponseWriter, user models.User, group models.Group, resource models.BookableResource, id string) {
+	bookingID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+	ctx := context.Background()
+	var booking models.ResourceBooking
+	err = config.DB.Collection("resource_bookings").FindOne(ctx, bson.M{"_id": bookingID, "resource_id": resource.ID}).Decode(&booking)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Booking not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch booking", http.StatusInternalServerError)
+		}
+		return
+	}
+	if booking.UserID != user.ID && !group.IsAdmin(user.ID) {
+		http.Error(w, "Only the member who made the booking and group admins can cancel it", http.StatusForbidden)
+		return
+	}
+
+	if _, err := config.DB.Collection("resource_bookings").DeleteOne(ctx, bson.M{"_id": booking.ID}); err != nil {
+		http.Error(w, "Failed to cancel booking", http.StatusInternalServerError)
+		return
+	}
+	if booking.UserID != user.ID && booking.EndsAt.After(time.Now()) {
+		insertNotifications(ctx, []*models.Notification{models.BookingCancelledNotification(booking, resource, user.Name)})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
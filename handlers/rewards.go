@@ -0,0 +1,443 @@
+// handlers/rewards.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	errRewardUnavailable    = errors.New("reward is no longer available")
+	errRedemptionNotFound   = errors.New("redemption not found")
+	errRedemptionNotInGroup = errors.New("redemption does not belong to this group")
+)
+
+// CreateRewardRequest defines the request structure for adding a reward
+type CreateRewardRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Cost        int    `json:"cost"`
+}
+
+// CreateRewardHandler lets a group admin define a reward members can buy with points
+func CreateRewardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateRewardRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Name == "" || request.Cost < 1 {
+		http.Error(w, "Name and a cost of at least 1 point are required", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can manage rewards", http.StatusForbidden)
+		return
+	}
+
+	reward := models.CreateReward(group.ID, user.ID, request.Name, request.Description, request.Cost)
+	result, err := config.DB.Collection("rewards").InsertOne(context.Background(), reward)
+	if err != nil {
+		log.Printf("Reward creation error: %v", err)
+		http.Error(w, "Failed to create reward", http.StatusInternalServerError)
+		return
+	}
+	reward.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reward)
+}
+
+// GetRewardsHandler lists the active rewards of the requesting user's group
+func GetRewardsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	cursor, err := config.DB.Collection("rewards").Find(
+		context.Background(),
+		bson.M{"group_id": user.GroupID, "is_active": true},
+		options.Find().SetSort(bson.D{{Key: "cost", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch rewards", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	rewards := make([]models.Reward, 0)
+	if err := cursor.All(context.Background(), &rewards); err != nil {
+		http.Error(w, "Failed to decode rewards", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rewards)
+}
+
+// DeleteRewardHandler lets a group admin retire a reward. Existing redemptions are kept.
+func DeleteRewardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rewardID, err := primitive.ObjectIDFromHex(r.URL.Query().Get("reward_id"))
+	if err != nil {
+		http.Error(w, "Invalid reward ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can manage rewards", http.StatusForbidden)
+		return
+	}
+
+	result, err := config.DB.Collection("rewards").UpdateOne(
+		context.Background(),
+		bson.M{"_id": rewardID, "group_id": group.ID},
+		bson.M{"$set": bson.M{"is_active": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Reward deletion error: %v", err)
+		http.Error(w, "Failed to delete reward", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "Reward not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Reward deleted successfully",
+	})
+}
+
+// RedeemRewardRequest defines the request structure for redeeming a reward
+type RedeemRewardRequest struct {
+	RewardID string `json:"reward_id"`
+}
+
+// RedeemRewardHandler spends the requesting user's points on a reward, pending admin approval
+func RedeemRewardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request RedeemRewardRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rewardID, err := primitive.ObjectIDFromHex(request.RewardID)
+	if err != nil {
+		http.Error(w, "Invalid reward ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	// Deduct the points and record the redemption together
+	result, err := session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		var reward models.Reward
+		err := config.DB.Collection("rewards").FindOne(
+			sc,
+			bson.M{"_id": rewardID, "group_id": user.GroupID, "is_active": true},
+		).Decode(&reward)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, errRewardUnavailable
+			}
+			return nil, err
+		}
+
+		// Only deduct if the user can afford it at this moment, so that redemptions racing for
+		// the same points cannot both succeed
+		var owner models.User
+		err = config.DB.Collection("users").FindOneAndUpdate(
+			sc,
+			bson.M{"_id": user.ID, "score": bson.M{"$gte": reward.Cost}},
+			bson.M{
+				"$inc": bson.M{"score": -reward.Cost},
+				"$set": bson.M{"updated_at": time.Now()},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.Before),
+		).Decode(&owner)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, models.ErrInsufficientPoints
+			}
+			return nil, err
+		}
+
+		// Apply the stored deduction to the score it was made from, for the redemption and its event
+		redemption, event, err := reward.Redeem(&owner)
+		if err != nil {
+			return nil, err
+		}
+		insertResult, err := config.DB.Collection("redemptions").InsertOne(sc, redemption)
+		if err != nil {
+			return nil, err
+		}
+		redemption.ID = insertResult.InsertedID.(primitive.ObjectID)
+
+		event.ReferenceID = &redemption.ID
+		if err := jobs.RecordScoreEvent(sc, event); err != nil {
+			return nil, err
+		}
+
+		return redemption, nil
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errRewardUnavailable):
+			http.Error(w, "Reward not found", http.StatusNotFound)
+		case errors.Is(err, models.ErrInsufficientPoints):
+			writeError(w, r, apierror.CodeInsufficientPoints, err.Error())
+		default:
+			log.Printf("Reward redemption failed: %v", err)
+			http.Error(w, "Failed to redeem reward", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ReviewRedemptionRequest defines the request structure for approving or rejecting a redemption
+type ReviewRedemptionRequest struct {
+	RedemptionID string `json:"redemption_id"`
+	Approve      bool   `json:"approve"`
+	Note         string `json:"note"`
+}
+
+// ReviewRedemptionHandler lets a group admin approve or reject a pending redemption.
+// Rejected redemptions are refunded.
+func ReviewRedemptionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ReviewRedemptionRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	redemptionID, err := primitive.ObjectIDFromHex(request.RedemptionID)
+	if err != nil {
+		http.Error(w, "Invalid redemption ID format", http.StatusBadRequest)
+		return
+	}
+
+	reviewer, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, reviewer.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(reviewer.ID) {
+		http.Error(w, "Only group admins can review redemptions", http.StatusForbidden)
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	result, err := session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		var redemption models.Redemption
+		err := config.DB.Collection("redemptions").FindOne(sc, bson.M{"_id": redemptionID}).Decode(&redemption)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, errRedemptionNotFound
+			}
+			return nil, err
+		}
+		if redemption.GroupID != group.ID {
+			return nil, errRedemptionNotInGroup
+		}
+
+		now := time.Now()
+		if err := redemption.Review(reviewer.ID, len(group.Members), request.Approve, request.Note, now); err != nil {
+			return nil, err
+		}
+
+		updateResult, err := config.DB.Collection("redemptions").UpdateOne(
+			sc,
+			bson.M{"_id": redemption.ID, "status": models.RedemptionStatusPending},
+			bson.M{"$set": bson.M{
+				"status":      redemption.Status,
+				"reviewed_by": redemption.ReviewedBy,
+				"reviewed_at": redemption.ReviewedAt,
+				"note":        redemption.Note,
+			}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		// Another admin reviewed it first; refunding again would pay the points back twice
+		if updateResult.MatchedCount == 0 {
+			return nil, models.ErrRedemptionNotPending
+		}
+
+		if redemption.Status == models.RedemptionStatusRejected {
+			var owner models.User
+			err = config.DB.Collection("users").FindOneAndUpdate(
+				sc,
+				bson.M{"_id": redemption.UserID},
+				bson.M{
+					"$inc": bson.M{"score": redemption.Cost},
+					"$set": bson.M{"updated_at": now},
+				},
+				options.FindOneAndUpdate().SetReturnDocument(options.Before),
+			).Decode(&owner)
+			if err != nil {
+				return nil, err
+			}
+
+			if event := redemption.Refund(&owner); event != nil {
+				if err := jobs.RecordScoreEvent(sc, event); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		return redemption, nil
+	})
+
+	if err != nil {
+		switch {
+		case errors.Is(err, errRedemptionNotFound):
+			http.Error(w, "Redemption not found", http.StatusNotFound)
+		case errors.Is(err, errRedemptionNotInGroup), errors.Is(err, models.ErrRedemptionSelfReview):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, models.ErrRedemptionNotPending):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("Redemption review failed: %v", err)
+			http.Error(w, "Failed to review redemption", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetRedemptionsHandler returns the redemption history of the group, or only the requesting user's with mine=true
+func GetRedemptionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	if r.URL.Query().Get("mine") == "true" {
+		filter["user_id"] = user.ID
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter["status"] = status
+	}
+
+	cursor, err := config.DB.Collection("redemptions").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch redemptions", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	redemptions := make([]models.Redemption, 0)
+	if err := cursor.All(context.Background(), &redemptions); err != nil {
+		http.Error(w, "Failed to decode redemptions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(redemptions)
+}
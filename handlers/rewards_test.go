@@ -0,0 +1,285 @@
+// handlers/rewards_test.go
+package handlers_test
+
+import (
+	"bytes"
+	"cribb-backend/apierror"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"cribb-backend/test"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// setupRewardTest creates a group with an admin and a member holding 10 points, and a reward costing 8
+func setupRewardTest() (*test.TestDB, models.User, models.User, models.Reward) {
+	testDB := test.NewTestDB()
+
+	testGroup := test.CreateTestGroup()
+	admin := test.CreateTestUser()
+	admin.Username = "admin"
+	admin.GroupID = testGroup.ID
+	member := test.CreateTestUser()
+	member.ID = primitive.NewObjectID()
+	member.Username = "member"
+	member.GroupID = testGroup.ID
+	member.Score = 10
+	testGroup.Members = []primitive.ObjectID{admin.ID, member.ID}
+	testGroup.Admins = []primitive.ObjectID{admin.ID}
+
+	reward := *models.CreateReward(testGroup.ID, admin.ID, "Skip a chore", "", 8)
+	reward.ID = primitive.NewObjectID()
+
+	testDB.AddGroup(testGroup)
+	testDB.AddUser(admin)
+	testDB.AddUser(member)
+	testDB.AddReward(reward)
+	return testDB, admin, member, reward
+}
+
+// redeemRewardHandler simulates RedeemRewardHandler against the test database
+func redeemRewardHandler(testDB *test.TestDB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			RewardID string `json:"reward_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		rewardID, err := primitive.ObjectIDFromHex(request.RewardID)
+		if err != nil {
+			http.Error(w, "Invalid reward ID format", http.StatusBadRequest)
+			return
+		}
+		userClaims, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID, _ := primitive.ObjectIDFromHex(userClaims.ID)
+
+		redemption, err := testDB.RedeemReward(userID, rewardID)
+		if err != nil {
+			switch {
+			case errors.Is(err, test.ErrNotFound):
+				http.Error(w, "Reward not found", http.StatusNotFound)
+			case errors.Is(err, models.ErrInsufficientPoints):
+				middleware.WriteError(w, r, apierror.New(apierror.CodeInsufficientPoints, err.Error()))
+			default:
+				http.Error(w, "Failed to redeem reward", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(redemption)
+	}
+}
+
+// reviewRedemptionHandler simulates ReviewRedemptionHandler against the test database
+func reviewRedemptionHandler(testDB *test.TestDB) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			RedemptionID string `json:"redemption_id"`
+			Approve      bool   `json:"approve"`
+			Note         string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		redemptionID, err := primitive.ObjectIDFromHex(request.RedemptionID)
+		if err != nil {
+			http.Error(w, "Invalid redemption ID format", http.StatusBadRequest)
+			return
+		}
+		userClaims, ok := middleware.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		reviewerID, _ := primitive.ObjectIDFromHex(userClaims.ID)
+
+		redemption, err := testDB.ReviewRedemption(redemptionID, reviewerID, request.Approve, request.Note)
+		if err != nil {
+			switch {
+			case errors.Is(err, test.ErrNotFound):
+				http.Error(w, "Redemption not found", http.StatusNotFound)
+			case errors.Is(err, models.ErrRedemptionSelfReview):
+				http.Error(w, err.Error(), http.StatusForbidden)
+			case errors.Is(err, models.ErrRedemptionNotPending):
+				http.Error(w, err.Error(), http.StatusConflict)
+			default:
+				http.Error(w, "Failed to review redemption", http.StatusInternalServerError)
+			}
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(redemption)
+	}
+}
+
+func serveAs(handler http.Handler, user models.User, body interface{}) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/api/rewards", bytes.NewBuffer(reqBody))
+	req = req.WithContext(createAuthContext(user.ID.Hex(), user.Username))
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestRedeemRewardConcurrently(t *testing.T) {
+	testDB, _, member, reward := setupRewardTest()
+	handler := redeemRewardHandler(testDB)
+
+	// 10 points only cover one redemption of the reward costing 8
+	const attempts = 5
+	codes := make(chan *httptest.ResponseRecorder, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serveAs(handler, member, map[string]string{"reward_id": reward.ID.Hex()})
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	created, refused := 0, 0
+	for rr := range codes {
+		switch rr.Code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			refused++
+			if code := rr.Header().Get(apierror.CodeHeader); code != string(apierror.CodeInsufficientPoints) {
+				t.Errorf("Expected error code %s, got %q", apierror.CodeInsufficientPoints, code)
+			}
+		default:
+			t.Errorf("Unexpected status %d: %s", rr.Code, rr.Body.String())
+		}
+	}
+	if created != 1 || refused != attempts-1 {
+		t.Errorf("Expected 1 redemption and %d refusals, got %d and %d", attempts-1, created, refused)
+	}
+
+	user, _ := testDB.FindUserByID(member.ID)
+	if user.Score != 2 {
+		t.Errorf("Expected score 2 after a single deduction, got %d", user.Score)
+	}
+	if len(testDB.Redemptions) != 1 {
+		t.Errorf("Expected 1 stored redemption, got %d", len(testDB.Redemptions))
+	}
+	events := testDB.GetScoreEventsForUser(member.ID)
+	if len(events) != 1 || events[0].Type != models.ScoreEventRedemption || events[0].Delta != -8 {
+		t.Errorf("Expected a single redemption score event, got %+v", events)
+	}
+}
+
+func TestRedeemRewardInsufficientPoints(t *testing.T) {
+	testDB, admin, _, reward := setupRewardTest()
+	handler := redeemRewardHandler(testDB)
+
+	// The admin has the 10 points of the test user too, so lower them below the cost
+	admin.Score = 7
+	testDB.UpdateUser(admin)
+
+	rr := serveAs(handler, admin, map[string]string{"reward_id": reward.ID.Hex()})
+	if rr.Code != http.StatusConflict || rr.Header().Get(apierror.CodeHeader) != string(apierror.CodeInsufficientPoints) {
+		t.Errorf("Expected 409 %s, got %d %q", apierror.CodeInsufficientPoints, rr.Code, rr.Header().Get(apierror.CodeHeader))
+	}
+
+	user, _ := testDB.FindUserByID(admin.ID)
+	if user.Score != 7 {
+		t.Errorf("Expected score to stay 7, got %d", user.Score)
+	}
+	if len(testDB.Redemptions) != 0 || len(testDB.ScoreEvents) != 0 {
+		t.Errorf("Expected no redemption or score event, got %d and %d", len(testDB.Redemptions), len(testDB.ScoreEvents))
+	}
+}
+
+func TestReviewRedemptionRejectRefunds(t *testing.T) {
+	testDB, admin, member, reward := setupRewardTest()
+	redeem := redeemRewardHandler(testDB)
+	review := reviewRedemptionHandler(testDB)
+
+	rr := serveAs(redeem, member, map[string]string{"reward_id": reward.ID.Hex()})
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("Expected redemption to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var redemption models.Redemption
+	json.NewDecoder(rr.Body).Decode(&redemption)
+
+	// Members cannot review their own redemption
+	rr = serveAs(review, member, map[string]interface{}{"redemption_id": redemption.ID.Hex(), "approve": true})
+	if rr.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a self review, got %d", rr.Code)
+	}
+
+	rr = serveAs(review, admin, map[string]interface{}{"redemption_id": redemption.ID.Hex(), "approve": false, "note": "Not this week"})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected rejection to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var reviewed models.Redemption
+	json.NewDecoder(rr.Body).Decode(&reviewed)
+	if reviewed.Status != models.RedemptionStatusRejected || reviewed.ReviewedBy == nil || *reviewed.ReviewedBy != admin.ID {
+		t.Errorf("Unexpected reviewed redemption %+v", reviewed)
+	}
+
+	user, _ := testDB.FindUserByID(member.ID)
+	if user.Score != 10 {
+		t.Errorf("Expected the points to be refunded to 10, got %d", user.Score)
+	}
+	events := testDB.GetScoreEventsForUser(member.ID)
+	if len(events) != 2 {
+		t.Fatalf("Expected redemption and refund score events, got %+v", events)
+	}
+	refund := events[1]
+	if refund.Type != models.ScoreEventRefund || refund.Delta != 8 || refund.ScoreAfter != 10 {
+		t.Errorf("Unexpected refund event %+v", refund)
+	}
+	if refund.ReferenceID == nil || *refund.ReferenceID != redemption.ID {
+		t.Errorf("Expected the refund event to refer to the redemption")
+	}
+
+	// Reviewing again must not refund twice
+	rr = serveAs(review, admin, map[string]interface{}{"redemption_id": redemption.ID.Hex(), "approve": false})
+	if rr.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for a second review, got %d", rr.Code)
+	}
+	user, _ = testDB.FindUserByID(member.ID)
+	if user.Score != 10 || len(testDB.GetScoreEventsForUser(member.ID)) != 2 {
+		t.Errorf("Expected no second refund, score %d", user.Score)
+	}
+}
+
+func TestReviewRedemptionApproveKeepsPoints(t *testing.T) {
+	testDB, admin, member, reward := setupRewardTest()
+
+	redemption, err := testDB.RedeemReward(member.ID, reward.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := serveAs(reviewRedemptionHandler(testDB), admin, map[string]interface{}{"redemption_id": redemption.ID.Hex(), "approve": true})
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected approval to succeed, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	user, _ := testDB.FindUserByID(member.ID)
+	if user.Score != 2 {
+		t.Errorf("Expected approved points to stay spent, got score %d", user.Score)
+	}
+	if events := testDB.GetScoreEventsForUser(member.ID); len(events) != 1 {
+		t.Errorf("Expected only the redemption score event, got %+v", events)
+	}
+}
@@ -0,0 +1,282 @@
+// handlers/score.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/jobs"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetScoreHistoryHandler returns the score audit trail of a user in the requester's group
+func GetScoreHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Path format: /api/users/{id}/score-history
+	path := strings.TrimPrefix(r.URL.Path, "/api/users/")
+	if !strings.HasSuffix(path, "/score-history") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/score-history"))
+	if err != nil {
+		http.Error(w, "Invalid user ID format", http.StatusBadRequest)
+		return
+	}
+
+	query, err := models.ParseScoreHistoryQuery(r.URL.Query().Get("limit"), r.URL.Query().Get("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var target models.User
+	err = config.DB.Collection("users").FindOne(context.Background(), bson.M{"_id": userID}).Decode(&target)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "User not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if !models.CanViewScoreHistory(&requester, &target) {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	cursor, err := config.DB.Collection("score_events").Find(
+		context.Background(),
+		query.Filter(target.ID),
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(query.Limit),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch score history", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	events := make([]models.ScoreEvent, 0)
+	if err := cursor.All(context.Background(), &events); err != nil {
+		http.Error(w, "Failed to decode score history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": target.ID,
+		"score":   target.Score,
+		"events":  events,
+	})
+}
+
+// AdjustScoreRequest defines the request structure for adjusting a member's score
+type AdjustScoreRequest struct {
+	Username string `json:"username"`
+	Delta    int    `json:"delta"`
+	Reason   string `json:"reason"`
+}
+
+// AdjustScoreHandler lets a group admin correct a member's score, recording the reason in the audit trail
+func AdjustScoreHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request AdjustScoreRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.Username == "" || request.Delta == 0 || strings.TrimSpace(request.Reason) == "" {
+		http.Error(w, "Username, a non-zero delta, and a reason are required", http.StatusBadRequest)
+		return
+	}
+
+	admin, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	group, ok := getGroupByID(w, admin.GroupID)
+	if !ok {
+		return
+	}
+
+	if !group.IsAdmin(admin.ID) {
+		http.Error(w, "Only group admins can adjust scores", http.StatusForbidden)
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	result, err := session.WithTransaction(context.Background(), func(sc mongo.SessionContext) (interface{}, error) {
+		var updatedUser models.User
+		err := config.DB.Collection("users").FindOneAndUpdate(
+			sc,
+			bson.M{"username": request.Username, "group_id": group.ID},
+			bson.M{
+				"$inc": bson.M{"score": request.Delta},
+				"$set": bson.M{"updated_at": time.Now()},
+			},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updatedUser)
+		if err != nil {
+			return nil, err
+		}
+
+		event := models.CreateScoreEvent(updatedUser.ID, group.ID, models.ScoreEventAdjustment, request.Delta, updatedUser.Score,
+			request.Reason+" (by "+admin.Username+")")
+		if err := jobs.RecordScoreEvent(sc, event); err != nil {
+			return nil, err
+		}
+		return event, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "User not found in group", http.StatusNotFound)
+		} else {
+			log.Printf("Score adjustment failed: %v", err)
+			http.Error(w, "Failed to adjust score", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetScoreTimeSeriesHandler returns the requester's score bucketed by day or week for progress charts
+func GetScoreTimeSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	granularity := models.ScoreGranularity(r.URL.Query().Get("granularity"))
+	if granularity == "" {
+		granularity = models.ScoreGranularityDay
+	}
+
+	var periods int
+	switch granularity {
+	case models.ScoreGranularityDay:
+		periods = 30
+	case models.ScoreGranularityWeek:
+		periods = 12
+	default:
+		http.Error(w, "Granularity must be day or week", http.StatusBadRequest)
+		return
+	}
+
+	if periodsStr := r.URL.Query().Get("periods"); periodsStr != "" {
+		parsed, err := strconv.Atoi(periodsStr)
+		if err != nil || parsed < 1 || parsed > 366 {
+			http.Error(w, "Periods must be between 1 and 366", http.StatusBadRequest)
+			return
+		}
+		periods = parsed
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	from := models.BucketStart(granularity, now)
+	if granularity == models.ScoreGranularityWeek {
+		from = from.AddDate(0, 0, -7*(periods-1))
+	} else {
+		from = from.AddDate(0, 0, -(periods - 1))
+	}
+
+	ctx := context.Background()
+	eventsCollection := config.DB.Collection("score_events")
+
+	// The score carried into the window is the result of the last change before it
+	initialScore := 0
+	var previous models.ScoreEvent
+	err := eventsCollection.FindOne(
+		ctx,
+		bson.M{"user_id": user.ID, "created_at": bson.M{"$lt": from}},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&previous)
+	if err == nil {
+		initialScore = previous.ScoreAfter
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		http.Error(w, "Failed to fetch score history", http.StatusInternalServerError)
+		return
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": user.ID, "created_at": bson.M{"$gte": from}}}},
+		{{Key: "$sort", Value: bson.M{"created_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateTrunc": bson.M{
+				"date":        "$created_at",
+				"unit":        string(granularity),
+				"timezone":    "UTC",
+				"startOfWeek": "monday",
+			}},
+			"delta":  bson.M{"$sum": "$delta"},
+			"score":  bson.M{"$last": "$score_after"},
+			"events": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := eventsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		http.Error(w, "Failed to aggregate score history", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var buckets []models.ScoreBucket
+	if err := cursor.All(ctx, &buckets); err != nil {
+		http.Error(w, "Failed to decode score history", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"granularity": granularity,
+		"from":        from,
+		"to":          now,
+		"score":       user.Score,
+		"buckets":     models.FillScoreBuckets(buckets, granularity, from, now, initialScore),
+	})
+}
@@ -0,0 +1,193 @@
+// handlers/score_events_test.go
+package handlers_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// scoreWriteFunc counts, for one function, the writes to a user's score and the score events it records
+type scoreWriteFunc struct {
+	name   string
+	writes int
+	events int
+}
+
+// stringLit returns the value of a string literal expression
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// updateOperator returns the update operator a document literal is the value of, for both
+// bson.M{"$inc": doc} and bson.D{{Key: "$set", Value: doc}}
+func updateOperator(stack []ast.Node) string {
+	if len(stack) < 2 {
+		return ""
+	}
+	parent, ok := stack[len(stack)-1].(*ast.KeyValueExpr)
+	if !ok {
+		return ""
+	}
+	if key, ok := stringLit(parent.Key); ok {
+		return key
+	}
+	if ident, ok := parent.Key.(*ast.Ident); !ok || ident.Name != "Value" {
+		return ""
+	}
+	element, ok := stack[len(stack)-2].(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	for _, elt := range element.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == "Key" {
+				key, _ := stringLit(kv.Value)
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+// isScoreWrite reports whether node changes a stored score: an $inc or $set of "score", an
+// update["$set"]["score"] assignment, or a new models.User with starting points
+func isScoreWrite(node ast.Node, stack []ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.CompositeLit:
+		if sel, ok := n.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "User" {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "models" {
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if ident, ok := kv.Key.(*ast.Ident); ok && ident.Name == "Score" {
+							return true
+						}
+					}
+				}
+			}
+			return false
+		}
+		for _, elt := range n.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				if key, _ := stringLit(kv.Key); key == "score" {
+					operator := updateOperator(stack)
+					return operator == "$inc" || operator == "$set"
+				}
+			}
+		}
+	case *ast.AssignStmt:
+		for _, lhs := range n.Lhs {
+			if index, ok := lhs.(*ast.IndexExpr); ok {
+				if key, _ := stringLit(index.Index); key == "score" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isRecordScoreEvent reports whether node calls RecordScoreEvent
+func isRecordScoreEvent(node ast.Node) bool {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name == "RecordScoreEvent"
+	case *ast.SelectorExpr:
+		return fn.Sel.Name == "RecordScoreEvent"
+	}
+	return false
+}
+
+// scoreWriteFuncs parses the non-test sources matching pattern and returns every function that writes a score
+func scoreWriteFuncs(t *testing.T, pattern string) []scoreWriteFunc {
+	files, err := filepath.Glob(pattern)
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to list %s: %v", pattern, err)
+	}
+
+	var funcs []scoreWriteFunc
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if matched, _ := filepath.Match("*_test.go", filepath.Base(file)); matched {
+			continue
+		}
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", file, err)
+		}
+
+		for _, decl := range parsed.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			counts := scoreWriteFunc{name: filepath.Base(file) + ":" + fn.Name.Name}
+			var stack []ast.Node
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				if node == nil {
+					stack = stack[:len(stack)-1]
+					return true
+				}
+				if isScoreWrite(node, stack) {
+					counts.writes++
+				}
+				if isRecordScoreEvent(node) {
+					counts.events++
+				}
+				stack = append(stack, node)
+				return true
+			})
+			if counts.writes > 0 || counts.events > 0 {
+				funcs = append(funcs, counts)
+			}
+		}
+	}
+	return funcs
+}
+
+func TestEveryScoreChangeRecordsOneEvent(t *testing.T) {
+	funcs := append(scoreWriteFuncs(t, "*.go"), scoreWriteFuncs(t, "../jobs/*.go")...)
+
+	// Guard against the scan silently missing the known score change paths
+	expected := map[string]bool{
+		"auth.go:RegisterHandler":                  false,
+		"score.go:AdjustScoreHandler":              false,
+		"kudos.go:sendKudos":                       false,
+		"chore_completion.go:CompleteChoreHandler": false,
+		"group.go:LeaveGroupHandler":               false,
+		"rewards.go:RedeemRewardHandler":           false,
+		"rewards.go:ReviewRedemptionHandler":       false,
+		"score_decay.go:decayUserScore":            false,
+		"overdue_penalty.go:applyOverduePenalty":   false,
+	}
+
+	for _, fn := range funcs {
+		if _, ok := expected[fn.name]; ok {
+			expected[fn.name] = true
+		}
+		if fn.name == "score_events.go:RecordScoreEvent" {
+			continue
+		}
+		if fn.writes != fn.events {
+			t.Errorf("%s writes a score %d times but records %d score events", fn.name, fn.writes, fn.events)
+		}
+	}
+
+	for name, found := range expected {
+		if !found {
+			t.Errorf("expected %s to change a score", name)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+// handlers/settlements.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errInvalidSettlement marks a settlement rejected because it does not match the balances
+var errInvalidSettlement = errors.New("invalid settlement")
+
+// CreateSettlementRequest defines the request structure for recording a repayment
+type CreateSettlementRequest struct {
+	To     string  `json:"to" validate:"required"` // Username of the member who was paid
+	From   string  `json:"from,omitempty"`         // Username of the member who paid; defaults to the requesting user
+	Amount float64 `json:"amount" validate:"required,min=0"`
+	Note   string  `json:"note,omitempty"`
+}
+
+// GroupBalancesResponse is where every member stands and the payments that would settle the group
+type GroupBalancesResponse struct {
+	Currency string                 `json:"currency"`
+	Balances []models.MemberBalance `json:"balances"`
+	Debts    []models.Debt          `json:"debts"`    // Fewest payments that settle everyone
+	Pairwise []models.Debt          `json:"pairwise"` // What members owe each other directly, with the expenses behind each debt
+}
+
+// loadGroupLedger fetches all of the group's expenses and settlements, oldest first
+func loadGroupLedger(ctx context.Context, group models.Group) ([]models.Expense, []models.Settlement, error) {
+	oldestFirst := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	var expenses []models.Expense
+	cursor, err := config.DB.Collection("expenses").Find(ctx, bson.M{"group_id": group.ID}, oldestFirst)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cursor.All(ctx, &expenses); err != nil {
+		return nil, nil, err
+	}
+
+	var settlements []models.Settlement
+	cursor, err = config.DB.Collection("settlements").Find(ctx, bson.M{"group_id": group.ID}, oldestFirst)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cursor.All(ctx, &settlements); err != nil {
+		return nil, nil, err
+	}
+	return expenses, settlements, nil
+}
+
+// loadGroupBalances computes the members' balances from all of the group's expenses and settlements
+func loadGroupBalances(ctx context.Context, group models.Group) ([]models.MemberBalance, error) {
+	expenses, settlements, err := loadGroupLedger(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	return models.ComputeBalances(expenses, settlements, group.Members), nil
+}
+
+// GetGroupBalancesHandler returns who owes whom in a group, netting expenses against recorded
+// settlements. Alongside each member's balance it lists the direct debts between members with the
+// expenses behind them, and the fewest payments that settle them all.
+// Path format: /api/groups/{id}/balances
+func GetGroupBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/groups/")
+	groupID, err := primitive.ObjectIDFromHex(strings.TrimSuffix(path, "/balances"))
+	if err != nil {
+		http.Error(w, "Invalid group ID format", http.StatusBadRequest)
+		return
+	}
+
+	requester, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+	if requester.GroupID != groupID {
+		http.Error(w, "User is not a member of this group", http.StatusForbidden)
+		return
+	}
+
+	group, ok := getGroupByID(w, groupID)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	expenses, settlements, err := loadGroupLedger(ctx, group)
+	if err != nil {
+		log.Printf("Failed to compute balances: %v", err)
+		http.Error(w, "Failed to compute balances", http.StatusInternalServerError)
+		return
+	}
+	balances := models.ComputeBalances(expenses, settlements, group.Members)
+	if err := fillBalanceNames(ctx, balances); err != nil {
+		log.Printf("Failed to fetch member names for balances: %v", err)
+	}
+
+	// Everyone in a direct debt has a balance, so their names are already known
+	pairwise := models.PairwiseDebts(expenses, settlements)
+	for i := range pairwise {
+		if balance := models.FindBalance(balances, pairwise[i].From); balance != nil {
+			pairwise[i].FromName = balance.Name
+		}
+		if balance := models.FindBalance(balances, pairwise[i].To); balance != nil {
+			pairwise[i].ToName = balance.Name
+		}
+	}
+
+	debts := models.SimplifyDebts(balances)
+	if err := fillDebtPaymentLinks(ctx, debts, group.Name); err != nil {
+		log.Printf("Failed to fetch payment handles for balances: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(GroupBalancesResponse{
+		Currency: group.Settings.CurrencyCode(),
+		Balances: balances,
+		Debts:    debts,
+		Pairwise: pairwise,
+	})
+}
+
+// fillDebtPaymentLinks adds links prefilled with the amount for paying each debt through the
+// payment apps the member who is owed has registered
+func fillDebtPaymentLinks(ctx context.Context, debts []models.Debt, groupName string) error {
+	if len(debts) == 0 {
+		return nil
+	}
+	ids := make([]primitive.ObjectID, 0, len(debts))
+	for _, debt := range debts {
+		ids = append(ids, debt.To)
+	}
+
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+	handles := make(map[primitive.ObjectID]models.PaymentHandles, len(users))
+	for _, user := range users {
+		handles[user.ID] = user.Preferences.PaymentHandles
+	}
+
+	note := "Settling up in " + groupName
+	for i := range debts {
+		debts[i].PaymentLinks = handles[debts[i].To].PaymentLinks(debts[i].ToName, debts[i].Amount, note)
+	}
+	return nil
+}
+
+// CreateSettlementHandler records that one member paid another back, in full or in part. The
+// repayment is checked against the current balances in the same transaction that records it, so it
+// can never settle more than is owed, and the settlement notes what the payer still owes after it.
+func CreateSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateSettlementRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Amount = models.RoundMoney(request.Amount)
+	if request.Amount <= 0 {
+		http.Error(w, "Amount must be positive", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	usernames := []string{request.To}
+	if request.From != "" && request.From != request.To {
+		usernames = append(usernames, request.From)
+	}
+	members, ok := findGroupMembersByUsername(w, user.GroupID, usernames)
+	if !ok {
+		return
+	}
+
+	from := user.ID
+	if request.From != "" {
+		from = members[request.From].ID
+	}
+	to := members[request.To].ID
+	if from == to {
+		http.Error(w, "A member cannot settle with themselves", http.StatusBadRequest)
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		// Bumping the ledger version makes concurrent settlements in the group conflict, so each
+		// one is checked against balances that include the others
+		var group models.Group
+		err := config.DB.Collection("groups").FindOneAndUpdate(
+			sessionContext,
+			bson.M{"_id": user.GroupID},
+			bson.M{"$inc": bson.M{"ledger_version": 1}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&group)
+		if err != nil {
+			return nil, err
+		}
+
+		balances, err := loadGroupBalances(sessionContext, group)
+		if err != nil {
+			return nil, err
+		}
+
+		owes := 0.0
+		if balance := models.FindBalance(balances, from); balance != nil {
+			owes = -balance.Net
+		}
+		owed := 0.0
+		if balance := models.FindBalance(balances, to); balance != nil {
+			owed = balance.Net
+		}
+		if owes <= 0 {
+			return nil, fmt.Errorf("%w: the paying member does not owe anything", errInvalidSettlement)
+		}
+		if owed <= 0 {
+			return nil, fmt.Errorf("%w: the receiving member is not owed anything", errInvalidSettlement)
+		}
+		if limit := math.Min(owes, owed); request.Amount > limit {
+			return nil, fmt.Errorf("%w: amount cannot exceed %.2f", errInvalidSettlement, limit)
+		}
+
+		settlement := models.CreateSettlement(group.ID, from, to, request.Amount, user.ID)
+		settlement.Note = strings.TrimSpace(request.Note)
+		settlement.RecordBalance(owes)
+		insertResult, err := config.DB.Collection("settlements").InsertOne(sessionContext, settlement)
+		if err != nil {
+			return nil, err
+		}
+		settlement.ID = insertResult.InsertedID.(primitive.ObjectID)
+		return settlement, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, errInvalidSettlement) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			log.Printf("Failed to record settlement: %v", err)
+			http.Error(w, "Failed to record settlement", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GetSettlementsHandler lists the repayments recorded in the requesting user's group, newest first
+func GetSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("settlements").Find(
+		ctx,
+		bson.M{"group_id": user.GroupID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch settlements: %v", err)
+		http.Error(w, "Failed to fetch settlements", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	settlements := make([]models.Settlement, 0)
+	if err := cursor.All(ctx, &settlements); err != nil {
+		http.Error(w, "Failed to decode settlements", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settlements)
+}
+
+// GetSettlementHistoryHandler lists the repayments between two members of the requesting user's
+// group, with what each has paid the other and what is still owed between them.
+// Query: ?from={username}&to={username}, where from defaults to the requesting user
+func GetSettlementHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	toUsername := query.Get("to")
+	if toUsername == "" {
+		http.Error(w, "The other member's username is required", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	usernames := []string{toUsername}
+	fromUsername := query.Get("from")
+	if fromUsername != "" {
+		usernames = append(usernames, fromUsername)
+	}
+	members, ok := findGroupMembersByUsername(w, user.GroupID, usernames)
+	if !ok {
+		return
+	}
+	from, to := user, members[toUsername]
+	if fromUsername != "" {
+		from = members[fromUsername]
+	}
+	if from.ID == to.ID {
+		http.Error(w, "A payment history needs two different members", http.StatusBadRequest)
+		return
+	}
+
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return
+	}
+
+	expenses, settlements, err := loadGroupLedger(context.Background(), group)
+	if err != nil {
+		log.Printf("Failed to fetch settlement history: %v", err)
+		http.Error(w, "Failed to fetch settlement history", http.StatusInternalServerError)
+		return
+	}
+
+	history := models.BuildPaymentHistory(from.ID, to.ID, expenses, settlements)
+	history.FromName = from.Name
+	history.ToName = to.Name
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
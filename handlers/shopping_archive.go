@@ -0,0 +1,169 @@
+// handlers/shopping_archive.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReaddArchivedItemRequest defines the request structure for putting an archived item back in the cart
+type ReaddArchivedItemRequest struct {
+	ItemID   string  `json:"item_id"`
+	Quantity float64 `json:"quantity,omitempty"` // Defaults to the archived quantity
+}
+
+// GetArchivedShoppingItemsHandler lists the group's recently purchased and removed cart items, newest first.
+// Query: ?purchased=true|false&list_id=
+func GetArchivedShoppingItemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	filter := bson.M{"group_id": user.GroupID}
+	switch r.URL.Query().Get("purchased") {
+	case "":
+	case "true":
+		filter["purchased_at"] = bson.M{"$exists": true}
+	case "false":
+		filter["purchased_at"] = bson.M{"$exists": false}
+	default:
+		http.Error(w, "Purchased filter must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	if listIDStr := r.URL.Query().Get("list_id"); listIDStr != "" {
+		listID, ok := getShoppingListID(w, listIDStr, user.GroupID)
+		if !ok {
+			return
+		}
+		filter["list_id"] = models.ListFilter(listID)
+	}
+
+	cursor, err := config.DB.Collection("shopping_cart_archive").Find(
+		context.Background(),
+		filter,
+		options.Find().SetSort(bson.D{{Key: "archived_at", Value: -1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch archived shopping items: %v", err)
+		http.Error(w, "Failed to fetch archived items", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	items := make([]models.ArchivedShoppingCartItem, 0)
+	if err := cursor.All(context.Background(), &items); err != nil {
+		log.Printf("Failed to decode archived shopping items: %v", err)
+		http.Error(w, "Failed to decode archived items", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Archived items retrieved successfully",
+		Data:    items,
+	})
+}
+
+// ReaddArchivedItemHandler puts an archived item from the group back in the requesting user's cart,
+// merging it into the same item if it is already there. The archived copy is kept.
+func ReaddArchivedItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ReaddArchivedItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	itemID, err := primitive.ObjectIDFromHex(request.ItemID)
+	if err != nil {
+		http.Error(w, "Invalid item ID format", http.StatusBadRequest)
+		return
+	}
+
+	if request.Quantity < 0 {
+		http.Error(w, "Quantity must be positive", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var archived models.ArchivedShoppingCartItem
+	err = config.DB.Collection("shopping_cart_archive").FindOne(
+		context.Background(),
+		bson.M{"_id": itemID, "group_id": user.GroupID},
+	).Decode(&archived)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Archived item not found in your group", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch archived item", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	quantity := archived.Quantity
+	if request.Quantity > 0 {
+		quantity = request.Quantity
+	}
+
+	item := models.CreateShoppingCartItem(user.ID, user.GroupID, archived.ItemName, quantity, archived.Unit, archived.Category)
+	item.IsShared = archived.IsShared
+
+	// The list or category may have been deleted since the item was archived
+	if !archived.ListID.IsZero() {
+		count, err := config.DB.Collection("shopping_lists").CountDocuments(
+			context.Background(),
+			bson.M{"_id": archived.ListID, "group_id": user.GroupID},
+		)
+		if err != nil {
+			http.Error(w, "Failed to fetch shopping list", http.StatusInternalServerError)
+			return
+		}
+		if count > 0 {
+			item.ListID = archived.ListID
+		}
+	}
+	if archived.CategoryID != nil {
+		if category, err := validateCategoryID(archived.CategoryID.Hex(), user.GroupID); err == nil {
+			item.CategoryID = &category.ID
+			item.Category = category.Name
+		}
+	}
+
+	finalShoppingCartItem, ok := addCartItem(w, r, user, item)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Item added back to the shopping cart",
+		Data:    finalShoppingCartItem,
+	})
+}
@@ -3,9 +3,11 @@ package handlers
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
 	"cribb-backend/middleware"
 	"cribb-backend/models"
+	"cribb-backend/webhooks"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -22,24 +24,32 @@ import (
 
 // AddShoppingCartItemRequest defines the request structure for adding a shopping cart item
 type AddShoppingCartItemRequest struct {
-	ItemName string  `json:"item_name" validate:"required,min=1"`
-	Quantity float64 `json:"quantity" validate:"required,min=0.1"`
-	Category string  `json:"category"`
+	ItemName   string  `json:"item_name" validate:"required,min=1"`
+	Quantity   float64 `json:"quantity" validate:"required,min=0.1"`
+	Unit       string  `json:"unit"` // e.g. "lbs" or "dozen"; omit for individual items
+	Category   string  `json:"category"`
+	CategoryID string  `json:"category_id,omitempty"` // Pantry category; takes precedence over category
+	ListID     string  `json:"list_id,omitempty"`     // Omit for the group's default list
+	IsShared   *bool   `json:"is_shared,omitempty"`   // Defaults to shared; personal items are not split
 }
 
 // UpdateShoppingCartItemRequest defines the request structure for updating a shopping cart item
 type UpdateShoppingCartItemRequest struct {
-	ItemID   string  `json:"item_id" validate:"required"`
-	ItemName string  `json:"item_name,omitempty" validate:"min=1"`
-	Quantity float64 `json:"quantity,omitempty" validate:"min=0.1"`
-	Category string  `json:"category,omitempty"`
+	ItemID     string  `json:"item_id" validate:"required"`
+	ItemName   string  `json:"item_name,omitempty" validate:"min=1"`
+	Quantity   float64 `json:"quantity,omitempty" validate:"min=0.1"`
+	Unit       *string `json:"unit,omitempty"` // Set to "" to count individual items
+	Category   string  `json:"category,omitempty"`
+	CategoryID string  `json:"category_id,omitempty"`
+	IsShared   *bool   `json:"is_shared,omitempty"`
 }
 
 // Response structures
 type ShoppingCartResponse struct {
-	Status  string      `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Status     string      `json:"status"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"` // Set on paged lists when another page follows
 }
 
 // AddShoppingCartItemHandler handles adding an item to the shopping cart
@@ -91,79 +101,134 @@ func AddShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Define filter to find the item
-	filter := bson.M{
-		"user_id":   userID,
-		"group_id":  user.GroupID,
-		"item_name": request.ItemName,
+	listID, ok := getShoppingListID(w, request.ListID, user.GroupID)
+	if !ok {
+		return
+	}
+
+	// A pantry category replaces the free-text category with its name
+	var category *models.PantryCategory
+	if request.CategoryID != "" {
+		category, err = validateCategoryID(request.CategoryID, user.GroupID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		request.Category = category.Name
+	}
+
+	newItem := models.CreateShoppingCartItem(
+		userID,
+		user.GroupID,
+		request.ItemName,
+		request.Quantity,
+		request.Unit,
+		request.Category,
+	)
+	newItem.ListID = listID
+	if category != nil {
+		newItem.CategoryID = &category.ID
+	}
+	if request.IsShared != nil {
+		newItem.IsShared = *request.IsShared
+	}
+
+	finalShoppingCartItem, ok := addCartItem(w, r, user, newItem)
+	if !ok {
+		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // 200 OK for both add and increment
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Item processed successfully", // Updated generic message
+		Data:    finalShoppingCartItem,
+	})
+}
+
+// addCartItem adds an item to the cart, merging it into the item with the same normalized name and
+// sharing on the same list, whoever added it, by converting the quantity into that item's unit and
+// recording the new requester. On merge the category is replaced when the item has one; the
+// existing item's sharing is kept. It logs the activity, writes the error response itself and
+// returns false on failure.
+func addCartItem(w http.ResponseWriter, r *http.Request, user models.User, item *models.ShoppingCartItem) (models.ShoppingCartItem, bool) {
+	// The same item on the list is merged into no matter which member added it
+	filter := item.DuplicateFilter()
+
 	// Variable to hold the final item state
 	var finalShoppingCartItem models.ShoppingCartItem
 	itemWasUpdated := false // Flag to track if we updated or inserted
+	addedQuantity := item.Quantity
+
+	// A concurrent add of the same item can win the insert, in which case merge into it on the second pass
+	for attempt := 0; attempt < 2; attempt++ {
+		// Attempt to find the existing item first
+		var existingItem models.ShoppingCartItem
+		err := config.DB.Collection("shopping_cart").FindOne(context.Background(), filter).Decode(&existingItem)
+
+		if err == nil {
+			// Item found - merge the quantity, converted into the unit already in the cart
+			converted, ok := models.ConvertQuantity(item.Quantity, item.Unit, existingItem.Unit)
+			if !ok {
+				writeError(w, r, apierror.CodeUnitMismatch, fmt.Sprintf("%s is already in the cart as %s; update that item or use a compatible unit",
+					existingItem.ItemName, existingItem.FormatQuantity()))
+				return models.ShoppingCartItem{}, false
+			}
+			itemWasUpdated = true
+			addedQuantity = converted
+
+			update := bson.M{
+				"$inc": bson.M{"quantity": converted}, // Increment quantity
+				"$set": bson.M{
+					"added_at": time.Now(), // Update timestamp
+				},
+				"$addToSet": bson.M{"requested_by": bson.M{"$each": item.Requesters()}},
+			}
+			// If a category is provided, update it as well
+			if item.Category != "" {
+				update["$set"].(bson.M)["category"] = item.Category
+			}
+			if item.CategoryID != nil {
+				update["$set"].(bson.M)["category_id"] = *item.CategoryID
+			}
 
-	// Attempt to find the existing item first
-	var existingItem models.ShoppingCartItem
-	err = config.DB.Collection("shopping_cart").FindOne(context.Background(), filter).Decode(&existingItem)
-
-	if err == nil {
-		// Item found - Increment quantity and update timestamp/category
-		itemWasUpdated = true
-		update := bson.M{
-			"$inc": bson.M{"quantity": request.Quantity}, // Increment quantity
-			"$set": bson.M{
-				"added_at": time.Now(), // Update timestamp
-				// Optionally update category if provided?
-				// "category": request.Category, // Keep existing category or update? Decide policy.
-			},
-		}
-		// If category is provided in the request, update it as well
-		if request.Category != "" {
-			update["$set"].(bson.M)["category"] = request.Category
-		}
-
-		_, updateErr := config.DB.Collection("shopping_cart").UpdateOne(
-			context.Background(),
-			filter,
-			update,
-		)
-		if updateErr != nil {
-			log.Printf("Failed to increment shopping cart item quantity: %v", updateErr)
-			http.Error(w, "Failed to update item quantity in shopping cart", http.StatusInternalServerError)
-			return
-		}
-		// Fetch the updated item to return it
-		fetchErr := config.DB.Collection("shopping_cart").FindOne(context.Background(), filter).Decode(&finalShoppingCartItem)
-		if fetchErr != nil {
-			log.Printf("Failed to fetch updated shopping cart item: %v", fetchErr)
-			http.Error(w, "Failed to fetch updated item", http.StatusInternalServerError)
-			return
-		}
+			updateErr := config.DB.Collection("shopping_cart").FindOneAndUpdate(
+				context.Background(),
+				bson.M{"_id": existingItem.ID},
+				update,
+				options.FindOneAndUpdate().SetReturnDocument(options.After),
+			).Decode(&finalShoppingCartItem)
+			if updateErr != nil {
+				log.Printf("Failed to increment shopping cart item quantity: %v", updateErr)
+				http.Error(w, "Failed to update item quantity in shopping cart", http.StatusInternalServerError)
+				return models.ShoppingCartItem{}, false
+			}
+			break
+
+		} else if errors.Is(err, mongo.ErrNoDocuments) {
+			// Item not found - Insert new item
+			newItem := *item
+			newItem.ID = primitive.NilObjectID
+			insertResult, insertErr := config.DB.Collection("shopping_cart").InsertOne(context.Background(), newItem)
+			if insertErr != nil {
+				if mongo.IsDuplicateKeyError(insertErr) && attempt == 0 {
+					continue
+				}
+				log.Printf("Failed to insert new shopping cart item: %v", insertErr)
+				http.Error(w, "Failed to add item to shopping cart", http.StatusInternalServerError)
+				return models.ShoppingCartItem{}, false
+			}
+			newItem.ID = insertResult.InsertedID.(primitive.ObjectID)
+			finalShoppingCartItem = newItem // Use the newly inserted item data
+			break
 
-	} else if errors.Is(err, mongo.ErrNoDocuments) {
-		// Item not found - Insert new item
-		itemWasUpdated = false
-		newItem := models.CreateShoppingCartItem(
-			userID,
-			user.GroupID,
-			request.ItemName,
-			request.Quantity,
-			request.Category,
-		)
-		insertResult, insertErr := config.DB.Collection("shopping_cart").InsertOne(context.Background(), newItem)
-		if insertErr != nil {
-			log.Printf("Failed to insert new shopping cart item: %v", insertErr)
-			http.Error(w, "Failed to add item to shopping cart", http.StatusInternalServerError)
-			return
+		} else {
+			// Other database error during FindOne
+			log.Printf("Error checking for existing shopping cart item: %v", err)
+			http.Error(w, "Database error checking for item", http.StatusInternalServerError)
+			return models.ShoppingCartItem{}, false
 		}
-		newItem.ID = insertResult.InsertedID.(primitive.ObjectID)
-		finalShoppingCartItem = *newItem // Use the newly inserted item data (Dereference the pointer)
-
-	} else {
-		// Other database error during FindOne
-		log.Printf("Error checking for existing shopping cart item: %v", err)
-		http.Error(w, "Database error checking for item", http.StatusInternalServerError)
-		return
 	}
 
 	// Log the activity
@@ -172,14 +237,14 @@ func AddShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 		activityDetails := "Added item to shopping cart"
 		if itemWasUpdated {
 			activityAction = models.CartActivityTypeUpdate // Using Update type for increment as well
-			activityDetails = fmt.Sprintf("Increased quantity of %s by %.2f (New total: %.2f)", finalShoppingCartItem.ItemName, request.Quantity, finalShoppingCartItem.Quantity)
+			activityDetails = fmt.Sprintf("Increased quantity of %s by %.2f (New total: %s)", finalShoppingCartItem.ItemName, addedQuantity, finalShoppingCartItem.FormatQuantity())
 		}
 
 		activity := models.CreateShoppingCartActivity(
 			user.GroupID,
 			finalShoppingCartItem.ID, // Use the ID from the final item state
 			finalShoppingCartItem.ItemName,
-			userID,
+			user.ID,
 			user.Name,
 			activityAction,                 // Use the determined action
 			finalShoppingCartItem.Quantity, // Log the *new* total quantity
@@ -194,15 +259,11 @@ func AddShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 		if insertErr != nil {
 			log.Printf("Failed to create shopping cart activity record: %v", insertErr)
 		}
+
+		webhooks.ItemAdded(context.Background(), finalShoppingCartItem, addedQuantity, user)
 	}()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK) // 200 OK for both add and increment
-	json.NewEncoder(w).Encode(ShoppingCartResponse{
-		Status:  "success",
-		Message: "Item processed successfully", // Updated generic message
-		Data:    finalShoppingCartItem,
-	})
+	return finalShoppingCartItem, true
 }
 
 // UpdateShoppingCartItemHandler handles updating an item in the shopping cart
@@ -286,14 +347,33 @@ func UpdateShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 	// Only update fields that were provided
 	if request.ItemName != "" {
 		updateFields["item_name"] = request.ItemName
+		updateFields["normalized_name"] = models.NormalizeItemName(request.ItemName)
 	}
 
 	if request.Quantity > 0 {
 		updateFields["quantity"] = request.Quantity
 	}
 
-	if request.Category != "" {
+	if request.Unit != nil {
+		updateFields["unit"] = models.NormalizeUnit(*request.Unit)
+	}
+
+	if request.IsShared != nil {
+		updateFields["is_shared"] = *request.IsShared
+	}
+
+	if request.CategoryID != "" {
+		category, err := validateCategoryID(request.CategoryID, shoppingCartItem.GroupID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateFields["category"] = category.Name
+		updateFields["category_id"] = category.ID
+	} else if request.Category != "" {
+		// A free-text category no longer refers to a pantry category
 		updateFields["category"] = request.Category
+		updateFields["category_id"] = nil
 	}
 
 	// If no fields to update, return early
@@ -305,6 +385,7 @@ func UpdateShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 	// Record the old values for activity logging
 	oldItemName := shoppingCartItem.ItemName
 	oldQuantity := shoppingCartItem.Quantity
+	oldUnit := shoppingCartItem.Unit
 
 	// Update the item
 	_, err = config.DB.Collection("shopping_cart").UpdateOne(
@@ -314,6 +395,10 @@ func UpdateShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeDuplicateItem, "An item with this name is already on the list; add to that item instead")
+			return
+		}
 		log.Printf("Failed to update shopping cart item: %v", err)
 		http.Error(w, "Failed to update shopping cart item", http.StatusInternalServerError)
 		return
@@ -345,6 +430,10 @@ func UpdateShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 			changes = append(changes, "quantity from "+fmt.Sprintf("%.2f", oldQuantity)+" to "+fmt.Sprintf("%.2f", request.Quantity))
 		}
 
+		if request.Unit != nil && models.NormalizeUnit(*request.Unit) != oldUnit {
+			changes = append(changes, "unit from '"+oldUnit+"' to '"+models.NormalizeUnit(*request.Unit)+"'")
+		}
+
 		details += strings.Join(changes, ", ")
 
 		// Create activity log
@@ -468,6 +557,15 @@ func DeleteShoppingCartItemHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Keep the removed item in the archive so it can be re-added
+	_, err = config.DB.Collection("shopping_cart_archive").InsertOne(
+		context.Background(),
+		models.ArchiveShoppingCartItem(shoppingCartItem, userID),
+	)
+	if err != nil {
+		log.Printf("Failed to archive shopping cart item: %v", err)
+	}
+
 	// Log the activity
 	go func() {
 		// Create activity log
@@ -566,6 +664,35 @@ func ListShoppingCartItemsHandler(w http.ResponseWriter, r *http.Request) {
 		filter["user_id"] = filterUserID
 	}
 
+	// Optionally restrict to shared or personal items
+	if shared := r.URL.Query().Get("shared"); shared != "" {
+		switch shared {
+		case "true":
+			filter["is_shared"] = true
+		case "false":
+			filter["is_shared"] = false
+		default:
+			http.Error(w, "Shared filter must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Optionally restrict to a single list
+	if listIDStr := r.URL.Query().Get("list_id"); listIDStr != "" {
+		listID, ok := getShoppingListID(w, listIDStr, user.GroupID)
+		if !ok {
+			return
+		}
+		filter["list_id"] = models.ListFilter(listID)
+	}
+
+	// Optionally group the items by category in the group's aisle order (?group_by=category)
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy != "" && groupBy != "category" {
+		http.Error(w, "group_by must be category", http.StatusBadRequest)
+		return
+	}
+
 	// Get all items in the shopping cart for the group
 	opts := options.Find().SetSort(bson.D{{Key: "added_at", Value: -1}})
 	cursor, err := config.DB.Collection("shopping_cart").Find(
@@ -588,6 +715,14 @@ func ListShoppingCartItemsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if groupBy == "category" {
+		group, ok := getGroupByID(w, user.GroupID)
+		if !ok {
+			return
+		}
+		models.SortByAisle(shoppingCartItems, group.Settings.AisleOrder)
+	}
+
 	// Return items with additional user info
 	type ShoppingCartItemWithUser struct {
 		models.ShoppingCartItem
@@ -627,6 +762,35 @@ func ListShoppingCartItemsHandler(w http.ResponseWriter, r *http.Request) {
 		itemsWithUsers = append(itemsWithUsers, itemWithUser)
 	}
 
+	if groupBy == "category" {
+		type ShoppingCartCategoryGroup struct {
+			CategoryID *primitive.ObjectID        `json:"category_id,omitempty"`
+			Category   string                     `json:"category"`
+			Items      []ShoppingCartItemWithUser `json:"items"`
+		}
+
+		// Sorting keeps each category's items together, so consecutive items with the same key form a group
+		groups := make([]ShoppingCartCategoryGroup, 0)
+		for i, item := range itemsWithUsers {
+			if i == 0 || itemsWithUsers[i-1].CategoryKey() != item.CategoryKey() {
+				name := item.Category
+				if item.CategoryKey() == "" {
+					name = "Uncategorized"
+				}
+				groups = append(groups, ShoppingCartCategoryGroup{CategoryID: item.CategoryID, Category: name})
+			}
+			groups[len(groups)-1].Items = append(groups[len(groups)-1].Items, item)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ShoppingCartResponse{
+			Status:  "success",
+			Message: "Shopping cart items retrieved successfully",
+			Data:    groups,
+		})
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ShoppingCartResponse{
 		Status:  "success",
@@ -635,7 +799,8 @@ func ListShoppingCartItemsHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetShoppingCartActivityHandler retrieves recent activity for a group's shopping cart
+// GetShoppingCartActivityHandler retrieves recent activity for a group's shopping cart, newest
+// first. ?limit= (20 by default) and ?cursor= page through it.
 func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -652,7 +817,6 @@ func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
 	groupName := r.URL.Query().Get("group_name")
 	groupCode := r.URL.Query().Get("group_code")
-	limit := 20 // Default limit
 
 	// Need either group name or group code
 	if groupName == "" && groupCode == "" {
@@ -660,6 +824,11 @@ func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, ok := parsePageRequest(w, r, models.PageOrder{Field: "created_at", Descending: true}, 20)
+	if !ok {
+		return
+	}
+
 	// Get user ID
 	userID, err := primitive.ObjectIDFromHex(userClaims.ID)
 	if err != nil {
@@ -712,16 +881,11 @@ func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set up options for sorting and limiting results
-	opts := options.Find().
-		SetSort(bson.D{{Key: "created_at", Value: -1}}). // Sort by newest first
-		SetLimit(int64(limit))
-
-	// Find all activity for this group
+	// Find a page of activity for this group, newest first
 	cursor, err := config.DB.Collection("shopping_cart_activity").Find(
 		context.Background(),
-		bson.M{"group_id": group.ID},
-		opts,
+		page.filter(bson.M{"group_id": group.ID}),
+		page.findOptions(),
 	)
 
 	if err != nil {
@@ -735,6 +899,12 @@ func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to decode shopping cart activity", http.StatusInternalServerError)
 		return
 	}
+	var next string
+	if page.hasMore(len(activities)) {
+		activities = activities[:page.Limit]
+		last := activities[page.Limit-1]
+		next = nextCursor(w, models.PageCursor{Key: last.CreatedAt, ID: last.ID})
+	}
 
 	// Update read status for the current user
 	go func() {
@@ -755,12 +925,18 @@ func GetShoppingCartActivityHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ShoppingCartResponse{
-		Status:  "success",
-		Message: "Shopping cart activity retrieved successfully",
-		Data:    activities,
+		Status:     "success",
+		Message:    "Shopping cart activity retrieved successfully",
+		Data:       activities,
+		NextCursor: next,
 	})
 }
 
+// MarkActivityReadRequest defines the request structure for marking shopping cart activity read
+type MarkActivityReadRequest struct {
+	ActivityID string `json:"activity_id" validate:"required"`
+}
+
 // MarkActivityReadHandler marks a shopping cart activity as read
 func MarkActivityReadHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -775,9 +951,7 @@ func MarkActivityReadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var request struct {
-		ActivityID string `json:"activity_id" validate:"required"`
-	}
+	var request MarkActivityReadRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -61,6 +61,7 @@ func TestAddShoppingCartItemHandler(t *testing.T) {
 		var req struct {
 			ItemName string  `json:"item_name"`
 			Quantity float64 `json:"quantity"`
+			Unit     string  `json:"unit"`
 			Category string  `json:"category"`
 		}
 
@@ -96,6 +97,7 @@ func TestAddShoppingCartItemHandler(t *testing.T) {
 			user.GroupID,
 			req.ItemName,
 			req.Quantity,
+			req.Unit,
 			req.Category,
 		)
 
@@ -805,6 +807,7 @@ func TestAddDuplicateShoppingCartItemHandler(t *testing.T) {
 		var req struct {
 			ItemName string  `json:"item_name"`
 			Quantity float64 `json:"quantity"`
+			Unit     string  `json:"unit"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -851,6 +854,7 @@ func TestAddDuplicateShoppingCartItemHandler(t *testing.T) {
 				user.GroupID,
 				req.ItemName,
 				req.Quantity,
+				req.Unit,
 				"", // Assuming category is empty if not provided in this test scenario
 			)
 			newItem.ID = primitive.NewObjectID()
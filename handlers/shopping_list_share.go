@@ -0,0 +1,215 @@
+// handlers/shopping_list_share.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// publicShoppingListPath is where shared shopping lists can be viewed without an account
+const publicShoppingListPath = "/api/public/shopping-lists/"
+
+// ShareShoppingListRequest defines the request structure for creating a public shopping list link
+type ShareShoppingListRequest struct {
+	ListID         string `json:"list_id"`          // Empty for the default list
+	ExpiresInHours int    `json:"expires_in_hours"` // Defaults to 72 hours
+}
+
+// loadShareableList fetches a list's title and items for rendering, leaving out members' personal
+// items when sharedOnly is set
+func loadShareableList(groupID, listID primitive.ObjectID, sharedOnly bool) (string, []models.ShoppingCartItem, error) {
+	ctx := context.Background()
+
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": groupID}).Decode(&group); err != nil {
+		return "", nil, err
+	}
+
+	title := group.Name + " shopping list"
+	if !listID.IsZero() {
+		var list models.ShoppingList
+		if err := config.DB.Collection("shopping_lists").FindOne(ctx, bson.M{"_id": listID, "group_id": groupID}).Decode(&list); err != nil {
+			return "", nil, err
+		}
+		title = group.Name + ": " + list.Name
+	}
+
+	filter := bson.M{"group_id": groupID, "list_id": models.ListFilter(listID)}
+	if sharedOnly {
+		filter["is_shared"] = true
+	}
+	cursor, err := config.DB.Collection("shopping_cart").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "item_name", Value: 1}}),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items := make([]models.ShoppingCartItem, 0)
+	if err := cursor.All(ctx, &items); err != nil {
+		return "", nil, err
+	}
+	return title, items, nil
+}
+
+// writeShoppingListText renders a list in the requested format (?format=text|markdown, default text)
+func writeShoppingListText(w http.ResponseWriter, r *http.Request, groupID, listID primitive.ObjectID, sharedOnly bool) {
+	format := models.ShoppingListFormat(r.URL.Query().Get("format"))
+	if format == "" {
+		format = models.ShoppingListFormatText
+	}
+	if !models.IsValidShoppingListFormat(format) {
+		http.Error(w, "Format must be text or markdown", http.StatusBadRequest)
+		return
+	}
+
+	title, items, err := loadShareableList(groupID, listID, sharedOnly)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Shopping list not found", http.StatusNotFound)
+		} else {
+			log.Printf("Failed to load shopping list for sharing: %v", err)
+			http.Error(w, "Failed to fetch shopping list", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if format == models.ShoppingListFormatMarkdown {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Write([]byte(models.RenderShoppingList(title, items, format)))
+}
+
+// ExportShoppingListHandler renders a list of the requesting user's group as shareable text.
+// Query: ?list_id= (empty or "default" for the default list)&format=text|markdown
+func ExportShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	listID, ok := getShoppingListID(w, r.URL.Query().Get("list_id"), user.GroupID)
+	if !ok {
+		return
+	}
+
+	writeShoppingListText(w, r, user.GroupID, listID, false)
+}
+
+// ShareShoppingListHandler creates a read-only public link to a list that expires after the requested hours
+func ShareShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ShareShoppingListRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.ExpiresInHours == 0 {
+		request.ExpiresInHours = models.DefaultShareLinkHours
+	}
+	if request.ExpiresInHours < 1 || request.ExpiresInHours > models.MaxShareLinkHours {
+		http.Error(w, fmt.Sprintf("Expiry must be between 1 and %d hours", models.MaxShareLinkHours), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	listID, ok := getShoppingListID(w, request.ListID, user.GroupID)
+	if !ok {
+		return
+	}
+
+	share, err := models.CreateShoppingListShare(user.GroupID, listID, user.ID, time.Duration(request.ExpiresInHours)*time.Hour)
+	if err != nil {
+		log.Printf("Failed to generate share token: %v", err)
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+
+	result, err := config.DB.Collection("shopping_list_shares").InsertOne(context.Background(), share)
+	if err != nil {
+		log.Printf("Failed to create shopping list share: %v", err)
+		http.Error(w, "Failed to create share link", http.StatusInternalServerError)
+		return
+	}
+	share.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Share link created successfully",
+		Data: map[string]interface{}{
+			"share": share,
+			"url":   config.PublicAPIURL + publicShoppingListPath + share.Token,
+		},
+	})
+}
+
+// GetSharedShoppingListHandler shows the shared items of a list to anyone holding an unexpired link.
+// It requires no authentication. Path format: /api/public/shopping-lists/{token}?format=text|markdown
+func GetSharedShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token := strings.TrimPrefix(r.URL.Path, publicShoppingListPath)
+	if token == "" || strings.Contains(token, "/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	var share models.ShoppingListShare
+	err := config.DB.Collection("shopping_list_shares").FindOne(
+		context.Background(),
+		bson.M{"token": token},
+	).Decode(&share)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Share link not found or expired", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch share link", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if share.IsExpired(time.Now()) {
+		http.Error(w, "Share link not found or expired", http.StatusNotFound)
+		return
+	}
+
+	// Members' personal items stay private to the group
+	writeShoppingListText(w, r, share.GroupID, share.ListID, true)
+}
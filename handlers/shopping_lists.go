@@ -0,0 +1,312 @@
+// handlers/shopping_lists.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// defaultShoppingListID is accepted in place of a list ID to address the group's default list
+const defaultShoppingListID = "default"
+
+// getShoppingListID resolves a list ID supplied by the client, checking that the list belongs
+// to the group. An empty ID or "default" resolves to the zero ID of the default list.
+// It writes the error response itself and returns false on failure.
+func getShoppingListID(w http.ResponseWriter, listIDHex string, groupID primitive.ObjectID) (primitive.ObjectID, bool) {
+	if listIDHex == "" || listIDHex == defaultShoppingListID {
+		return primitive.NilObjectID, true
+	}
+
+	listID, err := primitive.ObjectIDFromHex(listIDHex)
+	if err != nil {
+		http.Error(w, "Invalid list ID format", http.StatusBadRequest)
+		return primitive.NilObjectID, false
+	}
+
+	count, err := config.DB.Collection("shopping_lists").CountDocuments(
+		context.Background(),
+		bson.M{"_id": listID, "group_id": groupID},
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch shopping list", http.StatusInternalServerError)
+		return primitive.NilObjectID, false
+	}
+	if count == 0 {
+		http.Error(w, "Shopping list not found", http.StatusNotFound)
+		return primitive.NilObjectID, false
+	}
+	return listID, true
+}
+
+// GetShoppingListsHandler lists the named shopping lists of the requesting user's group with their item counts
+func GetShoppingListsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("shopping_lists").Find(
+		ctx,
+		bson.M{"group_id": user.GroupID},
+		options.Find().SetSort(bson.D{{Key: "name", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch shopping lists", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	lists := make([]models.ShoppingList, 0)
+	if err := cursor.All(ctx, &lists); err != nil {
+		http.Error(w, "Failed to decode shopping lists", http.StatusInternalServerError)
+		return
+	}
+
+	// Count items per list; the default list is grouped under a null list ID
+	countCursor, err := config.DB.Collection("shopping_cart").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": user.GroupID}}},
+		{{Key: "$group", Value: bson.M{"_id": "$list_id", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		http.Error(w, "Failed to count shopping list items", http.StatusInternalServerError)
+		return
+	}
+	var counts []struct {
+		ListID *primitive.ObjectID `bson:"_id"`
+		Count  int                 `bson:"count"`
+	}
+	if err := countCursor.All(ctx, &counts); err != nil {
+		http.Error(w, "Failed to count shopping list items", http.StatusInternalServerError)
+		return
+	}
+	itemCounts := make(map[primitive.ObjectID]int, len(counts))
+	for _, c := range counts {
+		if c.ListID == nil {
+			itemCounts[primitive.NilObjectID] += c.Count
+		} else {
+			itemCounts[*c.ListID] += c.Count
+		}
+	}
+
+	type ShoppingListWithCount struct {
+		models.ShoppingList
+		ItemCount int `json:"item_count"`
+	}
+
+	response := make([]ShoppingListWithCount, 0, len(lists))
+	for _, list := range lists {
+		response = append(response, ShoppingListWithCount{ShoppingList: list, ItemCount: itemCounts[list.ID]})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Shopping lists retrieved successfully",
+		Data: map[string]interface{}{
+			"default_item_count": itemCounts[primitive.NilObjectID],
+			"lists":              response,
+		},
+	})
+}
+
+// CreateShoppingListRequest defines the request structure for creating a shopping list
+type CreateShoppingListRequest struct {
+	Name string `json:"name"`
+}
+
+// CreateShoppingListHandler creates a named shopping list in the requesting user's group
+func CreateShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request CreateShoppingListRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, "List name is required", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	list := models.CreateShoppingList(user.GroupID, user.ID, request.Name)
+	result, err := config.DB.Collection("shopping_lists").InsertOne(context.Background(), list)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeDuplicateItem, "A shopping list with this name already exists")
+			return
+		}
+		log.Printf("Shopping list creation error: %v", err)
+		http.Error(w, "Failed to create shopping list", http.StatusInternalServerError)
+		return
+	}
+	list.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Shopping list created successfully",
+		Data:    list,
+	})
+}
+
+// UpdateShoppingListRequest defines the request structure for renaming a shopping list
+type UpdateShoppingListRequest struct {
+	ListID string `json:"list_id"`
+	Name   string `json:"name"`
+}
+
+// UpdateShoppingListHandler renames a shopping list
+func UpdateShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UpdateShoppingListRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	listID, err := primitive.ObjectIDFromHex(request.ListID)
+	if err != nil {
+		http.Error(w, "Invalid list ID format", http.StatusBadRequest)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, "List name is required", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	var list models.ShoppingList
+	err = config.DB.Collection("shopping_lists").FindOneAndUpdate(
+		context.Background(),
+		bson.M{"_id": listID, "group_id": user.GroupID},
+		bson.M{"$set": bson.M{"name": request.Name, "updated_at": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&list)
+	if err != nil {
+		switch {
+		case errors.Is(err, mongo.ErrNoDocuments):
+			http.Error(w, "Shopping list not found", http.StatusNotFound)
+		case mongo.IsDuplicateKeyError(err):
+			writeError(w, r, apierror.CodeDuplicateItem, "A shopping list with this name already exists")
+		default:
+			log.Printf("Shopping list update error: %v", err)
+			http.Error(w, "Failed to update shopping list", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Shopping list updated successfully",
+		Data:    list,
+	})
+}
+
+// DeleteShoppingListHandler deletes a shopping list together with the items on it
+func DeleteShoppingListHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	listID, err := primitive.ObjectIDFromHex(r.URL.Query().Get("list_id"))
+	if err != nil {
+		http.Error(w, "Invalid list ID format", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		log.Printf("Failed to start MongoDB session: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer session.EndSession(context.Background())
+
+	result, err := session.WithTransaction(context.Background(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		deleted, err := config.DB.Collection("shopping_lists").DeleteOne(
+			sessionContext,
+			bson.M{"_id": listID, "group_id": user.GroupID},
+		)
+		if err != nil {
+			return nil, err
+		}
+		if deleted.DeletedCount == 0 {
+			return nil, mongo.ErrNoDocuments
+		}
+
+		items, err := config.DB.Collection("shopping_cart").DeleteMany(
+			sessionContext,
+			bson.M{"list_id": listID, "group_id": user.GroupID},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return items.DeletedCount, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Shopping list not found", http.StatusNotFound)
+		} else {
+			log.Printf("Shopping list deletion error: %v", err)
+			http.Error(w, "Failed to delete shopping list", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Shopping list deleted successfully",
+		Data:    map[string]interface{}{"items_deleted": result},
+	})
+}
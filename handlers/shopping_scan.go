@@ -0,0 +1,75 @@
+// handlers/shopping_scan.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/models"
+	"cribb-backend/products"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ScanShoppingItemRequest defines the request structure for adding a scanned item to the shopping list
+type ScanShoppingItemRequest struct {
+	Barcode string `json:"barcode"`
+}
+
+// ScanShoppingItemHandler resolves a scanned barcode to a product and returns a pre-filled add-item payload
+func ScanShoppingItemHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request ScanShoppingItemRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.Barcode = strings.TrimSpace(request.Barcode)
+	if !models.ValidBarcode(request.Barcode) {
+		http.Error(w, "Invalid barcode", http.StatusBadRequest)
+		return
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	product, err := products.Resolve(context.Background(), request.Barcode)
+	if err != nil {
+		if errors.Is(err, products.ErrProductNotFound) {
+			http.Error(w, "No product found for this barcode", http.StatusNotFound)
+		} else {
+			log.Printf("Barcode lookup failed for %s: %v", request.Barcode, err)
+			http.Error(w, "Failed to look up barcode", http.StatusBadGateway)
+		}
+		return
+	}
+
+	// One package of the product, ready to send to the add endpoint
+	item := AddShoppingCartItemRequest{
+		ItemName: product.Name,
+		Quantity: 1,
+		Category: product.Category,
+	}
+	if category, err := findPantryCategoryByName(product.Category, user.GroupID); err == nil {
+		item.CategoryID = category.ID.Hex()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Product found",
+		Data: map[string]interface{}{
+			"product": product,
+			"item":    item,
+		},
+	})
+}
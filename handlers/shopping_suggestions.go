@@ -0,0 +1,103 @@
+// handlers/shopping_suggestions.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetItemSuggestionsHandler returns ranked item name suggestions for autocomplete (?q=mil&limit=10),
+// built from the group's past purchases and cart items plus a dictionary of common groceries
+func GetItemSuggestionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Query parameter q is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 50 {
+			http.Error(w, "Limit must be between 1 and 50", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	history, err := groupItemHistory(context.Background(), user.GroupID, query)
+	if err != nil {
+		log.Printf("Failed to build item suggestions: %v", err)
+		http.Error(w, "Failed to fetch suggestions", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ShoppingCartResponse{
+		Status:  "success",
+		Message: "Suggestions retrieved successfully",
+		Data:    models.RankSuggestions(query, history, limit),
+	})
+}
+
+// groupItemHistory returns the items matching query that the group has bought or put in a cart,
+// with how often each was used and the category and unit it was last used with
+func groupItemHistory(ctx context.Context, groupID primitive.ObjectID, query string) ([]models.ItemSuggestion, error) {
+	match := bson.M{
+		"group_id":  groupID,
+		"item_name": bson.M{"$regex": regexp.QuoteMeta(query), "$options": "i"},
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{"item_name": 1, "category": 1, "unit": 1, "used_at": "$purchased_at"}}},
+		{{Key: "$unionWith", Value: bson.M{
+			"coll": "shopping_cart",
+			"pipeline": bson.A{
+				bson.M{"$match": match},
+				bson.M{"$project": bson.M{"item_name": 1, "category": 1, "unit": 1, "used_at": "$added_at"}},
+			},
+		}}},
+		{{Key: "$sort", Value: bson.M{"used_at": 1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":      bson.M{"$toLower": "$item_name"},
+			"name":     bson.M{"$last": "$item_name"},
+			"category": bson.M{"$last": "$category"},
+			"unit":     bson.M{"$last": "$unit"},
+			"count":    bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"count": -1}}},
+		{{Key: "$limit", Value: 100}},
+	}
+
+	cursor, err := config.DB.Collection("purchase_history").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var history []models.ItemSuggestion
+	if err := cursor.All(ctx, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
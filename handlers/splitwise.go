@@ -0,0 +1,479 @@
+// handlers/splitwise.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/currency"
+	"cribb-backend/models"
+	"cribb-backend/splitwise"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// splitwiseSending prefixes the mark on a settlement being recorded in Splitwise, so two requests
+// never send it twice
+const splitwiseSending = "splitwise:sending:"
+
+// SplitwiseRequest connects to a Splitwise group through the Splitwise API
+type SplitwiseRequest struct {
+	APIKey  string            `json:"api_key"`           // Personal API key or OAuth token; used for the request only and never stored
+	GroupID int64             `json:"group_id"`          // The number in the Splitwise group's URL
+	Members map[string]string `json:"members,omitempty"` // Splitwise user ID, email or name to username, for people not matched by email or name
+}
+
+// SplitwiseImportResponse reports what a Splitwise import added, or would add on a dry run
+type SplitwiseImportResponse struct {
+	DryRun      bool                 `json:"dry_run"`
+	Valid       bool                 `json:"valid"` // Every Splitwise user is matched and no row has errors
+	Expenses    int                  `json:"expenses"`
+	Settlements int                  `json:"settlements"`
+	Skipped     int                  `json:"skipped"`   // Imported before
+	Unmatched   []string             `json:"unmatched"` // Splitwise users to map to members
+	Errors      []models.ImportError `json:"errors"`
+}
+
+// SplitwiseExportResponse reports the settlements sent to Splitwise, or that would be on a dry run
+type SplitwiseExportResponse struct {
+	DryRun    bool     `json:"dry_run"`
+	Sent      int      `json:"sent"`
+	Failed    int      `json:"failed"`    // Rejected by Splitwise; they are tried again next time
+	Unmatched []string `json:"unmatched"` // Members with no Splitwise user in the group, whose settlements are held back
+}
+
+// ImportSplitwiseHandler copies the expenses and payments of a Splitwise group into the group's
+// expenses and settlements, for households moving over from Splitwise. The history comes from the
+// Splitwise API, given a SplitwiseRequest as JSON, or from the group's CSV export, sent as the
+// "file" field of a multipart form with an optional "members" field holding the same mapping as
+// JSON. Splitwise users are matched to members by email, then name. Either everything is imported
+// in one transaction or nothing is; expenses imported before are skipped. Only group admins can
+// import.
+// Query: ?dry_run=true reports what would be imported without writing anything
+func ImportSplitwiseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, group, ok := getAdminGroup(w, r, "import from Splitwise")
+	if !ok {
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	ctx := context.Background()
+	var expenses []splitwise.Expense
+	var rowErrors []models.ImportError
+	var overrides map[string]string
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		request, client, ok := readSplitwiseRequest(w, r)
+		if !ok {
+			return
+		}
+		overrides = request.Members
+		splitwiseGroup, err := client.Group(ctx, request.GroupID)
+		if err == nil {
+			expenses, err = client.Expenses(ctx, request.GroupID)
+		}
+		if err != nil {
+			writeSplitwiseError(w, err)
+			return
+		}
+		splitwiseGroup.FillUsers(expenses)
+	} else {
+		data, format, ok := readImportFile(w, r)
+		if !ok {
+			return
+		}
+		if format != models.ImportFormatCSV {
+			http.Error(w, "Send the Splitwise group's CSV export, or JSON with an API key to import through the API", http.StatusUnsupportedMediaType)
+			return
+		}
+		if members := r.FormValue("members"); members != "" {
+			if err := json.Unmarshal([]byte(members), &overrides); err != nil {
+				http.Error(w, "members must map Splitwise names to usernames as JSON", http.StatusBadRequest)
+				return
+			}
+		}
+		var err error
+		if expenses, rowErrors, err = splitwise.ParseCSV(data); err != nil {
+			http.Error(w, "Invalid Splitwise export: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Balances are kept in the group's currency, so other currencies are converted when imported
+	target := splitwise.Target{
+		GroupID:    group.ID,
+		ImportedBy: user.ID,
+		Currency:   group.Settings.CurrencyCode(),
+		Rates:      make(map[string]float64),
+	}
+	for _, code := range splitwise.Currencies(expenses, target.Currency) {
+		rate, err := currency.Rate(ctx, code, target.Currency)
+		if errors.Is(err, currency.ErrUnsupportedCurrency) {
+			continue // Reported on the rows in that currency
+		}
+		if err != nil {
+			log.Printf("Failed to fetch exchange rates: %v", err)
+			http.Error(w, "Exchange rates are unavailable, try again later", http.StatusServiceUnavailable)
+			return
+		}
+		target.Rates[code] = rate
+	}
+
+	members, err := findGroupUsers(ctx, group)
+	if err != nil {
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return
+	}
+	plan := splitwise.Plan(expenses, target, splitwise.NewMatcher(members, overrides))
+	plan.Errors = append(rowErrors, plan.Errors...)
+
+	skipped, err := skipImportedSplitwise(ctx, group.ID, &plan)
+	if err != nil {
+		log.Printf("Failed to check earlier Splitwise imports of group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to check import", http.StatusInternalServerError)
+		return
+	}
+
+	response := SplitwiseImportResponse{
+		DryRun:      dryRun,
+		Valid:       len(plan.Errors) == 0 && len(plan.Unmatched) == 0,
+		Expenses:    len(plan.Expenses),
+		Settlements: len(plan.Settlements),
+		Skipped:     skipped,
+		Unmatched:   plan.Unmatched,
+		Errors:      plan.Errors,
+	}
+	if response.Errors == nil {
+		response.Errors = []models.ImportError{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if dryRun {
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	if !response.Valid {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	// Imported history is not announced to members, since it happened before they moved over
+	if err := applySplitwiseImport(ctx, group, plan); err != nil {
+		w.Header().Del("Content-Type")
+		if mongo.IsDuplicateKeyError(err) {
+			writeError(w, r, apierror.CodeInProgress, "This Splitwise history is already being imported")
+			return
+		}
+		log.Printf("Failed to import Splitwise history into group %s: %v", group.ID.Hex(), err)
+		http.Error(w, "Failed to apply import", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+// skipImportedSplitwise drops the expenses and settlements imported before from the plan and
+// returns how many there were
+func skipImportedSplitwise(ctx context.Context, groupID primitive.ObjectID, plan *splitwise.Import) (int, error) {
+	ids := make([]string, 0, len(plan.Expenses)+len(plan.Settlements))
+	for _, expense := range plan.Expenses {
+		ids = append(ids, expense.ExternalID)
+	}
+	for _, settlement := range plan.Settlements {
+		ids = append(ids, settlement.ExternalID)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	imported := make(map[string]bool)
+	for _, collection := range []string{"expenses", "settlements"} {
+		values, err := config.DB.Collection(collection).Distinct(ctx, "external_id", bson.M{"group_id": groupID, "external_id": bson.M{"$in": ids}})
+		if err != nil {
+			return 0, err
+		}
+		for _, value := range values {
+			if id, ok := value.(string); ok {
+				imported[id] = true
+			}
+		}
+	}
+
+	skipped := 0
+	expenses := plan.Expenses[:0]
+	for _, expense := range plan.Expenses {
+		if imported[expense.ExternalID] {
+			skipped++
+			continue
+		}
+		expenses = append(expenses, expense)
+	}
+	plan.Expenses = expenses
+	settlements := plan.Settlements[:0]
+	for _, settlement := range plan.Settlements {
+		if imported[settlement.ExternalID] {
+			skipped++
+			continue
+		}
+		settlements = append(settlements, settlement)
+	}
+	plan.Settlements = settlements
+	return skipped, nil
+}
+
+// applySplitwiseImport writes the planned expenses and settlements in one transaction
+func applySplitwiseImport(ctx context.Context, group models.Group, plan splitwise.Import) error {
+	if len(plan.Expenses) == 0 && len(plan.Settlements) == 0 {
+		return nil
+	}
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionContext mongo.SessionContext) (interface{}, error) {
+		// Bumping the ledger version makes settlements being recorded at the same time conflict
+		// with the import, so they are checked against balances that include it
+		if _, err := config.DB.Collection("groups").UpdateOne(sessionContext, bson.M{"_id": group.ID}, bson.M{"$inc": bson.M{"ledger_version": 1}}); err != nil {
+			return nil, err
+		}
+		if len(plan.Expenses) > 0 {
+			documents := make([]interface{}, len(plan.Expenses))
+			for i, expense := range plan.Expenses {
+				documents[i] = expense
+			}
+			if _, err := config.DB.Collection("expenses").InsertMany(sessionContext, documents); err != nil {
+				return nil, err
+			}
+		}
+		if len(plan.Settlements) > 0 {
+			documents := make([]interface{}, len(plan.Settlements))
+			for i, settlement := range plan.Settlements {
+				documents[i] = settlement
+			}
+			if _, err := config.DB.Collection("settlements").InsertMany(sessionContext, documents); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	return err
+}
+
+// ExportSplitwiseSettlementsHandler records the group's settlements in a Splitwise group as
+// payments, so households still using Splitwise see repayments made in Cribb. Each settlement is
+// sent once; settlements imported from Splitwise are never sent back. Members are matched to
+// Splitwise users the same way as on import. Only group admins can send settlements.
+// Query: ?dry_run=true reports what would be sent without sending anything
+func ExportSplitwiseSettlementsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	_, group, ok := getAdminGroup(w, r, "send settlements to Splitwise")
+	if !ok {
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	request, client, ok := readSplitwiseRequest(w, r)
+	if !ok {
+		return
+	}
+	ctx := context.Background()
+	splitwiseGroup, err := client.Group(ctx, request.GroupID)
+	if err != nil {
+		writeSplitwiseError(w, err)
+		return
+	}
+
+	members, err := findGroupUsers(ctx, group)
+	if err != nil {
+		http.Error(w, "Failed to fetch group members", http.StatusInternalServerError)
+		return
+	}
+	matcher := splitwise.NewMatcher(members, request.Members)
+	splitwiseUsers := make(map[string]splitwise.User, len(members))
+	for _, member := range members {
+		if user, ok := matcher.User(member, splitwiseGroup.Members); ok {
+			splitwiseUsers[member.ID.Hex()] = user
+		}
+	}
+
+	cursor, err := config.DB.Collection("settlements").Find(
+		ctx,
+		bson.M{"group_id": group.ID, "external_id": bson.M{"$exists": false}},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch settlements", http.StatusInternalServerError)
+		return
+	}
+	var settlements []models.Settlement
+	if err := cursor.All(ctx, &settlements); err != nil {
+		http.Error(w, "Failed to decode settlements", http.StatusInternalServerError)
+		return
+	}
+
+	names := make(map[string]string, len(members))
+	for _, member := range members {
+		names[member.ID.Hex()] = member.Name
+	}
+	response := SplitwiseExportResponse{DryRun: dryRun, Unmatched: make([]string, 0)}
+	unmatched := make(map[string]bool)
+	for _, settlement := range settlements {
+		from, fromOK := splitwiseUsers[settlement.FromUserID.Hex()]
+		to, toOK := splitwiseUsers[settlement.ToUserID.Hex()]
+		for _, party := range []struct {
+			id      primitive.ObjectID
+			matched bool
+		}{{settlement.FromUserID, fromOK}, {settlement.ToUserID, toOK}} {
+			if party.matched || unmatched[party.id.Hex()] {
+				continue
+			}
+			unmatched[party.id.Hex()] = true
+			name := names[party.id.Hex()]
+			if name == "" {
+				name = party.id.Hex() // A former member
+			}
+			response.Unmatched = append(response.Unmatched, name)
+		}
+		if !fromOK || !toOK {
+			continue
+		}
+		if dryRun {
+			response.Sent++
+			continue
+		}
+
+		sent, err := sendSplitwiseSettlement(ctx, client, splitwiseGroup.ID, settlement, from, to, group.Settings.CurrencyCode())
+		if errors.Is(err, splitwise.ErrUnauthorized) || errors.Is(err, splitwise.ErrGroupNotFound) {
+			writeSplitwiseError(w, err)
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to send settlement %s to Splitwise: %v", settlement.ID.Hex(), err)
+			response.Failed++
+		} else if sent {
+			response.Sent++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// sendSplitwiseSettlement records a settlement in Splitwise and notes the payment's ID on it. It
+// returns false when another request claimed the settlement first.
+func sendSplitwiseSettlement(ctx context.Context, client *splitwise.Client, groupID int64, settlement models.Settlement, from, to splitwise.User, currencyCode string) (bool, error) {
+	settlements := config.DB.Collection("settlements")
+	claim, err := settlements.UpdateOne(
+		ctx,
+		bson.M{"_id": settlement.ID, "external_id": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"external_id": splitwiseSending + settlement.ID.Hex()}},
+	)
+	if err != nil || claim.ModifiedCount == 0 {
+		return false, err
+	}
+
+	description := settlement.Note
+	if description == "" {
+		description = "Settled up in Cribb"
+	}
+	paymentID, err := client.CreatePayment(ctx, groupID, splitwise.Payment{
+		From:         from.ID,
+		To:           to.ID,
+		Amount:       settlement.Amount,
+		CurrencyCode: currencyCode,
+		Description:  description,
+		Date:         settlement.CreatedAt,
+	})
+	if err != nil {
+		// Release the settlement so it is sent next time
+		if _, releaseErr := settlements.UpdateOne(ctx, bson.M{"_id": settlement.ID}, bson.M{"$unset": bson.M{"external_id": ""}}); releaseErr != nil {
+			log.Printf("Failed to release settlement %s: %v", settlement.ID.Hex(), releaseErr)
+		}
+		return false, err
+	}
+
+	_, err = settlements.UpdateOne(
+		ctx,
+		bson.M{"_id": settlement.ID},
+		bson.M{"$set": bson.M{"external_id": "splitwise:" + strconv.FormatInt(paymentID, 10)}},
+	)
+	if err != nil {
+		// The settlement stays claimed, so it is not sent twice
+		log.Printf("Failed to note Splitwise payment %d on settlement %s: %v", paymentID, settlement.ID.Hex(), err)
+	}
+	return true, nil
+}
+
+// readSplitwiseRequest reads the request and creates a client with its API key.
+// On failure it writes the error response and returns false.
+func readSplitwiseRequest(w http.ResponseWriter, r *http.Request) (SplitwiseRequest, *splitwise.Client, bool) {
+	var request SplitwiseRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return request, nil, false
+	}
+	request.APIKey = strings.TrimSpace(request.APIKey)
+	if request.APIKey == "" {
+		http.Error(w, "api_key is required", http.StatusBadRequest)
+		return request, nil, false
+	}
+	if request.GroupID <= 0 {
+		http.Error(w, "group_id must be the ID of a Splitwise group", http.StatusBadRequest)
+		return request, nil, false
+	}
+	client, err := splitwise.NewClient(request.APIKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return request, nil, false
+	}
+	return request, client, true
+}
+
+// writeSplitwiseError writes the response for a failed call to the Splitwise API
+func writeSplitwiseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, splitwise.ErrUnauthorized):
+		http.Error(w, "Splitwise rejected the API key", http.StatusBadRequest)
+	case errors.Is(err, splitwise.ErrGroupNotFound):
+		http.Error(w, "Splitwise group not found, or the API key's account is not in it", http.StatusNotFound)
+	default:
+		log.Printf("Failed to reach Splitwise: %v", err)
+		http.Error(w, "Failed to reach Splitwise, try again later", http.StatusBadGateway)
+	}
+}
+
+// findGroupUsers fetches the group's current members
+func findGroupUsers(ctx context.Context, group models.Group) ([]models.User, error) {
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		return nil, err
+	}
+	var members []models.User
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
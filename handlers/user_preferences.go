@@ -0,0 +1,131 @@
+// handlers/user_preferences.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// UpdateUserPreferencesRequest defines the request structure for changing a member's preferences
+type UpdateUserPreferencesRequest struct {
+	PaymentHandles *models.PaymentHandles          `json:"payment_handles,omitempty"` // Replaces every handle; empty values clear them
+	Notifications  *models.NotificationPreferences `json:"notifications,omitempty"`   // Replaces every notification preference
+	Locale         *string                         `json:"locale,omitempty"`          // A language tag such as "es" or "es-MX"; empty resets to English
+}
+
+// UserPreferencesHandler returns the requesting user's preferences on GET and updates them on PUT,
+// or with a JSON Merge Patch on PATCH
+func UserPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(withChannelMatrix(user.Preferences))
+	case http.MethodPut:
+		updateUserPreferences(w, r, user)
+	case http.MethodPatch:
+		patchUserPreferences(w, r, user)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// updateUserPreferences saves the preferences given in the request, leaving the others unchanged
+func updateUserPreferences(w http.ResponseWriter, r *http.Request, user models.User) {
+	var request UpdateUserPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	saveUserPreferences(w, user, request)
+}
+
+// patchUserPreferences merges the patch into the user's preferences and saves the preferences it
+// names. Null resets a preference, so clearing locale goes back to English.
+func patchUserPreferences(w http.ResponseWriter, r *http.Request, user models.User) {
+	patch, ok := readMergePatch(w, r, "payment_handles", "notifications", "locale")
+	if !ok {
+		return
+	}
+	preferences := user.Preferences
+	if err := patch.Apply(&preferences); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request UpdateUserPreferencesRequest
+	if patch.Has("payment_handles") {
+		request.PaymentHandles = &preferences.PaymentHandles
+	}
+	if patch.Has("notifications") {
+		request.Notifications = &preferences.Notifications
+	}
+	if patch.Has("locale") {
+		locale := string(preferences.Locale)
+		request.Locale = &locale
+	}
+	saveUserPreferences(w, user, request)
+}
+
+// saveUserPreferences checks and saves the preferences in the request, leaving the others unchanged
+func saveUserPreferences(w http.ResponseWriter, user models.User, request UpdateUserPreferencesRequest) {
+	preferences := user.Preferences
+	if request.PaymentHandles != nil {
+		handles := request.PaymentHandles.Normalize()
+		if err := handles.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		preferences.PaymentHandles = handles
+	}
+	if request.Notifications != nil {
+		notifications := *request.Notifications
+		notifications.QuietHours.TimeZone = strings.TrimSpace(notifications.QuietHours.TimeZone)
+		if err := notifications.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		preferences.Notifications = notifications
+	}
+	if request.Locale != nil {
+		locale, ok := models.ParseLocale(*request.Locale)
+		if !ok {
+			http.Error(w, "Unsupported locale; notifications are available in English (en) and Spanish (es)", http.StatusBadRequest)
+			return
+		}
+		preferences.Locale = locale
+	}
+
+	_, err := config.DB.Collection("users").UpdateOne(
+		context.Background(),
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"preferences": preferences, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to update preferences of user %s: %v", user.ID.Hex(), err)
+		http.Error(w, "Failed to update preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withChannelMatrix(preferences))
+}
+
+// withChannelMatrix fills in the channels of every notification event, defaults included, so the
+// app can show the whole matrix
+func withChannelMatrix(preferences models.UserPreferences) models.UserPreferences {
+	preferences.Notifications.Channels = preferences.Notifications.ChannelMatrix()
+	return preferences
+}
@@ -0,0 +1,270 @@
+// handlers/voice.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/voice"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxVoiceRequestSize is the largest assistant request read, in bytes
+const maxVoiceRequestSize = 128 << 10
+
+// alexaVerifier checks the signatures of requests to the Alexa skill endpoint
+var alexaVerifier = voice.NewAlexaVerifier()
+
+// AlexaHandler answers the requests of the Cribb Alexa skill. Requests must be signed by Alexa,
+// and come from the skill set in ALEXA_SKILL_ID when it is set. Members link their account
+// through the OAuth endpoints first; the access token in the request identifies them.
+func AlexaHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxVoiceRequestSize))
+	if err != nil {
+		http.Error(w, "Request is too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	now := time.Now()
+	if err := alexaVerifier.Verify(r.Context(), r.Header, body, now); err != nil {
+		log.Printf("Rejected Alexa request: %v", err)
+		http.Error(w, "Invalid signature", http.StatusBadRequest)
+		return
+	}
+	var request voice.AlexaRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := request.CheckTimestamp(now); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !voice.AlexaSkillAllowed(request.SkillID()) {
+		http.Error(w, "Unknown skill", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Alexa expects an empty answer when the session ends
+	if request.Request.Type == "SessionEndedRequest" {
+		json.NewEncoder(w).Encode(voice.AlexaResponse{Version: "1.0"})
+		return
+	}
+	member, ok, err := findVoiceMember(r.Context(), request.AccessToken(), now)
+	if err != nil {
+		log.Printf("Failed to find the member of an Alexa request: %v", err)
+		json.NewEncoder(w).Encode(voice.NewAlexaResponse(voiceErrorSpeech, true))
+		return
+	}
+	if !ok {
+		json.NewEncoder(w).Encode(voice.NewAlexaLinkAccountResponse())
+		return
+	}
+
+	speech, endSession := runVoiceCommand(r.Context(), member, request.Command(), now)
+	json.NewEncoder(w).Encode(voice.NewAlexaResponse(speech, endSession))
+}
+
+// GoogleAssistantHandler answers the fulfillment requests Dialogflow sends for the Cribb Google
+// Assistant action. Dialogflow authenticates with the basic auth password set in
+// GOOGLE_ASSISTANT_WEBHOOK_SECRET, and members link their account through the OAuth endpoints
+// with Google Sign-In for account linking turned off.
+func GoogleAssistantHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !voice.GoogleWebhookEnabled() {
+		http.Error(w, "Google Assistant is not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if !voice.CheckGoogleWebhook(r) {
+		http.Error(w, "Invalid webhook credentials", http.StatusUnauthorized)
+		return
+	}
+
+	var request voice.DialogflowRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVoiceRequestSize)).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	now := time.Now()
+	member, ok, err := findVoiceMember(r.Context(), request.AccessToken(), now)
+	if err != nil {
+		log.Printf("Failed to find the member of a Google Assistant request: %v", err)
+		json.NewEncoder(w).Encode(voice.NewDialogflowResponse(voiceErrorSpeech, true))
+		return
+	}
+	if !ok {
+		json.NewEncoder(w).Encode(voice.NewDialogflowSignInResponse())
+		return
+	}
+
+	speech, endConversation := runVoiceCommand(r.Context(), member, request.Command(), now)
+	json.NewEncoder(w).Encode(voice.NewDialogflowResponse(speech, endConversation))
+}
+
+// voiceErrorSpeech is said when a request could not be carried out
+const voiceErrorSpeech = "Sorry, something went wrong. Please try again later."
+
+// findVoiceMember returns the member whose linked account the access token belongs to. It
+// reports false when the token is missing, expired or unlinked, so the assistant asks the member
+// to link their account again.
+func findVoiceMember(ctx context.Context, accessToken string, now time.Time) (models.User, bool, error) {
+	if accessToken == "" {
+		return models.User{}, false, nil
+	}
+	var grant models.OAuthGrant
+	err := config.DB.Collection("oauth_grants").FindOneAndUpdate(
+		ctx,
+		bson.M{
+			"access_token_hash":       models.HashOAuthToken(accessToken),
+			"access_token_expires_at": bson.M{"$gt": now},
+		},
+		bson.M{"$set": bson.M{"last_used_at": now}},
+	).Decode(&grant)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.User{}, false, nil
+	}
+	if err != nil {
+		return models.User{}, false, err
+	}
+
+	var member models.User
+	err = config.DB.Collection("users").FindOne(ctx, bson.M{"_id": grant.UserID}).Decode(&member)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return models.User{}, false, nil
+	}
+	return member, err == nil, err
+}
+
+// runVoiceCommand carries out what the member asked for and returns what to say, and whether
+// the conversation ends
+func runVoiceCommand(ctx context.Context, member models.User, command voice.Command, now time.Time) (string, bool) {
+	switch command.Intent {
+	case voice.IntentWelcome:
+		return voice.WelcomeSpeech, false
+	case voice.IntentHelp:
+		return voice.HelpSpeech, false
+	case voice.IntentStop:
+		return voice.StopSpeech, true
+	}
+	if member.GroupID.IsZero() {
+		return "You are not in a group yet. Join one in the Cribb app first.", true
+	}
+
+	switch command.Intent {
+	case voice.IntentChoresToday:
+		return choresTodaySpeech(ctx, member, now), true
+	case voice.IntentShoppingList:
+		return shoppingListSpeech(ctx, member), true
+	}
+
+	inbound, err := command.InboundCommand()
+	if err != nil {
+		if command.Intent == voice.IntentCompleteChore {
+			return "Which chore did you finish?", false
+		}
+		return "What should I add to the shopping list?", false
+	}
+	var response InboundCommandResponse
+	var status int
+	if inbound.Action == models.InboundActionCompleteChore {
+		response, status = completeInboundChore(ctx, member, inbound)
+	} else {
+		response, status = addInboundShoppingItem(member, inbound)
+	}
+	switch {
+	case status == http.StatusOK:
+		return response.Message + ".", true
+	case status == http.StatusNotFound && inbound.Action == models.InboundActionCompleteChore:
+		return "I couldn't find an open chore called " + inbound.Chore + ".", true
+	case status >= http.StatusInternalServerError:
+		log.Printf("Voice command %s of user %s failed: %s", inbound.Action, member.ID.Hex(), response.Message)
+		return voiceErrorSpeech, true
+	}
+	return "Sorry, I couldn't do that: " + response.Message + ".", true
+}
+
+// choresTodaySpeech reads out the member's open chores due today and those overdue, using the
+// day in the member's time zone
+func choresTodaySpeech(ctx context.Context, member models.User, now time.Time) string {
+	location, err := time.LoadLocation(member.Preferences.Notifications.QuietHours.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+	endOfDay := startOfDay.AddDate(0, 0, 1)
+
+	cursor, err := config.DB.Collection("chores").Find(
+		ctx,
+		bson.M{
+			"group_id":    member.GroupID,
+			"assigned_to": member.ID,
+			"status":      bson.M{"$ne": models.ChoreStatusCompleted},
+			"due_date":    bson.M{"$lt": endOfDay},
+		},
+		options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}).SetLimit(20),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch chores of user %s: %v", member.ID.Hex(), err)
+		return voiceErrorSpeech
+	}
+	var chores []models.Chore
+	if err := cursor.All(ctx, &chores); err != nil {
+		log.Printf("Failed to decode chores of user %s: %v", member.ID.Hex(), err)
+		return voiceErrorSpeech
+	}
+
+	dueToday, overdue := make([]string, 0), make([]string, 0)
+	for _, chore := range chores {
+		if chore.DueDate.Before(startOfDay) || chore.Status == models.ChoreStatusOverdue {
+			overdue = append(overdue, chore.Title)
+		} else {
+			dueToday = append(dueToday, chore.Title)
+		}
+	}
+	return voice.ChoresTodaySpeech(dueToday, overdue)
+}
+
+// shoppingListSpeech reads out the items on the group's default shopping list, oldest first
+func shoppingListSpeech(ctx context.Context, member models.User) string {
+	cursor, err := config.DB.Collection("shopping_cart").Find(
+		ctx,
+		bson.M{"group_id": member.GroupID, "list_id": models.ListFilter(primitive.NilObjectID)},
+		options.Find().SetSort(bson.D{{Key: "added_at", Value: 1}}),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch the shopping list of group %s: %v", member.GroupID.Hex(), err)
+		return voiceErrorSpeech
+	}
+	var items []models.ShoppingCartItem
+	if err := cursor.All(ctx, &items); err != nil {
+		log.Printf("Failed to decode the shopping list of group %s: %v", member.GroupID.Hex(), err)
+		return voiceErrorSpeech
+	}
+
+	spoken := make([]string, 0, len(items))
+	for _, item := range items {
+		spoken = append(spoken, voice.SpokenItem(item.ItemName, item.Quantity, item.Unit))
+	}
+	return voice.ShoppingListSpeech(spoken)
+}
@@ -0,0 +1,363 @@
+// handlers/webhooks.go
+package handlers
+
+import (
+	"context"
+	"cribb-backend/apierror"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreateWebhookRequest defines the request structure for registering a webhook
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events,omitempty"` // Empty subscribes to every event
+	Format      string   `json:"format,omitempty"` // json (default) or discord
+	Description string   `json:"description,omitempty"`
+}
+
+// UpdateWebhookRequest defines the request structure for changing a webhook; fields left out are
+// unchanged
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url,omitempty"`
+	Events      *[]string `json:"events,omitempty"`
+	Format      *string   `json:"format,omitempty"`
+	Description *string   `json:"description,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// WebhookSecretResponse is a webhook with its signing secret, which is only shown when the webhook
+// is created or its secret is rotated
+type WebhookSecretResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// parseWebhookEvents checks the events a webhook subscribes to
+func parseWebhookEvents(names []string) ([]models.WebhookEvent, error) {
+	events := make([]models.WebhookEvent, 0, len(names))
+	for _, name := range names {
+		event := models.WebhookEvent(strings.TrimSpace(name))
+		if !models.IsValidWebhookEvent(event) {
+			return nil, fmt.Errorf("unknown webhook event %q", name)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// getAdminGroup loads the requesting user's group and checks they are one of its admins. It
+// writes the error response itself and returns false on failure.
+func getAdminGroup(w http.ResponseWriter, r *http.Request, action string) (models.User, models.Group, bool) {
+	user, ok := getRequestUser(w, r)
+	if !ok {
+		return models.User{}, models.Group{}, false
+	}
+	group, ok := getGroupByID(w, user.GroupID)
+	if !ok {
+		return models.User{}, models.Group{}, false
+	}
+	if !group.IsAdmin(user.ID) {
+		http.Error(w, "Only group admins can "+action, http.StatusForbidden)
+		return models.User{}, models.Group{}, false
+	}
+	return user, group, true
+}
+
+// WebhooksHandler lists the group's webhooks on GET and registers one on POST. Only group admins
+// manage webhooks.
+func WebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	user, group, ok := getAdminGroup(w, r, "manage webhooks")
+	if !ok {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		listWebhooks(w, group)
+	case http.MethodPost:
+		createWebhook(w, r, user, group)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listWebhooks returns the group's webhooks, oldest first, without their secrets
+func listWebhooks(w http.ResponseWriter, group models.Group) {
+	cursor, err := config.DB.Collection("webhooks").Find(
+		context.Background(),
+		bson.M{"group_id": group.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch webhooks", http.StatusInternalServerError)
+		return
+	}
+	hooks := make([]models.Webhook, 0)
+	if err := cursor.All(context.Background(), &hooks); err != nil {
+		http.Error(w, "Failed to decode webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hooks)
+}
+
+// createWebhook registers a webhook and returns it with its secret
+func createWebhook(w http.ResponseWriter, r *http.Request, user models.User, group models.Group) {
+	var request CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	request.URL = strings.TrimSpace(request.URL)
+	if err := models.ValidateWebhookURL(request.URL); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := parseWebhookEvents(request.Events)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format := models.WebhookFormat(request.Format)
+	if !models.IsValidWebhookFormat(format) {
+		http.Error(w, "Format must be json or discord", http.StatusBadRequest)
+		return
+	}
+
+	count, err := config.DB.Collection("webhooks").CountDocuments(context.Background(), bson.M{"group_id": group.ID})
+	if err != nil {
+		http.Error(w, "Failed to count webhooks", http.StatusInternalServerError)
+		return
+	}
+	if count >= models.MaxWebhooksPerGroup {
+		writeError(w, r, apierror.CodeLimitReached, fmt.Sprintf("A group can have at most %d webhooks", models.MaxWebhooksPerGroup))
+		return
+	}
+
+	hook, err := models.CreateWebhook(group.ID, user.ID, request.URL, events, format, strings.TrimSpace(request.Description))
+	if err != nil {
+		log.Printf("Failed to create webhook secret: %v", err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	result, err := config.DB.Collection("webhooks").InsertOne(context.Background(), hook)
+	if err != nil {
+		log.Printf("Failed to create webhook: %v", err)
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	hook.ID = result.InsertedID.(primitive.ObjectID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(WebhookSecretResponse{Webhook: *hook, Secret: hook.Secret})
+}
+
+// WebhookHandler manages one of the group's webhooks: PUT /api/groups/webhooks/{id} changes it,
+// DELETE removes it, GET /api/groups/webhooks/{id}/deliveries lists its recent deliveries, POST
+// /api/groups/webhooks/{id}/test sends it a ping event and POST /api/groups/webhooks/{id}/secret
+// replaces its secret. Only group admins manage webhooks.
+func WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	_, group, ok := getAdminGroup(w, r, "manage webhooks")
+	if !ok {
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/groups/webhooks/"), "/"), "/")
+	webhookID, err := primitive.ObjectIDFromHex(parts[0])
+	if err != nil || len(parts) > 2 {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	var hook models.Webhook
+	err = config.DB.Collection("webhooks").FindOne(context.Background(), bson.M{"_id": webhookID, "group_id": group.ID}).Decode(&hook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Webhook not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Failed to fetch webhook", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	switch {
+	case action == "" && r.Method == http.MethodPut:
+		updateWebhook(w, r, hook)
+	case action == "" && r.Method == http.MethodDelete:
+		deleteWebhook(w, hook)
+	case action == "deliveries" && r.Method == http.MethodGet:
+		listWebhookDeliveries(w, hook)
+	case action == "test" && r.Method == http.MethodPost:
+		testWebhook(w, hook)
+	case action == "secret" && r.Method == http.MethodPost:
+		rotateWebhookSecret(w, hook)
+	case action == "" || action == "deliveries" || action == "test" || action == "secret":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// updateWebhook changes the fields given in the request
+func updateWebhook(w http.ResponseWriter, r *http.Request, hook models.Webhook) {
+	var request UpdateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if request.URL != nil {
+		hook.URL = strings.TrimSpace(*request.URL)
+		if err := models.ValidateWebhookURL(hook.URL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Events != nil {
+		events, err := parseWebhookEvents(*request.Events)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		hook.Events = events
+	}
+	if request.Format != nil {
+		hook.Format = models.WebhookFormat(*request.Format)
+		if !models.IsValidWebhookFormat(hook.Format) {
+			http.Error(w, "Format must be json or discord", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.Description != nil {
+		hook.Description = strings.TrimSpace(*request.Description)
+	}
+	if request.Active != nil {
+		hook.Active = *request.Active
+	}
+	hook.UpdatedAt = time.Now()
+
+	_, err := config.DB.Collection("webhooks").UpdateOne(
+		context.Background(),
+		bson.M{"_id": hook.ID},
+		bson.M{"$set": bson.M{
+			"url":         hook.URL,
+			"events":      hook.Events,
+			"format":      hook.Format,
+			"description": hook.Description,
+			"active":      hook.Active,
+			"updated_at":  hook.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		log.Printf("Failed to update webhook %s: %v", hook.ID.Hex(), err)
+		http.Error(w, "Failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// deleteWebhook removes the webhook and its deliveries
+func deleteWebhook(w http.ResponseWriter, hook models.Webhook) {
+	if _, err := config.DB.Collection("webhooks").DeleteOne(context.Background(), bson.M{"_id": hook.ID}); err != nil {
+		log.Printf("Failed to delete webhook %s: %v", hook.ID.Hex(), err)
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+	if _, err := config.DB.Collection("webhook_deliveries").DeleteMany(context.Background(), bson.M{"webhook_id": hook.ID}); err != nil {
+		log.Printf("Failed to delete deliveries of webhook %s: %v", hook.ID.Hex(), err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Webhook deleted successfully"})
+}
+
+// listWebhookDeliveries returns the webhook's 50 most recent deliveries, newest first
+func listWebhookDeliveries(w http.ResponseWriter, hook models.Webhook) {
+	cursor, err := config.DB.Collection("webhook_deliveries").Find(
+		context.Background(),
+		bson.M{"webhook_id": hook.ID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(50),
+	)
+	if err != nil {
+		http.Error(w, "Failed to fetch webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	deliveries := make([]models.WebhookDelivery, 0)
+	if err := cursor.All(context.Background(), &deliveries); err != nil {
+		http.Error(w, "Failed to decode webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+// testWebhook queues a ping event for the webhook, whatever events it subscribes to
+func testWebhook(w http.ResponseWriter, hook models.Webhook) {
+	if !hook.Active {
+		http.Error(w, "Turn the webhook on to test it", http.StatusConflict)
+		return
+	}
+	delivery, err := webhooks.NewDelivery(hook, models.WebhookEventPing, "Test delivery from Cribb",
+		map[string]string{"webhook_id": hook.ID.Hex()}, time.Now())
+	if err == nil {
+		_, err = config.DB.Collection("webhook_deliveries").InsertOne(context.Background(), delivery)
+	}
+	if err != nil {
+		log.Printf("Failed to queue test delivery for webhook %s: %v", hook.ID.Hex(), err)
+		http.Error(w, "Failed to queue test delivery", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+// rotateWebhookSecret replaces the webhook's secret and returns the new one. Deliveries already
+// queued are signed with the new secret when they are sent.
+func rotateWebhookSecret(w http.ResponseWriter, hook models.Webhook) {
+	secret, err := models.NewWebhookSecret()
+	if err != nil {
+		log.Printf("Failed to create webhook secret: %v", err)
+		http.Error(w, "Failed to rotate secret", http.StatusInternalServerError)
+		return
+	}
+	hook.Secret = secret
+	hook.UpdatedAt = time.Now()
+	_, err = config.DB.Collection("webhooks").UpdateOne(
+		context.Background(),
+		bson.M{"_id": hook.ID},
+		bson.M{"$set": bson.M{"secret": hook.Secret, "updated_at": hook.UpdatedAt}},
+	)
+	if err != nil {
+		log.Printf("Failed to rotate secret of webhook %s: %v", hook.ID.Hex(), err)
+		http.Error(w, "Failed to rotate secret", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(WebhookSecretResponse{Webhook: hook, Secret: hook.Secret})
+}
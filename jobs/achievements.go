@@ -0,0 +1,67 @@
+// jobs/achievements.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// EvaluateAchievements checks every badge rule for a user and awards badges they have newly earned.
+// It is called after chore completions and streak updates.
+func EvaluateAchievements(userID primitive.ObjectID) {
+	ctx := context.Background()
+
+	var user models.User
+	if err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		log.Printf("Failed to load user %s for achievements: %v", userID.Hex(), err)
+		return
+	}
+
+	stats, err := achievementStats(ctx, user)
+	if err != nil {
+		log.Printf("Failed to compute achievement stats for user %s: %v", userID.Hex(), err)
+		return
+	}
+
+	for _, key := range models.EarnedBadgeKeys(stats) {
+		badge := models.CreateUserBadge(user.ID, user.GroupID, key)
+		_, err := config.DB.Collection("user_badges").InsertOne(ctx, badge)
+		if err != nil {
+			// The unique index on user_id and badge_key means the badge was already awarded
+			if !mongo.IsDuplicateKeyError(err) {
+				log.Printf("Failed to award badge %s to user %s: %v", key, userID.Hex(), err)
+			}
+			continue
+		}
+		log.Printf("Awarded badge %s to user %s", key, userID.Hex())
+	}
+}
+
+// achievementStats gathers the numbers badge rules are evaluated against
+func achievementStats(ctx context.Context, user models.User) (models.AchievementStats, error) {
+	stats := models.AchievementStats{BestDailyStreak: user.Streak.BestDaily}
+
+	total, err := config.DB.Collection("chore_completions").CountDocuments(ctx, bson.M{"user_id": user.ID})
+	if err != nil {
+		return stats, err
+	}
+	stats.TotalCompletions = int(total)
+
+	dishes, err := config.DB.Collection("chores").CountDocuments(ctx, bson.M{
+		"assigned_to": user.ID,
+		"status":      models.ChoreStatusCompleted,
+		"title":       bson.M{"$regex": "dish", "$options": "i"},
+	})
+	if err != nil {
+		return stats, err
+	}
+	stats.DishesCompletions = int(dishes)
+
+	return stats, nil
+}
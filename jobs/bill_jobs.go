@@ -0,0 +1,323 @@
+// jobs/bill_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/sms"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StartBillJobs initializes and starts the recurring bill scheduler and due-date reminders
+func StartBillJobs() {
+	log.Println("Starting bill jobs...")
+
+	// Run every hour, like the chore scheduler
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go runBillJobs()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			runBillJobs()
+		}
+	}()
+}
+
+// runBillJobs creates the expenses for bills that are due, then reminds members of upcoming ones
+func runBillJobs() {
+	processRecurringBills()
+	sendBillReminders()
+}
+
+// processRecurringBills creates an expense for every occurrence of a recurring bill that has come due.
+// Missed occurrences are always backfilled since the money is owed either way.
+func processRecurringBills() {
+	if !IsLeader() {
+		return
+	}
+	log.Println("Processing recurring bills...")
+
+	now := time.Now()
+	cursor, err := config.DB.Collection("recurring_bills").Find(
+		context.Background(),
+		bson.M{
+			"is_active":     true,
+			"next_due_date": bson.M{"$lte": now},
+		},
+	)
+	if err != nil {
+		log.Printf("Error finding recurring bills: %v", err)
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var bills []models.RecurringBill
+	if err = cursor.All(context.Background(), &bills); err != nil {
+		log.Printf("Error decoding recurring bills: %v", err)
+		return
+	}
+
+	for _, bill := range bills {
+		if leaseLost("recurring bill processing") {
+			return
+		}
+		session, err := config.DB.Client().StartSession()
+		if err != nil {
+			log.Printf("Error starting session for recurring bill %s: %v", bill.ID.Hex(), err)
+			continue
+		}
+
+		func(s mongo.Session, rb models.RecurringBill) {
+			defer s.EndSession(context.Background())
+
+			_, err := s.WithTransaction(context.Background(), func(ctx mongo.SessionContext) (interface{}, error) {
+				// Get fresh copy of the bill to avoid creating the same expense twice
+				var freshBill models.RecurringBill
+				if err := config.DB.Collection("recurring_bills").FindOne(ctx, bson.M{"_id": rb.ID}).Decode(&freshBill); err != nil {
+					return nil, err
+				}
+				if freshBill.NextDueDate.After(now) || !freshBill.IsActive {
+					return nil, nil
+				}
+
+				var group models.Group
+				if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": freshBill.GroupID}).Decode(&group); err != nil {
+					return nil, err
+				}
+
+				windows := models.MissedWindows(freshBill.NextDueDate, freshBill.Frequency, now)
+				if len(windows) > maxBackfillWindows {
+					log.Printf("Recurring bill %s missed %d due dates, only the latest %d are created", freshBill.ID.Hex(), len(windows)-1, maxBackfillWindows)
+					windows = windows[len(windows)-maxBackfillWindows:]
+				}
+
+				var rentConfigs []models.RentConfig
+				if freshBill.UsesRentConfig {
+					configs, err := models.LoadRentConfigs(ctx, config.DB, freshBill.GroupID)
+					if err != nil {
+						return nil, err
+					}
+					rentConfigs = configs
+				}
+				var meterReadings []models.MeterReading
+				if freshBill.UsageMeter != "" {
+					readings, err := models.LoadMeterReadings(ctx, config.DB, bson.M{"group_id": freshBill.GroupID, "meter": freshBill.UsageMeter})
+					if err != nil {
+						return nil, err
+					}
+					meterReadings = readings
+				}
+
+				for _, dueDate := range windows {
+					// Each occurrence is billed at the rent in effect on its due date
+					if rentConfig := models.RentConfigAt(rentConfigs, dueDate); rentConfig != nil {
+						freshBill.ApplyRentConfig(*rentConfig)
+					}
+					// and split by the usage over its own billing period, without changing the bill
+					occurrence := freshBill
+					if freshBill.UsageMeter != "" {
+						from, to := freshBill.BillingPeriod(dueDate)
+						if !occurrence.ApplyMeterUsage(group.Members, models.MeterConsumption(meterReadings, from, to)) {
+							log.Printf("Meter readings do not cover the period of recurring bill %s due %s, using its own split", freshBill.ID.Hex(), dueDate.Format("2006-01-02"))
+						}
+					}
+					splits, err := occurrence.Splits(group.Members)
+					if err != nil {
+						return nil, err
+					}
+					expense := occurrence.ExpenseFor(dueDate, splits)
+					expense.Currency = group.Settings.CurrencyCode()
+					if _, err := config.DB.Collection("expenses").InsertOne(ctx, expense); err != nil {
+						return nil, err
+					}
+					freshBill.OccurrenceCount++
+				}
+
+				update := bson.M{
+					"next_due_date":    models.NextOccurrence(freshBill.Frequency, windows[len(windows)-1]),
+					"occurrence_count": freshBill.OccurrenceCount,
+					"updated_at":       time.Now(),
+				}
+				if freshBill.UsesRentConfig {
+					// Show the rent last billed on the bill itself
+					update["amount"] = freshBill.Amount
+					update["split_method"] = freshBill.SplitMethod
+					update["participants"] = freshBill.Participants
+				}
+				_, err := config.DB.Collection("recurring_bills").UpdateOne(ctx, bson.M{"_id": freshBill.ID}, bson.M{"$set": update})
+				if err != nil {
+					return nil, err
+				}
+
+				log.Printf("Created %d expenses for recurring bill %s", len(windows), freshBill.ID.Hex())
+				return nil, nil
+			})
+
+			if err != nil {
+				log.Printf("Error processing recurring bill %s: %v", rb.ID.Hex(), err)
+			}
+		}(session, bill)
+	}
+
+	log.Printf("Processed %d recurring bills", len(bills))
+}
+
+// sendBillReminders tells each member sharing an upcoming bill what their share is and when it is due
+func sendBillReminders() {
+	if !IsLeader() {
+		return
+	}
+
+	now := time.Now()
+	ctx := context.Background()
+
+	// Find bills due within the longest reminder window; each bill's own window is applied below
+	cursor, err := config.DB.Collection("recurring_bills").Find(
+		ctx,
+		bson.M{
+			"is_active":     true,
+			"reminder_days": bson.M{"$gt": 0},
+			"next_due_date": bson.M{
+				"$gt":  now,
+				"$lte": now.AddDate(0, 0, models.MaxBillReminderDays),
+			},
+		},
+	)
+	if err != nil {
+		log.Printf("Error finding upcoming bills: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var bills []models.RecurringBill
+	if err = cursor.All(ctx, &bills); err != nil {
+		log.Printf("Error decoding upcoming bills: %v", err)
+		return
+	}
+
+	for _, bill := range bills {
+		if leaseLost("bill reminders") {
+			return
+		}
+		if !bill.NeedsReminder(now) {
+			continue
+		}
+
+		var group models.Group
+		if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": bill.GroupID}).Decode(&group); err != nil {
+			log.Printf("Error loading group %s for bill reminders: %v", bill.GroupID.Hex(), err)
+			continue
+		}
+		if bill.UsesRentConfig {
+			rentConfigs, err := models.LoadRentConfigs(ctx, config.DB, bill.GroupID)
+			if err != nil {
+				log.Printf("Error loading rent configuration for bill reminders: %v", err)
+				continue
+			}
+			if rentConfig := models.RentConfigAt(rentConfigs, bill.NextDueDate); rentConfig != nil {
+				bill.ApplyRentConfig(*rentConfig)
+			}
+		}
+		if bill.UsageMeter != "" {
+			// Estimate the shares from the readings so far; the final split is made on the due date
+			readings, err := models.LoadMeterReadings(ctx, config.DB, bson.M{"group_id": bill.GroupID, "meter": bill.UsageMeter})
+			if err != nil {
+				log.Printf("Error loading meter readings for bill reminders: %v", err)
+				continue
+			}
+			from, to := bill.BillingPeriod(bill.NextDueDate)
+			bill.ApplyMeterUsage(group.Members, models.MeterConsumption(readings, from, to))
+		}
+		splits, err := bill.Splits(group.Members)
+		if err != nil {
+			log.Printf("Error splitting recurring bill %s for reminders: %v", bill.ID.Hex(), err)
+			continue
+		}
+
+		// Claim the reminder first so it is sent once even if another run overlaps
+		result, err := config.DB.Collection("recurring_bills").UpdateOne(
+			ctx,
+			bson.M{"_id": bill.ID, "next_due_date": bill.NextDueDate, "reminded_for": bson.M{"$ne": bill.NextDueDate}},
+			bson.M{"$set": bson.M{"reminded_for": bill.NextDueDate}},
+		)
+		if err != nil {
+			log.Printf("Error marking recurring bill %s reminded: %v", bill.ID.Hex(), err)
+			continue
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+
+		dueDate := bill.NextDueDate.Format("Jan 2")
+		currencyCode := group.Settings.CurrencyCode()
+		if bill.IsRent() {
+			textRentDue(ctx, bill, splits, currencyCode, now)
+		}
+		for _, share := range splits {
+			params := models.NotificationParams{
+				"bill":     bill.Description,
+				"amount":   fmt.Sprintf("%.2f", bill.Amount),
+				"share":    fmt.Sprintf("%.2f", share.Amount),
+				"currency": currencyCode,
+				"due":      models.NotificationDate(bill.NextDueDate),
+			}
+			if share.UserID == bill.PaidBy {
+				params["paying"] = "true"
+			}
+
+			notification := models.CreateTemplatedNotification(share.UserID, bill.GroupID, models.NotificationTypeBillDue, models.TemplateBillDue, params)
+			if _, err := config.DB.Collection("notifications").InsertOne(ctx, notification); err != nil {
+				log.Printf("Error creating bill reminder for recurring bill %s: %v", bill.ID.Hex(), err)
+			}
+		}
+		log.Printf("Sent reminders for recurring bill %s due %s", bill.ID.Hex(), dueDate)
+	}
+}
+
+// textRentDue texts the members sharing a rent bill, other than the one paying it, their share
+// when they opted in to SMS alerts
+func textRentDue(ctx context.Context, bill models.RecurringBill, splits []models.CostShare, currencyCode string, now time.Time) {
+	if !sms.Enabled() {
+		return
+	}
+	shares := make(map[primitive.ObjectID]float64, len(splits))
+	ids := make([]primitive.ObjectID, 0, len(splits))
+	for _, share := range splits {
+		if share.UserID != bill.PaidBy {
+			shares[share.UserID] = share.Amount
+			ids = append(ids, share.UserID)
+		}
+	}
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "preferences.notifications.sms_alerts": true})
+	if err != nil {
+		log.Printf("Error finding members to text about recurring bill %s: %v", bill.ID.Hex(), err)
+		return
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		log.Printf("Error decoding members to text about recurring bill %s: %v", bill.ID.Hex(), err)
+		return
+	}
+
+	for _, user := range users {
+		body := models.RenderText(models.TemplateSMSRentDue, user.Locale(), models.NotificationParams{
+			"bill":     bill.Description,
+			"due":      models.NotificationDate(bill.NextDueDate),
+			"share":    fmt.Sprintf("%.2f", shares[user.ID]),
+			"currency": currencyCode,
+		})
+		if _, err := sms.SendToUser(ctx, user, models.NotificationEventBills, body, now); err != nil {
+			log.Printf("Error texting user %s about recurring bill %s: %v", user.ID.Hex(), bill.ID.Hex(), err)
+		}
+	}
+}
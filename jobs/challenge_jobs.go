@@ -0,0 +1,222 @@
+// jobs/challenge_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StartChallengeJobs initializes and starts the job that settles challenges whose window has ended
+func StartChallengeJobs() {
+	log.Println("Starting challenge jobs...")
+
+	// Check every hour so challenges are settled soon after they end
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go settleEndedChallenges()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			settleEndedChallenges()
+		}
+	}()
+}
+
+// settleEndedChallenges updates every active challenge whose window has closed
+func settleEndedChallenges() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	groupIDs, err := config.DB.Collection("challenges").Distinct(ctx, "group_id", bson.M{
+		"status":   models.ChallengeStatusActive,
+		"end_date": bson.M{"$lte": time.Now()},
+	})
+	if err != nil {
+		log.Printf("Error finding ended challenges: %v", err)
+		return
+	}
+
+	for _, id := range groupIDs {
+		if leaseLost("challenge settlement") {
+			return
+		}
+		groupID, ok := id.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		if err := UpdateGroupChallenges(ctx, groupID); err != nil {
+			log.Printf("Error updating challenges for group %s: %v", groupID.Hex(), err)
+		}
+	}
+}
+
+// UpdateGroupChallenges recomputes the progress of a group's active challenges from its
+// chores and completions, and notifies the group of any challenge it has just completed.
+// It is called after chore completions, when challenges are listed and by the settle job.
+func UpdateGroupChallenges(ctx context.Context, groupID primitive.ObjectID) error {
+	cursor, err := config.DB.Collection("challenges").Find(ctx, bson.M{
+		"group_id": groupID,
+		"status":   models.ChallengeStatusActive,
+		"start_date": bson.M{
+			"$lte": time.Now(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	var challenges []models.Challenge
+	if err = cursor.All(ctx, &challenges); err != nil {
+		return err
+	}
+
+	for _, challenge := range challenges {
+		completed, due, err := challengeCounts(ctx, challenge)
+		if err != nil {
+			return err
+		}
+
+		justCompleted := challenge.UpdateProgress(completed, due, time.Now())
+
+		// Only the update that moves the challenge out of active wins, so the group is notified once
+		result, err := config.DB.Collection("challenges").UpdateOne(
+			ctx,
+			bson.M{"_id": challenge.ID, "status": models.ChallengeStatusActive},
+			bson.M{"$set": bson.M{
+				"progress":     challenge.Progress,
+				"target":       challenge.Target,
+				"status":       challenge.Status,
+				"completed_at": challenge.CompletedAt,
+				"updated_at":   challenge.UpdatedAt,
+			}},
+		)
+		if err != nil {
+			return err
+		}
+
+		if justCompleted && result.ModifiedCount > 0 {
+			notifyChallengeCompleted(ctx, challenge)
+		}
+	}
+	return nil
+}
+
+// challengeCounts returns the completions that count towards a challenge and the number of chores due in its window
+func challengeCounts(ctx context.Context, challenge models.Challenge) (int, int, error) {
+	window := bson.M{"$gte": challenge.StartDate, "$lt": challenge.EndDate}
+
+	if challenge.Goal == models.ChallengeGoalCompletionCount {
+		// Completions within the window of chores belonging to the group
+		pipeline := mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"completed_at": window}}},
+			{{Key: "$lookup", Value: bson.M{
+				"from":         "chores",
+				"localField":   "chore_id",
+				"foreignField": "_id",
+				"as":           "chore",
+			}}},
+			{{Key: "$match", Value: bson.M{"chore.group_id": challenge.GroupID}}},
+			{{Key: "$count", Value: "completed"}},
+		}
+		completed, err := countFromPipeline(ctx, "chore_completions", pipeline, "completed")
+		return completed, 0, err
+	}
+
+	// Chores due within the window, and those of them completed before the window closed
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"group_id": challenge.GroupID, "due_date": window}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "chore_completions",
+			"localField":   "_id",
+			"foreignField": "chore_id",
+			"as":           "completions",
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": nil,
+			"due": bson.M{"$sum": 1},
+			"completed": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{
+					bson.M{"$size": bson.M{"$filter": bson.M{
+						"input": "$completions",
+						"as":    "completion",
+						"cond":  bson.M{"$lt": bson.A{"$$completion.completed_at", challenge.EndDate}},
+					}}},
+					0,
+				}},
+				1, 0,
+			}}},
+		}}},
+	}
+
+	cursor, err := config.DB.Collection("chores").Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	var totals []struct {
+		Due       int `bson:"due"`
+		Completed int `bson:"completed"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return 0, 0, err
+	}
+	if len(totals) == 0 {
+		return 0, 0, nil
+	}
+	return totals[0].Completed, totals[0].Due, nil
+}
+
+// countFromPipeline runs a pipeline ending in a $count stage and returns the count, 0 when nothing matched
+func countFromPipeline(ctx context.Context, collection string, pipeline mongo.Pipeline, field string) (int, error) {
+	cursor, err := config.DB.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	var results []bson.M
+	if err = cursor.All(ctx, &results); err != nil {
+		return 0, err
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	switch count := results[0][field].(type) {
+	case int32:
+		return int(count), nil
+	case int64:
+		return int(count), nil
+	}
+	return 0, nil
+}
+
+// notifyChallengeCompleted tells every group member that the group completed a challenge
+func notifyChallengeCompleted(ctx context.Context, challenge models.Challenge) {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": challenge.GroupID}).Decode(&group); err != nil {
+		log.Printf("Failed to load group %s for challenge notification: %v", challenge.GroupID.Hex(), err)
+		return
+	}
+
+	params := models.NotificationParams{"challenge": challenge.Title, "reward": challenge.Reward}
+
+	for _, memberID := range group.Members {
+		notification := models.CreateTemplatedNotification(
+			memberID,
+			group.ID,
+			models.NotificationTypeChallengeCompleted,
+			models.TemplateChallengeCompleted,
+			params,
+		)
+		if _, err := config.DB.Collection("notifications").InsertOne(ctx, notification); err != nil {
+			log.Printf("Failed to create challenge notification for user %s: %v", memberID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// jobs/chat_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/chatbot"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartChatJobs initializes and starts posting the daily chore summary to the Slack and Discord
+// channels groups connected. Every instance takes part, since each summary is claimed before it
+// is posted.
+func StartChatJobs() {
+	log.Println("Starting chat jobs...")
+
+	// Run every 5 minutes so summaries go out close to the time the group chose
+	ticker := time.NewTicker(5 * time.Minute)
+
+	// Run immediately once at startup
+	go postChatSummaries()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			postChatSummaries()
+		}
+	}()
+}
+
+// postChatSummaries posts every daily summary that is due
+func postChatSummaries() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var integration models.ChatIntegration
+		err := config.DB.Collection("chat_integrations").FindOne(
+			ctx,
+			bson.M{"next_summary_at": bson.M{"$lte": now}},
+			options.FindOne().SetSort(bson.D{{Key: "next_summary_at", Value: 1}}),
+		).Decode(&integration)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching due chat summaries: %v", err)
+			return
+		}
+
+		// Claim the summary by moving it on to tomorrow; another instance may have got there first
+		due := *integration.NextSummaryAt
+		integration.ScheduleSummary(now)
+		result, err := config.DB.Collection("chat_integrations").UpdateOne(ctx,
+			bson.M{"_id": integration.ID, "next_summary_at": due},
+			bson.M{"$set": bson.M{"next_summary_at": integration.NextSummaryAt}},
+		)
+		if err != nil {
+			log.Printf("Error claiming chat summary %s: %v", integration.ID.Hex(), err)
+			return
+		}
+		// Summaries missed while the server was down for hours are skipped rather than sent late
+		if result.MatchedCount == 0 || now.Sub(due) > time.Hour {
+			continue
+		}
+		if err := PostChatSummary(ctx, integration, now); err != nil {
+			log.Printf("Error posting chat summary %s: %v", integration.ID.Hex(), err)
+		}
+	}
+}
+
+// PostChatSummary posts the group's open chores due on the day, in the integration's time zone,
+// and those overdue to the integration's webhook
+func PostChatSummary(ctx context.Context, integration models.ChatIntegration, now time.Time) error {
+	if integration.WebhookURL == "" {
+		return errors.New("the integration has no webhook URL")
+	}
+	local := now.In(integration.Zone())
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, integration.Zone())
+
+	cursor, err := config.DB.Collection("chores").Find(
+		ctx,
+		bson.M{
+			"group_id": integration.GroupID,
+			"status":   bson.M{"$ne": models.ChoreStatusCompleted},
+			"due_date": bson.M{"$lt": startOfDay.AddDate(0, 0, 1)},
+		},
+		options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}).SetLimit(50),
+	)
+	if err != nil {
+		return err
+	}
+	var chores []models.Chore
+	if err := cursor.All(ctx, &chores); err != nil {
+		return err
+	}
+
+	names, err := memberNames(ctx, integration.GroupID)
+	if err != nil {
+		return err
+	}
+	summary := make([]chatbot.SummaryChore, 0, len(chores))
+	for _, chore := range chores {
+		summary = append(summary, chatbot.SummaryChore{
+			Title:    chore.Title,
+			Assignee: names[chore.AssignedTo],
+			Overdue:  chore.DueDate.Before(startOfDay) || chore.Status == models.ChoreStatusOverdue,
+		})
+	}
+	return chatbot.Post(ctx, integration.Platform, integration.WebhookURL, chatbot.DailySummary(local, summary))
+}
+
+// memberNames maps the group's members to the names they go by
+func memberNames(ctx context.Context, groupID primitive.ObjectID) (map[primitive.ObjectID]string, error) {
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"group_id": groupID})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(users))
+	for _, user := range users {
+		names[user.ID] = user.Name
+		if user.Name == "" {
+			names[user.ID] = user.Username
+		}
+	}
+	return names, nil
+}
@@ -5,13 +5,45 @@ import (
 	"context"
 	"cribb-backend/config"
 	"cribb-backend/models"
+	"cribb-backend/webhooks"
 	"log"
+	"os"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// maxBackfillWindows caps how many missed occurrences are recreated for a single recurring chore
+const maxBackfillWindows = 30
+
+// defaultCatchUpPolicy is used for recurring chores without their own policy.
+// It can be set with SCHEDULER_CATCH_UP_POLICY and defaults to skipping forward.
+var defaultCatchUpPolicy = loadDefaultCatchUpPolicy()
+
+func loadDefaultCatchUpPolicy() models.CatchUpPolicy {
+	policy := models.CatchUpPolicy(strings.TrimSpace(os.Getenv("SCHEDULER_CATCH_UP_POLICY")))
+	switch policy {
+	case models.CatchUpPolicyBackfill, models.CatchUpPolicySkipForward:
+		return policy
+	case "":
+		return models.CatchUpPolicySkipForward
+	default:
+		log.Printf("Unknown SCHEDULER_CATCH_UP_POLICY %q, defaulting to %s", policy, models.CatchUpPolicySkipForward)
+		return models.CatchUpPolicySkipForward
+	}
+}
+
+// catchUpPolicyFor returns the chore's own catch-up policy, falling back to the configured default
+func catchUpPolicyFor(rc models.RecurringChore) models.CatchUpPolicy {
+	if rc.CatchUpPolicy != "" {
+		return rc.CatchUpPolicy
+	}
+	return defaultCatchUpPolicy
+}
+
 // StartChoreScheduler initializes and starts the recurring chore scheduler
 func StartChoreScheduler() {
 	log.Println("Starting chore scheduler...")
@@ -34,6 +66,9 @@ func StartChoreScheduler() {
 
 // processRecurringChores checks for recurring chores that need new instances created
 func processRecurringChores() {
+	if !IsLeader() {
+		return
+	}
 	log.Println("Processing recurring chores...")
 
 	// Find all active recurring chores that need to create new instances
@@ -59,6 +94,9 @@ func processRecurringChores() {
 	}
 
 	for _, recurringChore := range recurringChores {
+		if leaseLost("recurring chore processing") {
+			return
+		}
 		// Start a session for each recurring chore
 		session, err := config.DB.Client().StartSession()
 		if err != nil {
@@ -70,8 +108,14 @@ func processRecurringChores() {
 		func(s mongo.Session, rc models.RecurringChore) {
 			defer s.EndSession(context.Background())
 
+			var ended *models.RecurringChore
+			var assigned []models.Chore
+
 			// Execute in a transaction
 			_, err := s.WithTransaction(context.Background(), func(ctx mongo.SessionContext) (interface{}, error) {
+				ended = nil
+				assigned = nil
+
 				// Get fresh copy of recurring chore to avoid race conditions
 				var freshRC models.RecurringChore
 				err := config.DB.Collection("recurring_chores").FindOne(
@@ -88,37 +132,75 @@ func processRecurringChores() {
 					return nil, nil
 				}
 
-				// Create a new chore instance
-				newChore := models.CreateChoreFromRecurring(&freshRC)
-				_, err = config.DB.Collection("chores").InsertOne(ctx, newChore)
+				// Every assignment window that has passed; more than one means the server was down
+				windows := models.MissedWindows(freshRC.NextAssignment, freshRC.Frequency, now)
+				if len(windows) > 1 {
+					missed := len(windows) - 1
+					if catchUpPolicyFor(freshRC) == models.CatchUpPolicySkipForward {
+						log.Printf("Recurring chore %s missed %d assignment windows, skipping forward", freshRC.ID.Hex(), missed)
+						windows = windows[missed:]
+					} else {
+						if len(windows) > maxBackfillWindows {
+							log.Printf("Recurring chore %s missed %d assignment windows, only the latest %d are backfilled", freshRC.ID.Hex(), missed, maxBackfillWindows)
+							windows = windows[len(windows)-maxBackfillWindows:]
+						} else {
+							log.Printf("Recurring chore %s missed %d assignment windows, backfilling", freshRC.ID.Hex(), missed)
+						}
+					}
+				}
+
+				blackouts, err := GetGroupBlackouts(ctx, freshRC.GroupID, windows[0])
 				if err != nil {
 					return nil, err
 				}
 
-				// Calculate next assignment date
-				var nextAssignment time.Time
-				switch freshRC.Frequency {
-				case "daily":
-					nextAssignment = now.Add(24 * time.Hour)
-				case "weekly":
-					nextAssignment = now.Add(7 * 24 * time.Hour)
-				case "biweekly":
-					nextAssignment = now.Add(14 * 24 * time.Hour)
-				case "monthly":
-					nextAssignment = now.AddDate(0, 1, 0)
-				default:
-					nextAssignment = now.Add(7 * 24 * time.Hour) // Default to weekly
+				for _, assignAt := range windows {
+					// The run may end part way through, or may have ended already (e.g. the until date was moved)
+					if freshRC.HasEnded(assignAt) {
+						break
+					}
+
+					// Create a new chore instance, unless it falls on a blackout date and the policy drops it
+					previousIndex := freshRC.CurrentIndex
+					newChore := models.CreateChoreFromRecurringAt(&freshRC, assignAt)
+					if newChore.AssignedTo.IsZero() {
+						log.Printf("Recurring chore %s has no eligible members to assign", freshRC.ID.Hex())
+						break
+					}
+					if dueDate, ok := models.ApplyBlackouts(newChore.DueDate, freshRC.BlackoutPolicy, blackouts); ok {
+						newChore.DueDate = dueDate
+						result, err := config.DB.Collection("chores").InsertOne(ctx, newChore)
+						if err != nil {
+							return nil, err
+						}
+						newChore.ID = result.InsertedID.(primitive.ObjectID)
+						if _, err := config.DB.Collection("notifications").InsertOne(ctx, models.ChoreAssignedNotification(newChore)); err != nil {
+							return nil, err
+						}
+						assigned = append(assigned, *newChore)
+						freshRC.OccurrenceCount++
+					} else {
+						// The skipped member stays next in line
+						freshRC.CurrentIndex = previousIndex
+						log.Printf("Skipped occurrence of recurring chore %s due to a blackout date", freshRC.ID.Hex())
+					}
 				}
 
+				// Calculate next assignment date and whether this was the last instance
+				nextAssignment := models.NextOccurrence(freshRC.Frequency, windows[len(windows)-1])
+				isActive := !freshRC.HasEnded(nextAssignment)
+
 				// Update the recurring chore with the new next assignment date
 				_, err = config.DB.Collection("recurring_chores").UpdateOne(
 					ctx,
 					bson.M{"_id": freshRC.ID},
 					bson.M{
 						"$set": bson.M{
-							"next_assignment": nextAssignment,
-							"current_index":   freshRC.CurrentIndex,
-							"updated_at":      time.Now(),
+							"next_assignment":  nextAssignment,
+							"current_index":    freshRC.CurrentIndex,
+							"occurrence_count": freshRC.OccurrenceCount,
+							"is_active":        isActive,
+							"updated_at":       time.Now(),
 						},
 					},
 				)
@@ -127,12 +209,24 @@ func processRecurringChores() {
 					return nil, err
 				}
 
-				log.Printf("Created new chore instance from recurring chore %s", freshRC.ID.Hex())
+				if !isActive {
+					ended = &freshRC
+				}
+
+				log.Printf("Processed %d assignment windows for recurring chore %s", len(windows), freshRC.ID.Hex())
 				return nil, nil
 			})
 
 			if err != nil {
 				log.Printf("Error processing recurring chore %s: %v", rc.ID.Hex(), err)
+				return
+			}
+
+			for _, chore := range assigned {
+				webhooks.ChoreAssigned(context.Background(), chore)
+			}
+			if ended != nil {
+				NotifyRecurringChoreEnded(*ended)
 			}
 		}(session, recurringChore)
 	}
@@ -140,8 +234,56 @@ func processRecurringChores() {
 	log.Printf("Processed %d recurring chores", len(recurringChores))
 }
 
+// GetGroupBlackouts returns the group's blackout dates that have not ended before from
+func GetGroupBlackouts(ctx context.Context, groupID primitive.ObjectID, from time.Time) ([]models.BlackoutDate, error) {
+	cursor, err := config.DB.Collection("blackout_dates").Find(
+		ctx,
+		bson.M{
+			"group_id": groupID,
+			// Blackouts cover whole days, so keep any range that ends on or after the day before from
+			"end_date": bson.M{"$gte": from.AddDate(0, 0, -1)},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var blackouts []models.BlackoutDate
+	if err = cursor.All(ctx, &blackouts); err != nil {
+		return nil, err
+	}
+	return blackouts, nil
+}
+
+// NotifyRecurringChoreEnded tells the creator of a recurring chore that it has finished its run
+func NotifyRecurringChoreEnded(rc models.RecurringChore) {
+	log.Printf("Recurring chore %s finished its run and was deactivated", rc.ID.Hex())
+
+	// Recurring chores created before creators were tracked have nobody to notify
+	if rc.CreatedBy.IsZero() {
+		return
+	}
+
+	notification := models.CreateTemplatedNotification(
+		rc.CreatedBy,
+		rc.GroupID,
+		models.NotificationTypeRecurringChoreEnded,
+		models.TemplateRecurringChoreEnded,
+		models.NotificationParams{"chore": rc.Title},
+	)
+
+	_, err := config.DB.Collection("notifications").InsertOne(context.Background(), notification)
+	if err != nil {
+		log.Printf("Failed to create notification for recurring chore %s: %v", rc.ID.Hex(), err)
+	}
+}
+
 // detectOverdueChores finds and marks overdue chores
 func detectOverdueChores() {
+	if !IsLeader() {
+		return
+	}
 	log.Println("Detecting overdue chores...")
 
 	// Calculate the start of today in UTC
@@ -151,27 +293,17 @@ func detectOverdueChores() {
 	// Any pending chore whose due date is strictly before the start of today UTC
 	// has had its entire due day pass and should now be considered overdue.
 
-	result, err := config.DB.Collection("chores").UpdateMany(
-		context.Background(),
-		bson.M{
-			"status":   models.ChoreStatusPending,
-			"due_date": bson.M{"$lt": startOfTodayUTC},
-		},
-		bson.M{
-			"$set": bson.M{
-				"status":     models.ChoreStatusOverdue,
-				"updated_at": time.Now(),
-			},
-		},
-	)
+	marked, err := MarkChoresOverdue(context.Background(), bson.M{
+		"due_date": bson.M{"$lt": startOfTodayUTC},
+	})
 
 	if err != nil {
 		log.Printf("Error updating overdue chores: %v", err)
 		return
 	}
 
-	if result.ModifiedCount > 0 {
-		log.Printf("Marked %d chores as overdue", result.ModifiedCount)
+	if marked > 0 {
+		log.Printf("Marked %d chores as overdue", marked)
 	} else {
 		log.Printf("No overdue chores found")
 	}
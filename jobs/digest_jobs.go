@@ -0,0 +1,321 @@
+// jobs/digest_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/email"
+	"cribb-backend/models"
+	"cribb-backend/notifications"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartDigestJobs initializes and starts the daily and weekly digests, sent by email and push.
+// Nothing runs when neither is configured.
+func StartDigestJobs() {
+	if !email.Enabled() && !notifications.Enabled() {
+		return
+	}
+	log.Println("Starting digest jobs...")
+
+	// Run every hour so digests held back by quiet hours go out soon after they end
+	ticker := time.NewTicker(1 * time.Hour)
+
+	go func() {
+		for range ticker.C {
+			sendDigests()
+		}
+	}()
+}
+
+// digestChannels reports whether the member's digest goes out by email, unless they muted it or
+// cannot be emailed, and by push, if they asked for it
+func digestChannels(user models.User) (byEmail, byPush bool) {
+	preferences := user.Preferences.Notifications
+	_, hasAddress := user.EmailAddress()
+	return email.Enabled() && hasAddress && !preferences.MuteWeeklyDigest, notifications.Enabled() && preferences.DigestPush
+}
+
+// sendDigests sends every member whose digest is due a summary of their chores, expiring pantry
+// items, the group's shopping and their balance, as far as they want each in the digest. Members
+// the digest cannot reach, who want nothing in it or are in their quiet hours are skipped; the last
+// are tried again on a later run.
+func sendDigests() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{
+		"group_id": bson.M{"$exists": true, "$ne": primitive.NilObjectID},
+	})
+	if err != nil {
+		log.Printf("Error finding members for digests: %v", err)
+		return
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		log.Printf("Error decoding members for digests: %v", err)
+		return
+	}
+
+	due := make(map[primitive.ObjectID][]models.User)
+	for _, user := range users {
+		preferences := user.Preferences.Notifications
+		if byEmail, byPush := digestChannels(user); !byEmail && !byPush {
+			continue
+		}
+		if !user.DigestDue(now) || preferences.QuietHours.Contains(now) || !preferences.DigestCovers(models.NotificationEvents...) {
+			continue
+		}
+		due[user.GroupID] = append(due[user.GroupID], user)
+	}
+	for groupID, members := range due {
+		if leaseLost("weekly digests") {
+			return
+		}
+		if err := sendGroupDigests(ctx, groupID, members, now); err != nil {
+			log.Printf("Error sending digests for group %s: %v", groupID.Hex(), err)
+		}
+	}
+}
+
+// sendGroupDigests sends the digest to members of one group
+func sendGroupDigests(ctx context.Context, groupID primitive.ObjectID, members []models.User, now time.Time) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": groupID}).Decode(&group); err != nil {
+		return err
+	}
+	expenses, settlements, err := groupLedger(ctx, group)
+	if err != nil {
+		return err
+	}
+	balances := models.ComputeBalances(expenses, settlements, group.Members)
+
+	for _, user := range members {
+		since := user.DigestPeriodStart(now)
+
+		// Claim the digest first so it is sent once even if another run overlaps
+		result, err := config.DB.Collection("users").UpdateOne(
+			ctx,
+			bson.M{"_id": user.ID, "last_digest_at": user.LastDigestAt},
+			bson.M{"$set": bson.M{"last_digest_at": now}},
+		)
+		if err != nil {
+			log.Printf("Error recording digest for user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+		if result.ModifiedCount == 0 {
+			continue
+		}
+
+		data, err := digestData(ctx, user, group, balances, models.BalancesBefore(expenses, settlements, group.Members, since), since, now)
+		if err != nil {
+			log.Printf("Error building digest for user %s: %v", user.ID.Hex(), err)
+			continue
+		}
+
+		byEmail, byPush := digestChannels(user)
+		if byEmail {
+			address, _ := user.EmailAddress()
+			message, err := email.WeeklyDigestEmail(address, data)
+			if err == nil {
+				err = email.Send(ctx, message)
+			}
+			if err != nil {
+				log.Printf("Error emailing digest to user %s: %v", user.ID.Hex(), err)
+			}
+		}
+		if byPush {
+			message := notifications.Message{
+				Title:       "Your " + data.Period + " in " + group.Name,
+				Body:        data.Summary(),
+				Data:        map[string]string{"type": "digest", "group_id": group.ID.Hex()},
+				CollapseKey: "digest-" + group.ID.Hex(),
+			}
+			if _, err := notifications.QueuePush(ctx, user, message); err != nil {
+				log.Printf("Error pushing digest to user %s: %v", user.ID.Hex(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// digestData gathers the member's chores, expiring pantry items, the group's shopping and the
+// member's balance since the time for their digest, leaving out what they do not want in it
+func digestData(ctx context.Context, user models.User, group models.Group, balances, previousBalances []models.MemberBalance, since, now time.Time) (email.WeeklyDigestData, error) {
+	preferences := user.Preferences.Notifications
+	frequency := preferences.DigestFrequency
+	ahead := now.Add(frequency.Interval())
+	data := email.WeeklyDigestData{
+		Name:      user.Name,
+		GroupName: group.Name,
+		Period:    frequency.Period(),
+		AppURL:    config.FrontendURL,
+	}
+
+	if preferences.DigestCovers(models.NotificationEventShopping) {
+		itemsBought, err := itemsBoughtSince(ctx, group.ID, since)
+		if err != nil {
+			return data, err
+		}
+		data.ItemsBought = itemsBought
+	}
+	if preferences.DigestCovers(models.NotificationEventPantry) {
+		expiring, err := expiringPantryItems(ctx, user, now, ahead)
+		if err != nil {
+			return data, err
+		}
+		for _, item := range expiring {
+			data.ExpiringItems = append(data.ExpiringItems, email.DigestPantryItem{Name: item.Name, Expires: item.ExpirationDate.Format("Mon Jan 2")})
+		}
+	}
+	if preferences.DigestCovers(models.NotificationEventExpenses) {
+		currencyCode := group.Settings.CurrencyCode()
+		net, previous := 0.0, 0.0
+		if balance := models.FindBalance(balances, user.ID); balance != nil {
+			net = balance.Net
+		}
+		if balance := models.FindBalance(previousBalances, user.ID); balance != nil {
+			previous = balance.Net
+		}
+		data.Balance = models.BalanceSummary(net, currencyCode)
+		data.BalanceChange = models.BalanceChangeSummary(previous, net, currencyCode)
+	}
+	if !preferences.DigestCovers(models.NotificationEventChoreAssigned, models.NotificationEventChoreCompleted, models.NotificationEventChoreOverdue) {
+		data.HideChores = true
+		return data, nil
+	}
+
+	completed, err := config.DB.Collection("chore_completions").CountDocuments(ctx, bson.M{
+		"user_id":      user.ID,
+		"completed_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return data, err
+	}
+	groupCompleted, err := config.DB.Collection("chore_completions").CountDocuments(ctx, bson.M{
+		"user_id":      bson.M{"$in": group.Members},
+		"completed_at": bson.M{"$gte": since},
+	})
+	if err != nil {
+		return data, err
+	}
+
+	cursor, err := config.DB.Collection("chores").Find(
+		ctx,
+		bson.M{
+			"assigned_to": user.ID,
+			"group_id":    group.ID,
+			"status":      bson.M{"$in": []models.ChoreStatus{models.ChoreStatusPending, models.ChoreStatusOverdue}},
+			"due_date":    bson.M{"$lte": ahead},
+		},
+		options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}}),
+	)
+	if err != nil {
+		return data, err
+	}
+	var chores []models.Chore
+	if err := cursor.All(ctx, &chores); err != nil {
+		return data, err
+	}
+
+	data.ChoresCompleted = int(completed)
+	data.GroupChoresCompleted = int(groupCompleted)
+	data.WeeklyScore = user.WeeklyScore
+	for _, chore := range chores {
+		entry := email.DigestChore{Title: chore.Title, Due: chore.DueDate.Format("Mon Jan 2")}
+		if chore.Status == models.ChoreStatusOverdue || chore.DueDate.Before(now) {
+			data.OverdueChores = append(data.OverdueChores, entry)
+		} else {
+			data.UpcomingChores = append(data.UpcomingChores, entry)
+		}
+	}
+	return data, nil
+}
+
+// maxDigestItems caps how many bought or expiring items a digest lists
+const maxDigestItems = 10
+
+// itemsBoughtSince lists the names of the items the group bought since the time, newest first and
+// each once
+func itemsBoughtSince(ctx context.Context, groupID primitive.ObjectID, since time.Time) ([]string, error) {
+	cursor, err := config.DB.Collection("purchase_history").Find(
+		ctx,
+		bson.M{"group_id": groupID, "purchased_at": bson.M{"$gte": since}},
+		options.Find().SetSort(bson.D{{Key: "purchased_at", Value: -1}}).SetProjection(bson.M{"item_name": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var records []models.PurchaseRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, maxDigestItems)
+	seen := make(map[string]bool)
+	for _, record := range records {
+		key := strings.ToLower(record.ItemName)
+		if seen[key] || len(names) == maxDigestItems {
+			continue
+		}
+		seen[key] = true
+		names = append(names, record.ItemName)
+	}
+	return names, nil
+}
+
+// expiringPantryItems lists the shared pantry items of the member's group, and their own personal
+// ones, that are in stock and expire between now and before, soonest first
+func expiringPantryItems(ctx context.Context, user models.User, now, before time.Time) ([]models.PantryItem, error) {
+	cursor, err := config.DB.Collection("pantry_items").Find(
+		ctx,
+		bson.M{
+			"group_id":        user.GroupID,
+			"quantity":        bson.M{"$gt": 0},
+			"expiration_date": bson.M{"$gte": now, "$lte": before},
+			"$or": []bson.M{
+				{"visibility": bson.M{"$ne": models.VisibilityPersonal}},
+				{"owner_id": user.ID},
+			},
+		},
+		options.Find().SetSort(bson.D{{Key: "expiration_date", Value: 1}}).SetLimit(maxDigestItems),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var items []models.PantryItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// groupLedger fetches every expense and settlement of the group
+func groupLedger(ctx context.Context, group models.Group) ([]models.Expense, []models.Settlement, error) {
+	var expenses []models.Expense
+	cursor, err := config.DB.Collection("expenses").Find(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cursor.All(ctx, &expenses); err != nil {
+		return nil, nil, err
+	}
+	var settlements []models.Settlement
+	cursor, err = config.DB.Collection("settlements").Find(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cursor.All(ctx, &settlements); err != nil {
+		return nil, nil, err
+	}
+	return expenses, settlements, nil
+}
@@ -0,0 +1,146 @@
+// jobs/escalation_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/email"
+	"cribb-backend/models"
+	"cribb-backend/sms"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StartEscalationJobs initializes and starts telling members about chores left overdue, by email
+// and SMS. Nothing runs when neither is configured.
+func StartEscalationJobs() {
+	if !email.Enabled() && !sms.Enabled() {
+		return
+	}
+	log.Println("Starting overdue chore escalation jobs...")
+
+	ticker := time.NewTicker(1 * time.Hour)
+
+	go func() {
+		for range ticker.C {
+			escalateOverdueChores()
+		}
+	}()
+}
+
+// escalateOverdueChores tells the assignee and the group's admins about every chore that has
+// stayed overdue for models.ChoreEscalationDelay. Each chore is escalated once.
+func escalateOverdueChores() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	now := time.Now()
+	cursor, err := config.DB.Collection("chores").Find(ctx, bson.M{
+		"status":       models.ChoreStatusOverdue,
+		"escalated_at": bson.M{"$exists": false},
+		"due_date":     bson.M{"$lte": now.Add(-models.ChoreEscalationDelay)},
+	})
+	if err != nil {
+		log.Printf("Error finding overdue chores to escalate: %v", err)
+		return
+	}
+	var chores []models.Chore
+	if err := cursor.All(ctx, &chores); err != nil {
+		log.Printf("Error decoding overdue chores to escalate: %v", err)
+		return
+	}
+
+	for _, chore := range chores {
+		if leaseLost("overdue chore escalation") {
+			return
+		}
+		if !chore.NeedsEscalation(now) {
+			continue
+		}
+		result, err := config.DB.Collection("chores").UpdateOne(
+			ctx,
+			bson.M{"_id": chore.ID, "status": models.ChoreStatusOverdue, "escalated_at": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"escalated_at": now}},
+		)
+		if err != nil {
+			log.Printf("Error recording escalation of chore %s: %v", chore.ID.Hex(), err)
+			continue
+		}
+		if result.ModifiedCount == 0 {
+			continue // Completed or escalated concurrently
+		}
+		if err := escalateChore(ctx, chore, now); err != nil {
+			log.Printf("Error escalating chore %s: %v", chore.ID.Hex(), err)
+		}
+	}
+}
+
+// escalateChore emails the chore's assignee and the admins of its group, and texts the assignee if
+// they opted in to SMS alerts, as far as each wants overdue chores on those channels. Groups without admins treat every member as one, so the whole group
+// hears about it.
+func escalateChore(ctx context.Context, chore models.Chore, now time.Time) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": chore.GroupID}).Decode(&group); err != nil {
+		return err
+	}
+	recipients := []primitive.ObjectID{chore.AssignedTo}
+	for _, memberID := range group.Members {
+		if memberID != chore.AssignedTo && group.IsAdmin(memberID) {
+			recipients = append(recipients, memberID)
+		}
+	}
+
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": recipients}})
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+	assigneeName := "a housemate"
+	for _, user := range users {
+		if user.ID == chore.AssignedTo {
+			assigneeName = user.Name
+		}
+	}
+
+	for _, user := range users {
+		if user.ID == chore.AssignedTo {
+			body := models.RenderText(models.TemplateSMSChoreOverdue, user.Locale(), models.NotificationParams{
+				"chore": chore.Title,
+				"due":   models.NotificationDate(chore.DueDate),
+			})
+			if _, err := sms.SendToUser(ctx, user, models.NotificationEventChoreOverdue, body, now); err != nil {
+				log.Printf("Error texting user %s about overdue chore %s: %v", user.ID.Hex(), chore.ID.Hex(), err)
+			}
+		}
+
+		address, ok := user.EmailAddress()
+		if !email.Enabled() || !ok || !user.Preferences.Notifications.Allows(models.NotificationEventChoreOverdue, models.NotificationChannelEmail) {
+			continue
+		}
+		message, err := email.ChoreEscalationEmail(address, email.ChoreEscalationData{
+			Name:         user.Name,
+			AssigneeName: assigneeName,
+			GroupName:    group.Name,
+			ChoreTitle:   chore.Title,
+			DueDate:      chore.DueDate.Format("Mon Jan 2"),
+			DaysOverdue:  chore.DaysOverdue(now),
+			ToAssignee:   user.ID == chore.AssignedTo,
+			AppURL:       config.FrontendURL,
+		})
+		if err == nil {
+			err = email.Send(ctx, message)
+		}
+		if err != nil {
+			log.Printf("Error emailing user %s about overdue chore %s: %v", user.ID.Hex(), chore.ID.Hex(), err)
+		}
+	}
+	return nil
+}
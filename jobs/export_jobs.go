@@ -0,0 +1,229 @@
+// jobs/export_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/storage"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartExportJobs initializes and starts writing the group exports admins request and removing
+// expired ones. Every instance takes part, since each export is claimed before it is written.
+func StartExportJobs() {
+	log.Println("Starting group export jobs...")
+
+	ticker := time.NewTicker(30 * time.Second)
+
+	go func() {
+		for range ticker.C {
+			runGroupExports()
+			removeExpiredExports()
+		}
+	}()
+}
+
+// runGroupExports writes every requested export, oldest first. Exports left running past their
+// lease by an instance that stopped are started over.
+func runGroupExports() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var export models.GroupExport
+		err := config.DB.Collection("group_exports").FindOneAndUpdate(
+			ctx,
+			bson.M{"$or": []bson.M{
+				{"status": models.GroupExportPending},
+				{"status": models.GroupExportRunning, "started_at": bson.M{"$lt": now.Add(-models.GroupExportLease)}},
+			}},
+			bson.M{"$set": bson.M{"status": models.GroupExportRunning, "started_at": now}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "created_at", Value: 1}}).SetReturnDocument(options.After),
+		).Decode(&export)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming group export: %v", err)
+			return
+		}
+		runGroupExport(ctx, export)
+	}
+}
+
+// runGroupExport writes one export to file storage and records the outcome. Finished and failed
+// exports are both kept until models.GroupExportRetention has passed.
+func runGroupExport(ctx context.Context, export models.GroupExport) {
+	fileID, size, counts, err := writeGroupExport(ctx, export)
+
+	now := time.Now()
+	update := bson.M{"completed_at": now, "expires_at": now.Add(models.GroupExportRetention)}
+	if err != nil {
+		log.Printf("Error exporting group %s: %v", export.GroupID.Hex(), err)
+		update["status"] = models.GroupExportFailed
+		update["error"] = "The export could not be written; request a new one"
+	} else {
+		update["status"] = models.GroupExportCompleted
+		update["file_id"] = fileID
+		update["size"] = size
+		update["counts"] = counts
+	}
+
+	result, err := config.DB.Collection("group_exports").UpdateOne(
+		ctx,
+		bson.M{"_id": export.ID, "status": models.GroupExportRunning, "started_at": export.StartedAt},
+		bson.M{"$set": update},
+	)
+	if err != nil {
+		log.Printf("Error recording group export %s: %v", export.ID.Hex(), err)
+	}
+	// Another instance took the export over, so its archive is the one kept
+	if (err != nil || result.MatchedCount == 0) && !fileID.IsZero() {
+		if err := storage.Delete(ctx, fileID); err != nil {
+			log.Printf("Error removing archive of group export %s: %v", export.ID.Hex(), err)
+		}
+	}
+}
+
+// writeGroupExport streams the group's documents into an archive in file storage, returning the
+// archive's file ID and size and how many documents each collection had
+func writeGroupExport(ctx context.Context, export models.GroupExport) (primitive.ObjectID, int64, map[string]int, error) {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": export.GroupID}).Decode(&group); err != nil {
+		return primitive.NilObjectID, 0, nil, err
+	}
+
+	reader, writer := io.Pipe()
+	counts := make(map[string]int, len(models.GroupExportCollections))
+	written := make(chan error, 1)
+	go func() {
+		err := writeGroupDocuments(ctx, group, models.NewGroupExportWriter(writer, export.Format), counts)
+		writer.CloseWithError(err)
+		written <- err
+	}()
+
+	archive := &countingReader{r: reader}
+	fileID, err := storage.SaveExport(export.FileName(), export.ContentType(), archive)
+	// Unblock the writer if the upload stopped early
+	reader.CloseWithError(errors.New("upload stopped"))
+	if writeErr := <-written; writeErr != nil {
+		err = writeErr
+	}
+	if err != nil {
+		if !fileID.IsZero() {
+			storage.Delete(ctx, fileID)
+		}
+		return primitive.NilObjectID, 0, nil, err
+	}
+	return fileID, archive.n, counts, nil
+}
+
+// writeGroupDocuments writes every exported collection of the group and the manifest
+func writeGroupDocuments(ctx context.Context, group models.Group, archive models.GroupExportWriter, counts map[string]int) error {
+	var choreIDs []interface{}
+	for _, collection := range models.GroupExportCollections {
+		var filter bson.M
+		switch collection.Scope {
+		case models.ExportGroup:
+			filter = bson.M{"_id": group.ID}
+		case models.ExportByChore:
+			if choreIDs == nil {
+				ids, err := config.DB.Collection("chores").Distinct(ctx, "_id", bson.M{"group_id": group.ID})
+				if err != nil {
+					return err
+				}
+				choreIDs = append(ids, primitive.NilObjectID) // Never an empty $in
+			}
+			filter = bson.M{"chore_id": bson.M{"$in": choreIDs}}
+		default:
+			filter = bson.M{"group_id": group.ID}
+		}
+
+		opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+		if len(collection.Omit) > 0 {
+			projection := bson.M{}
+			for _, field := range collection.Omit {
+				projection[field] = 0
+			}
+			opts.SetProjection(projection)
+		}
+
+		cursor, err := config.DB.Collection(collection.Name).Find(ctx, filter, opts)
+		if err != nil {
+			return err
+		}
+		if err := archive.Collection(collection.Name); err != nil {
+			cursor.Close(ctx)
+			return err
+		}
+		count := 0
+		for cursor.Next(ctx) {
+			if err := archive.Document(cursor.Current); err != nil {
+				cursor.Close(ctx)
+				return err
+			}
+			count++
+		}
+		err = cursor.Err()
+		cursor.Close(ctx)
+		if err != nil {
+			return err
+		}
+		counts[collection.Name] = count
+	}
+
+	return archive.Close(models.GroupExportManifest{
+		Version:     models.GroupExportVersion,
+		GroupID:     group.ID,
+		GroupName:   group.Name,
+		ExportedAt:  time.Now(),
+		Collections: counts,
+	})
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// removeExpiredExports deletes exports and their archives once they can no longer be downloaded
+func removeExpiredExports() {
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("group_exports").Find(ctx, bson.M{"expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		log.Printf("Error finding expired group exports: %v", err)
+		return
+	}
+	var exports []models.GroupExport
+	if err := cursor.All(ctx, &exports); err != nil {
+		log.Printf("Error decoding expired group exports: %v", err)
+		return
+	}
+
+	for _, export := range exports {
+		if export.FileID != nil {
+			if err := storage.Delete(ctx, *export.FileID); err != nil {
+				log.Printf("Error removing archive of group export %s: %v", export.ID.Hex(), err)
+				continue
+			}
+		}
+		if _, err := config.DB.Collection("group_exports").DeleteOne(ctx, bson.M{"_id": export.ID}); err != nil {
+			log.Printf("Error removing group export %s: %v", export.ID.Hex(), err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// jobs/guest_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartGuestJobs initializes and starts telling members about guests who arrive soon. Every
+// instance takes part, since each stay is claimed before members are told.
+func StartGuestJobs() {
+	log.Println("Starting guest jobs...")
+
+	// Run every hour; arrivals are dates, so the notice does not need to be more precise
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go notifyGuestArrivals()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			notifyGuestArrivals()
+		}
+	}()
+}
+
+// notifyGuestArrivals tells the group about every guest arriving within models.GuestArrivalNotice
+// whose arrival has not been announced yet
+func notifyGuestArrivals() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var stay models.GuestStay
+		err := config.DB.Collection("guest_stays").FindOneAndUpdate(
+			ctx,
+			bson.M{
+				"arrive_on":           bson.M{"$gte": now.UTC().Truncate(24 * time.Hour), "$lte": now.Add(models.GuestArrivalNotice)},
+				"arrival_notified_at": bson.M{"$exists": false},
+			},
+			bson.M{"$set": bson.M{"arrival_notified_at": now}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "arrive_on", Value: 1}}),
+		).Decode(&stay)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming guest arrival: %v", err)
+			return
+		}
+		if err := notifyGuestArrival(ctx, stay); err != nil {
+			log.Printf("Error notifying members of guest stay %s: %v", stay.ID.Hex(), err)
+		}
+	}
+}
+
+// notifyGuestArrival notifies the group's members other than the host that the guest arrives soon
+func notifyGuestArrival(ctx context.Context, stay models.GuestStay) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": stay.GroupID}).Decode(&group); err != nil {
+		return err
+	}
+	var host models.User
+	if err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": stay.HostID}).Decode(&host); err != nil {
+		return err
+	}
+
+	documents := make([]interface{}, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != stay.HostID {
+			documents = append(documents, models.GuestArrivingNotification(stay, memberID, host.Name))
+		}
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+	_, err := config.DB.Collection("notifications").InsertMany(ctx, documents)
+	return err
+}
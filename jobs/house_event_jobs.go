@@ -0,0 +1,80 @@
+// jobs/house_event_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartHouseEventJobs initializes and starts reminding members of house events about to start.
+// Every instance takes part, since each event is claimed before its reminders are sent.
+func StartHouseEventJobs() {
+	log.Println("Starting house event jobs...")
+
+	// Run every minute so reminders arrive close to the time asked for
+	ticker := time.NewTicker(1 * time.Minute)
+
+	go func() {
+		for range ticker.C {
+			sendHouseEventReminders()
+		}
+	}()
+}
+
+// sendHouseEventReminders reminds the members of every event whose reminder is due, oldest first.
+// Events that started while reminders were down are not reminded of.
+func sendHouseEventReminders() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var event models.HouseEvent
+		err := config.DB.Collection("house_events").FindOneAndUpdate(
+			ctx,
+			bson.M{
+				"remind_at":   bson.M{"$lte": now},
+				"reminded_at": bson.M{"$exists": false},
+				"starts_at":   bson.M{"$gt": now},
+			},
+			bson.M{"$set": bson.M{"reminded_at": now}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "remind_at", Value: 1}}),
+		).Decode(&event)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming house event reminder: %v", err)
+			return
+		}
+		if err := remindHouseEvent(ctx, event); err != nil {
+			log.Printf("Error reminding members of house event %s: %v", event.ID.Hex(), err)
+		}
+	}
+}
+
+// remindHouseEvent notifies the group's members of the event, except those not going
+func remindHouseEvent(ctx context.Context, event models.HouseEvent) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": event.GroupID}).Decode(&group); err != nil {
+		return err
+	}
+
+	documents := make([]interface{}, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if event.RSVP(memberID) != models.RSVPNotGoing {
+			documents = append(documents, models.HouseEventReminderNotification(&event, memberID))
+		}
+	}
+	if len(documents) == 0 {
+		return nil
+	}
+	_, err := config.DB.Collection("notifications").InsertMany(ctx, documents)
+	return err
+}
@@ -0,0 +1,126 @@
+// jobs/leader.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// schedulerLeaseID identifies the single lease shared by every backend instance
+	schedulerLeaseID = "scheduler"
+
+	// leaseDuration is how long a lease stays valid without a heartbeat
+	leaseDuration = 30 * time.Second
+
+	// heartbeatInterval is how often the lease is renewed or contended for
+	heartbeatInterval = 10 * time.Second
+
+	// leaseSafetyMargin is how long before its lease expires this instance stops counting itself
+	// leader, leaving room for clock skew with the instance that would take over
+	leaseSafetyMargin = 5 * time.Second
+)
+
+var (
+	instanceID = buildInstanceID()
+
+	// heldLease is this instance's copy of the scheduler lease while it holds it, nil otherwise
+	heldLease atomic.Pointer[models.SchedulerLease]
+)
+
+// buildInstanceID returns a name for this process, overridable with SCHEDULER_INSTANCE_ID
+func buildInstanceID() string {
+	if id := strings.TrimSpace(os.Getenv("SCHEDULER_INSTANCE_ID")); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), time.Now().UnixNano())
+}
+
+// IsLeader reports whether this instance holds the scheduler lease, with leaseSafetyMargin to spare.
+//
+// A lease that is not renewed in time lapses even while a job is running, and another instance can
+// then start the same job. Jobs therefore check IsLeader before every unit of work they write, not
+// just once when they start (see leaseLost), and each unit must be idempotent: one that was under
+// way when the lease lapsed may be repeated by the new leader.
+func IsLeader() bool {
+	lease := heldLease.Load()
+	return lease != nil && lease.HeldBy(instanceID, time.Now().Add(leaseSafetyMargin))
+}
+
+// leaseLost reports whether this instance stopped being leader during a job, logging that the job stops
+func leaseLost(job string) bool {
+	if IsLeader() {
+		return false
+	}
+	log.Printf("Instance %s no longer holds the scheduler lease, stopping %s", instanceID, job)
+	return true
+}
+
+// StartLeaderElection makes a first attempt to acquire the scheduler lease and then keeps
+// renewing or contending for it, so that only one backend instance runs scheduled work
+func StartLeaderElection() {
+	log.Printf("Starting scheduler leader election as %s...", instanceID)
+
+	// Try once synchronously so the jobs started right after know whether to run
+	tryAcquireLease()
+
+	ticker := time.NewTicker(heartbeatInterval)
+	go func() {
+		for range ticker.C {
+			tryAcquireLease()
+		}
+	}()
+}
+
+// tryAcquireLease takes over an expired lease or extends the one this instance already holds
+func tryAcquireLease() {
+	ctx, cancel := context.WithTimeout(context.Background(), heartbeatInterval)
+	defer cancel()
+
+	// The expiry is counted from before the write, so this instance never believes its lease
+	// lasts longer than the stored one does
+	now := time.Now()
+	lease := models.SchedulerLease{ID: schedulerLeaseID}
+	lease.Acquire(instanceID, now, leaseDuration)
+
+	_, err := config.DB.Collection("scheduler_leases").UpdateOne(
+		ctx,
+		lease.AcquireFilter(instanceID, now),
+		bson.M{"$set": bson.M{
+			"holder":     lease.Holder,
+			"expires_at": lease.ExpiresAt,
+			"updated_at": lease.UpdatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+
+	if err != nil {
+		// A duplicate key means the lease exists and is held by another live instance
+		if !mongo.IsDuplicateKeyError(err) {
+			log.Printf("Error renewing scheduler lease: %v", err)
+		}
+		if heldLease.Swap(nil) != nil {
+			log.Printf("Instance %s lost scheduler leadership", instanceID)
+		}
+		return
+	}
+
+	if heldLease.Swap(&lease) == nil {
+		log.Printf("Instance %s acquired scheduler leadership", instanceID)
+	}
+}
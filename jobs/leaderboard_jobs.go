@@ -0,0 +1,234 @@
+// jobs/leaderboard_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// StartLeaderboardJobs initializes and starts the job that archives finished leaderboard periods
+func StartLeaderboardJobs() {
+	log.Println("Starting leaderboard jobs...")
+
+	// Check every hour so a period is archived soon after it ends
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go archiveLeaderboards()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			archiveLeaderboards()
+		}
+	}()
+}
+
+// ComputeLeaderboard totals the points each group member earned from completions in [start, end)
+func ComputeLeaderboard(ctx context.Context, groupID primitive.ObjectID, start, end time.Time) ([]models.LeaderboardEntry, error) {
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"group_id": groupID})
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.LeaderboardEntry, 0, len(users))
+	if len(users) == 0 {
+		return entries, nil
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	completedAt := bson.M{"$lt": end}
+	if !start.IsZero() {
+		completedAt["$gte"] = start
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"user_id":      bson.M{"$in": userIDs},
+			"completed_at": completedAt,
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         "$user_id",
+			"points":      bson.M{"$sum": "$points"},
+			"completions": bson.M{"$sum": 1},
+		}}},
+	}
+	cursor, err = config.DB.Collection("chore_completions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var totals []struct {
+		UserID      primitive.ObjectID `bson:"_id"`
+		Points      int                `bson:"points"`
+		Completions int                `bson:"completions"`
+	}
+	if err = cursor.All(ctx, &totals); err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[primitive.ObjectID]int, len(totals))
+	for i, total := range totals {
+		byUser[total.UserID] = i
+	}
+
+	for _, user := range users {
+		entry := models.LeaderboardEntry{
+			UserID:   user.ID,
+			Username: user.Username,
+			Name:     user.Name,
+		}
+		if i, ok := byUser[user.ID]; ok {
+			entry.Points = totals[i].Points
+			entry.Completions = totals[i].Completions
+		}
+		entries = append(entries, entry)
+	}
+
+	models.SortLeaderboard(entries)
+	return entries, nil
+}
+
+// archiveLeaderboards snapshots the previous weekly and monthly period of every group that has not been archived yet
+func archiveLeaderboards() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("groups").Find(ctx, bson.M{})
+	if err != nil {
+		log.Printf("Error finding groups for leaderboard archive: %v", err)
+		return
+	}
+	var groups []models.Group
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Printf("Error decoding groups for leaderboard archive: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, period := range []models.LeaderboardPeriod{models.LeaderboardPeriodWeekly, models.LeaderboardPeriodMonthly} {
+		currentStart, _ := models.PeriodBounds(period, now)
+		previousStart, previousEnd := models.PeriodBounds(period, currentStart.Add(-time.Nanosecond))
+
+		for _, group := range groups {
+			if leaseLost("leaderboard archiving") {
+				return
+			}
+			// Groups created after the period ended have nothing to archive
+			if group.CreatedAt.After(previousEnd) {
+				continue
+			}
+			if err := archiveGroupPeriod(ctx, group, period, previousStart, previousEnd); err != nil {
+				log.Printf("Error archiving %s leaderboard for group %s: %v", period, group.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// archiveGroupPeriod stores the snapshot of one finished period and resets period scores if the group wants it
+func archiveGroupPeriod(ctx context.Context, group models.Group, period models.LeaderboardPeriod, start, end time.Time) error {
+	err := config.DB.Collection("leaderboard_snapshots").FindOne(ctx, bson.M{
+		"group_id":     group.ID,
+		"period":       period,
+		"period_start": start,
+	}).Err()
+	if err == nil {
+		return nil // Already archived
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	entries, err := ComputeLeaderboard(ctx, group.ID, start, end)
+	if err != nil {
+		return err
+	}
+
+	snapshot := models.CreateLeaderboardSnapshot(group.ID, period, start, end, entries)
+
+	session, err := config.DB.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// Archive and reset together, so a failed reset is retried by the next run
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		if _, err := config.DB.Collection("leaderboard_snapshots").InsertOne(sc, snapshot); err != nil {
+			return nil, err
+		}
+		if group.Settings.ResetPeriodScores {
+			return nil, resetPeriodScores(sc, group.ID, period, end)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		// The unique index means another run archived it first
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return err
+	}
+
+	log.Printf("Archived %s leaderboard for group %s", period, group.ID.Hex())
+	return nil
+}
+
+// resetPeriodScores starts the period after end from the points members have earned since end.
+// The job can run well after the period ended, so clearing the scores would also drop points
+// already earned in the new period. It runs inside a transaction: score increments are written
+// in transactions together with their score events, so none can land between reading the events
+// and writing the scores.
+func resetPeriodScores(sc mongo.SessionContext, groupID primitive.ObjectID, period models.LeaderboardPeriod, end time.Time) error {
+	cursor, err := config.DB.Collection("score_events").Find(sc, bson.M{
+		"group_id":   groupID,
+		"type":       bson.M{"$in": models.PeriodScoreEventTypes},
+		"created_at": bson.M{"$gte": end},
+	})
+	if err != nil {
+		return err
+	}
+	var events []models.ScoreEvent
+	if err = cursor.All(sc, &events); err != nil {
+		return err
+	}
+	carried := models.PeriodScoresSince(events, end)
+
+	field := period.ScoreField()
+	carriedIDs := make([]primitive.ObjectID, 0, len(carried))
+	for userID, points := range carried {
+		_, err := config.DB.Collection("users").UpdateOne(
+			sc,
+			bson.M{"_id": userID, "group_id": groupID},
+			bson.M{"$set": bson.M{field: points}},
+		)
+		if err != nil {
+			return err
+		}
+		carriedIDs = append(carriedIDs, userID)
+	}
+
+	_, err = config.DB.Collection("users").UpdateMany(
+		sc,
+		bson.M{"group_id": groupID, "_id": bson.M{"$nin": carriedIDs}},
+		bson.M{"$set": bson.M{field: 0}},
+	)
+	return err
+}
@@ -0,0 +1,118 @@
+// jobs/overdue_penalty.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MarkChoresOverdue marks the pending chores matching filter as overdue and deducts
+// each group's overdue penalty from the assignees. Every chore is flipped individually
+// so a chore is only ever penalized once, whichever caller marks it first.
+func MarkChoresOverdue(ctx context.Context, filter bson.M) (int, error) {
+	filter["status"] = models.ChoreStatusPending
+
+	cursor, err := config.DB.Collection("chores").Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	var chores []models.Chore
+	if err = cursor.All(ctx, &chores); err != nil {
+		return 0, err
+	}
+
+	rules := make(map[primitive.ObjectID]models.ScoringRules)
+	marked := 0
+	for _, chore := range chores {
+		result, err := config.DB.Collection("chores").UpdateOne(
+			ctx,
+			bson.M{"_id": chore.ID, "status": models.ChoreStatusPending},
+			bson.M{"$set": bson.M{
+				"status":     models.ChoreStatusOverdue,
+				"updated_at": time.Now(),
+			}},
+		)
+		if err != nil {
+			return marked, err
+		}
+		if result.ModifiedCount == 0 {
+			continue // Completed or marked overdue concurrently
+		}
+		marked++
+
+		scoring, ok := rules[chore.GroupID]
+		if !ok {
+			var group models.Group
+			err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": chore.GroupID}).Decode(&group)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("Failed to fetch scoring rules for group %s: %v", chore.GroupID.Hex(), err)
+				continue
+			}
+			scoring = group.Settings.Scoring
+			rules[chore.GroupID] = scoring
+		}
+
+		if scoring.OverduePenalty > 0 {
+			if err := applyOverduePenalty(ctx, chore, scoring.OverduePenalty); err != nil {
+				log.Printf("Failed to apply overdue penalty for chore %s: %v", chore.ID.Hex(), err)
+			}
+		}
+	}
+
+	return marked, nil
+}
+
+// applyOverduePenalty deducts up to penalty points from the chore's assignee without taking the score
+// below zero. The same points come off the weekly and monthly scores, like completions add to all three.
+func applyOverduePenalty(ctx context.Context, chore models.Chore, penalty int) error {
+	deducted := bson.M{"$min": bson.A{penalty, bson.M{"$max": bson.A{0, "$score"}}}}
+	var before models.User
+	err := config.DB.Collection("users").FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": chore.AssignedTo},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{
+				"score":         bson.M{"$subtract": bson.A{"$score", deducted}},
+				"weekly_score":  bson.M{"$subtract": bson.A{"$weekly_score", deducted}},
+				"monthly_score": bson.M{"$subtract": bson.A{"$monthly_score", deducted}},
+				"updated_at":    time.Now(),
+			}}},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil // Assignee no longer exists
+		}
+		return err
+	}
+
+	amount := penalty
+	if before.Score < amount {
+		amount = before.Score
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	event := models.CreateScoreEvent(
+		before.ID,
+		chore.GroupID,
+		models.ScoreEventPenalty,
+		-amount,
+		before.Score-amount,
+		fmt.Sprintf("%s became overdue", chore.Title),
+	)
+	event.ReferenceID = &chore.ID
+	return RecordScoreEvent(ctx, event)
+}
@@ -5,20 +5,25 @@ import (
 	"context"
 	"cribb-backend/config"
 	"cribb-backend/models"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // StartPantryJobs initializes and starts the pantry background jobs
 func StartPantryJobs() {
 	log.Println("Starting pantry background jobs...")
 
-	// Run jobs every 6 hours
-	ticker := time.NewTicker(6 * time.Hour)
+	// Check stock every 6 hours and expiration dates daily
+	stockTicker := time.NewTicker(6 * time.Hour)
+	expirationTicker := time.NewTicker(24 * time.Hour)
 
 	// Run immediately once at startup
 	go checkExpiringItems()
@@ -26,55 +31,83 @@ func StartPantryJobs() {
 
 	// Then run on the schedule
 	go func() {
-		for range ticker.C {
-			checkExpiringItems()
+		for range stockTicker.C {
 			checkLowStockItems()
 		}
 	}()
+	go func() {
+		for range expirationTicker.C {
+			checkExpiringItems()
+		}
+	}()
 }
 
 // checkExpiringItems looks for items that will expire soon and creates notifications
 func checkExpiringItems() {
+	if !IsLeader() {
+		return
+	}
 	log.Println("Checking for expiring pantry items...")
 
-	// Find items that will expire in the next 3 days
 	now := time.Now()
-	expirationThreshold := now.AddDate(0, 0, 3)
+	ctx := context.Background()
 
-	// Find items that will expire soon but haven't been marked yet
-	// (no existing notification of type expiring_soon)
+	// Find items expiring within the longest window a group can choose; each group's own window is applied below
 	cursor, err := config.DB.Collection("pantry_items").Find(
-		context.Background(),
+		ctx,
 		bson.M{
 			"expiration_date": bson.M{
 				"$gte": now,
-				"$lte": expirationThreshold,
+				"$lte": now.AddDate(0, 0, models.MaxExpirationAlertDays),
 			},
 		},
+		options.Find().SetSort(bson.D{{Key: "expiration_date", Value: 1}}),
 	)
 
 	if err != nil {
 		log.Printf("Error finding expiring items: %v", err)
 		return
 	}
-	defer cursor.Close(context.Background())
+	defer cursor.Close(ctx)
 
-	var expiringItems []models.PantryItem
-	if err = cursor.All(context.Background(), &expiringItems); err != nil {
+	var candidates []models.PantryItem
+	if err = cursor.All(ctx, &candidates); err != nil {
 		log.Printf("Error decoding expiring items: %v", err)
 		return
 	}
 
+	windows := make(map[primitive.ObjectID]int)
+	newlyExpiring := make(map[primitive.ObjectID][]string)
+	var expiringItems []models.PantryItem
+
 	// Process each item and create notifications if needed
-	for _, item := range expiringItems {
-		// Check if a notification already exists for this item
+	for _, item := range candidates {
+		if leaseLost("expiring item checks") {
+			return
+		}
+		window, ok := windows[item.GroupID]
+		if !ok {
+			var group models.Group
+			if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": item.GroupID}).Decode(&group); err != nil {
+				log.Printf("Error loading group %s for expiring items: %v", item.GroupID.Hex(), err)
+				continue
+			}
+			window = group.Settings.ExpirationAlertWindow()
+			windows[item.GroupID] = window
+		}
+		if item.ExpirationDate.After(now.AddDate(0, 0, window)) {
+			continue
+		}
+		expiringItems = append(expiringItems, item)
+
+		// Check if a notification already exists for this item within the alert window
 		count, err := config.DB.Collection("pantry_notifications").CountDocuments(
-			context.Background(),
+			ctx,
 			bson.M{
 				"item_id": item.ID,
 				"type":    models.NotificationTypeExpiringSoon,
 				"created_at": bson.M{
-					"$gte": now.AddDate(0, 0, -3), // Only check for notifications in the last 3 days
+					"$gte": now.AddDate(0, 0, -window),
 				},
 			},
 		)
@@ -91,11 +124,11 @@ func checkExpiringItems() {
 				item.ID,
 				item.Name,
 				models.NotificationTypeExpiringSoon,
-				"Item will expire in 3 days or less",
+				fmt.Sprintf("Item will expire in %d days or less", window),
 			)
 
 			_, err = config.DB.Collection("pantry_notifications").InsertOne(
-				context.Background(),
+				ctx,
 				notification,
 			)
 
@@ -103,10 +136,16 @@ func checkExpiringItems() {
 				log.Printf("Error creating expiration notification: %v", err)
 			} else {
 				log.Printf("Created expiration notification for item: %s", item.Name)
+				newlyExpiring[item.GroupID] = append(newlyExpiring[item.GroupID], item.Name)
 			}
 		}
 	}
 
+	// Tell each member once per run about the items that just entered their group's window
+	for groupID, names := range newlyExpiring {
+		notifyExpiringItems(ctx, groupID, names, windows[groupID])
+	}
+
 	// Also check for already expired items
 	cursor, err = config.DB.Collection("pantry_items").Find(
 		context.Background(),
@@ -175,8 +214,49 @@ func checkExpiringItems() {
 		len(expiringItems), len(expiredItems))
 }
 
+// notifyExpiringItems sends each member of the group a notification listing items about to expire
+func notifyExpiringItems(ctx context.Context, groupID primitive.ObjectID, names []string, window int) {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": groupID}).Decode(&group); err != nil {
+		log.Printf("Failed to load group %s for expiration notification: %v", groupID.Hex(), err)
+		return
+	}
+
+	params := models.NotificationParams{
+		"first": names[0],
+		"count": strconv.Itoa(len(names)),
+		"days":  strconv.Itoa(window),
+		"items": strings.Join(names, ", "),
+	}
+
+	for _, memberID := range group.Members {
+		notification := models.CreateTemplatedNotification(
+			memberID,
+			group.ID,
+			models.NotificationTypeExpiringSoon,
+			models.TemplateExpiringSoon,
+			params,
+		)
+		if _, err := config.DB.Collection("notifications").InsertOne(ctx, notification); err != nil {
+			log.Printf("Failed to create expiration notification for user %s: %v", memberID.Hex(), err)
+		}
+	}
+}
+
+// lowStockExpr matches pantry items at or below their minimum quantity, or the default threshold when they have none
+var lowStockExpr = bson.M{
+	"$lte": bson.A{"$quantity", bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$min_quantity", 0}}, 0}},
+		"$min_quantity",
+		models.DefaultLowStockThreshold,
+	}}},
+}
+
 // checkLowStockItems looks for items that are running low and creates notifications
 func checkLowStockItems() {
+	if !IsLeader() {
+		return
+	}
 	log.Println("Checking for low stock and out of stock pantry items...")
 	now := time.Now()
 
@@ -258,15 +338,11 @@ func checkLowStockItems() {
 	}
 
 	// Then handle low stock items (but exclude items with quantity 0)
-	lowStockThreshold := 1.0 // Setting a fixed threshold for simplicity
-
 	cursor, err = config.DB.Collection("pantry_items").Find(
 		context.Background(),
 		bson.M{
-			"quantity": bson.M{
-				"$gt":  0,
-				"$lte": lowStockThreshold,
-			},
+			"quantity": bson.M{"$gt": 0},
+			"$expr":    lowStockExpr,
 		},
 	)
 
@@ -334,9 +410,7 @@ func GenerateShoppingList(groupID primitive.ObjectID) ([]map[string]interface{},
 		context.Background(),
 		bson.M{
 			"group_id": groupID,
-			"quantity": bson.M{
-				"$lte": 1.0, // Low stock threshold
-			},
+			"$expr":    lowStockExpr,
 		},
 	)
 
@@ -383,7 +457,9 @@ func GenerateShoppingList(groupID primitive.ObjectID) ([]map[string]interface{},
 
 			// Suggest a quantity to buy based on typical usage
 			suggestedQuantity := 1.0
-			if item.Quantity <= 0 {
+			if restock := item.RestockQuantity(); restock > 0 {
+				suggestedQuantity = restock
+			} else if item.Quantity <= 0 {
 				suggestedQuantity = 2.0 // If completely out, suggest buying 2
 			}
 
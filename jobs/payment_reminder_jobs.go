@@ -0,0 +1,196 @@
+// jobs/payment_reminder_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"log"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartPaymentReminderJobs initializes and starts the reminders for members who owe money
+func StartPaymentReminderJobs() {
+	log.Println("Starting payment reminder jobs...")
+
+	// Run every hour so reminders held back by quiet hours go out soon after they end
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go sendPaymentReminders()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			sendPaymentReminders()
+		}
+	}()
+}
+
+// sendPaymentReminders reminds the members of every group with expenses who owe enough, or have
+// owed for long enough, to pay up
+func sendPaymentReminders() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	groupIDs, err := config.DB.Collection("expenses").Distinct(ctx, "group_id", bson.M{})
+	if err != nil {
+		log.Printf("Error finding groups with expenses: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, id := range groupIDs {
+		if leaseLost("payment reminders") {
+			return
+		}
+		groupID, ok := id.(primitive.ObjectID)
+		if !ok {
+			continue
+		}
+		if err := remindGroupDebtors(ctx, groupID, now); err != nil {
+			log.Printf("Error sending payment reminders for group %s: %v", groupID.Hex(), err)
+		}
+	}
+}
+
+// remindGroupDebtors sends the payment reminders that are due in one group. Members who muted
+// payment reminders are skipped and members in their quiet hours are reminded on a later run.
+func remindGroupDebtors(ctx context.Context, groupID primitive.ObjectID, now time.Time) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": groupID}).Decode(&group); err != nil {
+		return err
+	}
+	rules := group.Settings.PaymentReminders
+	if rules.Disabled {
+		return nil
+	}
+
+	oldestFirst := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	var expenses []models.Expense
+	cursor, err := config.DB.Collection("expenses").Find(ctx, bson.M{"group_id": group.ID}, oldestFirst)
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &expenses); err != nil {
+		return err
+	}
+	var settlements []models.Settlement
+	cursor, err = config.DB.Collection("settlements").Find(ctx, bson.M{"group_id": group.ID}, oldestFirst)
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &settlements); err != nil {
+		return err
+	}
+
+	balances := models.ComputeBalances(expenses, settlements, group.Members)
+	owingSince := models.OwingSince(expenses, settlements)
+
+	// Former members no longer get the group's notifications, so only current members are reminded
+	debtors := make([]primitive.ObjectID, 0)
+	for _, balance := range balances {
+		if math.Round(balance.Net*100) < 0 && group.IsMember(balance.UserID) {
+			debtors = append(debtors, balance.UserID)
+		}
+	}
+	if len(debtors) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(balances))
+	for _, balance := range balances {
+		ids = append(ids, balance.UserID)
+	}
+	var users []models.User
+	cursor, err = config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+	usersByID := make(map[primitive.ObjectID]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+	for i := range balances {
+		balances[i].Name = usersByID[balances[i].UserID].Name
+	}
+	debts := models.SimplifyDebts(balances)
+
+	var sent []models.PaymentReminder
+	cursor, err = config.DB.Collection("payment_reminders").Find(ctx, bson.M{"group_id": group.ID})
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(ctx, &sent); err != nil {
+		return err
+	}
+	reminders := make(map[primitive.ObjectID]models.PaymentReminder, len(sent))
+	for _, reminder := range sent {
+		reminders[reminder.UserID] = reminder
+	}
+
+	currencyCode := group.Settings.CurrencyCode()
+	for _, userID := range debtors {
+		owed := -models.FindBalance(balances, userID).Net
+		since, ok := owingSince[userID]
+		if !ok {
+			since = now
+		}
+		reminder := reminders[userID]
+		if !rules.Due(owed, since, reminder, now) {
+			continue
+		}
+
+		notifications := usersByID[userID].Preferences.Notifications
+		if notifications.MutePaymentReminders || notifications.QuietHours.Contains(now) {
+			continue
+		}
+
+		// Reminders about an earlier debt that was settled do not count towards escalation
+		count := reminder.Count
+		if !reminder.OwingSince.Equal(since) {
+			count = 0
+		}
+
+		// Claim the reminder first so it is sent once even if another run overlaps; a second
+		// upsert of a new reminder fails on the unique index
+		result, err := config.DB.Collection("payment_reminders").UpdateOne(
+			ctx,
+			bson.M{"group_id": group.ID, "user_id": userID, "last_sent_at": reminder.LastSentAt},
+			bson.M{"$set": bson.M{"owing_since": since, "count": count + 1, "last_sent_at": now}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			if !mongo.IsDuplicateKeyError(err) {
+				log.Printf("Error recording payment reminder for user %s: %v", userID.Hex(), err)
+			}
+			continue
+		}
+		if result.MatchedCount == 0 && result.UpsertedCount == 0 {
+			continue
+		}
+
+		userDebts := make([]models.Debt, 0)
+		for _, debt := range debts {
+			if debt.From == userID {
+				userDebts = append(userDebts, debt)
+			}
+		}
+		notification := models.PaymentReminderNotification(userID, group.ID, owed, currencyCode, userDebts, since, count, now)
+		if _, err := config.DB.Collection("notifications").InsertOne(ctx, notification); err != nil {
+			log.Printf("Error creating payment reminder for user %s: %v", userID.Hex(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// jobs/pickup_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartPickupJobs initializes and starts reminding members to put the bins out the evening before
+// a pickup. Every instance takes part, since each reminder is claimed before it is sent.
+func StartPickupJobs() {
+	log.Println("Starting pickup jobs...")
+
+	// Run every 15 minutes so reminders go out close to the time the group chose
+	ticker := time.NewTicker(15 * time.Minute)
+
+	// Run immediately once at startup
+	go remindPickups()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			remindPickups()
+		}
+	}()
+}
+
+// remindPickups sends every pickup reminder that is due
+func remindPickups() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var schedule models.PickupSchedule
+		err := config.DB.Collection("pickup_schedules").FindOne(
+			ctx,
+			bson.M{"next_reminder_at": bson.M{"$lte": now}},
+			options.FindOne().SetSort(bson.D{{Key: "next_reminder_at", Value: 1}}),
+		).Decode(&schedule)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching due pickup reminders: %v", err)
+			return
+		}
+
+		// Claim the reminder by moving the schedule on; another instance may have got there first
+		due := schedule
+		schedule.Advance(now)
+		result, err := config.DB.Collection("pickup_schedules").UpdateOne(ctx,
+			bson.M{"_id": schedule.ID, "next_pickup": due.NextPickup},
+			bson.M{"$set": bson.M{
+				"next_pickup":      schedule.NextPickup,
+				"next_reminder_at": schedule.NextReminderAt,
+				"updated_at":       schedule.UpdatedAt,
+			}},
+		)
+		if err != nil {
+			log.Printf("Error claiming pickup reminder %s: %v", schedule.ID.Hex(), err)
+			return
+		}
+		if result.MatchedCount == 0 || !due.RemindsInTime(now) {
+			continue
+		}
+		if err := remindPickup(ctx, due); err != nil {
+			log.Printf("Error sending pickup reminder %s: %v", schedule.ID.Hex(), err)
+		}
+	}
+}
+
+// remindPickup reminds the member assigned the turn due on the pickup day. Turns skipped for a
+// blackout date have no chore, so nobody is reminded.
+func remindPickup(ctx context.Context, schedule models.PickupSchedule) error {
+	var chore models.Chore
+	err := config.DB.Collection("chores").FindOne(ctx, bson.M{
+		"recurring_id": schedule.RecurringChoreID,
+		"status":       bson.M{"$ne": models.ChoreStatusCompleted},
+		"due_date":     bson.M{"$gte": schedule.NextPickup, "$lt": schedule.NextPickup.AddDate(0, 0, 1)},
+	}).Decode(&chore)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	_, err = config.DB.Collection("notifications").InsertOne(ctx, models.PickupReminderNotification(schedule, chore))
+	return err
+}
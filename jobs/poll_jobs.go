@@ -0,0 +1,146 @@
+// jobs/poll_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartPollJobs initializes and starts deciding scheduling and decision polls whose voting has
+// closed. Every instance takes part, since a poll is only decided if nobody changed it in the
+// meantime.
+func StartPollJobs() {
+	log.Println("Starting poll jobs...")
+
+	// Run every minute so members hear about the winner soon after voting closes
+	ticker := time.NewTicker(1 * time.Minute)
+
+	// Run immediately once at startup
+	go func() {
+		decideDuePolls()
+		closeDueDecisions()
+	}()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			decideDuePolls()
+			closeDueDecisions()
+		}
+	}()
+}
+
+// decideDuePolls decides every open poll whose voting has closed
+func decideDuePolls() {
+	ctx := context.Background()
+	for {
+		var poll models.SchedulingPoll
+		err := config.DB.Collection("scheduling_polls").FindOne(
+			ctx,
+			bson.M{"status": models.PollOpen, "closes_at": bson.M{"$lte": time.Now()}},
+			options.FindOne().SetSort(bson.D{{Key: "closes_at", Value: 1}}),
+		).Decode(&poll)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching due polls: %v", err)
+			return
+		}
+		if _, err := decidePoll(ctx, poll); err != nil {
+			log.Printf("Error deciding poll %s: %v", poll.ID.Hex(), err)
+			return
+		}
+	}
+}
+
+// ClosePoll decides an open poll now, for instance when every member has voted. It returns the
+// poll as it ended up and whether this call decided it; a poll that was already decided is
+// returned as is.
+func ClosePoll(ctx context.Context, poll models.SchedulingPoll) (models.SchedulingPoll, bool, error) {
+	for {
+		if poll.Status != models.PollOpen {
+			return poll, false, nil
+		}
+		decided, err := decidePoll(ctx, poll)
+		if err != nil || decided != nil {
+			if decided != nil {
+				poll = *decided
+			}
+			return poll, decided != nil, err
+		}
+		// Someone voted or decided the poll since it was read, so decide it as it is now
+		if err := config.DB.Collection("scheduling_polls").FindOne(ctx, bson.M{"_id": poll.ID}).Decode(&poll); err != nil {
+			return poll, false, err
+		}
+	}
+}
+
+// decidePoll picks the poll's winner and tells the group. Votes bump updated_at, so the poll is
+// only decided if it has not changed since it was read; otherwise it returns nil.
+func decidePoll(ctx context.Context, poll models.SchedulingPoll) (*models.SchedulingPoll, error) {
+	readAt := poll.UpdatedAt
+	poll.Decide(time.Now())
+	result, err := config.DB.Collection("scheduling_polls").UpdateOne(ctx,
+		bson.M{"_id": poll.ID, "status": models.PollOpen, "updated_at": readAt},
+		bson.M{"$set": bson.M{
+			"status":          poll.Status,
+			"winning_slot_id": poll.WinningSlotID,
+			"decided_at":      poll.DecidedAt,
+			"updated_at":      poll.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": poll.GroupID}).Decode(&group); err != nil {
+		return &poll, err
+	}
+	documents := make([]interface{}, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		documents = append(documents, models.PollDecidedNotification(&poll, memberID))
+	}
+	if len(documents) > 0 {
+		if _, err := config.DB.Collection("notifications").InsertMany(ctx, documents); err != nil {
+			return &poll, err
+		}
+	}
+	return &poll, nil
+}
+
+// closeDueDecisions closes every open decision poll whose deadline has passed
+func closeDueDecisions() {
+	ctx := context.Background()
+	for {
+		var poll models.DecisionPoll
+		err := config.DB.Collection("decision_polls").FindOne(
+			ctx,
+			bson.M{"status": models.PollOpen, "deadline": bson.M{"$lte": time.Now()}},
+			options.FindOne().SetSort(bson.D{{Key: "deadline", Value: 1}}),
+		).Decode(&poll)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching due decision polls: %v", err)
+			return
+		}
+		if _, _, err := models.CloseDecisionPoll(ctx, config.DB, poll); err != nil {
+			log.Printf("Error closing decision poll %s: %v", poll.ID.Hex(), err)
+			return
+		}
+	}
+}
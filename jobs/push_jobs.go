@@ -0,0 +1,231 @@
+// jobs/push_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/notifications"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pushWindow is how old a notification or shopping update can be and still be pushed, so a backlog
+// from while push was down is not delivered all at once
+const pushWindow = time.Hour
+
+// pushLease is how long a claimed queued push is left to its instance before another may retry it,
+// covering an instance that stops mid-delivery
+const pushLease = time.Minute
+
+// StartPushJobs initializes and starts queueing notifications and shopping updates for members'
+// devices and delivering the queue. Every instance takes part, since each notification and queued
+// push is claimed before it is handled.
+func StartPushJobs() {
+	if !notifications.Enabled() {
+		return
+	}
+	log.Println("Starting push notification jobs...")
+
+	// Run often so pushes arrive shortly after the notification is created
+	ticker := time.NewTicker(15 * time.Second)
+
+	go func() {
+		for range ticker.C {
+			pushNotifications()
+			pushShoppingUpdates()
+			deliverQueuedPushes()
+		}
+	}()
+}
+
+// claimUnpushed marks the oldest recent document of the collection that has not been pushed yet
+// as pushed and decodes it. It returns false when there is nothing left to push.
+func claimUnpushed(ctx context.Context, collection string, filter bson.M, now time.Time, document interface{}) (bool, error) {
+	filter["pushed_at"] = bson.M{"$exists": false}
+	filter["created_at"] = bson.M{"$gte": now.Add(-pushWindow)}
+	err := config.DB.Collection(collection).FindOneAndUpdate(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"pushed_at": now}},
+		options.FindOneAndUpdate().SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	).Decode(document)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// pushNotifications pushes every new notification, such as chore assignments and reminders, to
+// the devices of the member it is for, unless they turned pushes off for its event. Members in
+// their quiet hours or who turned pushes off still find it in the app.
+func pushNotifications() {
+	ctx := context.Background()
+	now := time.Now()
+	for {
+		var notification models.Notification
+		found, err := claimUnpushed(ctx, "notifications", bson.M{}, now, &notification)
+		if err != nil {
+			log.Printf("Error claiming notification to push: %v", err)
+			return
+		}
+		if !found {
+			return
+		}
+
+		var user models.User
+		if err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": notification.UserID}).Decode(&user); err != nil {
+			continue
+		}
+		preferences := user.Preferences.Notifications
+		if !preferences.Allows(notification.Type.Event(), models.NotificationChannelPush) || preferences.QuietHours.Contains(now) {
+			continue
+		}
+		notification.Localize(user.Locale())
+		if _, err := notifications.QueuePush(ctx, user, notifications.MessageFromNotification(notification)); err != nil {
+			log.Printf("Error pushing notification %s: %v", notification.ID.Hex(), err)
+		}
+	}
+}
+
+// pushShoppingUpdates tells the other members of a group when items are added to or bought from
+// the shopping list, unless they turned off shopping pushes or are in their quiet hours
+func pushShoppingUpdates() {
+	ctx := context.Background()
+	now := time.Now()
+	filter := bson.M{"action": bson.M{"$in": []models.CartActivityType{models.CartActivityTypeAdd, models.CartActivityTypePurchase}}}
+	for {
+		var activity models.ShoppingCartActivity
+		found, err := claimUnpushed(ctx, "shopping_cart_activity", filter, now, &activity)
+		if err != nil {
+			log.Printf("Error claiming shopping update to push: %v", err)
+			return
+		}
+		if !found {
+			return
+		}
+		if err := pushShoppingUpdate(ctx, activity, now); err != nil {
+			log.Printf("Error pushing shopping update %s: %v", activity.ID.Hex(), err)
+		}
+	}
+}
+
+// pushShoppingUpdate pushes one shopping update to the group's members other than the one who made
+// it. Updates of the same group replace each other on the device.
+func pushShoppingUpdate(ctx context.Context, activity models.ShoppingCartActivity, now time.Time) error {
+	var group models.Group
+	if err := config.DB.Collection("groups").FindOne(ctx, bson.M{"_id": activity.GroupID}).Decode(&group); err != nil {
+		return err
+	}
+	recipients := make([]primitive.ObjectID, 0, len(group.Members))
+	for _, memberID := range group.Members {
+		if memberID != activity.UserID {
+			recipients = append(recipients, memberID)
+		}
+	}
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	cursor, err := config.DB.Collection("users").Find(ctx, bson.M{"_id": bson.M{"$in": recipients}})
+	if err != nil {
+		return err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	templateID := models.TemplateShoppingItemAdded
+	if activity.Action == models.CartActivityTypePurchase {
+		templateID = models.TemplateShoppingItemBought
+	}
+	params := models.NotificationParams{"name": activity.UserName, "item": activity.ItemName}
+	for _, user := range users {
+		preferences := user.Preferences.Notifications
+		if !preferences.Allows(models.NotificationEventShopping, models.NotificationChannelPush) || preferences.QuietHours.Contains(now) {
+			continue
+		}
+		title, body := models.RenderNotification(templateID, user.Locale(), params)
+		message := notifications.Message{
+			Title:       title,
+			Body:        body,
+			Data:        map[string]string{"type": "shopping_update", "group_id": group.ID.Hex(), "item_id": activity.ItemID.Hex()},
+			CollapseKey: "shopping-" + group.ID.Hex(),
+		}
+		if _, err := notifications.QueuePush(ctx, user, message); err != nil {
+			log.Printf("Error pushing shopping update to user %s: %v", user.ID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// deliverQueuedPushes sends every queued push that is due, oldest first
+func deliverQueuedPushes() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var push models.QueuedPush
+		err := config.DB.Collection("push_queue").FindOneAndUpdate(
+			ctx,
+			bson.M{"status": models.QueuedPushPending, "next_attempt_at": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"next_attempt_at": now.Add(pushLease)}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}),
+		).Decode(&push)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming queued push: %v", err)
+			return
+		}
+		deliverQueuedPush(ctx, push, now)
+	}
+}
+
+// deliverQueuedPush makes one attempt at a queued push and records the outcome. Failures are
+// retried with backoff until models.PushMaxAttempts, after which the push is dead-lettered for
+// group admins to inspect. Pushes to devices that were removed or unregistered are dropped.
+func deliverQueuedPush(ctx context.Context, push models.QueuedPush, now time.Time) {
+	var device models.DeviceToken
+	err := config.DB.Collection("device_tokens").FindOne(ctx, bson.M{"_id": push.DeviceID}).Decode(&device)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error loading device %s: %v", push.DeviceID.Hex(), err)
+		return // Retried once the lease runs out
+	}
+	if err == nil {
+		err = notifications.SendToDevice(ctx, device, notifications.MessageFromQueuedPush(push))
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, notifications.ErrUnregistered) {
+		if _, err := config.DB.Collection("push_queue").DeleteOne(ctx, bson.M{"_id": push.ID}); err != nil {
+			log.Printf("Error dropping queued push %s: %v", push.ID.Hex(), err)
+		}
+		return
+	}
+
+	attempts := push.Attempts + 1
+	update := bson.M{"attempts": attempts}
+	switch {
+	case err == nil:
+		update["status"] = models.QueuedPushSent
+		update["sent_at"] = now
+		update["last_error"] = ""
+	case attempts >= models.PushMaxAttempts || errors.Is(err, notifications.ErrNoNotifier):
+		update["status"] = models.QueuedPushDead
+		update["dead_at"] = now
+		update["last_error"] = err.Error()
+		log.Printf("Giving up on push %s to device %s of user %s: %v", push.ID.Hex(), push.DeviceID.Hex(), push.UserID.Hex(), err)
+	default:
+		update["next_attempt_at"] = now.Add(models.PushRetryDelay(attempts))
+		update["last_error"] = err.Error()
+	}
+	if _, err := config.DB.Collection("push_queue").UpdateOne(ctx, bson.M{"_id": push.ID}, bson.M{"$set": update}); err != nil {
+		log.Printf("Error recording queued push %s: %v", push.ID.Hex(), err)
+	}
+}
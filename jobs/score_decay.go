@@ -0,0 +1,145 @@
+// jobs/score_decay.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StartScoreDecayJobs initializes and starts the inactivity score decay job
+func StartScoreDecayJobs() {
+	log.Println("Starting score decay jobs...")
+
+	// Run once a day; each user decays at most once per inactive week
+	ticker := time.NewTicker(24 * time.Hour)
+
+	// Run immediately once at startup
+	go applyScoreDecay()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			applyScoreDecay()
+		}
+	}()
+}
+
+// applyScoreDecay reduces the scores of inactive members in groups that have decay enabled
+func applyScoreDecay() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	cursor, err := config.DB.Collection("groups").Find(ctx, bson.M{"settings.decay_percent": bson.M{"$gt": 0}})
+	if err != nil {
+		log.Printf("Error finding groups for score decay: %v", err)
+		return
+	}
+	var groups []models.Group
+	if err = cursor.All(ctx, &groups); err != nil {
+		log.Printf("Error decoding groups for score decay: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, group := range groups {
+		if leaseLost("score decay") {
+			return
+		}
+		cursor, err := config.DB.Collection("users").Find(ctx, bson.M{
+			"group_id": group.ID,
+			"score":    bson.M{"$gt": 0},
+		})
+		if err != nil {
+			log.Printf("Error finding users for score decay in group %s: %v", group.ID.Hex(), err)
+			continue
+		}
+		var users []models.User
+		if err = cursor.All(ctx, &users); err != nil {
+			log.Printf("Error decoding users for score decay in group %s: %v", group.ID.Hex(), err)
+			continue
+		}
+
+		for _, user := range users {
+			if err := decayUserScore(ctx, group, user, now); err != nil {
+				log.Printf("Error applying score decay to user %s: %v", user.ID.Hex(), err)
+			}
+		}
+	}
+}
+
+// decayUserScore applies one decay step to a user if they have been inactive long enough
+func decayUserScore(ctx context.Context, group models.Group, user models.User, now time.Time) error {
+	lastActivity := user.CreatedAt
+	var lastCompletion models.ChoreCompletion
+	err := config.DB.Collection("chore_completions").FindOne(
+		ctx,
+		bson.M{"user_id": user.ID},
+		options.FindOne().SetSort(bson.D{{Key: "completed_at", Value: -1}}),
+	).Decode(&lastCompletion)
+	if err == nil {
+		lastActivity = lastCompletion.CompletedAt
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	var lastDecay models.ScoreEvent
+	err = config.DB.Collection("score_events").FindOne(
+		ctx,
+		bson.M{"user_id": user.ID, "type": models.ScoreEventDecay},
+		options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	).Decode(&lastDecay)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	if !models.ShouldDecay(lastActivity, lastDecay.CreatedAt, now, group.Settings.DecayInactiveWeeks) {
+		return nil
+	}
+
+	amount := models.DecayAmount(user.Score, group.Settings.DecayPercent)
+	if amount == 0 {
+		return nil
+	}
+
+	// Only apply if the score has not changed since it was read
+	result, err := config.DB.Collection("users").UpdateOne(
+		ctx,
+		bson.M{"_id": user.ID, "score": user.Score},
+		bson.M{
+			"$inc": bson.M{"score": -amount},
+			"$set": bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return nil // Score changed concurrently, try again on the next run
+	}
+
+	event := models.CreateScoreEvent(
+		user.ID,
+		user.GroupID,
+		models.ScoreEventDecay,
+		-amount,
+		user.Score-amount,
+		fmt.Sprintf("%d%% decay after no completed chores since %s", group.Settings.DecayPercent, lastActivity.Format("2006-01-02")),
+	)
+	if err := RecordScoreEvent(ctx, event); err != nil {
+		return err
+	}
+
+	log.Printf("Decayed score of user %s by %d points", user.ID.Hex(), amount)
+	return nil
+}
@@ -0,0 +1,15 @@
+// jobs/score_events.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+)
+
+// RecordScoreEvent stores an entry in the score audit trail.
+// Pass a session context to record it in the same transaction as the score change.
+func RecordScoreEvent(ctx context.Context, event *models.ScoreEvent) error {
+	_, err := config.DB.Collection("score_events").InsertOne(ctx, event)
+	return err
+}
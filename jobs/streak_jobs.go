@@ -0,0 +1,198 @@
+// jobs/streak_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxStreakCatchUpDays caps how many unevaluated days are processed in one run
+const maxStreakCatchUpDays = 30
+
+// streakJobState records the last day whose outcomes have been applied to user streaks
+type streakJobState struct {
+	ID               string    `bson:"_id"`
+	LastEvaluatedDay time.Time `bson:"last_evaluated_day"`
+}
+
+// StartStreakJobs initializes and starts the nightly streak evaluation
+func StartStreakJobs() {
+	log.Println("Starting streak jobs...")
+
+	// Check every hour; each day is only evaluated once, after it has ended
+	ticker := time.NewTicker(1 * time.Hour)
+
+	// Run immediately once at startup
+	go updateStreaks()
+
+	// Then run on the schedule
+	go func() {
+		for range ticker.C {
+			updateStreaks()
+		}
+	}()
+}
+
+// updateStreaks applies the outcome of every finished day (and week) not yet evaluated
+func updateStreaks() {
+	if !IsLeader() {
+		return
+	}
+
+	ctx := context.Background()
+	year, month, day := time.Now().UTC().Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	var state streakJobState
+	err := config.DB.Collection("job_state").FindOne(ctx, bson.M{"_id": "streaks"}).Decode(&state)
+	if err != nil {
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Printf("Error loading streak job state: %v", err)
+			return
+		}
+		// First run: start tracking from yesterday onwards
+		state.LastEvaluatedDay = yesterday.AddDate(0, 0, -1)
+	}
+
+	next := state.LastEvaluatedDay.AddDate(0, 0, 1)
+	if next.Before(yesterday.AddDate(0, 0, -maxStreakCatchUpDays)) {
+		log.Printf("Streak evaluation is more than %d days behind, skipping ahead", maxStreakCatchUpDays)
+		next = yesterday.AddDate(0, 0, -maxStreakCatchUpDays)
+	}
+
+	for ; !next.After(yesterday); next = next.AddDate(0, 0, 1) {
+		if leaseLost("streak evaluation") {
+			return
+		}
+		if err := evaluateStreakDay(ctx, next); err != nil {
+			log.Printf("Error evaluating streaks for %s: %v", next.Format("2006-01-02"), err)
+			return
+		}
+
+		_, err := config.DB.Collection("job_state").UpdateOne(
+			ctx,
+			bson.M{"_id": "streaks"},
+			bson.M{"$set": bson.M{"last_evaluated_day": next}},
+			options.Update().SetUpsert(true),
+		)
+		if err != nil {
+			log.Printf("Error saving streak job state: %v", err)
+			return
+		}
+		log.Printf("Evaluated streaks for %s", next.Format("2006-01-02"))
+	}
+}
+
+// evaluateStreakDay applies the outcome of one day, and of its week when the day is a Sunday
+func evaluateStreakDay(ctx context.Context, day time.Time) error {
+	dailyOutcomes, err := periodOutcomes(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	var weeklyOutcomes map[primitive.ObjectID]models.PeriodOutcome
+	if day.Weekday() == time.Sunday {
+		weekStart := models.WeekStartUTC(day)
+		weeklyOutcomes, err = periodOutcomes(ctx, weekStart, weekStart.AddDate(0, 0, 7))
+		if err != nil {
+			return err
+		}
+	}
+
+	userIDs := make(map[primitive.ObjectID]bool)
+	for id := range dailyOutcomes {
+		userIDs[id] = true
+	}
+	for id := range weeklyOutcomes {
+		userIDs[id] = true
+	}
+
+	for userID := range userIDs {
+		var user models.User
+		err := config.DB.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+		if err != nil {
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				continue // Chore assigned to a user who has since been removed
+			}
+			return err
+		}
+
+		user.Streak.ApplyDaily(dailyOutcomes[userID])
+		if weeklyOutcomes != nil {
+			user.Streak.ApplyWeekly(weeklyOutcomes[userID])
+		}
+
+		_, err = config.DB.Collection("users").UpdateOne(
+			ctx,
+			bson.M{"_id": userID},
+			bson.M{"$set": bson.M{"streak": user.Streak}},
+		)
+		if err != nil {
+			return err
+		}
+
+		// Streak badges depend on the updated streak
+		EvaluateAchievements(userID)
+	}
+
+	return nil
+}
+
+// periodOutcomes classifies the chores due in [start, end) for every assignee
+func periodOutcomes(ctx context.Context, start, end time.Time) (map[primitive.ObjectID]models.PeriodOutcome, error) {
+	cursor, err := config.DB.Collection("chores").Find(
+		ctx,
+		bson.M{
+			"due_date":    bson.M{"$gte": start, "$lt": end},
+			"assigned_to": bson.M{"$exists": true},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	var chores []models.Chore
+	if err = cursor.All(ctx, &chores); err != nil {
+		return nil, err
+	}
+
+	choreIDs := make([]primitive.ObjectID, 0, len(chores))
+	choresByUser := make(map[primitive.ObjectID][]models.Chore)
+	for _, chore := range chores {
+		choreIDs = append(choreIDs, chore.ID)
+		choresByUser[chore.AssignedTo] = append(choresByUser[chore.AssignedTo], chore)
+	}
+
+	completedAt := make(map[primitive.ObjectID]time.Time)
+	if len(choreIDs) > 0 {
+		cursor, err := config.DB.Collection("chore_completions").Find(
+			ctx,
+			bson.M{"chore_id": bson.M{"$in": choreIDs}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		var completions []models.ChoreCompletion
+		if err = cursor.All(ctx, &completions); err != nil {
+			return nil, err
+		}
+		for _, completion := range completions {
+			completedAt[completion.ChoreID] = completion.CompletedAt
+		}
+	}
+
+	outcomes := make(map[primitive.ObjectID]models.PeriodOutcome, len(choresByUser))
+	for userID, userChores := range choresByUser {
+		outcomes[userID] = models.ClassifyPeriod(userChores, completedAt)
+	}
+	return outcomes, nil
+}
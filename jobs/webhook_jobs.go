@@ -0,0 +1,98 @@
+// jobs/webhook_jobs.go
+package jobs
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"cribb-backend/webhooks"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// webhookLease is how long a claimed delivery is left to its instance before another may retry it,
+// covering an instance that stops mid-delivery
+const webhookLease = time.Minute
+
+// StartWebhookJobs initializes and starts delivering group events to webhooks. Every instance
+// takes part, since each delivery is claimed before it is posted.
+func StartWebhookJobs() {
+	log.Println("Starting webhook delivery jobs...")
+
+	// Run often so events arrive shortly after they happen
+	ticker := time.NewTicker(10 * time.Second)
+
+	go func() {
+		for range ticker.C {
+			deliverWebhooks()
+		}
+	}()
+}
+
+// deliverWebhooks posts every delivery that is due, oldest first
+func deliverWebhooks() {
+	ctx := context.Background()
+	for {
+		now := time.Now()
+		var delivery models.WebhookDelivery
+		err := config.DB.Collection("webhook_deliveries").FindOneAndUpdate(
+			ctx,
+			bson.M{"status": models.WebhookDeliveryPending, "next_attempt_at": bson.M{"$lte": now}},
+			bson.M{"$set": bson.M{"next_attempt_at": now.Add(webhookLease)}},
+			options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}),
+		).Decode(&delivery)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return
+		}
+		if err != nil {
+			log.Printf("Error claiming webhook delivery: %v", err)
+			return
+		}
+		deliverWebhook(ctx, delivery, now)
+	}
+}
+
+// deliverWebhook makes one attempt at a delivery and records the outcome. Failures are retried
+// with backoff until models.WebhookMaxAttempts, unless the receiver rejected the delivery outright
+// or the webhook was removed or turned off.
+func deliverWebhook(ctx context.Context, delivery models.WebhookDelivery, now time.Time) {
+	var hook models.Webhook
+	err := config.DB.Collection("webhooks").FindOne(ctx, bson.M{"_id": delivery.WebhookID}).Decode(&hook)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		log.Printf("Error loading webhook %s: %v", delivery.WebhookID.Hex(), err)
+		return // Retried once the lease runs out
+	}
+	if err != nil || !hook.Active {
+		recordWebhookAttempt(ctx, delivery, bson.M{"status": models.WebhookDeliveryFailed, "last_error": "webhook was removed or turned off"})
+		return
+	}
+
+	statusCode, err := webhooks.Post(ctx, hook, delivery, now)
+	attempts := delivery.Attempts + 1
+	update := bson.M{"attempts": attempts, "last_status_code": statusCode}
+	switch {
+	case err == nil:
+		update["status"] = models.WebhookDeliveryDelivered
+		update["delivered_at"] = now
+		update["last_error"] = ""
+	case attempts >= models.WebhookMaxAttempts || !models.WebhookRetryable(statusCode):
+		update["status"] = models.WebhookDeliveryFailed
+		update["last_error"] = err.Error()
+	default:
+		update["next_attempt_at"] = now.Add(models.WebhookRetryDelay(attempts))
+		update["last_error"] = err.Error()
+	}
+	recordWebhookAttempt(ctx, delivery, update)
+}
+
+// recordWebhookAttempt saves the outcome of an attempt at a delivery
+func recordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery, update bson.M) {
+	if _, err := config.DB.Collection("webhook_deliveries").UpdateOne(ctx, bson.M{"_id": delivery.ID}, bson.M{"$set": update}); err != nil {
+		log.Printf("Error recording webhook delivery %s: %v", delivery.ID.Hex(), err)
+	}
+}
@@ -1,59 +1,274 @@
 package main
 
 import (
+	"cribb-backend/chatbot"
 	"cribb-backend/config"
+	"cribb-backend/email"
+	"cribb-backend/graph"
 	"cribb-backend/handlers"
 	"cribb-backend/jobs"
 	"cribb-backend/middleware"
+	"cribb-backend/notifications"
+	"cribb-backend/realtime"
+	"cribb-backend/rpc"
+	"cribb-backend/sms"
+	"cribb-backend/voice"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	_ "time/tzdata" // Quiet hours are kept in members' own time zones
 )
 
 func main() {
 	// Connect to MongoDB and initialize collections
 	config.ConnectDB()
 
-	// Start the background jobs
+	// Start the background jobs; only the instance holding the scheduler lease runs them
+	jobs.StartLeaderElection()
 	jobs.StartChoreScheduler()
 	jobs.StartPantryJobs() // Start the pantry background jobs
+	jobs.StartStreakJobs()
+	jobs.StartLeaderboardJobs()
+	jobs.StartScoreDecayJobs()
+	jobs.StartChallengeJobs()
+	jobs.StartBillJobs()
+	jobs.StartPaymentReminderJobs()
+
+	// Push notifications to members' devices when FCM or APNs credentials are configured
+	notifications.Configure()
+	jobs.StartPushJobs()
+
+	// Email overdue chore escalations and password resets when a provider is configured
+	email.Configure()
+
+	// Send daily and weekly digests by email and push
+	jobs.StartDigestJobs()
+
+	// Text members who opted in about rent due and chores left overdue when Twilio is configured
+	sms.Configure()
+	jobs.StartEscalationJobs()
+
+	// Post group events to the webhooks groups register
+	jobs.StartWebhookJobs()
+
+	// Remind members of parties, maintenance visits and inspections about to start
+	jobs.StartHouseEventJobs()
+
+	// Tell members about overnight guests arriving soon
+	jobs.StartGuestJobs()
+
+	// Pick the winners of scheduling polls and tally decision polls once voting closes
+	jobs.StartPollJobs()
+
+	// Remind members to put the bins out the evening before a pickup
+	jobs.StartPickupJobs()
+
+	// Write the group data exports admins request
+	jobs.StartExportJobs()
+
+	// Answer slash commands from Slack and Discord and post the daily chore summary to their channels
+	chatbot.Configure()
+	jobs.StartChatJobs()
+
+	// Stream shopping list changes to connected clients
+	realtime.StartShoppingStream()
+
+	// Stream completed chores, shopping list additions and new expenses over WebSockets
+	realtime.StartGroupStream()
+
+	// Answer Alexa and Google Assistant for members who linked their account
+	voice.Configure()
 
 	// Register routes
 	http.HandleFunc("/health", middleware.CORSMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Server is running!"))
 	}))
 
+	// API documentation - the OpenAPI document and Swagger UI, see handlers.APIOperations
+	http.HandleFunc("/openapi.json", middleware.CORSMiddleware(handlers.OpenAPIHandler))
+	http.HandleFunc("/docs", middleware.CORSMiddleware(handlers.APIDocsHandler))
+
+	// GraphQL - the home screen dashboard in one query, resolved from the caller's group
+	http.HandleFunc("/graphql", middleware.CORSMiddleware(middleware.AuthMiddleware(graph.NewHandler(graph.MongoStore{}).ServeHTTP)))
+
 	// Auth routes - apply CORS middleware to resolve login issue
 	http.HandleFunc("/api/register", middleware.CORSMiddleware(handlers.RegisterHandler))
 	http.HandleFunc("/api/login", middleware.CORSMiddleware(handlers.LoginHandler))
+	http.HandleFunc("/api/password/forgot", middleware.CORSMiddleware(handlers.ForgotPasswordHandler))
+	http.HandleFunc("/api/password/reset", middleware.CORSMiddleware(handlers.ResetPasswordHandler))
 
 	// User routes - wrap existing middleware with CORS middleware
-	http.HandleFunc("/api/users/profile", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUserProfileHandler)))
+	http.HandleFunc("/api/users/profile", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			handlers.PatchUserProfileHandler(w, r)
+			return
+		}
+		handlers.GetUserProfileHandler(w, r)
+	})))
 	http.HandleFunc("/api/users", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUsersHandler)))
 	http.HandleFunc("/api/users/by-username", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUserByUsernameHandler)))
 	http.HandleFunc("/api/users/by-score", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUsersByScoreHandler)))
+	http.HandleFunc("/api/users/score/adjust", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.AdjustScoreHandler)))
+	http.HandleFunc("/api/users/me/score-history", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetScoreTimeSeriesHandler)))
+	http.HandleFunc("/api/users/me/preferences", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UserPreferencesHandler)))
+	http.HandleFunc("/api/users/me/calendar", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CalendarHandler)))
+	http.HandleFunc("/api/devices", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeviceHandler)))
+	http.HandleFunc("/api/notifications", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetNotificationsHandler)))
+	http.HandleFunc("/api/notifications/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.NotificationHandler)))
+	// Direct messages between members of a group; new messages are also streamed over /api/groups/events
+	http.HandleFunc("/api/messages/conversations", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ConversationsHandler)))
+	http.HandleFunc("/api/messages/conversations/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ConversationHandler)))
+	http.HandleFunc("/api/messages/unread", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUnreadMessagesHandler)))
+	http.HandleFunc("/api/users/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetScoreHistoryHandler)))
 
 	// Group routes - wrap existing middleware with CORS middleware
 	http.HandleFunc("/api/groups", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateGroupHandler)))
 	http.HandleFunc("/api/groups/join", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.JoinGroupHandler)))
 	http.HandleFunc("/api/groups/leave", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.LeaveGroupHandler)))
 	http.HandleFunc("/api/groups/members", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetGroupMembersHandler)))
-	http.HandleFunc("/api/groups/details", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetGroupDetailsHandler)))
+	// Polled reads answer 304 Not Modified when the client's ETag is still current
+	http.HandleFunc("/api/groups/details", middleware.CORSMiddleware(middleware.AuthMiddleware(middleware.ETagMiddleware(handlers.GetGroupDetailsHandler))))
 	http.HandleFunc("/api/groups/leaderboard", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetGroupLeaderboardHandler)))
+	http.HandleFunc("/api/groups/leaderboard/history", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetLeaderboardHistoryHandler)))
+	http.HandleFunc("/api/groups/settings", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateGroupSettingsHandler)))
+	http.HandleFunc("/api/groups/blackouts", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetBlackoutDatesHandler)))
+	http.HandleFunc("/api/groups/blackouts/create", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateBlackoutDateHandler)))
+	http.HandleFunc("/api/groups/blackouts/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteBlackoutDateHandler)))
+	http.HandleFunc("/api/groups/events",
+		middleware.CORSMiddleware(
+			middleware.QueryTokenMiddleware(
+				middleware.AuthMiddleware(
+					handlers.GroupEventsHandler))))
+	http.HandleFunc("/api/groups/webhooks", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.WebhooksHandler)))
+	http.HandleFunc("/api/groups/webhooks/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.WebhookHandler)))
+	// Inbound hooks give automations a secret URL to post commands to; DELETE /api/groups/inbound-hooks/{id} removes
+	// one and POST /api/groups/inbound-hooks/{id}/token replaces its token
+	http.HandleFunc("/api/groups/inbound-hooks", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.InboundHooksHandler)))
+	http.HandleFunc("/api/groups/inbound-hooks/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.InboundHookHandler)))
+	// The token in an inbound hook's URL stands in for the auth header
+	http.HandleFunc("/api/hooks/", middleware.CORSMiddleware(handlers.InboundCommandHandler))
+	// Voice assistants link members' accounts through OAuth: the assistant sends the member to /api/oauth/authorize,
+	// the app approves the link and the assistant exchanges the code at /api/oauth/token
+	http.HandleFunc("/api/oauth/authorize", middleware.CORSMiddleware(handlers.OAuthAuthorizeHandler))
+	http.HandleFunc("/api/oauth/approve", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ApproveOAuthHandler)))
+	http.HandleFunc("/api/oauth/token", middleware.CORSMiddleware(handlers.OAuthTokenHandler))
+	http.HandleFunc("/api/oauth/grants", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.LinkedAssistantsHandler)))
+	http.HandleFunc("/api/oauth/grants/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.LinkedAssistantHandler)))
+	// Alexa signs its requests and Dialogflow sends a basic auth password; the linked account's access token is in the body
+	http.HandleFunc("/api/voice/alexa", middleware.CORSMiddleware(handlers.AlexaHandler))
+	http.HandleFunc("/api/voice/google", middleware.CORSMiddleware(handlers.GoogleAssistantHandler))
+	// Chat integrations connect the house Slack workspace or Discord server; POST /api/groups/chat-integrations/{id}/summary
+	// posts today's chore summary now, and members link their chat account with a code from /api/groups/chat-link
+	http.HandleFunc("/api/groups/chat-integrations", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ChatIntegrationsHandler)))
+	http.HandleFunc("/api/groups/chat-integrations/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ChatIntegrationHandler)))
+	http.HandleFunc("/api/groups/chat-link", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ChatLinkCodeHandler)))
+	// Slack and Discord sign their slash commands
+	http.HandleFunc("/api/chat/slack", middleware.CORSMiddleware(handlers.SlackCommandHandler))
+	http.HandleFunc("/api/chat/discord", middleware.CORSMiddleware(handlers.DiscordInteractionHandler))
+	http.HandleFunc("/api/groups/push-queue", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PushQueueHandler)))
+	http.HandleFunc("/api/groups/push-queue/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.QueuedPushHandler)))
+	http.HandleFunc("/api/groups/house-events", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.HouseEventsHandler)))
+	http.HandleFunc("/api/groups/house-events/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.HouseEventHandler)))
+	http.HandleFunc("/api/groups/guests", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GuestsHandler)))
+	http.HandleFunc("/api/groups/guests/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GuestHandler)))
+	http.HandleFunc("/api/groups/resources", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ResourcesHandler)))
+	http.HandleFunc("/api/groups/resources/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ResourceHandler)))
+	http.HandleFunc("/api/groups/quiet-hours/violations", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.QuietHoursViolationsHandler)))
+	http.HandleFunc("/api/groups/quiet-hours/violations/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.QuietHoursViolationHandler)))
+	http.HandleFunc("/api/groups/quiet-hours/report", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetQuietHoursReportHandler)))
+	http.HandleFunc("/api/groups/polls", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PollsHandler)))
+	http.HandleFunc("/api/groups/polls/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PollHandler)))
+	http.HandleFunc("/api/groups/decisions", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DecisionsHandler)))
+	http.HandleFunc("/api/groups/decisions/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DecisionHandler)))
+	http.HandleFunc("/api/groups/chat", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ChatHandler)))
+	http.HandleFunc("/api/groups/chat/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ChatMessageHandler)))
+	// GET /api/groups/house-rules/versions lists past versions; POST .../acknowledge accepts the rules in force and
+	// GET .../acknowledgments shows admins who has not
+	http.HandleFunc("/api/groups/house-rules", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.HouseRulesHandler)))
+	http.HandleFunc("/api/groups/house-rules/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.HouseRulesActionHandler)))
+	// GET/PUT/DELETE /api/groups/issues/{id} manage an issue; POST .../status moves it along and PUT/DELETE .../photo
+	// manage its photo
+	http.HandleFunc("/api/groups/issues", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.IssuesHandler)))
+	http.HandleFunc("/api/groups/issues/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.IssueHandler)))
+	// Issue photos are read through signed links, which stand in for the auth header
+	http.HandleFunc("/api/groups/issues/photos/", middleware.CORSMiddleware(handlers.ServeIssuePhotoHandler))
+	http.HandleFunc("/api/groups/kudos", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.KudosHandler)))
+	http.HandleFunc("/api/groups/kudos/summary", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetKudosSummaryHandler)))
+	http.HandleFunc("/api/groups/pickups", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PickupsHandler)))
+	http.HandleFunc("/api/groups/pickups/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PickupHandler)))
+	// GET /api/groups/posts/{id} returns a post and PUT/DELETE edit or remove it; POST /api/groups/posts/{id}/attachments
+	// uploads a file and DELETE /api/groups/posts/{id}/attachments/{file_id} removes one
+	http.HandleFunc("/api/groups/posts", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PostsHandler)))
+	http.HandleFunc("/api/groups/posts/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PostHandler)))
+	// Post attachments are read through signed links, which stand in for the auth header
+	http.HandleFunc("/api/groups/posts/attachments/", middleware.CORSMiddleware(handlers.ServePostAttachmentHandler))
+	// Emoji reactions on completed chores and posts; GET /api/groups/completions lists recent completions with theirs
+	http.HandleFunc("/api/groups/reactions/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ReactionsHandler)))
+	http.HandleFunc("/api/groups/completions", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetCompletionsHandler)))
+	// Group data exports are downloaded through signed links
+	http.HandleFunc("/api/groups/exports/", middleware.CORSMiddleware(handlers.ServeGroupExportHandler))
+	http.HandleFunc("/api/groups/", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/pantry/analytics"):
+			// GET /api/groups/{id}/pantry/analytics
+			handlers.GetPantryAnalyticsHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/balances"):
+			// GET /api/groups/{id}/balances
+			handlers.GetGroupBalancesHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/expenses/export"):
+			// GET /api/groups/{id}/expenses/export
+			handlers.ExportGroupExpensesHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/export"):
+			// POST /api/groups/{id}/export
+			handlers.RequestGroupExportHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/exports"):
+			// GET /api/groups/{id}/exports
+			handlers.GetGroupExportsHandler(w, r)
+		case strings.HasSuffix(r.URL.Path, "/import"):
+			// POST /api/groups/{id}/import
+			handlers.ImportGroupDataHandler(w, r)
+		default:
+			// GET /api/groups/{id}/compare
+			handlers.CompareMembersHandler(w, r)
+		}
+	})))
+
+	// Badge routes
+	http.HandleFunc("/api/badges/earned", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetEarnedBadgesHandler)))
+	http.HandleFunc("/api/badges/available", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetAvailableBadgesHandler)))
+
+	// Reward routes
+	http.HandleFunc("/api/rewards", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetRewardsHandler)))
+	http.HandleFunc("/api/rewards/create", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateRewardHandler)))
+	http.HandleFunc("/api/rewards/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteRewardHandler)))
+	http.HandleFunc("/api/rewards/redeem", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.RedeemRewardHandler)))
+	http.HandleFunc("/api/rewards/redemptions", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetRedemptionsHandler)))
+	http.HandleFunc("/api/rewards/redemptions/review", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ReviewRedemptionHandler)))
+
+	// Challenge routes
+	http.HandleFunc("/api/challenges", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetChallengesHandler)))
+	http.HandleFunc("/api/challenges/create", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateChallengeHandler)))
 
 	// Chore routes - existing - wrap with CORS middleware
 	http.HandleFunc("/api/chores/individual", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateIndividualChoreHandler)))
 	http.HandleFunc("/api/chores/recurring", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateRecurringChoreHandler)))
-	http.HandleFunc("/api/chores/user", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUserChoresHandler)))
+	http.HandleFunc("/api/chores/user", middleware.CORSMiddleware(middleware.AuthMiddleware(middleware.ETagMiddleware(handlers.GetUserChoresHandler))))
 
 	// Chore routes - new - wrap with CORS middleware
 	http.HandleFunc("/api/chores/complete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CompleteChoreHandler)))
 	http.HandleFunc("/api/chores/group", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetGroupChoresHandler)))
 	http.HandleFunc("/api/chores/group/recurring", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetGroupRecurringChoresHandler)))
 	http.HandleFunc("/api/chores/update", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateChoreHandler)))
+	// PATCH /api/chores/update/{id} - JSON Merge Patch of a chore
+	http.HandleFunc("/api/chores/update/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PatchChoreHandler)))
 	http.HandleFunc("/api/chores/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteChoreHandler)))
 	http.HandleFunc("/api/chores/recurring/update", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateRecurringChoreHandler)))
 	http.HandleFunc("/api/chores/recurring/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteRecurringChoreHandler)))
+	http.HandleFunc("/api/chores/recurring/rotation", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateRotationOrderHandler)))
+	http.HandleFunc("/api/chores/recurring/exclusions", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateRotationExclusionsHandler)))
+	http.HandleFunc("/api/chores/recurring/fairness", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetRotationFairnessHandler)))
+	http.HandleFunc("/api/recurring-chores/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetUpcomingAssignmentsHandler)))
 	http.HandleFunc("/api/chores/clear-completed", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ClearCompletedChoresHandler)))
 
 	// Pantry Category routes - NEW STRUCTURED ENDPOINT
@@ -64,6 +279,9 @@ func main() {
 	createCategoryValidation := middleware.ValidateRequest(handlers.CreatePantryCategoryHandler, handlers.CreateCategoryRequest{})
 	http.HandleFunc("/api/pantry/categories/create", middleware.CORSMiddleware(middleware.AuthMiddleware(createCategoryValidation)))
 
+	// Order the group's custom categories
+	http.HandleFunc("/api/pantry/categories/reorder", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ReorderPantryCategoriesHandler)))
+
 	// Update/Delete category routes (dynamic based on method)
 	http.HandleFunc("/api/pantry/categories/", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -73,6 +291,9 @@ func main() {
 			updateCategoryValidation(w, r)
 		case http.MethodDelete:
 			handlers.DeletePantryCategoryHandler(w, r)
+		case http.MethodPost:
+			// POST /api/pantry/categories/{id}/merge, /deactivate or /activate
+			handlers.PantryCategoryActionHandler(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
@@ -85,12 +306,23 @@ func main() {
 
 	// Update pantry item - now requires category_id (no fallbacks)
 	updatePantryValidation := middleware.ValidateRequest(handlers.UpdatePantryItemHandler, handlers.UpdatePantryItemRequest{})
-	http.HandleFunc("/api/pantry/update/", middleware.CORSMiddleware(middleware.AuthMiddleware(updatePantryValidation)))
+	http.HandleFunc("/api/pantry/update/", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		// PATCH takes a JSON Merge Patch, which names only the fields it changes
+		if r.Method == http.MethodPatch {
+			handlers.PatchPantryItemHandler(w, r)
+			return
+		}
+		updatePantryValidation(w, r)
+	})))
 
 	// Use pantry item
 	usePantryValidation := middleware.ValidateRequest(handlers.UsePantryItemHandler, handlers.UsePantryItemRequest{})
 	http.HandleFunc("/api/pantry/use", middleware.CORSMiddleware(middleware.AuthMiddleware(usePantryValidation)))
 
+	// Discard pantry item into the waste log
+	discardPantryValidation := middleware.ValidateRequest(handlers.DiscardPantryItemHandler, handlers.DiscardPantryItemRequest{})
+	http.HandleFunc("/api/pantry/discard", middleware.CORSMiddleware(middleware.AuthMiddleware(discardPantryValidation)))
+
 	// Get pantry items - now includes resolved category info and supports category_id filter
 	http.HandleFunc("/api/pantry/list", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryItemsHandler)))
 
@@ -100,8 +332,15 @@ func main() {
 	// Pantry management routes - existing functionality
 	http.HandleFunc("/api/pantry/warnings", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryWarningsHandler)))
 	http.HandleFunc("/api/pantry/expiring", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryExpiringHandler)))
+	http.HandleFunc("/api/pantry/expiring-items", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetExpiringPantryItemsHandler)))
 	http.HandleFunc("/api/pantry/shopping-list", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryShoppingListHandler)))
 	http.HandleFunc("/api/pantry/history", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryHistoryHandler)))
+	http.HandleFunc("/api/pantry/usage", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryUsageHandler)))
+	http.HandleFunc("/api/pantry/versions/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.PantryItemVersionsHandler)))
+	http.HandleFunc("/api/pantry/waste/report", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetPantryWasteReportHandler)))
+	http.HandleFunc("/api/pantry/scan", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ScanPantryItemHandler)))
+	http.HandleFunc("/api/pantry/search", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.SearchPantryItemsHandler)))
+	http.HandleFunc("/api/pantry/photo/", middleware.CORSMiddleware(middleware.QueryTokenMiddleware(middleware.AuthMiddleware(handlers.PantryItemPhotoHandler))))
 	http.HandleFunc("/api/pantry/notify/read", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.MarkNotificationReadHandler)))
 	http.HandleFunc("/api/pantry/notify/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteNotificationHandler)))
 
@@ -131,7 +370,165 @@ func main() {
 		middleware.CORSMiddleware(
 			middleware.AuthMiddleware(
 				middleware.GroupAccessControlMiddleware(
-					handlers.ListShoppingCartItemsHandler))))
+					middleware.ETagMiddleware(
+						handlers.ListShoppingCartItemsHandler)))))
+
+	// Purchase routes - any group member can mark an item purchased
+	http.HandleFunc("/api/shopping-cart/",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.PurchaseShoppingCartItemHandler)))
+
+	http.HandleFunc("/api/shopping-cart/checkout",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.CheckoutShoppingCartHandler)))
+
+	http.HandleFunc("/api/shopping-cart/history",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.GetPurchaseHistoryHandler)))
+
+	// PUT /api/shopping-cart/history/{id} corrects a purchase's price and its linked expense
+	http.HandleFunc("/api/shopping-cart/history/",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.UpdatePurchaseHandler)))
+
+	http.HandleFunc("/api/shopping-cart/scan",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.ScanShoppingItemHandler)))
+
+	http.HandleFunc("/api/shopping-cart/suggestions",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.GetItemSuggestionsHandler)))
+
+	// Purchased and removed items are archived and can be re-added
+	http.HandleFunc("/api/shopping-cart/archive",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.GetArchivedShoppingItemsHandler)))
+
+	http.HandleFunc("/api/shopping-cart/readd",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.ReaddArchivedItemHandler)))
+
+	// Live shopping list updates (server-sent events)
+	http.HandleFunc("/api/shopping-cart/events",
+		middleware.CORSMiddleware(
+			middleware.QueryTokenMiddleware(
+				middleware.AuthMiddleware(
+					handlers.ShoppingEventsHandler))))
+
+	// Price history of items the group has bought
+	http.HandleFunc("/api/shopping/items/",
+		middleware.CORSMiddleware(
+			middleware.AuthMiddleware(
+				handlers.GetItemPriceHistoryHandler)))
+
+	// Expense routes
+	// GET lists the group's expenses with filters, POST records a new expense
+	http.HandleFunc("/api/expenses", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetExpensesHandler(w, r)
+		case http.MethodPost:
+			createExpenseValidation := middleware.ValidateRequest(handlers.CreateExpenseHandler, handlers.CreateExpenseRequest{})
+			createExpenseValidation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	http.HandleFunc("/api/expenses/balances", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetExpenseBalancesHandler)))
+	http.HandleFunc("/api/expenses/report", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetExpenseReportHandler)))
+	// Splitwise: POST /api/expenses/splitwise/import copies a Splitwise group's history in from its API or CSV export,
+	// and POST /api/expenses/splitwise/settlements records the group's settlements there
+	http.HandleFunc("/api/expenses/splitwise/import", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ImportSplitwiseHandler)))
+	http.HandleFunc("/api/expenses/splitwise/settlements", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ExportSplitwiseSettlementsHandler)))
+	// GET /api/expenses/{id} returns an expense with its receipt link and PUT edits it; PUT/DELETE /api/expenses/{id}/receipt
+	// manage the receipt; POST /api/expenses/{id}/dispute, /dispute/comments and /dispute/resolve manage a dispute
+	http.HandleFunc("/api/expenses/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ExpenseHandler)))
+	// Receipt images are read through signed links, which stand in for the auth header
+	http.HandleFunc("/api/expenses/receipts/", middleware.CORSMiddleware(handlers.ServeExpenseReceiptHandler))
+
+	// Recurring bill routes
+	// GET lists the group's recurring bills, POST sets up a new one
+	http.HandleFunc("/api/bills/recurring", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetRecurringBillsHandler(w, r)
+		case http.MethodPost:
+			createBillValidation := middleware.ValidateRequest(handlers.CreateRecurringBillHandler, handlers.CreateRecurringBillRequest{})
+			createBillValidation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	http.HandleFunc("/api/bills/recurring/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteRecurringBillHandler)))
+
+	// Rent routes
+	// GET returns the rent in effect today, PUT records a new version (admins only)
+	http.HandleFunc("/api/rent", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetRentConfigHandler(w, r)
+		case http.MethodPut:
+			updateRentValidation := middleware.ValidateRequest(handlers.UpdateRentConfigHandler, handlers.UpdateRentConfigRequest{})
+			updateRentValidation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	http.HandleFunc("/api/rent/history", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetRentHistoryHandler)))
+
+	// Meter reading routes
+	// GET lists the group's meter readings, POST records a new one
+	http.HandleFunc("/api/meters/readings", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetMeterReadingsHandler(w, r)
+		case http.MethodPost:
+			createReadingValidation := middleware.ValidateRequest(handlers.CreateMeterReadingHandler, handlers.CreateMeterReadingRequest{})
+			createReadingValidation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	// GET/DELETE /api/meters/readings/{id} manage a reading; PUT/DELETE /api/meters/readings/{id}/photo manage its photo
+	http.HandleFunc("/api/meters/readings/", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.MeterReadingHandler)))
+	// Meter photos are read through signed links, which stand in for the auth header
+	http.HandleFunc("/api/meters/photos/", middleware.CORSMiddleware(handlers.ServeMeterPhotoHandler))
+	http.HandleFunc("/api/meters/consumption", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetMeterConsumptionHandler)))
+
+	// Settlement routes
+	// GET lists the group's recorded repayments, POST records a new one
+	http.HandleFunc("/api/settlements", middleware.CORSMiddleware(middleware.AuthMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetSettlementsHandler(w, r)
+		case http.MethodPost:
+			createSettlementValidation := middleware.ValidateRequest(handlers.CreateSettlementHandler, handlers.CreateSettlementRequest{})
+			createSettlementValidation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})))
+	http.HandleFunc("/api/settlements/history", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetSettlementHistoryHandler)))
+
+	// Shopping list routes
+	http.HandleFunc("/api/shopping-lists", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.GetShoppingListsHandler)))
+	http.HandleFunc("/api/shopping-lists/create", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.CreateShoppingListHandler)))
+	http.HandleFunc("/api/shopping-lists/update", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.UpdateShoppingListHandler)))
+	http.HandleFunc("/api/shopping-lists/delete", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.DeleteShoppingListHandler)))
+	http.HandleFunc("/api/shopping-lists/export", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ExportShoppingListHandler)))
+	http.HandleFunc("/api/shopping-lists/share", middleware.CORSMiddleware(middleware.AuthMiddleware(handlers.ShareShoppingListHandler)))
+
+	// Public shopping list links and calendar feeds - no authentication, the token or signature grants read-only access
+	http.HandleFunc("/api/public/shopping-lists/", middleware.CORSMiddleware(handlers.GetSharedShoppingListHandler))
+	http.HandleFunc("/api/public/calendar/", middleware.CORSMiddleware(handlers.PublicCalendarHandler))
 
 	// Shopping cart activity routes
 	http.HandleFunc("/api/shopping-cart/activity",
@@ -145,9 +542,16 @@ func main() {
 			middleware.AuthMiddleware(
 				handlers.MarkActivityReadHandler)))
 
+	// Serve /api/v1 alongside the unversioned /api paths, and later versions from the routes they change.
+	// From /api/v2 on, errors are JSON with a stable code.
+	api := middleware.ErrorResponseMiddleware(middleware.APIVersionMiddleware(http.DefaultServeMux, middleware.LatestAPIVersion))
+
+	// Serve the internal gRPC API for other services when GRPC_PORT and GRPC_API_KEY are set
+	rpc.Start(api)
+
 	port := 8080
 	log.Printf("Server starting on port %d...", port)
-	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), nil); err != nil {
+	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), api); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os"
 	"strings"
@@ -44,36 +45,13 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		tokenString := parts[1]
 
 		// Parse and validate the token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return config.JWTSecret, nil
-		})
-
+		userClaims, err := ParseToken(tokenString)
 		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		if !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
-
-		// Extract claims
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
 		// Store user info in context
-		userClaims := UserClaims{
-			ID:       claims["id"].(string),
-			Username: claims["username"].(string),
-		}
 		ctx := context.WithValue(r.Context(), UserContextKey, userClaims)
 
 		// Call next handler with updated context
@@ -81,6 +59,32 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// ParseToken validates a JWT issued at login and returns the user it was issued to
+func ParseToken(tokenString string) (UserClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		// Validate the signing method
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return config.JWTSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return UserClaims{}, errors.New("Invalid token")
+	}
+
+	// Extract claims
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return UserClaims{}, errors.New("Invalid token claims")
+	}
+	id, _ := claims["id"].(string)
+	username, _ := claims["username"].(string)
+	if id == "" {
+		return UserClaims{}, errors.New("Invalid token claims")
+	}
+	return UserClaims{ID: id, Username: username}, nil
+}
+
 // GetUserFromContext extracts user claims from the request context
 func GetUserFromContext(ctx context.Context) (UserClaims, bool) {
 	user, ok := ctx.Value(UserContextKey).(UserClaims)
@@ -99,9 +103,10 @@ func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 
 		// Set CORS headers
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Accept, X-Requested-With")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Accept, X-Requested-With, If-None-Match")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Expose-Headers", "API-Version, ETag, Next-Cursor")
 		w.Header().Set("Access-Control-Max-Age", "3600")
 
 		// Handle preflight requests
@@ -113,3 +118,19 @@ func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		next(w, r)
 	}
 }
+
+// QueryTokenMiddleware accepts the JWT as an access_token query parameter when no Authorization
+// header is sent, for endpoints read by browser EventSource, WebSocket or image tags, which cannot
+// set headers.
+// Wrap it around AuthMiddleware.
+func QueryTokenMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,116 @@
+// middleware/errors.go
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"cribb-backend/apierror"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// JSONErrorsAPIVersion is the first API version whose errors are JSON bodies with a code, see
+// apierror.Error. Earlier versions keep the plain text message.
+const JSONErrorsAPIVersion = 2
+
+// WriteError responds with the error: as JSON from JSONErrorsAPIVersion on, and as the plain text
+// message earlier versions send. The code is sent in the Error-Code header either way.
+func WriteError(w http.ResponseWriter, r *http.Request, err *apierror.Error) {
+	if GetAPIVersion(r.Context()) >= JSONErrorsAPIVersion {
+		apierror.Write(w, err)
+		return
+	}
+	w.Header().Set(apierror.CodeHeader, string(err.Code))
+	http.Error(w, err.Message, err.Status)
+}
+
+// ErrorResponseMiddleware gives every API error response an Error-Code header. Handlers that
+// report a specific failure pick its code with WriteError; the plain text errors they write with
+// http.Error get the general code of their status. For requests made to JSONErrorsAPIVersion or
+// later those plain text errors are also turned into JSON errors. It goes outside
+// APIVersionMiddleware, so errors that middleware writes are coded too.
+func ErrorResponseMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version, _, _ := parseAPIVersion(r.URL.Path)
+		ew := &errorResponseWriter{ResponseWriter: w, json: version >= JSONErrorsAPIVersion}
+		next.ServeHTTP(ew, r)
+		ew.finish()
+	})
+}
+
+// errorResponseWriter codes error responses by their status when the handler gave no code. With
+// json set it holds back plain text error responses so they can be sent as JSON. Every other
+// response is passed through.
+type errorResponseWriter struct {
+	http.ResponseWriter
+	json        bool
+	wroteHeader bool
+	status      int // Status of the held back error; 0 when the response is passed through
+	message     bytes.Buffer
+}
+
+func (w *errorResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if status >= http.StatusBadRequest && w.Header().Get(apierror.CodeHeader) == "" {
+		w.Header().Set(apierror.CodeHeader, string(apierror.CodeForStatus(status)))
+	}
+	if w.json && status >= http.StatusBadRequest && strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain") {
+		w.status = status
+		return
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.status != 0 {
+		return w.message.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// finish sends the held back error, if any, as JSON
+func (w *errorResponseWriter) finish() {
+	if w.status == 0 {
+		return
+	}
+	err := apierror.FromStatus(w.status, strings.TrimSpace(w.message.String()))
+	if code := w.Header().Get(apierror.CodeHeader); code != "" {
+		err.Code = apierror.Code(code)
+	}
+	w.Header().Del("X-Content-Type-Options")
+	apierror.Write(w.ResponseWriter, err)
+}
+
+// Flush sends buffered data of streamed responses, such as server-sent events
+func (w *errorResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.status == 0 {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection to websocket handlers
+func (w *errorResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying response for http.ResponseController
+func (w *errorResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
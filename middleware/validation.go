@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"bytes"
+	"cribb-backend/apierror"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -56,6 +57,14 @@ func ValidateRequest(next http.HandlerFunc, validator interface{}) http.HandlerF
 
 		// Validate the struct
 		errors := validateStruct(val)
+		if len(errors) > 0 && GetAPIVersion(r.Context()) >= JSONErrorsAPIVersion {
+			fields := make([]apierror.FieldError, 0, len(errors))
+			for _, e := range errors {
+				fields = append(fields, apierror.FieldError{Field: e.Field, Message: e.Message})
+			}
+			WriteError(w, r, apierror.New(apierror.CodeValidationFailed, "Validation failed").WithFields(fields...))
+			return
+		}
 		if len(errors) > 0 {
 			// Return validation errors
 			response := ValidationResponse{
@@ -0,0 +1,96 @@
+// middleware/versioning.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// LatestAPIVersion is the newest API version clients can request under /api/v{n}. Version 2
+// sends errors as JSON with a code, see JSONErrorsAPIVersion.
+const LatestAPIVersion = 2
+
+const apiVersionContextKey contextKey = "api_version"
+
+// APIVersionMiddleware serves versioned API paths from mux. Version 1 routes are registered under
+// /api and are served at both /api and /api/v1, so clients that predate versioning keep working.
+// A later version registers only the routes it changes, under /api/v{n}; a request for
+// /api/v{n}/path is served by the newest route registered at or below version n. Versions above
+// latest are not found. The version is passed on in the request context, see GetAPIVersion, and
+// reported in the API-Version response header.
+func APIVersionMiddleware(mux *http.ServeMux, latest int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api" && !strings.HasPrefix(r.URL.Path, "/api/") {
+			mux.ServeHTTP(w, r)
+			return
+		}
+
+		version, rest, versioned := parseAPIVersion(r.URL.Path)
+		if version < 1 || version > latest {
+			http.Error(w, "Unsupported API version", http.StatusNotFound)
+			return
+		}
+
+		path := r.URL.Path
+		if versioned {
+			path = "/api" + rest
+			for v := version; v > 1; v-- {
+				prefix := fmt.Sprintf("/api/v%d", v)
+				if hasRoute(mux, r, prefix+rest, prefix+"/") {
+					path = prefix + rest
+					break
+				}
+			}
+		}
+
+		w.Header().Set("API-Version", strconv.Itoa(version))
+		r = r.WithContext(context.WithValue(r.Context(), apiVersionContextKey, version))
+		if path != r.URL.Path {
+			url := *r.URL
+			url.Path = path
+			url.RawPath = ""
+			r.URL = &url
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// GetAPIVersion returns the API version the request was made for, 1 for unversioned paths
+func GetAPIVersion(ctx context.Context) int {
+	if version, ok := ctx.Value(apiVersionContextKey).(int); ok {
+		return version
+	}
+	return 1
+}
+
+// parseAPIVersion splits /api/v{n}/rest into its version and the rest of the path. Unversioned
+// API paths are version 1.
+func parseAPIVersion(path string) (version int, rest string, versioned bool) {
+	segment := strings.TrimPrefix(path, "/api/")
+	if end := strings.Index(segment, "/"); end >= 0 {
+		rest = segment[end:]
+		segment = segment[:end]
+	}
+	if len(segment) < 2 || segment[0] != 'v' {
+		return 1, "", false
+	}
+	version, err := strconv.Atoi(segment[1:])
+	if err != nil || segment[1] == '+' || segment[1] == '-' {
+		return 1, "", false
+	}
+	return version, rest, true
+}
+
+// hasRoute reports whether mux serves path with a route registered under prefix
+func hasRoute(mux *http.ServeMux, r *http.Request, path, prefix string) bool {
+	probe := *r
+	url := *r.URL
+	url.Path = path
+	url.RawPath = ""
+	probe.URL = &url
+	_, pattern := mux.Handler(&probe)
+	return strings.HasPrefix(pattern, prefix)
+}
@@ -3,10 +3,14 @@ package middleware_test
 
 import (
 	"context"
+	"cribb-backend/apierror"
 	"cribb-backend/config"
 	"cribb-backend/middleware"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -181,7 +185,7 @@ func TestCORSMiddleware(t *testing.T) {
 		t.Errorf("handler returned wrong CORS origin header: got %v want %v", origin, expectedOrigin)
 	}
 
-	expectedMethods := "GET, POST, PUT, DELETE, OPTIONS"
+	expectedMethods := "GET, POST, PUT, PATCH, DELETE, OPTIONS"
 	if methods := rr.Header().Get("Access-Control-Allow-Methods"); methods != expectedMethods {
 		t.Errorf("handler returned wrong CORS methods header: got %v want %v", methods, expectedMethods)
 	}
@@ -218,3 +222,208 @@ func TestCORSMiddlewareOptions(t *testing.T) {
 		t.Errorf("handler returned wrong CORS origin header: got %v want %v", origin, expectedOrigin)
 	}
 }
+
+func TestQueryTokenMiddleware(t *testing.T) {
+	testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := middleware.GetUserFromContext(r.Context()); !ok {
+			t.Error("Expected user claims in context")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"id":       "test-id",
+		"username": "testuser",
+		"exp":      time.Now().Add(time.Hour).Unix(),
+	})
+
+	tokenString, err := token.SignedString(config.JWTSecret)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	// EventSource clients pass the token in the query string
+	req, err := http.NewRequest("GET", "/test?access_token="+tokenString, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	middleware.QueryTokenMiddleware(middleware.AuthMiddleware(testHandler)).ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+}
+
+func TestAPIVersionMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	route := func(name string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(fmt.Sprintf("%s %d %s", name, middleware.GetAPIVersion(r.Context()), r.URL.Path)))
+		}
+	}
+	mux.HandleFunc("/api/chores/user", route("v1"))
+	mux.HandleFunc("/api/groups/", route("v1"))
+	mux.HandleFunc("/api/v2/groups/", route("v2"))
+	mux.HandleFunc("/health", route("health"))
+	handler := middleware.APIVersionMiddleware(mux, 2)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/api/chores/user", http.StatusOK, "v1 1 /api/chores/user"},
+		{"/api/v1/chores/user", http.StatusOK, "v1 1 /api/chores/user"},
+		{"/api/v2/chores/user", http.StatusOK, "v1 2 /api/chores/user"}, // Unchanged in v2
+		{"/api/v1/groups/abc/balances", http.StatusOK, "v1 1 /api/groups/abc/balances"},
+		{"/api/v2/groups/abc/balances", http.StatusOK, "v2 2 /api/v2/groups/abc/balances"},
+		{"/api/v3/chores/user", http.StatusNotFound, "Unsupported API version\n"},
+		{"/api/v0/chores/user", http.StatusNotFound, "Unsupported API version\n"},
+		{"/health", http.StatusOK, "health 1 /health"},
+	}
+
+	for _, tt := range tests {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rr.Code != tt.status || rr.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.path, rr.Code, rr.Body.String(), tt.status, tt.body)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v2/chores/user", nil))
+	if version := rr.Header().Get("API-Version"); version != "2" {
+		t.Errorf("expected the version in the response header, got %q", version)
+	}
+}
+
+func TestGetAPIVersionDefault(t *testing.T) {
+	if version := middleware.GetAPIVersion(context.Background()); version != 1 {
+		t.Errorf("expected requests outside the middleware to be version 1, got %d", version)
+	}
+}
+
+func TestETagMiddleware(t *testing.T) {
+	body := `{"name":"Flat 4"}`
+	handler := middleware.ETagMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/groups/details", nil))
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || rr.Body.String() != body || etag == "" {
+		t.Fatalf("expected the body with an ETag, got %d %q %q", rr.Code, rr.Body.String(), etag)
+	}
+
+	// The same tag, also when sent weak or among others, skips the body
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag} {
+		req := httptest.NewRequest(http.MethodGet, "/api/groups/details", nil)
+		req.Header.Set("If-None-Match", ifNoneMatch)
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 || rr.Header().Get("ETag") != etag {
+			t.Errorf("If-None-Match %s: expected 304 without a body, got %d %q", ifNoneMatch, rr.Code, rr.Body.String())
+		}
+	}
+
+	// A changed body gets a new tag
+	body = `{"name":"Flat 5"}`
+	req := httptest.NewRequest(http.MethodGet, "/api/groups/details", nil)
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK || rr.Body.String() != body || rr.Header().Get("ETag") == etag {
+		t.Errorf("expected the new body with a new ETag, got %d %q", rr.Code, rr.Body.String())
+	}
+}
+
+func TestETagMiddlewareSkipsErrorsAndWrites(t *testing.T) {
+	failing := middleware.ETagMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Group not found", http.StatusNotFound)
+	})
+	rr := httptest.NewRecorder()
+	failing.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/groups/details", nil))
+	if rr.Code != http.StatusNotFound || rr.Header().Get("ETag") != "" {
+		t.Errorf("expected errors to pass through untagged, got %d with %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	rr = httptest.NewRecorder()
+	middleware.ETagMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/groups/details", nil))
+	if rr.Code != http.StatusCreated || rr.Header().Get("ETag") != "" {
+		t.Errorf("expected writes to pass through untagged, got %d with %q", rr.Code, rr.Header().Get("ETag"))
+	}
+}
+
+func TestErrorResponseMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chores/update", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Chore not found", http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/chores/complete", func(w http.ResponseWriter, r *http.Request) {
+		middleware.WriteError(w, r, apierror.New(apierror.CodeChoreLocked, "Cannot update a completed chore"))
+	})
+	mux.HandleFunc("/api/chores/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	})
+	mux.HandleFunc("/api/pantry/add", middleware.ValidateRequest(func(w http.ResponseWriter, r *http.Request) {}, struct {
+		Name string `json:"name" validate:"required"`
+	}{}))
+	handler := middleware.ErrorResponseMiddleware(middleware.APIVersionMiddleware(mux, 2))
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	decode := func(rr *httptest.ResponseRecorder) apierror.Error {
+		t.Helper()
+		var body apierror.Error
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("expected a JSON error, got %q: %v", rr.Body.String(), err)
+		}
+		return body
+	}
+
+	// Version 1 keeps plain text errors, with the handler's code or the status's general code in a header
+	if rr := serve(http.MethodGet, "/api/chores/update", ""); rr.Code != http.StatusNotFound || rr.Body.String() != "Chore not found\n" ||
+		rr.Header().Get(apierror.CodeHeader) != "NOT_FOUND" {
+		t.Errorf("expected the plain text error with its general code, got %d %v %q", rr.Code, rr.Header(), rr.Body.String())
+	}
+	if rr := serve(http.MethodGet, "/api/chores/user", ""); rr.Code != http.StatusOK || rr.Header().Get(apierror.CodeHeader) != "" {
+		t.Errorf("expected successful responses without a code, got %d %v", rr.Code, rr.Header())
+	}
+	if rr := serve(http.MethodGet, "/api/v1/chores/complete", ""); rr.Code != http.StatusConflict || rr.Header().Get(apierror.CodeHeader) != "CHORE_LOCKED" ||
+		rr.Body.String() != "Cannot update a completed chore\n" {
+		t.Errorf("expected the plain text error with its code, got %d %v %q", rr.Code, rr.Header(), rr.Body.String())
+	}
+
+	// Version 2 sends every error as JSON
+	rr := serve(http.MethodGet, "/api/v2/chores/update", "")
+	if body := decode(rr); rr.Code != http.StatusNotFound || body.Code != apierror.CodeNotFound || body.Message != "Chore not found" ||
+		rr.Header().Get(apierror.CodeHeader) != "NOT_FOUND" {
+		t.Errorf("expected a converted error, got %d %+v", rr.Code, body)
+	}
+	rr = serve(http.MethodGet, "/api/v2/chores/complete", "")
+	if body := decode(rr); rr.Code != http.StatusConflict || body.Code != apierror.CodeChoreLocked {
+		t.Errorf("expected the handler's code, got %d %+v", rr.Code, body)
+	}
+	rr = serve(http.MethodPost, "/api/v2/pantry/add", `{}`)
+	if body := decode(rr); body.Code != apierror.CodeValidationFailed || len(body.Fields) != 1 || body.Fields[0].Field != "name" {
+		t.Errorf("expected the invalid field, got %+v", body)
+	}
+	if rr := serve(http.MethodGet, "/api/v3/chores/user", ""); rr.Code != http.StatusNotFound || decode(rr).Code != apierror.CodeNotFound {
+		t.Errorf("expected unsupported versions to be JSON errors too, got %d", rr.Code)
+	}
+
+	// Successful responses pass through untouched
+	if rr := serve(http.MethodGet, "/api/v2/chores/user", ""); rr.Code != http.StatusOK || rr.Body.String() != `[]` {
+		t.Errorf("expected the response unchanged, got %d %q", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShoppingArchiveRetention is how long purchased and removed cart items stay in the archive
+const ShoppingArchiveRetention = 30 * 24 * time.Hour
+
+// ArchivedShoppingCartItem is a cart item that was purchased or removed, kept so it can be
+// re-added with one tap. It keeps the original item's ID. Archived items are removed by a
+// TTL index on archived_at after ShoppingArchiveRetention.
+type ArchivedShoppingCartItem struct {
+	ShoppingCartItem `bson:",inline"`
+	PurchasedAt      *time.Time          `bson:"purchased_at,omitempty" json:"purchased_at,omitempty"` // Unset for removed items
+	PurchasedBy      *primitive.ObjectID `bson:"purchased_by,omitempty" json:"purchased_by,omitempty"`
+	ArchivedBy       primitive.ObjectID  `bson:"archived_by" json:"archived_by"`
+	ArchivedAt       time.Time           `bson:"archived_at" json:"archived_at"`
+}
+
+// ArchiveShoppingCartItem archives an item removed from the cart by archivedBy
+func ArchiveShoppingCartItem(item ShoppingCartItem, archivedBy primitive.ObjectID) *ArchivedShoppingCartItem {
+	return &ArchivedShoppingCartItem{
+		ShoppingCartItem: item,
+		ArchivedBy:       archivedBy,
+		ArchivedAt:       time.Now(),
+	}
+}
+
+// ArchivePurchasedItem archives an item bought by purchasedBy
+func ArchivePurchasedItem(item ShoppingCartItem, purchasedBy primitive.ObjectID, purchasedAt time.Time) *ArchivedShoppingCartItem {
+	archived := ArchiveShoppingCartItem(item, purchasedBy)
+	archived.PurchasedAt = &purchasedAt
+	archived.PurchasedBy = &purchasedBy
+	return archived
+}
+
+// WasPurchased reports whether the item left the cart by being bought rather than removed
+func (a *ArchivedShoppingCartItem) WasPurchased() bool {
+	return a.PurchasedAt != nil
+}
+
+// ExpiresAt returns when the archived item will be cleaned up
+func (a *ArchivedShoppingCartItem) ExpiresAt() time.Time {
+	return a.ArchivedAt.Add(ShoppingArchiveRetention)
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementStats is the snapshot of a user's activity that badge rules are evaluated against
+type AchievementStats struct {
+	TotalCompletions  int
+	DishesCompletions int // Completed chores with "dish" in the title
+	BestDailyStreak   int
+}
+
+// BadgeDefinition describes a badge and the rule that earns it
+type BadgeDefinition struct {
+	Key         string                      `json:"key"`
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Earned      func(AchievementStats) bool `json:"-"`
+}
+
+// UserBadge records a badge awarded to a user
+type UserBadge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	BadgeKey  string             `bson:"badge_key" json:"badge_key"`
+	AwardedAt time.Time          `bson:"awarded_at" json:"awarded_at"`
+}
+
+// BadgeDefinitions lists every badge that can be earned
+var BadgeDefinitions = []BadgeDefinition{
+	{
+		Key:         "first_chore",
+		Name:        "First Steps",
+		Description: "Complete your first chore",
+		Earned:      func(s AchievementStats) bool { return s.TotalCompletions >= 1 },
+	},
+	{
+		Key:         "fifty_chores",
+		Name:        "Half Century",
+		Description: "Complete 50 chores",
+		Earned:      func(s AchievementStats) bool { return s.TotalCompletions >= 50 },
+	},
+	{
+		Key:         "streak_30",
+		Name:        "Unstoppable",
+		Description: "Complete all your chores on time for 30 days in a row",
+		Earned:      func(s AchievementStats) bool { return s.BestDailyStreak >= 30 },
+	},
+	{
+		Key:         "dishes_dynasty",
+		Name:        "Dishes Dynasty",
+		Description: "Complete 25 dish duty chores",
+		Earned:      func(s AchievementStats) bool { return s.DishesCompletions >= 25 },
+	},
+}
+
+// CreateUserBadge creates a record of a badge awarded to a user
+func CreateUserBadge(userID, groupID primitive.ObjectID, badgeKey string) *UserBadge {
+	return &UserBadge{
+		UserID:    userID,
+		GroupID:   groupID,
+		BadgeKey:  badgeKey,
+		AwardedAt: time.Now(),
+	}
+}
+
+// EarnedBadgeKeys returns the keys of every badge whose rule the stats satisfy
+func EarnedBadgeKeys(stats AchievementStats) []string {
+	keys := make([]string, 0)
+	for _, badge := range BadgeDefinitions {
+		if badge.Earned(stats) {
+			keys = append(keys, badge.Key)
+		}
+	}
+	return keys
+}
+
+// FindBadgeDefinition looks up a badge by key
+func FindBadgeDefinition(key string) (BadgeDefinition, bool) {
+	for _, badge := range BadgeDefinitions {
+		if badge.Key == key {
+			return badge, true
+		}
+	}
+	return BadgeDefinition{}, false
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BlackoutPolicy controls what happens to a recurring chore occurrence that falls on a blackout date
+type BlackoutPolicy string
+
+const (
+	BlackoutPolicyPush BlackoutPolicy = "push" // Move the occurrence to the first day after the blackout
+	BlackoutPolicySkip BlackoutPolicy = "skip" // Drop the occurrence entirely
+)
+
+// BlackoutDate represents a range of days (holidays, exam weeks) on which a group's recurring chores are not scheduled
+type BlackoutDate struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	StartDate time.Time          `bson:"start_date" json:"start_date" validate:"required"`
+	EndDate   time.Time          `bson:"end_date" json:"end_date" validate:"required"`
+	Reason    string             `bson:"reason" json:"reason"`
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateBlackoutDate creates a new blackout range for a group
+func CreateBlackoutDate(groupID, createdBy primitive.ObjectID, startDate, endDate time.Time, reason string) *BlackoutDate {
+	return &BlackoutDate{
+		GroupID:   groupID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    reason,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// startOfDayUTC returns midnight UTC for the date portion of the supplied time
+func startOfDayUTC(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// Covers reports whether t falls on any day of the blackout range (inclusive, by UTC date)
+func (b *BlackoutDate) Covers(t time.Time) bool {
+	day := startOfDayUTC(t)
+	return !day.Before(startOfDayUTC(b.StartDate)) && !day.After(startOfDayUTC(b.EndDate))
+}
+
+// ApplyBlackouts adjusts an occurrence date according to the blackout policy.
+// With the push policy the date moves to the first free day after the blackout, keeping its time of day.
+// With the skip policy the occurrence is dropped and false is returned.
+func ApplyBlackouts(due time.Time, policy BlackoutPolicy, blackouts []BlackoutDate) (time.Time, bool) {
+	// Each pass can only move the date forward, so one pass per blackout is enough to settle overlaps
+	for i := 0; i <= len(blackouts); i++ {
+		moved := false
+		for _, blackout := range blackouts {
+			if !blackout.Covers(due) {
+				continue
+			}
+			if policy == BlackoutPolicySkip {
+				return due, false
+			}
+			days := int(startOfDayUTC(blackout.EndDate).Sub(startOfDayUTC(due)).Hours()/24) + 1
+			due = due.AddDate(0, 0, days)
+			moved = true
+		}
+		if !moved {
+			break
+		}
+	}
+	return due, true
+}
@@ -0,0 +1,287 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportRowType names what a row of a bulk import creates
+type ImportRowType string
+
+const (
+	ImportRowChore          ImportRowType = "chore"
+	ImportRowRecurringChore ImportRowType = "recurring_chore"
+	ImportRowPantryItem     ImportRowType = "pantry_item"
+)
+
+// ImportFormat is the file format of a bulk import
+type ImportFormat string
+
+const (
+	ImportFormatCSV  ImportFormat = "csv"
+	ImportFormatJSON ImportFormat = "json"
+)
+
+const (
+	// MaxImportSize is the largest import file accepted, in bytes
+	MaxImportSize = 1 << 20
+	// MaxImportRows is the most rows a single import can hold
+	MaxImportRows = 500
+)
+
+var (
+	ErrEmptyImport       = errors.New("the file has no rows")
+	ErrTooManyImportRows = fmt.Errorf("imports are limited to %d rows", MaxImportRows)
+)
+
+// ImportRow is one chore, recurring chore or pantry item of a bulk import. CSV files use the JSON
+// names as column headers, list rotation members separated by semicolons and leave unused cells empty.
+type ImportRow struct {
+	Type        ImportRowType `json:"type"`
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Points      int           `json:"points,omitempty"` // Zero uses the group's default
+	// Chores
+	AssignedTo string `json:"assigned_to,omitempty"` // Username
+	DueDate    string `json:"due_date,omitempty"`
+	// Recurring chores
+	Frequency    string   `json:"frequency,omitempty"`
+	Members      []string `json:"members,omitempty"` // Usernames in rotation order; empty rotates through everyone
+	FirstDueDate string   `json:"first_due_date,omitempty"`
+	// Pantry items
+	Name           string  `json:"name,omitempty"`
+	Quantity       float64 `json:"quantity,omitempty"`
+	Unit           string  `json:"unit,omitempty"`
+	Category       string  `json:"category,omitempty"` // Category name or ID
+	ExpirationDate string  `json:"expiration_date,omitempty"`
+	MinQuantity    float64 `json:"min_quantity,omitempty"`
+	Notes          string  `json:"notes,omitempty"`
+}
+
+// ImportError is a problem with one row of an import. Rows are numbered from 1 in file order, not
+// counting the CSV header.
+type ImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// importColumns are the CSV columns an import understands
+var importColumns = map[string]bool{
+	"type": true, "title": true, "description": true, "points": true,
+	"assigned_to": true, "due_date": true,
+	"frequency": true, "members": true, "first_due_date": true,
+	"name": true, "quantity": true, "unit": true, "category": true,
+	"expiration_date": true, "min_quantity": true, "notes": true,
+}
+
+// ParseImport reads the rows of an import file. Cells that cannot be converted are reported as row
+// errors; an error is returned when the file itself cannot be read.
+func ParseImport(data []byte, format ImportFormat) ([]ImportRow, []ImportError, error) {
+	var rows []ImportRow
+	var rowErrors []ImportError
+
+	switch format {
+	case ImportFormatJSON:
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return nil, nil, errors.New("the file must be a JSON array of rows")
+		}
+	case ImportFormatCSV:
+		var err error
+		if rows, rowErrors, err = parseImportCSV(data); err != nil {
+			return nil, nil, err
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported import format %q", format)
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, ErrEmptyImport
+	}
+	if len(rows) > MaxImportRows {
+		return nil, nil, ErrTooManyImportRows
+	}
+	return rows, rowErrors, nil
+}
+
+// parseImportCSV reads rows from CSV with a header row naming the columns
+func parseImportCSV(data []byte) ([]ImportRow, []ImportError, error) {
+	// Spreadsheet exports often start with a byte order mark
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Trailing empty cells may be left off
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrEmptyImport
+	}
+	if err != nil {
+		return nil, nil, errors.New("the file is not valid CSV")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !importColumns[name] {
+			return nil, nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["type"]; !ok {
+		return nil, nil, errors.New("the file needs a type column")
+	}
+
+	var rows []ImportRow
+	var rowErrors []ImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("the file is not valid CSV: %v", err)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, nil, ErrTooManyImportRows
+		}
+
+		n := len(rows) + 1
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		number := func(name string) float64 {
+			value := cell(name)
+			if value == "" {
+				return 0
+			}
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				rowErrors = append(rowErrors, ImportError{Row: n, Field: name, Message: "must be a number"})
+			}
+			return f
+		}
+
+		row := ImportRow{
+			Type:           ImportRowType(strings.ToLower(cell("type"))),
+			Title:          cell("title"),
+			Description:    cell("description"),
+			AssignedTo:     cell("assigned_to"),
+			DueDate:        cell("due_date"),
+			Frequency:      strings.ToLower(cell("frequency")),
+			FirstDueDate:   cell("first_due_date"),
+			Name:           cell("name"),
+			Quantity:       number("quantity"),
+			Unit:           cell("unit"),
+			Category:       cell("category"),
+			ExpirationDate: cell("expiration_date"),
+			MinQuantity:    number("min_quantity"),
+			Notes:          cell("notes"),
+		}
+		if value := cell("points"); value != "" {
+			points, err := strconv.Atoi(value)
+			if err != nil {
+				rowErrors = append(rowErrors, ImportError{Row: n, Field: "points", Message: "must be a whole number"})
+			}
+			row.Points = points
+		}
+		for _, member := range strings.Split(cell("members"), ";") {
+			if member = strings.TrimSpace(member); member != "" {
+				row.Members = append(row.Members, member)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors, nil
+}
+
+// ParseImportDate reads an RFC3339 timestamp or a YYYY-MM-DD date. Dates are taken as the end of
+// that day in UTC when endOfDay is set, so a chore due on a date can be done all day.
+func ParseImportDate(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		return endOfDayUTC(t), nil
+	}
+	return t, nil
+}
+
+// Validate checks the fields a row of its type needs, without looking up members or categories.
+// n is the row's number in the file.
+func (row ImportRow) Validate(n int) []ImportError {
+	var rowErrors []ImportError
+	fail := func(field, message string) {
+		rowErrors = append(rowErrors, ImportError{Row: n, Field: field, Message: message})
+	}
+	date := func(field, value string, required bool) {
+		if value == "" {
+			if required {
+				fail(field, "is required")
+			}
+			return
+		}
+		if _, err := ParseImportDate(value, false); err != nil {
+			fail(field, "must be a YYYY-MM-DD date or an RFC3339 timestamp")
+		}
+	}
+
+	switch row.Type {
+	case ImportRowChore, ImportRowRecurringChore:
+		if strings.TrimSpace(row.Title) == "" {
+			fail("title", "is required")
+		}
+		if row.Points < 0 {
+			fail("points", "cannot be negative")
+		}
+		if row.Type == ImportRowChore {
+			if row.AssignedTo == "" {
+				fail("assigned_to", "is required")
+			}
+			date("due_date", row.DueDate, true)
+			break
+		}
+		switch row.Frequency {
+		case "daily", "weekly", "biweekly", "monthly":
+		case "":
+			fail("frequency", "is required")
+		default:
+			fail("frequency", "must be daily, weekly, biweekly, or monthly")
+		}
+		date("first_due_date", row.FirstDueDate, false)
+	case ImportRowPantryItem:
+		if strings.TrimSpace(row.Name) == "" {
+			fail("name", "is required")
+		}
+		if row.Quantity < 0 {
+			fail("quantity", "cannot be negative")
+		}
+		if row.Unit == "" {
+			fail("unit", "is required")
+		}
+		if row.Category == "" {
+			fail("category", "is required")
+		}
+		if row.MinQuantity < 0 {
+			fail("min_quantity", "cannot be negative")
+		}
+		date("expiration_date", row.ExpirationDate, false)
+	case "":
+		fail("type", "is required")
+	default:
+		fail("type", "must be chore, recurring_chore, or pantry_item")
+	}
+	return rowErrors
+}
@@ -0,0 +1,177 @@
+// models/calendar.go
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarFeedHorizon is how far ahead recurring chores and bills are projected in a member's feed
+const CalendarFeedHorizon = 90 * 24 * time.Hour
+
+// CalendarFeedHistory is how far back assigned chores stay in a member's feed
+const CalendarFeedHistory = 30 * 24 * time.Hour
+
+// CalendarEvent is one event of an iCalendar feed
+type CalendarEvent struct {
+	UID         string // Stable across refreshes so calendar apps update the event instead of adding it again
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time // Ignored for all-day events, which last the day of Start
+	AllDay      bool
+}
+
+// NewCalendarKey generates the random key a member's calendar feed URL is signed with. Replacing
+// it revokes URLs handed out before.
+func NewCalendarKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// ChoreCalendarEvent is the all-day event on the due date of a chore assigned to a member
+func ChoreCalendarEvent(chore Chore) CalendarEvent {
+	summary := chore.Title
+	if chore.Status == ChoreStatusCompleted {
+		summary = "Done: " + chore.Title
+	}
+	return CalendarEvent{
+		UID:         "chore-" + chore.ID.Hex() + "@cribb",
+		Summary:     summary,
+		Description: strings.TrimSpace(fmt.Sprintf("%s\n\n%d points", chore.Description, chore.Points)),
+		Start:       chore.DueDate,
+		AllDay:      true,
+	}
+}
+
+// RecurringChoreCalendarEvents are the all-day events on the due dates of the recurring chore's
+// upcoming occurrences that will be assigned to the member, up to until
+func RecurringChoreCalendarEvents(rc RecurringChore, occurrences []UpcomingOccurrence, userID primitive.ObjectID, until time.Time) []CalendarEvent {
+	var events []CalendarEvent
+	for _, occurrence := range occurrences {
+		if occurrence.AssignedTo != userID || occurrence.DueDate.After(until) {
+			continue
+		}
+		events = append(events, CalendarEvent{
+			UID:         "chore-" + rc.ID.Hex() + "-" + occurrence.AssignAt.UTC().Format("20060102") + "@cribb",
+			Summary:     rc.Title,
+			Description: strings.TrimSpace(fmt.Sprintf("%s\n\n%d points, repeats %s", rc.Description, rc.Points, rc.Frequency)),
+			Start:       occurrence.DueDate,
+			AllDay:      true,
+		})
+	}
+	return events
+}
+
+// Involves reports whether the member shares the bill or pays it
+func (b *RecurringBill) Involves(userID primitive.ObjectID, members []primitive.ObjectID) bool {
+	if b.PaidBy == userID {
+		return true
+	}
+	for _, id := range b.sharingMembers(members) {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// CalendarEvents are the all-day events on the bill's due dates up to until
+func (b *RecurringBill) CalendarEvents(until time.Time) []CalendarEvent {
+	var events []CalendarEvent
+	if !b.IsActive {
+		return events
+	}
+	for due := b.NextDueDate; !due.After(until); due = NextOccurrence(b.Frequency, due) {
+		events = append(events, CalendarEvent{
+			UID:         "bill-" + b.ID.Hex() + "-" + due.UTC().Format("20060102") + "@cribb",
+			Summary:     b.Description + " due",
+			Description: fmt.Sprintf("%.2f, repeats %s", b.Amount, b.Frequency),
+			Start:       due,
+			AllDay:      true,
+		})
+	}
+	return events
+}
+
+// RenderCalendar renders the events as an iCalendar (RFC 5545) feed named name, earliest first.
+// now stamps the events; calendar apps are asked to refresh the feed hourly.
+func RenderCalendar(name string, events []CalendarEvent, now time.Time) string {
+	sorted := make([]CalendarEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var b strings.Builder
+	line := func(content string) {
+		b.WriteString(foldCalendarLine(content))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Cribb//Cribb Calendar//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:" + escapeCalendarText(name))
+	line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	line("X-PUBLISHED-TTL:PT1H")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, event := range sorted {
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + stamp)
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE:" + event.Start.UTC().Format("20060102"))
+			line("DTEND;VALUE=DATE:" + event.Start.UTC().AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			line("DTSTART:" + event.Start.UTC().Format("20060102T150405Z"))
+			line("DTEND:" + event.End.UTC().Format("20060102T150405Z"))
+		}
+		line("SUMMARY:" + escapeCalendarText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escapeCalendarText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION:" + escapeCalendarText(event.Location))
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// escapeCalendarText escapes the characters with a meaning in iCalendar text values
+func escapeCalendarText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// foldCalendarLine splits a content line into lines of at most 75 octets, continued with a
+// leading space, without splitting a UTF-8 character
+func foldCalendarLine(content string) string {
+	const limit = 75
+	if len(content) <= limit {
+		return content
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range content {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChallengeGoal defines what a group has to achieve to win a challenge
+type ChallengeGoal string
+
+const (
+	// ChallengeGoalAllChores is met when every chore due within the challenge window is completed
+	ChallengeGoalAllChores ChallengeGoal = "all_chores"
+	// ChallengeGoalCompletionCount is met when the group completes Target chores within the window
+	ChallengeGoalCompletionCount ChallengeGoal = "completion_count"
+)
+
+// ChallengeStatus represents where a challenge is in its lifecycle
+type ChallengeStatus string
+
+const (
+	ChallengeStatusActive    ChallengeStatus = "active"
+	ChallengeStatusCompleted ChallengeStatus = "completed"
+	ChallengeStatusFailed    ChallengeStatus = "failed"
+)
+
+const (
+	// NotificationTypeChallengeCompleted indicates a group reached a challenge's target
+	NotificationTypeChallengeCompleted NotificationType = "challenge_completed"
+)
+
+// Challenge is a time-boxed goal the whole group works towards together
+type Challenge struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Title       string             `bson:"title" json:"title" validate:"required"`
+	Description string             `bson:"description" json:"description"`
+	Reward      string             `bson:"reward" json:"reward"` // What the group gets for succeeding, e.g. "pizza night"
+	Goal        ChallengeGoal      `bson:"goal" json:"goal" validate:"required"`
+	Target      int                `bson:"target" json:"target"` // For all_chores, the number of chores due in the window
+	Progress    int                `bson:"progress" json:"progress"`
+	StartDate   time.Time          `bson:"start_date" json:"start_date" validate:"required"`
+	EndDate     time.Time          `bson:"end_date" json:"end_date" validate:"required"`
+	Status      ChallengeStatus    `bson:"status" json:"status"`
+	CompletedAt *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateChallenge creates a new active challenge
+func CreateChallenge(groupID, createdBy primitive.ObjectID, title, description, reward string, goal ChallengeGoal, target int, start, end time.Time) *Challenge {
+	return &Challenge{
+		GroupID:     groupID,
+		Title:       title,
+		Description: description,
+		Reward:      reward,
+		Goal:        goal,
+		Target:      target,
+		StartDate:   start,
+		EndDate:     end,
+		Status:      ChallengeStatusActive,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// IsValidChallengeGoal checks whether goal is a supported challenge goal
+func IsValidChallengeGoal(goal ChallengeGoal) bool {
+	return goal == ChallengeGoalAllChores || goal == ChallengeGoalCompletionCount
+}
+
+// UpdateProgress records the group's latest progress and moves an active challenge to
+// completed or failed. completed is the number of qualifying completions and due the
+// number of chores due in the window (used as the target of all_chores challenges).
+// It reports whether this update completed the challenge.
+func (c *Challenge) UpdateProgress(completed, due int, now time.Time) bool {
+	if c.Status != ChallengeStatusActive {
+		return false
+	}
+
+	c.Progress = completed
+	if c.Goal == ChallengeGoalAllChores {
+		c.Target = due
+	}
+	c.UpdatedAt = now
+
+	// An all_chores challenge can only be won once its window is over, since more chores may still fall due
+	met := c.Target > 0 && c.Progress >= c.Target
+	if c.Goal == ChallengeGoalAllChores && now.Before(c.EndDate) {
+		met = false
+	}
+
+	if met {
+		c.Status = ChallengeStatusCompleted
+		c.CompletedAt = &now
+		return true
+	}
+	if !now.Before(c.EndDate) {
+		c.Status = ChallengeStatusFailed
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// models/chat_integration.go
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChatPlatform is the chat app a group's integration is installed in
+type ChatPlatform string
+
+const (
+	ChatPlatformSlack   ChatPlatform = "slack"
+	ChatPlatformDiscord ChatPlatform = "discord"
+)
+
+// DefaultChatSummaryTime is when the daily chore summary is posted unless the group picks a time
+const DefaultChatSummaryTime = "08:00"
+
+// ChatLinkCodeTTL is how long a code to link a chat account works
+const ChatLinkCodeTTL = 15 * time.Minute
+
+// chatWebhookHosts are where each platform's incoming webhooks are served
+var chatWebhookHosts = map[ChatPlatform][]string{
+	ChatPlatformSlack:   {"hooks.slack.com"},
+	ChatPlatformDiscord: {"discord.com", "discordapp.com"},
+}
+
+// ChatIntegration connects a group to the house Slack workspace or Discord server. Slash commands
+// from the workspace act on the group as the member who linked the chat account sending them, and
+// when a webhook URL is set a summary of the day's chores is posted to its channel every morning.
+type ChatIntegration struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Platform    ChatPlatform       `bson:"platform" json:"platform"`
+	WorkspaceID string             `bson:"workspace_id" json:"workspace_id"`                   // Slack team ID or Discord server (guild) ID
+	WebhookURL  string             `bson:"webhook_url,omitempty" json:"webhook_url,omitempty"` // Incoming webhook the daily summary is posted to
+	SummaryTime string             `bson:"summary_time" json:"summary_time"`                   // HH:MM
+	TimeZone    string             `bson:"time_zone,omitempty" json:"time_zone,omitempty"`     // IANA name the summary time is in; defaults to UTC
+	// NextSummaryAt is when the next summary is posted; unset without a webhook URL
+	NextSummaryAt *time.Time         `bson:"next_summary_at,omitempty" json:"next_summary_at,omitempty"`
+	Links         []ChatLink         `bson:"links" json:"links"`
+	CreatedBy     primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ChatLink ties a chat account to the member whose commands it sends
+type ChatLink struct {
+	ExternalUserID string             `bson:"external_user_id" json:"external_user_id"` // Slack or Discord user ID
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	LinkedAt       time.Time          `bson:"linked_at" json:"linked_at"`
+}
+
+// IsValidChatPlatform checks if the platform is a supported chat app
+func IsValidChatPlatform(platform ChatPlatform) bool {
+	_, ok := chatWebhookHosts[platform]
+	return ok
+}
+
+// ValidateChatIntegration checks the platform, workspace, webhook URL, summary time and time zone
+// of an integration. The webhook URL is optional but must be one of the platform's.
+func ValidateChatIntegration(platform ChatPlatform, workspaceID, webhookURL, summaryTime, timeZone string) error {
+	if !IsValidChatPlatform(platform) {
+		return errors.New("platform must be slack or discord")
+	}
+	if strings.TrimSpace(workspaceID) == "" {
+		return errors.New("workspace_id is required")
+	}
+	if webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || parsed.Scheme != "https" || !isChatWebhookHost(platform, parsed.Hostname()) {
+			return fmt.Errorf("webhook_url must be a %s incoming webhook URL", platform)
+		}
+	}
+	if _, err := time.Parse("15:04", summaryTime); err != nil {
+		return errors.New("summary time must be in HH:MM format")
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", timeZone)
+	}
+	return nil
+}
+
+func isChatWebhookHost(platform ChatPlatform, host string) bool {
+	for _, allowed := range chatWebhookHosts[platform] {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateChatIntegration creates an integration with no linked accounts and schedules its first
+// summary
+func CreateChatIntegration(groupID, createdBy primitive.ObjectID, platform ChatPlatform, workspaceID, webhookURL, summaryTime, timeZone string, now time.Time) *ChatIntegration {
+	integration := &ChatIntegration{
+		GroupID:     groupID,
+		Platform:    platform,
+		WorkspaceID: strings.TrimSpace(workspaceID),
+		WebhookURL:  webhookURL,
+		SummaryTime: summaryTime,
+		TimeZone:    timeZone,
+		Links:       []ChatLink{},
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	integration.ScheduleSummary(now)
+	return integration
+}
+
+// Zone returns the time zone the summary time is in
+func (i ChatIntegration) Zone() *time.Location {
+	if location, err := time.LoadLocation(i.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// ScheduleSummary sets when the next summary is posted: the first summary time after now, or
+// never without a webhook URL
+func (i *ChatIntegration) ScheduleSummary(now time.Time) {
+	if i.WebhookURL == "" {
+		i.NextSummaryAt = nil
+		return
+	}
+	at, err := time.Parse("15:04", i.SummaryTime)
+	if err != nil {
+		at, _ = time.Parse("15:04", DefaultChatSummaryTime)
+	}
+	local := now.In(i.Zone())
+	next := time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, i.Zone())
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, at.Hour(), at.Minute(), 0, 0, i.Zone())
+	}
+	i.NextSummaryAt = &next
+}
+
+// LinkedMember returns the member the chat account is linked to
+func (i ChatIntegration) LinkedMember(externalUserID string) (primitive.ObjectID, bool) {
+	for _, link := range i.Links {
+		if link.ExternalUserID == externalUserID {
+			return link.UserID, true
+		}
+	}
+	return primitive.NilObjectID, false
+}
+
+// ChatLinkCode is a short-lived code a member sends from chat to link their chat account. Only a
+// hash of the code is stored.
+type ChatLinkCode struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	CodeHash  string             `bson:"code_hash" json:"-"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"` // Expired codes are removed by a TTL index
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateChatLinkCode creates a code for the member and returns it with the code to send
+func CreateChatLinkCode(userID, groupID primitive.ObjectID, now time.Time) (*ChatLinkCode, string, error) {
+	secret := make([]byte, 5)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	code := strings.ToUpper(hex.EncodeToString(secret))
+	return &ChatLinkCode{
+		CodeHash:  HashChatLinkCode(code),
+		UserID:    userID,
+		GroupID:   groupID,
+		ExpiresAt: now.Add(ChatLinkCodeTTL),
+		CreatedAt: now,
+	}, code, nil
+}
+
+// HashChatLinkCode returns the hash link codes are looked up by, ignoring case and spaces
+func HashChatLinkCode(code string) string {
+	normalized := strings.ToUpper(strings.Join(strings.Fields(code), ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,113 @@
+// models/chat_message.go
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationTypeChatMention tells a member they were mentioned in the group chat
+const NotificationTypeChatMention NotificationType = "chat_mention"
+
+// TypingThrottle is how often a member typing in the chat is recorded; clients show the indicator
+// for a little longer than this after the last event
+const TypingThrottle = 3 * time.Second
+
+// mentionPattern matches @mentions. Usernames are email addresses, so a mention may carry a domain.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])@([\p{L}\p{N}_.%+-]+(?:@[\p{L}\p{N}_.-]+)?)`)
+
+// ChatMessage is a message in the group's chat room
+type ChatMessage struct {
+	ID         primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID   `bson:"group_id" json:"group_id" validate:"required"`
+	SenderID   primitive.ObjectID   `bson:"sender_id" json:"sender_id" validate:"required"`
+	Body       string               `bson:"body" json:"body" validate:"required"`
+	MentionIDs []primitive.ObjectID `bson:"mention_ids,omitempty" json:"mention_ids,omitempty"` // Members @mentioned in the body
+	CreatedAt  time.Time            `bson:"created_at" json:"created_at"`
+}
+
+// ChatTyping records that a member is typing in the group chat. Documents expire shortly after the
+// member stops, and each change is streamed to the group as a typing indicator.
+type ChatTyping struct {
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name"`
+	TypingAt  time.Time          `bson:"typing_at" json:"typing_at"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"-"`
+}
+
+// CreateChatMessage creates a message from the member to the group's chat
+func CreateChatMessage(groupID, senderID primitive.ObjectID, body string, mentionIDs []primitive.ObjectID) *ChatMessage {
+	return &ChatMessage{
+		GroupID:    groupID,
+		SenderID:   senderID,
+		Body:       strings.TrimSpace(body),
+		MentionIDs: mentionIDs,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// ParseMentions returns the names @mentioned in the body, lowercased and without repeats
+func ParseMentions(body string) []string {
+	names := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mentionNames returns the names a member can be @mentioned by: their username, the part of it
+// before the @ for email usernames and their first name
+func mentionNames(user User) []string {
+	username := strings.ToLower(user.Username)
+	names := []string{username}
+	if at := strings.Index(username, "@"); at > 0 {
+		names = append(names, username[:at])
+	}
+	if first := strings.Fields(strings.ToLower(user.Name)); len(first) > 0 {
+		names = append(names, first[0])
+	}
+	return names
+}
+
+// MentionedMembers returns the members @mentioned in the body, leaving out the sender. A name
+// shared by several members mentions all of them.
+func MentionedMembers(body string, members []User, senderID primitive.ObjectID) []primitive.ObjectID {
+	mentioned := make(map[string]bool)
+	for _, name := range ParseMentions(body) {
+		mentioned[name] = true
+	}
+	ids := make([]primitive.ObjectID, 0)
+	if len(mentioned) == 0 {
+		return ids
+	}
+	for _, member := range members {
+		if member.ID == senderID {
+			continue
+		}
+		for _, name := range mentionNames(member) {
+			if mentioned[name] {
+				ids = append(ids, member.ID)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// ChatMentionNotification tells a member they were mentioned in the chat, quoting the message
+func ChatMentionNotification(message *ChatMessage, userID primitive.ObjectID, senderName string) *Notification {
+	params := NotificationParams{
+		"name":    senderName,
+		"message": MessagePreview(message.Body),
+	}
+	return CreateTemplatedNotification(userID, message.GroupID, NotificationTypeChatMention, TemplateChatMention, params)
+}
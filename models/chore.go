@@ -1,6 +1,9 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -23,6 +26,26 @@ const (
 	ChoreStatusOverdue   ChoreStatus = "overdue"
 )
 
+// CatchUpPolicy controls what the scheduler does with assignment windows missed while the server was down
+type CatchUpPolicy string
+
+const (
+	CatchUpPolicyBackfill    CatchUpPolicy = "backfill"     // Create an instance for every missed window
+	CatchUpPolicySkipForward CatchUpPolicy = "skip_forward" // Create only the latest instance and resume the schedule
+)
+
+// EditScope controls which generated instances an edit to a recurring chore applies to
+type EditScope string
+
+const (
+	EditScopeThis   EditScope = "this"   // Only the selected instance
+	EditScopeFuture EditScope = "future" // The template and instances from the selected one onwards
+	EditScopeAll    EditScope = "all"    // The template and every instance not yet completed
+)
+
+// SingleOccurrenceFields are the fields an edit scoped to one instance may change; the rest belong to the template
+var SingleOccurrenceFields = []string{"title", "description", "points"}
+
 // Chore represents a task that needs to be completed
 type Chore struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -36,24 +59,32 @@ type Chore struct {
 	StartDate   time.Time          `bson:"start_date" json:"start_date"`
 	DueDate     time.Time          `bson:"due_date,omitempty" json:"due_date,omitempty"`
 	RecurringID primitive.ObjectID `bson:"recurring_id,omitempty" json:"recurring_id,omitempty"`
+	EscalatedAt *time.Time         `bson:"escalated_at,omitempty" json:"escalated_at,omitempty"` // When the assignee and admins were told it stayed overdue
 	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // RecurringChore represents a template for chores that rotate among group members
 type RecurringChore struct {
-	ID             primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
-	Title          string               `bson:"title" json:"title" validate:"required"`
-	Description    string               `bson:"description" json:"description"`
-	GroupID        primitive.ObjectID   `bson:"group_id" json:"group_id" validate:"required"`
-	MemberRotation []primitive.ObjectID `bson:"member_rotation" json:"member_rotation"` // Order of members for rotation
-	CurrentIndex   int                  `bson:"current_index" json:"current_index"`     // Current position in rotation
-	Frequency      string               `bson:"frequency" json:"frequency"`             // daily, weekly, etc.
-	Points         int                  `bson:"points" json:"points" validate:"required,min=1"`
-	NextAssignment time.Time            `bson:"next_assignment" json:"next_assignment"` // When the next chore should be assigned
-	IsActive       bool                 `bson:"is_active" json:"is_active"`
-	CreatedAt      time.Time            `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time            `bson:"updated_at" json:"updated_at"`
+	ID              primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Title           string               `bson:"title" json:"title" validate:"required"`
+	Description     string               `bson:"description" json:"description"`
+	GroupID         primitive.ObjectID   `bson:"group_id" json:"group_id" validate:"required"`
+	MemberRotation  []primitive.ObjectID `bson:"member_rotation" json:"member_rotation"` // Order of members for rotation
+	CurrentIndex    int                  `bson:"current_index" json:"current_index"`     // Current position in rotation
+	Frequency       string               `bson:"frequency" json:"frequency"`             // daily, weekly, etc.
+	Points          int                  `bson:"points" json:"points" validate:"required,min=1"`
+	NextAssignment  time.Time            `bson:"next_assignment" json:"next_assignment"` // When the next chore should be assigned
+	IsActive        bool                 `bson:"is_active" json:"is_active"`
+	UntilDate       *time.Time           `bson:"until_date,omitempty" json:"until_date,omitempty"`           // No instances are scheduled after this date
+	MaxOccurrences  int                  `bson:"max_occurrences,omitempty" json:"max_occurrences,omitempty"` // 0 means unlimited
+	OccurrenceCount int                  `bson:"occurrence_count" json:"occurrence_count"`                   // Instances created so far
+	CreatedBy       primitive.ObjectID   `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	BlackoutPolicy  BlackoutPolicy       `bson:"blackout_policy,omitempty" json:"blackout_policy,omitempty"`   // push (default) or skip
+	CatchUpPolicy   CatchUpPolicy        `bson:"catch_up_policy,omitempty" json:"catch_up_policy,omitempty"`   // Overrides the scheduler default
+	ExcludedMembers []primitive.ObjectID `bson:"excluded_members,omitempty" json:"excluded_members,omitempty"` // Members in the rotation who are never assigned
+	CreatedAt       time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time            `bson:"updated_at" json:"updated_at"`
 }
 
 // ChoreCompletion represents a record of a completed chore
@@ -107,37 +138,255 @@ func CreateRecurringChore(title, description string, groupID primitive.ObjectID,
 	}
 }
 
-// GetNextAssignee returns the next user ID in the rotation
+// NextOccurrence returns the time one frequency interval after t, defaulting to weekly
+func NextOccurrence(frequency string, t time.Time) time.Time {
+	switch frequency {
+	case "daily":
+		return t.AddDate(0, 0, 1)
+	case "weekly":
+		return t.AddDate(0, 0, 7)
+	case "biweekly":
+		return t.AddDate(0, 0, 14)
+	case "monthly":
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 7)
+	}
+}
+
+// scheduledDueDate returns the due date of an instance assigned at t by the scheduler.
+// Daily chores are due at the end of the same day, others at the end of the day one interval later.
+func scheduledDueDate(frequency string, t time.Time) time.Time {
+	switch frequency {
+	case "daily":
+		return endOfDayUTC(t)
+	case "weekly", "biweekly", "monthly":
+		return endOfDayUTC(NextOccurrence(frequency, t))
+	default:
+		return endOfDayUTC(t)
+	}
+}
+
+// HasEnded reports whether the recurring chore has finished its run,
+// either by reaching its occurrence limit or because next falls after its until date
+func (rc *RecurringChore) HasEnded(next time.Time) bool {
+	if rc.MaxOccurrences > 0 && rc.OccurrenceCount >= rc.MaxOccurrences {
+		return true
+	}
+	if rc.UntilDate != nil && next.After(*rc.UntilDate) {
+		return true
+	}
+	return false
+}
+
+// IsExcluded reports whether a member is excluded from this recurring chore
+func (rc *RecurringChore) IsExcluded(userID primitive.ObjectID) bool {
+	for _, id := range rc.ExcludedMembers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// EligibleMembers returns the members of the rotation who can be assigned, in rotation order
+func (rc *RecurringChore) EligibleMembers() []primitive.ObjectID {
+	eligible := make([]primitive.ObjectID, 0, len(rc.MemberRotation))
+	for _, id := range rc.MemberRotation {
+		if !rc.IsExcluded(id) {
+			eligible = append(eligible, id)
+		}
+	}
+	return eligible
+}
+
+// GetNextAssignee returns the next user ID in the rotation, skipping excluded members
 func (rc *RecurringChore) GetNextAssignee() primitive.ObjectID {
 	if len(rc.MemberRotation) == 0 {
 		return primitive.NilObjectID
 	}
 
-	assignee := rc.MemberRotation[rc.CurrentIndex]
-	rc.CurrentIndex = (rc.CurrentIndex + 1) % len(rc.MemberRotation)
-	return assignee
+	if rc.CurrentIndex < 0 || rc.CurrentIndex >= len(rc.MemberRotation) {
+		rc.CurrentIndex = 0
+	}
+
+	for range rc.MemberRotation {
+		assignee := rc.MemberRotation[rc.CurrentIndex]
+		rc.CurrentIndex = (rc.CurrentIndex + 1) % len(rc.MemberRotation)
+		if !rc.IsExcluded(assignee) {
+			return assignee
+		}
+	}
+
+	// Everyone in the rotation is excluded
+	return primitive.NilObjectID
+}
+
+// MemberFairness summarises how often a member has been given a recurring chore compared to their fair share
+type MemberFairness struct {
+	UserID        primitive.ObjectID `json:"user_id"`
+	Excluded      bool               `json:"excluded"`
+	Assigned      int                `json:"assigned"`
+	Completed     int                `json:"completed"`
+	ExpectedShare float64            `json:"expected_share"` // Assignments this member should have had so far
+	Deviation     float64            `json:"deviation"`      // Assigned minus expected share
+}
+
+// RotationFairness compares each rotation member's assignments with an even split among eligible members.
+// Excluded members are expected to have no assignments.
+func (rc *RecurringChore) RotationFairness(assigned, completed map[primitive.ObjectID]int) []MemberFairness {
+	total := 0
+	for _, count := range assigned {
+		total += count
+	}
+
+	eligibleCount := len(rc.EligibleMembers())
+	stats := make([]MemberFairness, 0, len(rc.MemberRotation))
+	for _, id := range rc.MemberRotation {
+		excluded := rc.IsExcluded(id)
+		expected := 0.0
+		if !excluded && eligibleCount > 0 {
+			expected = float64(total) / float64(eligibleCount)
+		}
+		stats = append(stats, MemberFairness{
+			UserID:        id,
+			Excluded:      excluded,
+			Assigned:      assigned[id],
+			Completed:     completed[id],
+			ExpectedShare: expected,
+			Deviation:     float64(assigned[id]) - expected,
+		})
+	}
+	return stats
+}
+
+// ValidateRotationOrder checks that a rotation is non-empty, has no duplicates and only contains group members
+func ValidateRotationOrder(order []primitive.ObjectID, members []primitive.ObjectID) error {
+	if len(order) == 0 {
+		return errors.New("rotation must contain at least one member")
+	}
+
+	memberSet := make(map[primitive.ObjectID]bool, len(members))
+	for _, id := range members {
+		memberSet[id] = true
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(order))
+	for _, id := range order {
+		if !memberSet[id] {
+			return errors.New("rotation contains a user who is not a member of the group")
+		}
+		if seen[id] {
+			return errors.New("rotation contains duplicate members")
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// SetRotationOrder replaces the rotation while keeping the upcoming assignee next in line.
+// If the upcoming assignee is no longer part of the rotation, it restarts from the beginning.
+func (rc *RecurringChore) SetRotationOrder(order []primitive.ObjectID) {
+	var upcoming primitive.ObjectID
+	if rc.CurrentIndex >= 0 && rc.CurrentIndex < len(rc.MemberRotation) {
+		upcoming = rc.MemberRotation[rc.CurrentIndex]
+	}
+
+	rc.MemberRotation = order
+	rc.CurrentIndex = 0
+	for i, id := range order {
+		if id == upcoming {
+			rc.CurrentIndex = i
+			break
+		}
+	}
+}
+
+// ValidateEditScope checks the propagation options of an edit to a recurring chore that changes fields
+func ValidateEditScope(scope EditScope, regenerate bool, fields []string) error {
+	switch scope {
+	case "", EditScopeThis, EditScopeFuture, EditScopeAll:
+	default:
+		return errors.New("invalid scope, must be this, future or all")
+	}
+
+	if regenerate && scope != EditScopeFuture && scope != EditScopeAll {
+		return errors.New("regenerate is only supported with future or all scope")
+	}
+
+	if scope == EditScopeThis {
+		for _, field := range fields {
+			if !slices.Contains(SingleOccurrenceFields, field) {
+				return fmt.Errorf("%s cannot be changed on a single occurrence", field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// RegenerateInstances builds one instance from the template for each replaced instance, due when the
+// replaced one was. replaced must be sorted by due date. Unless the rotation was reset, assignment restarts
+// with whoever had the first replaced instance, so regenerating skips nobody. Instances the run no longer
+// reaches, by its until date or occurrence limit, are not rebuilt.
+func (rc *RecurringChore) RegenerateInstances(replaced []Chore, rotationReset bool) []*Chore {
+	// Replaced instances no longer count towards the occurrence limit
+	rc.OccurrenceCount -= len(replaced)
+	if rc.OccurrenceCount < 0 {
+		rc.OccurrenceCount = 0
+	}
+
+	if !rc.IsActive || len(rc.MemberRotation) == 0 || len(replaced) == 0 {
+		return nil
+	}
+
+	if !rotationReset {
+		if i := slices.Index(rc.MemberRotation, replaced[0].AssignedTo); i >= 0 {
+			rc.CurrentIndex = i
+		}
+	}
+
+	instances := make([]*Chore, 0, len(replaced))
+	for _, chore := range replaced {
+		if rc.HasEnded(chore.DueDate) {
+			break
+		}
+		instances = append(instances, CreateChoreFromRecurringWithBaseDate(rc, chore.DueDate))
+		rc.OccurrenceCount++
+	}
+	return instances
+}
+
+// MissedWindows returns every assignment time from next up to and including now, one frequency interval apart.
+// The scheduler normally sees a single window; more means assignments were missed.
+func MissedWindows(next time.Time, frequency string, now time.Time) []time.Time {
+	if next.IsZero() {
+		next = now
+	}
+
+	windows := make([]time.Time, 0, 1)
+	// Guard against unset dates producing an unbounded number of windows
+	for t := next; !t.After(now) && len(windows) < 10000; t = NextOccurrence(frequency, t) {
+		windows = append(windows, t)
+	}
+	if len(windows) == 0 {
+		windows = append(windows, now)
+	}
+	return windows
 }
 
 // CreateChoreFromRecurring creates a new chore instance from a recurring chore
 func CreateChoreFromRecurring(recurringChore *RecurringChore) *Chore {
+	return CreateChoreFromRecurringAt(recurringChore, time.Now())
+}
+
+// CreateChoreFromRecurringAt creates the chore instance for an assignment window starting at assignAt
+func CreateChoreFromRecurringAt(recurringChore *RecurringChore, assignAt time.Time) *Chore {
 	// Get the next assignee
 	assignedTo := recurringChore.GetNextAssignee()
 
-	// Calculate due date based on frequency
-	now := time.Now()
-	switch recurringChore.Frequency {
-	case "daily":
-		// Due at the end of the current day
-		now = now
-	case "weekly":
-		now = now.AddDate(0, 0, 7)
-	case "biweekly":
-		now = now.AddDate(0, 0, 14)
-	case "monthly":
-		now = now.AddDate(0, 1, 0)
-	}
-
-	dueDate := endOfDayUTC(now)
+	dueDate := scheduledDueDate(recurringChore.Frequency, assignAt)
 
 	return &Chore{
 		Title:       recurringChore.Title,
@@ -181,3 +430,80 @@ func CreateChoreFromRecurringWithBaseDate(recurringChore *RecurringChore, baseDa
 		UpdatedAt:   time.Now(),
 	}
 }
+
+// UpcomingOccurrence is a projected instance of a recurring chore
+type UpcomingOccurrence struct {
+	AssignAt   time.Time          `json:"assign_at"`
+	DueDate    time.Time          `json:"due_date"`
+	AssignedTo primitive.ObjectID `json:"assigned_to"`
+}
+
+// PreviewOccurrences computes the next count occurrences of the recurring chore the way the
+// scheduler would create them, without modifying the chore itself
+func (rc RecurringChore) PreviewOccurrences(count int, from time.Time, blackouts []BlackoutDate) []UpcomingOccurrence {
+	occurrences := make([]UpcomingOccurrence, 0, count)
+	if !rc.IsActive || len(rc.EligibleMembers()) == 0 || count <= 0 {
+		return occurrences
+	}
+
+	// Work on a copy of the rotation so the caller's chore is untouched
+	rc.MemberRotation = append([]primitive.ObjectID(nil), rc.MemberRotation...)
+	if rc.CurrentIndex < 0 || rc.CurrentIndex >= len(rc.MemberRotation) {
+		rc.CurrentIndex = 0
+	}
+
+	// An overdue assignment would be picked up on the scheduler's next run
+	assignAt := rc.NextAssignment
+	if assignAt.Before(from) {
+		assignAt = from
+	}
+
+	// Bound the projection so a long run of skipped occurrences cannot loop forever
+	for attempts := 0; len(occurrences) < count && attempts < count*10; attempts++ {
+		if rc.HasEnded(assignAt) {
+			break
+		}
+
+		dueDate, ok := ApplyBlackouts(scheduledDueDate(rc.Frequency, assignAt), rc.BlackoutPolicy, blackouts)
+		if ok {
+			occurrences = append(occurrences, UpcomingOccurrence{
+				AssignAt:   assignAt,
+				DueDate:    dueDate,
+				AssignedTo: rc.GetNextAssignee(),
+			})
+			rc.OccurrenceCount++
+		}
+
+		assignAt = NextOccurrence(rc.Frequency, assignAt)
+	}
+
+	return occurrences
+}
+
+// ChoreAssignedNotification tells the member a chore instance is assigned to that it is theirs
+func ChoreAssignedNotification(chore *Chore) *Notification {
+	params := NotificationParams{"chore": chore.Title}
+	if !chore.DueDate.IsZero() {
+		params["due"] = NotificationDate(chore.DueDate)
+	}
+	return CreateTemplatedNotification(chore.AssignedTo, chore.GroupID, NotificationTypeChoreAssigned, TemplateChoreAssigned, params)
+}
+
+// ChoreEscalationDelay is how long a chore can stay overdue before its assignee and the group's
+// admins are emailed, and the assignee texted, about it
+const ChoreEscalationDelay = 48 * time.Hour
+
+// DaysOverdue counts the whole days since the chore was due
+func (c Chore) DaysOverdue(now time.Time) int {
+	if c.DueDate.IsZero() || !now.After(c.DueDate) {
+		return 0
+	}
+	return int(now.Sub(c.DueDate) / (24 * time.Hour))
+}
+
+// NeedsEscalation reports whether the chore has been overdue for ChoreEscalationDelay without
+// anyone being told about it yet
+func (c Chore) NeedsEscalation(now time.Time) bool {
+	return c.Status == ChoreStatusOverdue && c.EscalatedAt == nil && !c.DueDate.IsZero() &&
+		!now.Before(c.DueDate.Add(ChoreEscalationDelay))
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CostShare is the part of a cost that one member is responsible for
+type CostShare struct {
+	UserID primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Amount float64            `bson:"amount" json:"amount"`
+}
+
+// SplitEvenly divides amount between members in whole cents. Leftover cents go to the first
+// members so the shares always add up to the amount exactly.
+func SplitEvenly(amount float64, members []primitive.ObjectID) []CostShare {
+	if len(members) == 0 {
+		return []CostShare{}
+	}
+
+	cents := int64(math.Round(amount * 100))
+	base := cents / int64(len(members))
+	remainder := cents % int64(len(members))
+
+	shares := make([]CostShare, 0, len(members))
+	for i, member := range members {
+		share := base
+		if int64(i) < remainder {
+			share++
+		}
+		shares = append(shares, CostShare{UserID: member, Amount: float64(share) / 100})
+	}
+	return shares
+}
+
+// SplitByPercentage divides amount between members by their percentages, which must add up to
+// 100. Shares are in whole cents; leftover cents go to the members whose shares were rounded
+// down the most so the shares always add up to the amount exactly.
+func SplitByPercentage(amount float64, members []primitive.ObjectID, percentages []float64) ([]CostShare, error) {
+	if len(members) == 0 || len(members) != len(percentages) {
+		return nil, errors.New("every participant needs a percentage")
+	}
+
+	total := 0.0
+	for _, percentage := range percentages {
+		if percentage < 0 {
+			return nil, errors.New("percentages cannot be negative")
+		}
+		total += percentage
+	}
+	if math.Abs(total-100) > 0.01 {
+		return nil, errors.New("percentages must add up to 100")
+	}
+
+	cents := int64(math.Round(amount * 100))
+	shares := make([]CostShare, len(members))
+	fractions := make([]float64, len(members))
+	assigned := int64(0)
+	for i, member := range members {
+		exact := float64(cents) * percentages[i] / total
+		share := int64(math.Floor(exact))
+		shares[i] = CostShare{UserID: member, Amount: float64(share) / 100}
+		fractions[i] = exact - float64(share)
+		assigned += share
+	}
+
+	order := make([]int, len(members))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return fractions[order[a]] > fractions[order[b]] })
+	for i := int64(0); i < cents-assigned; i++ {
+		share := &shares[order[i%int64(len(order))]]
+		share.Amount = math.Round(share.Amount*100+1) / 100
+	}
+	return shares, nil
+}
+
+// CheckExactSplit verifies that shares entered by hand are not negative and add up to the amount to the cent
+func CheckExactSplit(amount float64, shares []CostShare) error {
+	if len(shares) == 0 {
+		return errors.New("at least one participant is required")
+	}
+
+	cents := int64(0)
+	for _, share := range shares {
+		if share.Amount < 0 {
+			return errors.New("shares cannot be negative")
+		}
+		cents += int64(math.Round(share.Amount * 100))
+	}
+	if cents != int64(math.Round(amount*100)) {
+		return errors.New("shares must add up to the expense amount")
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+// models/decision_poll.go
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// NotificationTypeDecisionCreated asks members to vote on a question
+	NotificationTypeDecisionCreated NotificationType = "decision_created"
+
+	// NotificationTypeDecisionClosed tells members how a vote came out
+	NotificationTypeDecisionClosed NotificationType = "decision_closed"
+)
+
+// Limits on decision polls
+const (
+	MinDecisionOptions        = 2
+	MaxDecisionOptions        = 10
+	MaxDecisionQuestionLength = 200
+	MaxDecisionOptionLength   = 100
+	MaxDecisionVotingPeriod   = 30 * 24 * time.Hour
+)
+
+// DecisionOption is one of the answers members can vote for
+type DecisionOption struct {
+	ID   primitive.ObjectID `bson:"id" json:"id"`
+	Text string             `bson:"text" json:"text"`
+}
+
+// DecisionBallot is the options a member voted for. Ballots are kept even in anonymous polls so
+// members can change their vote, but are never sent to clients.
+type DecisionBallot struct {
+	UserID    primitive.ObjectID   `bson:"user_id" json:"user_id"`
+	OptionIDs []primitive.ObjectID `bson:"option_ids" json:"option_ids"`
+	VotedAt   time.Time            `bson:"voted_at" json:"voted_at"`
+}
+
+// DecisionResult is the votes an option has received
+type DecisionResult struct {
+	OptionID primitive.ObjectID   `json:"option_id"`
+	Text     string               `json:"text"`
+	Votes    int                  `json:"votes"`
+	VoterIDs []primitive.ObjectID `json:"voter_ids,omitempty"` // Left out of anonymous polls
+}
+
+// DecisionPoll asks the group a question with a few answers, for decisions like the thermostat
+// temperature. Members pick one answer, or several in multiple choice polls, until the deadline,
+// when the answers with the most votes win.
+type DecisionPoll struct {
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	GroupID          primitive.ObjectID   `bson:"group_id" json:"group_id" validate:"required"`
+	Question         string               `bson:"question" json:"question" validate:"required"`
+	Options          []DecisionOption     `bson:"options" json:"options"`
+	MultipleChoice   bool                 `bson:"multiple_choice" json:"multiple_choice"`
+	Anonymous        bool                 `bson:"anonymous" json:"anonymous"` // Results leave out who voted for what
+	Ballots          []DecisionBallot     `bson:"ballots" json:"-"`
+	Status           PollStatus           `bson:"status" json:"status"` // open or closed
+	Deadline         time.Time            `bson:"deadline" json:"deadline"`
+	WinningOptionIDs []primitive.ObjectID `bson:"winning_option_ids,omitempty" json:"winning_option_ids,omitempty"` // Several on a tie
+	ClosedAt         *time.Time           `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	CreatedBy        primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt        time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// ValidateDecisionPoll checks the question, that there are enough distinct options and that the
+// deadline is in the future but not too far off
+func ValidateDecisionPoll(question string, options []string, deadline, now time.Time) error {
+	question = strings.TrimSpace(question)
+	switch {
+	case question == "":
+		return errors.New("question is required")
+	case utf8.RuneCountInString(question) > MaxDecisionQuestionLength:
+		return fmt.Errorf("questions can be at most %d characters", MaxDecisionQuestionLength)
+	case len(options) < MinDecisionOptions || len(options) > MaxDecisionOptions:
+		return fmt.Errorf("a poll needs between %d and %d options", MinDecisionOptions, MaxDecisionOptions)
+	case !deadline.After(now):
+		return errors.New("deadline must be in the future")
+	case deadline.Sub(now) > MaxDecisionVotingPeriod:
+		return errors.New("deadline can be at most 30 days away")
+	}
+
+	seen := make(map[string]bool, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		switch {
+		case option == "":
+			return errors.New("options cannot be blank")
+		case utf8.RuneCountInString(option) > MaxDecisionOptionLength:
+			return fmt.Errorf("options can be at most %d characters", MaxDecisionOptionLength)
+		case seen[strings.ToLower(option)]:
+			return fmt.Errorf("option %q is listed twice", option)
+		}
+		seen[strings.ToLower(option)] = true
+	}
+	return nil
+}
+
+// CreateDecisionPoll creates an open poll with the options in the order given
+func CreateDecisionPoll(groupID, createdBy primitive.ObjectID, question string, options []string, multipleChoice, anonymous bool, deadline time.Time) *DecisionPoll {
+	now := time.Now()
+	answers := make([]DecisionOption, len(options))
+	for i, option := range options {
+		answers[i] = DecisionOption{ID: primitive.NewObjectID(), Text: strings.TrimSpace(option)}
+	}
+	return &DecisionPoll{
+		GroupID:        groupID,
+		Question:       strings.TrimSpace(question),
+		Options:        answers,
+		MultipleChoice: multipleChoice,
+		Anonymous:      anonymous,
+		Ballots:        []DecisionBallot{},
+		Status:         PollOpen,
+		Deadline:       deadline,
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Option returns the poll's option with the ID
+func (p *DecisionPoll) Option(optionID primitive.ObjectID) (DecisionOption, bool) {
+	for _, option := range p.Options {
+		if option.ID == optionID {
+			return option, true
+		}
+	}
+	return DecisionOption{}, false
+}
+
+// Ballot returns the member's ballot, if they have voted
+func (p *DecisionPoll) Ballot(userID primitive.ObjectID) (DecisionBallot, bool) {
+	for _, ballot := range p.Ballots {
+		if ballot.UserID == userID {
+			return ballot, true
+		}
+	}
+	return DecisionBallot{}, false
+}
+
+// SetBallot records the options the member votes for, replacing an earlier vote. Single choice
+// polls take exactly one option and multiple choice polls at least one.
+func (p *DecisionPoll) SetBallot(userID primitive.ObjectID, optionIDs []primitive.ObjectID, now time.Time) error {
+	chosen := make([]primitive.ObjectID, 0, len(optionIDs))
+	for _, optionID := range optionIDs {
+		if _, ok := p.Option(optionID); !ok {
+			return fmt.Errorf("option %s is not in the poll", optionID.Hex())
+		}
+		duplicate := false
+		for _, id := range chosen {
+			duplicate = duplicate || id == optionID
+		}
+		if !duplicate {
+			chosen = append(chosen, optionID)
+		}
+	}
+	switch {
+	case len(chosen) == 0:
+		return errors.New("pick at least one option")
+	case len(chosen) > 1 && !p.MultipleChoice:
+		return errors.New("this poll takes a single option")
+	}
+
+	for i := range p.Ballots {
+		if p.Ballots[i].UserID == userID {
+			p.Ballots[i].OptionIDs = chosen
+			p.Ballots[i].VotedAt = now
+			return nil
+		}
+	}
+	p.Ballots = append(p.Ballots, DecisionBallot{UserID: userID, OptionIDs: chosen, VotedAt: now})
+	return nil
+}
+
+// AllVoted reports whether every member has voted
+func (p *DecisionPoll) AllVoted(members []primitive.ObjectID) bool {
+	for _, memberID := range members {
+		if _, ok := p.Ballot(memberID); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Tally counts the votes for each option, in the poll's order. Voters are only listed when the
+// poll is not anonymous.
+func (p *DecisionPoll) Tally() []DecisionResult {
+	results := make([]DecisionResult, len(p.Options))
+	for i, option := range p.Options {
+		results[i] = DecisionResult{OptionID: option.ID, Text: option.Text}
+		for _, ballot := range p.Ballots {
+			for _, id := range ballot.OptionIDs {
+				if id != option.ID {
+					continue
+				}
+				results[i].Votes++
+				if !p.Anonymous {
+					results[i].VoterIDs = append(results[i].VoterIDs, ballot.UserID)
+				}
+			}
+		}
+	}
+	return results
+}
+
+// Close ends voting. The options with the most votes win, all of them on a tie; with no votes the
+// poll closes without a winner.
+func (p *DecisionPoll) Close(now time.Time) {
+	most := 0
+	for _, result := range p.Tally() {
+		if result.Votes > most {
+			most = result.Votes
+		}
+	}
+	p.WinningOptionIDs = nil
+	for _, result := range p.Tally() {
+		if most > 0 && result.Votes == most {
+			p.WinningOptionIDs = append(p.WinningOptionIDs, result.OptionID)
+		}
+	}
+	p.Status = PollClosed
+	p.ClosedAt = &now
+	p.UpdatedAt = now
+}
+
+// DecisionCreatedNotification asks a member to vote on the poll
+func DecisionCreatedNotification(poll *DecisionPoll, userID primitive.ObjectID, creatorName string) *Notification {
+	params := NotificationParams{
+		"name":     creatorName,
+		"question": poll.Question,
+		"deadline": NotificationDate(poll.Deadline),
+	}
+	return CreateTemplatedNotification(userID, poll.GroupID, NotificationTypeDecisionCreated, TemplateDecisionCreated, params)
+}
+
+// DecisionClosedNotification tells a member which option won, which tied or that nobody voted
+func DecisionClosedNotification(poll *DecisionPoll, userID primitive.ObjectID) *Notification {
+	params := NotificationParams{"question": poll.Question}
+	winners := make([]string, 0, len(poll.WinningOptionIDs))
+	votes := 0
+	for _, result := range poll.Tally() {
+		for _, id := range poll.WinningOptionIDs {
+			if id == result.OptionID {
+				winners = append(winners, result.Text)
+				votes = result.Votes
+			}
+		}
+	}
+	if len(winners) > 0 {
+		params["winner"] = strings.Join(winners, ", ")
+		params["votes"] = strconv.Itoa(votes)
+	}
+	if len(winners) > 1 {
+		params["tied"] = "true"
+	}
+	return CreateTemplatedNotification(userID, poll.GroupID, NotificationTypeDecisionClosed, TemplateDecisionClosed, params)
+}
+
+// CloseDecisionPoll closes an open decision poll now, tallies it and tells the group how it came
+// out. Votes bump updated_at, so a poll that changed since it was read is read again and closed as
+// it is now. It returns the poll as it ended up and whether this call closed it; a poll that was
+// already closed is returned as is.
+func CloseDecisionPoll(ctx context.Context, db *mongo.Database, poll DecisionPoll) (DecisionPoll, bool, error) {
+	polls := db.Collection("decision_polls")
+	for poll.Status == PollOpen {
+		readAt := poll.UpdatedAt
+		closed := poll
+		closed.Close(time.Now())
+		result, err := polls.UpdateOne(ctx,
+			bson.M{"_id": poll.ID, "status": PollOpen, "updated_at": readAt},
+			bson.M{"$set": bson.M{
+				"status":             closed.Status,
+				"winning_option_ids": closed.WinningOptionIDs,
+				"closed_at":          closed.ClosedAt,
+				"updated_at":         closed.UpdatedAt,
+			}},
+		)
+		if err != nil {
+			return poll, false, err
+		}
+		if result.MatchedCount == 0 {
+			// Someone voted or closed the poll since it was read
+			if err := polls.FindOne(ctx, bson.M{"_id": poll.ID}).Decode(&poll); err != nil {
+				return poll, false, err
+			}
+			continue
+		}
+
+		var group Group
+		if err := db.Collection("groups").FindOne(ctx, bson.M{"_id": closed.GroupID}).Decode(&group); err != nil {
+			return closed, true, err
+		}
+		documents := make([]interface{}, 0, len(group.Members))
+		for _, memberID := range group.Members {
+			documents = append(documents, DecisionClosedNotification(&closed, memberID))
+		}
+		if len(documents) > 0 {
+			if _, err := db.Collection("notifications").InsertMany(ctx, documents); err != nil {
+				return closed, true, err
+			}
+		}
+		return closed, true, nil
+	}
+	return poll, false, nil
+}
@@ -0,0 +1,35 @@
+// models/device_token.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DevicePlatform is the kind of device a push token was issued to
+type DevicePlatform string
+
+const (
+	DevicePlatformAndroid DevicePlatform = "android"
+	DevicePlatformIOS     DevicePlatform = "ios"
+	DevicePlatformWeb     DevicePlatform = "web"
+)
+
+// IsValidDevicePlatform checks if push tokens can be registered for the platform
+func IsValidDevicePlatform(platform DevicePlatform) bool {
+	return platform == DevicePlatformAndroid || platform == DevicePlatformIOS || platform == DevicePlatformWeb
+}
+
+// DeviceToken is a push token of one of a member's devices: an FCM registration token, or the APNs
+// device token of an iOS device when the server delivers to iOS through APNs. A token belongs to
+// the member who registered it last, so a shared device follows whoever is signed in.
+type DeviceToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Token      string             `bson:"token" json:"token"`
+	Platform   DevicePlatform     `bson:"platform" json:"platform"`
+	DeviceName string             `bson:"device_name,omitempty" json:"device_name,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"` // When the app last registered the token
+}
@@ -0,0 +1,128 @@
+// models/direct_message.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Limits on direct messages
+const (
+	MaxMessageLength       = 2000
+	MessagePreviewLength   = 100
+	DefaultMessagePageSize = 50
+	MaxMessagePageSize     = 200
+)
+
+// ConversationParticipant is a member of a conversation and how much of it they have read
+type ConversationParticipant struct {
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Unread     int                `bson:"unread" json:"unread"` // Messages from the other member since last read
+	LastReadAt *time.Time         `bson:"last_read_at,omitempty" json:"last_read_at,omitempty"`
+}
+
+// Conversation is a thread of direct messages between two members of a group. Each pair of
+// members has one conversation per group, found by its key.
+type Conversation struct {
+	ID            primitive.ObjectID        `bson:"_id,omitempty" json:"id"`
+	GroupID       primitive.ObjectID        `bson:"group_id" json:"group_id" validate:"required"`
+	Key           string                    `bson:"key" json:"-"`
+	Participants  []ConversationParticipant `bson:"participants" json:"participants"`                     // Ordered by user ID
+	LastMessage   string                    `bson:"last_message,omitempty" json:"last_message,omitempty"` // Preview of the latest message
+	LastSenderID  *primitive.ObjectID       `bson:"last_sender_id,omitempty" json:"last_sender_id,omitempty"`
+	LastMessageAt *time.Time                `bson:"last_message_at,omitempty" json:"last_message_at,omitempty"`
+	CreatedAt     time.Time                 `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time                 `bson:"updated_at" json:"updated_at"`
+}
+
+// DirectMessage is a message sent in a conversation. The group and recipient are copied from the
+// conversation so the message can be streamed to just the two members.
+type DirectMessage struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ConversationID primitive.ObjectID `bson:"conversation_id" json:"conversation_id" validate:"required"`
+	GroupID        primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	SenderID       primitive.ObjectID `bson:"sender_id" json:"sender_id" validate:"required"`
+	RecipientID    primitive.ObjectID `bson:"recipient_id" json:"recipient_id" validate:"required"`
+	Body           string             `bson:"body" json:"body" validate:"required"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ConversationKey identifies the conversation between two members, whichever of them starts it
+func ConversationKey(a, b primitive.ObjectID) string {
+	if b.Hex() < a.Hex() {
+		a, b = b, a
+	}
+	return a.Hex() + ":" + b.Hex()
+}
+
+// CreateConversation creates an empty conversation between two members of the group
+func CreateConversation(groupID, a, b primitive.ObjectID) *Conversation {
+	if b.Hex() < a.Hex() {
+		a, b = b, a
+	}
+	now := time.Now()
+	return &Conversation{
+		GroupID:      groupID,
+		Key:          ConversationKey(a, b),
+		Participants: []ConversationParticipant{{UserID: a}, {UserID: b}},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Participant returns the position of the member in the conversation's participants
+func (c *Conversation) Participant(userID primitive.ObjectID) (int, bool) {
+	for i, participant := range c.Participants {
+		if participant.UserID == userID {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Other returns the position of the participant who is not the member
+func (c *Conversation) Other(userID primitive.ObjectID) int {
+	for i, participant := range c.Participants {
+		if participant.UserID != userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// ValidateMessageBody checks that a message has text and fits the limit
+func ValidateMessageBody(body string) error {
+	body = strings.TrimSpace(body)
+	switch {
+	case body == "":
+		return errors.New("message cannot be empty")
+	case utf8.RuneCountInString(body) > MaxMessageLength:
+		return fmt.Errorf("messages can be at most %d characters", MaxMessageLength)
+	}
+	return nil
+}
+
+// CreateDirectMessage creates a message from the member to the other participant of the conversation
+func CreateDirectMessage(conversation *Conversation, senderID primitive.ObjectID, body string) *DirectMessage {
+	return &DirectMessage{
+		ConversationID: conversation.ID,
+		GroupID:        conversation.GroupID,
+		SenderID:       senderID,
+		RecipientID:    conversation.Participants[conversation.Other(senderID)].UserID,
+		Body:           strings.TrimSpace(body),
+		CreatedAt:      time.Now(),
+	}
+}
+
+// MessagePreview shortens a message for the conversation list
+func MessagePreview(body string) string {
+	if utf8.RuneCountInString(body) <= MessagePreviewLength {
+		return body
+	}
+	return string([]rune(body)[:MessagePreviewLength-1]) + "…"
+}
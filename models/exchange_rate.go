@@ -0,0 +1,52 @@
+// models/exchange_rate.go
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultCurrency is the currency of groups that have not chosen one
+const DefaultCurrency = "USD"
+
+// currencyCodePattern matches ISO 4217 currency codes
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency upper-cases and trims a currency code
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsValidCurrency checks that a normalized currency code is three letters
+func IsValidCurrency(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// ExchangeRates are the rates from a base currency to other currencies, cached in the
+// exchange_rates collection
+type ExchangeRates struct {
+	Base      string             `bson:"base" json:"base"`
+	Rates     map[string]float64 `bson:"rates" json:"rates"` // Units of each currency one unit of the base buys
+	Source    string             `bson:"source" json:"source"`
+	FetchedAt time.Time          `bson:"fetched_at" json:"fetched_at"`
+}
+
+// ConvertedShares rescales shares entered in another currency to the converted amount, keeping
+// their proportions and making them add up to the converted amount to the cent. The shares must
+// add up to more than zero.
+func ConvertedShares(convertedAmount float64, shares []CostShare) ([]CostShare, error) {
+	total := 0.0
+	for _, share := range shares {
+		total += share.Amount
+	}
+	members := make([]primitive.ObjectID, len(shares))
+	percentages := make([]float64, len(shares))
+	for i, share := range shares {
+		members[i] = share.UserID
+		percentages[i] = share.Amount / total * 100
+	}
+	return SplitByPercentage(convertedAmount, members, percentages)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExpenseSource tells how an expense was recorded
+type ExpenseSource string
+
+const (
+	ExpenseSourceManual    ExpenseSource = "manual"    // Entered directly by a member
+	ExpenseSourceShopping  ExpenseSource = "shopping"  // Created when shopping items were marked purchased
+	ExpenseSourceRecurring ExpenseSource = "recurring" // Created by the scheduler from a recurring bill
+	ExpenseSourceSplitwise ExpenseSource = "splitwise" // Imported from a Splitwise group
+)
+
+// SplitMethod tells how an expense was divided between its participants
+type SplitMethod string
+
+const (
+	SplitMethodEqual      SplitMethod = "equal"      // Everyone pays the same
+	SplitMethodPercentage SplitMethod = "percentage" // Each participant pays a percentage of the amount
+	SplitMethodExact      SplitMethod = "exact"      // Each participant's amount is entered by hand
+)
+
+// IsValidSplitMethod checks if the split method is one of the defined values
+func IsValidSplitMethod(method SplitMethod) bool {
+	return method == SplitMethodEqual || method == SplitMethodPercentage || method == SplitMethodExact
+}
+
+// Expense is money one member paid on behalf of others, split into the shares each member owes
+type Expense struct {
+	ID               primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	GroupID          primitive.ObjectID   `bson:"group_id" json:"group_id" validate:"required"`
+	Description      string               `bson:"description" json:"description" validate:"required"`
+	Amount           float64              `bson:"amount" json:"amount" validate:"required,min=0"`
+	Currency         string               `bson:"currency,omitempty" json:"currency,omitempty"`               // The group's currency, which Amount and Splits are in
+	OriginalAmount   float64              `bson:"original_amount,omitempty" json:"original_amount,omitempty"` // Amount as entered, when recorded in another currency
+	OriginalCurrency string               `bson:"original_currency,omitempty" json:"original_currency,omitempty"`
+	ExchangeRate     float64              `bson:"exchange_rate,omitempty" json:"exchange_rate,omitempty"` // Rate used to convert the original amount
+	PaidBy           primitive.ObjectID   `bson:"paid_by" json:"paid_by" validate:"required"`
+	Splits           []CostShare          `bson:"splits" json:"splits"`
+	SplitMethod      SplitMethod          `bson:"split_method,omitempty" json:"split_method,omitempty"` // Empty for shopping expenses, split per item
+	Category         ExpenseCategory      `bson:"category,omitempty" json:"category,omitempty"`         // Empty for expenses recorded before categories, see CategoryOrDefault
+	Source           ExpenseSource        `bson:"source" json:"source"`
+	PurchaseIDs      []primitive.ObjectID `bson:"purchase_ids,omitempty" json:"purchase_ids,omitempty"`           // Purchase history records behind a shopping expense
+	RecurringBillID  *primitive.ObjectID  `bson:"recurring_bill_id,omitempty" json:"recurring_bill_id,omitempty"` // Bill a recurring expense was created from
+	ReceiptID        *primitive.ObjectID  `bson:"receipt_id,omitempty" json:"receipt_id,omitempty"`               // Stored receipt image, see the storage package
+	Dispute          *ExpenseDispute      `bson:"dispute,omitempty" json:"dispute,omitempty"`                     // Latest dispute and the thread of every dispute so far
+	ExternalID       string               `bson:"external_id,omitempty" json:"external_id,omitempty"`             // Where an imported expense came from, e.g. splitwise:123
+	CreatedBy        primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt        time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateExpense creates a new expense paid by paidBy
+func CreateExpense(groupID, paidBy primitive.ObjectID, description string, amount float64, splits []CostShare, source ExpenseSource) *Expense {
+	return &Expense{
+		GroupID:     groupID,
+		Description: description,
+		Amount:      amount,
+		PaidBy:      paidBy,
+		Splits:      splits,
+		Source:      source,
+		Category:    DefaultExpenseCategory(source),
+		CreatedBy:   paidBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// RoundMoney rounds an amount to whole cents
+func RoundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// MergeShares adds up the shares of each member across several splits, keeping the order members first appear in
+func MergeShares(splits ...[]CostShare) []CostShare {
+	merged := make([]CostShare, 0)
+	index := make(map[primitive.ObjectID]int)
+	for _, split := range splits {
+		for _, share := range split {
+			if i, ok := index[share.UserID]; ok {
+				merged[i].Amount += share.Amount
+				continue
+			}
+			index[share.UserID] = len(merged)
+			merged = append(merged, share)
+		}
+	}
+
+	// Keep whole cents after summing floating point amounts
+	for i := range merged {
+		merged[i].Amount = RoundMoney(merged[i].Amount)
+	}
+	return merged
+}
+
+// MemberBalance is where a member stands across the group's expenses and settlements
+type MemberBalance struct {
+	UserID   primitive.ObjectID `json:"user_id"`
+	Name     string             `json:"name"`
+	Paid     float64            `json:"paid"`     // Total the member paid for others and themselves
+	Share    float64            `json:"share"`    // Total of the member's shares
+	Sent     float64            `json:"sent"`     // Repayments the member made
+	Received float64            `json:"received"` // Repayments the member got
+	Net      float64            `json:"net"`      // Positive when the group owes the member, negative when they owe the group
+}
+
+// ComputeBalances totals what each member paid and owes across expenses, less the repayments
+// recorded in settlements. Disputed expenses are left out until the dispute is resolved. Every
+// member in members is included, followed by former members that still appear in expenses or
+// settlements.
+func ComputeBalances(expenses []Expense, settlements []Settlement, members []primitive.ObjectID) []MemberBalance {
+	balances := make([]MemberBalance, 0, len(members))
+	index := make(map[primitive.ObjectID]int)
+	balance := func(userID primitive.ObjectID) *MemberBalance {
+		i, ok := index[userID]
+		if !ok {
+			i = len(balances)
+			index[userID] = i
+			balances = append(balances, MemberBalance{UserID: userID})
+		}
+		return &balances[i]
+	}
+
+	for _, member := range members {
+		balance(member)
+	}
+	for _, expense := range expenses {
+		if expense.IsDisputed() {
+			continue
+		}
+		balance(expense.PaidBy).Paid += expense.Amount
+		for _, share := range expense.Splits {
+			balance(share.UserID).Share += share.Amount
+		}
+	}
+	for _, settlement := range settlements {
+		balance(settlement.FromUserID).Sent += settlement.Amount
+		balance(settlement.ToUserID).Received += settlement.Amount
+	}
+
+	// Keep whole cents after summing floating point amounts
+	for i := range balances {
+		balances[i].Paid = RoundMoney(balances[i].Paid)
+		balances[i].Share = RoundMoney(balances[i].Share)
+		balances[i].Sent = RoundMoney(balances[i].Sent)
+		balances[i].Received = RoundMoney(balances[i].Received)
+		net := balances[i].Paid - balances[i].Share + balances[i].Sent - balances[i].Received
+		balances[i].Net = RoundMoney(net)
+	}
+	return balances
+}
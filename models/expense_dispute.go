@@ -0,0 +1,152 @@
+// models/expense_dispute.go
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// NotificationTypeExpenseDisputed tells a member that an expense they paid was disputed
+	NotificationTypeExpenseDisputed NotificationType = "expense_disputed"
+
+	// NotificationTypeExpenseDisputeResolved tells the member who disputed an expense that it counts again
+	NotificationTypeExpenseDisputeResolved NotificationType = "expense_dispute_resolved"
+)
+
+// MaxDisputeMessageLength caps the reason and every message in a dispute thread
+const MaxDisputeMessageLength = 1000
+
+// DisputeStatus tells whether a disputed expense is still held out of the balances
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen     DisputeStatus = "open"     // The expense does not count towards balances
+	DisputeStatusResolved DisputeStatus = "resolved" // The payer edited the expense or an admin resolved the dispute
+)
+
+// DisputeMessageKind tells what a message in a dispute thread records
+type DisputeMessageKind string
+
+const (
+	DisputeMessageOpened   DisputeMessageKind = "opened"   // A member disputed the expense, the message is their reason
+	DisputeMessageComment  DisputeMessageKind = "comment"  // Discussion while the dispute is open
+	DisputeMessageEdited   DisputeMessageKind = "edited"   // The payer edited the expense, resolving the dispute
+	DisputeMessageResolved DisputeMessageKind = "resolved" // An admin resolved the dispute without changing the expense
+)
+
+// DisputeMessage is one entry in an expense's dispute thread
+type DisputeMessage struct {
+	Kind      DisputeMessageKind `bson:"kind" json:"kind"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name"`
+	Message   string             `bson:"message,omitempty" json:"message,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ExpenseDispute is a member's objection to an expense. While it is open the expense is left out
+// of balances. The thread keeps every message, including those of earlier disputes of the same
+// expense, oldest first.
+type ExpenseDispute struct {
+	Status     DisputeStatus       `bson:"status" json:"status"`
+	OpenedBy   primitive.ObjectID  `bson:"opened_by" json:"opened_by"`
+	Reason     string              `bson:"reason" json:"reason"`
+	OpenedAt   time.Time           `bson:"opened_at" json:"opened_at"`
+	ResolvedBy *primitive.ObjectID `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	Thread     []DisputeMessage    `bson:"thread" json:"thread"`
+}
+
+// IsDisputed reports whether the expense has an open dispute and is left out of balances
+func (e Expense) IsDisputed() bool {
+	return e.Dispute != nil && e.Dispute.Status == DisputeStatusOpen
+}
+
+// HasShare reports whether the member has a share of the expense
+func (e Expense) HasShare(userID primitive.ObjectID) bool {
+	for _, share := range e.Splits {
+		if share.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// disputeMessage trims a message and checks its length
+func disputeMessage(message string, required bool) (string, error) {
+	message = strings.TrimSpace(message)
+	if required && message == "" {
+		return "", errors.New("a message is required")
+	}
+	if len(message) > MaxDisputeMessageLength {
+		return "", errors.New("message is too long")
+	}
+	return message, nil
+}
+
+// OpenDispute flags the expense as disputed by a member who shares it, for the reason given. A
+// resolved dispute is reopened with its thread kept.
+func (e *Expense) OpenDispute(userID primitive.ObjectID, name, reason string, now time.Time) error {
+	if e.IsDisputed() {
+		return errors.New("the expense is already disputed")
+	}
+	if userID == e.PaidBy {
+		return errors.New("the payer can edit the expense instead of disputing it")
+	}
+	if !e.HasShare(userID) {
+		return errors.New("only members sharing the expense can dispute it")
+	}
+	reason, err := disputeMessage(reason, true)
+	if err != nil {
+		return err
+	}
+
+	thread := make([]DisputeMessage, 0, 1)
+	if e.Dispute != nil {
+		thread = e.Dispute.Thread
+	}
+	e.Dispute = &ExpenseDispute{
+		Status:   DisputeStatusOpen,
+		OpenedBy: userID,
+		Reason:   reason,
+		OpenedAt: now,
+		Thread:   append(thread, DisputeMessage{Kind: DisputeMessageOpened, UserID: userID, Name: name, Message: reason, CreatedAt: now}),
+	}
+	return nil
+}
+
+// CommentOnDispute adds a message to the thread of an open dispute
+func (e *Expense) CommentOnDispute(userID primitive.ObjectID, name, message string, now time.Time) error {
+	if !e.IsDisputed() {
+		return errors.New("the expense is not disputed")
+	}
+	message, err := disputeMessage(message, true)
+	if err != nil {
+		return err
+	}
+	e.Dispute.Thread = append(e.Dispute.Thread, DisputeMessage{Kind: DisputeMessageComment, UserID: userID, Name: name, Message: message, CreatedAt: now})
+	return nil
+}
+
+// ResolveDispute closes an open dispute so the expense counts towards balances again. The kind
+// records whether the expense was edited or an admin resolved the dispute as is.
+func (e *Expense) ResolveDispute(userID primitive.ObjectID, name string, kind DisputeMessageKind, message string, now time.Time) error {
+	if !e.IsDisputed() {
+		return errors.New("the expense is not disputed")
+	}
+	if kind != DisputeMessageEdited && kind != DisputeMessageResolved {
+		return errors.New("a dispute is resolved by editing the expense or by an admin")
+	}
+	message, err := disputeMessage(message, false)
+	if err != nil {
+		return err
+	}
+	e.Dispute.Status = DisputeStatusResolved
+	e.Dispute.ResolvedBy = &userID
+	e.Dispute.ResolvedAt = &now
+	e.Dispute.Thread = append(e.Dispute.Thread, DisputeMessage{Kind: kind, UserID: userID, Name: name, Message: message, CreatedAt: now})
+	return nil
+}
@@ -0,0 +1,182 @@
+// models/expense_report.go
+package models
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ExpenseCategory groups expenses for budgeting
+type ExpenseCategory string
+
+const (
+	ExpenseCategoryRent      ExpenseCategory = "rent"
+	ExpenseCategoryGroceries ExpenseCategory = "groceries"
+	ExpenseCategoryUtilities ExpenseCategory = "utilities"
+	ExpenseCategoryFun       ExpenseCategory = "fun"
+	ExpenseCategoryOther     ExpenseCategory = "other"
+)
+
+// ExpenseCategories lists every category in the order reports show them
+var ExpenseCategories = []ExpenseCategory{
+	ExpenseCategoryRent,
+	ExpenseCategoryGroceries,
+	ExpenseCategoryUtilities,
+	ExpenseCategoryFun,
+	ExpenseCategoryOther,
+}
+
+// IsValidExpenseCategory checks if the category is one of the defined values
+func IsValidExpenseCategory(category ExpenseCategory) bool {
+	for _, c := range ExpenseCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultExpenseCategory is the category of expenses recorded without one: shopping trips are
+// groceries and everything else is other
+func DefaultExpenseCategory(source ExpenseSource) ExpenseCategory {
+	if source == ExpenseSourceShopping {
+		return ExpenseCategoryGroceries
+	}
+	return ExpenseCategoryOther
+}
+
+// CategoryOrDefault returns the expense's category, falling back to the default for its source
+// for expenses recorded before categories existed
+func (e Expense) CategoryOrDefault() ExpenseCategory {
+	if e.Category != "" {
+		return e.Category
+	}
+	return DefaultExpenseCategory(e.Source)
+}
+
+// MemberSpend is what a member paid and what their shares came to
+type MemberSpend struct {
+	UserID primitive.ObjectID `json:"user_id"`
+	Name   string             `json:"name"`
+	Paid   float64            `json:"paid"`
+	Share  float64            `json:"share"`
+}
+
+// CategorySpend is the spending in one category, broken down by member
+type CategorySpend struct {
+	Category ExpenseCategory `json:"category"`
+	Total    float64         `json:"total"`
+	Members  []MemberSpend   `json:"members"` // Only members who paid or had a share
+}
+
+// ExpenseReport totals a group's spending in a month (YYYY-MM) per category and member
+type ExpenseReport struct {
+	Month      string          `json:"month"`
+	Currency   string          `json:"currency"`
+	Total      float64         `json:"total"`
+	Categories []CategorySpend `json:"categories"` // Categories with spending, in ExpenseCategories order
+	Members    []MemberSpend   `json:"members"`    // Totals across categories for every member
+}
+
+// BuildExpenseReport totals the month's expenses per category and member. Every member in members
+// appears in the totals, followed by former members that still appear in expenses.
+func BuildExpenseReport(month string, expenses []Expense, members []primitive.ObjectID) ExpenseReport {
+	report := ExpenseReport{Month: month, Categories: make([]CategorySpend, 0), Members: make([]MemberSpend, 0, len(members))}
+
+	memberSpend := func(spend *[]MemberSpend, userID primitive.ObjectID) *MemberSpend {
+		for i := range *spend {
+			if (*spend)[i].UserID == userID {
+				return &(*spend)[i]
+			}
+		}
+		*spend = append(*spend, MemberSpend{UserID: userID})
+		return &(*spend)[len(*spend)-1]
+	}
+	for _, member := range members {
+		memberSpend(&report.Members, member)
+	}
+
+	categories := make(map[ExpenseCategory]*CategorySpend)
+	for _, expense := range expenses {
+		category := expense.CategoryOrDefault()
+		spend, ok := categories[category]
+		if !ok {
+			spend = &CategorySpend{Category: category, Members: make([]MemberSpend, 0)}
+			categories[category] = spend
+		}
+
+		spend.Total += expense.Amount
+		report.Total += expense.Amount
+		memberSpend(&spend.Members, expense.PaidBy).Paid += expense.Amount
+		memberSpend(&report.Members, expense.PaidBy).Paid += expense.Amount
+		for _, share := range expense.Splits {
+			memberSpend(&spend.Members, share.UserID).Share += share.Amount
+			memberSpend(&report.Members, share.UserID).Share += share.Amount
+		}
+	}
+
+	// List members in the same order in every category
+	for _, category := range ExpenseCategories {
+		spend, ok := categories[category]
+		if !ok {
+			continue
+		}
+		sorted := make([]MemberSpend, 0, len(spend.Members))
+		for _, member := range report.Members {
+			for _, m := range spend.Members {
+				if m.UserID == member.UserID {
+					sorted = append(sorted, roundMemberSpend(m))
+				}
+			}
+		}
+		spend.Members = sorted
+		spend.Total = RoundMoney(spend.Total)
+		report.Categories = append(report.Categories, *spend)
+	}
+
+	report.Total = RoundMoney(report.Total)
+	for i := range report.Members {
+		report.Members[i] = roundMemberSpend(report.Members[i])
+	}
+	return report
+}
+
+// roundMemberSpend rounds a member's totals to whole cents
+func roundMemberSpend(m MemberSpend) MemberSpend {
+	m.Paid = RoundMoney(m.Paid)
+	m.Share = RoundMoney(m.Share)
+	return m
+}
+
+// SetNames sets the name of each member in the report
+func (r *ExpenseReport) SetNames(names map[primitive.ObjectID]string) {
+	for i := range r.Members {
+		r.Members[i].Name = names[r.Members[i].UserID]
+	}
+	for i := range r.Categories {
+		for j := range r.Categories[i].Members {
+			r.Categories[i].Members[j].Name = names[r.Categories[i].Members[j].UserID]
+		}
+	}
+}
+
+// CSVRecords lays the report out as spreadsheet rows: a header, one row per category and member,
+// then one total row per member
+func (r ExpenseReport) CSVRecords() [][]string {
+	records := [][]string{{"month", "category", "member", "paid", "share"}}
+	for _, category := range r.Categories {
+		for _, member := range category.Members {
+			records = append(records, []string{r.Month, string(category.Category), member.Name, formatMoney(member.Paid), formatMoney(member.Share)})
+		}
+	}
+	for _, member := range r.Members {
+		records = append(records, []string{r.Month, "total", member.Name, formatMoney(member.Paid), formatMoney(member.Share)})
+	}
+	return records
+}
+
+// formatMoney formats an amount with two decimals
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
@@ -0,0 +1,189 @@
+// models/expense_statement.go
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatementEntry is one expense or repayment on a statement
+type StatementEntry struct {
+	Date             time.Time       `json:"date"`
+	Type             string          `json:"type"` // expense, disputed_expense or settlement
+	Description      string          `json:"description"`
+	Category         ExpenseCategory `json:"category,omitempty"`
+	From             string          `json:"from"`         // Member who paid
+	To               string          `json:"to,omitempty"` // Member who was paid back, for settlements
+	Amount           float64         `json:"amount"`
+	OriginalAmount   float64         `json:"original_amount,omitempty"`
+	OriginalCurrency string          `json:"original_currency,omitempty"`
+	Shares           string          `json:"shares,omitempty"` // Each member's share, e.g. "Alice 12.50; Bob 12.50"
+}
+
+// ExpenseStatement documents a group's expenses and repayments between two dates (both
+// inclusive) and where every member stood at the end, e.g. for the final settlement at lease end
+type ExpenseStatement struct {
+	Group    string           `json:"group"`
+	Currency string           `json:"currency"`
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Entries  []StatementEntry `json:"entries"` // Oldest first
+	Spent    float64          `json:"spent"`   // Total of the expenses, without disputed ones
+	Repaid   float64          `json:"repaid"`  // Total of the settlements
+	Balances []MemberBalance  `json:"balances"`
+	Debts    []Debt           `json:"debts"` // Payments that would settle the balances
+}
+
+// BuildExpenseStatement lists the expenses and settlements, which should already be limited to
+// the statement's dates, and balances them. Members are named from names, falling back to their ID
+// for accounts that no longer exist.
+func BuildExpenseStatement(from, to time.Time, expenses []Expense, settlements []Settlement, members []primitive.ObjectID, names map[primitive.ObjectID]string) ExpenseStatement {
+	name := func(userID primitive.ObjectID) string {
+		if n, ok := names[userID]; ok && n != "" {
+			return n
+		}
+		return userID.Hex()
+	}
+
+	statement := ExpenseStatement{From: from, To: to, Entries: make([]StatementEntry, 0, len(expenses)+len(settlements))}
+	for _, expense := range expenses {
+		shares := make([]string, 0, len(expense.Splits))
+		for _, share := range expense.Splits {
+			shares = append(shares, name(share.UserID)+" "+formatMoney(share.Amount))
+		}
+		entryType := "expense"
+		if expense.IsDisputed() {
+			// Listed for the record, but left out of the totals and balances until resolved
+			entryType = "disputed_expense"
+		}
+		statement.Entries = append(statement.Entries, StatementEntry{
+			Date:             expense.CreatedAt,
+			Type:             entryType,
+			Description:      expense.Description,
+			Category:         expense.CategoryOrDefault(),
+			From:             name(expense.PaidBy),
+			Amount:           expense.Amount,
+			OriginalAmount:   expense.OriginalAmount,
+			OriginalCurrency: expense.OriginalCurrency,
+			Shares:           strings.Join(shares, "; "),
+		})
+		if !expense.IsDisputed() {
+			statement.Spent += expense.Amount
+		}
+	}
+	for _, settlement := range settlements {
+		statement.Entries = append(statement.Entries, StatementEntry{
+			Date:        settlement.CreatedAt,
+			Type:        "settlement",
+			Description: settlement.Note,
+			From:        name(settlement.FromUserID),
+			To:          name(settlement.ToUserID),
+			Amount:      settlement.Amount,
+		})
+		statement.Repaid += settlement.Amount
+	}
+	sort.SliceStable(statement.Entries, func(i, j int) bool {
+		return statement.Entries[i].Date.Before(statement.Entries[j].Date)
+	})
+	statement.Spent = RoundMoney(statement.Spent)
+	statement.Repaid = RoundMoney(statement.Repaid)
+
+	statement.Balances = ComputeBalances(expenses, settlements, members)
+	for i := range statement.Balances {
+		statement.Balances[i].Name = name(statement.Balances[i].UserID)
+	}
+	statement.Debts = SimplifyDebts(statement.Balances)
+	return statement
+}
+
+// CSVRecords lays the statement out as spreadsheet rows: a header, one row per expense or
+// settlement, then one balance row per member with their net balance as the amount
+func (s ExpenseStatement) CSVRecords() [][]string {
+	records := [][]string{{"date", "type", "description", "category", "from", "to", "amount", "currency", "original_amount", "original_currency", "shares"}}
+	for _, entry := range s.Entries {
+		originalAmount := ""
+		if entry.OriginalCurrency != "" {
+			originalAmount = formatMoney(entry.OriginalAmount)
+		}
+		records = append(records, []string{
+			entry.Date.UTC().Format("2006-01-02"),
+			entry.Type,
+			entry.Description,
+			string(entry.Category),
+			entry.From,
+			entry.To,
+			formatMoney(entry.Amount),
+			s.Currency,
+			originalAmount,
+			entry.OriginalCurrency,
+			entry.Shares,
+		})
+	}
+	to := s.To.UTC().Format("2006-01-02")
+	for _, balance := range s.Balances {
+		records = append(records, []string{to, "balance", "", "", balance.Name, "", formatMoney(balance.Net), s.Currency, "", "", ""})
+	}
+	return records
+}
+
+// Lines lays the statement out as plain text lines for a printed statement
+func (s ExpenseStatement) Lines() []string {
+	lines := []string{
+		fmt.Sprintf("Group: %s", s.Group),
+		fmt.Sprintf("Period: %s to %s", s.From.UTC().Format("2006-01-02"), s.To.UTC().Format("2006-01-02")),
+		fmt.Sprintf("Currency: %s", s.Currency),
+		"",
+		fmt.Sprintf("%-10s  %-28s  %-24s  %10s", "Date", "Description", "Paid", "Amount"),
+	}
+	for _, entry := range s.Entries {
+		description, paid := entry.Description, entry.From
+		if entry.Type == "settlement" {
+			description = "Repayment"
+			if entry.Description != "" {
+				description += ": " + entry.Description
+			}
+			paid = entry.From + " to " + entry.To
+		}
+		if entry.Type == "disputed_expense" {
+			description = "Disputed: " + description
+		}
+		lines = append(lines, fmt.Sprintf("%-10s  %-28s  %-24s  %10s", entry.Date.UTC().Format("2006-01-02"), truncate(description, 28), truncate(paid, 24), formatMoney(entry.Amount)))
+		if entry.OriginalCurrency != "" {
+			lines = append(lines, fmt.Sprintf("%12s(%s %s)", "", formatMoney(entry.OriginalAmount), entry.OriginalCurrency))
+		}
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("Total spent: %s %s", formatMoney(s.Spent), s.Currency),
+		fmt.Sprintf("Total repaid: %s %s", formatMoney(s.Repaid), s.Currency),
+		"",
+		fmt.Sprintf("%-24s  %10s  %10s  %10s  %10s  %10s", "Member", "Paid", "Share", "Sent", "Received", "Balance"),
+	)
+	for _, balance := range s.Balances {
+		lines = append(lines, fmt.Sprintf("%-24s  %10s  %10s  %10s  %10s  %10s", truncate(balance.Name, 24), formatMoney(balance.Paid), formatMoney(balance.Share), formatMoney(balance.Sent), formatMoney(balance.Received), formatMoney(balance.Net)))
+	}
+
+	lines = append(lines, "")
+	if len(s.Debts) == 0 {
+		lines = append(lines, "Everyone is settled up.")
+	} else {
+		lines = append(lines, "To settle up:")
+		for _, debt := range s.Debts {
+			lines = append(lines, fmt.Sprintf("  %s pays %s %s %s", debt.FromName, debt.ToName, formatMoney(debt.Amount), s.Currency))
+		}
+	}
+	return lines
+}
+
+// truncate shortens text to at most n characters so columns line up
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "~"
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Fieldset is the fields of a document a client asked for with ?fields=, by their JSON names.
+// An empty fieldset asks for every field.
+type Fieldset []string
+
+// ParseFieldset reads a comma-separated list of the JSON field names of document, a struct, and
+// maps them to the document's stored fields
+func ParseFieldset(value string, document interface{}) (Fieldset, error) {
+	names := documentFields(reflect.TypeOf(document))
+	var fieldset Fieldset
+	for _, field := range splitList(value) {
+		if _, ok := names[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		fieldset = append(fieldset, field)
+	}
+	return fieldset, nil
+}
+
+// Has reports whether the fieldset includes the field
+func (f Fieldset) Has(field string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	for _, name := range f {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Projection returns the Mongo projection loading the fieldset of document along with the stored
+// fields the endpoint needs whatever was asked for, such as its sort key. It is nil when every
+// field is wanted.
+func (f Fieldset) Projection(document interface{}, required ...string) bson.M {
+	if len(f) == 0 {
+		return nil
+	}
+	names := documentFields(reflect.TypeOf(document))
+	projection := bson.M{"_id": 1}
+	for _, field := range f {
+		if stored := names[field]; stored != "" {
+			projection[stored] = 1
+		}
+	}
+	for _, stored := range required {
+		projection[stored] = 1
+	}
+	return projection
+}
+
+// Select returns the JSON form of the document with only the fieldset's fields
+func (f Fieldset) Select(document interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var selected map[string]interface{}
+	if err := json.Unmarshal(data, &selected); err != nil {
+		return nil, err
+	}
+	if len(f) > 0 {
+		for field := range selected {
+			if !f.Has(field) {
+				delete(selected, field)
+			}
+		}
+	}
+	return selected, nil
+}
+
+// ParseExpand reads a comma-separated ?expand= list of the related documents to embed, which
+// must be among those allowed
+func ParseExpand(value string, allowed ...string) ([]string, error) {
+	var expand []string
+	for _, name := range splitList(value) {
+		found := false
+		for _, option := range allowed {
+			found = found || name == option
+		}
+		if !found {
+			return nil, fmt.Errorf("%q cannot be expanded; use %s", name, strings.Join(allowed, ", "))
+		}
+		expand = append(expand, name)
+	}
+	return expand, nil
+}
+
+// documentFields maps the JSON names of a struct's fields, embedded ones included, to their BSON
+// names. Fields that are not stored map to an empty name.
+func documentFields(t reflect.Type) map[string]string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	fields := make(map[string]string)
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			for name, stored := range documentFields(field.Type) {
+				if _, ok := fields[name]; !ok {
+					fields[name] = stored
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name := tagName(field.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		stored := tagName(field.Tag.Get("bson"))
+		if stored == "-" {
+			stored = ""
+		}
+		fields[name] = stored
+	}
+	return fields
+}
+
+func tagName(tag string) string {
+	if i := strings.Index(tag, ","); i >= 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// splitList splits a comma-separated query value, dropping blanks and repeats
+func splitList(value string) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		list = append(list, item)
+	}
+	return list
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuggestionSource tells where an item suggestion came from
+type SuggestionSource string
+
+const (
+	SuggestionSourceHistory SuggestionSource = "history" // Items the group has added or bought before
+	SuggestionSourceCommon  SuggestionSource = "common"  // The built-in common groceries dictionary
+)
+
+// ItemSuggestion is an autocomplete suggestion for a shopping item name
+type ItemSuggestion struct {
+	Name     string           `bson:"name" json:"name"`
+	Category string           `bson:"category" json:"category,omitempty"`
+	Unit     string           `bson:"unit" json:"unit,omitempty"`
+	Count    int              `bson:"count" json:"count"` // How often the group has used the item
+	Source   SuggestionSource `bson:"-" json:"source"`
+}
+
+// CommonGroceries seeds suggestions for groups with little history
+var CommonGroceries = []ItemSuggestion{
+	{Name: "Milk", Category: "Dairy"},
+	{Name: "Eggs", Category: "Dairy", Unit: "dozen"},
+	{Name: "Butter", Category: "Dairy"},
+	{Name: "Cheese", Category: "Dairy"},
+	{Name: "Yogurt", Category: "Dairy"},
+	{Name: "Cream", Category: "Dairy"},
+	{Name: "Bread", Category: "Bakery"},
+	{Name: "Bagels", Category: "Bakery"},
+	{Name: "Tortillas", Category: "Bakery"},
+	{Name: "Apples", Category: "Produce"},
+	{Name: "Bananas", Category: "Produce"},
+	{Name: "Oranges", Category: "Produce"},
+	{Name: "Lemons", Category: "Produce"},
+	{Name: "Avocados", Category: "Produce"},
+	{Name: "Tomatoes", Category: "Produce"},
+	{Name: "Potatoes", Category: "Produce", Unit: "lb"},
+	{Name: "Onions", Category: "Produce"},
+	{Name: "Garlic", Category: "Produce"},
+	{Name: "Carrots", Category: "Produce"},
+	{Name: "Lettuce", Category: "Produce"},
+	{Name: "Spinach", Category: "Produce"},
+	{Name: "Broccoli", Category: "Produce"},
+	{Name: "Bell peppers", Category: "Produce"},
+	{Name: "Cucumbers", Category: "Produce"},
+	{Name: "Mushrooms", Category: "Produce"},
+	{Name: "Chicken breast", Category: "Meat", Unit: "lb"},
+	{Name: "Ground beef", Category: "Meat", Unit: "lb"},
+	{Name: "Bacon", Category: "Meat"},
+	{Name: "Salmon", Category: "Seafood", Unit: "lb"},
+	{Name: "Rice", Category: "Pantry"},
+	{Name: "Pasta", Category: "Pantry"},
+	{Name: "Pasta sauce", Category: "Pantry"},
+	{Name: "Flour", Category: "Pantry"},
+	{Name: "Sugar", Category: "Pantry"},
+	{Name: "Salt", Category: "Pantry"},
+	{Name: "Black pepper", Category: "Pantry"},
+	{Name: "Olive oil", Category: "Pantry"},
+	{Name: "Vegetable oil", Category: "Pantry"},
+	{Name: "Cereal", Category: "Pantry"},
+	{Name: "Oats", Category: "Pantry"},
+	{Name: "Peanut butter", Category: "Pantry"},
+	{Name: "Jam", Category: "Pantry"},
+	{Name: "Honey", Category: "Pantry"},
+	{Name: "Canned beans", Category: "Pantry"},
+	{Name: "Canned tomatoes", Category: "Pantry"},
+	{Name: "Soup", Category: "Pantry"},
+	{Name: "Coffee", Category: "Beverages"},
+	{Name: "Tea", Category: "Beverages"},
+	{Name: "Orange juice", Category: "Beverages"},
+	{Name: "Sparkling water", Category: "Beverages"},
+	{Name: "Chips", Category: "Snacks"},
+	{Name: "Crackers", Category: "Snacks"},
+	{Name: "Cookies", Category: "Snacks"},
+	{Name: "Ice cream", Category: "Frozen"},
+	{Name: "Frozen pizza", Category: "Frozen"},
+	{Name: "Frozen vegetables", Category: "Frozen"},
+	{Name: "Toilet paper", Category: "Household"},
+	{Name: "Paper towels", Category: "Household"},
+	{Name: "Dish soap", Category: "Household"},
+	{Name: "Laundry detergent", Category: "Household"},
+	{Name: "Trash bags", Category: "Household"},
+	{Name: "Sponges", Category: "Household"},
+	{Name: "Aluminum foil", Category: "Household"},
+	{Name: "Hand soap", Category: "Personal care"},
+	{Name: "Shampoo", Category: "Personal care"},
+	{Name: "Toothpaste", Category: "Personal care"},
+}
+
+// suggestionMatchRank scores how well name matches query: 0 for a prefix match, 1 when a later
+// word starts with the query, 2 for any other substring match and -1 for no match
+func suggestionMatchRank(name, query string) int {
+	name = strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(name, query):
+		return 0
+	case strings.Contains(name, " "+query):
+		return 1
+	case strings.Contains(name, query):
+		return 2
+	}
+	return -1
+}
+
+// RankSuggestions merges the group's history with the common groceries dictionary and returns
+// up to limit suggestions matching query. Better matches come first, then items the group uses
+// more often, so frequently bought items surface after a keystroke or two.
+func RankSuggestions(query string, history []ItemSuggestion, limit int) []ItemSuggestion {
+	query = strings.ToLower(strings.TrimSpace(query))
+
+	type candidate struct {
+		ItemSuggestion
+		rank int
+	}
+	candidates := make([]candidate, 0)
+	seen := make(map[string]bool)
+
+	add := func(s ItemSuggestion, source SuggestionSource) {
+		key := strings.ToLower(strings.TrimSpace(s.Name))
+		if key == "" || seen[key] {
+			return
+		}
+		rank := suggestionMatchRank(key, query)
+		if rank < 0 {
+			return
+		}
+		seen[key] = true
+		s.Source = source
+		candidates = append(candidates, candidate{ItemSuggestion: s, rank: rank})
+	}
+
+	for _, s := range history {
+		add(s, SuggestionSourceHistory)
+	}
+	for _, s := range CommonGroceries {
+		add(s, SuggestionSourceCommon)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].rank != candidates[j].rank {
+			return candidates[i].rank < candidates[j].rank
+		}
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return strings.ToLower(candidates[i].Name) < strings.ToLower(candidates[j].Name)
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	suggestions := make([]ItemSuggestion, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, c.ItemSuggestion)
+	}
+	return suggestions
+}
@@ -15,8 +15,12 @@ type Group struct {
 	Name      string               `bson:"name" json:"name" validate:"required,min=3"`
 	GroupCode string               `bson:"group_code" json:"group_code"`
 	Members   []primitive.ObjectID `bson:"members" json:"members"`
-	CreatedAt time.Time            `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time            `bson:"updated_at" json:"updated_at"`
+	Admins    []primitive.ObjectID `bson:"admins,omitempty" json:"admins,omitempty"`
+	Settings  GroupSettings        `bson:"settings" json:"settings"`
+	// LedgerVersion is bumped by every settlement so concurrent repayments are checked one at a time
+	LedgerVersion int64     `bson:"ledger_version,omitempty" json:"-"`
+	CreatedAt     time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `bson:"updated_at" json:"updated_at"`
 }
 
 func generateGroupCode() string {
@@ -33,11 +37,51 @@ func NewGroup(name string) *Group {
 		Name:      name,
 		GroupCode: generateGroupCode(),
 		Members:   make([]primitive.ObjectID, 0),
+		Admins:    make([]primitive.ObjectID, 0),
+		Settings:  GroupSettings{Scoring: DefaultScoringRules()},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 }
 
+// IsMember checks if a user is listed as a member of the group
+func (g *Group) IsMember(userID primitive.ObjectID) bool {
+	for _, id := range g.Members {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin checks if a user can manage group-wide settings
+func (g *Group) IsAdmin(userID primitive.ObjectID) bool {
+	for _, id := range g.Admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateGroupAdmins makes the earliest member the admin of groups created before admins were tracked
+func MigrateGroupAdmins(db *mongo.Database) error {
+	_, err := db.Collection("groups").UpdateMany(
+		context.Background(),
+		NoAdminsFilter(),
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"admins": bson.A{bson.M{"$arrayElemAt": bson.A{"$members", 0}}}}}}},
+	)
+	return err
+}
+
+// NoAdminsFilter matches groups that have members but no admin
+func NoAdminsFilter() bson.M {
+	return bson.M{
+		"$or":       bson.A{bson.M{"admins": bson.M{"$exists": false}}, bson.M{"admins": bson.M{"$size": 0}}},
+		"members.0": bson.M{"$exists": true},
+	}
+}
+
 // MigrateExistingGroups adds group codes to existing groups
 func MigrateExistingGroups(db *mongo.Database) error {
 	ctx := context.Background()
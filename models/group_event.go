@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupEventType is a kind of change streamed live to a group's members
+type GroupEventType string
+
+// Group event types share their names with the matching webhook events. Chat, direct messages and
+// kudos stay inside the app, so they have no webhook.
+const (
+	GroupEventChoreCompleted GroupEventType = "chore.completed"
+	GroupEventItemAdded      GroupEventType = "shopping.item_added"
+	GroupEventExpenseAdded   GroupEventType = "expense.added"
+	GroupEventMessageSent    GroupEventType = "message.sent"
+	GroupEventChatMessage    GroupEventType = "chat.message"
+	GroupEventChatTyping     GroupEventType = "chat.typing"
+	GroupEventKudosSent      GroupEventType = "kudos.sent"
+)
+
+// GroupEvent is pushed live to group members connected to the event stream. Data is the chore,
+// the shopping event, the expense, the chat or direct message, the member typing or the kudos the
+// event is about. Events with recipients only go to those members.
+type GroupEvent struct {
+	Type       GroupEventType       `json:"type"`
+	GroupID    primitive.ObjectID   `json:"group_id"`
+	Data       interface{}          `json:"data"`
+	At         time.Time            `json:"at"`
+	Recipients []primitive.ObjectID `json:"-"`
+}
+
+// VisibleTo reports whether the event should be sent to the member
+func (e GroupEvent) VisibleTo(userID primitive.ObjectID) bool {
+	if len(e.Recipients) == 0 {
+		return true
+	}
+	for _, id := range e.Recipients {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// ChoreCompletedEvent builds the live event for a completed chore
+func ChoreCompletedEvent(chore Chore) GroupEvent {
+	return GroupEvent{Type: GroupEventChoreCompleted, GroupID: chore.GroupID, Data: chore, At: chore.UpdatedAt}
+}
+
+// ItemAddedEvent builds the live event for an item added to the shopping list
+func ItemAddedEvent(activity ShoppingCartActivity) GroupEvent {
+	return GroupEvent{Type: GroupEventItemAdded, GroupID: activity.GroupID, Data: ShoppingEventFromActivity(activity), At: activity.CreatedAt}
+}
+
+// ExpenseAddedEvent builds the live event for a new expense
+func ExpenseAddedEvent(expense Expense) GroupEvent {
+	return GroupEvent{Type: GroupEventExpenseAdded, GroupID: expense.GroupID, Data: expense, At: expense.CreatedAt}
+}
+
+// MessageSentEvent builds the live event for a direct message, sent to both members of the
+// conversation so the sender's other devices see it too
+func MessageSentEvent(message DirectMessage) GroupEvent {
+	return GroupEvent{
+		Type:       GroupEventMessageSent,
+		GroupID:    message.GroupID,
+		Data:       message,
+		At:         message.CreatedAt,
+		Recipients: []primitive.ObjectID{message.SenderID, message.RecipientID},
+	}
+}
+
+// ChatMessageEvent builds the live event for a message in the group chat
+func ChatMessageEvent(message ChatMessage) GroupEvent {
+	return GroupEvent{Type: GroupEventChatMessage, GroupID: message.GroupID, Data: message, At: message.CreatedAt}
+}
+
+// ChatTypingEvent builds the live event for a member typing in the group chat
+func ChatTypingEvent(typing ChatTyping) GroupEvent {
+	return GroupEvent{Type: GroupEventChatTyping, GroupID: typing.GroupID, Data: typing, At: typing.TypingAt}
+}
+
+// KudosSentEvent builds the live event for kudos one member sent another
+func KudosSentEvent(kudos Kudos) GroupEvent {
+	return GroupEvent{Type: GroupEventKudosSent, GroupID: kudos.GroupID, Data: kudos, At: kudos.CreatedAt}
+}
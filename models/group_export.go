@@ -0,0 +1,303 @@
+package models
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupExportFormat is the file format of a group export
+type GroupExportFormat string
+
+const (
+	// GroupExportJSON is a single JSON document holding every collection
+	GroupExportJSON GroupExportFormat = "json"
+	// GroupExportNDJSON is a zip archive with a newline-delimited JSON file per collection, which
+	// mongoimport can load directly
+	GroupExportNDJSON GroupExportFormat = "ndjson"
+)
+
+// GroupExportStatus tracks a group export through the background job
+type GroupExportStatus string
+
+const (
+	GroupExportPending   GroupExportStatus = "pending"   // Waiting for the export job
+	GroupExportRunning   GroupExportStatus = "running"   // Being written
+	GroupExportCompleted GroupExportStatus = "completed" // Ready to download until it expires
+	GroupExportFailed    GroupExportStatus = "failed"
+)
+
+const (
+	// GroupExportVersion is bumped when the layout of export archives changes
+	GroupExportVersion = 1
+
+	// GroupExportRetention is how long a finished export can be downloaded before it is removed
+	GroupExportRetention = 7 * 24 * time.Hour
+
+	// GroupExportLease is how long a running export is left to its instance before another may
+	// start it over, covering an instance that stops mid-export
+	GroupExportLease = 15 * time.Minute
+)
+
+// GroupExport is a requested export of all of a group's data. The archive is kept in file storage
+// under FileID once the export job has written it.
+type GroupExport struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	RequestedBy primitive.ObjectID  `bson:"requested_by" json:"requested_by"`
+	Format      GroupExportFormat   `bson:"format" json:"format"`
+	Status      GroupExportStatus   `bson:"status" json:"status"`
+	FileID      *primitive.ObjectID `bson:"file_id,omitempty" json:"-"`
+	Size        int64               `bson:"size,omitempty" json:"size,omitempty"`     // Archive size in bytes
+	Counts      map[string]int      `bson:"counts,omitempty" json:"counts,omitempty"` // Documents per collection
+	Error       string              `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt   *time.Time          `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	CompletedAt *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time          `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// CreateGroupExport creates a pending export of the group
+func CreateGroupExport(groupID, requestedBy primitive.ObjectID, format GroupExportFormat) *GroupExport {
+	return &GroupExport{
+		GroupID:     groupID,
+		RequestedBy: requestedBy,
+		Format:      format,
+		Status:      GroupExportPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// IsValidGroupExportFormat checks a format supplied by the client
+func IsValidGroupExportFormat(format GroupExportFormat) bool {
+	return format == GroupExportJSON || format == GroupExportNDJSON
+}
+
+// FileName is the name the export's archive is downloaded under
+func (e *GroupExport) FileName() string {
+	name := "cribb-export-" + e.GroupID.Hex() + "-" + e.CreatedAt.UTC().Format("20060102")
+	if e.Format == GroupExportNDJSON {
+		return name + ".zip"
+	}
+	return name + ".json"
+}
+
+// ContentType is the media type of the export's archive
+func (e *GroupExport) ContentType() string {
+	if e.Format == GroupExportNDJSON {
+		return "application/zip"
+	}
+	return "application/json"
+}
+
+// GroupExportScope is how the documents of a collection belong to a group
+type GroupExportScope int
+
+const (
+	ExportByGroupID GroupExportScope = iota // Documents carrying the group's group_id
+	ExportGroup                             // The group document itself
+	ExportByChore                           // Documents carrying the chore_id of one of the group's chores
+)
+
+// GroupExportCollection is a collection included in group exports
+type GroupExportCollection struct {
+	Name  string
+	Scope GroupExportScope
+	Omit  []string // Fields left out, such as credentials
+}
+
+// GroupExportCollections are the collections a group export covers, in the order they are written.
+// Password hashes and webhook secrets are left out, so members set new passwords and webhooks are
+// given new secrets after a migration. Device tokens, password resets and the push queue belong
+// to the deployment rather than the group and are not exported, and neither are uploaded files,
+// which documents refer to by ID.
+var GroupExportCollections = []GroupExportCollection{
+	{Name: "groups", Scope: ExportGroup},
+	{Name: "users", Omit: []string{"password"}},
+	{Name: "chores"},
+	{Name: "recurring_chores"},
+	{Name: "chore_completions", Scope: ExportByChore},
+	{Name: "blackout_dates"},
+	{Name: "score_events"},
+	{Name: "user_badges"},
+	{Name: "leaderboard_snapshots"},
+	{Name: "rewards"},
+	{Name: "redemptions"},
+	{Name: "challenges"},
+	{Name: "kudos"},
+	{Name: "kudos_budgets"},
+	{Name: "notifications"},
+	{Name: "pantry_categories"},
+	{Name: "pantry_items"},
+	{Name: "pantry_item_versions"},
+	{Name: "pantry_history"},
+	{Name: "pantry_usage"},
+	{Name: "pantry_waste"},
+	{Name: "pantry_notifications"},
+	{Name: "shopping_lists"},
+	{Name: "shopping_list_shares"},
+	{Name: "shopping_cart"},
+	{Name: "shopping_cart_activity"},
+	{Name: "shopping_cart_archive"},
+	{Name: "purchase_history"},
+	{Name: "expenses"},
+	{Name: "settlements"},
+	{Name: "recurring_bills"},
+	{Name: "payment_reminders"},
+	{Name: "rent_configs"},
+	{Name: "meter_readings"},
+	{Name: "house_events"},
+	{Name: "house_rules"},
+	{Name: "house_rule_acknowledgments"},
+	{Name: "quiet_hours_violations"},
+	{Name: "guest_stays"},
+	{Name: "pickup_schedules"},
+	{Name: "resources"},
+	{Name: "resource_bookings"},
+	{Name: "scheduling_polls"},
+	{Name: "decision_polls"},
+	{Name: "chat_messages"},
+	{Name: "conversations"},
+	{Name: "direct_messages"},
+	{Name: "posts"},
+	{Name: "reactions"},
+	{Name: "issues"},
+	{Name: "webhooks", Omit: []string{"secret"}},
+	{Name: "webhook_deliveries"},
+	{Name: "inbound_hooks", Omit: []string{"token_hash"}},
+	{Name: "chat_integrations", Omit: []string{"webhook_url"}},
+}
+
+// GroupExportManifest describes an export archive
+type GroupExportManifest struct {
+	Version     int                `json:"version"`
+	GroupID     primitive.ObjectID `json:"group_id"`
+	GroupName   string             `json:"group_name"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	Collections map[string]int     `json:"collections"` // Documents per collection
+}
+
+// GroupExportWriter writes the documents of an export archive one collection at a time.
+// Documents are written as relaxed MongoDB Extended JSON, so IDs and dates keep their types.
+type GroupExportWriter interface {
+	// Collection starts the next collection
+	Collection(name string) error
+	// Document adds a document to the current collection
+	Document(doc bson.Raw) error
+	// Close finishes the archive with its manifest
+	Close(manifest GroupExportManifest) error
+}
+
+// ErrNoExportCollection is returned for documents written before any collection was started
+var ErrNoExportCollection = errors.New("no collection started")
+
+// NewGroupExportWriter returns a writer of an export archive in the format
+func NewGroupExportWriter(w io.Writer, format GroupExportFormat) GroupExportWriter {
+	if format == GroupExportNDJSON {
+		return &ndjsonExportWriter{archive: zip.NewWriter(w)}
+	}
+	return &jsonExportWriter{w: bufio.NewWriter(w)}
+}
+
+// jsonExportWriter writes {"collections": {"name": [documents]}, "manifest": {...}}
+type jsonExportWriter struct {
+	w           *bufio.Writer
+	collections int
+	documents   int
+}
+
+func (j *jsonExportWriter) Collection(name string) error {
+	key, err := json.Marshal(name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case j.collections == 0:
+		j.w.WriteString(`{"collections":{`)
+	default:
+		j.w.WriteString("],")
+	}
+	j.w.Write(key)
+	_, err = j.w.WriteString(":[")
+	j.collections++
+	j.documents = 0
+	return err
+}
+
+func (j *jsonExportWriter) Document(doc bson.Raw) error {
+	if j.collections == 0 {
+		return ErrNoExportCollection
+	}
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	if j.documents > 0 {
+		j.w.WriteByte(',')
+	}
+	j.documents++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonExportWriter) Close(manifest GroupExportManifest) error {
+	if j.collections == 0 {
+		j.w.WriteString(`{"collections":{`)
+	} else {
+		j.w.WriteString("]")
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	j.w.WriteString(`},"manifest":`)
+	j.w.Write(data)
+	j.w.WriteString("}\n")
+	return j.w.Flush()
+}
+
+// ndjsonExportWriter writes a zip archive of {name}.ndjson files and a manifest.json
+type ndjsonExportWriter struct {
+	archive *zip.Writer
+	current io.Writer
+}
+
+func (n *ndjsonExportWriter) Collection(name string) error {
+	file, err := n.archive.Create(name + ".ndjson")
+	if err != nil {
+		return err
+	}
+	n.current = file
+	return nil
+}
+
+func (n *ndjsonExportWriter) Document(doc bson.Raw) error {
+	if n.current == nil {
+		return ErrNoExportCollection
+	}
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	_, err = n.current.Write(append(data, '\n'))
+	return err
+}
+
+func (n *ndjsonExportWriter) Close(manifest GroupExportManifest) error {
+	file, err := n.archive.Create("manifest.json")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return n.archive.Close()
+}
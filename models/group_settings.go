@@ -0,0 +1,118 @@
+package models
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GroupSettings holds the options a group's admins can configure
+type GroupSettings struct {
+	// ResetPeriodScores zeroes members' weekly and monthly scores when each period ends.
+	// The all-time score is never reset.
+	ResetPeriodScores bool `bson:"reset_period_scores" json:"reset_period_scores"`
+
+	// DecayPercent is the share of their score members lose per inactive week; 0 disables decay
+	DecayPercent int `bson:"decay_percent" json:"decay_percent"`
+
+	// DecayInactiveWeeks is how many weeks without completions pass before decay starts
+	DecayInactiveWeeks int `bson:"decay_inactive_weeks" json:"decay_inactive_weeks"`
+
+	// Scoring tunes how many points members earn and lose
+	Scoring ScoringRules `bson:"scoring" json:"scoring"`
+
+	// AutoAddToPantry adds purchased shopping items to the pantry, incrementing matching items
+	AutoAddToPantry bool `bson:"auto_add_to_pantry" json:"auto_add_to_pantry"`
+
+	// AutoCreateExpenses bills purchased shopping items to the group as an expense
+	AutoCreateExpenses bool `bson:"auto_create_expenses" json:"auto_create_expenses"`
+
+	// AisleOrder lists pantry category IDs in the order the group walks the store
+	AisleOrder []primitive.ObjectID `bson:"aisle_order,omitempty" json:"aisle_order,omitempty"`
+
+	// ExpirationAlertDays is how many days ahead members are warned about expiring pantry items;
+	// 0 uses DefaultExpirationAlertDays
+	ExpirationAlertDays int `bson:"expiration_alert_days" json:"expiration_alert_days"`
+
+	// Currency is the ISO 4217 code expenses and balances are kept in; empty uses DefaultCurrency
+	Currency string `bson:"currency,omitempty" json:"currency,omitempty"`
+
+	// PaymentReminders decide when members who owe money are reminded to pay
+	PaymentReminders PaymentReminderRules `bson:"payment_reminders" json:"payment_reminders"`
+
+	// MaxGuestNights is how many nights in a row a guest can stay; 0 means no limit
+	MaxGuestNights int `bson:"max_guest_nights" json:"max_guest_nights"`
+
+	// QuietHours is when the house should be quiet; violations can only be logged within them
+	QuietHours QuietHours `bson:"quiet_hours" json:"quiet_hours"`
+
+	// KudosBudget is how many bonus points members can hand out with kudos each month; 0 means
+	// kudos carry no points
+	KudosBudget int `bson:"kudos_budget" json:"kudos_budget"`
+}
+
+// CurrencyCode returns the currency the group keeps its expenses in
+func (g GroupSettings) CurrencyCode() string {
+	if g.Currency == "" {
+		return DefaultCurrency
+	}
+	return g.Currency
+}
+
+// Limits of the pantry expiration alert window
+const (
+	DefaultExpirationAlertDays = 3
+	MaxExpirationAlertDays     = 30
+)
+
+// ExpirationAlertWindow returns how many days ahead to warn about expiring pantry items
+func (g GroupSettings) ExpirationAlertWindow() int {
+	if g.ExpirationAlertDays < 1 {
+		return DefaultExpirationAlertDays
+	}
+	return g.ExpirationAlertDays
+}
+
+// Limits of the scoring rules a group can configure
+const (
+	DefaultPointsPerCompletion = 1
+	MaxScoringPoints           = 1000
+)
+
+// ScoringRules holds a group's point values. Groups created before scoring rules existed
+// have zero values, which behave like DefaultScoringRules.
+type ScoringRules struct {
+	// PointsPerCompletion is awarded for chores created without an explicit point value
+	PointsPerCompletion int `bson:"points_per_completion" json:"points_per_completion"`
+
+	// OverduePenalty is deducted from the assignee when a chore becomes overdue; 0 disables it
+	OverduePenalty int `bson:"overdue_penalty" json:"overdue_penalty"`
+}
+
+// DefaultScoringRules returns the rules new groups start with
+func DefaultScoringRules() ScoringRules {
+	return ScoringRules{PointsPerCompletion: DefaultPointsPerCompletion}
+}
+
+// Validate checks that every rule is within its allowed range
+func (s ScoringRules) Validate() error {
+	if s.PointsPerCompletion < 1 || s.PointsPerCompletion > MaxScoringPoints {
+		return fmt.Errorf("points per completion must be between 1 and %d", MaxScoringPoints)
+	}
+	if s.OverduePenalty < 0 || s.OverduePenalty > MaxScoringPoints {
+		return fmt.Errorf("overdue penalty must be between 0 and %d", MaxScoringPoints)
+	}
+	return nil
+}
+
+// ChorePoints returns the points for a new chore, falling back to the group's
+// points per completion when none (or an invalid value) was requested
+func (s ScoringRules) ChorePoints(requested int) int {
+	if requested >= 1 {
+		return requested
+	}
+	if s.PointsPerCompletion >= 1 {
+		return s.PointsPerCompletion
+	}
+	return DefaultPointsPerCompletion
+}
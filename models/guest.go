@@ -0,0 +1,141 @@
+// models/guest.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// NotificationTypeGuestRegistered tells members a housemate registered an overnight guest
+	NotificationTypeGuestRegistered NotificationType = "guest_registered"
+
+	// NotificationTypeGuestArriving tells members a guest arrives within a day
+	NotificationTypeGuestArriving NotificationType = "guest_arriving"
+)
+
+const (
+	// MaxGuestNightsLimit is the highest limit on consecutive guest nights a group can set, and
+	// the longest a single stay can be registered for
+	MaxGuestNightsLimit = 60
+
+	// GuestArrivalNotice is how long before a guest arrives members are told
+	GuestArrivalNotice = 24 * time.Hour
+)
+
+// GuestStay is an overnight guest a member registered with the group. Arrival and departure are
+// dates, stored as midnight UTC.
+type GuestStay struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID           primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	HostID            primitive.ObjectID `bson:"host_id" json:"host_id" validate:"required"`
+	GuestName         string             `bson:"guest_name" json:"guest_name" validate:"required"`
+	ArriveOn          time.Time          `bson:"arrive_on" json:"arrive_on"`
+	DepartOn          time.Time          `bson:"depart_on" json:"depart_on"`
+	Notes             string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	ArrivalNotifiedAt *time.Time         `bson:"arrival_notified_at,omitempty" json:"-"` // When members were told the guest is arriving
+	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateGuestStay registers a guest staying from the arrival date until the departure date
+func CreateGuestStay(groupID, hostID primitive.ObjectID, guestName string, arriveOn, departOn time.Time, notes string) *GuestStay {
+	now := time.Now()
+	return &GuestStay{
+		GroupID:   groupID,
+		HostID:    hostID,
+		GuestName: strings.TrimSpace(guestName),
+		ArriveOn:  startOfDayUTC(arriveOn),
+		DepartOn:  startOfDayUTC(departOn),
+		Notes:     strings.TrimSpace(notes),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ValidateGuestStayDates checks that a stay arrives today or later, lasts at least one night and
+// no more than MaxGuestNightsLimit
+func ValidateGuestStayDates(arriveOn, departOn, now time.Time) error {
+	switch {
+	case arriveOn.IsZero() || departOn.IsZero():
+		return errors.New("arrival and departure dates are required")
+	case startOfDayUTC(arriveOn).Before(startOfDayUTC(now)):
+		return errors.New("guests cannot be registered for past dates")
+	case !startOfDayUTC(departOn).After(startOfDayUTC(arriveOn)):
+		return errors.New("guests must depart after the day they arrive")
+	case nightsBetween(arriveOn, departOn) > MaxGuestNightsLimit:
+		return fmt.Errorf("stays cannot be longer than %d nights", MaxGuestNightsLimit)
+	}
+	return nil
+}
+
+// nightsBetween counts the nights from the arrival date to the departure date
+func nightsBetween(arriveOn, departOn time.Time) int {
+	return int(startOfDayUTC(departOn).Sub(startOfDayUTC(arriveOn)).Hours() / 24)
+}
+
+// Nights returns how many nights the guest stays
+func (s GuestStay) Nights() int {
+	return nightsBetween(s.ArriveOn, s.DepartOn)
+}
+
+// SameGuest reports whether two stays are by the same guest, going by name
+func (s GuestStay) SameGuest(other GuestStay) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(s.GuestName), " "), strings.Join(strings.Fields(other.GuestName), " "))
+}
+
+// ConsecutiveGuestNights returns how many nights in a row the guest would stay, joining the stay
+// with the guest's other stays that overlap it or follow on without a night in between
+func ConsecutiveGuestNights(stay GuestStay, others []GuestStay) int {
+	arriveOn, departOn := stay.ArriveOn, stay.DepartOn
+	// Joining a stay can bring another within reach, so repeat until nothing changes
+	for joined := true; joined; {
+		joined = false
+		for _, other := range others {
+			if other.ID == stay.ID && !stay.ID.IsZero() || !stay.SameGuest(other) {
+				continue
+			}
+			if other.ArriveOn.After(departOn) || other.DepartOn.Before(arriveOn) {
+				continue
+			}
+			if other.ArriveOn.Before(arriveOn) {
+				arriveOn, joined = other.ArriveOn, true
+			}
+			if other.DepartOn.After(departOn) {
+				departOn, joined = other.DepartOn, true
+			}
+		}
+	}
+	return nightsBetween(arriveOn, departOn)
+}
+
+// GuestNightsExceeded reports whether a run of consecutive guest nights goes over the group's limit
+func (g GroupSettings) GuestNightsExceeded(nights int) bool {
+	return g.MaxGuestNights > 0 && nights > g.MaxGuestNights
+}
+
+// params are the template values describing the stay
+func (s GuestStay) params(hostName string) NotificationParams {
+	return NotificationParams{
+		"name":   hostName,
+		"guest":  s.GuestName,
+		"arrive": NotificationDate(s.ArriveOn),
+		"depart": NotificationDate(s.DepartOn),
+		"nights": strconv.Itoa(s.Nights()),
+	}
+}
+
+// GuestRegisteredNotification tells a member that the host registered the guest
+func GuestRegisteredNotification(stay GuestStay, userID primitive.ObjectID, hostName string) *Notification {
+	return CreateTemplatedNotification(userID, stay.GroupID, NotificationTypeGuestRegistered, TemplateGuestRegistered, stay.params(hostName))
+}
+
+// GuestArrivingNotification tells a member that the host's guest arrives soon
+func GuestArrivingNotification(stay GuestStay, userID primitive.ObjectID, hostName string) *Notification {
+	return CreateTemplatedNotification(userID, stay.GroupID, NotificationTypeGuestArriving, TemplateGuestArriving, stay.params(hostName))
+}
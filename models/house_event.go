@@ -0,0 +1,226 @@
+// models/house_event.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// HouseEventType is the kind of thing happening in the house
+type HouseEventType string
+
+const (
+	HouseEventParty       HouseEventType = "party"
+	HouseEventMaintenance HouseEventType = "maintenance" // Visits from plumbers, electricians and the like
+	HouseEventInspection  HouseEventType = "inspection"  // Landlord or agency inspections
+	HouseEventOther       HouseEventType = "other"
+)
+
+// RSVPStatus is a member's answer to a house event
+type RSVPStatus string
+
+const (
+	RSVPGoing    RSVPStatus = "going"
+	RSVPMaybe    RSVPStatus = "maybe"
+	RSVPNotGoing RSVPStatus = "not_going"
+)
+
+const (
+	// NotificationTypeHouseEventCreated tells members a housemate added an event
+	NotificationTypeHouseEventCreated NotificationType = "house_event_created"
+
+	// NotificationTypeHouseEventReminder reminds members of an event that is about to start
+	NotificationTypeHouseEventReminder NotificationType = "house_event_reminder"
+)
+
+// Limits on house events
+const (
+	DefaultHouseEventReminder = 60 * time.Minute
+	MaxHouseEventReminder     = 7 * 24 * time.Hour
+	MaxHouseEventDuration     = 7 * 24 * time.Hour
+)
+
+// houseEventLabels describe the kinds of event in calendar feeds
+var houseEventLabels = map[HouseEventType]string{
+	HouseEventParty:       "Party",
+	HouseEventMaintenance: "Maintenance visit",
+	HouseEventInspection:  "Inspection",
+}
+
+// HouseEventRSVP is a member's answer to a house event
+type HouseEventRSVP struct {
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Status      RSVPStatus         `bson:"status" json:"status"`
+	RespondedAt time.Time          `bson:"responded_at" json:"responded_at"`
+}
+
+// HouseEvent is something happening in the house that members should plan around, such as a
+// party, a maintenance visit or an inspection
+type HouseEvent struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Type        HouseEventType     `bson:"type" json:"type"`
+	Title       string             `bson:"title" json:"title" validate:"required"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Location    string             `bson:"location,omitempty" json:"location,omitempty"`
+	StartsAt    time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt      time.Time          `bson:"ends_at" json:"ends_at"`
+	TimeZone    string             `bson:"time_zone,omitempty" json:"time_zone,omitempty"` // IANA name reminders are written in; defaults to UTC
+	RemindAt    *time.Time         `bson:"remind_at,omitempty" json:"remind_at,omitempty"` // Nil when reminders are off
+	RemindedAt  *time.Time         `bson:"reminded_at,omitempty" json:"reminded_at,omitempty"`
+	RSVPs       []HouseEventRSVP   `bson:"rsvps" json:"rsvps"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// QuietHourConflict is a member whose quiet hours a house event runs into
+type QuietHourConflict struct {
+	UserID     primitive.ObjectID `json:"user_id"`
+	Name       string             `json:"name"`
+	QuietHours QuietHours         `json:"quiet_hours"`
+}
+
+// IsValidHouseEventType checks if the type is a known kind of house event
+func IsValidHouseEventType(eventType HouseEventType) bool {
+	switch eventType {
+	case HouseEventParty, HouseEventMaintenance, HouseEventInspection, HouseEventOther:
+		return true
+	}
+	return false
+}
+
+// IsValidRSVPStatus checks if the status is a known answer to a house event
+func IsValidRSVPStatus(status RSVPStatus) bool {
+	switch status {
+	case RSVPGoing, RSVPMaybe, RSVPNotGoing:
+		return true
+	}
+	return false
+}
+
+// CreateHouseEvent creates an event with its creator going and a reminder the given time before
+// it starts; a zero reminder turns reminders off
+func CreateHouseEvent(groupID, createdBy primitive.ObjectID, eventType HouseEventType, title string, startsAt, endsAt time.Time, timeZone string, reminder time.Duration) *HouseEvent {
+	now := time.Now()
+	event := &HouseEvent{
+		GroupID:   groupID,
+		Type:      eventType,
+		Title:     title,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		TimeZone:  timeZone,
+		RSVPs:     []HouseEventRSVP{{UserID: createdBy, Status: RSVPGoing, RespondedAt: now}},
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if reminder > 0 {
+		remindAt := startsAt.Add(-reminder)
+		event.RemindAt = &remindAt
+	}
+	return event
+}
+
+// ValidateHouseEventTimes checks that an event starts after now, ends after it starts and does not
+// run longer than MaxHouseEventDuration
+func ValidateHouseEventTimes(startsAt, endsAt, now time.Time) error {
+	switch {
+	case startsAt.IsZero() || endsAt.IsZero():
+		return errors.New("start and end times are required")
+	case !startsAt.After(now):
+		return errors.New("events cannot start in the past")
+	case !endsAt.After(startsAt):
+		return errors.New("events must end after they start")
+	case endsAt.Sub(startsAt) > MaxHouseEventDuration:
+		return fmt.Errorf("events cannot last longer than %d days", int(MaxHouseEventDuration.Hours()/24))
+	}
+	return nil
+}
+
+// RSVP returns the member's answer, or "" if they have not answered
+func (e *HouseEvent) RSVP(userID primitive.ObjectID) RSVPStatus {
+	for _, rsvp := range e.RSVPs {
+		if rsvp.UserID == userID {
+			return rsvp.Status
+		}
+	}
+	return ""
+}
+
+// SetRSVP records the member's answer, replacing an earlier one
+func (e *HouseEvent) SetRSVP(userID primitive.ObjectID, status RSVPStatus, now time.Time) {
+	for i := range e.RSVPs {
+		if e.RSVPs[i].UserID == userID {
+			e.RSVPs[i].Status = status
+			e.RSVPs[i].RespondedAt = now
+			return
+		}
+	}
+	e.RSVPs = append(e.RSVPs, HouseEventRSVP{UserID: userID, Status: status, RespondedAt: now})
+}
+
+// zone returns the time zone the event's times are shown in
+func (e *HouseEvent) zone() *time.Location {
+	if location, err := time.LoadLocation(e.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// QuietHourConflicts returns the members other than the event's creator whose quiet hours the
+// event runs into
+func (e *HouseEvent) QuietHourConflicts(members []User) []QuietHourConflict {
+	conflicts := make([]QuietHourConflict, 0)
+	for _, member := range members {
+		quietHours := member.Preferences.Notifications.QuietHours
+		if member.ID == e.CreatedBy || !quietHours.Overlaps(e.StartsAt, e.EndsAt) {
+			continue
+		}
+		conflicts = append(conflicts, QuietHourConflict{UserID: member.ID, Name: member.Name, QuietHours: quietHours})
+	}
+	return conflicts
+}
+
+// params are the template values describing the event, with its start in the event's time zone
+func (e *HouseEvent) params() NotificationParams {
+	start := e.StartsAt.In(e.zone())
+	return NotificationParams{
+		"event":    e.Title,
+		"date":     NotificationDate(start),
+		"time":     start.Format("15:04"),
+		"location": e.Location,
+	}
+}
+
+// HouseEventCreatedNotification tells a member that a housemate added the event
+func HouseEventCreatedNotification(event *HouseEvent, userID primitive.ObjectID, creatorName string) *Notification {
+	params := event.params()
+	params["name"] = creatorName
+	return CreateTemplatedNotification(userID, event.GroupID, NotificationTypeHouseEventCreated, TemplateHouseEventCreated, params)
+}
+
+// HouseEventReminderNotification reminds a member that the event is about to start
+func HouseEventReminderNotification(event *HouseEvent, userID primitive.ObjectID) *Notification {
+	return CreateTemplatedNotification(userID, event.GroupID, NotificationTypeHouseEventReminder, TemplateHouseEventReminder, event.params())
+}
+
+// CalendarEvent is the event in a member's calendar feed
+func (e *HouseEvent) CalendarEvent() CalendarEvent {
+	description := e.Description
+	if label, ok := houseEventLabels[e.Type]; ok {
+		description = strings.TrimSpace(label + "\n\n" + e.Description)
+	}
+	return CalendarEvent{
+		UID:         "event-" + e.ID.Hex() + "@cribb",
+		Summary:     e.Title,
+		Description: description,
+		Location:    e.Location,
+		Start:       e.StartsAt,
+		End:         e.EndsAt,
+	}
+}
@@ -0,0 +1,134 @@
+// models/house_rules.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationTypeHouseRulesUpdated asks members to read and accept a new version of the rules
+const NotificationTypeHouseRulesUpdated NotificationType = "house_rules_updated"
+
+// Limits on house rules
+const (
+	MaxHouseRulesLength     = 20000
+	MaxHouseRulesNoteLength = 200
+)
+
+// HouseRules is one version of a group's house rules. Every edit adds a new version, and the
+// highest version is the one in force.
+type HouseRules struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Version   int                `bson:"version" json:"version"`
+	Body      string             `bson:"body" json:"body" validate:"required"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"` // What changed since the last version
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// HouseRulesAcknowledgment records that a member read and accepted a version of the rules
+type HouseRulesAcknowledgment struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID        primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Version        int                `bson:"version" json:"version"`
+	AcknowledgedAt time.Time          `bson:"acknowledged_at" json:"acknowledged_at"`
+}
+
+// MemberAcknowledgment is whether a member has accepted the rules in force, and the latest
+// version they did accept
+type MemberAcknowledgment struct {
+	UserID         primitive.ObjectID `json:"user_id"`
+	Name           string             `json:"name"`
+	Acknowledged   bool               `json:"acknowledged"`              // Accepted the version in force
+	LatestVersion  int                `json:"latest_version,omitempty"`  // Latest version accepted, if any
+	AcknowledgedAt *time.Time         `json:"acknowledged_at,omitempty"` // When they accepted it
+}
+
+// ValidateHouseRules checks that the rules have text and that they and the note fit the limits
+func ValidateHouseRules(body, note string) error {
+	switch {
+	case strings.TrimSpace(body) == "":
+		return errors.New("rules cannot be empty")
+	case utf8.RuneCountInString(strings.TrimSpace(body)) > MaxHouseRulesLength:
+		return fmt.Errorf("rules can be at most %d characters", MaxHouseRulesLength)
+	case utf8.RuneCountInString(strings.TrimSpace(note)) > MaxHouseRulesNoteLength:
+		return fmt.Errorf("notes can be at most %d characters", MaxHouseRulesNoteLength)
+	}
+	return nil
+}
+
+// CreateHouseRules creates the version of the rules after the current one, or the first version
+// when the group has none
+func CreateHouseRules(groupID, createdBy primitive.ObjectID, current *HouseRules, body, note string) *HouseRules {
+	version := 1
+	if current != nil {
+		version = current.Version + 1
+	}
+	return &HouseRules{
+		GroupID:   groupID,
+		Version:   version,
+		Body:      strings.TrimSpace(body),
+		Note:      strings.TrimSpace(note),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// CreateHouseRulesAcknowledgment records the member accepting the version of the rules
+func CreateHouseRulesAcknowledgment(rules *HouseRules, userID primitive.ObjectID) *HouseRulesAcknowledgment {
+	return &HouseRulesAcknowledgment{
+		GroupID:        rules.GroupID,
+		UserID:         userID,
+		Version:        rules.Version,
+		AcknowledgedAt: time.Now(),
+	}
+}
+
+// AcknowledgmentStatus lists whether each member has accepted the version in force, members who
+// have not first and then by name
+func AcknowledgmentStatus(members []User, acknowledgments []HouseRulesAcknowledgment, version int) []MemberAcknowledgment {
+	latest := make(map[primitive.ObjectID]HouseRulesAcknowledgment)
+	for _, acknowledgment := range acknowledgments {
+		if previous, ok := latest[acknowledgment.UserID]; !ok || acknowledgment.Version > previous.Version {
+			latest[acknowledgment.UserID] = acknowledgment
+		}
+	}
+
+	status := make([]MemberAcknowledgment, 0, len(members))
+	for _, member := range members {
+		entry := MemberAcknowledgment{UserID: member.ID, Name: member.Name}
+		if acknowledgment, ok := latest[member.ID]; ok {
+			acknowledgedAt := acknowledgment.AcknowledgedAt
+			entry.Acknowledged = acknowledgment.Version >= version
+			entry.LatestVersion = acknowledgment.Version
+			entry.AcknowledgedAt = &acknowledgedAt
+		}
+		status = append(status, entry)
+	}
+	sort.SliceStable(status, func(i, j int) bool {
+		if status[i].Acknowledged != status[j].Acknowledged {
+			return !status[i].Acknowledged
+		}
+		return status[i].Name < status[j].Name
+	})
+	return status
+}
+
+// HouseRulesUpdatedNotification asks a member to read and accept the new version of the rules
+func HouseRulesUpdatedNotification(rules *HouseRules, userID primitive.ObjectID, editorName string) *Notification {
+	params := NotificationParams{
+		"name":    editorName,
+		"version": strconv.Itoa(rules.Version),
+		"note":    rules.Note,
+	}
+	return CreateTemplatedNotification(userID, rules.GroupID, NotificationTypeHouseRulesUpdated, TemplateHouseRulesUpdated, params)
+}
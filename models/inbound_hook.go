@@ -0,0 +1,134 @@
+// models/inbound_hook.go
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxInboundHooksPerGroup caps how many inbound hooks a group can create
+const MaxInboundHooksPerGroup = 10
+
+const inboundHookTokenBytes = 24
+
+// InboundHook is a secret URL automations such as IFTTT applets, Apple Shortcuts or smart-home
+// buttons post commands to. Commands run as the member who created the hook. Only a hash of the
+// token is stored, so it is shown once when the hook is created.
+type InboundHook struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Name       string             `bson:"name" json:"name"`
+	TokenHash  string             `bson:"token_hash" json:"-"`
+	Active     bool               `bson:"active" json:"active"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateInboundHook creates an active inbound hook and returns it with its token
+func CreateInboundHook(groupID, createdBy primitive.ObjectID, name string) (*InboundHook, string, error) {
+	secret := make([]byte, inboundHookTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	token := "cih_" + hex.EncodeToString(secret)
+	now := time.Now()
+	return &InboundHook{
+		GroupID:   groupID,
+		Name:      name,
+		TokenHash: HashInboundHookToken(token),
+		Active:    true,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, token, nil
+}
+
+// HashInboundHookToken returns the hash inbound hooks are looked up by
+func HashInboundHookToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// InboundAction is what an inbound command does
+type InboundAction string
+
+const (
+	InboundActionAddShoppingItem InboundAction = "add_shopping_item"
+	InboundActionCompleteChore   InboundAction = "complete_chore"
+)
+
+// InboundCommand is a command posted to an inbound hook
+type InboundCommand struct {
+	Action   InboundAction `json:"action"`
+	Item     string        `json:"item,omitempty"`     // Item to add to the shopping list
+	Quantity float64       `json:"quantity,omitempty"` // Defaults to 1
+	Unit     string        `json:"unit,omitempty"`
+	Chore    string        `json:"chore,omitempty"` // Title of the chore to complete
+}
+
+// ErrUnknownInboundCommand is returned for commands that cannot be understood
+var ErrUnknownInboundCommand = errors.New(`unknown command; try "add milk to the shopping list" or "complete chore dishes"`)
+
+var (
+	// "add 2 milk to the shopping list", "buy eggs", "put coffee on the grocery list"
+	addCommand = regexp.MustCompile(`^(?:add|buy|put|get)\s+(.+?)(?:\s+(?:to|on|onto)\s+(?:the\s+|my\s+|our\s+)?(?:shopping|grocery|groceries)(?:\s+list)?)?$`)
+	// "complete chore dishes", "mark trash as done", "done with vacuuming", "finished laundry"
+	completeCommand = regexp.MustCompile(`^(?:complete|completed|finish|finished|done(?:\s+with)?|mark)\s+(?:the\s+)?(?:chore\s+)?(.+?)(?:\s+(?:as\s+)?(?:done|complete|completed|finished))?$`)
+)
+
+// ParseInboundCommand reads a command written as a short sentence, as voice assistants and
+// shortcuts send them
+func ParseInboundCommand(text string) (InboundCommand, error) {
+	text = strings.ToLower(strings.Join(strings.Fields(text), " "))
+	text = strings.TrimRight(text, ".!? ")
+
+	if match := completeCommand.FindStringSubmatch(text); match != nil {
+		return InboundCommand{Action: InboundActionCompleteChore, Chore: match[1]}, nil
+	}
+	if match := addCommand.FindStringSubmatch(text); match != nil {
+		command := InboundCommand{Action: InboundActionAddShoppingItem, Item: match[1], Quantity: 1}
+		// A leading number is the quantity: "add 2 milk"
+		if fields := strings.SplitN(command.Item, " ", 2); len(fields) == 2 {
+			if quantity, err := strconv.ParseFloat(fields[0], 64); err == nil && quantity > 0 {
+				command.Quantity, command.Item = quantity, fields[1]
+			}
+		}
+		return command, nil
+	}
+	return InboundCommand{}, ErrUnknownInboundCommand
+}
+
+// Validate checks a command posted as JSON and fills in its defaults
+func (c *InboundCommand) Validate() error {
+	switch c.Action {
+	case InboundActionAddShoppingItem:
+		c.Item = strings.TrimSpace(c.Item)
+		if c.Item == "" {
+			return errors.New("item is required")
+		}
+		if c.Quantity < 0 {
+			return errors.New("quantity cannot be negative")
+		}
+		if c.Quantity == 0 {
+			c.Quantity = 1
+		}
+	case InboundActionCompleteChore:
+		c.Chore = strings.TrimSpace(c.Chore)
+		if c.Chore == "" {
+			return errors.New("chore is required")
+		}
+	default:
+		return errors.New("action must be add_shopping_item or complete_chore")
+	}
+	return nil
+}
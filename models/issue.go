@@ -0,0 +1,163 @@
+// models/issue.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Notification types for the issue log
+const (
+	NotificationTypeIssueReported NotificationType = "issue_reported"
+	NotificationTypeIssueResolved NotificationType = "issue_resolved"
+)
+
+// IssueCategory is what kind of friction an issue is about
+type IssueCategory string
+
+const (
+	IssueCategoryNoise       IssueCategory = "noise"
+	IssueCategoryCleanliness IssueCategory = "cleanliness"
+	IssueCategoryDamage      IssueCategory = "damage"
+	IssueCategoryOther       IssueCategory = "other"
+)
+
+// IssueStatus is where an issue is in being dealt with
+type IssueStatus string
+
+const (
+	IssueStatusOpen         IssueStatus = "open"
+	IssueStatusAcknowledged IssueStatus = "acknowledged" // Someone has seen it and is on it
+	IssueStatusResolved     IssueStatus = "resolved"
+)
+
+// Limits on issues
+const (
+	MaxIssueDescriptionLength = 2000
+	MaxIssueNoteLength        = 500
+)
+
+// Issue is a problem in the home a member logged so it gets dealt with. The reporter of an
+// anonymous issue is kept so they can still edit it, but is never shown to anyone else.
+type Issue struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID        primitive.ObjectID  `bson:"group_id" json:"group_id" validate:"required"`
+	ReportedBy     primitive.ObjectID  `bson:"reported_by" json:"-"`
+	Anonymous      bool                `bson:"anonymous" json:"anonymous"`
+	Category       IssueCategory       `bson:"category" json:"category" validate:"required"`
+	Description    string              `bson:"description" json:"description" validate:"required"`
+	PhotoID        *primitive.ObjectID `bson:"photo_id,omitempty" json:"photo_id,omitempty"`
+	Status         IssueStatus         `bson:"status" json:"status"`
+	AcknowledgedBy *primitive.ObjectID `bson:"acknowledged_by,omitempty" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time          `bson:"acknowledged_at,omitempty" json:"acknowledged_at,omitempty"`
+	ResolvedBy     *primitive.ObjectID `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time          `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	Resolution     string              `bson:"resolution,omitempty" json:"resolution,omitempty"` // How it was resolved
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// IsValidIssueCategory checks if the category is one issues can be logged under
+func IsValidIssueCategory(category IssueCategory) bool {
+	switch category {
+	case IssueCategoryNoise, IssueCategoryCleanliness, IssueCategoryDamage, IssueCategoryOther:
+		return true
+	}
+	return false
+}
+
+// ValidateIssue checks the category and that the description has text within the limit
+func ValidateIssue(category IssueCategory, description string) error {
+	switch {
+	case !IsValidIssueCategory(category):
+		return errors.New("category must be noise, cleanliness, damage or other")
+	case strings.TrimSpace(description) == "":
+		return errors.New("description cannot be empty")
+	case utf8.RuneCountInString(strings.TrimSpace(description)) > MaxIssueDescriptionLength:
+		return fmt.Errorf("description can be at most %d characters", MaxIssueDescriptionLength)
+	}
+	return nil
+}
+
+// CreateIssue logs an open issue reported by the member
+func CreateIssue(groupID, reportedBy primitive.ObjectID, category IssueCategory, description string, anonymous bool) *Issue {
+	now := time.Now()
+	return &Issue{
+		GroupID:     groupID,
+		ReportedBy:  reportedBy,
+		Anonymous:   anonymous,
+		Category:    category,
+		Description: strings.TrimSpace(description),
+		Status:      IssueStatusOpen,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Reporter returns who reported the issue as the member sees it: nil when it is anonymous and
+// they are not the reporter
+func (i *Issue) Reporter(viewerID primitive.ObjectID) *primitive.ObjectID {
+	if i.Anonymous && i.ReportedBy != viewerID {
+		return nil
+	}
+	reporter := i.ReportedBy
+	return &reporter
+}
+
+// SetStatus moves the issue to the status on behalf of the member. Resolved issues can be
+// reopened, which clears who acknowledged and resolved them.
+func (i *Issue) SetStatus(status IssueStatus, userID primitive.ObjectID, note string, now time.Time) error {
+	if utf8.RuneCountInString(strings.TrimSpace(note)) > MaxIssueNoteLength {
+		return fmt.Errorf("notes can be at most %d characters", MaxIssueNoteLength)
+	}
+	if status == i.Status {
+		return fmt.Errorf("issue is already %s", status)
+	}
+
+	switch status {
+	case IssueStatusOpen:
+		i.AcknowledgedBy, i.AcknowledgedAt = nil, nil
+		i.ResolvedBy, i.ResolvedAt, i.Resolution = nil, nil, ""
+	case IssueStatusAcknowledged:
+		if i.Status == IssueStatusResolved {
+			return errors.New("resolved issues must be reopened first")
+		}
+		i.AcknowledgedBy, i.AcknowledgedAt = &userID, &now
+	case IssueStatusResolved:
+		i.ResolvedBy, i.ResolvedAt, i.Resolution = &userID, &now, strings.TrimSpace(note)
+	default:
+		return errors.New("status must be open, acknowledged or resolved")
+	}
+	i.Status = status
+	i.UpdatedAt = now
+	return nil
+}
+
+// IssueReportedNotification tells a member about a new issue, leaving out who logged an
+// anonymous one
+func IssueReportedNotification(issue *Issue, userID primitive.ObjectID, reporterName string) *Notification {
+	params := NotificationParams{
+		"category":    string(issue.Category),
+		"description": MessagePreview(issue.Description),
+	}
+	if !issue.Anonymous {
+		params["name"] = reporterName
+	}
+	return CreateTemplatedNotification(userID, issue.GroupID, NotificationTypeIssueReported, TemplateIssueReported, params)
+}
+
+// IssueResolvedNotification tells the reporter their issue was resolved
+func IssueResolvedNotification(issue *Issue, resolverName string) *Notification {
+	params := NotificationParams{
+		"name":        resolverName,
+		"category":    string(issue.Category),
+		"description": MessagePreview(issue.Description),
+		"resolution":  issue.Resolution,
+	}
+	return CreateTemplatedNotification(issue.ReportedBy, issue.GroupID, NotificationTypeIssueResolved, TemplateIssueResolved, params)
+}
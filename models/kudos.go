@@ -0,0 +1,153 @@
+// models/kudos.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationTypeKudosReceived tells a member someone thanked them
+const NotificationTypeKudosReceived NotificationType = "kudos_received"
+
+// ScoreEventKudos is points a member received with kudos
+const ScoreEventKudos ScoreEventType = "kudos"
+
+// Limits on kudos
+const (
+	MaxKudosMessageLength = 280
+	MaxKudosPoints        = 5    // Most points a single kudos can carry
+	MaxKudosBudget        = 1000 // Highest monthly kudos budget a group can set
+)
+
+// ErrKudosBudgetSpent is returned when the group's kudos budget for the month cannot cover the points
+var ErrKudosBudgetSpent = errors.New("the group's kudos budget for this month is spent")
+
+// Kudos is thanks one member sent another for going above and beyond, optionally with a few bonus
+// points drawn from the group's monthly kudos budget
+type Kudos struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	FromID    primitive.ObjectID `bson:"from_id" json:"from_id" validate:"required"`
+	ToID      primitive.ObjectID `bson:"to_id" json:"to_id" validate:"required"`
+	Message   string             `bson:"message" json:"message" validate:"required"`
+	Points    int                `bson:"points" json:"points"`
+	Month     string             `bson:"month" json:"month"` // YYYY-MM in UTC, the budget the points came from
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// KudosBudget tracks how many of its kudos points a group has handed out in a month
+type KudosBudget struct {
+	GroupID primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Month   string             `bson:"month" json:"month"`
+	Used    int                `bson:"used" json:"used"`
+}
+
+// KudosMemberTotal is how many kudos a member sent and received in a month
+type KudosMemberTotal struct {
+	UserID   primitive.ObjectID `json:"user_id"`
+	Received int                `json:"received"`
+	Sent     int                `json:"sent"`
+	Points   int                `json:"points"` // Bonus points received
+}
+
+// KudosSummary sums up a group's kudos over a month
+type KudosSummary struct {
+	Month        string              `json:"month"` // YYYY-MM
+	Kudos        int                 `json:"kudos"`
+	Budget       int                 `json:"budget"`
+	PointsGiven  int                 `json:"points_given"`
+	TopRecipient *primitive.ObjectID `json:"top_recipient,omitempty"` // Most kudos received, if anyone got any
+	Members      []KudosMemberTotal  `json:"members"`                 // Most kudos received first
+}
+
+// KudosMonth returns the month whose budget kudos sent at t draw from
+func KudosMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// ValidateKudos checks that the member thanks someone else, says what for and asks for no more
+// points than a kudos can carry
+func ValidateKudos(fromID, toID primitive.ObjectID, message string, points int) error {
+	switch {
+	case fromID == toID:
+		return errors.New("you cannot send kudos to yourself")
+	case strings.TrimSpace(message) == "":
+		return errors.New("say what the kudos are for")
+	case utf8.RuneCountInString(strings.TrimSpace(message)) > MaxKudosMessageLength:
+		return fmt.Errorf("messages can be at most %d characters", MaxKudosMessageLength)
+	case points < 0 || points > MaxKudosPoints:
+		return fmt.Errorf("kudos can carry between 0 and %d points", MaxKudosPoints)
+	}
+	return nil
+}
+
+// CreateKudos creates kudos from one member to another
+func CreateKudos(groupID, fromID, toID primitive.ObjectID, message string, points int) *Kudos {
+	now := time.Now()
+	return &Kudos{
+		GroupID:   groupID,
+		FromID:    fromID,
+		ToID:      toID,
+		Message:   strings.TrimSpace(message),
+		Points:    points,
+		Month:     KudosMonth(now),
+		CreatedAt: now,
+	}
+}
+
+// SummarizeKudos totals the month's kudos per member, including members who neither sent nor
+// received any, with the most thanked first
+func SummarizeKudos(month string, budget int, members []primitive.ObjectID, kudos []Kudos) KudosSummary {
+	summary := KudosSummary{Month: month, Budget: budget, Members: make([]KudosMemberTotal, 0, len(members))}
+	totals := make(map[primitive.ObjectID]int, len(members))
+	total := func(userID primitive.ObjectID) *KudosMemberTotal {
+		i, ok := totals[userID]
+		if !ok {
+			i = len(summary.Members)
+			totals[userID] = i
+			summary.Members = append(summary.Members, KudosMemberTotal{UserID: userID})
+		}
+		return &summary.Members[i]
+	}
+	for _, memberID := range members {
+		total(memberID)
+	}
+
+	for _, k := range kudos {
+		summary.Kudos++
+		summary.PointsGiven += k.Points
+		total(k.FromID).Sent++
+		recipient := total(k.ToID)
+		recipient.Received++
+		recipient.Points += k.Points
+	}
+
+	sort.SliceStable(summary.Members, func(i, j int) bool {
+		if summary.Members[i].Received != summary.Members[j].Received {
+			return summary.Members[i].Received > summary.Members[j].Received
+		}
+		return summary.Members[i].Points > summary.Members[j].Points
+	})
+	if len(summary.Members) > 0 && summary.Members[0].Received > 0 {
+		top := summary.Members[0].UserID
+		summary.TopRecipient = &top
+	}
+	return summary
+}
+
+// KudosReceivedNotification tells the member who thanked them and for what
+func KudosReceivedNotification(kudos *Kudos, senderName string) *Notification {
+	params := NotificationParams{
+		"name":    senderName,
+		"message": MessagePreview(kudos.Message),
+		"points":  strconv.Itoa(kudos.Points),
+	}
+	return CreateTemplatedNotification(kudos.ToID, kudos.GroupID, NotificationTypeKudosReceived, TemplateKudosReceived, params)
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LeaderboardPeriod is the window a leaderboard covers
+type LeaderboardPeriod string
+
+const (
+	LeaderboardPeriodWeekly  LeaderboardPeriod = "weekly"
+	LeaderboardPeriodMonthly LeaderboardPeriod = "monthly"
+	LeaderboardPeriodAllTime LeaderboardPeriod = "all_time"
+)
+
+// LeaderboardEntry is one member's standing within a period
+type LeaderboardEntry struct {
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Username    string             `bson:"username" json:"username"`
+	Name        string             `bson:"name" json:"name"`
+	Points      int                `bson:"points" json:"points"`
+	Completions int                `bson:"completions" json:"completions"`
+}
+
+// LeaderboardSnapshot archives the final standings of a finished period
+type LeaderboardSnapshot struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	Period      LeaderboardPeriod   `bson:"period" json:"period"`
+	PeriodStart time.Time           `bson:"period_start" json:"period_start"`
+	PeriodEnd   time.Time           `bson:"period_end" json:"period_end"`
+	WinnerID    *primitive.ObjectID `bson:"winner_id,omitempty" json:"winner_id,omitempty"`
+	Entries     []LeaderboardEntry  `bson:"entries" json:"entries"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// PeriodBounds returns the start (inclusive) and end (exclusive) in UTC of the period containing t
+func PeriodBounds(period LeaderboardPeriod, t time.Time) (time.Time, time.Time) {
+	switch period {
+	case LeaderboardPeriodWeekly:
+		start := WeekStartUTC(t)
+		return start, start.AddDate(0, 0, 7)
+	case LeaderboardPeriodMonthly:
+		year, month, _ := t.UTC().Date()
+		start := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		return time.Time{}, t
+	}
+}
+
+// PeriodScoreEventTypes are the score changes that also apply to the weekly and monthly scores
+var PeriodScoreEventTypes = []ScoreEventType{ScoreEventCompletion, ScoreEventPenalty, ScoreEventKudos}
+
+// ScoreField returns the user field holding the running score of the period
+func (p LeaderboardPeriod) ScoreField() string {
+	switch p {
+	case LeaderboardPeriodWeekly:
+		return "weekly_score"
+	case LeaderboardPeriodMonthly:
+		return "monthly_score"
+	default:
+		return "score"
+	}
+}
+
+// PeriodScoresSince totals each member's period points from events at or after since. When the
+// period ending at since is reset, these are the points the next period has already earned.
+func PeriodScoresSince(events []ScoreEvent, since time.Time) map[primitive.ObjectID]int {
+	scores := make(map[primitive.ObjectID]int)
+	for _, event := range events {
+		if event.CreatedAt.Before(since) || !slices.Contains(PeriodScoreEventTypes, event.Type) {
+			continue
+		}
+		scores[event.UserID] += event.Delta
+	}
+	return scores
+}
+
+// SortLeaderboard orders entries by points, then completions, then username
+func SortLeaderboard(entries []LeaderboardEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Points != entries[j].Points {
+			return entries[i].Points > entries[j].Points
+		}
+		if entries[i].Completions != entries[j].Completions {
+			return entries[i].Completions > entries[j].Completions
+		}
+		return entries[i].Username < entries[j].Username
+	})
+}
+
+// CreateLeaderboardSnapshot archives sorted standings, naming the top scorer as winner if anyone scored
+func CreateLeaderboardSnapshot(groupID primitive.ObjectID, period LeaderboardPeriod, start, end time.Time, entries []LeaderboardEntry) *LeaderboardSnapshot {
+	SortLeaderboard(entries)
+
+	snapshot := &LeaderboardSnapshot{
+		GroupID:     groupID,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Entries:     entries,
+		CreatedAt:   time.Now(),
+	}
+	if len(entries) > 0 && entries[0].Points > 0 {
+		winner := entries[0].UserID
+		snapshot.WinnerID = &winner
+	}
+	return snapshot
+}
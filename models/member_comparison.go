@@ -0,0 +1,26 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MemberComparison holds one member's stats for a head-to-head comparison
+type MemberComparison struct {
+	UserID      primitive.ObjectID `bson:"_id" json:"user_id"`
+	Username    string             `bson:"username" json:"username"`
+	Name        string             `bson:"name" json:"name"`
+	Completions int                `bson:"completions" json:"completions"`
+	Points      int                `bson:"points" json:"points"`
+	// OnTime counts completions made by their due date; Rated counts completions whose due date is still known
+	OnTime     int         `bson:"on_time" json:"on_time"`
+	Rated      int         `bson:"rated" json:"-"`
+	OnTimeRate float64     `bson:"-" json:"on_time_rate"`
+	Streak     StreakStats `bson:"streak" json:"streak"`
+}
+
+// ComputeOnTimeRate sets OnTimeRate to the share of rated completions made on time, 0 when none are rated
+func (m *MemberComparison) ComputeOnTimeRate() {
+	if m.Rated == 0 {
+		m.OnTimeRate = 0
+		return
+	}
+	m.OnTimeRate = float64(m.OnTime) / float64(m.Rated)
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch (RFC 7386) request bodies
+const MergePatchContentType = "application/merge-patch+json"
+
+// MergePatch is a JSON Merge Patch: the members of a resource to change, each with its new value.
+// A null value removes the member, which clears the field.
+type MergePatch map[string]json.RawMessage
+
+// ParseMergePatch reads a merge patch. Patches that are not a JSON object would replace the
+// whole resource, so they are rejected.
+func ParseMergePatch(data []byte) (MergePatch, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || data[0] != '{' {
+		return nil, errors.New("a merge patch must be a JSON object")
+	}
+	var patch MergePatch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, errors.New("invalid merge patch: " + err.Error())
+	}
+	return patch, nil
+}
+
+// Has reports whether the patch changes the member
+func (p MergePatch) Has(name string) bool {
+	_, ok := p[name]
+	return ok
+}
+
+// IsNull reports whether the patch clears the member
+func (p MergePatch) IsNull(name string) bool {
+	value, ok := p[name]
+	return ok && isJSONNull(value)
+}
+
+// Allow checks that the patch only changes the given members
+func (p MergePatch) Allow(names ...string) error {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	for _, name := range p.members() {
+		if !allowed[name] {
+			return fmt.Errorf("%s cannot be changed", name)
+		}
+	}
+	return nil
+}
+
+// Require checks that the patch does not clear any of the given members
+func (p MergePatch) Require(names ...string) error {
+	for _, name := range names {
+		if p.IsNull(name) {
+			return fmt.Errorf("%s cannot be cleared", name)
+		}
+	}
+	return nil
+}
+
+// Apply merges the patch into the JSON form of the document, which must be a pointer. Members
+// the patch clears, at any depth, are left at their zero value.
+func (p MergePatch) Apply(document interface{}) error {
+	value := reflect.ValueOf(document)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("merge patches apply to a pointer")
+	}
+	target, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	merged, err := ApplyMergePatch(target, patch)
+	if err != nil {
+		return err
+	}
+	value.Elem().Set(reflect.Zero(value.Elem().Type()))
+	if err := json.Unmarshal(merged, document); err != nil {
+		return errors.New("invalid merge patch: " + err.Error())
+	}
+	return nil
+}
+
+// members returns the names of the members the patch changes, sorted so errors are stable
+func (p MergePatch) members() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyMergePatch applies a JSON Merge Patch to a JSON document as RFC 7386 describes: objects
+// are merged member by member, null removes a member and any other value replaces the target.
+func ApplyMergePatch(target, patch []byte) ([]byte, error) {
+	var targetValue, patchValue interface{}
+	if len(bytes.TrimSpace(target)) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(targetValue, patchValue))
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = make(map[string]interface{}, len(patchObject))
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergeValue(targetObject[name], value)
+	}
+	return targetObject
+}
+
+func isJSONNull(value json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+}
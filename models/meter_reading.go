@@ -0,0 +1,201 @@
+// models/meter_reading.go
+package models
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MeterType is the utility a meter measures
+type MeterType string
+
+const (
+	MeterElectricity MeterType = "electricity" // kWh
+	MeterGas         MeterType = "gas"         // Cubic meters or kWh, whatever the meter shows
+	MeterWater       MeterType = "water"       // Cubic meters or gallons
+)
+
+// IsValidMeterType checks if readings can be recorded for the meter type
+func IsValidMeterType(meter MeterType) bool {
+	return meter == MeterElectricity || meter == MeterGas || meter == MeterWater
+}
+
+// MeterReading is what a utility meter showed at a point in time. Readings with a UserID come
+// from the sub-meter of that member's room, readings without one from the home's main meter.
+type MeterReading struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID        primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	Meter          MeterType           `bson:"meter" json:"meter"`
+	UserID         primitive.ObjectID  `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	Value          float64             `bson:"value" json:"value"`
+	ReadAt         time.Time           `bson:"read_at" json:"read_at"`
+	PhotoID        *primitive.ObjectID `bson:"photo_id,omitempty" json:"photo_id,omitempty"` // Picture of the meter as proof of the reading
+	Note           string              `bson:"note,omitempty" json:"note,omitempty"`
+	RecordedBy     primitive.ObjectID  `bson:"recorded_by" json:"recorded_by"`
+	RecordedByName string              `bson:"recorded_by_name" json:"recorded_by_name"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// SameMeter reports whether two readings are of the same physical meter
+func (r MeterReading) SameMeter(other MeterReading) bool {
+	return r.GroupID == other.GroupID && r.Meter == other.Meter && r.UserID == other.UserID
+}
+
+// CheckMeterReading verifies that a new reading fits between the readings already recorded for
+// its meter, since meters only count up
+func CheckMeterReading(readings []MeterReading, reading MeterReading) error {
+	if reading.Value < 0 {
+		return errors.New("meter reading cannot be negative")
+	}
+	for _, existing := range readings {
+		if !existing.SameMeter(reading) {
+			continue
+		}
+		if existing.ReadAt.Equal(reading.ReadAt) {
+			return errors.New("the meter already has a reading at that time")
+		}
+		if existing.ReadAt.Before(reading.ReadAt) && existing.Value > reading.Value {
+			return errors.New("meter reading is lower than an earlier reading")
+		}
+		if existing.ReadAt.After(reading.ReadAt) && existing.Value < reading.Value {
+			return errors.New("meter reading is higher than a later reading")
+		}
+	}
+	return nil
+}
+
+// MeterUsage is how much of a utility one meter counted over a period, from the readings closest
+// to its start and end
+type MeterUsage struct {
+	UserID     primitive.ObjectID `json:"user_id,omitempty"` // Empty for the home's main meter
+	Name       string             `json:"name,omitempty"`
+	StartValue float64            `json:"start_value"`
+	StartAt    time.Time          `json:"start_at"`
+	EndValue   float64            `json:"end_value"`
+	EndAt      time.Time          `json:"end_at"`
+	Usage      float64            `json:"usage"`
+	Complete   bool               `json:"complete"` // Whether readings cover the whole period
+}
+
+// MeterConsumption works out the usage of every meter with readings over the period from..to.
+// Each meter starts at its last reading on or before from, or its first reading in the period,
+// and ends at its last reading on or before to. Readings must all be of one meter type.
+func MeterConsumption(readings []MeterReading, from, to time.Time) []MeterUsage {
+	byMeter := make(map[primitive.ObjectID][]MeterReading)
+	order := make([]primitive.ObjectID, 0)
+	for _, reading := range readings {
+		if reading.ReadAt.After(to) {
+			continue
+		}
+		if _, ok := byMeter[reading.UserID]; !ok {
+			order = append(order, reading.UserID)
+		}
+		byMeter[reading.UserID] = append(byMeter[reading.UserID], reading)
+	}
+
+	usages := make([]MeterUsage, 0, len(order))
+	for _, userID := range order {
+		meterReadings := byMeter[userID]
+		sort.SliceStable(meterReadings, func(i, j int) bool { return meterReadings[i].ReadAt.Before(meterReadings[j].ReadAt) })
+
+		start := -1
+		for i, reading := range meterReadings {
+			if !reading.ReadAt.After(from) || start == -1 {
+				start = i
+			}
+			if reading.ReadAt.After(from) {
+				break
+			}
+		}
+		end := len(meterReadings) - 1
+		if !meterReadings[end].ReadAt.After(from) {
+			// Nothing was read during the period
+			continue
+		}
+
+		usage := MeterUsage{
+			UserID:     userID,
+			StartValue: meterReadings[start].Value,
+			StartAt:    meterReadings[start].ReadAt,
+			EndValue:   meterReadings[end].Value,
+			EndAt:      meterReadings[end].ReadAt,
+		}
+		usage.Usage = math.Max(0, usage.EndValue-usage.StartValue)
+		usage.Complete = !usage.StartAt.After(from)
+		usages = append(usages, usage)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool { return usages[i].UserID.Hex() < usages[j].UserID.Hex() })
+	return usages
+}
+
+// BillingPeriod returns the period an occurrence of the bill due on dueDate pays for: from the
+// previous due date up to this one
+func (b *RecurringBill) BillingPeriod(dueDate time.Time) (time.Time, time.Time) {
+	switch b.Frequency {
+	case "weekly":
+		return dueDate.AddDate(0, 0, -7), dueDate
+	case "biweekly":
+		return dueDate.AddDate(0, 0, -14), dueDate
+	default:
+		return dueDate.AddDate(0, -1, 0), dueDate
+	}
+}
+
+// ApplyMeterUsage splits the bill between its participants, or all members when it has none, in
+// proportion to the usage their room's meter counted. It leaves the bill's own split and returns
+// false when a member's readings do not cover the period or nobody used anything.
+func (b *RecurringBill) ApplyMeterUsage(members []primitive.ObjectID, usages []MeterUsage) bool {
+	sharing := b.sharingMembers(members)
+	if len(sharing) == 0 {
+		return false
+	}
+
+	byUser := make(map[primitive.ObjectID]MeterUsage, len(usages))
+	for _, usage := range usages {
+		byUser[usage.UserID] = usage
+	}
+	total := 0.0
+	for _, userID := range sharing {
+		usage, ok := byUser[userID]
+		if !ok || !usage.Complete {
+			return false
+		}
+		total += usage.Usage
+	}
+	if total <= 0 {
+		return false
+	}
+
+	b.SplitMethod = SplitMethodPercentage
+	b.Participants = make([]BillParticipant, 0, len(sharing))
+	for _, userID := range sharing {
+		b.Participants = append(b.Participants, BillParticipant{UserID: userID, Percentage: byUser[userID].Usage / total * 100})
+	}
+	return true
+}
+
+// LoadMeterReadings fetches the meter readings matching filter, oldest first
+func LoadMeterReadings(ctx context.Context, db *mongo.Database, filter bson.M) ([]MeterReading, error) {
+	cursor, err := db.Collection("meter_readings").Find(
+		ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "read_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	readings := make([]MeterReading, 0)
+	if err := cursor.All(ctx, &readings); err != nil {
+		return nil, err
+	}
+	return readings, nil
+}
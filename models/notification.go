@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// NotificationTypeRecurringChoreEnded indicates a recurring chore finished its run and was deactivated
+	NotificationTypeRecurringChoreEnded NotificationType = "recurring_chore_ended"
+
+	// NotificationTypeBillDue reminds a member of their share of an upcoming recurring bill
+	NotificationTypeBillDue NotificationType = "bill_due"
+
+	// NotificationTypeChoreAssigned tells a member a chore was assigned to them
+	NotificationTypeChoreAssigned NotificationType = "chore_assigned"
+
+	// NotificationTypeChoreCompleted tells the rest of the group a member finished a chore
+	NotificationTypeChoreCompleted NotificationType = "chore_completed"
+
+	// NotificationTypeItemPurchased tells a member that items they put on the shopping list were bought
+	NotificationTypeItemPurchased NotificationType = "item_purchased"
+
+	// NotificationTypeExpenseAdded tells a member they have a share of a new expense
+	NotificationTypeExpenseAdded NotificationType = "expense_added"
+)
+
+// Notification represents a message addressed to a single user. Notifications built from a
+// template keep it and its params, so they can be shown in the recipient's locale; Title and
+// Message hold the default locale's copy.
+type Notification struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID     `bson:"user_id" json:"user_id" validate:"required"`
+	GroupID   primitive.ObjectID     `bson:"group_id,omitempty" json:"group_id,omitempty"`
+	Type      NotificationType       `bson:"type" json:"type" validate:"required"`
+	Title     string                 `bson:"title" json:"title"`
+	Message   string                 `bson:"message" json:"message"`
+	Template  NotificationTemplateID `bson:"template,omitempty" json:"-"`
+	Params    NotificationParams     `bson:"params,omitempty" json:"-"`
+	Read      bool                   `bson:"read" json:"read"`
+	ReadAt    *time.Time             `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt time.Time              `bson:"created_at" json:"created_at"`
+	PushedAt  *time.Time             `bson:"pushed_at,omitempty" json:"-"` // When the notification was sent to the member's devices
+}
+
+// CreateNotification creates a new unread notification for a user
+func CreateNotification(
+	userID primitive.ObjectID,
+	groupID primitive.ObjectID,
+	notificationType NotificationType,
+	title string,
+	message string,
+) *Notification {
+	return &Notification{
+		UserID:    userID,
+		GroupID:   groupID,
+		Type:      notificationType,
+		Title:     title,
+		Message:   message,
+		Read:      false,
+		CreatedAt: time.Now(),
+	}
+}
+
+// CreateTemplatedNotification creates a new unread notification for a user from the template
+// registry, see RenderNotification
+func CreateTemplatedNotification(
+	userID primitive.ObjectID,
+	groupID primitive.ObjectID,
+	notificationType NotificationType,
+	templateID NotificationTemplateID,
+	params NotificationParams,
+) *Notification {
+	title, message := RenderNotification(templateID, DefaultLocale, params)
+	notification := CreateNotification(userID, groupID, notificationType, title, message)
+	notification.Template = templateID
+	notification.Params = params
+	return notification
+}
+
+// Localize rewrites the notification's title and message in the locale. Notifications not built
+// from a template are left as they are.
+func (n *Notification) Localize(locale Locale) {
+	if n.Template == "" {
+		return
+	}
+	if title, message := RenderNotification(n.Template, locale, n.Params); message != "" {
+		n.Title, n.Message = title, message
+	}
+}
+
+// ChoreCompletedNotifications tells the group's members other than the one who did it that a
+// chore was completed
+func ChoreCompletedNotifications(chore Chore, members []primitive.ObjectID, completer User) []*Notification {
+	notifications := make([]*Notification, 0, len(members))
+	for _, memberID := range members {
+		if memberID == completer.ID {
+			continue
+		}
+		notifications = append(notifications, CreateTemplatedNotification(memberID, chore.GroupID, NotificationTypeChoreCompleted,
+			TemplateChoreCompleted, NotificationParams{"name": completer.Name, "chore": chore.Title}))
+	}
+	return notifications
+}
+
+// ItemPurchasedNotifications tells every member who put purchased items on the list, other than
+// the buyer, which of their items were bought
+func ItemPurchasedNotifications(records []*PurchaseRecord, buyer User) []*Notification {
+	items := make(map[primitive.ObjectID][]string)
+	order := make([]primitive.ObjectID, 0)
+	for _, record := range records {
+		if record.RequestedBy.IsZero() || record.RequestedBy == buyer.ID {
+			continue
+		}
+		if _, ok := items[record.RequestedBy]; !ok {
+			order = append(order, record.RequestedBy)
+		}
+		items[record.RequestedBy] = append(items[record.RequestedBy], record.ItemName)
+	}
+
+	notifications := make([]*Notification, 0, len(order))
+	for _, userID := range order {
+		notifications = append(notifications, CreateTemplatedNotification(userID, buyer.GroupID, NotificationTypeItemPurchased,
+			TemplateItemsPurchased, NotificationParams{"name": buyer.Name, "items": NotificationList(items[userID])}))
+	}
+	return notifications
+}
+
+// ExpenseAddedNotifications tells every member with a share of a new expense, other than the one
+// who recorded it, what their share is
+func ExpenseAddedNotifications(expense Expense, creatorName string) []*Notification {
+	notifications := make([]*Notification, 0, len(expense.Splits))
+	for _, share := range expense.Splits {
+		if share.UserID == expense.CreatedBy || share.Amount <= 0 {
+			continue
+		}
+		notifications = append(notifications, CreateTemplatedNotification(share.UserID, expense.GroupID, NotificationTypeExpenseAdded,
+			TemplateExpenseAdded, NotificationParams{
+				"name":     creatorName,
+				"expense":  expense.Description,
+				"amount":   fmt.Sprintf("%.2f", expense.Amount),
+				"share":    fmt.Sprintf("%.2f", share.Amount),
+				"currency": expense.Currency,
+			}))
+	}
+	return notifications
+}
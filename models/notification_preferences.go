@@ -0,0 +1,195 @@
+// models/notification_preferences.go
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NotificationEvent groups the notifications a member can route to channels together
+type NotificationEvent string
+
+const (
+	NotificationEventChoreAssigned  NotificationEvent = "chore_assigned"  // Chores assigned to the member
+	NotificationEventChoreCompleted NotificationEvent = "chore_completed" // Chores and challenges the group finished
+	NotificationEventChoreOverdue   NotificationEvent = "chore_overdue"   // Chores left overdue
+	NotificationEventShopping       NotificationEvent = "shopping"        // Items added to or bought from the shopping list
+	NotificationEventPantry         NotificationEvent = "pantry"          // Pantry items running low or expiring
+	NotificationEventExpenses       NotificationEvent = "expenses"        // New and disputed expenses, and the balance
+	NotificationEventPayments       NotificationEvent = "payments"        // Reminders to pay back what the member owes
+	NotificationEventBills          NotificationEvent = "bills"           // Recurring bills and rent coming due
+	NotificationEventHouseEvents    NotificationEvent = "house_events"    // Parties, visits and inspections added or about to start
+	NotificationEventGuests         NotificationEvent = "guests"          // Overnight guests registered or arriving
+	NotificationEventBookings       NotificationEvent = "bookings"        // Bookings of shared resources cancelled
+	NotificationEventPolls          NotificationEvent = "polls"           // Scheduling and decision polls opened or decided
+	NotificationEventMentions       NotificationEvent = "mentions"        // The member @mentioned in the group chat
+	NotificationEventHouseRules     NotificationEvent = "house_rules"     // New versions of the house rules to accept
+	NotificationEventIssues         NotificationEvent = "issues"          // Issues logged in the home and resolutions of the member's own
+	NotificationEventKudos          NotificationEvent = "kudos"           // Thanks from other members
+)
+
+// NotificationChannel is a way of reaching a member outside the app. Every notification is also
+// kept in the in-app notification center.
+type NotificationChannel string
+
+const (
+	NotificationChannelPush   NotificationChannel = "push"   // Push notifications to the member's devices
+	NotificationChannelEmail  NotificationChannel = "email"  // An email as it happens
+	NotificationChannelSMS    NotificationChannel = "sms"    // A text, for members who opted in to SMS alerts
+	NotificationChannelDigest NotificationChannel = "digest" // A section of the daily or weekly digest
+)
+
+// NotificationEvents lists every event in the order they are shown
+var NotificationEvents = []NotificationEvent{
+	NotificationEventChoreAssigned,
+	NotificationEventChoreCompleted,
+	NotificationEventChoreOverdue,
+	NotificationEventShopping,
+	NotificationEventPantry,
+	NotificationEventExpenses,
+	NotificationEventPayments,
+	NotificationEventBills,
+	NotificationEventHouseEvents,
+	NotificationEventGuests,
+	NotificationEventBookings,
+	NotificationEventPolls,
+	NotificationEventMentions,
+	NotificationEventHouseRules,
+	NotificationEventIssues,
+	NotificationEventKudos,
+}
+
+// NotificationChannels lists every channel
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelPush,
+	NotificationChannelEmail,
+	NotificationChannelSMS,
+	NotificationChannelDigest,
+}
+
+// ChannelSettings turns channels on or off for an event
+type ChannelSettings map[NotificationChannel]bool
+
+// defaultChannels are used for channels a member has not set. Everything is pushed, emails are
+// kept for chores left overdue and texts for the things that cost money or housemates' patience.
+var defaultChannels = map[NotificationEvent]ChannelSettings{
+	NotificationEventChoreAssigned:  {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventChoreCompleted: {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventChoreOverdue:   {NotificationChannelPush: true, NotificationChannelEmail: true, NotificationChannelSMS: true, NotificationChannelDigest: true},
+	NotificationEventShopping:       {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventPantry:         {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventExpenses:       {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventPayments:       {NotificationChannelPush: true},
+	NotificationEventBills:          {NotificationChannelPush: true, NotificationChannelSMS: true},
+	NotificationEventHouseEvents:    {NotificationChannelPush: true},
+	NotificationEventGuests:         {NotificationChannelPush: true},
+	NotificationEventBookings:       {NotificationChannelPush: true},
+	NotificationEventPolls:          {NotificationChannelPush: true},
+	NotificationEventMentions:       {NotificationChannelPush: true},
+	NotificationEventHouseRules:     {NotificationChannelPush: true, NotificationChannelEmail: true},
+	NotificationEventIssues:         {NotificationChannelPush: true, NotificationChannelDigest: true},
+	NotificationEventKudos:          {NotificationChannelPush: true},
+}
+
+// Event returns the event a notification type belongs to, or "" for types no event covers
+func (t NotificationType) Event() NotificationEvent {
+	switch t {
+	case NotificationTypeChoreAssigned, NotificationTypeRecurringChoreEnded, NotificationTypePickupReminder:
+		return NotificationEventChoreAssigned
+	case NotificationTypeChoreCompleted, NotificationTypeChallengeCompleted:
+		return NotificationEventChoreCompleted
+	case NotificationTypeItemPurchased:
+		return NotificationEventShopping
+	case NotificationTypeLowStock, NotificationTypeOutOfStock, NotificationTypeExpiringSoon, NotificationTypeExpired:
+		return NotificationEventPantry
+	case NotificationTypeExpenseAdded, NotificationTypeExpenseDisputed, NotificationTypeExpenseDisputeResolved:
+		return NotificationEventExpenses
+	case NotificationTypePaymentReminder:
+		return NotificationEventPayments
+	case NotificationTypeBillDue:
+		return NotificationEventBills
+	case NotificationTypeHouseEventCreated, NotificationTypeHouseEventReminder:
+		return NotificationEventHouseEvents
+	case NotificationTypeGuestRegistered, NotificationTypeGuestArriving:
+		return NotificationEventGuests
+	case NotificationTypeBookingCancelled:
+		return NotificationEventBookings
+	case NotificationTypePollCreated, NotificationTypePollDecided, NotificationTypeDecisionCreated, NotificationTypeDecisionClosed:
+		return NotificationEventPolls
+	case NotificationTypeChatMention:
+		return NotificationEventMentions
+	case NotificationTypeHouseRulesUpdated:
+		return NotificationEventHouseRules
+	case NotificationTypeIssueReported, NotificationTypeIssueResolved:
+		return NotificationEventIssues
+	case NotificationTypeKudosReceived:
+		return NotificationEventKudos
+	}
+	return ""
+}
+
+// Allows reports whether the member wants the event on the channel. Channels the member set win;
+// otherwise the older mute settings and then the defaults decide. Notifications of no event are
+// pushed but not sent anywhere else.
+func (p NotificationPreferences) Allows(event NotificationEvent, channel NotificationChannel) bool {
+	if allowed, ok := p.Channels[event][channel]; ok {
+		return allowed
+	}
+	if event == NotificationEventPayments && p.MutePaymentReminders {
+		return false
+	}
+	if event == NotificationEventShopping && channel == NotificationChannelPush && p.MuteShoppingUpdates {
+		return false
+	}
+	if event == "" {
+		return channel == NotificationChannelPush
+	}
+	return defaultChannels[event][channel]
+}
+
+// DigestCovers reports whether the member's digest covers any of the events
+func (p NotificationPreferences) DigestCovers(events ...NotificationEvent) bool {
+	for _, event := range events {
+		if p.Allows(event, NotificationChannelDigest) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelMatrix returns whether the member wants every event on every channel
+func (p NotificationPreferences) ChannelMatrix() map[NotificationEvent]ChannelSettings {
+	matrix := make(map[NotificationEvent]ChannelSettings, len(NotificationEvents))
+	for _, event := range NotificationEvents {
+		settings := make(ChannelSettings, len(NotificationChannels))
+		for _, channel := range NotificationChannels {
+			settings[channel] = p.Allows(event, channel)
+		}
+		matrix[event] = settings
+	}
+	return matrix
+}
+
+// Validate checks the quiet hours, the digest frequency and that channels are only set for known
+// events
+func (p NotificationPreferences) Validate() error {
+	if err := p.QuietHours.Validate(); err != nil {
+		return err
+	}
+	if !IsValidDigestFrequency(p.DigestFrequency) {
+		return errors.New("digest frequency must be daily or weekly")
+	}
+	for event, settings := range p.Channels {
+		if _, ok := defaultChannels[event]; !ok {
+			return fmt.Errorf("unknown notification event %q", event)
+		}
+		for channel := range settings {
+			switch channel {
+			case NotificationChannelPush, NotificationChannelEmail, NotificationChannelSMS, NotificationChannelDigest:
+			default:
+				return fmt.Errorf("unknown notification channel %q", channel)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+// models/notification_templates.go
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Locale is a language notifications are written in
+type Locale string
+
+const (
+	LocaleEnglish Locale = "en"
+	LocaleSpanish Locale = "es"
+
+	// DefaultLocale is used for members who have not chosen a locale, and for copy missing in theirs
+	DefaultLocale = LocaleEnglish
+)
+
+// SupportedLocales lists the locales every notification is written in
+var SupportedLocales = []Locale{LocaleEnglish, LocaleSpanish}
+
+// ParseLocale turns a language tag such as "es", "es-MX" or "es_ES" into a supported locale.
+// Empty means the default locale.
+func ParseLocale(tag string) (Locale, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return DefaultLocale, true
+	}
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, locale := range SupportedLocales {
+		if Locale(tag) == locale {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// NotificationTemplateID names a piece of notification copy in the registry
+type NotificationTemplateID string
+
+const (
+	TemplateChoreAssigned          NotificationTemplateID = "chore_assigned"
+	TemplateChoreCompleted         NotificationTemplateID = "chore_completed"
+	TemplateRecurringChoreEnded    NotificationTemplateID = "recurring_chore_ended"
+	TemplateItemsPurchased         NotificationTemplateID = "items_purchased"
+	TemplateShoppingItemAdded      NotificationTemplateID = "shopping_item_added"
+	TemplateShoppingItemBought     NotificationTemplateID = "shopping_item_bought"
+	TemplateLowStock               NotificationTemplateID = "low_stock"
+	TemplateExpiringSoon           NotificationTemplateID = "expiring_soon"
+	TemplateExpenseAdded           NotificationTemplateID = "expense_added"
+	TemplateExpenseDisputed        NotificationTemplateID = "expense_disputed"
+	TemplateExpenseDisputeResolved NotificationTemplateID = "expense_dispute_resolved"
+	TemplateDisputedExpenseEdited  NotificationTemplateID = "disputed_expense_edited"
+	TemplateBillDue                NotificationTemplateID = "bill_due"
+	TemplatePaymentReminder        NotificationTemplateID = "payment_reminder"
+	TemplatePaymentOverdue         NotificationTemplateID = "payment_overdue"
+	TemplateChallengeCompleted     NotificationTemplateID = "challenge_completed"
+	TemplateHouseEventCreated      NotificationTemplateID = "house_event_created"
+	TemplateHouseEventReminder     NotificationTemplateID = "house_event_reminder"
+	TemplateGuestRegistered        NotificationTemplateID = "guest_registered"
+	TemplateGuestArriving          NotificationTemplateID = "guest_arriving"
+	TemplateBookingCancelled       NotificationTemplateID = "booking_cancelled"
+	TemplatePollCreated            NotificationTemplateID = "poll_created"
+	TemplatePollDecided            NotificationTemplateID = "poll_decided"
+	TemplatePickupReminder         NotificationTemplateID = "pickup_reminder"
+	TemplateDecisionCreated        NotificationTemplateID = "decision_created"
+	TemplateDecisionClosed         NotificationTemplateID = "decision_closed"
+	TemplateChatMention            NotificationTemplateID = "chat_mention"
+	TemplateHouseRulesUpdated      NotificationTemplateID = "house_rules_updated"
+	TemplateIssueReported          NotificationTemplateID = "issue_reported"
+	TemplateIssueResolved          NotificationTemplateID = "issue_resolved"
+	TemplateKudosReceived          NotificationTemplateID = "kudos_received"
+	TemplateSMSRentDue             NotificationTemplateID = "sms_rent_due"      // Text only, no title
+	TemplateSMSChoreOverdue        NotificationTemplateID = "sms_chore_overdue" // Text only, no title
+)
+
+// NotificationParams are the values interpolated into a template. Dates are passed as
+// YYYY-MM-DD and formatted for the locale with the date and weekday functions; lists are built with
+// NotificationList and joined for the locale with the list function.
+type NotificationParams map[string]string
+
+// NotificationCopy is the text of a notification in one locale, as text/template source
+type NotificationCopy struct {
+	Title   string
+	Message string
+}
+
+// notificationCopy is the registry of notification text in every supported locale
+var notificationCopy = map[NotificationTemplateID]map[Locale]NotificationCopy{
+	TemplateChoreAssigned: {
+		LocaleEnglish: {"New chore", "{{.chore}} is yours{{with .due}}, due {{weekday .}}{{end}}"},
+		LocaleSpanish: {"Nueva tarea", "Te toca {{.chore}}{{with .due}}, para el {{weekday .}}{{end}}"},
+	},
+	TemplateChoreCompleted: {
+		LocaleEnglish: {"Chore completed", "{{.name}} completed {{.chore}}"},
+		LocaleSpanish: {"Tarea completada", "{{.name}} completó {{.chore}}"},
+	},
+	TemplateRecurringChoreEnded: {
+		LocaleEnglish: {"Recurring chore ended", "{{.chore}} has finished its run and is no longer being assigned"},
+		LocaleSpanish: {"Tarea recurrente terminada", "{{.chore}} ha terminado y ya no se asignará"},
+	},
+	TemplateItemsPurchased: {
+		LocaleEnglish: {"Items bought", "{{.name}} bought {{list .items}}"},
+		LocaleSpanish: {"Compras hechas", "{{.name}} compró {{list .items}}"},
+	},
+	TemplateShoppingItemAdded: {
+		LocaleEnglish: {"Shopping list", "{{.name}} added {{.item}} to the shopping list"},
+		LocaleSpanish: {"Lista de compras", "{{.name}} añadió {{.item}} a la lista de compras"},
+	},
+	TemplateShoppingItemBought: {
+		LocaleEnglish: {"Shopping list", "{{.name}} bought {{.item}}"},
+		LocaleSpanish: {"Lista de compras", "{{.name}} compró {{.item}}"},
+	},
+	TemplateLowStock: {
+		LocaleEnglish: {"{{.item}} is running low", "{{.item}} is below {{.minimum}} and was added to the shopping list"},
+		LocaleSpanish: {"Queda poco de {{.item}}", "{{.item}} está por debajo de {{.minimum}} y se añadió a la lista de compras"},
+	},
+	TemplateExpiringSoon: {
+		LocaleEnglish: {
+			`{{if eq .count "1"}}{{.first}} expires soon{{else}}{{.count}} pantry items expire soon{{end}}`,
+			"Expiring within {{.days}} days: {{.items}}",
+		},
+		LocaleSpanish: {
+			`{{if eq .count "1"}}{{.first}} caduca pronto{{else}}{{.count}} productos de la despensa caducan pronto{{end}}`,
+			"Caducan en {{.days}} días: {{.items}}",
+		},
+	},
+	TemplateExpenseAdded: {
+		LocaleEnglish: {"New expense", `{{.name}} added "{{.expense}}" ({{.amount}} {{.currency}}). Your share is {{.share}} {{.currency}}`},
+		LocaleSpanish: {"Nuevo gasto", `{{.name}} añadió "{{.expense}}" ({{.amount}} {{.currency}}). Tu parte es {{.share}} {{.currency}}`},
+	},
+	TemplateExpenseDisputed: {
+		LocaleEnglish: {"Expense disputed", `{{.name}} disputed "{{.expense}}": {{.reason}}. It is left out of balances until you edit it or an admin resolves the dispute.`},
+		LocaleSpanish: {"Gasto impugnado", `{{.name}} impugnó "{{.expense}}": {{.reason}}. No cuenta en los saldos hasta que lo edites o un administrador resuelva la disputa.`},
+	},
+	TemplateExpenseDisputeResolved: {
+		LocaleEnglish: {"Dispute resolved", `{{.name}} resolved your dispute of "{{.expense}}". It counts towards balances again.`},
+		LocaleSpanish: {"Disputa resuelta", `{{.name}} resolvió tu disputa sobre "{{.expense}}". Vuelve a contar en los saldos.`},
+	},
+	TemplateDisputedExpenseEdited: {
+		LocaleEnglish: {"Disputed expense corrected", `{{.name}} edited "{{.expense}}", which you disputed. It counts towards balances again.`},
+		LocaleSpanish: {"Gasto impugnado corregido", `{{.name}} editó "{{.expense}}", que impugnaste. Vuelve a contar en los saldos.`},
+	},
+	TemplateBillDue: {
+		LocaleEnglish: {"Bill due soon", "{{.bill}} of {{.amount}} {{.currency}} is due on {{date .due}}{{if .paying}} and you are paying it{{end}}. Your share is {{.share}} {{.currency}}"},
+		LocaleSpanish: {"Factura por vencer", "{{.bill}} de {{.amount}} {{.currency}} vence el {{date .due}}{{if .paying}} y la pagas tú{{end}}. Tu parte es {{.share}} {{.currency}}"},
+	},
+	TemplatePaymentReminder: {
+		LocaleEnglish: {"Time to settle up", "You owe your roommates {{.owed}} {{.currency}}.{{with .payments}} Pay {{list .}} to settle up.{{end}}"},
+		LocaleSpanish: {"Hora de saldar cuentas", "Debes {{.owed}} {{.currency}} a tus compañeros.{{with .payments}} Paga a {{list .}} para saldar cuentas.{{end}}"},
+	},
+	TemplatePaymentOverdue: {
+		LocaleEnglish: {"Payment overdue", "This is reminder {{.reminder}}: you have owed your roommates money for {{.days}} days and still owe {{.owed}} {{.currency}}.{{with .payments}} Pay {{list .}} to settle up.{{end}} Please settle up or talk to them if something is wrong."},
+		LocaleSpanish: {"Pago atrasado", "Este es el recordatorio {{.reminder}}: llevas {{.days}} días debiendo dinero a tus compañeros y aún debes {{.owed}} {{.currency}}.{{with .payments}} Paga a {{list .}} para saldar cuentas.{{end}} Salda tus cuentas o habla con ellos si algo no va bien."},
+	},
+	TemplateChallengeCompleted: {
+		LocaleEnglish: {"Challenge completed", `Your group completed "{{.challenge}}"{{with .reward}} and earned: {{.}}{{else}}!{{end}}`},
+		LocaleSpanish: {"Reto completado", `{{if not .reward}}¡{{end}}Tu grupo completó "{{.challenge}}"{{with .reward}} y ganó: {{.}}{{else}}!{{end}}`},
+	},
+	TemplateHouseEventCreated: {
+		LocaleEnglish: {"New house event", "{{.name}} added {{.event}} on {{weekday .date}} at {{.time}}. Let them know if you are coming"},
+		LocaleSpanish: {"Nuevo evento en casa", "{{.name}} añadió {{.event}} el {{weekday .date}} a las {{.time}}. Avisa si vas a ir"},
+	},
+	TemplateHouseEventReminder: {
+		LocaleEnglish: {"Coming up", "{{.event}} starts {{weekday .date}} at {{.time}}{{with .location}} at {{.}}{{end}}"},
+		LocaleSpanish: {"Ya casi empieza", "{{.event}} empieza el {{weekday .date}} a las {{.time}}{{with .location}} en {{.}}{{end}}"},
+	},
+	TemplateGuestRegistered: {
+		LocaleEnglish: {"Guest staying over", `{{.name}} has {{.guest}} staying from {{weekday .arrive}} to {{weekday .depart}} ({{if eq .nights "1"}}1 night{{else}}{{.nights}} nights{{end}})`},
+		LocaleSpanish: {"Invitado en casa", `{{.name}} recibe a {{.guest}} del {{weekday .arrive}} al {{weekday .depart}} ({{if eq .nights "1"}}1 noche{{else}}{{.nights}} noches{{end}})`},
+	},
+	TemplateGuestArriving: {
+		LocaleEnglish: {"Guest arriving", "{{.guest}}, {{.name}}'s guest, arrives {{weekday .arrive}} and stays until {{weekday .depart}}"},
+		LocaleSpanish: {"Llega un invitado", "{{.guest}}, invitado de {{.name}}, llega el {{weekday .arrive}} y se queda hasta el {{weekday .depart}}"},
+	},
+	TemplateBookingCancelled: {
+		LocaleEnglish: {"Booking cancelled", "{{if .name}}{{.name}} cancelled your {{.resource}} booking on {{weekday .date}} at {{.time}}{{else}}{{.resource}} was removed, so your booking on {{weekday .date}} at {{.time}} is cancelled{{end}}"},
+		LocaleSpanish: {"Reserva cancelada", "{{if .name}}{{.name}} canceló tu reserva de {{.resource}} del {{weekday .date}} a las {{.time}}{{else}}Se eliminó {{.resource}}, así que tu reserva del {{weekday .date}} a las {{.time}} queda cancelada{{end}}"},
+	},
+	TemplatePollCreated: {
+		LocaleEnglish: {"When suits you?", "{{.name}} is finding a time for {{.poll}}. Pick which of the {{.slots}} times you can make by {{weekday .closes}} at {{.time}}"},
+		LocaleSpanish: {"¿Cuándo te viene bien?", "{{.name}} busca un momento para {{.poll}}. Elige cuáles de los {{.slots}} horarios te vienen bien antes del {{weekday .closes}} a las {{.time}}"},
+	},
+	TemplatePollDecided: {
+		LocaleEnglish: {"Time picked", `{{if .date}}{{.poll}} is on {{weekday .date}} at {{.time}}, when {{if eq .votes "1"}}1 member{{else}}{{.votes}} members{{end}} can make it{{else}}Nobody could make any of the times for {{.poll}}, so none was picked{{end}}`},
+		LocaleSpanish: {"Horario elegido", `{{if .date}}{{.poll}} será el {{weekday .date}} a las {{.time}}, cuando {{if eq .votes "1"}}1 miembro puede{{else}}{{.votes}} miembros pueden{{end}} ir{{else}}Nadie podía en ninguno de los horarios de {{.poll}}, así que no se eligió ninguno{{end}}`},
+	},
+	TemplatePickupReminder: {
+		LocaleEnglish: {"Bins out tonight", `{{if eq .kind "recycling"}}Recycling{{else if eq .kind "compost"}}Compost{{else if eq .kind "yard_waste"}}Yard waste{{else}}Trash{{end}} is collected {{weekday .date}}. It's your turn to put it out tonight`},
+		LocaleSpanish: {"Saca los contenedores esta noche", `{{if eq .kind "recycling"}}El reciclaje{{else if eq .kind "compost"}}El compost{{else if eq .kind "yard_waste"}}La poda{{else}}La basura{{end}} se recoge el {{weekday .date}}. Te toca sacarlo esta noche`},
+	},
+	TemplateDecisionCreated: {
+		LocaleEnglish: {"New poll", `{{.name}} asks "{{.question}}". Vote by {{weekday .deadline}}`},
+		LocaleSpanish: {"Nueva votación", `{{.name}} pregunta "{{.question}}". Vota antes del {{weekday .deadline}}`},
+	},
+	TemplateDecisionClosed: {
+		LocaleEnglish: {"Poll closed", `{{if .tied}}{{.question}} ended in a tie between {{.winner}} with {{.votes}} votes each{{else if .winner}}{{.question}}: {{.winner}} won with {{if eq .votes "1"}}1 vote{{else}}{{.votes}} votes{{end}}{{else}}Nobody voted on {{.question}}{{end}}`},
+		LocaleSpanish: {"Votación cerrada", `{{if .tied}}{{.question}} terminó en empate entre {{.winner}} con {{.votes}} votos cada una{{else if .winner}}{{.question}}: ganó {{.winner}} con {{if eq .votes "1"}}1 voto{{else}}{{.votes}} votos{{end}}{{else}}Nadie votó en {{.question}}{{end}}`},
+	},
+	TemplateChatMention: {
+		LocaleEnglish: {"{{.name}} mentioned you", "{{.message}}"},
+		LocaleSpanish: {"{{.name}} te mencionó", "{{.message}}"},
+	},
+	TemplateHouseRulesUpdated: {
+		LocaleEnglish: {"House rules updated", `{{if eq .version "1"}}{{.name}} wrote the house rules{{else}}{{.name}} updated the house rules{{if .note}}: {{.note}}{{end}}{{end}}. Please read and accept them`},
+		LocaleSpanish: {"Normas de la casa actualizadas", `{{if eq .version "1"}}{{.name}} escribió las normas de la casa{{else}}{{.name}} actualizó las normas de la casa{{if .note}}: {{.note}}{{end}}{{end}}. Léelas y acéptalas`},
+	},
+	TemplateIssueReported: {
+		LocaleEnglish: {"New {{.category}} issue", `{{if .name}}{{.name}}{{else}}A roommate{{end}} logged an issue: {{.description}}`},
+		LocaleSpanish: {`Nuevo problema de {{if eq .category "noise"}}ruido{{else if eq .category "cleanliness"}}limpieza{{else if eq .category "damage"}}daños{{else}}otro{{end}}`, `{{if .name}}{{.name}}{{else}}Un compañero{{end}} registró un problema: {{.description}}`},
+	},
+	TemplateIssueResolved: {
+		LocaleEnglish: {"Issue resolved", `{{.name}} resolved your {{.category}} issue "{{.description}}"{{if .resolution}}: {{.resolution}}{{end}}`},
+		LocaleSpanish: {"Problema resuelto", `{{.name}} resolvió tu problema de {{if eq .category "noise"}}ruido{{else if eq .category "cleanliness"}}limpieza{{else if eq .category "damage"}}daños{{else}}otro{{end}} "{{.description}}"{{if .resolution}}: {{.resolution}}{{end}}`},
+	},
+	TemplateKudosReceived: {
+		LocaleEnglish: {"Kudos from {{.name}}", `{{.message}}{{if eq .points "1"}} (+1 point){{else if ne .points "0"}} (+{{.points}} points){{end}}`},
+		LocaleSpanish: {"Felicitaciones de {{.name}}", `{{.message}}{{if eq .points "1"}} (+1 punto){{else if ne .points "0"}} (+{{.points}} puntos){{end}}`},
+	},
+	TemplateSMSRentDue: {
+		LocaleEnglish: {"", "Cribb: {{.bill}} is due {{date .due}}. Your share is {{.share}} {{.currency}}."},
+		LocaleSpanish: {"", "Cribb: {{.bill}} vence el {{date .due}}. Tu parte es {{.share}} {{.currency}}."},
+	},
+	TemplateSMSChoreOverdue: {
+		LocaleEnglish: {"", "Cribb: {{.chore}} was due {{weekday .due}} and is still not done. Please take care of it or let your housemates know."},
+		LocaleSpanish: {"", "Cribb: {{.chore}} vencía el {{weekday .due}} y sigue sin hacerse. Encárgate o avisa a tus compañeros."},
+	},
+}
+
+// localeWords are the words notification functions need in each locale
+type localeWords struct {
+	and      string
+	months   [12]string
+	weekdays [7]string // Starting on Sunday
+}
+
+var localeVocabulary = map[Locale]localeWords{
+	LocaleEnglish: {
+		and:      "and",
+		months:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	},
+	LocaleSpanish: {
+		and:      "y",
+		months:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		weekdays: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+}
+
+// notificationDateLayout is how dates are passed in NotificationParams
+const notificationDateLayout = "2006-01-02"
+
+// listSeparator separates the items of a NotificationList
+const listSeparator = "\n"
+
+// NotificationDate formats a date for NotificationParams
+func NotificationDate(t time.Time) string {
+	return t.Format(notificationDateLayout)
+}
+
+// NotificationList packs items into one value of NotificationParams
+func NotificationList(items []string) string {
+	return strings.Join(items, listSeparator)
+}
+
+// notificationFuncs returns the template functions that format values for the locale
+func notificationFuncs(locale Locale) template.FuncMap {
+	words := localeVocabulary[locale]
+	formatDate := func(value string, withWeekday bool) string {
+		date, err := time.Parse(notificationDateLayout, value)
+		if err != nil {
+			return value
+		}
+		month := words.months[date.Month()-1]
+		var formatted string
+		if locale == LocaleEnglish {
+			formatted = fmt.Sprintf("%s %d", month, date.Day())
+		} else {
+			formatted = fmt.Sprintf("%d %s", date.Day(), month)
+		}
+		if withWeekday {
+			formatted = words.weekdays[date.Weekday()] + " " + formatted
+		}
+		return formatted
+	}
+	return template.FuncMap{
+		"date":    func(value string) string { return formatDate(value, false) },
+		"weekday": func(value string) string { return formatDate(value, true) },
+		"list": func(value string) string {
+			if value == "" {
+				return ""
+			}
+			items := strings.Split(value, listSeparator)
+			if len(items) == 1 {
+				return items[0]
+			}
+			return strings.Join(items[:len(items)-1], ", ") + " " + words.and + " " + items[len(items)-1]
+		},
+	}
+}
+
+// parsedCopy is the compiled title and message of a notification in one locale
+type parsedCopy struct {
+	title   *template.Template
+	message *template.Template
+}
+
+var parsedNotificationCopy = map[NotificationTemplateID]map[Locale]parsedCopy{}
+
+// init compiles the registry; copy that does not parse or is missing a supported locale stops the
+// server from starting rather than reaching members half translated
+func init() {
+	for id, locales := range notificationCopy {
+		for _, locale := range SupportedLocales {
+			if _, ok := locales[locale]; !ok {
+				panic(fmt.Sprintf("notification template %s has no %s copy", id, locale))
+			}
+		}
+		parsedNotificationCopy[id] = make(map[Locale]parsedCopy, len(locales))
+		for locale, text := range locales {
+			funcs := notificationFuncs(locale)
+			parse := func(part, source string) *template.Template {
+				name := fmt.Sprintf("%s.%s.%s", id, locale, part)
+				return template.Must(template.New(name).Funcs(funcs).Option("missingkey=zero").Parse(source))
+			}
+			parsedNotificationCopy[id][locale] = parsedCopy{title: parse("title", text.Title), message: parse("message", text.Message)}
+		}
+	}
+}
+
+// RenderNotification writes the notification copy in the locale, falling back to the default
+// locale when the copy has not been translated. Unknown templates render nothing.
+func RenderNotification(id NotificationTemplateID, locale Locale, params NotificationParams) (string, string) {
+	locales, ok := parsedNotificationCopy[id]
+	if !ok {
+		return "", ""
+	}
+	parsed, ok := locales[locale]
+	if !ok {
+		parsed, ok = locales[DefaultLocale]
+		if !ok {
+			return "", ""
+		}
+	}
+	if params == nil {
+		params = NotificationParams{}
+	}
+	var title, message bytes.Buffer
+	if err := parsed.title.Execute(&title, params); err != nil && locale != DefaultLocale {
+		return RenderNotification(id, DefaultLocale, params)
+	}
+	if err := parsed.message.Execute(&message, params); err != nil && locale != DefaultLocale {
+		return RenderNotification(id, DefaultLocale, params)
+	}
+	return title.String(), message.String()
+}
+
+// RenderText writes text-only copy, such as an SMS, in the locale
+func RenderText(id NotificationTemplateID, locale Locale, params NotificationParams) string {
+	_, message := RenderNotification(id, locale, params)
+	return message
+}
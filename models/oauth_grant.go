@@ -0,0 +1,75 @@
+// models/oauth_grant.go
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// OAuthCodeTTL is how long an authorization code can be exchanged for tokens
+	OAuthCodeTTL = 10 * time.Minute
+	// OAuthAccessTokenTTL is how long an access token works before it is refreshed
+	OAuthAccessTokenTTL = time.Hour
+)
+
+const oauthTokenBytes = 32
+
+// OAuthGrant links a member's account to a voice assistant. The member approves the link, the
+// assistant exchanges the authorization code for an access token and a refresh token, and then
+// acts as the member with the access token. Only hashes of the code and tokens are stored.
+type OAuthGrant struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ClientID    string             `bson:"client_id" json:"client_id"`
+	Assistant   string             `bson:"assistant" json:"assistant"` // e.g. Alexa
+	RedirectURI string             `bson:"redirect_uri" json:"-"`
+	CodeHash    string             `bson:"code_hash,omitempty" json:"-"`
+	// CodeExpiresAt is set until the code is exchanged; grants whose code was never exchanged are
+	// removed by a TTL index
+	CodeExpiresAt        *time.Time `bson:"code_expires_at,omitempty" json:"-"`
+	AccessTokenHash      string     `bson:"access_token_hash,omitempty" json:"-"`
+	AccessTokenExpiresAt *time.Time `bson:"access_token_expires_at,omitempty" json:"-"`
+	RefreshTokenHash     string     `bson:"refresh_token_hash,omitempty" json:"-"`
+	LastUsedAt           *time.Time `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+	CreatedAt            time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt            time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateOAuthGrant creates a grant the member approved and returns it with its authorization code
+func CreateOAuthGrant(userID primitive.ObjectID, clientID, assistant, redirectURI string, now time.Time) (*OAuthGrant, string, error) {
+	code, err := NewOAuthToken("coc_")
+	if err != nil {
+		return nil, "", err
+	}
+	expiresAt := now.Add(OAuthCodeTTL)
+	return &OAuthGrant{
+		UserID:        userID,
+		ClientID:      clientID,
+		Assistant:     assistant,
+		RedirectURI:   redirectURI,
+		CodeHash:      HashOAuthToken(code),
+		CodeExpiresAt: &expiresAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, code, nil
+}
+
+// NewOAuthToken returns a random code or token with the prefix
+func NewOAuthToken(prefix string) (string, error) {
+	secret := make([]byte, oauthTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(secret), nil
+}
+
+// HashOAuthToken returns the hash grants are looked up by
+func HashOAuthToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
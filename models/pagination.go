@@ -0,0 +1,125 @@
+// models/pagination.go
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultPageSize is how many items list endpoints return when no limit is given
+	DefaultPageSize = 50
+	// MaxPageSize caps the limit clients can ask list endpoints for
+	MaxPageSize = 100
+	// Unpaged is the default limit of lists that predate paging: they return every item until the
+	// client asks for a page with a limit or a cursor, so clients unaware of cursors see every item
+	Unpaged = 0
+)
+
+// ErrInvalidPageCursor is returned for cursors that were not issued by EncodePageCursor
+var ErrInvalidPageCursor = errors.New("invalid page cursor")
+
+// ErrInvalidPageLimit is returned for limits outside 1 to MaxPageSize
+var ErrInvalidPageLimit = fmt.Errorf("limit must be between 1 and %d", MaxPageSize)
+
+// PageLimit returns how many items a list returns for the requested limit and whether a cursor
+// was given: the limit when there is one, otherwise defaultLimit. Unpaged lists page by
+// DefaultPageSize once a cursor is given, and return 0, every item, otherwise.
+func PageLimit(limit string, hasCursor bool, defaultLimit int) (int, error) {
+	if limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 || parsed > MaxPageSize {
+			return 0, ErrInvalidPageLimit
+		}
+		return parsed, nil
+	}
+	if defaultLimit == Unpaged && hasCursor {
+		return DefaultPageSize, nil
+	}
+	return defaultLimit, nil
+}
+
+// PageCursor marks the last item of a page: its sort key and its ID, which breaks ties between
+// items with the same key. A zero key stands for items without one. Clients get it encoded as an
+// opaque string and pass it back to fetch the next page.
+type PageCursor struct {
+	Key time.Time          `json:"k,omitempty"`
+	ID  primitive.ObjectID `json:"id"`
+}
+
+// EncodePageCursor returns the cursor as an opaque URL-safe string
+func EncodePageCursor(cursor PageCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageCursor parses a cursor returned by EncodePageCursor
+func DecodePageCursor(encoded string) (PageCursor, error) {
+	var cursor PageCursor
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, ErrInvalidPageCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID.IsZero() {
+		return cursor, ErrInvalidPageCursor
+	}
+	return cursor, nil
+}
+
+// PageOrder is the order a list endpoint pages through: by a time field, then by ID. Pages by
+// "_id" alone when Field is "_id", which orders documents by creation time.
+type PageOrder struct {
+	Field      string
+	Descending bool
+}
+
+// Sort returns the sort that pages follow
+func (o PageOrder) Sort() bson.D {
+	direction := 1
+	if o.Descending {
+		direction = -1
+	}
+	if o.Field == "_id" {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+	return bson.D{{Key: o.Field, Value: direction}, {Key: "_id", Value: direction}}
+}
+
+// After returns the filter matching the items that follow the cursor. Items without the sort key
+// sort as null, first in ascending and last in descending order.
+func (o PageOrder) After(cursor PageCursor) bson.M {
+	compare := "$gt"
+	if o.Descending {
+		compare = "$lt"
+	}
+	afterID := bson.M{"_id": bson.M{compare: cursor.ID}}
+	if o.Field == "_id" {
+		return afterID
+	}
+
+	if cursor.Key.IsZero() {
+		if o.Descending {
+			return bson.M{o.Field: nil, "_id": bson.M{compare: cursor.ID}}
+		}
+		return bson.M{"$or": bson.A{
+			bson.M{o.Field: nil, "_id": bson.M{compare: cursor.ID}},
+			bson.M{o.Field: bson.M{"$ne": nil}},
+		}}
+	}
+
+	after := bson.A{
+		bson.M{o.Field: bson.M{compare: cursor.Key}},
+		bson.M{o.Field: cursor.Key, "_id": bson.M{compare: cursor.ID}},
+	}
+	if o.Descending {
+		after = append(after, bson.M{o.Field: nil})
+	}
+	return bson.M{"$or": after}
+}
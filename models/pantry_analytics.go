@@ -0,0 +1,153 @@
+// models/pantry_analytics.go
+package models
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultAnalyticsMonths is how many months pantry analytics cover when no range is given
+const DefaultAnalyticsMonths = 6
+
+// MaxAnalyticsMonths is the longest range pantry analytics can cover
+const MaxAnalyticsMonths = 24
+
+// UncategorizedName labels spending on items bought without a pantry category
+const UncategorizedName = "Uncategorized"
+
+// CategoryMonthTotals is one category's purchases and waste in a month (YYYY-MM)
+type CategoryMonthTotals struct {
+	CategoryID primitive.ObjectID `bson:"category_id" json:"-"` // NilObjectID for uncategorized purchases
+	Month      string             `bson:"month" json:"month"`
+	Purchases  int                `bson:"purchases" json:"purchases"`
+	Spend      float64            `bson:"spend" json:"spend"`
+	Discards   int                `bson:"discards" json:"discards"`
+	WasteValue float64            `bson:"waste_value" json:"waste_value"`
+}
+
+// CategoryAnalytics is one category's pantry activity over the analytics range
+type CategoryAnalytics struct {
+	CategoryID *primitive.ObjectID   `json:"category_id"` // Nil for uncategorized purchases
+	Name       string                `json:"name"`
+	ItemCount  int                   `json:"item_count"` // Items in the pantry now
+	Purchases  int                   `json:"purchases"`
+	Spend      float64               `json:"spend"`
+	Discards   int                   `json:"discards"`
+	WasteValue float64               `json:"waste_value"`
+	Months     []CategoryMonthTotals `json:"months"` // One entry per month of the range, oldest first
+}
+
+// PantryAnalytics breaks down where a group's grocery money goes by pantry category
+type PantryAnalytics struct {
+	Months     []string            `json:"months"`
+	Spend      float64             `json:"spend"`
+	WasteValue float64             `json:"waste_value"`
+	Categories []CategoryAnalytics `json:"categories"` // Highest spend first
+}
+
+// AnalyticsMonths lists the count months up to and including the month of end, oldest first
+func AnalyticsMonths(end time.Time, count int) []string {
+	end = end.UTC()
+	first := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, 1-count, 0)
+	months := make([]string, count)
+	for i := range months {
+		months[i] = first.AddDate(0, i, 0).Format("2006-01")
+	}
+	return months
+}
+
+// BuildPantryAnalytics combines current item counts, purchase totals and waste totals per
+// category into monthly series. Totals for months outside the range are ignored; categories
+// without a name are reported as uncategorized.
+func BuildPantryAnalytics(months []string, names map[primitive.ObjectID]string, itemCounts map[primitive.ObjectID]int, spend, waste []CategoryMonthTotals) PantryAnalytics {
+	analytics := PantryAnalytics{Months: months, Categories: make([]CategoryAnalytics, 0)}
+	inRange := make(map[string]bool, len(months))
+	for _, month := range months {
+		inRange[month] = true
+	}
+
+	positions := make(map[primitive.ObjectID]int)
+	category := func(id primitive.ObjectID) *CategoryAnalytics {
+		if _, ok := names[id]; !ok {
+			id = primitive.NilObjectID
+		}
+		i, ok := positions[id]
+		if !ok {
+			i = len(analytics.Categories)
+			positions[id] = i
+			entry := CategoryAnalytics{Name: UncategorizedName, Months: make([]CategoryMonthTotals, len(months))}
+			if !id.IsZero() {
+				categoryID := id
+				entry.CategoryID = &categoryID
+				entry.Name = names[id]
+			}
+			for j, month := range months {
+				entry.Months[j].Month = month
+			}
+			analytics.Categories = append(analytics.Categories, entry)
+		}
+		return &analytics.Categories[i]
+	}
+	monthTotals := func(c *CategoryAnalytics, month string) *CategoryMonthTotals {
+		for i := range c.Months {
+			if c.Months[i].Month == month {
+				return &c.Months[i]
+			}
+		}
+		return nil
+	}
+
+	for id, count := range itemCounts {
+		category(id).ItemCount += count
+	}
+	for _, row := range spend {
+		if !inRange[row.Month] {
+			continue
+		}
+		c := category(row.CategoryID)
+		totals := monthTotals(c, row.Month)
+		totals.Purchases += row.Purchases
+		totals.Spend += row.Spend
+		c.Purchases += row.Purchases
+		c.Spend += row.Spend
+		analytics.Spend += row.Spend
+	}
+	for _, row := range waste {
+		if !inRange[row.Month] {
+			continue
+		}
+		c := category(row.CategoryID)
+		totals := monthTotals(c, row.Month)
+		totals.Discards += row.Discards
+		totals.WasteValue += row.WasteValue
+		c.Discards += row.Discards
+		c.WasteValue += row.WasteValue
+		analytics.WasteValue += row.WasteValue
+	}
+
+	analytics.Spend = RoundMoney(analytics.Spend)
+	analytics.WasteValue = RoundMoney(analytics.WasteValue)
+	for i := range analytics.Categories {
+		c := &analytics.Categories[i]
+		c.Spend = RoundMoney(c.Spend)
+		c.WasteValue = RoundMoney(c.WasteValue)
+		for j := range c.Months {
+			c.Months[j].Spend = RoundMoney(c.Months[j].Spend)
+			c.Months[j].WasteValue = RoundMoney(c.Months[j].WasteValue)
+		}
+	}
+
+	sort.SliceStable(analytics.Categories, func(i, j int) bool {
+		a, b := analytics.Categories[i], analytics.Categories[j]
+		if a.Spend != b.Spend {
+			return a.Spend > b.Spend
+		}
+		if a.ItemCount != b.ItemCount {
+			return a.ItemCount > b.ItemCount
+		}
+		return a.Name < b.Name
+	})
+	return analytics
+}
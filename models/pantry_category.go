@@ -1,6 +1,8 @@
 package models
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -26,6 +28,7 @@ type PantryCategory struct {
 	CreatedBy *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"` // null for predefined, user_id for custom
 	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
 	IsActive  bool                `bson:"is_active" json:"is_active"`
+	SortOrder int                 `bson:"sort_order" json:"sort_order"` // Position among the group's custom categories
 }
 
 // CreatePredefinedCategory creates a new predefined category
@@ -87,3 +90,34 @@ func (pc *PantryCategory) CanBeDeletedBy(userID primitive.ObjectID, userGroupID
 	// Custom categories can be deleted by members of the same group
 	return pc.GroupID != nil && *pc.GroupID == userGroupID
 }
+
+// OrderCategories assigns positions to a group's custom categories: the categories in order come
+// first in the given sequence, followed by the rest by name. Positions start at 1. IDs in order
+// that are not among the categories are ignored.
+func OrderCategories(categories []PantryCategory, order []primitive.ObjectID) map[primitive.ObjectID]int {
+	known := make(map[primitive.ObjectID]bool, len(categories))
+	for _, category := range categories {
+		known[category.ID] = true
+	}
+
+	positions := make(map[primitive.ObjectID]int, len(categories))
+	for _, id := range order {
+		if known[id] && positions[id] == 0 {
+			positions[id] = len(positions) + 1
+		}
+	}
+
+	rest := make([]PantryCategory, 0, len(categories))
+	for _, category := range categories {
+		if positions[category.ID] == 0 {
+			rest = append(rest, category)
+		}
+	}
+	sort.SliceStable(rest, func(i, j int) bool {
+		return strings.ToLower(rest[i].Name) < strings.ToLower(rest[j].Name)
+	})
+	for _, category := range rest {
+		positions[category.ID] = len(positions) + 1
+	}
+	return positions
+}
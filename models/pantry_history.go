@@ -22,6 +22,12 @@ const (
 
 	// ActionTypeRemove indicates an item was removed from the pantry
 	ActionTypeRemove ActionType = "remove"
+
+	// ActionTypeDiscard indicates food was thrown away
+	ActionTypeDiscard ActionType = "discard"
+
+	// ActionTypeRevert indicates an item was restored to an earlier version
+	ActionTypeRevert ActionType = "revert"
 )
 
 // PantryHistory represents a record of changes to a pantry item
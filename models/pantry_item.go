@@ -1,24 +1,52 @@
 package models
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// DefaultLowStockThreshold is the quantity at or below which items without a minimum are reported as running low
+const DefaultLowStockThreshold = 1.0
+
+// PantryVisibility defines who a pantry item belongs to
+type PantryVisibility string
+
+const (
+	// VisibilityShared marks communal items any member can use; items without a visibility are shared
+	VisibilityShared PantryVisibility = "shared"
+
+	// VisibilityPersonal marks a member's own food, which only they can use or edit
+	VisibilityPersonal PantryVisibility = "personal"
+)
+
+// IsValidPantryVisibility checks if the visibility is one of the defined values
+func IsValidPantryVisibility(visibility PantryVisibility) bool {
+	return visibility == VisibilityShared || visibility == VisibilityPersonal
+}
+
 // PantryItem represents an item in a group's shared pantry
 type PantryItem struct {
-	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	GroupID        primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
-	Name           string             `bson:"name" json:"name" validate:"required"`
-	Quantity       float64            `bson:"quantity" json:"quantity" validate:"required,min=0"`
-	Unit           string             `bson:"unit" json:"unit" validate:"required"`
-	CategoryID     primitive.ObjectID `bson:"category_id" json:"category_id" validate:"required"`
-	Category       string             `bson:"category,omitempty" json:"category,omitempty"`
-	ExpirationDate time.Time          `bson:"expiration_date,omitempty" json:"expiration_date,omitempty"`
-	AddedBy        primitive.ObjectID `bson:"added_by" json:"added_by" validate:"required"`
-	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID  `bson:"group_id" json:"group_id" validate:"required"`
+	Name       string              `bson:"name" json:"name" validate:"required"`
+	Quantity   float64             `bson:"quantity" json:"quantity" validate:"required,min=0"`
+	Unit       string              `bson:"unit" json:"unit" validate:"required"`
+	CategoryID primitive.ObjectID  `bson:"category_id" json:"category_id" validate:"required"`
+	Category   string              `bson:"category,omitempty" json:"category,omitempty"`
+	Notes      string              `bson:"notes" json:"notes,omitempty"`
+	PhotoID    *primitive.ObjectID `bson:"photo_id,omitempty" json:"photo_id,omitempty"` // Stored image, see the storage package
+	// Visibility and OwnerID separate a member's personal food from the communal pantry
+	Visibility     PantryVisibility    `bson:"visibility,omitempty" json:"visibility"`
+	OwnerID        *primitive.ObjectID `bson:"owner_id" json:"owner_id,omitempty"`
+	ExpirationDate time.Time           `bson:"expiration_date,omitempty" json:"expiration_date,omitempty"`
+	// MinQuantity is the stock level below which the item is put on the shopping list; zero disables it
+	MinQuantity float64            `bson:"min_quantity" json:"min_quantity,omitempty"`
+	AddedBy     primitive.ObjectID `bson:"added_by" json:"added_by" validate:"required"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // CreatePantryItem creates a new pantry item with category ID
@@ -39,6 +67,7 @@ func CreatePantryItem(
 		CategoryID:     categoryID,
 		ExpirationDate: expirationDate,
 		AddedBy:        addedBy,
+		Visibility:     VisibilityShared,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
 	}
@@ -77,6 +106,20 @@ func (p *PantryItem) IsExpiringSoon(days int) bool {
 	return p.ExpirationDate.Before(expirationThreshold) && p.ExpirationDate.After(time.Now())
 }
 
+// DaysUntilExpiration returns the whole days left before the item expires, negative once expired.
+// It reports false for items without an expiration date.
+func (p *PantryItem) DaysUntilExpiration(now time.Time) (int, bool) {
+	if p.ExpirationDate.IsZero() {
+		return 0, false
+	}
+	remaining := p.ExpirationDate.Sub(now)
+	days := int(remaining / (24 * time.Hour))
+	if remaining < 0 && remaining%(24*time.Hour) != 0 {
+		days--
+	}
+	return days, true
+}
+
 // IsExpired checks if the item is already expired
 func (p *PantryItem) IsExpired() bool {
 	if p.ExpirationDate.IsZero() {
@@ -91,3 +134,88 @@ func (p *PantryItem) UpdateQuantity(newQuantity float64) {
 	p.Quantity = newQuantity
 	p.UpdatedAt = time.Now()
 }
+
+// InItemUnit converts a quantity given in unit into the item's unit; an empty unit means the
+// quantity is already in the item's unit. It reports false when the units measure different things.
+func (p *PantryItem) InItemUnit(quantity float64, unit string) (float64, bool) {
+	if strings.TrimSpace(unit) == "" {
+		return quantity, true
+	}
+	return ConvertQuantity(quantity, unit, p.Unit)
+}
+
+// AddStock adds a quantity given in unit to the item, where an empty unit means the item's unit.
+// It reports false, leaving the item unchanged, when the units measure different things.
+func (p *PantryItem) AddStock(quantity float64, unit string) bool {
+	added, ok := p.InItemUnit(quantity, unit)
+	if !ok {
+		return false
+	}
+	p.UpdateQuantity(p.Quantity + added)
+	return true
+}
+
+// Restock adds a purchased quantity to the item, converting it into the item's unit.
+// It reports false, leaving the item unchanged, when the units measure different things.
+func (p *PantryItem) Restock(quantity float64, unit string) bool {
+	converted, ok := ConvertQuantity(quantity, unit, p.Unit)
+	if !ok {
+		return false
+	}
+	p.UpdateQuantity(p.Quantity + converted)
+	return true
+}
+
+// LowStockThreshold returns the quantity at or below which the item is running low
+func (p *PantryItem) LowStockThreshold() float64 {
+	if p.MinQuantity > 0 {
+		return p.MinQuantity
+	}
+	return DefaultLowStockThreshold
+}
+
+// IsBelowMinimum reports whether the item has a minimum quantity and its stock has dropped below it
+func (p *PantryItem) IsBelowMinimum() bool {
+	return p.MinQuantity > 0 && p.Quantity < p.MinQuantity
+}
+
+// RestockQuantity returns how much to buy to bring the item back up to its minimum quantity.
+// Items counted individually are rounded up to whole items.
+func (p *PantryItem) RestockQuantity() float64 {
+	shortfall := p.MinQuantity - p.Quantity
+	if shortfall <= 0 {
+		return 0
+	}
+	if NormalizeUnit(p.Unit) == "" {
+		return math.Ceil(shortfall)
+	}
+	return shortfall
+}
+
+// IsPersonal reports whether the item belongs to a single member
+func (p *PantryItem) IsPersonal() bool {
+	return p.Visibility == VisibilityPersonal && p.OwnerID != nil
+}
+
+// SetOwner makes the item personal to the owner, or shared when owner is nil
+func (p *PantryItem) SetOwner(owner *primitive.ObjectID) {
+	if owner == nil {
+		p.Visibility = VisibilityShared
+		p.OwnerID = nil
+		return
+	}
+	id := *owner
+	p.Visibility = VisibilityPersonal
+	p.OwnerID = &id
+}
+
+// IsOwnedBy reports whether the item is personal to the user
+func (p *PantryItem) IsOwnedBy(userID primitive.ObjectID) bool {
+	return p.IsPersonal() && *p.OwnerID == userID
+}
+
+// CanBeModifiedBy reports whether a member may use, edit or delete the item: anyone for shared
+// items, only the owner for personal ones
+func (p *PantryItem) CanBeModifiedBy(userID primitive.ObjectID) bool {
+	return !p.IsPersonal() || p.IsOwnedBy(userID)
+}
@@ -0,0 +1,109 @@
+// models/pantry_item_version.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PantryItemChange is one field of a pantry item changed by an edit
+type PantryItemChange struct {
+	Field string      `bson:"field" json:"field"`
+	From  interface{} `bson:"from" json:"from"`
+	To    interface{} `bson:"to" json:"to"`
+}
+
+// PantryItemVersion is the state of a pantry item after a change, numbered from 1 per item.
+// Items that existed before versions were kept get a first version without changes holding
+// their state before the first recorded edit.
+type PantryItemVersion struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	ItemID    primitive.ObjectID `bson:"item_id" json:"item_id"`
+	Version   int                `bson:"version" json:"version"`
+	Item      PantryItem         `bson:"item" json:"item"`
+	Changes   []PantryItemChange `bson:"changes" json:"changes"`
+	Action    ActionType         `bson:"action" json:"action"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName  string             `bson:"user_name" json:"user_name"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreatePantryItemVersion records the state of an item after a member changed it
+func CreatePantryItemVersion(item *PantryItem, version int, changes []PantryItemChange, action ActionType, userID primitive.ObjectID, userName string) *PantryItemVersion {
+	if changes == nil {
+		changes = []PantryItemChange{}
+	}
+	return &PantryItemVersion{
+		GroupID:   item.GroupID,
+		ItemID:    item.ID,
+		Version:   version,
+		Item:      *item,
+		Changes:   changes,
+		Action:    action,
+		UserID:    userID,
+		UserName:  userName,
+		CreatedAt: time.Now(),
+	}
+}
+
+// DiffPantryItems lists the fields members edit that differ between two states of an item
+func DiffPantryItems(before, after *PantryItem) []PantryItemChange {
+	changes := make([]PantryItemChange, 0)
+	add := func(field string, from, to interface{}) {
+		changes = append(changes, PantryItemChange{Field: field, From: from, To: to})
+	}
+
+	if before.Name != after.Name {
+		add("name", before.Name, after.Name)
+	}
+	if before.Quantity != after.Quantity {
+		add("quantity", before.Quantity, after.Quantity)
+	}
+	if before.Unit != after.Unit {
+		add("unit", before.Unit, after.Unit)
+	}
+	if before.CategoryID != after.CategoryID {
+		add("category_id", before.CategoryID, after.CategoryID)
+	}
+	if !before.ExpirationDate.Equal(after.ExpirationDate) {
+		add("expiration_date", optionalTime(before.ExpirationDate), optionalTime(after.ExpirationDate))
+	}
+	if before.MinQuantity != after.MinQuantity {
+		add("min_quantity", before.MinQuantity, after.MinQuantity)
+	}
+	if before.Notes != after.Notes {
+		add("notes", before.Notes, after.Notes)
+	}
+	if before.IsPersonal() != after.IsPersonal() || (before.IsPersonal() && *before.OwnerID != *after.OwnerID) {
+		add("owner_id", before.OwnerID, after.OwnerID)
+	}
+	return changes
+}
+
+// RestoreTo sets the fields members edit back to how they were in this version, leaving the
+// item's identity, photo and creation details alone
+func (v *PantryItemVersion) RestoreTo(item *PantryItem) {
+	item.Name = v.Item.Name
+	item.Quantity = v.Item.Quantity
+	item.Unit = v.Item.Unit
+	item.CategoryID = v.Item.CategoryID
+	item.ExpirationDate = v.Item.ExpirationDate
+	item.MinQuantity = v.Item.MinQuantity
+	item.Notes = v.Item.Notes
+	if v.Item.IsPersonal() {
+		item.SetOwner(v.Item.OwnerID)
+	} else {
+		item.SetOwner(nil)
+	}
+	item.UpdatedAt = time.Now()
+}
+
+// optionalTime returns nil for an unset time so cleared dates read as empty in a diff
+func optionalTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
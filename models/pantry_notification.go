@@ -6,7 +6,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// NotificationType defines the type of pantry notification
+// NotificationType defines the type of a notification
 type NotificationType string
 
 const (
@@ -0,0 +1,156 @@
+// models/pantry_search.go
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// notesWeight scales matches in an item's notes below matches in its name
+const notesWeight = 0.6
+
+// PantrySearchResult is a pantry item matching a search with its relevance, higher is better
+type PantrySearchResult struct {
+	PantryItem `bson:",inline"`
+	Score      float64 `bson:"score" json:"score"`
+}
+
+// SearchPantryItems ranks items matching every word of the query in their name or notes,
+// tolerating typos such as "tomatos" for "tomatoes". At most limit results are returned, best first.
+func SearchPantryItems(items []PantryItem, query string, limit int) []PantrySearchResult {
+	queryWords := searchWords(query)
+	results := make([]PantrySearchResult, 0)
+	if len(queryWords) == 0 {
+		return results
+	}
+
+	for _, item := range items {
+		nameWords, notesWords := searchWords(item.Name), searchWords(item.Notes)
+
+		total := 0.0
+		for _, queryWord := range queryWords {
+			best := bestWordMatch(queryWord, nameWords)
+			if notes := bestWordMatch(queryWord, notesWords) * notesWeight; notes > best {
+				best = notes
+			}
+			if best == 0 {
+				total = 0
+				break
+			}
+			total += best
+		}
+		if total > 0 {
+			results = append(results, PantrySearchResult{PantryItem: item, Score: total / float64(len(queryWords))})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return strings.ToLower(results[i].Name) < strings.ToLower(results[j].Name)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// FuzzyMatchScore rates how well text matches every word of the query, from 0 (no match) to 1
+// (every word appears exactly). Each query word is matched against its closest word in text.
+func FuzzyMatchScore(query, text string) float64 {
+	queryWords, textWords := searchWords(query), searchWords(text)
+	if len(queryWords) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, queryWord := range queryWords {
+		best := bestWordMatch(queryWord, textWords)
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(queryWords))
+}
+
+// searchWords splits text into lowercase words, dropping punctuation
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// bestWordMatch returns the best score of a query word against any of the words
+func bestWordMatch(query string, words []string) float64 {
+	best := 0.0
+	for _, word := range words {
+		if score := wordMatchScore(query, word); score > best {
+			best = score
+		}
+	}
+	return best
+}
+
+// wordMatchScore rates a single query word against a word of the text
+func wordMatchScore(query, word string) float64 {
+	switch {
+	case query == word:
+		return 1
+	case strings.HasPrefix(word, query):
+		return 0.9
+	case strings.Contains(word, query):
+		return 0.75
+	}
+
+	// Typos are only forgiven once a word is long enough to be recognizable: one in short words
+	// and two in longer ones
+	length := len([]rune(query))
+	if length < 3 {
+		return 0
+	}
+	allowed := 1
+	if length > 5 {
+		allowed = 2
+	}
+
+	distance := editDistance(query, word)
+	if prefix := []rune(word); distance > allowed && len(prefix) > length {
+		// The query may be a misspelled start of the word
+		distance = editDistance(query, string(prefix[:length]))
+	}
+	if distance > allowed {
+		return 0
+	}
+
+	longest := length
+	if n := len([]rune(word)); n > longest {
+		longest = n
+	}
+	return 0.7 * (1 - float64(distance)/float64(longest))
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
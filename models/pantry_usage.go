@@ -0,0 +1,139 @@
+// models/pantry_usage.go
+package models
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultUsageReportDays is how far back usage reports look when no range is given
+const DefaultUsageReportDays = 30
+
+// MaxUsageReportDays is the longest range a usage report can cover
+const MaxUsageReportDays = 365
+
+// UsageSource identifies how a pantry item's quantity was decreased
+type UsageSource string
+
+const (
+	// UsageSourceUse is a member using part of an item
+	UsageSourceUse UsageSource = "use"
+
+	// UsageSourceUpdate is a member editing an item to a lower quantity
+	UsageSourceUpdate UsageSource = "update"
+)
+
+// PantryUsage records one decrease of a pantry item's quantity
+type PantryUsage struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id"`
+	ItemID    primitive.ObjectID `bson:"item_id" json:"item_id"`
+	ItemName  string             `bson:"item_name" json:"item_name"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName  string             `bson:"user_name" json:"user_name"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	Unit      string             `bson:"unit" json:"unit"`
+	Remaining float64            `bson:"remaining" json:"remaining"` // Quantity left after the decrease
+	Source    UsageSource        `bson:"source" json:"source"`
+	UsedAt    time.Time          `bson:"used_at" json:"used_at"`
+}
+
+// CreatePantryUsage records that a member took quantity of an item, leaving the item's current quantity
+func CreatePantryUsage(item *PantryItem, userID primitive.ObjectID, userName string, quantity float64, source UsageSource) *PantryUsage {
+	return &PantryUsage{
+		GroupID:   item.GroupID,
+		ItemID:    item.ID,
+		ItemName:  item.Name,
+		UserID:    userID,
+		UserName:  userName,
+		Quantity:  quantity,
+		Unit:      item.Unit,
+		Remaining: item.Quantity,
+		Source:    source,
+		UsedAt:    time.Now(),
+	}
+}
+
+// FinishedItem reports whether this usage emptied the item
+func (u *PantryUsage) FinishedItem() bool {
+	return u.Remaining <= 0
+}
+
+// PantryUsageTotal sums one member's usage of one item
+type PantryUsageTotal struct {
+	ItemID        primitive.ObjectID `json:"item_id"`
+	ItemName      string             `json:"item_name"`
+	UserID        primitive.ObjectID `json:"user_id"`
+	UserName      string             `json:"user_name"`
+	Quantity      float64            `json:"quantity"`
+	Unit          string             `json:"unit"`
+	Uses          int                `json:"uses"`
+	TimesFinished int                `json:"times_finished"` // How often this member used the last of the item
+	LastUsedAt    time.Time          `json:"last_used_at"`
+}
+
+// SummarizePantryUsage totals usage per item and member, converting quantities into the unit
+// first seen for the item. Totals are ordered by item name, then by largest quantity.
+func SummarizePantryUsage(usage []PantryUsage) []PantryUsageTotal {
+	type key struct{ item, user primitive.ObjectID }
+	index := make(map[key]int)
+	itemUnits := make(map[primitive.ObjectID]string)
+	totals := make([]PantryUsageTotal, 0)
+
+	for _, u := range usage {
+		unit, ok := itemUnits[u.ItemID]
+		if !ok {
+			unit = u.Unit
+			itemUnits[u.ItemID] = unit
+		}
+
+		k := key{u.ItemID, u.UserID}
+		i, ok := index[k]
+		if !ok {
+			i = len(totals)
+			index[k] = i
+			totals = append(totals, PantryUsageTotal{
+				ItemID:   u.ItemID,
+				ItemName: u.ItemName,
+				UserID:   u.UserID,
+				UserName: u.UserName,
+				Unit:     unit,
+			})
+		}
+
+		total := &totals[i]
+		quantity, ok := ConvertQuantity(u.Quantity, u.Unit, total.Unit)
+		if !ok {
+			quantity = u.Quantity
+		}
+		total.Quantity += quantity
+		total.Uses++
+		if u.FinishedItem() {
+			total.TimesFinished++
+		}
+		if u.UsedAt.After(total.LastUsedAt) {
+			total.LastUsedAt = u.UsedAt
+		}
+	}
+
+	sort.SliceStable(totals, func(i, j int) bool {
+		if totals[i].ItemName != totals[j].ItemName {
+			return totals[i].ItemName < totals[j].ItemName
+		}
+		return totals[i].Quantity > totals[j].Quantity
+	})
+	return totals
+}
+
+// LastFinished returns the most recent usage that emptied an item, if any
+func LastFinished(usage []PantryUsage) *PantryUsage {
+	var last *PantryUsage
+	for i := range usage {
+		if usage[i].FinishedItem() && (last == nil || usage[i].UsedAt.After(last.UsedAt)) {
+			last = &usage[i]
+		}
+	}
+	return last
+}
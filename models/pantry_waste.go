@@ -0,0 +1,162 @@
+// models/pantry_waste.go
+package models
+
+import (
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WasteReason explains why pantry food was thrown away
+type WasteReason string
+
+const (
+	// WasteReasonExpired is food discarded after its expiration date
+	WasteReasonExpired WasteReason = "expired"
+
+	// WasteReasonSpoiled is food that went bad before its expiration date
+	WasteReasonSpoiled WasteReason = "spoiled"
+
+	// WasteReasonOther covers anything else, such as food nobody wanted
+	WasteReasonOther WasteReason = "other"
+)
+
+// IsValidWasteReason checks if the reason is one of the defined values
+func IsValidWasteReason(reason WasteReason) bool {
+	return reason == WasteReasonExpired || reason == WasteReasonSpoiled || reason == WasteReasonOther
+}
+
+// PantryWaste records food discarded from the pantry
+type PantryWaste struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID        primitive.ObjectID `bson:"group_id" json:"group_id"`
+	ItemID         primitive.ObjectID `bson:"item_id" json:"item_id"`
+	ItemName       string             `bson:"item_name" json:"item_name"`
+	CategoryID     primitive.ObjectID `bson:"category_id" json:"category_id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName       string             `bson:"user_name" json:"user_name"`
+	Quantity       float64            `bson:"quantity" json:"quantity"`
+	Unit           string             `bson:"unit" json:"unit"`
+	Reason         WasteReason        `bson:"reason" json:"reason"`
+	Notes          string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	EstimatedValue float64            `bson:"estimated_value" json:"estimated_value"` // Zero when the item has no known price
+	DiscardedAt    time.Time          `bson:"discarded_at" json:"discarded_at"`
+}
+
+// CreatePantryWaste records that a member threw away quantity of an item, in the item's unit
+func CreatePantryWaste(item *PantryItem, userID primitive.ObjectID, userName string, quantity float64, reason WasteReason, value float64) *PantryWaste {
+	return &PantryWaste{
+		GroupID:        item.GroupID,
+		ItemID:         item.ID,
+		ItemName:       item.Name,
+		CategoryID:     item.CategoryID,
+		UserID:         userID,
+		UserName:       userName,
+		Quantity:       quantity,
+		Unit:           item.Unit,
+		Reason:         reason,
+		EstimatedValue: value,
+		DiscardedAt:    time.Now(),
+	}
+}
+
+// EstimateValue prices quantity at the average unit price the group paid. It reports false when
+// there are no priced purchases or their unit cannot be converted from unit.
+func (s PriceSummary) EstimateValue(quantity float64, unit string) (float64, bool) {
+	if len(s.Points) == 0 {
+		return 0, false
+	}
+	converted, ok := ConvertQuantity(quantity, unit, s.Unit)
+	if !ok {
+		return 0, false
+	}
+	return RoundMoney(converted * s.Average), true
+}
+
+// WasteReasonTotal sums the discards for one reason
+type WasteReasonTotal struct {
+	Reason   WasteReason `json:"reason"`
+	Discards int         `json:"discards"`
+	Value    float64     `json:"value"`
+}
+
+// WasteItemTotal sums the discards of one item, in the unit first seen for it
+type WasteItemTotal struct {
+	ItemName string  `json:"item_name"`
+	Quantity float64 `json:"quantity"`
+	Unit     string  `json:"unit"`
+	Discards int     `json:"discards"`
+	Value    float64 `json:"value"`
+}
+
+// WasteReport summarizes a group's food waste over a month
+type WasteReport struct {
+	Month              string             `json:"month"` // YYYY-MM
+	Discards           int                `json:"discards"`
+	TotalValue         float64            `json:"total_value"`
+	PreviousTotalValue float64            `json:"previous_total_value"` // Value wasted the month before, for comparison
+	ByReason           []WasteReasonTotal `json:"by_reason"`
+	Items              []WasteItemTotal   `json:"items"` // Most valuable first
+}
+
+// SummarizeWaste totals discards by reason and by item. Items are matched by name regardless of
+// case, and ordered by value, then by number of discards.
+func SummarizeWaste(month string, waste []PantryWaste) WasteReport {
+	report := WasteReport{
+		Month:    month,
+		ByReason: make([]WasteReasonTotal, 0),
+		Items:    make([]WasteItemTotal, 0),
+	}
+	reasons := make(map[WasteReason]int)
+	items := make(map[string]int)
+
+	for _, w := range waste {
+		report.Discards++
+		report.TotalValue += w.EstimatedValue
+
+		i, ok := reasons[w.Reason]
+		if !ok {
+			i = len(report.ByReason)
+			reasons[w.Reason] = i
+			report.ByReason = append(report.ByReason, WasteReasonTotal{Reason: w.Reason})
+		}
+		report.ByReason[i].Discards++
+		report.ByReason[i].Value += w.EstimatedValue
+
+		name := NormalizeItemName(w.ItemName)
+		j, ok := items[name]
+		if !ok {
+			j = len(report.Items)
+			items[name] = j
+			report.Items = append(report.Items, WasteItemTotal{ItemName: w.ItemName, Unit: w.Unit})
+		}
+		item := &report.Items[j]
+		quantity, ok := ConvertQuantity(w.Quantity, w.Unit, item.Unit)
+		if !ok {
+			quantity = w.Quantity
+		}
+		item.Quantity += quantity
+		item.Discards++
+		item.Value += w.EstimatedValue
+	}
+
+	report.TotalValue = RoundMoney(report.TotalValue)
+	for i := range report.ByReason {
+		report.ByReason[i].Value = RoundMoney(report.ByReason[i].Value)
+	}
+	for i := range report.Items {
+		report.Items[i].Value = RoundMoney(report.Items[i].Value)
+	}
+
+	sort.SliceStable(report.ByReason, func(i, j int) bool {
+		return report.ByReason[i].Discards > report.ByReason[j].Discards
+	})
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		if report.Items[i].Value != report.Items[j].Value {
+			return report.Items[i].Value > report.Items[j].Value
+		}
+		return report.Items[i].Discards > report.Items[j].Discards
+	})
+	return report
+}
@@ -0,0 +1,29 @@
+// models/password_reset.go
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PasswordResetTTL is how long a password reset link works
+const PasswordResetTTL = time.Hour
+
+// PasswordReset is an emailed link to choose a new password. Only a hash of the token in the
+// link is stored, so the stored record cannot be used to reset the password.
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"` // Expired resets are removed by a TTL index
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// HashResetToken returns the hash stored for the token of a password reset link
+func HashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
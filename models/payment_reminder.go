@@ -0,0 +1,160 @@
+// models/payment_reminder.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationTypePaymentReminder nudges a member to pay back what they owe the group
+const NotificationTypePaymentReminder NotificationType = "payment_reminder"
+
+// Defaults and limits of the payment reminder rules
+const (
+	DefaultPaymentReminderThreshold = 50.0
+	DefaultPaymentReminderAfterDays = 14
+	DefaultPaymentReminderInterval  = 3
+	MaxPaymentReminderDays          = 90
+
+	// PaymentReminderEscalation is how many reminders a member gets before the copy gets firmer
+	PaymentReminderEscalation = 3
+)
+
+// PaymentReminderRules decide when members who owe money are reminded to pay. A member is reminded
+// once they owe at least the threshold, or have owed anything for AfterDays, and again every
+// IntervalDays until they are settled up. Zero values use the defaults.
+type PaymentReminderRules struct {
+	Disabled     bool    `bson:"disabled" json:"disabled"`
+	Threshold    float64 `bson:"threshold" json:"threshold"`
+	AfterDays    int     `bson:"after_days" json:"after_days"`
+	IntervalDays int     `bson:"interval_days" json:"interval_days"`
+}
+
+// WithDefaults fills in the rules that are not set
+func (r PaymentReminderRules) WithDefaults() PaymentReminderRules {
+	if r.Threshold <= 0 {
+		r.Threshold = DefaultPaymentReminderThreshold
+	}
+	if r.AfterDays <= 0 {
+		r.AfterDays = DefaultPaymentReminderAfterDays
+	}
+	if r.IntervalDays <= 0 {
+		r.IntervalDays = DefaultPaymentReminderInterval
+	}
+	return r
+}
+
+// Validate checks that the rules are within their limits
+func (r PaymentReminderRules) Validate() error {
+	if r.Threshold < 0 {
+		return errors.New("threshold cannot be negative")
+	}
+	if r.AfterDays < 0 || r.AfterDays > MaxPaymentReminderDays {
+		return fmt.Errorf("after days must be between 1 and %d, or 0 for the default", MaxPaymentReminderDays)
+	}
+	if r.IntervalDays < 0 || r.IntervalDays > MaxPaymentReminderDays {
+		return fmt.Errorf("interval days must be between 1 and %d, or 0 for the default", MaxPaymentReminderDays)
+	}
+	return nil
+}
+
+// PaymentReminder tracks the reminders sent to a member about their current debt. It starts over
+// when the member settles up and owes again.
+type PaymentReminder struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	OwingSince time.Time          `bson:"owing_since" json:"owing_since"` // When the debt the reminders are about started
+	Count      int                `bson:"count" json:"count"`
+	LastSentAt time.Time          `bson:"last_sent_at" json:"last_sent_at"`
+}
+
+// Due reports whether a member who owes the amount since owingSince should be reminded now, given
+// the reminders already sent about that debt
+func (r PaymentReminderRules) Due(owed float64, owingSince time.Time, reminder PaymentReminder, now time.Time) bool {
+	if r.Disabled || math.Round(owed*100) <= 0 {
+		return false
+	}
+	r = r.WithDefaults()
+	if owed < r.Threshold && now.Sub(owingSince) < time.Duration(r.AfterDays)*24*time.Hour {
+		return false
+	}
+	if reminder.Count == 0 || !reminder.OwingSince.Equal(owingSince) {
+		return true
+	}
+	return now.Sub(reminder.LastSentAt) >= time.Duration(r.IntervalDays)*24*time.Hour
+}
+
+// OwingSince replays the expenses and settlements in order and returns, for every member who owes
+// money at the end, when they last went from settled or owed to owing. Disputed expenses are left
+// out, like in ComputeBalances.
+func OwingSince(expenses []Expense, settlements []Settlement) map[primitive.ObjectID]time.Time {
+	type change struct {
+		at     time.Time
+		userID primitive.ObjectID
+		cents  int64
+	}
+	changes := make([]change, 0)
+	for _, expense := range expenses {
+		if expense.IsDisputed() {
+			continue
+		}
+		changes = append(changes, change{expense.CreatedAt, expense.PaidBy, int64(math.Round(expense.Amount * 100))})
+		for _, share := range expense.Splits {
+			changes = append(changes, change{expense.CreatedAt, share.UserID, -int64(math.Round(share.Amount * 100))})
+		}
+	}
+	for _, settlement := range settlements {
+		cents := int64(math.Round(settlement.Amount * 100))
+		changes = append(changes, change{settlement.CreatedAt, settlement.FromUserID, cents}, change{settlement.CreatedAt, settlement.ToUserID, -cents})
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].at.Before(changes[j].at) })
+
+	// Apply everything recorded at the same moment before checking, so an expense the member
+	// paid for and shares in never counts as a debt in between
+	net := make(map[primitive.ObjectID]int64)
+	since := make(map[primitive.ObjectID]time.Time)
+	for i := 0; i < len(changes); {
+		at := changes[i].at
+		touched := make([]primitive.ObjectID, 0)
+		for ; i < len(changes) && changes[i].at.Equal(at); i++ {
+			net[changes[i].userID] += changes[i].cents
+			touched = append(touched, changes[i].userID)
+		}
+		for _, userID := range touched {
+			_, owing := since[userID]
+			switch {
+			case net[userID] < 0 && !owing:
+				since[userID] = at
+			case net[userID] >= 0 && owing:
+				delete(since, userID)
+			}
+		}
+	}
+	return since
+}
+
+// PaymentReminderNotification is the reminder for a member who owes the amount, naming who to pay.
+// Once PaymentReminderEscalation reminders have been sent about the debt (count) the copy points
+// out how long it has been open.
+func PaymentReminderNotification(userID, groupID primitive.ObjectID, owed float64, currency string, debts []Debt, owingSince time.Time, count int, now time.Time) *Notification {
+	payments := make([]string, 0, len(debts))
+	for _, debt := range debts {
+		payments = append(payments, fmt.Sprintf("%s %.2f %s", debt.ToName, debt.Amount, currency))
+	}
+	params := NotificationParams{"owed": fmt.Sprintf("%.2f", owed), "currency": currency, "payments": NotificationList(payments)}
+
+	templateID := TemplatePaymentReminder
+	if count >= PaymentReminderEscalation {
+		templateID = TemplatePaymentOverdue
+		params["reminder"] = strconv.Itoa(count + 1)
+		params["days"] = strconv.Itoa(int(now.Sub(owingSince).Hours() / 24))
+	}
+	return CreateTemplatedNotification(userID, groupID, NotificationTypePaymentReminder, templateID, params)
+}
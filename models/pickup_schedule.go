@@ -0,0 +1,163 @@
+// models/pickup_schedule.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PickupKind is what the municipality collects on a pickup
+type PickupKind string
+
+const (
+	PickupTrash     PickupKind = "trash"
+	PickupRecycling PickupKind = "recycling"
+	PickupCompost   PickupKind = "compost"
+	PickupYardWaste PickupKind = "yard_waste"
+)
+
+// NotificationTypePickupReminder reminds the member whose turn it is to put the bins out the
+// evening before a pickup
+const NotificationTypePickupReminder NotificationType = "pickup_reminder"
+
+// DefaultPickupReminderTime is when members are reminded the evening before a pickup
+const DefaultPickupReminderTime = "19:00"
+
+// pickupChoreTitles name the chores that rotate putting the bins out
+var pickupChoreTitles = map[PickupKind]string{
+	PickupTrash:     "Take out the trash",
+	PickupRecycling: "Take out the recycling",
+	PickupCompost:   "Take out the compost",
+	PickupYardWaste: "Take out the yard waste",
+}
+
+// PickupSchedule is a group's trash or recycling collection, every week or every other week on
+// the same weekday. Putting the bins out rotates among members through a recurring chore due on
+// each pickup day. Pickup dates are stored as midnight UTC.
+type PickupSchedule struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID          primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Kind             PickupKind         `bson:"kind" json:"kind"`
+	EveryWeeks       int                `bson:"every_weeks" json:"every_weeks"` // 1 or 2
+	FirstPickup      time.Time          `bson:"first_pickup" json:"first_pickup"`
+	ReminderTime     string             `bson:"reminder_time" json:"reminder_time"`             // HH:MM the evening before
+	TimeZone         string             `bson:"time_zone,omitempty" json:"time_zone,omitempty"` // IANA name the reminder time is in; defaults to UTC
+	RecurringChoreID primitive.ObjectID `bson:"recurring_chore_id" json:"recurring_chore_id"`
+	NextPickup       time.Time          `bson:"next_pickup" json:"next_pickup"`
+	NextReminderAt   time.Time          `bson:"next_reminder_at" json:"next_reminder_at"`
+	CreatedBy        primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsValidPickupKind checks if the kind is a known kind of pickup
+func IsValidPickupKind(kind PickupKind) bool {
+	_, ok := pickupChoreTitles[kind]
+	return ok
+}
+
+// ValidatePickupSchedule checks the interval, the reminder time and time zone, and that the
+// first pickup is after today
+func ValidatePickupSchedule(everyWeeks int, firstPickup time.Time, reminderTime, timeZone string, now time.Time) error {
+	if everyWeeks != 1 && everyWeeks != 2 {
+		return errors.New("pickups must be every week or every other week")
+	}
+	if firstPickup.IsZero() {
+		return errors.New("the first pickup date is required")
+	}
+	if !startOfDayUTC(firstPickup).After(startOfDayUTC(now)) {
+		return errors.New("the first pickup must be after today")
+	}
+	if _, err := time.Parse("15:04", reminderTime); err != nil {
+		return errors.New("reminder time must be in HH:MM format")
+	}
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", timeZone)
+	}
+	return nil
+}
+
+// CreatePickupSchedule creates a schedule whose first pickup is on the given date
+func CreatePickupSchedule(groupID, createdBy primitive.ObjectID, kind PickupKind, everyWeeks int, firstPickup time.Time, reminderTime, timeZone string) *PickupSchedule {
+	now := time.Now()
+	schedule := &PickupSchedule{
+		GroupID:      groupID,
+		Kind:         kind,
+		EveryWeeks:   everyWeeks,
+		FirstPickup:  startOfDayUTC(firstPickup),
+		ReminderTime: reminderTime,
+		TimeZone:     timeZone,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	schedule.NextPickup = schedule.FirstPickup
+	schedule.NextReminderAt = schedule.ReminderAt(schedule.NextPickup)
+	return schedule
+}
+
+// Frequency is the recurring chore frequency matching the schedule
+func (s PickupSchedule) Frequency() string {
+	if s.EveryWeeks == 2 {
+		return "biweekly"
+	}
+	return "weekly"
+}
+
+// zone returns the time zone the reminder time is in
+func (s PickupSchedule) zone() *time.Location {
+	if location, err := time.LoadLocation(s.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// ReminderAt returns when to remind the member whose turn it is: the evening before the pickup,
+// at the reminder time in the schedule's time zone
+func (s PickupSchedule) ReminderAt(pickup time.Time) time.Time {
+	at, err := time.Parse("15:04", s.ReminderTime)
+	if err != nil {
+		at, _ = time.Parse("15:04", DefaultPickupReminderTime)
+	}
+	year, month, day := pickup.UTC().Date()
+	return time.Date(year, month, day-1, at.Hour(), at.Minute(), 0, 0, s.zone())
+}
+
+// RemindsInTime reports whether a reminder sent now still comes before the pickup day starts
+func (s PickupSchedule) RemindsInTime(now time.Time) bool {
+	year, month, day := s.NextPickup.UTC().Date()
+	return now.Before(time.Date(year, month, day, 0, 0, 0, 0, s.zone()))
+}
+
+// Advance moves the schedule on to the first pickup whose reminder is after now
+func (s *PickupSchedule) Advance(now time.Time) {
+	for !s.ReminderAt(s.NextPickup).After(now) {
+		s.NextPickup = s.NextPickup.AddDate(0, 0, 7*s.EveryWeeks)
+	}
+	s.NextReminderAt = s.ReminderAt(s.NextPickup)
+	s.UpdatedAt = now
+}
+
+// RecurringChore creates the chore that rotates putting the bins out among the members. The
+// scheduler assigns each turn one interval ahead and makes it due on the pickup day; turns
+// falling on blackout dates are skipped, since the truck comes regardless.
+func (s PickupSchedule) RecurringChore(members []primitive.ObjectID, points int) *RecurringChore {
+	chore := CreateRecurringChore(pickupChoreTitles[s.Kind], "", s.GroupID, members, s.Frequency(), points)
+	chore.NextAssignment = s.FirstPickup.AddDate(0, 0, -7*s.EveryWeeks)
+	chore.BlackoutPolicy = BlackoutPolicySkip
+	chore.CatchUpPolicy = CatchUpPolicySkipForward
+	chore.CreatedBy = s.CreatedBy
+	return chore
+}
+
+// PickupReminderNotification reminds the chore's assignee to put the bins out tonight
+func PickupReminderNotification(schedule PickupSchedule, chore Chore) *Notification {
+	params := NotificationParams{
+		"kind": string(schedule.Kind),
+		"date": NotificationDate(schedule.NextPickup),
+	}
+	return CreateTemplatedNotification(chore.AssignedTo, chore.GroupID, NotificationTypePickupReminder, TemplatePickupReminder, params)
+}
@@ -0,0 +1,100 @@
+// models/post.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostCategory is what a bulletin board post is about
+type PostCategory string
+
+const (
+	PostGeneral    PostCategory = "general"
+	PostHouseRules PostCategory = "house_rules" // Discussions of how the house is run
+	PostSublet     PostCategory = "sublet"      // Rooms offered or wanted while a member is away
+)
+
+// Limits on bulletin board posts
+const (
+	MaxPostTitleLength  = 120
+	MaxPostBodyLength   = 10000
+	MaxPostAttachments  = 5
+	DefaultPostPageSize = 20
+	MaxPostPageSize     = 100
+)
+
+// PostAttachment is a file attached to a post, kept in the storage layer
+type PostAttachment struct {
+	FileID      primitive.ObjectID `bson:"file_id" json:"file_id"`
+	Filename    string             `bson:"filename" json:"filename"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Size        int                `bson:"size" json:"size"` // In bytes
+}
+
+// Post is a longer-form post on the group's bulletin board, such as a house rules discussion or a
+// sublet notice. Posts stay until their author or an admin removes them.
+type Post struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	AuthorID    primitive.ObjectID `bson:"author_id" json:"author_id" validate:"required"`
+	Category    PostCategory       `bson:"category" json:"category"`
+	Title       string             `bson:"title" json:"title" validate:"required"`
+	Body        string             `bson:"body" json:"body"`
+	Attachments []PostAttachment   `bson:"attachments" json:"attachments"`
+	EditedAt    *time.Time         `bson:"edited_at,omitempty" json:"edited_at,omitempty"` // When the title or body last changed
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// IsValidPostCategory checks if the category is a known kind of post
+func IsValidPostCategory(category PostCategory) bool {
+	switch category {
+	case PostGeneral, PostHouseRules, PostSublet:
+		return true
+	}
+	return false
+}
+
+// ValidatePost checks that a post has a title and that the title and body fit the limits
+func ValidatePost(title, body string) error {
+	switch {
+	case strings.TrimSpace(title) == "":
+		return errors.New("title is required")
+	case utf8.RuneCountInString(strings.TrimSpace(title)) > MaxPostTitleLength:
+		return fmt.Errorf("titles can be at most %d characters", MaxPostTitleLength)
+	case utf8.RuneCountInString(strings.TrimSpace(body)) > MaxPostBodyLength:
+		return fmt.Errorf("posts can be at most %d characters", MaxPostBodyLength)
+	}
+	return nil
+}
+
+// CreatePost creates a post by the member without attachments
+func CreatePost(groupID, authorID primitive.ObjectID, category PostCategory, title, body string) *Post {
+	now := time.Now()
+	return &Post{
+		GroupID:     groupID,
+		AuthorID:    authorID,
+		Category:    category,
+		Title:       strings.TrimSpace(title),
+		Body:        strings.TrimSpace(body),
+		Attachments: []PostAttachment{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Attachment returns the post's attachment stored under the file ID
+func (p *Post) Attachment(fileID primitive.ObjectID) (PostAttachment, bool) {
+	for _, attachment := range p.Attachments {
+		if attachment.FileID == fileID {
+			return attachment, true
+		}
+	}
+	return PostAttachment{}, false
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// PriceTrend describes how an item's price has moved over time
+type PriceTrend string
+
+const (
+	PriceTrendRising  PriceTrend = "rising"
+	PriceTrendFalling PriceTrend = "falling"
+	PriceTrendStable  PriceTrend = "stable"
+	PriceTrendUnknown PriceTrend = "unknown" // Fewer than two priced purchases
+)
+
+// PriceTrendThreshold is the percentage change between older and newer purchases that counts as a trend
+const PriceTrendThreshold = 5.0
+
+// PricePoint is one purchase of an item with its price per unit
+type PricePoint struct {
+	PurchasedAt     time.Time `json:"purchased_at"`
+	Price           float64   `json:"price"`
+	Quantity        float64   `json:"quantity"`
+	Unit            string    `json:"unit"`
+	UnitPrice       float64   `json:"unit_price"` // Price per one of the summary's unit
+	PurchasedByName string    `json:"purchased_by_name"`
+}
+
+// PriceSummary describes an item's price history in a single unit
+type PriceSummary struct {
+	Unit          string       `json:"unit"` // Unit prices are per one of this unit; empty for individual items
+	Points        []PricePoint `json:"points"`
+	Average       float64      `json:"average_unit_price"`
+	Min           float64      `json:"min_unit_price"`
+	Max           float64      `json:"max_unit_price"`
+	Latest        float64      `json:"latest_unit_price"`
+	Trend         PriceTrend   `json:"trend"`
+	ChangePercent float64      `json:"change_percent"` // Newer half of purchases against the older half
+}
+
+// SummarizePrices builds an item's price history from its purchases, oldest first. Prices are
+// compared per unit of the latest purchase; free or unpriced purchases and purchases in units
+// that cannot be converted are left out.
+func SummarizePrices(purchases []PurchaseRecord) PriceSummary {
+	summary := PriceSummary{Points: make([]PricePoint, 0, len(purchases)), Trend: PriceTrendUnknown}
+
+	for i := len(purchases) - 1; i >= 0; i-- {
+		if purchases[i].Price > 0 && purchases[i].Quantity > 0 {
+			summary.Unit = purchases[i].Unit
+			break
+		}
+	}
+
+	for _, purchase := range purchases {
+		if purchase.Price <= 0 || purchase.Quantity <= 0 {
+			continue
+		}
+		quantity, ok := ConvertQuantity(purchase.Quantity, purchase.Unit, summary.Unit)
+		if !ok || quantity <= 0 {
+			continue
+		}
+		summary.Points = append(summary.Points, PricePoint{
+			PurchasedAt:     purchase.PurchasedAt,
+			Price:           purchase.Price,
+			Quantity:        purchase.Quantity,
+			Unit:            purchase.Unit,
+			UnitPrice:       roundUnitPrice(purchase.Price / quantity),
+			PurchasedByName: purchase.PurchasedByName,
+		})
+	}
+
+	if len(summary.Points) == 0 {
+		return summary
+	}
+
+	total := 0.0
+	summary.Min, summary.Max = math.Inf(1), math.Inf(-1)
+	for _, point := range summary.Points {
+		total += point.UnitPrice
+		summary.Min = math.Min(summary.Min, point.UnitPrice)
+		summary.Max = math.Max(summary.Max, point.UnitPrice)
+	}
+	summary.Average = roundUnitPrice(total / float64(len(summary.Points)))
+	summary.Latest = summary.Points[len(summary.Points)-1].UnitPrice
+
+	if len(summary.Points) < 2 {
+		return summary
+	}
+
+	// Compare the newer half of purchases against the older half to smooth out one-off prices
+	half := len(summary.Points) / 2
+	older := averageUnitPrice(summary.Points[:half])
+	newer := averageUnitPrice(summary.Points[len(summary.Points)-half:])
+	if older > 0 {
+		summary.ChangePercent = math.Round((newer-older)/older*1000) / 10
+	}
+
+	switch {
+	case summary.ChangePercent >= PriceTrendThreshold:
+		summary.Trend = PriceTrendRising
+	case summary.ChangePercent <= -PriceTrendThreshold:
+		summary.Trend = PriceTrendFalling
+	default:
+		summary.Trend = PriceTrendStable
+	}
+	return summary
+}
+
+// averageUnitPrice returns the mean unit price of points
+func averageUnitPrice(points []PricePoint) float64 {
+	total := 0.0
+	for _, point := range points {
+		total += point.UnitPrice
+	}
+	return total / float64(len(points))
+}
+
+// roundUnitPrice rounds a unit price to four decimals, keeping precision for prices per gram or milliliter
+func roundUnitPrice(amount float64) float64 {
+	return math.Round(amount*10000) / 10000
+}
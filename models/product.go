@@ -0,0 +1,108 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Product is a packaged product resolved from its barcode, cached in the products collection
+type Product struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Barcode   string             `bson:"barcode" json:"barcode" validate:"required"`
+	Name      string             `bson:"name" json:"name"`
+	Brand     string             `bson:"brand,omitempty" json:"brand,omitempty"`
+	Category  string             `bson:"category" json:"category"` // One of the predefined pantry category names
+	Quantity  float64            `bson:"quantity,omitempty" json:"quantity,omitempty"`
+	Unit      string             `bson:"unit,omitempty" json:"unit,omitempty"`
+	Source    string             `bson:"source" json:"source"` // Where the product data came from, e.g. "openfoodfacts"
+	FetchedAt time.Time          `bson:"fetched_at" json:"fetched_at"`
+}
+
+// ValidBarcode reports whether barcode is a well-formed EAN-8, UPC-A, EAN-13 or GTIN-14 code with a correct check digit
+func ValidBarcode(barcode string) bool {
+	switch len(barcode) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+
+	sum := 0
+	for i := len(barcode) - 1; i >= 0; i-- {
+		c := barcode[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		digit := int(c - '0')
+		// Weights alternate 1, 3, 1, ... from the check digit leftwards
+		if (len(barcode)-1-i)%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+	return sum%10 == 0
+}
+
+// productCategoryKeywords maps keywords found in product category tags to pantry categories, most specific first
+var productCategoryKeywords = []struct {
+	keyword  string
+	category string
+}{
+	{"frozen", "Frozen Foods"},
+	{"canned", "Canned Goods"},
+	{"seafood", "Seafood"},
+	{"fish", "Seafood"},
+	{"meat", "Meat & Poultry"},
+	{"poultr", "Meat & Poultry"},
+	{"dair", "Dairy"},
+	{"milk", "Dairy"},
+	{"cheese", "Dairy"},
+	{"yogurt", "Dairy"},
+	{"beverage", "Beverages"},
+	{"drink", "Beverages"},
+	{"juice", "Beverages"},
+	{"pasta", "Pasta & Rice"},
+	{"rice", "Pasta & Rice"},
+	{"bread", "Bread & Bakery"},
+	{"bakery", "Bread & Bakery"},
+	{"cereal", "Grains & Cereals"},
+	{"grain", "Grains & Cereals"},
+	{"snack", "Snacks"},
+	{"sauce", "Condiments & Sauces"},
+	{"condiment", "Condiments & Sauces"},
+	{"spice", "Spices & Seasonings"},
+	{"oil", "Oils & Vinegars"},
+	{"vinegar", "Oils & Vinegars"},
+	{"nut", "Nuts & Seeds"},
+	{"seed", "Nuts & Seeds"},
+	{"fruit", "Fruits"},
+	{"vegetable", "Vegetables"},
+	{"baking", "Baking Supplies"},
+	{"flour", "Baking Supplies"},
+	{"sugar", "Baking Supplies"},
+	{"cleaning", "Cleaning Supplies"},
+	{"hygiene", "Personal Care"},
+	{"cosmetic", "Personal Care"},
+}
+
+// CategoryFromTags picks the pantry category matching product category tags such as "en:dairies", or "Other"
+func CategoryFromTags(tags []string) string {
+	for _, rule := range productCategoryKeywords {
+		for _, tag := range tags {
+			if strings.Contains(strings.ToLower(tag), rule.keyword) {
+				return rule.category
+			}
+		}
+	}
+	return "Other"
+}
+
+// PackageAmount returns how much of the product a number of packages holds, in the product's unit.
+// Products without a known package size are counted as individual items.
+func (p *Product) PackageAmount(packages int) (float64, string) {
+	if p.Quantity <= 0 {
+		return float64(packages), ""
+	}
+	return p.Quantity * float64(packages), p.Unit
+}
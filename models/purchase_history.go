@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PurchaseRecord records a shopping cart item that was bought, kept for budgeting
+type PurchaseRecord struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID         primitive.ObjectID  `bson:"group_id" json:"group_id" validate:"required"`
+	ItemID          primitive.ObjectID  `bson:"item_id" json:"item_id"` // The shopping cart item that was bought
+	ListID          primitive.ObjectID  `bson:"list_id,omitempty" json:"list_id,omitempty"`
+	ItemName        string              `bson:"item_name" json:"item_name" validate:"required"`
+	Quantity        float64             `bson:"quantity" json:"quantity"`
+	Unit            string              `bson:"unit" json:"unit"`
+	Category        string              `bson:"category" json:"category"`
+	CategoryID      *primitive.ObjectID `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	RequestedBy     primitive.ObjectID  `bson:"requested_by" json:"requested_by"` // Member who added the item to the cart
+	PurchasedBy     primitive.ObjectID  `bson:"purchased_by" json:"purchased_by" validate:"required"`
+	PurchasedByName string              `bson:"purchased_by_name" json:"purchased_by_name"`
+	Price           float64             `bson:"price" json:"price" validate:"min=0"`
+	IsShared        bool                `bson:"is_shared" json:"is_shared"`
+	Shares          []CostShare         `bson:"shares" json:"shares"`                             // Who owes what for the item
+	ExpenseID       *primitive.ObjectID `bson:"expense_id,omitempty" json:"expense_id,omitempty"` // Expense the purchase was billed through, if any
+	PurchasedAt     time.Time           `bson:"purchased_at" json:"purchased_at"`
+}
+
+// CreatePurchaseRecord creates a purchase record for a shopping cart item. The price of a shared
+// item is split evenly between members (the whole group or those selected at checkout);
+// a personal item is split between the members who asked for it.
+func CreatePurchaseRecord(item ShoppingCartItem, purchasedBy primitive.ObjectID, purchasedByName string, price float64, members []primitive.ObjectID) *PurchaseRecord {
+	shares := SplitEvenly(price, item.Requesters())
+	if item.IsShared {
+		shares = SplitEvenly(price, members)
+	}
+
+	return &PurchaseRecord{
+		GroupID:         item.GroupID,
+		ItemID:          item.ID,
+		ListID:          item.ListID,
+		ItemName:        item.ItemName,
+		Quantity:        item.Quantity,
+		Unit:            item.Unit,
+		Category:        item.Category,
+		CategoryID:      item.CategoryID,
+		RequestedBy:     item.UserID,
+		PurchasedBy:     purchasedBy,
+		PurchasedByName: purchasedByName,
+		Price:           price,
+		IsShared:        item.IsShared,
+		Shares:          shares,
+		PurchasedAt:     time.Now(),
+	}
+}
+
+// Reprice changes the price of the purchase, splitting it evenly between the members who shared it
+func (p *PurchaseRecord) Reprice(price float64) {
+	members := make([]primitive.ObjectID, 0, len(p.Shares))
+	for _, share := range p.Shares {
+		members = append(members, share.UserID)
+	}
+	if len(members) == 0 {
+		members = append(members, p.PurchasedBy)
+	}
+	p.Price = price
+	p.Shares = SplitEvenly(price, members)
+}
+
+// PurchasesTotal adds up the prices and shares of the purchases billed through one expense
+func PurchasesTotal(purchases []PurchaseRecord) (float64, []CostShare) {
+	total := 0.0
+	shares := make([][]CostShare, 0, len(purchases))
+	for _, purchase := range purchases {
+		total += purchase.Price
+		shares = append(shares, purchase.Shares)
+	}
+	return RoundMoney(total), MergeShares(shares...)
+}
+
+// MonthBounds parses a month in YYYY-MM format and returns its start (inclusive) and end (exclusive) in UTC
+func MonthBounds(month string) (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01", month)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
@@ -0,0 +1,64 @@
+// models/push_queue.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// QueuedPushStatus tracks a queued push through its attempts
+type QueuedPushStatus string
+
+const (
+	QueuedPushPending QueuedPushStatus = "pending" // Waiting for its next attempt
+	QueuedPushSent    QueuedPushStatus = "sent"    // Accepted by the push provider
+	QueuedPushDead    QueuedPushStatus = "dead"    // Given up on; group admins can inspect and retry it
+)
+
+const (
+	// PushMaxAttempts is how often a push is tried before it is dead-lettered
+	PushMaxAttempts = 8
+
+	// PushQueueRetention is how long queued pushes are kept; a TTL index removes older ones
+	PushQueueRetention = 14 * 24 * time.Hour
+
+	pushRetryBaseDelay = 30 * time.Second
+	pushRetryMaxDelay  = 30 * time.Minute
+)
+
+// QueuedPush is a push message on its way to one of a member's devices. Each device gets its own
+// entry, so a retry never repeats the message on devices that already have it.
+type QueuedPush struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	GroupID       primitive.ObjectID `bson:"group_id,omitempty" json:"group_id,omitempty"` // The member's group when it was queued
+	DeviceID      primitive.ObjectID `bson:"device_id" json:"device_id"`
+	Platform      DevicePlatform     `bson:"platform" json:"platform"`
+	Title         string             `bson:"title" json:"title"`
+	Body          string             `bson:"body" json:"body"`
+	Data          map[string]string  `bson:"data,omitempty" json:"data,omitempty"`
+	CollapseKey   string             `bson:"collapse_key,omitempty" json:"collapse_key,omitempty"`
+	Status        QueuedPushStatus   `bson:"status" json:"status"`
+	Attempts      int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	SentAt        *time.Time         `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	DeadAt        *time.Time         `bson:"dead_at,omitempty" json:"dead_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// PushRetryDelay returns how long to wait after the given number of failed attempts, doubling
+// from 30 seconds up to half an hour
+func PushRetryDelay(attempts int) time.Duration {
+	return backoffDelay(attempts, pushRetryBaseDelay, pushRetryMaxDelay)
+}
+
+// IsValidQueuedPushStatus checks if the status is one a queued push can have
+func IsValidQueuedPushStatus(status QueuedPushStatus) bool {
+	switch status {
+	case QueuedPushPending, QueuedPushSent, QueuedPushDead:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+// models/quiet_hours_violation.go
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaxViolationBackdate is how long after a violation members can still log it
+const MaxViolationBackdate = 7 * 24 * time.Hour
+
+// QuietHoursViolation is a member's record of noise during the group's quiet hours
+type QuietHoursViolation struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	ReportedBy primitive.ObjectID `bson:"reported_by" json:"reported_by" validate:"required"`
+	OccurredAt time.Time          `bson:"occurred_at" json:"occurred_at"`
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateQuietHoursViolation logs a violation the member noticed at the given time
+func CreateQuietHoursViolation(groupID, reportedBy primitive.ObjectID, occurredAt time.Time, note string) *QuietHoursViolation {
+	return &QuietHoursViolation{
+		GroupID:    groupID,
+		ReportedBy: reportedBy,
+		OccurredAt: occurredAt,
+		Note:       strings.TrimSpace(note),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// ValidateViolationTime checks that the group has quiet hours, that the violation happened within
+// them and that it is neither in the future nor older than MaxViolationBackdate
+func ValidateViolationTime(quietHours QuietHours, occurredAt, now time.Time) error {
+	switch {
+	case quietHours.Start == "":
+		return errors.New("the group has no quiet hours")
+	case occurredAt.After(now):
+		return errors.New("violations cannot be logged ahead of time")
+	case occurredAt.Before(now.Add(-MaxViolationBackdate)):
+		return errors.New("violations can only be logged within a week")
+	case !quietHours.Contains(occurredAt):
+		return errors.New("the time is outside the group's quiet hours")
+	}
+	return nil
+}
+
+// ViolationCount is how many violations fell into a bucket of the summary
+type ViolationCount struct {
+	Label      string `json:"label"`
+	Violations int    `json:"violations"`
+}
+
+// ViolationReporterTotal is how many violations a member logged
+type ViolationReporterTotal struct {
+	UserID     primitive.ObjectID `json:"user_id"`
+	Violations int                `json:"violations"`
+}
+
+// QuietHoursReport summarizes a group's quiet hours violations over a month
+type QuietHoursReport struct {
+	Month              string                   `json:"month"` // YYYY-MM
+	QuietHours         QuietHours               `json:"quiet_hours"`
+	Violations         int                      `json:"violations"`
+	PreviousViolations int                      `json:"previous_violations"` // Violations the month before, for comparison
+	Nights             int                      `json:"nights"`              // Nights with at least one violation
+	ByWeekday          []ViolationCount         `json:"by_weekday"`          // Monday to Sunday, by the night they fell in
+	ByHour             []ViolationCount         `json:"by_hour"`             // Hours of the quiet hours' time zone with violations, in order
+	Reporters          []ViolationReporterTotal `json:"reporters"`           // Most violations logged first
+}
+
+// violationNight returns the date of the night the violation fell in, in the quiet hours' time
+// zone. Times before noon belong to the night that began the day before.
+func violationNight(t time.Time, location *time.Location) time.Time {
+	local := t.In(location).Add(-12 * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+}
+
+// SummarizeQuietHoursViolations counts the month's violations by night, weekday, hour and the
+// member who logged them, reading times in the quiet hours' time zone
+func SummarizeQuietHoursViolations(month string, quietHours QuietHours, violations []QuietHoursViolation) QuietHoursReport {
+	location, err := time.LoadLocation(quietHours.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	report := QuietHoursReport{
+		Month:      month,
+		QuietHours: quietHours,
+		ByWeekday:  make([]ViolationCount, 7),
+		ByHour:     make([]ViolationCount, 0),
+		Reporters:  make([]ViolationReporterTotal, 0),
+	}
+	for i := range report.ByWeekday {
+		report.ByWeekday[i].Label = time.Weekday((i + 1) % 7).String()
+	}
+
+	nights := make(map[string]bool)
+	var hours [24]int
+	reporters := make(map[primitive.ObjectID]int)
+	for _, violation := range violations {
+		report.Violations++
+
+		night := violationNight(violation.OccurredAt, location)
+		nights[night.Format("2006-01-02")] = true
+		report.ByWeekday[(int(night.Weekday())+6)%7].Violations++
+		hours[violation.OccurredAt.In(location).Hour()]++
+
+		i, ok := reporters[violation.ReportedBy]
+		if !ok {
+			i = len(report.Reporters)
+			reporters[violation.ReportedBy] = i
+			report.Reporters = append(report.Reporters, ViolationReporterTotal{UserID: violation.ReportedBy})
+		}
+		report.Reporters[i].Violations++
+	}
+	report.Nights = len(nights)
+
+	// List the hours from the start of the quiet hours so a window spanning midnight reads in order
+	first := 0
+	if start, err := time.Parse("15:04", quietHours.Start); err == nil {
+		first = start.Hour()
+	}
+	for i := 0; i < 24; i++ {
+		hour := (first + i) % 24
+		if hours[hour] > 0 {
+			report.ByHour = append(report.ByHour, ViolationCount{Label: time.Date(2000, 1, 1, hour, 0, 0, 0, time.UTC).Format("15:04"), Violations: hours[hour]})
+		}
+	}
+
+	sort.SliceStable(report.Reporters, func(i, j int) bool {
+		return report.Reporters[i].Violations > report.Reporters[j].Violations
+	})
+	return report
+}
@@ -0,0 +1,107 @@
+// models/reaction.go
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReactionTarget is the kind of thing members react to
+type ReactionTarget string
+
+const (
+	ReactionTargetCompletion ReactionTarget = "completion" // A completed chore, keyed by the chore's ID
+	ReactionTargetPost       ReactionTarget = "post"       // A bulletin board post
+)
+
+// Limits on reactions
+const (
+	MaxReactionLength         = 8 // Runes, enough for skin tones and joined emoji
+	MaxReactionsPerMember     = 10
+	DefaultCompletionsPerFeed = 20
+)
+
+// Reaction is an emoji a member left on a completion or a post. A member can leave several
+// different emoji on the same thing, but each only once.
+type Reaction struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	TargetType ReactionTarget     `bson:"target_type" json:"target_type" validate:"required"`
+	TargetID   primitive.ObjectID `bson:"target_id" json:"target_id" validate:"required"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id" validate:"required"`
+	Emoji      string             `bson:"emoji" json:"emoji" validate:"required"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// ReactionCount is how many members left an emoji, and whether the requester is one of them
+type ReactionCount struct {
+	Emoji   string               `json:"emoji"`
+	Count   int                  `json:"count"`
+	Reacted bool                 `json:"reacted"`
+	UserIDs []primitive.ObjectID `json:"user_ids"`
+}
+
+// IsValidReactionTarget checks if members can react to the kind of thing
+func IsValidReactionTarget(target ReactionTarget) bool {
+	return target == ReactionTargetCompletion || target == ReactionTargetPost
+}
+
+// ValidateReactionEmoji checks that a reaction is a single emoji rather than text
+func ValidateReactionEmoji(emoji string) error {
+	if emoji == "" || utf8.RuneCountInString(emoji) > MaxReactionLength {
+		return errors.New("reaction must be a single emoji")
+	}
+	for _, r := range emoji {
+		// Emoji and their modifiers all sit above the general punctuation block
+		if r < 0x2000 || unicode.IsLetter(r) || unicode.IsSpace(r) || unicode.IsDigit(r) {
+			return errors.New("reaction must be a single emoji")
+		}
+	}
+	return nil
+}
+
+// CreateReaction creates a member's reaction
+func CreateReaction(groupID primitive.ObjectID, target ReactionTarget, targetID, userID primitive.ObjectID, emoji string) *Reaction {
+	return &Reaction{
+		GroupID:    groupID,
+		TargetType: target,
+		TargetID:   targetID,
+		UserID:     userID,
+		Emoji:      strings.TrimSpace(emoji),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// SummarizeReactions counts the reactions on each target, most used emoji first and ties in the
+// order they were first left
+func SummarizeReactions(reactions []Reaction, userID primitive.ObjectID) map[primitive.ObjectID][]ReactionCount {
+	sorted := make([]Reaction, len(reactions))
+	copy(sorted, reactions)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	summaries := make(map[primitive.ObjectID][]ReactionCount)
+	for _, reaction := range sorted {
+		counts := summaries[reaction.TargetID]
+		i := 0
+		for i < len(counts) && counts[i].Emoji != reaction.Emoji {
+			i++
+		}
+		if i == len(counts) {
+			counts = append(counts, ReactionCount{Emoji: reaction.Emoji, UserIDs: []primitive.ObjectID{}})
+		}
+		counts[i].Count++
+		counts[i].Reacted = counts[i].Reacted || reaction.UserID == userID
+		counts[i].UserIDs = append(counts[i].UserIDs, reaction.UserID)
+		summaries[reaction.TargetID] = counts
+	}
+	for _, counts := range summaries {
+		sort.SliceStable(counts, func(i, j int) bool { return counts[i].Count > counts[j].Count })
+	}
+	return summaries
+}
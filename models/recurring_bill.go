@@ -0,0 +1,159 @@
+// models/recurring_bill.go
+package models
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DefaultBillReminderDays is how many days before a bill is due members are reminded when no value is given
+const DefaultBillReminderDays = 3
+
+// MaxBillReminderDays is the earliest a bill reminder can be sent
+const MaxBillReminderDays = 14
+
+// IsValidBillFrequency checks if a recurring bill can repeat at the frequency
+func IsValidBillFrequency(frequency string) bool {
+	return frequency == "weekly" || frequency == "biweekly" || frequency == "monthly"
+}
+
+// BillParticipant is one member sharing a recurring bill
+type BillParticipant struct {
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Percentage float64            `bson:"percentage,omitempty" json:"percentage,omitempty"` // For the percentage split method
+	Amount     float64            `bson:"amount,omitempty" json:"amount,omitempty"`         // For the exact split method
+}
+
+// RecurringBill is a template for an expense that repeats, such as rent or internet
+type RecurringBill struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID         primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Description     string             `bson:"description" json:"description"`
+	Amount          float64            `bson:"amount" json:"amount"`
+	PaidBy          primitive.ObjectID `bson:"paid_by" json:"paid_by"` // Member who pays the bill and is owed the shares
+	SplitMethod     SplitMethod        `bson:"split_method" json:"split_method"`
+	Category        ExpenseCategory    `bson:"category,omitempty" json:"category,omitempty"`
+	Participants    []BillParticipant  `bson:"participants,omitempty" json:"participants,omitempty"`         // Empty splits evenly across the whole group
+	UsesRentConfig  bool               `bson:"uses_rent_config,omitempty" json:"uses_rent_config,omitempty"` // Amount and split follow the group's rent configuration
+	UsageMeter      MeterType          `bson:"usage_meter,omitempty" json:"usage_meter,omitempty"`           // Split by each member's usage on this meter when readings allow
+	Frequency       string             `bson:"frequency" json:"frequency"`                                   // weekly, biweekly or monthly
+	NextDueDate     time.Time          `bson:"next_due_date" json:"next_due_date"`                           // When the next expense is created
+	ReminderDays    int                `bson:"reminder_days" json:"reminder_days"`                           // 0 turns reminders off
+	RemindedFor     *time.Time         `bson:"reminded_for,omitempty" json:"reminded_for,omitempty"`         // Due date members were last reminded of
+	OccurrenceCount int                `bson:"occurrence_count" json:"occurrence_count"`                     // Expenses created so far
+	IsActive        bool               `bson:"is_active" json:"is_active"`
+	CreatedBy       primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// sharingMembers returns the bill's participants, or all members when it has none
+func (b *RecurringBill) sharingMembers(members []primitive.ObjectID) []primitive.ObjectID {
+	if len(b.Participants) == 0 {
+		return members
+	}
+	sharing := make([]primitive.ObjectID, 0, len(b.Participants))
+	for _, participant := range b.Participants {
+		sharing = append(sharing, participant.UserID)
+	}
+	return sharing
+}
+
+// IsRent reports whether the bill is rent, which members can get texted about
+func (b *RecurringBill) IsRent() bool {
+	return b.UsesRentConfig || b.Category == ExpenseCategoryRent
+}
+
+// CreateRecurringBill creates a new active recurring bill, first due on firstDueDate
+func CreateRecurringBill(groupID, paidBy primitive.ObjectID, description string, amount float64, frequency string, firstDueDate time.Time) *RecurringBill {
+	return &RecurringBill{
+		GroupID:      groupID,
+		Description:  description,
+		Amount:       amount,
+		PaidBy:       paidBy,
+		SplitMethod:  SplitMethodEqual,
+		Frequency:    frequency,
+		NextDueDate:  firstDueDate,
+		ReminderDays: DefaultBillReminderDays,
+		IsActive:     true,
+		CreatedBy:    paidBy,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+}
+
+// FirstBillDueDate returns the first day on or after now that falls on dayOfMonth, at midnight UTC
+func FirstBillDueDate(now time.Time, dayOfMonth int) time.Time {
+	now = now.UTC()
+	due := time.Date(now.Year(), now.Month(), dayOfMonth, 0, 0, 0, 0, time.UTC)
+	if due.Before(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)) {
+		due = due.AddDate(0, 1, 0)
+	}
+	return due
+}
+
+// Splits divides one occurrence of the bill between its participants, or evenly across members
+// when the bill has none
+func (b *RecurringBill) Splits(members []primitive.ObjectID) ([]CostShare, error) {
+	if len(b.Participants) == 0 {
+		if b.SplitMethod != SplitMethodEqual {
+			return nil, errors.New("participants are required for percentage and exact splits")
+		}
+		if len(members) == 0 {
+			return nil, errors.New("the group has no members to split the bill between")
+		}
+		return SplitEvenly(b.Amount, members), nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(b.Participants))
+	for _, participant := range b.Participants {
+		ids = append(ids, participant.UserID)
+	}
+
+	switch b.SplitMethod {
+	case SplitMethodPercentage:
+		percentages := make([]float64, 0, len(b.Participants))
+		for _, participant := range b.Participants {
+			percentages = append(percentages, participant.Percentage)
+		}
+		return SplitByPercentage(b.Amount, ids, percentages)
+	case SplitMethodExact:
+		splits := make([]CostShare, 0, len(b.Participants))
+		for _, participant := range b.Participants {
+			splits = append(splits, CostShare{UserID: participant.UserID, Amount: participant.Amount})
+		}
+		if err := CheckExactSplit(b.Amount, splits); err != nil {
+			return nil, err
+		}
+		return splits, nil
+	default:
+		return SplitEvenly(b.Amount, ids), nil
+	}
+}
+
+// ExpenseFor creates the expense for the occurrence of the bill due on dueDate
+func (b *RecurringBill) ExpenseFor(dueDate time.Time, splits []CostShare) *Expense {
+	expense := CreateExpense(b.GroupID, b.PaidBy, b.Description, b.Amount, splits, ExpenseSourceRecurring)
+	expense.SplitMethod = b.SplitMethod
+	if b.Category != "" {
+		expense.Category = b.Category
+	}
+	expense.CreatedBy = b.CreatedBy
+	expense.CreatedAt = dueDate
+	billID := b.ID
+	expense.RecurringBillID = &billID
+	return expense
+}
+
+// NeedsReminder reports whether members should be reminded at now of the bill's next due date
+func (b *RecurringBill) NeedsReminder(now time.Time) bool {
+	if !b.IsActive || b.ReminderDays <= 0 || !now.Before(b.NextDueDate) {
+		return false
+	}
+	if b.RemindedFor != nil && b.RemindedFor.Equal(b.NextDueDate) {
+		return false
+	}
+	return !now.Before(b.NextDueDate.AddDate(0, 0, -b.ReminderDays))
+}
@@ -0,0 +1,151 @@
+// models/rent_config.go
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RentSplitMethod tells how rent is divided between members
+type RentSplitMethod string
+
+const (
+	RentSplitPercentage RentSplitMethod = "percentage" // Each member pays an agreed percentage
+	RentSplitRoomSize   RentSplitMethod = "room_size"  // Each member pays in proportion to the size of their room
+)
+
+// RentShare is one member's part of the rent
+type RentShare struct {
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name       string             `bson:"name" json:"name"`
+	Room       string             `bson:"room,omitempty" json:"room,omitempty"`
+	Percentage float64            `bson:"percentage,omitempty" json:"percentage,omitempty"` // For the percentage split method
+	RoomSize   float64            `bson:"room_size,omitempty" json:"room_size,omitempty"`   // For the room size split method, in any unit
+	Amount     float64            `bson:"amount" json:"amount"`                             // What the member pays per bill, see ComputeShares
+}
+
+// RentConfig is a version of a group's rent agreement. Versions are never changed; each edit adds
+// a new one, numbered from 1 per group, so members can look back at who paid what and when.
+type RentConfig struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID       primitive.ObjectID `bson:"group_id" json:"group_id"`
+	Version       int                `bson:"version" json:"version"`
+	Amount        float64            `bson:"amount" json:"amount"` // Total rent per bill
+	Method        RentSplitMethod    `bson:"method" json:"method"`
+	Shares        []RentShare        `bson:"shares" json:"shares"`
+	EffectiveFrom time.Time          `bson:"effective_from" json:"effective_from"` // First due date the version applies to
+	Note          string             `bson:"note,omitempty" json:"note,omitempty"`
+	UpdatedBy     primitive.ObjectID `bson:"updated_by" json:"updated_by"`
+	UpdatedByName string             `bson:"updated_by_name" json:"updated_by_name"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// percentages returns each member's percentage of the rent, derived from room sizes for the room
+// size split method
+func (c RentConfig) percentages() ([]primitive.ObjectID, []float64, error) {
+	if len(c.Shares) == 0 {
+		return nil, nil, errors.New("rent needs at least one member's share")
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(c.Shares))
+	percentages := make([]float64, 0, len(c.Shares))
+	seen := make(map[primitive.ObjectID]bool)
+	totalSize := 0.0
+	for _, share := range c.Shares {
+		if seen[share.UserID] {
+			return nil, nil, errors.New("each member can only have one share of the rent")
+		}
+		seen[share.UserID] = true
+		ids = append(ids, share.UserID)
+
+		switch c.Method {
+		case RentSplitPercentage:
+			percentages = append(percentages, share.Percentage)
+		case RentSplitRoomSize:
+			if share.RoomSize <= 0 {
+				return nil, nil, errors.New("every room size must be positive")
+			}
+			totalSize += share.RoomSize
+		default:
+			return nil, nil, errors.New("rent split method must be percentage or room_size")
+		}
+	}
+
+	if c.Method == RentSplitRoomSize {
+		for _, share := range c.Shares {
+			percentages = append(percentages, share.RoomSize/totalSize*100)
+		}
+	}
+	return ids, percentages, nil
+}
+
+// ComputeShares checks the configuration and sets what each member pays per bill, to the cent
+func (c *RentConfig) ComputeShares() error {
+	c.Amount = RoundMoney(c.Amount)
+	if c.Amount <= 0 {
+		return errors.New("rent must be positive")
+	}
+
+	ids, percentages, err := c.percentages()
+	if err != nil {
+		return err
+	}
+	splits, err := SplitByPercentage(c.Amount, ids, percentages)
+	if err != nil {
+		return err
+	}
+	for i := range c.Shares {
+		c.Shares[i].Amount = splits[i].Amount
+	}
+	return nil
+}
+
+// RentConfigAt returns the version in effect on date: the latest version effective on or before
+// it, or nil when none is
+func RentConfigAt(configs []RentConfig, date time.Time) *RentConfig {
+	var current *RentConfig
+	for i := range configs {
+		config := &configs[i]
+		if config.EffectiveFrom.After(date) {
+			continue
+		}
+		if current == nil || config.EffectiveFrom.After(current.EffectiveFrom) ||
+			(config.EffectiveFrom.Equal(current.EffectiveFrom) && config.Version > current.Version) {
+			current = config
+		}
+	}
+	return current
+}
+
+// ApplyRentConfig sets the bill's amount and split to the rent configuration's
+func (b *RecurringBill) ApplyRentConfig(config RentConfig) {
+	b.Amount = config.Amount
+	b.SplitMethod = SplitMethodExact
+	b.Participants = make([]BillParticipant, 0, len(config.Shares))
+	for _, share := range config.Shares {
+		b.Participants = append(b.Participants, BillParticipant{UserID: share.UserID, Amount: share.Amount})
+	}
+}
+
+// LoadRentConfigs fetches every version of a group's rent configuration, newest first
+func LoadRentConfigs(ctx context.Context, db *mongo.Database, groupID primitive.ObjectID) ([]RentConfig, error) {
+	cursor, err := db.Collection("rent_configs").Find(
+		ctx,
+		bson.M{"group_id": groupID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]RentConfig, 0)
+	if err := cursor.All(ctx, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
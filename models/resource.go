@@ -0,0 +1,176 @@
+// models/resource.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ResourceKind is what a bookable resource is
+type ResourceKind string
+
+const (
+	ResourceLaundry  ResourceKind = "laundry"
+	ResourceParking  ResourceKind = "parking"
+	ResourceBathroom ResourceKind = "bathroom"
+	ResourceOther    ResourceKind = "other"
+)
+
+// NotificationTypeBookingCancelled tells a member their booking of a resource was cancelled by
+// someone else or because the resource was removed
+const NotificationTypeBookingCancelled NotificationType = "booking_cancelled"
+
+// Limits on resources and bookings
+const (
+	MinResourceSlotMinutes     = 15
+	MaxResourceSlotsAtOnce     = 12 // Highest number of slots a resource can let one booking take
+	DefaultResourceSlotsAtOnce = 1
+	MaxResourceBookingAhead    = 30 * 24 * time.Hour
+	MaxResourcesPerGroup       = 20
+)
+
+// BookableResource is something members of a group take turns using, booked in fixed slots such as
+// an hour on the washing machine or an evening in the parking space
+type BookableResource struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Name        string             `bson:"name" json:"name" validate:"required"`
+	Kind        ResourceKind       `bson:"kind" json:"kind"`
+	SlotMinutes int                `bson:"slot_minutes" json:"slot_minutes"`                 // Slots start at midnight and every SlotMinutes after
+	OpenFrom    string             `bson:"open_from,omitempty" json:"open_from,omitempty"`   // HH:MM; empty with OpenUntil means always open
+	OpenUntil   string             `bson:"open_until,omitempty" json:"open_until,omitempty"` // HH:MM
+	TimeZone    string             `bson:"time_zone,omitempty" json:"time_zone,omitempty"`   // IANA name slots and hours are in; defaults to UTC
+	SlotsAtOnce int                `bson:"slots_at_once" json:"slots_at_once"`               // Most consecutive slots one booking can take
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// ResourceBooking reserves consecutive slots of a resource for a member. Slots holds the start of
+// every slot booked; a unique index on the resource and its slots keeps bookings from overlapping.
+type ResourceBooking struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ResourceID primitive.ObjectID `bson:"resource_id" json:"resource_id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Slots      []time.Time        `bson:"slots" json:"-"`
+	StartsAt   time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt     time.Time          `bson:"ends_at" json:"ends_at"`
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// IsValidResourceKind checks if the kind is a known kind of resource
+func IsValidResourceKind(kind ResourceKind) bool {
+	switch kind {
+	case ResourceLaundry, ResourceParking, ResourceBathroom, ResourceOther:
+		return true
+	}
+	return false
+}
+
+// Validate checks the resource's slots, opening hours and time zone
+func (r BookableResource) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name is required")
+	}
+	if !IsValidResourceKind(r.Kind) {
+		return errors.New("kind must be laundry, parking, bathroom or other")
+	}
+	if r.SlotMinutes < MinResourceSlotMinutes || r.SlotMinutes > 24*60 || (24*60)%r.SlotMinutes != 0 {
+		return fmt.Errorf("slots must be at least %d minutes and divide the day evenly", MinResourceSlotMinutes)
+	}
+	if r.SlotsAtOnce < 1 || r.SlotsAtOnce > MaxResourceSlotsAtOnce {
+		return fmt.Errorf("slots at once must be between 1 and %d", MaxResourceSlotsAtOnce)
+	}
+	if _, err := time.LoadLocation(r.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", r.TimeZone)
+	}
+	if r.OpenFrom == "" && r.OpenUntil == "" {
+		return nil
+	}
+	from, errFrom := time.Parse("15:04", r.OpenFrom)
+	until, errUntil := time.Parse("15:04", r.OpenUntil)
+	if errFrom != nil || errUntil != nil {
+		return errors.New("opening hours need a start and an end in HH:MM format")
+	}
+	if !until.After(from) {
+		return errors.New("opening hours must end after they start on the same day")
+	}
+	return nil
+}
+
+// zone returns the time zone the resource's slots are in
+func (r BookableResource) zone() *time.Location {
+	if location, err := time.LoadLocation(r.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// BookingSlots returns the starts of count consecutive slots from start, checking that start is
+// the start of a slot and that every slot is within the opening hours
+func (r BookableResource) BookingSlots(start time.Time, count int) ([]time.Time, error) {
+	if count < 1 || count > r.SlotsAtOnce {
+		return nil, fmt.Errorf("a booking can take 1 to %d slots", r.SlotsAtOnce)
+	}
+	local := start.In(r.zone())
+	minute := local.Hour()*60 + local.Minute()
+	if local.Second() != 0 || local.Nanosecond() != 0 || minute%r.SlotMinutes != 0 {
+		return nil, fmt.Errorf("bookings must start on a %d minute slot", r.SlotMinutes)
+	}
+
+	slotLength := time.Duration(r.SlotMinutes) * time.Minute
+	slots := make([]time.Time, 0, count)
+	for i := 0; i < count; i++ {
+		slot := local.Add(time.Duration(i) * slotLength)
+		if !r.isOpen(slot, slotLength) {
+			return nil, fmt.Errorf("%s can only be booked from %s to %s", r.Name, r.OpenFrom, r.OpenUntil)
+		}
+		slots = append(slots, slot.UTC())
+	}
+	return slots, nil
+}
+
+// isOpen reports whether the slot starting at the local time lies within the opening hours
+func (r BookableResource) isOpen(slot time.Time, length time.Duration) bool {
+	if r.OpenFrom == "" {
+		return true
+	}
+	from, _ := time.Parse("15:04", r.OpenFrom)
+	until, _ := time.Parse("15:04", r.OpenUntil)
+	opens := time.Date(slot.Year(), slot.Month(), slot.Day(), from.Hour(), from.Minute(), 0, 0, slot.Location())
+	closes := time.Date(slot.Year(), slot.Month(), slot.Day(), until.Hour(), until.Minute(), 0, 0, slot.Location())
+	return !slot.Before(opens) && !slot.Add(length).After(closes)
+}
+
+// CreateResourceBooking books the slots, which must be consecutive slots of the resource
+func CreateResourceBooking(resource BookableResource, userID primitive.ObjectID, slots []time.Time, note string) *ResourceBooking {
+	return &ResourceBooking{
+		ResourceID: resource.ID,
+		GroupID:    resource.GroupID,
+		UserID:     userID,
+		Slots:      slots,
+		StartsAt:   slots[0],
+		EndsAt:     slots[len(slots)-1].Add(time.Duration(resource.SlotMinutes) * time.Minute),
+		Note:       strings.TrimSpace(note),
+		CreatedAt:  time.Now(),
+	}
+}
+
+// BookingCancelledNotification tells the member who made the booking that it was cancelled, by
+// the named member or, when cancelledBy is empty, because the resource was removed
+func BookingCancelledNotification(booking ResourceBooking, resource BookableResource, cancelledBy string) *Notification {
+	start := booking.StartsAt.In(resource.zone())
+	params := NotificationParams{
+		"resource": resource.Name,
+		"date":     NotificationDate(start),
+		"time":     start.Format("15:04"),
+		"name":     cancelledBy,
+	}
+	return CreateTemplatedNotification(booking.UserID, booking.GroupID, NotificationTypeBookingCancelled, TemplateBookingCancelled, params)
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RedemptionStatus represents where a reward redemption is in the approval process
+type RedemptionStatus string
+
+const (
+	RedemptionStatusPending  RedemptionStatus = "pending"
+	RedemptionStatusApproved RedemptionStatus = "approved"
+	RedemptionStatusRejected RedemptionStatus = "rejected"
+)
+
+var (
+	// ErrInsufficientPoints is returned when a member redeems a reward that costs more than their score
+	ErrInsufficientPoints = errors.New("not enough points to redeem this reward")
+	// ErrRedemptionNotPending is returned when a redemption that was already reviewed is reviewed again
+	ErrRedemptionNotPending = errors.New("redemption has already been reviewed")
+	// ErrRedemptionSelfReview is returned when an admin reviews their own redemption in a shared group
+	ErrRedemptionSelfReview = errors.New("admins cannot review their own redemptions")
+)
+
+// Reward is something a group lets members buy with their points
+type Reward struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Name        string             `bson:"name" json:"name" validate:"required"`
+	Description string             `bson:"description" json:"description"`
+	Cost        int                `bson:"cost" json:"cost" validate:"required,min=1"`
+	IsActive    bool               `bson:"is_active" json:"is_active"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// Redemption records a member spending points on a reward.
+// Points are deducted when it is requested and refunded if it is rejected.
+type Redemption struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	RewardID   primitive.ObjectID  `bson:"reward_id" json:"reward_id"`
+	RewardName string              `bson:"reward_name" json:"reward_name"`
+	GroupID    primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Cost       int                 `bson:"cost" json:"cost"`
+	Status     RedemptionStatus    `bson:"status" json:"status"`
+	ReviewedBy *primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time          `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	Note       string              `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// CreateReward creates a new active reward for a group
+func CreateReward(groupID, createdBy primitive.ObjectID, name, description string, cost int) *Reward {
+	return &Reward{
+		GroupID:     groupID,
+		Name:        name,
+		Description: description,
+		Cost:        cost,
+		IsActive:    true,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+}
+
+// CreateRedemption creates a pending redemption of a reward by a user
+func CreateRedemption(reward *Reward, userID primitive.ObjectID) *Redemption {
+	return &Redemption{
+		RewardID:   reward.ID,
+		RewardName: reward.Name,
+		GroupID:    reward.GroupID,
+		UserID:     userID,
+		Cost:       reward.Cost,
+		Status:     RedemptionStatusPending,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// Redeem deducts the reward's cost from the user's score and returns the pending redemption with the
+// score event recording the deduction. The redemption's ID is filled in once it is stored; the event
+// refers to it then.
+func (r *Reward) Redeem(user *User) (*Redemption, *ScoreEvent, error) {
+	if user.Score < r.Cost {
+		return nil, nil, ErrInsufficientPoints
+	}
+	user.Score -= r.Cost
+
+	redemption := CreateRedemption(r, user.ID)
+	event := CreateScoreEvent(user.ID, r.GroupID, ScoreEventRedemption, -r.Cost, user.Score, fmt.Sprintf("Redeemed %s", r.Name))
+	return redemption, event, nil
+}
+
+// Review approves or rejects a pending redemption. Admins can only review their own redemptions when
+// they are the group's only member.
+func (r *Redemption) Review(reviewerID primitive.ObjectID, memberCount int, approve bool, note string, now time.Time) error {
+	if r.Status != RedemptionStatusPending {
+		return ErrRedemptionNotPending
+	}
+	if r.UserID == reviewerID && memberCount > 1 {
+		return ErrRedemptionSelfReview
+	}
+
+	r.Status = RedemptionStatusRejected
+	if approve {
+		r.Status = RedemptionStatusApproved
+	}
+	r.ReviewedBy = &reviewerID
+	r.ReviewedAt = &now
+	r.Note = note
+	return nil
+}
+
+// Refund returns the cost of a rejected redemption to the user's score and returns the score event
+// recording it. Other redemptions are not refunded and return nil.
+func (r *Redemption) Refund(user *User) *ScoreEvent {
+	if r.Status != RedemptionStatusRejected || user.ID != r.UserID {
+		return nil
+	}
+	user.Score += r.Cost
+
+	event := CreateScoreEvent(r.UserID, r.GroupID, ScoreEventRefund, r.Cost, user.Score,
+		fmt.Sprintf("Refund for rejected %s redemption", r.RewardName))
+	event.ReferenceID = &r.ID
+	return event
+}
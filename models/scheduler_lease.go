@@ -0,0 +1,51 @@
+// models/scheduler_lease.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SchedulerLease is the lease document stored in the scheduler_leases collection. The backend
+// instance holding an unexpired lease is the one that runs scheduled jobs.
+type SchedulerLease struct {
+	ID        string    `bson:"_id" json:"id"`
+	Holder    string    `bson:"holder" json:"holder"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// CanAcquire reports whether holder may take the lease at now: nobody holds it, holder already
+// does, or the last holder let it expire
+func (l *SchedulerLease) CanAcquire(holder string, now time.Time) bool {
+	return l.Holder == "" || l.Holder == holder || l.ExpiresAt.Before(now)
+}
+
+// Acquire takes or renews the lease for holder until now plus duration, and reports whether it could
+func (l *SchedulerLease) Acquire(holder string, now time.Time, duration time.Duration) bool {
+	if !l.CanAcquire(holder, now) {
+		return false
+	}
+	l.Holder = holder
+	l.ExpiresAt = now.Add(duration)
+	l.UpdatedAt = now
+	return true
+}
+
+// HeldBy reports whether holder has the lease at now
+func (l *SchedulerLease) HeldBy(holder string, now time.Time) bool {
+	return l.Holder == holder && now.Before(l.ExpiresAt)
+}
+
+// AcquireFilter matches the stored lease when holder may take it at now, by the same rule as
+// CanAcquire, so that claiming it with an upsert is atomic
+func (l *SchedulerLease) AcquireFilter(holder string, now time.Time) bson.M {
+	return bson.M{
+		"_id": l.ID,
+		"$or": []bson.M{
+			{"holder": holder},
+			{"expires_at": bson.M{"$lt": now}},
+		},
+	}
+}
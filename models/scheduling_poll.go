@@ -0,0 +1,252 @@
+// models/scheduling_poll.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PollStatus is where a scheduling poll is in its life
+type PollStatus string
+
+const (
+	PollOpen    PollStatus = "open"
+	PollDecided PollStatus = "decided" // Voting closed and a slot won
+	PollClosed  PollStatus = "closed"  // Voting closed without anyone being available
+)
+
+const (
+	// NotificationTypePollCreated asks members to vote on when suits them
+	NotificationTypePollCreated NotificationType = "poll_created"
+
+	// NotificationTypePollDecided tells members which slot a poll picked
+	NotificationTypePollDecided NotificationType = "poll_decided"
+)
+
+// Limits on scheduling polls
+const (
+	MinPollSlots            = 2
+	MaxPollSlots            = 10
+	DefaultPollVotingPeriod = 48 * time.Hour
+	MaxPollSlotDuration     = 24 * time.Hour
+)
+
+// PollSlot is a time proposed in a poll
+type PollSlot struct {
+	ID       primitive.ObjectID `bson:"id" json:"id"`
+	StartsAt time.Time          `bson:"starts_at" json:"starts_at"`
+	EndsAt   time.Time          `bson:"ends_at" json:"ends_at"`
+}
+
+// PollVote is the slots a member is available for
+type PollVote struct {
+	UserID  primitive.ObjectID   `bson:"user_id" json:"user_id"`
+	SlotIDs []primitive.ObjectID `bson:"slot_ids" json:"slot_ids"`
+	VotedAt time.Time            `bson:"voted_at" json:"voted_at"`
+}
+
+// SchedulingPoll asks members which of a few proposed times suit them, for things like a deep
+// clean or a house meeting. When voting closes the slot most members can make wins, and the
+// winner can be turned into a chore or a house event.
+type SchedulingPoll struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	GroupID       primitive.ObjectID  `bson:"group_id" json:"group_id" validate:"required"`
+	Title         string              `bson:"title" json:"title" validate:"required"`
+	Description   string              `bson:"description,omitempty" json:"description,omitempty"`
+	TimeZone      string              `bson:"time_zone,omitempty" json:"time_zone,omitempty"` // IANA name slots are written in for notifications; defaults to UTC
+	Slots         []PollSlot          `bson:"slots" json:"slots"`                             // Earliest first
+	Votes         []PollVote          `bson:"votes" json:"votes"`
+	Status        PollStatus          `bson:"status" json:"status"`
+	ClosesAt      time.Time           `bson:"closes_at" json:"closes_at"`
+	WinningSlotID *primitive.ObjectID `bson:"winning_slot_id,omitempty" json:"winning_slot_id,omitempty"`
+	DecidedAt     *time.Time          `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+	ChoreID       *primitive.ObjectID `bson:"chore_id,omitempty" json:"chore_id,omitempty"`             // Set once the winner is made a chore
+	HouseEventID  *primitive.ObjectID `bson:"house_event_id,omitempty" json:"house_event_id,omitempty"` // Set once the winner is made a house event
+	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateSchedulingPoll creates an open poll over the slots, sorted earliest first
+func CreateSchedulingPoll(groupID, createdBy primitive.ObjectID, title, description, timeZone string, slots []PollSlot, closesAt time.Time) *SchedulingPoll {
+	now := time.Now()
+	proposed := make([]PollSlot, len(slots))
+	for i, slot := range slots {
+		proposed[i] = PollSlot{ID: primitive.NewObjectID(), StartsAt: slot.StartsAt, EndsAt: slot.EndsAt}
+	}
+	sort.SliceStable(proposed, func(i, j int) bool {
+		return proposed[i].StartsAt.Before(proposed[j].StartsAt)
+	})
+	return &SchedulingPoll{
+		GroupID:     groupID,
+		Title:       title,
+		Description: description,
+		TimeZone:    timeZone,
+		Slots:       proposed,
+		Votes:       []PollVote{},
+		Status:      PollOpen,
+		ClosesAt:    closesAt,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ValidatePollSlots checks the proposed slots and that voting closes after now and before the
+// earliest slot starts
+func ValidatePollSlots(slots []PollSlot, closesAt, now time.Time) error {
+	if len(slots) < MinPollSlots || len(slots) > MaxPollSlots {
+		return fmt.Errorf("polls need between %d and %d slots", MinPollSlots, MaxPollSlots)
+	}
+	if !closesAt.After(now) {
+		return errors.New("voting must close in the future")
+	}
+	seen := make(map[int64]bool, len(slots))
+	for _, slot := range slots {
+		switch {
+		case slot.StartsAt.IsZero() || slot.EndsAt.IsZero():
+			return errors.New("every slot needs a start and an end")
+		case !slot.EndsAt.After(slot.StartsAt):
+			return errors.New("slots must end after they start")
+		case slot.EndsAt.Sub(slot.StartsAt) > MaxPollSlotDuration:
+			return fmt.Errorf("slots cannot last longer than %d hours", int(MaxPollSlotDuration.Hours()))
+		case slot.StartsAt.Before(closesAt):
+			return errors.New("voting must close before the earliest slot starts")
+		case seen[slot.StartsAt.Unix()]:
+			return errors.New("two slots cannot start at the same time")
+		}
+		seen[slot.StartsAt.Unix()] = true
+	}
+	return nil
+}
+
+// Slot returns the poll's slot with the ID
+func (p *SchedulingPoll) Slot(slotID primitive.ObjectID) (PollSlot, bool) {
+	for _, slot := range p.Slots {
+		if slot.ID == slotID {
+			return slot, true
+		}
+	}
+	return PollSlot{}, false
+}
+
+// WinningSlot returns the slot the poll picked, if it has been decided
+func (p *SchedulingPoll) WinningSlot() (PollSlot, bool) {
+	if p.WinningSlotID == nil {
+		return PollSlot{}, false
+	}
+	return p.Slot(*p.WinningSlotID)
+}
+
+// SetVote records the slots the member is available for, replacing an earlier vote. Slots that
+// are not in the poll are an error; an empty list means the member can make none of them.
+func (p *SchedulingPoll) SetVote(userID primitive.ObjectID, slotIDs []primitive.ObjectID, now time.Time) error {
+	available := make([]primitive.ObjectID, 0, len(slotIDs))
+	for _, slotID := range slotIDs {
+		if _, ok := p.Slot(slotID); !ok {
+			return fmt.Errorf("slot %s is not in the poll", slotID.Hex())
+		}
+		duplicate := false
+		for _, id := range available {
+			duplicate = duplicate || id == slotID
+		}
+		if !duplicate {
+			available = append(available, slotID)
+		}
+	}
+
+	for i := range p.Votes {
+		if p.Votes[i].UserID == userID {
+			p.Votes[i].SlotIDs = available
+			p.Votes[i].VotedAt = now
+			return nil
+		}
+	}
+	p.Votes = append(p.Votes, PollVote{UserID: userID, SlotIDs: available, VotedAt: now})
+	return nil
+}
+
+// Available returns the members who voted for the slot
+func (p *SchedulingPoll) Available(slotID primitive.ObjectID) []primitive.ObjectID {
+	members := make([]primitive.ObjectID, 0)
+	for _, vote := range p.Votes {
+		for _, id := range vote.SlotIDs {
+			if id == slotID {
+				members = append(members, vote.UserID)
+				break
+			}
+		}
+	}
+	return members
+}
+
+// AllVoted reports whether every member has voted
+func (p *SchedulingPoll) AllVoted(members []primitive.ObjectID) bool {
+	for _, memberID := range members {
+		voted := false
+		for _, vote := range p.Votes {
+			voted = voted || vote.UserID == memberID
+		}
+		if !voted {
+			return false
+		}
+	}
+	return true
+}
+
+// Decide closes voting, picking the slot the most members are available for. Ties go to the
+// earliest slot; with no votes for any slot the poll closes without a winner.
+func (p *SchedulingPoll) Decide(now time.Time) {
+	best, bestVotes := -1, 0
+	for i, slot := range p.Slots {
+		if votes := len(p.Available(slot.ID)); votes > bestVotes {
+			best, bestVotes = i, votes
+		}
+	}
+	p.Status = PollClosed
+	p.WinningSlotID = nil
+	if best >= 0 {
+		p.Status = PollDecided
+		p.WinningSlotID = &p.Slots[best].ID
+	}
+	p.DecidedAt = &now
+	p.UpdatedAt = now
+}
+
+// zone returns the time zone the poll's slots are written in
+func (p *SchedulingPoll) zone() *time.Location {
+	if location, err := time.LoadLocation(p.TimeZone); err == nil {
+		return location
+	}
+	return time.UTC
+}
+
+// PollCreatedNotification asks a member to vote in the poll
+func PollCreatedNotification(poll *SchedulingPoll, userID primitive.ObjectID, creatorName string) *Notification {
+	closes := poll.ClosesAt.In(poll.zone())
+	params := NotificationParams{
+		"name":   creatorName,
+		"poll":   poll.Title,
+		"slots":  strconv.Itoa(len(poll.Slots)),
+		"closes": NotificationDate(closes),
+		"time":   closes.Format("15:04"),
+	}
+	return CreateTemplatedNotification(userID, poll.GroupID, NotificationTypePollCreated, TemplatePollCreated, params)
+}
+
+// PollDecidedNotification tells a member which slot the poll picked, or that none was picked
+func PollDecidedNotification(poll *SchedulingPoll, userID primitive.ObjectID) *Notification {
+	params := NotificationParams{"poll": poll.Title}
+	if slot, ok := poll.WinningSlot(); ok {
+		start := slot.StartsAt.In(poll.zone())
+		params["date"] = NotificationDate(start)
+		params["time"] = start.Format("15:04")
+		params["votes"] = strconv.Itoa(len(poll.Available(slot.ID)))
+	}
+	return CreateTemplatedNotification(userID, poll.GroupID, NotificationTypePollDecided, TemplatePollDecided, params)
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScoreEventType describes why a user's score changed
+type ScoreEventType string
+
+const (
+	ScoreEventInitial    ScoreEventType = "initial"    // Starting points given at registration
+	ScoreEventCompletion ScoreEventType = "completion" // Points earned by completing a chore
+	ScoreEventPenalty    ScoreEventType = "penalty"    // Points lost for a missed or late chore
+	ScoreEventDecay      ScoreEventType = "decay"      // Points lost through inactivity
+	ScoreEventAdjustment ScoreEventType = "adjustment" // Manual correction by a group admin
+	ScoreEventReset      ScoreEventType = "reset"      // Score cleared when leaving a group
+	ScoreEventRedemption ScoreEventType = "redemption" // Points spent on a reward
+	ScoreEventRefund     ScoreEventType = "refund"     // Points returned for a rejected redemption
+)
+
+// ScoreEvent records a single change to a user's score so score history stays explainable
+type ScoreEvent struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID      primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	GroupID     primitive.ObjectID  `bson:"group_id" json:"group_id"`
+	Type        ScoreEventType      `bson:"type" json:"type"`
+	Delta       int                 `bson:"delta" json:"delta"`
+	ScoreAfter  int                 `bson:"score_after" json:"score_after"`
+	Reason      string              `bson:"reason" json:"reason"`
+	ReferenceID *primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id,omitempty"` // Chore, reward, etc. that caused the change
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// CreateScoreEvent creates a record of a score change
+func CreateScoreEvent(userID, groupID primitive.ObjectID, eventType ScoreEventType, delta, scoreAfter int, reason string) *ScoreEvent {
+	return &ScoreEvent{
+		UserID:     userID,
+		GroupID:    groupID,
+		Type:       eventType,
+		Delta:      delta,
+		ScoreAfter: scoreAfter,
+		Reason:     reason,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// ScoreEventTypes lists every reason a score can change, for validating filters
+var ScoreEventTypes = []ScoreEventType{
+	ScoreEventInitial, ScoreEventCompletion, ScoreEventPenalty, ScoreEventDecay, ScoreEventAdjustment,
+	ScoreEventReset, ScoreEventRedemption, ScoreEventRefund, ScoreEventKudos,
+}
+
+const (
+	// DefaultScoreHistoryLimit is how many score events the history returns when no limit is given
+	DefaultScoreHistoryLimit = 50
+	// MaxScoreHistoryLimit caps the limit clients can ask the history for
+	MaxScoreHistoryLimit = 500
+)
+
+// ScoreHistoryQuery selects the most recent score events of a user, optionally of one type
+type ScoreHistoryQuery struct {
+	Limit int64
+	Type  ScoreEventType
+}
+
+// ParseScoreHistoryQuery validates the limit and type query parameters; empty values use the defaults
+func ParseScoreHistoryQuery(limit, eventType string) (ScoreHistoryQuery, error) {
+	query := ScoreHistoryQuery{Limit: DefaultScoreHistoryLimit, Type: ScoreEventType(eventType)}
+
+	if limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || parsed < 1 || parsed > MaxScoreHistoryLimit {
+			return query, errors.New("limit must be between 1 and 500")
+		}
+		query.Limit = parsed
+	}
+
+	if query.Type != "" && !slices.Contains(ScoreEventTypes, query.Type) {
+		return query, errors.New("unknown score event type")
+	}
+
+	return query, nil
+}
+
+// Filter matches the score events of the user the query selects
+func (q ScoreHistoryQuery) Filter(userID primitive.ObjectID) bson.M {
+	filter := bson.M{"user_id": userID}
+	if q.Type != "" {
+		filter["type"] = q.Type
+	}
+	return filter
+}
+
+// CanViewScoreHistory reports whether requester may see the score history of target: their own,
+// or that of a member of the same group. Users without a group only see their own.
+func CanViewScoreHistory(requester, target *User) bool {
+	if requester.ID == target.ID {
+		return true
+	}
+	return !requester.GroupID.IsZero() && requester.GroupID == target.GroupID
+}
+
+// DecayAmount returns how many points a score loses to a decay of percent, at least one point while the score is positive
+func DecayAmount(score, percent int) int {
+	if score <= 0 || percent <= 0 {
+		return 0
+	}
+	amount := score * percent / 100
+	if amount < 1 {
+		amount = 1
+	}
+	if amount > score {
+		amount = score
+	}
+	return amount
+}
+
+// ShouldDecay reports whether a user inactive since lastActivity is due another decay.
+// Decay starts after inactiveWeeks without completions and then repeats once per further inactive week.
+func ShouldDecay(lastActivity, lastDecay, now time.Time, inactiveWeeks int) bool {
+	if inactiveWeeks < 1 {
+		inactiveWeeks = 1
+	}
+	week := 7 * 24 * time.Hour
+	if now.Sub(lastActivity) < time.Duration(inactiveWeeks)*week {
+		return false
+	}
+	// Decays from before the latest activity belong to an earlier inactive spell
+	if lastDecay.After(lastActivity) && now.Sub(lastDecay) < week {
+		return false
+	}
+	return true
+}
+
+// ScoreGranularity is the bucket size of a score time series
+type ScoreGranularity string
+
+const (
+	ScoreGranularityDay  ScoreGranularity = "day"
+	ScoreGranularityWeek ScoreGranularity = "week"
+)
+
+// ScoreBucket summarizes the score changes within one bucket of a time series
+type ScoreBucket struct {
+	Start  time.Time `bson:"_id" json:"start"`
+	Delta  int       `bson:"delta" json:"delta"`   // Net change within the bucket
+	Score  int       `bson:"score" json:"score"`   // Score at the end of the bucket
+	Events int       `bson:"events" json:"events"` // Number of score changes within the bucket
+}
+
+// BucketStart returns the start of the bucket containing t, with weeks starting on Monday (UTC)
+func BucketStart(granularity ScoreGranularity, t time.Time) time.Time {
+	if granularity == ScoreGranularityWeek {
+		return WeekStartUTC(t)
+	}
+	return startOfDayUTC(t)
+}
+
+// FillScoreBuckets returns one bucket per period from from through to, carrying the
+// score forward across periods without changes so charts have no gaps.
+// buckets must be sorted by start; initialScore is the score before from.
+func FillScoreBuckets(buckets []ScoreBucket, granularity ScoreGranularity, from, to time.Time, initialScore int) []ScoreBucket {
+	byStart := make(map[time.Time]ScoreBucket, len(buckets))
+	for _, b := range buckets {
+		byStart[BucketStart(granularity, b.Start)] = b
+	}
+
+	filled := make([]ScoreBucket, 0)
+	score := initialScore
+	for start := BucketStart(granularity, from); !start.After(to); {
+		if b, ok := byStart[start]; ok {
+			b.Start = start
+			score = b.Score
+			filled = append(filled, b)
+		} else {
+			filled = append(filled, ScoreBucket{Start: start, Score: score})
+		}
+		if granularity == ScoreGranularityWeek {
+			start = start.AddDate(0, 0, 7)
+		} else {
+			start = start.AddDate(0, 0, 1)
+		}
+	}
+	return filled
+}
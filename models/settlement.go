@@ -0,0 +1,320 @@
+// models/settlement.go
+package models
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Settlement records one member paying another back outside the app
+type Settlement struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID    primitive.ObjectID `bson:"group_id" json:"group_id"`
+	FromUserID primitive.ObjectID `bson:"from_user_id" json:"from_user_id"` // Member who paid
+	ToUserID   primitive.ObjectID `bson:"to_user_id" json:"to_user_id"`     // Member who was paid
+	Amount     float64            `bson:"amount" json:"amount"`
+	Note       string             `bson:"note,omitempty" json:"note,omitempty"`
+	OwedBefore float64            `bson:"owed_before,omitempty" json:"owed_before,omitempty"` // What the payer owed the group before paying
+	Remaining  float64            `bson:"remaining" json:"remaining"`                         // What the payer still owed afterwards; 0 when settled in full
+	ExternalID string             `bson:"external_id,omitempty" json:"external_id,omitempty"` // The same repayment in another app, e.g. splitwise:123, once imported or sent there
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateSettlement records that from paid to the amount
+func CreateSettlement(groupID, from, to primitive.ObjectID, amount float64, createdBy primitive.ObjectID) *Settlement {
+	return &Settlement{
+		GroupID:    groupID,
+		FromUserID: from,
+		ToUserID:   to,
+		Amount:     amount,
+		CreatedBy:  createdBy,
+		CreatedAt:  time.Now(),
+	}
+}
+
+// RecordBalance notes what the payer owed before the repayment and what is left to pay after it
+func (s *Settlement) RecordBalance(owedBefore float64) {
+	s.OwedBefore = RoundMoney(owedBefore)
+	s.Remaining = math.Max(RoundMoney(owedBefore-s.Amount), 0)
+}
+
+// IsPartial reports whether the payer still owed money after the repayment
+func (s Settlement) IsPartial() bool {
+	return s.Remaining > 0
+}
+
+// PaymentHistory is what two members have paid each other back and what one still owes the other
+type PaymentHistory struct {
+	From        primitive.ObjectID `json:"from_user_id"`
+	FromName    string             `json:"from_name"`
+	To          primitive.ObjectID `json:"to_user_id"`
+	ToName      string             `json:"to_name"`
+	Settlements []Settlement       `json:"settlements"` // Repayments either way, newest first
+	Paid        float64            `json:"paid"`        // Total From paid To
+	Received    float64            `json:"received"`    // Total To paid From
+	Remaining   float64            `json:"remaining"`   // What From still owes To directly, see PairwiseDebts; negative when To owes From
+}
+
+// BuildPaymentHistory collects the repayments between two members and nets what they still owe
+// each other from the group's expenses and settlements
+func BuildPaymentHistory(from, to primitive.ObjectID, expenses []Expense, settlements []Settlement) PaymentHistory {
+	history := PaymentHistory{From: from, To: to, Settlements: make([]Settlement, 0)}
+	for _, settlement := range settlements {
+		switch {
+		case settlement.FromUserID == from && settlement.ToUserID == to:
+			history.Paid += settlement.Amount
+		case settlement.FromUserID == to && settlement.ToUserID == from:
+			history.Received += settlement.Amount
+		default:
+			continue
+		}
+		history.Settlements = append(history.Settlements, settlement)
+	}
+	sort.SliceStable(history.Settlements, func(i, j int) bool {
+		return history.Settlements[i].CreatedAt.After(history.Settlements[j].CreatedAt)
+	})
+	history.Paid = RoundMoney(history.Paid)
+	history.Received = RoundMoney(history.Received)
+
+	for _, debt := range PairwiseDebts(expenses, settlements) {
+		switch {
+		case debt.From == from && debt.To == to:
+			history.Remaining = debt.Amount
+		case debt.From == to && debt.To == from:
+			history.Remaining = -debt.Amount
+		}
+	}
+	return history
+}
+
+// Debt is an amount one member should pay another to settle up
+type Debt struct {
+	From     primitive.ObjectID `json:"from_user_id"`
+	FromName string             `json:"from_name"`
+	To       primitive.ObjectID `json:"to_user_id"`
+	ToName   string             `json:"to_name"`
+	Amount   float64            `json:"amount"`
+
+	// PaymentLinks open payment apps prefilled to pay the member who is owed
+	PaymentLinks []PaymentLink `json:"payment_links,omitempty"`
+
+	// Sources are the expenses and settlements behind a direct debt, see PairwiseDebts
+	Sources []DebtSource `json:"sources,omitempty"`
+}
+
+// maxExactSimplification is the most members with a balance for which SimplifyDebts searches for
+// the fewest payments; the search doubles in cost with every member
+const maxExactSimplification = 16
+
+// DebtSource is an expense or settlement behind a debt between two members
+type DebtSource struct {
+	Type         string              `json:"type"` // expense or settlement
+	ExpenseID    *primitive.ObjectID `json:"expense_id,omitempty"`
+	SettlementID *primitive.ObjectID `json:"settlement_id,omitempty"`
+	Description  string              `json:"description"`
+	Date         time.Time           `json:"date"`
+	Amount       float64             `json:"amount"` // What it adds to the debt; negative when it reduces it
+}
+
+// SimplifyDebts settles the balances with the fewest payments. Members whose balances cancel out
+// among themselves settle within their own circle, since every circle of k members needs k-1
+// payments; within a circle the member who owes the most repeatedly pays the member who is owed the
+// most. Large groups skip the search for circles and are settled as one.
+func SimplifyDebts(balances []MemberBalance) []Debt {
+	type position struct {
+		balance *MemberBalance
+		cents   int64
+	}
+	positions := make([]position, 0)
+	total := int64(0)
+	for i := range balances {
+		if cents := int64(math.Round(balances[i].Net * 100)); cents != 0 {
+			positions = append(positions, position{&balances[i], cents})
+			total += cents
+		}
+	}
+
+	circles := [][]position{positions}
+	if total == 0 && len(positions) <= maxExactSimplification {
+		cents := make([]int64, len(positions))
+		for i, p := range positions {
+			cents[i] = p.cents
+		}
+		circles = circles[:0]
+		for _, circle := range zeroSumCircles(cents) {
+			members := make([]position, 0, len(circle))
+			for _, i := range circle {
+				members = append(members, positions[i])
+			}
+			circles = append(circles, members)
+		}
+	}
+
+	largestFirst := func(positions []position) {
+		sort.SliceStable(positions, func(i, j int) bool { return positions[i].cents > positions[j].cents })
+	}
+	debts := make([]Debt, 0)
+	for _, circle := range circles {
+		debtors := make([]position, 0)
+		creditors := make([]position, 0)
+		for _, p := range circle {
+			if p.cents < 0 {
+				debtors = append(debtors, position{p.balance, -p.cents})
+			} else {
+				creditors = append(creditors, p)
+			}
+		}
+
+		for len(debtors) > 0 && len(creditors) > 0 {
+			largestFirst(debtors)
+			largestFirst(creditors)
+			debtor, creditor := &debtors[0], &creditors[0]
+
+			cents := min(debtor.cents, creditor.cents)
+			debts = append(debts, Debt{
+				From:     debtor.balance.UserID,
+				FromName: debtor.balance.Name,
+				To:       creditor.balance.UserID,
+				ToName:   creditor.balance.Name,
+				Amount:   float64(cents) / 100,
+			})
+
+			debtor.cents -= cents
+			creditor.cents -= cents
+			if debtor.cents == 0 {
+				debtors = debtors[1:]
+			}
+			if creditor.cents == 0 {
+				creditors = creditors[1:]
+			}
+		}
+	}
+	return debts
+}
+
+// zeroSumCircles splits balances that add up to zero into as many groups adding up to zero as
+// possible, returned as indexes in their original order. It tries every subset, so it is only
+// meant for a handful of balances.
+func zeroSumCircles(cents []int64) [][]int {
+	n := len(cents)
+	full := 1<<n - 1
+	sums := make([]int64, full+1)
+	circles := make([]int, full+1) // Most zero-sum groups the members of each subset can form
+	for mask := 1; mask <= full; mask++ {
+		low := mask & -mask
+		sums[mask] = sums[mask^low] + cents[bits.TrailingZeros(uint(low))]
+		for rest := mask; rest != 0; rest &= rest - 1 {
+			circles[mask] = max(circles[mask], circles[mask^(rest&-rest)])
+		}
+		if sums[mask] == 0 {
+			circles[mask]++
+		}
+	}
+
+	// Peel members off the full set one at a time along the best choices; the members removed
+	// between two subsets adding up to zero form a group
+	groups := make([][]int, 0, circles[full])
+	current := make([]int, 0)
+	for mask := full; mask != 0; {
+		zero := 0
+		if sums[mask] == 0 {
+			zero = 1
+		}
+		for i := 0; i < n; i++ {
+			if bit := 1 << i; mask&bit != 0 && circles[mask^bit]+zero == circles[mask] {
+				current = append(current, i)
+				mask ^= bit
+				break
+			}
+		}
+		if sums[mask] == 0 {
+			sort.Ints(current)
+			groups = append(groups, current)
+			current = make([]int, 0)
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+	return groups
+}
+
+// PairwiseDebts nets what each pair of members owes one another directly: a member owes the payer
+// of every expense they had a share in, less what was repaid between the two. Disputed expenses are
+// left out. Each debt lists the expenses and settlements behind it, in the order given.
+func PairwiseDebts(expenses []Expense, settlements []Settlement) []Debt {
+	type pair struct{ a, b primitive.ObjectID } // a owes b when the debt is positive
+	debts := make(map[pair]*Debt)
+	order := make([]pair, 0)
+	add := func(debtor, creditor primitive.ObjectID, amount float64, source DebtSource) {
+		if debtor == creditor || amount == 0 {
+			return
+		}
+		key := pair{debtor, creditor}
+		if creditor.Hex() < debtor.Hex() {
+			key = pair{creditor, debtor}
+			amount = -amount
+		}
+		debt, ok := debts[key]
+		if !ok {
+			debt = &Debt{From: key.a, To: key.b}
+			debts[key] = debt
+			order = append(order, key)
+		}
+		debt.Amount += amount
+		source.Amount = amount
+		debt.Sources = append(debt.Sources, source)
+	}
+
+	for i := range expenses {
+		expense := &expenses[i]
+		if expense.IsDisputed() {
+			continue
+		}
+		for _, share := range expense.Splits {
+			add(share.UserID, expense.PaidBy, share.Amount, DebtSource{
+				Type: "expense", ExpenseID: &expense.ID, Description: expense.Description, Date: expense.CreatedAt,
+			})
+		}
+	}
+	for i := range settlements {
+		settlement := &settlements[i]
+		add(settlement.FromUserID, settlement.ToUserID, -settlement.Amount, DebtSource{
+			Type: "settlement", SettlementID: &settlement.ID, Description: settlement.Note, Date: settlement.CreatedAt,
+		})
+	}
+
+	result := make([]Debt, 0, len(order))
+	for _, key := range order {
+		debt := *debts[key]
+		debt.Amount = RoundMoney(debt.Amount)
+		if debt.Amount == 0 {
+			continue
+		}
+		// Sources are kept from the point of view of whoever ends up owing
+		if debt.Amount < 0 {
+			debt.From, debt.To, debt.Amount = debt.To, debt.From, -debt.Amount
+			for i := range debt.Sources {
+				debt.Sources[i].Amount = -debt.Sources[i].Amount
+			}
+		}
+		for i := range debt.Sources {
+			debt.Sources[i].Amount = RoundMoney(debt.Sources[i].Amount)
+		}
+		result = append(result, debt)
+	}
+	return result
+}
+
+// FindBalance returns the balance of a member, or nil when they have none
+func FindBalance(balances []MemberBalance, userID primitive.ObjectID) *MemberBalance {
+	for i := range balances {
+		if balances[i].UserID == userID {
+			return &balances[i]
+		}
+	}
+	return nil
+}
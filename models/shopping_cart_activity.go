@@ -19,6 +19,9 @@ const (
 
 	// CartActivityTypeDelete indicates an item was removed from the shopping cart
 	CartActivityTypeDelete CartActivityType = "delete"
+
+	// CartActivityTypePurchase indicates an item was bought and moved to the purchase history
+	CartActivityTypePurchase CartActivityType = "purchase"
 )
 
 // ShoppingCartActivity represents a record of changes to a shopping cart item
@@ -36,6 +39,7 @@ type ShoppingCartActivity struct {
 	IsRead    bool                 `bson:"is_read" json:"is_read"`
 	ReadBy    []primitive.ObjectID `bson:"read_by" json:"read_by"`
 	ExpiresAt time.Time            `bson:"expires_at" json:"expires_at"`
+	PushedAt  *time.Time           `bson:"pushed_at,omitempty" json:"-"` // When members' devices were told about it
 }
 
 // CreateShoppingCartActivity creates a new shopping cart activity record
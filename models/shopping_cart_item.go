@@ -1,20 +1,31 @@
 package models
 
 import (
+	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ShoppingCartItem represents an item in a user's shopping cart
 type ShoppingCartItem struct {
-	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID   primitive.ObjectID `bson:"user_id" json:"user_id" validate:"required"`
-	GroupID  primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
-	ItemName string             `bson:"item_name" json:"item_name" validate:"required"`
-	Quantity float64            `bson:"quantity" json:"quantity" validate:"required,min=0.1"`
-	Category string             `bson:"category" json:"category"`
-	AddedAt  time.Time          `bson:"added_at" json:"added_at"`
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id" validate:"required"` // Member who first added the item
+	GroupID        primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	ListID         primitive.ObjectID `bson:"list_id,omitempty" json:"list_id,omitempty"` // Unset for the group's default list
+	ItemName       string             `bson:"item_name" json:"item_name" validate:"required"`
+	NormalizedName string             `bson:"normalized_name" json:"-"` // Identifies the item within a list
+	Quantity       float64            `bson:"quantity" json:"quantity" validate:"required,min=0.1"`
+	Unit           string             `bson:"unit" json:"unit"` // e.g. "lb" or "dozen"; empty for individual items
+	Category       string             `bson:"category" json:"category"`
+	// CategoryID references a pantry category; Category then holds its name
+	CategoryID  *primitive.ObjectID  `bson:"category_id,omitempty" json:"category_id,omitempty"`
+	IsShared    bool                 `bson:"is_shared" json:"is_shared"`                           // Personal items are not split between members
+	RequestedBy []primitive.ObjectID `bson:"requested_by,omitempty" json:"requested_by,omitempty"` // Every member who added the item
+	AddedAt     time.Time            `bson:"added_at" json:"added_at"`
 }
 
 // CreateShoppingCartItem creates a new shopping cart item
@@ -23,19 +34,148 @@ func CreateShoppingCartItem(
 	groupID primitive.ObjectID,
 	itemName string,
 	quantity float64,
+	unit string,
 	category string,
 ) *ShoppingCartItem {
 	return &ShoppingCartItem{
-		UserID:   userID,
-		GroupID:  groupID,
-		ItemName: itemName,
-		Quantity: quantity,
-		Category: category,
-		AddedAt:  time.Now(),
+		UserID:         userID,
+		GroupID:        groupID,
+		ItemName:       itemName,
+		NormalizedName: NormalizeItemName(itemName),
+		Quantity:       quantity,
+		Unit:           NormalizeUnit(unit),
+		Category:       category,
+		IsShared:       true,
+		RequestedBy:    []primitive.ObjectID{userID},
+		AddedAt:        time.Now(),
 	}
 }
 
+// NormalizeItemName returns the form of an item name used to detect duplicates:
+// lowercased with surrounding and repeated whitespace removed
+func NormalizeItemName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// Requesters returns the members who asked for the item. Items from before several
+// requesters were tracked only have their owner.
+func (s *ShoppingCartItem) Requesters() []primitive.ObjectID {
+	if len(s.RequestedBy) == 0 {
+		return []primitive.ObjectID{s.UserID}
+	}
+	return s.RequestedBy
+}
+
+// DuplicateFilter matches the item an add of s merges into: the same normalized name on the same
+// list, whichever member added it, with the same sharing. Shared and personal items are never
+// merged, so a personal item's cost is not split and a shared item's is.
+func (s *ShoppingCartItem) DuplicateFilter() bson.M {
+	return bson.M{
+		"group_id":        s.GroupID,
+		"list_id":         ListFilter(s.ListID),
+		"normalized_name": NormalizeItemName(s.ItemName),
+		"is_shared":       s.IsShared,
+	}
+}
+
+// MergeDuplicates folds duplicates of the same item into keeper, converting quantities into
+// keeper's unit and collecting every requester. It returns the IDs of the duplicates that were
+// merged; duplicates whose units cannot be converted or whose sharing differs are left out.
+func MergeDuplicates(keeper *ShoppingCartItem, duplicates []ShoppingCartItem) []primitive.ObjectID {
+	requesters := append([]primitive.ObjectID{}, keeper.Requesters()...)
+	seen := make(map[primitive.ObjectID]bool, len(requesters))
+	for _, id := range requesters {
+		seen[id] = true
+	}
+
+	merged := make([]primitive.ObjectID, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		if duplicate.IsShared != keeper.IsShared {
+			continue
+		}
+		converted, ok := ConvertQuantity(duplicate.Quantity, duplicate.Unit, keeper.Unit)
+		if !ok {
+			continue
+		}
+		keeper.Quantity += converted
+		for _, id := range duplicate.Requesters() {
+			if !seen[id] {
+				seen[id] = true
+				requesters = append(requesters, id)
+			}
+		}
+		merged = append(merged, duplicate.ID)
+	}
+
+	keeper.RequestedBy = requesters
+	return merged
+}
+
 // UpdateQuantity updates the item's quantity
 func (s *ShoppingCartItem) UpdateQuantity(newQuantity float64) {
 	s.Quantity = newQuantity
 }
+
+// FormatQuantity returns the quantity with its unit for display, e.g. "2.00 lb"
+func (s *ShoppingCartItem) FormatQuantity() string {
+	if s.Unit == "" {
+		return fmt.Sprintf("%.2f", s.Quantity)
+	}
+	return fmt.Sprintf("%.2f %s", s.Quantity, s.Unit)
+}
+
+// ListFilter returns the filter value matching items of a list; the zero ID matches the default list
+func ListFilter(listID primitive.ObjectID) interface{} {
+	if listID.IsZero() {
+		return nil
+	}
+	return listID
+}
+
+// CategoryKey identifies the item's category for grouping: its category ID when set, otherwise
+// its lowercased category name. Uncategorized items have an empty key.
+func (s *ShoppingCartItem) CategoryKey() string {
+	if s.CategoryID != nil {
+		return s.CategoryID.Hex()
+	}
+	return strings.ToLower(strings.TrimSpace(s.Category))
+}
+
+// SortByAisle orders items so each category's items are together, following the group's aisle
+// order first, then the remaining categories by name, with uncategorized items last. Items
+// within a category are sorted by name.
+func SortByAisle(items []ShoppingCartItem, aisleOrder []primitive.ObjectID) {
+	aisle := make(map[primitive.ObjectID]int, len(aisleOrder))
+	for i, id := range aisleOrder {
+		if _, ok := aisle[id]; !ok {
+			aisle[id] = i
+		}
+	}
+
+	rank := func(item *ShoppingCartItem) (int, string) {
+		if item.CategoryID != nil {
+			if position, ok := aisle[*item.CategoryID]; ok {
+				return position, ""
+			}
+		}
+		if item.CategoryKey() == "" {
+			return len(aisle) + 1, ""
+		}
+		return len(aisle), strings.ToLower(item.Category)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		ri, ni := rank(&items[i])
+		rj, nj := rank(&items[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if ni != nj {
+			return ni < nj
+		}
+		if ki, kj := items[i].CategoryKey(), items[j].CategoryKey(); ki != kj {
+			return ki < kj
+		}
+		return strings.ToLower(items[i].ItemName) < strings.ToLower(items[j].ItemName)
+	})
+}
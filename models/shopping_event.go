@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShoppingEvent is pushed live to group members watching the shopping list
+type ShoppingEvent struct {
+	Type     CartActivityType   `json:"type"`
+	GroupID  primitive.ObjectID `json:"group_id"`
+	ItemID   primitive.ObjectID `json:"item_id"`
+	ItemName string             `json:"item_name"`
+	UserID   primitive.ObjectID `json:"user_id"`
+	UserName string             `json:"user_name"`
+	Quantity float64            `json:"quantity"`
+	Details  string             `json:"details,omitempty"`
+	Item     *ShoppingCartItem  `json:"item,omitempty"` // Current item state for adds and updates
+	At       time.Time          `json:"at"`
+}
+
+// ShoppingEventFromActivity builds the live event for a recorded shopping cart activity
+func ShoppingEventFromActivity(activity ShoppingCartActivity) ShoppingEvent {
+	return ShoppingEvent{
+		Type:     activity.Action,
+		GroupID:  activity.GroupID,
+		ItemID:   activity.ItemID,
+		ItemName: activity.ItemName,
+		UserID:   activity.UserID,
+		UserName: activity.UserName,
+		Quantity: activity.Quantity,
+		Details:  activity.Details,
+		At:       activity.CreatedAt,
+	}
+}
+
+// IncludesItem reports whether the item still exists after the event, so its current state can be attached
+func (e ShoppingEvent) IncludesItem() bool {
+	return e.Type == CartActivityTypeAdd || e.Type == CartActivityTypeUpdate
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShoppingList is a named list of shopping cart items within a group, e.g. "Costco run".
+// Items without a list belong to the group's default list.
+type ShoppingList struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	Name      string             `bson:"name" json:"name" validate:"required"`
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateShoppingList creates a new shopping list for a group
+func CreateShoppingList(groupID, createdBy primitive.ObjectID, name string) *ShoppingList {
+	return &ShoppingList{
+		GroupID:   groupID,
+		Name:      name,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
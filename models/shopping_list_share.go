@@ -0,0 +1,128 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShoppingListFormat is a plain-text rendering of a shopping list
+type ShoppingListFormat string
+
+const (
+	ShoppingListFormatText     ShoppingListFormat = "text"
+	ShoppingListFormatMarkdown ShoppingListFormat = "markdown"
+)
+
+// Limits on how long a public shopping list link stays valid
+const (
+	DefaultShareLinkHours = 72
+	MaxShareLinkHours     = 30 * 24
+)
+
+// ShoppingListShare is a read-only public link to a group's shopping list, for members
+// without the app. Expired shares are removed by a TTL index on expires_at.
+type ShoppingListShare struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Token     string             `bson:"token" json:"token"`
+	GroupID   primitive.ObjectID `bson:"group_id" json:"group_id" validate:"required"`
+	ListID    primitive.ObjectID `bson:"list_id,omitempty" json:"list_id,omitempty"` // Zero for the default list
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateShoppingListShare creates a share with a random, unguessable token valid for ttl
+func CreateShoppingListShare(groupID, listID, createdBy primitive.ObjectID, ttl time.Duration) (*ShoppingListShare, error) {
+	token := make([]byte, 24)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &ShoppingListShare{
+		Token:     hex.EncodeToString(token),
+		GroupID:   groupID,
+		ListID:    listID,
+		CreatedBy: createdBy,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, nil
+}
+
+// IsExpired reports whether the share can no longer be viewed. The TTL index only removes
+// expired shares periodically, so this is checked on every view.
+func (s *ShoppingListShare) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// IsValidShoppingListFormat reports whether format is a supported rendering
+func IsValidShoppingListFormat(format ShoppingListFormat) bool {
+	return format == ShoppingListFormatText || format == ShoppingListFormatMarkdown
+}
+
+// RenderShoppingList renders items as a titled list grouped by category, in text that can be
+// pasted into a chat. Markdown renders the items as a checklist.
+func RenderShoppingList(title string, items []ShoppingCartItem, format ShoppingListFormat) string {
+	sorted := make([]ShoppingCartItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, cj := strings.ToLower(sorted[i].Category), strings.ToLower(sorted[j].Category)
+		if ci != cj {
+			// Uncategorized items go last
+			if ci == "" || cj == "" {
+				return cj == ""
+			}
+			return ci < cj
+		}
+		return strings.ToLower(sorted[i].ItemName) < strings.ToLower(sorted[j].ItemName)
+	})
+
+	var b strings.Builder
+	if format == ShoppingListFormatMarkdown {
+		fmt.Fprintf(&b, "# %s\n", title)
+	} else {
+		fmt.Fprintf(&b, "%s\n", title)
+	}
+
+	if len(sorted) == 0 {
+		b.WriteString("\nNothing to buy.\n")
+		return b.String()
+	}
+
+	for i, item := range sorted {
+		category := item.Category
+		if category == "" {
+			category = "Other"
+		}
+		if i == 0 || !strings.EqualFold(sorted[i-1].Category, item.Category) {
+			if format == ShoppingListFormatMarkdown {
+				fmt.Fprintf(&b, "\n## %s\n", category)
+			} else {
+				fmt.Fprintf(&b, "\n%s:\n", category)
+			}
+		}
+
+		bullet := "- "
+		if format == ShoppingListFormatMarkdown {
+			bullet = "- [ ] "
+		}
+		fmt.Fprintf(&b, "%s%s (%s)\n", bullet, item.ItemName, shareQuantity(item.Quantity, item.Unit))
+	}
+	return b.String()
+}
+
+// shareQuantity formats a quantity without trailing zeros, e.g. "2 lb" or "1.5"
+func shareQuantity(quantity float64, unit string) string {
+	formatted := strconv.FormatFloat(quantity, 'f', -1, 64)
+	if unit == "" {
+		return formatted
+	}
+	return formatted + " " + unit
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StreakStats tracks consecutive periods in which a user completed every assigned chore on time
+type StreakStats struct {
+	Daily      int `bson:"daily" json:"daily"`
+	BestDaily  int `bson:"best_daily" json:"best_daily"`
+	Weekly     int `bson:"weekly" json:"weekly"`
+	BestWeekly int `bson:"best_weekly" json:"best_weekly"`
+}
+
+// PeriodOutcome is the result of a user's chores for one day or week
+type PeriodOutcome int
+
+const (
+	PeriodNoChores  PeriodOutcome = iota // Nothing was due, the streak is left as is
+	PeriodAllOnTime                      // Everything due was completed on time, the streak grows
+	PeriodMissed                         // Something due was late or not done, the streak resets
+)
+
+// ClassifyPeriod decides the outcome of the chores due in a period given when each was completed
+func ClassifyPeriod(chores []Chore, completedAt map[primitive.ObjectID]time.Time) PeriodOutcome {
+	if len(chores) == 0 {
+		return PeriodNoChores
+	}
+	for _, chore := range chores {
+		done, ok := completedAt[chore.ID]
+		if !ok || done.After(chore.DueDate) {
+			return PeriodMissed
+		}
+	}
+	return PeriodAllOnTime
+}
+
+// ApplyDaily updates the daily streak with the outcome of one day
+func (s *StreakStats) ApplyDaily(outcome PeriodOutcome) {
+	s.Daily, s.BestDaily = applyOutcome(s.Daily, s.BestDaily, outcome)
+}
+
+// ApplyWeekly updates the weekly streak with the outcome of one week
+func (s *StreakStats) ApplyWeekly(outcome PeriodOutcome) {
+	s.Weekly, s.BestWeekly = applyOutcome(s.Weekly, s.BestWeekly, outcome)
+}
+
+func applyOutcome(current, best int, outcome PeriodOutcome) (int, int) {
+	switch outcome {
+	case PeriodAllOnTime:
+		current++
+		if current > best {
+			best = current
+		}
+	case PeriodMissed:
+		current = 0
+	}
+	return current, best
+}
+
+// WeekStartUTC returns midnight UTC on the Monday of the week containing t
+func WeekStartUTC(t time.Time) time.Time {
+	day := startOfDayUTC(t)
+	offset := (int(day.Weekday()) + 6) % 7 // Monday is 0
+	return day.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// unitDefinition places a canonical unit within a dimension, with its size in the dimension's base unit
+type unitDefinition struct {
+	dimension string
+	factor    float64
+}
+
+// knownUnits maps canonical unit names to their dimension; count is based on single items
+var knownUnits = map[string]unitDefinition{
+	"":      {"count", 1},
+	"dozen": {"count", 12},
+	"g":     {"mass", 1},
+	"kg":    {"mass", 1000},
+	"oz":    {"mass", 28.349523125},
+	"lb":    {"mass", 453.59237},
+	"ml":    {"volume", 1},
+	"l":     {"volume", 1000},
+	"fl oz": {"volume", 29.5735295625},
+	"cup":   {"volume", 236.5882365},
+	"gal":   {"volume", 3785.411784},
+}
+
+// unitAliases maps common spellings to canonical unit names
+var unitAliases = map[string]string{
+	"each": "", "ea": "", "item": "", "items": "", "pc": "", "pcs": "", "piece": "", "pieces": "", "count": "",
+	"dz": "dozen", "dozens": "dozen",
+	"gram": "g", "grams": "g",
+	"kilogram": "kg", "kilograms": "kg", "kgs": "kg",
+	"ounce": "oz", "ounces": "oz",
+	"lbs": "lb", "pound": "lb", "pounds": "lb",
+	"milliliter": "ml", "milliliters": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l",
+	"floz": "fl oz", "fluid ounce": "fl oz", "fluid ounces": "fl oz",
+	"cups":   "cup",
+	"gallon": "gal", "gallons": "gal",
+}
+
+// NormalizeUnit returns the canonical spelling of a unit. Unknown units are lowercased and kept
+// so free-form units like "bunch" still work; an empty unit means individual items.
+func NormalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if canonical, ok := unitAliases[unit]; ok {
+		return canonical
+	}
+	return unit
+}
+
+// ConvertQuantity converts quantity from one unit to another. It reports false when the
+// units measure different things (e.g. pounds and liters) or either is unknown and they differ.
+func ConvertQuantity(quantity float64, from, to string) (float64, bool) {
+	from, to = NormalizeUnit(from), NormalizeUnit(to)
+	if from == to {
+		return quantity, true
+	}
+
+	fromDef, fromKnown := knownUnits[from]
+	toDef, toKnown := knownUnits[to]
+	if !fromKnown || !toKnown || fromDef.dimension != toDef.dimension {
+		return 0, false
+	}
+	return quantity * fromDef.factor / toDef.factor, true
+}
+
+// quantityPattern matches amounts written with their unit, such as "500g", "1.5 L" or "3"
+var quantityPattern = regexp.MustCompile(`^\s*([0-9]+(?:[.,][0-9]+)?)\s*([a-zA-Z ]*?)\s*$`)
+
+// ParseQuantity splits an amount like "500 g" into its quantity and normalized unit. A bare
+// number counts individual items. It reports false when the text is not an amount.
+func ParseQuantity(text string) (float64, string, bool) {
+	match := quantityPattern.FindStringSubmatch(text)
+	if match == nil {
+		return 0, "", false
+	}
+	quantity, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return quantity, NormalizeUnit(match[2]), true
+}
@@ -1,22 +1,72 @@
 package models
 
 import (
+	"net/mail"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Username    string             `bson:"username" json:"username"`
-	Password    string             `bson:"password" json:"-"`
-	Name        string             `bson:"name" json:"name"`
-	PhoneNumber string             `bson:"phone_number" json:"phone_number"`
-	RoomNumber  string             `bson:"room_number" json:"room_number"`
-	Score       int                `bson:"score" json:"score"`
-	Group       string             `bson:"group" json:"group"`
-	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id"`
-	GroupCode   string             `bson:"group_code" json:"group_code"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username     string             `bson:"username" json:"username"`
+	Password     string             `bson:"password" json:"-"`
+	Name         string             `bson:"name" json:"name"`
+	PhoneNumber  string             `bson:"phone_number" json:"phone_number"`
+	RoomNumber   string             `bson:"room_number" json:"room_number"`
+	Score        int                `bson:"score" json:"score"`
+	WeeklyScore  int                `bson:"weekly_score" json:"weekly_score"`   // Points earned in the current week
+	MonthlyScore int                `bson:"monthly_score" json:"monthly_score"` // Points earned in the current month
+	Group        string             `bson:"group" json:"group"`
+	GroupID      primitive.ObjectID `bson:"group_id" json:"group_id"`
+	GroupCode    string             `bson:"group_code" json:"group_code"`
+	Streak       StreakStats        `bson:"streak" json:"streak"`
+	Preferences  UserPreferences    `bson:"preferences" json:"preferences"`
+	LastDigestAt *time.Time         `bson:"last_digest_at,omitempty" json:"-"` // When the weekly digest was last emailed
+	CalendarKey  string             `bson:"calendar_key,omitempty" json:"-"`   // Signs the member's calendar feed URL
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// EmailAddress returns the address emails to the user go to. Members sign up with their email
+// address as username; usernames that are not an address cannot be emailed.
+func (u User) EmailAddress() (string, bool) {
+	address, err := mail.ParseAddress(u.Username)
+	if err != nil || address.Address != u.Username {
+		return "", false
+	}
+	return address.Address, true
+}
+
+// Locale returns the locale notifications to the member are written in
+func (u User) Locale() Locale {
+	if locale, ok := ParseLocale(string(u.Preferences.Locale)); ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// SMSNumber returns the member's phone number in E.164 format, e.g. +15551234567. Spaces, dashes,
+// dots and parentheses are dropped; numbers without a leading + get the default country code.
+func (u User) SMSNumber(defaultCountryCode string) (string, bool) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			return -1
+		}
+		return 'x'
+	}, strings.TrimPrefix(strings.TrimSpace(u.PhoneNumber), "+"))
+	if !strings.HasPrefix(strings.TrimSpace(u.PhoneNumber), "+") {
+		if defaultCountryCode == "" {
+			return "", false
+		}
+		digits = strings.TrimPrefix(defaultCountryCode, "+") + strings.TrimLeft(digits, "0")
+	}
+	if strings.Contains(digits, "x") || len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", false
+	}
+	return "+" + digits, true
 }
@@ -0,0 +1,193 @@
+// models/user_preferences.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// UserPreferences holds the options members set for themselves
+type UserPreferences struct {
+	// PaymentHandles are where the member can be paid back
+	PaymentHandles PaymentHandles `bson:"payment_handles" json:"payment_handles"`
+
+	// Notifications controls which reminders the member gets and when
+	Notifications NotificationPreferences `bson:"notifications" json:"notifications"`
+
+	// Locale is the language notifications are written in; empty means DefaultLocale
+	Locale Locale `bson:"locale,omitempty" json:"locale,omitempty"`
+}
+
+// NotificationPreferences are the reminders a member has opted out of, which channels each kind
+// of notification reaches them on and when they do not want to be disturbed
+type NotificationPreferences struct {
+	MutePaymentReminders bool                                  `bson:"mute_payment_reminders" json:"mute_payment_reminders"`
+	MuteShoppingUpdates  bool                                  `bson:"mute_shopping_updates" json:"mute_shopping_updates"`           // Push messages about the shared shopping list
+	MuteWeeklyDigest     bool                                  `bson:"mute_weekly_digest" json:"mute_weekly_digest"`                 // The digest email, weekly or daily
+	DigestFrequency      DigestFrequency                       `bson:"digest_frequency,omitempty" json:"digest_frequency,omitempty"` // Daily or weekly, the default
+	DigestPush           bool                                  `bson:"digest_push" json:"digest_push"`                               // Also push a short summary of the digest
+	SMSAlerts            bool                                  `bson:"sms_alerts" json:"sms_alerts"`                                 // Opt in to texts, see Channels for which
+	Channels             map[NotificationEvent]ChannelSettings `bson:"channels,omitempty" json:"channels,omitempty"`                 // Overrides the default channels per event, see Allows
+	QuietHours           QuietHours                            `bson:"quiet_hours" json:"quiet_hours"`
+}
+
+// QuietHours is a daily window in the member's time zone during which scheduled reminders wait.
+// The window may span midnight, e.g. 22:00 to 08:00. Empty start and end mean no quiet hours.
+type QuietHours struct {
+	Start    string `bson:"start,omitempty" json:"start,omitempty"`         // HH:MM
+	End      string `bson:"end,omitempty" json:"end,omitempty"`             // HH:MM
+	TimeZone string `bson:"time_zone,omitempty" json:"time_zone,omitempty"` // IANA name such as America/New_York; defaults to UTC
+}
+
+// Validate checks that the window has both ends and a known time zone
+func (q QuietHours) Validate() error {
+	if q.Start == "" && q.End == "" {
+		return nil
+	}
+	start, errStart := time.Parse("15:04", q.Start)
+	end, errEnd := time.Parse("15:04", q.End)
+	if errStart != nil || errEnd != nil {
+		return errors.New("quiet hours need a start and an end in HH:MM format")
+	}
+	if start.Equal(end) {
+		return errors.New("quiet hours cannot start and end at the same time")
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", q.TimeZone)
+	}
+	return nil
+}
+
+// Contains reports whether t falls within the quiet hours. Invalid quiet hours contain nothing.
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.Validate() != nil || q.Start == "" {
+		return false
+	}
+	location, _ := time.LoadLocation(q.TimeZone)
+	start, _ := time.Parse("15:04", q.Start)
+	end, _ := time.Parse("15:04", q.End)
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// Overlaps reports whether any moment from start to end falls within the quiet hours. Invalid
+// quiet hours overlap nothing.
+func (q QuietHours) Overlaps(start, end time.Time) bool {
+	if q.Validate() != nil || q.Start == "" || !start.Before(end) {
+		return false
+	}
+	location, _ := time.LoadLocation(q.TimeZone)
+	from, _ := time.Parse("15:04", q.Start)
+	to, _ := time.Parse("15:04", q.End)
+
+	// Check each day's window, starting the day before so a window spanning midnight into the
+	// first day is covered
+	local := start.In(location)
+	for day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		windowStart := time.Date(day.Year(), day.Month(), day.Day(), from.Hour(), from.Minute(), 0, 0, location)
+		windowEnd := time.Date(day.Year(), day.Month(), day.Day(), to.Hour(), to.Minute(), 0, 0, location)
+		if !windowEnd.After(windowStart) {
+			windowEnd = windowEnd.AddDate(0, 0, 1)
+		}
+		if windowStart.Before(end) && windowEnd.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// PaymentHandles are a member's accounts on payment apps; empty handles are not set
+type PaymentHandles struct {
+	Venmo  string `bson:"venmo,omitempty" json:"venmo,omitempty"`   // Venmo username, without the @
+	PayPal string `bson:"paypal,omitempty" json:"paypal,omitempty"` // PayPal.Me name
+	UPI    string `bson:"upi,omitempty" json:"upi,omitempty"`       // UPI ID, such as name@bank
+}
+
+// Payment providers deep links are built for
+const (
+	PaymentProviderVenmo  = "venmo"
+	PaymentProviderPayPal = "paypal"
+	PaymentProviderUPI    = "upi"
+)
+
+var (
+	venmoHandlePattern  = regexp.MustCompile(`^[A-Za-z0-9_-]{5,30}$`)
+	paypalHandlePattern = regexp.MustCompile(`^[A-Za-z0-9]{1,20}$`)
+	upiHandlePattern    = regexp.MustCompile(`^[A-Za-z0-9._-]{2,256}@[A-Za-z][A-Za-z0-9]{1,63}$`)
+)
+
+// Normalize trims the handles and strips the @ people tend to type before Venmo usernames
+func (h PaymentHandles) Normalize() PaymentHandles {
+	return PaymentHandles{
+		Venmo:  strings.TrimPrefix(strings.TrimSpace(h.Venmo), "@"),
+		PayPal: strings.TrimSpace(h.PayPal),
+		UPI:    strings.TrimSpace(h.UPI),
+	}
+}
+
+// Validate checks that every handle that is set is well formed
+func (h PaymentHandles) Validate() error {
+	if h.Venmo != "" && !venmoHandlePattern.MatchString(h.Venmo) {
+		return errors.New("venmo handle must be 5 to 30 letters, numbers, dashes or underscores")
+	}
+	if h.PayPal != "" && !paypalHandlePattern.MatchString(h.PayPal) {
+		return errors.New("paypal handle must be up to 20 letters or numbers")
+	}
+	if h.UPI != "" && !upiHandlePattern.MatchString(h.UPI) {
+		return errors.New("upi handle must look like name@bank")
+	}
+	return nil
+}
+
+// PaymentLink is a link that opens a payment app prefilled to pay a member
+type PaymentLink struct {
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+}
+
+// PaymentLinks builds a prefilled link for paying amount to the member on every app they have a
+// handle for. PayPal.Me does not take a note, so it is only added to Venmo and UPI links.
+func (h PaymentHandles) PaymentLinks(name string, amount float64, note string) []PaymentLink {
+	links := make([]PaymentLink, 0)
+	formatted := fmt.Sprintf("%.2f", amount)
+
+	if h.Venmo != "" {
+		query := url.Values{}
+		query.Set("txn", "pay")
+		query.Set("amount", formatted)
+		query.Set("note", note)
+		links = append(links, PaymentLink{
+			Provider: PaymentProviderVenmo,
+			URL:      "https://venmo.com/" + url.PathEscape(h.Venmo) + "?" + query.Encode(),
+		})
+	}
+	if h.PayPal != "" {
+		links = append(links, PaymentLink{
+			Provider: PaymentProviderPayPal,
+			URL:      "https://paypal.me/" + url.PathEscape(h.PayPal) + "/" + formatted,
+		})
+	}
+	if h.UPI != "" {
+		query := url.Values{}
+		query.Set("pa", h.UPI)
+		query.Set("pn", name)
+		query.Set("am", formatted)
+		query.Set("cu", "INR")
+		query.Set("tn", note)
+		links = append(links, PaymentLink{
+			Provider: PaymentProviderUPI,
+			URL:      "upi://pay?" + query.Encode(),
+		})
+	}
+	return links
+}
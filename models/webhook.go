@@ -0,0 +1,213 @@
+// models/webhook.go
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookEvent is a group event that can be delivered to webhooks
+type WebhookEvent string
+
+const (
+	WebhookEventChoreAssigned  WebhookEvent = "chore.assigned"
+	WebhookEventChoreCompleted WebhookEvent = "chore.completed"
+	WebhookEventItemAdded      WebhookEvent = "shopping.item_added"
+	WebhookEventItemPurchased  WebhookEvent = "shopping.item_purchased"
+	WebhookEventExpenseAdded   WebhookEvent = "expense.added"
+	WebhookEventPing           WebhookEvent = "ping" // Sent on request to test a webhook, whatever its filter
+)
+
+// MaxWebhooksPerGroup caps how many webhooks a group can register
+const MaxWebhooksPerGroup = 10
+
+const (
+	// WebhookMaxAttempts is how often a delivery is tried before it is given up on
+	WebhookMaxAttempts = 8
+
+	// WebhookDeliveryRetention is how long deliveries are kept; a TTL index removes older ones
+	WebhookDeliveryRetention = 30 * 24 * time.Hour
+
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+	webhookSecretBytes    = 32
+)
+
+// WebhookEvents lists the events webhooks can subscribe to
+var WebhookEvents = []WebhookEvent{
+	WebhookEventChoreAssigned,
+	WebhookEventChoreCompleted,
+	WebhookEventItemAdded,
+	WebhookEventItemPurchased,
+	WebhookEventExpenseAdded,
+}
+
+// IsValidWebhookEvent checks if webhooks can subscribe to the event
+func IsValidWebhookEvent(event WebhookEvent) bool {
+	for _, known := range WebhookEvents {
+		if event == known {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookFormat is the shape of the body posted to a webhook
+type WebhookFormat string
+
+const (
+	WebhookFormatJSON    WebhookFormat = "json"    // The event envelope, the default
+	WebhookFormatDiscord WebhookFormat = "discord" // A Discord message with the event summary
+)
+
+// IsValidWebhookFormat checks if the format is one of the defined values; empty means JSON
+func IsValidWebhookFormat(format WebhookFormat) bool {
+	return format == "" || format == WebhookFormatJSON || format == WebhookFormatDiscord
+}
+
+// Webhook is a URL a group's events are posted to, e.g. a Home Assistant automation or a Discord
+// channel. Each delivery is signed with the secret so the receiver can check it came from Cribb.
+type Webhook struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	GroupID     primitive.ObjectID `bson:"group_id" json:"group_id"`
+	URL         string             `bson:"url" json:"url"`
+	Secret      string             `bson:"secret" json:"-"`
+	Events      []WebhookEvent     `bson:"events" json:"events"` // Empty subscribes to every event
+	Format      WebhookFormat      `bson:"format,omitempty" json:"format,omitempty"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Active      bool               `bson:"active" json:"active"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateWebhook creates an active webhook with a random secret
+func CreateWebhook(groupID, createdBy primitive.ObjectID, webhookURL string, events []WebhookEvent, format WebhookFormat, description string) (*Webhook, error) {
+	secret, err := NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &Webhook{
+		GroupID:     groupID,
+		URL:         webhookURL,
+		Secret:      secret,
+		Events:      events,
+		Format:      format,
+		Description: description,
+		Active:      true,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// NewWebhookSecret returns a random secret for signing deliveries
+func NewWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(secret), nil
+}
+
+// ValidateWebhookURL checks that deliveries can be posted to the URL
+func ValidateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return errors.New("webhook URL must be an absolute http or https URL")
+	}
+	if parsed.User != nil {
+		return errors.New("webhook URL cannot contain credentials; check the signature instead")
+	}
+	return nil
+}
+
+// Subscribed reports whether the webhook wants the event
+func (w Webhook) Subscribed(event WebhookEvent) bool {
+	if !w.Active {
+		return false
+	}
+	if event == WebhookEventPing || len(w.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range w.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus tracks a delivery through its attempts
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Waiting for its next attempt
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered" // Accepted by the receiver
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Given up on
+)
+
+// WebhookDelivery is one event on its way to a webhook. The body is built when the event happens,
+// so every attempt sends the same payload.
+type WebhookDelivery struct {
+	ID             primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	WebhookID      primitive.ObjectID    `bson:"webhook_id" json:"webhook_id"`
+	GroupID        primitive.ObjectID    `bson:"group_id" json:"group_id"`
+	Event          WebhookEvent          `bson:"event" json:"event"`
+	Body           string                `bson:"body" json:"body"`
+	Status         WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts       int                   `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time             `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode int                   `bson:"last_status_code,omitempty" json:"last_status_code,omitempty"`
+	LastError      string                `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time            `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `bson:"created_at" json:"created_at"`
+}
+
+// WebhookPayload is the JSON body posted for an event
+type WebhookPayload struct {
+	ID        primitive.ObjectID `json:"id"` // The delivery, so receivers can ignore repeats
+	Event     WebhookEvent       `json:"event"`
+	GroupID   primitive.ObjectID `json:"group_id"`
+	Summary   string             `json:"summary"` // e.g. "Sam completed Dishes"
+	Data      interface{}        `json:"data"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// WebhookRetryDelay returns how long to wait after the given number of failed attempts, doubling
+// from 30 seconds up to an hour
+func WebhookRetryDelay(attempts int) time.Duration {
+	return backoffDelay(attempts, webhookRetryBaseDelay, webhookRetryMaxDelay)
+}
+
+// backoffDelay doubles the base delay for every failed attempt after the first, up to max
+func backoffDelay(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	return delay
+}
+
+// WebhookRetryable reports whether a delivery that got the status code, or none when it is zero,
+// is worth trying again. Other client errors mean the receiver will not accept it.
+func WebhookRetryable(statusCode int) bool {
+	switch {
+	case statusCode == 0, statusCode >= 500:
+		return true
+	case statusCode == http.StatusRequestTimeout, statusCode == http.StatusTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
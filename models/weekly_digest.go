@@ -0,0 +1,109 @@
+// models/weekly_digest.go
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WeeklyDigestInterval is how often members get a summary of their week
+const WeeklyDigestInterval = 7 * 24 * time.Hour
+
+// DailyDigestInterval is how often members who chose a daily digest get one
+const DailyDigestInterval = 24 * time.Hour
+
+// DigestFrequency is how often a member gets their digest
+type DigestFrequency string
+
+const (
+	DigestFrequencyWeekly DigestFrequency = "weekly" // The default
+	DigestFrequencyDaily  DigestFrequency = "daily"
+)
+
+// IsValidDigestFrequency checks if the frequency is one of the defined values; empty means weekly
+func IsValidDigestFrequency(frequency DigestFrequency) bool {
+	return frequency == "" || frequency == DigestFrequencyWeekly || frequency == DigestFrequencyDaily
+}
+
+// Interval returns how long one digest covers
+func (f DigestFrequency) Interval() time.Duration {
+	if f == DigestFrequencyDaily {
+		return DailyDigestInterval
+	}
+	return WeeklyDigestInterval
+}
+
+// Period names what one digest covers, e.g. "week"
+func (f DigestFrequency) Period() string {
+	if f == DigestFrequencyDaily {
+		return "day"
+	}
+	return "week"
+}
+
+// DigestDue reports whether the member's digest should be sent. The first one goes out one
+// interval after they signed up.
+func (u User) DigestDue(now time.Time) bool {
+	return !now.Before(u.DigestPeriodStart(now).Add(u.Preferences.Notifications.DigestFrequency.Interval()))
+}
+
+// DigestPeriodStart returns when the period the member's next digest covers began: their last
+// digest, or when they signed up. It is never more than one interval before now.
+func (u User) DigestPeriodStart(now time.Time) time.Time {
+	start := u.CreatedAt
+	if u.LastDigestAt != nil {
+		start = *u.LastDigestAt
+	}
+	if earliest := now.Add(-u.Preferences.Notifications.DigestFrequency.Interval()); start.Before(earliest) {
+		return earliest
+	}
+	return start
+}
+
+// BalancesBefore computes every member's net balance from the expenses and settlements recorded
+// before the time, e.g. to see how balances moved since the last digest
+func BalancesBefore(expenses []Expense, settlements []Settlement, members []primitive.ObjectID, before time.Time) []MemberBalance {
+	earlierExpenses := make([]Expense, 0, len(expenses))
+	for _, expense := range expenses {
+		if expense.CreatedAt.Before(before) {
+			earlierExpenses = append(earlierExpenses, expense)
+		}
+	}
+	earlierSettlements := make([]Settlement, 0, len(settlements))
+	for _, settlement := range settlements {
+		if settlement.CreatedAt.Before(before) {
+			earlierSettlements = append(earlierSettlements, settlement)
+		}
+	}
+	return ComputeBalances(earlierExpenses, earlierSettlements, members)
+}
+
+// BalanceChangeSummary describes how a member's net balance moved since their last digest, or
+// returns "" when it did not
+func BalanceChangeSummary(previous, current float64, currency string) string {
+	cents := math.Round((current - previous) * 100)
+	switch {
+	case cents > 0:
+		return fmt.Sprintf("Up %.2f %s since your last digest", cents/100, currency)
+	case cents < 0:
+		return fmt.Sprintf("Down %.2f %s since your last digest", -cents/100, currency)
+	default:
+		return ""
+	}
+}
+
+// BalanceSummary describes a member's net balance in the group's currency
+func BalanceSummary(net float64, currency string) string {
+	cents := math.Round(net * 100)
+	switch {
+	case cents < 0:
+		return fmt.Sprintf("You owe %.2f %s", -cents/100, currency)
+	case cents > 0:
+		return fmt.Sprintf("You are owed %.2f %s", cents/100, currency)
+	default:
+		return "You are settled up"
+	}
+}
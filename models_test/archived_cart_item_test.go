@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestArchiveShoppingCartItem(t *testing.T) {
+	item := *models.CreateShoppingCartItem(primitive.NewObjectID(), primitive.NewObjectID(), "Milk", 1, "gal", "Dairy")
+	item.ID = primitive.NewObjectID()
+	memberID := primitive.NewObjectID()
+
+	removed := models.ArchiveShoppingCartItem(item, memberID)
+	if removed.WasPurchased() || removed.ID != item.ID || removed.ArchivedBy != memberID {
+		t.Errorf("unexpected removed archive: %+v", removed)
+	}
+	if got := removed.ExpiresAt().Sub(removed.ArchivedAt); got != models.ShoppingArchiveRetention {
+		t.Errorf("expected archive to expire after %v, got %v", models.ShoppingArchiveRetention, got)
+	}
+
+	purchasedAt := time.Now()
+	purchased := models.ArchivePurchasedItem(item, memberID, purchasedAt)
+	if !purchased.WasPurchased() || !purchased.PurchasedAt.Equal(purchasedAt) || *purchased.PurchasedBy != memberID {
+		t.Errorf("unexpected purchased archive: %+v", purchased)
+	}
+	if purchased.ItemName != "Milk" || purchased.Unit != "gal" {
+		t.Errorf("expected archived item to keep its details, got %+v", purchased.ShoppingCartItem)
+	}
+}
@@ -0,0 +1,28 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"reflect"
+	"testing"
+)
+
+func TestEarnedBadgeKeys(t *testing.T) {
+	if keys := models.EarnedBadgeKeys(models.AchievementStats{}); len(keys) != 0 {
+		t.Errorf("Expected no badges for a new user, got %v", keys)
+	}
+
+	keys := models.EarnedBadgeKeys(models.AchievementStats{TotalCompletions: 1})
+	if !reflect.DeepEqual(keys, []string{"first_chore"}) {
+		t.Errorf("Expected first_chore badge, got %v", keys)
+	}
+
+	keys = models.EarnedBadgeKeys(models.AchievementStats{
+		TotalCompletions:  60,
+		DishesCompletions: 25,
+		BestDailyStreak:   30,
+	})
+	expected := []string{"first_chore", "fifty_chores", "streak_30", "dishes_dynasty"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected %v, got %v", expected, keys)
+	}
+}
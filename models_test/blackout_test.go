@@ -0,0 +1,44 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+)
+
+func TestApplyBlackouts(t *testing.T) {
+	examWeek := models.BlackoutDate{
+		StartDate: time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 5, 9, 0, 0, 0, 0, time.UTC),
+	}
+	holiday := models.BlackoutDate{
+		StartDate: time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 5, 10, 0, 0, 0, 0, time.UTC),
+	}
+	blackouts := []models.BlackoutDate{holiday, examWeek}
+
+	due := time.Date(2025, 5, 7, 23, 59, 0, 0, time.UTC)
+
+	// Push moves past both adjoining blackouts and keeps the time of day
+	pushed, ok := models.ApplyBlackouts(due, models.BlackoutPolicyPush, blackouts)
+	expected := time.Date(2025, 5, 11, 23, 59, 0, 0, time.UTC)
+	if !ok || !pushed.Equal(expected) {
+		t.Errorf("Expected occurrence pushed to %v, got %v (ok=%v)", expected, pushed, ok)
+	}
+
+	// An empty policy behaves like push
+	if pushed, ok := models.ApplyBlackouts(due, "", blackouts); !ok || !pushed.Equal(expected) {
+		t.Errorf("Expected default policy to push to %v, got %v", expected, pushed)
+	}
+
+	// Skip drops the occurrence
+	if _, ok := models.ApplyBlackouts(due, models.BlackoutPolicySkip, blackouts); ok {
+		t.Error("Expected occurrence on a blackout date to be skipped")
+	}
+
+	// Dates outside any blackout are untouched
+	free := time.Date(2025, 5, 12, 23, 59, 0, 0, time.UTC)
+	if result, ok := models.ApplyBlackouts(free, models.BlackoutPolicySkip, blackouts); !ok || !result.Equal(free) {
+		t.Errorf("Expected %v to be unchanged, got %v (ok=%v)", free, result, ok)
+	}
+}
@@ -0,0 +1,130 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseImportCSV(t *testing.T) {
+	data := "\ufefftype,title,assigned_to,due_date,points,frequency,members,name,quantity,unit,category\n" +
+		"chore,Take out trash,alice,2025-03-01,5,,,,,,\n" +
+		"recurring_chore,Vacuum,,,,weekly,alice; bob,,,,\n" +
+		"pantry_item,,,,,,,Rice,2.5,kg,Grains\n" +
+		"pantry_item,,,,ten,,,Milk,lots,l,Dairy\n"
+
+	rows, rowErrors, err := models.ParseImport([]byte(data), models.ImportFormatCSV)
+	if err != nil {
+		t.Fatalf("expected the file to parse, got %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(rows))
+	}
+
+	if rows[0].Type != models.ImportRowChore || rows[0].AssignedTo != "alice" || rows[0].Points != 5 {
+		t.Errorf("unexpected chore row %+v", rows[0])
+	}
+	if !reflect.DeepEqual(rows[1].Members, []string{"alice", "bob"}) {
+		t.Errorf("expected the rotation split on semicolons, got %v", rows[1].Members)
+	}
+	if rows[2].Quantity != 2.5 || rows[2].Category != "Grains" {
+		t.Errorf("unexpected pantry row %+v", rows[2])
+	}
+
+	want := []models.ImportError{
+		{Row: 4, Field: "quantity", Message: "must be a number"},
+		{Row: 4, Field: "points", Message: "must be a whole number"},
+	}
+	if !reflect.DeepEqual(rowErrors, want) {
+		t.Errorf("expected errors %v, got %v", want, rowErrors)
+	}
+}
+
+func TestParseImportRejectsUnreadableFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format models.ImportFormat
+	}{
+		{"unknown column", "type,colour\nchore,red\n", models.ImportFormatCSV},
+		{"no type column", "title\nDishes\n", models.ImportFormatCSV},
+		{"header only", "type,title\n", models.ImportFormatCSV},
+		{"empty", "", models.ImportFormatCSV},
+		{"not an array", `{"type":"chore"}`, models.ImportFormatJSON},
+		{"empty array", `[]`, models.ImportFormatJSON},
+		{"unknown format", "type\nchore\n", "xlsx"},
+	}
+
+	for _, tt := range tests {
+		if _, _, err := models.ParseImport([]byte(tt.data), tt.format); err == nil {
+			t.Errorf("%s: expected the file to be rejected", tt.name)
+		}
+	}
+
+	tooMany := "type\n" + strings.Repeat("chore\n", models.MaxImportRows+1)
+	if _, _, err := models.ParseImport([]byte(tooMany), models.ImportFormatCSV); err != models.ErrTooManyImportRows {
+		t.Errorf("expected too many rows to be rejected, got %v", err)
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	data := `[{"type":"recurring_chore","title":"Dishes","frequency":"daily","members":["bob"]},{"type":"pantry_item","name":"Eggs","quantity":12,"unit":"pcs","category":"Dairy"}]`
+	rows, rowErrors, err := models.ParseImport([]byte(data), models.ImportFormatJSON)
+	if err != nil || len(rowErrors) != 0 {
+		t.Fatalf("expected the file to parse, got %v and %v", err, rowErrors)
+	}
+	if len(rows) != 2 || rows[0].Frequency != "daily" || rows[1].Quantity != 12 {
+		t.Errorf("unexpected rows %+v", rows)
+	}
+}
+
+func TestParseImportDate(t *testing.T) {
+	due, err := models.ParseImportDate("2025-03-01", true)
+	if err != nil || !due.Equal(time.Date(2025, 3, 1, 23, 59, 0, 0, time.UTC)) {
+		t.Errorf("expected the end of the day, got %v and %v", due, err)
+	}
+	day, err := models.ParseImportDate("2025-03-01", false)
+	if err != nil || !day.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the start of the day, got %v and %v", day, err)
+	}
+	exact, err := models.ParseImportDate("2025-03-01T10:00:00Z", true)
+	if err != nil || !exact.Equal(time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected timestamps kept as given, got %v and %v", exact, err)
+	}
+	if _, err := models.ParseImportDate("03/01/2025", false); err == nil {
+		t.Error("expected other date formats to be rejected")
+	}
+}
+
+func TestImportRowValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		row    models.ImportRow
+		fields []string
+	}{
+		{"valid chore", models.ImportRow{Type: models.ImportRowChore, Title: "Dishes", AssignedTo: "alice", DueDate: "2025-03-01"}, nil},
+		{"chore missing fields", models.ImportRow{Type: models.ImportRowChore, Points: -1}, []string{"title", "points", "assigned_to", "due_date"}},
+		{"chore with a bad date", models.ImportRow{Type: models.ImportRowChore, Title: "Dishes", AssignedTo: "alice", DueDate: "tomorrow"}, []string{"due_date"}},
+		{"valid recurring chore", models.ImportRow{Type: models.ImportRowRecurringChore, Title: "Vacuum", Frequency: "weekly"}, nil},
+		{"recurring chore with a bad frequency", models.ImportRow{Type: models.ImportRowRecurringChore, Title: "Vacuum", Frequency: "hourly", FirstDueDate: "soon"}, []string{"frequency", "first_due_date"}},
+		{"valid pantry item", models.ImportRow{Type: models.ImportRowPantryItem, Name: "Rice", Unit: "kg", Category: "Grains", ExpirationDate: "2025-06-01"}, nil},
+		{"pantry item missing fields", models.ImportRow{Type: models.ImportRowPantryItem, Quantity: -1, MinQuantity: -1}, []string{"name", "quantity", "unit", "category", "min_quantity"}},
+		{"missing type", models.ImportRow{Title: "Dishes"}, []string{"type"}},
+		{"unknown type", models.ImportRow{Type: "expense"}, []string{"type"}},
+	}
+
+	for _, tt := range tests {
+		var fields []string
+		for _, rowError := range tt.row.Validate(3) {
+			if rowError.Row != 3 {
+				t.Errorf("%s: expected errors on row 3, got %d", tt.name, rowError.Row)
+			}
+			fields = append(fields, rowError.Field)
+		}
+		if !reflect.DeepEqual(fields, tt.fields) {
+			t.Errorf("%s: expected errors on %v, got %v", tt.name, tt.fields, fields)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRenderCalendar(t *testing.T) {
+	now := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	events := []models.CalendarEvent{
+		{UID: "b@cribb", Summary: "Movie night", Start: now.Add(48 * time.Hour), End: now.Add(50 * time.Hour)},
+		{UID: "a@cribb", Summary: "Dishes, floors; bins", Description: "Line one\nLine two", Start: time.Date(2025, 3, 8, 23, 59, 0, 0, time.UTC), AllDay: true},
+	}
+	feed := models.RenderCalendar("Cribb: Maple St", events, now)
+
+	if !strings.HasPrefix(feed, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(feed, "END:VCALENDAR\r\n") {
+		t.Fatalf("expected a CRLF-terminated calendar, got %q", feed)
+	}
+	for _, want := range []string{
+		"X-WR-CALNAME:Cribb: Maple St\r\n",
+		"DTSTAMP:20250307T180000Z\r\n",
+		"SUMMARY:Dishes\\, floors\\; bins\r\n",
+		"DESCRIPTION:Line one\\nLine two\r\n",
+		"DTSTART;VALUE=DATE:20250308\r\nDTEND;VALUE=DATE:20250309\r\n",
+		"DTSTART:20250309T180000Z\r\nDTEND:20250309T200000Z\r\n",
+	} {
+		if !strings.Contains(feed, want) {
+			t.Errorf("expected feed to contain %q", want)
+		}
+	}
+	if strings.Index(feed, "UID:a@cribb") > strings.Index(feed, "UID:b@cribb") {
+		t.Error("expected events earliest first")
+	}
+}
+
+func TestRenderCalendarFoldsLongLines(t *testing.T) {
+	feed := models.RenderCalendar("Cribb", []models.CalendarEvent{
+		{UID: "a@cribb", Summary: strings.Repeat("é", 60), Start: time.Now(), AllDay: true},
+	}, time.Now())
+	for _, line := range strings.Split(feed, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line longer than 75 octets: %q", line)
+		}
+	}
+	if !strings.Contains(feed, "\r\n é") {
+		t.Error("expected the long summary to be folded")
+	}
+}
+
+func TestRecurringChoreCalendarEvents(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	chore := models.RecurringChore{
+		ID:             primitive.NewObjectID(),
+		Title:          "Bins",
+		Frequency:      "weekly",
+		Points:         5,
+		MemberRotation: []primitive.ObjectID{alice, bob},
+		NextAssignment: start,
+		IsActive:       true,
+	}
+	occurrences := chore.PreviewOccurrences(10, start, nil)
+	events := models.RecurringChoreCalendarEvents(chore, occurrences, bob, start.AddDate(0, 0, 30))
+
+	if len(events) != 2 {
+		t.Fatalf("expected Bob's two turns within 30 days, got %d", len(events))
+	}
+	if !events[0].AllDay || events[0].Start.Format("2006-01-02") != "2025-03-17" || events[0].Summary != "Bins" {
+		t.Errorf("unexpected first event %+v", events[0])
+	}
+	if events[0].UID == events[1].UID {
+		t.Error("expected each occurrence to have its own UID")
+	}
+}
+
+func TestRecurringBillCalendarEvents(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	due := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Rent", 1800, "monthly", due)
+	bill.ID = primitive.NewObjectID()
+
+	events := bill.CalendarEvents(due.AddDate(0, 0, 75))
+	if len(events) != 3 || events[0].Summary != "Rent due" || !events[2].Start.Equal(due.AddDate(0, 2, 0)) {
+		t.Errorf("unexpected bill events %+v", events)
+	}
+
+	members := []primitive.ObjectID{alice, bob, carol}
+	if !bill.Involves(carol, members) {
+		t.Error("expected a bill without participants to involve every member")
+	}
+	bill.Participants = []models.BillParticipant{{UserID: bob}}
+	if !bill.Involves(alice, members) || !bill.Involves(bob, members) || bill.Involves(carol, members) {
+		t.Error("expected a bill with participants to involve them and the payer only")
+	}
+
+	bill.IsActive = false
+	if len(bill.CalendarEvents(due.AddDate(1, 0, 0))) != 0 {
+		t.Error("expected paused bills to have no events")
+	}
+}
@@ -0,0 +1,60 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestChallenge(goal models.ChallengeGoal, target int) *models.Challenge {
+	start := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	return models.CreateChallenge(primitive.NewObjectID(), primitive.NewObjectID(), "Clean week", "", "Pizza night",
+		goal, target, start, start.AddDate(0, 0, 7))
+}
+
+func TestChallengeCompletionCountCompletesEarly(t *testing.T) {
+	challenge := newTestChallenge(models.ChallengeGoalCompletionCount, 5)
+	now := challenge.StartDate.AddDate(0, 0, 2)
+
+	if challenge.UpdateProgress(4, 0, now) {
+		t.Error("Expected challenge to stay active below its target")
+	}
+	if !challenge.UpdateProgress(5, 0, now) {
+		t.Error("Expected challenge to complete on reaching its target")
+	}
+	if challenge.Status != models.ChallengeStatusCompleted || challenge.CompletedAt == nil {
+		t.Errorf("Expected completed status with a completion time, got %s", challenge.Status)
+	}
+
+	// Settled challenges are not reopened or completed again
+	if challenge.UpdateProgress(6, 0, now) {
+		t.Error("Expected a completed challenge not to complete again")
+	}
+}
+
+func TestChallengeAllChoresWaitsForWindowEnd(t *testing.T) {
+	challenge := newTestChallenge(models.ChallengeGoalAllChores, 0)
+
+	if challenge.UpdateProgress(3, 3, challenge.StartDate.AddDate(0, 0, 3)) {
+		t.Error("Expected all chores challenge to wait until its window closes")
+	}
+	if challenge.Target != 3 {
+		t.Errorf("Expected target to track chores due, got %d", challenge.Target)
+	}
+	if !challenge.UpdateProgress(3, 3, challenge.EndDate) {
+		t.Error("Expected all chores challenge to complete once its window closes")
+	}
+}
+
+func TestChallengeFailsAfterWindow(t *testing.T) {
+	challenge := newTestChallenge(models.ChallengeGoalAllChores, 0)
+
+	if challenge.UpdateProgress(2, 3, challenge.EndDate.Add(time.Hour)) {
+		t.Error("Expected challenge with missed chores not to complete")
+	}
+	if challenge.Status != models.ChallengeStatusFailed {
+		t.Errorf("Expected failed status, got %s", challenge.Status)
+	}
+}
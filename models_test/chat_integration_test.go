@@ -0,0 +1,97 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateChatIntegration(t *testing.T) {
+	valid := []struct {
+		platform models.ChatPlatform
+		webhook  string
+	}{
+		{models.ChatPlatformSlack, "https://hooks.slack.com/services/T000/B000/XXXX"},
+		{models.ChatPlatformDiscord, "https://discord.com/api/webhooks/1/abc"},
+		{models.ChatPlatformDiscord, ""},
+	}
+	for _, tt := range valid {
+		if err := models.ValidateChatIntegration(tt.platform, "T123", tt.webhook, "08:00", "America/New_York"); err != nil {
+			t.Errorf("%s %q: unexpected error %v", tt.platform, tt.webhook, err)
+		}
+	}
+
+	invalid := []struct {
+		platform  models.ChatPlatform
+		workspace string
+		webhook   string
+		time      string
+		zone      string
+	}{
+		{"teams", "T123", "", "08:00", ""},
+		{models.ChatPlatformSlack, " ", "", "08:00", ""},
+		{models.ChatPlatformSlack, "T123", "https://discord.com/api/webhooks/1/abc", "08:00", ""},
+		{models.ChatPlatformDiscord, "G1", "http://discord.com/api/webhooks/1/abc", "08:00", ""},
+		{models.ChatPlatformSlack, "T123", "", "8am", ""},
+		{models.ChatPlatformSlack, "T123", "", "08:00", "Mars/Olympus"},
+	}
+	for _, tt := range invalid {
+		if err := models.ValidateChatIntegration(tt.platform, tt.workspace, tt.webhook, tt.time, tt.zone); err == nil {
+			t.Errorf("expected %+v to be rejected", tt)
+		}
+	}
+}
+
+func TestChatIntegrationScheduleSummary(t *testing.T) {
+	now := time.Date(2025, 3, 4, 14, 0, 0, 0, time.UTC) // 09:00 in New York
+	integration := models.CreateChatIntegration(primitive.NewObjectID(), primitive.NewObjectID(), models.ChatPlatformSlack,
+		" T123 ", "https://hooks.slack.com/services/T/B/X", "08:00", "America/New_York", now)
+	if integration.WorkspaceID != "T123" || integration.Links == nil {
+		t.Errorf("unexpected integration %+v", integration)
+	}
+	want := time.Date(2025, 3, 5, 13, 0, 0, 0, time.UTC)
+	if integration.NextSummaryAt == nil || !integration.NextSummaryAt.Equal(want) {
+		t.Errorf("expected tomorrow's summary at %v, got %v", want, integration.NextSummaryAt)
+	}
+
+	integration.SummaryTime = "18:30"
+	integration.ScheduleSummary(now)
+	if want := time.Date(2025, 3, 4, 23, 30, 0, 0, time.UTC); !integration.NextSummaryAt.Equal(want) {
+		t.Errorf("expected this evening's summary at %v, got %v", want, integration.NextSummaryAt)
+	}
+
+	integration.WebhookURL = ""
+	integration.ScheduleSummary(now)
+	if integration.NextSummaryAt != nil {
+		t.Errorf("expected no summary without a webhook, got %v", integration.NextSummaryAt)
+	}
+}
+
+func TestChatIntegrationLinkedMember(t *testing.T) {
+	member := primitive.NewObjectID()
+	integration := models.ChatIntegration{Links: []models.ChatLink{{ExternalUserID: "U1", UserID: member}}}
+	if id, ok := integration.LinkedMember("U1"); !ok || id != member {
+		t.Errorf("expected U1 to be linked to the member, got %v %v", id, ok)
+	}
+	if _, ok := integration.LinkedMember("U2"); ok {
+		t.Error("expected U2 not to be linked")
+	}
+}
+
+func TestChatLinkCode(t *testing.T) {
+	now := time.Now()
+	linkCode, code, err := models.CreateChatLinkCode(primitive.NewObjectID(), primitive.NewObjectID(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 10 || !linkCode.ExpiresAt.Equal(now.Add(models.ChatLinkCodeTTL)) {
+		t.Errorf("unexpected code %q expiring at %v", code, linkCode.ExpiresAt)
+	}
+	// Codes typed in chat match whatever their case and spacing
+	if models.HashChatLinkCode(" "+code[:5]+" "+strings.ToLower(code[5:])) != linkCode.CodeHash {
+		t.Error("expected the code to match ignoring case and spaces")
+	}
+}
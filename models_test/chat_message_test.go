@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseMentions(t *testing.T) {
+	got := models.ParseMentions("@Alex can you swap with @jo@example.com? Thanks @alex. email me at sam@example.com")
+	if want := []string{"alex", "jo@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := models.ParseMentions("No mentions here"); len(got) != 0 {
+		t.Errorf("expected no mentions, got %v", got)
+	}
+}
+
+func TestMentionedMembers(t *testing.T) {
+	sam := models.User{ID: primitive.NewObjectID(), Username: "sam@example.com", Name: "Sam Lee"}
+	alex := models.User{ID: primitive.NewObjectID(), Username: "alex.k@example.com", Name: "Alex Kim"}
+	jo := models.User{ID: primitive.NewObjectID(), Username: "jo", Name: "Jo Park"}
+	members := []models.User{sam, alex, jo}
+
+	got := models.MentionedMembers("@alex.k and @Jo, the bins are out. cc @sam", members, sam.ID)
+	if want := []primitive.ObjectID{alex.ID, jo.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected Alex and Jo without the sender, got %v", got)
+	}
+	if got := models.MentionedMembers("@alex@example.com", members, sam.ID); len(got) != 0 {
+		t.Errorf("expected an unknown username to mention nobody, got %v", got)
+	}
+
+	message := models.CreateChatMessage(primitive.NewObjectID(), sam.ID, " @alex.k dinner? ", got)
+	notification := models.ChatMentionNotification(message, alex.ID, sam.Name)
+	if notification.Title != "Sam Lee mentioned you" || notification.Message != "@alex.k dinner?" {
+		t.Errorf("unexpected notification %q: %q", notification.Title, notification.Message)
+	}
+	if notification.Type.Event() != models.NotificationEventMentions {
+		t.Error("expected mentions to have their own notification setting")
+	}
+}
@@ -185,23 +185,366 @@ func TestCreateChoreFromRecurring(t *testing.T) {
 		t.Errorf("Expected recurring ID %s, got %s", recurringChore.ID.Hex(), chore.RecurringID.Hex())
 	}
 
-	// Due date should be calculated based on frequency
+	// Due date should be the end of the day (UTC) one interval from now
 	now := time.Now()
-	var expectedDueDate time.Time
+	var expected time.Time
 	switch recurringChore.Frequency {
 	case "daily":
-		expectedDueDate = now.Add(24 * time.Hour)
+		expected = now
 	case "weekly":
-		expectedDueDate = now.Add(7 * 24 * time.Hour)
+		expected = now.AddDate(0, 0, 7)
 	case "biweekly":
-		expectedDueDate = now.Add(14 * 24 * time.Hour)
+		expected = now.AddDate(0, 0, 14)
 	case "monthly":
-		expectedDueDate = now.AddDate(0, 1, 0)
+		expected = now.AddDate(0, 1, 0)
 	}
+	year, month, day := expected.UTC().Date()
+	expectedDueDate := time.Date(year, month, day, 23, 59, 0, 0, time.UTC)
 
-	// Allow a small time difference due to execution time
-	timeDiff := chore.DueDate.Sub(expectedDueDate)
-	if timeDiff < -5*time.Second || timeDiff > 5*time.Second {
-		t.Errorf("Expected due date around %v, got %v (diff: %v)", expectedDueDate, chore.DueDate, timeDiff)
+	if !chore.DueDate.Equal(expectedDueDate) {
+		t.Errorf("Expected due date %v, got %v", expectedDueDate, chore.DueDate)
+	}
+}
+
+func TestNextOccurrence(t *testing.T) {
+	// A completed chore due at the end of January 31st
+	due := time.Date(2025, time.January, 31, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency string
+		expected  time.Time
+	}{
+		{"daily", time.Date(2025, time.February, 1, 23, 59, 0, 0, time.UTC)},
+		{"weekly", time.Date(2025, time.February, 7, 23, 59, 0, 0, time.UTC)},
+		{"biweekly", time.Date(2025, time.February, 14, 23, 59, 0, 0, time.UTC)},
+		{"monthly", time.Date(2025, time.March, 3, 23, 59, 0, 0, time.UTC)},   // February has no 31st
+		{"yearly", time.Date(2025, time.February, 7, 23, 59, 0, 0, time.UTC)}, // Unknown frequencies repeat weekly
+	}
+
+	for _, tt := range tests {
+		if next := models.NextOccurrence(tt.frequency, due); !next.Equal(tt.expected) {
+			t.Errorf("%s: expected next due date %v, got %v", tt.frequency, tt.expected, next)
+		}
+	}
+
+	// The next instance is never due on the same day as the completed one
+	for _, frequency := range []string{"daily", "weekly", "biweekly", "monthly"} {
+		if next := models.NextOccurrence(frequency, due); !next.After(due) {
+			t.Errorf("%s: expected next due date after %v, got %v", frequency, due, next)
+		}
+	}
+}
+
+func TestValidateRotationOrder(t *testing.T) {
+	member1 := primitive.NewObjectID()
+	member2 := primitive.NewObjectID()
+	member3 := primitive.NewObjectID()
+	members := []primitive.ObjectID{member1, member2, member3}
+
+	if err := models.ValidateRotationOrder([]primitive.ObjectID{member3, member1}, members); err != nil {
+		t.Errorf("Expected valid rotation, got error: %v", err)
+	}
+	if err := models.ValidateRotationOrder([]primitive.ObjectID{}, members); err == nil {
+		t.Error("Expected error for empty rotation")
+	}
+	if err := models.ValidateRotationOrder([]primitive.ObjectID{member1, member1}, members); err == nil {
+		t.Error("Expected error for duplicate member in rotation")
+	}
+	if err := models.ValidateRotationOrder([]primitive.ObjectID{member1, primitive.NewObjectID()}, members); err == nil {
+		t.Error("Expected error for non-member in rotation")
+	}
+}
+
+func TestSetRotationOrderKeepsUpcomingAssignee(t *testing.T) {
+	member1 := primitive.NewObjectID()
+	member2 := primitive.NewObjectID()
+	member3 := primitive.NewObjectID()
+
+	recurringChore := models.RecurringChore{
+		MemberRotation: []primitive.ObjectID{member1, member2, member3},
+		CurrentIndex:   1,
+	}
+
+	recurringChore.SetRotationOrder([]primitive.ObjectID{member3, member2, member1})
+	if recurringChore.CurrentIndex != 1 {
+		t.Errorf("Expected current index 1, got %d", recurringChore.CurrentIndex)
+	}
+	if next := recurringChore.GetNextAssignee(); next != member2 {
+		t.Errorf("Expected next assignee %s, got %s", member2.Hex(), next.Hex())
+	}
+
+	// Removing the upcoming assignee restarts the rotation
+	recurringChore.CurrentIndex = 1
+	recurringChore.SetRotationOrder([]primitive.ObjectID{member3, member1})
+	if recurringChore.CurrentIndex != 0 {
+		t.Errorf("Expected current index 0, got %d", recurringChore.CurrentIndex)
+	}
+}
+
+func TestRecurringChoreHasEnded(t *testing.T) {
+	now := time.Now()
+	until := now.AddDate(0, 0, 10)
+
+	recurringChore := models.RecurringChore{Frequency: "weekly"}
+	if recurringChore.HasEnded(now.AddDate(1, 0, 0)) {
+		t.Error("Expected chore without limits never to end")
+	}
+
+	recurringChore.MaxOccurrences = 3
+	recurringChore.OccurrenceCount = 2
+	if recurringChore.HasEnded(now) {
+		t.Error("Expected chore below its occurrence limit to continue")
+	}
+	recurringChore.OccurrenceCount = 3
+	if !recurringChore.HasEnded(now) {
+		t.Error("Expected chore at its occurrence limit to end")
+	}
+
+	recurringChore.MaxOccurrences = 0
+	recurringChore.UntilDate = &until
+	if recurringChore.HasEnded(models.NextOccurrence("weekly", now)) {
+		t.Error("Expected occurrence before until date to be allowed")
+	}
+	if !recurringChore.HasEnded(models.NextOccurrence("biweekly", now)) {
+		t.Error("Expected occurrence after until date to end the chore")
+	}
+}
+
+func TestPreviewOccurrences(t *testing.T) {
+	member1 := primitive.NewObjectID()
+	member2 := primitive.NewObjectID()
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	recurringChore := models.RecurringChore{
+		MemberRotation:  []primitive.ObjectID{member1, member2},
+		CurrentIndex:    1,
+		Frequency:       "weekly",
+		NextAssignment:  start,
+		IsActive:        true,
+		MaxOccurrences:  4,
+		OccurrenceCount: 1,
+	}
+
+	occurrences := recurringChore.PreviewOccurrences(10, start, nil)
+
+	// Only three occurrences remain before the limit is reached
+	if len(occurrences) != 3 {
+		t.Fatalf("Expected 3 occurrences, got %d", len(occurrences))
+	}
+
+	expectedAssignees := []primitive.ObjectID{member2, member1, member2}
+	for i, occurrence := range occurrences {
+		if occurrence.AssignedTo != expectedAssignees[i] {
+			t.Errorf("Occurrence %d: expected assignee %s, got %s", i, expectedAssignees[i].Hex(), occurrence.AssignedTo.Hex())
+		}
+		expectedAssignAt := start.AddDate(0, 0, 7*i)
+		if !occurrence.AssignAt.Equal(expectedAssignAt) {
+			t.Errorf("Occurrence %d: expected assignment at %v, got %v", i, expectedAssignAt, occurrence.AssignAt)
+		}
+	}
+
+	// The preview must not advance the real rotation
+	if recurringChore.CurrentIndex != 1 || recurringChore.OccurrenceCount != 1 {
+		t.Error("Expected preview to leave the recurring chore unchanged")
+	}
+}
+
+func TestMissedWindows(t *testing.T) {
+	next := time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	// Server was down for a little over three weeks
+	now := time.Date(2025, 1, 23, 12, 0, 0, 0, time.UTC)
+	windows := models.MissedWindows(next, "weekly", now)
+	if len(windows) != 4 {
+		t.Fatalf("Expected 4 windows, got %d", len(windows))
+	}
+	for i, window := range windows {
+		expected := next.AddDate(0, 0, 7*i)
+		if !window.Equal(expected) {
+			t.Errorf("Window %d: expected %v, got %v", i, expected, window)
+		}
+	}
+
+	// On schedule there is exactly one window
+	windows = models.MissedWindows(next, "weekly", next.Add(time.Hour))
+	if len(windows) != 1 || !windows[0].Equal(next) {
+		t.Errorf("Expected a single window at %v, got %v", next, windows)
+	}
+}
+
+func TestGetNextAssigneeSkipsExcludedMembers(t *testing.T) {
+	member1 := primitive.NewObjectID()
+	member2 := primitive.NewObjectID()
+	member3 := primitive.NewObjectID()
+
+	recurringChore := models.RecurringChore{
+		MemberRotation:  []primitive.ObjectID{member1, member2, member3},
+		ExcludedMembers: []primitive.ObjectID{member2},
+	}
+
+	expected := []primitive.ObjectID{member1, member3, member1, member3}
+	for i, want := range expected {
+		if got := recurringChore.GetNextAssignee(); got != want {
+			t.Errorf("Assignment %d: expected %s, got %s", i, want.Hex(), got.Hex())
+		}
+	}
+
+	stats := recurringChore.RotationFairness(
+		map[primitive.ObjectID]int{member1: 3, member3: 1},
+		map[primitive.ObjectID]int{member1: 2},
+	)
+	if len(stats) != 3 {
+		t.Fatalf("Expected stats for 3 members, got %d", len(stats))
+	}
+	if !stats[1].Excluded || stats[1].ExpectedShare != 0 {
+		t.Error("Expected excluded member to have no expected share")
+	}
+	if stats[0].ExpectedShare != 2 || stats[0].Deviation != 1 {
+		t.Errorf("Expected share 2 and deviation 1, got %v and %v", stats[0].ExpectedShare, stats[0].Deviation)
+	}
+
+	// With everyone excluded there is nobody to assign
+	recurringChore.ExcludedMembers = []primitive.ObjectID{member1, member2, member3}
+	if got := recurringChore.GetNextAssignee(); !got.IsZero() {
+		t.Errorf("Expected no assignee, got %s", got.Hex())
+	}
+}
+
+func TestValidateEditScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		scope      models.EditScope
+		regenerate bool
+		fields     []string
+		wantErr    bool
+	}{
+		{"template only", "", false, []string{"frequency", "until_date"}, false},
+		{"this with instance fields", models.EditScopeThis, false, []string{"title", "description", "points"}, false},
+		{"this with frequency", models.EditScopeThis, false, []string{"title", "frequency"}, true},
+		{"this with rotation", models.EditScopeThis, false, []string{"member_usernames"}, true},
+		{"this with until date", models.EditScopeThis, false, []string{"until_date"}, true},
+		{"this with regenerate", models.EditScopeThis, true, []string{"title"}, true},
+		{"future with template fields", models.EditScopeFuture, false, []string{"frequency", "is_active"}, false},
+		{"future with regenerate", models.EditScopeFuture, true, []string{"frequency"}, false},
+		{"all with regenerate", models.EditScopeAll, true, []string{"member_usernames"}, false},
+		{"template only with regenerate", "", true, []string{"title"}, true},
+		{"unknown scope", "some", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		err := models.ValidateEditScope(tt.scope, tt.regenerate, tt.fields)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRegenerateInstances(t *testing.T) {
+	member1 := primitive.NewObjectID()
+	member2 := primitive.NewObjectID()
+	member3 := primitive.NewObjectID()
+	start := time.Date(2025, time.March, 3, 23, 59, 0, 0, time.UTC)
+
+	// Four pending weekly instances assigned member2, member3, member1, member2
+	pending := func() []models.Chore {
+		assignees := []primitive.ObjectID{member2, member3, member1, member2}
+		chores := make([]models.Chore, len(assignees))
+		for i, assignee := range assignees {
+			chores[i] = models.Chore{AssignedTo: assignee, DueDate: start.AddDate(0, 0, 7*i)}
+		}
+		return chores
+	}
+	template := func() models.RecurringChore {
+		return models.RecurringChore{
+			ID:              primitive.NewObjectID(),
+			Title:           "Mop the floors",
+			Points:          4,
+			Frequency:       "weekly",
+			MemberRotation:  []primitive.ObjectID{member1, member2, member3},
+			CurrentIndex:    0, // Next after the last pending instance
+			IsActive:        true,
+			OccurrenceCount: 6,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		scope         models.EditScope
+		edit          func(rc *models.RecurringChore)
+		rotationReset bool
+		wantAssignees []primitive.ObjectID
+		wantCount     int
+	}{
+		{
+			// Future edits from the second instance replace the last three
+			name:          "future",
+			scope:         models.EditScopeFuture,
+			edit:          func(rc *models.RecurringChore) { rc.Points = 6 },
+			wantAssignees: []primitive.ObjectID{member3, member1, member2},
+			wantCount:     6,
+		},
+		{
+			name:          "all",
+			scope:         models.EditScopeAll,
+			edit:          func(rc *models.RecurringChore) { rc.Title = "Mop and vacuum" },
+			wantAssignees: []primitive.ObjectID{member2, member3, member1, member2},
+			wantCount:     6,
+		},
+		{
+			name:  "all with a new rotation",
+			scope: models.EditScopeAll,
+			edit: func(rc *models.RecurringChore) {
+				rc.MemberRotation = []primitive.ObjectID{member3, member1}
+				rc.CurrentIndex = 0
+			},
+			rotationReset: true,
+			wantAssignees: []primitive.ObjectID{member3, member1, member3, member1},
+			wantCount:     6,
+		},
+		{
+			name:  "all with a shorter run",
+			scope: models.EditScopeAll,
+			edit: func(rc *models.RecurringChore) {
+				until := start.AddDate(0, 0, 10)
+				rc.UntilDate = &until
+			},
+			wantAssignees: []primitive.ObjectID{member2, member3},
+			wantCount:     4,
+		},
+		{
+			name:          "all after deactivation",
+			scope:         models.EditScopeAll,
+			edit:          func(rc *models.RecurringChore) { rc.IsActive = false },
+			wantAssignees: nil,
+			wantCount:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		replaced := pending()
+		if tt.scope == models.EditScopeFuture {
+			replaced = replaced[1:]
+		}
+		recurringChore := template()
+		tt.edit(&recurringChore)
+
+		instances := recurringChore.RegenerateInstances(replaced, tt.rotationReset)
+		if len(instances) != len(tt.wantAssignees) {
+			t.Fatalf("%s: expected %d instances, got %d", tt.name, len(tt.wantAssignees), len(instances))
+		}
+		for i, instance := range instances {
+			if instance.AssignedTo != tt.wantAssignees[i] {
+				t.Errorf("%s: instance %d assigned to %s, expected %s", tt.name, i, instance.AssignedTo.Hex(), tt.wantAssignees[i].Hex())
+			}
+			if !instance.DueDate.Equal(replaced[i].DueDate) {
+				t.Errorf("%s: instance %d due %v, expected %v", tt.name, i, instance.DueDate, replaced[i].DueDate)
+			}
+			if instance.Title != recurringChore.Title || instance.Points != recurringChore.Points {
+				t.Errorf("%s: instance %d was not built from the updated template", tt.name, i)
+			}
+		}
+		if recurringChore.OccurrenceCount != tt.wantCount {
+			t.Errorf("%s: expected occurrence count %d, got %d", tt.name, tt.wantCount, recurringChore.OccurrenceCount)
+		}
 	}
 }
@@ -0,0 +1,93 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateDecisionPoll(t *testing.T) {
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	tomorrow := now.AddDate(0, 0, 1)
+	if err := models.ValidateDecisionPoll("Thermostat temperature?", []string{"68°F", "70°F", "72°F"}, tomorrow, now); err != nil {
+		t.Errorf("expected the poll to be valid, got %v", err)
+	}
+	if models.ValidateDecisionPoll("Thermostat?", []string{"68°F"}, tomorrow, now) == nil {
+		t.Error("expected a single option to be rejected")
+	}
+	if models.ValidateDecisionPoll("Thermostat?", []string{"Warm", " warm "}, tomorrow, now) == nil {
+		t.Error("expected repeated options to be rejected")
+	}
+	if models.ValidateDecisionPoll("Thermostat?", []string{"68°F", "70°F"}, now, now) == nil {
+		t.Error("expected a deadline in the past to be rejected")
+	}
+	if models.ValidateDecisionPoll("Thermostat?", []string{"68°F", "70°F"}, now.AddDate(0, 2, 0), now) == nil {
+		t.Error("expected a deadline two months out to be rejected")
+	}
+}
+
+func TestDecisionPollVoting(t *testing.T) {
+	sam, alex, jo := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	poll := models.CreateDecisionPoll(primitive.NewObjectID(), sam, "Thermostat temperature?", []string{"68°F", "70°F", "72°F"}, false, false, now.AddDate(0, 0, 2))
+	low, mid, high := poll.Options[0].ID, poll.Options[1].ID, poll.Options[2].ID
+
+	if poll.SetBallot(sam, []primitive.ObjectID{low, mid}, now) == nil {
+		t.Error("expected two options in a single choice poll to be rejected")
+	}
+	if poll.SetBallot(sam, nil, now) == nil {
+		t.Error("expected an empty vote to be rejected")
+	}
+	poll.SetBallot(sam, []primitive.ObjectID{low}, now)
+	poll.SetBallot(sam, []primitive.ObjectID{mid}, now) // Changed their mind
+	poll.SetBallot(alex, []primitive.ObjectID{mid}, now)
+	if poll.AllVoted([]primitive.ObjectID{sam, alex, jo}) {
+		t.Error("expected Jo not to have voted")
+	}
+	poll.SetBallot(jo, []primitive.ObjectID{high}, now)
+
+	results := poll.Tally()
+	if results[0].Votes != 0 || results[1].Votes != 2 || len(results[1].VoterIDs) != 2 {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	poll.Close(now)
+	if poll.Status != models.PollClosed || len(poll.WinningOptionIDs) != 1 || poll.WinningOptionIDs[0] != mid {
+		t.Errorf("expected 70°F to win, got %+v", poll.WinningOptionIDs)
+	}
+	closed := models.DecisionClosedNotification(poll, sam)
+	if closed.Message != "Thermostat temperature?: 70°F won with 2 votes" {
+		t.Errorf("unexpected notification %q", closed.Message)
+	}
+}
+
+func TestDecisionPollTie(t *testing.T) {
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	poll := models.CreateDecisionPoll(primitive.NewObjectID(), sam, "Movie night pick", []string{"Alien", "Heat", "Up"}, true, true, now.AddDate(0, 0, 2))
+	poll.SetBallot(sam, []primitive.ObjectID{poll.Options[0].ID, poll.Options[1].ID}, now)
+	poll.SetBallot(alex, []primitive.ObjectID{poll.Options[1].ID, poll.Options[0].ID}, now)
+
+	for _, result := range poll.Tally() {
+		if len(result.VoterIDs) > 0 {
+			t.Errorf("expected an anonymous poll not to list voters, got %+v", result)
+		}
+	}
+	poll.Close(now)
+	if len(poll.WinningOptionIDs) != 2 {
+		t.Errorf("expected a tie between two options, got %+v", poll.WinningOptionIDs)
+	}
+	closed := models.DecisionClosedNotification(poll, alex)
+	if !strings.Contains(closed.Message, "tie between Alien, Heat") {
+		t.Errorf("unexpected notification %q", closed.Message)
+	}
+
+	empty := models.CreateDecisionPoll(primitive.NewObjectID(), sam, "Movie night pick", []string{"Alien", "Heat"}, false, false, now.AddDate(0, 0, 2))
+	empty.Close(now)
+	if len(empty.WinningOptionIDs) != 0 || models.DecisionClosedNotification(empty, sam).Message != "Nobody voted on Movie night pick" {
+		t.Error("expected a poll nobody voted on to close without a winner")
+	}
+}
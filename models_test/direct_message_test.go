@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestConversationKey(t *testing.T) {
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	if models.ConversationKey(sam, alex) != models.ConversationKey(alex, sam) {
+		t.Error("expected the key not to depend on who starts the conversation")
+	}
+
+	conversation := models.CreateConversation(primitive.NewObjectID(), alex, sam)
+	if conversation.Key != models.ConversationKey(sam, alex) || conversation.Participants[0].UserID != sam {
+		t.Errorf("expected participants ordered by ID, got %+v", conversation.Participants)
+	}
+	if i, ok := conversation.Participant(alex); !ok || conversation.Participants[conversation.Other(alex)].UserID != sam || i == conversation.Other(alex) {
+		t.Error("unexpected participant lookup")
+	}
+
+	message := models.CreateDirectMessage(conversation, alex, "  Swap Tuesday's dishes for Thursday's trash?  ")
+	if message.RecipientID != sam || message.Body != "Swap Tuesday's dishes for Thursday's trash?" {
+		t.Errorf("unexpected message %+v", message)
+	}
+	event := models.MessageSentEvent(*message)
+	if !event.VisibleTo(sam) || !event.VisibleTo(alex) || event.VisibleTo(primitive.NewObjectID()) {
+		t.Error("expected the message event to reach only the two members")
+	}
+}
+
+func TestValidateMessageBody(t *testing.T) {
+	if models.ValidateMessageBody(" \n ") == nil {
+		t.Error("expected a blank message to be rejected")
+	}
+	if models.ValidateMessageBody(strings.Repeat("a", models.MaxMessageLength+1)) == nil {
+		t.Error("expected a long message to be rejected")
+	}
+	if preview := models.MessagePreview(strings.Repeat("é", 150)); len([]rune(preview)) != models.MessagePreviewLength || !strings.HasSuffix(preview, "…") {
+		t.Errorf("unexpected preview %q", preview)
+	}
+	if models.MessagePreview("See you at 7") != "See you at 7" {
+		t.Error("expected short messages to be left as they are")
+	}
+}
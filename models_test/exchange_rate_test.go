@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCurrencyCodes(t *testing.T) {
+	if code := models.NormalizeCurrency(" eur "); code != "EUR" || !models.IsValidCurrency(code) {
+		t.Errorf("Expected EUR to be a valid currency, got %q", code)
+	}
+	for _, code := range []string{"", "EURO", "E1R", "eur"} {
+		if models.IsValidCurrency(code) {
+			t.Errorf("Expected %q to be rejected", code)
+		}
+	}
+
+	if code := (models.GroupSettings{}).CurrencyCode(); code != models.DefaultCurrency {
+		t.Errorf("Expected groups without a currency to use %s, got %s", models.DefaultCurrency, code)
+	}
+	if code := (models.GroupSettings{Currency: "INR"}).CurrencyCode(); code != "INR" {
+		t.Errorf("Expected the group's own currency, got %s", code)
+	}
+}
+
+func TestConvertedShares(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	// 100 EUR entered by hand, converted to 108.37 USD
+	shares, err := models.ConvertedShares(108.37, []models.CostShare{
+		{UserID: alice, Amount: 50},
+		{UserID: bob, Amount: 25},
+		{UserID: carol, Amount: 25},
+	})
+	if err != nil {
+		t.Fatalf("Expected the shares to convert, got %v", err)
+	}
+
+	total := 0.0
+	for _, share := range shares {
+		total += share.Amount
+	}
+	if shares[0].UserID != alice || shares[0].Amount != 54.19 {
+		t.Errorf("Expected alice to keep half of the converted amount, got %+v", shares[0])
+	}
+	if int64(total*100+0.5) != 10837 {
+		t.Errorf("Expected the converted shares to add up to 108.37, got %v", total)
+	}
+}
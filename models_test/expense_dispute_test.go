@@ -0,0 +1,103 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExpenseDisputeLifecycle(t *testing.T) {
+	groupID, alice, bob, admin := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	expense := models.CreateExpense(groupID, alice, "Internet", 60, models.SplitEvenly(60, []primitive.ObjectID{alice, bob}), models.ExpenseSourceManual)
+	now := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := expense.OpenDispute(alice, "Alice", "Wrong amount", now); err == nil {
+		t.Error("Expected the payer not to be able to dispute their own expense")
+	}
+	if err := expense.OpenDispute(admin, "Admin", "Wrong amount", now); err == nil {
+		t.Error("Expected a member without a share not to be able to dispute the expense")
+	}
+	if err := expense.OpenDispute(bob, "Bob", "   ", now); err == nil {
+		t.Error("Expected a dispute to need a reason")
+	}
+	if err := expense.CommentOnDispute(alice, "Alice", "Why?", now); err == nil {
+		t.Error("Expected no comments on an expense that is not disputed")
+	}
+
+	if err := expense.OpenDispute(bob, "Bob", " The plan is 50 ", now); err != nil {
+		t.Fatalf("Expected bob to dispute the expense, got %v", err)
+	}
+	if !expense.IsDisputed() || expense.Dispute.Reason != "The plan is 50" {
+		t.Fatalf("Expected an open dispute with the trimmed reason, got %+v", expense.Dispute)
+	}
+	if err := expense.OpenDispute(bob, "Bob", "Again", now); err == nil {
+		t.Error("Expected an open dispute not to be opened twice")
+	}
+	if err := expense.CommentOnDispute(alice, "Alice", "It went up in April", now.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected a comment on the open dispute, got %v", err)
+	}
+	if err := expense.ResolveDispute(admin, "Admin", models.DisputeMessageComment, "", now); err == nil {
+		t.Error("Expected a dispute to only be resolved by an edit or an admin")
+	}
+	if err := expense.ResolveDispute(admin, "Admin", models.DisputeMessageResolved, "", now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Expected the admin to resolve the dispute, got %v", err)
+	}
+	if expense.IsDisputed() || expense.Dispute.Status != models.DisputeStatusResolved || *expense.Dispute.ResolvedBy != admin {
+		t.Errorf("Expected the dispute to be resolved by the admin, got %+v", expense.Dispute)
+	}
+
+	// Disputing again keeps the earlier discussion
+	if err := expense.OpenDispute(bob, "Bob", "Still wrong", now.Add(3*time.Hour)); err != nil {
+		t.Fatalf("Expected the dispute to be reopened, got %v", err)
+	}
+	kinds := make([]models.DisputeMessageKind, 0)
+	for _, message := range expense.Dispute.Thread {
+		kinds = append(kinds, message.Kind)
+	}
+	want := []models.DisputeMessageKind{models.DisputeMessageOpened, models.DisputeMessageComment, models.DisputeMessageResolved, models.DisputeMessageOpened}
+	if len(kinds) != len(want) {
+		t.Fatalf("Expected thread %v, got %v", want, kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Errorf("Expected thread %v, got %v", want, kinds)
+			break
+		}
+	}
+	if expense.Dispute.ResolvedBy != nil || expense.Dispute.Reason != "Still wrong" {
+		t.Errorf("Expected the reopened dispute to start over, got %+v", expense.Dispute)
+	}
+}
+
+func TestDisputedExpensesLeftOutOfBalances(t *testing.T) {
+	groupID, alice, bob := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob}
+	rent := models.CreateExpense(groupID, alice, "Rent", 1000, models.SplitEvenly(1000, members), models.ExpenseSourceManual)
+	internet := models.CreateExpense(groupID, bob, "Internet", 60, models.SplitEvenly(60, members), models.ExpenseSourceManual)
+	if err := rent.OpenDispute(bob, "Bob", "Rent is 900", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	expenses := []models.Expense{*rent, *internet}
+
+	balances := models.ComputeBalances(expenses, nil, members)
+	if net := models.FindBalance(balances, bob).Net; net != 30 {
+		t.Errorf("Expected bob to be owed 30 with the rent disputed, got %.2f", net)
+	}
+	debts := models.PairwiseDebts(expenses, nil)
+	if len(debts) != 1 || debts[0].From != alice || debts[0].Amount != 30 || len(debts[0].Sources) != 1 {
+		t.Errorf("Expected only the internet debt, got %+v", debts)
+	}
+	if _, ok := models.OwingSince(expenses, nil)[bob]; ok {
+		t.Error("Expected bob not to owe anything while the rent is disputed")
+	}
+
+	if err := rent.ResolveDispute(alice, "Alice", models.DisputeMessageEdited, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	balances = models.ComputeBalances([]models.Expense{*rent, *internet}, nil, members)
+	if net := models.FindBalance(balances, bob).Net; net != -470 {
+		t.Errorf("Expected bob to owe 470 once the dispute is resolved, got %.2f", net)
+	}
+}
@@ -0,0 +1,76 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDefaultExpenseCategory(t *testing.T) {
+	shopping := models.CreateExpense(primitive.NewObjectID(), primitive.NewObjectID(), "Costco", 80, nil, models.ExpenseSourceShopping)
+	if shopping.Category != models.ExpenseCategoryGroceries {
+		t.Errorf("Expected shopping expenses to be groceries, got %q", shopping.Category)
+	}
+
+	legacy := models.Expense{Source: models.ExpenseSourceShopping}
+	if legacy.CategoryOrDefault() != models.ExpenseCategoryGroceries {
+		t.Errorf("Expected uncategorized shopping expenses to count as groceries, got %q", legacy.CategoryOrDefault())
+	}
+	if (models.Expense{Source: models.ExpenseSourceManual}).CategoryOrDefault() != models.ExpenseCategoryOther {
+		t.Error("Expected uncategorized manual expenses to count as other")
+	}
+	if models.IsValidExpenseCategory("travel") {
+		t.Error("Expected unknown categories to be rejected")
+	}
+}
+
+func TestBuildExpenseReport(t *testing.T) {
+	alice, bob, former := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob}
+
+	expenses := []models.Expense{
+		{PaidBy: bob, Amount: 40, Category: models.ExpenseCategoryFun, Splits: models.SplitEvenly(40, members)},
+		{PaidBy: alice, Amount: 1000, Category: models.ExpenseCategoryRent, Splits: models.SplitEvenly(1000, members)},
+		{PaidBy: former, Amount: 30.3, Source: models.ExpenseSourceShopping, Splits: []models.CostShare{{UserID: alice, Amount: 30.3}}},
+	}
+
+	report := models.BuildExpenseReport("2025-03", expenses, members)
+	if report.Total != 1070.3 {
+		t.Errorf("Expected a total of 1070.3, got %v", report.Total)
+	}
+
+	if len(report.Categories) != 3 {
+		t.Fatalf("Expected 3 categories, got %+v", report.Categories)
+	}
+	order := []models.ExpenseCategory{models.ExpenseCategoryRent, models.ExpenseCategoryGroceries, models.ExpenseCategoryFun}
+	for i, category := range order {
+		if report.Categories[i].Category != category {
+			t.Errorf("Expected %s at position %d, got %s", category, i, report.Categories[i].Category)
+		}
+	}
+
+	groceries := report.Categories[1]
+	if len(groceries.Members) != 2 || groceries.Members[0].UserID != alice || groceries.Members[1].UserID != former {
+		t.Errorf("Expected only alice and the former member in groceries, got %+v", groceries.Members)
+	}
+
+	if len(report.Members) != 3 {
+		t.Fatalf("Expected every member and the former member, got %+v", report.Members)
+	}
+	if report.Members[0].Paid != 1000 || report.Members[0].Share != 550.3 {
+		t.Errorf("Unexpected totals for alice: %+v", report.Members[0])
+	}
+
+	report.SetNames(map[primitive.ObjectID]string{alice: "Alice", bob: "Bob"})
+	records := report.CSVRecords()
+	if len(records) != 1+6+3 {
+		t.Fatalf("Expected a header, 6 category rows and 3 total rows, got %d", len(records))
+	}
+	if row := records[1]; row[0] != "2025-03" || row[1] != "rent" || row[2] != "Alice" || row[3] != "1000.00" || row[4] != "500.00" {
+		t.Errorf("Unexpected first row %v", row)
+	}
+	if row := records[len(records)-3]; row[1] != "total" || row[2] != "Alice" {
+		t.Errorf("Unexpected total row %v", row)
+	}
+}
@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBuildExpenseStatement(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob}
+	names := map[primitive.ObjectID]string{alice: "Alice", bob: "Bob"}
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	expenses := []models.Expense{
+		{PaidBy: bob, Description: "Groceries", Amount: 40, CreatedAt: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Splits: models.SplitEvenly(40, members)},
+		{PaidBy: alice, Description: "Rent", Amount: 1000, Category: models.ExpenseCategoryRent, CreatedAt: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Splits: models.SplitEvenly(1000, members),
+			OriginalAmount: 920, OriginalCurrency: "EUR"},
+	}
+	settlements := []models.Settlement{
+		{FromUserID: bob, ToUserID: alice, Amount: 400, Note: "March", CreatedAt: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	statement := models.BuildExpenseStatement(from, to, expenses, settlements, members, names)
+	statement.Currency = "USD"
+
+	if len(statement.Entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %+v", statement.Entries)
+	}
+	if statement.Entries[0].Description != "Rent" || statement.Entries[2].Type != "settlement" {
+		t.Errorf("Expected entries oldest first, got %+v", statement.Entries)
+	}
+	if statement.Spent != 1040 || statement.Repaid != 400 {
+		t.Errorf("Expected 1040 spent and 400 repaid, got %v and %v", statement.Spent, statement.Repaid)
+	}
+
+	// Bob owes 520 - 40 = 480 and has repaid 400
+	if len(statement.Debts) != 1 || statement.Debts[0].FromName != "Bob" || statement.Debts[0].ToName != "Alice" || statement.Debts[0].Amount != 80 {
+		t.Errorf("Expected Bob to owe Alice 80, got %+v", statement.Debts)
+	}
+
+	records := statement.CSVRecords()
+	if len(records) != 1+3+2 {
+		t.Fatalf("Expected a header, 3 entries and 2 balances, got %d rows", len(records))
+	}
+	rent := records[1]
+	if rent[0] != "2025-02-01" || rent[3] != "rent" || rent[4] != "Alice" || rent[6] != "1000.00" || rent[8] != "920.00" || rent[9] != "EUR" || rent[10] != "Alice 500.00; Bob 500.00" {
+		t.Errorf("Unexpected rent row %v", rent)
+	}
+	repayment := records[3]
+	if repayment[1] != "settlement" || repayment[4] != "Bob" || repayment[5] != "Alice" || repayment[8] != "" {
+		t.Errorf("Unexpected settlement row %v", repayment)
+	}
+	if balance := records[5]; balance[0] != "2025-06-30" || balance[1] != "balance" || balance[4] != "Bob" || balance[6] != "-80.00" {
+		t.Errorf("Unexpected balance row %v", balance)
+	}
+
+	text := strings.Join(statement.Lines(), "\n")
+	if !strings.Contains(text, "Period: 2025-01-01 to 2025-06-30") || !strings.Contains(text, "Bob pays Alice 80.00 USD") {
+		t.Errorf("Expected the period and the remaining debt in the statement, got:\n%s", text)
+	}
+}
+
+func TestBuildExpenseStatementNamesFormerMembers(t *testing.T) {
+	alice, former := primitive.NewObjectID(), primitive.NewObjectID()
+	expenses := []models.Expense{
+		{PaidBy: former, Description: "Internet", Amount: 60, Splits: models.SplitEvenly(60, []primitive.ObjectID{alice, former})},
+	}
+
+	statement := models.BuildExpenseStatement(time.Time{}, time.Time{}, expenses, nil, []primitive.ObjectID{alice}, map[primitive.ObjectID]string{alice: "Alice"})
+	if statement.Entries[0].From != former.Hex() {
+		t.Errorf("Expected a deleted account to be named by its ID, got %q", statement.Entries[0].From)
+	}
+	if len(statement.Balances) != 2 {
+		t.Errorf("Expected the former member's balance to be listed, got %+v", statement.Balances)
+	}
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRoundMoney(t *testing.T) {
+	cases := []struct {
+		amount, expected float64
+	}{
+		{10, 10},
+		{0.1 + 0.2, 0.3},
+		{12.345, 12.35},
+		{12.344, 12.34},
+		{-4.005, -4.01},
+		{33.333333, 33.33},
+	}
+
+	for _, tc := range cases {
+		if got := models.RoundMoney(tc.amount); got != tc.expected {
+			t.Errorf("RoundMoney(%v) = %v, expected %v", tc.amount, got, tc.expected)
+		}
+	}
+}
+
+func TestMergeShares(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	merged := models.MergeShares(
+		[]models.CostShare{{UserID: alice, Amount: 1.1}, {UserID: bob, Amount: 1.1}},
+		[]models.CostShare{{UserID: bob, Amount: 2.2}},
+	)
+
+	if len(merged) != 2 {
+		t.Fatalf("Expected 2 merged shares, got %d", len(merged))
+	}
+	if merged[0].UserID != alice || merged[0].Amount != 1.1 {
+		t.Errorf("Expected alice to owe 1.10 first, got %+v", merged[0])
+	}
+	if merged[1].UserID != bob || merged[1].Amount != 3.3 {
+		t.Errorf("Expected bob to owe 3.30, got %+v", merged[1])
+	}
+}
+
+func TestSplitByPercentage(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	shares, err := models.SplitByPercentage(100, []primitive.ObjectID{alice, bob, carol}, []float64{33.33, 33.33, 33.34})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	total := 0.0
+	for _, share := range shares {
+		total += share.Amount
+	}
+	if math.Round(total*100) != 10000 {
+		t.Errorf("Expected shares to add up to 100, got %v", total)
+	}
+	if shares[2].Amount != 33.34 {
+		t.Errorf("Expected carol to owe 33.34, got %v", shares[2].Amount)
+	}
+
+	shares, err = models.SplitByPercentage(10, []primitive.ObjectID{alice, bob, carol}, []float64{50, 25, 25})
+	if err != nil || shares[0].Amount != 5 || shares[1].Amount != 2.5 {
+		t.Errorf("Expected 5 / 2.50 / 2.50, got %+v (%v)", shares, err)
+	}
+
+	if _, err := models.SplitByPercentage(10, []primitive.ObjectID{alice, bob}, []float64{50, 40}); err == nil {
+		t.Error("Expected an error when percentages do not add up to 100")
+	}
+	if _, err := models.SplitByPercentage(10, []primitive.ObjectID{alice, bob}, []float64{100}); err == nil {
+		t.Error("Expected an error when a participant has no percentage")
+	}
+}
+
+func TestCheckExactSplit(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	if err := models.CheckExactSplit(30, []models.CostShare{{UserID: alice, Amount: 10.1}, {UserID: bob, Amount: 19.9}}); err != nil {
+		t.Errorf("Expected shares adding up to the amount to pass, got %v", err)
+	}
+	if err := models.CheckExactSplit(30, []models.CostShare{{UserID: alice, Amount: 10}, {UserID: bob, Amount: 19}}); err == nil {
+		t.Error("Expected an error when shares do not add up to the amount")
+	}
+	if err := models.CheckExactSplit(30, []models.CostShare{{UserID: alice, Amount: 40}, {UserID: bob, Amount: -10}}); err == nil {
+		t.Error("Expected an error for a negative share")
+	}
+}
+
+func TestComputeBalances(t *testing.T) {
+	alice, bob, carol, former := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	expenses := []models.Expense{
+		{PaidBy: alice, Amount: 30, Splits: models.SplitEvenly(30, []primitive.ObjectID{alice, bob, carol})},
+		{PaidBy: former, Amount: 10, Splits: []models.CostShare{{UserID: bob, Amount: 10}}},
+	}
+
+	balances := models.ComputeBalances(expenses, nil, []primitive.ObjectID{alice, bob, carol})
+	if len(balances) != 4 {
+		t.Fatalf("Expected 4 balances, got %d", len(balances))
+	}
+	if balances[0].UserID != alice || balances[0].Paid != 30 || balances[0].Net != 20 {
+		t.Errorf("Expected alice to be owed 20, got %+v", balances[0])
+	}
+	if balances[1].Share != 20 || balances[1].Net != -20 {
+		t.Errorf("Expected bob to owe 20, got %+v", balances[1])
+	}
+	if balances[3].UserID != former || balances[3].Net != 10 {
+		t.Errorf("Expected the former member last and owed 10, got %+v", balances[3])
+	}
+}
@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseFieldset(t *testing.T) {
+	fields, err := models.ParseFieldset(" id, title,due_date,,title ", models.Chore{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (models.Fieldset{"id", "title", "due_date"}); !reflect.DeepEqual(fields, want) {
+		t.Errorf("got %v, want %v", fields, want)
+	}
+	if !fields.Has("title") || fields.Has("points") {
+		t.Errorf("unexpected fieldset %v", fields)
+	}
+
+	for _, value := range []string{"title,password", "Title", "updatedAt"} {
+		if _, err := models.ParseFieldset(value, models.Chore{}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+	// Fields hidden from JSON cannot be asked for
+	if _, err := models.ParseFieldset("password", models.User{}); err == nil {
+		t.Error("expected a hidden field to be rejected")
+	}
+
+	all, err := models.ParseFieldset("", models.Chore{})
+	if err != nil || len(all) != 0 || !all.Has("points") {
+		t.Errorf("expected an empty fieldset to ask for everything, got %v %v", all, err)
+	}
+}
+
+func TestFieldsetProjection(t *testing.T) {
+	if projection := models.Fieldset(nil).Projection(models.Chore{}, "due_date"); projection != nil {
+		t.Errorf("expected every field to be loaded, got %v", projection)
+	}
+	fields := models.Fieldset{"id", "title"}
+	want := bson.M{"_id": 1, "title": 1, "due_date": 1}
+	if projection := fields.Projection(models.Chore{}, "due_date"); !reflect.DeepEqual(projection, want) {
+		t.Errorf("got %v, want %v", projection, want)
+	}
+}
+
+func TestFieldsetSelect(t *testing.T) {
+	chore := models.Chore{ID: primitive.NewObjectID(), Title: "Dishes", Points: 2, DueDate: time.Now()}
+	selected, err := models.Fieldset{"id", "title"}.Select(chore)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected["title"] != "Dishes" || selected["id"] != chore.ID.Hex() {
+		t.Errorf("unexpected selection %v", selected)
+	}
+
+	everything, err := models.Fieldset(nil).Select(chore)
+	if err != nil || everything["points"] != float64(2) {
+		t.Errorf("expected every field, got %v %v", everything, err)
+	}
+}
+
+func TestParseExpand(t *testing.T) {
+	expand, err := models.ParseExpand("group, assigned_to", "assigned_to", "group")
+	if err != nil || !reflect.DeepEqual(expand, []string{"group", "assigned_to"}) {
+		t.Errorf("unexpected expansion %v %v", expand, err)
+	}
+	if _, err := models.ParseExpand("created_by", "assigned_to", "group"); err == nil {
+		t.Error("expected an unknown expansion to be rejected")
+	}
+	if expand, err := models.ParseExpand("", "group"); err != nil || expand != nil {
+		t.Errorf("expected nothing to expand, got %v %v", expand, err)
+	}
+}
@@ -0,0 +1,42 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+)
+
+func TestRankSuggestionsPrefersHistoryAndPrefixMatches(t *testing.T) {
+	history := []models.ItemSuggestion{
+		{Name: "Oat milk", Count: 9},
+		{Name: "milk", Count: 3},
+	}
+
+	suggestions := models.RankSuggestions("mil", history, 5)
+	if len(suggestions) < 2 {
+		t.Fatalf("Expected at least 2 suggestions, got %d", len(suggestions))
+	}
+
+	// The prefix match wins over the more frequent word match, and the history entry replaces the dictionary one
+	if suggestions[0].Name != "milk" || suggestions[0].Source != models.SuggestionSourceHistory {
+		t.Errorf("Expected history 'milk' first, got %q from %s", suggestions[0].Name, suggestions[0].Source)
+	}
+	if suggestions[1].Name != "Oat milk" {
+		t.Errorf("Expected 'Oat milk' second, got %q", suggestions[1].Name)
+	}
+	for _, s := range suggestions[1:] {
+		if s.Name == "Milk" {
+			t.Error("Expected the dictionary duplicate of milk to be dropped")
+		}
+	}
+}
+
+func TestRankSuggestionsFallsBackToCommonGroceries(t *testing.T) {
+	suggestions := models.RankSuggestions("toil", nil, 5)
+	if len(suggestions) != 1 || suggestions[0].Name != "Toilet paper" || suggestions[0].Source != models.SuggestionSourceCommon {
+		t.Errorf("Expected the common 'Toilet paper' suggestion, got %+v", suggestions)
+	}
+
+	if got := models.RankSuggestions("p", nil, 3); len(got) != 3 {
+		t.Errorf("Expected the limit to be applied, got %d suggestions", len(got))
+	}
+}
@@ -0,0 +1,147 @@
+package models_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"cribb-backend/models"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func exportDocument(t *testing.T, doc bson.M) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		t.Fatalf("failed to marshal document: %v", err)
+	}
+	return data
+}
+
+func writeExport(t *testing.T, format models.GroupExportFormat, collections map[string][]bson.Raw, order []string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := models.NewGroupExportWriter(&buf, format)
+	counts := map[string]int{}
+	for _, name := range order {
+		if err := writer.Collection(name); err != nil {
+			t.Fatalf("failed to start %s: %v", name, err)
+		}
+		for _, doc := range collections[name] {
+			if err := writer.Document(doc); err != nil {
+				t.Fatalf("failed to write to %s: %v", name, err)
+			}
+		}
+		counts[name] = len(collections[name])
+	}
+	if err := writer.Close(models.GroupExportManifest{Version: models.GroupExportVersion, GroupName: "Flat 4", Collections: counts}); err != nil {
+		t.Fatalf("failed to close export: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestGroupExportJSON(t *testing.T) {
+	id := primitive.NewObjectID()
+	created := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	collections := map[string][]bson.Raw{
+		"chores": {
+			exportDocument(t, bson.M{"_id": id, "title": "Dishes", "created_at": created}),
+			exportDocument(t, bson.M{"title": "Trash"}),
+		},
+		"users": {},
+	}
+
+	data := writeExport(t, models.GroupExportJSON, collections, []string{"chores", "users"})
+
+	var export struct {
+		Collections map[string][]map[string]interface{} `json:"collections"`
+		Manifest    models.GroupExportManifest          `json:"manifest"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("expected valid JSON, got %v: %s", err, data)
+	}
+	if len(export.Collections["chores"]) != 2 || len(export.Collections["users"]) != 0 {
+		t.Fatalf("unexpected collections %v", export.Collections)
+	}
+	chore := export.Collections["chores"][0]
+	if oid, ok := chore["_id"].(map[string]interface{}); !ok || oid["$oid"] != id.Hex() {
+		t.Errorf("expected the ID in Extended JSON, got %v", chore["_id"])
+	}
+	if date, ok := chore["created_at"].(map[string]interface{}); !ok || date["$date"] != "2025-03-01T09:00:00Z" {
+		t.Errorf("expected the date in Extended JSON, got %v", chore["created_at"])
+	}
+	if export.Manifest.GroupName != "Flat 4" || export.Manifest.Collections["chores"] != 2 {
+		t.Errorf("unexpected manifest %+v", export.Manifest)
+	}
+}
+
+func TestGroupExportJSONWithoutCollections(t *testing.T) {
+	data := writeExport(t, models.GroupExportJSON, nil, nil)
+	if !json.Valid(data) {
+		t.Errorf("expected valid JSON, got %s", data)
+	}
+}
+
+func TestGroupExportNDJSON(t *testing.T) {
+	collections := map[string][]bson.Raw{
+		"pantry_items": {
+			exportDocument(t, bson.M{"name": "Rice"}),
+			exportDocument(t, bson.M{"name": "Eggs"}),
+		},
+	}
+
+	data := writeExport(t, models.GroupExportNDJSON, collections, []string{"pantry_items"})
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("expected a zip archive, got %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		files[file.Name] = string(content)
+	}
+
+	lines := strings.Split(strings.TrimSpace(files["pantry_items.ndjson"]), "\n")
+	if len(lines) != 2 || lines[0] != `{"name":"Rice"}` || lines[1] != `{"name":"Eggs"}` {
+		t.Errorf("expected a document per line, got %q", files["pantry_items.ndjson"])
+	}
+	var manifest models.GroupExportManifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &manifest); err != nil || manifest.Collections["pantry_items"] != 2 {
+		t.Errorf("unexpected manifest %q (%v)", files["manifest.json"], err)
+	}
+}
+
+func TestGroupExportDocumentNeedsCollection(t *testing.T) {
+	for _, format := range []models.GroupExportFormat{models.GroupExportJSON, models.GroupExportNDJSON} {
+		writer := models.NewGroupExportWriter(io.Discard, format)
+		if err := writer.Document(exportDocument(t, bson.M{"a": 1})); err != models.ErrNoExportCollection {
+			t.Errorf("%s: expected documents outside a collection to be rejected, got %v", format, err)
+		}
+	}
+}
+
+func TestGroupExportFileName(t *testing.T) {
+	export := models.CreateGroupExport(primitive.NewObjectID(), primitive.NewObjectID(), models.GroupExportNDJSON)
+	export.CreatedAt = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if name := export.FileName(); name != "cribb-export-"+export.GroupID.Hex()+"-20250301.zip" || export.ContentType() != "application/zip" {
+		t.Errorf("unexpected archive %q (%s)", name, export.ContentType())
+	}
+	export.Format = models.GroupExportJSON
+	if !strings.HasSuffix(export.FileName(), ".json") || export.ContentType() != "application/json" {
+		t.Errorf("unexpected archive %q (%s)", export.FileName(), export.ContentType())
+	}
+	if models.IsValidGroupExportFormat("xml") {
+		t.Error("expected unknown formats to be rejected")
+	}
+}
@@ -42,3 +42,36 @@ func TestGenerateGroupCode(t *testing.T) {
 		}
 	}
 }
+
+func TestScoringRulesValidate(t *testing.T) {
+	if err := models.DefaultScoringRules().Validate(); err != nil {
+		t.Errorf("Expected default scoring rules to be valid, got %v", err)
+	}
+
+	invalid := []models.ScoringRules{
+		{PointsPerCompletion: 0},
+		{PointsPerCompletion: models.MaxScoringPoints + 1},
+		{PointsPerCompletion: 5, OverduePenalty: -1},
+		{PointsPerCompletion: 5, OverduePenalty: models.MaxScoringPoints + 1},
+	}
+	for _, rules := range invalid {
+		if err := rules.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", rules)
+		}
+	}
+}
+
+func TestScoringRulesChorePoints(t *testing.T) {
+	rules := models.ScoringRules{PointsPerCompletion: 3}
+	if got := rules.ChorePoints(7); got != 7 {
+		t.Errorf("Expected explicit points to be kept, got %d", got)
+	}
+	if got := rules.ChorePoints(0); got != 3 {
+		t.Errorf("Expected group default of 3 points, got %d", got)
+	}
+
+	// Groups created before scoring rules existed fall back to the global default
+	if got := (models.ScoringRules{}).ChorePoints(0); got != models.DefaultPointsPerCompletion {
+		t.Errorf("Expected %d points for legacy groups, got %d", models.DefaultPointsPerCompletion, got)
+	}
+}
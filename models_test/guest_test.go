@@ -0,0 +1,82 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func guestStay(name string, arrive, depart int) models.GuestStay {
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 15, 0, 0, 0, time.UTC) }
+	stay := models.CreateGuestStay(primitive.NewObjectID(), primitive.NewObjectID(), name, day(arrive), day(depart), "")
+	stay.ID = primitive.NewObjectID()
+	return *stay
+}
+
+func TestGuestStayNights(t *testing.T) {
+	stay := guestStay("  Jamie ", 7, 10)
+	if stay.Nights() != 3 || stay.GuestName != "Jamie" || stay.ArriveOn.Hour() != 0 {
+		t.Errorf("unexpected stay %+v with %d nights", stay, stay.Nights())
+	}
+}
+
+func TestConsecutiveGuestNights(t *testing.T) {
+	stay := models.CreateGuestStay(primitive.NewObjectID(), primitive.NewObjectID(), "jamie",
+		time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC), "")
+	others := []models.GuestStay{
+		guestStay("Jamie", 7, 10),  // Leaves the day the new stay starts
+		guestStay("JAMIE", 12, 14), // Arrives the day it ends
+		guestStay("Jamie", 15, 17), // A night in between
+		guestStay("Alex", 1, 20),   // Someone else
+	}
+	if nights := models.ConsecutiveGuestNights(*stay, others); nights != 7 {
+		t.Errorf("expected 7 nights in a row, got %d", nights)
+	}
+	if nights := models.ConsecutiveGuestNights(*stay, nil); nights != 2 {
+		t.Errorf("expected the stay alone to be 2 nights, got %d", nights)
+	}
+
+	settings := models.GroupSettings{MaxGuestNights: 5}
+	if !settings.GuestNightsExceeded(7) || settings.GuestNightsExceeded(5) {
+		t.Error("expected only runs longer than the limit to exceed it")
+	}
+	if (models.GroupSettings{}).GuestNightsExceeded(60) {
+		t.Error("expected no limit by default")
+	}
+}
+
+func TestValidateGuestStayDates(t *testing.T) {
+	now := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	if err := models.ValidateGuestStayDates(now, now.AddDate(0, 0, 1), now); err != nil {
+		t.Errorf("expected a stay from today to be valid, got %v", err)
+	}
+	if models.ValidateGuestStayDates(now.AddDate(0, 0, -1), now.AddDate(0, 0, 1), now) == nil {
+		t.Error("expected stays in the past to be rejected")
+	}
+	if models.ValidateGuestStayDates(now, now.Add(time.Hour), now) == nil {
+		t.Error("expected stays without a night to be rejected")
+	}
+	if models.ValidateGuestStayDates(now, now.AddDate(0, 0, models.MaxGuestNightsLimit+1), now) == nil {
+		t.Error("expected overly long stays to be rejected")
+	}
+}
+
+func TestGuestNotifications(t *testing.T) {
+	stay := guestStay("Jamie", 7, 8)
+	registered := models.GuestRegisteredNotification(stay, primitive.NewObjectID(), "Sam")
+	if !strings.Contains(registered.Message, "Sam has Jamie staying") || !strings.HasSuffix(registered.Message, "(1 night)") {
+		t.Errorf("unexpected message %q", registered.Message)
+	}
+	if registered.Type.Event() != models.NotificationEventGuests {
+		t.Error("expected guest notifications to follow the guests setting")
+	}
+
+	arriving := models.GuestArrivingNotification(guestStay("Jamie", 7, 10), primitive.NewObjectID(), "Sam")
+	arriving.Localize(models.LocaleSpanish)
+	if !strings.HasPrefix(arriving.Message, "Jamie, invitado de Sam, llega el") {
+		t.Errorf("unexpected Spanish message %q", arriving.Message)
+	}
+}
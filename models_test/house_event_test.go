@@ -0,0 +1,119 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQuietHoursOverlaps(t *testing.T) {
+	quiet := models.QuietHours{Start: "22:00", End: "07:00", TimeZone: "America/New_York"}
+	newYork, _ := time.LoadLocation("America/New_York")
+	at := func(day, hour, minute int) time.Time { return time.Date(2025, 3, day, hour, minute, 0, 0, newYork) }
+
+	cases := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"evening before", at(7, 19, 0), at(7, 22, 0), false},
+		{"runs past start", at(7, 20, 0), at(7, 23, 30), true},
+		{"early morning", at(8, 6, 0), at(8, 9, 0), true},
+		{"daytime", at(8, 7, 0), at(8, 21, 59), false},
+		{"spans a day", at(8, 12, 0), at(9, 12, 0), true},
+	}
+	for _, c := range cases {
+		if got := quiet.Overlaps(c.start, c.end); got != c.want {
+			t.Errorf("%s: Overlaps = %v, want %v", c.name, got, c.want)
+		}
+	}
+
+	if (models.QuietHours{}).Overlaps(at(7, 0, 0), at(8, 0, 0)) {
+		t.Error("expected no quiet hours to overlap nothing")
+	}
+}
+
+func TestHouseEventQuietHourConflicts(t *testing.T) {
+	host, sleeper, nightOwl := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	quiet := models.QuietHours{Start: "23:00", End: "08:00"}
+	members := []models.User{
+		{ID: host, Name: "Host"},
+		{ID: sleeper, Name: "Sleeper"},
+		{ID: nightOwl, Name: "Night owl"},
+	}
+	members[0].Preferences.Notifications.QuietHours = quiet
+	members[1].Preferences.Notifications.QuietHours = quiet
+
+	start := time.Date(2025, 3, 8, 20, 0, 0, 0, time.UTC)
+	event := models.CreateHouseEvent(primitive.NewObjectID(), host, models.HouseEventParty, "Party", start, start.Add(5*time.Hour), "", 0)
+	conflicts := event.QuietHourConflicts(members)
+	if len(conflicts) != 1 || conflicts[0].UserID != sleeper {
+		t.Errorf("expected only the sleeper to conflict, got %+v", conflicts)
+	}
+	if event.RemindAt != nil {
+		t.Error("expected a zero reminder to turn reminders off")
+	}
+}
+
+func TestHouseEventRSVP(t *testing.T) {
+	host, guest := primitive.NewObjectID(), primitive.NewObjectID()
+	start := time.Date(2025, 3, 8, 20, 0, 0, 0, time.UTC)
+	event := models.CreateHouseEvent(primitive.NewObjectID(), host, models.HouseEventParty, "Party", start, start.Add(time.Hour), "", time.Hour)
+
+	if event.RSVP(host) != models.RSVPGoing || event.RSVP(guest) != "" {
+		t.Errorf("expected only the host to be going, got %+v", event.RSVPs)
+	}
+	if event.RemindAt == nil || !event.RemindAt.Equal(start.Add(-time.Hour)) {
+		t.Errorf("expected a reminder an hour before, got %v", event.RemindAt)
+	}
+
+	event.SetRSVP(guest, models.RSVPMaybe, start)
+	event.SetRSVP(guest, models.RSVPNotGoing, start)
+	if event.RSVP(guest) != models.RSVPNotGoing || len(event.RSVPs) != 2 {
+		t.Errorf("expected the guest's answer to be replaced, got %+v", event.RSVPs)
+	}
+}
+
+func TestValidateHouseEventTimes(t *testing.T) {
+	now := time.Date(2025, 3, 7, 12, 0, 0, 0, time.UTC)
+	if err := models.ValidateHouseEventTimes(now.Add(time.Hour), now.Add(2*time.Hour), now); err != nil {
+		t.Errorf("expected a valid event, got %v", err)
+	}
+	if models.ValidateHouseEventTimes(now.Add(-time.Hour), now.Add(time.Hour), now) == nil {
+		t.Error("expected events in the past to be rejected")
+	}
+	if models.ValidateHouseEventTimes(now.Add(2*time.Hour), now.Add(time.Hour), now) == nil {
+		t.Error("expected events ending before they start to be rejected")
+	}
+	if models.ValidateHouseEventTimes(now.Add(time.Hour), now.Add(8*24*time.Hour), now) == nil {
+		t.Error("expected events longer than a week to be rejected")
+	}
+}
+
+func TestHouseEventNotifications(t *testing.T) {
+	start := time.Date(2025, 3, 7, 23, 30, 0, 0, time.UTC)
+	event := models.CreateHouseEvent(primitive.NewObjectID(), primitive.NewObjectID(), models.HouseEventMaintenance, "Plumber", start, start.Add(time.Hour), "America/New_York", time.Hour)
+	event.Location = "Kitchen"
+
+	reminder := models.HouseEventReminderNotification(event, primitive.NewObjectID())
+	if reminder.Type != models.NotificationTypeHouseEventReminder || !strings.Contains(reminder.Message, "18:30") || !strings.Contains(reminder.Message, "at Kitchen") {
+		t.Errorf("expected the start in the event's time zone, got %q", reminder.Message)
+	}
+	if reminder.Type.Event() != models.NotificationEventHouseEvents {
+		t.Error("expected house event notifications to follow the house events setting")
+	}
+
+	created := models.HouseEventCreatedNotification(event, primitive.NewObjectID(), "Sam")
+	created.Localize(models.LocaleSpanish)
+	if !strings.HasPrefix(created.Message, "Sam añadió Plumber") {
+		t.Errorf("unexpected Spanish message %q", created.Message)
+	}
+
+	calendar := event.CalendarEvent()
+	if calendar.AllDay || calendar.Location != "Kitchen" || !strings.HasPrefix(calendar.Description, "Maintenance visit") {
+		t.Errorf("unexpected calendar event %+v", calendar)
+	}
+}
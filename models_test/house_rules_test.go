@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateHouseRules(t *testing.T) {
+	if err := models.ValidateHouseRules("Quiet after 10pm.\nDishes done the same day.", "Added dishes"); err != nil {
+		t.Errorf("expected rules to be valid, got %v", err)
+	}
+	if models.ValidateHouseRules("   ", "") == nil {
+		t.Error("expected blank rules to be rejected")
+	}
+	if models.ValidateHouseRules(strings.Repeat("a", models.MaxHouseRulesLength+1), "") == nil {
+		t.Error("expected overlong rules to be rejected")
+	}
+	if models.ValidateHouseRules("Quiet after 10pm.", strings.Repeat("a", models.MaxHouseRulesNoteLength+1)) == nil {
+		t.Error("expected an overlong note to be rejected")
+	}
+}
+
+func TestCreateHouseRulesVersions(t *testing.T) {
+	groupID, adminID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	first := models.CreateHouseRules(groupID, adminID, nil, "  Quiet after 10pm.  ", "")
+	if first.Version != 1 || first.Body != "Quiet after 10pm." {
+		t.Errorf("expected trimmed version 1, got version %d %q", first.Version, first.Body)
+	}
+	second := models.CreateHouseRules(groupID, adminID, first, "Quiet after 11pm.", " Later quiet hours ")
+	if second.Version != 2 || second.Note != "Later quiet hours" {
+		t.Errorf("expected version 2 with a trimmed note, got version %d %q", second.Version, second.Note)
+	}
+}
+
+func TestAcknowledgmentStatus(t *testing.T) {
+	sam := models.User{ID: primitive.NewObjectID(), Name: "Sam"}
+	alex := models.User{ID: primitive.NewObjectID(), Name: "Alex"}
+	jo := models.User{ID: primitive.NewObjectID(), Name: "Jo"}
+	kai := models.User{ID: primitive.NewObjectID(), Name: "Kai"}
+	at := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	acknowledgments := []models.HouseRulesAcknowledgment{
+		{UserID: sam.ID, Version: 1, AcknowledgedAt: at},
+		{UserID: sam.ID, Version: 2, AcknowledgedAt: at.Add(time.Hour)},
+		{UserID: alex.ID, Version: 1, AcknowledgedAt: at},
+		{UserID: kai.ID, Version: 2, AcknowledgedAt: at},
+	}
+
+	status := models.AcknowledgmentStatus([]models.User{sam, alex, jo, kai}, acknowledgments, 2)
+	if len(status) != 4 {
+		t.Fatalf("expected every member listed, got %d", len(status))
+	}
+	// Members who have not accepted version 2 come first, then by name
+	want := []struct {
+		name         string
+		acknowledged bool
+		latest       int
+	}{{"Alex", false, 1}, {"Jo", false, 0}, {"Kai", true, 2}, {"Sam", true, 2}}
+	for i, entry := range want {
+		got := status[i]
+		if got.Name != entry.name || got.Acknowledged != entry.acknowledged || got.LatestVersion != entry.latest {
+			t.Errorf("entry %d: expected %+v, got %s acknowledged=%v latest=%d", i, entry, got.Name, got.Acknowledged, got.LatestVersion)
+		}
+	}
+	if status[1].AcknowledgedAt != nil {
+		t.Error("expected no acknowledgment time for a member who never accepted")
+	}
+	if status[3].AcknowledgedAt == nil || !status[3].AcknowledgedAt.Equal(at.Add(time.Hour)) {
+		t.Errorf("expected Sam's latest acknowledgment time, got %v", status[3].AcknowledgedAt)
+	}
+}
+
+func TestHouseRulesUpdatedNotification(t *testing.T) {
+	rules := models.CreateHouseRules(primitive.NewObjectID(), primitive.NewObjectID(), &models.HouseRules{Version: 1}, "Quiet after 11pm.", "Later quiet hours")
+	notification := models.HouseRulesUpdatedNotification(rules, primitive.NewObjectID(), "Sam")
+	if notification.Type != models.NotificationTypeHouseRulesUpdated {
+		t.Errorf("expected house rules notification, got %s", notification.Type)
+	}
+	if !strings.Contains(notification.Message, "Sam updated the house rules: Later quiet hours") {
+		t.Errorf("unexpected message %q", notification.Message)
+	}
+	if models.NotificationTypeHouseRulesUpdated.Event() != models.NotificationEventHouseRules {
+		t.Error("expected house rules notifications to follow the house rules preference")
+	}
+}
@@ -0,0 +1,79 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseInboundCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		want models.InboundCommand
+	}{
+		{"Add milk to the shopping list", models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "milk", Quantity: 1}},
+		{"add 2 oat milk to my grocery list.", models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "oat milk", Quantity: 2}},
+		{"Buy   eggs", models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "eggs", Quantity: 1}},
+		{"put coffee on the shopping list", models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: "coffee", Quantity: 1}},
+		{"Complete chore Dishes", models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: "dishes"}},
+		{"mark the trash as done!", models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: "trash"}},
+		{"done with vacuuming", models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: "vacuuming"}},
+		{"finished laundry", models.InboundCommand{Action: models.InboundActionCompleteChore, Chore: "laundry"}},
+	}
+	for _, tt := range tests {
+		got, err := models.ParseInboundCommand(tt.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: expected %+v, got %+v", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestParseInboundCommandRejectsUnknown(t *testing.T) {
+	for _, text := range []string{"", "hello", "turn on the lights"} {
+		if _, err := models.ParseInboundCommand(text); err != models.ErrUnknownInboundCommand {
+			t.Errorf("%q: expected ErrUnknownInboundCommand, got %v", text, err)
+		}
+	}
+}
+
+func TestInboundCommandValidate(t *testing.T) {
+	command := models.InboundCommand{Action: models.InboundActionAddShoppingItem, Item: " milk "}
+	if err := command.Validate(); err != nil || command.Item != "milk" || command.Quantity != 1 {
+		t.Errorf("expected a trimmed item with the default quantity, got %+v (%v)", command, err)
+	}
+
+	invalid := []models.InboundCommand{
+		{Action: models.InboundActionAddShoppingItem},
+		{Action: models.InboundActionAddShoppingItem, Item: "milk", Quantity: -1},
+		{Action: models.InboundActionCompleteChore, Chore: "  "},
+		{Action: "water_plants"},
+	}
+	for _, command := range invalid {
+		if err := command.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", command)
+		}
+	}
+}
+
+func TestCreateInboundHook(t *testing.T) {
+	hook, token, err := models.CreateInboundHook(primitive.NewObjectID(), primitive.NewObjectID(), "Kitchen button")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(token, "cih_") || !hook.Active {
+		t.Errorf("unexpected hook %+v with token %q", hook, token)
+	}
+	if hook.TokenHash != models.HashInboundHookToken(token) || strings.Contains(hook.TokenHash, token) {
+		t.Error("expected only the token's hash to be stored")
+	}
+	_, other, _ := models.CreateInboundHook(hook.GroupID, hook.CreatedBy, hook.Name)
+	if other == token {
+		t.Error("expected every hook to get its own token")
+	}
+}
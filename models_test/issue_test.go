@@ -0,0 +1,103 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateIssue(t *testing.T) {
+	if err := models.ValidateIssue(models.IssueCategoryNoise, "Music past 1am on a weeknight"); err != nil {
+		t.Errorf("expected issue to be valid, got %v", err)
+	}
+	if models.ValidateIssue("parking", "Blocked the driveway") == nil {
+		t.Error("expected an unknown category to be rejected")
+	}
+	if models.ValidateIssue(models.IssueCategoryDamage, "  ") == nil {
+		t.Error("expected a blank description to be rejected")
+	}
+	if models.ValidateIssue(models.IssueCategoryCleanliness, strings.Repeat("a", models.MaxIssueDescriptionLength+1)) == nil {
+		t.Error("expected an overlong description to be rejected")
+	}
+}
+
+func TestIssueReporterAnonymity(t *testing.T) {
+	reporter, other := primitive.NewObjectID(), primitive.NewObjectID()
+	issue := models.CreateIssue(primitive.NewObjectID(), reporter, models.IssueCategoryCleanliness, "Dishes left for days", true)
+
+	if issue.Reporter(other) != nil {
+		t.Error("expected an anonymous reporter to be hidden from other members")
+	}
+	if got := issue.Reporter(reporter); got == nil || *got != reporter {
+		t.Error("expected the reporter to see themselves")
+	}
+
+	issue.Anonymous = false
+	if got := issue.Reporter(other); got == nil || *got != reporter {
+		t.Error("expected a named reporter to be shown")
+	}
+}
+
+func TestIssueSetStatus(t *testing.T) {
+	member := primitive.NewObjectID()
+	now := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	issue := models.CreateIssue(primitive.NewObjectID(), primitive.NewObjectID(), models.IssueCategoryDamage, "Cracked bathroom tile", false)
+
+	if err := issue.SetStatus(models.IssueStatusAcknowledged, member, "", now); err != nil {
+		t.Fatalf("expected issue to be acknowledged, got %v", err)
+	}
+	if issue.AcknowledgedBy == nil || *issue.AcknowledgedBy != member || !issue.UpdatedAt.Equal(now) {
+		t.Error("expected acknowledgment to be recorded")
+	}
+	if issue.SetStatus(models.IssueStatusAcknowledged, member, "", now) == nil {
+		t.Error("expected acknowledging twice to be rejected")
+	}
+
+	if err := issue.SetStatus(models.IssueStatusResolved, member, " Landlord replaced it ", now.Add(time.Hour)); err != nil {
+		t.Fatalf("expected issue to be resolved, got %v", err)
+	}
+	if issue.Resolution != "Landlord replaced it" || issue.ResolvedAt == nil {
+		t.Errorf("expected resolution to be recorded, got %q", issue.Resolution)
+	}
+	if issue.SetStatus(models.IssueStatusAcknowledged, member, "", now) == nil {
+		t.Error("expected a resolved issue to need reopening before acknowledgment")
+	}
+
+	if err := issue.SetStatus(models.IssueStatusOpen, member, "", now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("expected issue to be reopened, got %v", err)
+	}
+	if issue.AcknowledgedBy != nil || issue.ResolvedBy != nil || issue.Resolution != "" {
+		t.Error("expected reopening to clear acknowledgment and resolution")
+	}
+	if issue.SetStatus("ignored", member, "", now) == nil {
+		t.Error("expected an unknown status to be rejected")
+	}
+}
+
+func TestIssueNotifications(t *testing.T) {
+	issue := models.CreateIssue(primitive.NewObjectID(), primitive.NewObjectID(), models.IssueCategoryNoise, "Music past 1am", true)
+	reported := models.IssueReportedNotification(issue, primitive.NewObjectID(), "Sam")
+	if strings.Contains(reported.Message, "Sam") || !strings.Contains(reported.Message, "A roommate logged an issue: Music past 1am") {
+		t.Errorf("expected an anonymous notification, got %q", reported.Message)
+	}
+	if reported.Title != "New noise issue" {
+		t.Errorf("unexpected title %q", reported.Title)
+	}
+
+	issue.Anonymous = false
+	if reported := models.IssueReportedNotification(issue, primitive.NewObjectID(), "Sam"); !strings.HasPrefix(reported.Message, "Sam logged") {
+		t.Errorf("expected the reporter to be named, got %q", reported.Message)
+	}
+
+	issue.SetStatus(models.IssueStatusResolved, primitive.NewObjectID(), "Agreed on headphones", time.Now())
+	resolved := models.IssueResolvedNotification(issue, "Alex")
+	if resolved.UserID != issue.ReportedBy || resolved.Message != `Alex resolved your noise issue "Music past 1am": Agreed on headphones` {
+		t.Errorf("unexpected resolution notification %q", resolved.Message)
+	}
+	if models.NotificationTypeIssueResolved.Event() != models.NotificationEventIssues {
+		t.Error("expected issue notifications to follow the issues preference")
+	}
+}
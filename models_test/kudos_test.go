@@ -0,0 +1,85 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateKudos(t *testing.T) {
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	if err := models.ValidateKudos(sam, alex, "Thanks for deep cleaning the oven", 3); err != nil {
+		t.Errorf("expected kudos to be valid, got %v", err)
+	}
+	if models.ValidateKudos(sam, sam, "Great job me", 0) == nil {
+		t.Error("expected kudos to yourself to be rejected")
+	}
+	if models.ValidateKudos(sam, alex, " ", 0) == nil {
+		t.Error("expected kudos without a message to be rejected")
+	}
+	if models.ValidateKudos(sam, alex, strings.Repeat("a", models.MaxKudosMessageLength+1), 0) == nil {
+		t.Error("expected an overlong message to be rejected")
+	}
+	if models.ValidateKudos(sam, alex, "Thanks", models.MaxKudosPoints+1) == nil || models.ValidateKudos(sam, alex, "Thanks", -1) == nil {
+		t.Error("expected points outside the limit to be rejected")
+	}
+}
+
+func TestCreateKudosMonth(t *testing.T) {
+	kudos := models.CreateKudos(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), "  Thanks!  ", 2)
+	if kudos.Message != "Thanks!" || kudos.Month != models.KudosMonth(kudos.CreatedAt) {
+		t.Errorf("unexpected kudos %+v", kudos)
+	}
+	if got := models.KudosMonth(time.Date(2025, 3, 31, 23, 30, 0, 0, time.FixedZone("EST", -5*3600))); got != "2025-04" {
+		t.Errorf("expected kudos months to be in UTC, got %s", got)
+	}
+}
+
+func TestSummarizeKudos(t *testing.T) {
+	sam, alex, jo := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	kudos := []models.Kudos{
+		{FromID: sam, ToID: alex, Points: 2},
+		{FromID: jo, ToID: alex, Points: 0},
+		{FromID: alex, ToID: sam, Points: 3},
+	}
+
+	summary := models.SummarizeKudos("2025-03", 20, []primitive.ObjectID{sam, alex, jo}, kudos)
+	if summary.Kudos != 3 || summary.PointsGiven != 5 || summary.Budget != 20 {
+		t.Errorf("unexpected totals %+v", summary)
+	}
+	if len(summary.Members) != 3 {
+		t.Fatalf("expected every member listed, got %d", len(summary.Members))
+	}
+	first, second, last := summary.Members[0], summary.Members[1], summary.Members[2]
+	if first.UserID != alex || first.Received != 2 || first.Points != 2 || first.Sent != 1 {
+		t.Errorf("expected Alex first with 2 kudos, got %+v", first)
+	}
+	if second.UserID != sam || second.Received != 1 || second.Points != 3 {
+		t.Errorf("expected Sam second, got %+v", second)
+	}
+	if last.UserID != jo || last.Received != 0 || last.Sent != 1 {
+		t.Errorf("expected Jo last, got %+v", last)
+	}
+	if summary.TopRecipient == nil || *summary.TopRecipient != alex {
+		t.Error("expected Alex to be the top recipient")
+	}
+
+	if empty := models.SummarizeKudos("2025-04", 0, []primitive.ObjectID{sam}, nil); empty.TopRecipient != nil || empty.Kudos != 0 {
+		t.Errorf("expected no top recipient without kudos, got %+v", empty)
+	}
+}
+
+func TestKudosReceivedNotification(t *testing.T) {
+	kudos := models.CreateKudos(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), "Thanks for fixing the sink", 2)
+	notification := models.KudosReceivedNotification(kudos, "Sam")
+	if notification.UserID != kudos.ToID || notification.Title != "Kudos from Sam" || notification.Message != "Thanks for fixing the sink (+2 points)" {
+		t.Errorf("unexpected notification %q: %q", notification.Title, notification.Message)
+	}
+	kudos.Points = 0
+	if notification := models.KudosReceivedNotification(kudos, "Sam"); notification.Message != "Thanks for fixing the sink" {
+		t.Errorf("expected no points in the message, got %q", notification.Message)
+	}
+}
@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	now := time.Date(2025, 2, 13, 15, 30, 0, 0, time.UTC) // Thursday
+
+	start, end := models.PeriodBounds(models.LeaderboardPeriodWeekly, now)
+	if !start.Equal(time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 2, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected weekly bounds %v - %v", start, end)
+	}
+
+	start, end = models.PeriodBounds(models.LeaderboardPeriodMonthly, now)
+	if !start.Equal(time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected monthly bounds %v - %v", start, end)
+	}
+}
+
+func TestCreateLeaderboardSnapshot(t *testing.T) {
+	alice := models.LeaderboardEntry{UserID: primitive.NewObjectID(), Username: "alice", Points: 10, Completions: 2}
+	bob := models.LeaderboardEntry{UserID: primitive.NewObjectID(), Username: "bob", Points: 15, Completions: 3}
+	carol := models.LeaderboardEntry{UserID: primitive.NewObjectID(), Username: "carol", Points: 10, Completions: 4}
+
+	snapshot := models.CreateLeaderboardSnapshot(primitive.NewObjectID(), models.LeaderboardPeriodWeekly, time.Now(), time.Now(),
+		[]models.LeaderboardEntry{alice, bob, carol})
+
+	if snapshot.WinnerID == nil || *snapshot.WinnerID != bob.UserID {
+		t.Errorf("Expected bob to win")
+	}
+	if snapshot.Entries[1].Username != "carol" || snapshot.Entries[2].Username != "alice" {
+		t.Errorf("Expected ties broken by completions, got %v", snapshot.Entries)
+	}
+
+	// Nobody scoring means no winner
+	empty := models.CreateLeaderboardSnapshot(primitive.NewObjectID(), models.LeaderboardPeriodMonthly, time.Now(), time.Now(),
+		[]models.LeaderboardEntry{{UserID: primitive.NewObjectID(), Username: "dave"}})
+	if empty.WinnerID != nil {
+		t.Error("Expected no winner when nobody scored")
+	}
+}
+
+func TestComputeOnTimeRate(t *testing.T) {
+	member := models.MemberComparison{Completions: 5, Rated: 4, OnTime: 3}
+	member.ComputeOnTimeRate()
+	if member.OnTimeRate != 0.75 {
+		t.Errorf("Expected on-time rate 0.75, got %v", member.OnTimeRate)
+	}
+
+	empty := models.MemberComparison{}
+	empty.ComputeOnTimeRate()
+	if empty.OnTimeRate != 0 {
+		t.Errorf("Expected on-time rate 0 without rated completions, got %v", empty.OnTimeRate)
+	}
+}
+
+func TestPeriodScoreField(t *testing.T) {
+	if field := models.LeaderboardPeriodWeekly.ScoreField(); field != "weekly_score" {
+		t.Errorf("Expected weekly_score, got %s", field)
+	}
+	if field := models.LeaderboardPeriodMonthly.ScoreField(); field != "monthly_score" {
+		t.Errorf("Expected monthly_score, got %s", field)
+	}
+}
+
+func TestPeriodScoresSince(t *testing.T) {
+	alice := primitive.NewObjectID()
+	bob := primitive.NewObjectID()
+	// The week ended at midnight, but the archive job only ran at 01:30
+	_, end := models.PeriodBounds(models.LeaderboardPeriodWeekly, time.Date(2025, 2, 13, 15, 30, 0, 0, time.UTC))
+
+	event := func(userID primitive.ObjectID, eventType models.ScoreEventType, delta int, at time.Time) models.ScoreEvent {
+		return models.ScoreEvent{UserID: userID, Type: eventType, Delta: delta, CreatedAt: at}
+	}
+	events := []models.ScoreEvent{
+		event(alice, models.ScoreEventCompletion, 5, end.Add(-time.Minute)), // Last week's, reset with it
+		event(alice, models.ScoreEventCompletion, 3, end),
+		event(alice, models.ScoreEventCompletion, 1, end.Add(30*time.Minute)),
+		event(bob, models.ScoreEventKudos, 2, end.Add(time.Hour)),
+		event(bob, models.ScoreEventPenalty, -1, end.Add(time.Hour)),     // Penalties come off the period scores too
+		event(bob, models.ScoreEventRedemption, -10, end.Add(time.Hour)), // Redemptions only touch the total score
+	}
+
+	scores := models.PeriodScoresSince(events, end)
+	if scores[alice] != 4 {
+		t.Errorf("Expected alice to keep the 4 points earned after the period ended, got %d", scores[alice])
+	}
+	if scores[bob] != 1 {
+		t.Errorf("Expected bob to keep 1 point after his penalty, got %d", scores[bob])
+	}
+	if len(scores) != 2 {
+		t.Errorf("Expected scores for 2 members, got %v", scores)
+	}
+}
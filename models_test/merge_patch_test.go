@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// The examples of RFC 7386, appendix A
+	tests := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := models.ApplyMergePatch([]byte(tt.target), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s + %s: unexpected error %v", tt.target, tt.patch, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("%s + %s = %s, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestParseMergePatch(t *testing.T) {
+	for _, body := range []string{``, `[]`, `null`, `"title"`, `{"title":`} {
+		if _, err := models.ParseMergePatch([]byte(body)); err == nil {
+			t.Errorf("expected %q to be rejected", body)
+		}
+	}
+
+	patch, err := models.ParseMergePatch([]byte(` {"title": "Dishes", "description": null} `))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !patch.Has("title") || patch.IsNull("title") || !patch.IsNull("description") || patch.Has("points") {
+		t.Errorf("unexpected members %v", patch)
+	}
+	if err := patch.Allow("title", "description"); err != nil {
+		t.Errorf("expected the members to be allowed, got %v", err)
+	}
+	if err := patch.Allow("title"); err == nil || err.Error() != "description cannot be changed" {
+		t.Errorf("expected the description to be refused, got %v", err)
+	}
+	if err := patch.Require("title", "points"); err != nil {
+		t.Errorf("expected the title to be kept, got %v", err)
+	}
+	if err := patch.Require("description"); err == nil {
+		t.Error("expected clearing the description to be refused")
+	}
+}
+
+func TestMergePatchApply(t *testing.T) {
+	assignee := primitive.NewObjectID()
+	due := time.Date(2025, 3, 4, 18, 0, 0, 0, time.UTC)
+	chore := models.Chore{Title: "Dishes", Description: "After dinner", AssignedTo: assignee, Points: 3, DueDate: due}
+
+	patch, err := models.ParseMergePatch([]byte(`{"points": 5, "description": null, "assigned_to": null}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := patch.Apply(&chore); err != nil {
+		t.Fatal(err)
+	}
+	want := models.Chore{Title: "Dishes", Points: 5, DueDate: due}
+	if !reflect.DeepEqual(chore, want) {
+		t.Errorf("got %+v, want %+v", chore, want)
+	}
+
+	// Nested objects are merged, so a patch changes one setting without resending the others
+	settings := models.GroupSettings{Scoring: models.ScoringRules{PointsPerCompletion: 2, OverduePenalty: 1}, Currency: "EUR"}
+	patch, _ = models.ParseMergePatch([]byte(`{"scoring": {"overdue_penalty": null}, "currency": null}`))
+	if err := patch.Apply(&settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings.Scoring.PointsPerCompletion != 2 || settings.Scoring.OverduePenalty != 0 || settings.Currency != "" {
+		t.Errorf("unexpected settings %+v", settings)
+	}
+
+	patch, _ = models.ParseMergePatch([]byte(`{"points": "many"}`))
+	if err := patch.Apply(&chore); err == nil {
+		t.Error("expected a value of the wrong type to be rejected")
+	}
+	if err := patch.Apply(chore); err == nil {
+		t.Error("expected a document that is not a pointer to be rejected")
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(x, y)
+}
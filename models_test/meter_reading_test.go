@@ -0,0 +1,117 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckMeterReading(t *testing.T) {
+	groupID, alice, bob := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	day := func(d int) time.Time { return time.Date(2025, time.March, d, 9, 0, 0, 0, time.UTC) }
+	readings := []models.MeterReading{
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 100, ReadAt: day(1)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 180, ReadAt: day(20)},
+	}
+
+	valid := []models.MeterReading{
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 150, ReadAt: day(10)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 180, ReadAt: day(25)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: bob, Value: 20, ReadAt: day(10)}, // Another room's meter
+		{GroupID: groupID, Meter: models.MeterWater, UserID: alice, Value: 5, ReadAt: day(1)},
+	}
+	for _, reading := range valid {
+		if err := models.CheckMeterReading(readings, reading); err != nil {
+			t.Errorf("Expected %+v to be accepted, got %v", reading, err)
+		}
+	}
+
+	invalid := []models.MeterReading{
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 90, ReadAt: day(10)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 200, ReadAt: day(10)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: alice, Value: 100, ReadAt: day(1)},
+		{GroupID: groupID, Meter: models.MeterElectricity, UserID: bob, Value: -1, ReadAt: day(10)},
+	}
+	for _, reading := range invalid {
+		if err := models.CheckMeterReading(readings, reading); err == nil {
+			t.Errorf("Expected %+v to be rejected", reading)
+		}
+	}
+}
+
+func TestMeterConsumption(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	readings := []models.MeterReading{
+		{UserID: alice, Value: 1300, ReadAt: day(4, 1)},
+		{UserID: alice, Value: 1000, ReadAt: day(2, 25)},
+		{UserID: alice, Value: 1100, ReadAt: day(3, 1)},
+		{UserID: alice, Value: 1500, ReadAt: day(4, 20)}, // After the period
+		{UserID: bob, Value: 50, ReadAt: day(3, 10)},     // Started reading mid-period
+		{UserID: bob, Value: 80, ReadAt: day(3, 30)},
+		{UserID: carol, Value: 10, ReadAt: day(2, 1)}, // Not read during the period
+		{Value: 9000, ReadAt: day(3, 1)},              // Main meter
+		{Value: 9400, ReadAt: day(4, 1)},
+	}
+
+	usages := models.MeterConsumption(readings, day(3, 1), day(4, 1))
+	byUser := make(map[primitive.ObjectID]models.MeterUsage)
+	for _, usage := range usages {
+		byUser[usage.UserID] = usage
+	}
+	if len(usages) != 3 {
+		t.Fatalf("Expected usage for alice, bob and the main meter, got %+v", usages)
+	}
+	if usage := byUser[alice]; usage.Usage != 200 || !usage.Complete || usage.StartValue != 1100 || usage.EndValue != 1300 {
+		t.Errorf("Expected alice to use 200 from 1100 to 1300, got %+v", usage)
+	}
+	if usage := byUser[bob]; usage.Usage != 30 || usage.Complete {
+		t.Errorf("Expected bob's 30 to be marked incomplete, got %+v", usage)
+	}
+	if usage := byUser[primitive.NilObjectID]; usage.Usage != 400 || !usage.Complete {
+		t.Errorf("Expected the main meter to count 400, got %+v", usage)
+	}
+	if _, ok := byUser[carol]; ok {
+		t.Error("Expected no usage for a meter that was not read during the period")
+	}
+}
+
+func TestRecurringBillApplyMeterUsage(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob}
+	usages := []models.MeterUsage{
+		{UserID: alice, Usage: 300, Complete: true},
+		{UserID: bob, Usage: 100, Complete: true},
+	}
+
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Electricity", 120, "monthly", time.Now())
+	if !bill.ApplyMeterUsage(members, usages) {
+		t.Fatal("Expected the bill to be split by usage")
+	}
+	splits, err := bill.Splits(members)
+	if err != nil {
+		t.Fatalf("Expected the usage split to be valid, got %v", err)
+	}
+	if splits[0].Amount != 90 || splits[1].Amount != 30 {
+		t.Errorf("Expected alice to pay 90 and bob 30, got %+v", splits)
+	}
+
+	fallback := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Electricity", 120, "monthly", time.Now())
+	incomplete := []models.MeterUsage{{UserID: alice, Usage: 300, Complete: true}, {UserID: bob, Usage: 100}}
+	unused := []models.MeterUsage{{UserID: alice, Complete: true}, {UserID: bob, Complete: true}}
+	for _, usages := range [][]models.MeterUsage{incomplete, unused, usages[:1]} {
+		if fallback.ApplyMeterUsage(members, usages) {
+			t.Errorf("Expected %+v to leave the bill's own split", usages)
+		}
+	}
+	if fallback.SplitMethod != models.SplitMethodEqual || len(fallback.Participants) != 0 {
+		t.Errorf("Expected the bill's split to be unchanged, got %+v", fallback)
+	}
+
+	from, to := bill.BillingPeriod(time.Date(2025, time.March, 5, 0, 0, 0, 0, time.UTC))
+	if !from.Equal(time.Date(2025, time.February, 5, 0, 0, 0, 0, time.UTC)) || to.Day() != 5 {
+		t.Errorf("Expected a monthly bill to pay for the month before its due date, got %v to %v", from, to)
+	}
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+)
+
+func TestNotificationPreferencesAllows(t *testing.T) {
+	var defaults models.NotificationPreferences
+	if !defaults.Allows(models.NotificationEventChoreAssigned, models.NotificationChannelPush) {
+		t.Error("expected chore assignments to be pushed by default")
+	}
+	if defaults.Allows(models.NotificationEventShopping, models.NotificationChannelEmail) {
+		t.Error("expected shopping activity not to be emailed by default")
+	}
+	if !defaults.Allows(models.NotificationEventShopping, models.NotificationChannelDigest) {
+		t.Error("expected shopping activity in the digest by default")
+	}
+	if !defaults.Allows("", models.NotificationChannelPush) || defaults.Allows("", models.NotificationChannelEmail) {
+		t.Error("expected notifications of no event to only be pushed")
+	}
+
+	preferences := models.NotificationPreferences{
+		Channels: map[models.NotificationEvent]models.ChannelSettings{
+			models.NotificationEventShopping: {models.NotificationChannelPush: false},
+		},
+	}
+	if preferences.Allows(models.NotificationEventShopping, models.NotificationChannelPush) {
+		t.Error("expected the member's setting to turn shopping pushes off")
+	}
+	if !preferences.Allows(models.NotificationEventShopping, models.NotificationChannelDigest) {
+		t.Error("expected channels the member did not set to keep their default")
+	}
+}
+
+func TestNotificationPreferencesLegacyMutes(t *testing.T) {
+	preferences := models.NotificationPreferences{MuteShoppingUpdates: true, MutePaymentReminders: true}
+	if preferences.Allows(models.NotificationEventShopping, models.NotificationChannelPush) {
+		t.Error("expected muted shopping updates not to be pushed")
+	}
+	if !preferences.Allows(models.NotificationEventShopping, models.NotificationChannelDigest) {
+		t.Error("expected muted shopping updates to stay in the digest")
+	}
+	if preferences.Allows(models.NotificationEventPayments, models.NotificationChannelPush) {
+		t.Error("expected muted payment reminders not to be pushed")
+	}
+
+	preferences.Channels = map[models.NotificationEvent]models.ChannelSettings{
+		models.NotificationEventShopping: {models.NotificationChannelPush: true},
+	}
+	if !preferences.Allows(models.NotificationEventShopping, models.NotificationChannelPush) {
+		t.Error("expected the member's setting to win over the older mute")
+	}
+}
+
+func TestNotificationTypeEvent(t *testing.T) {
+	cases := map[models.NotificationType]models.NotificationEvent{
+		models.NotificationTypeChoreAssigned:   models.NotificationEventChoreAssigned,
+		models.NotificationTypeItemPurchased:   models.NotificationEventShopping,
+		models.NotificationTypeExpiringSoon:    models.NotificationEventPantry,
+		models.NotificationTypeExpenseDisputed: models.NotificationEventExpenses,
+		models.NotificationTypeBillDue:         models.NotificationEventBills,
+	}
+	for notificationType, want := range cases {
+		if got := notificationType.Event(); got != want {
+			t.Errorf("%s.Event() = %q, want %q", notificationType, got, want)
+		}
+	}
+}
+
+func TestNotificationPreferencesValidate(t *testing.T) {
+	valid := models.NotificationPreferences{
+		Channels: map[models.NotificationEvent]models.ChannelSettings{
+			models.NotificationEventBills: {models.NotificationChannelSMS: false},
+		},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("expected known events and channels to be valid, got %v", err)
+	}
+
+	unknownEvent := models.NotificationPreferences{
+		Channels: map[models.NotificationEvent]models.ChannelSettings{"parties": {models.NotificationChannelPush: true}},
+	}
+	if err := unknownEvent.Validate(); err == nil {
+		t.Error("expected an unknown event to be rejected")
+	}
+	unknownChannel := models.NotificationPreferences{
+		Channels: map[models.NotificationEvent]models.ChannelSettings{models.NotificationEventBills: {"fax": true}},
+	}
+	if err := unknownChannel.Validate(); err == nil {
+		t.Error("expected an unknown channel to be rejected")
+	}
+}
+
+func TestChannelMatrix(t *testing.T) {
+	matrix := models.NotificationPreferences{}.ChannelMatrix()
+	if len(matrix) != len(models.NotificationEvents) {
+		t.Fatalf("expected every event in the matrix, got %d", len(matrix))
+	}
+	for _, settings := range matrix {
+		if len(settings) != len(models.NotificationChannels) {
+			t.Errorf("expected every channel for each event, got %v", settings)
+		}
+	}
+	if !matrix[models.NotificationEventBills][models.NotificationChannelSMS] {
+		t.Error("expected bills to be texted by default")
+	}
+}
@@ -0,0 +1,137 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseLocale(t *testing.T) {
+	cases := map[string]models.Locale{
+		"":      models.LocaleEnglish,
+		"en":    models.LocaleEnglish,
+		"es":    models.LocaleSpanish,
+		"es-MX": models.LocaleSpanish,
+		"ES_es": models.LocaleSpanish,
+		" en ":  models.LocaleEnglish,
+	}
+	for tag, want := range cases {
+		if got, ok := models.ParseLocale(tag); !ok || got != want {
+			t.Errorf("expected %q to parse as %s, got %q (%v)", tag, want, got, ok)
+		}
+	}
+	for _, tag := range []string{"fr", "klingon", "-"} {
+		if _, ok := models.ParseLocale(tag); ok {
+			t.Errorf("expected %q to be unsupported", tag)
+		}
+	}
+}
+
+func TestUserLocale(t *testing.T) {
+	if locale := (models.User{}).Locale(); locale != models.DefaultLocale {
+		t.Errorf("expected members without a preference to get %s, got %s", models.DefaultLocale, locale)
+	}
+	user := models.User{Preferences: models.UserPreferences{Locale: models.LocaleSpanish}}
+	if user.Locale() != models.LocaleSpanish {
+		t.Errorf("expected Spanish, got %s", user.Locale())
+	}
+	user.Preferences.Locale = "fr"
+	if user.Locale() != models.DefaultLocale {
+		t.Errorf("expected an unsupported locale to fall back to %s, got %s", models.DefaultLocale, user.Locale())
+	}
+}
+
+func TestChoreAssignedNotificationLocalized(t *testing.T) {
+	chore := &models.Chore{
+		AssignedTo: primitive.NewObjectID(),
+		GroupID:    primitive.NewObjectID(),
+		Title:      "Dishes",
+		DueDate:    time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC),
+	}
+
+	notification := models.ChoreAssignedNotification(chore)
+	if notification.Title != "New chore" || notification.Message != "Dishes is yours, due Fri Mar 7" {
+		t.Errorf("unexpected English copy %q: %q", notification.Title, notification.Message)
+	}
+
+	notification.Localize(models.LocaleSpanish)
+	if notification.Title != "Nueva tarea" || notification.Message != "Te toca Dishes, para el vie 7 mar" {
+		t.Errorf("unexpected Spanish copy %q: %q", notification.Title, notification.Message)
+	}
+
+	notification.Localize(models.LocaleEnglish)
+	if notification.Message != "Dishes is yours, due Fri Mar 7" {
+		t.Errorf("expected localizing back to English to restore the copy, got %q", notification.Message)
+	}
+
+	chore.DueDate = time.Time{}
+	if message := models.ChoreAssignedNotification(chore).Message; message != "Dishes is yours" {
+		t.Errorf("expected no due date, got %q", message)
+	}
+}
+
+func TestItemPurchasedNotificationLocalized(t *testing.T) {
+	buyer := models.User{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID(), Name: "Sam"}
+	alex := primitive.NewObjectID()
+	records := []*models.PurchaseRecord{
+		{ItemName: "Milk", RequestedBy: alex},
+		{ItemName: "Eggs", RequestedBy: alex},
+		{ItemName: "Bread", RequestedBy: alex},
+	}
+
+	notification := models.ItemPurchasedNotifications(records, buyer)[0]
+	if notification.Message != "Sam bought Milk, Eggs and Bread" {
+		t.Errorf("unexpected English copy %q", notification.Message)
+	}
+	notification.Localize(models.LocaleSpanish)
+	if notification.Title != "Compras hechas" || notification.Message != "Sam compró Milk, Eggs y Bread" {
+		t.Errorf("unexpected Spanish copy %q: %q", notification.Title, notification.Message)
+	}
+}
+
+func TestLocalizeUntemplatedNotification(t *testing.T) {
+	notification := models.CreateNotification(primitive.NewObjectID(), primitive.NewObjectID(), models.NotificationTypeLowStock, "Heads up", "Written by hand")
+	notification.Localize(models.LocaleSpanish)
+	if notification.Title != "Heads up" || notification.Message != "Written by hand" {
+		t.Errorf("expected copy without a template to be left alone, got %q: %q", notification.Title, notification.Message)
+	}
+}
+
+func TestRenderNotificationConditionals(t *testing.T) {
+	params := models.NotificationParams{"challenge": "Clean streak"}
+	if _, message := models.RenderNotification(models.TemplateChallengeCompleted, models.LocaleEnglish, params); message != `Your group completed "Clean streak"!` {
+		t.Errorf("unexpected message %q", message)
+	}
+	if _, message := models.RenderNotification(models.TemplateChallengeCompleted, models.LocaleSpanish, params); message != `¡Tu grupo completó "Clean streak"!` {
+		t.Errorf("unexpected message %q", message)
+	}
+	params["reward"] = "Pizza night"
+	if _, message := models.RenderNotification(models.TemplateChallengeCompleted, models.LocaleSpanish, params); message != `Tu grupo completó "Clean streak" y ganó: Pizza night` {
+		t.Errorf("unexpected message %q", message)
+	}
+
+	bill := models.NotificationParams{"bill": "Rent", "amount": "1200.00", "share": "400.00", "currency": "USD", "due": "2025-04-01", "paying": "true"}
+	if title, message := models.RenderNotification(models.TemplateBillDue, models.LocaleEnglish, bill); title != "Bill due soon" ||
+		message != "Rent of 1200.00 USD is due on Apr 1 and you are paying it. Your share is 400.00 USD" {
+		t.Errorf("unexpected bill reminder %q: %q", title, message)
+	}
+	if _, message := models.RenderNotification(models.TemplateBillDue, models.LocaleSpanish, bill); message != "Rent de 1200.00 USD vence el 1 abr y la pagas tú. Tu parte es 400.00 USD" {
+		t.Errorf("unexpected Spanish bill reminder %q", message)
+	}
+}
+
+func TestRenderNotificationFallbacks(t *testing.T) {
+	params := models.NotificationParams{"name": "Sam", "chore": "Dishes"}
+	title, message := models.RenderNotification(models.TemplateChoreCompleted, "fr", params)
+	if title != "Chore completed" || message != "Sam completed Dishes" {
+		t.Errorf("expected an unknown locale to fall back to English, got %q: %q", title, message)
+	}
+	if title, message := models.RenderNotification("no_such_template", models.LocaleEnglish, params); title != "" || message != "" {
+		t.Errorf("expected an unknown template to render nothing, got %q: %q", title, message)
+	}
+	if text := models.RenderText(models.TemplateSMSChoreOverdue, models.LocaleSpanish, models.NotificationParams{"chore": "Trash", "due": "2025-03-03"}); text != "Cribb: Trash vencía el lun 3 mar y sigue sin hacerse. Encárgate o avisa a tus compañeros." {
+		t.Errorf("unexpected SMS %q", text)
+	}
+}
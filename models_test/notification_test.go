@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChoreCompletedNotifications(t *testing.T) {
+	completer := models.User{ID: primitive.NewObjectID(), Name: "Sam"}
+	other := primitive.NewObjectID()
+	chore := models.Chore{GroupID: primitive.NewObjectID(), Title: "Dishes"}
+
+	notifications := models.ChoreCompletedNotifications(chore, []primitive.ObjectID{completer.ID, other}, completer)
+	if len(notifications) != 1 || notifications[0].UserID != other {
+		t.Fatalf("expected only the other member to be told, got %+v", notifications)
+	}
+	if notifications[0].Message != "Sam completed Dishes" || notifications[0].Type != models.NotificationTypeChoreCompleted {
+		t.Errorf("unexpected notification %+v", notifications[0])
+	}
+	if notifications[0].Read {
+		t.Error("expected new notifications to be unread")
+	}
+}
+
+func TestItemPurchasedNotifications(t *testing.T) {
+	buyer := models.User{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID(), Name: "Sam"}
+	alex := primitive.NewObjectID()
+	records := []*models.PurchaseRecord{
+		{ItemName: "Milk", RequestedBy: alex},
+		{ItemName: "Eggs", RequestedBy: buyer.ID},
+		{ItemName: "Bread", RequestedBy: alex},
+		{ItemName: "Rice"},
+	}
+
+	notifications := models.ItemPurchasedNotifications(records, buyer)
+	if len(notifications) != 1 || notifications[0].UserID != alex {
+		t.Fatalf("expected one notification for the member who asked, got %+v", notifications)
+	}
+	if notifications[0].Message != "Sam bought Milk and Bread" || notifications[0].GroupID != buyer.GroupID {
+		t.Errorf("unexpected notification %+v", notifications[0])
+	}
+}
+
+func TestExpenseAddedNotifications(t *testing.T) {
+	creator := primitive.NewObjectID()
+	alex := primitive.NewObjectID()
+	expense := models.Expense{
+		GroupID:     primitive.NewObjectID(),
+		CreatedBy:   creator,
+		Description: "Pizza",
+		Amount:      30,
+		Currency:    "USD",
+		Splits: []models.CostShare{
+			{UserID: creator, Amount: 15},
+			{UserID: alex, Amount: 15},
+			{UserID: primitive.NewObjectID(), Amount: 0},
+		},
+	}
+
+	notifications := models.ExpenseAddedNotifications(expense, "Sam")
+	if len(notifications) != 1 || notifications[0].UserID != alex {
+		t.Fatalf("expected only members with a share to be told, got %+v", notifications)
+	}
+	if want := `Sam added "Pizza" (30.00 USD). Your share is 15.00 USD`; notifications[0].Message != want {
+		t.Errorf("got message %q, want %q", notifications[0].Message, want)
+	}
+}
@@ -0,0 +1,127 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	cursor := models.PageCursor{Key: time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC), ID: primitive.NewObjectID()}
+	encoded := models.EncodePageCursor(cursor)
+	decoded, err := models.DecodePageCursor(encoded)
+	if err != nil || !decoded.Key.Equal(cursor.Key) || decoded.ID != cursor.ID {
+		t.Errorf("expected the cursor back, got %+v and %v", decoded, err)
+	}
+
+	for _, invalid := range []string{"", "not a cursor", models.EncodePageCursor(models.PageCursor{Key: cursor.Key})} {
+		if _, err := models.DecodePageCursor(invalid); err != models.ErrInvalidPageCursor {
+			t.Errorf("expected %q to be rejected, got %v", invalid, err)
+		}
+	}
+}
+
+func TestPageOrderSort(t *testing.T) {
+	if sort := (models.PageOrder{Field: "_id", Descending: true}).Sort(); !reflect.DeepEqual(sort, bson.D{{Key: "_id", Value: -1}}) {
+		t.Errorf("expected ID pages to sort by ID alone, got %v", sort)
+	}
+	if sort := (models.PageOrder{Field: "due_date"}).Sort(); !reflect.DeepEqual(sort, bson.D{{Key: "due_date", Value: 1}, {Key: "_id", Value: 1}}) {
+		t.Errorf("expected ties broken by ID, got %v", sort)
+	}
+}
+
+func TestPageOrderAfter(t *testing.T) {
+	id := primitive.NewObjectID()
+	key := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		order  models.PageOrder
+		cursor models.PageCursor
+		want   bson.M
+	}{
+		{
+			name:   "by ID",
+			order:  models.PageOrder{Field: "_id", Descending: true},
+			cursor: models.PageCursor{ID: id},
+			want:   bson.M{"_id": bson.M{"$lt": id}},
+		},
+		{
+			name:   "ascending",
+			order:  models.PageOrder{Field: "due_date"},
+			cursor: models.PageCursor{Key: key, ID: id},
+			want: bson.M{"$or": bson.A{
+				bson.M{"due_date": bson.M{"$gt": key}},
+				bson.M{"due_date": key, "_id": bson.M{"$gt": id}},
+			}},
+		},
+		{
+			name:   "ascending after an item without the key",
+			order:  models.PageOrder{Field: "due_date"},
+			cursor: models.PageCursor{ID: id},
+			want: bson.M{"$or": bson.A{
+				bson.M{"due_date": nil, "_id": bson.M{"$gt": id}},
+				bson.M{"due_date": bson.M{"$ne": nil}},
+			}},
+		},
+		{
+			name:   "descending keeps items without the key for last",
+			order:  models.PageOrder{Field: "created_at", Descending: true},
+			cursor: models.PageCursor{Key: key, ID: id},
+			want: bson.M{"$or": bson.A{
+				bson.M{"created_at": bson.M{"$lt": key}},
+				bson.M{"created_at": key, "_id": bson.M{"$lt": id}},
+				bson.M{"created_at": nil},
+			}},
+		},
+		{
+			name:   "descending after an item without the key",
+			order:  models.PageOrder{Field: "created_at", Descending: true},
+			cursor: models.PageCursor{ID: id},
+			want:   bson.M{"created_at": nil, "_id": bson.M{"$lt": id}},
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.order.After(tt.cursor); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPageLimit(t *testing.T) {
+	cases := []struct {
+		name         string
+		limit        string
+		hasCursor    bool
+		defaultLimit int
+		expected     int
+		wantErr      bool
+	}{
+		{"default", "", false, 20, 20, false},
+		{"default with a cursor", "", true, 20, 20, false},
+		{"unpaged without a limit or cursor", "", false, models.Unpaged, models.Unpaged, false},
+		{"unpaged with a cursor", "", true, models.Unpaged, models.DefaultPageSize, false},
+		{"unpaged with a limit", "10", false, models.Unpaged, 10, false},
+		{"lower bound", "1", false, 20, 1, false},
+		{"upper bound", "100", false, 20, 100, false},
+		{"zero", "0", false, 20, 0, true},
+		{"above the cap", "101", false, models.Unpaged, 0, true},
+		{"not a number", "all", false, 20, 0, true},
+	}
+
+	for _, tc := range cases {
+		limit, err := models.PageLimit(tc.limit, tc.hasCursor, tc.defaultLimit)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.wantErr, err)
+			continue
+		}
+		if limit != tc.expected {
+			t.Errorf("%s: expected limit %d, got %d", tc.name, tc.expected, limit)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAnalyticsMonths(t *testing.T) {
+	months := models.AnalyticsMonths(time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC), 3)
+	expected := []string{"2024-12", "2025-01", "2025-02"}
+	if len(months) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, months)
+	}
+	for i := range expected {
+		if months[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected, months)
+			break
+		}
+	}
+}
+
+func TestBuildPantryAnalytics(t *testing.T) {
+	dairy, produce := primitive.NewObjectID(), primitive.NewObjectID()
+	names := map[primitive.ObjectID]string{dairy: "Dairy", produce: "Produce"}
+	months := []string{"2025-01", "2025-02"}
+
+	analytics := models.BuildPantryAnalytics(
+		months,
+		names,
+		map[primitive.ObjectID]int{dairy: 4, produce: 2},
+		[]models.CategoryMonthTotals{
+			{CategoryID: produce, Month: "2025-01", Purchases: 3, Spend: 12.5},
+			{CategoryID: produce, Month: "2025-02", Purchases: 1, Spend: 4},
+			{CategoryID: dairy, Month: "2025-02", Purchases: 2, Spend: 6},
+			{CategoryID: primitive.NilObjectID, Month: "2025-02", Purchases: 1, Spend: 2},
+			{CategoryID: dairy, Month: "2024-12", Purchases: 9, Spend: 99},
+		},
+		[]models.CategoryMonthTotals{
+			{CategoryID: produce, Month: "2025-02", Discards: 2, WasteValue: 1.75},
+		},
+	)
+
+	if analytics.Spend != 24.5 || analytics.WasteValue != 1.75 {
+		t.Errorf("expected 24.5 spent and 1.75 wasted, got %v and %v", analytics.Spend, analytics.WasteValue)
+	}
+	if len(analytics.Categories) != 3 {
+		t.Fatalf("expected 3 categories, got %d", len(analytics.Categories))
+	}
+
+	produceTotals := analytics.Categories[0]
+	if produceTotals.Name != "Produce" || produceTotals.Spend != 16.5 || produceTotals.ItemCount != 2 || produceTotals.Discards != 2 {
+		t.Errorf("expected produce to have the highest spend, got %+v", produceTotals)
+	}
+	if len(produceTotals.Months) != 2 || produceTotals.Months[0].Spend != 12.5 || produceTotals.Months[1].WasteValue != 1.75 {
+		t.Errorf("unexpected monthly totals for produce: %+v", produceTotals.Months)
+	}
+
+	dairyTotals := analytics.Categories[1]
+	if dairyTotals.Spend != 6 || dairyTotals.Months[0].Purchases != 0 {
+		t.Errorf("expected purchases outside the range to be ignored, got %+v", dairyTotals)
+	}
+
+	if uncategorized := analytics.Categories[2]; uncategorized.CategoryID != nil || uncategorized.Name != models.UncategorizedName {
+		t.Errorf("expected uncategorized purchases last, got %+v", uncategorized)
+	}
+}
@@ -0,0 +1,27 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOrderCategories(t *testing.T) {
+	snacks := models.PantryCategory{ID: primitive.NewObjectID(), Name: "Snacks"}
+	baking := models.PantryCategory{ID: primitive.NewObjectID(), Name: "baking"}
+	spices := models.PantryCategory{ID: primitive.NewObjectID(), Name: "Spices"}
+	categories := []models.PantryCategory{snacks, baking, spices}
+
+	positions := models.OrderCategories(categories, []primitive.ObjectID{spices.ID, primitive.NewObjectID(), spices.ID})
+
+	expected := map[primitive.ObjectID]int{spices.ID: 1, baking.ID: 2, snacks.ID: 3}
+	if len(positions) != len(expected) {
+		t.Fatalf("expected %d positions, got %d", len(expected), len(positions))
+	}
+	for id, position := range expected {
+		if positions[id] != position {
+			t.Errorf("expected position %d for %s, got %d", position, id.Hex(), positions[id])
+		}
+	}
+}
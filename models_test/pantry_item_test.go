@@ -0,0 +1,127 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"math"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPantryItemDaysUntilExpiration(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		expires  time.Time
+		expected int
+	}{
+		{now.Add(2*24*time.Hour + time.Hour), 2},
+		{now.Add(time.Hour), 0},
+		{now.Add(-time.Hour), -1},
+		{now.Add(-48 * time.Hour), -2},
+	}
+	for _, c := range cases {
+		item := models.PantryItem{ExpirationDate: c.expires}
+		if days, ok := item.DaysUntilExpiration(now); !ok || days != c.expected {
+			t.Errorf("expires %v: expected %d days, got %d", c.expires, c.expected, days)
+		}
+	}
+
+	if _, ok := (&models.PantryItem{}).DaysUntilExpiration(now); ok {
+		t.Error("expected no expiration for an item without a date")
+	}
+}
+
+func TestExpirationAlertWindow(t *testing.T) {
+	if got := (models.GroupSettings{}).ExpirationAlertWindow(); got != models.DefaultExpirationAlertDays {
+		t.Errorf("expected default window %d, got %d", models.DefaultExpirationAlertDays, got)
+	}
+	if got := (models.GroupSettings{ExpirationAlertDays: 7}).ExpirationAlertWindow(); got != 7 {
+		t.Errorf("expected window 7, got %d", got)
+	}
+}
+
+func TestPantryItemMinimumQuantity(t *testing.T) {
+	item := models.PantryItem{Quantity: 0.5, Unit: "pcs"}
+	if item.IsBelowMinimum() {
+		t.Error("expected no minimum without min_quantity")
+	}
+	if item.LowStockThreshold() != models.DefaultLowStockThreshold {
+		t.Errorf("expected default threshold, got %v", item.LowStockThreshold())
+	}
+
+	item.MinQuantity = 3
+	if !item.IsBelowMinimum() {
+		t.Error("expected item to be below its minimum")
+	}
+	if got := item.RestockQuantity(); got != 3 {
+		t.Errorf("expected counted items to round up to 3, got %v", got)
+	}
+
+	item.Unit = "kg"
+	if got := item.RestockQuantity(); got != 2.5 {
+		t.Errorf("expected 2.5 kg, got %v", got)
+	}
+
+	item.Quantity = 3
+	if item.IsBelowMinimum() || item.RestockQuantity() != 0 {
+		t.Error("expected item at its minimum to need no restock")
+	}
+}
+
+func TestPantryItemOwnership(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	item := models.PantryItem{}
+	if item.IsPersonal() || !item.CanBeModifiedBy(bob) {
+		t.Error("expected items without a visibility to be shared")
+	}
+
+	item.SetOwner(&alice)
+	if !item.IsPersonal() || item.Visibility != models.VisibilityPersonal {
+		t.Error("expected item to be personal after setting an owner")
+	}
+	if !item.IsOwnedBy(alice) || !item.CanBeModifiedBy(alice) {
+		t.Error("expected the owner to be able to modify the item")
+	}
+	if item.IsOwnedBy(bob) || item.CanBeModifiedBy(bob) {
+		t.Error("expected other members to be unable to modify a personal item")
+	}
+
+	item.SetOwner(nil)
+	if item.IsPersonal() || item.OwnerID != nil || item.Visibility != models.VisibilityShared {
+		t.Errorf("expected item to be shared again, got %+v", item)
+	}
+}
+
+func TestSummarizePantryUsage(t *testing.T) {
+	coffee := primitive.NewObjectID()
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	usage := []models.PantryUsage{
+		{ItemID: coffee, ItemName: "Coffee", UserID: alice, UserName: "Alice", Quantity: 100, Unit: "g", Remaining: 400, UsedAt: now.Add(-3 * time.Hour)},
+		{ItemID: coffee, ItemName: "Coffee", UserID: bob, UserName: "Bob", Quantity: 0.3, Unit: "kg", Remaining: 100, UsedAt: now.Add(-2 * time.Hour)},
+		{ItemID: coffee, ItemName: "Coffee", UserID: alice, UserName: "Alice", Quantity: 100, Unit: "g", Remaining: 0, UsedAt: now.Add(-time.Hour)},
+	}
+
+	totals := models.SummarizePantryUsage(usage)
+	if len(totals) != 2 {
+		t.Fatalf("expected 2 totals, got %d", len(totals))
+	}
+	if totals[0].UserID != bob || math.Abs(totals[0].Quantity-300) > 1e-9 || totals[0].Unit != "g" {
+		t.Errorf("expected Bob first with 300 g, got %s with %v %s", totals[0].UserName, totals[0].Quantity, totals[0].Unit)
+	}
+	if totals[1].Quantity != 200 || totals[1].Uses != 2 || totals[1].TimesFinished != 1 {
+		t.Errorf("unexpected total for Alice: %+v", totals[1])
+	}
+
+	last := models.LastFinished(usage)
+	if last == nil || last.UserID != alice {
+		t.Errorf("expected Alice to have finished the coffee, got %+v", last)
+	}
+	if models.LastFinished(usage[:2]) != nil {
+		t.Error("expected no finisher while the item was never emptied")
+	}
+}
@@ -0,0 +1,67 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDiffPantryItems(t *testing.T) {
+	owner := primitive.NewObjectID()
+	before := models.PantryItem{Name: "Milk", Quantity: 2, Unit: "l", CategoryID: primitive.NewObjectID()}
+	after := before
+
+	if changes := models.DiffPantryItems(&before, &after); len(changes) != 0 {
+		t.Errorf("expected no changes, got %+v", changes)
+	}
+
+	after.Name = "Oat milk"
+	after.Quantity = 1
+	after.CategoryID = primitive.NewObjectID()
+	after.ExpirationDate = time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC)
+	after.SetOwner(&owner)
+
+	changes := models.DiffPantryItems(&before, &after)
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	expected := []string{"name", "quantity", "category_id", "expiration_date", "owner_id"}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected changes to %v, got %v", expected, fields)
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("expected change %d to be %s, got %s", i, expected[i], fields[i])
+		}
+	}
+	if changes[3].From != nil {
+		t.Errorf("expected an unset expiration date to diff as nil, got %v", changes[3].From)
+	}
+}
+
+func TestPantryItemVersionRestoreTo(t *testing.T) {
+	owner := primitive.NewObjectID()
+	photo := primitive.NewObjectID()
+	snapshot := models.PantryItem{Name: "Rice", Quantity: 2, Unit: "kg", Notes: "top shelf"}
+	version := models.CreatePantryItemVersion(&snapshot, 1, nil, models.ActionTypeAdd, owner, "Alice")
+	if version.Changes == nil {
+		t.Error("expected a version without changes to have an empty list")
+	}
+
+	item := models.PantryItem{ID: primitive.NewObjectID(), Name: "Rice (brown)", Quantity: 0.5, Unit: "kg", PhotoID: &photo}
+	item.SetOwner(&owner)
+	version.RestoreTo(&item)
+
+	if item.Name != "Rice" || item.Quantity != 2 || item.Notes != "top shelf" {
+		t.Errorf("expected edited fields to be restored, got %+v", item)
+	}
+	if item.IsPersonal() {
+		t.Error("expected the item to be shared again")
+	}
+	if item.PhotoID == nil || *item.PhotoID != photo {
+		t.Error("expected the photo to be kept")
+	}
+}
@@ -0,0 +1,47 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+)
+
+func TestSearchPantryItems(t *testing.T) {
+	items := []models.PantryItem{
+		{Name: "Tomatoes"},
+		{Name: "Tomato sauce", Notes: "red lid"},
+		{Name: "Pasta sauce", Notes: "blue lid, basil"},
+		{Name: "Potatoes"},
+	}
+
+	results := models.SearchPantryItems(items, "tomatos", 10)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 matches for a misspelled query, got %d", len(results))
+	}
+	if results[0].Name != "Tomatoes" {
+		t.Errorf("expected Tomatoes first, got %s", results[0].Name)
+	}
+
+	results = models.SearchPantryItems(items, "sauce basil", 10)
+	if len(results) != 1 || results[0].Name != "Pasta sauce" {
+		t.Errorf("expected only Pasta sauce to match name and notes words, got %+v", results)
+	}
+
+	if results := models.SearchPantryItems(items, "tom", 1); len(results) != 1 {
+		t.Errorf("expected the limit to apply, got %d results", len(results))
+	}
+	if results := models.SearchPantryItems(items, "xyz", 10); len(results) != 0 {
+		t.Errorf("expected no matches, got %d", len(results))
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	if score := models.FuzzyMatchScore("milk", "Milk"); score != 1 {
+		t.Errorf("expected an exact match to score 1, got %v", score)
+	}
+	if models.FuzzyMatchScore("chese", "Cheddar cheese") <= 0 {
+		t.Error("expected a one-letter typo to match")
+	}
+	if models.FuzzyMatchScore("ab", "abc") <= models.FuzzyMatchScore("ac", "abc") {
+		t.Error("expected a prefix to beat an unrelated short query")
+	}
+}
@@ -0,0 +1,54 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+)
+
+func TestPriceSummaryEstimateValue(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	summary := models.SummarizePrices([]models.PurchaseRecord{
+		{Price: 4, Quantity: 1, Unit: "kg", PurchasedAt: now.AddDate(0, -1, 0)},
+		{Price: 6, Quantity: 1, Unit: "kg", PurchasedAt: now},
+	})
+
+	if value, ok := summary.EstimateValue(250, "g"); !ok || value != 1.25 {
+		t.Errorf("expected 250 g to be worth 1.25, got %v (%v)", value, ok)
+	}
+	if _, ok := summary.EstimateValue(1, "l"); ok {
+		t.Error("expected no estimate for an incompatible unit")
+	}
+	if _, ok := models.SummarizePrices(nil).EstimateValue(1, "kg"); ok {
+		t.Error("expected no estimate without priced purchases")
+	}
+}
+
+func TestSummarizeWaste(t *testing.T) {
+	waste := []models.PantryWaste{
+		{ItemName: "Milk", Quantity: 1, Unit: "l", Reason: models.WasteReasonExpired, EstimatedValue: 1.2},
+		{ItemName: "Spinach", Quantity: 200, Unit: "g", Reason: models.WasteReasonSpoiled, EstimatedValue: 2.5},
+		{ItemName: "milk", Quantity: 500, Unit: "ml", Reason: models.WasteReasonExpired, EstimatedValue: 0.6},
+	}
+
+	report := models.SummarizeWaste("2025-03", waste)
+	if report.Discards != 3 || report.TotalValue != 4.3 {
+		t.Errorf("expected 3 discards worth 4.3, got %d worth %v", report.Discards, report.TotalValue)
+	}
+	if len(report.ByReason) != 2 || report.ByReason[0].Reason != models.WasteReasonExpired || report.ByReason[0].Discards != 2 {
+		t.Errorf("expected expired food to be the most common reason, got %+v", report.ByReason)
+	}
+	if len(report.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(report.Items))
+	}
+	if report.Items[0].ItemName != "Spinach" {
+		t.Errorf("expected the most valuable item first, got %s", report.Items[0].ItemName)
+	}
+	if milk := report.Items[1]; milk.Quantity != 1.5 || milk.Unit != "l" || milk.Discards != 2 || milk.Value != 1.8 {
+		t.Errorf("unexpected total for milk: %+v", milk)
+	}
+
+	if empty := models.SummarizeWaste("2025-04", nil); empty.Discards != 0 || empty.Items == nil {
+		t.Errorf("expected an empty report, got %+v", empty)
+	}
+}
@@ -0,0 +1,91 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPaymentReminderRulesDue(t *testing.T) {
+	now := time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC)
+	rules := models.PaymentReminderRules{}
+
+	if rules.Due(20, now.AddDate(0, 0, -3), models.PaymentReminder{}, now) {
+		t.Error("Expected a small recent debt not to be reminded")
+	}
+	if !rules.Due(60, now.AddDate(0, 0, -1), models.PaymentReminder{}, now) {
+		t.Error("Expected a debt over the default threshold to be reminded")
+	}
+	since := now.AddDate(0, 0, -models.DefaultPaymentReminderAfterDays)
+	if !rules.Due(5, since, models.PaymentReminder{}, now) {
+		t.Error("Expected an old debt to be reminded whatever its amount")
+	}
+
+	reminded := models.PaymentReminder{OwingSince: since, Count: 1, LastSentAt: now.AddDate(0, 0, -1)}
+	if rules.Due(5, since, reminded, now) {
+		t.Error("Expected no reminder before the interval has passed")
+	}
+	if !rules.Due(5, since, reminded, now.AddDate(0, 0, models.DefaultPaymentReminderInterval-1)) {
+		t.Error("Expected another reminder once the interval has passed")
+	}
+	if !rules.Due(60, now, reminded, now) {
+		t.Error("Expected a new debt to be reminded regardless of reminders about an earlier one")
+	}
+
+	if (models.PaymentReminderRules{Disabled: true}).Due(500, since, models.PaymentReminder{}, now) {
+		t.Error("Expected no reminders when they are disabled")
+	}
+	if (models.PaymentReminderRules{Threshold: 1000}).Due(500, now, models.PaymentReminder{}, now) {
+		t.Error("Expected the group's own threshold to apply")
+	}
+	if err := (models.PaymentReminderRules{IntervalDays: -1}).Validate(); err == nil {
+		t.Error("Expected a negative interval to be rejected")
+	}
+}
+
+func TestOwingSince(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob}
+	day := func(d int) time.Time { return time.Date(2025, 5, d, 0, 0, 0, 0, time.UTC) }
+
+	expenses := []models.Expense{
+		{PaidBy: alice, Amount: 100, CreatedAt: day(1), Splits: models.SplitEvenly(100, members)},
+		{PaidBy: alice, Amount: 40, CreatedAt: day(10), Splits: models.SplitEvenly(40, members)},
+		{PaidBy: bob, Amount: 30, CreatedAt: day(3), Splits: []models.CostShare{{UserID: bob, Amount: 30}}},
+	}
+	settlements := []models.Settlement{
+		{FromUserID: bob, ToUserID: alice, Amount: 50, CreatedAt: day(5)},
+	}
+
+	// Bob settled on the 5th, so his current debt dates from the expense on the 10th
+	since := models.OwingSince(expenses, settlements)
+	if !since[bob].Equal(day(10)) {
+		t.Errorf("Expected bob to owe since the 10th, got %v", since[bob])
+	}
+	if _, ok := since[alice]; ok {
+		t.Error("Expected alice, who is owed money, not to be owing")
+	}
+}
+
+func TestPaymentReminderMessage(t *testing.T) {
+	now := time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC)
+	debts := []models.Debt{{ToName: "Alice", Amount: 40}, {ToName: "Carol", Amount: 22.5}}
+
+	userID, groupID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	reminder := models.PaymentReminderNotification(userID, groupID, 62.5, "USD", debts, now.AddDate(0, 0, -10), 0, now)
+	if reminder.Title != "Time to settle up" || reminder.Message != "You owe your roommates 62.50 USD. Pay Alice 40.00 USD and Carol 22.50 USD to settle up." {
+		t.Errorf("Unexpected first reminder %q: %q", reminder.Title, reminder.Message)
+	}
+	if reminder.UserID != userID || reminder.GroupID != groupID || reminder.Type != models.NotificationTypePaymentReminder {
+		t.Errorf("Unexpected reminder %+v", reminder)
+	}
+
+	reminder = models.PaymentReminderNotification(userID, groupID, 62.5, "USD", debts, now.AddDate(0, 0, -10), models.PaymentReminderEscalation, now)
+	if reminder.Title != "Payment overdue" || !strings.Contains(reminder.Message, "reminder 4") || !strings.Contains(reminder.Message, "for 10 days") {
+		t.Errorf("Expected an escalated reminder, got %q: %q", reminder.Title, reminder.Message)
+	}
+}
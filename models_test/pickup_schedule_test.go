@@ -0,0 +1,77 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidatePickupSchedule(t *testing.T) {
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	thursday := time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)
+	if err := models.ValidatePickupSchedule(2, thursday, "19:00", "America/Chicago", now); err != nil {
+		t.Errorf("expected the schedule to be valid, got %v", err)
+	}
+	if models.ValidatePickupSchedule(3, thursday, "19:00", "", now) == nil {
+		t.Error("expected pickups every three weeks to be rejected")
+	}
+	if models.ValidatePickupSchedule(1, now, "19:00", "", now) == nil {
+		t.Error("expected a first pickup today to be rejected")
+	}
+	if models.ValidatePickupSchedule(1, thursday, "7pm", "", now) == nil {
+		t.Error("expected a malformed reminder time to be rejected")
+	}
+}
+
+func TestPickupScheduleReminders(t *testing.T) {
+	schedule := models.CreatePickupSchedule(primitive.NewObjectID(), primitive.NewObjectID(), models.PickupRecycling, 2,
+		time.Date(2025, 3, 13, 15, 0, 0, 0, time.UTC), "19:00", "America/Chicago")
+	chicago, _ := time.LoadLocation("America/Chicago")
+
+	if !schedule.NextPickup.Equal(time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)) || schedule.Frequency() != "biweekly" {
+		t.Errorf("unexpected schedule %+v", schedule)
+	}
+	if want := time.Date(2025, 3, 12, 19, 0, 0, 0, chicago); !schedule.NextReminderAt.Equal(want) {
+		t.Errorf("expected the reminder the evening before at %v, got %v", want, schedule.NextReminderAt)
+	}
+	if !schedule.RemindsInTime(time.Date(2025, 3, 12, 23, 0, 0, 0, chicago)) || schedule.RemindsInTime(time.Date(2025, 3, 13, 7, 0, 0, 0, chicago)) {
+		t.Error("expected reminders to be in time only before the pickup day")
+	}
+
+	// A server that was down for a month picks up at the next pickup still to come
+	schedule.Advance(time.Date(2025, 4, 9, 20, 0, 0, 0, chicago))
+	if !schedule.NextPickup.Equal(time.Date(2025, 4, 24, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the pickup of April 24, got %v", schedule.NextPickup)
+	}
+}
+
+func TestPickupRecurringChore(t *testing.T) {
+	schedule := models.CreatePickupSchedule(primitive.NewObjectID(), primitive.NewObjectID(), models.PickupTrash, 1,
+		time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC), models.DefaultPickupReminderTime, "")
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	recurring := schedule.RecurringChore([]primitive.ObjectID{sam, alex}, 2)
+	if recurring.Title != "Take out the trash" || recurring.Frequency != "weekly" || recurring.BlackoutPolicy != models.BlackoutPolicySkip {
+		t.Errorf("unexpected recurring chore %+v", recurring)
+	}
+
+	// The scheduler's first turn is due on the first pickup day
+	chore := models.CreateChoreFromRecurringAt(recurring, recurring.NextAssignment)
+	if chore.AssignedTo != sam || chore.DueDate.Format("2006-01-02") != "2025-03-13" {
+		t.Errorf("expected Sam's turn due on the first pickup, got %v due %v", chore.AssignedTo, chore.DueDate)
+	}
+
+	reminder := models.PickupReminderNotification(*schedule, *chore)
+	if reminder.UserID != sam || !strings.HasPrefix(reminder.Message, "Trash is collected Thu Mar 13") {
+		t.Errorf("unexpected reminder %q", reminder.Message)
+	}
+	if reminder.Type.Event() != models.NotificationEventChoreAssigned {
+		t.Error("expected pickup reminders to follow the chore assignment setting")
+	}
+	reminder.Localize(models.LocaleSpanish)
+	if !strings.HasPrefix(reminder.Message, "La basura se recoge") {
+		t.Errorf("unexpected Spanish reminder %q", reminder.Message)
+	}
+}
@@ -0,0 +1,43 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidatePost(t *testing.T) {
+	if err := models.ValidatePost("Room available for the summer", "June through August"); err != nil {
+		t.Errorf("expected the post to be valid, got %v", err)
+	}
+	if models.ValidatePost("   ", "No title") == nil {
+		t.Error("expected a blank title to be rejected")
+	}
+	if models.ValidatePost(strings.Repeat("a", models.MaxPostTitleLength+1), "") == nil {
+		t.Error("expected a long title to be rejected")
+	}
+	if models.ValidatePost("Quiet hours", strings.Repeat("ü", models.MaxPostBodyLength)) != nil {
+		t.Error("expected the body limit to count characters rather than bytes")
+	}
+	if models.IsValidPostCategory("announcement") || !models.IsValidPostCategory(models.PostSublet) {
+		t.Error("unexpected post category validation")
+	}
+}
+
+func TestCreatePost(t *testing.T) {
+	post := models.CreatePost(primitive.NewObjectID(), primitive.NewObjectID(), models.PostHouseRules, "  Dishes  ", " Wash them the same day. ")
+	if post.Title != "Dishes" || post.Body != "Wash them the same day." || post.EditedAt != nil {
+		t.Errorf("unexpected post %+v", post)
+	}
+
+	fileID := primitive.NewObjectID()
+	post.Attachments = append(post.Attachments, models.PostAttachment{FileID: fileID, Filename: "rules.pdf"})
+	if attachment, ok := post.Attachment(fileID); !ok || attachment.Filename != "rules.pdf" {
+		t.Errorf("expected to find the attachment, got %+v", attachment)
+	}
+	if _, ok := post.Attachment(primitive.NewObjectID()); ok {
+		t.Error("expected an unknown attachment not to be found")
+	}
+}
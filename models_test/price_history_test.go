@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+)
+
+func TestSummarizePricesTrend(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	purchases := []models.PurchaseRecord{
+		{Price: 3, Quantity: 1, Unit: "dozen", PurchasedAt: start},
+		{Price: 0, Quantity: 1, Unit: "dozen", PurchasedAt: start.AddDate(0, 1, 0)}, // Unpriced
+		{Price: 3.5, Quantity: 12, Unit: "", PurchasedAt: start.AddDate(0, 2, 0)},
+		{Price: 6, Quantity: 1, Unit: "dozen", PurchasedAt: start.AddDate(0, 3, 0)},
+		{Price: 5, Quantity: 1, Unit: "lb", PurchasedAt: start.AddDate(0, 4, 0)}, // Incompatible unit
+		{Price: 7, Quantity: 1, Unit: "dozen", PurchasedAt: start.AddDate(0, 5, 0)},
+	}
+
+	summary := models.SummarizePrices(purchases)
+	if summary.Unit != "dozen" {
+		t.Fatalf("expected prices per dozen, got %q", summary.Unit)
+	}
+	if len(summary.Points) != 4 {
+		t.Fatalf("expected 4 priced points, got %d", len(summary.Points))
+	}
+	if summary.Points[1].UnitPrice != 3.5 {
+		t.Errorf("expected 12 eggs to convert to a dozen, got %v", summary.Points[1].UnitPrice)
+	}
+	if summary.Average != 4.875 || summary.Min != 3 || summary.Max != 7 || summary.Latest != 7 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	// Older half averages 3.25 and newer half 6.5
+	if summary.Trend != models.PriceTrendRising || summary.ChangePercent != 100 {
+		t.Errorf("expected prices to have doubled, got %s %.1f%%", summary.Trend, summary.ChangePercent)
+	}
+}
+
+func TestSummarizePricesWithoutHistory(t *testing.T) {
+	summary := models.SummarizePrices(nil)
+	if summary.Trend != models.PriceTrendUnknown || len(summary.Points) != 0 {
+		t.Errorf("unexpected empty summary: %+v", summary)
+	}
+
+	single := models.SummarizePrices([]models.PurchaseRecord{{Price: 2, Quantity: 1}})
+	if single.Trend != models.PriceTrendUnknown || single.Average != 2 {
+		t.Errorf("unexpected single-purchase summary: %+v", single)
+	}
+}
@@ -0,0 +1,46 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+)
+
+func TestValidBarcode(t *testing.T) {
+	valid := []string{"4006381333931", "96385074", "036000291452"}
+	for _, barcode := range valid {
+		if !models.ValidBarcode(barcode) {
+			t.Errorf("Expected %s to be a valid barcode", barcode)
+		}
+	}
+
+	invalid := []string{"4006381333932", "12345", "40063813339a1", ""}
+	for _, barcode := range invalid {
+		if models.ValidBarcode(barcode) {
+			t.Errorf("Expected %s to be an invalid barcode", barcode)
+		}
+	}
+}
+
+func TestCategoryFromTags(t *testing.T) {
+	if got := models.CategoryFromTags([]string{"en:plant-based-foods", "en:dairies", "en:cheeses"}); got != "Dairy" {
+		t.Errorf("Expected Dairy, got %s", got)
+	}
+	if got := models.CategoryFromTags([]string{"en:frozen-foods", "en:pizzas"}); got != "Frozen Foods" {
+		t.Errorf("Expected Frozen Foods, got %s", got)
+	}
+	if got := models.CategoryFromTags(nil); got != "Other" {
+		t.Errorf("Expected Other without tags, got %s", got)
+	}
+}
+
+func TestProductPackageAmount(t *testing.T) {
+	coffee := models.Product{Name: "Coffee", Quantity: 250, Unit: "g"}
+	if quantity, unit := coffee.PackageAmount(2); quantity != 500 || unit != "g" {
+		t.Errorf("expected 500 g for two packages, got %v %q", quantity, unit)
+	}
+
+	soap := models.Product{Name: "Soap"}
+	if quantity, unit := soap.PackageAmount(3); quantity != 3 || unit != "" {
+		t.Errorf("expected 3 items without a package size, got %v %q", quantity, unit)
+	}
+}
@@ -0,0 +1,121 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCreatePurchaseRecord(t *testing.T) {
+	item := models.CreateShoppingCartItem(primitive.NewObjectID(), primitive.NewObjectID(), "Eggs", 1, "dozen", "Dairy")
+	item.ID = primitive.NewObjectID()
+	purchaser := primitive.NewObjectID()
+
+	record := models.CreatePurchaseRecord(*item, purchaser, "Sam", 4.5, []primitive.ObjectID{purchaser, item.UserID})
+	if record.ItemID != item.ID || record.RequestedBy != item.UserID || record.PurchasedBy != purchaser {
+		t.Error("Expected purchase record to keep the item, requester and purchaser")
+	}
+	if record.Unit != "dozen" || record.Price != 4.5 {
+		t.Errorf("Expected 1 dozen at 4.50, got %s at %.2f", record.Unit, record.Price)
+	}
+	if len(record.Shares) != 2 || record.Shares[0].Amount != 2.25 {
+		t.Errorf("Expected a shared item to be split evenly, got %+v", record.Shares)
+	}
+}
+
+func TestCreatePurchaseRecordPersonalItem(t *testing.T) {
+	requester := primitive.NewObjectID()
+	item := models.CreateShoppingCartItem(requester, primitive.NewObjectID(), "Protein powder", 1, "", "")
+	item.IsShared = false
+
+	record := models.CreatePurchaseRecord(*item, primitive.NewObjectID(), "Sam", 30, []primitive.ObjectID{requester, primitive.NewObjectID()})
+	if len(record.Shares) != 1 || record.Shares[0].UserID != requester || record.Shares[0].Amount != 30 {
+		t.Errorf("Expected the requester to owe the full price of a personal item, got %+v", record.Shares)
+	}
+}
+
+func TestSplitEvenlyDistributesCents(t *testing.T) {
+	members := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	shares := models.SplitEvenly(10, members)
+
+	expected := []float64{3.34, 3.33, 3.33}
+	for i, share := range shares {
+		if share.Amount != expected[i] {
+			t.Errorf("Share %d: expected %.2f, got %.2f", i, expected[i], share.Amount)
+		}
+	}
+
+	if len(models.SplitEvenly(10, nil)) != 0 {
+		t.Error("Expected no shares without members")
+	}
+}
+
+func TestMonthBounds(t *testing.T) {
+	start, end, err := models.MonthBounds("2025-12")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !start.Equal(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)) || !end.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected bounds %v - %v", start, end)
+	}
+
+	if _, _, err := models.MonthBounds("December"); err == nil {
+		t.Error("Expected an error for an invalid month")
+	}
+}
+
+func TestListFilter(t *testing.T) {
+	if models.ListFilter(primitive.NilObjectID) != nil {
+		t.Error("Expected the default list to match items without a list")
+	}
+	listID := primitive.NewObjectID()
+	if models.ListFilter(listID) != listID {
+		t.Error("Expected a named list to match its own ID")
+	}
+}
+
+func TestCreatePurchaseRecordPersonalItemSeveralRequesters(t *testing.T) {
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	item := models.CreateShoppingCartItem(first, primitive.NewObjectID(), "Shampoo", 1, "", "")
+	item.IsShared = false
+	item.RequestedBy = append(item.RequestedBy, second)
+
+	record := models.CreatePurchaseRecord(*item, primitive.NewObjectID(), "Sam", 9, nil)
+	if len(record.Shares) != 2 || record.Shares[0].UserID != first || record.Shares[1].UserID != second ||
+		record.Shares[0].Amount != 4.5 || record.Shares[1].Amount != 4.5 {
+		t.Errorf("Expected a personal item to be split between its requesters, got %+v", record.Shares)
+	}
+}
+
+func TestPurchaseRecordReprice(t *testing.T) {
+	alice, bob, sam := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	item := models.CreateShoppingCartItem(alice, primitive.NewObjectID(), "Olive oil", 1, "", "")
+
+	// Members sharing an item bought for free still split a corrected price
+	record := models.CreatePurchaseRecord(*item, sam, "Sam", 0, []primitive.ObjectID{alice, bob, sam})
+	record.Reprice(10)
+	if record.Price != 10 || len(record.Shares) != 3 {
+		t.Fatalf("Expected 10 split three ways, got %.2f split as %+v", record.Price, record.Shares)
+	}
+	if record.Shares[0].Amount != 3.34 || record.Shares[2].UserID != sam || record.Shares[2].Amount != 3.33 {
+		t.Errorf("Expected the same members to split the new price to the cent, got %+v", record.Shares)
+	}
+}
+
+func TestPurchasesTotal(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	purchases := []models.PurchaseRecord{
+		{Price: 4.5, Shares: models.SplitEvenly(4.5, []primitive.ObjectID{alice, bob})},
+		{Price: 30.1, Shares: []models.CostShare{{UserID: bob, Amount: 30.1}}},
+	}
+
+	total, shares := models.PurchasesTotal(purchases)
+	if total != 34.6 {
+		t.Errorf("Expected a total of 34.60, got %v", total)
+	}
+	if len(shares) != 2 || shares[0].UserID != alice || shares[0].Amount != 2.25 || shares[1].Amount != 32.35 {
+		t.Errorf("Expected the shares merged per member, got %+v", shares)
+	}
+}
@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+)
+
+func TestPushRetryDelay(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		6:  16 * time.Minute,
+		7:  30 * time.Minute,
+		20: 30 * time.Minute,
+	}
+	for attempts, want := range cases {
+		if got := models.PushRetryDelay(attempts); got != want {
+			t.Errorf("PushRetryDelay(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestIsValidQueuedPushStatus(t *testing.T) {
+	if !models.IsValidQueuedPushStatus(models.QueuedPushDead) || !models.IsValidQueuedPushStatus(models.QueuedPushPending) {
+		t.Error("expected dead and pending to be valid")
+	}
+	if models.IsValidQueuedPushStatus("failed") {
+		t.Error("expected unknown statuses to be rejected")
+	}
+}
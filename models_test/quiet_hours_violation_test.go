@@ -0,0 +1,65 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var houseQuietHours = models.QuietHours{Start: "22:00", End: "07:00", TimeZone: "UTC"}
+
+func TestValidateViolationTime(t *testing.T) {
+	now := time.Date(2025, 3, 8, 9, 0, 0, 0, time.UTC)
+	if err := models.ValidateViolationTime(houseQuietHours, now.Add(-8*time.Hour), now); err != nil {
+		t.Errorf("expected 01:00 to be within quiet hours, got %v", err)
+	}
+
+	rejected := map[string]struct {
+		quietHours models.QuietHours
+		occurredAt time.Time
+	}{
+		"no quiet hours": {models.QuietHours{}, now.Add(-8 * time.Hour)},
+		"outside":        {houseQuietHours, now.Add(-time.Hour)},
+		"future":         {houseQuietHours, now.Add(14 * time.Hour)},
+		"too old":        {houseQuietHours, now.Add(-8*time.Hour - models.MaxViolationBackdate)},
+	}
+	for name, violation := range rejected {
+		if models.ValidateViolationTime(violation.quietHours, violation.occurredAt, now) == nil {
+			t.Errorf("%s: expected the violation to be rejected", name)
+		}
+	}
+}
+
+func TestSummarizeQuietHoursViolations(t *testing.T) {
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	violation := func(reportedBy primitive.ObjectID, day, hour int) models.QuietHoursViolation {
+		return *models.CreateQuietHoursViolation(primitive.NewObjectID(), reportedBy, time.Date(2025, 3, day, hour, 15, 0, 0, time.UTC), "")
+	}
+	violations := []models.QuietHoursViolation{
+		violation(sam, 7, 23),  // Friday night
+		violation(alex, 8, 1),  // Still Friday night
+		violation(alex, 8, 23), // Saturday night
+		violation(alex, 12, 2), // Tuesday night
+	}
+
+	report := models.SummarizeQuietHoursViolations("2025-03", houseQuietHours, violations)
+	if report.Violations != 4 || report.Nights != 3 {
+		t.Errorf("expected 4 violations over 3 nights, got %d over %d", report.Violations, report.Nights)
+	}
+	if len(report.ByWeekday) != 7 || report.ByWeekday[0].Label != "Monday" || report.ByWeekday[4].Violations != 2 || report.ByWeekday[1].Violations != 1 {
+		t.Errorf("unexpected weekdays %+v", report.ByWeekday)
+	}
+	if len(report.ByHour) != 3 || report.ByHour[0].Label != "23:00" || report.ByHour[0].Violations != 2 || report.ByHour[2].Label != "02:00" {
+		t.Errorf("expected hours from the start of quiet hours, got %+v", report.ByHour)
+	}
+	if len(report.Reporters) != 2 || report.Reporters[0].UserID != alex || report.Reporters[0].Violations != 3 {
+		t.Errorf("unexpected reporters %+v", report.Reporters)
+	}
+
+	empty := models.SummarizeQuietHoursViolations("2025-04", houseQuietHours, nil)
+	if empty.Violations != 0 || empty.ByHour == nil || empty.Reporters == nil {
+		t.Errorf("expected an empty report with empty lists, got %+v", empty)
+	}
+}
@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateReactionEmoji(t *testing.T) {
+	for _, emoji := range []string{"👍", "❤️", "👏🏽", "🧑‍🍳", "🎉"} {
+		if err := models.ValidateReactionEmoji(emoji); err != nil {
+			t.Errorf("expected %q to be accepted, got %v", emoji, err)
+		}
+	}
+	for _, emoji := range []string{"", "ok", "great job", "+1", "👍👍👍👍👍👍👍👍👍"} {
+		if models.ValidateReactionEmoji(emoji) == nil {
+			t.Errorf("expected %q to be rejected", emoji)
+		}
+	}
+}
+
+func TestSummarizeReactions(t *testing.T) {
+	sam, alex, jo := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	post, other := primitive.NewObjectID(), primitive.NewObjectID()
+	start := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+	react := func(target, user primitive.ObjectID, emoji string, minutes int) models.Reaction {
+		return models.Reaction{TargetID: target, UserID: user, Emoji: emoji, CreatedAt: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+	reactions := []models.Reaction{
+		react(post, alex, "🎉", 1),
+		react(post, sam, "👍", 0),
+		react(post, jo, "🎉", 2),
+		react(other, jo, "👍", 3),
+	}
+
+	summaries := models.SummarizeReactions(reactions, sam)
+	counts := summaries[post]
+	if len(counts) != 2 || counts[0].Emoji != "🎉" || counts[0].Count != 2 || counts[0].Reacted {
+		t.Errorf("expected the party popper first with two reactions, got %+v", counts)
+	}
+	if counts[1].Emoji != "👍" || !counts[1].Reacted || len(counts[1].UserIDs) != 1 {
+		t.Errorf("expected Sam's thumbs up second, got %+v", counts[1])
+	}
+	if len(summaries[other]) != 1 || summaries[other][0].Reacted {
+		t.Errorf("unexpected reactions on the other post %+v", summaries[other])
+	}
+}
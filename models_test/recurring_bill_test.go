@@ -0,0 +1,91 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFirstBillDueDate(t *testing.T) {
+	now := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	if due := models.FirstBillDueDate(now, 15); !due.Equal(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected a later day this month, got %v", due)
+	}
+	if due := models.FirstBillDueDate(now, 10); !due.Equal(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected today, got %v", due)
+	}
+	if due := models.FirstBillDueDate(now, 1); !due.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first of next month, got %v", due)
+	}
+}
+
+func TestRecurringBillSplits(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Rent", 1200, "monthly", time.Now())
+
+	splits, err := bill.Splits([]primitive.ObjectID{alice, bob, carol})
+	if err != nil || len(splits) != 3 || splits[0].Amount != 400 {
+		t.Errorf("Expected the rent split evenly across the group, got %+v (%v)", splits, err)
+	}
+
+	bill.SplitMethod = models.SplitMethodPercentage
+	bill.Participants = []models.BillParticipant{{UserID: alice, Percentage: 50}, {UserID: bob, Percentage: 50}}
+	splits, err = bill.Splits([]primitive.ObjectID{alice, bob, carol})
+	if err != nil || len(splits) != 2 || splits[1].Amount != 600 {
+		t.Errorf("Expected only the participants to share the rent, got %+v (%v)", splits, err)
+	}
+
+	bill.SplitMethod = models.SplitMethodExact
+	bill.Participants = []models.BillParticipant{{UserID: alice, Amount: 700}, {UserID: bob, Amount: 400}}
+	if _, err := bill.Splits(nil); err == nil {
+		t.Error("Expected exact amounts that do not add up to be rejected")
+	}
+
+	bill.Participants = nil
+	if _, err := bill.Splits([]primitive.ObjectID{alice}); err == nil {
+		t.Error("Expected an exact split without participants to be rejected")
+	}
+}
+
+func TestRecurringBillExpenseFor(t *testing.T) {
+	alice := primitive.NewObjectID()
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Internet", 60, "monthly", time.Now())
+	bill.ID = primitive.NewObjectID()
+	due := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	expense := bill.ExpenseFor(due, []models.CostShare{{UserID: alice, Amount: 60}})
+	if expense.Source != models.ExpenseSourceRecurring || expense.RecurringBillID == nil || *expense.RecurringBillID != bill.ID {
+		t.Errorf("Expected a recurring expense linked to the bill, got %+v", expense)
+	}
+	if !expense.CreatedAt.Equal(due) || expense.PaidBy != alice || expense.Amount != 60 {
+		t.Errorf("Expected the expense dated on the due date, got %+v", expense)
+	}
+}
+
+func TestRecurringBillNeedsReminder(t *testing.T) {
+	due := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), primitive.NewObjectID(), "Rent", 1200, "monthly", due)
+
+	if bill.NeedsReminder(due.AddDate(0, 0, -4)) {
+		t.Error("Expected no reminder before the reminder window")
+	}
+	if !bill.NeedsReminder(due.AddDate(0, 0, -3)) {
+		t.Error("Expected a reminder inside the reminder window")
+	}
+	if bill.NeedsReminder(due) {
+		t.Error("Expected no reminder once the bill is due")
+	}
+
+	bill.RemindedFor = &due
+	if bill.NeedsReminder(due.AddDate(0, 0, -1)) {
+		t.Error("Expected a single reminder per due date")
+	}
+
+	bill.RemindedFor = nil
+	bill.ReminderDays = 0
+	if bill.NeedsReminder(due.AddDate(0, 0, -1)) {
+		t.Error("Expected no reminders when they are turned off")
+	}
+}
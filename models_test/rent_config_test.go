@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRentConfigComputeShares(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	bySize := models.RentConfig{Amount: 2000, Method: models.RentSplitRoomSize, Shares: []models.RentShare{
+		{UserID: alice, Room: "Master", RoomSize: 200},
+		{UserID: bob, RoomSize: 150},
+		{UserID: carol, RoomSize: 150},
+	}}
+	if err := bySize.ComputeShares(); err != nil {
+		t.Fatalf("Expected a valid room size split, got %v", err)
+	}
+	if bySize.Shares[0].Amount != 800 || bySize.Shares[1].Amount != 600 || bySize.Shares[2].Amount != 600 {
+		t.Errorf("Expected rent in proportion to room size, got %+v", bySize.Shares)
+	}
+
+	byPercentage := models.RentConfig{Amount: 1000, Method: models.RentSplitPercentage, Shares: []models.RentShare{
+		{UserID: alice, Percentage: 33.34},
+		{UserID: bob, Percentage: 33.33},
+		{UserID: carol, Percentage: 33.33},
+	}}
+	if err := byPercentage.ComputeShares(); err != nil {
+		t.Fatalf("Expected a valid percentage split, got %v", err)
+	}
+	total := 0.0
+	for _, share := range byPercentage.Shares {
+		total += share.Amount
+	}
+	if byPercentage.Shares[0].Amount != 333.4 || total < 999.995 || total > 1000.005 {
+		t.Errorf("Expected the shares to add up to the rent, got %+v", byPercentage.Shares)
+	}
+
+	invalid := []models.RentConfig{
+		{Amount: 0, Method: models.RentSplitRoomSize, Shares: []models.RentShare{{UserID: alice, RoomSize: 10}}},
+		{Amount: 1000, Method: "equal", Shares: []models.RentShare{{UserID: alice}}},
+		{Amount: 1000, Method: models.RentSplitRoomSize, Shares: []models.RentShare{{UserID: alice, RoomSize: 0}}},
+		{Amount: 1000, Method: models.RentSplitPercentage, Shares: []models.RentShare{{UserID: alice, Percentage: 60}, {UserID: bob, Percentage: 30}}},
+		{Amount: 1000, Method: models.RentSplitPercentage, Shares: []models.RentShare{{UserID: alice, Percentage: 50}, {UserID: alice, Percentage: 50}}},
+		{Amount: 1000, Method: models.RentSplitPercentage},
+	}
+	for _, config := range invalid {
+		if err := config.ComputeShares(); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
+
+func TestRentConfigAt(t *testing.T) {
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	configs := []models.RentConfig{
+		{Version: 1, Amount: 1800, EffectiveFrom: day(1, 1)},
+		{Version: 3, Amount: 2100, EffectiveFrom: day(6, 1)},
+		{Version: 2, Amount: 1900, EffectiveFrom: day(3, 1)},
+		{Version: 4, Amount: 1950, EffectiveFrom: day(3, 1)}, // Correction of version 2
+	}
+
+	if models.RentConfigAt(configs, day(0, 31)) != nil {
+		t.Error("Expected no rent before the first version takes effect")
+	}
+	if config := models.RentConfigAt(configs, day(2, 28)); config == nil || config.Version != 1 {
+		t.Errorf("Expected version 1 in February, got %+v", config)
+	}
+	if config := models.RentConfigAt(configs, day(3, 1)); config == nil || config.Version != 4 {
+		t.Errorf("Expected the correction to win on its effective date, got %+v", config)
+	}
+	if config := models.RentConfigAt(configs, day(7, 1)); config == nil || config.Amount != 2100 {
+		t.Errorf("Expected the June rent from then on, got %+v", config)
+	}
+}
+
+func TestRecurringBillApplyRentConfig(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+	config := models.RentConfig{Amount: 1000, Method: models.RentSplitRoomSize, Shares: []models.RentShare{
+		{UserID: alice, RoomSize: 3},
+		{UserID: bob, RoomSize: 1},
+	}}
+	if err := config.ComputeShares(); err != nil {
+		t.Fatal(err)
+	}
+
+	bill := models.CreateRecurringBill(primitive.NewObjectID(), alice, "Rent", 0, "monthly", time.Now())
+	bill.ApplyRentConfig(config)
+	splits, err := bill.Splits([]primitive.ObjectID{alice, bob})
+	if err != nil {
+		t.Fatalf("Expected the rent split to be valid, got %v", err)
+	}
+	if bill.Amount != 1000 || splits[0].Amount != 750 || splits[1].Amount != 250 {
+		t.Errorf("Expected alice to pay 750 and bob 250 of 1000, got %.2f split as %+v", bill.Amount, splits)
+	}
+}
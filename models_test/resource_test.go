@@ -0,0 +1,118 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func laundry() models.BookableResource {
+	return models.BookableResource{
+		ID:          primitive.NewObjectID(),
+		GroupID:     primitive.NewObjectID(),
+		Name:        "Washer",
+		Kind:        models.ResourceLaundry,
+		SlotMinutes: 60,
+		OpenFrom:    "08:00",
+		OpenUntil:   "22:00",
+		TimeZone:    "America/New_York",
+		SlotsAtOnce: 2,
+	}
+}
+
+func TestValidateBookableResource(t *testing.T) {
+	if err := laundry().Validate(); err != nil {
+		t.Errorf("expected the resource to be valid, got %v", err)
+	}
+
+	invalid := map[string]func(*models.BookableResource){
+		"kind":          func(r *models.BookableResource) { r.Kind = "garage" },
+		"short slots":   func(r *models.BookableResource) { r.SlotMinutes = 10 },
+		"uneven slots":  func(r *models.BookableResource) { r.SlotMinutes = 50 },
+		"slots at once": func(r *models.BookableResource) { r.SlotsAtOnce = models.MaxResourceSlotsAtOnce + 1 },
+		"time zone":     func(r *models.BookableResource) { r.TimeZone = "Mars/Olympus" },
+		"missing end":   func(r *models.BookableResource) { r.OpenUntil = "" },
+		"backwards":     func(r *models.BookableResource) { r.OpenFrom, r.OpenUntil = "22:00", "08:00" },
+	}
+	for name, change := range invalid {
+		resource := laundry()
+		change(&resource)
+		if resource.Validate() == nil {
+			t.Errorf("%s: expected the resource to be rejected", name)
+		}
+	}
+
+	alwaysOpen := laundry()
+	alwaysOpen.OpenFrom, alwaysOpen.OpenUntil = "", ""
+	if err := alwaysOpen.Validate(); err != nil {
+		t.Errorf("expected a resource without opening hours to be valid, got %v", err)
+	}
+}
+
+func TestBookingSlots(t *testing.T) {
+	resource := laundry()
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	slots, err := resource.BookingSlots(time.Date(2025, 3, 7, 20, 0, 0, 0, newYork), 2)
+	if err != nil {
+		t.Fatalf("expected the last two slots of the day to be bookable, got %v", err)
+	}
+	if len(slots) != 2 || !slots[1].Equal(time.Date(2025, 3, 7, 21, 0, 0, 0, newYork)) || slots[0].Location() != time.UTC {
+		t.Errorf("unexpected slots %v", slots)
+	}
+
+	rejected := map[string]struct {
+		start time.Time
+		count int
+	}{
+		"misaligned":   {time.Date(2025, 3, 7, 20, 30, 0, 0, newYork), 1},
+		"before hours": {time.Date(2025, 3, 7, 7, 0, 0, 0, newYork), 1},
+		"past closing": {time.Date(2025, 3, 7, 21, 0, 0, 0, newYork), 2},
+		"too many":     {time.Date(2025, 3, 7, 10, 0, 0, 0, newYork), 3},
+	}
+	for name, booking := range rejected {
+		if _, err := resource.BookingSlots(booking.start, booking.count); err == nil {
+			t.Errorf("%s: expected the booking to be rejected", name)
+		}
+	}
+
+	// Slots line up with midnight in the resource's time zone, not UTC
+	if _, err := resource.BookingSlots(time.Date(2025, 3, 7, 14, 0, 0, 0, time.UTC), 1); err != nil {
+		t.Errorf("expected 9:00 New York time to be bookable, got %v", err)
+	}
+}
+
+func TestCreateResourceBooking(t *testing.T) {
+	resource := laundry()
+	slots, _ := resource.BookingSlots(time.Date(2025, 3, 7, 13, 0, 0, 0, time.UTC), 2)
+	booking := models.CreateResourceBooking(resource, primitive.NewObjectID(), slots, "  darks ")
+	if !booking.StartsAt.Equal(slots[0]) || booking.EndsAt.Sub(booking.StartsAt) != 2*time.Hour || booking.Note != "darks" {
+		t.Errorf("unexpected booking %+v", booking)
+	}
+	if booking.ResourceID != resource.ID || booking.GroupID != resource.GroupID {
+		t.Error("expected the booking to belong to the resource's group")
+	}
+}
+
+func TestBookingCancelledNotification(t *testing.T) {
+	resource := laundry()
+	slots, _ := resource.BookingSlots(time.Date(2025, 3, 7, 13, 0, 0, 0, time.UTC), 1)
+	booking := models.CreateResourceBooking(resource, primitive.NewObjectID(), slots, "")
+
+	cancelled := models.BookingCancelledNotification(*booking, resource, "Sam")
+	if !strings.HasPrefix(cancelled.Message, "Sam cancelled your Washer booking on Fri Mar 7 at 08:00") {
+		t.Errorf("unexpected message %q", cancelled.Message)
+	}
+	if cancelled.Type.Event() != models.NotificationEventBookings {
+		t.Error("expected booking notifications to follow the bookings setting")
+	}
+
+	removed := models.BookingCancelledNotification(*booking, resource, "")
+	removed.Localize(models.LocaleSpanish)
+	if !strings.HasPrefix(removed.Message, "Se eliminó Washer") {
+		t.Errorf("unexpected Spanish message %q", removed.Message)
+	}
+}
@@ -0,0 +1,132 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRewardRedeem(t *testing.T) {
+	groupID := primitive.NewObjectID()
+	reward := models.CreateReward(groupID, primitive.NewObjectID(), "Skip a chore", "", 8)
+	reward.ID = primitive.NewObjectID()
+	user := models.User{ID: primitive.NewObjectID(), GroupID: groupID, Score: 10}
+
+	redemption, event, err := reward.Redeem(&user)
+	if err != nil {
+		t.Fatalf("Expected redemption to succeed, got %v", err)
+	}
+	if user.Score != 2 {
+		t.Errorf("Expected score 2 after redeeming, got %d", user.Score)
+	}
+	if redemption.Status != models.RedemptionStatusPending || redemption.Cost != 8 || redemption.UserID != user.ID || redemption.RewardID != reward.ID {
+		t.Errorf("Unexpected redemption %+v", redemption)
+	}
+	if event.Type != models.ScoreEventRedemption || event.Delta != -8 || event.ScoreAfter != 2 || event.GroupID != groupID {
+		t.Errorf("Unexpected score event %+v", event)
+	}
+
+	// The remaining points do not cover a second redemption
+	redemption, event, err = reward.Redeem(&user)
+	if !errors.Is(err, models.ErrInsufficientPoints) {
+		t.Errorf("Expected ErrInsufficientPoints, got %v", err)
+	}
+	if redemption != nil || event != nil || user.Score != 2 {
+		t.Errorf("Expected nothing to change on insufficient points, score %d", user.Score)
+	}
+
+	// Exactly the cost is enough
+	user.Score = 8
+	if _, _, err := reward.Redeem(&user); err != nil || user.Score != 0 {
+		t.Errorf("Expected a score equal to the cost to be enough, got %v with score %d", err, user.Score)
+	}
+}
+
+func TestRedemptionReview(t *testing.T) {
+	admin := primitive.NewObjectID()
+	member := primitive.NewObjectID()
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		reviewer    primitive.ObjectID
+		memberCount int
+		approve     bool
+		wantStatus  models.RedemptionStatus
+		wantErr     error
+	}{
+		{"approve", admin, 2, true, models.RedemptionStatusApproved, nil},
+		{"reject", admin, 2, false, models.RedemptionStatusRejected, nil},
+		{"own redemption in a shared group", member, 2, true, models.RedemptionStatusPending, models.ErrRedemptionSelfReview},
+		{"own redemption as the only member", member, 1, true, models.RedemptionStatusApproved, nil},
+	}
+
+	for _, tt := range tests {
+		redemption := models.Redemption{UserID: member, Cost: 5, Status: models.RedemptionStatusPending}
+		err := redemption.Review(tt.reviewer, tt.memberCount, tt.approve, "note", now)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		if redemption.Status != tt.wantStatus {
+			t.Errorf("%s: expected status %s, got %s", tt.name, tt.wantStatus, redemption.Status)
+		}
+		if err == nil && (redemption.ReviewedBy == nil || *redemption.ReviewedBy != tt.reviewer || !redemption.ReviewedAt.Equal(now)) {
+			t.Errorf("%s: expected the review to be recorded, got %+v", tt.name, redemption)
+		}
+	}
+
+	// A reviewed redemption cannot be reviewed again
+	redemption := models.Redemption{UserID: member, Status: models.RedemptionStatusRejected}
+	if err := redemption.Review(admin, 2, true, "", now); !errors.Is(err, models.ErrRedemptionNotPending) {
+		t.Errorf("Expected ErrRedemptionNotPending, got %v", err)
+	}
+	if redemption.Status != models.RedemptionStatusRejected {
+		t.Errorf("Expected status to stay rejected, got %s", redemption.Status)
+	}
+}
+
+func TestRedemptionRefund(t *testing.T) {
+	user := models.User{ID: primitive.NewObjectID(), Score: 2}
+	redemption := models.Redemption{
+		ID:         primitive.NewObjectID(),
+		RewardName: "Skip a chore",
+		GroupID:    primitive.NewObjectID(),
+		UserID:     user.ID,
+		Cost:       8,
+		Status:     models.RedemptionStatusPending,
+	}
+
+	// Pending and approved redemptions keep the points
+	if event := redemption.Refund(&user); event != nil || user.Score != 2 {
+		t.Errorf("Expected no refund for a pending redemption, got %+v", event)
+	}
+	redemption.Status = models.RedemptionStatusApproved
+	if event := redemption.Refund(&user); event != nil || user.Score != 2 {
+		t.Errorf("Expected no refund for an approved redemption, got %+v", event)
+	}
+
+	redemption.Status = models.RedemptionStatusRejected
+	event := redemption.Refund(&user)
+	if event == nil {
+		t.Fatal("Expected a refund for a rejected redemption")
+	}
+	if user.Score != 10 {
+		t.Errorf("Expected score 10 after the refund, got %d", user.Score)
+	}
+	if event.Type != models.ScoreEventRefund || event.Delta != 8 || event.ScoreAfter != 10 ||
+		event.UserID != user.ID || event.GroupID != redemption.GroupID {
+		t.Errorf("Unexpected refund event %+v", event)
+	}
+	if event.ReferenceID == nil || *event.ReferenceID != redemption.ID {
+		t.Errorf("Expected the refund event to refer to the redemption")
+	}
+
+	// Points are only refunded to the member who redeemed
+	other := models.User{ID: primitive.NewObjectID(), Score: 0}
+	if event := redemption.Refund(&other); event != nil || other.Score != 0 {
+		t.Errorf("Expected no refund to another member, got %+v", event)
+	}
+}
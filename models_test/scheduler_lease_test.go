@@ -0,0 +1,102 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// fakeClock is a clock tests move forward by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestSchedulerLeaseAcquire(t *testing.T) {
+	const duration = 30 * time.Second
+	clock := &fakeClock{now: time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)}
+	lease := models.SchedulerLease{ID: "scheduler"}
+
+	// Nobody holds a new lease
+	if !lease.Acquire("a", clock.Now(), duration) {
+		t.Fatal("Expected the first instance to acquire a new lease")
+	}
+	if !lease.HeldBy("a", clock.Now()) || lease.HeldBy("b", clock.Now()) {
+		t.Errorf("Expected the lease to be held by a only, got %+v", lease)
+	}
+
+	// Another instance cannot take a live lease
+	clock.Advance(10 * time.Second)
+	if lease.Acquire("b", clock.Now(), duration) {
+		t.Error("Expected a live lease to be refused to another instance")
+	}
+
+	// The holder renews it from the time of the heartbeat
+	if !lease.Acquire("a", clock.Now(), duration) {
+		t.Fatal("Expected the holder to renew its lease")
+	}
+	if want := clock.Now().Add(duration); !lease.ExpiresAt.Equal(want) {
+		t.Errorf("Expected renewed lease to expire at %v, got %v", want, lease.ExpiresAt)
+	}
+
+	// Renewed leases outlive the original expiry
+	clock.Advance(25 * time.Second)
+	if !lease.HeldBy("a", clock.Now()) {
+		t.Error("Expected the renewed lease to still be held")
+	}
+
+	// Without heartbeats the lease lapses, even in the middle of a job
+	clock.Advance(5 * time.Second)
+	if lease.HeldBy("a", clock.Now()) {
+		t.Error("Expected the lease to lapse at its expiry")
+	}
+
+	// It is still stored until another instance claims it after expiry
+	if lease.Acquire("b", clock.Now(), duration) {
+		t.Error("Expected a lease at exactly its expiry to be refused")
+	}
+	clock.Advance(time.Millisecond)
+	if !lease.Acquire("b", clock.Now(), duration) {
+		t.Fatal("Expected another instance to take over an expired lease")
+	}
+	if !lease.HeldBy("b", clock.Now()) || lease.HeldBy("a", clock.Now()) {
+		t.Errorf("Expected the lease to move to b, got %+v", lease)
+	}
+
+	// The previous holder has to wait for the new lease to expire
+	if lease.Acquire("a", clock.Now(), duration) {
+		t.Error("Expected the previous holder to be refused a taken-over lease")
+	}
+}
+
+func TestSchedulerLeaseAcquireFilter(t *testing.T) {
+	now := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	lease := models.SchedulerLease{ID: "scheduler"}
+
+	filter := lease.AcquireFilter("a", now)
+	if filter["_id"] != "scheduler" {
+		t.Errorf("Expected the filter to match the scheduler lease, got %v", filter["_id"])
+	}
+
+	// The filter only allows the holder or an expired lease, like CanAcquire
+	conditions, ok := filter["$or"].([]bson.M)
+	if !ok {
+		t.Fatalf("Expected $or conditions, got %T", filter["$or"])
+	}
+	if len(conditions) != 2 || conditions[0]["holder"] != "a" {
+		t.Errorf("Unexpected conditions %v", conditions)
+	}
+	expiry, ok := conditions[1]["expires_at"].(bson.M)
+	if !ok || !expiry["$lt"].(time.Time).Equal(now) {
+		t.Errorf("Expected expired leases to match, got %v", conditions[1])
+	}
+}
@@ -0,0 +1,113 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func pollSlots(now time.Time, days ...int) []models.PollSlot {
+	slots := make([]models.PollSlot, 0, len(days))
+	for _, day := range days {
+		start := now.AddDate(0, 0, day)
+		slots = append(slots, models.PollSlot{StartsAt: start, EndsAt: start.Add(2 * time.Hour)})
+	}
+	return slots
+}
+
+func TestValidatePollSlots(t *testing.T) {
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	closesAt := now.Add(24 * time.Hour)
+	if err := models.ValidatePollSlots(pollSlots(now, 3, 2), closesAt, now); err != nil {
+		t.Errorf("expected the slots to be valid, got %v", err)
+	}
+
+	rejected := map[string]struct {
+		slots    []models.PollSlot
+		closesAt time.Time
+	}{
+		"one slot":        {pollSlots(now, 2), closesAt},
+		"closed already":  {pollSlots(now, 2, 3), now},
+		"before closing":  {pollSlots(now, 2, 3), now.AddDate(0, 0, 2).Add(time.Hour)},
+		"same start":      {pollSlots(now, 2, 2), closesAt},
+		"ends before":     {[]models.PollSlot{{StartsAt: now.AddDate(0, 0, 2), EndsAt: now.AddDate(0, 0, 2)}, pollSlots(now, 3)[0]}, closesAt},
+		"too long a slot": {[]models.PollSlot{{StartsAt: now.AddDate(0, 0, 2), EndsAt: now.AddDate(0, 0, 4)}, pollSlots(now, 3)[0]}, closesAt},
+	}
+	for name, poll := range rejected {
+		if models.ValidatePollSlots(poll.slots, poll.closesAt, now) == nil {
+			t.Errorf("%s: expected the poll to be rejected", name)
+		}
+	}
+}
+
+func TestSchedulingPollVotingAndDecision(t *testing.T) {
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	sam, alex, jo := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	poll := models.CreateSchedulingPoll(primitive.NewObjectID(), sam, "Deep clean", "", "", pollSlots(now, 4, 2, 3), now.Add(time.Hour))
+	if !poll.Slots[0].StartsAt.Before(poll.Slots[1].StartsAt) || poll.Slots[0].ID.IsZero() {
+		t.Fatalf("expected slots sorted earliest first with IDs, got %+v", poll.Slots)
+	}
+	early, middle, late := poll.Slots[0].ID, poll.Slots[1].ID, poll.Slots[2].ID
+
+	if poll.SetVote(sam, []primitive.ObjectID{primitive.NewObjectID()}, now) == nil {
+		t.Error("expected votes for slots outside the poll to be rejected")
+	}
+	poll.SetVote(sam, []primitive.ObjectID{early, late, late}, now)
+	poll.SetVote(alex, []primitive.ObjectID{middle, late}, now)
+	poll.SetVote(sam, []primitive.ObjectID{middle, late}, now) // Sam changes their mind
+	if len(poll.Votes) != 2 || len(poll.Available(late)) != 2 || len(poll.Available(early)) != 0 {
+		t.Errorf("unexpected votes %+v", poll.Votes)
+	}
+	if poll.AllVoted([]primitive.ObjectID{sam, alex, jo}) {
+		t.Error("expected Jo not to have voted")
+	}
+	poll.SetVote(jo, nil, now)
+	if !poll.AllVoted([]primitive.ObjectID{sam, alex, jo}) {
+		t.Error("expected everyone to have voted")
+	}
+
+	// Middle and late tie, so the earlier wins
+	poll.Decide(now)
+	if slot, ok := poll.WinningSlot(); poll.Status != models.PollDecided || !ok || slot.ID != middle {
+		t.Errorf("expected the middle slot to win, got %v in %s", poll.WinningSlotID, poll.Status)
+	}
+
+	empty := models.CreateSchedulingPoll(primitive.NewObjectID(), sam, "House meeting", "", "", pollSlots(now, 2, 3), now.Add(time.Hour))
+	empty.SetVote(sam, nil, now)
+	empty.Decide(now)
+	if _, ok := empty.WinningSlot(); empty.Status != models.PollClosed || ok || empty.DecidedAt == nil {
+		t.Errorf("expected the poll to close without a winner, got %+v", empty)
+	}
+}
+
+func TestPollNotifications(t *testing.T) {
+	now := time.Date(2025, 3, 7, 10, 0, 0, 0, time.UTC)
+	sam := primitive.NewObjectID()
+	poll := models.CreateSchedulingPoll(primitive.NewObjectID(), sam, "the deep clean", "", "Europe/Madrid", pollSlots(now, 2, 3), now.Add(24*time.Hour))
+
+	created := models.PollCreatedNotification(poll, primitive.NewObjectID(), "Sam")
+	if !strings.Contains(created.Message, "which of the 2 times") || !strings.HasSuffix(created.Message, "at 11:00") {
+		t.Errorf("unexpected message %q", created.Message)
+	}
+	if created.Type.Event() != models.NotificationEventPolls {
+		t.Error("expected poll notifications to follow the polls setting")
+	}
+
+	poll.SetVote(sam, []primitive.ObjectID{poll.Slots[1].ID}, now)
+	poll.Decide(now)
+	decided := models.PollDecidedNotification(poll, sam)
+	if !strings.HasPrefix(decided.Message, "the deep clean is on Mon Mar 10 at 11:00, when 1 member can") {
+		t.Errorf("unexpected message %q", decided.Message)
+	}
+
+	poll.SetVote(sam, nil, now)
+	poll.Decide(now)
+	none := models.PollDecidedNotification(poll, sam)
+	none.Localize(models.LocaleSpanish)
+	if !strings.HasPrefix(none.Message, "Nadie podía") {
+		t.Errorf("unexpected Spanish message %q", none.Message)
+	}
+}
@@ -0,0 +1,160 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDecayAmount(t *testing.T) {
+	cases := []struct {
+		score, percent, expected int
+	}{
+		{100, 5, 5},
+		{10, 5, 1}, // Always at least one point
+		{0, 5, 0},
+		{100, 0, 0},
+		{3, 100, 3},
+	}
+	for _, c := range cases {
+		if got := models.DecayAmount(c.score, c.percent); got != c.expected {
+			t.Errorf("DecayAmount(%d, %d) = %d, expected %d", c.score, c.percent, got, c.expected)
+		}
+	}
+}
+
+func TestShouldDecay(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	if models.ShouldDecay(now.AddDate(0, 0, -6), time.Time{}, now, 1) {
+		t.Error("Expected no decay within the first inactive week")
+	}
+	if !models.ShouldDecay(now.AddDate(0, 0, -8), time.Time{}, now, 1) {
+		t.Error("Expected decay after a full inactive week")
+	}
+	if models.ShouldDecay(now.AddDate(0, 0, -8), time.Time{}, now, 2) {
+		t.Error("Expected no decay before the configured inactive weeks")
+	}
+	if models.ShouldDecay(now.AddDate(0, 0, -20), now.AddDate(0, 0, -3), now, 1) {
+		t.Error("Expected at most one decay per week")
+	}
+	if !models.ShouldDecay(now.AddDate(0, 0, -20), now.AddDate(0, 0, -7), now, 1) {
+		t.Error("Expected another decay a week after the last one")
+	}
+}
+
+func TestFillScoreBucketsCarriesScoreForward(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	buckets := []models.ScoreBucket{
+		{Start: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Delta: 5, Score: 15, Events: 1},
+	}
+
+	filled := models.FillScoreBuckets(buckets, models.ScoreGranularityDay, from, to, 10)
+	if len(filled) != 4 {
+		t.Fatalf("Expected 4 daily buckets, got %d", len(filled))
+	}
+
+	expected := []int{10, 15, 15, 15}
+	for i, b := range filled {
+		if b.Score != expected[i] {
+			t.Errorf("Bucket %d: expected score %d, got %d", i, expected[i], b.Score)
+		}
+	}
+	if filled[1].Delta != 5 || filled[2].Delta != 0 {
+		t.Errorf("Expected deltas only on days with changes, got %d and %d", filled[1].Delta, filled[2].Delta)
+	}
+}
+
+func TestFillScoreBucketsWeekly(t *testing.T) {
+	// Wednesday to the following Wednesday spans two Monday-based weeks
+	from := time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	filled := models.FillScoreBuckets(nil, models.ScoreGranularityWeek, from, to, 7)
+	if len(filled) != 2 {
+		t.Fatalf("Expected 2 weekly buckets, got %d", len(filled))
+	}
+	if filled[0].Start.Weekday() != time.Monday {
+		t.Errorf("Expected weekly buckets to start on Monday, got %v", filled[0].Start.Weekday())
+	}
+}
+
+func TestParseScoreHistoryQuery(t *testing.T) {
+	cases := []struct {
+		name, limit, eventType string
+		wantLimit              int64
+		wantErr                bool
+	}{
+		{"defaults", "", "", models.DefaultScoreHistoryLimit, false},
+		{"lower bound", "1", "", 1, false},
+		{"upper bound", "500", "", 500, false},
+		{"zero", "0", "", 0, true},
+		{"negative", "-5", "", 0, true},
+		{"above the cap", "501", "", 0, true},
+		{"not a number", "ten", "", 0, true},
+		{"known type", "20", "completion", 20, false},
+		{"kudos type", "", "kudos", models.DefaultScoreHistoryLimit, false},
+		{"unknown type", "", "lottery", 0, true},
+	}
+
+	for _, tc := range cases {
+		query, err := models.ParseScoreHistoryQuery(tc.limit, tc.eventType)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tc.name, tc.wantErr, err)
+			continue
+		}
+		if err == nil && query.Limit != tc.wantLimit {
+			t.Errorf("%s: expected limit %d, got %d", tc.name, tc.wantLimit, query.Limit)
+		}
+	}
+}
+
+func TestScoreHistoryQueryFilter(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	query, _ := models.ParseScoreHistoryQuery("", "")
+	filter := query.Filter(userID)
+	if filter["user_id"] != userID {
+		t.Errorf("Expected the filter to match the user, got %v", filter["user_id"])
+	}
+	if _, ok := filter["type"]; ok {
+		t.Errorf("Expected no type filter without a type, got %v", filter["type"])
+	}
+
+	query, _ = models.ParseScoreHistoryQuery("", "penalty")
+	filter = query.Filter(userID)
+	if filter["type"] != models.ScoreEventPenalty {
+		t.Errorf("Expected the filter to match penalties only, got %v", filter["type"])
+	}
+}
+
+func TestCanViewScoreHistory(t *testing.T) {
+	groupID := primitive.NewObjectID()
+	requester := models.User{ID: primitive.NewObjectID(), GroupID: groupID}
+	roommate := models.User{ID: primitive.NewObjectID(), GroupID: groupID}
+	stranger := models.User{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID()}
+	loner := models.User{ID: primitive.NewObjectID()}
+	otherLoner := models.User{ID: primitive.NewObjectID()}
+
+	cases := []struct {
+		name              string
+		requester, target models.User
+		expected          bool
+	}{
+		{"own history", requester, requester, true},
+		{"same group", requester, roommate, true},
+		{"other group", requester, stranger, false},
+		{"own history without a group", loner, loner, true},
+		{"both without a group", loner, otherLoner, false},
+		{"group member viewing user without a group", requester, loner, false},
+	}
+
+	for _, tc := range cases {
+		if got := models.CanViewScoreHistory(&tc.requester, &tc.target); got != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
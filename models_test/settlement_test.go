@@ -0,0 +1,185 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestComputeBalancesWithSettlements(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob, carol}
+
+	expenses := []models.Expense{
+		{PaidBy: alice, Amount: 30, Splits: models.SplitEvenly(30, members)},
+	}
+	settlements := []models.Settlement{
+		{FromUserID: bob, ToUserID: alice, Amount: 10},
+		{FromUserID: carol, ToUserID: alice, Amount: 4},
+	}
+
+	balances := models.ComputeBalances(expenses, settlements, members)
+	if balances[0].Received != 14 || balances[0].Net != 6 {
+		t.Errorf("Expected alice to be owed 6 after repayments, got %+v", balances[0])
+	}
+	if balances[1].Sent != 10 || balances[1].Net != 0 {
+		t.Errorf("Expected bob to be settled, got %+v", balances[1])
+	}
+	if balances[2].Net != -6 {
+		t.Errorf("Expected carol to still owe 6, got %+v", balances[2])
+	}
+}
+
+func TestSimplifyDebts(t *testing.T) {
+	alice, bob, carol, dave := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	balances := []models.MemberBalance{
+		{UserID: alice, Name: "Alice", Net: 50},
+		{UserID: bob, Name: "Bob", Net: -30},
+		{UserID: carol, Name: "Carol", Net: -20.01},
+		{UserID: dave, Name: "Dave", Net: 0.01},
+	}
+
+	debts := models.SimplifyDebts(balances)
+	if len(debts) != 3 {
+		t.Fatalf("Expected 3 debts, got %+v", debts)
+	}
+	if debts[0].From != bob || debts[0].To != alice || debts[0].Amount != 30 || debts[0].ToName != "Alice" {
+		t.Errorf("Expected bob to pay alice 30 first, got %+v", debts[0])
+	}
+	if debts[1].From != carol || debts[1].To != alice || debts[1].Amount != 20 {
+		t.Errorf("Expected carol to pay alice 20, got %+v", debts[1])
+	}
+	if debts[2].From != carol || debts[2].To != dave || debts[2].Amount != 0.01 {
+		t.Errorf("Expected carol to pay dave the last cent, got %+v", debts[2])
+	}
+
+	if debts := models.SimplifyDebts(nil); len(debts) != 0 {
+		t.Errorf("Expected no debts for no balances, got %+v", debts)
+	}
+}
+
+func TestSimplifyDebtsSettlesCirclesSeparately(t *testing.T) {
+	alice, bob, carol, dave, erin := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	balances := []models.MemberBalance{
+		{UserID: alice, Net: 6},
+		{UserID: bob, Net: 7},
+		{UserID: carol, Net: 4},
+		{UserID: dave, Net: -10},
+		{UserID: erin, Net: -7},
+	}
+
+	// Always paying the largest creditor takes 4 payments here; Erin and Bob can settle on their own
+	debts := models.SimplifyDebts(balances)
+	if len(debts) != 3 {
+		t.Fatalf("Expected 3 payments, got %+v", debts)
+	}
+	expected := []models.Debt{
+		{From: dave, To: alice, Amount: 6},
+		{From: dave, To: carol, Amount: 4},
+		{From: erin, To: bob, Amount: 7},
+	}
+	for i, debt := range expected {
+		if debts[i].From != debt.From || debts[i].To != debt.To || debts[i].Amount != debt.Amount {
+			t.Errorf("Expected payment %d to be %+v, got %+v", i, debt, debts[i])
+		}
+	}
+}
+
+func TestPairwiseDebts(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{alice, bob, carol}
+	expenses := []models.Expense{
+		{ID: primitive.NewObjectID(), Description: "Pizza", PaidBy: alice, Amount: 30, Splits: models.SplitEvenly(30, members)},
+		{ID: primitive.NewObjectID(), Description: "Internet", PaidBy: bob, Amount: 60, Splits: models.SplitEvenly(60, members)},
+		{ID: primitive.NewObjectID(), Description: "Cab", PaidBy: carol, Amount: 3, Splits: models.SplitEvenly(3, []primitive.ObjectID{bob, carol})},
+	}
+	settlements := []models.Settlement{
+		{ID: primitive.NewObjectID(), FromUserID: carol, ToUserID: bob, Amount: 5, Note: "Partly"},
+	}
+
+	debts := models.PairwiseDebts(expenses, settlements)
+	if len(debts) != 3 {
+		t.Fatalf("Expected 3 direct debts, got %+v", debts)
+	}
+	find := func(from, to primitive.ObjectID) *models.Debt {
+		for i := range debts {
+			if debts[i].From == from && debts[i].To == to {
+				return &debts[i]
+			}
+		}
+		t.Fatalf("Expected a debt from %s to %s in %+v", from.Hex(), to.Hex(), debts)
+		return nil
+	}
+
+	// Alice owes Bob 20 for the internet, less the 10 Bob owes her for pizza
+	aliceBob := find(alice, bob)
+	if aliceBob.Amount != 10 || len(aliceBob.Sources) != 2 {
+		t.Fatalf("Expected alice to owe bob 10 from two expenses, got %+v", aliceBob)
+	}
+	if aliceBob.Sources[0].Description != "Pizza" || aliceBob.Sources[0].Amount != -10 || aliceBob.Sources[1].Amount != 20 {
+		t.Errorf("Expected pizza to reduce the debt and the internet to add to it, got %+v", aliceBob.Sources)
+	}
+
+	if carolAlice := find(carol, alice); carolAlice.Amount != 10 {
+		t.Errorf("Expected carol to owe alice 10, got %+v", carolAlice)
+	}
+
+	carolBob := find(carol, bob)
+	if carolBob.Amount != 13.5 || len(carolBob.Sources) != 3 {
+		t.Fatalf("Expected carol to owe bob 13.50 after the cab and her repayment, got %+v", carolBob)
+	}
+	if repayment := carolBob.Sources[2]; repayment.Type != "settlement" || repayment.SettlementID == nil || repayment.Amount != -5 {
+		t.Errorf("Expected the repayment to reduce the debt, got %+v", repayment)
+	}
+
+	// The three direct debts settle with one payment
+	balances := models.ComputeBalances(expenses, settlements, members)
+	if simplified := models.SimplifyDebts(balances); len(simplified) != 1 || simplified[0].From != carol || simplified[0].To != bob || simplified[0].Amount != 23.5 {
+		t.Errorf("Expected carol to pay bob 23.50, got %+v", simplified)
+	}
+}
+
+func TestSettlementRecordBalance(t *testing.T) {
+	partial := models.CreateSettlement(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), 50, primitive.NewObjectID())
+	partial.RecordBalance(120)
+	if partial.OwedBefore != 120 || partial.Remaining != 70 || !partial.IsPartial() {
+		t.Errorf("Expected 70 of 120 to remain after paying 50, got %+v", partial)
+	}
+
+	full := models.CreateSettlement(primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), 33.33, primitive.NewObjectID())
+	full.RecordBalance(33.330000001)
+	if full.Remaining != 0 || full.IsPartial() {
+		t.Errorf("Expected paying the whole balance to settle it, got %+v", full)
+	}
+}
+
+func TestBuildPaymentHistory(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	day := func(d int) time.Time { return time.Date(2025, 5, d, 0, 0, 0, 0, time.UTC) }
+	expenses := []models.Expense{
+		{PaidBy: alice, Amount: 240, Splits: models.SplitEvenly(240, []primitive.ObjectID{alice, bob})},
+	}
+	settlements := []models.Settlement{
+		{FromUserID: bob, ToUserID: alice, Amount: 50, CreatedAt: day(1)},
+		{FromUserID: carol, ToUserID: alice, Amount: 10, CreatedAt: day(2)},
+		{FromUserID: bob, ToUserID: alice, Amount: 30, CreatedAt: day(3)},
+		{FromUserID: alice, ToUserID: bob, Amount: 5, CreatedAt: day(4)},
+	}
+
+	history := models.BuildPaymentHistory(bob, alice, expenses, settlements)
+	if len(history.Settlements) != 3 || !history.Settlements[0].CreatedAt.Equal(day(4)) {
+		t.Fatalf("Expected the 3 repayments between bob and alice, newest first, got %+v", history.Settlements)
+	}
+	if history.Paid != 80 || history.Received != 5 {
+		t.Errorf("Expected bob to have paid 80 and received 5, got %v and %v", history.Paid, history.Received)
+	}
+	if history.Remaining != 45 {
+		t.Errorf("Expected bob to still owe alice 45, got %v", history.Remaining)
+	}
+
+	if reverse := models.BuildPaymentHistory(alice, bob, expenses, settlements); reverse.Remaining != -45 || reverse.Paid != 5 {
+		t.Errorf("Expected the history from alice's side to mirror bob's, got %+v", reverse)
+	}
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSortByAisle(t *testing.T) {
+	produce, dairy, frozen := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	items := []models.ShoppingCartItem{
+		{ItemName: "Ice cream", Category: "Frozen Foods", CategoryID: &frozen},
+		{ItemName: "Batteries"},
+		{ItemName: "Yogurt", Category: "Dairy", CategoryID: &dairy},
+		{ItemName: "Bread", Category: "Bakery"},
+		{ItemName: "milk", Category: "Dairy", CategoryID: &dairy},
+		{ItemName: "Bananas", Category: "Fruits", CategoryID: &produce},
+	}
+
+	// Frozen is not in the aisle order, so it sorts by name with the other unordered categories
+	models.SortByAisle(items, []primitive.ObjectID{produce, dairy})
+
+	expected := []string{"Bananas", "milk", "Yogurt", "Bread", "Ice cream", "Batteries"}
+	for i, name := range expected {
+		if items[i].ItemName != name {
+			t.Fatalf("position %d: expected %s, got %s", i, name, items[i].ItemName)
+		}
+	}
+
+	if items[5].CategoryKey() != "" || items[1].CategoryKey() != dairy.Hex() || items[3].CategoryKey() != "bakery" {
+		t.Error("unexpected category keys")
+	}
+}
+
+func TestNormalizeItemName(t *testing.T) {
+	if got := models.NormalizeItemName("  Whole   MILK "); got != "whole milk" {
+		t.Errorf("expected \"whole milk\", got %q", got)
+	}
+
+	item := models.CreateShoppingCartItem(primitive.NewObjectID(), primitive.NewObjectID(), "Oat Milk", 1, "", "")
+	if item.NormalizedName != "oat milk" || len(item.RequestedBy) != 1 || item.RequestedBy[0] != item.UserID {
+		t.Errorf("unexpected new item: %+v", item)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	alice, bob, carol := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	keeper := models.ShoppingCartItem{ID: primitive.NewObjectID(), UserID: alice, Quantity: 1, Unit: "lb"}
+	duplicates := []models.ShoppingCartItem{
+		{ID: primitive.NewObjectID(), UserID: bob, Quantity: 16, Unit: "oz"},
+		{ID: primitive.NewObjectID(), UserID: carol, Quantity: 1, Unit: "l"},
+		{ID: primitive.NewObjectID(), UserID: alice, RequestedBy: []primitive.ObjectID{alice}, Quantity: 1, Unit: "lb"},
+	}
+
+	merged := models.MergeDuplicates(&keeper, duplicates)
+	if len(merged) != 2 || merged[0] != duplicates[0].ID || merged[1] != duplicates[2].ID {
+		t.Fatalf("expected the convertible duplicates to merge, got %v", merged)
+	}
+	if keeper.Quantity < 2.999 || keeper.Quantity > 3.001 {
+		t.Errorf("expected 3 lb, got %v", keeper.Quantity)
+	}
+	if len(keeper.RequestedBy) != 2 || keeper.RequestedBy[0] != alice || keeper.RequestedBy[1] != bob {
+		t.Errorf("expected alice and bob as requesters, got %v", keeper.RequestedBy)
+	}
+}
+
+func TestMergeDuplicatesKeepsSharedAndPersonalApart(t *testing.T) {
+	alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+	shared := models.ShoppingCartItem{ID: primitive.NewObjectID(), UserID: alice, ItemName: "Milk", Quantity: 1, IsShared: true}
+	personal := models.ShoppingCartItem{ID: primitive.NewObjectID(), UserID: bob, ItemName: "milk", Quantity: 2, IsShared: false}
+
+	merged := models.MergeDuplicates(&shared, []models.ShoppingCartItem{personal})
+	if len(merged) != 0 {
+		t.Errorf("expected a personal item not to merge into a shared one, got %v", merged)
+	}
+	if shared.Quantity != 1 || !shared.IsShared {
+		t.Errorf("expected the shared item to be left alone, got %+v", shared)
+	}
+	if len(shared.RequestedBy) != 1 || shared.RequestedBy[0] != alice {
+		t.Errorf("expected alice as the only requester, got %v", shared.RequestedBy)
+	}
+}
+
+func TestDuplicateFilter(t *testing.T) {
+	groupID, alice, bob := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+
+	shared := models.CreateShoppingCartItem(alice, groupID, "Milk", 1, "", "")
+	personal := models.CreateShoppingCartItem(bob, groupID, "  milk ", 1, "", "")
+	personal.IsShared = false
+
+	sharedFilter, personalFilter := shared.DuplicateFilter(), personal.DuplicateFilter()
+	if sharedFilter["normalized_name"] != "milk" || personalFilter["normalized_name"] != "milk" {
+		t.Errorf("expected both filters to match the normalized name, got %v and %v", sharedFilter, personalFilter)
+	}
+	if sharedFilter["is_shared"] != true || personalFilter["is_shared"] != false {
+		t.Errorf("expected each filter to match only items with the same sharing, got %v and %v", sharedFilter, personalFilter)
+	}
+	if sharedFilter["list_id"] != nil || sharedFilter["group_id"] != groupID {
+		t.Errorf("expected the default list of the group, got %v", sharedFilter)
+	}
+	if _, ok := sharedFilter["user_id"]; ok {
+		t.Errorf("expected items to merge whichever member added them, got %v", sharedFilter)
+	}
+}
@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestRenderShoppingList(t *testing.T) {
+	items := []models.ShoppingCartItem{
+		{ItemName: "Milk", Quantity: 1, Unit: "gal", Category: "Dairy"},
+		{ItemName: "Batteries", Quantity: 4},
+		{ItemName: "Apples", Quantity: 2.5, Unit: "lb", Category: "Fruits"},
+		{ItemName: "Cheese", Quantity: 1, Category: "Dairy"},
+	}
+
+	text := models.RenderShoppingList("Home shopping list", items, models.ShoppingListFormatText)
+	expected := "Home shopping list\n\nDairy:\n- Cheese (1)\n- Milk (1 gal)\n\nFruits:\n- Apples (2.5 lb)\n\nOther:\n- Batteries (4)\n"
+	if text != expected {
+		t.Errorf("unexpected text rendering:\n%s", text)
+	}
+
+	markdown := models.RenderShoppingList("Home shopping list", items[:1], models.ShoppingListFormatMarkdown)
+	if markdown != "# Home shopping list\n\n## Dairy\n- [ ] Milk (1 gal)\n" {
+		t.Errorf("unexpected markdown rendering:\n%s", markdown)
+	}
+
+	if empty := models.RenderShoppingList("Empty", nil, models.ShoppingListFormatText); empty != "Empty\n\nNothing to buy.\n" {
+		t.Errorf("unexpected empty rendering: %q", empty)
+	}
+}
+
+func TestShoppingListShare(t *testing.T) {
+	share, err := models.CreateShoppingListShare(primitive.NewObjectID(), primitive.NilObjectID, primitive.NewObjectID(), time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, _ := models.CreateShoppingListShare(primitive.NewObjectID(), primitive.NilObjectID, primitive.NewObjectID(), time.Hour)
+	if len(share.Token) != 48 || share.Token == other.Token {
+		t.Errorf("expected distinct 48-character tokens, got %q and %q", share.Token, other.Token)
+	}
+
+	if share.IsExpired(time.Now()) {
+		t.Error("expected new share to be valid")
+	}
+	if !share.IsExpired(share.ExpiresAt) {
+		t.Error("expected share to expire at its expiry time")
+	}
+}
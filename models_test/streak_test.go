@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestClassifyPeriod(t *testing.T) {
+	due := time.Date(2025, 4, 2, 23, 59, 0, 0, time.UTC)
+	chore1 := models.Chore{ID: primitive.NewObjectID(), DueDate: due}
+	chore2 := models.Chore{ID: primitive.NewObjectID(), DueDate: due}
+
+	if outcome := models.ClassifyPeriod(nil, nil); outcome != models.PeriodNoChores {
+		t.Errorf("Expected no chores outcome, got %v", outcome)
+	}
+
+	onTime := map[primitive.ObjectID]time.Time{
+		chore1.ID: due.Add(-time.Hour),
+		chore2.ID: due,
+	}
+	if outcome := models.ClassifyPeriod([]models.Chore{chore1, chore2}, onTime); outcome != models.PeriodAllOnTime {
+		t.Errorf("Expected all on time outcome, got %v", outcome)
+	}
+
+	late := map[primitive.ObjectID]time.Time{
+		chore1.ID: due.Add(-time.Hour),
+		chore2.ID: due.Add(time.Minute),
+	}
+	if outcome := models.ClassifyPeriod([]models.Chore{chore1, chore2}, late); outcome != models.PeriodMissed {
+		t.Errorf("Expected missed outcome for late completion, got %v", outcome)
+	}
+
+	if outcome := models.ClassifyPeriod([]models.Chore{chore1}, nil); outcome != models.PeriodMissed {
+		t.Errorf("Expected missed outcome for incomplete chore, got %v", outcome)
+	}
+}
+
+func TestStreakStats(t *testing.T) {
+	var streak models.StreakStats
+
+	streak.ApplyDaily(models.PeriodAllOnTime)
+	streak.ApplyDaily(models.PeriodAllOnTime)
+	streak.ApplyDaily(models.PeriodNoChores)
+	streak.ApplyDaily(models.PeriodAllOnTime)
+	if streak.Daily != 3 || streak.BestDaily != 3 {
+		t.Errorf("Expected daily streak 3 and best 3, got %d and %d", streak.Daily, streak.BestDaily)
+	}
+
+	streak.ApplyDaily(models.PeriodMissed)
+	streak.ApplyDaily(models.PeriodAllOnTime)
+	if streak.Daily != 1 || streak.BestDaily != 3 {
+		t.Errorf("Expected daily streak 1 and best 3, got %d and %d", streak.Daily, streak.BestDaily)
+	}
+
+	streak.ApplyWeekly(models.PeriodAllOnTime)
+	if streak.Weekly != 1 || streak.BestWeekly != 1 {
+		t.Errorf("Expected weekly streak 1 and best 1, got %d and %d", streak.Weekly, streak.BestWeekly)
+	}
+}
+
+func TestWeekStartUTC(t *testing.T) {
+	sunday := time.Date(2025, 4, 6, 18, 0, 0, 0, time.UTC)
+	expected := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	if start := models.WeekStartUTC(sunday); !start.Equal(expected) {
+		t.Errorf("Expected week start %v, got %v", expected, start)
+	}
+}
@@ -0,0 +1,129 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"math"
+	"testing"
+)
+
+func TestNormalizeUnit(t *testing.T) {
+	cases := map[string]string{
+		"Lbs":     "lb",
+		" pounds": "lb",
+		"each":    "",
+		"DZ":      "dozen",
+		"bunch":   "bunch",
+	}
+	for input, expected := range cases {
+		if got := models.NormalizeUnit(input); got != expected {
+			t.Errorf("NormalizeUnit(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
+
+func TestConvertQuantity(t *testing.T) {
+	if got, ok := models.ConvertQuantity(1, "dozen", ""); !ok || got != 12 {
+		t.Errorf("Expected 1 dozen to be 12 items, got %v (%v)", got, ok)
+	}
+
+	got, ok := models.ConvertQuantity(16, "oz", "lbs")
+	if !ok || math.Abs(got-1) > 1e-9 {
+		t.Errorf("Expected 16 oz to be 1 lb, got %v (%v)", got, ok)
+	}
+
+	if _, ok := models.ConvertQuantity(1, "lb", "l"); ok {
+		t.Error("Expected mass and volume not to convert")
+	}
+	if _, ok := models.ConvertQuantity(1, "bunch", "lb"); ok {
+		t.Error("Expected unknown units not to convert")
+	}
+	if got, ok := models.ConvertQuantity(2, "Bunch", "bunch"); !ok || got != 2 {
+		t.Errorf("Expected identical free-form units to merge, got %v (%v)", got, ok)
+	}
+}
+
+func TestPantryItemRestock(t *testing.T) {
+	item := models.PantryItem{Name: "Flour", Quantity: 1, Unit: "kg"}
+
+	if !item.Restock(500, "g") {
+		t.Fatal("expected grams to restock a kilogram item")
+	}
+	if math.Abs(item.Quantity-1.5) > 1e-9 {
+		t.Errorf("expected 1.5 kg, got %v", item.Quantity)
+	}
+
+	if item.Restock(2, "l") {
+		t.Error("expected liters to be rejected for a kilogram item")
+	}
+	if math.Abs(item.Quantity-1.5) > 1e-9 {
+		t.Errorf("expected quantity unchanged after rejected restock, got %v", item.Quantity)
+	}
+
+	eggs := models.PantryItem{Name: "Eggs", Quantity: 6, Unit: "pcs"}
+	if !eggs.Restock(1, "dozen") || eggs.Quantity != 18 {
+		t.Errorf("expected 18 eggs after restocking a dozen, got %v", eggs.Quantity)
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	cases := []struct {
+		input    string
+		quantity float64
+		unit     string
+	}{
+		{"500g", 500, "g"},
+		{"1,5 L", 1.5, "l"},
+		{" 2 lbs ", 2, "lb"},
+		{"3", 3, ""},
+		{"12 fl oz", 12, "fl oz"},
+	}
+	for _, c := range cases {
+		quantity, unit, ok := models.ParseQuantity(c.input)
+		if !ok || quantity != c.quantity || unit != c.unit {
+			t.Errorf("ParseQuantity(%q): expected %v %q, got %v %q (%v)", c.input, c.quantity, c.unit, quantity, unit, ok)
+		}
+	}
+
+	if _, _, ok := models.ParseQuantity("some flour"); ok {
+		t.Error("expected text without an amount to be rejected")
+	}
+}
+
+func TestPantryItemInItemUnit(t *testing.T) {
+	item := models.PantryItem{Name: "Coffee", Quantity: 1, Unit: "kg"}
+
+	if got, ok := item.InItemUnit(500, "g"); !ok || math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("expected 500 g to be 0.5 kg, got %v (%v)", got, ok)
+	}
+	if got, ok := item.InItemUnit(0.25, ""); !ok || got != 0.25 {
+		t.Errorf("expected an empty unit to mean the item's unit, got %v (%v)", got, ok)
+	}
+	if _, ok := item.InItemUnit(1, "l"); ok {
+		t.Error("expected liters to be rejected for a kilogram item")
+	}
+}
+
+func TestPantryItemAddStock(t *testing.T) {
+	item := models.PantryItem{Name: "Rice", Quantity: 2, Unit: "kg"}
+
+	if !item.AddStock(1.5, "") {
+		t.Fatal("expected a quantity without a unit to be added in the item's unit")
+	}
+	if math.Abs(item.Quantity-3.5) > 1e-9 || item.Unit != "kg" {
+		t.Errorf("expected 3.5 kg, got %v %q", item.Quantity, item.Unit)
+	}
+
+	if !item.AddStock(500, "g") {
+		t.Fatal("expected grams to be added to a kilogram item")
+	}
+	if math.Abs(item.Quantity-4) > 1e-9 || item.Unit != "kg" {
+		t.Errorf("expected 4 kg, got %v %q", item.Quantity, item.Unit)
+	}
+
+	if item.AddStock(3, "pcs") {
+		t.Error("expected pieces to be rejected for a kilogram item")
+	}
+	if math.Abs(item.Quantity-4) > 1e-9 || item.Unit != "kg" {
+		t.Errorf("expected the item unchanged after a rejected add, got %v %q", item.Quantity, item.Unit)
+	}
+}
@@ -0,0 +1,100 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"net/url"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestPaymentHandlesValidate(t *testing.T) {
+	handles := models.PaymentHandles{Venmo: " @sam-smith ", PayPal: "samsmith", UPI: "sam.smith@okbank"}.Normalize()
+	if handles.Venmo != "sam-smith" {
+		t.Errorf("Expected the @ to be stripped from the Venmo handle, got %q", handles.Venmo)
+	}
+	if err := handles.Validate(); err != nil {
+		t.Errorf("Expected valid handles, got %v", err)
+	}
+	if err := (models.PaymentHandles{}).Validate(); err != nil {
+		t.Errorf("Expected empty handles to be valid, got %v", err)
+	}
+
+	invalid := []models.PaymentHandles{
+		{Venmo: "sam"},
+		{PayPal: "sam/smith"},
+		{UPI: "sam.smith"},
+	}
+	for _, h := range invalid {
+		if err := h.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", h)
+		}
+	}
+}
+
+func TestPaymentLinks(t *testing.T) {
+	if links := (models.PaymentHandles{}).PaymentLinks("Sam", 23.5, "Rent"); len(links) != 0 {
+		t.Errorf("Expected no links without handles, got %+v", links)
+	}
+
+	handles := models.PaymentHandles{Venmo: "sam-smith", PayPal: "samsmith", UPI: "sam@okbank"}
+	links := handles.PaymentLinks("Sam Smith", 23.5, "Settling up in Apt 4")
+	if len(links) != 3 {
+		t.Fatalf("Expected a link per handle, got %+v", links)
+	}
+
+	venmo, err := url.Parse(links[0].URL)
+	if err != nil || links[0].Provider != models.PaymentProviderVenmo || venmo.Path != "/sam-smith" {
+		t.Errorf("Unexpected Venmo link %+v", links[0])
+	}
+	if query := venmo.Query(); query.Get("amount") != "23.50" || query.Get("note") != "Settling up in Apt 4" || query.Get("txn") != "pay" {
+		t.Errorf("Expected the Venmo link to be prefilled, got %s", links[0].URL)
+	}
+
+	if links[1].URL != "https://paypal.me/samsmith/23.50" {
+		t.Errorf("Unexpected PayPal link %s", links[1].URL)
+	}
+
+	upi, err := url.Parse(links[2].URL)
+	if err != nil || upi.Scheme != "upi" {
+		t.Fatalf("Unexpected UPI link %+v", links[2])
+	}
+	if query := upi.Query(); query.Get("pa") != "sam@okbank" || query.Get("pn") != "Sam Smith" || query.Get("am") != "23.50" {
+		t.Errorf("Expected the UPI link to be prefilled, got %s", links[2].URL)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	overnight := models.QuietHours{Start: "22:00", End: "08:00", TimeZone: "America/New_York"}
+	if err := overnight.Validate(); err != nil {
+		t.Fatalf("Expected valid quiet hours, got %v", err)
+	}
+
+	// 03:30 UTC is 23:30 the evening before in New York during daylight saving time
+	if !overnight.Contains(time.Date(2025, 6, 2, 3, 30, 0, 0, time.UTC)) {
+		t.Error("Expected 23:30 in New York to be within quiet hours")
+	}
+	if overnight.Contains(time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected 08:00 in New York to be outside quiet hours")
+	}
+
+	afternoon := models.QuietHours{Start: "13:00", End: "15:00"}
+	if !afternoon.Contains(time.Date(2025, 6, 2, 14, 59, 0, 0, time.UTC)) || afternoon.Contains(time.Date(2025, 6, 2, 15, 0, 0, 0, time.UTC)) {
+		t.Error("Expected a same-day window to default to UTC and end before its end time")
+	}
+
+	if (models.QuietHours{}).Contains(time.Now()) {
+		t.Error("Expected no quiet hours when none are set")
+	}
+	invalid := []models.QuietHours{
+		{Start: "22:00"},
+		{Start: "25:00", End: "08:00"},
+		{Start: "08:00", End: "08:00"},
+		{Start: "22:00", End: "08:00", TimeZone: "Mars/Olympus_Mons"},
+	}
+	for _, quietHours := range invalid {
+		if quietHours.Validate() == nil {
+			t.Errorf("Expected %+v to be rejected", quietHours)
+		}
+	}
+}
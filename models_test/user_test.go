@@ -0,0 +1,33 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+)
+
+func TestSMSNumber(t *testing.T) {
+	cases := []struct {
+		phone, countryCode, want string
+	}{
+		{"+1 (555) 123-4567", "", "+15551234567"},
+		{"555.123.4567", "1", "+15551234567"},
+		{"07911 123456", "+44", "+447911123456"},
+	}
+	for _, c := range cases {
+		if got, ok := (models.User{PhoneNumber: c.phone}).SMSNumber(c.countryCode); !ok || got != c.want {
+			t.Errorf("SMSNumber(%q, %q) = %q (%v), want %q", c.phone, c.countryCode, got, ok, c.want)
+		}
+	}
+
+	invalid := []struct{ phone, countryCode string }{
+		{"5551234567", ""},      // No country code to add
+		{"+1 555 CALL NOW", ""}, // Letters
+		{"+123", ""},            // Too short
+		{"", "1"},
+	}
+	for _, c := range invalid {
+		if got, ok := (models.User{PhoneNumber: c.phone}).SMSNumber(c.countryCode); ok {
+			t.Errorf("expected %q to be rejected, got %q", c.phone, got)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package models_test
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDigestDue(t *testing.T) {
+	signedUp := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	user := models.User{CreatedAt: signedUp}
+
+	if user.DigestDue(signedUp.Add(6 * 24 * time.Hour)) {
+		t.Error("expected no digest in the first week after signing up")
+	}
+	if !user.DigestDue(signedUp.Add(models.WeeklyDigestInterval)) {
+		t.Error("expected the first digest a week after signing up")
+	}
+
+	sent := signedUp.Add(10 * 24 * time.Hour)
+	user.LastDigestAt = &sent
+	if user.DigestDue(sent.Add(models.WeeklyDigestInterval - time.Hour)) {
+		t.Error("expected no digest within a week of the last one")
+	}
+	if !user.DigestDue(sent.Add(models.WeeklyDigestInterval)) {
+		t.Error("expected a digest a week after the last one")
+	}
+}
+
+func TestBalanceSummary(t *testing.T) {
+	cases := map[float64]string{
+		-12.5:  "You owe 12.50 USD",
+		30:     "You are owed 30.00 USD",
+		0.004:  "You are settled up",
+		-0.004: "You are settled up",
+	}
+	for net, want := range cases {
+		if got := models.BalanceSummary(net, "USD"); got != want {
+			t.Errorf("BalanceSummary(%v) = %q, want %q", net, got, want)
+		}
+	}
+}
+
+func TestEmailAddress(t *testing.T) {
+	if address, ok := (models.User{Username: "sam@example.com"}).EmailAddress(); !ok || address != "sam@example.com" {
+		t.Errorf("expected the username to be the address, got %q (%v)", address, ok)
+	}
+	for _, username := range []string{"sam", "Sam <sam@example.com>", ""} {
+		if _, ok := (models.User{Username: username}).EmailAddress(); ok {
+			t.Errorf("expected %q not to be an address", username)
+		}
+	}
+}
+
+func TestChoreNeedsEscalation(t *testing.T) {
+	due := time.Date(2025, time.March, 3, 23, 59, 0, 0, time.UTC)
+	chore := models.Chore{Status: models.ChoreStatusOverdue, DueDate: due}
+
+	if chore.NeedsEscalation(due.Add(models.ChoreEscalationDelay - time.Minute)) {
+		t.Error("expected no escalation before the delay")
+	}
+	now := due.Add(models.ChoreEscalationDelay + time.Hour)
+	if !chore.NeedsEscalation(now) {
+		t.Error("expected an escalation once the chore stayed overdue")
+	}
+	if days := chore.DaysOverdue(now); days != 2 {
+		t.Errorf("expected 2 days overdue, got %d", days)
+	}
+
+	escalated := now
+	chore.EscalatedAt = &escalated
+	if chore.NeedsEscalation(now.Add(time.Hour)) {
+		t.Error("expected a chore to be escalated once")
+	}
+
+	completed := models.Chore{Status: models.ChoreStatusCompleted, DueDate: due}
+	if completed.NeedsEscalation(now) {
+		t.Error("expected completed chores not to be escalated")
+	}
+}
+
+func TestDailyDigestDue(t *testing.T) {
+	sent := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	user := models.User{CreatedAt: sent.Add(-30 * 24 * time.Hour), LastDigestAt: &sent}
+	user.Preferences.Notifications.DigestFrequency = models.DigestFrequencyDaily
+
+	if user.DigestDue(sent.Add(23 * time.Hour)) {
+		t.Error("expected no daily digest within a day of the last one")
+	}
+	if !user.DigestDue(sent.Add(models.DailyDigestInterval)) {
+		t.Error("expected a daily digest a day after the last one")
+	}
+	if period := user.Preferences.Notifications.DigestFrequency.Period(); period != "day" {
+		t.Errorf("expected a daily digest to cover a day, got %q", period)
+	}
+}
+
+func TestDigestPeriodStart(t *testing.T) {
+	sent := time.Date(2025, time.March, 1, 9, 0, 0, 0, time.UTC)
+	user := models.User{LastDigestAt: &sent}
+	if start := user.DigestPeriodStart(sent.Add(3 * 24 * time.Hour)); !start.Equal(sent) {
+		t.Errorf("expected the period to start at the last digest, got %v", start)
+	}
+
+	// Switching from weekly to daily covers the last day, not the whole week
+	user.Preferences.Notifications.DigestFrequency = models.DigestFrequencyDaily
+	now := sent.Add(5 * 24 * time.Hour)
+	if start := user.DigestPeriodStart(now); !start.Equal(now.Add(-models.DailyDigestInterval)) {
+		t.Errorf("expected the period to be at most a day, got %v", start)
+	}
+}
+
+func TestBalancesBefore(t *testing.T) {
+	sam, alex := primitive.NewObjectID(), primitive.NewObjectID()
+	members := []primitive.ObjectID{sam, alex}
+	cutoff := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	expenses := []models.Expense{
+		{PaidBy: sam, Amount: 20, Splits: []models.CostShare{{UserID: sam, Amount: 10}, {UserID: alex, Amount: 10}}, CreatedAt: cutoff.Add(-time.Hour)},
+		{PaidBy: alex, Amount: 40, Splits: []models.CostShare{{UserID: sam, Amount: 20}, {UserID: alex, Amount: 20}}, CreatedAt: cutoff.Add(time.Hour)},
+	}
+	settlements := []models.Settlement{{FromUserID: alex, ToUserID: sam, Amount: 10, CreatedAt: cutoff.Add(time.Minute)}}
+
+	before := models.BalancesBefore(expenses, settlements, members, cutoff)
+	if net := models.FindBalance(before, sam).Net; net != 10 {
+		t.Errorf("expected only the earlier expense to count, got %v", net)
+	}
+}
+
+func TestBalanceChangeSummary(t *testing.T) {
+	cases := []struct {
+		previous, current float64
+		want              string
+	}{
+		{-10, -5, "Up 5.00 USD since your last digest"},
+		{10, -2.5, "Down 12.50 USD since your last digest"},
+		{3, 3.001, ""},
+	}
+	for _, c := range cases {
+		if got := models.BalanceChangeSummary(c.previous, c.current, "USD"); got != c.want {
+			t.Errorf("BalanceChangeSummary(%v, %v) = %q, want %q", c.previous, c.current, got, c.want)
+		}
+	}
+}
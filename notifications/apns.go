@@ -0,0 +1,201 @@
+// notifications/apns.go
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// APNs hosts; the sandbox one serves development builds of the app
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+)
+
+// apnsTokenTTL is how long a provider token is reused. Apple rejects tokens older than an hour and
+// throttles providers that create new ones more often than every 20 minutes.
+const apnsTokenTTL = 50 * time.Minute
+
+// APNsKey identifies the signing key created in the Apple developer account and the app pushes
+// are sent to
+type APNsKey struct {
+	KeyID      string // ID of the .p8 key
+	TeamID     string // Developer team the key belongs to
+	Topic      string // Bundle ID of the iOS app
+	PrivateKey string // Contents of the .p8 key file
+}
+
+// APNsClient sends push messages to iOS devices through the Apple Push Notification service,
+// authenticating with a signed provider token
+type APNsClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	key        APNsKey
+	signingKey *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsClient creates a client that signs its provider tokens with the key. Messages go to the
+// production service unless sandbox is set.
+func NewAPNsClient(key APNsKey, sandbox bool) (*APNsClient, error) {
+	if key.KeyID == "" || key.TeamID == "" || key.Topic == "" {
+		return nil, errors.New("APNs needs a key ID, team ID and topic")
+	}
+	signingKey, err := jwt.ParseECPrivateKeyFromPEM([]byte(key.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs private key: %v", err)
+	}
+
+	baseURL := os.Getenv("APNS_BASE_URL")
+	if baseURL == "" {
+		baseURL = apnsProductionURL
+		if sandbox {
+			baseURL = apnsSandboxURL
+		}
+	}
+	return &APNsClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second}, // APNs requires HTTP/2, which the default transport negotiates
+		key:        key,
+		signingKey: signingKey,
+	}, nil
+}
+
+// NewAPNsClientFromEnv creates a client from APNS_KEY_ID, APNS_TEAM_ID, APNS_TOPIC and the .p8 key
+// in APNS_PRIVATE_KEY or the file named by APNS_PRIVATE_KEY_FILE. APNS_ENVIRONMENT=sandbox sends to
+// development builds. It returns nil without an error when no key is set.
+func NewAPNsClientFromEnv() (*APNsClient, error) {
+	privateKey := strings.TrimSpace(os.Getenv("APNS_PRIVATE_KEY"))
+	if privateKey == "" {
+		path := strings.TrimSpace(os.Getenv("APNS_PRIVATE_KEY_FILE"))
+		if path == "" {
+			return nil, nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read APNs key: %v", err)
+		}
+		privateKey = string(data)
+	}
+
+	return NewAPNsClient(APNsKey{
+		KeyID:      strings.TrimSpace(os.Getenv("APNS_KEY_ID")),
+		TeamID:     strings.TrimSpace(os.Getenv("APNS_TEAM_ID")),
+		Topic:      strings.TrimSpace(os.Getenv("APNS_TOPIC")),
+		PrivateKey: privateKey,
+	}, strings.EqualFold(strings.TrimSpace(os.Getenv("APNS_ENVIRONMENT")), "sandbox"))
+}
+
+// providerToken returns the signed token sent with every request, creating a new one when the
+// current one is about to expire
+func (c *APNsClient) providerToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Since(c.issuedAt) < apnsTokenTTL {
+		return c.token, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": c.key.TeamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = c.key.KeyID
+	signed, err := token.SignedString(c.signingKey)
+	if err != nil {
+		return "", err
+	}
+	c.token, c.issuedAt = signed, now
+	return c.token, nil
+}
+
+// resetProviderToken drops the current token so the next request signs a new one
+func (c *APNsClient) resetProviderToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+}
+
+// apnsAlert is the visible part of a push message
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Send delivers a message to one device
+func (c *APNsClient) Send(ctx context.Context, deviceToken string, message Message) error {
+	providerToken, err := c.providerToken()
+	if err != nil {
+		return fmt.Errorf("failed to sign APNs token: %v", err)
+	}
+
+	// Custom data sits next to the aps dictionary, the same keys FCM delivers as data
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": apnsAlert{Title: message.Title, Body: message.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range message.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+"/3/device/"+url.PathEscape(deviceToken), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("apns-topic", c.key.Topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if message.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", message.CollapseKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	json.NewDecoder(resp.Body).Decode(&failure)
+	switch {
+	case resp.StatusCode == http.StatusGone || failure.Reason == "Unregistered":
+		return ErrUnregistered
+	case failure.Reason == "ExpiredProviderToken":
+		c.resetProviderToken()
+	}
+	if failure.Reason != "" {
+		return fmt.Errorf("apns returned %s", failure.Reason)
+	}
+	return fmt.Errorf("apns returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,99 @@
+package notifications
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// newTestAPNs starts a fake APNs server and returns a client using it
+func newTestAPNs(t *testing.T, handler http.HandlerFunc) *APNsClient {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := NewAPNsClient(APNsKey{
+		KeyID:      "KEY123",
+		TeamID:     "TEAM456",
+		Topic:      "com.cribb.app",
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	}, true)
+	if err != nil {
+		t.Fatalf("expected a client, got %v", err)
+	}
+	client.BaseURL = server.URL
+	return client
+}
+
+func TestAPNsSend(t *testing.T) {
+	var request *http.Request
+	var payload map[string]interface{}
+	client := newTestAPNs(t, func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		json.NewDecoder(r.Body).Decode(&payload)
+	})
+
+	message := Message{Title: "New chore", Body: "Dishes is yours", CollapseKey: "chores", Data: map[string]string{"type": "chore_assigned"}}
+	if err := client.Send(context.Background(), "abc123", message); err != nil {
+		t.Fatalf("expected the message to be sent, got %v", err)
+	}
+
+	if request.URL.Path != "/3/device/abc123" {
+		t.Errorf("expected the device token in the path, got %q", request.URL.Path)
+	}
+	if request.Header.Get("apns-topic") != "com.cribb.app" || request.Header.Get("apns-push-type") != "alert" || request.Header.Get("apns-collapse-id") != "chores" {
+		t.Errorf("unexpected headers %v", request.Header)
+	}
+
+	signed := strings.TrimPrefix(request.Header.Get("Authorization"), "bearer ")
+	token, _, err := new(jwt.Parser).ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("expected a provider token, got %v", err)
+	}
+	if token.Header["kid"] != "KEY123" || token.Header["alg"] != "ES256" || token.Claims.(jwt.MapClaims)["iss"] != "TEAM456" {
+		t.Errorf("unexpected provider token %v %v", token.Header, token.Claims)
+	}
+
+	aps, _ := payload["aps"].(map[string]interface{})
+	alert, _ := aps["alert"].(map[string]interface{})
+	if alert["title"] != "New chore" || alert["body"] != "Dishes is yours" || payload["type"] != "chore_assigned" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+}
+
+func TestAPNsSendUnregistered(t *testing.T) {
+	client := newTestAPNs(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+		w.Write([]byte(`{"reason":"Unregistered","timestamp":1700000000000}`))
+	})
+	if err := client.Send(context.Background(), "stale", Message{Title: "New chore"}); !errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected ErrUnregistered, got %v", err)
+	}
+
+	client = newTestAPNs(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"reason":"BadTopic"}`))
+	})
+	if err := client.Send(context.Background(), "device", Message{Title: "New chore"}); err == nil || errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected another error for a bad topic, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+// notifications/fcm.go
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// defaultFCMURL is used unless FCM_BASE_URL is set
+const defaultFCMURL = "https://fcm.googleapis.com"
+
+// fcmScope is the OAuth scope needed to send messages
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// ServiceAccount holds the fields of a Firebase service account key file that are needed to send
+// messages
+type ServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// FCMClient sends push messages through the Firebase Cloud Messaging HTTP v1 API
+type FCMClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	account ServiceAccount
+	key     *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewFCMClient creates a client that authenticates as the service account
+func NewFCMClient(account ServiceAccount) (*FCMClient, error) {
+	if account.ProjectID == "" || account.ClientEmail == "" || account.TokenURI == "" {
+		return nil, errors.New("service account needs a project_id, client_email and token_uri")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid service account private key: %v", err)
+	}
+
+	baseURL := os.Getenv("FCM_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultFCMURL
+	}
+	return &FCMClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		account:    account,
+		key:        key,
+	}, nil
+}
+
+// NewFCMClientFromEnv creates a client from the service account key in FCM_CREDENTIALS_JSON, or in
+// the file named by FCM_CREDENTIALS_FILE. It returns nil without an error when neither is set.
+func NewFCMClientFromEnv() (*FCMClient, error) {
+	data := []byte(strings.TrimSpace(os.Getenv("FCM_CREDENTIALS_JSON")))
+	if len(data) == 0 {
+		path := strings.TrimSpace(os.Getenv("FCM_CREDENTIALS_FILE"))
+		if path == "" {
+			return nil, nil
+		}
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read FCM credentials: %v", err)
+		}
+	}
+
+	var account ServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %v", err)
+	}
+	return NewFCMClient(account)
+}
+
+// token returns an OAuth access token for the service account, fetching a new one shortly before
+// the current one expires
+func (c *FCMClient) token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.accessToken != "" && time.Now().Before(c.expiresAt.Add(-time.Minute)) {
+		return c.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   c.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   c.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(c.key)
+	if err != nil {
+		return "", err
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.account.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.AccessToken == "" {
+		return "", errors.New("token endpoint returned no access token")
+	}
+	c.accessToken = body.AccessToken
+	c.expiresAt = now.Add(time.Duration(body.ExpiresIn) * time.Second)
+	return c.accessToken, nil
+}
+
+// fcmMessage is the message resource of the send request
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+	Android      *fcmAndroid       `json:"android,omitempty"`
+	APNS         *fcmAPNS          `json:"apns,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmAndroid struct {
+	CollapseKey string `json:"collapse_key"`
+}
+
+type fcmAPNS struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// fcmError is the error body of a failed send
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send delivers a message to one device
+func (c *FCMClient) Send(ctx context.Context, deviceToken string, message Message) error {
+	accessToken, err := c.token(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with FCM: %v", err)
+	}
+
+	payload := fcmMessage{
+		Token:        deviceToken,
+		Notification: fcmNotification{Title: message.Title, Body: message.Body},
+		Data:         message.Data,
+	}
+	if message.CollapseKey != "" {
+		// Newer messages with the same key replace older ones still shown on the device
+		payload.Android = &fcmAndroid{CollapseKey: message.CollapseKey}
+		payload.APNS = &fcmAPNS{Headers: map[string]string{"apns-collapse-id": message.CollapseKey}}
+	}
+	body, err := json.Marshal(map[string]fcmMessage{"message": payload})
+	if err != nil {
+		return err
+	}
+
+	endpoint := c.BaseURL + "/v1/projects/" + url.PathEscape(c.account.ProjectID) + "/messages:send"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure fcmError
+	if err := json.NewDecoder(resp.Body).Decode(&failure); err == nil {
+		for _, detail := range failure.Error.Details {
+			if detail.ErrorCode == "UNREGISTERED" {
+				return ErrUnregistered
+			}
+		}
+		if failure.Error.Status != "" {
+			return fmt.Errorf("fcm returned %s: %s", failure.Error.Status, failure.Error.Message)
+		}
+	}
+	return fmt.Errorf("fcm returned status %d", resp.StatusCode)
+}
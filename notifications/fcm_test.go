@@ -0,0 +1,114 @@
+package notifications
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestFCM starts a fake token endpoint and FCM API and returns a client using them. The send
+// handler gets the decoded message of every request.
+func newTestFCM(t *testing.T, send func(w http.ResponseWriter, message fcmMessage)) (*FCMClient, *int32) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var tokenRequests int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&tokenRequests, 1)
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || r.FormValue("assertion") == "" {
+			http.Error(w, "bad grant", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "expires_in": 3600})
+	})
+	mux.HandleFunc("/v1/projects/cribb/messages:send", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Message fcmMessage `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "bad body", http.StatusBadRequest)
+			return
+		}
+		send(w, body.Message)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, err := NewFCMClient(ServiceAccount{
+		ProjectID:   "cribb",
+		ClientEmail: "push@cribb.iam.gserviceaccount.com",
+		PrivateKey:  string(privateKey),
+		TokenURI:    server.URL + "/token",
+	})
+	if err != nil {
+		t.Fatalf("expected a client, got %v", err)
+	}
+	client.BaseURL = server.URL
+	return client, &tokenRequests
+}
+
+func TestFCMSend(t *testing.T) {
+	var received []fcmMessage
+	client, tokenRequests := newTestFCM(t, func(w http.ResponseWriter, message fcmMessage) {
+		received = append(received, message)
+		w.Write([]byte(`{"name":"projects/cribb/messages/1"}`))
+	})
+
+	message := Message{Title: "Shopping list", Body: "Sam bought milk", CollapseKey: "shopping-1", Data: map[string]string{"type": "shopping_update"}}
+	for i := 0; i < 2; i++ {
+		if err := client.Send(context.Background(), "device", message); err != nil {
+			t.Fatalf("expected the message to be sent, got %v", err)
+		}
+	}
+
+	if atomic.LoadInt32(tokenRequests) != 1 {
+		t.Errorf("expected the access token to be reused, got %d token requests", *tokenRequests)
+	}
+	if len(received) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(received))
+	}
+	got := received[0]
+	if got.Token != "device" || got.Notification.Title != "Shopping list" || got.Data["type"] != "shopping_update" {
+		t.Errorf("unexpected message %+v", got)
+	}
+	if got.Android == nil || got.Android.CollapseKey != "shopping-1" || got.APNS == nil || got.APNS.Headers["apns-collapse-id"] != "shopping-1" {
+		t.Errorf("expected the collapse key on android and apns, got %+v", got)
+	}
+}
+
+func TestFCMSendUnregistered(t *testing.T) {
+	client, _ := newTestFCM(t, func(w http.ResponseWriter, message fcmMessage) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","message":"Requested entity was not found.","details":[{"@type":"type.googleapis.com/google.firebase.fcm.v1.FcmError","errorCode":"UNREGISTERED"}]}}`))
+	})
+	err := client.Send(context.Background(), "stale", Message{Title: "New chore", Body: "Dishes is yours"})
+	if !errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected ErrUnregistered, got %v", err)
+	}
+
+	client, _ = newTestFCM(t, func(w http.ResponseWriter, message fcmMessage) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"code":400,"status":"INVALID_ARGUMENT","message":"bad token"}}`))
+	})
+	err = client.Send(context.Background(), "bad", Message{Title: "New chore"})
+	if err == nil || errors.Is(err, ErrUnregistered) {
+		t.Errorf("expected another error for an invalid request, got %v", err)
+	}
+}
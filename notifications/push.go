@@ -0,0 +1,158 @@
+// notifications/push.go
+package notifications
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrUnregistered is returned when a push provider no longer knows a device token, e.g. because
+// the app was uninstalled. Such tokens should be removed.
+var ErrUnregistered = errors.New("device token is no longer registered")
+
+// Message is a push notification shown on a member's devices
+type Message struct {
+	Title       string
+	Body        string
+	Data        map[string]string // Passed to the app, e.g. which screen to open
+	CollapseKey string            // Messages with the same key replace each other on the device
+}
+
+// MessageFromNotification builds the push message for a stored notification
+func MessageFromNotification(notification models.Notification) Message {
+	data := map[string]string{"type": string(notification.Type)}
+	if !notification.ID.IsZero() {
+		data["notification_id"] = notification.ID.Hex()
+	}
+	if !notification.GroupID.IsZero() {
+		data["group_id"] = notification.GroupID.Hex()
+	}
+	return Message{Title: notification.Title, Body: notification.Message, Data: data}
+}
+
+// Notifier delivers a message to one device through a push provider
+type Notifier interface {
+	Send(ctx context.Context, deviceToken string, message Message) error
+}
+
+// notifiers deliver push messages to devices of each platform; platforms without one get none
+var notifiers = map[models.DevicePlatform]Notifier{}
+
+// SetNotifier replaces how push messages reach devices of the platform; nil turns them off
+func SetNotifier(platform models.DevicePlatform, notifier Notifier) {
+	if notifier == nil {
+		delete(notifiers, platform)
+		return
+	}
+	notifiers[platform] = notifier
+}
+
+// Enabled reports whether push messages are delivered to any platform
+func Enabled() bool {
+	return len(notifiers) > 0
+}
+
+// Configure sets up delivery from the environment. FCM, see NewFCMClientFromEnv, delivers to every
+// platform; APNs, see NewAPNsClientFromEnv, takes over iOS devices when it is configured, in which
+// case iOS apps register their APNs device token instead of an FCM one. Push notifications stay
+// off when neither is configured.
+func Configure() {
+	fcm, err := NewFCMClientFromEnv()
+	switch {
+	case err != nil:
+		log.Printf("FCM push notifications disabled: %v", err)
+	case fcm == nil:
+		log.Println("FCM push notifications disabled: FCM_CREDENTIALS_JSON or FCM_CREDENTIALS_FILE is not set")
+	default:
+		SetNotifier(models.DevicePlatformAndroid, fcm)
+		SetNotifier(models.DevicePlatformWeb, fcm)
+		SetNotifier(models.DevicePlatformIOS, fcm)
+		log.Println("Push notifications enabled through FCM")
+	}
+
+	apns, err := NewAPNsClientFromEnv()
+	switch {
+	case err != nil:
+		log.Printf("APNs push notifications disabled: %v", err)
+	case apns != nil:
+		SetNotifier(models.DevicePlatformIOS, apns)
+		log.Println("Push notifications to iOS devices enabled through APNs")
+	}
+}
+
+// QueuePush queues a message for every device the member registered on a platform with a
+// notifier. The push jobs deliver each entry and retry failures with backoff, so a provider outage
+// delays the message instead of dropping it. It returns how many devices the message was queued for.
+func QueuePush(ctx context.Context, user models.User, message Message) (int, error) {
+	if !Enabled() {
+		return 0, nil
+	}
+
+	cursor, err := config.DB.Collection("device_tokens").Find(ctx, bson.M{"user_id": user.ID})
+	if err != nil {
+		return 0, err
+	}
+	var devices []models.DeviceToken
+	if err := cursor.All(ctx, &devices); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var pushes []interface{}
+	for _, device := range devices {
+		if _, ok := notifiers[device.Platform]; !ok {
+			continue
+		}
+		pushes = append(pushes, models.QueuedPush{
+			UserID:        user.ID,
+			GroupID:       user.GroupID,
+			DeviceID:      device.ID,
+			Platform:      device.Platform,
+			Title:         message.Title,
+			Body:          message.Body,
+			Data:          message.Data,
+			CollapseKey:   message.CollapseKey,
+			Status:        models.QueuedPushPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+	}
+	if len(pushes) == 0 {
+		return 0, nil
+	}
+	if _, err := config.DB.Collection("push_queue").InsertMany(ctx, pushes); err != nil {
+		return 0, err
+	}
+	return len(pushes), nil
+}
+
+// MessageFromQueuedPush rebuilds the message of a queued push
+func MessageFromQueuedPush(push models.QueuedPush) Message {
+	return Message{Title: push.Title, Body: push.Body, Data: push.Data, CollapseKey: push.CollapseKey}
+}
+
+// ErrNoNotifier is returned when a device's platform has no notifier, e.g. because its provider
+// was turned off after the push was queued
+var ErrNoNotifier = errors.New("push notifications are not enabled for the device's platform")
+
+// SendToDevice delivers a message to one device through the notifier of its platform. Tokens the
+// provider reports as no longer registered are removed, and ErrUnregistered is returned.
+func SendToDevice(ctx context.Context, device models.DeviceToken, message Message) error {
+	notifier, ok := notifiers[device.Platform]
+	if !ok {
+		return ErrNoNotifier
+	}
+	err := notifier.Send(ctx, device.Token, message)
+	if errors.Is(err, ErrUnregistered) {
+		if _, deleteErr := config.DB.Collection("device_tokens").DeleteOne(ctx, bson.M{"_id": device.ID}); deleteErr != nil {
+			log.Printf("Failed to remove unregistered device token %s: %v", device.ID.Hex(), deleteErr)
+		}
+	}
+	return err
+}
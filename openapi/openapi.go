@@ -0,0 +1,201 @@
+// openapi/openapi.go
+package openapi
+
+import (
+	"cribb-backend/apierror"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version of the OpenAPI specification documents are written against
+const Version = "3.0.3"
+
+// Operation documents one method of one route. Request and response bodies are given as values of
+// the Go types the handler decodes and encodes, so the schemas follow the code.
+type Operation struct {
+	Method   string
+	Path     string // Path parameters in braces, e.g. /api/groups/polls/{id}/vote
+	Tag      string
+	Summary  string
+	Query    []Param
+	Request  interface{} // JSON request body, nil when there is none
+	Files    []string    // Multipart file fields of uploads; with Request, either body is accepted
+	Response interface{} // JSON response body, nil when there is none
+	Produces string      // Content type of responses that are not JSON, like text/csv
+	Status   int         // Success status, defaults to 200
+	Public   bool        // Served without a bearer token
+}
+
+// Param is a query string parameter
+type Param struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components holds the schemas of named types and the security schemes operations refer to
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// bearerAuth names the JWT security scheme protected operations require
+const bearerAuth = "bearerAuth"
+
+// Build writes the OpenAPI document for the operations
+func Build(title, version string, operations []Operation) *Document {
+	reg := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*operation),
+		Components: Components{
+			Schemas: reg.components,
+			SecuritySchemes: map[string]*securityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, op := range operations {
+		if doc.Paths[op.Path] == nil {
+			doc.Paths[op.Path] = make(map[string]*operation)
+		}
+		doc.Paths[op.Path][strings.ToLower(op.Method)] = reg.operation(op)
+	}
+	return doc
+}
+
+func (reg *schemaRegistry) operation(op Operation) *operation {
+	out := &operation{Summary: op.Summary, Responses: make(map[string]*response)}
+	if op.Tag != "" {
+		out.Tags = []string{op.Tag}
+	}
+	if !op.Public {
+		out.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	for _, name := range PathParams(op.Path) {
+		out.Parameters = append(out.Parameters, parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	for _, param := range op.Query {
+		out.Parameters = append(out.Parameters, parameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	// Operations taking both a JSON body and files accept either
+	if len(op.Files) > 0 || op.Request != nil {
+		out.RequestBody = &requestBody{Required: true, Content: make(map[string]*mediaType)}
+	}
+	if len(op.Files) > 0 {
+		form := &Schema{Type: "object", Properties: make(map[string]*Schema), Required: op.Files}
+		for _, field := range op.Files {
+			form.Properties[field] = &Schema{Type: "string", Format: "binary"}
+		}
+		out.RequestBody.Content["multipart/form-data"] = &mediaType{Schema: form}
+	}
+	if op.Request != nil {
+		out.RequestBody.Content["application/json"] = &mediaType{Schema: reg.schemaOf(reflect.TypeOf(op.Request))}
+		// PATCH bodies are JSON Merge Patches of the request, see RFC 7386
+		if op.Method == http.MethodPatch {
+			out.RequestBody.Content["application/merge-patch+json"] = out.RequestBody.Content["application/json"]
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := &response{Description: http.StatusText(status)}
+	switch {
+	case op.Produces != "":
+		body := &Schema{Type: "string"}
+		if !strings.HasPrefix(op.Produces, "text/") {
+			body.Format = "binary"
+		}
+		success.Content = map[string]*mediaType{op.Produces: {Schema: body}}
+	case op.Response != nil:
+		success.Content = map[string]*mediaType{"application/json": {Schema: reg.schemaOf(reflect.TypeOf(op.Response))}}
+	}
+	out.Responses[strconv.Itoa(status)] = success
+	// Errors are plain text messages before API version 2, and JSON with a stable code from then on
+	out.Responses["default"] = &response{
+		Description: "Error; its code is also sent in the Error-Code header",
+		Content: map[string]*mediaType{
+			"text/plain":       {Schema: &Schema{Type: "string"}},
+			"application/json": {Schema: reg.schemaOf(reflect.TypeOf(apierror.Error{}))},
+		},
+	}
+	return out
+}
+
+// PathParams returns the names of the parameters in a path, in order
+func PathParams(path string) []string {
+	var params []string
+	for _, segment := range strings.Split(path, "/") {
+		if strings.HasPrefix(segment, "{") {
+			if end := strings.Index(segment, "}"); end > 0 {
+				params = append(params, segment[1:end])
+			}
+		}
+	}
+	return params
+}
@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type testBase struct {
+	ID        primitive.ObjectID `json:"id"`
+	Name      string             `json:"name"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+type testItem struct {
+	testBase
+	Name     string            `json:"name" validate:"required"` // Shadows the embedded name
+	Secret   string            `json:"-"`
+	Quantity *float64          `json:"quantity,omitempty"`
+	Tags     []string          `json:"tags"`
+	Counts   map[string]int    `json:"counts"`
+	Parent   *testItem         `json:"parent,omitempty"`
+	Extra    interface{}       `json:"extra"`
+	Labels   map[string]string `json:"labels,string"`
+	internal int
+}
+
+func TestSchemaOfStruct(t *testing.T) {
+	reg := newSchemaRegistry()
+	schema := reg.schemaOf(reflect.TypeOf(testItem{}))
+	if schema.Ref != "#/components/schemas/testItem" {
+		t.Fatalf("expected a reference to the component, got %+v", schema)
+	}
+
+	item := reg.components["testItem"]
+	for _, name := range []string{"id", "name", "created_at", "quantity", "tags", "counts", "parent", "extra", "labels"} {
+		if item.Properties[name] == nil {
+			t.Errorf("expected property %q", name)
+		}
+	}
+	if _, ok := item.Properties["Secret"]; ok || len(item.Properties) != 9 {
+		t.Errorf("expected skipped and unexported fields to be left out, got %d properties", len(item.Properties))
+	}
+	if len(item.Required) != 1 || item.Required[0] != "name" {
+		t.Errorf("expected the outer name to be required, got %v", item.Required)
+	}
+
+	if id := item.Properties["id"]; id.Type != "string" || id.Pattern != objectIDPattern {
+		t.Errorf("expected object IDs as hex strings, got %+v", id)
+	}
+	if created := item.Properties["created_at"]; created.Format != "date-time" {
+		t.Errorf("expected times as date-time strings, got %+v", created)
+	}
+	if quantity := item.Properties["quantity"]; quantity.Type != "number" || !quantity.Nullable {
+		t.Errorf("expected a nullable number, got %+v", quantity)
+	}
+	if tags := item.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Errorf("expected an array of strings, got %+v", tags)
+	}
+	if counts := item.Properties["counts"]; counts.Type != "object" || counts.AdditionalProperties.Type != "integer" {
+		t.Errorf("expected a map of integers, got %+v", counts)
+	}
+	if parent := item.Properties["parent"]; !parent.Nullable || len(parent.AllOf) != 1 || parent.AllOf[0].Ref != schema.Ref {
+		t.Errorf("expected a nullable reference back to the type, got %+v", parent)
+	}
+	if labels := item.Properties["labels"]; labels.Type != "string" {
+		t.Errorf("expected the string option to encode as a string, got %+v", labels)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	doc := Build("Test", "1.0.0", []Operation{
+		{Method: http.MethodGet, Path: "/api/items/{id}/versions/{version}", Summary: "A version", Response: testItem{}},
+		{Method: http.MethodPost, Path: "/api/items", Request: testItem{}, Response: []testItem{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Path: "/api/items/{id}/photo", Files: []string{"photo"}},
+		{Method: http.MethodPatch, Path: "/api/items/{id}", Request: testItem{}, Response: testItem{}},
+		{Method: http.MethodGet, Path: "/api/public/items/{token}", Public: true, Produces: "text/plain"},
+	})
+
+	get := doc.Paths["/api/items/{id}/versions/{version}"]["get"]
+	if get == nil || len(get.Parameters) != 2 || get.Parameters[1].Name != "version" || get.Parameters[1].In != "path" {
+		t.Fatalf("expected both path parameters, got %+v", get)
+	}
+	if len(get.Security) != 1 || get.Responses["200"] == nil || get.Responses["default"] == nil {
+		t.Errorf("expected a protected operation with success and error responses, got %+v", get)
+	}
+	if errors := get.Responses["default"].Content["application/json"]; errors == nil || errors.Schema.Ref != "#/components/schemas/Error" {
+		t.Errorf("expected coded JSON errors, got %+v", get.Responses["default"])
+	}
+
+	post := doc.Paths["/api/items"]["post"]
+	if post.RequestBody == nil || post.RequestBody.Content["application/json"] == nil || post.Responses["201"] == nil {
+		t.Errorf("expected a JSON body and a 201 response, got %+v", post)
+	}
+	patch := doc.Paths["/api/items/{id}"]["patch"]
+	if patch.RequestBody == nil || patch.RequestBody.Content["application/merge-patch+json"] == nil {
+		t.Errorf("expected a merge patch body, got %+v", patch)
+	}
+	upload := doc.Paths["/api/items/{id}/photo"]["put"].RequestBody.Content["multipart/form-data"]
+	if upload == nil || upload.Schema.Properties["photo"].Format != "binary" {
+		t.Errorf("expected a multipart upload, got %+v", upload)
+	}
+	public := doc.Paths["/api/public/items/{token}"]["get"]
+	if public.Security != nil || public.Responses["200"].Content["text/plain"] == nil {
+		t.Errorf("expected a public plain text operation, got %+v", public)
+	}
+
+	if _, ok := doc.Components.Schemas["testItem"]; !ok {
+		t.Error("expected the item schema among the components")
+	}
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatalf("expected the document to encode, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+// openapi/schema.go
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Schema is the subset of the OpenAPI schema object generated from Go types
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// objectIDPattern matches the hex form MongoDB object IDs are sent in
+const objectIDPattern = "^[0-9a-f]{24}$"
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// schemaRegistry turns Go types into schemas, collecting named structs as components so each is
+// described once and referenced everywhere else
+type schemaRegistry struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+}
+
+// schemaOf describes the JSON encoding/json produces for t
+func (reg *schemaRegistry) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string", Pattern: objectIDPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := reg.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			// Siblings of $ref are ignored, so nullable references are wrapped
+			return &Schema{Nullable: true, AllOf: []*Schema{schema}}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: reg.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: reg.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return reg.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + reg.component(t)}
+	}
+	// Interfaces and anything else encoding/json accepts carry no schema
+	return &Schema{}
+}
+
+// component registers a named struct and returns its component name. Types sharing a name across
+// packages are told apart by their package.
+func (reg *schemaRegistry) component(t reflect.Type) string {
+	if name, ok := reg.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := reg.components[name]; taken {
+		name = t.String()
+	}
+	reg.names[t] = name
+	// Reserve the name first so recursive types refer back to it
+	reg.components[name] = &Schema{}
+	*reg.components[name] = *reg.structSchema(t)
+	return name
+}
+
+// structSchema describes a struct's JSON object. Fields of embedded structs are promoted the way
+// encoding/json promotes them, with the outer struct's fields taking precedence.
+func (reg *schemaRegistry) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	// Embedded structs are walked a level at a time so shallower fields are never shadowed
+	for level := []reflect.Type{t}; len(level) > 0; {
+		var embedded []reflect.Type
+		for _, structType := range level {
+			embedded = append(embedded, reg.addFields(schema, structType)...)
+		}
+		level = embedded
+	}
+	return schema
+}
+
+// addFields adds the struct's own fields to the schema and returns the structs it embeds
+func (reg *schemaRegistry) addFields(schema *Schema, t reflect.Type) []reflect.Type {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				embedded = append(embedded, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := schema.Properties[name]; ok {
+			continue
+		}
+
+		property := reg.schemaOf(fieldType)
+		if strings.Contains(","+options+",", ",string,") {
+			property = &Schema{Type: "string"}
+		}
+		schema.Properties[name] = property
+		if strings.Contains(field.Tag.Get("validate"), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return embedded
+}
@@ -0,0 +1,55 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestTextDocument(t *testing.T) {
+	doc := TextDocument("Statement", []string{"Rent (March)  1000.00", "Café"})
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("expected a PDF header and trailer")
+	}
+	if !bytes.Contains(doc, []byte(`(Rent \(March\)  1000.00) '`)) {
+		t.Error("expected parentheses in the text to be escaped")
+	}
+	if !bytes.Contains(doc, []byte("(Caf?) '")) {
+		t.Error("expected non-ASCII characters to be replaced")
+	}
+
+	// Every object must start where the cross-reference table says it does
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(doc)
+	if startxref == nil {
+		t.Fatal("expected a startxref entry")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref points at %q", doc[xref:xref+10])
+	}
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(doc[xref:], -1)
+	if len(offsets) != 6 {
+		t.Fatalf("expected 6 objects for a one page document, got %d", len(offsets))
+	}
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(string(offset[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[at:], []byte(want)) {
+			t.Errorf("expected object %d at offset %d", i+1, at)
+		}
+	}
+}
+
+func TestTextDocumentPages(t *testing.T) {
+	lines := make([]string, linesPerPage*2+1)
+	doc := TextDocument("Statement", lines)
+
+	if !bytes.Contains(doc, []byte("/Count 3 >>")) {
+		t.Error("expected the lines to fill 3 pages")
+	}
+	if !bytes.Contains(doc, []byte("(Page 3 of 3)")) {
+		t.Error("expected page numbers on long documents")
+	}
+}
@@ -0,0 +1,55 @@
+// products/lookup.go
+package products
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// cacheTTL is how long a cached product is trusted before it is fetched again
+const cacheTTL = 30 * 24 * time.Hour
+
+// client is the product database used for lookups
+var client = NewOpenFoodFactsClient()
+
+// Resolve returns the product for a barcode, from the products collection when cached recently
+// and from the product database otherwise. Fresh results are written back to the cache.
+func Resolve(ctx context.Context, barcode string) (*models.Product, error) {
+	collection := config.DB.Collection("products")
+
+	var cached models.Product
+	err := collection.FindOne(ctx, bson.M{"barcode": barcode}).Decode(&cached)
+	if err == nil && time.Since(cached.FetchedAt) < cacheTTL {
+		return &cached, nil
+	}
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	product, lookupErr := client.Lookup(ctx, barcode)
+	if lookupErr != nil {
+		// Serve a stale entry rather than nothing when the product database is unavailable
+		if err == nil && !errors.Is(lookupErr, ErrProductNotFound) {
+			return &cached, nil
+		}
+		return nil, lookupErr
+	}
+
+	err = collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"barcode": barcode},
+		bson.M{"$set": product},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(product)
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
@@ -0,0 +1,97 @@
+// products/openfoodfacts.go
+package products
+
+import (
+	"context"
+	"cribb-backend/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrProductNotFound is returned when no product is known for a barcode
+var ErrProductNotFound = errors.New("product not found")
+
+// defaultOpenFoodFactsURL is used unless OPENFOODFACTS_BASE_URL is set
+const defaultOpenFoodFactsURL = "https://world.openfoodfacts.org"
+
+// OpenFoodFactsClient looks up products in the Open Food Facts database
+type OpenFoodFactsClient struct {
+	BaseURL    string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// NewOpenFoodFactsClient creates a client for the Open Food Facts API
+func NewOpenFoodFactsClient() *OpenFoodFactsClient {
+	baseURL := os.Getenv("OPENFOODFACTS_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultOpenFoodFactsURL
+	}
+	return &OpenFoodFactsClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		// Open Food Facts asks clients to identify themselves
+		UserAgent:  "CribbRoommate/1.0",
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// openFoodFactsResponse is the subset of the product API response we use
+type openFoodFactsResponse struct {
+	Status  int `json:"status"`
+	Product struct {
+		ProductName    string   `json:"product_name"`
+		Brands         string   `json:"brands"`
+		CategoriesTags []string `json:"categories_tags"`
+		Quantity       string   `json:"quantity"`
+	} `json:"product"`
+}
+
+// Lookup fetches the product with the given barcode
+func (c *OpenFoodFactsClient) Lookup(ctx context.Context, barcode string) (*models.Product, error) {
+	url := fmt.Sprintf("%s/api/v2/product/%s.json?fields=product_name,brands,categories_tags,quantity", c.BaseURL, barcode)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrProductNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("open food facts returned status %d", resp.StatusCode)
+	}
+
+	var body openFoodFactsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Status != 1 || body.Product.ProductName == "" {
+		return nil, ErrProductNotFound
+	}
+
+	brand := strings.TrimSpace(strings.Split(body.Product.Brands, ",")[0])
+	quantity, unit, _ := models.ParseQuantity(body.Product.Quantity)
+
+	return &models.Product{
+		Barcode:   barcode,
+		Name:      strings.TrimSpace(body.Product.ProductName),
+		Brand:     brand,
+		Category:  models.CategoryFromTags(body.Product.CategoriesTags),
+		Quantity:  quantity,
+		Unit:      unit,
+		Source:    "openfoodfacts",
+		FetchedAt: time.Now(),
+	}, nil
+}
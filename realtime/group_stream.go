@@ -0,0 +1,228 @@
+// realtime/group_stream.go
+package realtime
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GroupHub fans group events out to the subscribers of each group
+type GroupHub struct {
+	mu          sync.RWMutex
+	subscribers map[primitive.ObjectID]map[chan models.GroupEvent]struct{}
+}
+
+// Groups is the hub fed by the group events change stream
+var Groups = NewGroupHub()
+
+// NewGroupHub creates an empty hub
+func NewGroupHub() *GroupHub {
+	return &GroupHub{subscribers: make(map[primitive.ObjectID]map[chan models.GroupEvent]struct{})}
+}
+
+// Subscribe registers for a group's events. The returned function unsubscribes and closes the channel.
+func (h *GroupHub) Subscribe(groupID primitive.ObjectID) (<-chan models.GroupEvent, func()) {
+	events := make(chan models.GroupEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[groupID] == nil {
+		h.subscribers[groupID] = make(map[chan models.GroupEvent]struct{})
+	}
+	h.subscribers[groupID][events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[groupID], events)
+			if len(h.subscribers[groupID]) == 0 {
+				delete(h.subscribers, groupID)
+			}
+			h.mu.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish delivers an event to the group's subscribers without blocking; subscribers whose
+// buffer is full miss the event and catch up by refetching
+func (h *GroupHub) Publish(event models.GroupEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events := range h.subscribers[event.GroupID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// HasSubscribers reports whether anyone is watching the group
+func (h *GroupHub) HasSubscribers(groupID primitive.ObjectID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[groupID]) > 0
+}
+
+// groupEventsPipeline matches the changes streamed as group events: chores being completed, items
+// added to the shopping list, new expenses, chat and direct messages, members typing in the chat
+// and kudos
+var groupEventsPipeline = mongo.Pipeline{{{Key: "$match", Value: bson.M{"$or": bson.A{
+	bson.M{
+		"ns.coll":                                "chores",
+		"operationType":                          "update",
+		"updateDescription.updatedFields.status": models.ChoreStatusCompleted,
+	},
+	bson.M{
+		"ns.coll":             "shopping_cart_activity",
+		"operationType":       "insert",
+		"fullDocument.action": models.CartActivityTypeAdd,
+	},
+	bson.M{
+		"ns.coll":       "expenses",
+		"operationType": "insert",
+	},
+	bson.M{
+		"ns.coll":       "direct_messages",
+		"operationType": "insert",
+	},
+	bson.M{
+		"ns.coll":       "chat_messages",
+		"operationType": "insert",
+	},
+	bson.M{
+		"ns.coll":       "chat_typing",
+		"operationType": bson.M{"$in": bson.A{"insert", "update"}},
+	},
+	bson.M{
+		"ns.coll":       "kudos",
+		"operationType": "insert",
+	},
+}}}}}
+
+// StartGroupStream watches the database for chore, shopping, expense, chat, message and kudos
+// changes and publishes them to the Groups hub. Every instance runs its own stream since
+// subscribers are connected to a single instance. Change streams need a replica set; the watch is
+// retried if it cannot be opened.
+func StartGroupStream() {
+	log.Println("Starting group events change stream...")
+
+	go func() {
+		var resumeToken bson.Raw
+		for {
+			resumeToken = watchGroupEvents(resumeToken)
+			time.Sleep(10 * time.Second)
+		}
+	}()
+}
+
+// watchGroupEvents publishes group events until the stream fails, returning the last resume token
+func watchGroupEvents(resumeToken bson.Raw) bson.Raw {
+	ctx := context.Background()
+
+	// Updates only carry the changed fields, so look up the completed chore or the member typing
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+
+	stream, err := config.DB.Watch(ctx, groupEventsPipeline, opts)
+	if err != nil {
+		// The resume point may have aged out of the oplog, so start fresh next time
+		log.Printf("Failed to open group events change stream: %v", err)
+		return nil
+	}
+	defer stream.Close(ctx)
+
+	for stream.Next(ctx) {
+		var change struct {
+			Namespace struct {
+				Collection string `bson:"coll"`
+			} `bson:"ns"`
+			FullDocument bson.Raw `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("Failed to decode group change event: %v", err)
+			continue
+		}
+		resumeToken = stream.ResumeToken()
+		if change.FullDocument == nil {
+			continue // The chore or typing indicator was deleted before it could be looked up
+		}
+
+		event, err := groupEventFromChange(change.Namespace.Collection, change.FullDocument)
+		if err != nil {
+			log.Printf("Failed to decode %s change: %v", change.Namespace.Collection, err)
+			continue
+		}
+		if event.Type != "" && Groups.HasSubscribers(event.GroupID) {
+			Groups.Publish(event)
+		}
+	}
+
+	if err := stream.Err(); err != nil {
+		log.Printf("Group events change stream stopped: %v", err)
+	}
+	return resumeToken
+}
+
+// groupEventFromChange builds the group event for a changed document of the collection. Documents
+// of other collections build an event without a type.
+func groupEventFromChange(collection string, document bson.Raw) (models.GroupEvent, error) {
+	switch collection {
+	case "chores":
+		var chore models.Chore
+		if err := bson.Unmarshal(document, &chore); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.ChoreCompletedEvent(chore), nil
+	case "shopping_cart_activity":
+		var activity models.ShoppingCartActivity
+		if err := bson.Unmarshal(document, &activity); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.ItemAddedEvent(activity), nil
+	case "expenses":
+		var expense models.Expense
+		if err := bson.Unmarshal(document, &expense); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.ExpenseAddedEvent(expense), nil
+	case "direct_messages":
+		var message models.DirectMessage
+		if err := bson.Unmarshal(document, &message); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.MessageSentEvent(message), nil
+	case "chat_messages":
+		var message models.ChatMessage
+		if err := bson.Unmarshal(document, &message); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.ChatMessageEvent(message), nil
+	case "chat_typing":
+		var typing models.ChatTyping
+		if err := bson.Unmarshal(document, &typing); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.ChatTypingEvent(typing), nil
+	case "kudos":
+		var kudos models.Kudos
+		if err := bson.Unmarshal(document, &kudos); err != nil {
+			return models.GroupEvent{}, err
+		}
+		return models.KudosSentEvent(kudos), nil
+	}
+	return models.GroupEvent{}, nil
+}
@@ -0,0 +1,105 @@
+package realtime
+
+import (
+	"cribb-backend/models"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGroupHubPublish(t *testing.T) {
+	hub := NewGroupHub()
+	groupID, otherGroupID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	events, unsubscribe := hub.Subscribe(groupID)
+	other, unsubscribeOther := hub.Subscribe(otherGroupID)
+	defer unsubscribeOther()
+
+	hub.Publish(models.GroupEvent{Type: models.GroupEventExpenseAdded, GroupID: groupID})
+	select {
+	case event := <-events:
+		if event.Type != models.GroupEventExpenseAdded {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("expected the group's subscriber to receive the event")
+	}
+	select {
+	case event := <-other:
+		t.Errorf("other group received event: %+v", event)
+	default:
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if hub.HasSubscribers(groupID) {
+		t.Error("expected no subscribers after unsubscribing")
+	}
+	for i := 0; i < subscriberBuffer+1; i++ {
+		hub.Publish(models.GroupEvent{GroupID: otherGroupID})
+	}
+}
+
+func TestGroupEventFromChange(t *testing.T) {
+	groupID := primitive.NewObjectID()
+	now := time.Date(2025, 3, 7, 18, 0, 0, 0, time.UTC)
+
+	document, _ := bson.Marshal(models.Chore{GroupID: groupID, Title: "Dishes", Status: models.ChoreStatusCompleted, UpdatedAt: now})
+	event, err := groupEventFromChange("chores", document)
+	if err != nil || event.Type != models.GroupEventChoreCompleted || event.GroupID != groupID || !event.At.Equal(now) {
+		t.Errorf("unexpected chore event %+v: %v", event, err)
+	}
+	if chore, ok := event.Data.(models.Chore); !ok || chore.Title != "Dishes" {
+		t.Errorf("expected the chore as data, got %+v", event.Data)
+	}
+
+	document, _ = bson.Marshal(models.ShoppingCartActivity{GroupID: groupID, ItemName: "Milk", Action: models.CartActivityTypeAdd, CreatedAt: now})
+	event, err = groupEventFromChange("shopping_cart_activity", document)
+	if err != nil || event.Type != models.GroupEventItemAdded || event.GroupID != groupID {
+		t.Errorf("unexpected shopping event %+v: %v", event, err)
+	}
+	if shopping, ok := event.Data.(models.ShoppingEvent); !ok || shopping.ItemName != "Milk" {
+		t.Errorf("expected the shopping event as data, got %+v", event.Data)
+	}
+
+	document, _ = bson.Marshal(models.Expense{GroupID: groupID, Description: "Groceries", CreatedAt: now})
+	event, err = groupEventFromChange("expenses", document)
+	if err != nil || event.Type != models.GroupEventExpenseAdded || event.GroupID != groupID {
+		t.Errorf("unexpected expense event %+v: %v", event, err)
+	}
+
+	sender, recipient := primitive.NewObjectID(), primitive.NewObjectID()
+	document, _ = bson.Marshal(models.DirectMessage{GroupID: groupID, SenderID: sender, RecipientID: recipient, Body: "Swap Tuesday's dishes?", CreatedAt: now})
+	event, err = groupEventFromChange("direct_messages", document)
+	if err != nil || event.Type != models.GroupEventMessageSent || event.GroupID != groupID {
+		t.Errorf("unexpected message event %+v: %v", event, err)
+	}
+	if !event.VisibleTo(sender) || !event.VisibleTo(recipient) || event.VisibleTo(primitive.NewObjectID()) {
+		t.Error("expected the message to be visible only to the two members")
+	}
+
+	document, _ = bson.Marshal(models.ChatMessage{GroupID: groupID, SenderID: sender, Body: "Pizza tonight?", CreatedAt: now})
+	event, err = groupEventFromChange("chat_messages", document)
+	if err != nil || event.Type != models.GroupEventChatMessage || !event.VisibleTo(recipient) {
+		t.Errorf("unexpected chat event %+v: %v", event, err)
+	}
+
+	document, _ = bson.Marshal(models.ChatTyping{GroupID: groupID, UserID: sender, Name: "Sam", TypingAt: now})
+	event, err = groupEventFromChange("chat_typing", document)
+	if typing, ok := event.Data.(models.ChatTyping); err != nil || event.Type != models.GroupEventChatTyping || !ok || typing.Name != "Sam" {
+		t.Errorf("unexpected typing event %+v: %v", event, err)
+	}
+
+	document, _ = bson.Marshal(models.Kudos{GroupID: groupID, FromID: sender, ToID: recipient, Message: "Thanks for fixing the sink", Points: 2, CreatedAt: now})
+	event, err = groupEventFromChange("kudos", document)
+	if kudos, ok := event.Data.(models.Kudos); err != nil || event.Type != models.GroupEventKudosSent || !ok || kudos.Points != 2 || !event.VisibleTo(primitive.NewObjectID()) {
+		t.Errorf("unexpected kudos event %+v: %v", event, err)
+	}
+
+	document, _ = bson.Marshal(models.Expense{GroupID: groupID, Description: "Groceries", CreatedAt: now})
+	if event, err := groupEventFromChange("users", document); err != nil || event.Type != "" {
+		t.Errorf("expected other collections to be ignored, got %+v: %v", event, err)
+	}
+}
@@ -0,0 +1,146 @@
+// realtime/shopping_stream.go
+package realtime
+
+import (
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"log"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// subscriberBuffer is how many events a slow subscriber can fall behind before events are dropped for it
+const subscriberBuffer = 32
+
+// ShoppingHub fans shopping events out to the subscribers of each group
+type ShoppingHub struct {
+	mu          sync.RWMutex
+	subscribers map[primitive.ObjectID]map[chan models.ShoppingEvent]struct{}
+}
+
+// Shopping is the hub fed by the shopping activity change stream
+var Shopping = NewShoppingHub()
+
+// NewShoppingHub creates an empty hub
+func NewShoppingHub() *ShoppingHub {
+	return &ShoppingHub{subscribers: make(map[primitive.ObjectID]map[chan models.ShoppingEvent]struct{})}
+}
+
+// Subscribe registers for a group's events. The returned function unsubscribes and closes the channel.
+func (h *ShoppingHub) Subscribe(groupID primitive.ObjectID) (<-chan models.ShoppingEvent, func()) {
+	events := make(chan models.ShoppingEvent, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[groupID] == nil {
+		h.subscribers[groupID] = make(map[chan models.ShoppingEvent]struct{})
+	}
+	h.subscribers[groupID][events] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return events, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[groupID], events)
+			if len(h.subscribers[groupID]) == 0 {
+				delete(h.subscribers, groupID)
+			}
+			h.mu.Unlock()
+			close(events)
+		})
+	}
+}
+
+// Publish delivers an event to the group's subscribers without blocking; subscribers whose
+// buffer is full miss the event and catch up from the activity feed
+func (h *ShoppingHub) Publish(event models.ShoppingEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events := range h.subscribers[event.GroupID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// HasSubscribers reports whether anyone is watching the group
+func (h *ShoppingHub) HasSubscribers(groupID primitive.ObjectID) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers[groupID]) > 0
+}
+
+// StartShoppingStream watches new shopping cart activity and publishes it to the Shopping hub.
+// Every instance runs its own stream since subscribers are connected to a single instance.
+// Change streams need a replica set; the watch is retried if it cannot be opened.
+func StartShoppingStream() {
+	log.Println("Starting shopping change stream...")
+
+	go func() {
+		var resumeToken bson.Raw
+		for {
+			resumeToken = watchShoppingActivity(resumeToken)
+			time.Sleep(10 * time.Second)
+		}
+	}()
+}
+
+// watchShoppingActivity publishes activity inserts until the stream fails, returning the last resume token
+func watchShoppingActivity(resumeToken bson.Raw) bson.Raw {
+	ctx := context.Background()
+
+	opts := options.ChangeStream()
+	if resumeToken != nil {
+		opts.SetResumeAfter(resumeToken)
+	}
+
+	stream, err := config.DB.Collection("shopping_cart_activity").Watch(
+		ctx,
+		mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}},
+		opts,
+	)
+	if err != nil {
+		// The resume point may have aged out of the oplog, so start fresh next time
+		log.Printf("Failed to open shopping change stream: %v", err)
+		return nil
+	}
+	defer stream.Close(ctx)
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument models.ShoppingCartActivity `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			log.Printf("Failed to decode shopping change event: %v", err)
+			continue
+		}
+		resumeToken = stream.ResumeToken()
+
+		event := models.ShoppingEventFromActivity(change.FullDocument)
+		if !Shopping.HasSubscribers(event.GroupID) {
+			continue
+		}
+
+		if event.IncludesItem() {
+			var item models.ShoppingCartItem
+			err := config.DB.Collection("shopping_cart").FindOne(ctx, bson.M{"_id": event.ItemID}).Decode(&item)
+			if err == nil {
+				event.Item = &item
+			}
+		}
+		Shopping.Publish(event)
+	}
+
+	if err := stream.Err(); err != nil {
+		log.Printf("Shopping change stream stopped: %v", err)
+	}
+	return resumeToken
+}
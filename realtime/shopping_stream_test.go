@@ -0,0 +1,49 @@
+package realtime
+
+import (
+	"cribb-backend/models"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestShoppingHubPublish(t *testing.T) {
+	hub := NewShoppingHub()
+	groupID, otherGroupID := primitive.NewObjectID(), primitive.NewObjectID()
+
+	events, unsubscribe := hub.Subscribe(groupID)
+	other, unsubscribeOther := hub.Subscribe(otherGroupID)
+	defer unsubscribeOther()
+
+	hub.Publish(models.ShoppingEvent{Type: models.CartActivityTypePurchase, GroupID: groupID, ItemName: "Milk"})
+
+	select {
+	case event := <-events:
+		if event.ItemName != "Milk" || event.Type != models.CartActivityTypePurchase {
+			t.Errorf("unexpected event: %+v", event)
+		}
+	default:
+		t.Fatal("expected the group's subscriber to receive the event")
+	}
+
+	select {
+	case event := <-other:
+		t.Errorf("other group received event: %+v", event)
+	default:
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if hub.HasSubscribers(groupID) {
+		t.Error("expected no subscribers after unsubscribing")
+	}
+	if _, open := <-events; open {
+		t.Error("expected channel to be closed after unsubscribing")
+	}
+
+	// Publishing to a group nobody watches, or to a full subscriber, must not block
+	hub.Publish(models.ShoppingEvent{GroupID: groupID})
+	for i := 0; i < subscriberBuffer+1; i++ {
+		hub.Publish(models.ShoppingEvent{GroupID: otherGroupID})
+	}
+}
@@ -0,0 +1,275 @@
+// realtime/websocket.go
+package realtime
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The server side of the WebSocket protocol (RFC 6455) as far as streaming events needs it: the
+// server sends text messages and pings, answers the client's pings and close, and reads the
+// client's short text messages, such as typing indicators.
+
+// websocketGUID is appended to the client's key to prove the server speaks WebSocket
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	// PingInterval is how often the server pings connected clients to keep the connection open
+	PingInterval = 30 * time.Second
+
+	// readTimeout drops clients that have sent nothing, not even a pong, for two pings
+	readTimeout = 2*PingInterval + 10*time.Second
+
+	// writeTimeout drops clients that stop reading
+	writeTimeout = 10 * time.Second
+
+	// maxClientFrame caps frames from clients, which only send control frames and short messages
+	maxClientFrame = 4096
+)
+
+// Frame opcodes
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes
+const (
+	CloseNormal        = 1000
+	CloseGoingAway     = 1001
+	CloseProtocolError = 1002
+	CloseTooLarge      = 1009
+)
+
+var (
+	errProtocol      = errors.New("websocket protocol error")
+	errFrameTooLarge = errors.New("websocket frame too large")
+)
+
+// Conn is an upgraded WebSocket connection. Writes may be made from several goroutines; reads
+// belong to ReadLoop or ReadMessages.
+type Conn struct {
+	conn      net.Conn
+	reader    *bufio.Reader
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+}
+
+// AcceptKey returns the Sec-WebSocket-Accept value for a client's Sec-WebSocket-Key
+func AcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// headerHasToken reports whether a comma separated header contains the token, ignoring case
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Upgrade switches the request's connection to WebSocket. When the request is not a valid
+// WebSocket handshake it writes the error response itself and returns an error.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, errProtocol
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "Expected a WebSocket upgrade", http.StatusBadRequest)
+		return nil, errProtocol
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "Unsupported WebSocket version", http.StatusUpgradeRequired)
+		return nil, errProtocol
+	}
+	key := strings.TrimSpace(r.Header.Get("Sec-WebSocket-Key"))
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		http.Error(w, "Invalid WebSocket key", http.StatusBadRequest)
+		return nil, errProtocol
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSockets not supported", http.StatusInternalServerError)
+		return nil, errors.New("response writer cannot be hijacked")
+	}
+
+	netConn, buffered, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + AcceptKey(key) + "\r\n\r\n"
+	netConn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := netConn.Write([]byte(response)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	return &Conn{conn: netConn, reader: buffered.Reader}, nil
+}
+
+// writeFrame sends one unfragmented frame; servers never mask their frames
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch length := len(payload); {
+	case length < 126:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(header); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(payload)
+	return err
+}
+
+// WriteJSON sends the value as a text message
+func (c *Conn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.writeFrame(opText, data)
+}
+
+// Ping asks the client to answer, keeping the connection open through proxies
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// readFrame reads one frame from the client, reporting whether it is the final frame of its
+// message. Client frames must be masked.
+func (c *Conn) readFrame() (byte, bool, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.reader, head[:]); err != nil {
+		return 0, false, nil, err
+	}
+	opcode := head[0] & 0x0F
+	final := head[0]&0x80 != 0
+	if head[0]&0x70 != 0 || head[1]&0x80 == 0 {
+		return opcode, final, nil, errProtocol // Reserved bits set or an unmasked frame
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return 0, false, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return 0, false, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	if length > maxClientFrame {
+		return opcode, final, nil, errFrameTooLarge
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+		return 0, false, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, false, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, final, payload, nil
+}
+
+// ReadLoop reads from the client until it closes the connection or stops answering pings,
+// answering its pings and close. Messages from the client are ignored. It returns nil when the
+// client closed the connection cleanly.
+func (c *Conn) ReadLoop() error {
+	return c.ReadMessages(nil)
+}
+
+// ReadMessages works like ReadLoop but hands each text message from the client to handle. Clients
+// only send short messages, so fragmented ones are dropped rather than reassembled.
+func (c *Conn) ReadMessages(handle func(payload []byte)) error {
+	for {
+		c.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		opcode, final, payload, err := c.readFrame()
+		if err != nil {
+			switch {
+			case errors.Is(err, errFrameTooLarge):
+				c.CloseWithCode(CloseTooLarge)
+			case errors.Is(err, errProtocol):
+				c.CloseWithCode(CloseProtocolError)
+			}
+			return err
+		}
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		case opClose:
+			c.CloseWithCode(CloseNormal)
+			return nil
+		case opText:
+			if final && handle != nil {
+				handle(payload)
+			}
+		case opPong, opBinary, opContinuation:
+		default:
+			c.CloseWithCode(CloseProtocolError)
+			return errProtocol
+		}
+	}
+}
+
+// CloseWithCode sends a close frame with the code and closes the connection. Closing more than once
+// does nothing.
+func (c *Conn) CloseWithCode(code int) error {
+	var err error
+	c.closeOnce.Do(func() {
+		payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+		c.writeFrame(opClose, payload)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// Close closes the connection normally
+func (c *Conn) Close() error {
+	return c.CloseWithCode(CloseNormal)
+}
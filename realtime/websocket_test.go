@@ -0,0 +1,211 @@
+package realtime
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcceptKey(t *testing.T) {
+	// The example handshake of RFC 6455
+	if got := AcceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("unexpected accept key %q", got)
+	}
+}
+
+func TestUpgradeRejectsPlainRequests(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	if _, err := Upgrade(recorder, httptest.NewRequest(http.MethodGet, "/api/groups/events", nil)); err == nil {
+		t.Fatal("expected a request without upgrade headers to be rejected")
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", recorder.Code)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/groups/events", nil)
+	request.Header.Set("Connection", "keep-alive, Upgrade")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Sec-WebSocket-Version", "8")
+	recorder = httptest.NewRecorder()
+	Upgrade(recorder, request)
+	if recorder.Code != http.StatusUpgradeRequired || recorder.Header().Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("expected old versions to be told to use 13, got %d", recorder.Code)
+	}
+}
+
+// writeClientFrame sends a masked frame as a browser would
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatalf("failed to write frame: %v", err)
+	}
+}
+
+// readServerFrame reads an unmasked frame from the server
+func readServerFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		t.Fatalf("failed to read frame: %v", err)
+	}
+	if head[0]&0x80 == 0 || head[1]&0x80 != 0 {
+		t.Fatalf("expected a final unmasked frame, got % x", head)
+	}
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var extended [2]byte
+		io.ReadFull(reader, extended[:])
+		length = int(binary.BigEndian.Uint16(extended[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func TestWebSocketConversation(t *testing.T) {
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]string{"type": "chore.completed", "padding": strings.Repeat("x", 200)})
+		done <- conn.ReadLoop()
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	handshake := "GET /api/groups/events HTTP/1.1\r\n" +
+		"Host: cribb.test\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	conn.Write([]byte(handshake))
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols || response.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected handshake response %d %v", response.StatusCode, response.Header)
+	}
+
+	opcode, payload := readServerFrame(t, reader)
+	var message map[string]string
+	if opcode != opText || json.Unmarshal(payload, &message) != nil || message["type"] != "chore.completed" {
+		t.Fatalf("unexpected message %d %q", opcode, payload)
+	}
+
+	writeClientFrame(t, conn, opPing, []byte("hi"))
+	if opcode, payload := readServerFrame(t, reader); opcode != opPong || string(payload) != "hi" {
+		t.Errorf("expected the ping to be answered, got %d %q", opcode, payload)
+	}
+
+	writeClientFrame(t, conn, opClose, binary.BigEndian.AppendUint16(nil, CloseGoingAway))
+	if opcode, payload := readServerFrame(t, reader); opcode != opClose || binary.BigEndian.Uint16(payload) != CloseNormal {
+		t.Errorf("expected the close to be answered, got %d %v", opcode, payload)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean close, got %v", err)
+	}
+}
+
+func TestWebSocketRejectsUnmaskedFrames(t *testing.T) {
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		done <- conn.ReadLoop()
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: cribb.test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	if _, err := http.ReadResponse(reader, nil); err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+
+	conn.Write([]byte{0x80 | opText, 2, 'h', 'i'})
+	if opcode, payload := readServerFrame(t, reader); opcode != opClose || binary.BigEndian.Uint16(payload) != CloseProtocolError {
+		t.Errorf("expected a protocol error close, got %d %v", opcode, payload)
+	}
+	if err := <-done; err == nil {
+		t.Error("expected the read loop to fail")
+	}
+}
+
+func TestWebSocketReadsMessages(t *testing.T) {
+	received := make(chan string, 2)
+	done := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		done <- conn.ReadMessages(func(payload []byte) { received <- string(payload) })
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	conn.Write([]byte("GET / HTTP/1.1\r\nHost: cribb.test\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n"))
+	reader := bufio.NewReader(conn)
+	if _, err := http.ReadResponse(reader, nil); err != nil {
+		t.Fatalf("failed to read handshake response: %v", err)
+	}
+
+	// The first frame of a fragmented message is dropped
+	conn.Write([]byte{opText, 0x80 | 2, 0, 0, 0, 0, '{', '"'})
+	writeClientFrame(t, conn, opText, []byte(`{"type":"chat.typing"}`))
+	writeClientFrame(t, conn, opClose, binary.BigEndian.AppendUint16(nil, CloseNormal))
+	readServerFrame(t, reader)
+
+	if err := <-done; err != nil {
+		t.Errorf("expected a clean close, got %v", err)
+	}
+	close(received)
+	messages := make([]string, 0)
+	for message := range received {
+		messages = append(messages, message)
+	}
+	if len(messages) != 1 || messages[0] != `{"type":"chat.typing"}` {
+		t.Errorf("expected only the complete message, got %q", messages)
+	}
+}
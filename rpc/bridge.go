@@ -0,0 +1,110 @@
+// rpc/bridge.go
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// responseBuffer collects what a handler writes
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	if b.status == 0 {
+		b.status = http.StatusOK
+	}
+	return b.body.Write(data)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+// callHandler sends a request for target to api, the REST API's handler, as the calling member
+// from ctx, sending body as JSON, and decodes its JSON response into out. Error responses become
+// gRPC status errors.
+func callHandler(ctx context.Context, api http.Handler, method, target string, body, out interface{}) error {
+	_, err := serveHandler(ctx, api, method, target, body, out)
+	return err
+}
+
+// serveHandler is callHandler returning the response headers, which carry the Next-Cursor of lists
+func serveHandler(ctx context.Context, api http.Handler, method, target string, body, out interface{}) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "Failed to encode request")
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Failed to build request")
+	}
+	r.Header.Set("Content-Type", "application/json")
+	// The route's AuthMiddleware checks the member's JWT again, as for any REST request
+	if token, ok := ctx.Value(userTokenKey{}).(string); ok {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := &responseBuffer{header: make(http.Header)}
+	api.ServeHTTP(w, r)
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.status >= http.StatusBadRequest {
+		return nil, status.Error(codeForStatus(w.status), strings.TrimSpace(w.body.String()))
+	}
+	if out == nil {
+		return w.header, nil
+	}
+	if err := json.Unmarshal(w.body.Bytes(), out); err != nil {
+		return nil, status.Error(codes.Internal, "Failed to decode response")
+	}
+	return w.header, nil
+}
+
+// codeForStatus maps an HTTP error status to the closest gRPC code
+func codeForStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
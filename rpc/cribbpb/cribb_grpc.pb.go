@@ -0,0 +1,443 @@
+// Internal gRPC API for other services, served by the rpc package.
+//
+// Regenerate the Go code after editing with protoc-gen-go and protoc-gen-go-grpc:
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative rpc/cribbpb/cribb.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: rpc/cribbpb/cribb.proto
+
+package cribbpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ChoreService_CreateChore_FullMethodName     = "/cribb.v1.ChoreService/CreateChore"
+	ChoreService_ListUserChores_FullMethodName  = "/cribb.v1.ChoreService/ListUserChores"
+	ChoreService_ListGroupChores_FullMethodName = "/cribb.v1.ChoreService/ListGroupChores"
+	ChoreService_UpdateChore_FullMethodName     = "/cribb.v1.ChoreService/UpdateChore"
+	ChoreService_DeleteChore_FullMethodName     = "/cribb.v1.ChoreService/DeleteChore"
+	ChoreService_CompleteChore_FullMethodName   = "/cribb.v1.ChoreService/CompleteChore"
+)
+
+// ChoreServiceClient is the client API for ChoreService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChoreService creates, edits and completes chores. Calls act for the member
+// whose JWT is in the user-token metadata, see the rpc package.
+type ChoreServiceClient interface {
+	// CreateChore creates a one-off chore assigned to a member
+	CreateChore(ctx context.Context, in *CreateChoreRequest, opts ...grpc.CallOption) (*Chore, error)
+	// ListUserChores lists the chores a member has yet to complete
+	ListUserChores(ctx context.Context, in *ListUserChoresRequest, opts ...grpc.CallOption) (*ListChoresResponse, error)
+	// ListGroupChores lists the chores of a group that are yet to be completed
+	ListGroupChores(ctx context.Context, in *ListGroupChoresRequest, opts ...grpc.CallOption) (*ListChoresResponse, error)
+	// UpdateChore edits a chore that is not completed yet
+	UpdateChore(ctx context.Context, in *UpdateChoreRequest, opts ...grpc.CallOption) (*Chore, error)
+	// DeleteChore deletes a chore
+	DeleteChore(ctx context.Context, in *DeleteChoreRequest, opts ...grpc.CallOption) (*DeleteChoreResponse, error)
+	// CompleteChore marks a chore completed and awards its points
+	CompleteChore(ctx context.Context, in *CompleteChoreRequest, opts ...grpc.CallOption) (*CompleteChoreResponse, error)
+}
+
+type choreServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChoreServiceClient(cc grpc.ClientConnInterface) ChoreServiceClient {
+	return &choreServiceClient{cc}
+}
+
+func (c *choreServiceClient) CreateChore(ctx context.Context, in *CreateChoreRequest, opts ...grpc.CallOption) (*Chore, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chore)
+	err := c.cc.Invoke(ctx, ChoreService_CreateChore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *choreServiceClient) ListUserChores(ctx context.Context, in *ListUserChoresRequest, opts ...grpc.CallOption) (*ListChoresResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChoresResponse)
+	err := c.cc.Invoke(ctx, ChoreService_ListUserChores_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *choreServiceClient) ListGroupChores(ctx context.Context, in *ListGroupChoresRequest, opts ...grpc.CallOption) (*ListChoresResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChoresResponse)
+	err := c.cc.Invoke(ctx, ChoreService_ListGroupChores_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *choreServiceClient) UpdateChore(ctx context.Context, in *UpdateChoreRequest, opts ...grpc.CallOption) (*Chore, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chore)
+	err := c.cc.Invoke(ctx, ChoreService_UpdateChore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *choreServiceClient) DeleteChore(ctx context.Context, in *DeleteChoreRequest, opts ...grpc.CallOption) (*DeleteChoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteChoreResponse)
+	err := c.cc.Invoke(ctx, ChoreService_DeleteChore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *choreServiceClient) CompleteChore(ctx context.Context, in *CompleteChoreRequest, opts ...grpc.CallOption) (*CompleteChoreResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteChoreResponse)
+	err := c.cc.Invoke(ctx, ChoreService_CompleteChore_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChoreServiceServer is the server API for ChoreService service.
+// All implementations must embed UnimplementedChoreServiceServer
+// for forward compatibility.
+//
+// ChoreService creates, edits and completes chores. Calls act for the member
+// whose JWT is in the user-token metadata, see the rpc package.
+type ChoreServiceServer interface {
+	// CreateChore creates a one-off chore assigned to a member
+	CreateChore(context.Context, *CreateChoreRequest) (*Chore, error)
+	// ListUserChores lists the chores a member has yet to complete
+	ListUserChores(context.Context, *ListUserChoresRequest) (*ListChoresResponse, error)
+	// ListGroupChores lists the chores of a group that are yet to be completed
+	ListGroupChores(context.Context, *ListGroupChoresRequest) (*ListChoresResponse, error)
+	// UpdateChore edits a chore that is not completed yet
+	UpdateChore(context.Context, *UpdateChoreRequest) (*Chore, error)
+	// DeleteChore deletes a chore
+	DeleteChore(context.Context, *DeleteChoreRequest) (*DeleteChoreResponse, error)
+	// CompleteChore marks a chore completed and awards its points
+	CompleteChore(context.Context, *CompleteChoreRequest) (*CompleteChoreResponse, error)
+	mustEmbedUnimplementedChoreServiceServer()
+}
+
+// UnimplementedChoreServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChoreServiceServer struct{}
+
+func (UnimplementedChoreServiceServer) CreateChore(context.Context, *CreateChoreRequest) (*Chore, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateChore not implemented")
+}
+func (UnimplementedChoreServiceServer) ListUserChores(context.Context, *ListUserChoresRequest) (*ListChoresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUserChores not implemented")
+}
+func (UnimplementedChoreServiceServer) ListGroupChores(context.Context, *ListGroupChoresRequest) (*ListChoresResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroupChores not implemented")
+}
+func (UnimplementedChoreServiceServer) UpdateChore(context.Context, *UpdateChoreRequest) (*Chore, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateChore not implemented")
+}
+func (UnimplementedChoreServiceServer) DeleteChore(context.Context, *DeleteChoreRequest) (*DeleteChoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteChore not implemented")
+}
+func (UnimplementedChoreServiceServer) CompleteChore(context.Context, *CompleteChoreRequest) (*CompleteChoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteChore not implemented")
+}
+func (UnimplementedChoreServiceServer) mustEmbedUnimplementedChoreServiceServer() {}
+func (UnimplementedChoreServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeChoreServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChoreServiceServer will
+// result in compilation errors.
+type UnsafeChoreServiceServer interface {
+	mustEmbedUnimplementedChoreServiceServer()
+}
+
+func RegisterChoreServiceServer(s grpc.ServiceRegistrar, srv ChoreServiceServer) {
+	// If the following call pancis, it indicates UnimplementedChoreServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChoreService_ServiceDesc, srv)
+}
+
+func _ChoreService_CreateChore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateChoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).CreateChore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_CreateChore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).CreateChore(ctx, req.(*CreateChoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChoreService_ListUserChores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserChoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).ListUserChores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_ListUserChores_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).ListUserChores(ctx, req.(*ListUserChoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChoreService_ListGroupChores_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupChoresRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).ListGroupChores(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_ListGroupChores_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).ListGroupChores(ctx, req.(*ListGroupChoresRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChoreService_UpdateChore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateChoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).UpdateChore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_UpdateChore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).UpdateChore(ctx, req.(*UpdateChoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChoreService_DeleteChore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteChoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).DeleteChore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_DeleteChore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).DeleteChore(ctx, req.(*DeleteChoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChoreService_CompleteChore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteChoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChoreServiceServer).CompleteChore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChoreService_CompleteChore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChoreServiceServer).CompleteChore(ctx, req.(*CompleteChoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChoreService_ServiceDesc is the grpc.ServiceDesc for ChoreService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChoreService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cribb.v1.ChoreService",
+	HandlerType: (*ChoreServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateChore",
+			Handler:    _ChoreService_CreateChore_Handler,
+		},
+		{
+			MethodName: "ListUserChores",
+			Handler:    _ChoreService_ListUserChores_Handler,
+		},
+		{
+			MethodName: "ListGroupChores",
+			Handler:    _ChoreService_ListGroupChores_Handler,
+		},
+		{
+			MethodName: "UpdateChore",
+			Handler:    _ChoreService_UpdateChore_Handler,
+		},
+		{
+			MethodName: "DeleteChore",
+			Handler:    _ChoreService_DeleteChore_Handler,
+		},
+		{
+			MethodName: "CompleteChore",
+			Handler:    _ChoreService_CompleteChore_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rpc/cribbpb/cribb.proto",
+}
+
+const (
+	BalanceService_GetGroupBalances_FullMethodName = "/cribb.v1.BalanceService/GetGroupBalances"
+)
+
+// BalanceServiceClient is the client API for BalanceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BalanceService reports who owes whom in a group
+type BalanceServiceClient interface {
+	// GetGroupBalances nets the group's expenses against its settlements
+	GetGroupBalances(ctx context.Context, in *GetGroupBalancesRequest, opts ...grpc.CallOption) (*GroupBalances, error)
+}
+
+type balanceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBalanceServiceClient(cc grpc.ClientConnInterface) BalanceServiceClient {
+	return &balanceServiceClient{cc}
+}
+
+func (c *balanceServiceClient) GetGroupBalances(ctx context.Context, in *GetGroupBalancesRequest, opts ...grpc.CallOption) (*GroupBalances, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupBalances)
+	err := c.cc.Invoke(ctx, BalanceService_GetGroupBalances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BalanceServiceServer is the server API for BalanceService service.
+// All implementations must embed UnimplementedBalanceServiceServer
+// for forward compatibility.
+//
+// BalanceService reports who owes whom in a group
+type BalanceServiceServer interface {
+	// GetGroupBalances nets the group's expenses against its settlements
+	GetGroupBalances(context.Context, *GetGroupBalancesRequest) (*GroupBalances, error)
+	mustEmbedUnimplementedBalanceServiceServer()
+}
+
+// UnimplementedBalanceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBalanceServiceServer struct{}
+
+func (UnimplementedBalanceServiceServer) GetGroupBalances(context.Context, *GetGroupBalancesRequest) (*GroupBalances, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGroupBalances not implemented")
+}
+func (UnimplementedBalanceServiceServer) mustEmbedUnimplementedBalanceServiceServer() {}
+func (UnimplementedBalanceServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeBalanceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BalanceServiceServer will
+// result in compilation errors.
+type UnsafeBalanceServiceServer interface {
+	mustEmbedUnimplementedBalanceServiceServer()
+}
+
+func RegisterBalanceServiceServer(s grpc.ServiceRegistrar, srv BalanceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBalanceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BalanceService_ServiceDesc, srv)
+}
+
+func _BalanceService_GetGroupBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupBalancesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BalanceServiceServer).GetGroupBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BalanceService_GetGroupBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BalanceServiceServer).GetGroupBalances(ctx, req.(*GetGroupBalancesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BalanceService_ServiceDesc is the grpc.ServiceDesc for BalanceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BalanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "cribb.v1.BalanceService",
+	HandlerType: (*BalanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGroupBalances",
+			Handler:    _BalanceService_GetGroupBalances_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "rpc/cribbpb/cribb.proto",
+}
@@ -0,0 +1,101 @@
+// rpc/server.go
+//
+// Package rpc serves the internal gRPC API defined in cribbpb/cribb.proto for other services such
+// as analytics. Every call carries the shared GRPC_API_KEY as a bearer token and may act for a
+// member by passing the JWT that member logged in with in the user-token metadata. The services
+// send their requests through the REST API's own routes and middleware, so authentication,
+// validation, transactions, notifications and webhooks behave identically over both.
+package rpc
+
+import (
+	"context"
+	"cribb-backend/middleware"
+	"cribb-backend/rpc/cribbpb"
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UserTokenMetadata names the metadata key holding the JWT of the member a call acts for
+const UserTokenMetadata = "user-token"
+
+// userTokenKey is the context key of the member's JWT, which bridged requests send on
+type userTokenKey struct{}
+
+// Start serves the gRPC API on GRPC_PORT when both it and GRPC_API_KEY are set, answering calls
+// through api, the REST API's handler
+func Start(api http.Handler) {
+	port := strings.TrimSpace(os.Getenv("GRPC_PORT"))
+	if port == "" {
+		log.Println("gRPC server disabled: GRPC_PORT is not set")
+		return
+	}
+	apiKey := strings.TrimSpace(os.Getenv("GRPC_API_KEY"))
+	if apiKey == "" {
+		log.Println("gRPC server disabled: GRPC_API_KEY is not set")
+		return
+	}
+
+	listener, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		log.Printf("gRPC server disabled: %v", err)
+		return
+	}
+
+	server := NewServer(apiKey, api)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			log.Printf("gRPC server stopped: %v", err)
+		}
+	}()
+	log.Printf("gRPC server listening on port %s", port)
+}
+
+// NewServer returns a gRPC server with the chore and balance services that accepts calls
+// authenticated with apiKey and serves them through api
+func NewServer(apiKey string, api http.Handler) *grpc.Server {
+	server := grpc.NewServer(grpc.UnaryInterceptor(authInterceptor(apiKey)))
+	cribbpb.RegisterChoreServiceServer(server, choreService{api: api})
+	cribbpb.RegisterBalanceServiceServer(server, balanceService{api: api})
+	return server
+}
+
+// authInterceptor rejects calls without the API key and puts the member whose JWT is in the
+// user-token metadata in the context, the way middleware.AuthMiddleware does for HTTP requests
+func authInterceptor(apiKey string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		token := strings.TrimPrefix(firstValue(md, "authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(apiKey)) != 1 {
+			return nil, status.Error(codes.Unauthenticated, "Invalid API key")
+		}
+
+		if userToken := firstValue(md, UserTokenMetadata); userToken != "" {
+			claims, err := middleware.ParseToken(userToken)
+			if err != nil {
+				return nil, status.Error(codes.Unauthenticated, "Invalid user token")
+			}
+			ctx = context.WithValue(ctx, middleware.UserContextKey, claims)
+			ctx = context.WithValue(ctx, userTokenKey{}, userToken)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// firstValue returns the first value of the metadata key, or an empty string
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
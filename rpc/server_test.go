@@ -0,0 +1,117 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"cribb-backend/handlers"
+	"cribb-backend/middleware"
+	"cribb-backend/rpc/cribbpb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T) cribbpb.ChoreServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	// The chore route as main.go registers it
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/chores/delete", middleware.AuthMiddleware(handlers.DeleteChoreHandler))
+	server := NewServer("secret", mux)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return cribbpb.NewChoreServiceClient(conn)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.DeleteChore(context.Background(), &cribbpb.DeleteChoreRequest{ChoreId: "nope"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected calls without the API key to be rejected, got %v", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	if _, err := client.DeleteChore(ctx, &cribbpb.DeleteChoreRequest{ChoreId: "nope"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a wrong API key to be rejected, got %v", err)
+	}
+
+	// Without a member the route's AuthMiddleware turns the call away
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.DeleteChore(ctx, &cribbpb.DeleteChoreRequest{ChoreId: "nope"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected calls without a user token to be rejected, got %v", err)
+	}
+
+	if _, err := client.DeleteChore(metadata.AppendToOutgoingContext(ctx, UserTokenMetadata, "nope"), &cribbpb.DeleteChoreRequest{ChoreId: "nope"}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected a malformed user token to be rejected, got %v", err)
+	}
+
+	// The handler rejects the malformed ID before touching the database
+	token := handlers.GenerateJWTToken(primitive.NewObjectID().Hex(), "alice")
+	_, err = client.DeleteChore(metadata.AppendToOutgoingContext(ctx, UserTokenMetadata, token), &cribbpb.DeleteChoreRequest{ChoreId: "nope"})
+	if status.Code(err) != codes.InvalidArgument || status.Convert(err).Message() != "Invalid chore ID format" {
+		t.Errorf("expected the handler's error as invalid argument, got %v", err)
+	}
+}
+
+func TestCallHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "Authorization header is required", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"points_earned":5}`))
+	})
+
+	var result struct {
+		PointsEarned int `json:"points_earned"`
+	}
+	if err := callHandler(context.Background(), handler, http.MethodPost, "/", map[string]string{}, &result); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected calls without a member to be unauthenticated, got %v", err)
+	}
+
+	// The member's token is sent on to the route's AuthMiddleware
+	ctx := context.WithValue(context.Background(), userTokenKey{}, "token")
+	if err := callHandler(ctx, handler, http.MethodPost, "/", map[string]string{}, &result); err != nil || result.PointsEarned != 5 {
+		t.Errorf("expected the decoded response, got %+v and %v", result, err)
+	}
+
+	err := callHandler(ctx, handler, http.MethodGet, "/", nil, nil)
+	if status.Code(err) != codes.Unimplemented || status.Convert(err).Message() != "Method not allowed" {
+		t.Errorf("expected the error status to map to a gRPC code, got %v", err)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	if toTimestamp(time.Time{}) != nil || !fromTimestamp(nil).IsZero() {
+		t.Error("expected unset times to stay unset")
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if !fromTimestamp(toTimestamp(now)).Equal(now) {
+		t.Error("expected times to round trip")
+	}
+}
@@ -0,0 +1,226 @@
+// rpc/services.go
+package rpc
+
+import (
+	"context"
+	"cribb-backend/handlers"
+	"cribb-backend/middleware"
+	"cribb-backend/models"
+	"cribb-backend/rpc/cribbpb"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// choreService implements cribbpb.ChoreServiceServer over the chore routes of api
+type choreService struct {
+	cribbpb.UnimplementedChoreServiceServer
+	api http.Handler
+}
+
+func (s choreService) CreateChore(ctx context.Context, req *cribbpb.CreateChoreRequest) (*cribbpb.Chore, error) {
+	var chore models.Chore
+	err := callHandler(ctx, s.api, http.MethodPost, "/api/chores/individual", handlers.CreateIndividualChoreRequest{
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		GroupName:   req.GetGroupName(),
+		AssignedTo:  req.GetAssignedTo(),
+		DueDate:     fromTimestamp(req.GetDueDate()),
+		Points:      int(req.GetPoints()),
+	}, &chore)
+	if err != nil {
+		return nil, err
+	}
+	return choreToProto(chore, ""), nil
+}
+
+func (s choreService) ListUserChores(ctx context.Context, req *cribbpb.ListUserChoresRequest) (*cribbpb.ListChoresResponse, error) {
+	var chores []models.Chore
+	query := pageQuery(req.GetLimit(), req.GetCursor())
+	query.Set("username", req.GetUsername())
+	header, err := serveHandler(ctx, s.api, http.MethodGet, "/api/chores/user?"+query.Encode(), nil, &chores)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &cribbpb.ListChoresResponse{Chores: make([]*cribbpb.Chore, 0, len(chores)), NextCursor: header.Get("Next-Cursor")}
+	for _, chore := range chores {
+		response.Chores = append(response.Chores, choreToProto(chore, ""))
+	}
+	return response, nil
+}
+
+func (s choreService) ListGroupChores(ctx context.Context, req *cribbpb.ListGroupChoresRequest) (*cribbpb.ListChoresResponse, error) {
+	var chores []struct {
+		models.Chore
+		AssigneeName string `json:"assignee_name"`
+	}
+	query := pageQuery(req.GetLimit(), req.GetCursor())
+	query.Set("group_name", req.GetGroupName())
+	header, err := serveHandler(ctx, s.api, http.MethodGet, "/api/chores/group?"+query.Encode(), nil, &chores)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &cribbpb.ListChoresResponse{Chores: make([]*cribbpb.Chore, 0, len(chores)), NextCursor: header.Get("Next-Cursor")}
+	for _, chore := range chores {
+		response.Chores = append(response.Chores, choreToProto(chore.Chore, chore.AssigneeName))
+	}
+	return response, nil
+}
+
+func (s choreService) UpdateChore(ctx context.Context, req *cribbpb.UpdateChoreRequest) (*cribbpb.Chore, error) {
+	var chore models.Chore
+	err := callHandler(ctx, s.api, http.MethodPut, "/api/chores/update", handlers.UpdateChoreRequest{
+		ChoreID:     req.GetChoreId(),
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		AssignedTo:  req.GetAssignedTo(),
+		DueDate:     fromTimestamp(req.GetDueDate()),
+		Points:      int(req.GetPoints()),
+	}, &chore)
+	if err != nil {
+		return nil, err
+	}
+	return choreToProto(chore, ""), nil
+}
+
+func (s choreService) DeleteChore(ctx context.Context, req *cribbpb.DeleteChoreRequest) (*cribbpb.DeleteChoreResponse, error) {
+	var result struct {
+		Message string `json:"message"`
+	}
+	target := "/api/chores/delete?" + url.Values{"chore_id": {req.GetChoreId()}}.Encode()
+	if err := callHandler(ctx, s.api, http.MethodDelete, target, nil, &result); err != nil {
+		return nil, err
+	}
+	return &cribbpb.DeleteChoreResponse{Message: result.Message}, nil
+}
+
+func (s choreService) CompleteChore(ctx context.Context, req *cribbpb.CompleteChoreRequest) (*cribbpb.CompleteChoreResponse, error) {
+	// The calling member completes the chore unless the request names someone else
+	userID := req.GetUserId()
+	if claims, ok := middleware.GetUserFromContext(ctx); ok && userID == "" {
+		userID = claims.ID
+	}
+
+	var result struct {
+		PointsEarned int `json:"points_earned"`
+		NewScore     int `json:"new_score"`
+	}
+	err := callHandler(ctx, s.api, http.MethodPost, "/api/chores/complete", handlers.CompleteChoreRequest{
+		ChoreID: req.GetChoreId(),
+		UserID:  userID,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &cribbpb.CompleteChoreResponse{PointsEarned: int32(result.PointsEarned), NewScore: int32(result.NewScore)}, nil
+}
+
+// balanceService implements cribbpb.BalanceServiceServer over the settlement routes of api
+type balanceService struct {
+	cribbpb.UnimplementedBalanceServiceServer
+	api http.Handler
+}
+
+func (s balanceService) GetGroupBalances(ctx context.Context, req *cribbpb.GetGroupBalancesRequest) (*cribbpb.GroupBalances, error) {
+	var result handlers.GroupBalancesResponse
+	target := "/api/groups/" + url.PathEscape(req.GetGroupId()) + "/balances"
+	if err := callHandler(ctx, s.api, http.MethodGet, target, nil, &result); err != nil {
+		return nil, err
+	}
+
+	response := &cribbpb.GroupBalances{
+		Currency: result.Currency,
+		Balances: make([]*cribbpb.MemberBalance, 0, len(result.Balances)),
+		Debts:    debtsToProto(result.Debts),
+		Pairwise: debtsToProto(result.Pairwise),
+	}
+	for _, balance := range result.Balances {
+		response.Balances = append(response.Balances, &cribbpb.MemberBalance{
+			UserId:   hexID(balance.UserID),
+			Name:     balance.Name,
+			Paid:     balance.Paid,
+			Share:    balance.Share,
+			Sent:     balance.Sent,
+			Received: balance.Received,
+			Net:      balance.Net,
+		})
+	}
+	return response, nil
+}
+
+// pageQuery returns the query parameters of a list page, leaving out unset ones
+func pageQuery(limit int32, cursor string) url.Values {
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(int(limit)))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	return query
+}
+
+// choreToProto converts a chore to its message
+func choreToProto(chore models.Chore, assigneeName string) *cribbpb.Chore {
+	return &cribbpb.Chore{
+		Id:           hexID(chore.ID),
+		Title:        chore.Title,
+		Description:  chore.Description,
+		Type:         string(chore.Type),
+		GroupId:      hexID(chore.GroupID),
+		AssignedTo:   hexID(chore.AssignedTo),
+		AssigneeName: assigneeName,
+		Status:       string(chore.Status),
+		Points:       int32(chore.Points),
+		StartDate:    toTimestamp(chore.StartDate),
+		DueDate:      toTimestamp(chore.DueDate),
+		RecurringId:  hexID(chore.RecurringID),
+		CreatedAt:    toTimestamp(chore.CreatedAt),
+		UpdatedAt:    toTimestamp(chore.UpdatedAt),
+	}
+}
+
+// debtsToProto converts debts to their messages
+func debtsToProto(debts []models.Debt) []*cribbpb.Debt {
+	messages := make([]*cribbpb.Debt, 0, len(debts))
+	for _, debt := range debts {
+		messages = append(messages, &cribbpb.Debt{
+			FromUserId: hexID(debt.From),
+			FromName:   debt.FromName,
+			ToUserId:   hexID(debt.To),
+			ToName:     debt.ToName,
+			Amount:     debt.Amount,
+		})
+	}
+	return messages
+}
+
+// hexID returns the ID as hex, or an empty string when it is unset
+func hexID(id primitive.ObjectID) string {
+	if id.IsZero() {
+		return ""
+	}
+	return id.Hex()
+}
+
+// toTimestamp converts t, leaving unset times unset
+func toTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromTimestamp converts ts, reading an unset timestamp as the zero time
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,77 @@
+// sms/sms.go
+package sms
+
+import (
+	"context"
+	"cribb-backend/models"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrDisabled is returned when a text is sent while no provider is configured
+var ErrDisabled = errors.New("sms is not configured")
+
+// Provider delivers text messages to phone numbers in E.164 format
+type Provider interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+var (
+	// provider delivers texts; nil turns SMS off
+	provider Provider
+
+	// defaultCountryCode is added to stored phone numbers without one (SMS_DEFAULT_COUNTRY_CODE)
+	defaultCountryCode string
+)
+
+// SetProvider replaces how texts are delivered; nil turns SMS off
+func SetProvider(p Provider) {
+	provider = p
+}
+
+// Enabled reports whether texts are delivered
+func Enabled() bool {
+	return provider != nil
+}
+
+// Configure sets up delivery through Twilio from the environment, see NewTwilioClientFromEnv.
+// SMS stays off when Twilio is not configured.
+func Configure() {
+	defaultCountryCode = strings.TrimPrefix(strings.TrimSpace(os.Getenv("SMS_DEFAULT_COUNTRY_CODE")), "+")
+
+	client, err := NewTwilioClientFromEnv()
+	if err != nil {
+		log.Printf("SMS disabled: %v", err)
+		return
+	}
+	if client == nil {
+		log.Println("SMS disabled: TWILIO_ACCOUNT_SID is not set")
+		return
+	}
+	SetProvider(client)
+	log.Println("SMS enabled through Twilio")
+}
+
+// SendToUser texts the member about the event if they opted in to SMS alerts, want texts for the
+// event, are outside their quiet hours and have a usable phone number. It reports whether a text
+// was sent.
+func SendToUser(ctx context.Context, user models.User, event models.NotificationEvent, body string, now time.Time) (bool, error) {
+	if provider == nil {
+		return false, nil
+	}
+	preferences := user.Preferences.Notifications
+	if !preferences.SMSAlerts || !preferences.Allows(event, models.NotificationChannelSMS) || preferences.QuietHours.Contains(now) {
+		return false, nil
+	}
+	number, ok := user.SMSNumber(defaultCountryCode)
+	if !ok {
+		return false, nil
+	}
+	if err := provider.Send(ctx, number, body); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,91 @@
+// sms/twilio.go
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultTwilioURL is used unless TWILIO_BASE_URL is set
+const defaultTwilioURL = "https://api.twilio.com"
+
+// TwilioClient sends texts through the Twilio Messages API
+type TwilioClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	AccountSID string
+	AuthToken  string
+	From       string // Sender number, or a messaging service SID starting with MG
+}
+
+// NewTwilioClient creates a client for the account sending from the number or messaging service
+func NewTwilioClient(accountSID, authToken, from string) (*TwilioClient, error) {
+	if accountSID == "" || authToken == "" || from == "" {
+		return nil, errors.New("twilio needs an account SID, auth token and sender")
+	}
+	baseURL := os.Getenv("TWILIO_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultTwilioURL
+	}
+	return &TwilioClient{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		AccountSID: accountSID,
+		AuthToken:  authToken,
+		From:       from,
+	}, nil
+}
+
+// NewTwilioClientFromEnv creates a client from TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and
+// TWILIO_FROM, a phone number or messaging service SID. It returns nil without an error when
+// TWILIO_ACCOUNT_SID is not set.
+func NewTwilioClientFromEnv() (*TwilioClient, error) {
+	accountSID := strings.TrimSpace(os.Getenv("TWILIO_ACCOUNT_SID"))
+	if accountSID == "" {
+		return nil, nil
+	}
+	return NewTwilioClient(accountSID, strings.TrimSpace(os.Getenv("TWILIO_AUTH_TOKEN")), strings.TrimSpace(os.Getenv("TWILIO_FROM")))
+}
+
+// Send texts the body to the number
+func (c *TwilioClient) Send(ctx context.Context, to, body string) error {
+	form := url.Values{"To": {to}, "Body": {body}}
+	if strings.HasPrefix(c.From, "MG") {
+		form.Set("MessagingServiceSid", c.From)
+	} else {
+		form.Set("From", c.From)
+	}
+
+	endpoint := c.BaseURL + "/2010-04-01/Accounts/" + url.PathEscape(c.AccountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.AccountSID, c.AuthToken)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var failure struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&failure); err == nil && failure.Message != "" {
+		return fmt.Errorf("twilio returned error %d: %s", failure.Code, failure.Message)
+	}
+	return fmt.Errorf("twilio returned status %d", resp.StatusCode)
+}
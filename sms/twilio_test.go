@@ -0,0 +1,65 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTwilioSend(t *testing.T) {
+	var form url.Values
+	var path, user, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		user, password, _ = r.BasicAuth()
+		r.ParseForm()
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewTwilioClient("AC123", "secret", "+15550001111")
+	if err != nil {
+		t.Fatalf("expected a client, got %v", err)
+	}
+	client.BaseURL = server.URL
+	if err := client.Send(context.Background(), "+15551234567", "Rent is due"); err != nil {
+		t.Fatalf("expected the text to be sent, got %v", err)
+	}
+
+	if path != "/2010-04-01/Accounts/AC123/Messages.json" {
+		t.Errorf("unexpected path %q", path)
+	}
+	if user != "AC123" || password != "secret" {
+		t.Errorf("expected basic auth with the account, got %q %q", user, password)
+	}
+	if form.Get("To") != "+15551234567" || form.Get("From") != "+15550001111" || form.Get("Body") != "Rent is due" {
+		t.Errorf("unexpected form %v", form)
+	}
+
+	client.From = "MG456"
+	if err := client.Send(context.Background(), "+15551234567", "Rent is due"); err != nil {
+		t.Fatalf("expected the text to be sent, got %v", err)
+	}
+	if form.Get("MessagingServiceSid") != "MG456" || form.Get("From") != "" {
+		t.Errorf("expected the messaging service to send, got %v", form)
+	}
+}
+
+func TestTwilioSendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewTwilioClient("AC123", "secret", "+15550001111")
+	client.BaseURL = server.URL
+	err := client.Send(context.Background(), "+1", "Rent is due")
+	if err == nil || err.Error() != "twilio returned error 21211: The 'To' number is not a valid phone number." {
+		t.Errorf("expected Twilio's error, got %v", err)
+	}
+}
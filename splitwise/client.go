@@ -0,0 +1,184 @@
+// splitwise/client.go
+package splitwise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultSplitwiseURL is used unless SPLITWISE_BASE_URL is set
+const defaultSplitwiseURL = "https://secure.splitwise.com/api/v3.0"
+
+// expensesPageSize is how many expenses are fetched per request
+const expensesPageSize = 100
+
+var (
+	// ErrUnauthorized is returned when Splitwise rejects the API key
+	ErrUnauthorized = errors.New("splitwise rejected the API key")
+	// ErrGroupNotFound is returned when the group does not exist or the key's account is not in it
+	ErrGroupNotFound = errors.New("splitwise group not found")
+)
+
+// Client reads and records expenses in Splitwise groups through the Splitwise API
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	APIKey     string // A personal API key from the Splitwise developer settings, or an OAuth access token
+}
+
+// NewClient creates a client acting as the account the API key belongs to
+func NewClient(apiKey string) (*Client, error) {
+	if apiKey == "" {
+		return nil, errors.New("splitwise needs an API key")
+	}
+	baseURL := os.Getenv("SPLITWISE_BASE_URL")
+	if baseURL == "" {
+		baseURL = defaultSplitwiseURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		APIKey:     apiKey,
+	}, nil
+}
+
+// Group fetches a group with its members
+func (c *Client) Group(ctx context.Context, groupID int64) (*Group, error) {
+	var body struct {
+		Group *Group `json:"group"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/get_group/"+strconv.FormatInt(groupID, 10), nil, &body); err != nil {
+		return nil, err
+	}
+	if body.Group == nil {
+		return nil, ErrGroupNotFound
+	}
+	return body.Group, nil
+}
+
+// Expenses fetches every expense and payment recorded in a group, deleted ones included
+func (c *Client) Expenses(ctx context.Context, groupID int64) ([]Expense, error) {
+	expenses := make([]Expense, 0)
+	for offset := 0; ; offset += expensesPageSize {
+		query := url.Values{
+			"group_id": {strconv.FormatInt(groupID, 10)},
+			"limit":    {strconv.Itoa(expensesPageSize)},
+			"offset":   {strconv.Itoa(offset)},
+		}
+		var body struct {
+			Expenses []Expense `json:"expenses"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/get_expenses?"+query.Encode(), nil, &body); err != nil {
+			return nil, err
+		}
+		expenses = append(expenses, body.Expenses...)
+		if len(body.Expenses) < expensesPageSize {
+			return expenses, nil
+		}
+	}
+}
+
+// CreatePayment records in a group that one of its users paid another back and returns the ID of
+// the payment
+func (c *Client) CreatePayment(ctx context.Context, groupID int64, payment Payment) (int64, error) {
+	amount := strconv.FormatFloat(payment.Amount, 'f', 2, 64)
+	request := map[string]interface{}{
+		"group_id":             groupID,
+		"payment":              true,
+		"cost":                 amount,
+		"currency_code":        payment.CurrencyCode,
+		"description":          payment.Description,
+		"date":                 payment.Date.UTC().Format(time.RFC3339),
+		"users__0__user_id":    payment.From,
+		"users__0__paid_share": amount,
+		"users__0__owed_share": "0.00",
+		"users__1__user_id":    payment.To,
+		"users__1__paid_share": "0.00",
+		"users__1__owed_share": amount,
+	}
+
+	var body struct {
+		Expenses []Expense                  `json:"expenses"`
+		Errors   map[string]json.RawMessage `json:"errors"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/create_expense", request, &body); err != nil {
+		return 0, err
+	}
+	// Splitwise answers rejected expenses with OK and the reasons in errors
+	if len(body.Errors) > 0 || len(body.Expenses) == 0 {
+		return 0, fmt.Errorf("splitwise rejected the payment: %s", validationMessage(body.Errors))
+	}
+	return body.Expenses[0].ID, nil
+}
+
+// validationMessage joins the messages of the errors Splitwise returns for rejected expenses,
+// which map each field to a list of messages
+func validationMessage(errs map[string]json.RawMessage) string {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, 0)
+	for _, field := range fields {
+		var list []string
+		if err := json.Unmarshal(errs[field], &list); err != nil {
+			list = []string{strings.Trim(string(errs[field]), `"`)}
+		}
+		for _, message := range list {
+			messages = append(messages, field+": "+message)
+		}
+	}
+	if len(messages) == 0 {
+		return "no reason given"
+	}
+	return strings.Join(messages, "; ")
+}
+
+// do sends a request to the API and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return ErrGroupNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("splitwise returned status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,545 @@
+// splitwise/splitwise.go
+package splitwise
+
+import (
+	"bytes"
+	"cribb-backend/models"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// User is a person in a Splitwise group
+type User struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+}
+
+// Name returns the user's full name as Splitwise shows it
+func (u User) Name() string {
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+// Group is a Splitwise group and the users in it
+type Group struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Members []User `json:"members"`
+}
+
+// FillUsers completes the users of the group's expenses from its members, since expenses leave
+// out their email addresses
+func (g Group) FillUsers(expenses []Expense) {
+	members := make(map[int64]User, len(g.Members))
+	for _, member := range g.Members {
+		members[member.ID] = member
+	}
+	for i := range expenses {
+		for j := range expenses[i].Users {
+			share := &expenses[i].Users[j]
+			if member, ok := members[share.UserID]; ok {
+				share.User = member
+			}
+		}
+	}
+}
+
+// Category is the category Splitwise filed an expense under
+type Category struct {
+	Name string `json:"name"`
+}
+
+// Amount is a sum of money, which the Splitwise API writes as a decimal string
+type Amount float64
+
+// UnmarshalJSON reads amounts written as strings, numbers or null
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if text == "" || text == "null" {
+		*a = 0
+		return nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %s", data)
+	}
+	*a = Amount(value)
+	return nil
+}
+
+// ExpenseUser is what one user paid towards an expense and what their share of it is
+type ExpenseUser struct {
+	UserID    int64  `json:"user_id"`
+	User      User   `json:"user"`
+	PaidShare Amount `json:"paid_share"`
+	OwedShare Amount `json:"owed_share"`
+}
+
+// Expense is an expense or, when Payment is set, a repayment between two users of a Splitwise group
+type Expense struct {
+	ID           int64         `json:"id"`
+	Description  string        `json:"description"`
+	Cost         Amount        `json:"cost"`
+	CurrencyCode string        `json:"currency_code"`
+	Date         time.Time     `json:"date"`
+	Payment      bool          `json:"payment"`
+	DeletedAt    *time.Time    `json:"deleted_at"`
+	Category     Category      `json:"category"`
+	Users        []ExpenseUser `json:"users"`
+
+	Row int `json:"-"` // Row of the CSV export the expense was read from
+
+	fingerprint string // Identifies rows of a CSV export, which have no ID
+}
+
+// ExternalID identifies the expense once imported: its Splitwise ID, or a fingerprint of its row
+// for expenses read from a CSV export, so importing the same history again adds nothing
+func (e Expense) ExternalID() string {
+	if e.ID != 0 {
+		return "splitwise:" + strconv.FormatInt(e.ID, 10)
+	}
+	return "splitwise-csv:" + e.fingerprint
+}
+
+// Payment is a repayment to record in a Splitwise group
+type Payment struct {
+	From         int64 // Splitwise user who paid
+	To           int64 // Splitwise user who was paid
+	Amount       float64
+	CurrencyCode string
+	Description  string
+	Date         time.Time
+}
+
+// csvColumns are the columns of a CSV export before the column of each user
+var csvColumns = map[string]bool{"date": true, "description": true, "category": true, "cost": true, "currency": true}
+
+// csvPerson is the column of a CSV export holding a user's balance changes
+type csvPerson struct {
+	index int
+	name  string
+}
+
+// ParseCSV reads the spreadsheet export of a Splitwise group. Besides the date, description,
+// category, cost and currency of each expense it has a column per user with what the expense did
+// to their balance: positive for what they paid beyond their share, negative for the share others
+// paid for them. Payments are filed under the Payment category. The total balance row at the end
+// and rows that change no balance are left out.
+func ParseCSV(data []byte) ([]Expense, []models.ImportError, error) {
+	// Spreadsheet exports often start with a byte order mark
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, models.ErrEmptyImport
+	}
+	if err != nil {
+		return nil, nil, errors.New("the file is not valid CSV")
+	}
+	columns := make(map[string]int)
+	var people []csvPerson
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if csvColumns[strings.ToLower(name)] {
+			columns[strings.ToLower(name)] = i
+		} else if name != "" {
+			people = append(people, csvPerson{i, name})
+		}
+	}
+	for _, name := range []string{"date", "description", "cost"} {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("the file is not a Splitwise export: it has no %s column", name)
+		}
+	}
+	if len(people) == 0 {
+		return nil, nil, errors.New("the file is not a Splitwise export: it has no column per person")
+	}
+
+	var expenses []Expense
+	var rowErrors []models.ImportError
+	seen := make(map[string]int)
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("the file is not valid CSV: %v", err)
+		}
+		cell := func(i int) string {
+			if i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		description := cell(columns["description"])
+		if strings.EqualFold(description, "Total balance") || strings.Join(record, "") == "" {
+			continue
+		}
+		expense, rowError := parseCSVRow(n, cell, columns, people)
+		if rowError != nil {
+			rowErrors = append(rowErrors, *rowError)
+			continue
+		}
+		if expense == nil {
+			continue
+		}
+
+		// Identical rows, such as two coffees on the same day, are told apart by their order
+		seen[expense.fingerprint]++
+		if count := seen[expense.fingerprint]; count > 1 {
+			expense.fingerprint += "-" + strconv.Itoa(count)
+		}
+		expenses = append(expenses, *expense)
+	}
+	if len(expenses) == 0 && len(rowErrors) == 0 {
+		return nil, nil, models.ErrEmptyImport
+	}
+	return expenses, rowErrors, nil
+}
+
+// parseCSVRow reads one expense of a CSV export. Users' shares are worked out from the changes to
+// their balances: when one user's balance went up they paid the whole cost, otherwise each user
+// paid what their balance went up by. It returns nil for rows that change no balance.
+func parseCSVRow(n int, cell func(int) string, columns map[string]int, people []csvPerson) (*Expense, *models.ImportError) {
+	expense := &Expense{Row: n, Description: cell(columns["description"])}
+
+	date, err := models.ParseImportDate(cell(columns["date"]), false)
+	if err != nil {
+		return nil, &models.ImportError{Row: n, Field: "date", Message: "must be YYYY-MM-DD"}
+	}
+	expense.Date = date
+	cost, err := strconv.ParseFloat(cell(columns["cost"]), 64)
+	if err != nil || cost <= 0 {
+		return nil, &models.ImportError{Row: n, Field: "cost", Message: "must be a positive number"}
+	}
+	expense.Cost = Amount(cost)
+	if i, ok := columns["currency"]; ok {
+		expense.CurrencyCode = cell(i)
+	}
+	if i, ok := columns["category"]; ok {
+		expense.Category.Name = cell(i)
+		expense.Payment = strings.EqualFold(expense.Category.Name, "Payment")
+	}
+
+	type change struct {
+		name string
+		net  float64
+	}
+	changes := make([]change, 0, len(people))
+	creditors := 0
+	total := 0.0
+	for _, person := range people {
+		value := cell(person.index)
+		if value == "" {
+			continue
+		}
+		net, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, &models.ImportError{Row: n, Field: person.name, Message: "must be a number"}
+		}
+		if net = models.RoundMoney(net); net == 0 {
+			continue
+		}
+		if net > 0 {
+			creditors++
+		}
+		total += net
+		changes = append(changes, change{person.name, net})
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if math.Abs(total) > 0.01*float64(len(changes)) {
+		return nil, &models.ImportError{Row: n, Message: "the changes to balances do not add up to zero"}
+	}
+
+	fingerprint := sha256.New()
+	fmt.Fprintf(fingerprint, "%s|%s|%.2f|%s", expense.Date.Format("2006-01-02"), expense.Description, cost, expense.CurrencyCode)
+	for _, c := range changes {
+		user := ExpenseUser{User: User{FirstName: c.name}}
+		switch {
+		case c.net > 0 && creditors == 1:
+			user.PaidShare, user.OwedShare = Amount(cost), Amount(models.RoundMoney(cost-c.net))
+		case c.net > 0:
+			user.PaidShare = Amount(c.net)
+		default:
+			user.OwedShare = Amount(-c.net)
+		}
+		expense.Users = append(expense.Users, user)
+		fmt.Fprintf(fingerprint, "|%s:%.2f", c.name, c.net)
+	}
+	// Several users paying leaves only what changed hands known, so that becomes the cost
+	if creditors != 1 {
+		paid := 0.0
+		for _, user := range expense.Users {
+			paid += float64(user.PaidShare)
+		}
+		expense.Cost = Amount(models.RoundMoney(paid))
+	}
+	expense.fingerprint = hex.EncodeToString(fingerprint.Sum(nil))[:24]
+	return expense, nil
+}
+
+// CategoryFor maps a Splitwise category to the closest expense category
+func CategoryFor(name string) models.ExpenseCategory {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "rent", "mortgage":
+		return models.ExpenseCategoryRent
+	case "groceries", "household supplies":
+		return models.ExpenseCategoryGroceries
+	case "electricity", "heat/gas", "water", "tv/phone/internet", "trash", "utilities - other", "cleaning":
+		return models.ExpenseCategoryUtilities
+	case "dining out", "entertainment", "games", "movies", "music", "sports", "liquor", "entertainment - other":
+		return models.ExpenseCategoryFun
+	}
+	return models.ExpenseCategoryOther
+}
+
+// Matcher finds the group member each Splitwise user stands for
+type Matcher struct {
+	members   []models.User
+	overrides map[string]string // Splitwise user ID, email or name to the member's username
+}
+
+// NewMatcher creates a matcher for the group's members. overrides maps Splitwise users, by ID,
+// email or name, to the usernames of the members they are.
+func NewMatcher(members []models.User, overrides map[string]string) *Matcher {
+	lowered := make(map[string]string, len(overrides))
+	for key, username := range overrides {
+		lowered[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(username)
+	}
+	return &Matcher{members: members, overrides: lowered}
+}
+
+// Member returns the member a Splitwise user is: the one the overrides name for the user, else
+// the member whose username is the user's email, else the only member with the user's name
+func (m *Matcher) Member(user User) (models.User, bool) {
+	keys := []string{user.Email, user.Name()}
+	if user.ID != 0 {
+		keys = append([]string{strconv.FormatInt(user.ID, 10)}, keys...)
+	}
+	for _, key := range keys {
+		if username, ok := m.overrides[strings.ToLower(strings.TrimSpace(key))]; ok && key != "" {
+			return m.find(func(member models.User) bool { return strings.EqualFold(member.Username, username) })
+		}
+	}
+
+	if user.Email != "" {
+		if member, ok := m.find(func(member models.User) bool { return strings.EqualFold(member.Username, user.Email) }); ok {
+			return member, true
+		}
+	}
+	name := user.Name()
+	if name == "" {
+		return models.User{}, false
+	}
+	return m.find(func(member models.User) bool {
+		return strings.EqualFold(strings.TrimSpace(member.Name), name) || strings.EqualFold(member.Username, name)
+	})
+}
+
+// User returns the Splitwise user among users that stands for the member
+func (m *Matcher) User(member models.User, users []User) (User, bool) {
+	for _, user := range users {
+		if match, ok := m.Member(user); ok && match.ID == member.ID {
+			return user, true
+		}
+	}
+	return User{}, false
+}
+
+// find returns the only member matching, so an ambiguous name matches no one
+func (m *Matcher) find(match func(models.User) bool) (models.User, bool) {
+	var found []models.User
+	for _, member := range m.members {
+		if match(member) {
+			found = append(found, member)
+		}
+	}
+	if len(found) != 1 {
+		return models.User{}, false
+	}
+	return found[0], true
+}
+
+// Import is what the history of a Splitwise group adds to a Cribb group
+type Import struct {
+	Expenses    []*models.Expense
+	Settlements []*models.Settlement
+	Unmatched   []string // Splitwise users no member stands for
+	Errors      []models.ImportError
+}
+
+// Target is the group a Splitwise history is imported into
+type Target struct {
+	GroupID    primitive.ObjectID
+	ImportedBy primitive.ObjectID
+	Currency   string             // The group's currency, which balances are kept in
+	Rates      map[string]float64 // Units of Currency one unit of each other currency buys
+}
+
+// Plan turns Splitwise expenses into expenses and settlements of the target group, recorded on the
+// dates they happened. Deleted expenses are left out. An expense several users paid for becomes
+// one expense per payer, each split in the same proportions as the whole. Amounts in other
+// currencies are converted at the target's rates, keeping the original amount on expenses. Rows
+// are numbered from 1 unless read from a CSV export.
+func Plan(expenses []Expense, target Target, matcher *Matcher) Import {
+	plan := Import{
+		Expenses:    make([]*models.Expense, 0),
+		Settlements: make([]*models.Settlement, 0),
+		Unmatched:   make([]string, 0),
+		Errors:      make([]models.ImportError, 0),
+	}
+	unmatched := make(map[string]bool)
+
+	for i, expense := range expenses {
+		if expense.DeletedAt != nil {
+			continue
+		}
+		row := expense.Row
+		if row == 0 {
+			row = i + 1
+		}
+		fail := func(field, message string) {
+			plan.Errors = append(plan.Errors, models.ImportError{Row: row, Field: field, Message: message})
+		}
+
+		cost := models.RoundMoney(float64(expense.Cost))
+		if cost <= 0 {
+			fail("cost", "must be positive")
+			continue
+		}
+		code := models.NormalizeCurrency(expense.CurrencyCode)
+		if code == "" {
+			code = target.Currency
+		}
+		rate := 1.0
+		if code != target.Currency {
+			var ok bool
+			if rate, ok = target.Rates[code]; !ok || rate <= 0 {
+				fail("currency", "no exchange rate from "+code+" to "+target.Currency)
+				continue
+			}
+		}
+
+		// Shares are added up per member, since two Splitwise users may be the same member
+		paid := make([]models.CostShare, 0)
+		owed := make([]models.CostShare, 0)
+		paidTotal, owedTotal := 0.0, 0.0
+		resolved := true
+		for _, share := range expense.Users {
+			if share.PaidShare == 0 && share.OwedShare == 0 {
+				continue
+			}
+			user := share.User
+			if user.ID == 0 {
+				user.ID = share.UserID
+			}
+			member, ok := matcher.Member(user)
+			if !ok {
+				name := user.Name()
+				if name == "" {
+					name = strconv.FormatInt(user.ID, 10)
+				}
+				if !unmatched[name] {
+					unmatched[name] = true
+					plan.Unmatched = append(plan.Unmatched, name)
+				}
+				resolved = false
+				continue
+			}
+			if share.PaidShare > 0 {
+				paid = append(paid, models.CostShare{UserID: member.ID, Amount: float64(share.PaidShare)})
+				paidTotal += float64(share.PaidShare)
+			}
+			if share.OwedShare > 0 {
+				owed = append(owed, models.CostShare{UserID: member.ID, Amount: float64(share.OwedShare)})
+				owedTotal += float64(share.OwedShare)
+			}
+		}
+		if !resolved {
+			continue
+		}
+		if models.RoundMoney(paidTotal) != cost || models.RoundMoney(owedTotal) != cost {
+			fail("cost", "what was paid and owed does not add up to the cost")
+			continue
+		}
+		paid, owed = models.MergeShares(paid), models.MergeShares(owed)
+
+		if expense.Payment {
+			if len(paid) != 1 || len(owed) != 1 || paid[0].UserID == owed[0].UserID {
+				fail("", "a payment must be from one member to another")
+				continue
+			}
+			settlement := models.CreateSettlement(target.GroupID, paid[0].UserID, owed[0].UserID, models.RoundMoney(cost*rate), target.ImportedBy)
+			settlement.Note = strings.TrimSpace(expense.Description)
+			settlement.ExternalID = expense.ExternalID()
+			settlement.CreatedAt = expense.Date
+			plan.Settlements = append(plan.Settlements, settlement)
+			continue
+		}
+
+		description := strings.TrimSpace(expense.Description)
+		if description == "" {
+			description = "Splitwise expense"
+		}
+		for n, payer := range paid {
+			amount := models.RoundMoney(payer.Amount * rate)
+			splits := owed
+			if len(paid) > 1 || rate != 1 {
+				var err error
+				if splits, err = models.ConvertedShares(amount, owed); err != nil {
+					fail("", err.Error())
+					break
+				}
+			}
+			imported := models.CreateExpense(target.GroupID, payer.UserID, description, amount, splits, models.ExpenseSourceSplitwise)
+			imported.Currency = target.Currency
+			if code != target.Currency {
+				imported.OriginalAmount = payer.Amount
+				imported.OriginalCurrency = code
+				imported.ExchangeRate = rate
+			}
+			imported.SplitMethod = models.SplitMethodExact
+			imported.Category = CategoryFor(expense.Category.Name)
+			imported.CreatedBy = target.ImportedBy
+			imported.CreatedAt = expense.Date
+			imported.ExternalID = expense.ExternalID()
+			if len(paid) > 1 {
+				imported.ExternalID += "/" + strconv.Itoa(n+1)
+			}
+			plan.Expenses = append(plan.Expenses, imported)
+		}
+	}
+	return plan
+}
+
+// Currencies lists the currencies of the expenses other than the given one, so their rates can be
+// looked up before planning an import
+func Currencies(expenses []Expense, except string) []string {
+	seen := map[string]bool{except: true, "": true}
+	codes := make([]string, 0)
+	for _, expense := range expenses {
+		code := models.NormalizeCurrency(expense.CurrencyCode)
+		if !seen[code] && expense.DeletedAt == nil {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
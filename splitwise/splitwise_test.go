@@ -0,0 +1,276 @@
+package splitwise
+
+import (
+	"context"
+	"cribb-backend/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const exportCSV = "\ufeffDate,Description,Category,Cost,Currency,Alice Smith,Bob Jones,Carol\n" +
+	"2025-01-05,Groceries,Groceries,30.00,USD,20.00,-10.00,-10.00\n" +
+	"2025-01-06,Rent,Rent,1500.00,USD,-500.00,-500.00,1000.00\n" +
+	"2025-01-07,Bob paid Alice,Payment,10.00,USD,-10.00,10.00,0.00\n" +
+	"2025-01-08,Coffee,Dining out,4.00,USD,0.00,0.00,0.00\n" +
+	"2025-01-09,Dinner,Dining out,90.00,USD,30.00,15.00,-45.00\n" +
+	"\n" +
+	" ,Total balance, , ,USD,-460.00,-485.00,945.00\n"
+
+func members() []models.User {
+	return []models.User{
+		{ID: primitive.NewObjectID(), Username: "alice@example.com", Name: "Alice Smith"},
+		{ID: primitive.NewObjectID(), Username: "bob@example.com", Name: "Bob Jones"},
+		{ID: primitive.NewObjectID(), Username: "carol@example.com", Name: "Carol King"},
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	expenses, rowErrors, err := ParseCSV([]byte(exportCSV))
+	if err != nil || len(rowErrors) != 0 {
+		t.Fatalf("unexpected errors %v %v", err, rowErrors)
+	}
+	if len(expenses) != 4 {
+		t.Fatalf("expected the coffee and the total balance to be left out, got %d expenses", len(expenses))
+	}
+
+	groceries := expenses[0]
+	if groceries.Row != 1 || groceries.Cost != 30 || groceries.Date.Format("2006-01-02") != "2025-01-05" {
+		t.Errorf("unexpected expense %+v", groceries)
+	}
+	alice := groceries.Users[0]
+	if alice.User.Name() != "Alice Smith" || alice.PaidShare != 30 || alice.OwedShare != 10 {
+		t.Errorf("expected Alice to pay everything and owe a third, got %+v", alice)
+	}
+	if payment := expenses[2]; !payment.Payment || payment.Users[0].OwedShare != 10 || payment.Users[1].PaidShare != 10 {
+		t.Errorf("expected Bob's payment to Alice, got %+v", payment)
+	}
+
+	// Two payers leave only what changed hands
+	dinner := expenses[3]
+	if dinner.Cost != 45 || dinner.Users[0].PaidShare != 30 || dinner.Users[2].OwedShare != 45 {
+		t.Errorf("unexpected shared payment %+v", dinner)
+	}
+	if dinner.ExternalID() == groceries.ExternalID() || groceries.ExternalID()[:14] != "splitwise-csv:" {
+		t.Errorf("expected rows to have their own IDs, got %q and %q", groceries.ExternalID(), dinner.ExternalID())
+	}
+}
+
+func TestParseCSVRowErrors(t *testing.T) {
+	data := "Date,Description,Category,Cost,Currency,Alice,Bob\n" +
+		"someday,Milk,Groceries,3.00,USD,1.50,-1.50\n" +
+		"2025-01-05,Milk,Groceries,3.00,USD,1.50,-1.00\n" +
+		"2025-01-05,Milk,Groceries,3.00,USD,1.50,-1.50\n" +
+		"2025-01-05,Milk,Groceries,3.00,USD,1.50,-1.50\n"
+	expenses, rowErrors, err := ParseCSV([]byte(data))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Row != 1 || rowErrors[0].Field != "date" || rowErrors[1].Row != 2 {
+		t.Errorf("unexpected row errors %+v", rowErrors)
+	}
+	if len(expenses) != 2 || expenses[0].ExternalID() == expenses[1].ExternalID() {
+		t.Errorf("expected identical rows to be told apart, got %+v", expenses)
+	}
+
+	if _, _, err := ParseCSV([]byte("Title,Points\nDishes,5\n")); err == nil {
+		t.Error("expected a file that is not a Splitwise export to be rejected")
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	group := members()
+	matcher := NewMatcher(group, map[string]string{"42": "carol@example.com"})
+
+	if member, ok := matcher.Member(User{ID: 7, Email: "ALICE@example.com"}); !ok || member.ID != group[0].ID {
+		t.Errorf("expected a match by email, got %+v", member)
+	}
+	if member, ok := matcher.Member(User{FirstName: "bob", LastName: "jones"}); !ok || member.ID != group[1].ID {
+		t.Errorf("expected a match by name, got %+v", member)
+	}
+	if member, ok := matcher.Member(User{ID: 42, FirstName: "C"}); !ok || member.ID != group[2].ID {
+		t.Errorf("expected a match by the override, got %+v", member)
+	}
+	if _, ok := matcher.Member(User{FirstName: "Dave"}); ok {
+		t.Error("expected strangers not to match")
+	}
+
+	users := []User{{ID: 1, FirstName: "Alice", LastName: "Smith"}, {ID: 2, FirstName: "Bob", LastName: "Jones"}}
+	if user, ok := matcher.User(group[1], users); !ok || user.ID != 2 {
+		t.Errorf("expected Bob's Splitwise user, got %+v", user)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	group := members()
+	expenses, _, err := ParseCSV([]byte(exportCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := Target{GroupID: primitive.NewObjectID(), ImportedBy: group[0].ID, Currency: "USD"}
+	plan := Plan(expenses, target, NewMatcher(group, map[string]string{"Carol": "carol@example.com"}))
+	if len(plan.Errors) != 0 || len(plan.Unmatched) != 0 {
+		t.Fatalf("unexpected problems %+v %v", plan.Errors, plan.Unmatched)
+	}
+
+	// The dinner two members paid for becomes an expense per payer
+	if len(plan.Expenses) != 4 || len(plan.Settlements) != 1 {
+		t.Fatalf("expected 4 expenses and a settlement, got %d and %d", len(plan.Expenses), len(plan.Settlements))
+	}
+	rent := plan.Expenses[1]
+	if rent.PaidBy != group[2].ID || rent.Amount != 1500 || rent.Category != models.ExpenseCategoryRent || rent.Source != models.ExpenseSourceSplitwise {
+		t.Errorf("unexpected rent %+v", rent)
+	}
+	if rent.CreatedAt.Format("2006-01-02") != "2025-01-06" || rent.CreatedBy != group[0].ID {
+		t.Errorf("expected the Splitwise date and the importer, got %+v", rent)
+	}
+	settlement := plan.Settlements[0]
+	if settlement.FromUserID != group[1].ID || settlement.ToUserID != group[0].ID || settlement.Amount != 10 || settlement.Note != "Bob paid Alice" {
+		t.Errorf("unexpected settlement %+v", settlement)
+	}
+
+	// Balances come out as in Splitwise
+	all := make([]models.Expense, 0, len(plan.Expenses))
+	for _, expense := range plan.Expenses {
+		all = append(all, *expense)
+	}
+	balances := balancesOf(all, plan.Settlements, group)
+	want := []float64{-460, -485, 945}
+	for i, balance := range balances {
+		if balance.Net != want[i] {
+			t.Errorf("expected %s to be at %.2f, got %.2f", group[i].Name, want[i], balance.Net)
+		}
+	}
+}
+
+// balancesOf nets the planned expenses and settlements for the members
+func balancesOf(expenses []models.Expense, settlements []*models.Settlement, group []models.User) []models.MemberBalance {
+	recorded := make([]models.Settlement, 0, len(settlements))
+	for _, settlement := range settlements {
+		recorded = append(recorded, *settlement)
+	}
+	ids := make([]primitive.ObjectID, 0, len(group))
+	for _, member := range group {
+		ids = append(ids, member.ID)
+	}
+	return models.ComputeBalances(expenses, recorded, ids)
+}
+
+func TestPlanCurrenciesAndUnmatched(t *testing.T) {
+	group := members()
+	deleted := time.Now()
+	expenses := []Expense{
+		{ID: 1, Description: "Train", Cost: 20, CurrencyCode: "EUR", Date: time.Now(), Users: []ExpenseUser{
+			{UserID: 1, User: User{ID: 1, Email: "alice@example.com"}, PaidShare: 20, OwedShare: 10},
+			{UserID: 2, User: User{ID: 2, Email: "bob@example.com"}, OwedShare: 10},
+		}},
+		{ID: 2, Description: "Museum", Cost: 10, CurrencyCode: "JPY", Users: []ExpenseUser{
+			{UserID: 1, User: User{ID: 1, Email: "alice@example.com"}, PaidShare: 10, OwedShare: 10},
+		}},
+		{ID: 3, Description: "Taxi", Cost: 8, CurrencyCode: "USD", Users: []ExpenseUser{
+			{UserID: 9, User: User{ID: 9, FirstName: "Dave"}, PaidShare: 8},
+			{UserID: 1, User: User{ID: 1, Email: "alice@example.com"}, OwedShare: 8},
+		}},
+		{ID: 4, Description: "Removed", Cost: 5, CurrencyCode: "USD", DeletedAt: &deleted},
+	}
+	if codes := Currencies(expenses, "USD"); len(codes) != 2 || codes[0] != "EUR" || codes[1] != "JPY" {
+		t.Errorf("unexpected currencies %v", codes)
+	}
+
+	target := Target{GroupID: primitive.NewObjectID(), ImportedBy: group[0].ID, Currency: "USD", Rates: map[string]float64{"EUR": 1.1}}
+	plan := Plan(expenses, target, NewMatcher(group, nil))
+
+	if len(plan.Expenses) != 1 {
+		t.Fatalf("expected only the train to be planned, got %d expenses", len(plan.Expenses))
+	}
+	train := plan.Expenses[0]
+	if train.Amount != 22 || train.OriginalAmount != 20 || train.OriginalCurrency != "EUR" || train.Splits[1].Amount != 11 {
+		t.Errorf("expected the train converted to dollars, got %+v", train)
+	}
+	if train.ExternalID != "splitwise:1" {
+		t.Errorf("expected the Splitwise ID, got %q", train.ExternalID)
+	}
+	if len(plan.Errors) != 1 || plan.Errors[0].Row != 2 || plan.Errors[0].Field != "currency" {
+		t.Errorf("expected the museum's currency to be reported, got %+v", plan.Errors)
+	}
+	if len(plan.Unmatched) != 1 || plan.Unmatched[0] != "Dave" {
+		t.Errorf("expected Dave to be unmatched, got %v", plan.Unmatched)
+	}
+}
+
+func TestClientExpensesAndPayments(t *testing.T) {
+	var auth string
+	var payment map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/get_group/5":
+			w.Write([]byte(`{"group":{"id":5,"name":"Flat","members":[{"id":1,"first_name":"Alice","last_name":null,"email":"alice@example.com"}]}}`))
+		case "/get_expenses":
+			if r.URL.Query().Get("offset") != "0" {
+				w.Write([]byte(`{"expenses":[]}`))
+				return
+			}
+			w.Write([]byte(`{"expenses":[{"id":9,"description":"Milk","cost":"3.5","currency_code":"USD","date":"2025-01-05T10:00:00Z","payment":false,"deleted_at":null,"category":{"name":"Groceries"},"users":[{"user_id":1,"user":{"id":1,"first_name":"Alice"},"paid_share":"3.5","owed_share":"3.5"}]}]}`))
+		case "/create_expense":
+			json.NewDecoder(r.Body).Decode(&payment)
+			w.Write([]byte(`{"expenses":[{"id":77}],"errors":{}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("key")
+	if err != nil {
+		t.Fatalf("expected a client, got %v", err)
+	}
+	client.BaseURL = server.URL
+	ctx := context.Background()
+
+	group, err := client.Group(ctx, 5)
+	if err != nil || group.Members[0].Name() != "Alice" {
+		t.Fatalf("unexpected group %+v (%v)", group, err)
+	}
+	expenses, err := client.Expenses(ctx, 5)
+	if err != nil || len(expenses) != 1 || expenses[0].Cost != 3.5 || expenses[0].Users[0].PaidShare != 3.5 {
+		t.Fatalf("unexpected expenses %+v (%v)", expenses, err)
+	}
+	group.FillUsers(expenses)
+	if expenses[0].Users[0].User.Email != "alice@example.com" {
+		t.Errorf("expected the member's email to be filled in, got %+v", expenses[0].Users[0].User)
+	}
+	if auth != "Bearer key" {
+		t.Errorf("expected the API key as a bearer token, got %q", auth)
+	}
+
+	id, err := client.CreatePayment(ctx, 5, Payment{From: 1, To: 2, Amount: 12.5, CurrencyCode: "USD", Description: "Rent", Date: time.Now()})
+	if err != nil || id != 77 {
+		t.Fatalf("expected the payment's ID, got %d (%v)", id, err)
+	}
+	if payment["payment"] != true || payment["cost"] != "12.50" || payment["users__0__paid_share"] != "12.50" || payment["users__1__owed_share"] != "12.50" {
+		t.Errorf("unexpected payment %v", payment)
+	}
+
+	if _, err := client.Group(ctx, 6); err != ErrGroupNotFound {
+		t.Errorf("expected a missing group, got %v", err)
+	}
+}
+
+func TestClientPaymentRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"expenses":[],"errors":{"base":["You cannot add a payment to yourself"]}}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("key")
+	client.BaseURL = server.URL
+	_, err := client.CreatePayment(context.Background(), 5, Payment{From: 1, To: 1, Amount: 1, CurrencyCode: "USD"})
+	if err == nil || err.Error() != "splitwise rejected the payment: base: You cannot add a payment to yourself" {
+		t.Errorf("expected Splitwise's reason, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+// storage/signing.go
+package storage
+
+import (
+	"cribb-backend/config"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SignedURLTTL is how long a signed file URL stays valid
+const SignedURLTTL = 15 * time.Minute
+
+// ErrInvalidSignature is returned for signed URLs that were tampered with or have expired
+var ErrInvalidSignature = errors.New("invalid or expired signature")
+
+// signature computes the signature of a file ID and expiry with the server secret
+func signature(id primitive.ObjectID, expires int64) string {
+	mac := hmac.New(sha256.New, config.JWTSecret)
+	fmt.Fprintf(mac, "%s:%d", id.Hex(), expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignedURL returns a URL for the file under path that can be fetched without logging in until
+// SignedURLTTL after now. The file ID is the last path segment.
+func SignedURL(path string, id primitive.ObjectID, now time.Time) string {
+	expires := now.Add(SignedURLTTL).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", signature(id, expires))
+	return path + id.Hex() + "?" + query.Encode()
+}
+
+// VerifySignature checks the expires and signature query parameters of a signed URL for the file
+func VerifySignature(id primitive.ObjectID, query url.Values, now time.Time) error {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || now.Unix() > expires {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(query.Get("signature")), []byte(signature(id, expires))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
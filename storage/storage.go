@@ -0,0 +1,163 @@
+// storage/storage.go
+package storage
+
+import (
+	"bytes"
+	"context"
+	"cribb-backend/config"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bucketName is the GridFS bucket uploaded files are kept in
+const bucketName = "uploads"
+
+// MaxImageSize is the largest image that can be uploaded, in bytes
+const MaxImageSize = 5 << 20
+
+// MaxAttachmentSize is the largest attachment that can be uploaded, in bytes
+const MaxAttachmentSize = 10 << 20
+
+// ErrFileNotFound is returned when no file is stored under an ID
+var ErrFileNotFound = errors.New("file not found")
+
+// ErrImageTooLarge is returned for images over MaxImageSize
+var ErrImageTooLarge = errors.New("image too large")
+
+// ErrUnsupportedImage is returned for uploads that are not JPEG, PNG, GIF or WebP images
+var ErrUnsupportedImage = errors.New("unsupported image type")
+
+// imageTypes are the image content types accepted for upload
+var imageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ErrFileTooLarge is returned for attachments over MaxAttachmentSize
+var ErrFileTooLarge = errors.New("file too large")
+
+// ErrUnsupportedFile is returned for attachments that are neither accepted images nor PDFs
+var ErrUnsupportedFile = errors.New("unsupported file type")
+
+// ImageContentType detects the content type of an image from its first bytes, reporting
+// false when the data is not an accepted image
+func ImageContentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	return contentType, imageTypes[contentType]
+}
+
+// AttachmentContentType detects the content type of an attachment from its first bytes,
+// reporting false unless it is an accepted image or a PDF
+func AttachmentContentType(data []byte) (string, bool) {
+	contentType := http.DetectContentType(data)
+	return contentType, imageTypes[contentType] || contentType == "application/pdf"
+}
+
+// bucket opens the uploads bucket; GridFS keeps files in the database so every instance sees them
+func bucket() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(config.DB, options.GridFSBucket().SetName(bucketName))
+}
+
+// SaveImage stores an uploaded image and returns its file ID. The image must be at most
+// MaxImageSize bytes and of an accepted type.
+func SaveImage(filename string, data []byte) (primitive.ObjectID, error) {
+	if len(data) > MaxImageSize {
+		return primitive.NilObjectID, ErrImageTooLarge
+	}
+	contentType, ok := ImageContentType(data)
+	if !ok {
+		return primitive.NilObjectID, ErrUnsupportedImage
+	}
+
+	b, err := bucket()
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType})
+	return b.UploadFromStream(filename, bytes.NewReader(data), opts)
+}
+
+// SaveAttachment stores an uploaded image or PDF and returns its file ID and content type. The
+// file must be at most MaxAttachmentSize bytes.
+func SaveAttachment(filename string, data []byte) (primitive.ObjectID, string, error) {
+	if len(data) > MaxAttachmentSize {
+		return primitive.NilObjectID, "", ErrFileTooLarge
+	}
+	contentType, ok := AttachmentContentType(data)
+	if !ok {
+		return primitive.NilObjectID, "", ErrUnsupportedFile
+	}
+
+	b, err := bucket()
+	if err != nil {
+		return primitive.NilObjectID, "", err
+	}
+
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType})
+	id, err := b.UploadFromStream(filename, bytes.NewReader(data), opts)
+	return id, contentType, err
+}
+
+// SaveExport stores an archive the server generated, reading it to the end, and returns its file
+// ID. Unlike uploads, exports are neither size limited nor checked for their type.
+func SaveExport(filename, contentType string, data io.Reader) (primitive.ObjectID, error) {
+	b, err := bucket()
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType})
+	return b.UploadFromStream(filename, data, opts)
+}
+
+// Open returns a stored file's contents and content type. The caller closes the reader.
+func Open(ctx context.Context, id primitive.ObjectID) (io.ReadCloser, string, error) {
+	b, err := bucket()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var file struct {
+		Metadata struct {
+			ContentType string `bson:"content_type"`
+		} `bson:"metadata"`
+	}
+	if err := b.GetFilesCollection().FindOne(ctx, bson.M{"_id": id}).Decode(&file); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, "", ErrFileNotFound
+		}
+		return nil, "", err
+	}
+
+	stream, err := b.OpenDownloadStream(id)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, "", ErrFileNotFound
+		}
+		return nil, "", err
+	}
+	return stream, file.Metadata.ContentType, nil
+}
+
+// Delete removes a stored file; deleting a file that no longer exists is not an error
+func Delete(ctx context.Context, id primitive.ObjectID) error {
+	b, err := bucket()
+	if err != nil {
+		return err
+	}
+
+	if err := b.DeleteContext(ctx, id); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestImageContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if contentType, ok := ImageContentType(png); !ok || contentType != "image/png" {
+		t.Errorf("expected a PNG image, got %q (%v)", contentType, ok)
+	}
+
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F'}
+	if contentType, ok := ImageContentType(jpeg); !ok || contentType != "image/jpeg" {
+		t.Errorf("expected a JPEG image, got %q (%v)", contentType, ok)
+	}
+
+	if _, ok := ImageContentType([]byte("<html><body>not an image</body></html>")); ok {
+		t.Error("expected HTML to be rejected")
+	}
+}
+
+func TestAttachmentContentType(t *testing.T) {
+	if contentType, ok := AttachmentContentType([]byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3")); !ok || contentType != "application/pdf" {
+		t.Errorf("expected a PDF, got %q (%v)", contentType, ok)
+	}
+	if _, ok := AttachmentContentType([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")); !ok {
+		t.Error("expected images to be accepted")
+	}
+	if _, ok := AttachmentContentType([]byte("plain text is not an attachment")); ok {
+		t.Error("expected text to be rejected")
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	id := primitive.NewObjectID()
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	signed, err := url.Parse(SignedURL("/api/expenses/receipts/", id, now))
+	if err != nil {
+		t.Fatalf("expected a valid URL, got %v", err)
+	}
+	if signed.Path != "/api/expenses/receipts/"+id.Hex() {
+		t.Errorf("expected the file ID at the end of the path, got %q", signed.Path)
+	}
+	query := signed.Query()
+
+	if err := VerifySignature(id, query, now.Add(time.Minute)); err != nil {
+		t.Errorf("expected the signature to verify, got %v", err)
+	}
+	if err := VerifySignature(id, query, now.Add(SignedURLTTL+time.Second)); err == nil {
+		t.Error("expected an expired signature to be rejected")
+	}
+	if err := VerifySignature(primitive.NewObjectID(), query, now); err == nil {
+		t.Error("expected a signature for another file to be rejected")
+	}
+
+	query.Set("expires", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+	if err := VerifySignature(id, query, now); err == nil {
+		t.Error("expected a tampered expiry to be rejected")
+	}
+}
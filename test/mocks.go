@@ -3,6 +3,7 @@ package test
 
 import (
 	"cribb-backend/models"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,6 +20,12 @@ type TestDB struct {
 	PantryNotifications []models.PantryNotification // Added for pantry tests
 	PantryHistory       []models.PantryHistory      // Added for pantry tests
 	ShoppingCartItems   []models.ShoppingCartItem
+	Rewards             []models.Reward
+	Redemptions         []models.Redemption
+	ScoreEvents         []models.ScoreEvent
+
+	// mu serializes the operations that a transaction makes atomic in MongoDB
+	mu sync.Mutex
 }
 
 // NewTestDB creates a new test database with some initial data
@@ -33,6 +40,9 @@ func NewTestDB() *TestDB {
 		PantryNotifications: []models.PantryNotification{},
 		PantryHistory:       []models.PantryHistory{},
 		ShoppingCartItems:   []models.ShoppingCartItem{},
+		Rewards:             []models.Reward{},
+		Redemptions:         []models.Redemption{},
+		ScoreEvents:         []models.ScoreEvent{},
 	}
 }
 
@@ -0,0 +1,103 @@
+// test/mocks_rewards.go
+package test
+
+import (
+	"cribb-backend/models"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotFound is returned by mock operations when a document does not exist
+var ErrNotFound = errors.New("not found")
+
+// AddReward adds a reward to the test database
+func (db *TestDB) AddReward(reward models.Reward) {
+	db.Rewards = append(db.Rewards, reward)
+}
+
+// RedeemReward mirrors the redemption transaction: the cost is only deducted from a score that
+// covers it, in the same step as the check, and the redemption and its score event are stored with it
+func (db *TestDB) RedeemReward(userID, rewardID primitive.ObjectID) (*models.Redemption, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var reward *models.Reward
+	for i := range db.Rewards {
+		if db.Rewards[i].ID == rewardID && db.Rewards[i].IsActive {
+			reward = &db.Rewards[i]
+		}
+	}
+	user := db.userByID(userID)
+	if reward == nil || user == nil || user.GroupID != reward.GroupID {
+		return nil, ErrNotFound
+	}
+
+	redemption, event, err := reward.Redeem(user)
+	if err != nil {
+		return nil, err
+	}
+	redemption.ID = primitive.NewObjectID()
+	event.ReferenceID = &redemption.ID
+
+	db.Redemptions = append(db.Redemptions, *redemption)
+	db.ScoreEvents = append(db.ScoreEvents, *event)
+	return redemption, nil
+}
+
+// ReviewRedemption mirrors the review transaction: the redemption must still be pending, and a
+// rejection refunds the points with a refund score event
+func (db *TestDB) ReviewRedemption(redemptionID, reviewerID primitive.ObjectID, approve bool, note string) (*models.Redemption, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var redemption *models.Redemption
+	for i := range db.Redemptions {
+		if db.Redemptions[i].ID == redemptionID {
+			redemption = &db.Redemptions[i]
+		}
+	}
+	if redemption == nil {
+		return nil, ErrNotFound
+	}
+
+	memberCount := 0
+	for _, group := range db.Groups {
+		if group.ID == redemption.GroupID {
+			memberCount = len(group.Members)
+		}
+	}
+
+	if err := redemption.Review(reviewerID, memberCount, approve, note, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if owner := db.userByID(redemption.UserID); owner != nil {
+		if event := redemption.Refund(owner); event != nil {
+			db.ScoreEvents = append(db.ScoreEvents, *event)
+		}
+	}
+	return redemption, nil
+}
+
+// GetScoreEventsForUser returns the score events of a user in the order they were recorded
+func (db *TestDB) GetScoreEventsForUser(userID primitive.ObjectID) []models.ScoreEvent {
+	var events []models.ScoreEvent
+	for _, event := range db.ScoreEvents {
+		if event.UserID == userID {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// userByID returns the stored user so it can be changed in place
+func (db *TestDB) userByID(id primitive.ObjectID) *models.User {
+	for i := range db.Users {
+		if db.Users[i].ID == id {
+			return &db.Users[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+// voice/alexa.go
+package voice
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// AlexaCertURLHeader and AlexaSignatureHeader carry the signature of every request Alexa sends
+	AlexaCertURLHeader   = "SignatureCertChainUrl"
+	AlexaSignatureHeader = "Signature-256"
+
+	// alexaCertHost is the only host Alexa signing certificates are fetched from
+	alexaCertHost = "s3.amazonaws.com"
+	// alexaCertPath is the path every Alexa signing certificate URL starts with
+	alexaCertPath = "/echo.api/"
+	// alexaCertName is the name the signing certificate must be issued for
+	alexaCertName = "echo-api.amazon.com"
+	// alexaTimestampTolerance is how old, or how far ahead, a request's timestamp may be
+	alexaTimestampTolerance = 150 * time.Second
+	// maxCertChainSize is the largest certificate chain read, in bytes
+	maxCertChainSize = 64 << 10
+)
+
+// ErrInvalidAlexaSignature is returned for requests that were not signed by Alexa
+var ErrInvalidAlexaSignature = errors.New("invalid alexa request signature")
+
+// AlexaRequest is the body of a request Alexa sends to a custom skill
+type AlexaRequest struct {
+	Version string        `json:"version"`
+	Session *AlexaSession `json:"session,omitempty"`
+	Context struct {
+		System struct {
+			Application AlexaApplication `json:"application"`
+			User        AlexaUser        `json:"user"`
+		} `json:"System"`
+	} `json:"context"`
+	Request struct {
+		Type      string       `json:"type"` // LaunchRequest, IntentRequest or SessionEndedRequest
+		RequestID string       `json:"requestId"`
+		Timestamp string       `json:"timestamp"`
+		Locale    string       `json:"locale"`
+		Intent    *AlexaIntent `json:"intent,omitempty"`
+	} `json:"request"`
+}
+
+// AlexaSession is the conversation a request belongs to
+type AlexaSession struct {
+	New         bool             `json:"new"`
+	SessionID   string           `json:"sessionId"`
+	Application AlexaApplication `json:"application"`
+	User        AlexaUser        `json:"user"`
+}
+
+// AlexaApplication identifies the skill
+type AlexaApplication struct {
+	ApplicationID string `json:"applicationId"`
+}
+
+// AlexaUser is the Amazon account talking to the skill, with the access token of the linked
+// account once it is linked
+type AlexaUser struct {
+	UserID      string `json:"userId"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// AlexaIntent is the intent Alexa matched with the slot values it heard
+type AlexaIntent struct {
+	Name  string               `json:"name"`
+	Slots map[string]AlexaSlot `json:"slots,omitempty"`
+}
+
+// AlexaSlot is a value heard for an intent
+type AlexaSlot struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// SkillID returns the ID of the skill the request was sent to
+func (r AlexaRequest) SkillID() string {
+	if id := r.Context.System.Application.ApplicationID; id != "" {
+		return id
+	}
+	if r.Session != nil {
+		return r.Session.Application.ApplicationID
+	}
+	return ""
+}
+
+// AccessToken returns the access token of the linked account, or "" when it is not linked
+func (r AlexaRequest) AccessToken() string {
+	if token := r.Context.System.User.AccessToken; token != "" {
+		return token
+	}
+	if r.Session != nil {
+		return r.Session.User.AccessToken
+	}
+	return ""
+}
+
+// CheckTimestamp checks that the request was sent moments ago, so recorded requests cannot be
+// replayed
+func (r AlexaRequest) CheckTimestamp(now time.Time) error {
+	sent, err := time.Parse(time.RFC3339, r.Request.Timestamp)
+	if err != nil {
+		return errors.New("invalid alexa request timestamp")
+	}
+	if age := now.Sub(sent); age > alexaTimestampTolerance || age < -alexaTimestampTolerance {
+		return errors.New("alexa request timestamp is too old")
+	}
+	return nil
+}
+
+// Command returns what the member asked for. The skill's interaction model defines
+// ChoresTodayIntent, ShoppingListIntent, AddShoppingItemIntent with the Item and Quantity slots and
+// CompleteChoreIntent with the Chore slot.
+func (r AlexaRequest) Command() Command {
+	if r.Request.Type == "LaunchRequest" || r.Request.Intent == nil {
+		return Command{Intent: IntentWelcome}
+	}
+	slot := func(name string) string {
+		return strings.TrimSpace(r.Request.Intent.Slots[name].Value)
+	}
+	switch r.Request.Intent.Name {
+	case "ChoresTodayIntent":
+		return Command{Intent: IntentChoresToday}
+	case "ShoppingListIntent":
+		return Command{Intent: IntentShoppingList}
+	case "AddShoppingItemIntent":
+		command := Command{Intent: IntentAddShoppingItem, Item: slot("Item")}
+		if quantity, err := strconv.ParseFloat(slot("Quantity"), 64); err == nil {
+			command.Quantity = quantity
+		}
+		return command
+	case "CompleteChoreIntent":
+		return Command{Intent: IntentCompleteChore, Chore: slot("Chore")}
+	case "AMAZON.StopIntent", "AMAZON.CancelIntent", "AMAZON.NoIntent":
+		return Command{Intent: IntentStop}
+	}
+	return Command{Intent: IntentHelp}
+}
+
+// AlexaResponse is the body of a skill's answer
+type AlexaResponse struct {
+	Version  string `json:"version"`
+	Response struct {
+		OutputSpeech     *AlexaSpeech   `json:"outputSpeech,omitempty"`
+		Card             *AlexaCard     `json:"card,omitempty"`
+		Reprompt         *AlexaReprompt `json:"reprompt,omitempty"`
+		ShouldEndSession bool           `json:"shouldEndSession"`
+	} `json:"response"`
+}
+
+// AlexaReprompt is said when the member does not answer
+type AlexaReprompt struct {
+	OutputSpeech AlexaSpeech `json:"outputSpeech"`
+}
+
+// AlexaSpeech is what Alexa says
+type AlexaSpeech struct {
+	Type string `json:"type"` // PlainText
+	Text string `json:"text"`
+}
+
+// AlexaCard is shown in the Alexa app
+type AlexaCard struct {
+	Type    string `json:"type"` // Simple, or LinkAccount to ask the member to link their account
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// NewAlexaResponse answers with the speech. Unless the session ends, Alexa keeps listening and
+// repeats the speech if nothing is heard.
+func NewAlexaResponse(speech string, endSession bool) AlexaResponse {
+	response := AlexaResponse{Version: "1.0"}
+	response.Response.OutputSpeech = &AlexaSpeech{Type: "PlainText", Text: speech}
+	response.Response.ShouldEndSession = endSession
+	if !endSession {
+		response.Response.Reprompt = &AlexaReprompt{OutputSpeech: AlexaSpeech{Type: "PlainText", Text: speech}}
+	}
+	return response
+}
+
+// NewAlexaLinkAccountResponse asks the member to link their account, with a card in the Alexa
+// app that starts linking
+func NewAlexaLinkAccountResponse() AlexaResponse {
+	response := NewAlexaResponse(LinkAccountSpeech, true)
+	response.Response.Card = &AlexaCard{Type: "LinkAccount"}
+	return response
+}
+
+// AlexaVerifier checks that requests were signed by Alexa with a certificate issued for the Alexa
+// service. Certificate chains are cached by URL until they expire.
+type AlexaVerifier struct {
+	// Fetch downloads a certificate chain; an HTTP GET unless replaced
+	Fetch func(ctx context.Context, certURL string) ([]byte, error)
+	// Roots are the trusted certificate authorities; the system's unless set
+	Roots *x509.CertPool
+
+	mu    sync.Mutex
+	certs map[string][]*x509.Certificate
+}
+
+// NewAlexaVerifier creates a verifier that downloads certificates over HTTPS
+func NewAlexaVerifier() *AlexaVerifier {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return &AlexaVerifier{
+		Fetch: func(ctx context.Context, certURL string) ([]byte, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+			if err != nil {
+				return nil, err
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("certificate chain returned status %d", resp.StatusCode)
+			}
+			return io.ReadAll(io.LimitReader(resp.Body, maxCertChainSize))
+		},
+	}
+}
+
+// Verify checks the signature of the request body against the certificate chain in the request's
+// headers
+func (v *AlexaVerifier) Verify(ctx context.Context, header http.Header, body []byte, now time.Time) error {
+	certURL, err := normalizeAlexaCertURL(header.Get(AlexaCertURLHeader))
+	if err != nil {
+		return err
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get(AlexaSignatureHeader))
+	if err != nil || len(signature) == 0 {
+		return ErrInvalidAlexaSignature
+	}
+
+	chain, err := v.chain(ctx, certURL, now)
+	if err != nil {
+		return err
+	}
+	key, ok := chain[0].PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return ErrInvalidAlexaSignature
+	}
+	digest := sha256.Sum256(body)
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return ErrInvalidAlexaSignature
+	}
+	return nil
+}
+
+// chain returns the verified certificate chain at the URL, signing certificate first
+func (v *AlexaVerifier) chain(ctx context.Context, certURL string, now time.Time) ([]*x509.Certificate, error) {
+	v.mu.Lock()
+	cached, ok := v.certs[certURL]
+	v.mu.Unlock()
+	if ok && now.Before(cached[0].NotAfter) {
+		return cached, nil
+	}
+
+	data, err := v.Fetch(ctx, certURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch alexa certificate chain: %v", err)
+	}
+	certs := make([]*x509.Certificate, 0)
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alexa certificate chain: %v", err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("alexa certificate chain is empty")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err = certs[0].Verify(x509.VerifyOptions{
+		DNSName:       alexaCertName,
+		Intermediates: intermediates,
+		Roots:         v.Roots,
+		CurrentTime:   now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("untrusted alexa certificate: %v", err)
+	}
+
+	v.mu.Lock()
+	if v.certs == nil {
+		v.certs = make(map[string][]*x509.Certificate)
+	}
+	v.certs[certURL] = certs
+	v.mu.Unlock()
+	return certs, nil
+}
+
+// normalizeAlexaCertURL checks that a certificate chain URL points where Alexa keeps its
+// certificates: https://s3.amazonaws.com/echo.api/..., on the default port
+func normalizeAlexaCertURL(raw string) (string, error) {
+	invalid := fmt.Errorf("invalid alexa certificate URL %q", raw)
+	certURL, err := url.Parse(raw)
+	if err != nil || !strings.EqualFold(certURL.Scheme, "https") || !strings.EqualFold(certURL.Hostname(), alexaCertHost) {
+		return "", invalid
+	}
+	if port := certURL.Port(); port != "" && port != "443" {
+		return "", invalid
+	}
+	cleaned := path.Clean(certURL.Path)
+	if !strings.HasPrefix(cleaned, alexaCertPath) {
+		return "", invalid
+	}
+	return "https://" + alexaCertHost + cleaned, nil
+}
@@ -0,0 +1,124 @@
+// voice/google.go
+package voice
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DialogflowRequest is the body of a fulfillment request Dialogflow sends for a Google Assistant
+// action
+type DialogflowRequest struct {
+	ResponseID  string `json:"responseId"`
+	Session     string `json:"session"`
+	QueryResult struct {
+		QueryText  string                 `json:"queryText"`
+		Parameters map[string]interface{} `json:"parameters,omitempty"`
+		Intent     struct {
+			DisplayName string `json:"displayName"`
+		} `json:"intent"`
+	} `json:"queryResult"`
+	OriginalDetectIntentRequest struct {
+		Source  string `json:"source"`
+		Payload struct {
+			User struct {
+				AccessToken string `json:"accessToken,omitempty"`
+			} `json:"user"`
+		} `json:"payload"`
+	} `json:"originalDetectIntentRequest"`
+}
+
+// AccessToken returns the access token of the linked account, or "" when it is not linked
+func (r DialogflowRequest) AccessToken() string {
+	return r.OriginalDetectIntentRequest.Payload.User.AccessToken
+}
+
+// Command returns what the member asked for. The agent defines the Welcome, Chores Today,
+// Shopping List, Add Shopping Item (item and quantity parameters), Complete Chore (chore
+// parameter), Help and Stop intents; anything else, such as the fallback intent, is read as a
+// sentence the way inbound hooks read it.
+func (r DialogflowRequest) Command() Command {
+	parameter := func(name string) string {
+		switch value := r.QueryResult.Parameters[name].(type) {
+		case string:
+			return strings.TrimSpace(value)
+		case float64:
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		return ""
+	}
+	switch r.QueryResult.Intent.DisplayName {
+	case "Welcome", "Default Welcome Intent":
+		return Command{Intent: IntentWelcome}
+	case "Chores Today":
+		return Command{Intent: IntentChoresToday}
+	case "Shopping List":
+		return Command{Intent: IntentShoppingList}
+	case "Add Shopping Item":
+		command := Command{Intent: IntentAddShoppingItem, Item: parameter("item")}
+		if quantity, err := strconv.ParseFloat(parameter("quantity"), 64); err == nil {
+			command.Quantity = quantity
+		}
+		return command
+	case "Complete Chore":
+		return Command{Intent: IntentCompleteChore, Chore: parameter("chore")}
+	case "Help":
+		return Command{Intent: IntentHelp}
+	case "Stop":
+		return Command{Intent: IntentStop}
+	}
+	return commandFromText(r.QueryResult.QueryText)
+}
+
+// DialogflowResponse is the body of a fulfillment answer, with the Google Assistant payload
+type DialogflowResponse struct {
+	FulfillmentText string `json:"fulfillmentText"`
+	Payload         struct {
+		Google GooglePayload `json:"google"`
+	} `json:"payload"`
+}
+
+// GooglePayload is what Google Assistant says and whether it keeps listening
+type GooglePayload struct {
+	ExpectUserResponse bool `json:"expectUserResponse"`
+	RichResponse       struct {
+		Items []GoogleResponseItem `json:"items"`
+	} `json:"richResponse"`
+	SystemIntent *GoogleSystemIntent `json:"systemIntent,omitempty"`
+}
+
+// GoogleResponseItem is a part of the answer
+type GoogleResponseItem struct {
+	SimpleResponse struct {
+		TextToSpeech string `json:"textToSpeech"`
+	} `json:"simpleResponse"`
+}
+
+// GoogleSystemIntent hands the conversation to Google, here to link the member's account
+type GoogleSystemIntent struct {
+	Intent string                 `json:"intent"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+// NewDialogflowResponse answers with the speech, keeping the conversation open unless it ends
+func NewDialogflowResponse(speech string, endConversation bool) DialogflowResponse {
+	response := DialogflowResponse{FulfillmentText: speech}
+	response.Payload.Google.ExpectUserResponse = !endConversation
+	item := GoogleResponseItem{}
+	item.SimpleResponse.TextToSpeech = speech
+	response.Payload.Google.RichResponse.Items = []GoogleResponseItem{item}
+	return response
+}
+
+// NewDialogflowSignInResponse asks Google Assistant to link the member's account
+func NewDialogflowSignInResponse() DialogflowResponse {
+	response := NewDialogflowResponse(LinkAccountSpeech, false)
+	response.Payload.Google.SystemIntent = &GoogleSystemIntent{
+		Intent: "actions.intent.SIGN_IN",
+		Data: map[string]interface{}{
+			"@type":      "type.googleapis.com/google.actions.v2.SignInValueSpec",
+			"optContext": "To manage your chores and shopping list",
+		},
+	}
+	return response
+}
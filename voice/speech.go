@@ -0,0 +1,138 @@
+// voice/speech.go
+package voice
+
+import (
+	"cribb-backend/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxSpokenItems is how many shopping list items are read out before the rest are counted
+const maxSpokenItems = 8
+
+// Intent is what a member asked their voice assistant to do
+type Intent string
+
+const (
+	IntentWelcome         Intent = "welcome" // The skill or action was opened without a request
+	IntentChoresToday     Intent = "chores_today"
+	IntentAddShoppingItem Intent = "add_shopping_item"
+	IntentCompleteChore   Intent = "complete_chore"
+	IntentShoppingList    Intent = "shopping_list"
+	IntentHelp            Intent = "help"
+	IntentStop            Intent = "stop"
+)
+
+// Command is a request to a voice assistant with the values it was given
+type Command struct {
+	Intent   Intent
+	Item     string  // Item to add to the shopping list
+	Quantity float64 // Defaults to 1
+	Chore    string  // Title of the chore to complete
+}
+
+// InboundCommand converts an add or complete command to the command inbound hooks run, checking
+// that the item or chore was heard
+func (c Command) InboundCommand() (models.InboundCommand, error) {
+	command := models.InboundCommand{Item: c.Item, Quantity: c.Quantity, Chore: c.Chore}
+	switch c.Intent {
+	case IntentAddShoppingItem:
+		command.Action = models.InboundActionAddShoppingItem
+	case IntentCompleteChore:
+		command.Action = models.InboundActionCompleteChore
+	default:
+		return command, fmt.Errorf("%s is not an inbound command", c.Intent)
+	}
+	return command, command.Validate()
+}
+
+// commandFromText understands a sentence the assistant could not match to an intent, as inbound
+// hooks do, after dropping how people start sentences to an assistant: "please", "I", "I've"
+func commandFromText(text string) Command {
+	text = strings.TrimSpace(strings.ToLower(text))
+	for _, prefix := range []string{"please ", "i've ", "i have ", "i "} {
+		text = strings.TrimPrefix(text, prefix)
+	}
+	command, err := models.ParseInboundCommand(text)
+	if err != nil {
+		return Command{Intent: IntentHelp}
+	}
+	if command.Action == models.InboundActionCompleteChore {
+		return Command{Intent: IntentCompleteChore, Chore: command.Chore}
+	}
+	return Command{Intent: IntentAddShoppingItem, Item: command.Item, Quantity: command.Quantity}
+}
+
+const (
+	// WelcomeSpeech greets members who open the skill or action
+	WelcomeSpeech = "Welcome to Cribb. You can ask what your chores are today, add something to the shopping list, or mark a chore as done."
+	// HelpSpeech lists what can be asked
+	HelpSpeech = "You can say: what are my chores today, add eggs to the list, what's on the shopping list, or I finished the dishes."
+	// StopSpeech ends the conversation
+	StopSpeech = "Goodbye."
+	// LinkAccountSpeech asks members to link their account before anything else
+	LinkAccountSpeech = "To use Cribb, link your Cribb account in the app first."
+)
+
+// SpokenList joins the items as they are read out: "a", "a and b", "a, b, and c"
+func SpokenList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+}
+
+// SpokenItem reads out a shopping list item with its quantity unless it is a single one: "eggs",
+// "2 eggs", "1.5 kg flour"
+func SpokenItem(name string, quantity float64, unit string) string {
+	if quantity <= 0 || (quantity == 1 && unit == "") {
+		return name
+	}
+	spoken := strconv.FormatFloat(quantity, 'f', -1, 64)
+	if unit != "" {
+		spoken += " " + unit
+	}
+	return spoken + " " + name
+}
+
+// ChoresTodaySpeech tells a member which of their chores are due today and which are overdue
+func ChoresTodaySpeech(dueToday, overdue []string) string {
+	var speech string
+	switch len(dueToday) {
+	case 0:
+		speech = "You have no chores due today."
+	case 1:
+		speech = "You have one chore due today: " + dueToday[0] + "."
+	default:
+		speech = fmt.Sprintf("You have %d chores due today: %s.", len(dueToday), SpokenList(dueToday))
+	}
+	switch len(overdue) {
+	case 0:
+	case 1:
+		speech += " " + overdue[0] + " is overdue."
+	default:
+		speech += " " + SpokenList(overdue) + " are overdue."
+	}
+	return speech
+}
+
+// ShoppingListSpeech reads out the items on the shopping list, counting those past the first few
+func ShoppingListSpeech(items []string) string {
+	switch len(items) {
+	case 0:
+		return "The shopping list is empty."
+	case 1:
+		return "The shopping list has " + items[0] + "."
+	}
+	if len(items) <= maxSpokenItems {
+		return fmt.Sprintf("The shopping list has %d items: %s.", len(items), SpokenList(items))
+	}
+	spoken := append(append([]string{}, items[:maxSpokenItems]...), fmt.Sprintf("%d more", len(items)-maxSpokenItems))
+	return fmt.Sprintf("The shopping list has %d items: %s.", len(items), SpokenList(spoken))
+}
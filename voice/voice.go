@@ -0,0 +1,127 @@
+// voice/voice.go
+package voice
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Assistant is a voice assistant that links members' accounts through OAuth, registered as an
+// OAuth client with the credentials entered in the Alexa or Actions console
+type Assistant struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURIs []string // Where authorization codes may be sent, matched exactly
+}
+
+// AllowsRedirect reports whether codes may be sent to the redirect URI
+func (a Assistant) AllowsRedirect(uri string) bool {
+	for _, allowed := range a.RedirectURIs {
+		if uri == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckSecret compares the client secret in constant time
+func (a Assistant) CheckSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(a.ClientSecret), []byte(secret)) == 1
+}
+
+var (
+	// assistants are the voice assistants members can link their accounts to
+	assistants []Assistant
+
+	// alexaSkillID is the only skill requests are accepted from when set (ALEXA_SKILL_ID)
+	alexaSkillID string
+
+	// googleWebhookSecret is the basic auth password Dialogflow sends with fulfillment requests
+	// (GOOGLE_ASSISTANT_WEBHOOK_SECRET)
+	googleWebhookSecret string
+)
+
+// Configure registers the assistants set up in the environment. Alexa is registered from
+// ALEXA_CLIENT_ID, ALEXA_CLIENT_SECRET and ALEXA_REDIRECT_URIS, and Google Assistant from the same
+// variables starting with GOOGLE_ASSISTANT_; redirect URIs are separated by commas.
+func Configure() {
+	alexaSkillID = strings.TrimSpace(os.Getenv("ALEXA_SKILL_ID"))
+	googleWebhookSecret = strings.TrimSpace(os.Getenv("GOOGLE_ASSISTANT_WEBHOOK_SECRET"))
+
+	assistants = nil
+	for _, assistant := range []struct{ name, prefix string }{
+		{"Alexa", "ALEXA_"},
+		{"Google Assistant", "GOOGLE_ASSISTANT_"},
+	} {
+		registered, err := assistantFromEnv(assistant.name, assistant.prefix)
+		if err != nil {
+			log.Printf("%s account linking disabled: %v", assistant.name, err)
+			continue
+		}
+		if registered != nil {
+			assistants = append(assistants, *registered)
+			log.Printf("%s account linking enabled", assistant.name)
+		}
+	}
+}
+
+// assistantFromEnv reads an assistant's OAuth client from the environment. It returns nil
+// without an error when its client ID is not set.
+func assistantFromEnv(name, prefix string) (*Assistant, error) {
+	clientID := strings.TrimSpace(os.Getenv(prefix + "CLIENT_ID"))
+	if clientID == "" {
+		return nil, nil
+	}
+	assistant := Assistant{
+		Name:         name,
+		ClientID:     clientID,
+		ClientSecret: strings.TrimSpace(os.Getenv(prefix + "CLIENT_SECRET")),
+	}
+	for _, uri := range strings.Split(os.Getenv(prefix+"REDIRECT_URIS"), ",") {
+		if uri = strings.TrimSpace(uri); uri != "" {
+			assistant.RedirectURIs = append(assistant.RedirectURIs, uri)
+		}
+	}
+	if assistant.ClientSecret == "" || len(assistant.RedirectURIs) == 0 {
+		return nil, fmt.Errorf("%sCLIENT_SECRET and %sREDIRECT_URIS are required", prefix, prefix)
+	}
+	return &assistant, nil
+}
+
+// SetAssistants replaces the registered assistants
+func SetAssistants(registered []Assistant) {
+	assistants = registered
+}
+
+// FindAssistant returns the assistant registered with the OAuth client ID
+func FindAssistant(clientID string) (Assistant, bool) {
+	for _, assistant := range assistants {
+		if assistant.ClientID == clientID {
+			return assistant, true
+		}
+	}
+	return Assistant{}, false
+}
+
+// AlexaSkillAllowed reports whether requests from the skill are answered
+func AlexaSkillAllowed(skillID string) bool {
+	return alexaSkillID == "" || skillID == alexaSkillID
+}
+
+// GoogleWebhookEnabled reports whether Dialogflow fulfillment requests are answered
+func GoogleWebhookEnabled() bool {
+	return googleWebhookSecret != ""
+}
+
+// CheckGoogleWebhook reports whether the request carries the basic auth password set for the
+// Dialogflow fulfillment webhook
+func CheckGoogleWebhook(r *http.Request) bool {
+	_, password, ok := r.BasicAuth()
+	return ok && googleWebhookSecret != "" &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(googleWebhookSecret)) == 1
+}
@@ -0,0 +1,355 @@
+package voice
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cribb-backend/models"
+)
+
+func TestSpokenList(t *testing.T) {
+	cases := map[string][]string{
+		"":                     nil,
+		"milk":                 {"milk"},
+		"milk and eggs":        {"milk", "eggs"},
+		"milk, eggs, and rice": {"milk", "eggs", "rice"},
+	}
+	for want, items := range cases {
+		if got := SpokenList(items); got != want {
+			t.Errorf("SpokenList(%v) = %q, want %q", items, got, want)
+		}
+	}
+}
+
+func TestSpokenItem(t *testing.T) {
+	if got := SpokenItem("eggs", 1, ""); got != "eggs" {
+		t.Errorf("expected a single item without its quantity, got %q", got)
+	}
+	if got := SpokenItem("eggs", 12, ""); got != "12 eggs" {
+		t.Errorf("got %q", got)
+	}
+	if got := SpokenItem("flour", 1.5, "kg"); got != "1.5 kg flour" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestChoresTodaySpeech(t *testing.T) {
+	if got := ChoresTodaySpeech(nil, nil); got != "You have no chores due today." {
+		t.Errorf("got %q", got)
+	}
+	if got := ChoresTodaySpeech([]string{"dishes"}, nil); got != "You have one chore due today: dishes." {
+		t.Errorf("got %q", got)
+	}
+	got := ChoresTodaySpeech([]string{"dishes", "trash"}, []string{"laundry"})
+	if want := "You have 2 chores due today: dishes and trash. laundry is overdue."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	got = ChoresTodaySpeech(nil, []string{"laundry", "vacuuming"})
+	if want := "You have no chores due today. laundry and vacuuming are overdue."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestShoppingListSpeech(t *testing.T) {
+	if got := ShoppingListSpeech(nil); got != "The shopping list is empty." {
+		t.Errorf("got %q", got)
+	}
+	if got := ShoppingListSpeech([]string{"milk"}); got != "The shopping list has milk." {
+		t.Errorf("got %q", got)
+	}
+	items := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	want := "The shopping list has 10 items: a, b, c, d, e, f, g, h, and 2 more."
+	if got := ShoppingListSpeech(items); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func alexaIntentRequest(name string, slots map[string]string) AlexaRequest {
+	var request AlexaRequest
+	request.Request.Type = "IntentRequest"
+	request.Request.Intent = &AlexaIntent{Name: name, Slots: map[string]AlexaSlot{}}
+	for slot, value := range slots {
+		request.Request.Intent.Slots[slot] = AlexaSlot{Name: slot, Value: value}
+	}
+	return request
+}
+
+func TestAlexaCommand(t *testing.T) {
+	var launch AlexaRequest
+	launch.Request.Type = "LaunchRequest"
+	if command := launch.Command(); command.Intent != IntentWelcome {
+		t.Errorf("expected the launch request to welcome, got %+v", command)
+	}
+
+	command := alexaIntentRequest("AddShoppingItemIntent", map[string]string{"Item": " eggs ", "Quantity": "12"}).Command()
+	if command.Intent != IntentAddShoppingItem || command.Item != "eggs" || command.Quantity != 12 {
+		t.Errorf("unexpected command %+v", command)
+	}
+	inbound, err := command.InboundCommand()
+	if err != nil || inbound.Action != models.InboundActionAddShoppingItem || inbound.Item != "eggs" || inbound.Quantity != 12 {
+		t.Errorf("unexpected inbound command %+v %v", inbound, err)
+	}
+
+	command = alexaIntentRequest("CompleteChoreIntent", map[string]string{"Chore": "dishes"}).Command()
+	if command.Intent != IntentCompleteChore || command.Chore != "dishes" {
+		t.Errorf("unexpected command %+v", command)
+	}
+	if command := alexaIntentRequest("CompleteChoreIntent", nil).Command(); command.Intent != IntentCompleteChore {
+		t.Errorf("unexpected command %+v", command)
+	} else if _, err := command.InboundCommand(); err == nil {
+		t.Error("expected a chore to be required")
+	}
+
+	if command := alexaIntentRequest("AMAZON.StopIntent", nil).Command(); command.Intent != IntentStop {
+		t.Errorf("unexpected command %+v", command)
+	}
+	if command := alexaIntentRequest("AMAZON.FallbackIntent", nil).Command(); command.Intent != IntentHelp {
+		t.Errorf("unexpected command %+v", command)
+	}
+}
+
+func TestAlexaAccessTokenAndTimestamp(t *testing.T) {
+	var request AlexaRequest
+	if err := json.Unmarshal([]byte(`{
+		"session": {"application": {"applicationId": "amzn1.ask.skill.1"}, "user": {"userId": "u", "accessToken": "cat_1"}},
+		"request": {"type": "IntentRequest", "timestamp": "2025-03-01T12:00:00Z"}
+	}`), &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.AccessToken() != "cat_1" || request.SkillID() != "amzn1.ask.skill.1" {
+		t.Errorf("expected the session's token and skill, got %q %q", request.AccessToken(), request.SkillID())
+	}
+
+	sent := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := request.CheckTimestamp(sent.Add(2 * time.Minute)); err != nil {
+		t.Errorf("expected a recent request to pass, got %v", err)
+	}
+	if err := request.CheckTimestamp(sent.Add(3 * time.Minute)); err == nil {
+		t.Error("expected a replayed request to be rejected")
+	}
+}
+
+func TestDialogflowCommand(t *testing.T) {
+	var request DialogflowRequest
+	if err := json.Unmarshal([]byte(`{
+		"queryResult": {"queryText": "add 2 eggs", "parameters": {"item": "eggs", "quantity": 2}, "intent": {"displayName": "Add Shopping Item"}},
+		"originalDetectIntentRequest": {"source": "google", "payload": {"user": {"accessToken": "cat_2"}}}
+	}`), &request); err != nil {
+		t.Fatal(err)
+	}
+	command := request.Command()
+	if command.Intent != IntentAddShoppingItem || command.Item != "eggs" || command.Quantity != 2 || request.AccessToken() != "cat_2" {
+		t.Errorf("unexpected command %+v with token %q", command, request.AccessToken())
+	}
+
+	// Intents the agent does not define are read as sentences
+	request.QueryResult.Intent.DisplayName = "Default Fallback Intent"
+	request.QueryResult.QueryText = "I finished the dishes"
+	if command := request.Command(); command.Intent != IntentCompleteChore || command.Chore != "dishes" {
+		t.Errorf("unexpected command %+v", command)
+	}
+	request.QueryResult.QueryText = "sing a song"
+	if command := request.Command(); command.Intent != IntentHelp {
+		t.Errorf("expected help for a sentence that is not a command, got %+v", command)
+	}
+}
+
+func TestResponses(t *testing.T) {
+	alexa := NewAlexaResponse("Hello", false)
+	if alexa.Response.ShouldEndSession || alexa.Response.Reprompt == nil || alexa.Response.OutputSpeech.Text != "Hello" {
+		t.Errorf("unexpected response %+v", alexa.Response)
+	}
+	if link := NewAlexaLinkAccountResponse(); link.Response.Card == nil || link.Response.Card.Type != "LinkAccount" {
+		t.Errorf("expected a link account card, got %+v", link.Response)
+	}
+
+	google := NewDialogflowResponse("Bye", true)
+	if google.Payload.Google.ExpectUserResponse || google.Payload.Google.RichResponse.Items[0].SimpleResponse.TextToSpeech != "Bye" {
+		t.Errorf("unexpected response %+v", google)
+	}
+	if signIn := NewDialogflowSignInResponse(); signIn.Payload.Google.SystemIntent == nil || signIn.Payload.Google.SystemIntent.Intent != "actions.intent.SIGN_IN" {
+		t.Errorf("expected the sign in intent, got %+v", signIn.Payload.Google)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Setenv("ALEXA_CLIENT_ID", "alexa")
+	t.Setenv("ALEXA_CLIENT_SECRET", "secret")
+	t.Setenv("ALEXA_REDIRECT_URIS", "https://layla.amazon.com/api/skill/link/M1, https://pitangui.amazon.com/api/skill/link/M1")
+	t.Setenv("GOOGLE_ASSISTANT_CLIENT_ID", "google")
+	t.Setenv("GOOGLE_ASSISTANT_CLIENT_SECRET", "")
+	t.Setenv("GOOGLE_ASSISTANT_WEBHOOK_SECRET", "hook")
+	Configure()
+	defer SetAssistants(nil)
+
+	alexa, ok := FindAssistant("alexa")
+	if !ok || !alexa.AllowsRedirect("https://pitangui.amazon.com/api/skill/link/M1") || alexa.AllowsRedirect("https://example.com") {
+		t.Errorf("unexpected assistant %+v", alexa)
+	}
+	if !alexa.CheckSecret("secret") || alexa.CheckSecret("wrong") {
+		t.Error("expected only the client secret to pass")
+	}
+	if _, ok := FindAssistant("google"); ok {
+		t.Error("expected Google Assistant without a client secret to be left out")
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/api/voice/google", nil)
+	if CheckGoogleWebhook(r) {
+		t.Error("expected a request without credentials to be rejected")
+	}
+	r.SetBasicAuth("dialogflow", "hook")
+	if !CheckGoogleWebhook(r) {
+		t.Error("expected the webhook password to pass")
+	}
+}
+
+func TestNormalizeAlexaCertURL(t *testing.T) {
+	valid := map[string]string{
+		"https://s3.amazonaws.com/echo.api/echo-api-cert.pem":             "https://s3.amazonaws.com/echo.api/echo-api-cert.pem",
+		"https://s3.amazonaws.com:443/echo.api/echo-api-cert.pem":         "https://s3.amazonaws.com/echo.api/echo-api-cert.pem",
+		"HTTPS://s3.amazonaws.com/echo.api/../echo.api/echo-api-cert.pem": "https://s3.amazonaws.com/echo.api/echo-api-cert.pem",
+	}
+	for raw, want := range valid {
+		if got, err := normalizeAlexaCertURL(raw); err != nil || got != want {
+			t.Errorf("normalizeAlexaCertURL(%q) = %q, %v", raw, got, err)
+		}
+	}
+	for _, raw := range []string{
+		"http://s3.amazonaws.com/echo.api/echo-api-cert.pem",
+		"https://notamazon.com/echo.api/echo-api-cert.pem",
+		"https://s3.amazonaws.com/EcHo.aPi/echo-api-cert.pem",
+		"https://s3.amazonaws.com:563/echo.api/echo-api-cert.pem",
+		"https://s3.amazonaws.com/invalid.path/echo-api-cert.pem",
+		"https://s3.amazonaws.com/echo.api/../invalid.path/echo-api-cert.pem",
+	} {
+		if _, err := normalizeAlexaCertURL(raw); err == nil {
+			t.Errorf("expected %q to be rejected", raw)
+		}
+	}
+}
+
+// testChain creates a certificate authority and a signing certificate issued by it for the name
+func testChain(t *testing.T, name string) (*x509.CertPool, *rsa.PrivateKey, []byte) {
+	t.Helper()
+	caKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})
+	chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})...)
+	return roots, key, chain
+}
+
+func signedHeader(t *testing.T, key *rsa.PrivateKey, body []byte) http.Header {
+	t.Helper()
+	digest := sha256.Sum256(body)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set(AlexaCertURLHeader, "https://s3.amazonaws.com/echo.api/echo-api-cert.pem")
+	header.Set(AlexaSignatureHeader, base64.StdEncoding.EncodeToString(signature))
+	return header
+}
+
+func TestAlexaVerifier(t *testing.T) {
+	roots, key, chain := testChain(t, "echo-api.amazon.com")
+	fetches := 0
+	verifier := &AlexaVerifier{
+		Roots: roots,
+		Fetch: func(ctx context.Context, certURL string) ([]byte, error) {
+			fetches++
+			if certURL != "https://s3.amazonaws.com/echo.api/echo-api-cert.pem" {
+				return nil, errors.New("unexpected URL " + certURL)
+			}
+			return chain, nil
+		},
+	}
+	body := []byte(`{"version":"1.0"}`)
+	header := signedHeader(t, key, body)
+
+	if err := verifier.Verify(context.Background(), header, body, time.Now()); err != nil {
+		t.Fatalf("expected the signed request to pass, got %v", err)
+	}
+	if err := verifier.Verify(context.Background(), header, []byte(`{"version":"2.0"}`), time.Now()); !errors.Is(err, ErrInvalidAlexaSignature) {
+		t.Errorf("expected a changed body to be rejected, got %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("expected the certificate chain to be cached, fetched %d times", fetches)
+	}
+
+	header.Set(AlexaCertURLHeader, "https://example.com/echo.api/echo-api-cert.pem")
+	if err := verifier.Verify(context.Background(), header, body, time.Now()); err == nil {
+		t.Error("expected a certificate from elsewhere to be rejected")
+	}
+}
+
+func TestAlexaVerifierRejectsOtherCertificates(t *testing.T) {
+	roots, key, chain := testChain(t, "example.com")
+	verifier := &AlexaVerifier{
+		Roots: roots,
+		Fetch: func(ctx context.Context, certURL string) ([]byte, error) { return chain, nil },
+	}
+	body := []byte(`{}`)
+	if err := verifier.Verify(context.Background(), signedHeader(t, key, body), body, time.Now()); err == nil {
+		t.Error("expected a certificate not issued for echo-api.amazon.com to be rejected")
+	}
+
+	untrusted := &AlexaVerifier{
+		Roots: x509.NewCertPool(),
+		Fetch: func(ctx context.Context, certURL string) ([]byte, error) { return chain, nil },
+	}
+	if err := untrusted.Verify(context.Background(), signedHeader(t, key, body), body, time.Now()); err == nil {
+		t.Error("expected a certificate from an untrusted authority to be rejected")
+	}
+}
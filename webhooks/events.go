@@ -0,0 +1,77 @@
+// webhooks/events.go
+package webhooks
+
+import (
+	"context"
+	"cribb-backend/models"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Member identifies who caused an event
+type Member struct {
+	ID   primitive.ObjectID `json:"id"`
+	Name string             `json:"name"`
+}
+
+// ChoreCompletedData is the data of a chore.completed event
+type ChoreCompletedData struct {
+	Chore       models.Chore `json:"chore"`
+	CompletedBy Member       `json:"completed_by"`
+}
+
+// ItemAddedData is the data of a shopping.item_added event; the item has its new total quantity
+type ItemAddedData struct {
+	Item          models.ShoppingCartItem `json:"item"`
+	AddedQuantity float64                 `json:"added_quantity"`
+	AddedBy       Member                  `json:"added_by"`
+}
+
+// ExpenseAddedData is the data of an expense.added event
+type ExpenseAddedData struct {
+	Expense models.Expense `json:"expense"`
+	AddedBy Member         `json:"added_by"`
+}
+
+// ChoreAssigned queues a chore.assigned event for a new chore
+func ChoreAssigned(ctx context.Context, chore models.Chore) {
+	Enqueue(ctx, chore.GroupID, models.WebhookEventChoreAssigned,
+		fmt.Sprintf("%s was assigned, due %s", chore.Title, chore.DueDate.Format("Mon Jan 2")), chore)
+}
+
+// ChoreCompleted queues a chore.completed event
+func ChoreCompleted(ctx context.Context, chore models.Chore, completer models.User) {
+	Enqueue(ctx, chore.GroupID, models.WebhookEventChoreCompleted,
+		fmt.Sprintf("%s completed %s", completer.Name, chore.Title),
+		ChoreCompletedData{Chore: chore, CompletedBy: Member{ID: completer.ID, Name: completer.Name}})
+}
+
+// ItemAdded queues a shopping.item_added event for an item added to the shopping list, or merged
+// into one already on it. Personal items are left out.
+func ItemAdded(ctx context.Context, item models.ShoppingCartItem, addedQuantity float64, user models.User) {
+	if !item.IsShared {
+		return
+	}
+	Enqueue(ctx, item.GroupID, models.WebhookEventItemAdded,
+		fmt.Sprintf("%s added %s to the shopping list", user.Name, item.ItemName),
+		ItemAddedData{Item: item, AddedQuantity: addedQuantity, AddedBy: Member{ID: user.ID, Name: user.Name}})
+}
+
+// ItemsPurchased queues a shopping.item_purchased event for every purchased item that was shared
+func ItemsPurchased(ctx context.Context, records []*models.PurchaseRecord) {
+	for _, record := range records {
+		if !record.IsShared {
+			continue
+		}
+		Enqueue(ctx, record.GroupID, models.WebhookEventItemPurchased,
+			fmt.Sprintf("%s bought %s", record.PurchasedByName, record.ItemName), record)
+	}
+}
+
+// ExpenseAdded queues an expense.added event
+func ExpenseAdded(ctx context.Context, expense models.Expense, creator models.User) {
+	Enqueue(ctx, expense.GroupID, models.WebhookEventExpenseAdded,
+		fmt.Sprintf("%s added %s (%.2f %s)", creator.Name, expense.Description, expense.Amount, expense.Currency),
+		ExpenseAddedData{Expense: expense, AddedBy: Member{ID: creator.ID, Name: creator.Name}})
+}
@@ -0,0 +1,147 @@
+// webhooks/webhooks.go
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"cribb-backend/config"
+	"cribb-backend/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Headers sent with every delivery. The signature is "sha256=" and the hex HMAC-SHA256, keyed with
+// the webhook's secret, of the timestamp, a dot and the body, so receivers can check a delivery
+// came from Cribb and reject replays of old ones.
+const (
+	EventHeader     = "X-Cribb-Event"
+	DeliveryHeader  = "X-Cribb-Delivery"
+	TimestampHeader = "X-Cribb-Timestamp"
+	SignatureHeader = "X-Cribb-Signature"
+)
+
+// HTTPClient posts deliveries; receivers get 10 seconds to answer
+var HTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// Sign returns the signature header value of a delivery body sent at the Unix timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// discordMessage is the body of a Discord webhook execution
+type discordMessage struct {
+	Content  string `json:"content"`
+	Username string `json:"username"`
+}
+
+// BuildBody renders the payload in the webhook's format
+func BuildBody(format models.WebhookFormat, payload models.WebhookPayload) ([]byte, error) {
+	if format == models.WebhookFormatDiscord {
+		return json.Marshal(discordMessage{Content: payload.Summary, Username: "Cribb"})
+	}
+	return json.Marshal(payload)
+}
+
+// NewDelivery builds the delivery of an event to a webhook, due right away
+func NewDelivery(hook models.Webhook, event models.WebhookEvent, summary string, data interface{}, now time.Time) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID:            primitive.NewObjectID(),
+		WebhookID:     hook.ID,
+		GroupID:       hook.GroupID,
+		Event:         event,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	body, err := BuildBody(hook.Format, models.WebhookPayload{
+		ID:        delivery.ID,
+		Event:     event,
+		GroupID:   hook.GroupID,
+		Summary:   summary,
+		Data:      data,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery.Body = string(body)
+	return delivery, nil
+}
+
+// Enqueue queues the event for every active webhook of the group subscribed to it; the webhook
+// jobs deliver it. Failing to queue does not undo the event, so errors are only logged.
+func Enqueue(ctx context.Context, groupID primitive.ObjectID, event models.WebhookEvent, summary string, data interface{}) {
+	cursor, err := config.DB.Collection("webhooks").Find(ctx, bson.M{"group_id": groupID, "active": true})
+	if err != nil {
+		log.Printf("Failed to find webhooks for %s: %v", event, err)
+		return
+	}
+	var hooks []models.Webhook
+	if err := cursor.All(ctx, &hooks); err != nil {
+		log.Printf("Failed to decode webhooks for %s: %v", event, err)
+		return
+	}
+
+	now := time.Now()
+	deliveries := make([]interface{}, 0, len(hooks))
+	for _, hook := range hooks {
+		if !hook.Subscribed(event) {
+			continue
+		}
+		delivery, err := NewDelivery(hook, event, summary, data, now)
+		if err != nil {
+			log.Printf("Failed to build %s delivery for webhook %s: %v", event, hook.ID.Hex(), err)
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if _, err := config.DB.Collection("webhook_deliveries").InsertMany(ctx, deliveries); err != nil {
+		log.Printf("Failed to queue %d %s deliveries: %v", len(deliveries), event, err)
+	}
+}
+
+// Post sends a delivery to the webhook and returns the receiver's status code, or zero when it
+// could not be reached. Any status other than 2xx is an error.
+func Post(ctx context.Context, hook models.Webhook, delivery models.WebhookDelivery, now time.Time) (int, error) {
+	body := []byte(delivery.Body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Cribb-Webhooks/1.0")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, delivery.ID.Hex())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, body))
+
+	resp, err := HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"context"
+	"cribb-backend/models"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"ping"}`)
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign("whsec_test", 1700000000, body); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if Sign("whsec_other", 1700000000, body) == want || Sign("whsec_test", 1700000001, body) == want {
+		t.Error("expected the signature to depend on the secret and timestamp")
+	}
+}
+
+func TestNewDelivery(t *testing.T) {
+	hook := models.Webhook{ID: primitive.NewObjectID(), GroupID: primitive.NewObjectID(), Active: true}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	delivery, err := NewDelivery(hook, models.WebhookEventChoreCompleted, "Sam completed Dishes", map[string]string{"chore": "Dishes"}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if delivery.Status != models.WebhookDeliveryPending || !delivery.NextAttemptAt.Equal(now) || delivery.WebhookID != hook.ID {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	var payload struct {
+		ID      string            `json:"id"`
+		Event   string            `json:"event"`
+		Summary string            `json:"summary"`
+		Data    map[string]string `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(delivery.Body), &payload); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if payload.ID != delivery.ID.Hex() || payload.Event != "chore.completed" || payload.Summary != "Sam completed Dishes" || payload.Data["chore"] != "Dishes" {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
+
+func TestDiscordBody(t *testing.T) {
+	body, err := BuildBody(models.WebhookFormatDiscord, models.WebhookPayload{Event: models.WebhookEventItemAdded, Summary: "Sam added Milk to the shopping list"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var message map[string]string
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if message["content"] != "Sam added Milk to the shopping list" || message["username"] != "Cribb" || len(message) != 2 {
+		t.Errorf("unexpected Discord message %v", message)
+	}
+}
+
+func TestPost(t *testing.T) {
+	hook := models.Webhook{ID: primitive.NewObjectID(), Secret: "whsec_test", Active: true}
+	delivery, err := NewDelivery(hook, models.WebhookEventPing, "Test delivery from Cribb", nil, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		switch {
+		case string(body) != delivery.Body:
+			http.Error(w, "unexpected body", http.StatusBadRequest)
+		case timestamp != now.Unix() || r.Header.Get(SignatureHeader) != Sign("whsec_test", timestamp, body):
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+		case r.Header.Get(EventHeader) != "ping" || r.Header.Get(DeliveryHeader) != delivery.ID.Hex():
+			http.Error(w, "missing headers", http.StatusBadRequest)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	hook.URL = server.URL
+	if status, err := Post(context.Background(), hook, *delivery, now); err != nil || status != http.StatusNoContent {
+		t.Fatalf("expected the delivery to be accepted, got %d: %v", status, err)
+	}
+
+	hook.Secret = "whsec_rotated"
+	if status, err := Post(context.Background(), hook, *delivery, now); err == nil || status != http.StatusUnauthorized {
+		t.Errorf("expected a rejected signature to fail with 401, got %d: %v", status, err)
+	}
+}